@@ -217,7 +217,7 @@ func stateReaderHCL(_ context.Context, config *StateReaderConfig, paths []string
 				case config.Dev.URL.Schema != "":
 					realm.Schemas[0], err = nr.NormalizeSchema(ctx, realm.Schemas[0])
 				default:
-					realm, err = nr.NormalizeRealm(ctx, realm)
+					realm, err = normalizeRealm(ctx, nr, realm)
 				}
 				if err != nil {
 					return nil, err
@@ -250,6 +250,21 @@ func stateReaderHCL(_ context.Context, config *StateReaderConfig, paths []string
 	}, nil
 }
 
+// normalizeRealm normalizes the schemas of the given realm in a single round on the dev
+// database, if the driver supports it. Realms that hold objects (e.g., extensions) or a
+// single schema are normalized as a whole.
+func normalizeRealm(ctx context.Context, nr schema.Normalizer, r *schema.Realm) (*schema.Realm, error) {
+	sn, ok := nr.(schema.SchemasNormalizer)
+	if !ok || len(r.Schemas) < 2 || len(r.Objects) > 0 {
+		return nr.NormalizeRealm(ctx, r)
+	}
+	schemas, err := sn.NormalizeSchemas(ctx, r.Schemas...)
+	if err != nil {
+		return nil, err
+	}
+	return schema.NewRealm(schemas...), nil
+}
+
 // FilesExt returns the file extension of the given URLs.
 // Note, all URL must have the same extension.
 func FilesExt(urls []*url.URL) (string, error) {
@@ -177,7 +177,7 @@ func (r *Runner) analyze(ctx context.Context, files []*sqlcheck.File) error {
 				return az.Analyze(ctx, &sqlcheck.Pass{
 					File:     f,
 					Dev:      r.Dev,
-					Reporter: nl.reporterFor(fr, az),
+					Reporter: sqlcheck.WithSchemaPos(f, nl.reporterFor(fr, az)),
 				})
 			}(az)
 			// If the last report was skipped,
@@ -74,7 +74,7 @@ func (s *State) setInputVals(ctx *hcl.EvalContext, body hcl.Body, input map[stri
 }
 
 // evalReferences evaluates local and data blocks.
-func (s *State) evalReferences(ctx *hcl.EvalContext, body *hclsyntax.Body) error {
+func (s *State) evalReferences(ctx *hcl.EvalContext, opts *EvalOptions, body *hclsyntax.Body) error {
 	type node struct {
 		addr  [3]string
 		edges func() []hcl.Traversal
@@ -97,6 +97,9 @@ func (s *State) evalReferences(ctx *hcl.EvalContext, body *hclsyntax.Body) error
 				return fmt.Errorf("data block %q must have exactly 2 labels", b.Type)
 			}
 			h, ok := s.config.datasrc[b.Labels[0]]
+			if !ok {
+				h, ok = opts.DataSources[b.Labels[0]]
+			}
 			if !ok {
 				return fmt.Errorf("missing data source handler for %q", b.Labels[0])
 			}
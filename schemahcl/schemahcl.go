@@ -34,6 +34,7 @@ type (
 		datasrc, initblk map[string]BlockFunc
 		typedblk         map[string]map[string]BlockFunc
 		lazyattrs        map[string]bool
+		computed         map[string][]computedAttr
 		// Optional context to pass to dynamic block handlers,
 		// such as data-sources, type-blocks, etc.
 		ctx     context.Context
@@ -263,6 +264,108 @@ func (nopValidator) ValidateAttribute(*hcl.EvalContext, *hclsyntax.Attribute, ct
 	return nil
 }
 
+// ComputeFunc is the function signature for computing the value of an
+// attribute from the other attributes and blocks of its resource.
+type ComputeFunc func(*Resource) (cty.Value, error)
+
+// computedAttr describes an attribute that is computed by a ComputeFunc.
+type computedAttr struct {
+	name string
+	f    ComputeFunc
+}
+
+// WithComputedAttr registers an attribute of the blocks in the given path whose value is
+// derived from the other attributes of the block. An empty attribute name refers to the
+// block name (label). e.g., the example below derives the name of the index from its columns.
+//
+//	WithComputedAttr("table.index", "", func(r *Resource) (cty.Value, error) {
+//		a, ok := r.Attr("columns")
+//		if !ok {
+//			return cty.NilVal, errors.New("missing columns")
+//		}
+//		refs, err := a.Refs()
+//		...
+//		return cty.StringVal(strings.Join(names, "_") + "_idx"), nil
+//	})
+//
+//	table "users" {
+//	  ...
+//	  index {
+//	    columns = [column.name]
+//	  }
+//	}
+//
+// On evaluation, missing attributes are set to their computed value, and attributes
+// that were set explicitly must be consistent with it. On marshaling, missing attributes
+// are rendered with their computed value.
+func WithComputedAttr(path, name string, f ComputeFunc) Option {
+	return func(c *Config) {
+		if c.computed == nil {
+			c.computed = make(map[string][]computedAttr)
+		}
+		c.computed[path] = append(c.computed[path], computedAttr{name: name, f: f})
+	}
+}
+
+// compute sets the computed attributes of the resource
+// and checks the consistency of the ones that were set.
+func (s *State) compute(r *Resource, path string) error {
+	for _, c := range s.config.computed[path] {
+		v, err := c.f(r)
+		if err != nil {
+			return fmt.Errorf("schemahcl: computing %s of %s %q: %w", c, path, r.Name, err)
+		}
+		if c.name == "" {
+			if v.IsNull() || v.Type() != cty.String {
+				return fmt.Errorf("schemahcl: computed name of %s must be a string, got %s", path, v.Type().FriendlyName())
+			}
+			switch n := v.AsString(); {
+			case r.Name == "":
+				r.Name = n
+			case r.Name != n:
+				return fmt.Errorf("schemahcl: %s %q does not match its computed name %q", path, r.Name, n)
+			}
+			continue
+		}
+		switch a, ok := attrVal(r.Attrs, c.name); {
+		case !ok:
+			r.SetAttr(&Attr{K: c.name, V: v})
+		case !a.V.RawEquals(v):
+			return fmt.Errorf("schemahcl: %s of %s %q does not match its computed value", c, path, r.Name)
+		}
+	}
+	return nil
+}
+
+// computeChildren sets the computed attributes of the resource children, recursively.
+func (s *State) computeChildren(r *Resource, path string) error {
+	for _, c := range r.Children {
+		// Embedded resources are part of their parents.
+		if c.Type == "" {
+			continue
+		}
+		p := c.Type
+		if path != "" {
+			p = path + "." + c.Type
+		}
+		if err := s.computeChildren(c, p); err != nil {
+			return err
+		}
+		if err := s.compute(c, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// String implements fmt.Stringer.
+func (c computedAttr) String() string {
+	if c.name == "" {
+		return "name"
+	}
+	return fmt.Sprintf("attribute %q", c.name)
+}
+
 // WithSchemaValidator registers a schema validator to be used during unmarshaling.
 func WithSchemaValidator(v func() SchemaValidator) Option {
 	return func(c *Config) {
@@ -302,6 +405,11 @@ func (s *State) MarshalSpec(v any) ([]byte, error) {
 	if err := r.Scan(v); err != nil {
 		return nil, fmt.Errorf("schemahcl: failed scanning %T to resource: %w", v, err)
 	}
+	if len(s.config.computed) > 0 {
+		if err := s.computeChildren(r, ""); err != nil {
+			return nil, err
+		}
+	}
 	return s.encode(r)
 }
 
@@ -317,6 +425,11 @@ type EvalOptions struct {
 	// Validator is the schema validator to be used during evaluation.
 	// It defaults to the State (Driver) config.
 	Validator SchemaValidator
+
+	// DataSources holds additional data sources that are available to the evaluated
+	// documents, on top of the ones registered to the State (Driver) config. e.g.,
+	// enum values that are read from a reference table using a "sql" data source.
+	DataSources map[string]BlockFunc
 }
 
 // EvalFiles evaluates the files in the provided paths using the input variables and
@@ -361,7 +474,7 @@ func (s *State) EvalOptions(parsed *hclparse.Parser, v any, opts *EvalOptions) e
 			return err
 		}
 		body := file.Body.(*hclsyntax.Body)
-		if err := s.evalReferences(ctx, body); err != nil {
+		if err := s.evalReferences(ctx, opts, body); err != nil {
 			return err
 		}
 		blocks := make(hclsyntax.Blocks, 0, len(body.Blocks))
@@ -780,6 +893,9 @@ func (s *State) toResource(ctx *hcl.EvalContext, opts *EvalOptions, block *hclsy
 	if err := closeScope(); err != nil {
 		return nil, err
 	}
+	if err := s.compute(spec, strings.Join(scope, ".")); err != nil {
+		return nil, err
+	}
 	return spec, nil
 }
 
@@ -887,7 +1003,7 @@ func (s *State) writeAttr(attr *Attr, body *hclwrite.Body) error {
 	case attr.V.Type().IsListType():
 		// Skip scanning nil slices ([]T(nil)) by default. Users that
 		// want to print empty lists, should use make([]T, 0) instead.
-		if attr.V.LengthInt() == 0 {
+		if attr.V.IsNull() || attr.V.LengthInt() == 0 {
 			return nil
 		}
 		tokens := make([]hclwrite.Tokens, 0, attr.V.LengthInt())
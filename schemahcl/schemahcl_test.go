@@ -12,6 +12,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/hcl/v2"
@@ -355,6 +356,91 @@ ref = named.block_id.name
 	require.EqualValues(t, "atlas", test.Ref)
 }
 
+func TestComputedAttr(t *testing.T) {
+	type (
+		Index struct {
+			Name    string   `spec:",name"`
+			Columns []string `spec:"columns"`
+			Comment string   `spec:"comment,omitempty"`
+		}
+		Table struct {
+			Name    string   `spec:",name"`
+			Indexes []*Index `spec:"index"`
+		}
+		Doc struct {
+			Tables []*Table `spec:"table"`
+		}
+	)
+	state := New(
+		WithComputedAttr("table.index", "", func(r *Resource) (cty.Value, error) {
+			a, ok := r.Attr("columns")
+			if !ok {
+				return cty.NilVal, errors.New("missing columns")
+			}
+			cs, err := a.Strings()
+			if err != nil {
+				return cty.NilVal, err
+			}
+			return cty.StringVal(strings.Join(cs, "_") + "_idx"), nil
+		}),
+		WithComputedAttr("table.index", "comment", func(r *Resource) (cty.Value, error) {
+			return cty.StringVal("generated"), nil
+		}),
+	)
+	var d Doc
+	err := state.EvalBytes([]byte(`
+table "users" {
+  index {
+    columns = ["first", "last"]
+  }
+  index "email_idx" {
+    columns = ["email"]
+    comment = "generated"
+  }
+}
+`), &d, nil)
+	require.NoError(t, err)
+	require.Equal(t, []*Index{
+		{Name: "first_last_idx", Columns: []string{"first", "last"}, Comment: "generated"},
+		{Name: "email_idx", Columns: []string{"email"}, Comment: "generated"},
+	}, d.Tables[0].Indexes)
+
+	err = state.EvalBytes([]byte(`
+table "users" {
+  index "idx" {
+    columns = ["email"]
+  }
+}
+`), &d, nil)
+	require.EqualError(t, err, `schemahcl: table.index "idx" does not match its computed name "email_idx"`)
+	err = state.EvalBytes([]byte(`
+table "users" {
+  index {
+    columns = ["email"]
+    comment = "manual"
+  }
+}
+`), &d, nil)
+	require.EqualError(t, err, `schemahcl: attribute "comment" of table.index "email_idx" does not match its computed value`)
+	err = state.EvalBytes([]byte(`
+table "users" {
+  index {}
+}
+`), &d, nil)
+	require.EqualError(t, err, `schemahcl: computing name of table.index "": missing columns`)
+
+	// Marshal renders the computed values.
+	buf, err := state.MarshalSpec(&Doc{Tables: []*Table{{Name: "users", Indexes: []*Index{{Columns: []string{"email"}}}}}})
+	require.NoError(t, err)
+	require.Equal(t, `table "users" {
+  index "email_idx" {
+    columns = ["email"]
+    comment = "generated"
+  }
+}
+`, string(buf))
+}
+
 func TestRefPatch(t *testing.T) {
 	type (
 		Family struct {
@@ -169,6 +169,7 @@ func Scan(r *schema.Realm, doc *ScanDoc, funcs *ScanFuncs) error {
 	}
 	var (
 		fks     = make(map[*schema.Table][]*sqlspec.ForeignKey)
+		clones  = make(map[*schema.Table]*schemahcl.Ref)
 		deps    = make(map[schema.Object][]*schemahcl.Ref, len(doc.Views))
 		aliases = make(map[string]string)
 	)
@@ -197,6 +198,21 @@ func Scan(r *schema.Realm, doc *ScanDoc, funcs *ScanFuncs) error {
 			}
 			deps[t] = refs
 		}
+		if c, ok := st.Attr("clone"); ok {
+			if len(st.Columns) > 0 || st.PrimaryKey != nil || len(st.Indexes) > 0 || len(st.ForeignKeys) > 0 || len(st.Checks) > 0 {
+				return fmt.Errorf("table %q is a clone and cannot define columns, keys, indexes or checks", st.Name)
+			}
+			ref, err := c.Ref()
+			if err != nil {
+				return fmt.Errorf("expect reference for attribute table.%s.clone: %w", st.Name, err)
+			}
+			clones[t] = &schemahcl.Ref{V: ref}
+		}
+	}
+	for t, ref := range clones {
+		if err := fromClone(t, ref, clones, aliases); err != nil {
+			return err
+		}
 	}
 	// Link the foreign keys.
 	for t, fks := range fks {
@@ -623,6 +639,15 @@ func linkForeignKeys(funcs *ScanFuncs, tbl *schema.Table, fks []*sqlspec.Foreign
 		if spec.OnDelete != nil {
 			fk.OnDelete = schema.ReferenceOption(FromVar(spec.OnDelete.V))
 		}
+		if a, ok := spec.Attr("virtual"); ok {
+			v, err := a.Bool()
+			if err != nil {
+				return fmt.Errorf("sqlspec: extract virtual attribute of foreign-key %q: %w", fk.Symbol, err)
+			}
+			if v {
+				fk.AddAttrs(&schema.VirtualFK{})
+			}
+		}
 		if n, m := len(spec.Columns), len(spec.RefColumns); n != m {
 			return fmt.Errorf("sqlspec: number of referencing and referenced columns do not match for foreign-key %q", fk.Symbol)
 		}
@@ -950,6 +975,68 @@ func fromDependsOn[T interface{ AddDeps(...schema.Object) T }](loc string, t T,
 	return nil
 }
 
+// fromClone copies the structure of the table referenced by the clone attribute to
+// the given table. That is, its columns, primary key and checks. Secondary indexes and
+// foreign keys are not copied.
+func fromClone(t *schema.Table, r *schemahcl.Ref, clones map[*schema.Table]*schemahcl.Ref, aliases map[string]string) error {
+	p, err := r.Path()
+	if err != nil {
+		return fmt.Errorf("extract table.%s.clone reference: %w", t.Name, err)
+	}
+	if len(p) == 0 || p[0].T != typeTable && aliases[p[0].T] != typeTable {
+		return fmt.Errorf("table.%s.clone must reference a table", t.Name)
+	}
+	q, n, err := RefName(r, p[0].T)
+	if err != nil {
+		return fmt.Errorf("extract table name from table.%s.clone: %w", t.Name, err)
+	}
+	src, err := findT(t.Schema, q, n, func(s *schema.Schema, name string) (*schema.Table, bool) {
+		return s.Table(name)
+	})
+	if err != nil {
+		return fmt.Errorf("find table reference for table.%s.clone: %w", t.Name, err)
+	}
+	switch {
+	case src == t:
+		return fmt.Errorf("table %q cannot be cloned from itself", t.Name)
+	case clones[src] != nil:
+		return fmt.Errorf("table %q cannot be cloned from table %q, which is a clone itself", t.Name, src.Name)
+	}
+	byName := make(map[string]*schema.Column, len(src.Columns))
+	for _, c := range src.Columns {
+		c1 := &schema.Column{
+			Name:    c.Name,
+			Default: c.Default,
+			Attrs:   slices.Clone(c.Attrs),
+		}
+		if c.Type != nil {
+			ct := *c.Type
+			c1.Type = &ct
+		}
+		byName[c.Name] = c1
+		t.AddColumns(c1)
+	}
+	if pk := src.PrimaryKey; pk != nil {
+		pk1 := &schema.Index{Unique: pk.Unique, Table: t, Attrs: slices.Clone(pk.Attrs)}
+		for _, p := range pk.Parts {
+			p1 := &schema.IndexPart{SeqNo: p.SeqNo, Desc: p.Desc, X: p.X, Attrs: slices.Clone(p.Attrs)}
+			if p.C != nil {
+				p1.C = byName[p.C.Name]
+				p1.C.Indexes = append(p1.C.Indexes, pk1)
+			}
+			pk1.Parts = append(pk1.Parts, p1)
+		}
+		t.SetPrimaryKey(pk1)
+	}
+	for _, a := range src.Attrs {
+		if c, ok := a.(*schema.Check); ok {
+			t.AddChecks(&schema.Check{Name: c.Name, Expr: c.Expr, Attrs: slices.Clone(c.Attrs)})
+		}
+	}
+	t.AddAttrs(&schema.Clone{Source: src})
+	return nil
+}
+
 // FromPrimaryKey converts schema.Index to a sqlspec.PrimaryKey.
 func FromPrimaryKey(s *schema.Index) (*sqlspec.PrimaryKey, error) {
 	c := make([]*schemahcl.Ref, 0, len(s.Parts))
@@ -1169,6 +1256,9 @@ func FromForeignKey(s *schema.ForeignKey) (*sqlspec.ForeignKey, error) {
 	if s.OnDelete != "" {
 		fk.OnDelete = &schemahcl.Ref{V: Var(string(s.OnDelete))}
 	}
+	if s.Virtual() {
+		fk.Extra.Attrs = append(fk.Extra.Attrs, schemahcl.BoolAttr("virtual", true))
+	}
 	return fk, nil
 }
 
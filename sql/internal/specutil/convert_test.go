@@ -95,6 +95,11 @@ func TestFromForeignKey(t *testing.T) {
 			{V: "$column.id"},
 		},
 	}, key)
+
+	fk.AddAttrs(&schema.VirtualFK{})
+	key, err = FromForeignKey(fk)
+	require.NoError(t, err)
+	require.Equal(t, []*schemahcl.Attr{schemahcl.BoolAttr("virtual", true)}, key.Extra.Attrs)
 }
 
 func TestDefault(t *testing.T) {
@@ -26,6 +26,7 @@ type (
 		Funcs        []*sqlspec.Func
 		Procs        []*sqlspec.Func
 		Materialized []*sqlspec.View
+		Sequences    []*sqlspec.Sequence
 		// Collected triggers to convert into spec.
 		Triggers []*schema.Trigger
 	}
@@ -37,13 +38,14 @@ type (
 	}
 	// Doc represents the common HCL spec document.
 	Doc struct {
-		Tables       []*sqlspec.Table   `spec:"table"`
-		Views        []*sqlspec.View    `spec:"view"`
-		Materialized []*sqlspec.View    `spec:"materialized"`
-		Funcs        []*sqlspec.Func    `spec:"function"`
-		Procs        []*sqlspec.Func    `spec:"procedure"`
-		Triggers     []*sqlspec.Trigger `spec:"trigger"`
-		Schemas      []*sqlspec.Schema  `spec:"schema"`
+		Tables       []*sqlspec.Table    `spec:"table"`
+		Views        []*sqlspec.View     `spec:"view"`
+		Materialized []*sqlspec.View     `spec:"materialized"`
+		Sequences    []*sqlspec.Sequence `spec:"sequence"`
+		Funcs        []*sqlspec.Func     `spec:"function"`
+		Procs        []*sqlspec.Func     `spec:"procedure"`
+		Triggers     []*sqlspec.Trigger  `spec:"trigger"`
+		Schemas      []*sqlspec.Schema   `spec:"schema"`
 	}
 )
 
@@ -63,6 +65,7 @@ func Marshal(v any, marshaler schemahcl.Marshaler, funcs RealmFuncs) ([]byte, er
 		d.Tables = spec.Tables
 		d.Views = spec.Views
 		d.Materialized = spec.Materialized
+		d.Sequences = spec.Sequences
 		d.Schemas = []*sqlspec.Schema{spec.Schema}
 		d.Funcs = spec.Funcs
 		d.Procs = spec.Procs
@@ -76,6 +79,7 @@ func Marshal(v any, marshaler schemahcl.Marshaler, funcs RealmFuncs) ([]byte, er
 			d.Tables = append(d.Tables, spec.Tables...)
 			d.Views = append(d.Views, spec.Views...)
 			d.Materialized = append(d.Materialized, spec.Materialized...)
+			d.Sequences = append(d.Sequences, spec.Sequences...)
 			d.Schemas = append(d.Schemas, spec.Schema)
 			d.Funcs = append(d.Funcs, spec.Funcs...)
 			d.Procs = append(d.Procs, spec.Procs...)
@@ -90,6 +94,9 @@ func Marshal(v any, marshaler schemahcl.Marshaler, funcs RealmFuncs) ([]byte, er
 		if err := QualifyObjects(d.Materialized); err != nil {
 			return nil, err
 		}
+		if err := QualifyObjects(d.Sequences); err != nil {
+			return nil, err
+		}
 		if err := QualifyObjects(d.Funcs); err != nil {
 			return nil, err
 		}
@@ -6,7 +6,9 @@ package sqlx
 
 import (
 	"context"
+	"crypto/rand"
 	"fmt"
+	"slices"
 	"strings"
 
 	"ariga.io/atlas/sql/migrate"
@@ -102,6 +104,20 @@ func (d *DevDriver) NormalizeRealm(ctx context.Context, r *schema.Realm) (nr *sc
 	if len(name2pos) > 0 {
 		name2pos.patchRealm(nr)
 	}
+	find := func(t *schema.Table) (*schema.Table, bool) {
+		if t.Schema == nil {
+			return nil, false
+		}
+		s, ok := nr.Schema(t.Schema.Name)
+		if !ok {
+			return nil, false
+		}
+		return s.Table(t.Name)
+	}
+	for _, s := range r.Schemas {
+		patchClones(s.Tables, find)
+		patchVirtualFKs(s.Tables, find)
+	}
 	return nr, nil
 }
 
@@ -193,9 +209,204 @@ func (d *DevDriver) NormalizeSchema(ctx context.Context, s *schema.Schema) (*sch
 	if len(name2pos) > 0 {
 		name2pos.patchSchema(ns)
 	}
+	find := func(t *schema.Table) (*schema.Table, bool) {
+		if t.Schema != s {
+			return nil, false
+		}
+		return ns.Table(t.Name)
+	}
+	patchClones(s.Tables, find)
+	patchVirtualFKs(s.Tables, find)
 	return ns, err
 }
 
+// patchClones attaches the Clone attributes of the given tables to their
+// normalized representation, as the clone information is lost on inspection.
+func patchClones(ts []*schema.Table, find func(*schema.Table) (*schema.Table, bool)) {
+	for _, t := range ts {
+		var c schema.Clone
+		if !Has(t.Attrs, &c) || c.Source == nil {
+			continue
+		}
+		nt, ok := find(t)
+		if !ok {
+			continue
+		}
+		if src, ok := find(c.Source); ok {
+			nt.AddAttrs(&schema.Clone{Source: src})
+		}
+	}
+}
+
+// patchVirtualFKs attaches the virtual foreign keys of the given tables to their normalized
+// representation, as they are not created in the dev database and are lost on inspection.
+func patchVirtualFKs(ts []*schema.Table, find func(*schema.Table) (*schema.Table, bool)) {
+	for _, t := range ts {
+		for _, fk := range t.ForeignKeys {
+			if !fk.Virtual() || fk.RefTable == nil {
+				continue
+			}
+			nt, ok := find(t)
+			if !ok {
+				continue
+			}
+			ref, ok := find(fk.RefTable)
+			if !ok {
+				continue
+			}
+			nfk := &schema.ForeignKey{
+				Symbol:   fk.Symbol,
+				Table:    nt,
+				RefTable: ref,
+				OnUpdate: fk.OnUpdate,
+				OnDelete: fk.OnDelete,
+				Attrs:    fk.Attrs,
+			}
+			if columnsOf(nt, fk.Columns, &nfk.Columns) && columnsOf(ref, fk.RefColumns, &nfk.RefColumns) {
+				nt.ForeignKeys = append(nt.ForeignKeys, nfk)
+				for _, c := range nfk.Columns {
+					c.ForeignKeys = append(c.ForeignKeys, nfk)
+				}
+			}
+		}
+	}
+}
+
+// columnsOf appends the columns of the table that match the given columns
+// by name, and reports if all of them were found.
+func columnsOf(t *schema.Table, cs []*schema.Column, to *[]*schema.Column) bool {
+	for _, c := range cs {
+		nc, ok := t.Column(c.Name)
+		if !ok {
+			return false
+		}
+		*to = append(*to, nc)
+	}
+	return true
+}
+
+// NormalizeSchemas returns the normal representation of the given schemas using a single round
+// on the dev database, instead of a create/drop cycle per schema as done by NormalizeSchema. The
+// schemas are copied and created side by side in the dev database under unique temporary names,
+// inspected at once, and then renamed back to their original names. Hence, schemas with the same
+// name (e.g., tenants that are defined by different specs) can be normalized together, and the
+// given schemas are not modified. Note, the dev driver must be connected to a database (realm)
+// scope to create schemas.
+//
+// Since schemas are renamed, references between them are supported only if they are not textual.
+// For example, foreign keys between the given schemas are supported, but views, functions,
+// procedures, triggers or checks that reference one of the given schemas by name would reference
+// the original schema instead of the temporary one. In this case, the schemas are normalized under
+// their own names, as done by NormalizeRealm, and schemas with the same name are rejected.
+func (d *DevDriver) NormalizeSchemas(ctx context.Context, schemas ...*schema.Schema) ([]*schema.Schema, error) {
+	for _, s := range schemas {
+		ref, ok := schemaTextRef(s, schemas)
+		if !ok {
+			continue
+		}
+		names := make(map[string]bool, len(schemas))
+		for _, s1 := range schemas {
+			if names[s1.Name] {
+				return nil, fmt.Errorf("schema %q cannot be normalized with temporary name: %s references schema by name", s.Name, ref)
+			}
+			names[s1.Name] = true
+		}
+		return d.normalizeSchemas(ctx, schemas, schema.CopySchemas(schemas...))
+	}
+	var b [4]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return nil, err
+	}
+	copied := schema.CopySchemas(schemas...)
+	// Temporary names are unique, to avoid collisions with
+	// concurrent normalizations on a shared dev database.
+	for i, s := range copied {
+		s.Name = fmt.Sprintf("atlas_normalize_%x_%d", b, i)
+	}
+	return d.normalizeSchemas(ctx, schemas, copied)
+}
+
+// normalizeSchemas normalizes the copies of the given schemas in a single
+// realm, and returns them in their order, under their original names.
+func (d *DevDriver) normalizeSchemas(ctx context.Context, schemas, copied []*schema.Schema) ([]*schema.Schema, error) {
+	nr, err := d.NormalizeRealm(ctx, &schema.Realm{Schemas: copied})
+	if err != nil {
+		return nil, err
+	}
+	normalized := make([]*schema.Schema, len(schemas))
+	for _, s := range nr.Schemas {
+		i := slices.IndexFunc(copied, func(c *schema.Schema) bool { return c.Name == s.Name })
+		if i == -1 {
+			continue
+		}
+		s.Name = schemas[i].Name
+		normalized[i] = s
+	}
+	for i, s := range normalized {
+		if s == nil {
+			return nil, fmt.Errorf("schema %q was not found in normalized realm", schemas[i].Name)
+		}
+	}
+	return normalized, nil
+}
+
+// schemaTextRef reports if one of the textual definitions of the given schema (e.g.,
+// a view definition) references one of the given schemas by name, and returns it.
+func schemaTextRef(s *schema.Schema, schemas []*schema.Schema) (string, bool) {
+	var defs [][2]string
+	for _, t := range s.Tables {
+		for _, c := range t.Checks() {
+			defs = append(defs, [2]string{fmt.Sprintf("check %q of table %q", c.Name, t.Name), c.Expr})
+		}
+		for _, tr := range t.Triggers {
+			defs = append(defs, [2]string{fmt.Sprintf("trigger %q", tr.Name), tr.Body})
+		}
+	}
+	for _, v := range s.Views {
+		defs = append(defs, [2]string{fmt.Sprintf("view %q", v.Name), v.Def})
+		for _, tr := range v.Triggers {
+			defs = append(defs, [2]string{fmt.Sprintf("trigger %q", tr.Name), tr.Body})
+		}
+	}
+	for _, f := range s.Funcs {
+		defs = append(defs, [2]string{fmt.Sprintf("function %q", f.Name), f.Body})
+	}
+	for _, p := range s.Procs {
+		defs = append(defs, [2]string{fmt.Sprintf("procedure %q", p.Name), p.Body})
+	}
+	for _, d := range defs {
+		for _, rs := range schemas {
+			if qualifiedBy(d[1], rs.Name) {
+				return d[0], true
+			}
+		}
+	}
+	return "", false
+}
+
+// qualifiedBy reports if the given definition contains
+// an identifier qualified by the given schema name.
+func qualifiedBy(def, name string) bool {
+	for _, q := range []string{name + ".", `"` + name + `".`, "`" + name + "`."} {
+		for i := strings.Index(def, q); i != -1; {
+			if i == 0 || !isIdentByte(def[i-1]) {
+				return true
+			}
+			j := strings.Index(def[i+1:], q)
+			if j == -1 {
+				break
+			}
+			i += j + 1
+		}
+	}
+	return false
+}
+
+// isIdentByte reports if the given byte can be part of an unquoted identifier.
+func isIdentByte(b byte) bool {
+	return b == '_' || b == '$' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
 const (
 	keyS  = "schema"
 	keyV  = "view"
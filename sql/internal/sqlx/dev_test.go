@@ -6,6 +6,7 @@ package sqlx
 
 import (
 	"context"
+	"slices"
 	"testing"
 
 	"ariga.io/atlas/sql/migrate"
@@ -72,6 +73,109 @@ func TestDriver_NormalizeRealm(t *testing.T) {
 	p, ok = normal.Schemas[0].Tables[0].Columns[0].Pos()
 	require.True(t, ok)
 	require.Equal(t, schema.NewFilePos("schema.hcl").SetStart(hcl.Pos{Line: 3, Column: 3, Byte: 3}), p)
+
+	// Virtual foreign keys are not created in the dev database,
+	// and therefore, are attached to the normalized tables.
+	t1, t2 := r.Schemas[0].Tables[0], schema.NewTable("t2").AddColumns(schema.NewIntColumn("t1_id", "int"))
+	t2.AddForeignKeys(schema.NewForeignKey("t1_fk").AddColumns(t2.Columns[0]).SetRefTable(t1).AddRefColumns(t1.Columns[0]).AddAttrs(&schema.VirtualFK{}))
+	r.Schemas[0].AddTables(t2)
+	drv.realm.Schemas[0].AddTables(schema.NewTable("t2").AddColumns(schema.NewIntColumn("t1_id", "int")))
+	normal, err = dev.NormalizeRealm(context.Background(), r)
+	require.NoError(t, err)
+	nt1, nt2 := normal.Schemas[0].Tables[0], normal.Schemas[0].Tables[1]
+	require.Len(t, nt2.ForeignKeys, 1)
+	require.True(t, nt2.ForeignKeys[0].Virtual())
+	require.Same(t, nt2, nt2.ForeignKeys[0].Table)
+	require.Same(t, nt1, nt2.ForeignKeys[0].RefTable)
+	require.Equal(t, []*schema.Column{nt2.Columns[0]}, nt2.ForeignKeys[0].Columns)
+	require.Equal(t, []*schema.Column{nt1.Columns[0]}, nt2.ForeignKeys[0].RefColumns)
+	require.Equal(t, nt2.ForeignKeys, nt2.Columns[0].ForeignKeys)
+}
+
+func TestDriver_NormalizeSchemas(t *testing.T) {
+	var (
+		drv = &mockDriver{}
+		dev = &DevDriver{
+			Driver: drv,
+		}
+		s1 = schema.New("tenant").AddTables(schema.NewTable("t1"))
+		s2 = schema.New("tenant").AddTables(schema.NewTable("t2"))
+	)
+	normal, err := dev.NormalizeSchemas(context.Background(), s1, s2)
+	require.NoError(t, err)
+	require.Len(t, drv.schemas, 2, "single inspection")
+	require.Regexp(t, `^atlas_normalize_[0-9a-f]{8}_0$`, drv.schemas[0])
+	require.Regexp(t, `^atlas_normalize_[0-9a-f]{8}_1$`, drv.schemas[1])
+	require.Len(t, normal, 2)
+	require.Equal(t, "tenant", normal[0].Name)
+	require.Equal(t, "t1", normal[0].Tables[0].Name)
+	require.Equal(t, "tenant", normal[1].Name)
+	require.Equal(t, "t2", normal[1].Tables[0].Name)
+	require.Same(t, s1, s1.Tables[0].Schema, "input schemas are not modified")
+	require.Nil(t, s1.Realm)
+
+	// Temporary names are unique per normalization.
+	prev := drv.schemas
+	drv.schemas = nil
+	_, err = dev.NormalizeSchemas(context.Background(), s1, s2)
+	require.NoError(t, err)
+	require.NotEqual(t, prev, drv.schemas)
+
+	// Schemas that reference the given schemas by name are rejected.
+	s2.AddViews(schema.NewView("v", "SELECT * FROM tenant.t1"))
+	_, err = dev.NormalizeSchemas(context.Background(), s1, s2)
+	require.EqualError(t, err, `schema "tenant" cannot be normalized with temporary name: view "v" references schema by name`)
+	s2.Views[0].Def = "SELECT * FROM my_tenant.t1"
+	_, err = dev.NormalizeSchemas(context.Background(), s1, s2)
+	require.NoError(t, err)
+
+	// Schemas with textual references and unique names are normalized under their own names.
+	s3 := schema.New("other").AddViews(schema.NewView("v", "SELECT * FROM tenant.t1"))
+	drv.schemas = nil
+	normal, err = dev.NormalizeSchemas(context.Background(), s1, s3)
+	require.NoError(t, err)
+	require.Equal(t, []string{"tenant", "other"}, drv.schemas)
+	require.Equal(t, "tenant", normal[0].Name)
+	require.Equal(t, "other", normal[1].Name)
+
+	drv.realm = schema.NewRealm(schema.New("atlas_normalize_0"))
+	_, err = dev.NormalizeSchemas(context.Background(), s1, s2)
+	require.EqualError(t, err, `schema "tenant" was not found in normalized realm`)
+}
+
+func TestDriver_NormalizeSchemasEnum(t *testing.T) {
+	var (
+		drv    = &mockDriver{}
+		dev    = &DevDriver{Driver: drv}
+		s      = schema.New("tenant")
+		status = &schema.EnumType{T: "status", Values: []string{"active", "inactive"}, Schema: s}
+		users  = schema.NewTable("users").AddColumns(schema.NewColumn("status").SetType(status))
+	)
+	s.AddObjects(status).AddTables(users)
+	normal, err := dev.NormalizeSchemas(context.Background(), s)
+	require.NoError(t, err)
+	require.Len(t, normal, 1)
+	require.Equal(t, "tenant", normal[0].Name)
+
+	// The enum is created in the renamed schema, and used by the created column.
+	var add *schema.AddObject
+	for _, c := range drv.changes {
+		if a, ok := c.(*schema.AddObject); ok {
+			add = a
+		}
+	}
+	require.NotNil(t, add)
+	e, ok := add.O.(*schema.EnumType)
+	require.True(t, ok)
+	require.NotSame(t, status, e)
+	require.Regexp(t, `^atlas_normalize_[0-9a-f]{8}_0$`, e.Schema.Name)
+	tc := e.Schema.Tables[0]
+	require.Same(t, e, tc.Columns[0].Type.Type)
+
+	// The given schema and its enum are not modified.
+	require.Same(t, s, status.Schema)
+	require.Equal(t, "tenant", s.Name)
+	require.Same(t, status, users.Columns[0].Type.Type)
 }
 
 type mockDriver struct {
@@ -85,7 +189,17 @@ type mockDriver struct {
 
 func (m *mockDriver) InspectRealm(_ context.Context, opts *schema.InspectRealmOption) (*schema.Realm, error) {
 	m.schemas = append(m.schemas, opts.Schemas...)
-	return m.realm, nil
+	if m.realm != nil {
+		return m.realm, nil
+	}
+	// Return the created schemas, if no realm was set.
+	r := schema.NewRealm()
+	for _, c := range m.changes {
+		if a, ok := c.(*schema.AddSchema); ok && slices.Contains(opts.Schemas, a.S.Name) {
+			r.AddSchemas(schema.CopySchemas(a.S)...)
+		}
+	}
+	return r, nil
 }
 
 func (m *mockDriver) ApplyChanges(_ context.Context, changes []schema.Change, _ ...migrate.PlanOption) error {
@@ -119,6 +119,13 @@ type (
 		TriggerDiff(from, to *schema.Trigger) ([]schema.Change, error)
 	}
 
+	// ExprComparer is an optional interface allows DiffDriver to compare SQL
+	// expressions using dialect-specific normalization. For example, index
+	// expressions that were rewritten by the database.
+	ExprComparer interface {
+		ExprEqual(x1, x2 string) bool
+	}
+
 	// ChangeSupporter wraps the single SupportChange method.
 	ChangeSupporter interface {
 		// SupportChange can be implemented to tell the Differ if they support
@@ -321,7 +328,7 @@ func (d *Diff) tableDiff(from, to *schema.Table, opts *schema.DiffOptions) ([]sc
 	if err != nil {
 		return nil, err
 	}
-	changes = append(changes, change...)
+	changes = append(changes, suppressAttrChanges(change, opts)...)
 
 	// Drop, add or modify columns.
 	if change, err = d.columnDiff(from, to, opts); err != nil {
@@ -336,10 +343,14 @@ func (d *Diff) tableDiff(from, to *schema.Table, opts *schema.DiffOptions) ([]sc
 	}
 	changes = append(changes, change...)
 
-	// Drop or modify foreign-keys.
+	// Drop or modify foreign-keys. Virtual foreign keys are
+	// not enforced by the database, and therefore, are skipped.
 	for _, fk1 := range from.ForeignKeys {
+		if fk1.Virtual() {
+			continue
+		}
 		fk2, ok := to.ForeignKey(fk1.Symbol)
-		if !ok {
+		if !ok || fk2.Virtual() {
 			changes = opts.AddOrSkip(changes, &schema.DropForeignKey{F: fk1})
 			continue
 		}
@@ -353,7 +364,10 @@ func (d *Diff) tableDiff(from, to *schema.Table, opts *schema.DiffOptions) ([]sc
 	}
 	// Add foreign-keys.
 	for _, fk1 := range to.ForeignKeys {
-		if _, ok := from.ForeignKey(fk1.Symbol); !ok {
+		if fk1.Virtual() {
+			continue
+		}
+		if fk2, ok := from.ForeignKey(fk1.Symbol); !ok || fk2.Virtual() {
 			changes = opts.AddOrSkip(changes, &schema.AddForeignKey{F: fk1})
 		}
 	}
@@ -373,7 +387,11 @@ func (d *Diff) mayAnnotate(changes []schema.Change, opts *schema.DiffOptions) (_
 // addTableChange returns the changeset for creating the table.
 func addTableChange(t *schema.Table) []schema.Change {
 	changes := make([]schema.Change, 0, 1+len(t.Triggers))
-	changes = append(changes, &schema.AddTable{T: t})
+	if c := (schema.Clone{}); Has(t.Attrs, &c) && c.Source != nil {
+		changes = append(changes, &schema.CloneTable{T: t, Source: c.Source})
+	} else {
+		changes = append(changes, &schema.AddTable{T: t})
+	}
 	for _, r := range t.Triggers {
 		changes = append(changes, &schema.AddTrigger{T: r})
 	}
@@ -404,6 +422,11 @@ func (d *Diff) columnDiff(from, to *schema.Table, opts *schema.DiffOptions) ([]s
 		if err != nil {
 			return nil, err
 		}
+		if m, ok := change.(*schema.ModifyColumn); ok {
+			if m.Change = suppressColumnChange(m, opts); m.Change == schema.NoChange {
+				change = NoChange
+			}
+		}
 		if change != NoChange {
 			all = append(all, change)
 		}
@@ -423,12 +446,185 @@ func (d *Diff) columnDiff(from, to *schema.Table, opts *schema.DiffOptions) ([]s
 	if all, err = d.askForColumns(from, all, opts); err != nil {
 		return nil, err
 	}
+	if opts.ColumnOrder {
+		all = d.columnOrder(from, to, all, opts)
+	}
 	for _, c := range all {
 		changes = opts.AddOrSkip(changes, c)
 	}
 	return changes, nil
 }
 
+// suppressColumnChange returns the change kind of the column modification
+// without the changes that were reported as equal by the custom comparators.
+func suppressColumnChange(m *schema.ModifyColumn, opts *schema.DiffOptions) schema.ChangeKind {
+	k := m.Change
+	for _, c := range []struct {
+		kind     schema.ChangeKind
+		from, to any
+	}{
+		{schema.ChangeComment, attrOf[*schema.Comment](m.From.Attrs), attrOf[*schema.Comment](m.To.Attrs)},
+		{schema.ChangeCharset, attrOf[*schema.Charset](m.From.Attrs), attrOf[*schema.Charset](m.To.Attrs)},
+		{schema.ChangeCollate, attrOf[*schema.Collation](m.From.Attrs), attrOf[*schema.Collation](m.To.Attrs)},
+		{schema.ChangeGenerated, attrOf[*schema.GeneratedExpr](m.From.Attrs), attrOf[*schema.GeneratedExpr](m.To.Attrs)},
+		{schema.ChangeDefault, m.From.Default, m.To.Default},
+	} {
+		if k.Is(c.kind) && opts.AttrEqual(c.from, c.to) {
+			k &^= c.kind
+		}
+	}
+	return k
+}
+
+// suppressAttrChanges filters out the attribute changes that
+// were reported as equal by the custom comparators.
+func suppressAttrChanges(changes []schema.Change, opts *schema.DiffOptions) []schema.Change {
+	filtered := changes[:0]
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddAttr:
+			if opts.AttrEqual(nil, c.A) {
+				continue
+			}
+		case *schema.DropAttr:
+			if opts.AttrEqual(c.A, nil) {
+				continue
+			}
+		case *schema.ModifyAttr:
+			if opts.AttrEqual(c.From, c.To) {
+				continue
+			}
+		}
+		filtered = append(filtered, c)
+	}
+	return filtered
+}
+
+// attrOf returns the first attribute of type T, or its zero value if it does not exist.
+func attrOf[T schema.Attr](attrs []schema.Attr) T {
+	for _, a := range attrs {
+		if t, ok := a.(T); ok {
+			return t
+		}
+	}
+	var zero T
+	return zero
+}
+
+// columnOrder appends or updates the column changes with the positions needed to
+// migrate the column order of a table to the desired state. The columns that are
+// kept in place are the longest common subsequence of the two orders, and others
+// are moved after their preceding columns in the desired state.
+func (d *Diff) columnOrder(from, to *schema.Table, changes []schema.Change, opts *schema.DiffOptions) []schema.Change {
+	var fromC, toC []string
+	for _, c := range from.Columns {
+		if _, ok := to.Column(c.Name); ok {
+			fromC = append(fromC, c.Name)
+		}
+	}
+	for _, c := range to.Columns {
+		if _, ok := from.Column(c.Name); ok {
+			toC = append(toC, c.Name)
+		}
+	}
+	var (
+		kept   = lcs(fromC, toC)
+		moved  = make(map[string]bool)
+		placed = make(map[string]bool)
+	)
+	for _, c := range toC {
+		if !kept[c] {
+			moved[c] = true
+		}
+	}
+	// Added columns are positioned only if they are not appended
+	// to the end of the table, which is the default behavior.
+	for i, c := range to.Columns {
+		if _, ok := from.Column(c.Name); !ok && slices.ContainsFunc(to.Columns[i+1:], func(c *schema.Column) bool {
+			_, ok := from.Column(c.Name)
+			return ok
+		}) {
+			placed[c.Name] = true
+		}
+	}
+	if len(moved) == 0 && len(placed) == 0 {
+		return changes
+	}
+	if s, ok := d.DiffDriver.(ChangeSupporter); ok && !s.SupportChange(&schema.ModifyColumn{Change: schema.ChangePosition}) {
+		if opts.ColumnOrderReport != nil {
+			opts.ColumnOrderReport(from, to)
+		}
+		return changes
+	}
+	// Positioned changes are ordered by the desired column order, as
+	// a position might refer to a column that was added or moved.
+	var positioned []schema.Change
+	for i, c2 := range to.Columns {
+		if !moved[c2.Name] && !placed[c2.Name] {
+			continue
+		}
+		pos := &schema.ColumnPosition{}
+		if i > 0 {
+			pos.After = to.Columns[i-1]
+		}
+		idx := slices.IndexFunc(changes, func(c schema.Change) bool {
+			switch c := c.(type) {
+			case *schema.AddColumn:
+				return c.C == c2
+			case *schema.ModifyColumn:
+				return c.To == c2
+			}
+			return false
+		})
+		if idx == -1 {
+			c1, _ := from.Column(c2.Name)
+			positioned = append(positioned, &schema.ModifyColumn{From: c1, To: c2, Change: schema.ChangePosition, Extra: []schema.Clause{pos}})
+			continue
+		}
+		switch c := changes[idx].(type) {
+		case *schema.AddColumn:
+			c.Extra = append(c.Extra, pos)
+		case *schema.ModifyColumn:
+			c.Change |= schema.ChangePosition
+			c.Extra = append(c.Extra, pos)
+		}
+		positioned = append(positioned, changes[idx])
+		changes = slices.Delete(changes, idx, idx+1)
+	}
+	return append(changes, positioned...)
+}
+
+// lcs returns the set of elements that are part of the
+// longest common subsequence of the two given slices.
+func lcs(a, b []string) map[string]bool {
+	dp := make([][]int, len(a)+1)
+	for i := range dp {
+		dp[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				dp[i][j] = dp[i+1][j+1] + 1
+			} else {
+				dp[i][j] = max(dp[i+1][j], dp[i][j+1])
+			}
+		}
+	}
+	common := make(map[string]bool)
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			common[a[i]] = true
+			i, j = i+1, j+1
+		case dp[i+1][j] >= dp[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return common
+}
+
 // pkDiff returns the schema changes (if any) for migrating table
 // primary-key from current state to the desired state.
 func (d *Diff) pkDiff(from, to *schema.Table, opts *schema.DiffOptions) (changes []schema.Change) {
@@ -625,7 +821,7 @@ func (d *Diff) partsChange(fromI, toI *schema.Index, renames map[string]string)
 			}
 		case from[i].X != nil && to[i].X != nil:
 			x1, x2 := from[i].X.(*schema.RawExpr).X, to[i].X.(*schema.RawExpr).X
-			if x1 != x2 && x1 != MayWrap(x2) {
+			if x1 != x2 && x1 != MayWrap(x2) && !d.exprEqual(x1, x2) {
 				return schema.ChangeParts
 			}
 		default: // (C1 != nil) != (C2 != nil) || (X1 != nil) != (X2 != nil).
@@ -820,15 +1016,16 @@ func CommentDiff(from, to []schema.Attr) schema.Change {
 
 // CheckDiffMode is like CheckDiff, but compares also expressions
 // if the schema.DiffMode is equal to schema.DiffModeNormalized.
-func CheckDiffMode(from, to *schema.Table, mode schema.DiffMode, compare ...func(c1, c2 *schema.Check) bool) []schema.Change {
+// Expressions are compared using the given normalizer, if not nil.
+func CheckDiffMode(from, to *schema.Table, mode schema.DiffMode, n *ExprNormalizer, compare ...func(c1, c2 *schema.Check) bool) []schema.Change {
 	if !mode.Is(schema.DiffModeNormalized) {
-		return checksSimilarDiff(from, to, compare...)
+		return checksSimilarDiff(from, to, n, compare...)
 	}
 	return ChecksDiff(from, to, func(c1, c2 *schema.Check) bool {
 		if len(compare) == 1 && !compare[0](c1, c2) {
 			return false
 		}
-		return c1.Expr == c2.Expr || MayWrap(c1.Expr) == MayWrap(c2.Expr)
+		return n.Equal(c1.Expr, c2.Expr)
 	})
 }
 
@@ -874,11 +1071,11 @@ func ChecksDiff(from, to *schema.Table, compare func(c1, c2 *schema.Check) bool)
 // Unlike ChecksDiff, it does not compare the constraint name, but
 // determines if there is any similar constraint by its expression.
 // This is an old implementation that is not used anymore by the CLI.
-func checksSimilarDiff(from, to *schema.Table, compare ...func(c1, c2 *schema.Check) bool) []schema.Change {
+func checksSimilarDiff(from, to *schema.Table, n *ExprNormalizer, compare ...func(c1, c2 *schema.Check) bool) []schema.Change {
 	var changes []schema.Change
 	// Drop or modify checks.
 	for _, c1 := range checks(from.Attrs) {
-		switch c2, ok := similarCheck(to.Attrs, c1); {
+		switch c2, ok := similarCheck(to.Attrs, c1, n); {
 		case !ok:
 			changes = append(changes, &schema.DropCheck{
 				C: c1,
@@ -892,7 +1089,7 @@ func checksSimilarDiff(from, to *schema.Table, compare ...func(c1, c2 *schema.Ch
 	}
 	// Add checks.
 	for _, c1 := range checks(to.Attrs) {
-		if _, ok := similarCheck(from.Attrs, c1); !ok {
+		if _, ok := similarCheck(from.Attrs, c1, n); !ok {
 			changes = append(changes, &schema.AddCheck{
 				C: c1,
 			})
@@ -912,7 +1109,7 @@ func checks(attr []schema.Attr) (checks []*schema.Check) {
 }
 
 // similarCheck returns a CHECK by its constraints name or expression.
-func similarCheck(attrs []schema.Attr, c *schema.Check) (*schema.Check, bool) {
+func similarCheck(attrs []schema.Attr, c *schema.Check, n *ExprNormalizer) (*schema.Check, bool) {
 	var byName, byExpr *schema.Check
 	for i := 0; i < len(attrs) && (byName == nil || byExpr == nil); i++ {
 		check, ok := attrs[i].(*schema.Check)
@@ -922,7 +1119,7 @@ func similarCheck(attrs []schema.Attr, c *schema.Check) (*schema.Check, bool) {
 		if check.Name != "" && check.Name == c.Name {
 			byName = check
 		}
-		if check.Expr == c.Expr || MayWrap(check.Expr) == MayWrap(c.Expr) {
+		if n.Equal(check.Expr, c.Expr) {
 			byExpr = check
 		}
 	}
@@ -936,6 +1133,13 @@ func similarCheck(attrs []schema.Attr, c *schema.Check) (*schema.Check, bool) {
 	return nil, false
 }
 
+// exprEqual reports if the two expressions are equal using the ExprComparer of
+// the DiffDriver, if it is implemented.
+func (d *Diff) exprEqual(x1, x2 string) bool {
+	c, ok := d.DiffDriver.(ExprComparer)
+	return ok && c.ExprEqual(x1, x2)
+}
+
 // Unquote single or double quotes.
 func Unquote(s string) (string, error) {
 	switch {
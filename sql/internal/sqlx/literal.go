@@ -0,0 +1,140 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlx
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LiteralFormat describes how Go values are formatted
+// as SQL literals in a specific dialect.
+type LiteralFormat struct {
+	// String quotes and escapes the given string.
+	String func(string) string
+	// Bytes formats the given bytes as a binary string literal.
+	Bytes func([]byte) string
+	// Bool formats the given boolean value.
+	Bool func(bool) string
+	// TimeLayout is the layout used to format time values.
+	// The formatted value is quoted as a string literal.
+	TimeLayout string
+	// Array formats the given (already formatted) elements as an
+	// array literal. A nil Array indicates arrays are not supported.
+	Array func([]string) string
+	// JSON formats the given JSON document. If nil,
+	// the document is formatted as a string literal.
+	JSON func(string) string
+}
+
+// SingleQuoteEscape quotes the given string with single quotes
+// and escapes the single quotes in it by doubling them.
+func SingleQuoteEscape(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// HexBytes formats the given bytes as a hexadecimal literal (X'...').
+func HexBytes(b []byte) string {
+	return "X'" + strings.ToUpper(hex.EncodeToString(b)) + "'"
+}
+
+// Format formats the given value as an SQL literal. Supported values are nil,
+// strings, bytes, booleans, numbers, time.Time, JSON documents (json.RawMessage,
+// maps and structs), slices and arrays of supported values (if the dialect
+// supports them), pointers to supported values and driver.Valuer implementations.
+func (f *LiteralFormat) Format(v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "NULL", nil
+	case driver.Valuer:
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return "NULL", nil
+		}
+		x, err := v.Value()
+		if err != nil {
+			return "", err
+		}
+		return f.Format(x)
+	case string:
+		return f.String(v), nil
+	case []byte:
+		return f.Bytes(v), nil
+	case json.RawMessage:
+		return f.json(string(v)), nil
+	case bool:
+		return f.Bool(v), nil
+	case time.Time:
+		return f.String(v.Format(f.TimeLayout)), nil
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return "NULL", nil
+		}
+		return f.Format(rv.Elem().Interface())
+	case reflect.String:
+		return f.String(rv.String()), nil
+	case reflect.Bool:
+		return f.Bool(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		x := rv.Float()
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return "", fmt.Errorf("sql: unsupported float value %v", x)
+		}
+		return strconv.FormatFloat(x, 'g', -1, rv.Type().Bits()), nil
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return "NULL", nil
+		}
+		// Named byte slices (e.g., net.IP) are formatted as binary strings.
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			return f.Bytes(b), nil
+		}
+		if f.Array == nil {
+			return "", fmt.Errorf("sql: array values (%T) are not supported by the dialect", v)
+		}
+		elems := make([]string, rv.Len())
+		for i := range elems {
+			e, err := f.Format(rv.Index(i).Interface())
+			if err != nil {
+				return "", err
+			}
+			elems[i] = e
+		}
+		return f.Array(elems), nil
+	case reflect.Map, reflect.Struct:
+		if rv.Kind() == reflect.Map && rv.IsNil() {
+			return "NULL", nil
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return f.json(string(b)), nil
+	default:
+		return "", fmt.Errorf("sql: unsupported literal value %T", v)
+	}
+}
+
+func (f *LiteralFormat) json(s string) string {
+	if f.JSON != nil {
+		return f.JSON(s)
+	}
+	return f.String(s)
+}
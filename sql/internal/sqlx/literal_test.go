@@ -0,0 +1,73 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlx
+
+import (
+	"database/sql"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLiteralFormat_Format(t *testing.T) {
+	type status string
+	var (
+		f = &LiteralFormat{
+			String:     SingleQuoteEscape,
+			Bytes:      HexBytes,
+			Bool:       strconv.FormatBool,
+			TimeLayout: time.RFC3339,
+			Array: func(elems []string) string {
+				return "ARRAY[" + strings.Join(elems, ", ") + "]"
+			},
+		}
+		s  = "it's"
+		np *int
+	)
+	for _, tt := range []struct {
+		v      any
+		expect string
+	}{
+		{v: nil, expect: "NULL"},
+		{v: np, expect: "NULL"},
+		{v: &s, expect: "'it''s'"},
+		{v: "it's", expect: "'it''s'"},
+		{v: status("active"), expect: "'active'"},
+		{v: []byte("\x00a'"), expect: "X'006127'"},
+		{v: true, expect: "true"},
+		{v: int8(-1), expect: "-1"},
+		{v: uint64(math.MaxUint64), expect: "18446744073709551615"},
+		{v: 1.5, expect: "1.5"},
+		{v: float32(0.1), expect: "0.1"},
+		{v: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), expect: "'2024-01-02T03:04:05Z'"},
+		{v: json.RawMessage(`{"a": "b'c"}`), expect: `'{"a": "b''c"}'`},
+		{v: map[string]int{"a": 1}, expect: `'{"a":1}'`},
+		{v: map[string]int(nil), expect: "NULL"},
+		{v: struct{ A int }{A: 1}, expect: `'{"A":1}'`},
+		{v: []string{"a", "b"}, expect: "ARRAY['a', 'b']"},
+		{v: [2]int{1, 2}, expect: "ARRAY[1, 2]"},
+		{v: []string(nil), expect: "NULL"},
+		{v: sql.NullString{String: "a", Valid: true}, expect: "'a'"},
+		{v: sql.NullInt64{}, expect: "NULL"},
+		{v: (*sql.NullString)(nil), expect: "NULL"},
+	} {
+		got, err := f.Format(tt.v)
+		require.NoError(t, err)
+		require.Equal(t, tt.expect, got, "value: %#v", tt.v)
+	}
+
+	_, err := f.Format(math.NaN())
+	require.EqualError(t, err, "sql: unsupported float value NaN")
+	_, err = f.Format(func() {})
+	require.EqualError(t, err, "sql: unsupported literal value func()")
+	f.Array = nil
+	_, err = f.Format([]int{1})
+	require.EqualError(t, err, "sql: array values ([]int) are not supported by the dialect")
+}
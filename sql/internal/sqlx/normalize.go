@@ -0,0 +1,304 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlx
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// ExprNormalizer normalizes SQL expressions before they are compared, to avoid reporting
+// changes between expressions that differ only in the way they are formatted. For example,
+// CHECK constraints that are returned by the database in a different form than they were
+// defined by the user:
+//
+//	a > 0 AND b IS NOT NULL  =>  ((a > 0) AND (b IS NOT NULL))
+//	status = 'active'        =>  ((status)::text = 'active'::text)
+//
+// Normalization ignores whitespace and case of keywords, unquotes identifiers that do not
+// require quoting, removes redundant parentheses and drops casts that are configured by the
+// dialect as verbose. Note, string literals are never changed.
+type ExprNormalizer struct {
+	// IdentQuotes holds the characters used for quoting identifiers. e.g., '"' or '`'.
+	IdentQuotes string
+	// CaseSensitive indicates quoted identifiers are case-sensitive, and only
+	// identifiers that are written in lowercase can be unquoted (e.g., PostgreSQL).
+	CaseSensitive bool
+	// TrimCast reports if a cast to the given type (e.g., "::text") can be dropped
+	// from the expression. A nil function means casts are kept as-is.
+	TrimCast func(typ string) bool
+	// TrimIntroducers indicates that character set introducers (e.g., _utf8mb4'a')
+	// can be dropped from string literals (e.g., MySQL).
+	TrimIntroducers bool
+	// Rewrite rewrites the tokens of the expression to the form that is stored by the
+	// database. e.g., operators that are stored as function calls. Tokens are lowercased,
+	// and quoted identifiers are unquoted if they do not require quoting.
+	Rewrite func(tokens []string) []string
+}
+
+// Equal reports if the two expressions are equal after normalization.
+// A nil normalizer compares the expressions with their wrapping parentheses.
+func (n *ExprNormalizer) Equal(x1, x2 string) bool {
+	switch {
+	case x1 == x2 || MayWrap(x1) == MayWrap(x2):
+		return true
+	case n == nil:
+		return false
+	default:
+		return n.Normalize(x1) == n.Normalize(x2)
+	}
+}
+
+// Normalize returns the normalized form of the expression. The returned string is
+// used for comparison only, and should not be used as a valid SQL expression.
+func (n *ExprNormalizer) Normalize(x string) string {
+	tokens := n.tokens(x)
+	if n.Rewrite != nil {
+		tokens = n.Rewrite(tokens)
+	}
+	tokens = n.trimCasts(tokens)
+	tokens = trimParens(tokens)
+	return strings.Join(tokens, " ")
+}
+
+// operators holds the multi-character operators, sorted by their length.
+var operators = []string{
+	"!~~", "!~*", "<=>", "->>",
+	"::", "<=", ">=", "<>", "!=", "||", "->", "==", "<<", ">>", "@>", "<@", "&&", "!~", "~*", "~~",
+}
+
+// tokens splits the expression into normalized tokens.
+func (n *ExprNormalizer) tokens(x string) []string {
+	var tokens []string
+	for i := 0; i < len(x); {
+		switch r := rune(x[i]); {
+		case unicode.IsSpace(r):
+			i++
+		case r == '\'':
+			j := quotedEnd(x, i)
+			// Drop the character set introducer of the literal.
+			if k := len(tokens) - 1; n.TrimIntroducers && k >= 0 && len(tokens[k]) > 1 && tokens[k][0] == '_' {
+				tokens = tokens[:k]
+			}
+			tokens = append(tokens, x[i:j])
+			i = j
+		case strings.ContainsRune(n.IdentQuotes, r):
+			j := quotedEnd(x, i)
+			tokens = append(tokens, n.ident(x[i:j]))
+			i = j
+		case r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			j := i + 1
+			for j < len(x) && (x[j] == '_' || x[j] == '$' || x[j] == '.' && unicode.IsDigit(r) || unicode.IsLetter(rune(x[j])) || unicode.IsDigit(rune(x[j]))) {
+				j++
+			}
+			tokens = append(tokens, strings.ToLower(x[i:j]))
+			i = j
+		default:
+			op := x[i : i+1]
+			for _, o := range operators {
+				if strings.HasPrefix(x[i:], o) {
+					op = o
+					break
+				}
+			}
+			tokens = append(tokens, op)
+			i += len(op)
+		}
+	}
+	return tokens
+}
+
+// quotedEnd returns the end position of the quoted string starting at position i.
+func quotedEnd(x string, i int) int {
+	q := x[i]
+	for j := i + 1; j < len(x); j++ {
+		switch {
+		case x[j] == '\\' && q == '\'':
+			j++
+		case x[j] == q && j+1 < len(x) && x[j+1] == q:
+			j++
+		case x[j] == q:
+			return j + 1
+		}
+	}
+	return len(x)
+}
+
+// ident returns the unquoted form of the identifier, if it does not require quoting.
+func (n *ExprNormalizer) ident(s string) string {
+	if len(s) < 3 || s[0] != s[len(s)-1] {
+		return s
+	}
+	name := s[1 : len(s)-1]
+	for i, r := range name {
+		if r != '_' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return s
+		}
+	}
+	if n.CaseSensitive && name != strings.ToLower(name) {
+		return s
+	}
+	return strings.ToLower(name)
+}
+
+// trimCasts drops the casts that are allowed by the TrimCast function.
+func (n *ExprNormalizer) trimCasts(tokens []string) []string {
+	if n.TrimCast == nil {
+		return tokens
+	}
+	for i := 0; i < len(tokens); i++ {
+		if tokens[i] != "::" {
+			continue
+		}
+		// Types may be written in multiple words. e.g., "character varying".
+		end := -1
+		for j := i + 1; j < len(tokens) && j <= i+4 && isWord(tokens[j]); j++ {
+			if n.TrimCast(strings.Join(tokens[i+1:j+1], " ")) {
+				end = j + 1
+			}
+		}
+		if end == -1 {
+			continue
+		}
+		// Type modifiers. e.g., "::character varying(255)".
+		if end < len(tokens) && tokens[end] == "(" {
+			if j := closing(tokens, end); j != -1 {
+				end = j + 1
+			}
+		}
+		tokens = slices.Delete(tokens, i, end)
+		i--
+	}
+	return tokens
+}
+
+// trimParens removes redundant parentheses from the expression.
+func trimParens(tokens []string) []string {
+	for i := 0; i < len(tokens); i++ {
+		if tokens[i] != "(" {
+			continue
+		}
+		j := closing(tokens, i)
+		if j == -1 {
+			return tokens
+		}
+		if redundantParens(tokens, i, j) {
+			tokens = slices.Delete(tokens, j, j+1)
+			tokens = slices.Delete(tokens, i, i+1)
+			// Start over, as outer parentheses may became redundant.
+			i = -1
+		}
+	}
+	return tokens
+}
+
+// redundantParens reports if the parentheses at positions i and j can be removed.
+func redundantParens(tokens []string, i, j int) bool {
+	var prev, next string
+	if i > 0 {
+		prev = tokens[i-1]
+	}
+	if j < len(tokens)-1 {
+		next = tokens[j+1]
+	}
+	switch {
+	// Function calls or lists. e.g., "lower(a)" or "IN (1, 2)".
+	case isWord(prev) && !slices.Contains([]string{"and", "or", "not"}, prev):
+		return false
+	// Wrapped expression, or a single operand. e.g., "(a > 0)" or "(a)".
+	case i == 0 && j == len(tokens)-1, j == i+2:
+		return true
+	// Operands of logical operators. e.g., "(a > 0) AND (b > 0)".
+	case slices.Contains([]string{"", "(", ",", "and", "or", "not"}, prev) && slices.Contains([]string{"", ")", ",", "and", "or"}, next):
+		for k := i + 1; k < j; k++ {
+			switch tokens[k] {
+			case "and", "or", ",":
+				return false
+			case "(":
+				k = closing(tokens, k)
+			}
+		}
+		return true
+	default:
+		return arithmeticParens(tokens, i, j, prev, next)
+	}
+}
+
+// precedence holds the binding power of the arithmetic operators. Comparison operators
+// and expression boundaries bind looser than all of them, and are represented by 0.
+var precedence = map[string]int{
+	"": 0, "(": 0, ")": 0, ",": 0, "=": 0, "<>": 0, "!=": 0, "<": 0, ">": 0, "<=": 0, ">=": 0,
+	"+": 1, "-": 1,
+	"*": 2, "/": 2, "%": 2,
+}
+
+// arithmeticParens reports if the parentheses at positions i and j wrap an arithmetic
+// operation that binds tighter than its surrounding operators. e.g., "a + (b * 2)". Since
+// operators are left-associative, the operation may bind equally to the operator after it.
+func arithmeticParens(tokens []string, i, j int, prev, next string) bool {
+	p1, ok1 := precedence[prev]
+	p2, ok2 := precedence[next]
+	if !ok1 || !ok2 {
+		return false
+	}
+	inner, operand := -1, false
+	for k := i + 1; k < j; k++ {
+		switch t := tokens[k]; {
+		case t == "(":
+			// Only function calls may follow an operand. e.g., "lower(a)".
+			if operand && !isWord(tokens[k-1]) {
+				return false
+			}
+			k, operand = closing(tokens, k), true
+		case isWord(t) || isOperand(t):
+			// Consecutive operands are joined by keywords. e.g., "a IS NULL".
+			if operand {
+				return false
+			}
+			operand = true
+		default:
+			p, ok := precedence[t]
+			if !ok || p == 0 {
+				return false
+			}
+			if inner == -1 || p < inner {
+				inner = p
+			}
+			operand = false
+		}
+	}
+	// A single operand. e.g., "(lower(a))".
+	if inner == -1 {
+		return true
+	}
+	return inner > p1 && inner >= p2
+}
+
+// isOperand reports if the token is a literal or a quoted identifier.
+func isOperand(t string) bool {
+	return t != "" && (unicode.IsDigit(rune(t[0])) || t[0] == '\'' || t[0] == '"' || t[0] == '`')
+}
+
+// closing returns the position of the parenthesis that closes the one at position i.
+func closing(tokens []string, i int) int {
+	depth := 0
+	for j := i; j < len(tokens); j++ {
+		switch tokens[j] {
+		case "(":
+			depth++
+		case ")":
+			if depth--; depth == 0 {
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+// isWord reports if the token is a keyword or an unquoted identifier.
+func isWord(t string) bool {
+	return t != "" && (t[0] == '_' || unicode.IsLetter(rune(t[0])))
+}
@@ -0,0 +1,74 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExprNormalizer_Equal(t *testing.T) {
+	var (
+		pg = &ExprNormalizer{
+			IdentQuotes:   `"`,
+			CaseSensitive: true,
+			TrimCast: func(t string) bool {
+				return t == "text" || t == "character varying"
+			},
+		}
+		my = &ExprNormalizer{IdentQuotes: "`", TrimIntroducers: true}
+	)
+	for _, tt := range []struct {
+		n      *ExprNormalizer
+		x1, x2 string
+		equal  bool
+	}{
+		{n: nil, x1: "a > 0", x2: "(a > 0)", equal: true},
+		{n: nil, x1: "a > 0", x2: "a>0"},
+		{n: pg, x1: "a > 0", x2: "a>0", equal: true},
+		{n: pg, x1: "a > 0 AND b IS NOT NULL", x2: "((a > 0) AND (b IS NOT NULL))", equal: true},
+		{n: pg, x1: "(a > 0 OR b > 0) AND c > 0", x2: "a > 0 OR b > 0 AND c > 0"},
+		{n: pg, x1: "status = 'active'", x2: "((status)::text = 'active'::text)", equal: true},
+		{n: pg, x1: "status = 'active'", x2: `("status")::character varying(10) = 'active'`, equal: true},
+		{n: pg, x1: "status = 'active'", x2: "status = 'Active'"},
+		{n: pg, x1: "a = 1", x2: "a::int = 1"},
+		{n: pg, x1: `"A" > 0`, x2: "a > 0"},
+		{n: pg, x1: `"a" > 0`, x2: "A > 0", equal: true},
+		{n: pg, x1: "lower(name) <> ''", x2: "(lower((name)::text) <> ''::text)", equal: true},
+		{n: pg, x1: "a IN (1, 2)", x2: "(a IN (1,2))", equal: true},
+		{n: pg, x1: "(a, b) = (1, 2)", x2: "a, b = 1, 2"},
+		{n: pg, x1: "NOT (a > 0)", x2: "(NOT (a > 0))", equal: true},
+		{n: pg, x1: "a + b * 2", x2: "(a + (b * 2))", equal: true},
+		{n: pg, x1: "(a - b) + c", x2: "a - b + c", equal: true},
+		{n: pg, x1: "(a + b) * 2", x2: "a + b * 2"},
+		{n: pg, x1: "a - (b - c)", x2: "a - b - c"},
+		{n: pg, x1: "(a + b)::int", x2: "a + b::int"},
+		{n: pg, x1: "price * quantity", x2: "(price * (quantity)::text)", equal: true},
+		{n: pg, x1: "(a IS NULL) = b", x2: "a IS NULL = b"},
+		{n: my, x1: "`a` > 0", x2: "(a > 0)", equal: true},
+		{n: my, x1: "name <> 'x'", x2: "(`name` <> _utf8mb4'x')", equal: true},
+		{n: my, x1: "json_valid(`doc`)", x2: "JSON_VALID(doc)", equal: true},
+		{n: my, x1: "a = 'it''s'", x2: "a = 'it''s '"},
+	} {
+		require.Equal(t, tt.equal, tt.n.Equal(tt.x1, tt.x2), "%q = %q", tt.x1, tt.x2)
+	}
+}
+
+func TestExprNormalizer_Normalize(t *testing.T) {
+	n := &ExprNormalizer{IdentQuotes: `"`}
+	require.Equal(t, "a > 0 and b <= 'X  y'", n.Normalize(`(("a">0) AND (B<='X  y'))`))
+	require.Equal(t, "f ( a ) :: int", n.Normalize("F((a))::INT"))
+
+	n.Rewrite = func(tokens []string) []string {
+		for i, t := range tokens {
+			if t == "ifnull" {
+				tokens[i] = "coalesce"
+			}
+		}
+		return tokens
+	}
+	require.Equal(t, "coalesce ( a , 0 ) > 0", n.Normalize(`(IFNULL("a", 0) > 0)`))
+}
@@ -0,0 +1,108 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlx
+
+import (
+	"context"
+	"errors"
+
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlerr"
+)
+
+// PartialInspect wraps the inspector of a driver with support for the schema.InspectErrorPartial
+// policy. Inspections are first executed as-is, and only if they fail with a permission error,
+// objects are re-inspected one by one to skip the inaccessible ones.
+type PartialInspect struct {
+	schema.Inspector
+}
+
+// InspectSchema implements the schema.Inspector interface.
+func (i *PartialInspect) InspectSchema(ctx context.Context, name string, opts *schema.InspectOptions) (*schema.Schema, error) {
+	s, err := i.Inspector.InspectSchema(ctx, name, opts)
+	if err == nil || opts == nil || opts.OnPermissionError != schema.InspectErrorPartial || len(opts.Tables) == 0 || !errors.Is(err, sqlerr.PermissionDenied) {
+		return s, err
+	}
+	w := &schema.InspectWarnings{}
+	s = nil
+	for _, t := range opts.Tables {
+		o := *opts
+		o.Tables = []string{t}
+		ts, err := i.Inspector.InspectSchema(ctx, name, &o)
+		switch {
+		case errors.Is(err, sqlerr.PermissionDenied):
+			if name != "" {
+				t = name + "." + t
+			}
+			w.Skipped = append(w.Skipped, &schema.SkippedObject{Type: "table", Name: t, Err: err})
+		case err != nil:
+			return nil, err
+		case s == nil:
+			s = ts
+		default:
+			for _, t := range ts.Tables {
+				if _, ok := s.Table(t.Name); !ok {
+					s.AddTables(t)
+				}
+			}
+			for _, v := range ts.Views {
+				if _, ok := s.View(v.Name); !ok {
+					s.AddViews(v)
+				}
+			}
+		}
+	}
+	// All tables were skipped.
+	if s == nil {
+		o := *opts
+		o.Mode, o.Tables = schema.InspectSchemas, nil
+		if s, err = i.Inspector.InspectSchema(ctx, name, &o); err != nil {
+			return nil, err
+		}
+	}
+	LinkSchemaTables([]*schema.Schema{s})
+	schema.ReplaceOrAppend(&s.Attrs, w)
+	return s, nil
+}
+
+// InspectRealm implements the schema.Inspector interface.
+func (i *PartialInspect) InspectRealm(ctx context.Context, opts *schema.InspectRealmOption) (*schema.Realm, error) {
+	r, err := i.Inspector.InspectRealm(ctx, opts)
+	if err == nil || opts == nil || opts.OnPermissionError != schema.InspectErrorPartial || !errors.Is(err, sqlerr.PermissionDenied) {
+		return r, err
+	}
+	o := *opts
+	o.Mode = schema.InspectSchemas
+	names, err := i.Inspector.InspectRealm(ctx, &o)
+	if err != nil {
+		return nil, err
+	}
+	w := &schema.InspectWarnings{}
+	r = nil
+	for _, s := range names.Schemas {
+		o := *opts
+		o.Schemas = []string{s.Name}
+		sr, err := i.Inspector.InspectRealm(ctx, &o)
+		switch {
+		case errors.Is(err, sqlerr.PermissionDenied):
+			w.Skipped = append(w.Skipped, &schema.SkippedObject{Type: "schema", Name: s.Name, Err: err})
+		case err != nil:
+			return nil, err
+		case r == nil:
+			// Realm-level objects and attributes are
+			// taken from the first inspected schema.
+			r = sr
+		default:
+			r.AddSchemas(sr.Schemas...)
+		}
+	}
+	// All schemas were skipped.
+	if r == nil {
+		r, names.Schemas = names, nil
+	}
+	LinkSchemaTables(r.Schemas)
+	schema.ReplaceOrAppend(&r.Attrs, w)
+	return r, nil
+}
@@ -0,0 +1,163 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlerr"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartialInspect_InspectRealm(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		insp = &deniedInspector{
+			schemas: map[string][]string{"a": {"users"}, "b": {"pets"}, "c": {"secrets"}},
+			denied:  []string{"c"},
+		}
+		i = &PartialInspect{Inspector: insp}
+	)
+	// Permission errors fail the inspection by default.
+	_, err := i.InspectRealm(ctx, &schema.InspectRealmOption{})
+	require.True(t, errors.Is(err, sqlerr.PermissionDenied))
+	_, err = i.InspectRealm(ctx, nil)
+	require.True(t, errors.Is(err, sqlerr.PermissionDenied))
+
+	r, err := i.InspectRealm(ctx, &schema.InspectRealmOption{OnPermissionError: schema.InspectErrorPartial})
+	require.NoError(t, err)
+	require.Len(t, r.Schemas, 2)
+	a, b := r.Schemas[0], r.Schemas[1]
+	require.Equal(t, "a", a.Name)
+	require.Equal(t, "b", b.Name)
+	require.Same(t, r, b.Realm)
+	// References between schemas that were inspected separately are linked.
+	require.Same(t, a.Tables[0], b.Tables[0].ForeignKeys[0].RefTable)
+	require.Same(t, a.Tables[0].Columns[0], b.Tables[0].ForeignKeys[0].RefColumns[0])
+	w, ok := r.InspectWarnings()
+	require.True(t, ok)
+	require.Len(t, w.Skipped, 1)
+	require.Equal(t, "schema", w.Skipped[0].Type)
+	require.Equal(t, "c", w.Skipped[0].Name)
+	require.Equal(t, `schema "c" was skipped: inspect schema "c": sql: permission denied`, w.Skipped[0].String())
+
+	// Other errors are returned as-is.
+	insp.fail = errors.New("connection refused")
+	_, err = i.InspectRealm(ctx, &schema.InspectRealmOption{OnPermissionError: schema.InspectErrorPartial})
+	require.EqualError(t, err, "connection refused")
+
+	// All schemas were skipped.
+	insp.fail, insp.denied = nil, []string{"a", "b", "c"}
+	r, err = i.InspectRealm(ctx, &schema.InspectRealmOption{OnPermissionError: schema.InspectErrorPartial})
+	require.NoError(t, err)
+	require.Empty(t, r.Schemas)
+	w, ok = r.InspectWarnings()
+	require.True(t, ok)
+	require.Len(t, w.Skipped, 3)
+}
+
+func TestPartialInspect_InspectSchema(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		insp = &deniedInspector{
+			schemas: map[string][]string{"b": {"pets", "secrets", "toys"}},
+			denied:  []string{"b.secrets"},
+		}
+		i = &PartialInspect{Inspector: insp}
+	)
+	_, err := i.InspectSchema(ctx, "b", &schema.InspectOptions{Tables: []string{"pets", "secrets", "toys"}})
+	require.True(t, errors.Is(err, sqlerr.PermissionDenied))
+	// Tables cannot be skipped if they were not listed explicitly.
+	_, err = i.InspectSchema(ctx, "b", &schema.InspectOptions{OnPermissionError: schema.InspectErrorPartial})
+	require.True(t, errors.Is(err, sqlerr.PermissionDenied))
+
+	s, err := i.InspectSchema(ctx, "b", &schema.InspectOptions{Tables: []string{"pets", "secrets", "toys"}, OnPermissionError: schema.InspectErrorPartial})
+	require.NoError(t, err)
+	require.Len(t, s.Tables, 2)
+	require.Equal(t, "pets", s.Tables[0].Name)
+	require.Equal(t, "toys", s.Tables[1].Name)
+	require.Same(t, s, s.Tables[1].Schema)
+	w, ok := s.InspectWarnings()
+	require.True(t, ok)
+	require.Equal(t, []*schema.SkippedObject{{Type: "table", Name: "b.secrets", Err: w.Skipped[0].Err}}, w.Skipped)
+	require.True(t, errors.Is(w.Skipped[0].Err, sqlerr.PermissionDenied))
+
+	// All tables were skipped.
+	s, err = i.InspectSchema(ctx, "b", &schema.InspectOptions{Tables: []string{"secrets"}, OnPermissionError: schema.InspectErrorPartial})
+	require.NoError(t, err)
+	require.Equal(t, "b", s.Name)
+	require.Empty(t, s.Tables)
+	w, ok = s.InspectWarnings()
+	require.True(t, ok)
+	require.Len(t, w.Skipped, 1)
+}
+
+// deniedInspector is a fake inspector that fails with permission
+// errors on inspecting the denied schemas or tables.
+type deniedInspector struct {
+	schemas map[string][]string
+	denied  []string
+	fail    error
+}
+
+func (i *deniedInspector) InspectSchema(_ context.Context, name string, opts *schema.InspectOptions) (*schema.Schema, error) {
+	s := schema.New(name)
+	if opts.Mode == schema.InspectSchemas {
+		return s, nil
+	}
+	tables := opts.Tables
+	if len(tables) == 0 {
+		tables = i.schemas[name]
+	}
+	for _, t := range tables {
+		if slices.Contains(i.denied, name+"."+t) {
+			return nil, fmt.Errorf("inspect table %q: %w", t, sqlerr.PermissionDenied)
+		}
+		s.AddTables(schema.NewTable(t).AddColumns(schema.NewIntColumn("id", "int")))
+	}
+	return s, nil
+}
+
+func (i *deniedInspector) InspectRealm(_ context.Context, opts *schema.InspectRealmOption) (*schema.Realm, error) {
+	if i.fail != nil {
+		return nil, i.fail
+	}
+	var names []string
+	if opts != nil && len(opts.Schemas) > 0 {
+		names = opts.Schemas
+	} else {
+		for n := range i.schemas {
+			names = append(names, n)
+		}
+		slices.Sort(names)
+	}
+	r := schema.NewRealm()
+	for _, n := range names {
+		s := schema.New(n)
+		r.AddSchemas(s)
+		if opts != nil && opts.Mode == schema.InspectSchemas {
+			continue
+		}
+		if slices.Contains(i.denied, n) {
+			return nil, fmt.Errorf("inspect schema %q: %w", n, sqlerr.PermissionDenied)
+		}
+		for _, t := range i.schemas[n] {
+			tt := schema.NewTable(t).AddColumns(schema.NewIntColumn("id", "int"))
+			// Tables of schema "b" reference a stub of the "users" table of schema "a".
+			if n == "b" {
+				users := schema.NewTable("users").SetSchema(schema.New("a")).AddColumns(schema.NewIntColumn("id", "int"))
+				tt.AddForeignKeys(schema.NewForeignKey("owner").AddColumns(tt.Columns[0]).SetRefTable(users).AddRefColumns(users.Columns[0]))
+			}
+			s.AddTables(tt)
+		}
+	}
+	return r, nil
+}
@@ -11,6 +11,8 @@ import (
 	"fmt"
 	"slices"
 	"sort"
+	"sync"
+	"sync/atomic"
 
 	"ariga.io/atlas/sql/migrate"
 	"ariga.io/atlas/sql/schema"
@@ -24,17 +26,30 @@ type (
 	// ApplyError is an error that exposes an information for getting
 	// how any changes were applied before encountering the failure.
 	ApplyError struct {
-		err     string
-		applied int
+		err      string
+		applied  int
+		executed []int
 	}
 )
 
-// Applied reports how many changes were applied before getting an error.
-// In case the first change was failed, Applied() returns 0.
+// Applied reports how many changes were applied before getting an error. i.e.,
+// the first Applied() changes in the plan were executed. In case the first change
+// was failed, Applied() returns 0.
+//
+// Note, in case of parallel execution, changes that come after the failed one in
+// the plan might have been executed as well, and Applied() reports only the longest
+// prefix of executed changes. Use Executed() to get all of them.
 func (e *ApplyError) Applied() int {
 	return e.applied
 }
 
+// Executed returns the indexes of the planned changes that were executed before
+// getting an error, in plan order. Unlike Applied, it also includes the changes
+// executed after the failed one in case of parallel execution.
+func (e *ApplyError) Executed() []int {
+	return e.executed
+}
+
 // Error implements the error interface.
 func (e *ApplyError) Error() string {
 	return e.err
@@ -46,13 +61,115 @@ func ApplyChanges(ctx context.Context, changes []schema.Change, p execPlanner, o
 	if err != nil {
 		return err
 	}
+	var o migrate.PlanOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.Parallel > 1 {
+		return applyParallel(ctx, plan, p, o.Parallel)
+	}
+	for i, c := range plan.Changes {
+		if err := execChange(ctx, p, c); err != nil {
+			e := &ApplyError{err: err.Error(), applied: i}
+			for j := range i {
+				e.executed = append(e.executed, j)
+			}
+			return e
+		}
+	}
+	return nil
+}
+
+// applyParallel executes the planned changes using up to n concurrent statements. A change
+// is started only after the changes it depends on were executed, and no change is started
+// after a failure. The error of the first failed change (in plan order) is returned, and
+// it reports the longest prefix of executed changes and the indexes of all of them.
+func applyParallel(ctx context.Context, plan *migrate.Plan, p execPlanner, n int) error {
+	dependsOn := func(c1, c2 schema.Change) bool {
+		return DependsOn(c1, c2, nil)
+	}
+	if d, ok := p.(migrate.ChangeDepender); ok {
+		dependsOn = d.DependsOn
+	}
+	var (
+		wg     sync.WaitGroup
+		failed atomic.Bool
+		sem    = make(chan struct{}, n)
+		errs   = make([]error, len(plan.Changes))
+		execd  = make([]bool, len(plan.Changes))
+		done   = make([]chan struct{}, len(plan.Changes))
+	)
 	for i, c := range plan.Changes {
-		if _, err := p.ExecContext(ctx, c.Cmd, c.Args...); err != nil {
-			if c.Comment != "" {
-				err = fmt.Errorf("%s: %w", c.Comment, err)
+		var deps []int
+		for j := range i {
+			if !parallelChanges(plan.Changes[j], c, dependsOn) {
+				deps = append(deps, j)
+			}
+		}
+		done[i] = make(chan struct{})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(done[i])
+			for _, j := range deps {
+				<-done[j]
+			}
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			// Dependencies were executed successfully, unless
+			// a failure was recorded before they were done.
+			if failed.Load() {
+				return
+			}
+			if errs[i] = execChange(ctx, p, c); errs[i] != nil {
+				failed.Store(true)
+				return
+			}
+			execd[i] = true
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		e := &ApplyError{err: err.Error(), applied: slices.Index(execd, false)}
+		for i := range execd {
+			if execd[i] {
+				e.executed = append(e.executed, i)
 			}
-			return &ApplyError{err: err.Error(), applied: i}
 		}
+		return e
+	}
+	return nil
+}
+
+// parallelChanges reports if the planned change c2 can be executed concurrently with
+// c1, that was planned before it. To stay on the safe side, only statements of table
+// creations and modifications that do not depend on each other run concurrently.
+func parallelChanges(c1, c2 *migrate.Change, dependsOn func(c1, c2 schema.Change) bool) bool {
+	for _, c := range []schema.Change{c1.Source, c2.Source} {
+		switch c.(type) {
+		case *schema.AddTable, *schema.ModifyTable:
+		default:
+			return false
+		}
+	}
+	return c1.Source != c2.Source && table(c1.Source) != table(c2.Source) &&
+		!dependsOn(c2.Source, c1.Source) && !dependsOn(c1.Source, c2.Source)
+}
+
+// execChange executes the given planned change.
+func execChange(ctx context.Context, p execPlanner, c *migrate.Change) error {
+	if _, err := p.ExecContext(ctx, c.Cmd, c.Args...); err != nil {
+		if c.Comment != "" {
+			err = fmt.Errorf("%s: %w", c.Comment, err)
+		}
+		// Point the error to the schema file that caused it, if exists.
+		if pos, ok := c.Pos(); ok {
+			err = fmt.Errorf("%s: %w", pos, err)
+		}
+		return err
 	}
 	return nil
 }
@@ -227,6 +344,10 @@ func dependencies(changes []schema.Change) (map[string][]*schema.Table, error) {
 					deps[change.T.Name] = append(deps[change.T.Name], fk.RefTable)
 				}
 			}
+		case *schema.CloneTable:
+			if change.Source != change.T {
+				deps[change.T.Name] = append(deps[change.T.Name], change.Source)
+			}
 		case *schema.DropTable:
 			for _, fk := range change.T.ForeignKeys {
 				if err := checkFK(fk); err != nil {
@@ -292,6 +413,8 @@ func table(change schema.Change) (t string) {
 	switch change := change.(type) {
 	case *schema.AddTable:
 		t = change.T.Name
+	case *schema.CloneTable:
+		t = change.T.Name
 	case *schema.DropTable:
 		t = change.T.Name
 	case *schema.ModifyTable:
@@ -310,6 +433,22 @@ func isDropped(changes []schema.Change, t *schema.Table) bool {
 	return false
 }
 
+// Backfill is a helper used by the different drivers to build the statement
+// for copying the data of one column to another in the same table. The Using
+// expression of the backfill, if set, is used instead of the old column.
+func Backfill(b *Builder, bf *migrate.Backfill) *migrate.Change {
+	b.P("UPDATE").Table(bf.T).P("SET").Ident(bf.To.Name).P("=")
+	if bf.Using != "" {
+		b.P(bf.Using)
+	} else {
+		b.Ident(bf.From.Name)
+	}
+	return &migrate.Change{
+		Cmd:     b.String(),
+		Comment: fmt.Sprintf("backfill column %q from column %q", bf.To.Name, bf.From.Name),
+	}
+}
+
 // CheckChangesScope checks that changes can be applied
 // on a schema scope (connection).
 func CheckChangesScope(opts migrate.PlanOptions, changes []schema.Change) error {
@@ -333,6 +472,8 @@ func CheckChangesScope(opts migrate.PlanOptions, changes []schema.Change) error
 			return fmt.Errorf("%T is not allowed when migration plan is scoped to one schema", c)
 		case *schema.AddTable:
 			t = c.T
+		case *schema.CloneTable:
+			t = c.T
 		case *schema.ModifyTable:
 			t = c.T
 		case *schema.DropTable:
@@ -382,6 +523,274 @@ func byKeys[T any](m map[string]T) []struct {
 	return vs
 }
 
+// GroupByKind groups the given changes by their kind, while preserving the
+// relative order of changes of the same kind. For example, all table creations
+// are placed before table modifications. Note, the result should be passed to
+// SortChanges in order to keep the order required by the dependencies.
+func GroupByKind(changes []schema.Change) []schema.Change {
+	planned := make([]schema.Change, len(changes))
+	copy(planned, changes)
+	sort.SliceStable(planned, func(i, j int) bool {
+		return kindRank(planned[i]) < kindRank(planned[j])
+	})
+	return planned
+}
+
+// MergeAlters merges adjacent modifications of the same table into one change, if
+// they consist of column changes only (ADD, DROP or MODIFY COLUMN). This allows drivers
+// to plan them as a single ALTER TABLE statement, which reduces the number of table
+// rebuilds and metadata locks. Changes that touch the same column are not merged.
+// The given (sorted) changes are not modified.
+func MergeAlters(changes []schema.Change) []schema.Change {
+	planned := make([]schema.Change, 0, len(changes))
+	for _, c := range changes {
+		m, ok := c.(*schema.ModifyTable)
+		if !ok || !columnChanges(m.Changes) || len(planned) == 0 {
+			planned = append(planned, c)
+			continue
+		}
+		prev, ok := planned[len(planned)-1].(*schema.ModifyTable)
+		if !ok || prev.T != m.T || !columnChanges(prev.Changes) || sharedColumns(prev.Changes, m.Changes) {
+			planned = append(planned, c)
+			continue
+		}
+		merged := make([]schema.Change, 0, len(prev.Changes)+len(m.Changes))
+		planned[len(planned)-1] = &schema.ModifyTable{T: m.T, Changes: append(append(merged, prev.Changes...), m.Changes...)}
+	}
+	return planned
+}
+
+// columnChanges reports if all the given changes are column changes that can be
+// combined with other column changes in the same ALTER TABLE statement.
+func columnChanges(changes []schema.Change) bool {
+	for _, c := range changes {
+		switch c.(type) {
+		case *schema.AddColumn, *schema.DropColumn, *schema.ModifyColumn:
+		default:
+			return false
+		}
+	}
+	return len(changes) > 0
+}
+
+// sharedColumns reports if the two lists of column changes touch the same column.
+func sharedColumns(c1, c2 []schema.Change) bool {
+	names := make(map[string]bool)
+	for _, c := range c1 {
+		for _, n := range changedColumns(c) {
+			names[n] = true
+		}
+	}
+	for _, c := range c2 {
+		for _, n := range changedColumns(c) {
+			if names[n] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// changedColumns returns the names of the columns touched by the column change.
+func changedColumns(c schema.Change) []string {
+	switch c := c.(type) {
+	case *schema.AddColumn:
+		return []string{c.C.Name}
+	case *schema.DropColumn:
+		return []string{c.C.Name}
+	case *schema.ModifyColumn:
+		return []string{c.From.Name, c.To.Name}
+	}
+	return nil
+}
+
+// SkipVirtualFKs returns the given changes without their virtual foreign keys, as they are
+// not enforced by the database and must not be planned. Tables that hold virtual foreign keys
+// are shallow-copied, and modifications between virtual and enforced foreign keys are converted
+// to their drop or add form. The given changes are not modified.
+func SkipVirtualFKs(changes []schema.Change) []schema.Change {
+	enforced := func(t *schema.Table) *schema.Table {
+		if !slices.ContainsFunc(t.ForeignKeys, (*schema.ForeignKey).Virtual) {
+			return t
+		}
+		c := *t
+		c.ForeignKeys = slices.DeleteFunc(slices.Clone(t.ForeignKeys), (*schema.ForeignKey).Virtual)
+		return &c
+	}
+	planned := make([]schema.Change, 0, len(changes))
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddTable:
+			if t := enforced(c.T); t != c.T {
+				a := *c
+				a.T = t
+				planned = append(planned, &a)
+				continue
+			}
+		case *schema.DropTable:
+			if t := enforced(c.T); t != c.T {
+				d := *c
+				d.T = t
+				planned = append(planned, &d)
+				continue
+			}
+		case *schema.ModifyTable:
+			var (
+				changed bool
+				mc      = make([]schema.Change, 0, len(c.Changes))
+			)
+			for _, c1 := range c.Changes {
+				switch c1 := c1.(type) {
+				case *schema.AddForeignKey:
+					if c1.F.Virtual() {
+						changed = true
+						continue
+					}
+				case *schema.DropForeignKey:
+					if c1.F.Virtual() {
+						changed = true
+						continue
+					}
+				case *schema.ModifyForeignKey:
+					switch from, to := c1.From.Virtual(), c1.To.Virtual(); {
+					case from && to:
+						changed = true
+						continue
+					case from:
+						changed = true
+						mc = append(mc, &schema.AddForeignKey{F: c1.To})
+						continue
+					case to:
+						changed = true
+						mc = append(mc, &schema.DropForeignKey{F: c1.From})
+						continue
+					}
+				}
+				mc = append(mc, c1)
+			}
+			// Skip tables that were modified only by their virtual foreign keys.
+			if changed && len(mc) == 0 {
+				continue
+			}
+			if t := enforced(c.T); changed || t != c.T {
+				m := *c
+				m.T, m.Changes = t, mc
+				planned = append(planned, &m)
+				continue
+			}
+		}
+		planned = append(planned, c)
+	}
+	return planned
+}
+
+// PostponeChanges postpones the creation of indexes and foreign keys to the
+// end of the given (sorted) changes, based on the given ordering policy.
+func PostponeChanges(changes []schema.Change, o migrate.PlanOrder) []schema.Change {
+	if !o.Is(migrate.PlanOrderIndexesLast) && !o.Is(migrate.PlanOrderFKsLast) {
+		return changes
+	}
+	var (
+		planned        = make([]schema.Change, 0, len(changes))
+		deferI, deferF []schema.Change
+		indexes, fks   = o.Is(migrate.PlanOrderIndexesLast), o.Is(migrate.PlanOrderFKsLast)
+		postpone       = func(idx *schema.Index) bool {
+			// Unique indexes can be referenced by foreign keys,
+			// and therefore, are postponed only if the keys are.
+			return indexes && (!idx.Unique || fks)
+		}
+	)
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddTable:
+			var (
+				t      = *c.T
+				ai, af []schema.Change
+			)
+			t.Indexes, t.ForeignKeys = nil, nil
+			for _, idx := range c.T.Indexes {
+				if postpone(idx) {
+					ai = append(ai, &schema.AddIndex{I: idx})
+				} else {
+					t.Indexes = append(t.Indexes, idx)
+				}
+			}
+			for _, fk := range c.T.ForeignKeys {
+				if fks {
+					af = append(af, &schema.AddForeignKey{F: fk})
+				} else {
+					t.ForeignKeys = append(t.ForeignKeys, fk)
+				}
+			}
+			if len(ai) > 0 || len(af) > 0 {
+				c = &schema.AddTable{T: &t, Extra: c.Extra}
+			}
+			planned = append(planned, c)
+			if len(ai) > 0 {
+				deferI = append(deferI, &schema.ModifyTable{T: c.T, Changes: ai})
+			}
+			if len(af) > 0 {
+				deferF = append(deferF, &schema.ModifyTable{T: c.T, Changes: af})
+			}
+		case *schema.ModifyTable:
+			var rest, ai, af []schema.Change
+			for _, mc := range c.Changes {
+				switch mc := mc.(type) {
+				case *schema.AddIndex:
+					if postpone(mc.I) {
+						ai = append(ai, mc)
+					} else {
+						rest = append(rest, mc)
+					}
+				case *schema.AddForeignKey:
+					if fks {
+						af = append(af, mc)
+					} else {
+						rest = append(rest, mc)
+					}
+				default:
+					rest = append(rest, mc)
+				}
+			}
+			if len(rest) > 0 {
+				planned = append(planned, &schema.ModifyTable{T: c.T, Changes: rest})
+			}
+			if len(ai) > 0 {
+				deferI = append(deferI, &schema.ModifyTable{T: c.T, Changes: ai})
+			}
+			if len(af) > 0 {
+				deferF = append(deferF, &schema.ModifyTable{T: c.T, Changes: af})
+			}
+		default:
+			planned = append(planned, c)
+		}
+	}
+	// Indexes are created before foreign keys, as the
+	// latter may depend on (unique) indexes being created.
+	return append(planned, append(deferI, deferF...)...)
+}
+
+// kindRank returns the rank of the change kind that is used
+// for grouping changes when ordering them by their kind.
+func kindRank(c schema.Change) int {
+	switch c.(type) {
+	case *schema.AddSchema, *schema.ModifySchema:
+		return 0
+	case *schema.AddObject, *schema.ModifyObject:
+		return 1
+	case *schema.AddTable, *schema.CloneTable:
+		return 2
+	case *schema.RenameTable:
+		return 3
+	case *schema.ModifyTable:
+		return 4
+	case *schema.DropSchema, *schema.DropTable, *schema.DropFunc, *schema.DropProc, *schema.DropObject, *schema.DropView:
+		return 6
+	default:
+		return 5
+	}
+}
+
 // SortOptions allows drivers to customize the behavior of the SortChanges function.
 type SortOptions struct {
 	// FuncDepT reports if a function depends on the given table.
@@ -396,6 +805,9 @@ type SortOptions struct {
 	ProcDepO func(*schema.Proc, schema.Object) bool
 	// ViewDepT reports if a view depends on the given table.
 	ViewDepT func(*schema.View, *schema.Table) bool
+	// TableDepT reports if a table depends on the given table by other
+	// means than foreign keys. e.g., a child table that inherits it.
+	TableDepT func(*schema.Table, *schema.Table) bool
 	// CompareFuncArgs set to true to compare function arguments.
 	CompareFuncArgs bool
 	// DefaultSchema defines the default schema (also known as "search_path") that
@@ -403,6 +815,12 @@ type SortOptions struct {
 	DefaultSchema string
 }
 
+// DependsOn reports if the change c1 depends on the change c2, i.e., it must
+// be executed after it. It uses the same rules as the SortChanges function.
+func DependsOn(c1, c2 schema.Change, opts *SortOptions) bool {
+	return dependsOn(c1, c2, V(opts))
+}
+
 // SortChanges is a helper function to sort to level changes based on their priority.
 func SortChanges(changes []schema.Change, opts *SortOptions) []schema.Change {
 	var views, drop, other []schema.Change
@@ -551,6 +969,11 @@ func depOfAdd(refs []schema.Object, c schema.Change) bool {
 			t, ok := o.(*schema.Table)
 			return ok && SameTable(c.T, t)
 		})
+	case *schema.CloneTable:
+		return slices.ContainsFunc(refs, func(o schema.Object) bool {
+			t, ok := o.(*schema.Table)
+			return ok && SameTable(c.T, t)
+		})
 	case *schema.ModifyTable:
 		return slices.ContainsFunc(refs, func(o schema.Object) bool {
 			t, ok := o.(*schema.Table)
@@ -5,8 +5,14 @@
 package sqlx
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"slices"
+	"sync"
 	"testing"
+	"time"
 
 	"ariga.io/atlas/sql/migrate"
 	"ariga.io/atlas/sql/schema"
@@ -197,3 +203,208 @@ func TestSortDropTables_WithFK(t *testing.T) {
 	changes = []schema.Change{&schema.DropTable{T: t1}, &schema.DropTable{T: t2}}
 	require.Equal(t, []schema.Change{changes[1], changes[0]}, SortChanges(changes, nil))
 }
+
+func TestOrderChanges(t *testing.T) {
+	var (
+		users = schema.NewTable("users").
+			AddColumns(schema.NewIntColumn("id", "int"), schema.NewStringColumn("email", "text"))
+		posts = schema.NewTable("posts").
+			AddColumns(schema.NewIntColumn("id", "int"), schema.NewIntColumn("author_id", "int"))
+	)
+	users.AddIndexes(schema.NewUniqueIndex("users_email").AddColumns(users.Columns[1]))
+	posts.AddIndexes(schema.NewIndex("posts_author").AddColumns(posts.Columns[1]))
+	posts.AddForeignKeys(schema.NewForeignKey("posts_author").AddColumns(posts.Columns[1]).SetRefTable(users).AddRefColumns(users.Columns[0]))
+	modify := &schema.ModifyTable{T: users, Changes: []schema.Change{&schema.AddColumn{C: schema.NewIntColumn("age", "int")}}}
+	changes := []schema.Change{modify, &schema.AddTable{T: users}, &schema.AddTable{T: posts}}
+
+	planned := GroupByKind(changes)
+	require.Equal(t, []schema.Change{changes[1], changes[2], changes[0]}, planned)
+	require.Equal(t, changes, PostponeChanges(changes, migrate.PlanOrderDefault))
+
+	// Unique indexes are kept as they might be referenced by foreign keys.
+	planned = PostponeChanges(planned, migrate.PlanOrderIndexesLast)
+	require.Len(t, planned, 4)
+	require.Equal(t, users, planned[0].(*schema.AddTable).T)
+	require.Empty(t, planned[1].(*schema.AddTable).T.Indexes)
+	require.Len(t, planned[1].(*schema.AddTable).T.ForeignKeys, 1)
+	require.Equal(t, modify, planned[2])
+	require.Equal(t, &schema.ModifyTable{T: planned[1].(*schema.AddTable).T, Changes: []schema.Change{&schema.AddIndex{I: posts.Indexes[0]}}}, planned[3])
+
+	planned = PostponeChanges(changes, migrate.PlanOrderIndexesLast|migrate.PlanOrderFKsLast)
+	require.Len(t, planned, 6)
+	require.Empty(t, planned[1].(*schema.AddTable).T.Indexes)
+	require.Empty(t, planned[2].(*schema.AddTable).T.Indexes)
+	require.Empty(t, planned[2].(*schema.AddTable).T.ForeignKeys)
+	require.Equal(t, []schema.Change{&schema.AddIndex{I: users.Indexes[0]}}, planned[3].(*schema.ModifyTable).Changes)
+	require.Equal(t, []schema.Change{&schema.AddIndex{I: posts.Indexes[0]}}, planned[4].(*schema.ModifyTable).Changes)
+	require.Equal(t, []schema.Change{&schema.AddForeignKey{F: posts.ForeignKeys[0]}}, planned[5].(*schema.ModifyTable).Changes)
+}
+
+func TestMergeAlters(t *testing.T) {
+	var (
+		users = schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"), schema.NewIntColumn("age", "int"))
+		pets  = schema.NewTable("pets").AddColumns(schema.NewIntColumn("id", "int"))
+		name  = schema.NewStringColumn("name", "text")
+		c1    = &schema.ModifyTable{T: users, Changes: []schema.Change{&schema.AddColumn{C: name}}}
+		c2    = &schema.ModifyTable{T: users, Changes: []schema.Change{&schema.DropColumn{C: users.Columns[1]}}}
+		c3    = &schema.ModifyTable{T: users, Changes: []schema.Change{&schema.ModifyColumn{From: name, To: schema.NewStringColumn("name", "varchar(255)")}}}
+		c4    = &schema.ModifyTable{T: users, Changes: []schema.Change{&schema.AddIndex{I: schema.NewIndex("idx").AddColumns(name)}}}
+		c5    = &schema.ModifyTable{T: pets, Changes: []schema.Change{&schema.AddColumn{C: schema.NewIntColumn("age", "int")}}}
+	)
+	planned := MergeAlters([]schema.Change{c1, c2, c3, c4, c5, &schema.AddTable{T: pets}})
+	require.Len(t, planned, 5)
+	require.Equal(t, &schema.ModifyTable{T: users, Changes: []schema.Change{c1.Changes[0], c2.Changes[0]}}, planned[0])
+	// Changes that touch the same column, or that are not column changes, are not merged.
+	require.Equal(t, []schema.Change{c3, c4, c5}, planned[1:4])
+	// The original changes are not modified.
+	require.Len(t, c1.Changes, 1)
+	require.Empty(t, MergeAlters(nil))
+}
+
+func TestSkipVirtualFKs(t *testing.T) {
+	var (
+		users = schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))
+		pets  = schema.NewTable("pets").AddColumns(schema.NewIntColumn("id", "int"), schema.NewIntColumn("owner_id", "int"))
+		fk1   = schema.NewForeignKey("owner").AddColumns(pets.Columns[1]).SetRefTable(users).AddRefColumns(users.Columns[0]).AddAttrs(&schema.VirtualFK{})
+		fk2   = schema.NewForeignKey("owner").AddColumns(pets.Columns[1]).SetRefTable(users).AddRefColumns(users.Columns[0])
+	)
+	pets.AddForeignKeys(fk1)
+	require.True(t, fk1.Virtual())
+	require.False(t, fk2.Virtual())
+
+	planned := SkipVirtualFKs([]schema.Change{&schema.AddTable{T: users}, &schema.AddTable{T: pets}, &schema.DropTable{T: pets}})
+	require.Len(t, planned, 3)
+	require.Same(t, users, planned[0].(*schema.AddTable).T)
+	require.Empty(t, planned[1].(*schema.AddTable).T.ForeignKeys)
+	require.Equal(t, pets.Columns, planned[1].(*schema.AddTable).T.Columns)
+	require.Empty(t, planned[2].(*schema.DropTable).T.ForeignKeys)
+	// The original tables are not modified.
+	require.Equal(t, []*schema.ForeignKey{fk1}, pets.ForeignKeys)
+
+	// Tables that are modified only by virtual foreign keys are skipped.
+	planned = SkipVirtualFKs([]schema.Change{
+		&schema.ModifyTable{T: pets, Changes: []schema.Change{&schema.AddForeignKey{F: fk1}}},
+		&schema.ModifyTable{T: pets, Changes: []schema.Change{&schema.ModifyForeignKey{From: fk1, To: fk1}}},
+	})
+	require.Empty(t, planned)
+
+	// Modifications between virtual and enforced foreign keys.
+	name := schema.NewStringColumn("name", "text")
+	planned = SkipVirtualFKs([]schema.Change{
+		&schema.ModifyTable{T: pets, Changes: []schema.Change{&schema.ModifyForeignKey{From: fk1, To: fk2}, &schema.AddColumn{C: name}}},
+		&schema.ModifyTable{T: pets, Changes: []schema.Change{&schema.ModifyForeignKey{From: fk2, To: fk1}}},
+	})
+	require.Len(t, planned, 2)
+	require.Equal(t, []schema.Change{&schema.AddForeignKey{F: fk2}, &schema.AddColumn{C: name}}, planned[0].(*schema.ModifyTable).Changes)
+	require.Equal(t, []schema.Change{&schema.DropForeignKey{F: fk2}}, planned[1].(*schema.ModifyTable).Changes)
+	require.Empty(t, planned[1].(*schema.ModifyTable).T.ForeignKeys)
+}
+
+func TestApplyChanges_Parallel(t *testing.T) {
+	var (
+		users  = schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))
+		pets   = schema.NewTable("pets").AddColumns(schema.NewIntColumn("id", "int"))
+		owners = schema.NewTable("owners").AddColumns(schema.NewIntColumn("id", "int"))
+		addU   = &schema.AddTable{T: users}
+		addP   = &schema.AddTable{T: pets}
+		addO   = &schema.AddTable{T: owners}
+		modP   = &schema.ModifyTable{T: pets}
+		view   = &schema.AddView{V: schema.NewView("v", "SELECT 1")}
+		p      = &parallelPlanner{
+			started: make(chan string, 2),
+			plan: &migrate.Plan{
+				Changes: []*migrate.Change{
+					{Cmd: "SET 1"},
+					{Cmd: "CREATE users", Source: addU},
+					{Cmd: "CREATE pets", Source: addP},
+					{Cmd: "INDEX users", Source: addU},
+					{Cmd: "INDEX pets", Source: modP},
+					{Cmd: "CREATE owners", Source: addO},
+					{Cmd: "CREATE v", Source: view},
+				},
+			},
+		}
+	)
+	owners.AddForeignKeys(schema.NewForeignKey("owner").AddColumns(owners.Columns[0]).SetRefTable(users).AddRefColumns(users.Columns[0]))
+	// Statements are executed sequentially by default.
+	require.NoError(t, ApplyChanges(context.Background(), nil, p))
+	require.Equal(t, []string{"SET 1", "CREATE users", "CREATE pets", "INDEX users", "INDEX pets", "CREATE owners", "CREATE v"}, p.executed)
+	require.Equal(t, 1, p.max)
+
+	p.executed, p.wait = nil, true
+	require.NoError(t, ApplyChanges(context.Background(), nil, p, func(o *migrate.PlanOptions) { o.Parallel = 4 }))
+	require.Len(t, p.executed, 7)
+	require.Equal(t, 2, p.max, "tables are created concurrently")
+	for _, order := range [][]string{
+		{"SET 1", "CREATE users", "INDEX users", "CREATE owners", "CREATE v"},
+		{"SET 1", "CREATE pets", "INDEX pets", "CREATE v"},
+	} {
+		var idx []int
+		for _, c := range order {
+			idx = append(idx, slices.Index(p.executed, c))
+		}
+		require.True(t, slices.IsSorted(idx), "dependencies are respected: %v", p.executed)
+	}
+
+	// No statements are started after a failure.
+	p.executed, p.wait, p.fail = nil, false, "INDEX users"
+	err := ApplyChanges(context.Background(), nil, p)
+	require.EqualError(t, err, "fail")
+	require.Equal(t, 3, err.(*ApplyError).Applied())
+	require.Equal(t, []int{0, 1, 2}, err.(*ApplyError).Executed())
+
+	p.executed = nil
+	err = ApplyChanges(context.Background(), nil, p, func(o *migrate.PlanOptions) { o.Parallel = 4 })
+	require.EqualError(t, err, "fail")
+	executed := err.(*ApplyError).Executed()
+	require.Len(t, executed, len(p.executed)-1)
+	require.Subset(t, executed, []int{0, 1})
+	require.NotContains(t, executed, 3, "failed change is not executed")
+	// Applied reports the longest prefix of executed changes, as changes
+	// after the failed one (e.g., INDEX pets) might have been executed.
+	applied := err.(*ApplyError).Applied()
+	require.Contains(t, []int{2, 3}, applied)
+	require.Equal(t, executed[:applied], []int{0, 1, 2}[:applied])
+	require.NotContains(t, p.executed, "CREATE owners")
+	require.NotContains(t, p.executed, "CREATE v")
+}
+
+// parallelPlanner is an execPlanner that records the executed statements.
+type parallelPlanner struct {
+	plan     *migrate.Plan
+	fail     string
+	wait     bool
+	started  chan string
+	mu       sync.Mutex
+	running  int
+	max      int
+	executed []string
+}
+
+func (p *parallelPlanner) PlanChanges(context.Context, string, []schema.Change, ...migrate.PlanOption) (*migrate.Plan, error) {
+	return p.plan, nil
+}
+
+func (p *parallelPlanner) ExecContext(_ context.Context, q string, _ ...any) (sql.Result, error) {
+	p.mu.Lock()
+	p.executed = append(p.executed, q)
+	p.running++
+	p.max = max(p.max, p.running)
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		p.running--
+		p.mu.Unlock()
+	}()
+	// Block the creation of the tables until both are started.
+	if p.wait && (q == "CREATE users" || q == "CREATE pets") {
+		p.started <- q
+		for deadline := time.Now().Add(time.Second); len(p.started) < 2 && time.Now().Before(deadline); {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	if q == p.fail {
+		return nil, errors.New("fail")
+	}
+	return nil, nil
+}
@@ -0,0 +1,382 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlx
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Pretty is a pretty-printer for the bodies of functions, procedures, triggers and views
+// that are written to migration files and HCL documents. It changes only the whitespaces
+// outside literals, quoted identifiers and comments, and keeps the layout of the bodies
+// stable regardless of the indentation used by the database or the user:
+//
+//   - Line endings are normalized and trailing spaces are removed.
+//   - Leading tabs are replaced with Indent, and the common indentation of the lines is removed.
+//   - Repeated blank lines are collapsed, and leading and trailing blank lines are removed.
+//   - Long single-line bodies are broken into lines after each statement, and their
+//     BEGIN ... END blocks are indented.
+type Pretty struct {
+	// Indent is the indentation unit. Defaults to two spaces.
+	Indent string
+	// Width is the maximum width of single-line bodies that are kept as-is. Defaults to 80.
+	Width int
+	// DollarQuote enables the PostgreSQL dollar-quoted strings.
+	DollarQuote bool
+	// BackslashEscapes enables backslash-escaped strings (MySQL).
+	BackslashEscapes bool
+	// Backticks enables backtick-quoted identifiers (MySQL).
+	Backticks bool
+	// HashComments enables hash-like (#) comments (MySQL).
+	HashComments bool
+}
+
+type (
+	// segment is a part of the body. Opaque segments, such as literals
+	// and comments, are written as-is.
+	segment struct {
+		text   string
+		opaque bool
+	}
+	// line is a line of the body. A line that starts (or ends) inside
+	// an opaque segment keeps its leading (or trailing) spaces.
+	line struct {
+		segs        []segment
+		cont, inLit bool
+	}
+)
+
+// Format formats the given body.
+func (p *Pretty) Format(body string) string {
+	lines := p.lines(p.scan(body))
+	var (
+		b      strings.Builder
+		prefix *string
+		blank  = func(l *line) bool { return !l.cont && len(l.segs) == 1 && strings.TrimSpace(l.segs[0].text) == "" }
+	)
+	for _, l := range lines {
+		if l.cont || blank(l) {
+			continue
+		}
+		lead := leadingSpace(l.segs[0].text)
+		switch {
+		case prefix == nil:
+			prefix = &lead
+		default:
+			n := 0
+			for n < len(lead) && n < len(*prefix) && lead[n] == (*prefix)[n] {
+				n++
+			}
+			*prefix = (*prefix)[:n]
+		}
+	}
+	for i := 0; i < len(lines); i++ {
+		l := lines[i]
+		if blank(l) {
+			// Collapse repeated blank lines, and skip leading and trailing ones.
+			if b.Len() == 0 || i == len(lines)-1 || blank(lines[i+1]) {
+				continue
+			}
+			b.WriteByte('\n')
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		for j, s := range l.segs {
+			if j == 0 && !l.cont && prefix != nil {
+				s.text = strings.TrimPrefix(s.text, *prefix)
+			}
+			b.WriteString(s.text)
+		}
+	}
+	s := b.String()
+	if !strings.Contains(s, "\n") && len(s) > p.width() {
+		s = p.breakLines(p.scan(s))
+	}
+	return s
+}
+
+// Equal reports if the two bodies are equal, ignoring their layout. i.e., the
+// indentation of their lines, the trailing spaces and the blank lines.
+func (p *Pretty) Equal(b1, b2 string) bool {
+	return b1 == b2 || p.flat(b1) == p.flat(b2)
+}
+
+// flat returns the formatted body without
+// the indentation and the blank lines.
+func (p *Pretty) flat(body string) string {
+	var lines []string
+	for _, l := range p.lines(p.scan(p.Format(body))) {
+		var b strings.Builder
+		for i, s := range l.segs {
+			if i == 0 && !l.cont {
+				s.text = strings.TrimLeft(s.text, " \t")
+			}
+			b.WriteString(s.text)
+		}
+		if b.Len() > 0 || l.cont {
+			lines = append(lines, b.String())
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (p *Pretty) indent() string {
+	if p.Indent == "" {
+		return "  "
+	}
+	return p.Indent
+}
+
+func (p *Pretty) width() int {
+	if p.Width <= 0 {
+		return 80
+	}
+	return p.Width
+}
+
+// lines splits the segments into lines, and normalizes the
+// whitespaces of the lines outside the opaque segments.
+func (p *Pretty) lines(segs []segment) []*line {
+	var (
+		cur   = &line{}
+		lines = []*line{cur}
+	)
+	for _, s := range segs {
+		for i, t := range strings.Split(s.text, "\n") {
+			if i > 0 {
+				cur.inLit = s.opaque
+				cur = &line{cont: s.opaque}
+				lines = append(lines, cur)
+			}
+			if t != "" {
+				cur.segs = append(cur.segs, segment{text: t, opaque: s.opaque})
+			}
+		}
+	}
+	for _, l := range lines {
+		if len(l.segs) == 0 {
+			l.segs = []segment{{}}
+		}
+		if first := &l.segs[0]; !l.cont && !first.opaque {
+			lead := leadingSpace(first.text)
+			first.text = strings.ReplaceAll(lead, "\t", p.indent()) + first.text[len(lead):]
+		}
+		if last := &l.segs[len(l.segs)-1]; !l.inLit && !last.opaque {
+			last.text = strings.TrimRight(last.text, " \t\r")
+		}
+	}
+	return lines
+}
+
+// breakLines breaks a single-line body into lines after each top-level statement,
+// and indents its BEGIN ... END blocks. Lines are broken only in existing whitespaces.
+func (p *Pretty) breakLines(segs []segment) string {
+	var (
+		b      strings.Builder
+		toks   = tokens(segs)
+		stack  []string
+		depth  int
+		parens int
+		brk    bool
+		space  bool
+	)
+	nextWord := func(i int) string {
+		for j := i + 1; j < len(toks); j++ {
+			if !toks[j].opaque && strings.TrimSpace(toks[j].text) == "" {
+				continue
+			}
+			return strings.ToUpper(toks[j].text)
+		}
+		return ""
+	}
+	for i, t := range toks {
+		if !t.opaque && strings.TrimSpace(t.text) == "" {
+			if !brk {
+				b.WriteString(t.text)
+			}
+			space = true
+			continue
+		}
+		word := ""
+		if !t.opaque {
+			word = strings.ToUpper(t.text)
+		}
+		switch {
+		case parens > 0:
+		case word == "BEGIN":
+			if len(stack) > 0 && stack[len(stack)-1] == "DECLARE" {
+				stack = stack[:len(stack)-1]
+				depth--
+			}
+		case word == "END":
+			switch next := nextWord(i); next {
+			case "IF", "LOOP", "WHILE", "REPEAT", "FOR":
+			case "CASE":
+				if len(stack) > 0 && stack[len(stack)-1] == "CASE" {
+					stack = stack[:len(stack)-1]
+				}
+			default:
+				if len(stack) > 0 {
+					if stack[len(stack)-1] == "BEGIN" {
+						depth--
+					}
+					stack = stack[:len(stack)-1]
+				}
+			}
+		}
+		if brk && space {
+			b.WriteString("\n" + strings.Repeat(p.indent(), depth))
+		}
+		brk, space = false, false
+		b.WriteString(t.text)
+		switch {
+		case word == "(":
+			parens++
+		case word == ")" && parens > 0:
+			parens--
+		case parens > 0:
+		case word == ";":
+			brk = true
+		case word == "CASE":
+			stack = append(stack, word)
+		case word == "DECLARE" && i == 0:
+			stack = append(stack, word)
+			depth++
+			brk = true
+		case word == "BEGIN":
+			stack = append(stack, word)
+			depth++
+			brk = nextWord(i) != "ATOMIC"
+		case word == "ATOMIC" && i > 1 && strings.EqualFold(toks[i-2].text, "BEGIN"):
+			brk = true
+		}
+	}
+	return b.String()
+}
+
+// tokens splits the segments into words, whitespaces and punctuation tokens.
+// Opaque segments are returned as single tokens.
+func tokens(segs []segment) []segment {
+	var toks []segment
+	for _, s := range segs {
+		if s.opaque {
+			toks = append(toks, s)
+			continue
+		}
+		for t := s.text; t != ""; {
+			r, w := utf8.DecodeRuneInString(t)
+			var f func(rune) bool
+			switch {
+			case unicode.IsSpace(r):
+				f = unicode.IsSpace
+			case isIdent(r):
+				f = isIdent
+			}
+			for f != nil && w < len(t) {
+				r, n := utf8.DecodeRuneInString(t[w:])
+				if !f(r) {
+					break
+				}
+				w += n
+			}
+			toks = append(toks, segment{text: t[:w]})
+			t = t[w:]
+		}
+	}
+	return toks
+}
+
+// scan splits the body into code and opaque segments.
+func (p *Pretty) scan(body string) []segment {
+	var (
+		segs  []segment
+		start int
+	)
+	emit := func(end int, opaque bool) {
+		if end > start {
+			segs = append(segs, segment{text: body[start:end], opaque: opaque})
+		}
+		start = end
+	}
+	for i := 0; i < len(body); {
+		var end int
+		switch c := body[i]; {
+		case c == '\'':
+			escapes := p.BackslashEscapes || i > 0 && (body[i-1] == 'E' || body[i-1] == 'e') && (i == 1 || !isIdent(rune(body[i-2])))
+			end = skipQuoted(body, i, '\'', escapes)
+		case c == '"':
+			end = skipQuoted(body, i, '"', p.BackslashEscapes)
+		case c == '`' && p.Backticks:
+			end = skipQuoted(body, i, '`', false)
+		case c == '-' && strings.HasPrefix(body[i:], "--"), c == '#' && p.HashComments:
+			if end = strings.IndexByte(body[i:], '\n'); end == -1 {
+				end = len(body)
+			} else {
+				end += i
+			}
+		case c == '/' && strings.HasPrefix(body[i:], "/*"):
+			if end = strings.Index(body[i+2:], "*/"); end == -1 {
+				end = len(body)
+			} else {
+				end += i + 4
+			}
+		case c == '$' && p.DollarQuote && (i == 0 || !isIdent(rune(body[i-1]))):
+			end = skipDollarQuoted(body, i)
+		}
+		if end == 0 {
+			i++
+			continue
+		}
+		emit(i, false)
+		i = end
+		emit(i, true)
+	}
+	emit(len(body), false)
+	return segs
+}
+
+// skipQuoted returns the position after the quoted string that starts at i.
+func skipQuoted(s string, i int, q byte, escapes bool) int {
+	for j := i + 1; j < len(s); j++ {
+		switch {
+		case escapes && s[j] == '\\':
+			j++
+		case s[j] == q && j+1 < len(s) && s[j+1] == q:
+			j++
+		case s[j] == q:
+			return j + 1
+		}
+	}
+	return len(s)
+}
+
+// skipDollarQuoted returns the position after the dollar-quoted string
+// that starts at i, or 0 if there is no dollar-quoted string at i.
+func skipDollarQuoted(s string, i int) int {
+	j := i + 1
+	for j < len(s) && isIdent(rune(s[j])) && (j > i+1 || !unicode.IsDigit(rune(s[j]))) {
+		j++
+	}
+	if j == len(s) || s[j] != '$' {
+		return 0
+	}
+	tag := s[i : j+1]
+	if k := strings.Index(s[j+1:], tag); k != -1 {
+		return j + 1 + k + len(tag)
+	}
+	return len(s)
+}
+
+func isIdent(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func leadingSpace(s string) string {
+	return s[:len(s)-len(strings.TrimLeft(s, " \t"))]
+}
@@ -0,0 +1,107 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPretty_Format(t *testing.T) {
+	var (
+		pg = &Pretty{DollarQuote: true, Width: 40}
+		my = &Pretty{Indent: "\t", BackslashEscapes: true, Backticks: true, HashComments: true, Width: 40}
+	)
+	for _, tt := range []struct {
+		p         *Pretty
+		body, out string
+	}{
+		{p: pg, body: "SELECT 1", out: "SELECT 1"},
+		{p: pg, body: "\r\n\n  SELECT 1  \r\n\n", out: "SELECT 1"},
+		// Common indentation is removed, and tabs are replaced with spaces.
+		{
+			p:    pg,
+			body: "\n\tBEGIN\n\t\tRETURN 1;   \n\n\n\tEND;\n",
+			out:  "BEGIN\n  RETURN 1;\n\nEND;",
+		},
+		// Lines inside literals are kept as-is.
+		{
+			p:    pg,
+			body: "  BEGIN\n    RETURN 'a  \n\tb';\n  END",
+			out:  "BEGIN\n  RETURN 'a  \n\tb';\nEND",
+		},
+		{
+			p:    pg,
+			body: "  BEGIN\n    RETURN $q$ a  \n\tb$q$;\n  END",
+			out:  "BEGIN\n  RETURN $q$ a  \n\tb$q$;\nEND",
+		},
+		// Short single-line bodies are kept as-is.
+		{p: pg, body: "BEGIN RETURN 1; END", out: "BEGIN RETURN 1; END"},
+		// Long single-line bodies are broken into lines.
+		{
+			p:    pg,
+			body: "BEGIN UPDATE t SET c = 1; RETURN NEW; END",
+			out:  "BEGIN\n  UPDATE t SET c = 1;\n  RETURN NEW;\nEND",
+		},
+		{
+			p:    pg,
+			body: "DECLARE n int; BEGIN SELECT count(*) INTO n FROM t; IF n > 0 THEN RETURN 'a;b'; END IF; RETURN CASE WHEN n > 1 THEN 1 ELSE 0 END; END",
+			out:  "DECLARE\n  n int;\nBEGIN\n  SELECT count(*) INTO n FROM t;\n  IF n > 0 THEN RETURN 'a;b';\n  END IF;\n  RETURN CASE WHEN n > 1 THEN 1 ELSE 0 END;\nEND",
+		},
+		{
+			p:    pg,
+			body: "BEGIN ATOMIC INSERT INTO t VALUES (1); SELECT 1; END",
+			out:  "BEGIN ATOMIC\n  INSERT INTO t VALUES (1);\n  SELECT 1;\nEND",
+		},
+		// Lines are not broken without whitespaces.
+		{
+			p:    pg,
+			body: "BEGIN UPDATE t SET c = 1;RETURN NEW.c + 1;END",
+			out:  "BEGIN\n  UPDATE t SET c = 1;RETURN NEW.c + 1;END",
+		},
+		{
+			p:    my,
+			body: "BEGIN DECLARE n INT; SET n = (SELECT 1 /* ; */); SET `a;b` = 'it\\'s;'; END",
+			out:  "BEGIN\n\tDECLARE n INT;\n\tSET n = (SELECT 1 /* ; */);\n\tSET `a;b` = 'it\\'s;';\nEND",
+		},
+		// Multi-line bodies are not broken.
+		{
+			p:    my,
+			body: "  BEGIN # a; b\n    SET n = 1; SET m = 2; SET o = 3; SET p = 4; SET q = 5;\n  END",
+			out:  "BEGIN # a; b\n  SET n = 1; SET m = 2; SET o = 3; SET p = 4; SET q = 5;\nEND",
+		},
+		{
+			p:    my,
+			body: "BEGIN DECLARE n INT; SET n = 1; WHILE n > 0 DO SET n = n - 1; END WHILE; END",
+			out:  "BEGIN\n\tDECLARE n INT;\n\tSET n = 1;\n\tWHILE n > 0 DO SET n = n - 1;\n\tEND WHILE;\nEND",
+		},
+	} {
+		out := tt.p.Format(tt.body)
+		require.Equal(t, tt.out, out, tt.body)
+		// Formatting is stable.
+		require.Equal(t, out, tt.p.Format(out), tt.body)
+	}
+}
+
+func TestPretty_Equal(t *testing.T) {
+	p := &Pretty{DollarQuote: true, Width: 40}
+	for _, tt := range []struct {
+		b1, b2 string
+		equal  bool
+	}{
+		{b1: "SELECT 1", b2: "SELECT 1", equal: true},
+		{b1: "SELECT 1", b2: "\n  SELECT 1\n", equal: true},
+		{b1: "BEGIN\n\tRETURN 1;\nEND", b2: "BEGIN\n    RETURN 1;\n\nEND\n", equal: true},
+		{b1: "BEGIN UPDATE t SET c = 1; RETURN NEW; END", b2: "BEGIN\n\tUPDATE t SET c = 1;\n\tRETURN NEW;\nEND", equal: true},
+		{b1: "BEGIN\n  RETURN 'a\n  b';\nEND", b2: "BEGIN\n    RETURN 'a\n  b';\nEND", equal: true},
+		{b1: "BEGIN\n  RETURN 'a\n  b';\nEND", b2: "BEGIN\n  RETURN 'a\n    b';\nEND"},
+		{b1: "BEGIN\n  RETURN 'a\n\n  b';\nEND", b2: "BEGIN\n  RETURN 'a\n  b';\nEND"},
+		{b1: "SELECT 1", b2: "SELECT  1"},
+		{b1: "SELECT 1;\nSELECT 2", b2: "SELECT 1; SELECT 2"},
+	} {
+		require.Equal(t, tt.equal, p.Equal(tt.b1, tt.b2), "%q == %q", tt.b1, tt.b2)
+	}
+}
@@ -17,7 +17,9 @@ import (
 	"strings"
 	"unicode"
 
+	"ariga.io/atlas/sql/migrate"
 	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlerr"
 )
 
 type (
@@ -30,15 +32,57 @@ type (
 	nopCloser struct {
 		schema.ExecQuerier
 	}
+	// kindExecQuerier classifies the errors returned by the wrapped ExecQuerier.
+	kindExecQuerier struct {
+		schema.ExecQuerier
+		kind func(error) sqlerr.Kind
+	}
 )
 
+// WithErrorKind wraps the given ExecQuerier, and classifies the errors returned by its
+// ExecContext and QueryContext methods using the given function. It is used by drivers
+// to expose the kind of the errors returned by the database. See sqlerr.Kind.
+func WithErrorKind(e schema.ExecQuerier, kind func(error) sqlerr.Kind) schema.ExecQuerier {
+	return &kindExecQuerier{ExecQuerier: e, kind: kind}
+}
+
+// ExecContext executes the query on the underlying connection,
+// and classifies the returned error, if any.
+func (e *kindExecQuerier) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	r, err := e.ExecQuerier.ExecContext(ctx, query, args...)
+	return r, sqlerr.Wrap(err, e.kind(err))
+}
+
+// QueryContext executes the query on the underlying connection,
+// and classifies the returned error, if any.
+func (e *kindExecQuerier) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	rows, err := e.ExecQuerier.QueryContext(ctx, query, args...)
+	return rows, sqlerr.Wrap(err, e.kind(err))
+}
+
 // Close implements the io.Closer interface.
 func (nopCloser) Close() error { return nil }
 
+// kindCloser is a single connection that classifies its errors.
+type kindCloser struct {
+	kindExecQuerier
+	c io.Closer
+}
+
+// Close implements the io.Closer interface.
+func (k *kindCloser) Close() error { return k.c.Close() }
+
 // SingleConn returns a closable single connection from the given ExecQuerier.
 // If the ExecQuerier is already bound to a single connection (e.g. Tx, Conn),
 // the connection will return as-is with a NopCloser.
 func SingleConn(ctx context.Context, conn schema.ExecQuerier) (ExecQueryCloser, error) {
+	if k, ok := conn.(*kindExecQuerier); ok {
+		c, err := SingleConn(ctx, k.ExecQuerier)
+		if err != nil {
+			return nil, err
+		}
+		return &kindCloser{kindExecQuerier: kindExecQuerier{ExecQuerier: c, kind: k.kind}, c: c}, nil
+	}
 	// A standard sql.DB or a wrapper of it.
 	if opener, ok := conn.(interface {
 		Conn(context.Context) (*sql.Conn, error)
@@ -259,11 +303,12 @@ func modeSchemaOrAll(exclude []string, match string) schema.InspectMode {
 // A Builder provides a syntactic sugar API for writing SQL statements.
 type Builder struct {
 	bytes.Buffer
-	QuoteOpening byte    // quoting identifiers
-	QuoteClosing byte    // quoting identifiers
-	Schema       *string // schema qualifier
-	Indent       string  // indentation string
-	level        int     // current indentation level
+	QuoteOpening byte                 // quoting identifiers
+	QuoteClosing byte                 // quoting identifiers
+	Quoting      migrate.IdentQuoting // identifiers quoting strategy
+	Schema       *string              // schema qualifier
+	Indent       string               // indentation string
+	level        int                  // current indentation level
 }
 
 // P writes a list of phrases to the builder separated and
@@ -289,17 +334,88 @@ func (b *Builder) Int64(v int64) *Builder {
 	return b.P(strconv.FormatInt(v, 10))
 }
 
-// Ident writes the given string quoted as an SQL identifier.
+// Ident writes the given string quoted as an SQL identifier,
+// according to the quoting strategy of the builder.
 func (b *Builder) Ident(s string) *Builder {
-	if s != "" {
-		b.WriteByte(b.QuoteOpening)
+	if s == "" {
+		return b
+	}
+	check := s
+	switch b.Quoting {
+	case migrate.FoldLower:
+		s = strings.ToLower(s)
+		check = s
+	case migrate.FoldUpper:
+		s = strings.ToUpper(s)
+		check = strings.ToLower(s)
+	}
+	// Folded identifiers are written unquoted, as the database folds
+	// unquoted identifiers as well, unless they contain special
+	// characters or are reserved words.
+	if b.Quoting != migrate.QuoteAlways && !IdentNeedsQuote(check) {
 		b.WriteString(s)
-		b.WriteByte(b.QuoteClosing)
 		b.WriteByte(' ')
+		return b
 	}
+	b.WriteByte(b.QuoteOpening)
+	b.WriteString(s)
+	b.WriteByte(b.QuoteClosing)
+	b.WriteByte(' ')
 	return b
 }
 
+// IdentNeedsQuote reports if the given identifier must be quoted to be written
+// as-is in all supported dialects. That is, identifiers that are not lowercase,
+// contain special characters, or are reserved words.
+func IdentNeedsQuote(s string) bool {
+	if s == "" || s[0] >= '0' && s[0] <= '9' {
+		return true
+	}
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '_' {
+			return true
+		}
+	}
+	_, ok := reserved[s]
+	return ok
+}
+
+// reserved holds the words that are reserved (or cannot be used as identifiers
+// in all positions) in at least one of the supported dialects.
+var reserved = func() map[string]struct{} {
+	m := make(map[string]struct{})
+	for _, w := range strings.Fields(`
+		abort accessible add all alter analyse analyze and any array as asc asymmetric
+		attach authorization autoincrement before begin between bigint binary blob both
+		by call cascade case cast change char character check collate collation column
+		commit concurrently condition conflict constraint continue convert create cross
+		cube cume_dist current_catalog current_date current_role current_schema
+		current_time current_timestamp current_user cursor database databases dec decimal
+		declare default deferrable delayed delete dense_rank desc describe detach
+		distinct distinctrow div do double drop dual each else elseif empty enclosed end
+		escape escaped except exclusive exists exit explain false fetch first_value float
+		for force foreign freeze from full fulltext function generated glob grant group
+		grouping groups having high_priority if ignore ilike in index indexed infile
+		initially inner inout insensitive insert instead int integer intersect interval
+		into is isnull iterate join json_table key keys kill lag last_value lateral lead
+		leading leave left like limit linear lines load localtime localtimestamp lock
+		long loop low_priority match mod modifies natural not notnull nth_value ntile
+		null numeric of offset on only optimize option optionally or order out outer
+		outfile over overlaps partition percent_rank placing pragma precision primary
+		procedure purge raise range rank read reads real recursive references regexp
+		reindex release rename repeat replace require resignal restrict return returning
+		revoke right rlike rollback row row_number rows schema schemas select sensitive
+		separator session_user set show signal similar smallint some spatial sql
+		sqlexception sqlstate sqlwarning ssl starting stored straight_join symmetric
+		system table tablesample temp temporary terminated then tinyint to trailing
+		transaction trigger true undo union unique unlock unsigned update usage use user
+		using utc_date utc_time utc_timestamp vacuum values varchar varying variadic
+		verbose view virtual when where while window with write xor zerofill`) {
+		m[w] = struct{}{}
+	}
+	return m
+}()
+
 // View writes the view identifier to the builder, prefixed
 // with the schema name if exists.
 func (b *Builder) View(v *schema.View) *Builder {
@@ -569,6 +685,7 @@ func (b *Builder) Clone() *Builder {
 	return &Builder{
 		QuoteOpening: b.QuoteOpening,
 		QuoteClosing: b.QuoteClosing,
+		Quoting:      b.Quoting,
 		Buffer:       *bytes.NewBufferString(b.Buffer.String()),
 	}
 }
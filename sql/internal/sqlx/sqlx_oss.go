@@ -17,10 +17,33 @@ func sortViewChanges(changes []schema.Change) ([]schema.Change, error) {
 	return changes, nil // unimplemented.
 }
 
-func (*Diff) triggerDiff(_, _ interface {
+// triggerDiff returns the changes for migrating the triggers of a table (or a view) from one
+// state to the other. Triggers are matched by their names, and modified triggers are diffed
+// by the driver, in case it implements the TriggerDiffer interface.
+func (d *Diff) triggerDiff(from, to interface {
 	Trigger(string) (*schema.Trigger, bool)
-}, _, _ []*schema.Trigger, _ *schema.DiffOptions) ([]schema.Change, error) {
-	return nil, nil // unimplemented.
+}, fromT, toT []*schema.Trigger, opts *schema.DiffOptions) ([]schema.Change, error) {
+	var changes schema.Changes
+	for _, t1 := range fromT {
+		t2, ok := to.Trigger(t1.Name)
+		if !ok {
+			changes = opts.AddOrSkip(changes, &schema.DropTrigger{T: t1})
+			continue
+		}
+		if td, ok := d.DiffDriver.(TriggerDiffer); ok {
+			change, err := td.TriggerDiff(t1, t2)
+			if err != nil {
+				return nil, err
+			}
+			changes = opts.AddOrSkip(changes, change...)
+		}
+	}
+	for _, t1 := range toT {
+		if _, ok := from.Trigger(t1.Name); !ok {
+			changes = opts.AddOrSkip(changes, &schema.AddTrigger{T: t1})
+		}
+	}
+	return changes, nil
 }
 
 // funcDep returns true if f1 depends on f2.
@@ -50,7 +73,7 @@ func (*Diff) fixRenames(changes schema.Changes) schema.Changes {
 }
 
 // dependsOn reports if the given change depends on the other change.
-func dependsOn(c1, c2 schema.Change, _ SortOptions) bool {
+func dependsOn(c1, c2 schema.Change, opts SortOptions) bool {
 	if dependOnOf(c1, c2) {
 		return true
 	}
@@ -76,7 +99,7 @@ func dependsOn(c1, c2 schema.Change, _ SortOptions) bool {
 			// Table recreation.
 			return c1.T.Name == c2.T.Name && SameSchema(c1.T.Schema, c2.T.Schema)
 		case *schema.AddTable:
-			if refTo(c1.T.ForeignKeys, c2.T) {
+			if refTo(c1.T.ForeignKeys, c2.T) || opts.TableDepT != nil && opts.TableDepT(c1.T, c2.T) {
 				return true
 			}
 			if slices.ContainsFunc(c1.T.Columns, func(c *schema.Column) bool {
@@ -97,13 +120,30 @@ func dependsOn(c1, c2 schema.Change, _ SortOptions) bool {
 			}
 		}
 		return depOfAdd(c1.T.Deps, c2)
+	case *schema.CloneTable:
+		switch c2 := c2.(type) {
+		case *schema.AddSchema:
+			return c1.T.Schema.Name == c2.S.Name
+		case *schema.DropTable:
+			// Table recreation.
+			return c1.T.Name == c2.T.Name && SameSchema(c1.T.Schema, c2.T.Schema)
+		case *schema.AddTable:
+			// The source table must be created (or modified)
+			// before its structure is copied.
+			return SameTable(c1.Source, c2.T)
+		case *schema.CloneTable:
+			return SameTable(c1.Source, c2.T)
+		case *schema.ModifyTable:
+			return SameTable(c1.Source, c2.T)
+		}
+		return depOfAdd(c1.T.Deps, c2)
 	case *schema.DropTable:
 		// If it is a drop of a table, the change must occur
 		// after all resources that rely on it will be dropped.
 		switch c2 := c2.(type) {
 		case *schema.DropTable:
 			// References to this table, must be dropped first.
-			if refTo(c2.T.ForeignKeys, c1.T) {
+			if refTo(c2.T.ForeignKeys, c1.T) || opts.TableDepT != nil && opts.TableDepT(c2.T, c1.T) {
 				return true
 			}
 			if slices.ContainsFunc(c2.T.Columns, func(c *schema.Column) bool {
@@ -127,11 +167,20 @@ func dependsOn(c1, c2 schema.Change, _ SortOptions) bool {
 		return depOfDrop(c1.T, c2)
 	case *schema.ModifyTable:
 		switch c2 := c2.(type) {
+		case *schema.DropTrigger:
+			// Triggers are dropped before the columns they use.
+			return c2.T.Table != nil && SameTable(c1.T, c2.T.Table)
+		case *schema.CloneTable:
+			// Table modification relies on its creation.
+			return c1.T.Name == c2.T.Name && SameSchema(c1.T.Schema, c2.T.Schema)
 		case *schema.AddTable:
 			// Table modification relies on its creation.
 			if c1.T.Name == c2.T.Name && SameSchema(c1.T.Schema, c2.T.Schema) {
 				return true
 			}
+			if opts.TableDepT != nil && opts.TableDepT(c1.T, c2.T) {
+				return true
+			}
 			// Tables need to be created before referencing them.
 			if slices.ContainsFunc(c1.Changes, func(c schema.Change) bool {
 				switch c := c.(type) {
@@ -175,6 +224,41 @@ func dependsOn(c1, c2 schema.Change, _ SortOptions) bool {
 			}
 		}
 		return depOfAdd(c1.T.Deps, c2)
+	case *schema.AddFunc:
+		switch c2 := c2.(type) {
+		case *schema.AddSchema:
+			return c1.F.Schema != nil && c1.F.Schema.Name == c2.S.Name
+		case *schema.DropFunc:
+			// Function recreation.
+			return c1.F.Name == c2.F.Name && SameSchema(c1.F.Schema, c2.F.Schema)
+		}
+		return depOfAdd(c1.F.Deps, c2)
+	case *schema.ModifyFunc:
+		return depOfAdd(c1.To.Deps, c2)
+	case *schema.DropFunc:
+		return depOfDrop(c1.F, c2)
+	case *schema.AddProc:
+		switch c2 := c2.(type) {
+		case *schema.AddSchema:
+			return c1.P.Schema != nil && c1.P.Schema.Name == c2.S.Name
+		case *schema.DropProc:
+			// Procedure recreation.
+			return c1.P.Name == c2.P.Name && SameSchema(c1.P.Schema, c2.P.Schema)
+		}
+		return depOfAdd(c1.P.Deps, c2)
+	case *schema.ModifyProc:
+		return depOfAdd(c1.To.Deps, c2)
+	case *schema.DropProc:
+		return depOfDrop(c1.P, c2)
+	case *schema.AddTrigger:
+		switch c2 := c2.(type) {
+		case *schema.AddTable:
+			return c1.T.Table != nil && SameTable(c1.T.Table, c2.T)
+		case *schema.ModifyTable:
+			// Columns that are used by the trigger must be added first.
+			return c1.T.Table != nil && SameTable(c1.T.Table, c2.T)
+		}
+		return depOfAdd(c1.T.Deps, c2)
 	case *schema.DropObject:
 		t, ok := c1.O.(schema.Type)
 		if !ok {
@@ -199,6 +283,10 @@ func dependsOn(c1, c2 schema.Change, _ SortOptions) bool {
 				d, ok := c.(*schema.DropColumn)
 				return ok && schema.IsType(d.C.Type.Type, t)
 			})
+		case *schema.DropFunc:
+			return slices.Contains(c2.F.Deps, c1.O)
+		case *schema.DropProc:
+			return slices.Contains(c2.P.Deps, c1.O)
 		}
 	}
 	return false
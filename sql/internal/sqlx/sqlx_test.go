@@ -5,15 +5,50 @@
 package sqlx
 
 import (
+	"context"
 	"errors"
 	"strconv"
 	"testing"
 
+	"ariga.io/atlas/sql/migrate"
 	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlerr"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/require"
 )
 
+func TestWithErrorKind(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	var (
+		ctx  = context.Background()
+		kind = func(err error) sqlerr.Kind {
+			if err != nil && err.Error() == "locked" {
+				return sqlerr.Locked
+			}
+			return sqlerr.Unknown
+		}
+		conn = WithErrorKind(db, kind)
+	)
+	m.ExpectExec("UPDATE t").WillReturnError(errors.New("locked"))
+	_, err = conn.ExecContext(ctx, "UPDATE t")
+	require.EqualError(t, err, "locked")
+	require.Equal(t, sqlerr.Locked, sqlerr.KindOf(err))
+	m.ExpectQuery("SELECT 1").WillReturnError(errors.New("syntax error"))
+	_, err = conn.QueryContext(ctx, "SELECT 1")
+	require.Equal(t, sqlerr.Unknown, sqlerr.KindOf(err))
+
+	// Single connections keep classifying errors.
+	c, err := SingleConn(ctx, conn)
+	require.NoError(t, err)
+	m.ExpectQuery("SELECT 2").WillReturnError(errors.New("locked"))
+	_, err = c.QueryContext(ctx, "SELECT 2")
+	require.Equal(t, sqlerr.Locked, sqlerr.KindOf(err))
+	require.NoError(t, c.Close())
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
 func TestModeInspectRealm(t *testing.T) {
 	m := ModeInspectRealm(nil)
 	require.True(t, m.Is(schema.InspectSchemas))
@@ -108,6 +143,30 @@ func TestBuilder_Qualifier(t *testing.T) {
 	require.Equal(t, `CREATE TABLE "users"`, b.String())
 }
 
+func TestBuilder_Quoting(t *testing.T) {
+	tbl := schema.NewTable("Users").SetSchema(schema.New("public"))
+	for q, want := range map[migrate.IdentQuoting]string{
+		migrate.QuoteAlways:     `ALTER TABLE "public"."Users" ADD COLUMN "id" int, ADD COLUMN "order" int`,
+		migrate.QuoteWhenNeeded: `ALTER TABLE public."Users" ADD COLUMN id int, ADD COLUMN "order" int`,
+		migrate.FoldLower:       `ALTER TABLE public.users ADD COLUMN id int, ADD COLUMN "order" int`,
+		migrate.FoldUpper:       `ALTER TABLE PUBLIC.USERS ADD COLUMN ID int, ADD COLUMN "ORDER" int`,
+	} {
+		b := &Builder{QuoteOpening: '"', QuoteClosing: '"', Quoting: q}
+		b.P("ALTER TABLE").Table(tbl).
+			P("ADD COLUMN").Ident("id").P("int").Comma().
+			P("ADD COLUMN").Ident("order").P("int")
+		require.Equal(t, want, b.String())
+		require.Equal(t, want, b.Clone().String())
+	}
+	require.False(t, IdentNeedsQuote("users_2"))
+	require.True(t, IdentNeedsQuote("2users"))
+	require.True(t, IdentNeedsQuote("user-name"))
+	require.True(t, IdentNeedsQuote("select"))
+	for _, w := range []string{"array", "only", "do", "lateral", "authorization", "ilike", "similar", "interval", "range"} {
+		require.True(t, IdentNeedsQuote(w), w)
+	}
+}
+
 func TestQuote(t *testing.T) {
 	var (
 		s = "s1"
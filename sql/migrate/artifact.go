@@ -0,0 +1,181 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type (
+	// ArtifactStore stores the apply artifacts recorded by an Executor, one per execution
+	// run, to keep a complete record of the executions for post-incident analysis.
+	ArtifactStore interface {
+		WriteArtifact(context.Context, *ApplyArtifact) error
+	}
+
+	// The ArtifactStoreFunc type is an adapter to allow the use
+	// of ordinary functions as ArtifactStores.
+	ArtifactStoreFunc func(context.Context, *ApplyArtifact) error
+
+	// LocalArtifactStore is an ArtifactStore that writes
+	// the artifacts as JSON files to a local directory.
+	LocalArtifactStore struct {
+		path string
+	}
+
+	// ApplyArtifact describes an execution run of an Executor.
+	ApplyArtifact struct {
+		// From and To are the versions of the database before and after the run.
+		From string `json:"From,omitempty"`
+		To   string `json:"To,omitempty"`
+		// Plan holds the names of the files planned for execution.
+		Plan []string `json:"Plan"`
+		// Files that were executed (or partially executed) in this run.
+		Files []*ArtifactFile `json:"Files"`
+		// ServerVersion is the version of the database server, if known.
+		ServerVersion string `json:"ServerVersion,omitempty"`
+		// OperatorVersion that executed the run. See WithOperatorVersion.
+		OperatorVersion string `json:"OperatorVersion,omitempty"`
+		// StartedAt is the starting point of the run, and
+		// ExecutionTime is the time it took to complete it.
+		StartedAt     time.Time     `json:"StartedAt"`
+		ExecutionTime time.Duration `json:"ExecutionTime"`
+		// Error of the run, if any occurred.
+		Error string `json:"Error,omitempty"`
+	}
+
+	// ArtifactFile describes a migration file executed in a run.
+	ArtifactFile struct {
+		Version     string          `json:"Version"`
+		Description string          `json:"Description"`
+		Skipped     int             `json:"Skipped,omitempty"` // Statements that were applied in previous runs.
+		Stmts       []*ArtifactStmt `json:"Stmts"`
+		Error       string          `json:"Error,omitempty"` // Error that is not related to a specific statement.
+	}
+
+	// ArtifactStmt describes the result of a statement executed in a run.
+	ArtifactStmt struct {
+		SQL           string        `json:"SQL"`
+		StartedAt     time.Time     `json:"StartedAt"`
+		ExecutionTime time.Duration `json:"ExecutionTime"`
+		Error         string        `json:"Error,omitempty"`
+	}
+)
+
+// WriteArtifact calls f(ctx, a).
+func (f ArtifactStoreFunc) WriteArtifact(ctx context.Context, a *ApplyArtifact) error {
+	return f(ctx, a)
+}
+
+// NewLocalArtifactStore returns a new LocalArtifactStore that writes
+// the artifacts to the given directory. The directory is created if
+// it does not exist.
+func NewLocalArtifactStore(path string) (*LocalArtifactStore, error) {
+	if err := os.MkdirAll(path, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("sql/migrate: create artifacts directory: %w", err)
+	}
+	return &LocalArtifactStore{path: path}, nil
+}
+
+// WriteArtifact implements the ArtifactStore interface. Artifacts are
+// named after the time they were started at. e.g., 20261016T120000.000000000Z.json.
+func (s *LocalArtifactStore) WriteArtifact(_ context.Context, a *ApplyArtifact) error {
+	b, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return err
+	}
+	name := a.StartedAt.UTC().Format("20060102T150405.000000000Z") + ".json"
+	return os.WriteFile(filepath.Join(s.path, name), b, 0644)
+}
+
+// WithArtifacts sets the ArtifactStore to write an apply
+// artifact to, on every execution run of the Executor.
+func WithArtifacts(s ArtifactStore) ExecutorOption {
+	return func(ex *Executor) error {
+		ex.artifacts = s
+		return nil
+	}
+}
+
+// artifactRecorder is a Logger that records the execution
+// logs to an ApplyArtifact, and passes them to the wrapped Logger.
+type artifactRecorder struct {
+	Logger
+	a    *ApplyArtifact
+	file *ArtifactFile
+	stmt *ArtifactStmt
+}
+
+// recordArtifact starts recording an artifact for the current run,
+// and returns a function that writes it to the configured store.
+func (e *Executor) recordArtifact() func(context.Context, error) error {
+	r := &artifactRecorder{
+		Logger: e.log,
+		a:      &ApplyArtifact{OperatorVersion: e.operator, StartedAt: time.Now()},
+	}
+	if v, ok := e.drv.(interface{ Version() string }); ok {
+		r.a.ServerVersion = v.Version()
+	}
+	e.log = r
+	return func(ctx context.Context, err error) error {
+		e.log = r.Logger
+		r.done("")
+		r.a.ExecutionTime = time.Since(r.a.StartedAt)
+		if err != nil {
+			r.a.Error = err.Error()
+		}
+		if err := e.artifacts.WriteArtifact(ctx, r.a); err != nil {
+			return fmt.Errorf("sql/migrate: write apply artifact: %w", err)
+		}
+		return nil
+	}
+}
+
+// Log implements the Logger interface.
+func (r *artifactRecorder) Log(e LogEntry) {
+	switch e := e.(type) {
+	case LogExecution:
+		r.a.From, r.a.To = e.From, e.To
+		for _, f := range e.Files {
+			r.a.Plan = append(r.a.Plan, f.Name())
+		}
+	case LogFile:
+		r.done("")
+		r.file = &ArtifactFile{Version: e.File.Version(), Description: e.File.Desc(), Skipped: e.Skip}
+		r.a.Files = append(r.a.Files, r.file)
+	case LogStmt:
+		r.done("")
+		if r.file != nil {
+			r.stmt = &ArtifactStmt{SQL: e.SQL, StartedAt: time.Now()}
+			r.file.Stmts = append(r.file.Stmts, r.stmt)
+		}
+	case LogError:
+		switch {
+		case e.Error == nil:
+		case r.stmt != nil && e.SQL != "":
+			r.done(e.Error.Error())
+		case r.file != nil:
+			r.done("")
+			r.file.Error = e.Error.Error()
+		}
+	case LogDone:
+		r.done("")
+	}
+	r.Logger.Log(e)
+}
+
+// done marks the current statement as completed.
+func (r *artifactRecorder) done(err string) {
+	if r.stmt != nil {
+		r.stmt.ExecutionTime = time.Since(r.stmt.StartedAt)
+		r.stmt.Error = err
+		r.stmt = nil
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type (
+	// TimeBudget limits the total runtime of an execution run (e.g., ExecuteN). Once the
+	// budget is exceeded, the Executor does not start new statements, and the statement
+	// that is running is canceled through its context. The revision of the interrupted
+	// file is marked as partially applied, and the execution can be resumed later from
+	// the first statement that was not applied.
+	TimeBudget struct {
+		// Max is the maximum total runtime of an execution run.
+		Max time.Duration
+		// Rollback is called after an execution was aborted. For example, it can be
+		// used to roll back the transaction the statements were executed in. If nil,
+		// the applied statements are kept.
+		//
+		// After a successful rollback, the revisions that were written during the run
+		// are reset to their state before it, unless RevisionsInTx is set.
+		Rollback func(context.Context) error
+		// RevisionsInTx reports that the revisions are written in the same transaction
+		// that is rolled back by Rollback, and therefore, they are rolled back with it.
+		RevisionsInTx bool
+		// Report receives the report of an aborted execution, after it was rolled back.
+		Report func(context.Context, *BudgetReport)
+
+		start time.Time // Start time of the current execution run.
+	}
+
+	// BudgetReport describes an execution that was aborted, as it exceeded its time budget.
+	BudgetReport struct {
+		// Budget is the configured time budget, and Elapsed
+		// is the total runtime until the execution was aborted.
+		Budget  time.Duration `json:"Budget"`
+		Elapsed time.Duration `json:"Elapsed"`
+		// Version and Description of the file that was being executed.
+		Version     string `json:"Version"`
+		Description string `json:"Description"`
+		// Applied and Total are the amount of applied statements of the file and the
+		// total amount of its statements. Stmt is the first statement that was not
+		// applied, and Interrupted reports if it was canceled while running.
+		Applied     int    `json:"Applied"`
+		Total       int    `json:"Total"`
+		Stmt        string `json:"Stmt"`
+		Interrupted bool   `json:"Interrupted"`
+		// RolledBack reports if the execution was rolled back,
+		// and RollbackError holds the rollback error, if any.
+		RolledBack    bool   `json:"RolledBack"`
+		RollbackError string `json:"RollbackError,omitempty"`
+	}
+
+	// BudgetExceededError is returned by the Executor if an
+	// execution was aborted, as it exceeded its time budget.
+	BudgetExceededError struct {
+		Report *BudgetReport
+	}
+)
+
+// WithTimeBudget sets the TimeBudget of the execution runs.
+func WithTimeBudget(b TimeBudget) ExecutorOption {
+	return func(ex *Executor) error {
+		if b.Max <= 0 {
+			return errors.New("sql/migrate: time budget must be positive")
+		}
+		ex.budget = &b
+		return nil
+	}
+}
+
+// Error implements the error interface.
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf(
+		"sql/migrate: time budget of %s exceeded after applying %d of %d statements of version %q",
+		e.Report.Budget, e.Report.Applied, e.Report.Total, e.Report.Version,
+	)
+}
+
+// Unwrap returns the context.DeadlineExceeded error.
+func (e *BudgetExceededError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// begin starts the budget of a new execution, if it was not started by the execution
+// run that contains it (e.g., ExecuteN), and reports if the caller should end it.
+func (b *TimeBudget) begin() bool {
+	if b == nil || !b.start.IsZero() {
+		return false
+	}
+	b.start = time.Now()
+	return true
+}
+
+// end ends the budget of the execution run.
+func (b *TimeBudget) end() {
+	b.start = time.Time{}
+}
+
+// context returns a context that is canceled when the budget is exceeded.
+func (b *TimeBudget) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	if b == nil {
+		return ctx, func() {}
+	}
+	return context.WithDeadline(ctx, b.start.Add(b.Max))
+}
+
+// exceeded reports if the budget was exceeded.
+func (b *TimeBudget) exceeded() bool {
+	return b != nil && !b.start.IsZero() && time.Since(b.start) >= b.Max
+}
+
+// exceededError returns the error of an execution that was aborted
+// before the given statement was applied, or while it was running.
+func (b *TimeBudget) exceededError(r *Revision, stmt *Stmt, interrupted bool) *BudgetExceededError {
+	return &BudgetExceededError{
+		Report: &BudgetReport{
+			Budget:      b.Max,
+			Elapsed:     time.Since(b.start),
+			Version:     r.Version,
+			Description: r.Description,
+			Applied:     r.Applied,
+			Total:       r.Total,
+			Stmt:        stmt.Text,
+			Interrupted: interrupted,
+		},
+	}
+}
+
+// abort rolls back the aborted execution, if possible, and reports it. The reset function
+// is called after a successful rollback to reset the revisions that were written by the run.
+func (b *TimeBudget) abort(ctx context.Context, err *BudgetExceededError, reset func(context.Context) error) error {
+	var rerr error
+	if b.Rollback != nil {
+		switch rerr = b.Rollback(ctx); {
+		case rerr != nil:
+			err.Report.RollbackError = rerr.Error()
+			rerr = fmt.Errorf("sql/migrate: rollback aborted execution: %w", rerr)
+		case !b.RevisionsInTx:
+			if rerr = reset(ctx); rerr != nil {
+				err.Report.RollbackError = rerr.Error()
+				rerr = fmt.Errorf("sql/migrate: reset revisions of aborted execution: %w", rerr)
+				break
+			}
+			fallthrough
+		default:
+			err.Report.RolledBack = true
+		}
+	}
+	if b.Report != nil {
+		b.Report(ctx, err.Report)
+	}
+	return rerr
+}
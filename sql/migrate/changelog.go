@@ -0,0 +1,116 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+// A Changelog describes what ultimately changed in the database schema between two versions
+// of a migration directory. Unlike the migration files themselves, intermediate changes that
+// were reverted by later files (e.g., a table that was created and then dropped) are collapsed,
+// which makes it suitable for release notes.
+type Changelog struct {
+	// From and To are the versions the changelog was computed between.
+	// An empty From version stands for the state before the first file.
+	From string `json:"From"`
+	To   string `json:"To"`
+	// Changes are the schema changes between the two versions.
+	Changes []schema.Change `json:"-"`
+	// Summary is the object-level summary of the changes.
+	Summary *schema.Summary `json:"Summary"`
+}
+
+// DirChangelog computes the Changelog of the given migration directory between the two versions.
+// The states of the versions are computed by replaying the directory on the given dev-database
+// driver, which is restored to its original state afterward. An empty from version stands for the
+// state before the first file, and an empty to version for the latest version of the directory.
+// For example:
+//
+//	c, err := migrate.DirChangelog(ctx, dev, dir, "20240101000000", "20240301000000")
+//	...
+//	fmt.Print(c)
+func DirChangelog(ctx context.Context, drv Driver, dir Dir, from, to string, opts ...schema.DiffOption) (*Changelog, error) {
+	files, err := dir.Files()
+	if err != nil {
+		return nil, fmt.Errorf("sql/migrate: read migration directory files: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, ErrNoPendingFiles
+	}
+	if to == "" {
+		to = files[len(files)-1].Version()
+	}
+	i, j := versionIndex(files, from), versionIndex(files, to)
+	switch {
+	case from != "" && i == -1:
+		return nil, fmt.Errorf("sql/migrate: migration with version %q not found", from)
+	case j == -1:
+		return nil, fmt.Errorf("sql/migrate: migration with version %q not found", to)
+	case i >= j:
+		return nil, fmt.Errorf("sql/migrate: version %q must be before version %q", from, to)
+	}
+	current, err := replayState(ctx, drv, dir, from)
+	if err != nil {
+		return nil, err
+	}
+	desired, err := replayState(ctx, drv, dir, to)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := drv.RealmDiff(current, desired, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Changelog{From: from, To: to, Changes: changes, Summary: schema.Summarize(changes)}, nil
+}
+
+// String returns the changelog as a list of the affected objects,
+// in their changeset order. Nested objects (e.g., columns) are indented.
+func (c *Changelog) String() string {
+	var b strings.Builder
+	for _, o := range c.Summary.Objects {
+		// Nested objects are qualified with the name of their parent.
+		for _, p := range c.Summary.Objects {
+			if p != o && p.Action == schema.SummaryModify && strings.HasPrefix(o.Name, p.Name+".") {
+				b.WriteString("  ")
+				break
+			}
+		}
+		fmt.Fprintf(&b, "- %s %s %q", o.Action, strings.ToLower(o.Type), o.Name)
+		if o.From != "" {
+			fmt.Fprintf(&b, " (from %q)", o.From)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// versionIndex returns the index of the file with the given version, or -1 if it does not exist.
+func versionIndex(files []File, version string) int {
+	if version == "" {
+		return -1
+	}
+	return FilesLastIndex(files, func(f File) bool {
+		return f.Version() == version
+	})
+}
+
+// replayState returns the state of the migration directory at the given
+// version, or the state of the clean database if the version is empty.
+func replayState(ctx context.Context, drv Driver, dir Dir, version string) (*schema.Realm, error) {
+	if version == "" {
+		return RealmConn(drv, nil).ReadState(ctx)
+	}
+	ex, err := NewExecutor(drv, dir, NopRevisionReadWriter{})
+	if err != nil {
+		return nil, err
+	}
+	return ex.Replay(ctx, RealmConn(drv, nil), ReplayToVersion(version))
+}
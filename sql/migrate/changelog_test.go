@@ -0,0 +1,94 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlite"
+	"ariga.io/atlas/sql/sqltesting"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirChangelog(t *testing.T) {
+	var (
+		ctx = context.Background()
+		dir = &migrate.MemDir{}
+		drv = &ddlDriver{Driver: sqltesting.NewDriver(schema.NewRealm(schema.New("main")), sqltesting.WithDiffer(sqlite.DefaultDiff))}
+	)
+	for name, stmts := range map[string]string{
+		"1_init.sql":  "CREATE TABLE users;\n",
+		"2_pets.sql":  "CREATE TABLE pets;\nCREATE TABLE tmp;\n",
+		"3_name.sql":  "ALTER TABLE users ADD COLUMN name;\nDROP TABLE tmp;\n",
+		"4_owner.sql": "ALTER TABLE pets ADD COLUMN owner;\n",
+	} {
+		require.NoError(t, dir.WriteFile(name, []byte(stmts)))
+	}
+	sum, err := dir.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(dir, sum))
+
+	// Intermediate changes (table "tmp") are collapsed.
+	c, err := migrate.DirChangelog(ctx, drv, dir, "1", "3")
+	require.NoError(t, err)
+	require.Equal(t, "1", c.From)
+	require.Equal(t, "3", c.To)
+	require.Equal(t, `- Modify table "main.users"
+  - Add column "main.users.name"
+- Add table "main.pets"
+`, c.String())
+	// The database is restored after the replay.
+	require.Empty(t, drv.Realm().Schemas[0].Tables)
+
+	// Empty versions stand for the first and the latest states.
+	c, err = migrate.DirChangelog(ctx, drv, dir, "", "")
+	require.NoError(t, err)
+	require.Equal(t, "4", c.To)
+	require.Equal(t, 2, c.Summary.Counts["AddTable"])
+	require.Equal(t, `- Add table "main.users"
+- Add table "main.pets"
+`, c.String())
+
+	_, err = migrate.DirChangelog(ctx, drv, dir, "3", "1")
+	require.EqualError(t, err, `sql/migrate: version "3" must be before version "1"`)
+	_, err = migrate.DirChangelog(ctx, drv, dir, "1", "5")
+	require.EqualError(t, err, `sql/migrate: migration with version "5" not found`)
+}
+
+// ddlDriver is a fake driver that applies a minimal DDL dialect on its
+// realm, which allows replaying migration directories in tests.
+type ddlDriver struct {
+	*sqltesting.Driver
+}
+
+func (d *ddlDriver) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	r := d.Realm()
+	s := r.Schemas[0]
+	switch f := strings.Fields(strings.TrimSuffix(query, ";")); {
+	case len(f) == 3 && f[0] == "CREATE":
+		s.AddTables(schema.NewTable(f[2]))
+	case len(f) == 3 && f[0] == "DROP":
+		s.Tables = slices.DeleteFunc(s.Tables, func(t *schema.Table) bool { return t.Name == f[2] })
+	case len(f) == 6 && f[0] == "ALTER":
+		t, ok := s.Table(f[2])
+		if !ok {
+			return nil, fmt.Errorf("table %q not found", f[2])
+		}
+		t.AddColumns(schema.NewStringColumn(f[5], "text"))
+	default:
+		return nil, fmt.Errorf("unexpected statement %q", query)
+	}
+	d.SetRealm(r)
+	return driver.RowsAffected(0), nil
+}
@@ -0,0 +1,178 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+// A DependencyError is returned by the plan editing methods (e.g., Plan.RemoveChange)
+// when an edit breaks the order between a change and a change it depends on. It allows
+// tools that build review interfaces to point the user to the conflicting statements.
+type DependencyError struct {
+	Change    *Change // The change that depends on the other change.
+	DependsOn *Change // The change it depends on.
+}
+
+// Error implements the error interface.
+func (e *DependencyError) Error() string {
+	return fmt.Sprintf("sql/migrate: change %q depends on change %q", e.Change.Cmd, e.DependsOn.Cmd)
+}
+
+// errDepender is returned by the plan editing methods if a
+// change with a source is edited without a ChangeDepender.
+var errDepender = errors.New("sql/migrate: a ChangeDepender is required for editing changes with a source")
+
+// RemoveChange removes the change at the given index from the plan. If it is the last statement
+// of its source, the removal fails with a DependencyError in case another change of the plan
+// depends on it, as computed by the given ChangeDepender (usually, the driver that created
+// the plan). The Reversible field is recomputed after the removal.
+func (p *Plan) RemoveChange(i int, d ChangeDepender) error {
+	if err := checkIndex(i, len(p.Changes)-1); err != nil {
+		return err
+	}
+	var (
+		c       = p.Changes[i]
+		changes = slices.Delete(slices.Clone(p.Changes), i, i+1)
+	)
+	if c.Source != nil && !slices.ContainsFunc(changes, func(o *Change) bool { return o.Source == c.Source }) {
+		if d == nil {
+			return errDepender
+		}
+		for _, o := range changes {
+			if o.Source != nil && d.DependsOn(o.Source, c.Source) {
+				return &DependencyError{Change: o, DependsOn: c}
+			}
+		}
+	}
+	reversible, err := isReversible(changes)
+	if err != nil {
+		return err
+	}
+	p.Changes, p.Reversible = changes, reversible
+	return nil
+}
+
+// InsertChange inserts the given change at the given index of the plan. Changes without a
+// source (e.g., manual statements written by the user) can be inserted at any position, and
+// the ChangeDepender may be nil. Otherwise, the insertion fails with a DependencyError if it
+// is not ordered after the changes it depends on and before the changes that depend on it.
+func (p *Plan) InsertChange(i int, c *Change, d ChangeDepender) error {
+	switch {
+	case c == nil || c.Cmd == "":
+		return errors.New("sql/migrate: cannot insert a change without a statement")
+	case c.Source != nil && d == nil:
+		return errDepender
+	}
+	if err := checkIndex(i, len(p.Changes)); err != nil {
+		return err
+	}
+	changes := slices.Insert(slices.Clone(p.Changes), i, c)
+	if err := checkOrder(changes, i, d); err != nil {
+		return err
+	}
+	reversible, err := isReversible(changes)
+	if err != nil {
+		return err
+	}
+	p.Changes, p.Reversible = changes, reversible
+	return nil
+}
+
+// MoveChange moves the change at index from to index to, and shifts the changes in between.
+// The move fails if the change is reordered with another statement of its source, or with
+// a DependencyError if it breaks the order between the change and its dependencies.
+func (p *Plan) MoveChange(from, to int, d ChangeDepender) error {
+	if err := checkIndex(from, len(p.Changes)-1); err != nil {
+		return err
+	}
+	if err := checkIndex(to, len(p.Changes)-1); err != nil {
+		return err
+	}
+	c := p.Changes[from]
+	changes := slices.Insert(slices.Delete(slices.Clone(p.Changes), from, from+1), to, c)
+	if c.Source != nil {
+		if d == nil {
+			return errDepender
+		}
+		if !slices.Equal(sourceChanges(p.Changes, c.Source), sourceChanges(changes, c.Source)) {
+			return fmt.Errorf("sql/migrate: change %q cannot be reordered with other statements of its source", c.Cmd)
+		}
+		if err := checkOrder(changes, to, d); err != nil {
+			return err
+		}
+	}
+	p.Changes = changes
+	return nil
+}
+
+// AnnotateChange sets the comment of the change at the given index.
+func (p *Plan) AnnotateChange(i int, comment string) error {
+	if err := checkIndex(i, len(p.Changes)-1); err != nil {
+		return err
+	}
+	p.Changes[i].Comment = comment
+	return nil
+}
+
+// checkIndex checks the given index is in the range [0, n].
+func checkIndex(i, n int) error {
+	if i < 0 || i > n {
+		return fmt.Errorf("sql/migrate: change index %d out of range [0, %d]", i, n)
+	}
+	return nil
+}
+
+// isReversible reports if all the given changes are reversible. It is computed before the
+// changes are assigned to the plan, to keep the plan unchanged in case of an error.
+func isReversible(changes []*Change) (bool, error) {
+	reversible := true
+	for _, c := range changes {
+		stmts, err := c.ReverseStmts()
+		if err != nil {
+			return false, err
+		}
+		if len(stmts) == 0 {
+			reversible = false
+		}
+	}
+	return reversible, nil
+}
+
+// checkOrder checks the change at the given index is ordered after
+// the changes it depends on, and before the changes that depend on it.
+func checkOrder(changes []*Change, i int, d ChangeDepender) error {
+	c := changes[i]
+	if c.Source == nil {
+		return nil
+	}
+	for j, o := range changes {
+		if o.Source == nil || o.Source == c.Source {
+			continue
+		}
+		switch {
+		case j > i && d.DependsOn(c.Source, o.Source):
+			return &DependencyError{Change: c, DependsOn: o}
+		case j < i && d.DependsOn(o.Source, c.Source):
+			return &DependencyError{Change: o, DependsOn: c}
+		}
+	}
+	return nil
+}
+
+// sourceChanges returns the changes that were caused by the given source, in their order.
+func sourceChanges(changes []*Change, s schema.Change) []*Change {
+	var cs []*Change
+	for _, c := range changes {
+		if c.Source == s {
+			cs = append(cs, c)
+		}
+	}
+	return cs
+}
@@ -0,0 +1,268 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"time"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	// Backfiller is an optional interface implemented by drivers that support the
+	// expand/contract planning mode. Backfill returns the statement for copying
+	// the data of the old column to the new column of the backfill.
+	Backfiller interface {
+		Backfill(b *Backfill, opts ...PlanOption) (*Change, error)
+	}
+
+	// Phase describes a phase of an expand/contract migration.
+	Phase string
+)
+
+// List of expand/contract phases, in their execution order.
+const (
+	PhaseExpand   Phase = "expand"   // Additive changes. e.g., adding the new columns.
+	PhaseBackfill Phase = "backfill" // Copying data to the new columns, after dual-writes were enabled.
+	PhaseSwap     Phase = "swap"     // Swapping the old columns with the new ones.
+	PhaseContract Phase = "contract" // Destructive changes. e.g., dropping the old columns.
+)
+
+const (
+	// directivePhase annotates a migration file with its expand/contract phase.
+	// For example: "-- atlas:phase 2/4 backfill".
+	directivePhase = "phase"
+	// directiveDualWrite marks the start of a dual-write window. Applications are expected
+	// to write to both columns before the file is executed, and until the contract phase.
+	// For example: "-- atlas:dualwrite users.name users.full_name".
+	directiveDualWrite = "dualwrite"
+)
+
+// PlanWithExpandContract enables the expand/contract planning mode. In this mode, the
+// PlanPhases method rewrites risky single-step changes (e.g., column renames, type changes
+// and column drops) into multiple phases that are written as separate migration files:
+//
+//  1. expand: add the new columns (as nullable) alongside the old ones.
+//  2. backfill: copy the data from the old columns, after the application started dual-writing.
+//  3. swap: rename the new columns to take the place of the old ones.
+//  4. contract: enforce the final column definitions and drop the old columns.
+//
+// Note, this mode requires the driver to implement the Backfiller interface.
+func PlanWithExpandContract(b bool) PlannerOption {
+	return func(p *Planner) {
+		p.expand = b
+	}
+}
+
+// PlanWithBackfillUsing sets a function that returns the expression for computing the
+// values of the new columns of the backfill phase from the old ones. For example, a
+// conversion that is required by a column type change. An empty expression keeps the
+// default behavior of the driver. See Backfill.Using for details.
+func PlanWithBackfillUsing(f func(*Backfill) string) PlannerOption {
+	return func(p *Planner) {
+		p.backfillUsing = f
+	}
+}
+
+// PlanPhases is like Plan, but in case the expand/contract mode is enabled, the changes
+// are split into multiple phases, and a plan is returned for each non-empty phase. The
+// plans are versioned sequentially and annotated with their phase.
+func (p *Planner) PlanPhases(ctx context.Context, name string, to StateReader) ([]*Plan, error) {
+	if !p.expand {
+		plan, err := p.Plan(ctx, name, to)
+		if err != nil {
+			return nil, err
+		}
+		return []*Plan{plan}, nil
+	}
+	bf, ok := p.drv.(Backfiller)
+	if !ok {
+		return nil, fmt.Errorf("sql/migrate: driver %T does not support expand/contract planning", p.drv)
+	}
+	changes, err := p.changes(ctx, to, true)
+	if err != nil {
+		return nil, err
+	}
+	phases := expandContract(changes, p.tempName)
+	var plans []*Plan
+	for _, ph := range phases {
+		plan := &Plan{Name: name + "_" + string(ph.Phase)}
+		switch {
+		case ph.Phase == PhaseBackfill:
+			plan.Transactional = true
+			for _, b := range ph.Backfills {
+				if p.backfillUsing != nil {
+					b.Using = p.backfillUsing(b)
+				}
+				c, err := bf.Backfill(b, p.planOpts...)
+				if err != nil {
+					return nil, err
+				}
+				plan.Changes = append(plan.Changes, c)
+				plan.Directives = append(plan.Directives, fmt.Sprintf("-- atlas:%s %s.%s %s.%s", directiveDualWrite, b.T.Name, b.From.Name, b.T.Name, b.To.Name))
+			}
+		default:
+			if plan, err = p.drv.PlanChanges(ctx, plan.Name, ph.Changes, p.planOpts...); err != nil {
+				return nil, err
+			}
+		}
+		plans = append(plans, plan)
+	}
+	if len(plans) == 0 {
+		return nil, ErrNoPlan
+	}
+	base := p.clock()
+	for i, plan := range plans {
+		plan.Version = base.Add(time.Duration(i) * time.Second).Format(versionFormat)
+		plan.Directives = append([]string{fmt.Sprintf("-- atlas:%s %d/%d %s", directivePhase, i+1, len(plans), phases[i].Phase)}, plan.Directives...)
+	}
+	return plans, nil
+}
+
+// WritePlans writes the given plans to the Dir based on the configured Formatter.
+func (p *Planner) WritePlans(plans ...*Plan) error {
+	for _, plan := range plans {
+		if err := p.WritePlan(plan); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type (
+	// PhaseChanges holds the changes of an expand/contract phase.
+	PhaseChanges struct {
+		Phase     Phase
+		Changes   []schema.Change // Schema changes of the phase.
+		Backfills []*Backfill     // Data changes of the backfill phase.
+	}
+
+	// Backfill describes copying the data of one column to another.
+	Backfill struct {
+		T        *schema.Table
+		From, To *schema.Column
+		// Using is an optional expression for computing the value of the new column
+		// from the old one. e.g., "CAST(amount AS numeric(10,2))". If empty, the value
+		// of the old column is copied, and converted by the driver if the column type
+		// was changed and the database does not convert it implicitly (e.g., PostgreSQL).
+		Using string
+	}
+)
+
+// ExpandContract splits the given changes into expand/contract phases. Empty phases
+// are omitted. Column renames and type changes of columns that are not part of indexes
+// or foreign keys are rewritten into new columns that are backfilled and swapped, and
+// column and table drops are postponed to the contract phase. Renames and type changes
+// of indexed or referenced columns are kept as single-step changes in the expand phase,
+// as the indexes and foreign keys would be lost with the old columns.
+func ExpandContract(changes []schema.Change) []*PhaseChanges {
+	return expandContract(changes, func(_ *schema.Table, c *schema.Column, suffix string) string {
+		return c.Name + "_" + suffix
+	})
+}
+
+// tempName returns the name of the temporary column that is generated for the given column
+// in the expand/contract mode. e.g., "age_new", or "age_new_1a2b3c4d" if a seed was set.
+func (p *Planner) tempName(t *schema.Table, c *schema.Column, suffix string) string {
+	if p.seed == "" {
+		return c.Name + "_" + suffix
+	}
+	h := sha256.Sum256([]byte(strings.Join([]string{p.seed, t.Name, c.Name, suffix}, "\x00")))
+	return fmt.Sprintf("%s_%s_%x", c.Name, suffix, h[:4])
+}
+
+// expandContract implements ExpandContract with the given function for naming temporary columns.
+func expandContract(changes []schema.Change, tempName func(*schema.Table, *schema.Column, string) string) []*PhaseChanges {
+	var (
+		backfills              []*Backfill
+		expand, swap, contract []schema.Change
+		modify                 = func(t *schema.Table, l *[]schema.Change, cs []schema.Change) {
+			if len(cs) > 0 {
+				*l = append(*l, &schema.ModifyTable{T: t, Changes: cs})
+			}
+		}
+	)
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.DropTable:
+			contract = append(contract, c)
+		case *schema.ModifyTable:
+			var e, s, k []schema.Change
+			for _, c1 := range c.Changes {
+				switch c1 := c1.(type) {
+				case *schema.RenameColumn:
+					if len(c1.From.Indexes) > 0 || len(c1.From.ForeignKeys) > 0 {
+						e = append(e, c1)
+						break
+					}
+					to := nullable(c1.To, c1.To.Name)
+					e = append(e, &schema.AddColumn{C: to})
+					backfills = append(backfills, &Backfill{T: c.T, From: c1.From, To: to})
+					k = append(k, finalize(to, c1.To)...)
+					k = append(k, &schema.DropColumn{C: c1.From})
+				case *schema.ModifyColumn:
+					if !c1.Change.Is(schema.ChangeType) || len(c1.From.Indexes) > 0 || len(c1.From.ForeignKeys) > 0 {
+						e = append(e, c1)
+						break
+					}
+					var (
+						tmp = nullable(c1.To, tempName(c.T, c1.To, "new"))
+						old = &schema.Column{Name: tempName(c.T, c1.From, "old"), Type: c1.From.Type, Default: c1.From.Default, Attrs: c1.From.Attrs}
+						fin = nullable(c1.To, c1.To.Name)
+					)
+					e = append(e, &schema.AddColumn{C: tmp})
+					backfills = append(backfills, &Backfill{T: c.T, From: c1.From, To: tmp})
+					s = append(s, &schema.RenameColumn{From: c1.From, To: old}, &schema.RenameColumn{From: tmp, To: fin})
+					k = append(k, finalize(fin, c1.To)...)
+					k = append(k, &schema.DropColumn{C: old})
+				case *schema.DropColumn:
+					k = append(k, c1)
+				default:
+					e = append(e, c1)
+				}
+			}
+			modify(c.T, &expand, e)
+			modify(c.T, &swap, s)
+			modify(c.T, &contract, k)
+		default:
+			expand = append(expand, c)
+		}
+	}
+	var phases []*PhaseChanges
+	if len(expand) > 0 {
+		phases = append(phases, &PhaseChanges{Phase: PhaseExpand, Changes: expand})
+	}
+	if len(backfills) > 0 {
+		phases = append(phases, &PhaseChanges{Phase: PhaseBackfill, Backfills: backfills})
+	}
+	if len(swap) > 0 {
+		phases = append(phases, &PhaseChanges{Phase: PhaseSwap, Changes: swap})
+	}
+	if len(contract) > 0 {
+		phases = append(phases, &PhaseChanges{Phase: PhaseContract, Changes: contract})
+	}
+	return phases
+}
+
+// nullable returns a nullable copy of the given column with the given name, as
+// columns that are added to existing tables cannot be defined as NOT NULL before
+// they are backfilled.
+func nullable(c *schema.Column, name string) *schema.Column {
+	ct := *c.Type
+	ct.Null = true
+	return &schema.Column{Name: name, Type: &ct, Default: c.Default, Attrs: c.Attrs}
+}
+
+// finalize returns the changes for bringing the nullable column to its final definition.
+func finalize(c, to *schema.Column) []schema.Change {
+	if to.Type.Null {
+		return nil
+	}
+	return []schema.Change{&schema.ModifyColumn{From: c, To: to, Change: schema.ChangeNull}}
+}
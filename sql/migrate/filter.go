@@ -0,0 +1,132 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"slices"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	// ChangeDepender is an optional interface implemented by drivers that support
+	// partial plans. DependsOn reports if the change depends on the other change,
+	// i.e., it must be executed after it. For example, a table creation depends on
+	// the creation of the tables it references.
+	ChangeDepender interface {
+		DependsOn(change, other schema.Change) bool
+	}
+
+	// A PlanTarget selects the changes of a plan to keep by their source.
+	PlanTarget func(schema.Change) bool
+)
+
+// TargetTable returns a PlanTarget that selects the changes of the given table,
+// including its creation, modification, renaming and deletion. An empty schema
+// name matches tables in any schema.
+func TargetTable(schemaName, name string) PlanTarget {
+	match := func(t *schema.Table) bool {
+		return t != nil && t.Name == name && (schemaName == "" || t.Schema != nil && t.Schema.Name == schemaName)
+	}
+	return func(c schema.Change) bool {
+		switch c := c.(type) {
+		case *schema.AddTable:
+			return match(c.T)
+		case *schema.CloneTable:
+			return match(c.T)
+		case *schema.DropTable:
+			return match(c.T)
+		case *schema.ModifyTable:
+			return match(c.T)
+		case *schema.RenameTable:
+			return match(c.From) || match(c.To)
+		}
+		return false
+	}
+}
+
+// TargetView returns a PlanTarget that selects the changes of the given view,
+// including its creation, modification, renaming and deletion. An empty schema
+// name matches views in any schema.
+func TargetView(schemaName, name string) PlanTarget {
+	match := func(v *schema.View) bool {
+		return v != nil && v.Name == name && (schemaName == "" || v.Schema != nil && v.Schema.Name == schemaName)
+	}
+	return func(c schema.Change) bool {
+		switch c := c.(type) {
+		case *schema.AddView:
+			return match(c.V)
+		case *schema.DropView:
+			return match(c.V)
+		case *schema.ModifyView:
+			return match(c.To)
+		case *schema.RenameView:
+			return match(c.From) || match(c.To)
+		}
+		return false
+	}
+}
+
+// FilterPlan returns a new plan that contains only the changes of p that were caused by
+// the selected targets, and the changes they depend on (transitively). The dependency
+// closure is computed by the given ChangeDepender (usually, the driver that created the
+// plan), which allows rolling out a large plan incrementally, object by object. e.g.,
+// selecting a table that references a new table, keeps the creation of both tables.
+//
+// Changes without a source (e.g., statements that configure the session) are kept as-is,
+// unless no change was selected. The order of the changes is kept, and the Reversible field
+// is recomputed for the filtered plan.
+func FilterPlan(p *Plan, d ChangeDepender, targets ...PlanTarget) (*Plan, error) {
+	var sources []schema.Change
+	for _, c := range p.Changes {
+		if c.Source != nil && !slices.Contains(sources, c.Source) {
+			sources = append(sources, c.Source)
+		}
+	}
+	selected := make(map[schema.Change]bool)
+	for _, s := range sources {
+		selected[s] = slices.ContainsFunc(targets, func(t PlanTarget) bool { return t(s) })
+	}
+	// Expand the selection until no new dependency is found.
+	for changed := true; changed; {
+		changed = false
+		for _, s := range sources {
+			if !selected[s] {
+				continue
+			}
+			for _, o := range sources {
+				if !selected[o] && d.DependsOn(s, o) {
+					selected[o], changed = true, true
+				}
+			}
+		}
+	}
+	filtered := &Plan{
+		Version:       p.Version,
+		Name:          p.Name,
+		Reversible:    true,
+		Transactional: p.Transactional,
+		Delimiter:     p.Delimiter,
+		Directives:    slices.Clone(p.Directives),
+	}
+	if !slices.ContainsFunc(sources, func(s schema.Change) bool { return selected[s] }) {
+		filtered.Reversible = false
+		return filtered, nil
+	}
+	for _, c := range p.Changes {
+		if c.Source != nil && !selected[c.Source] {
+			continue
+		}
+		stmts, err := c.ReverseStmts()
+		if err != nil {
+			return nil, err
+		}
+		if len(stmts) == 0 {
+			filtered.Reversible = false
+		}
+		filtered.Changes = append(filtered.Changes, c)
+	}
+	return filtered, nil
+}
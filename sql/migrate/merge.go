@@ -0,0 +1,390 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+// MergeConflictError is returned by the StateReader returned by MergeStates
+// when a schema resource is defined by more than one state source.
+type MergeConflictError struct {
+	Kind    string // Kind of the resource. e.g., "table" or "column".
+	Name    string // Qualified name of the resource.
+	Sources [2]int // Indexes of the conflicting state readers.
+}
+
+func (e *MergeConflictError) Error() string {
+	return fmt.Sprintf("sql/migrate: %s %q is defined by state sources %d and %d", e.Kind, e.Name, e.Sources[0], e.Sources[1])
+}
+
+// MergeStates returns a StateReader that composes the desired state from all
+// given readers (e.g., a base SQL file, HCL files and an ORM provider). The
+// states are merged in the given order:
+//
+//   - Schemas are merged by name.
+//   - Tables defined by more than one source are merged at the resource level
+//     (columns, indexes, foreign keys, checks, triggers and attributes).
+//   - Any other resource (e.g., views, functions or types) is merged by name.
+//
+// A resource that is defined by more than one reader results in a MergeConflictError.
+// Use OverrideStates to allow later readers to override definitions of earlier ones.
+func MergeStates(readers ...StateReader) StateReader {
+	return mergeStates(false, readers)
+}
+
+// OverrideStates is like MergeStates, but resources that are defined by more than
+// one reader are replaced by the definition of the latter one instead of failing.
+// For example, a base SQL schema can be extended and partially overridden by HCL.
+func OverrideStates(readers ...StateReader) StateReader {
+	return mergeStates(true, readers)
+}
+
+func mergeStates(override bool, readers []StateReader) StateReader {
+	return StateReaderFunc(func(ctx context.Context) (*schema.Realm, error) {
+		m := &merger{override: override, dst: schema.NewRealm(), owners: make(map[string]int)}
+		for i, r := range readers {
+			realm, err := r.ReadState(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("sql/migrate: read state source %d: %w", i, err)
+			}
+			m.src = i
+			if err := m.realm(realm); err != nil {
+				return nil, err
+			}
+		}
+		m.relink()
+		return m.dst, nil
+	})
+}
+
+// merger merges multiple realms into one.
+type merger struct {
+	override bool
+	src      int
+	dst      *schema.Realm
+	owners   map[string]int // Resource key to its source index.
+}
+
+// claim marks the current source as the owner of the resource. It reports if an
+// existing definition should be replaced, or returns an error if it conflicts.
+func (m *merger) claim(kind, name string) (bool, error) {
+	k := kind + ":" + name
+	owner, ok := m.owners[k]
+	switch {
+	case !ok || owner == m.src:
+		m.owners[k] = m.src
+		return false, nil
+	case m.override:
+		m.owners[k] = m.src
+		return true, nil
+	default:
+		return false, &MergeConflictError{Kind: kind, Name: name, Sources: [2]int{owner, m.src}}
+	}
+}
+
+func (m *merger) realm(r *schema.Realm) error {
+	if err := m.attrs(&m.dst.Attrs, r.Attrs, "realm"); err != nil {
+		return err
+	}
+	if err := m.objects(&m.dst.Objects, r.Objects, ""); err != nil {
+		return err
+	}
+	for _, s := range r.Schemas {
+		d, ok := m.dst.Schema(s.Name)
+		if !ok {
+			d = schema.New(s.Name)
+			m.dst.AddSchemas(d)
+		}
+		if err := m.schema(d, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *merger) schema(dst, src *schema.Schema) error {
+	if err := m.attrs(&dst.Attrs, src.Attrs, dst.Name); err != nil {
+		return err
+	}
+	if err := m.objects(&dst.Objects, src.Objects, dst.Name); err != nil {
+		return err
+	}
+	for _, o := range dst.Objects {
+		if e, ok := o.(*schema.EnumType); ok {
+			e.Schema = dst
+		}
+	}
+	for _, t := range src.Tables {
+		name := dst.Name + "." + t.Name
+		t1, ok := dst.Table(t.Name)
+		if !ok {
+			if _, err := m.claim("table", name); err != nil {
+				return err
+			}
+			// Keep the table pointer, as it might be referenced
+			// by other resources, and merge its content into it.
+			t1, t = t, &schema.Table{
+				Name:        t.Name,
+				Columns:     t.Columns,
+				Indexes:     t.Indexes,
+				PrimaryKey:  t.PrimaryKey,
+				ForeignKeys: t.ForeignKeys,
+				Attrs:       t.Attrs,
+				Triggers:    t.Triggers,
+				Deps:        t.Deps,
+				Refs:        t.Refs,
+			}
+			t1.Columns, t1.Indexes, t1.PrimaryKey, t1.ForeignKeys = nil, nil, nil, nil
+			t1.Attrs, t1.Triggers, t1.Deps, t1.Refs = nil, nil, nil, nil
+			dst.AddTables(t1)
+		}
+		if err := m.table(t1, t); err != nil {
+			return err
+		}
+	}
+	for _, v := range src.Views {
+		replace, err := m.claim("view", dst.Name+"."+v.Name)
+		if err != nil {
+			return err
+		}
+		v.Schema = dst
+		dst.Views = put(dst.Views, v, replace, func(v1 *schema.View) bool { return v1.Name == v.Name })
+	}
+	for _, f := range src.Funcs {
+		replace, err := m.claim("function", dst.Name+"."+f.Name)
+		if err != nil {
+			return err
+		}
+		f.Schema = dst
+		dst.Funcs = put(dst.Funcs, f, replace, func(f1 *schema.Func) bool { return f1.Name == f.Name })
+	}
+	for _, p := range src.Procs {
+		replace, err := m.claim("procedure", dst.Name+"."+p.Name)
+		if err != nil {
+			return err
+		}
+		p.Schema = dst
+		dst.Procs = put(dst.Procs, p, replace, func(p1 *schema.Proc) bool { return p1.Name == p.Name })
+	}
+	return nil
+}
+
+// table merges the resources of src into dst.
+func (m *merger) table(dst, src *schema.Table) (err error) {
+	name := dst.Schema.Name + "." + dst.Name
+	if err := m.attrs(&dst.Attrs, src.Attrs, name); err != nil {
+		return err
+	}
+	for _, c := range src.Columns {
+		replace, err := m.claim("column", name+"."+c.Name)
+		if err != nil {
+			return err
+		}
+		dst.Columns = put(dst.Columns, c, replace, func(c1 *schema.Column) bool { return c1.Name == c.Name })
+	}
+	if pk := src.PrimaryKey; pk != nil {
+		replace, err := m.claim("primary key", name)
+		if err != nil {
+			return err
+		}
+		if dst.PrimaryKey == nil || replace {
+			pk.Table, dst.PrimaryKey = dst, pk
+		}
+	}
+	// Unnamed indexes and foreign keys cannot be
+	// identified, and therefore, are always appended.
+	for _, idx := range src.Indexes {
+		var replace bool
+		if idx.Name != "" {
+			if replace, err = m.claim("index", name+"."+idx.Name); err != nil {
+				return err
+			}
+		}
+		idx.Table = dst
+		dst.Indexes = put(dst.Indexes, idx, replace, func(i1 *schema.Index) bool { return i1.Name == idx.Name })
+	}
+	for _, fk := range src.ForeignKeys {
+		var replace bool
+		if fk.Symbol != "" {
+			if replace, err = m.claim("foreign key", name+"."+fk.Symbol); err != nil {
+				return err
+			}
+		}
+		fk.Table = dst
+		dst.ForeignKeys = put(dst.ForeignKeys, fk, replace, func(f1 *schema.ForeignKey) bool { return f1.Symbol == fk.Symbol })
+	}
+	for _, tr := range src.Triggers {
+		replace, err := m.claim("trigger", name+"."+tr.Name)
+		if err != nil {
+			return err
+		}
+		tr.Table = dst
+		dst.Triggers = put(dst.Triggers, tr, replace, func(t1 *schema.Trigger) bool { return t1.Name == tr.Name })
+	}
+	dst.Deps = append(dst.Deps, src.Deps...)
+	dst.Refs = append(dst.Refs, src.Refs...)
+	return nil
+}
+
+// attrs merges the src attributes into dst. Attributes are identified by their
+// type, and checks by their names. Identical attributes are not considered as
+// conflicts.
+func (m *merger) attrs(dst *[]schema.Attr, src []schema.Attr, name string) error {
+	for _, a := range src {
+		kind := fmt.Sprintf("%T", a)
+		match := func(a1 schema.Attr) bool { return reflect.TypeOf(a1) == reflect.TypeOf(a) }
+		if c, ok := a.(*schema.Check); ok {
+			kind = "check"
+			match = func(a1 schema.Attr) bool {
+				c1, ok := a1.(*schema.Check)
+				return ok && c.Name != "" && c1.Name == c.Name
+			}
+			if c.Name == "" {
+				*dst = append(*dst, a)
+				continue
+			}
+		}
+		i := -1
+		for j := range *dst {
+			if match((*dst)[j]) {
+				i = j
+				break
+			}
+		}
+		if i != -1 && reflect.DeepEqual((*dst)[i], a) {
+			continue
+		}
+		key := name
+		if c, ok := a.(*schema.Check); ok {
+			key += "." + c.Name
+		}
+		replace, err := m.claim(kind, key)
+		if err != nil {
+			return err
+		}
+		*dst = put(*dst, a, replace, match)
+	}
+	return nil
+}
+
+// objects merges the src objects into dst. Objects are identified by their
+// type and name. Unnamed objects are appended as-is.
+func (m *merger) objects(dst *[]schema.Object, src []schema.Object, name string) error {
+	for _, o := range src {
+		n := objectName(o)
+		if n == "" {
+			*dst = append(*dst, o)
+			continue
+		}
+		if name != "" {
+			n = name + "." + n
+		}
+		replace, err := m.claim(fmt.Sprintf("%T", o), n)
+		if err != nil {
+			return err
+		}
+		*dst = put(*dst, o, replace, func(o1 schema.Object) bool {
+			return reflect.TypeOf(o1) == reflect.TypeOf(o) && objectName(o1) == objectName(o)
+		})
+	}
+	return nil
+}
+
+// relink updates the references between the resources of the merged
+// realm, as they might point to resources that were replaced or moved.
+func (m *merger) relink() {
+	for _, s := range m.dst.Schemas {
+		for _, t := range s.Tables {
+			for _, c := range t.Columns {
+				c.Indexes, c.ForeignKeys = nil, nil
+				if e, ok := c.Type.Type.(*schema.EnumType); ok && e.Schema != nil {
+					if o, ok := e.Schema.Object(func(o schema.Object) bool {
+						e1, ok := o.(*schema.EnumType)
+						return ok && e1.T == e.T
+					}); ok {
+						c.Type.Type = o.(*schema.EnumType)
+					}
+				}
+			}
+		}
+	}
+	column := func(t *schema.Table, c *schema.Column) *schema.Column {
+		if c1, ok := t.Column(c.Name); ok {
+			return c1
+		}
+		return c
+	}
+	for _, s := range m.dst.Schemas {
+		for _, t := range s.Tables {
+			for _, idx := range append([]*schema.Index{t.PrimaryKey}, t.Indexes...) {
+				if idx == nil {
+					continue
+				}
+				for _, p := range idx.Parts {
+					if p.C != nil {
+						p.C = column(t, p.C)
+						p.C.Indexes = append(p.C.Indexes, idx)
+					}
+				}
+			}
+			for _, fk := range t.ForeignKeys {
+				for i, c := range fk.Columns {
+					fk.Columns[i] = column(t, c)
+					fk.Columns[i].ForeignKeys = append(fk.Columns[i].ForeignKeys, fk)
+				}
+				if fk.RefTable == nil || fk.RefTable.Schema == nil {
+					continue
+				}
+				rs, ok := m.dst.Schema(fk.RefTable.Schema.Name)
+				if !ok {
+					continue
+				}
+				if ref, ok := rs.Table(fk.RefTable.Name); ok {
+					fk.RefTable = ref
+					for i, c := range fk.RefColumns {
+						fk.RefColumns[i] = column(ref, c)
+					}
+				}
+			}
+			for _, tr := range t.Triggers {
+				tr.Table = t
+			}
+		}
+	}
+}
+
+// put appends v to the slice, or replaces the first
+// element that matches f in case replace is true.
+func put[T any](s []T, v T, replace bool, f func(T) bool) []T {
+	if replace {
+		for i := range s {
+			if f(s[i]) {
+				s[i] = v
+				return s
+			}
+		}
+	}
+	return append(s, v)
+}
+
+// objectName returns the name of the given object, if it has one.
+func objectName(o schema.Object) string {
+	if e, ok := o.(*schema.EnumType); ok {
+		return e.T
+	}
+	v := reflect.Indirect(reflect.ValueOf(o))
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+	if f := v.FieldByName("Name"); f.IsValid() && f.Kind() == reflect.String {
+		return f.String()
+	}
+	return ""
+}
@@ -0,0 +1,278 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	// A DiffMiddleware wraps a schema.Differ with additional behavior. For example,
+	// filtering or rewriting the changes computed by the next Differ in the chain.
+	// Implementations that customize only some of the methods can embed the next
+	// Differ and override the methods they need.
+	DiffMiddleware func(next schema.Differ) schema.Differ
+
+	// PlanFunc is the signature of the PlanApplier.PlanChanges method.
+	PlanFunc func(ctx context.Context, name string, changes []schema.Change, opts ...PlanOption) (*Plan, error)
+
+	// A PlanMiddleware wraps a PlanFunc with additional behavior. For example,
+	// rewriting the planned statements or recording them in an audit log.
+	PlanMiddleware func(next PlanFunc) PlanFunc
+
+	// WrapOption configures a driver wrapped by WrapDriver.
+	WrapOption func(*wrappedDriver)
+
+	// wrappedDriver is a Driver whose Differ and planning
+	// methods are decorated by user-provided middlewares.
+	wrappedDriver struct {
+		Driver
+		differ schema.Differ
+		plan   PlanFunc
+		// planned indicates if plan middlewares were configured.
+		planned bool
+	}
+)
+
+// WithDiffMiddleware appends the given middlewares to the Differ chain of the driver.
+func WithDiffMiddleware(mws ...DiffMiddleware) WrapOption {
+	return func(d *wrappedDriver) {
+		// The first middleware is the outermost one.
+		for i := len(mws) - 1; i >= 0; i-- {
+			d.differ = mws[i](d.differ)
+		}
+	}
+}
+
+// WithPlanMiddleware appends the given middlewares to the planning chain of the driver.
+func WithPlanMiddleware(mws ...PlanMiddleware) WrapOption {
+	return func(d *wrappedDriver) {
+		for i := len(mws) - 1; i >= 0; i-- {
+			d.plan = mws[i](d.plan)
+			d.planned = true
+		}
+	}
+}
+
+// WrapDriver returns a Driver that delegates its diffing and planning to the given driver
+// through the configured middlewares, which allows customizing the behavior of a driver
+// without forking its package. Middlewares are invoked in the order they were given, i.e.,
+// the first one is the outermost. For example:
+//
+//	drv = migrate.WrapDriver(drv,
+//		migrate.WithDiffMiddleware(migrate.FilterChanges(func(c schema.Change) bool {
+//			_, ok := c.(*schema.DropTable)
+//			return !ok
+//		})),
+//		migrate.WithPlanMiddleware(migrate.AuditPlan(func(_ context.Context, p *migrate.Plan) {
+//			log.Printf("planned %d statements", len(p.Changes))
+//		})),
+//	)
+//
+// If plan middlewares were configured, ApplyChanges executes the statements planned by the
+// chain on the driver. Note that, optional interfaces implemented by the underlying driver
+// (e.g., schema.Normalizer) are not exposed by the returned Driver. Use UnwrapDriver to get
+// the underlying driver.
+func WrapDriver(drv Driver, opts ...WrapOption) Driver {
+	d := &wrappedDriver{Driver: drv, differ: drv, plan: drv.PlanChanges}
+	// Options are applied in reverse order to keep
+	// the first-given middleware as the outermost.
+	for i := len(opts) - 1; i >= 0; i-- {
+		opts[i](d)
+	}
+	return d
+}
+
+// UnwrapDriver returns the underlying driver of a driver
+// returned by WrapDriver, or the driver itself otherwise.
+func UnwrapDriver(drv Driver) Driver {
+	for {
+		w, ok := drv.(*wrappedDriver)
+		if !ok {
+			return drv
+		}
+		drv = w.Driver
+	}
+}
+
+// RealmDiff implements the schema.Differ interface.
+func (d *wrappedDriver) RealmDiff(from, to *schema.Realm, opts ...schema.DiffOption) ([]schema.Change, error) {
+	return d.differ.RealmDiff(from, to, opts...)
+}
+
+// SchemaDiff implements the schema.Differ interface.
+func (d *wrappedDriver) SchemaDiff(from, to *schema.Schema, opts ...schema.DiffOption) ([]schema.Change, error) {
+	return d.differ.SchemaDiff(from, to, opts...)
+}
+
+// TableDiff implements the schema.Differ interface.
+func (d *wrappedDriver) TableDiff(from, to *schema.Table, opts ...schema.DiffOption) ([]schema.Change, error) {
+	return d.differ.TableDiff(from, to, opts...)
+}
+
+// PlanChanges implements the PlanApplier interface.
+func (d *wrappedDriver) PlanChanges(ctx context.Context, name string, changes []schema.Change, opts ...PlanOption) (*Plan, error) {
+	return d.plan(ctx, name, changes, opts...)
+}
+
+// ApplyChanges implements the PlanApplier interface.
+func (d *wrappedDriver) ApplyChanges(ctx context.Context, changes []schema.Change, opts ...PlanOption) error {
+	// Without plan middlewares, the driver applies the changes itself.
+	if !d.planned {
+		return d.Driver.ApplyChanges(ctx, changes, opts...)
+	}
+	p, err := d.plan(ctx, "apply", changes, opts...)
+	if err != nil {
+		return err
+	}
+	for _, c := range p.Changes {
+		if _, err := d.ExecContext(ctx, c.Cmd, c.Args...); err != nil {
+			if c.Comment != "" {
+				err = fmt.Errorf("%s: %w", c.Comment, err)
+			}
+			// Point the error to the schema file that caused it, if exists.
+			if pos, ok := c.Pos(); ok {
+				err = fmt.Errorf("%s: %w", pos, err)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// MapChanges returns a DiffMiddleware that passes the changes computed
+// by the next Differ through fn, and returns its result instead.
+func MapChanges(fn func([]schema.Change) ([]schema.Change, error)) DiffMiddleware {
+	return func(next schema.Differ) schema.Differ {
+		return &mapDiffer{next: next, fn: fn}
+	}
+}
+
+// FilterChanges returns a DiffMiddleware that keeps only the changes that satisfy the
+// given predicate. Changes nested in a ModifyTable are filtered as well, and a ModifyTable
+// change is dropped if none of its nested changes were kept.
+func FilterChanges(keep func(schema.Change) bool) DiffMiddleware {
+	var filter func([]schema.Change) []schema.Change
+	filter = func(changes []schema.Change) []schema.Change {
+		kept := make([]schema.Change, 0, len(changes))
+		for _, c := range changes {
+			if !keep(c) {
+				continue
+			}
+			if m, ok := c.(*schema.ModifyTable); ok && len(m.Changes) > 0 {
+				nested := filter(m.Changes)
+				if len(nested) == 0 {
+					continue
+				}
+				c = &schema.ModifyTable{T: m.T, Changes: nested}
+			}
+			kept = append(kept, c)
+		}
+		return kept
+	}
+	return MapChanges(func(changes []schema.Change) ([]schema.Change, error) {
+		return filter(changes), nil
+	})
+}
+
+// FilterLabels returns a DiffMiddleware that keeps only the changes of elements whose labels
+// match the given selector (see schema.MatchLabels). Changes nested in a matched element are
+// kept as is, and the nested changes of other tables are filtered by the labels of their own
+// elements (e.g., labeled columns). For example:
+//
+//	migrate.FilterLabels(map[string]string{"team": "payments"})
+func FilterLabels(selector map[string]string) DiffMiddleware {
+	var filter func([]schema.Change) []schema.Change
+	filter = func(changes []schema.Change) []schema.Change {
+		kept := make([]schema.Change, 0, len(changes))
+		for _, c := range changes {
+			if schema.MatchLabels(schema.ChangeLabels(c), selector) {
+				kept = append(kept, c)
+				continue
+			}
+			if m, ok := c.(*schema.ModifyTable); ok {
+				if nested := filter(m.Changes); len(nested) > 0 {
+					kept = append(kept, &schema.ModifyTable{T: m.T, Changes: nested})
+				}
+			}
+		}
+		return kept
+	}
+	return MapChanges(func(changes []schema.Change) ([]schema.Change, error) {
+		return filter(changes), nil
+	})
+}
+
+// RewriteStmts returns a PlanMiddleware that passes each statement planned by
+// the next PlanFunc through fn, which may modify it in place (e.g., its Cmd,
+// Args or Reverse). A nil Change returned by fn removes it from the plan.
+func RewriteStmts(fn func(*Change) (*Change, error)) PlanMiddleware {
+	return func(next PlanFunc) PlanFunc {
+		return func(ctx context.Context, name string, changes []schema.Change, opts ...PlanOption) (*Plan, error) {
+			p, err := next(ctx, name, changes, opts...)
+			if err != nil {
+				return nil, err
+			}
+			stmts := make([]*Change, 0, len(p.Changes))
+			for _, c := range p.Changes {
+				c, err := fn(c)
+				if err != nil {
+					return nil, err
+				}
+				if c != nil {
+					stmts = append(stmts, c)
+				}
+			}
+			p.Changes = stmts
+			return p, nil
+		}
+	}
+}
+
+// AuditPlan returns a PlanMiddleware that reports each plan created
+// by the next PlanFunc to the given sink, before it is returned.
+func AuditPlan(sink func(context.Context, *Plan)) PlanMiddleware {
+	return func(next PlanFunc) PlanFunc {
+		return func(ctx context.Context, name string, changes []schema.Change, opts ...PlanOption) (*Plan, error) {
+			p, err := next(ctx, name, changes, opts...)
+			if err != nil {
+				return nil, err
+			}
+			sink(ctx, p)
+			return p, nil
+		}
+	}
+}
+
+// mapDiffer is the Differ returned by MapChanges.
+type mapDiffer struct {
+	next schema.Differ
+	fn   func([]schema.Change) ([]schema.Change, error)
+}
+
+// RealmDiff implements the schema.Differ interface.
+func (d *mapDiffer) RealmDiff(from, to *schema.Realm, opts ...schema.DiffOption) ([]schema.Change, error) {
+	return d.mapped(d.next.RealmDiff(from, to, opts...))
+}
+
+// SchemaDiff implements the schema.Differ interface.
+func (d *mapDiffer) SchemaDiff(from, to *schema.Schema, opts ...schema.DiffOption) ([]schema.Change, error) {
+	return d.mapped(d.next.SchemaDiff(from, to, opts...))
+}
+
+// TableDiff implements the schema.Differ interface.
+func (d *mapDiffer) TableDiff(from, to *schema.Table, opts ...schema.DiffOption) ([]schema.Change, error) {
+	return d.mapped(d.next.TableDiff(from, to, opts...))
+}
+
+func (d *mapDiffer) mapped(changes []schema.Change, err error) ([]schema.Change, error) {
+	if err != nil {
+		return nil, err
+	}
+	return d.fn(changes)
+}
@@ -0,0 +1,176 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlite"
+	"ariga.io/atlas/sql/sqltesting"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrapDriver(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		users = schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))
+		from  = schema.NewRealm(schema.New("main").AddTables(users))
+		to    = schema.NewRealm(schema.New("main").AddTables(
+			schema.NewTable("pets").AddColumns(schema.NewIntColumn("id", "int")),
+		))
+		inner   = sqltesting.NewDriver(from, sqltesting.WithDiffer(sqlite.DefaultDiff))
+		audited []*migrate.Plan
+		calls   []string
+		trace   = func(name string) migrate.PlanMiddleware {
+			return func(next migrate.PlanFunc) migrate.PlanFunc {
+				return func(ctx context.Context, n string, changes []schema.Change, opts ...migrate.PlanOption) (*migrate.Plan, error) {
+					calls = append(calls, name)
+					return next(ctx, n, changes, opts...)
+				}
+			}
+		}
+	)
+	drv := migrate.WrapDriver(inner,
+		migrate.WithDiffMiddleware(migrate.FilterChanges(func(c schema.Change) bool {
+			_, ok := c.(*schema.DropTable)
+			return !ok
+		})),
+		migrate.WithPlanMiddleware(
+			trace("first"),
+			migrate.RewriteStmts(func(c *migrate.Change) (*migrate.Change, error) {
+				c.Cmd = "-- " + c.Cmd
+				return c, nil
+			}),
+			migrate.AuditPlan(func(_ context.Context, p *migrate.Plan) {
+				audited = append(audited, p)
+			}),
+		),
+		migrate.WithPlanMiddleware(trace("second")),
+	)
+	require.Equal(t, inner, migrate.UnwrapDriver(drv))
+	require.Equal(t, inner, migrate.UnwrapDriver(inner))
+
+	changes, err := drv.RealmDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.IsType(t, (*schema.AddTable)(nil), changes[0])
+
+	plan, err := drv.PlanChanges(ctx, "plan", changes)
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.True(t, strings.HasPrefix(plan.Changes[0].Cmd, "-- "))
+	require.Equal(t, []*migrate.Plan{plan}, audited)
+	require.Equal(t, []string{"first", "second"}, calls)
+
+	// Statements planned by the chain are executed on the driver.
+	require.NoError(t, drv.ApplyChanges(ctx, changes))
+	require.Len(t, audited, 2)
+	require.Equal(t, "apply", audited[1].Name)
+	require.Equal(t, []string{audited[1].Changes[0].Cmd}, inner.Stmts())
+
+	// Statements can be removed from the plan.
+	drv = migrate.WrapDriver(inner, migrate.WithPlanMiddleware(migrate.RewriteStmts(func(*migrate.Change) (*migrate.Change, error) {
+		return nil, nil
+	})))
+	plan, err = drv.PlanChanges(ctx, "plan", changes)
+	require.NoError(t, err)
+	require.Empty(t, plan.Changes)
+	drv = migrate.WrapDriver(inner, migrate.WithPlanMiddleware(migrate.RewriteStmts(func(*migrate.Change) (*migrate.Change, error) {
+		return nil, errors.New("oops")
+	})))
+	_, err = drv.PlanChanges(ctx, "plan", changes)
+	require.EqualError(t, err, "oops")
+
+	// Without plan middlewares, changes are applied by the driver itself.
+	inner.Reset()
+	drv = migrate.WrapDriver(inner, migrate.WithDiffMiddleware(migrate.MapChanges(func(changes []schema.Change) ([]schema.Change, error) {
+		return nil, errors.New("diff disabled")
+	})))
+	_, err = drv.SchemaDiff(from.Schemas[0], to.Schemas[0])
+	require.EqualError(t, err, "diff disabled")
+	require.NoError(t, drv.ApplyChanges(ctx, changes))
+	require.Len(t, inner.Stmts(), 1)
+	_, ok := inner.Realm().Schemas[0].Table("pets")
+	require.True(t, ok)
+}
+
+func TestFilterChanges(t *testing.T) {
+	var (
+		users   = schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"), schema.NewIntColumn("age", "int"))
+		changes = []schema.Change{
+			&schema.AddTable{T: schema.NewTable("pets")},
+			&schema.ModifyTable{T: users, Changes: []schema.Change{
+				&schema.DropColumn{C: users.Columns[1]},
+				&schema.AddIndex{I: schema.NewIndex("idx").AddColumns(users.Columns[0])},
+			}},
+			&schema.ModifyTable{T: users, Changes: []schema.Change{
+				&schema.DropColumn{C: users.Columns[1]},
+			}},
+		}
+		differ = migrate.FilterChanges(func(c schema.Change) bool {
+			_, ok := c.(*schema.DropColumn)
+			return !ok
+		})(diffFunc(func() []schema.Change { return changes }))
+	)
+	got, err := differ.TableDiff(nil, nil)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	require.Equal(t, changes[0], got[0])
+	require.Equal(t, []schema.Change{changes[1].(*schema.ModifyTable).Changes[1]}, got[1].(*schema.ModifyTable).Changes)
+	// The original changes are not modified.
+	require.Len(t, changes[1].(*schema.ModifyTable).Changes, 2)
+}
+
+func TestFilterLabels(t *testing.T) {
+	var (
+		payments = map[string]string{"team": "payments"}
+		users    = schema.NewTable("users").AddColumns(
+			schema.NewIntColumn("id", "int"),
+			schema.NewIntColumn("balance", "int").SetLabels(payments),
+		)
+		changes = []schema.Change{
+			&schema.AddTable{T: schema.NewTable("pets")},
+			&schema.AddTable{T: schema.NewTable("charges").SetLabels(payments)},
+			&schema.ModifyTable{T: schema.NewTable("refunds").SetLabels(payments), Changes: []schema.Change{
+				&schema.AddColumn{C: schema.NewIntColumn("amount", "int")},
+			}},
+			&schema.ModifyTable{T: users, Changes: []schema.Change{
+				&schema.DropColumn{C: users.Columns[0]},
+				&schema.AddColumn{C: users.Columns[1]},
+			}},
+			&schema.ModifyTable{T: schema.NewTable("logs"), Changes: []schema.Change{
+				&schema.DropColumn{C: users.Columns[0]},
+			}},
+		}
+		differ = migrate.FilterLabels(payments)(diffFunc(func() []schema.Change { return changes }))
+	)
+	got, err := differ.TableDiff(nil, nil)
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+	require.Equal(t, changes[1], got[0])
+	require.Equal(t, changes[2], got[1])
+	require.Equal(t, []schema.Change{changes[3].(*schema.ModifyTable).Changes[1]}, got[2].(*schema.ModifyTable).Changes)
+}
+
+// diffFunc is a Differ that always returns the same changes.
+type diffFunc func() []schema.Change
+
+func (f diffFunc) RealmDiff(_, _ *schema.Realm, _ ...schema.DiffOption) ([]schema.Change, error) {
+	return f(), nil
+}
+
+func (f diffFunc) SchemaDiff(_, _ *schema.Schema, _ ...schema.DiffOption) ([]schema.Change, error) {
+	return f(), nil
+}
+
+func (f diffFunc) TableDiff(_, _ *schema.Table, _ ...schema.DiffOption) ([]schema.Change, error) {
+	return f(), nil
+}
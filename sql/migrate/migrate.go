@@ -10,6 +10,7 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"reflect"
 	"slices"
 	"strings"
 	"time"
@@ -60,6 +61,10 @@ type (
 
 		// The Source that caused this change, or nil.
 		Source schema.Change
+
+		// Simulation holds the result of simulating the change
+		// on the database, if it was simulated. See SimulatePlan.
+		Simulation *Simulation
 	}
 )
 
@@ -84,6 +89,133 @@ func (c *Change) ReverseStmts() (cmd []string, err error) {
 	return
 }
 
+// Pos returns the source position of the schema element that caused
+// this change, if exists. See ChangePos for more details.
+func (c *Change) Pos() (*schema.Pos, bool) {
+	if c.Source == nil {
+		return nil, false
+	}
+	return ChangePos(c.Source)
+}
+
+// ChangePos returns the source position of the schema element that caused the given
+// change, if exists. Positions are attached to schema elements that were loaded from
+// HCL files using the schemahcl.WithPos option. Elements of the desired state are
+// preferred over the current state, and a table modification that holds a single
+// change is resolved to the position of the nested element (e.g., a column).
+func ChangePos(c schema.Change) (*schema.Pos, bool) {
+	var e any
+	switch c := c.(type) {
+	case *schema.AddSchema:
+		e = c.S
+	case *schema.DropSchema:
+		e = c.S
+	case *schema.ModifySchema:
+		e = c.S
+	case *schema.AddTable:
+		e = c.T
+	case *schema.CloneTable:
+		e = c.T
+	case *schema.DropTable:
+		e = c.T
+	case *schema.ModifyTable:
+		if len(c.Changes) == 1 {
+			if p, ok := ChangePos(c.Changes[0]); ok {
+				return p, true
+			}
+		}
+		e = c.T
+	case *schema.RenameTable:
+		e = c.To
+	case *schema.AddView:
+		e = c.V
+	case *schema.DropView:
+		e = c.V
+	case *schema.ModifyView:
+		e = c.To
+	case *schema.RenameView:
+		e = c.To
+	case *schema.AddFunc:
+		e = c.F
+	case *schema.DropFunc:
+		e = c.F
+	case *schema.ModifyFunc:
+		e = c.To
+	case *schema.RenameFunc:
+		e = c.To
+	case *schema.AddProc:
+		e = c.P
+	case *schema.DropProc:
+		e = c.P
+	case *schema.ModifyProc:
+		e = c.To
+	case *schema.RenameProc:
+		e = c.To
+	case *schema.AddTrigger:
+		e = c.T
+	case *schema.DropTrigger:
+		e = c.T
+	case *schema.ModifyTrigger:
+		e = c.To
+	case *schema.RenameTrigger:
+		e = c.To
+	case *schema.AddObject:
+		e = c.O
+	case *schema.DropObject:
+		e = c.O
+	case *schema.ModifyObject:
+		e = c.To
+	case *schema.RenameObject:
+		e = c.To
+	case *schema.AddColumn:
+		e = c.C
+	case *schema.DropColumn:
+		e = c.C
+	case *schema.ModifyColumn:
+		e = c.To
+	case *schema.RenameColumn:
+		e = c.To
+	case *schema.AddIndex:
+		e = c.I
+	case *schema.DropIndex:
+		e = c.I
+	case *schema.ModifyIndex:
+		e = c.To
+	case *schema.RenameIndex:
+		e = c.To
+	case *schema.AddPrimaryKey:
+		e = c.P
+	case *schema.DropPrimaryKey:
+		e = c.P
+	case *schema.ModifyPrimaryKey:
+		e = c.To
+	case *schema.AddForeignKey:
+		e = c.F
+	case *schema.DropForeignKey:
+		e = c.F
+	case *schema.ModifyForeignKey:
+		e = c.To
+	case *schema.AddCheck:
+		e = c.C
+	case *schema.DropCheck:
+		e = c.C
+	case *schema.ModifyCheck:
+		e = c.To
+	case *schema.RenameConstraint:
+		e = c.To
+	}
+	if p, ok := e.(interface{ Pos() (*schema.Pos, bool) }); ok && !isNil(p) {
+		return p.Pos()
+	}
+	return nil, false
+}
+
+// isNil reports if the given interface holds a nil pointer.
+func isNil(v any) bool {
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Ptr && rv.IsNil()
+}
+
 type (
 	// The Driver interface must be implemented by the different dialects to support database
 	// migration authoring/planning and applying. ExecQuerier, Inspector and Differ, provide
@@ -112,6 +244,16 @@ type (
 		ApplyChanges(context.Context, []schema.Change, ...PlanOption) error
 	}
 
+	// LiteralFormatter is an optional interface implemented by drivers that can
+	// format Go values as SQL literals of their dialect. It allows building safe
+	// statements with inlined values (e.g., seed data or backfills) without
+	// re-implementing the quoting rules of each database.
+	LiteralFormatter interface {
+		// FormatLiteral formats the given value as an SQL literal. An error
+		// is returned if the value is not supported by the dialect.
+		FormatLiteral(v any) (string, error)
+	}
+
 	// PlanOptions holds the migration plan options to be used by PlanApplier.
 	PlanOptions struct {
 		// PlanWithSchemaQualifier allows setting a custom schema to prefix
@@ -124,11 +266,41 @@ type (
 		// This is useful to indicate to the driver whether the context is a live database, an empty one, or the
 		// versioned migration workflow.
 		Mode PlanMode
+		// Quoting controls how identifiers are written in the generated
+		// statements. If not specified, identifiers are always quoted.
+		Quoting IdentQuoting
+		// Order controls how the planned statements are ordered. Note, the ordering
+		// policy is applied on top of the dependency sorting, and therefore, does not
+		// break the order required by the database. If not specified, the driver picks
+		// its default, which groups statements by table.
+		Order PlanOrder
+		// NoMergeAlters disables the merging of adjacent column changes of the same
+		// table into a single ALTER TABLE statement, in dialects that support it.
+		NoMergeAlters bool
+		// IfNotExists indicates the planned creations of objects should not fail if the object
+		// already exists, as if all AddObject changes were given the schema.IfNotExists clause.
+		// Dialects that do not support the clause for a statement guard it differently. e.g.,
+		// PostgreSQL wraps the creation of types and roles with an anonymous code block (DO).
+		IfNotExists bool
+		// Parallel is the maximum number of statements ApplyChanges executes concurrently.
+		// Only the statements of table creations and modifications that do not depend on
+		// each other (e.g., indexes created on different tables) run concurrently, and all
+		// other statements are executed after the statements planned before them. Note,
+		// the driver must be opened on a connection pool (e.g., *sql.DB) to benefit from
+		// it, and statements are not executed on the same session. Zero or one means the
+		// statements are executed sequentially.
+		Parallel int
 	}
 
 	// PlanMode defines the plan mode to use.
 	PlanMode uint8
 
+	// IdentQuoting defines the strategy for writing identifiers in planned statements.
+	IdentQuoting uint8
+
+	// PlanOrder defines the ordering policy of planned statements.
+	PlanOrder uint8
+
 	// PlanOption allows configuring a drivers' plan using functional arguments.
 	PlanOption func(*PlanOptions)
 
@@ -163,6 +335,28 @@ func (m PlanMode) Is(m1 PlanMode) bool {
 	return m == m1 || m&m1 != 0
 }
 
+// List of identifier quoting strategies.
+const (
+	QuoteAlways     IdentQuoting = iota // Identifiers are always quoted (default).
+	QuoteWhenNeeded                     // Identifiers are quoted only if they are not lowercase, or are reserved words.
+	FoldLower                           // Identifiers are folded to lowercase, and quoted only if they are reserved words or contain special characters.
+	FoldUpper                           // Identifiers are folded to uppercase, and quoted only if they are reserved words or contain special characters.
+)
+
+// List of plan ordering policies. Policies can be combined, for
+// example: PlanOrderByKind|PlanOrderIndexesLast.
+const (
+	PlanOrderDefault     PlanOrder = 0               // Driver default (grouped by table).
+	PlanOrderByKind      PlanOrder = 1 << (iota - 1) // Changes are grouped by their kind. e.g., all table creations first.
+	PlanOrderIndexesLast                             // Index creations are postponed to the end of the plan.
+	PlanOrderFKsLast                                 // Foreign-key creations are postponed to the end of the plan.
+)
+
+// Is reports whether the ordering policy o contains the given policy.
+func (o PlanOrder) Is(o1 PlanOrder) bool {
+	return o&o1 != 0
+}
+
 // ErrNoPlan is returned by Plan when there is no change between the two states.
 var ErrNoPlan = errors.New("sql/migrate: no plan for matched states")
 
@@ -207,13 +401,18 @@ type (
 	// Planner can plan the steps to take to migrate from one state to another. It uses the enclosed Dir to
 	// those changes to versioned migration files.
 	Planner struct {
-		drv      Driver              // driver to use
-		dir      Dir                 // where migration files are stored and read from
-		fmt      Formatter           // how to format a plan to migration files
-		sum      bool                // whether to create a sum file for the migration directory
-		exclude  []string            // exclude resources from planning that match the patterns
-		planOpts []PlanOption        // plan options
-		diffOpts []schema.DiffOption // diff options
+		drv           Driver                 // driver to use
+		dir           Dir                    // where migration files are stored and read from
+		fmt           Formatter              // how to format a plan to migration files
+		sum           bool                   // whether to create a sum file for the migration directory
+		exclude       []string               // exclude resources from planning that match the patterns
+		planOpts      []PlanOption           // plan options
+		diffOpts      []schema.DiffOption    // diff options
+		expand        bool                   // expand/contract planning mode
+		backfillUsing func(*Backfill) string // expressions of the backfilled columns
+		simulate      bool                   // simulate the planned changes
+		now           func() time.Time       // clock used for versioning plans
+		seed          string                 // seed for names generated by the planner
 	}
 
 	// PlannerOption allows managing a Planner using functional arguments.
@@ -263,6 +462,10 @@ type (
 		baselineVer string             // Start the first migration after the given baseline version.
 		allowDirty  bool               // Allow start working on a non-clean database.
 		operator    string             // Revision.OperatorVersion
+		publishers  []Publisher        // Publishers to notify on successful execution.
+		artifacts   ArtifactStore      // Store to write the apply artifacts to.
+		throttle    *Throttle          // Throttle the execution of statements.
+		budget      *TimeBudget        // Abort executions that exceed their time budget.
 	}
 
 	// ExecutorOption allows configuring an Executor using functional arguments.
@@ -359,6 +562,49 @@ func PlanWithMode(m PlanMode) PlannerOption {
 	}
 }
 
+// PlanWithIdentQuoting allows setting the strategy for writing identifiers in
+// the generated statements. For example, using QuoteWhenNeeded, "users" is written
+// as users, and "Users" or "order" are written quoted.
+func PlanWithIdentQuoting(q IdentQuoting) PlannerOption {
+	return func(p *Planner) {
+		p.planOpts = append(p.planOpts, func(o *PlanOptions) {
+			o.Quoting = q
+		})
+	}
+}
+
+// PlanWithOrder allows setting the ordering policy of the planned
+// statements. For example, PlanOrderIndexesLast postpones index creation
+// to the end of the plan, after all tables were created or altered.
+func PlanWithOrder(o PlanOrder) PlannerOption {
+	return func(p *Planner) {
+		p.planOpts = append(p.planOpts, func(opts *PlanOptions) {
+			opts.Order = o
+		})
+	}
+}
+
+// PlanWithMergeAlters allows disabling (or enabling) the merging of adjacent column
+// changes of the same table into a single ALTER TABLE statement. Merging is enabled
+// by default in dialects that support it.
+func PlanWithMergeAlters(b bool) PlannerOption {
+	return func(p *Planner) {
+		p.planOpts = append(p.planOpts, func(opts *PlanOptions) {
+			opts.NoMergeAlters = !b
+		})
+	}
+}
+
+// PlanWithIfNotExists allows planning object creations that do not fail if
+// the objects already exist, in dialects that support it. See PlanOptions.
+func PlanWithIfNotExists(b bool) PlannerOption {
+	return func(p *Planner) {
+		p.planOpts = append(p.planOpts, func(opts *PlanOptions) {
+			opts.IfNotExists = b
+		})
+	}
+}
+
 // PlanWithDiffOptions allows setting custom diff options.
 func PlanWithDiffOptions(opts ...schema.DiffOption) PlannerOption {
 	return func(p *Planner) {
@@ -381,6 +627,28 @@ func PlanWithChecksum(b bool) PlannerOption {
 	}
 }
 
+// PlanWithClock sets the clock used for generating the versions of the planned migration
+// files. By default, plans are versioned by the formatter using the current time. Setting
+// a fixed clock (e.g., derived from SOURCE_DATE_EPOCH) makes the versions, and therefore,
+// the names of the planned files reproducible. See PlanWithNameSeed for the names that are
+// generated in the planned statements.
+func PlanWithClock(now func() time.Time) PlannerOption {
+	return func(p *Planner) {
+		p.now = now
+	}
+}
+
+// PlanWithNameSeed sets the seed for the names generated by the planner, such as the names
+// of the temporary columns in the expand/contract mode. By default, temporary columns are
+// suffixed with "_new" and "_old", which might collide with existing columns. If a seed is
+// set, the suffixes are followed by a short hash of the seed and the column, and planning
+// identical inputs with the same seed (and clock) produces byte-identical migration files.
+func PlanWithNameSeed(seed string) PlannerOption {
+	return func(p *Planner) {
+		p.seed = seed
+	}
+}
+
 // PlanWithExclude allows setting exclude patterns for the planner.
 // Resources that match the patterns are excluded from planning.
 func PlanWithExclude(patterns ...string) PlannerOption {
@@ -411,6 +679,45 @@ func (p *Planner) PlanSchema(ctx context.Context, name string, to StateReader) (
 }
 
 func (p *Planner) plan(ctx context.Context, name string, to StateReader, realmScope bool) (*Plan, error) {
+	changes, err := p.changes(ctx, to, realmScope)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := p.drv.PlanChanges(ctx, name, changes, p.planOpts...)
+	if err != nil {
+		return nil, err
+	}
+	if p.simulate {
+		if err := p.simulatePlan(ctx, plan); err != nil {
+			return nil, err
+		}
+	}
+	return p.versioned(plan), nil
+}
+
+// versioned sets the version of the plan from the clock of the planner, if it was set.
+func (p *Planner) versioned(plan *Plan) *Plan {
+	if p.now != nil && plan.Version == "" {
+		plan.Version = p.version()
+	}
+	return plan
+}
+
+// version returns a new migration version from the clock of the planner.
+func (p *Planner) version() string {
+	return p.clock().Format(versionFormat)
+}
+
+// clock returns the current UTC time from the clock of the planner.
+func (p *Planner) clock() time.Time {
+	if p.now == nil {
+		return time.Now().UTC()
+	}
+	return p.now().UTC()
+}
+
+// changes returns the changes between the current state and the desired state.
+func (p *Planner) changes(ctx context.Context, to StateReader, realmScope bool) ([]schema.Change, error) {
 	current, err := p.current(ctx, realmScope)
 	if err != nil {
 		return nil, err
@@ -449,7 +756,7 @@ func (p *Planner) plan(ctx context.Context, name string, to StateReader, realmSc
 	if len(changes) == 0 {
 		return nil, ErrNoPlan
 	}
-	return p.drv.PlanChanges(ctx, name, changes, p.planOpts...)
+	return changes, nil
 }
 
 // Checkpoint calculate the current state of the migration directory by executing its files,
@@ -490,9 +797,13 @@ func (p *Planner) checkpoint(ctx context.Context, name string, realmScope bool)
 	}
 	// No changes mean an empty checkpoint.
 	if len(changes) == 0 {
-		return &Plan{Name: name}, nil
+		return p.versioned(&Plan{Name: name}), nil
 	}
-	return p.drv.PlanChanges(ctx, name, changes, p.planOpts...)
+	plan, err := p.drv.PlanChanges(ctx, name, changes, p.planOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return p.versioned(plan), nil
 }
 
 // current returns the current realm state.
@@ -670,6 +981,15 @@ func WithOperatorVersion(v string) ExecutorOption {
 	}
 }
 
+// WithPublishers sets the Publishers to notify with the
+// executed migration files on successful execution.
+func WithPublishers(p ...Publisher) ExecutorOption {
+	return func(ex *Executor) error {
+		ex.publishers = append(ex.publishers, p...)
+		return nil
+	}
+}
+
 // Pending returns all pending (not fully applied) migration files in the migration directory.
 func (e *Executor) Pending(ctx context.Context) ([]File, error) {
 	// Don't operate with a broken migration directory.
@@ -807,16 +1127,9 @@ func (e *Executor) Execute(ctx context.Context, m File) (err error) {
 		e.log.Log(LogError{Error: err})
 		return err
 	}
-	// Create checksums for the statements.
-	var (
-		sums = make([]string, len(stmts))
-		h    = sha256.New()
-	)
-	for i, stmt := range stmts {
-		if _, err := h.Write([]byte(stmt.Text)); err != nil {
-			return err
-		}
-		sums[i] = base64.StdEncoding.EncodeToString(h.Sum(nil))
+	sums, err := stmtSums(stmts)
+	if err != nil {
+		return err
 	}
 	version := m.Version()
 	// If there already is a revision with this version in the database,
@@ -841,13 +1154,15 @@ func (e *Executor) Execute(ctx context.Context, m File) (err error) {
 		return err
 	}
 	// Make sure to store the Revision information, if it did not fail before.
+	// The write is not canceled with the context, to keep the progress of
+	// interrupted executions.
 	defer func(ctx context.Context, e *Executor, r *Revision) {
 		if !errors.As(err, new(*WriteRevisionError)) {
 			if err2 := e.writeRevision(ctx, r); err2 != nil {
 				err = errors.Join(err, err2)
 			}
 		}
-	}(ctx, e, r)
+	}(context.WithoutCancel(ctx), e, r)
 	if r.Applied > 0 {
 		// If the file has been applied partially before, check if the
 		// applied statements have not changed.
@@ -866,9 +1181,39 @@ func (e *Executor) Execute(ctx context.Context, m File) (err error) {
 		r.Error = err.Error()
 		return err
 	}
+	// Statements are canceled once the time budget is exceeded, if configured.
+	if e.budget.begin() {
+		defer e.budget.end()
+	}
+	bctx, cancel := e.budget.context(ctx)
+	defer cancel()
+	aborted := func(stmt *Stmt, interrupted bool) error {
+		err := e.budget.exceededError(r, stmt, interrupted)
+		e.log.Log(LogError{SQL: stmt.Text, Stmt: stmt, Error: err})
+		r.done()
+		r.ErrorStmt = stmt.Text
+		r.Error = err.Error()
+		return err
+	}
 	for _, stmt := range stmts[r.Applied:] {
+		if e.budget.exceeded() {
+			return aborted(stmt, false)
+		}
+		if err = e.throttle.wait(bctx); err != nil {
+			if e.budget.exceeded() {
+				return aborted(stmt, false)
+			}
+			e.log.Log(LogError{Error: err})
+			return err
+		}
+		start := time.Now()
 		e.log.Log(LogStmt{SQL: stmt.Text, Stmt: stmt})
-		if _, err = e.drv.ExecContext(ctx, stmt.Text); err != nil {
+		_, err = e.drv.ExecContext(bctx, stmt.Text)
+		e.throttle.done(stmt, start)
+		if err != nil {
+			if e.budget.exceeded() {
+				return aborted(stmt, true)
+			}
 			e.log.Log(LogError{SQL: stmt.Text, Stmt: stmt, Error: err})
 			r.done()
 			r.ErrorStmt = stmt.Text
@@ -894,6 +1239,22 @@ func (e *Executor) Execute(ctx context.Context, m File) (err error) {
 	return
 }
 
+// stmtSums creates the checksums for the given statements. Note that each
+// checksum is computed on the statement and all statements that precede it.
+func stmtSums(stmts []*Stmt) ([]string, error) {
+	var (
+		sums = make([]string, len(stmts))
+		h    = sha256.New()
+	)
+	for i, stmt := range stmts {
+		if _, err := h.Write([]byte(stmt.Text)); err != nil {
+			return nil, err
+		}
+		sums[i] = base64.StdEncoding.EncodeToString(h.Sum(nil))
+	}
+	return sums, nil
+}
+
 func (e *Executor) writeRevision(ctx context.Context, r *Revision) error {
 	r.ExecutedAt = time.Now()
 	r.OperatorVersion = e.operator
@@ -1030,19 +1391,60 @@ func (e *Executor) ExecuteFiles(ctx context.Context, files []File) error {
 	}
 }
 
-func (e *Executor) exec(ctx context.Context, files []File) error {
+func (e *Executor) exec(ctx context.Context, files []File) (err error) {
+	if e.artifacts != nil {
+		write := e.recordArtifact()
+		defer func() { err = errors.Join(err, write(ctx, err)) }()
+	}
 	revs, err := e.rrw.ReadRevisions(ctx)
 	if err != nil {
 		return fmt.Errorf("sql/migrate: read revisions: %w", err)
 	}
 	LogIntro(e.log, revs, files)
-	for _, m := range files {
+	if e.budget.begin() {
+		defer e.budget.end()
+	}
+	// The revisions before the run, to reset them if the run is aborted and rolled back.
+	prev := make(map[string]*Revision, len(revs))
+	for _, r := range revs {
+		c := *r
+		c.PartialHashes = slices.Clone(r.PartialHashes)
+		prev[r.Version] = &c
+	}
+	for i, m := range files {
 		if err := e.Execute(ctx, m); err != nil {
+			if be := (*BudgetExceededError)(nil); errors.As(err, &be) {
+				err = errors.Join(err, e.budget.abort(ctx, be, func(ctx context.Context) error {
+					return e.resetRevisions(ctx, prev, files[:i+1])
+				}))
+			}
 			return err
 		}
 	}
+	// The files were executed and committed at this stage,
+	// and therefore, publish errors do not fail the execution.
+	if err := e.publish(ctx, files); err != nil {
+		e.log.Log(LogPublishError{Error: err})
+	}
 	e.log.Log(LogDone{})
-	return err
+	return nil
+}
+
+// resetRevisions resets the revisions of the given files to their previous state,
+// or deletes them if they did not exist before.
+func (e *Executor) resetRevisions(ctx context.Context, prev map[string]*Revision, files []File) error {
+	for _, f := range files {
+		var err error
+		if r, ok := prev[f.Version()]; ok {
+			err = e.rrw.WriteRevision(ctx, r)
+		} else {
+			err = e.rrw.DeleteRevision(ctx, f.Version())
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 type (
@@ -1227,6 +1629,13 @@ type (
 		Error error
 	}
 
+	// LogPublishError is sent if the executed files could not be reported to the
+	// configured publishers. Unlike LogError, it does not fail the execution, as
+	// the files were already executed and their revisions were committed.
+	LogPublishError struct {
+		Error error
+	}
+
 	// LogChecks is sent before the execution of a group of check statements.
 	LogChecks struct {
 		Name  string   // Optional name.
@@ -1251,14 +1660,15 @@ type (
 	NopLogger struct{}
 )
 
-func (LogExecution) logEntry()  {}
-func (LogFile) logEntry()       {}
-func (LogStmt) logEntry()       {}
-func (LogCheck) logEntry()      {}
-func (LogChecks) logEntry()     {}
-func (LogChecksDone) logEntry() {}
-func (LogDone) logEntry()       {}
-func (LogError) logEntry()      {}
+func (LogExecution) logEntry()    {}
+func (LogFile) logEntry()         {}
+func (LogStmt) logEntry()         {}
+func (LogCheck) logEntry()        {}
+func (LogChecks) logEntry()       {}
+func (LogChecksDone) logEntry()   {}
+func (LogDone) logEntry()         {}
+func (LogError) logEntry()        {}
+func (LogPublishError) logEntry() {}
 
 // Log implements the Logger interface.
 func (NopLogger) Log(LogEntry) {}
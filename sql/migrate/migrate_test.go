@@ -5,12 +5,18 @@
 package migrate_test
 
 import (
+	"cmp"
 	"context"
 	"database/sql"
 	_ "embed"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io/fs"
+	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"text/template"
 	"time"
@@ -40,6 +46,78 @@ func TestRevisionType_MarshalText(t *testing.T) {
 	}
 }
 
+func TestRegistry(t *testing.T) {
+	t.Cleanup(schema.ResetRegistry)
+	_, err := migrate.Registry().ReadState(context.Background())
+	require.ErrorIs(t, err, migrate.ErrEmptyRegistry)
+	schema.Register(schema.New("app").AddTables(schema.NewTable("users")))
+	r, err := migrate.Registry().ReadState(context.Background())
+	require.NoError(t, err)
+	require.Len(t, r.Schemas, 1)
+	require.Equal(t, "app", r.Schemas[0].Name)
+}
+
+func TestMergeStates(t *testing.T) {
+	base := func() *schema.Realm {
+		users := schema.NewTable("users").
+			AddColumns(
+				schema.NewIntColumn("id", "int"),
+				schema.NewStringColumn("name", "text"),
+			)
+		users.SetPrimaryKey(schema.NewPrimaryKey(users.Columns[0]))
+		return schema.NewRealm(schema.New("public").AddTables(users))
+	}
+	ext := func() *schema.Realm {
+		users := schema.NewTable("users").
+			AddColumns(schema.NewStringColumn("email", "text"))
+		users.AddIndexes(schema.NewUniqueIndex("users_email").AddColumns(users.Columns[0]))
+		posts := schema.NewTable("posts").
+			AddColumns(
+				schema.NewIntColumn("id", "int"),
+				schema.NewIntColumn("author_id", "int"),
+			)
+		// References a table that is defined by another source.
+		ref := schema.NewTable("users").SetSchema(schema.New("public")).AddColumns(schema.NewIntColumn("id", "int"))
+		posts.AddForeignKeys(schema.NewForeignKey("author").AddColumns(posts.Columns[1]).SetRefTable(ref).AddRefColumns(ref.Columns[0]))
+		return schema.NewRealm(schema.New("public").AddTables(users, posts))
+	}
+	r, err := migrate.MergeStates(migrate.Realm(base()), migrate.Realm(ext())).ReadState(context.Background())
+	require.NoError(t, err)
+	require.Len(t, r.Schemas, 1)
+	require.Len(t, r.Schemas[0].Tables, 2)
+	users, posts := r.Schemas[0].Tables[0], r.Schemas[0].Tables[1]
+	require.Len(t, users.Columns, 3)
+	require.Equal(t, "email", users.Columns[2].Name)
+	require.Equal(t, users, users.Indexes[0].Table)
+	require.Equal(t, users.Columns[2], users.Indexes[0].Parts[0].C)
+	require.Equal(t, users, posts.ForeignKeys[0].RefTable)
+	require.Equal(t, users.Columns[0], posts.ForeignKeys[0].RefColumns[0])
+	require.Equal(t, r.Schemas[0], posts.Schema)
+
+	// Conflicting definitions.
+	_, err = migrate.MergeStates(migrate.Realm(base()), migrate.Realm(base())).ReadState(context.Background())
+	require.EqualError(t, err, `sql/migrate: column "public.users.id" is defined by state sources 0 and 1`)
+	var cerr *migrate.MergeConflictError
+	require.ErrorAs(t, err, &cerr)
+	require.Equal(t, [2]int{0, 1}, cerr.Sources)
+
+	// Later definitions override earlier ones.
+	over := schema.NewRealm(schema.New("public").AddTables(
+		schema.NewTable("users").AddColumns(schema.NewIntColumn("name", "int")),
+	))
+	r, err = migrate.OverrideStates(migrate.Realm(base()), migrate.Realm(over)).ReadState(context.Background())
+	require.NoError(t, err)
+	users = r.Schemas[0].Tables[0]
+	require.Len(t, users.Columns, 2)
+	require.Equal(t, over.Schemas[0].Tables[0].Columns[0], users.Columns[1])
+
+	// Read errors.
+	_, err = migrate.MergeStates(migrate.Realm(base()), migrate.StateReaderFunc(func(context.Context) (*schema.Realm, error) {
+		return nil, errors.New("oops")
+	})).ReadState(context.Background())
+	require.EqualError(t, err, "sql/migrate: read state source 1: oops")
+}
+
 func TestPlanner_WritePlan(t *testing.T) {
 	p := t.TempDir()
 	d, err := migrate.NewLocalDir(p)
@@ -85,6 +163,337 @@ func TestPlanner_WritePlan(t *testing.T) {
 	requireFileEqual(t, d, v+"_add_t1_and_t2.sql", "-- atlas:delimiter \\nGO\n\nCREATE TABLE t1(c int)\nGO\nCREATE TABLE t2(c int)\nGO\n")
 }
 
+func TestFilterPlan(t *testing.T) {
+	var (
+		t1, t2, t3 = &schema.AddTable{T: schema.NewTable("t1")}, &schema.AddTable{T: schema.NewTable("t2")}, &schema.AddTable{T: schema.NewTable("t3")}
+		v1         = &schema.AddView{V: schema.NewView("v1", "SELECT * FROM t3")}
+		plan       = &migrate.Plan{
+			Name: "plan",
+			Changes: []*migrate.Change{
+				{Cmd: "SET x = 1"},
+				{Cmd: "CREATE TABLE t1", Source: t1, Reverse: "DROP TABLE t1"},
+				{Cmd: "CREATE TABLE t2", Source: t2},
+				{Cmd: "CREATE TABLE t3", Source: t3, Reverse: "DROP TABLE t3"},
+				{Cmd: "CREATE VIEW v1", Source: v1, Reverse: "DROP VIEW v1"},
+			},
+		}
+		// v1 depends on t3, and t3 depends on t1.
+		deps = dependerFunc(func(c, o schema.Change) bool {
+			return c == v1 && o == t3 || c == t3 && o == t1
+		})
+	)
+	filtered, err := migrate.FilterPlan(plan, deps, migrate.TargetView("", "v1"))
+	require.NoError(t, err)
+	require.Equal(t, []*migrate.Change{plan.Changes[0], plan.Changes[1], plan.Changes[3], plan.Changes[4]}, filtered.Changes)
+	require.False(t, filtered.Reversible, "session statement is not reversible")
+
+	filtered, err = migrate.FilterPlan(plan, deps, migrate.TargetTable("", "t2"))
+	require.NoError(t, err)
+	require.Equal(t, []*migrate.Change{plan.Changes[0], plan.Changes[2]}, filtered.Changes)
+
+	filtered, err = migrate.FilterPlan(plan, deps, migrate.TargetTable("", "t4"))
+	require.NoError(t, err)
+	require.Empty(t, filtered.Changes)
+	require.Equal(t, "plan", filtered.Name)
+}
+
+func TestPlan_Edit(t *testing.T) {
+	var (
+		t1, t2 = &schema.AddTable{T: schema.NewTable("t1")}, &schema.AddTable{T: schema.NewTable("t2")}
+		c1, c2 = &migrate.Change{Cmd: "CREATE TABLE t1", Source: t1, Reverse: "DROP TABLE t1"}, &migrate.Change{Cmd: "CREATE TABLE t2", Source: t2, Reverse: "DROP TABLE t2"}
+		i1     = &migrate.Change{Cmd: "CREATE INDEX i1 ON t1", Source: t1, Reverse: "DROP INDEX i1"}
+		plan   = &migrate.Plan{Reversible: true, Changes: []*migrate.Change{c1, i1, c2}}
+		// t2 depends on t1.
+		deps = dependerFunc(func(c, o schema.Change) bool { return c == t2 && o == t1 })
+	)
+	// Dependencies are validated.
+	err := plan.MoveChange(2, 0, deps)
+	var depErr *migrate.DependencyError
+	require.ErrorAs(t, err, &depErr)
+	require.Equal(t, c2, depErr.Change)
+	require.Equal(t, c1, depErr.DependsOn)
+	require.EqualError(t, err, `sql/migrate: change "CREATE TABLE t2" depends on change "CREATE TABLE t1"`)
+	require.EqualError(t, plan.MoveChange(1, 0, deps), `sql/migrate: change "CREATE INDEX i1 ON t1" cannot be reordered with other statements of its source`)
+	require.Equal(t, []*migrate.Change{c1, i1, c2}, plan.Changes)
+	require.EqualError(t, plan.MoveChange(3, 0, deps), "sql/migrate: change index 3 out of range [0, 2]")
+	// A source depends on all of its statements.
+	require.ErrorAs(t, plan.MoveChange(2, 1, deps), &depErr)
+	require.Equal(t, i1, depErr.DependsOn)
+
+	// Manual statements.
+	require.EqualError(t, plan.InsertChange(0, &migrate.Change{}, nil), "sql/migrate: cannot insert a change without a statement")
+	require.ErrorAs(t, plan.InsertChange(0, &migrate.Change{Cmd: "CREATE TABLE t2", Source: t2}, deps), &depErr)
+	require.NoError(t, plan.InsertChange(3, &migrate.Change{Cmd: "ANALYZE t1"}, nil))
+	require.False(t, plan.Reversible)
+	require.NoError(t, plan.MoveChange(3, 2, nil))
+	require.NoError(t, plan.AnnotateChange(2, "refresh statistics"))
+	require.Equal(t, "ANALYZE t1", plan.Changes[2].Cmd)
+	require.Equal(t, "refresh statistics", plan.Changes[2].Comment)
+
+	// The last statement of a source cannot be removed if others depend on it.
+	require.NoError(t, plan.RemoveChange(2, nil))
+	require.True(t, plan.Reversible)
+	require.NoError(t, plan.RemoveChange(1, deps))
+	require.ErrorAs(t, plan.RemoveChange(0, deps), &depErr)
+	require.Equal(t, c1, depErr.DependsOn)
+	require.NoError(t, plan.RemoveChange(1, deps))
+	require.NoError(t, plan.RemoveChange(0, deps))
+	require.Empty(t, plan.Changes)
+
+	// Plans are left unchanged if their reversibility cannot be computed.
+	plan = &migrate.Plan{Reversible: true, Changes: []*migrate.Change{c1}}
+	require.EqualError(t, plan.InsertChange(1, &migrate.Change{Cmd: "ANALYZE t1", Reverse: 1}, nil), "sql/migrate: unexpected type int for reverse commands")
+	require.Equal(t, []*migrate.Change{c1}, plan.Changes)
+	require.True(t, plan.Reversible)
+	plan = &migrate.Plan{Reversible: true, Changes: []*migrate.Change{{Cmd: "ANALYZE t1", Reverse: 1}, c1}}
+	require.Error(t, plan.RemoveChange(1, deps))
+	require.Len(t, plan.Changes, 2)
+	require.True(t, plan.Reversible)
+}
+
+func TestChangePos(t *testing.T) {
+	var (
+		tpos  = schema.NewFilePos("schema.hcl").SetStart(struct{ Line, Column, Byte int }{Line: 1, Column: 1})
+		cpos  = schema.NewFilePos("schema.hcl").SetStart(struct{ Line, Column, Byte int }{Line: 3, Column: 3})
+		c1    = schema.NewIntColumn("c1", "int")
+		c2    = schema.NewIntColumn("c2", "int").AddAttrs(cpos)
+		users = schema.NewTable("users").AddColumns(c1, c2).AddAttrs(tpos)
+	)
+	_, ok := (&migrate.Change{Cmd: "SET x = 1"}).Pos()
+	require.False(t, ok)
+	_, ok = migrate.ChangePos(&schema.DropTable{T: schema.NewTable("pets")})
+	require.False(t, ok)
+	_, ok = migrate.ChangePos(&schema.ModifyObject{})
+	require.False(t, ok)
+
+	p, ok := (&migrate.Change{Source: &schema.AddTable{T: users}}).Pos()
+	require.True(t, ok)
+	require.Equal(t, tpos, p)
+	require.Equal(t, "schema.hcl:1:1", p.String())
+	// A single nested change is resolved to its element.
+	p, ok = migrate.ChangePos(&schema.ModifyTable{T: users, Changes: []schema.Change{&schema.AddColumn{C: c2}}})
+	require.True(t, ok)
+	require.Equal(t, cpos, p)
+	p, ok = migrate.ChangePos(&schema.ModifyTable{T: users, Changes: []schema.Change{&schema.AddColumn{C: c1}}})
+	require.True(t, ok)
+	require.Equal(t, tpos, p)
+	p, ok = migrate.ChangePos(&schema.ModifyTable{T: users, Changes: []schema.Change{&schema.AddColumn{C: c1}, &schema.AddColumn{C: c2}}})
+	require.True(t, ok)
+	require.Equal(t, tpos, p)
+	p, ok = migrate.ChangePos(&schema.ModifyColumn{From: c1, To: c2})
+	require.True(t, ok)
+	require.Equal(t, cpos, p)
+}
+
+type dependerFunc func(schema.Change, schema.Change) bool
+
+func (f dependerFunc) DependsOn(c, o schema.Change) bool { return f(c, o) }
+
+func TestExpandContract(t *testing.T) {
+	var (
+		users = schema.NewTable("users")
+		name  = schema.NewStringColumn("name", "text")
+		age   = schema.NewIntColumn("age", "int")
+		bio   = schema.NewStringColumn("bio", "text")
+		posts = schema.NewTable("posts")
+	)
+	phases := migrate.ExpandContract([]schema.Change{
+		&schema.AddTable{T: schema.NewTable("tags")},
+		&schema.ModifyTable{
+			T: users,
+			Changes: []schema.Change{
+				&schema.AddIndex{I: schema.NewIndex("idx")},
+				&schema.RenameColumn{From: name, To: schema.NewStringColumn("full_name", "text")},
+				&schema.ModifyColumn{From: age, To: schema.NewIntColumn("age", "bigint"), Change: schema.ChangeType},
+				&schema.DropColumn{C: bio},
+			},
+		},
+		&schema.DropTable{T: posts},
+	})
+	require.Len(t, phases, 4)
+	require.Equal(t, migrate.PhaseExpand, phases[0].Phase)
+	require.Len(t, phases[0].Changes, 2)
+	expand := phases[0].Changes[1].(*schema.ModifyTable).Changes
+	require.IsType(t, &schema.AddIndex{}, expand[0])
+	require.Equal(t, "full_name", expand[1].(*schema.AddColumn).C.Name)
+	require.True(t, expand[1].(*schema.AddColumn).C.Type.Null)
+	require.Equal(t, "age_new", expand[2].(*schema.AddColumn).C.Name)
+
+	require.Equal(t, migrate.PhaseBackfill, phases[1].Phase)
+	require.Len(t, phases[1].Backfills, 2)
+	require.Equal(t, name, phases[1].Backfills[0].From)
+	require.Equal(t, expand[1].(*schema.AddColumn).C, phases[1].Backfills[0].To)
+	require.Equal(t, age, phases[1].Backfills[1].From)
+
+	require.Equal(t, migrate.PhaseSwap, phases[2].Phase)
+	swap := phases[2].Changes[0].(*schema.ModifyTable).Changes
+	require.Equal(t, "age_old", swap[0].(*schema.RenameColumn).To.Name)
+	require.Equal(t, "age", swap[1].(*schema.RenameColumn).To.Name)
+
+	require.Equal(t, migrate.PhaseContract, phases[3].Phase)
+	require.Len(t, phases[3].Changes, 2)
+	contract := phases[3].Changes[0].(*schema.ModifyTable).Changes
+	require.Len(t, contract, 5)
+	require.Equal(t, schema.ChangeNull, contract[0].(*schema.ModifyColumn).Change)
+	require.Equal(t, name, contract[1].(*schema.DropColumn).C)
+	require.Equal(t, "age_old", contract[3].(*schema.DropColumn).C.Name)
+	require.Equal(t, bio, contract[4].(*schema.DropColumn).C)
+	require.Equal(t, posts, phases[3].Changes[1].(*schema.DropTable).T)
+
+	// Safe changes are kept in one phase.
+	phases = migrate.ExpandContract([]schema.Change{&schema.AddTable{T: schema.NewTable("tags")}})
+	require.Len(t, phases, 1)
+
+	// Renames of indexed columns are kept as-is.
+	email := schema.NewStringColumn("email", "text")
+	accounts := schema.NewTable("accounts").AddColumns(email).AddIndexes(schema.NewIndex("email").AddColumns(email))
+	rename := &schema.RenameColumn{From: email, To: schema.NewStringColumn("mail", "text")}
+	phases = migrate.ExpandContract([]schema.Change{&schema.ModifyTable{T: accounts, Changes: []schema.Change{rename}}})
+	require.Len(t, phases, 1)
+	require.Equal(t, migrate.PhaseExpand, phases[0].Phase)
+	require.Equal(t, []schema.Change{rename}, phases[0].Changes[0].(*schema.ModifyTable).Changes)
+}
+
+func TestPlanner_PlanPhases(t *testing.T) {
+	var (
+		drv = &backfillDriver{mockDriver: &mockDriver{}}
+		ctx = context.Background()
+	)
+	d, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	drv.changes = []schema.Change{
+		&schema.ModifyTable{
+			T: schema.NewTable("users"),
+			Changes: []schema.Change{
+				&schema.RenameColumn{From: schema.NewStringColumn("name", "text"), To: schema.NewNullStringColumn("full_name", "text")},
+			},
+		},
+	}
+	_, err = migrate.NewPlanner(drv.mockDriver, d, migrate.PlanWithExpandContract(true)).PlanPhases(ctx, "rename", migrate.Realm(nil))
+	require.EqualError(t, err, "sql/migrate: driver *migrate_test.mockDriver does not support expand/contract planning")
+
+	pl := migrate.NewPlanner(drv, d, migrate.PlanWithExpandContract(true))
+	plans, err := pl.PlanPhases(ctx, "rename", migrate.Realm(nil))
+	require.NoError(t, err)
+	require.Len(t, plans, 3)
+	require.Equal(t, "rename_expand", plans[0].Name)
+	require.Equal(t, []string{"-- atlas:phase 1/3 expand"}, plans[0].Directives)
+	require.Equal(t, []string{"-- atlas:phase 2/3 backfill", "-- atlas:dualwrite users.name users.full_name"}, plans[1].Directives)
+	require.Equal(t, "UPDATE users SET full_name = name", plans[1].Changes[0].Cmd)
+	require.Equal(t, []string{"-- atlas:phase 3/3 contract"}, plans[2].Directives)
+	require.Equal(t, "*schema.DropColumn", plans[2].Changes[0].Cmd)
+	require.NotEqual(t, plans[0].Version, plans[1].Version)
+
+	require.NoError(t, pl.WritePlans(plans...))
+	files, err := d.Files()
+	require.NoError(t, err)
+	require.Len(t, files, 3)
+	require.Equal(t, "-- atlas:phase 2/3 backfill\n-- atlas:dualwrite users.name users.full_name\n\nUPDATE users SET full_name = name;\n", string(files[1].Bytes()))
+
+	// Backfill expressions are set by the planner option.
+	plans, err = migrate.NewPlanner(drv, d, migrate.PlanWithExpandContract(true), migrate.PlanWithBackfillUsing(func(b *migrate.Backfill) string {
+		return "TRIM(" + b.From.Name + ")"
+	})).PlanPhases(ctx, "rename", migrate.Realm(nil))
+	require.NoError(t, err)
+	require.Equal(t, "UPDATE users SET full_name = TRIM(name)", plans[1].Changes[0].Cmd)
+
+	// Mode is disabled.
+	drv.plan = &migrate.Plan{Changes: []*migrate.Change{{Cmd: "ALTER TABLE users RENAME COLUMN name TO full_name"}}}
+	plans, err = migrate.NewPlanner(drv.mockDriver, d).PlanPhases(ctx, "rename", migrate.Realm(nil))
+	require.NoError(t, err)
+	require.Equal(t, []*migrate.Plan{drv.plan}, plans)
+}
+
+type backfillDriver struct {
+	*mockDriver
+}
+
+func (*backfillDriver) PlanChanges(_ context.Context, name string, changes []schema.Change, _ ...migrate.PlanOption) (*migrate.Plan, error) {
+	p := &migrate.Plan{Name: name}
+	for _, c := range changes {
+		for _, c1 := range c.(*schema.ModifyTable).Changes {
+			p.Changes = append(p.Changes, &migrate.Change{Cmd: fmt.Sprintf("%T", c1)})
+		}
+	}
+	return p, nil
+}
+
+func (*backfillDriver) Backfill(b *migrate.Backfill, _ ...migrate.PlanOption) (*migrate.Change, error) {
+	return &migrate.Change{Cmd: fmt.Sprintf("UPDATE %s SET %s = %s", b.T.Name, b.To.Name, cmp.Or(b.Using, b.From.Name))}, nil
+}
+
+func TestPlanner_Simulate(t *testing.T) {
+	var (
+		drv = &simulateDriver{mockDriver: &mockDriver{}}
+		ctx = context.Background()
+	)
+	d, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	drv.changes = []schema.Change{
+		&schema.AddTable{T: schema.NewTable("t1").AddColumns(schema.NewIntColumn("c", "int"))},
+	}
+	drv.plan = &migrate.Plan{
+		Changes: []*migrate.Change{
+			{Cmd: "CREATE TABLE t1(c int)"},
+			{Cmd: "ALTER TABLE t2 ADD COLUMN c int"},
+		},
+	}
+	// Simulation is disabled by default.
+	plan, err := migrate.NewPlanner(drv, d).Plan(ctx, "", migrate.Realm(nil))
+	require.NoError(t, err)
+	require.Nil(t, plan.Changes[1].Simulation)
+
+	plan, err = migrate.NewPlanner(drv, d, migrate.PlanWithSimulation(true)).Plan(ctx, "", migrate.Realm(nil))
+	require.NoError(t, err)
+	require.Nil(t, plan.Changes[0].Simulation)
+	require.Equal(t, &migrate.Simulation{Feasible: true, Algorithm: "INSTANT", Lock: "NONE"}, plan.Changes[1].Simulation)
+	require.Equal(t, []string{"CREATE TABLE t1(c int)", "ALTER TABLE t2 ADD COLUMN c int"}, drv.executed, "changes are executed after they are simulated")
+
+	// Changes are simulated on the result of the changes planned before them.
+	drv.executed = nil
+	drv.plan.Changes = append(drv.plan.Changes, &migrate.Change{Cmd: "CREATE INDEX i ON t2(c)"})
+	plan, err = migrate.NewPlanner(drv, d, migrate.PlanWithSimulation(true)).Plan(ctx, "", migrate.Realm(nil))
+	require.NoError(t, err)
+	require.Equal(t, &migrate.Simulation{Feasible: true}, plan.Changes[2].Simulation)
+
+	// Changes that fail to execute are not feasible.
+	drv.executed = nil
+	drv.failOn(2, errors.New("table t2 does not exist"))
+	_, err = migrate.NewPlanner(drv, d, migrate.PlanWithSimulation(true)).Plan(ctx, "", migrate.Realm(nil))
+	require.EqualError(t, err, `sql/migrate: planned change "ALTER TABLE t2 ADD COLUMN c int" is not feasible: table t2 does not exist`)
+	drv.plan.Changes = drv.plan.Changes[:2]
+
+	drv.plan.Changes[1].Cmd = "ALTER TABLE t2 DROP COLUMN c"
+	_, err = migrate.NewPlanner(drv, d, migrate.PlanWithSimulation(true)).Plan(ctx, "", migrate.Realm(nil))
+	require.EqualError(t, err, `sql/migrate: planned change "ALTER TABLE t2 DROP COLUMN c" is not feasible: column "c" does not exist`)
+
+	// Drivers that do not support simulation are skipped.
+	drv.plan = &migrate.Plan{Changes: []*migrate.Change{{Cmd: "ALTER TABLE t2 DROP COLUMN c"}}}
+	plan, err = migrate.NewPlanner(drv.mockDriver, d, migrate.PlanWithSimulation(true)).Plan(ctx, "", migrate.Realm(nil))
+	require.NoError(t, err)
+	require.Nil(t, plan.Changes[0].Simulation)
+}
+
+type simulateDriver struct {
+	*mockDriver
+}
+
+func (d *simulateDriver) SimulateChange(_ context.Context, c *migrate.Change) (*migrate.Simulation, error) {
+	switch {
+	case strings.HasPrefix(c.Cmd, "CREATE INDEX i ON t2(c)"):
+		if !slices.Contains(d.executed, "ALTER TABLE t2 ADD COLUMN c int") {
+			return &migrate.Simulation{Reason: `column "c" does not exist`}, nil
+		}
+		return &migrate.Simulation{Feasible: true}, nil
+	case strings.HasPrefix(c.Cmd, "ALTER TABLE t2 ADD"):
+		return &migrate.Simulation{Feasible: true, Algorithm: "INSTANT", Lock: "NONE"}, nil
+	case strings.HasPrefix(c.Cmd, "ALTER TABLE t2 DROP"):
+		return &migrate.Simulation{Reason: `column "c" does not exist`}, nil
+	default:
+		return nil, nil
+	}
+}
+
 func TestPlanner_WriteCheckpoint(t *testing.T) {
 	p := t.TempDir()
 	d, err := migrate.NewLocalDir(p)
@@ -141,6 +550,121 @@ func TestPlanner_Plan(t *testing.T) {
 	require.Equal(t, drv.plan, plan)
 }
 
+func TestPlanner_PlanWithClock(t *testing.T) {
+	var (
+		drv = &backfillDriver{mockDriver: &mockDriver{}}
+		ctx = context.Background()
+		now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.FixedZone("", 3600)) }
+	)
+	drv.changes = []schema.Change{
+		&schema.ModifyTable{
+			T: schema.NewTable("users"),
+			Changes: []schema.Change{
+				&schema.RenameColumn{From: schema.NewStringColumn("name", "text"), To: schema.NewNullStringColumn("full_name", "text")},
+			},
+		},
+	}
+	// Identical inputs produce identical files.
+	var dirs []*migrate.LocalDir
+	for range 2 {
+		d, err := migrate.NewLocalDir(t.TempDir())
+		require.NoError(t, err)
+		pl := migrate.NewPlanner(drv, d, migrate.PlanWithClock(now))
+		plan, err := pl.Plan(ctx, "rename", migrate.Realm(nil))
+		require.NoError(t, err)
+		require.Equal(t, "20260102020405", plan.Version)
+		require.NoError(t, pl.WritePlan(plan))
+		dirs = append(dirs, d)
+	}
+	files1, err := dirs[0].Files()
+	require.NoError(t, err)
+	files2, err := dirs[1].Files()
+	require.NoError(t, err)
+	require.Len(t, files1, 1)
+	require.Equal(t, "20260102020405_rename.sql", files1[0].Name())
+	require.Equal(t, files1[0].Name(), files2[0].Name())
+	require.Equal(t, files1[0].Bytes(), files2[0].Bytes())
+	sum1, err := dirs[0].Checksum()
+	require.NoError(t, err)
+	sum2, err := dirs[1].Checksum()
+	require.NoError(t, err)
+	require.Equal(t, sum1.Sum(), sum2.Sum())
+
+	// Phases are versioned sequentially from the clock.
+	d, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	plans, err := migrate.NewPlanner(drv, d, migrate.PlanWithClock(now), migrate.PlanWithExpandContract(true)).PlanPhases(ctx, "rename", migrate.Realm(nil))
+	require.NoError(t, err)
+	require.Len(t, plans, 3)
+	for i, v := range []string{"20260102020405", "20260102020406", "20260102020407"} {
+		require.Equal(t, v, plans[i].Version)
+	}
+	// Versions are valid timestamps across minute boundaries.
+	plans, err = migrate.NewPlanner(drv, d, migrate.PlanWithClock(func() time.Time {
+		return time.Date(2026, 12, 31, 23, 59, 59, 0, time.UTC)
+	}), migrate.PlanWithExpandContract(true)).PlanPhases(ctx, "rename", migrate.Realm(nil))
+	require.NoError(t, err)
+	for i, v := range []string{"20261231235959", "20270101000000", "20270101000001"} {
+		require.Equal(t, v, plans[i].Version)
+	}
+
+	// Checkpoints are versioned from the clock.
+	plan, err := migrate.NewPlanner(drv, d, migrate.PlanWithClock(now)).Checkpoint(ctx, "checkpoint")
+	require.NoError(t, err)
+	require.Equal(t, "20260102020405", plan.Version)
+}
+
+func TestPlanner_PlanWithNameSeed(t *testing.T) {
+	var (
+		drv = &backfillDriver{mockDriver: &mockDriver{}}
+		ctx = context.Background()
+		now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+	)
+	drv.changes = []schema.Change{
+		&schema.ModifyTable{
+			T: schema.NewTable("users"),
+			Changes: []schema.Change{
+				&schema.ModifyColumn{From: schema.NewIntColumn("age", "int"), To: schema.NewIntColumn("age", "bigint"), Change: schema.ChangeType},
+			},
+		},
+	}
+	plan := func(seed string) ([]*migrate.Plan, migrate.Dir) {
+		d, err := migrate.NewLocalDir(t.TempDir())
+		require.NoError(t, err)
+		pl := migrate.NewPlanner(drv, d, migrate.PlanWithClock(now), migrate.PlanWithNameSeed(seed), migrate.PlanWithExpandContract(true))
+		plans, err := pl.PlanPhases(ctx, "age", migrate.Realm(nil))
+		require.NoError(t, err)
+		require.NoError(t, pl.WritePlans(plans...))
+		return plans, d
+	}
+
+	// Identical inputs and seeds produce identical files.
+	plans1, d1 := plan("seed")
+	plans2, d2 := plan("seed")
+	require.Len(t, plans1, 4)
+	require.Equal(t, "UPDATE users SET age_new_c3275a18 = age", plans1[1].Changes[0].Cmd)
+	files1, err := d1.Files()
+	require.NoError(t, err)
+	files2, err := d2.Files()
+	require.NoError(t, err)
+	require.Len(t, files1, 4)
+	require.Len(t, files2, 4)
+	for i := range files1 {
+		require.Equal(t, files1[i].Name(), files2[i].Name())
+		require.Equal(t, files1[i].Bytes(), files2[i].Bytes())
+	}
+	require.Equal(t, "20260102030406_age_backfill.sql", files1[1].Name())
+	require.Equal(t, plans1[1].Changes[0].Cmd, plans2[1].Changes[0].Cmd)
+
+	// Different seeds produce different names.
+	plans3, _ := plan("other")
+	require.NotEqual(t, plans1[1].Changes[0].Cmd, plans3[1].Changes[0].Cmd)
+
+	// Without a seed, the default suffixes are used.
+	plans4, _ := plan("")
+	require.Equal(t, "UPDATE users SET age_new = age", plans4[1].Changes[0].Cmd)
+}
+
 func TestPlanner_PlanSchema(t *testing.T) {
 	var (
 		drv = &mockDriver{}
@@ -571,6 +1095,323 @@ func TestExecutor(t *testing.T) {
 	require.Empty(t, (*rrw)[0].ErrorStmt)
 }
 
+func TestExecutor_Publishers(t *testing.T) {
+	dir, err := migrate.NewLocalDir(filepath.Join("testdata", "migrate", "sub"))
+	require.NoError(t, err)
+	var reports []*migrate.ExecReport
+	ex, err := migrate.NewExecutor(&mockDriver{}, dir, &mockRevisionReadWriter{}, migrate.WithPublishers(
+		migrate.PublisherFunc(func(_ context.Context, r *migrate.ExecReport) error {
+			reports = append(reports, r)
+			return nil
+		}),
+	))
+	require.NoError(t, err)
+	require.NoError(t, ex.ExecuteN(context.Background(), 2))
+	require.Len(t, reports, 1)
+	r := reports[0]
+	require.Len(t, r.Files, 2)
+	require.Equal(t, "1.a", r.Files[0].Version)
+	require.Equal(t, "sub.up", r.Files[0].Description)
+	require.Len(t, r.Files[0].Stmts, 2)
+	require.Equal(t, "CREATE TABLE t_sub(c int);", r.Files[0].Stmts[0].SQL)
+	require.Equal(t, "2.10.x-20", r.Files[1].Version)
+	require.NotEmpty(t, r.Target)
+
+	// Publish errors are logged, and do not fail the execution.
+	var (
+		log = &mockLogger{}
+		rrw = &mockRevisionReadWriter{}
+	)
+	ex, err = migrate.NewExecutor(&mockDriver{}, dir, rrw, migrate.WithLogger(log), migrate.WithPublishers(
+		migrate.PublisherFunc(func(context.Context, *migrate.ExecReport) error {
+			return errors.New("unavailable")
+		}),
+	))
+	require.NoError(t, err)
+	require.NoError(t, ex.ExecuteN(context.Background(), 1))
+	require.Len(t, *rrw, 1)
+	var pe migrate.LogPublishError
+	require.True(t, slices.ContainsFunc(*log, func(l migrate.LogEntry) bool {
+		var ok bool
+		pe, ok = l.(migrate.LogPublishError)
+		return ok
+	}))
+	require.EqualError(t, pe.Error, "sql/migrate: publish executed files: unavailable")
+	require.Equal(t, migrate.LogDone{}, (*log)[len(*log)-1])
+}
+
+func TestExecutor_Artifacts(t *testing.T) {
+	dir, err := migrate.NewLocalDir(filepath.Join("testdata", "migrate", "sub"))
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "artifacts")
+	store, err := migrate.NewLocalArtifactStore(path)
+	require.NoError(t, err)
+	var artifacts []*migrate.ApplyArtifact
+	ex, err := migrate.NewExecutor(&mockDriver{}, dir, &mockRevisionReadWriter{}, migrate.WithOperatorVersion("v1"), migrate.WithArtifacts(
+		migrate.ArtifactStoreFunc(func(ctx context.Context, a *migrate.ApplyArtifact) error {
+			artifacts = append(artifacts, a)
+			return store.WriteArtifact(ctx, a)
+		}),
+	))
+	require.NoError(t, err)
+	require.NoError(t, ex.ExecuteN(context.Background(), 1))
+	require.Len(t, artifacts, 1)
+	a := artifacts[0]
+	require.Equal(t, "1.a", a.To)
+	require.Equal(t, []string{"1.a_sub.up.sql"}, a.Plan)
+	require.Equal(t, "v1", a.OperatorVersion)
+	require.Empty(t, a.Error)
+	require.Len(t, a.Files, 1)
+	require.Equal(t, "1.a", a.Files[0].Version)
+	require.Len(t, a.Files[0].Stmts, 2)
+	require.Equal(t, "CREATE TABLE t_sub(c int);", a.Files[0].Stmts[0].SQL)
+	require.False(t, a.Files[0].Stmts[0].StartedAt.IsZero())
+
+	// Failed statements are recorded.
+	drv := &mockDriver{failCounter: 2, failWith: errors.New("failed")}
+	ex, err = migrate.NewExecutor(drv, dir, &mockRevisionReadWriter{}, migrate.WithArtifacts(
+		migrate.ArtifactStoreFunc(func(ctx context.Context, a *migrate.ApplyArtifact) error {
+			artifacts = append(artifacts, a)
+			return store.WriteArtifact(ctx, a)
+		}),
+	))
+	require.NoError(t, err)
+	require.Error(t, ex.ExecuteN(context.Background(), 1))
+	require.Len(t, artifacts, 2)
+	a = artifacts[1]
+	require.Len(t, a.Files[0].Stmts, 2)
+	require.Empty(t, a.Files[0].Stmts[0].Error)
+	require.Equal(t, "failed", a.Files[0].Stmts[1].Error)
+	require.Contains(t, a.Error, "failed")
+
+	// Artifacts are written to the local directory.
+	entries, err := os.ReadDir(path)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	var stored migrate.ApplyArtifact
+	b, err := os.ReadFile(filepath.Join(path, entries[0].Name()))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(b, &stored))
+	require.Equal(t, artifacts[0].Plan, stored.Plan)
+}
+
+func TestExecutor_StoreRevisions(t *testing.T) {
+	dir, err := migrate.NewLocalDir(filepath.Join("testdata", "migrate", "sub"))
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "revisions")
+	store, err := migrate.NewLocalRevisionStore(path)
+	require.NoError(t, err)
+	rrw := migrate.NewStoreRevisions(store)
+	require.Nil(t, rrw.Ident())
+	ex, err := migrate.NewExecutor(&mockDriver{}, dir, rrw)
+	require.NoError(t, err)
+	require.NoError(t, ex.ExecuteN(context.Background(), 2))
+	revs, err := rrw.ReadRevisions(context.Background())
+	require.NoError(t, err)
+	require.Len(t, revs, 2)
+	require.Equal(t, "1.a", revs[0].Version)
+	require.Equal(t, "2.10.x-20", revs[1].Version)
+	require.Equal(t, 2, revs[0].Applied)
+	require.Equal(t, migrate.RevisionTypeExecute, revs[0].Type)
+	// Hashes are stored, unlike in the JSON form of a revision.
+	require.NotEmpty(t, revs[0].Hash)
+	entries, err := os.ReadDir(path)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "1.a.json", entries[0].Name())
+
+	// Applied files are not executed again.
+	ex, err = migrate.NewExecutor(&mockDriver{}, dir, rrw)
+	require.NoError(t, err)
+	require.NoError(t, ex.ExecuteN(context.Background(), 1))
+	revs, err = rrw.ReadRevisions(context.Background())
+	require.NoError(t, err)
+	require.Len(t, revs, 3)
+
+	require.NoError(t, rrw.DeleteRevision(context.Background(), "1.a"))
+	_, err = rrw.ReadRevision(context.Background(), "1.a")
+	require.ErrorIs(t, err, migrate.ErrRevisionNotExist)
+	require.NoError(t, rrw.DeleteRevision(context.Background(), "1.a"))
+	_, err = store.Get(context.Background(), "../1.a")
+	require.EqualError(t, err, `sql/migrate: invalid revision version "../1.a"`)
+}
+
+func TestExecutor_Throttle(t *testing.T) {
+	dir, err := migrate.NewLocalDir(filepath.Join("testdata", "migrate", "sub"))
+	require.NoError(t, err)
+	_, err = migrate.NewExecutor(&mockDriver{}, dir, &mockRevisionReadWriter{}, migrate.WithThrottle(migrate.Throttle{Rate: -1}))
+	require.EqualError(t, err, "sql/migrate: throttle rate and pause must not be negative")
+	_, err = migrate.NewExecutor(&mockDriver{}, dir, &mockRevisionReadWriter{}, migrate.WithThrottle(migrate.Throttle{
+		Windows: []migrate.ThrottleWindow{{Start: time.Hour, End: time.Hour}},
+	}))
+	require.EqualError(t, err, "sql/migrate: invalid throttle window 1h0m0s-1h0m0s")
+
+	// Pause after heavy statements.
+	drv, rrw := &mockDriver{}, &mockRevisionReadWriter{}
+	ex, err := migrate.NewExecutor(drv, dir, rrw, migrate.WithThrottle(migrate.Throttle{
+		Pause: 50 * time.Millisecond,
+		Heavy: func(s *migrate.Stmt) bool {
+			return strings.HasPrefix(s.Text, "CREATE TABLE")
+		},
+	}))
+	require.NoError(t, err)
+	start := time.Now()
+	require.NoError(t, ex.ExecuteN(context.Background(), 1))
+	require.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	require.Equal(t, []string{"CREATE TABLE t_sub(c int);", "ALTER TABLE t_sub ADD c1 int;"}, drv.executed)
+
+	// Interrupted executions resume from the first statement that was not executed.
+	drv, rrw = &mockDriver{}, &mockRevisionReadWriter{}
+	ex, err = migrate.NewExecutor(drv, dir, rrw, migrate.WithThrottle(migrate.Throttle{Pause: time.Hour}))
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = ex.ExecuteN(ctx, 1)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, []string{"CREATE TABLE t_sub(c int);"}, drv.executed)
+	require.Len(t, *rrw, 1)
+	require.Equal(t, 1, (*rrw)[0].Applied)
+	require.Equal(t, 2, (*rrw)[0].Total)
+	ex, err = migrate.NewExecutor(drv, dir, rrw)
+	require.NoError(t, err)
+	require.NoError(t, ex.ExecuteN(context.Background(), 1))
+	require.Equal(t, []string{"CREATE TABLE t_sub(c int);", "ALTER TABLE t_sub ADD c1 int;"}, drv.executed)
+	require.Equal(t, 2, (*rrw)[0].Applied)
+
+	// Statements are not executed outside the time windows.
+	now := time.Now().UTC()
+	y, m, d := now.Date()
+	open := (now.Sub(time.Date(y, m, d, 0, 0, 0, 0, time.UTC)) + time.Hour) % (24 * time.Hour)
+	drv, rrw = &mockDriver{}, &mockRevisionReadWriter{}
+	ex, err = migrate.NewExecutor(drv, dir, rrw, migrate.WithThrottle(migrate.Throttle{
+		Windows: []migrate.ThrottleWindow{{Start: open, End: (open + time.Hour) % (24 * time.Hour)}},
+	}))
+	require.NoError(t, err)
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, ex.ExecuteN(ctx, 1), context.DeadlineExceeded)
+	require.Empty(t, drv.executed)
+	require.Equal(t, 0, (*rrw)[0].Applied)
+}
+
+func TestExecutor_TimeBudget(t *testing.T) {
+	dir, err := migrate.NewLocalDir(filepath.Join("testdata", "migrate", "sub"))
+	require.NoError(t, err)
+	_, err = migrate.NewExecutor(&mockDriver{}, dir, &mockRevisionReadWriter{}, migrate.WithTimeBudget(migrate.TimeBudget{}))
+	require.EqualError(t, err, "sql/migrate: time budget must be positive")
+
+	// Running statements are canceled, and the execution is rolled back and reported.
+	var (
+		rolledBack bool
+		report     *migrate.BudgetReport
+		drv, rrw   = &slowDriver{mockDriver: &mockDriver{}, slow: "ALTER TABLE"}, &mockRevisionReadWriter{}
+	)
+	ex, err := migrate.NewExecutor(drv, dir, rrw, migrate.WithTimeBudget(migrate.TimeBudget{
+		Max: 50 * time.Millisecond,
+		Rollback: func(context.Context) error {
+			rolledBack = true
+			return nil
+		},
+		Report: func(_ context.Context, r *migrate.BudgetReport) {
+			report = r
+		},
+	}))
+	require.NoError(t, err)
+	err = ex.ExecuteN(context.Background(), 1)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	var be *migrate.BudgetExceededError
+	require.ErrorAs(t, err, &be)
+	require.EqualError(t, err, `sql/migrate: time budget of 50ms exceeded after applying 1 of 2 statements of version "1.a"`)
+	require.True(t, rolledBack)
+	require.Same(t, be.Report, report)
+	require.GreaterOrEqual(t, report.Elapsed, 50*time.Millisecond)
+	require.Equal(t, 1, report.Applied)
+	require.Equal(t, 2, report.Total)
+	require.Equal(t, "ALTER TABLE t_sub ADD c1 int;", report.Stmt)
+	require.True(t, report.Interrupted)
+	require.True(t, report.RolledBack)
+	require.Equal(t, []string{"CREATE TABLE t_sub(c int);"}, drv.executed)
+	// The revisions that were written by the rolled back run are reset.
+	require.Empty(t, *rrw)
+
+	// Revisions that are written in the rolled back transaction are not reset.
+	drv, rrw = &slowDriver{mockDriver: &mockDriver{}, slow: "ALTER TABLE"}, &mockRevisionReadWriter{}
+	ex, err = migrate.NewExecutor(drv, dir, rrw, migrate.WithTimeBudget(migrate.TimeBudget{
+		Max:           50 * time.Millisecond,
+		Rollback:      func(context.Context) error { return nil },
+		RevisionsInTx: true,
+	}))
+	require.NoError(t, err)
+	err = ex.ExecuteN(context.Background(), 1)
+	require.ErrorAs(t, err, &be)
+	require.True(t, be.Report.RolledBack)
+	require.Len(t, *rrw, 1)
+	require.Equal(t, 1, (*rrw)[0].Applied)
+	require.Equal(t, "ALTER TABLE t_sub ADD c1 int;", (*rrw)[0].ErrorStmt)
+	require.Equal(t, err.Error(), (*rrw)[0].Error)
+
+	// The budget is started for every execution, and not inherited from previous runs.
+	time.Sleep(60 * time.Millisecond)
+	drv.slow = "DROP"
+	files, err := dir.Files()
+	require.NoError(t, err)
+	require.NoError(t, ex.Execute(context.Background(), files[0]))
+	require.Equal(t, 2, (*rrw)[0].Applied)
+
+	// Statements are not started once the budget is exceeded, and rollback errors are reported.
+	drv, rrw = &slowDriver{mockDriver: &mockDriver{}}, &mockRevisionReadWriter{}
+	ex, err = migrate.NewExecutor(drv, dir, rrw,
+		migrate.WithThrottle(migrate.Throttle{Pause: time.Hour}),
+		migrate.WithTimeBudget(migrate.TimeBudget{
+			Max: 50 * time.Millisecond,
+			Rollback: func(context.Context) error {
+				return errors.New("tx done")
+			},
+			Report: func(_ context.Context, r *migrate.BudgetReport) {
+				report = r
+			},
+		}),
+	)
+	require.NoError(t, err)
+	err = ex.ExecuteN(context.Background(), 1)
+	require.ErrorAs(t, err, &be)
+	require.ErrorContains(t, err, "sql/migrate: rollback aborted execution: tx done")
+	require.False(t, report.Interrupted)
+	require.False(t, report.RolledBack)
+	require.Equal(t, "tx done", report.RollbackError)
+	require.Equal(t, "ALTER TABLE t_sub ADD c1 int;", report.Stmt)
+	require.Equal(t, 1, (*rrw)[0].Applied)
+
+	// Executions are resumed from the first statement that was not applied.
+	ex, err = migrate.NewExecutor(drv, dir, rrw)
+	require.NoError(t, err)
+	require.NoError(t, ex.ExecuteN(context.Background(), 1))
+	require.Equal(t, []string{"CREATE TABLE t_sub(c int);", "ALTER TABLE t_sub ADD c1 int;"}, drv.executed)
+	require.Equal(t, 2, (*rrw)[0].Applied)
+	require.Empty(t, (*rrw)[0].Error)
+}
+
+// slowDriver is a mockDriver that blocks the execution of
+// statements with the given prefix until they are canceled.
+type slowDriver struct {
+	*mockDriver
+	slow string
+}
+
+func (d *slowDriver) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	if d.slow != "" && strings.HasPrefix(query, d.slow) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return d.mockDriver.ExecContext(ctx, query, args...)
+}
+
+func TestTargetFingerprint(t *testing.T) {
+	revs := []*migrate.Revision{{Version: "1", Hash: "a"}, {Version: "2", Hash: "b"}}
+	require.Equal(t, migrate.TargetFingerprint(revs), migrate.TargetFingerprint(revs))
+	require.NotEqual(t, migrate.TargetFingerprint(revs), migrate.TargetFingerprint(revs[:1]))
+}
+
 func TestExecutor_Baseline(t *testing.T) {
 	var (
 		rrw mockRevisionReadWriter
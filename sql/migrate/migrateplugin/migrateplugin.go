@@ -0,0 +1,54 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package migrateplugin provides a migrate.StateReader for the desired state of
+// applications built as Go plugins. It is kept outside the migrate package, as
+// importing the plugin package affects the linking of all programs that import it.
+package migrateplugin
+
+import (
+	"context"
+	"fmt"
+	"plugin"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+// Symbol is the optional symbol a Go plugin can export to provide its desired
+// state explicitly, instead of registering it using schema.Register. The symbol
+// must be a function with the following signature:
+//
+//	func AtlasRealm() (*schema.Realm, error)
+const Symbol = "AtlasRealm"
+
+// StateReader returns a migrate.StateReader that loads the Go plugin in the given
+// path and reads the desired state exported by it. The plugin can either export the
+// Symbol function, or register its schemas on init using schema.Register.
+func StateReader(path string) migrate.StateReader {
+	return migrate.StateReaderFunc(func(ctx context.Context) (*schema.Realm, error) {
+		p, err := plugin.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("sql/migrateplugin: open plugin %q: %w", path, err)
+		}
+		sym, err := p.Lookup(Symbol)
+		if err != nil {
+			// Symbol is not exported. Fallback to the schemas
+			// registered by the plugin packages on their init.
+			return migrate.Registry().ReadState(ctx)
+		}
+		f, ok := sym.(func() (*schema.Realm, error))
+		if !ok {
+			return nil, fmt.Errorf("sql/migrateplugin: unexpected %s type %T in plugin %q", Symbol, sym, path)
+		}
+		r, err := f()
+		if err != nil {
+			return nil, fmt.Errorf("sql/migrateplugin: read plugin %q state: %w", path, err)
+		}
+		if r == nil {
+			return nil, migrate.ErrEmptyRegistry
+		}
+		return r, nil
+	})
+}
@@ -0,0 +1,19 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrateplugin_test
+
+import (
+	"context"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate/migrateplugin"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateReader(t *testing.T) {
+	_, err := migrateplugin.StateReader("testdata/notfound.so").ReadState(context.Background())
+	require.ErrorContains(t, err, `sql/migrateplugin: open plugin "testdata/notfound.so"`)
+}
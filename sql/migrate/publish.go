@@ -0,0 +1,107 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type (
+	// Publisher publishes the migration files that were applied by an Executor
+	// to an external sink (e.g., an HTTP webhook or a message queue adapter),
+	// allowing centralized auditing of the changes applied to databases.
+	Publisher interface {
+		Publish(context.Context, *ExecReport) error
+	}
+
+	// The PublisherFunc type is an adapter to allow the use of
+	// ordinary functions as Publishers.
+	PublisherFunc func(context.Context, *ExecReport) error
+
+	// ExecReport describes a successful execution of migration files.
+	ExecReport struct {
+		// Target is the fingerprint of the target database, computed
+		// from its revision history after the execution.
+		Target string `json:"Target"`
+		// Files that were executed on the target database.
+		Files []*ExecFile `json:"Files"`
+		// ExecutedAt is the time the execution was completed.
+		ExecutedAt time.Time `json:"ExecutedAt"`
+	}
+
+	// ExecFile describes an executed migration file.
+	ExecFile struct {
+		Version     string      `json:"Version"`
+		Description string      `json:"Description"`
+		Stmts       []*ExecStmt `json:"Stmts"`
+	}
+
+	// ExecStmt describes an executed statement and its hash, as
+	// recorded in the revision history (see Revision.PartialHashes).
+	ExecStmt struct {
+		SQL  string `json:"SQL"`
+		Hash string `json:"Hash"`
+	}
+)
+
+// Publish calls f(ctx, r).
+func (f PublisherFunc) Publish(ctx context.Context, r *ExecReport) error {
+	return f(ctx, r)
+}
+
+// publish reports the executed files to the configured publishers.
+func (e *Executor) publish(ctx context.Context, files []File) error {
+	if len(e.publishers) == 0 || len(files) == 0 {
+		return nil
+	}
+	r := &ExecReport{ExecutedAt: time.Now()}
+	for _, f := range files {
+		stmts, err := e.fileStmts(f)
+		if err != nil {
+			return fmt.Errorf("sql/migrate: scanning statements from %q: %w", f.Name(), err)
+		}
+		sums, err := stmtSums(stmts)
+		if err != nil {
+			return err
+		}
+		ef := &ExecFile{Version: f.Version(), Description: f.Desc()}
+		for i, s := range stmts {
+			ef.Stmts = append(ef.Stmts, &ExecStmt{SQL: s.Text, Hash: "h1:" + sums[i]})
+		}
+		r.Files = append(r.Files, ef)
+	}
+	revs, err := e.rrw.ReadRevisions(ctx)
+	if err != nil {
+		return fmt.Errorf("sql/migrate: read revisions: %w", err)
+	}
+	r.Target = TargetFingerprint(revs)
+	var errs []error
+	for _, p := range e.publishers {
+		if err := p.Publish(ctx, r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("sql/migrate: publish executed files: %w", err)
+	}
+	return nil
+}
+
+// TargetFingerprint returns a fingerprint of a target database computed from
+// its revision history. Two databases that executed the same migration files
+// share the same fingerprint.
+func TargetFingerprint(revs []*Revision) string {
+	h := sha256.New()
+	for _, r := range revs {
+		h.Write([]byte(r.Version))
+		h.Write([]byte(r.Hash))
+	}
+	return "h1:" + base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
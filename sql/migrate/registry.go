@@ -0,0 +1,30 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"errors"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+// ErrEmptyRegistry is returned when reading the state
+// of an application that did not register any schema.
+var ErrEmptyRegistry = errors.New("sql/migrate: no schema was registered")
+
+// Registry returns a StateReader for the schemas registered using schema.Register.
+// It is used by programs that link the application packages into their binary
+// (e.g., using a build tag), making the application the source of truth of its schema.
+// Applications built as Go plugins are read using the migrateplugin package.
+func Registry() StateReader {
+	return StateReaderFunc(func(context.Context) (*schema.Realm, error) {
+		r := schema.Registered()
+		if r == nil {
+			return nil, ErrEmptyRegistry
+		}
+		return r, nil
+	})
+}
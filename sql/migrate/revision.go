@@ -0,0 +1,191 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type (
+	// RevisionStore is a minimal key-value storage for migration revisions. It allows keeping the
+	// revisions outside the target database, for targets where a revisions table is not desired,
+	// such as warehouses or read-mostly databases. Implementations for key-value services (e.g.,
+	// DynamoDB or etcd) only need to map these methods to their client. Keys are the versions of
+	// the revisions, and values are their encoded form.
+	RevisionStore interface {
+		// Get returns the value stored under the given key.
+		// Returns ErrRevisionNotExist if the key does not exist.
+		Get(ctx context.Context, key string) ([]byte, error)
+		// Put stores the value under the given key, replacing the existing one.
+		Put(ctx context.Context, key string, value []byte) error
+		// Delete deletes the value stored under the given key, if exists.
+		Delete(ctx context.Context, key string) error
+		// Keys returns all keys in the storage.
+		Keys(ctx context.Context) ([]string, error)
+	}
+
+	// StoreRevisions is a RevisionReadWriter that keeps
+	// the revisions in a RevisionStore, encoded as JSON.
+	StoreRevisions struct {
+		s RevisionStore
+	}
+
+	// LocalRevisionStore is a RevisionStore that writes the revisions as JSON files to
+	// a local directory (e.g., next to the migration directory). Note, it is not safe
+	// for concurrent use by multiple processes.
+	LocalRevisionStore struct {
+		path string
+	}
+
+	// storedRevision is the encoded form of a Revision in a RevisionStore. Unlike the JSON
+	// form of a Revision, it holds the hashes that are required for executions, and the
+	// type is stored as a number, as its text form cannot be decoded.
+	storedRevision struct {
+		*Revision
+		Type          uint     `json:"Type"`
+		Hash          string   `json:"Hash"`
+		PartialHashes []string `json:"PartialHashes,omitempty"`
+	}
+)
+
+// NewStoreRevisions returns a new RevisionReadWriter that reads and writes the revisions to the given store.
+func NewStoreRevisions(s RevisionStore) *StoreRevisions {
+	return &StoreRevisions{s: s}
+}
+
+// Ident implements RevisionsReadWriter.TableIdent. Revisions are not stored in the
+// target database, and therefore, no table is excluded when checking it is clean.
+func (*StoreRevisions) Ident() *TableIdent {
+	return nil
+}
+
+// ReadRevisions implements RevisionsReadWriter.ReadRevisions. Revisions are ordered by their versions.
+func (r *StoreRevisions) ReadRevisions(ctx context.Context) ([]*Revision, error) {
+	keys, err := r.s.Keys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	revs := make([]*Revision, 0, len(keys))
+	for _, k := range keys {
+		rev, err := r.ReadRevision(ctx, k)
+		if err != nil {
+			return nil, err
+		}
+		revs = append(revs, rev)
+	}
+	sort.Slice(revs, func(i, j int) bool {
+		return revs[i].Version < revs[j].Version
+	})
+	return revs, nil
+}
+
+// ReadRevision implements RevisionsReadWriter.ReadRevision.
+func (r *StoreRevisions) ReadRevision(ctx context.Context, v string) (*Revision, error) {
+	b, err := r.s.Get(ctx, v)
+	if err != nil {
+		return nil, err
+	}
+	rev := storedRevision{Revision: &Revision{}}
+	if err := json.Unmarshal(b, &rev); err != nil {
+		return nil, fmt.Errorf("sql/migrate: decode revision %q: %w", v, err)
+	}
+	rev.Revision.Type, rev.Revision.Hash, rev.Revision.PartialHashes = RevisionType(rev.Type), rev.Hash, rev.PartialHashes
+	return rev.Revision, nil
+}
+
+// WriteRevision implements RevisionsReadWriter.WriteRevision.
+func (r *StoreRevisions) WriteRevision(ctx context.Context, rev *Revision) error {
+	b, err := json.Marshal(storedRevision{Revision: rev, Type: uint(rev.Type), Hash: rev.Hash, PartialHashes: rev.PartialHashes})
+	if err != nil {
+		return fmt.Errorf("sql/migrate: encode revision %q: %w", rev.Version, err)
+	}
+	return r.s.Put(ctx, rev.Version, b)
+}
+
+// DeleteRevision implements RevisionsReadWriter.DeleteRevision.
+func (r *StoreRevisions) DeleteRevision(ctx context.Context, v string) error {
+	return r.s.Delete(ctx, v)
+}
+
+var _ RevisionReadWriter = (*StoreRevisions)(nil)
+
+// NewLocalRevisionStore returns a new LocalRevisionStore that writes the
+// revisions to the given directory. The directory is created if it does not exist.
+func NewLocalRevisionStore(path string) (*LocalRevisionStore, error) {
+	if err := os.MkdirAll(path, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("sql/migrate: create revisions directory: %w", err)
+	}
+	return &LocalRevisionStore{path: path}, nil
+}
+
+// Get implements the RevisionStore interface.
+func (s *LocalRevisionStore) Get(_ context.Context, key string) ([]byte, error) {
+	name, err := s.name(key)
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrRevisionNotExist
+	}
+	return b, err
+}
+
+// Put implements the RevisionStore interface. The file is first written to a temporary
+// file and then renamed, to avoid leaving a partially written revision behind.
+func (s *LocalRevisionStore) Put(_ context.Context, key string, value []byte) error {
+	name, err := s.name(key)
+	if err != nil {
+		return err
+	}
+	tmp := name + ".tmp"
+	if err := os.WriteFile(tmp, value, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, name)
+}
+
+// Delete implements the RevisionStore interface.
+func (s *LocalRevisionStore) Delete(_ context.Context, key string) error {
+	name, err := s.name(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Keys implements the RevisionStore interface.
+func (s *LocalRevisionStore) Keys(context.Context) ([]string, error) {
+	entries, err := os.ReadDir(s.path)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, e := range entries {
+		if k, ok := strings.CutSuffix(e.Name(), ".json"); ok && !e.IsDir() {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+
+// name returns the file name of the given key. e.g., 20261016120000.json.
+func (s *LocalRevisionStore) name(key string) (string, error) {
+	if key == "" || key == "." || key == ".." || strings.ContainsAny(key, `/\`) {
+		return "", fmt.Errorf("sql/migrate: invalid revision version %q", key)
+	}
+	return filepath.Join(s.path, key+".json"), nil
+}
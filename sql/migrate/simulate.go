@@ -0,0 +1,107 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	// Simulator is an optional interface implemented by drivers that can simulate the execution
+	// of planned changes without applying them, using the EXPLAIN or dry-run facilities of the
+	// database. SimulateChange returns a nil Simulation if the change cannot be simulated.
+	Simulator interface {
+		SimulateChange(context.Context, *Change) (*Simulation, error)
+	}
+
+	// Simulation describes the result of simulating a planned change.
+	Simulation struct {
+		// Feasible reports if the database accepted the change.
+		Feasible bool
+		// Reason holds the error returned by the database, if the change is not feasible.
+		Reason string
+		// Algorithm and Lock describe how the database executes the change, if known.
+		// For example, "INSTANT" and "NONE", or "COPY" and "SHARED" in MySQL.
+		Algorithm, Lock string
+		// Details holds additional information reported by
+		// the database. e.g., the output of an EXPLAIN command.
+		Details []string
+	}
+)
+
+// PlanWithSimulation enables the simulation step of the planner. If enabled, and the driver
+// implements the Simulator interface, the planned changes are simulated one after the other on
+// the dev database, on top of the migration directory and the changes planned before them, and
+// their results are attached to the plan. Planning fails if any of the changes is not feasible.
+func PlanWithSimulation(b bool) PlannerOption {
+	return func(p *Planner) {
+		p.simulate = b
+	}
+}
+
+// SimulatePlan simulates the changes of the plan using the given driver, and attaches their
+// results to the plan changes. It is a no-op if the driver does not implement the Simulator
+// interface. Note, changes are simulated independently, in their planned order.
+func SimulatePlan(ctx context.Context, drv Driver, p *Plan) error {
+	s, ok := drv.(Simulator)
+	if !ok {
+		return nil
+	}
+	for _, c := range p.Changes {
+		r, err := s.SimulateChange(ctx, c)
+		if err != nil {
+			return fmt.Errorf("sql/migrate: simulate change %q: %w", c.Cmd, err)
+		}
+		c.Simulation = r
+	}
+	return nil
+}
+
+// simulatePlan simulates the given plan on the state of the migration directory,
+// and reports the first change that is not feasible. Unlike SimulatePlan, changes
+// are simulated cumulatively, as each change is executed after it was simulated,
+// to allow simulating changes that depend on the changes planned before them.
+// For example, adding an index on a column that is added by the plan.
+func (p *Planner) simulatePlan(ctx context.Context, plan *Plan) error {
+	s, ok := p.drv.(Simulator)
+	if !ok {
+		return nil
+	}
+	ex, err := NewExecutor(p.drv, p.dir, NopRevisionReadWriter{})
+	if err != nil {
+		return err
+	}
+	// The database is restored to its
+	// original state after the simulation.
+	if _, err := ex.Replay(ctx, StateReaderFunc(func(ctx context.Context) (*schema.Realm, error) {
+		for _, c := range plan.Changes {
+			r, err := s.SimulateChange(ctx, c)
+			if err != nil {
+				return nil, fmt.Errorf("sql/migrate: simulate change %q: %w", c.Cmd, err)
+			}
+			if c.Simulation = r; r != nil && !r.Feasible {
+				return nil, nil
+			}
+			// Changes that cannot be executed are not feasible, and
+			// the changes that follow them cannot be simulated.
+			if _, err := p.drv.ExecContext(ctx, c.Cmd, c.Args...); err != nil {
+				c.Simulation = &Simulation{Reason: err.Error()}
+				return nil, nil
+			}
+		}
+		return nil, nil
+	})); err != nil {
+		return err
+	}
+	for _, c := range plan.Changes {
+		if c.Simulation != nil && !c.Simulation.Feasible {
+			return fmt.Errorf("sql/migrate: planned change %q is not feasible: %s", c.Cmd, c.Simulation.Reason)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	// A Snapshot is a serialized inspection of a database state. Snapshots allow computing
+	// plan previews (e.g., in CI) against the state of a database that is not reachable at
+	// planning time, such as production. Its JSON encoding is stable and can be stored as a
+	// build artifact. For example:
+	//
+	//	snap, err := migrate.TakeSnapshot(ctx, migrate.RealmConn(prod, nil), codec)
+	//	...
+	//	g, err := migrate.NewTargetGroup([]*migrate.Target{
+	//		{Name: "prod", Driver: dev, Current: migrate.SnapshotState(snap, codec)},
+	//	})
+	Snapshot struct {
+		// Fingerprint of the snapshot state. It is used to verify the snapshot was not
+		// modified since it was taken, and to compare it with later snapshots.
+		Fingerprint string `json:"Fingerprint"`
+		// State is the inspected state, encoded by the SnapshotCodec.
+		State string `json:"State"`
+		// TakenAt is the time the snapshot was taken.
+		TakenAt time.Time `json:"TakenAt"`
+	}
+
+	// SnapshotCodec encodes and decodes the states of snapshots. Driver-specific attributes are
+	// kept only by the driver codecs, and therefore, the HCL marshaler and evaluator of the
+	// driver are commonly used. For example:
+	//
+	//	codec := migrate.SnapshotCodec{
+	//		Marshal: postgres.MarshalHCL.MarshalSpec,
+	//		Unmarshal: func(b []byte, r *schema.Realm) error {
+	//			return postgres.EvalHCLBytes(b, r, nil)
+	//		},
+	//	}
+	SnapshotCodec struct {
+		Marshal   func(any) ([]byte, error)
+		Unmarshal func([]byte, *schema.Realm) error
+	}
+)
+
+// TakeSnapshot reads the state from the given StateReader (usually, a connection
+// to the database) and returns its snapshot, encoded by the given codec.
+func TakeSnapshot(ctx context.Context, r StateReader, c SnapshotCodec) (*Snapshot, error) {
+	if c.Marshal == nil {
+		return nil, errors.New("sql/migrate: missing snapshot marshaler")
+	}
+	realm, err := r.ReadState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	b, err := c.Marshal(realm)
+	if err != nil {
+		return nil, fmt.Errorf("sql/migrate: marshal snapshot state: %w", err)
+	}
+	return &Snapshot{Fingerprint: StateFingerprint(b), State: string(b), TakenAt: time.Now()}, nil
+}
+
+// ReadSnapshot reads a JSON-encoded snapshot and verifies its fingerprint.
+func ReadSnapshot(b []byte) (*Snapshot, error) {
+	var s Snapshot
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, fmt.Errorf("sql/migrate: read snapshot: %w", err)
+	}
+	if err := s.Verify(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Verify reports an error if the snapshot state does not match its fingerprint.
+func (s *Snapshot) Verify() error {
+	if f := StateFingerprint([]byte(s.State)); f != s.Fingerprint {
+		return fmt.Errorf("sql/migrate: snapshot fingerprint mismatch: %q != %q", s.Fingerprint, f)
+	}
+	return nil
+}
+
+// SnapshotState returns a StateReader for the state of the given snapshot. The snapshot
+// is verified before it is decoded, and each read returns a newly decoded state.
+func SnapshotState(s *Snapshot, c SnapshotCodec) StateReader {
+	return StateReaderFunc(func(context.Context) (*schema.Realm, error) {
+		if c.Unmarshal == nil {
+			return nil, errors.New("sql/migrate: missing snapshot unmarshaler")
+		}
+		if err := s.Verify(); err != nil {
+			return nil, err
+		}
+		var r schema.Realm
+		if err := c.Unmarshal([]byte(s.State), &r); err != nil {
+			return nil, fmt.Errorf("sql/migrate: unmarshal snapshot state: %w", err)
+		}
+		return &r, nil
+	})
+}
+
+// StateFingerprint returns the fingerprint of an encoded state.
+func StateFingerprint(state []byte) string {
+	h := sha256.Sum256(state)
+	return "h1:" + base64.StdEncoding.EncodeToString(h[:])
+}
@@ -0,0 +1,261 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	// Target describes a database that is part of a TargetGroup.
+	Target struct {
+		// Name of the target. e.g., "staging", "canary" or "prod".
+		Name string
+		// Driver connected to the target database.
+		Driver Driver
+		// Schema limits the scope of the target to the given schema. If empty,
+		// the scope of the target is the connected realm (database).
+		Schema string
+		// Exclude resources from planning that match the patterns.
+		Exclude []string
+		// Current reads the current state of the target instead of inspecting it
+		// with its Driver. For example, a Snapshot of a database that cannot be
+		// reached at planning time. In this case, the Driver is used only for
+		// diffing and planning (e.g., a dev database), and the target can be
+		// planned but not applied.
+		Current StateReader
+	}
+
+	// TargetGroup maps one desired state to multiple ordered targets. For example,
+	// staging, canary and production databases. Targets are migrated one after the
+	// other, where the plan is recomputed for each target against its current state,
+	// and gating callbacks are called between the stages. TargetGroup is useful for
+	// library users that build deployment pipelines.
+	TargetGroup struct {
+		targets  []*Target
+		planOpts []PlanOption        // plan options
+		diffOpts []schema.DiffOption // diff options
+		approve  func(context.Context, *TargetResult) error
+		gate     func(context.Context, *TargetResult) error
+	}
+
+	// TargetGroupOption allows configuring a TargetGroup using functional arguments.
+	TargetGroupOption func(*TargetGroup)
+
+	// TargetResult describes the result of planning (and applying) the desired
+	// state on a target. Plan is nil in case the target is in sync with the
+	// desired state.
+	TargetResult struct {
+		Target  *Target
+		Changes []schema.Change
+		Plan    *Plan
+		Applied bool
+	}
+
+	// TargetError is returned by TargetGroup when it fails to plan,
+	// apply or gate a target. Later targets are not migrated.
+	TargetError struct {
+		Target *Target
+		Stage  string // plan, approve, apply or gate.
+		Err    error
+	}
+)
+
+// NewTargetGroup creates a new TargetGroup from the given targets. Targets are migrated
+// in the order they were given.
+func NewTargetGroup(targets []*Target, opts ...TargetGroupOption) (*TargetGroup, error) {
+	names := make(map[string]bool, len(targets))
+	for _, t := range targets {
+		switch {
+		case t.Driver == nil:
+			return nil, fmt.Errorf("sql/migrate: missing driver for target %q", t.Name)
+		case names[t.Name]:
+			return nil, fmt.Errorf("sql/migrate: duplicate target %q", t.Name)
+		}
+		names[t.Name] = true
+	}
+	g := &TargetGroup{targets: targets}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g, nil
+}
+
+// GroupWithPlanOptions sets the plan options used for planning and applying the changes.
+func GroupWithPlanOptions(opts ...PlanOption) TargetGroupOption {
+	return func(g *TargetGroup) {
+		g.planOpts = append(g.planOpts, opts...)
+	}
+}
+
+// GroupWithDiffOptions sets the diff options used for computing the changes.
+func GroupWithDiffOptions(opts ...schema.DiffOption) TargetGroupOption {
+	return func(g *TargetGroup) {
+		g.diffOpts = append(g.diffOpts, opts...)
+	}
+}
+
+// GroupWithApproval sets a callback that is called with the computed plan of each
+// target before it is applied. Returning an error stops the rollout, and the target
+// and its following targets are not migrated.
+func GroupWithApproval(f func(context.Context, *TargetResult) error) TargetGroupOption {
+	return func(g *TargetGroup) {
+		g.approve = f
+	}
+}
+
+// GroupWithGate sets a callback that is called after each target was migrated and
+// before moving to the next one. For example, for running health checks or waiting
+// for a bake time. Returning an error stops the rollout.
+func GroupWithGate(f func(context.Context, *TargetResult) error) TargetGroupOption {
+	return func(g *TargetGroup) {
+		g.gate = f
+	}
+}
+
+// Targets returns the targets of the group.
+func (g *TargetGroup) Targets() []*Target {
+	return g.targets
+}
+
+// Plan computes the plan for each target without applying it.
+func (g *TargetGroup) Plan(ctx context.Context, name string, to StateReader) ([]*TargetResult, error) {
+	results := make([]*TargetResult, 0, len(g.targets))
+	for _, t := range g.targets {
+		r, err := g.plan(ctx, name, t, to)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// Apply migrates the targets to the desired state one after the other. The plan of each
+// target is recomputed against its current state right before it is applied, as previous
+// stages might take time. The returned results describe the targets that were processed,
+// and an error of type TargetError is returned in case one of the stages failed.
+func (g *TargetGroup) Apply(ctx context.Context, name string, to StateReader) ([]*TargetResult, error) {
+	results := make([]*TargetResult, 0, len(g.targets))
+	for _, t := range g.targets {
+		if t.Current != nil {
+			return results, &TargetError{Target: t, Stage: "apply", Err: errors.New("target with a static current state is read-only")}
+		}
+		r, err := g.plan(ctx, name, t, to)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, r)
+		if r.Plan != nil {
+			if g.approve != nil {
+				if err := g.approve(ctx, r); err != nil {
+					return results, &TargetError{Target: t, Stage: "approve", Err: err}
+				}
+			}
+			if err := t.Driver.ApplyChanges(ctx, r.Changes, g.planOptions(t)...); err != nil {
+				return results, &TargetError{Target: t, Stage: "apply", Err: err}
+			}
+			r.Applied = true
+		}
+		if g.gate != nil {
+			if err := g.gate(ctx, r); err != nil {
+				return results, &TargetError{Target: t, Stage: "gate", Err: err}
+			}
+		}
+	}
+	return results, nil
+}
+
+// plan computes the changes and the plan for migrating the target to the desired state.
+func (g *TargetGroup) plan(ctx context.Context, name string, t *Target, to StateReader) (*TargetResult, error) {
+	changes, err := g.changes(ctx, t, to)
+	if err != nil {
+		return nil, &TargetError{Target: t, Stage: "plan", Err: err}
+	}
+	r := &TargetResult{Target: t, Changes: changes}
+	if len(changes) > 0 {
+		if r.Plan, err = t.Driver.PlanChanges(ctx, name, changes, g.planOptions(t)...); err != nil {
+			return nil, &TargetError{Target: t, Stage: "plan", Err: err}
+		}
+	}
+	return r, nil
+}
+
+// changes returns the changes between the current state of the target and the desired state.
+func (g *TargetGroup) changes(ctx context.Context, t *Target, to StateReader) ([]schema.Change, error) {
+	desired, err := to.ReadState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if t.Schema == "" {
+		current, err := g.currentRealm(ctx, t)
+		if err != nil {
+			return nil, err
+		}
+		return t.Driver.RealmDiff(current, desired, g.diffOpts...)
+	}
+	switch n := len(desired.Schemas); {
+	case n == 0:
+		return nil, errors.New("no schema was found in desired state")
+	case n > 1:
+		return nil, fmt.Errorf("%d schemas were found in desired state; expect 1", n)
+	}
+	current, err := g.currentSchema(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+	// The schema name of the target is controlled by the caller, and the desired
+	// schema is applied on it regardless of its name. See, TargetGroup.planOptions.
+	s1, s2 := *current, *desired.Schemas[0]
+	s1.Name = s2.Name
+	return t.Driver.SchemaDiff(&s1, &s2, g.diffOpts...)
+}
+
+// currentRealm returns the current state of the target realm.
+func (g *TargetGroup) currentRealm(ctx context.Context, t *Target) (*schema.Realm, error) {
+	if t.Current != nil {
+		return t.Current.ReadState(ctx)
+	}
+	return t.Driver.InspectRealm(ctx, &schema.InspectRealmOption{Exclude: t.Exclude})
+}
+
+// currentSchema returns the current state of the target schema.
+func (g *TargetGroup) currentSchema(ctx context.Context, t *Target) (*schema.Schema, error) {
+	if t.Current == nil {
+		return t.Driver.InspectSchema(ctx, t.Schema, &schema.InspectOptions{Exclude: t.Exclude})
+	}
+	r, err := t.Current.ReadState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := r.Schema(t.Schema)
+	if !ok {
+		return nil, fmt.Errorf("schema %q was not found in current state", t.Schema)
+	}
+	return s, nil
+}
+
+// planOptions returns the plan options of the given target. Targets that are limited
+// to a schema are planned with their schema as a qualifier, unless configured otherwise.
+func (g *TargetGroup) planOptions(t *Target) []PlanOption {
+	if t.Schema == "" {
+		return g.planOpts
+	}
+	return append([]PlanOption{func(o *PlanOptions) { o.SchemaQualifier = &t.Schema }}, g.planOpts...)
+}
+
+// Error implements the error interface.
+func (e *TargetError) Error() string {
+	return fmt.Sprintf("sql/migrate: %s target %q: %v", e.Stage, e.Target.Name, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *TargetError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,150 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlite"
+	"ariga.io/atlas/sql/sqltesting"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTargetGroup(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		staging = sqltesting.NewDriver(schema.NewRealm(schema.New("main")), sqltesting.WithDiffer(sqlite.DefaultDiff))
+		canary  = sqltesting.NewDriver(schema.NewRealm(schema.New("main").AddTables(
+			schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int")),
+		)), sqltesting.WithDiffer(sqlite.DefaultDiff))
+		prod = sqltesting.NewDriver(schema.NewRealm(schema.New("prod")), sqltesting.WithDiffer(sqlite.DefaultDiff))
+		to   = migrate.Realm(schema.NewRealm(schema.New("main").AddTables(
+			schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int")),
+		)))
+	)
+	_, err := migrate.NewTargetGroup([]*migrate.Target{{Name: "staging"}})
+	require.EqualError(t, err, `sql/migrate: missing driver for target "staging"`)
+	_, err = migrate.NewTargetGroup([]*migrate.Target{{Name: "staging", Driver: staging}, {Name: "staging", Driver: canary}})
+	require.EqualError(t, err, `sql/migrate: duplicate target "staging"`)
+
+	var (
+		stages []string
+		fail   error
+	)
+	g, err := migrate.NewTargetGroup(
+		[]*migrate.Target{
+			{Name: "staging", Driver: staging},
+			{Name: "canary", Driver: canary},
+			{Name: "prod", Driver: prod, Schema: "prod"},
+		},
+		migrate.GroupWithApproval(func(_ context.Context, r *migrate.TargetResult) error {
+			stages = append(stages, "approve "+r.Target.Name)
+			return nil
+		}),
+		migrate.GroupWithGate(func(_ context.Context, r *migrate.TargetResult) error {
+			stages = append(stages, "gate "+r.Target.Name)
+			return fail
+		}),
+	)
+	require.NoError(t, err)
+	require.Len(t, g.Targets(), 3)
+
+	results, err := g.Plan(ctx, "add_users", to)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	require.NotNil(t, results[0].Plan)
+	require.Nil(t, results[1].Plan, "canary is in sync")
+	require.Len(t, results[2].Changes, 1)
+	require.Empty(t, stages)
+	require.Empty(t, staging.Stmts())
+
+	// Gate fails after the first stage.
+	fail = errors.New("unhealthy")
+	results, err = g.Apply(ctx, "add_users", to)
+	require.EqualError(t, err, `sql/migrate: gate target "staging": unhealthy`)
+	var terr *migrate.TargetError
+	require.ErrorAs(t, err, &terr)
+	require.Equal(t, "staging", terr.Target.Name)
+	require.Len(t, results, 1)
+	require.True(t, results[0].Applied)
+	require.Equal(t, []string{"approve staging", "gate staging"}, stages)
+	require.Len(t, staging.Stmts(), 1)
+	require.Empty(t, prod.Stmts())
+
+	// Plans are recomputed on each stage.
+	fail, stages = nil, nil
+	results, err = g.Apply(ctx, "add_users", to)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	require.Nil(t, results[0].Plan, "staging was already migrated")
+	require.False(t, results[1].Applied)
+	require.True(t, results[2].Applied)
+	require.Equal(t, []string{"gate staging", "gate canary", "approve prod", "gate prod"}, stages)
+	s, err := prod.InspectSchema(ctx, "prod", nil)
+	require.NoError(t, err)
+	require.Len(t, s.Tables, 1)
+}
+
+func TestTargetGroup_Snapshot(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		codec = migrate.SnapshotCodec{
+			Marshal: sqlite.MarshalHCL.MarshalSpec,
+			Unmarshal: func(b []byte, r *schema.Realm) error {
+				return sqlite.EvalHCLBytes(b, r, nil)
+			},
+		}
+		prod = sqltesting.NewDriver(schema.NewRealm(schema.New("main").AddTables(
+			schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int")),
+		)), sqltesting.WithDiffer(sqlite.DefaultDiff))
+		dev = sqltesting.NewDriver(schema.NewRealm(), sqltesting.WithDiffer(sqlite.DefaultDiff))
+		to  = migrate.Realm(schema.NewRealm(schema.New("main").AddTables(
+			schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"), schema.NewIntColumn("age", "int")),
+		)))
+	)
+	_, err := migrate.TakeSnapshot(ctx, migrate.RealmConn(prod, nil), migrate.SnapshotCodec{})
+	require.EqualError(t, err, "sql/migrate: missing snapshot marshaler")
+	snap, err := migrate.TakeSnapshot(ctx, migrate.RealmConn(prod, nil), codec)
+	require.NoError(t, err)
+	require.Equal(t, migrate.StateFingerprint([]byte(snap.State)), snap.Fingerprint)
+
+	// Snapshots are verified on read.
+	b, err := json.Marshal(snap)
+	require.NoError(t, err)
+	snap, err = migrate.ReadSnapshot(b)
+	require.NoError(t, err)
+	_, err = migrate.ReadSnapshot([]byte(strings.Replace(string(b), "users", "posts", 1)))
+	require.ErrorContains(t, err, "sql/migrate: snapshot fingerprint mismatch")
+
+	// Snapshot targets are planned without their database, but cannot be applied.
+	g, err := migrate.NewTargetGroup([]*migrate.Target{
+		{Name: "prod", Driver: dev, Current: migrate.SnapshotState(snap, codec)},
+		{Name: "prod-main", Driver: dev, Schema: "main", Current: migrate.SnapshotState(snap, codec)},
+		{Name: "prod-other", Driver: dev, Schema: "other", Current: migrate.SnapshotState(snap, codec)},
+	})
+	require.NoError(t, err)
+	results, err := g.Plan(ctx, "add_age", to)
+	require.EqualError(t, err, `sql/migrate: plan target "prod-other": schema "other" was not found in current state`)
+	require.Len(t, results, 2)
+	for _, r := range results {
+		require.Len(t, r.Changes, 1)
+		m, ok := r.Changes[0].(*schema.ModifyTable)
+		require.True(t, ok)
+		require.Len(t, m.Changes, 1)
+		require.IsType(t, (*schema.AddColumn)(nil), m.Changes[0])
+		require.NotNil(t, r.Plan)
+	}
+	_, err = g.Apply(ctx, "add_age", to)
+	require.EqualError(t, err, `sql/migrate: apply target "prod": target with a static current state is read-only`)
+	require.Empty(t, dev.Stmts())
+	require.Empty(t, prod.Stmts())
+}
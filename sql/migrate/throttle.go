@@ -0,0 +1,142 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type (
+	// Throttle configures the rate in which an Executor executes statements. It is
+	// useful for managed databases (e.g., Aurora or Cloud SQL) that penalize bursts
+	// of DDL statements. Since the Executor records its progress after each executed
+	// statement, an execution that was interrupted while waiting (e.g., its context
+	// was canceled) resumes from the first statement that was not executed.
+	Throttle struct {
+		// Rate is the maximum number of statements executed per second.
+		// A zero value means the execution rate is not limited.
+		Rate float64
+		// Pause is the duration to wait after executing a heavy statement.
+		Pause time.Duration
+		// Heavy reports if the given statement is heavy. If nil,
+		// all statements are considered heavy.
+		Heavy func(*Stmt) bool
+		// Windows restrict the execution to the given daily time windows. Statements
+		// are started only inside one of the windows, and the Executor waits for the
+		// next window to open otherwise. An empty list means no time restrictions.
+		Windows []ThrottleWindow
+
+		next time.Time // Earliest time the next statement can be started.
+	}
+
+	// ThrottleWindow describes a daily time window. For example, the window
+	// between 22:00 and 04:00 UTC is defined as:
+	//
+	//	migrate.ThrottleWindow{Start: 22 * time.Hour, End: 4 * time.Hour}
+	ThrottleWindow struct {
+		// Start and End are the offsets from midnight the window opens and
+		// closes at. A window with Start after End spans over midnight.
+		Start, End time.Duration
+		// Location of the window. If nil, UTC is used.
+		Location *time.Location
+	}
+)
+
+// WithThrottle sets the Throttle to use when executing statements.
+func WithThrottle(t Throttle) ExecutorOption {
+	return func(ex *Executor) error {
+		if t.Rate < 0 || t.Pause < 0 {
+			return errors.New("sql/migrate: throttle rate and pause must not be negative")
+		}
+		for _, w := range t.Windows {
+			if w.Start < 0 || w.Start >= 24*time.Hour || w.End < 0 || w.End > 24*time.Hour || w.Start == w.End {
+				return fmt.Errorf("sql/migrate: invalid throttle window %s-%s", w.Start, w.End)
+			}
+		}
+		ex.throttle = &t
+		return nil
+	}
+}
+
+// wait blocks until the next statement can be executed, or the context is done.
+func (t *Throttle) wait(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+	for {
+		now := time.Now()
+		d := t.next.Sub(now)
+		if d <= 0 {
+			d = t.closed(now)
+		}
+		if d <= 0 {
+			return nil
+		}
+		timer := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("sql/migrate: throttled execution interrupted: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// done records the execution of the given statement.
+func (t *Throttle) done(s *Stmt, start time.Time) {
+	if t == nil {
+		return
+	}
+	var d time.Duration
+	if t.Rate > 0 {
+		d = time.Duration(float64(time.Second) / t.Rate)
+	}
+	t.next = start.Add(d)
+	if t.Pause > 0 && (t.Heavy == nil || t.Heavy(s)) {
+		if next := time.Now().Add(t.Pause); next.After(t.next) {
+			t.next = next
+		}
+	}
+}
+
+// closed returns the duration until the next window opens,
+// or zero if the given time is inside one of the windows.
+func (t *Throttle) closed(now time.Time) time.Duration {
+	var wait time.Duration
+	for i, w := range t.Windows {
+		d := w.opensIn(now)
+		if d == 0 {
+			return 0
+		}
+		if i == 0 || d < wait {
+			wait = d
+		}
+	}
+	return wait
+}
+
+// opensIn returns the duration until the window opens,
+// or zero if the given time is inside the window.
+func (w ThrottleWindow) opensIn(now time.Time) time.Duration {
+	loc := w.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	now = now.In(loc)
+	y, m, d := now.Date()
+	midnight := time.Date(y, m, d, 0, 0, 0, 0, loc)
+	switch off := now.Sub(midnight); {
+	case w.Start < w.End && off >= w.Start && off < w.End,
+		w.Start > w.End && (off >= w.Start || off < w.End):
+		return 0
+	case off < w.Start:
+		return midnight.Add(w.Start).Sub(now)
+	default:
+		return time.Date(y, m, d+1, 0, 0, 0, 0, loc).Add(w.Start).Sub(now)
+	}
+}
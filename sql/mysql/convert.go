@@ -13,6 +13,38 @@ import (
 	"ariga.io/atlas/sql/schema"
 )
 
+// literals defines how Go values are formatted as MySQL literals.
+var literals = &sqlx.LiteralFormat{
+	String:     QuoteString,
+	Bytes:      sqlx.HexBytes,
+	Bool:       strconv.FormatBool,
+	TimeLayout: "2006-01-02 15:04:05.999999",
+}
+
+// literalEscaper escapes the special characters of MySQL string literals.
+var literalEscaper = strings.NewReplacer(
+	"\x00", `\0`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\x1a", `\Z`,
+	"'", `\'`,
+	`"`, `\"`,
+	`\`, `\\`,
+)
+
+// QuoteString quotes the given string as a MySQL string literal,
+// and escapes its special characters using backslashes.
+func QuoteString(s string) string {
+	return "'" + literalEscaper.Replace(s) + "'"
+}
+
+// FormatLiteral formats the given Go value as a MySQL literal. Binary strings
+// are formatted as hexadecimal literals, time values without their time zone,
+// and JSON documents (e.g., maps) as string literals. Arrays are not supported.
+func FormatLiteral(v any) (string, error) {
+	return literals.Format(v)
+}
+
 // FormatType converts schema type to its column form in the database.
 // An error is returned if the type cannot be recognized.
 func FormatType(t schema.Type) (string, error) {
@@ -10,10 +10,14 @@ import (
 	"encoding/hex"
 	"fmt"
 	"reflect"
+	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 
+	"ariga.io/atlas/schemahcl"
 	"ariga.io/atlas/sql/internal/sqlx"
 	"ariga.io/atlas/sql/schema"
 )
@@ -72,8 +76,99 @@ func (*diff) RealmObjectDiff(_, _ *schema.Realm) ([]schema.Change, error) {
 
 // SchemaObjectDiff returns a changeset for migrating schema objects from
 // one state to the other.
-func (*diff) SchemaObjectDiff(_, _ *schema.Schema, _ *schema.DiffOptions) ([]schema.Change, error) {
-	return nil, nil
+func (*diff) SchemaObjectDiff(from, to *schema.Schema, _ *schema.DiffOptions) ([]schema.Change, error) {
+	var changes []schema.Change
+	// Drop or modify sequences.
+	for _, o1 := range from.Objects {
+		s1, ok := o1.(*Sequence)
+		if !ok {
+			continue // Unsupported object type.
+		}
+		s2, ok := findSequence(to, s1.Name)
+		switch {
+		case !ok:
+			changes = append(changes, &schema.DropObject{O: s1})
+		case !seqEqual(s1, s2):
+			changes = append(changes, &schema.ModifyObject{From: s1, To: s2})
+		}
+	}
+	// Add new sequences.
+	for _, o1 := range to.Objects {
+		if s1, ok := o1.(*Sequence); ok {
+			if _, ok := findSequence(from, s1.Name); !ok {
+				changes = append(changes, &schema.AddObject{O: s1})
+			}
+		}
+	}
+	return changes, nil
+}
+
+// findSequence returns the sequence with the given name from the schema, if exists.
+func findSequence(s *schema.Schema, name string) (*Sequence, bool) {
+	o, ok := s.Object(func(o schema.Object) bool {
+		seq, ok := o.(*Sequence)
+		return ok && seq.Name == name
+	})
+	if !ok {
+		return nil, false
+	}
+	return o.(*Sequence), true
+}
+
+// seqEqual reports if the two sequences are equal.
+func seqEqual(s1, s2 *Sequence) bool {
+	min1, max1 := seqBounds(s1)
+	min2, max2 := seqBounds(s2)
+	return seqStart(s1) == seqStart(s2) && seqIncrement(s1) == seqIncrement(s2) && min1 == min2 && max1 == max2 &&
+		seqCache(s1) == seqCache(s2) && s1.Cycle == s2.Cycle && seqComment(s1) == seqComment(s2)
+}
+
+// seqIncrement returns the increment of the sequence. Defaults to 1.
+func seqIncrement(s *Sequence) int64 {
+	if s.Increment == 0 {
+		return 1
+	}
+	return s.Increment
+}
+
+// seqBounds returns the minimum and maximum values of the sequence.
+func seqBounds(s *Sequence) (int64, int64) {
+	minv, maxv := seqMinMax(seqIncrement(s))
+	if s.Min != nil {
+		minv = *s.Min
+	}
+	if s.Max != nil {
+		maxv = *s.Max
+	}
+	return minv, maxv
+}
+
+// seqStart returns the start value of the sequence. Defaults to the minimum
+// value for ascending sequences, and the maximum value for descending ones.
+func seqStart(s *Sequence) int64 {
+	if s.Start != 0 {
+		return s.Start
+	}
+	minv, maxv := seqBounds(s)
+	if seqIncrement(s) < 0 {
+		return maxv
+	}
+	return minv
+}
+
+// seqCache returns the cache size of the sequence. Defaults to 1000.
+func seqCache(s *Sequence) int64 {
+	if s.Cache == 0 {
+		return defaultSeqCache
+	}
+	return s.Cache
+}
+
+// seqComment returns the comment of the sequence, if exists.
+func seqComment(s *Sequence) string {
+	var c schema.Comment
+	sqlx.Has(s.Attrs, &c)
+	return c.Text
 }
 
 // TableAttrDiff returns a changeset for migrating table attributes from one state to the other.
@@ -97,6 +192,12 @@ func (d *diff) TableAttrDiff(from, to *schema.Table, opts *schema.DiffOptions) (
 	if change := d.systemVerChange(from.Attrs, to.Attrs); change != noChange {
 		changes = append(changes, change)
 	}
+	changes = append(changes, d.tableOptionsChanges(from.Attrs, to.Attrs)...)
+	extra, err := diffOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	changes = append(changes, histogramChanges(from, to, extra.Histograms)...)
 	if !d.SupportsCheck() && sqlx.Has(to.Attrs, &schema.Check{}) {
 		return nil, fmt.Errorf("version %q does not support CHECK constraints", d.V)
 	}
@@ -105,7 +206,7 @@ func (d *diff) TableAttrDiff(from, to *schema.Table, opts *schema.DiffOptions) (
 	// also cannot be dropped using "DROP CONSTRAINTS", but can be modified and dropped
 	// using "MODIFY COLUMN".
 	var checks []schema.Change
-	for _, c := range sqlx.CheckDiffMode(from, to, opts.Mode, func(c1, c2 *schema.Check) bool {
+	for _, c := range sqlx.CheckDiffMode(from, to, opts.Mode, exprNormalizer, func(c1, c2 *schema.Check) bool {
 		return enforced(c1.Attrs) == enforced(c2.Attrs)
 	}) {
 		drop, ok := c.(*schema.DropCheck)
@@ -159,6 +260,9 @@ func (d *diff) ColumnChange(fromT *schema.Table, from, to *schema.Column, _ *sch
 	if changed {
 		change |= schema.ChangeCollate
 	}
+	if sqlx.Has(from.Attrs, &Invisible{}) != sqlx.Has(to.Attrs, &Invisible{}) || onUpdateChanged(from.Attrs, to.Attrs) {
+		change |= schema.ChangeAttr
+	}
 	if change.Is(schema.NoChange) {
 		return sqlx.NoChange, nil
 	}
@@ -204,9 +308,13 @@ func (*diff) IndexAttrChanged(from, to []schema.Attr) bool {
 	}
 	var (
 		fromP, toP     IndexParser
+		fromK, toK     KeyBlockSize
 		fromHas, toHas = sqlx.Has(from, &fromP), sqlx.Has(to, &toP)
 	)
-	return fromHas != toHas || (fromHas && fromP.P != toP.P)
+	// A missing KEY_BLOCK_SIZE is equal to 0 (the default).
+	sqlx.Has(from, &fromK)
+	sqlx.Has(to, &toK)
+	return fromHas != toHas || (fromHas && fromP.P != toP.P) || fromK.V != toK.V
 }
 
 // IndexPartAttrChanged reports if the index-part attributes (collation or prefix) were changed.
@@ -240,6 +348,19 @@ func (d *diff) Normalize(from, to *schema.Table, opts *schema.DiffOptions) error
 	if opts.Mode.Is(schema.DiffModeNormalized) {
 		return nil // already normalized
 	}
+	extra, err := diffOptions(opts)
+	if err != nil {
+		return err
+	}
+	// Tables that were created without a primary key while the sql_generate_invisible_primary_key
+	// system variable is enabled, are given a generated invisible primary key (GIPK) by the server.
+	// Unless requested otherwise, ignore the GIPK if it was not declared in the desired state, as it
+	// cannot be dropped without replacing it with another primary key.
+	if !extra.ExplicitGIPK && sqlx.Has(from.Attrs, &GeneratedPK{}) && to.PrimaryKey == nil {
+		if _, ok := to.Column(GIPKColumn); !ok {
+			dropGIPK(from)
+		}
+	}
 	indexes := make([]*schema.Index, 0, len(from.Indexes))
 	for _, idx := range from.Indexes {
 		// MySQL requires that foreign key columns be indexed; Therefore, if the child
@@ -376,6 +497,44 @@ func (d *diff) systemVerChange(from, to []schema.Attr) schema.Change {
 	}
 }
 
+// tableOptionsChanges returns the schema changes for migrating the table options
+// (e.g., ROW_FORMAT or COMPRESSION) in case they were changed. Options that were
+// removed from the desired state are reset to their default values.
+func (*diff) tableOptionsChanges(from, to []schema.Attr) []schema.Change {
+	var changes []schema.Change
+	for _, o := range []struct {
+		from, to, def schema.Attr
+		value         func(schema.Attr) string
+	}{
+		{
+			from: &RowFormat{}, to: &RowFormat{}, def: &RowFormat{V: "DEFAULT"},
+			value: func(a schema.Attr) string { return strings.ToUpper(a.(*RowFormat).V) },
+		},
+		{
+			from: &KeyBlockSize{}, to: &KeyBlockSize{}, def: &KeyBlockSize{V: 0},
+			value: func(a schema.Attr) string { return strconv.FormatInt(a.(*KeyBlockSize).V, 10) },
+		},
+		{
+			from: &StatsPersistent{}, to: &StatsPersistent{}, def: &StatsPersistent{V: "DEFAULT"},
+			value: func(a schema.Attr) string { return strings.ToUpper(a.(*StatsPersistent).V) },
+		},
+		{
+			from: &Compression{}, to: &Compression{}, def: &Compression{V: "None"},
+			value: func(a schema.Attr) string { return strings.ToUpper(a.(*Compression).V) },
+		},
+	} {
+		switch fromHas, toHas := sqlx.Has(from, o.from), sqlx.Has(to, o.to); {
+		case fromHas && toHas && o.value(o.from) != o.value(o.to):
+			changes = append(changes, &schema.ModifyAttr{From: o.from, To: o.to})
+		case fromHas && !toHas && o.value(o.from) != o.value(o.def):
+			changes = append(changes, &schema.ModifyAttr{From: o.from, To: o.def})
+		case !fromHas && toHas && o.value(o.to) != o.value(o.def):
+			changes = append(changes, &schema.AddAttr{A: o.to})
+		}
+	}
+	return changes
+}
+
 // charsetChange returns the schema change for migrating the collation if
 // it was changed, and it is not the default attribute inherited from its parent.
 func (*diff) charsetChange(from, top, to []schema.Attr) schema.Change {
@@ -445,6 +604,117 @@ func (d *diff) columnCollateChanged(fromT *schema.Table, from, to *schema.Column
 
 }
 
+// DiffOptions defines MySQL specific schema diffing process.
+type DiffOptions struct {
+	// ExplicitGIPK indicates that generated invisible primary keys are expected to be
+	// declared in the desired state, and are diffed like any other column and key. By
+	// default, GIPKs that are not declared in the desired state are ignored.
+	ExplicitGIPK bool `spec:"explicit_gipk"`
+	// Histograms indicates that column histograms that exist only in the current state
+	// should be dropped. By default, histograms created by ANALYZE TABLE are ignored if
+	// they are not declared in the desired state.
+	Histograms bool `spec:"histograms"`
+}
+
+// diffOptions returns the MySQL specific diff options
+// that were defined in the given schema.DiffOptions.
+func diffOptions(opts *schema.DiffOptions) (*DiffOptions, error) {
+	var extra DiffOptions
+	if opts == nil {
+		return &extra, nil
+	}
+	switch ex := opts.Extra.(type) {
+	case nil:
+	case *DiffOptions:
+		extra = *ex
+	case schemahcl.DefaultExtension:
+		if err := ex.Extra.As(&extra); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("mysql: unexpected DiffOptions.Extra type %T", opts.Extra)
+	}
+	return &extra, nil
+}
+
+// dropGIPK removes the generated invisible primary key from the table.
+func dropGIPK(t *schema.Table) {
+	columns := make([]*schema.Column, 0, len(t.Columns))
+	for _, c := range t.Columns {
+		if c.Name != GIPKColumn {
+			columns = append(columns, c)
+		}
+	}
+	attrs := make([]schema.Attr, 0, len(t.Attrs))
+	for _, a := range t.Attrs {
+		switch a.(type) {
+		// The AUTO_INCREMENT counter belongs to the GIPK column.
+		case *GeneratedPK, *AutoIncrement:
+		default:
+			attrs = append(attrs, a)
+		}
+	}
+	t.Columns, t.Attrs, t.PrimaryKey = columns, attrs, nil
+}
+
+// histogramChanges returns the schema changes for migrating the column histograms
+// of the table. Histograms that exist only in the current state are dropped only if
+// it was requested (see DiffOptions.Histograms), and their columns were not dropped,
+// as dropping a column removes its histogram as well.
+func histogramChanges(from, to *schema.Table, drop bool) []schema.Change {
+	var (
+		changes  []schema.Change
+		fromH    = tableHistograms(from)
+		toH      = tableHistograms(to)
+		toColumn = func(name string) bool { _, ok := to.Column(name); return ok }
+	)
+	for _, h1 := range fromH {
+		h2, ok := toH[h1.C.Name]
+		switch {
+		case !ok && drop && toColumn(h1.C.Name):
+			changes = append(changes, &schema.DropAttr{A: h1})
+		case ok && h1.Buckets != h2.Buckets:
+			changes = append(changes, &schema.ModifyAttr{From: h1, To: h2})
+		}
+	}
+	for _, h2 := range toH {
+		if _, ok := fromH[h2.C.Name]; !ok {
+			changes = append(changes, &schema.AddAttr{A: h2})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return histogramOf(changes[i]).C.Name < histogramOf(changes[j]).C.Name
+	})
+	return changes
+}
+
+// tableHistograms returns the histograms of the table mapped by their column names.
+func tableHistograms(t *schema.Table) map[string]*Histogram {
+	hs := make(map[string]*Histogram)
+	for _, a := range t.Attrs {
+		if h, ok := a.(*Histogram); ok && h.C != nil {
+			hs[h.C.Name] = h
+		}
+	}
+	return hs
+}
+
+// histogramOf returns the histogram of the given change, or nil
+// if the change does not describe a histogram change.
+func histogramOf(c schema.Change) *Histogram {
+	var a schema.Attr
+	switch c := c.(type) {
+	case *schema.AddAttr:
+		a = c.A
+	case *schema.DropAttr:
+		a = c.A
+	case *schema.ModifyAttr:
+		a = c.To
+	}
+	h, _ := a.(*Histogram)
+	return h
+}
+
 // autoIncChange returns the schema change for changing the AUTO_INCREMENT
 // attribute in case it is not the default.
 func (*diff) autoIncChange(from, to []schema.Attr) schema.Change {
@@ -548,6 +818,13 @@ func (d *diff) defaultChanged(from, to *schema.Column) (bool, error) {
 	if d1 == d2 {
 		return false, nil
 	}
+	// Compare expressions, as they may be returned differently by the database.
+	// e.g., "(now() + interval 1 day)" vs "NOW() + INTERVAL 1 DAY".
+	_, fromX := from.Default.(*schema.RawExpr)
+	_, toX := to.Default.(*schema.RawExpr)
+	if fromX && toX && exprNormalizer.Equal(d1, d2) {
+		return false, nil
+	}
 	switch from.Type.Type.(type) {
 	case *schema.BinaryType:
 		a, err1 := binValue(d1)
@@ -586,7 +863,7 @@ func (*diff) generatedChanged(from, to *schema.Column) (bool, error) {
 		fromX, toX     schema.GeneratedExpr
 		fromHas, toHas = sqlx.Has(from.Attrs, &fromX), sqlx.Has(to.Attrs, &toX)
 	)
-	if !fromHas && !toHas || fromHas && toHas && sqlx.MayWrap(fromX.Expr) == sqlx.MayWrap(toX.Expr) && storedOrVirtual(fromX.Type) == storedOrVirtual(toX.Type) {
+	if !fromHas && !toHas || fromHas && toHas && exprNormalizer.Equal(fromX.Expr, toX.Expr) && storedOrVirtual(fromX.Type) == storedOrVirtual(toX.Type) {
 		return false, nil
 	}
 	// Checking validity of the change is done
@@ -594,6 +871,73 @@ func (*diff) generatedChanged(from, to *schema.Column) (bool, error) {
 	return true, nil
 }
 
+// onUpdateChanged reports if the ON UPDATE clause of a column was changed.
+func onUpdateChanged(from, to []schema.Attr) bool {
+	var (
+		fromO, toO     OnUpdate
+		fromHas, toHas = sqlx.Has(from, &fromO), sqlx.Has(to, &toO)
+	)
+	if fromHas != toHas {
+		return true
+	}
+	if !fromHas || strings.EqualFold(fromO.A, toO.A) {
+		return false
+	}
+	p1, ok1 := onUpdatePrecision(fromO.A)
+	p2, ok2 := onUpdatePrecision(toO.A)
+	return !ok1 || !ok2 || p1 != p2
+}
+
+// reOnUpdateNow matches the CURRENT_TIMESTAMP function and its synonyms.
+var reOnUpdateNow = regexp.MustCompile(`(?i)^\s*(?:current_timestamp|now|localtime|localtimestamp)\s*(?:\(\s*(\d?)\s*\))?\s*$`)
+
+// onUpdatePrecision returns the fractional seconds precision of the
+// CURRENT_TIMESTAMP expression used in an ON UPDATE clause.
+func onUpdatePrecision(x string) (int, bool) {
+	m := reOnUpdateNow.FindStringSubmatch(x)
+	switch {
+	case m == nil:
+		return 0, false
+	case m[1] == "":
+		return 0, true
+	default:
+		p, err := strconv.Atoi(m[1])
+		return p, err == nil
+	}
+}
+
+// exprNormalizer normalizes expressions before they are compared. MySQL rewrites
+// expressions when they are stored, quotes identifiers and wraps them with parentheses,
+// and adds character set introducers to string literals. e.g., "(`a` = _utf8mb4'x')".
+var exprNormalizer = &sqlx.ExprNormalizer{
+	IdentQuotes:     "`",
+	TrimIntroducers: true,
+	Rewrite:         jsonOperators,
+}
+
+// jsonOperators rewrites the column-path operators of JSON columns to the function calls that
+// MySQL stores them as. e.g., "c->>'$.a'" is stored as "json_unquote(json_extract(`c`,'$.a'))".
+func jsonOperators(tokens []string) []string {
+	for i := 1; i < len(tokens)-1; i++ {
+		op, path := tokens[i], tokens[i+1]
+		if op != "->" && op != "->>" || !strings.HasPrefix(path, "'") {
+			continue
+		}
+		call := []string{"json_extract", "(", tokens[i-1], ",", path, ")"}
+		if op == "->>" {
+			call = slices.Concat([]string{"json_unquote", "("}, call, []string{")"})
+		}
+		tokens = slices.Concat(tokens[:i-1], call, tokens[i+2:])
+		i += len(call) - 2
+	}
+	return tokens
+}
+
+// ExprEqual implements the sqlx.ExprComparer interface.
+func (*diff) ExprEqual(x1, x2 string) bool {
+	return exprNormalizer.Equal(x1, x2)
+}
+
 // equalIntValues report if the 2 int default values are ~equal.
 // Note that default expression are not supported atm.
 func (d *diff) equalIntValues(x1, x2 string) bool {
@@ -7,6 +7,8 @@
 package mysql
 
 import (
+	"context"
+	"strings"
 	"testing"
 
 	"ariga.io/atlas/sql/schema"
@@ -39,6 +41,17 @@ func TestDiff_TableDiff(t *testing.T) {
 			from: &schema.Table{Name: "users", Schema: &schema.Schema{Name: "public"}, Columns: []*schema.Column{{Name: "enum", Default: &schema.RawExpr{X: "'A'"}, Type: &schema.ColumnType{Type: &schema.EnumType{Values: []string{"A"}}}}}},
 			to:   &schema.Table{Name: "users", Columns: []*schema.Column{{Name: "enum", Default: &schema.RawExpr{X: `"A"`}, Type: &schema.ColumnType{Type: &schema.EnumType{Values: []string{"A"}}}}}},
 		},
+		{
+			name: "normalized expressions",
+			from: &schema.Table{Name: "users", Schema: &schema.Schema{Name: "public"}, Columns: []*schema.Column{
+				{Name: "d", Default: &schema.RawExpr{X: "(curdate() + interval 1 day)"}, Type: &schema.ColumnType{Type: &schema.TimeType{T: "date"}}},
+				{Name: "g", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "int"}}, Attrs: []schema.Attr{&schema.GeneratedExpr{Expr: "(`a` * 2)", Type: "VIRTUAL"}}},
+			}},
+			to: &schema.Table{Name: "users", Columns: []*schema.Column{
+				{Name: "d", Default: &schema.RawExpr{X: "CURDATE() + INTERVAL 1 DAY"}, Type: &schema.ColumnType{Type: &schema.TimeType{T: "date"}}},
+				{Name: "g", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "int"}}, Attrs: []schema.Attr{&schema.GeneratedExpr{Expr: "a*2", Type: "VIRTUAL"}}},
+			}},
+		},
 		{
 			name: "modify counter",
 			from: &schema.Table{Name: "users", Schema: &schema.Schema{Name: "public"}, Attrs: []schema.Attr{&AutoIncrement{V: 1}}},
@@ -92,6 +105,24 @@ func TestDiff_TableDiff(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "table options",
+			from: &schema.Table{Name: "users", Schema: &schema.Schema{Name: "public"}, Attrs: []schema.Attr{&RowFormat{V: "DYNAMIC"}, &KeyBlockSize{V: 8}, &Compression{V: "zlib"}}},
+			to:   &schema.Table{Name: "users", Schema: &schema.Schema{Name: "public"}, Attrs: []schema.Attr{&RowFormat{V: "compressed"}, &StatsPersistent{V: "1"}, &Compression{V: "ZLIB"}}},
+			wantChanges: []schema.Change{
+				&schema.ModifyAttr{
+					From: &RowFormat{V: "DYNAMIC"},
+					To:   &RowFormat{V: "compressed"},
+				},
+				&schema.ModifyAttr{
+					From: &KeyBlockSize{V: 8},
+					To:   &KeyBlockSize{V: 0},
+				},
+				&schema.AddAttr{
+					A: &StatsPersistent{V: "1"},
+				},
+			},
+		},
 		// The desired state has no engine specified, and the current state is not the default.
 		{
 			name: "engine changed",
@@ -432,6 +463,44 @@ func TestDiff_TableDiff(t *testing.T) {
 				},
 			}
 		}(),
+		func() testcase {
+			var (
+				s    = schema.New("public")
+				ts   = func(name string) *schema.Column { return schema.NewTimeColumn(name, TypeTimestamp) }
+				from = schema.NewTable("t1").
+					SetSchema(s).
+					AddColumns(
+						ts("c1"),
+						ts("c2").AddAttrs(&OnUpdate{A: "CURRENT_TIMESTAMP"}),
+						ts("c3").AddAttrs(&OnUpdate{A: "CURRENT_TIMESTAMP"}),
+						ts("c4").AddAttrs(&OnUpdate{A: "current_timestamp()"}),
+						ts("c5").AddAttrs(&OnUpdate{A: "CURRENT_TIMESTAMP(3)"}),
+					)
+				to = schema.NewTable("t1").
+					SetSchema(s).
+					AddColumns(
+						// Add ON UPDATE.
+						ts("c1").AddAttrs(&OnUpdate{A: "CURRENT_TIMESTAMP"}),
+						// Drop ON UPDATE.
+						ts("c2"),
+						// No change.
+						ts("c3").AddAttrs(&OnUpdate{A: "now()"}),
+						ts("c4").AddAttrs(&OnUpdate{A: "CURRENT_TIMESTAMP"}),
+						// Modify precision.
+						ts("c5").AddAttrs(&OnUpdate{A: "CURRENT_TIMESTAMP(6)"}),
+					)
+			)
+			return testcase{
+				name: "modify column on update",
+				from: from,
+				to:   to,
+				wantChanges: []schema.Change{
+					&schema.ModifyColumn{From: from.Columns[0], To: to.Columns[0], Change: schema.ChangeAttr},
+					&schema.ModifyColumn{From: from.Columns[1], To: to.Columns[1], Change: schema.ChangeAttr},
+					&schema.ModifyColumn{From: from.Columns[4], To: to.Columns[4], Change: schema.ChangeAttr},
+				},
+			}
+		}(),
 		func() testcase {
 			var (
 				from = &schema.Table{
@@ -465,6 +534,8 @@ func TestDiff_TableDiff(t *testing.T) {
 				{Name: "c1_prefix", Table: from, Parts: []*schema.IndexPart{{SeqNo: 1, C: from.Columns[1], Attrs: []schema.Attr{&SubPart{Len: 50}}}}},
 				{Name: "c1_desc", Table: from, Parts: []*schema.IndexPart{{SeqNo: 1, C: from.Columns[1]}}},
 				{Name: "parser", Table: from, Parts: []*schema.IndexPart{{SeqNo: 1, C: from.Columns[3]}}, Attrs: []schema.Attr{&IndexType{T: IndexTypeFullText}, &IndexParser{P: "ngram"}}},
+				{Name: "block", Table: from, Parts: []*schema.IndexPart{{SeqNo: 1, C: from.Columns[2]}}, Attrs: []schema.Attr{&KeyBlockSize{V: 8}}},
+				{Name: "block_default", Table: from, Parts: []*schema.IndexPart{{SeqNo: 1, C: from.Columns[2]}}, Attrs: []schema.Attr{&KeyBlockSize{V: 0}}},
 			}
 			to.Indexes = []*schema.Index{
 				{Name: "c1_index", Table: from, Parts: []*schema.IndexPart{{SeqNo: 1, C: from.Columns[0]}}},
@@ -472,6 +543,8 @@ func TestDiff_TableDiff(t *testing.T) {
 				{Name: "c1_prefix", Table: from, Parts: []*schema.IndexPart{{SeqNo: 1, C: from.Columns[0], Attrs: []schema.Attr{&SubPart{Len: 100}}}}},
 				{Name: "c1_desc", Table: from, Parts: []*schema.IndexPart{{SeqNo: 1, C: from.Columns[1], Desc: true}}},
 				{Name: "parser", Table: from, Parts: []*schema.IndexPart{{SeqNo: 1, C: from.Columns[3]}}, Attrs: []schema.Attr{&IndexType{T: IndexTypeFullText}}},
+				{Name: "block", Table: from, Parts: []*schema.IndexPart{{SeqNo: 1, C: from.Columns[2]}}, Attrs: []schema.Attr{&KeyBlockSize{V: 4}}},
+				{Name: "block_default", Table: from, Parts: []*schema.IndexPart{{SeqNo: 1, C: from.Columns[2]}}},
 			}
 			return testcase{
 				name: "indexes",
@@ -483,6 +556,7 @@ func TestDiff_TableDiff(t *testing.T) {
 					&schema.ModifyIndex{From: from.Indexes[2], To: to.Indexes[2], Change: schema.ChangeParts},
 					&schema.ModifyIndex{From: from.Indexes[3], To: to.Indexes[3], Change: schema.ChangeParts},
 					&schema.ModifyIndex{From: from.Indexes[4], To: to.Indexes[4], Change: schema.ChangeAttr},
+					&schema.ModifyIndex{From: from.Indexes[5], To: to.Indexes[5], Change: schema.ChangeAttr},
 					&schema.AddIndex{I: to.Indexes[1]},
 				},
 			}
@@ -626,6 +700,164 @@ func TestDiff_UnsupportedChecks(t *testing.T) {
 	require.EqualError(t, err, `version "5.6.35" does not support CHECK constraints`)
 }
 
+func TestDiff_ColumnOrder(t *testing.T) {
+	var (
+		s    = schema.New("public")
+		from = schema.NewTable("t").SetSchema(s).AddColumns(
+			schema.NewIntColumn("a", "int"),
+			schema.NewIntColumn("b", "int"),
+			schema.NewIntColumn("c", "int"),
+		)
+		to = schema.NewTable("t").SetSchema(s).AddColumns(
+			schema.NewIntColumn("c", "int"),
+			schema.NewIntColumn("a", "int"),
+			schema.NewIntColumn("d", "int"),
+			schema.NewIntColumn("b", "int"),
+		)
+	)
+	// Column order is ignored by default.
+	changes, err := DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Empty(t, changes[0].(*schema.AddColumn).Extra)
+
+	changes, err = DefaultDiff.TableDiff(from, to, schema.DiffColumnOrder(nil))
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	m, ok := changes[0].(*schema.ModifyColumn)
+	require.True(t, ok)
+	require.Equal(t, "c", m.To.Name)
+	require.Equal(t, schema.ChangePosition, m.Change)
+	require.Equal(t, []schema.Clause{&schema.ColumnPosition{}}, m.Extra)
+	add, ok := changes[1].(*schema.AddColumn)
+	require.True(t, ok)
+	require.Equal(t, []schema.Clause{&schema.ColumnPosition{After: to.Columns[1]}}, add.Extra)
+
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.ModifyTable{T: to, Changes: changes}})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, "ALTER TABLE `public`.`t` MODIFY COLUMN `c` int NOT NULL FIRST, ADD COLUMN `d` int NOT NULL AFTER `a`", plan.Changes[0].Cmd)
+	require.False(t, plan.Reversible)
+}
+
+func TestDiff_GIPK(t *testing.T) {
+	var (
+		s    = schema.New("public")
+		from = schema.NewTable("t").SetSchema(s).
+			AddColumns(
+				schema.NewColumn(GIPKColumn).SetType(&schema.IntegerType{T: TypeBigInt, Unsigned: true}).AddAttrs(&AutoIncrement{}, &Invisible{}),
+				schema.NewIntColumn("a", "int"),
+			).
+			AddAttrs(&GeneratedPK{}, &AutoIncrement{V: 10})
+		to = schema.NewTable("t").SetSchema(s).AddColumns(schema.NewIntColumn("a", "int"))
+	)
+	from.SetPrimaryKey(schema.NewPrimaryKey(from.Columns[0]))
+	// GIPKs that are not declared in the desired state are ignored.
+	current := *from
+	changes, err := DefaultDiff.TableDiff(&current, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	changes, err = DefaultDiff.TableDiff(from, to, func(o *schema.DiffOptions) {
+		o.Extra = &DiffOptions{ExplicitGIPK: true}
+	})
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	require.IsType(t, &schema.DropColumn{}, changes[0])
+	require.IsType(t, &schema.DropPrimaryKey{}, changes[1])
+
+	// Declared explicitly.
+	to = schema.NewTable("t").SetSchema(s).AddColumns(
+		schema.NewColumn(GIPKColumn).SetType(&schema.IntegerType{T: TypeBigInt, Unsigned: true}).AddAttrs(&AutoIncrement{}),
+		schema.NewIntColumn("a", "int"),
+	)
+	to.SetPrimaryKey(schema.NewPrimaryKey(to.Columns[0]))
+	changes, err = DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, schema.ChangeAttr, changes[0].(*schema.ModifyColumn).Change)
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.ModifyTable{T: to, Changes: changes}})
+	require.NoError(t, err)
+	require.Equal(t, "ALTER TABLE `public`.`t` MODIFY COLUMN `my_row_id` bigint unsigned NOT NULL AUTO_INCREMENT", plan.Changes[0].Cmd)
+	require.Equal(t, "ALTER TABLE `public`.`t` MODIFY COLUMN `my_row_id` bigint unsigned NOT NULL AUTO_INCREMENT INVISIBLE", plan.Changes[0].Reverse)
+}
+
+func TestDiff_Histograms(t *testing.T) {
+	var (
+		s    = schema.New("public")
+		from = schema.NewTable("t").SetSchema(s).AddColumns(schema.NewIntColumn("a", "int"), schema.NewIntColumn("b", "int"))
+		to   = schema.NewTable("t").SetSchema(s).AddColumns(schema.NewIntColumn("a", "int"), schema.NewIntColumn("b", "int"))
+	)
+	// Histograms created by ANALYZE TABLE are ignored by default.
+	from.AddAttrs(&Histogram{C: from.Columns[0], Buckets: 100})
+	changes, err := DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+	changes, err = DefaultDiff.TableDiff(from, to, func(o *schema.DiffOptions) {
+		o.Extra = &DiffOptions{Histograms: true}
+	})
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{&schema.DropAttr{A: from.Attrs[0]}}, changes)
+
+	to.AddAttrs(&Histogram{C: to.Columns[0], Buckets: 64}, &Histogram{C: to.Columns[1], Buckets: 16})
+	changes, err = DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.ModifyAttr{From: from.Attrs[0], To: to.Attrs[0]},
+		&schema.AddAttr{A: to.Attrs[1]},
+	}, changes)
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.ModifyTable{T: to, Changes: changes}})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, "ANALYZE TABLE `public`.`t` UPDATE HISTOGRAM ON `a` WITH 64 BUCKETS", plan.Changes[0].Cmd)
+	require.Equal(t, "ANALYZE TABLE `public`.`t` UPDATE HISTOGRAM ON `a` WITH 100 BUCKETS", plan.Changes[0].Reverse)
+	require.Equal(t, "ANALYZE TABLE `public`.`t` UPDATE HISTOGRAM ON `b` WITH 16 BUCKETS", plan.Changes[1].Cmd)
+	require.Equal(t, "ANALYZE TABLE `public`.`t` DROP HISTOGRAM ON `b`", plan.Changes[1].Reverse)
+
+	_, err = DefaultDiff.TableDiff(from, to, func(o *schema.DiffOptions) { o.Extra = 1 })
+	require.EqualError(t, err, "mysql: unexpected DiffOptions.Extra type int")
+}
+
+func TestDiff_AttrComparator(t *testing.T) {
+	var (
+		s    = schema.New("public")
+		from = schema.NewTable("t").SetSchema(s).SetComment("old").AddColumns(
+			schema.NewIntColumn("a", "int").SetComment("old"),
+			schema.NewTimeColumn("b", "timestamp").SetDefault(&schema.RawExpr{X: "now()"}),
+		)
+		to = schema.NewTable("t").SetSchema(s).SetComment("new").AddColumns(
+			schema.NewIntColumn("a", "int"),
+			schema.NewTimeColumn("b", "timestamp").SetDefault(&schema.RawExpr{X: "CURRENT_TIMESTAMP"}),
+		)
+	)
+	changes, err := DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+
+	changes, err = DefaultDiff.TableDiff(from, to,
+		schema.DiffAttrComparator(func(_, _ *schema.Comment) bool {
+			return true
+		}),
+	)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, schema.ChangeDefault, changes[0].(*schema.ModifyColumn).Change)
+
+	changes, err = DefaultDiff.TableDiff(from, to,
+		schema.DiffAttrComparator(func(_, _ *schema.Comment) bool {
+			return true
+		}),
+		schema.DiffAttrComparator(func(from, to *schema.RawExpr) bool {
+			now := func(x *schema.RawExpr) bool {
+				return x != nil && (strings.EqualFold(x.X, "now()") || strings.EqualFold(x.X, "CURRENT_TIMESTAMP"))
+			}
+			return now(from) && now(to)
+		}),
+	)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
 func TestDiff_SchemaDiff(t *testing.T) {
 	db, m, err := sqlmock.New()
 	require.NoError(t, err)
@@ -39,9 +39,10 @@ type (
 		schema string
 		// System variables that are set on `Open`.
 		mysqlversion.V
-		collate string
-		charset string
-		lcnames int
+		collate  string
+		charset  string
+		lcnames  int
+		timezone string
 	}
 )
 
@@ -77,26 +78,26 @@ func init() {
 
 // Open opens a new MySQL driver.
 func Open(db schema.ExecQuerier) (migrate.Driver, error) {
-	c := &conn{ExecQuerier: db}
+	c := &conn{ExecQuerier: sqlx.WithErrorKind(db, errorKind)}
 	rows, err := db.QueryContext(context.Background(), variablesQuery)
 	if err != nil {
 		return nil, fmt.Errorf("mysql: query system variables: %w", err)
 	}
-	if err := sqlx.ScanOne(rows, &c.V, &c.collate, &c.charset, &c.lcnames); err != nil {
+	if err := sqlx.ScanOne(rows, &c.V, &c.collate, &c.charset, &c.lcnames, &c.timezone); err != nil {
 		return nil, fmt.Errorf("mysql: scan system variables: %w", err)
 	}
 	if c.TiDB() {
 		return &Driver{
 			conn:        c,
 			Differ:      &sqlx.Diff{DiffDriver: &tdiff{diff{conn: c}}},
-			Inspector:   &tinspect{inspect{c}},
+			Inspector:   &sqlx.PartialInspect{Inspector: &tinspect{inspect{c}}},
 			PlanApplier: &tplanApply{planApply{c}},
 		}, nil
 	}
 	return &Driver{
 		conn:        c,
 		Differ:      &sqlx.Diff{DiffDriver: &diff{conn: c}},
-		Inspector:   &inspect{c},
+		Inspector:   &sqlx.PartialInspect{Inspector: &inspect{c}},
 		PlanApplier: &planApply{c},
 	}, nil
 }
@@ -126,6 +127,24 @@ func opener(name string) sqlclient.OpenerFunc {
 	}
 }
 
+// serverInfo returns the information of the connected server.
+func (c *conn) serverInfo() *schema.ServerInfo {
+	info := &schema.ServerInfo{
+		Flavor:    DriverName,
+		Version:   string(c.V),
+		Charset:   c.charset,
+		Collation: c.collate,
+		TimeZone:  c.timezone,
+	}
+	switch {
+	case c.Maria():
+		info.Flavor = DriverMaria
+	case c.TiDB():
+		info.Flavor = "tidb"
+	}
+	return info
+}
+
 // NormalizeRealm returns the normal representation of the given database.
 func (d *Driver) NormalizeRealm(ctx context.Context, r *schema.Realm) (*schema.Realm, error) {
 	return (&sqlx.DevDriver{Driver: d}).NormalizeRealm(ctx, r)
@@ -136,6 +155,11 @@ func (d *Driver) NormalizeSchema(ctx context.Context, s *schema.Schema) (*schema
 	return (&sqlx.DevDriver{Driver: d}).NormalizeSchema(ctx, s)
 }
 
+// NormalizeSchemas returns the normal representation of the given schemas.
+func (d *Driver) NormalizeSchemas(ctx context.Context, schemas ...*schema.Schema) ([]*schema.Schema, error) {
+	return (&sqlx.DevDriver{Driver: d}).NormalizeSchemas(ctx, schemas...)
+}
+
 // Lock implements the schema.Locker interface.
 func (d *Driver) Lock(ctx context.Context, name string, timeout time.Duration) (schema.UnlockFunc, error) {
 	conn, err := sqlx.SingleConn(ctx, d.ExecQuerier)
@@ -265,6 +289,12 @@ func (*Driver) FormatType(t schema.Type) (string, error) {
 	return FormatType(t)
 }
 
+// FormatLiteral formats the given Go value as a MySQL literal.
+// It implements the migrate.LiteralFormatter interface.
+func (*Driver) FormatLiteral(v any) (string, error) {
+	return FormatLiteral(v)
+}
+
 // ParseType returns the schema.Type value represented by the given string.
 func (*Driver) ParseType(s string) (schema.Type, error) {
 	return ParseType(s)
@@ -277,7 +307,23 @@ func (*Driver) StmtBuilder(opts migrate.PlanOptions) *sqlx.Builder {
 		QuoteClosing: '`',
 		Schema:       opts.SchemaQualifier,
 		Indent:       opts.Indent,
+		Quoting:      opts.Quoting,
+	}
+}
+
+// Backfill implements migrate.Backfiller. Values of columns whose type was changed
+// are converted implicitly by MySQL on assignment, unless a Using expression is set.
+func (d *Driver) Backfill(b *migrate.Backfill, opts ...migrate.PlanOption) (*migrate.Change, error) {
+	var o migrate.PlanOptions
+	for _, opt := range opts {
+		opt(&o)
 	}
+	return sqlx.Backfill(d.StmtBuilder(o), b), nil
+}
+
+// DependsOn implements migrate.ChangeDepender.
+func (*Driver) DependsOn(change, other schema.Change) bool {
+	return sqlx.DependsOn(change, other, nil)
 }
 
 // ScanStmts implements migrate.StmtScanner.
@@ -473,6 +519,12 @@ const (
 	EngineCSV    = "CSV"
 	EngineNDB    = "NDB" // NDBCLUSTER
 
+	// GIPKColumn is the name of the column used by generated invisible primary keys.
+	GIPKColumn = "my_row_id"
+
+	// defaultSeqCache is the default number of sequence values to cache.
+	defaultSeqCache = 1000
+
 	currentTS     = "current_timestamp"
 	defaultGen    = "default_generated"
 	autoIncrement = "auto_increment"
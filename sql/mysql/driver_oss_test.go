@@ -15,6 +15,7 @@ import (
 
 	"ariga.io/atlas/sql/internal/sqltest"
 	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/mysql/internal/mysqlversion"
 	"ariga.io/atlas/sql/schema"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -213,6 +214,17 @@ func TestDriver_Version(t *testing.T) {
 	require.Equal(t, "8.0.13", drv.(vr).Version())
 }
 
+func TestDriver_ServerInfo(t *testing.T) {
+	for v, flavor := range map[string]string{
+		"8.0.13":             DriverName,
+		"10.7.1-MariaDB":     DriverMaria,
+		"5.7.25-TiDB-v6.1.0": "tidb",
+	} {
+		c := &conn{V: mysqlversion.V(v), charset: "utf8mb4", collate: "utf8mb4_bin", timezone: "+00:00"}
+		require.Equal(t, &schema.ServerInfo{Flavor: flavor, Version: v, Charset: "utf8mb4", Collation: "utf8mb4_bin", TimeZone: "+00:00"}, c.serverInfo())
+	}
+}
+
 type mockInspector struct {
 	schema.Inspector
 	realm  *schema.Realm
@@ -239,3 +251,78 @@ func (m *mockOpener) Conn(ctx context.Context) (*sql.Conn, error) {
 	m.opened++
 	return m.DB.Conn(ctx)
 }
+
+func TestDriver_Backfill(t *testing.T) {
+	var (
+		drv   migrate.Backfiller = &Driver{}
+		users                    = schema.NewTable("users").SetSchema(schema.New("public"))
+	)
+	b := &migrate.Backfill{T: users, From: schema.NewStringColumn("name", "text"), To: schema.NewStringColumn("full_name", "text")}
+	c, err := drv.Backfill(b)
+	require.NoError(t, err)
+	require.Equal(t, "UPDATE `public`.`users` SET `full_name` = `name`", c.Cmd)
+	c, err = drv.Backfill(b, func(o *migrate.PlanOptions) {
+		o.SchemaQualifier = new(string)
+	})
+	require.NoError(t, err)
+	require.Equal(t, "UPDATE `users` SET `full_name` = `name`", c.Cmd)
+	b.Using = "TRIM(`name`)"
+	c, err = drv.Backfill(b)
+	require.NoError(t, err)
+	require.Equal(t, "UPDATE `public`.`users` SET `full_name` = TRIM(`name`)", c.Cmd)
+}
+
+func TestDriver_FilterPlan(t *testing.T) {
+	var (
+		s     = schema.New("public")
+		users = schema.NewTable("users").SetSchema(s).AddColumns(schema.NewIntColumn("id", "int"))
+		posts = schema.NewTable("posts").SetSchema(s).AddColumns(schema.NewIntColumn("id", "int"), schema.NewIntColumn("author_id", "int"))
+		tags  = schema.NewTable("tags").SetSchema(s).AddColumns(schema.NewIntColumn("id", "int"))
+	)
+	posts.AddForeignKeys(schema.NewForeignKey("author").AddColumns(posts.Columns[1]).SetRefTable(users).AddRefColumns(users.Columns[0]))
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddTable{T: tags},
+		&schema.AddTable{T: users},
+		&schema.AddTable{T: posts},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 3)
+
+	// Creating "posts" requires creating "users" first.
+	filtered, err := migrate.FilterPlan(plan, &Driver{}, migrate.TargetTable("public", "posts"))
+	require.NoError(t, err)
+	require.Len(t, filtered.Changes, 2)
+	require.Equal(t, "CREATE TABLE `public`.`users` (`id` int NOT NULL)", filtered.Changes[0].Cmd)
+	require.Equal(t, posts, filtered.Changes[1].Source.(*schema.AddTable).T)
+	require.True(t, filtered.Reversible)
+
+	filtered, err = migrate.FilterPlan(plan, &Driver{}, migrate.TargetTable("", "tags"))
+	require.NoError(t, err)
+	require.Len(t, filtered.Changes, 1)
+	require.Equal(t, "CREATE TABLE `public`.`tags` (`id` int NOT NULL)", filtered.Changes[0].Cmd)
+
+	filtered, err = migrate.FilterPlan(plan, &Driver{}, migrate.TargetTable("other", "tags"))
+	require.NoError(t, err)
+	require.Empty(t, filtered.Changes)
+}
+
+func TestDriver_FormatLiteral(t *testing.T) {
+	var drv migrate.LiteralFormatter = &Driver{}
+	for _, tt := range []struct {
+		v      any
+		expect string
+	}{
+		{v: "it's \"a\"\n\\\x00\x1a", expect: `'it\'s \"a\"\n\\\0\Z'`},
+		{v: []byte{0xde, 0xad}, expect: "X'DEAD'"},
+		{v: []byte{}, expect: "X''"},
+		{v: true, expect: "true"},
+		{v: time.Date(2024, 1, 2, 3, 4, 5, 6000, time.UTC), expect: "'2024-01-02 03:04:05.000006'"},
+		{v: map[string]string{"a": "b"}, expect: `'{\"a\":\"b\"}'`},
+	} {
+		got, err := drv.FormatLiteral(tt.v)
+		require.NoError(t, err)
+		require.Equal(t, tt.expect, got)
+	}
+	_, err := drv.FormatLiteral([]int{1})
+	require.Error(t, err)
+}
@@ -0,0 +1,53 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+//go:build !ent
+
+package mysql
+
+import (
+	"errors"
+	"reflect"
+
+	"ariga.io/atlas/sql/sqlerr"
+)
+
+// errorKind classifies the errors returned by the server based on their error number.
+// See: https://dev.mysql.com/doc/mysql-errors/8.0/en/server-error-reference.html
+func errorKind(err error) sqlerr.Kind {
+	n, ok := errorNumber(err)
+	if !ok {
+		return sqlerr.Unknown
+	}
+	switch n {
+	case 1049, 1051, 1054, 1091, 1146, 1176, 1305, 1360:
+		return sqlerr.NotExist
+	case 1178, 1235, 1845, 1846:
+		return sqlerr.Unsupported
+	case 1099, 1100, 1205, 1213, 3572:
+		return sqlerr.Locked
+	case 1044, 1045, 1142, 1143, 1227, 1370:
+		return sqlerr.PermissionDenied
+	case 1022, 1048, 1062, 1216, 1217, 1451, 1452, 1557, 1586, 3819:
+		return sqlerr.ConstraintViolation
+	default:
+		return sqlerr.Unknown
+	}
+}
+
+// errorNumber extracts the server error number from the error chain. The number is read
+// from the "Number" field of the driver error (e.g., *mysql.MySQLError), to avoid depending
+// on a specific driver implementation.
+func errorNumber(err error) (uint16, bool) {
+	for ; err != nil; err = errors.Unwrap(err) {
+		v := reflect.Indirect(reflect.ValueOf(err))
+		if v.Kind() != reflect.Struct {
+			continue
+		}
+		if f := v.FieldByName("Number"); f.IsValid() && f.CanUint() {
+			return uint16(f.Uint()), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,59 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+//go:build !ent
+
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/sqlerr"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+// mysqlError mimics the error type returned by the MySQL driver.
+type mysqlError struct {
+	Number  uint16
+	Message string
+}
+
+func (e *mysqlError) Error() string {
+	return fmt.Sprintf("Error %d: %s", e.Number, e.Message)
+}
+
+func TestDriver_ErrorKind(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("8.0.13")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	for _, tt := range []struct {
+		err  error
+		kind sqlerr.Kind
+	}{
+		{err: &mysqlError{Number: 1146, Message: "Table 'test.users' doesn't exist"}, kind: sqlerr.NotExist},
+		{err: &mysqlError{Number: 1235, Message: "This version of MySQL doesn't yet support ..."}, kind: sqlerr.Unsupported},
+		{err: &mysqlError{Number: 1205, Message: "Lock wait timeout exceeded"}, kind: sqlerr.Locked},
+		{err: &mysqlError{Number: 1142, Message: "CREATE command denied to user"}, kind: sqlerr.PermissionDenied},
+		{err: &mysqlError{Number: 1062, Message: "Duplicate entry '1' for key 'PRIMARY'"}, kind: sqlerr.ConstraintViolation},
+		{err: fmt.Errorf("driver: %w", &mysqlError{Number: 3819, Message: "Check constraint 'c' is violated"}), kind: sqlerr.ConstraintViolation},
+		{err: &mysqlError{Number: 1064, Message: "You have an error in your SQL syntax"}, kind: sqlerr.Unknown},
+	} {
+		m.ExpectExec(sqltest.Escape("INSERT INTO `users` VALUES (1)")).WillReturnError(tt.err)
+		_, err := drv.ExecContext(context.Background(), "INSERT INTO `users` VALUES (1)")
+		require.EqualError(t, err, tt.err.Error())
+		require.Equal(t, tt.kind, sqlerr.KindOf(err))
+		require.ErrorIs(t, err, tt.err)
+	}
+	m.ExpectQuery(sqltest.Escape("SELECT * FROM `users`")).WillReturnError(&mysqlError{Number: 1146})
+	_, err = drv.QueryContext(context.Background(), "SELECT * FROM `users`")
+	require.ErrorIs(t, err, sqlerr.NotExist)
+	require.NoError(t, m.ExpectationsWereMet())
+}
@@ -10,6 +10,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"math"
 	"regexp"
 	"strconv"
 	"strings"
@@ -34,7 +35,7 @@ func (i *inspect) InspectRealm(ctx context.Context, opts *schema.InspectRealmOpt
 	}
 	var (
 		mode = sqlx.ModeInspectRealm(opts)
-		r    = schema.NewRealm(schemas...).SetCharset(i.charset).SetCollation(i.collate)
+		r    = schema.NewRealm(schemas...).SetCharset(i.charset).SetCollation(i.collate).SetServerInfo(i.serverInfo())
 	)
 	if len(schemas) > 0 {
 		if mode.Is(schema.InspectTables) {
@@ -43,8 +44,14 @@ func (i *inspect) InspectRealm(ctx context.Context, opts *schema.InspectRealmOpt
 			}
 			sqlx.LinkSchemaTables(schemas)
 		}
+		if mode.Is(schema.InspectObjects) {
+			if err := i.inspectSequences(ctx, r); err != nil {
+				return nil, err
+			}
+		}
 	}
-	return schema.ExcludeRealm(r, opts.Exclude)
+	schema.SortRealm(r)
+	return schema.ExcludeRealm(r, opts.Excluded())
 }
 
 // InspectSchema returns schema descriptions of the tables in the given schema.
@@ -65,7 +72,7 @@ func (i *inspect) InspectSchema(ctx context.Context, name string, opts *schema.I
 	}
 	var (
 		mode = sqlx.ModeInspectSchema(opts)
-		r    = schema.NewRealm(schemas...).SetCharset(i.charset).SetCollation(i.collate)
+		r    = schema.NewRealm(schemas...).SetCharset(i.charset).SetCollation(i.collate).SetServerInfo(i.serverInfo())
 	)
 	if mode.Is(schema.InspectTables) {
 		if err := i.inspectTables(ctx, r, opts); err != nil {
@@ -73,7 +80,13 @@ func (i *inspect) InspectSchema(ctx context.Context, name string, opts *schema.I
 		}
 		sqlx.LinkSchemaTables(schemas)
 	}
-	return schema.ExcludeSchema(r.Schemas[0], opts.Exclude)
+	if mode.Is(schema.InspectObjects) {
+		if err := i.inspectSequences(ctx, r); err != nil {
+			return nil, err
+		}
+	}
+	schema.SortRealm(r)
+	return schema.ExcludeSchema(r.Schemas[0], opts.Excluded())
 }
 
 func (i *inspect) inspectTables(ctx context.Context, r *schema.Realm, opts *schema.InspectOptions) error {
@@ -90,12 +103,16 @@ func (i *inspect) inspectTables(ctx context.Context, r *schema.Realm, opts *sche
 		if err := i.indexes(ctx, s); err != nil {
 			return err
 		}
+		i.gipk(s)
 		if err := i.fks(ctx, s); err != nil {
 			return err
 		}
 		if err := i.checks(ctx, s); err != nil {
 			return err
 		}
+		if err := i.histograms(ctx, s); err != nil {
+			return err
+		}
 		if err := i.showCreate(ctx, s); err != nil {
 			return err
 		}
@@ -103,6 +120,82 @@ func (i *inspect) inspectTables(ctx context.Context, r *schema.Realm, opts *sche
 	return nil
 }
 
+// inspectSequences adds the sequences of the inspected schemas to their objects.
+// Sequences are supported only by MariaDB, and are skipped for other flavors.
+func (i *inspect) inspectSequences(ctx context.Context, r *schema.Realm) error {
+	if !i.SupportsSequences() || len(r.Schemas) == 0 {
+		return nil
+	}
+	args := make([]any, 0, len(r.Schemas))
+	for _, s := range r.Schemas {
+		args = append(args, s.Name)
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(sequencesQuery, nArgs(len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("mysql: querying sequences: %w", err)
+	}
+	var seqs []*Sequence
+	if err := func() error {
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				ns, name string
+				comment  sql.NullString
+			)
+			if err := rows.Scan(&ns, &name, &comment); err != nil {
+				return fmt.Errorf("mysql: scanning sequence: %w", err)
+			}
+			s, ok := r.Schema(ns)
+			if !ok {
+				return fmt.Errorf("mysql: schema %q for sequence %q was not found in inspection", ns, name)
+			}
+			seq := &Sequence{Name: name, Schema: s}
+			if sqlx.ValidString(comment) {
+				seq.Attrs = append(seq.Attrs, &schema.Comment{Text: comment.String})
+			}
+			s.Objects = append(s.Objects, seq)
+			seqs = append(seqs, seq)
+		}
+		return rows.Err()
+	}(); err != nil {
+		return err
+	}
+	// Sequence options are stored in the sequence table itself.
+	for _, seq := range seqs {
+		rows, err := i.QueryContext(ctx, fmt.Sprintf(sequenceQuery, quoteIdent(seq.Schema.Name), quoteIdent(seq.Name)))
+		if err != nil {
+			return fmt.Errorf("mysql: querying sequence %q: %w", seq.Name, err)
+		}
+		var minv, maxv int64
+		if err := sqlx.ScanOne(rows, &seq.Start, &minv, &maxv, &seq.Increment, &seq.Cache, &seq.Cycle); err != nil {
+			return fmt.Errorf("mysql: scanning sequence %q: %w", seq.Name, err)
+		}
+		// Min and max values are omitted if they are the defaults.
+		d1, d2 := seqMinMax(seq.Increment)
+		if minv != d1 {
+			seq.Min = &minv
+		}
+		if maxv != d2 {
+			seq.Max = &maxv
+		}
+	}
+	return nil
+}
+
+// seqMinMax returns the default minimum and maximum values of
+// a sequence, based on its direction (increment sign).
+func seqMinMax(inc int64) (int64, int64) {
+	if inc < 0 {
+		return math.MinInt64 + 1, -1
+	}
+	return 1, math.MaxInt64 - 1
+}
+
+// quoteIdent returns the given identifier quoted with backticks.
+func quoteIdent(s string) string {
+	return "`" + strings.ReplaceAll(s, "`", "``") + "`"
+}
+
 // schemas returns the list of the schemas in the database.
 func (i *inspect) schemas(ctx context.Context, opts *schema.InspectRealmOption) ([]*schema.Schema, error) {
 	var (
@@ -202,9 +295,13 @@ func (i *inspect) tables(ctx context.Context, realm *schema.Realm, opts *schema.
 			})
 		}
 		if sqlx.ValidString(options) {
-			t.Attrs = append(t.Attrs, &CreateOptions{
-				V: options.String,
-			})
+			attrs, rest := parseCreateOptions(options.String)
+			t.Attrs = append(t.Attrs, attrs...)
+			if rest != "" {
+				t.Attrs = append(t.Attrs, &CreateOptions{
+					V: rest,
+				})
+			}
 		}
 		if sqlx.ValidString(engine) && defaultE.Valid {
 			t.Attrs = append(t.Attrs, &Engine{
@@ -279,6 +376,9 @@ func (i *inspect) addColumn(s *schema.Schema, rows *sql.Rows) error {
 	if attr.onUpdate != "" {
 		c.Attrs = append(c.Attrs, &OnUpdate{A: attr.onUpdate})
 	}
+	if attr.invisible {
+		c.Attrs = append(c.Attrs, &Invisible{})
+	}
 	if x := expr.String; x != "" {
 		if !i.Maria() {
 			x = unescape(x)
@@ -352,6 +452,9 @@ func (i *inspect) addIndexes(s *schema.Schema, rows *sql.Rows) error {
 				if indexType == IndexTypeFullText {
 					putShow(t).addFullText(idx)
 				}
+				if keyBlockSized(t) {
+					putShow(t).addKeyBlock(idx)
+				}
 				if sqlx.ValidString(comment) {
 					idx.SetComment(comment.String)
 				}
@@ -451,6 +554,66 @@ func (i *inspect) checks(ctx context.Context, s *schema.Schema) error {
 	return rows.Err()
 }
 
+// gipk marks the tables with a generated invisible primary key. i.e., a primary
+// key that was created by the server on a table defined without one, when the
+// sql_generate_invisible_primary_key system variable is enabled.
+func (i *inspect) gipk(s *schema.Schema) {
+	if !i.SupportsGIPK() {
+		return
+	}
+	for _, t := range s.Tables {
+		if isGIPK(t) {
+			t.Attrs = append(t.Attrs, &GeneratedPK{})
+		}
+	}
+}
+
+// isGIPK reports if the primary key of the table has the form
+// of a generated invisible primary key.
+func isGIPK(t *schema.Table) bool {
+	pk := t.PrimaryKey
+	if pk == nil || len(pk.Parts) != 1 || pk.Parts[0].C == nil || pk.Parts[0].C.Name != GIPKColumn {
+		return false
+	}
+	c := pk.Parts[0].C
+	it, ok := c.Type.Type.(*schema.IntegerType)
+	return ok && it.T == TypeBigInt && it.Unsigned &&
+		sqlx.Has(c.Attrs, &Invisible{}) && sqlx.Has(c.Attrs, &AutoIncrement{})
+}
+
+// histograms queries and appends the column histograms of the given schema.
+// Histograms are created by the ANALYZE TABLE command, and are attached to
+// the tables as attributes.
+func (i *inspect) histograms(ctx context.Context, s *schema.Schema) error {
+	if !i.SupportsHistograms() {
+		return nil
+	}
+	rows, err := i.querySchema(ctx, histogramsQuery, s)
+	if err != nil {
+		return fmt.Errorf("mysql: query schema %q histograms: %w", s.Name, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			table, column string
+			buckets       sql.NullInt64
+		)
+		if err := rows.Scan(&table, &column, &buckets); err != nil {
+			return fmt.Errorf("mysql: %w", err)
+		}
+		t, ok := s.Table(table)
+		if !ok {
+			return fmt.Errorf("table %q was not found in schema", table)
+		}
+		c, ok := t.Column(column)
+		if !ok {
+			return fmt.Errorf("column %q was not found in table %q", column, table)
+		}
+		t.Attrs = append(t.Attrs, &Histogram{C: c, Buckets: int(buckets.Int64)})
+	}
+	return rows.Err()
+}
+
 // supportsCheck reports if the connected database supports
 // the CHECK clause, and return the querying for getting them.
 func (i *inspect) supportsCheck() (string, bool) {
@@ -479,6 +642,7 @@ type extraAttr struct {
 	onUpdate         string
 	generatedType    string
 	defaultGenerated bool
+	invisible        bool
 }
 
 var (
@@ -490,6 +654,12 @@ var (
 // from the INFORMATION_SCHEMA.COLUMNS table.
 func parseExtra(extra string) (*extraAttr, error) {
 	attr := &extraAttr{}
+	// The INVISIBLE keyword is appended to the other
+	// attributes of the column (e.g., auto_increment).
+	if el := strings.ToLower(extra); el == "invisible" || strings.HasSuffix(el, " invisible") {
+		attr.invisible = true
+		extra = strings.TrimSpace(extra[:len(extra)-len("invisible")])
+	}
 	switch el := strings.ToLower(extra); {
 	case el == "", el == "null":
 	case el == defaultGen:
@@ -521,6 +691,7 @@ func (i *inspect) showCreate(ctx context.Context, s *schema.Schema) error {
 			return err
 		}
 		st.setIndexParser(c)
+		st.setKeyBlockSize(c)
 		if err := st.setAutoInc(t, c); err != nil {
 			return err
 		}
@@ -604,6 +775,43 @@ func parseColumn(typ string) (parts []string, size int, unsigned bool, err error
 	return parts, size, unsigned, nil
 }
 
+// parseCreateOptions extracts the table options that are supported by Atlas from the
+// CREATE_OPTIONS column, and returns the rest of the options as they were reported.
+func parseCreateOptions(options string) ([]schema.Attr, string) {
+	var (
+		rest  []string
+		attrs []schema.Attr
+	)
+	for _, o := range strings.Fields(options) {
+		k, v, ok := strings.Cut(o, "=")
+		if !ok {
+			rest = append(rest, o)
+			continue
+		}
+		switch strings.ToUpper(k) {
+		case "ROW_FORMAT":
+			attrs = append(attrs, &RowFormat{V: strings.ToUpper(v)})
+		case "KEY_BLOCK_SIZE":
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				rest = append(rest, o)
+				continue
+			}
+			attrs = append(attrs, &KeyBlockSize{V: n})
+		case "STATS_PERSISTENT":
+			attrs = append(attrs, &StatsPersistent{V: strings.ToUpper(v)})
+		case "COMPRESSION":
+			if u, err := sqlx.Unquote(v); err == nil {
+				v = u
+			}
+			attrs = append(attrs, &Compression{V: v})
+		default:
+			rest = append(rest, o)
+		}
+	}
+	return attrs, strings.Join(rest, " ")
+}
+
 // hasNumericDefault reports if the given type has a numeric default value.
 func hasNumericDefault(t schema.Type) bool {
 	switch t.(type) {
@@ -665,7 +873,7 @@ func nArgs(n int) string { return strings.Repeat("?, ", n-1) + "?" }
 
 const (
 	// Query to list system variables.
-	variablesQuery = "SELECT @@version, @@collation_server, @@character_set_server, @@lower_case_table_names"
+	variablesQuery = "SELECT @@version, @@collation_server, @@character_set_server, @@lower_case_table_names, @@time_zone"
 
 	// Query to list database schemas.
 	schemasQuery = "SELECT `SCHEMA_NAME`, `DEFAULT_CHARACTER_SET_NAME`, `DEFAULT_COLLATION_NAME` from `INFORMATION_SCHEMA`.`SCHEMATA` WHERE `SCHEMA_NAME` NOT IN ('information_schema','innodb','mysql','performance_schema','sys') ORDER BY `SCHEMA_NAME`"
@@ -682,6 +890,12 @@ const (
 	indexesExprQuery      = "SELECT `TABLE_NAME`, `INDEX_NAME`, `COLUMN_NAME`, `NON_UNIQUE`, `SEQ_IN_INDEX`, `INDEX_TYPE`, UPPER(`COLLATION`) = 'D' AS `DESC`, `INDEX_COMMENT`, `SUB_PART`, `EXPRESSION` FROM `INFORMATION_SCHEMA`.`STATISTICS` WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` IN (%s) ORDER BY `index_name`, `seq_in_index`"
 	indexesNoCommentQuery = "SELECT `TABLE_NAME`, `INDEX_NAME`, `COLUMN_NAME`, `NON_UNIQUE`, `SEQ_IN_INDEX`, `INDEX_TYPE`, UPPER(`COLLATION`) = 'D' AS `DESC`, NULL AS `INDEX_COMMENT`, `SUB_PART`, NULL AS `EXPRESSION` FROM `INFORMATION_SCHEMA`.`STATISTICS` WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` IN (%s) ORDER BY `index_name`, `seq_in_index`"
 
+	// Query to list sequences of the given schemas.
+	sequencesQuery = "SELECT `TABLE_SCHEMA`, `TABLE_NAME`, `TABLE_COMMENT` FROM `INFORMATION_SCHEMA`.`TABLES` WHERE `TABLE_SCHEMA` IN (%s) AND `TABLE_TYPE` = 'SEQUENCE' ORDER BY `TABLE_SCHEMA`, `TABLE_NAME`"
+
+	// Query to get the options of a sequence.
+	sequenceQuery = "SELECT `start_value`, `minimum_value`, `maximum_value`, `increment`, `cache_size`, `cycle_option` FROM %s.%s"
+
 	tablesQuery = `
 SELECT
 	t1.TABLE_SCHEMA,
@@ -790,6 +1004,9 @@ ORDER BY
 	BINARY t1.TABLE_NAME,
 	BINARY t1.CONSTRAINT_NAME,
 	t1.ORDINAL_POSITION`
+
+	// Query to list the column histograms.
+	histogramsQuery = "SELECT `TABLE_NAME`, `COLUMN_NAME`, JSON_EXTRACT(`HISTOGRAM`, '$.\"number-of-buckets-specified\"') FROM `INFORMATION_SCHEMA`.`COLUMN_STATISTICS` WHERE `SCHEMA_NAME` = ? AND `TABLE_NAME` IN (%s) ORDER BY `TABLE_NAME`, `COLUMN_NAME`"
 )
 
 type (
@@ -819,6 +1036,33 @@ type (
 		Default bool   // The default engine used by the server.
 	}
 
+	// RowFormat attribute describes the physical row format of a table.
+	RowFormat struct {
+		schema.Attr
+		V string // DEFAULT, DYNAMIC, FIXED, COMPRESSED, REDUNDANT, COMPACT
+	}
+
+	// KeyBlockSize attribute describes the page size in KB of compressed InnoDB
+	// tables, or a hint of the key block size for MyISAM tables and indexes.
+	KeyBlockSize struct {
+		schema.Attr
+		V int64
+	}
+
+	// StatsPersistent attribute indicates if the persistent statistics
+	// are enabled for an InnoDB table.
+	StatsPersistent struct {
+		schema.Attr
+		V string // DEFAULT, 0 or 1
+	}
+
+	// Compression attribute describes the page-level compression
+	// algorithm used by an InnoDB table.
+	Compression struct {
+		schema.Attr
+		V string // zlib, lz4 or none.
+	}
+
 	// SystemVersioned is an attribute attached to MariaDB tables indicates they are
 	// system versioned. See: https://mariadb.com/kb/en/system-versioned-tables
 	SystemVersioned struct {
@@ -831,12 +1075,48 @@ type (
 		A string
 	}
 
+	// Invisible attribute for columns defined as INVISIBLE.
+	// Invisible columns are hidden from "SELECT *" queries.
+	Invisible struct {
+		schema.Attr
+	}
+
+	// GeneratedPK is an attribute attached to tables with a generated invisible primary
+	// key (GIPK). i.e., a primary key on an invisible "my_row_id" column that was added
+	// by the server because the table was created without one, and the system variable
+	// sql_generate_invisible_primary_key was enabled.
+	GeneratedPK struct {
+		schema.Attr
+	}
+
+	// Histogram attribute describes a column histogram that was created
+	// using the "ANALYZE TABLE ... UPDATE HISTOGRAM" command.
+	Histogram struct {
+		schema.Attr
+		C       *schema.Column
+		Buckets int // Number of buckets that were specified.
+	}
+
 	// SubPart attribute defines an option index prefix length for columns.
 	SubPart struct {
 		schema.Attr
 		Len int
 	}
 
+	// Sequence describes a MariaDB sequence object (10.3+).
+	// https://mariadb.com/kb/en/create-sequence
+	Sequence struct {
+		schema.Object
+		Name      string
+		Schema    *schema.Schema
+		Start     int64         // Defaults to min (or max for descending sequences).
+		Increment int64         // Defaults to 1.
+		Min, Max  *int64        // Nil means the defaults.
+		Cache     int64         // Defaults to 1000.
+		Cycle     bool          // Whether the sequence cycles.
+		Attrs     []schema.Attr // Additional attributes (e.g., comments).
+	}
+
 	// Enforced attribute defines the ENFORCED flag for CHECK constraint.
 	Enforced struct {
 		schema.Attr
@@ -897,6 +1177,8 @@ type (
 		auto *AutoIncrement
 		// FULLTEXT indexes that might have custom parser.
 		idxs []*schema.Index
+		// Indexes that might have a KEY_BLOCK_SIZE option.
+		blocks []*schema.Index
 	}
 )
 
@@ -906,6 +1188,23 @@ func (s *showTable) addFullText(idx *schema.Index) {
 	s.idxs = append(s.idxs, idx)
 }
 
+// addKeyBlock adds an index to the list of indexes
+// that their KEY_BLOCK_SIZE needs to be extracted.
+func (s *showTable) addKeyBlock(idx *schema.Index) {
+	s.blocks = append(s.blocks, idx)
+}
+
+// keyBlockSized reports if the indexes of the table might have a KEY_BLOCK_SIZE option,
+// which is not exposed by INFORMATION_SCHEMA. i.e., non-InnoDB tables, or tables that
+// define a KEY_BLOCK_SIZE themselves. Other tables are not loaded with 'SHOW CREATE'.
+func keyBlockSized(t *schema.Table) bool {
+	if sqlx.Has(t.Attrs, &KeyBlockSize{}) {
+		return true
+	}
+	e := &Engine{}
+	return sqlx.Has(t.Attrs, e) && !strings.EqualFold(e.V, EngineInnoDB)
+}
+
 // setAutoInc extracts the updated AUTO_INCREMENT from CREATE TABLE.
 func (s *showTable) setAutoInc(t *schema.Table, c *CreateStmt) error {
 	if s.auto == nil {
@@ -959,6 +1258,29 @@ func (s *showTable) setIndexParser(c *CreateStmt) {
 	}
 }
 
+// reKeyBlockSize matches the KEY_BLOCK_SIZE option of an index definition.
+var reKeyBlockSize = regexp.MustCompile(`\bKEY_BLOCK_SIZE=(\d+)`)
+
+// setKeyBlockSize updates the KEY_BLOCK_SIZE of indexes from CREATE TABLE statement.
+func (s *showTable) setKeyBlockSize(c *CreateStmt) {
+	for _, idx := range s.blocks {
+		k := (&sqlx.Builder{QuoteOpening: '`', QuoteClosing: '`'}).P("KEY").Ident(idx.Name).String() + " ("
+		for _, l := range strings.Split(c.S, "\n") {
+			i := strings.Index(l, k)
+			if i == -1 {
+				continue
+			}
+			// The rest of the line holds the index parts and options.
+			if matches := reKeyBlockSize.FindStringSubmatch(l[i+len(k):]); len(matches) == 2 {
+				if v, err := strconv.ParseInt(matches[1], 10, 64); err == nil && v > 0 {
+					idx.AddAttrs(&KeyBlockSize{V: v})
+				}
+			}
+			break
+		}
+	}
+}
+
 func putShow(t *schema.Table) *showTable {
 	for i := range t.Attrs {
 		if s, ok := t.Attrs[i].(*showTable); ok {
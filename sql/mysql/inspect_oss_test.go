@@ -30,6 +30,7 @@ var (
 	queryIndexesExpr      = sqltest.Escape(fmt.Sprintf(indexesExprQuery, "?"))
 	queryMyChecks         = sqltest.Escape(fmt.Sprintf(myChecksQuery, "?"))
 	queryMarChecks        = sqltest.Escape(fmt.Sprintf(marChecksQuery, "?"))
+	queryHistograms       = sqltest.Escape(fmt.Sprintf(histogramsQuery, "?"))
 )
 
 func TestDriver_InspectTable(t *testing.T) {
@@ -70,6 +71,7 @@ func TestDriver_InspectTable(t *testing.T) {
 +--------------------+--------------+-------------+------------+--------------+--------------+----------+--------------+------------+------------------+
 `))
 				m.noFKs()
+				m.noHistograms()
 				m.ExpectQuery(sqltest.Escape("SHOW CREATE TABLE `public`.`users`")).
 					WillReturnRows(sqltest.Rows(`
 +-------+---------------------------------------------------------------------------------------------------------------------------------------------+
@@ -86,7 +88,7 @@ func TestDriver_InspectTable(t *testing.T) {
 					&schema.Charset{V: "utf8mb4"},
 					&schema.Collation{V: "utf8mb4_0900_ai_ci"},
 					&schema.Comment{Text: "Comment"},
-					&CreateOptions{V: `COMPRESSION="ZLIB"`},
+					&Compression{V: "ZLIB"},
 					&Engine{V: "InnoDB", Default: true},
 					&CreateStmt{S: "CREATE TABLE users (id bigint NOT NULL AUTO_INCREMENT) ENGINE=InnoDB AUTO_INCREMENT=55834574848 DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin"},
 					&AutoIncrement{V: 55834574848},
@@ -134,6 +136,7 @@ func TestDriver_InspectTable(t *testing.T) {
 `))
 				m.noIndexes()
 				m.noFKs()
+				m.noHistograms()
 			},
 			expect: func(require *require.Assertions, t *schema.Table, err error) {
 				require.NoError(err)
@@ -229,6 +232,7 @@ func TestDriver_InspectTable(t *testing.T) {
 `))
 				m.noIndexes()
 				m.noFKs()
+				m.noHistograms()
 			},
 			expect: func(require *require.Assertions, t *schema.Table, err error) {
 				require.NoError(err)
@@ -261,6 +265,7 @@ func TestDriver_InspectTable(t *testing.T) {
 `))
 				m.noIndexes()
 				m.noFKs()
+				m.noHistograms()
 			},
 			expect: func(require *require.Assertions, t *schema.Table, err error) {
 				require.NoError(err)
@@ -295,6 +300,7 @@ func TestDriver_InspectTable(t *testing.T) {
 `))
 				m.noIndexes()
 				m.noFKs()
+				m.noHistograms()
 			},
 			expect: func(require *require.Assertions, t *schema.Table, err error) {
 				require.NoError(err)
@@ -327,6 +333,7 @@ func TestDriver_InspectTable(t *testing.T) {
 `))
 				m.noIndexes()
 				m.noFKs()
+				m.noHistograms()
 			},
 			expect: func(require *require.Assertions, t *schema.Table, err error) {
 				require.NoError(err)
@@ -359,6 +366,7 @@ func TestDriver_InspectTable(t *testing.T) {
 `))
 				m.noIndexes()
 				m.noFKs()
+				m.noHistograms()
 			},
 			expect: func(require *require.Assertions, t *schema.Table, err error) {
 				require.NoError(err)
@@ -390,6 +398,7 @@ func TestDriver_InspectTable(t *testing.T) {
 `))
 				m.noIndexes()
 				m.noFKs()
+				m.noHistograms()
 			},
 			expect: func(require *require.Assertions, t *schema.Table, err error) {
 				require.NoError(err)
@@ -421,6 +430,7 @@ func TestDriver_InspectTable(t *testing.T) {
 `))
 				m.noIndexes()
 				m.noFKs()
+				m.noHistograms()
 			},
 			expect: func(require *require.Assertions, t *schema.Table, err error) {
 				p := func(i int) *int { return &i }
@@ -452,6 +462,7 @@ func TestDriver_InspectTable(t *testing.T) {
 `))
 				m.noIndexes()
 				m.noFKs()
+				m.noHistograms()
 			},
 			expect: func(require *require.Assertions, t *schema.Table, err error) {
 				require.NoError(err)
@@ -484,6 +495,7 @@ func TestDriver_InspectTable(t *testing.T) {
 `))
 				m.noIndexes()
 				m.noFKs()
+				m.noHistograms()
 			},
 			expect: func(require *require.Assertions, t *schema.Table, err error) {
 				require.NoError(err)
@@ -519,6 +531,7 @@ func TestDriver_InspectTable(t *testing.T) {
 `))
 				m.noIndexes()
 				m.noFKs()
+				m.noHistograms()
 			},
 			expect: func(require *require.Assertions, t *schema.Table, err error) {
 				require.NoError(err)
@@ -563,6 +576,7 @@ func TestDriver_InspectTable(t *testing.T) {
 +--------------+--------------+-------------+------------+--------------+--------------+---------+--------------+------------+------------------+
 `))
 				m.noFKs()
+				m.noHistograms()
 				m.ExpectQuery(sqltest.Escape("SHOW CREATE TABLE `public`.`users`")).
 					WillReturnRows(sqltest.Rows(`
 +-------+---------------------------------------------------------------------------------------------------------------------------------------------+
@@ -667,6 +681,7 @@ func TestDriver_InspectTable(t *testing.T) {
 | self_reference   | users      | uid         | public       | users                 | id                     | public                 | NO ACTION   | CASCADE     |
 +------------------+------------+-------------+--------------+-----------------------+------------------------+------------------------+ ------------+-------------+
 `))
+				m.noHistograms()
 			},
 			expect: func(require *require.Assertions, t *schema.Table, err error) {
 				require.NoError(err)
@@ -718,6 +733,7 @@ func TestDriver_InspectTable(t *testing.T) {
 | users             | users_chk_5       | (c1 <> _latin1\'\\\\\\\\\\\'\\\'\')       |  YES       |
 +-------------------+-------------------+-------------------------------------------+------------+
 `))
+				m.noHistograms()
 				m.ExpectQuery(sqltest.Escape("SHOW CREATE TABLE `public`.`users`")).
 					WillReturnRows(sqltest.Rows(`
 +-------+------------------------+
@@ -745,6 +761,125 @@ func TestDriver_InspectTable(t *testing.T) {
 				}, t.Attrs)
 			},
 		},
+		{
+			name:    "json checks",
+			version: "8.0.31",
+			before: func(m mock) {
+				m.tableExists("public", "users", true)
+				m.ExpectQuery(queryColumns).
+					WithArgs("public", "users").
+					WillReturnRows(sqltest.Rows(`
++-------------+-------------+--------------+----------------+-------------+------------+----------------+----------------+--------------------+--------------------+---------------------------+
+| TABLE_NAME  | COLUMN_NAME | COLUMN_TYPE  | COLUMN_COMMENT | IS_NULLABLE | COLUMN_KEY | COLUMN_DEFAULT | EXTRA          | CHARACTER_SET_NAME | COLLATION_NAME     | GENERATION_EXPRESSION     |
++-------------+-------------+--------------+----------------+-------------+------------+----------------+----------------+--------------------+--------------------+---------------------------+
+| users       | doc         | json         |                | NO          |            | NULL           |                | NULL               | NULL               | NULL                      |
++-------------+-------------+--------------+----------------+-------------+------------+----------------+----------------+--------------------+--------------------+---------------------------+
+`))
+				m.noIndexes()
+				m.noFKs()
+				m.ExpectQuery(queryMyChecks).
+					WithArgs("public", "users").
+					WillReturnRows(sqltest.Rows(`
++-------------------+-------------------+--------------------------------------------------------------------------------------------------------------------------+------------+
+| TABLE_NAME        | CONSTRAINT_NAME   | CHECK_CLAUSE                                                                                                             |  ENFORCED  |
++-------------------+-------------------+--------------------------------------------------------------------------------------------------------------------------+------------+
+| users             | doc_object        | json_schema_valid(_utf8mb4\'{"type": "object"}\',` + "`doc`" + `)                                                              |  YES       |
+| users             | doc_status        | (json_unquote(json_extract(` + "`doc`" + `,_utf8mb4\'$.status\')) in (_utf8mb4\'active\',_utf8mb4\'inactive\'))                      |  YES       |
+| users             | doc_tags          | (json_length(json_extract(` + "`doc`" + `,_utf8mb4\'$.tags\')) > 0)                                                                 |  YES       |
+| users             | doc_valid         | json_valid(` + "`doc`" + `)                                                                                                        |  YES       |
++-------------------+-------------------+--------------------------------------------------------------------------------------------------------------------------+------------+
+`))
+				m.noHistograms()
+				m.ExpectQuery(sqltest.Escape("SHOW CREATE TABLE `public`.`users`")).
+					WillReturnRows(sqltest.Rows(`
++-------+------------------------+
+| Table | Create Table           |
++-------+------------------------+
+| users | CREATE TABLE users()   |
++-------+------------------------+
+`))
+			},
+			expect: func(require *require.Assertions, t *schema.Table, err error) {
+				require.NoError(err)
+				require.EqualValues([]schema.Attr{
+					&schema.Check{Name: "doc_object", Expr: "json_schema_valid(_utf8mb4'{\"type\": \"object\"}',`doc`)"},
+					&schema.Check{Name: "doc_status", Expr: "(json_unquote(json_extract(`doc`,_utf8mb4'$.status')) in (_utf8mb4'active',_utf8mb4'inactive'))"},
+					&schema.Check{Name: "doc_tags", Expr: "(json_length(json_extract(`doc`,_utf8mb4'$.tags')) > 0)"},
+					&schema.Check{Name: "doc_valid", Expr: "json_valid(`doc`)"},
+				}, t.Attrs)
+				// The checks, as written by the user, match their inspected form.
+				desired := schema.NewTable("users").AddColumns(t.Columns...).AddChecks(
+					schema.NewCheck().SetName("doc_object").SetExpr(`JSON_SCHEMA_VALID('{"type": "object"}', doc)`),
+					schema.NewCheck().SetName("doc_status").SetExpr(`doc->>'$.status' IN ('active', 'inactive')`),
+					schema.NewCheck().SetName("doc_tags").SetExpr(`JSON_LENGTH(doc->'$.tags') > 0`),
+					schema.NewCheck().SetName("doc_valid").SetExpr("JSON_VALID(`doc`)"),
+				)
+				changes, err := DefaultDiff.TableDiff(t, desired, schema.DiffNormalized())
+				require.NoError(err)
+				require.Empty(changes)
+			},
+		},
+		{
+			name:    "generated invisible primary key",
+			version: "8.0.30",
+			before: func(m mock) {
+				m.ExpectQuery(queryTable).
+					WithArgs("public").
+					WillReturnRows(sqltest.Rows(`
++--------------+--------------+--------------------+--------------------+----------------+---------------+----------------+------------------+------------------+------------------+
+| TABLE_SCHEMA | TABLE_NAME   | CHARACTER_SET_NAME | TABLE_COLLATION    | AUTO_INCREMENT | TABLE_COMMENT | CREATE_OPTIONS |      ENGINE      |  DEFAULT_ENGINE  |  TABLE_TYPE      |
++--------------+--------------+--------------------+--------------------+----------------+---------------+----------------+------------------+------------------+------------------+
+| public       | users        | utf8mb4            | utf8mb4_0900_ai_ci | 1              |               |                |       InnoDB     |       1          |                  |
++--------------+--------------+--------------------+--------------------+----------------+---------------+----------------+------------------+------------------+------------------+
+`))
+				m.ExpectQuery(queryColumns).
+					WithArgs("public", "users").
+					WillReturnRows(sqltest.Rows(`
++-------------+-------------+-----------------+----------------+-------------+------------+----------------+--------------------------+--------------------+--------------------+---------------------------+
+| TABLE_NAME  | COLUMN_NAME | COLUMN_TYPE     | COLUMN_COMMENT | IS_NULLABLE | COLUMN_KEY | COLUMN_DEFAULT | EXTRA                    | CHARACTER_SET_NAME | COLLATION_NAME     | GENERATION_EXPRESSION     |
++-------------+-------------+-----------------+----------------+-------------+------------+----------------+--------------------------+--------------------+--------------------+---------------------------+
+| users       | my_row_id   | bigint unsigned |                | NO          | PRI        | NULL           | auto_increment INVISIBLE | NULL               | NULL               | NULL                      |
+| users       | name        | varchar(255)    |                | NO          |            | NULL           |                          | NULL               | NULL               | NULL                      |
+| users       | age         | int             |                | YES         |            | NULL           | INVISIBLE                | NULL               | NULL               | NULL                      |
++-------------+-------------+-----------------+----------------+-------------+------------+----------------+--------------------------+--------------------+--------------------+---------------------------+
+`))
+				m.ExpectQuery(queryIndexesExpr).
+					WithArgs("public", "users").
+					WillReturnRows(sqltest.Rows(`
++--------------------+--------------+-------------+------------+--------------+--------------+----------+--------------+------------+------------------+
+| TABLE_NAME         | INDEX_NAME   | COLUMN_NAME | NON_UNIQUE | SEQ_IN_INDEX | INDEX_TYPE   | DESC     | COMMENT      | SUB_PART   | EXPRESSION       |
++--------------------+--------------+-------------+------------+--------------+--------------+----------+--------------+------------+------------------+
+| users              | PRIMARY      | my_row_id   |          0 |            1 | BTREE        | 0        |              |       NULL |      NULL        |
++--------------------+--------------+-------------+------------+--------------+--------------+----------+--------------+------------+------------------+
+`))
+				m.noFKs()
+				m.ExpectQuery(queryMyChecks).
+					WithArgs("public", "users").
+					WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME", "CONSTRAINT_NAME", "CHECK_CLAUSE", "ENFORCED"}))
+				m.ExpectQuery(queryHistograms).
+					WithArgs("public", "users").
+					WillReturnRows(sqltest.Rows(`
++------------+-------------+---------+
+| TABLE_NAME | COLUMN_NAME | BUCKETS |
++------------+-------------+---------+
+| users      | name        | 100     |
++------------+-------------+---------+
+`))
+			},
+			expect: func(require *require.Assertions, t *schema.Table, err error) {
+				require.NoError(err)
+				require.Equal("users", t.Name)
+				require.Len(t.Columns, 3)
+				require.EqualValues([]schema.Attr{&AutoIncrement{V: 1}, &Invisible{}}, t.Columns[0].Attrs)
+				require.Empty(t.Columns[1].Attrs)
+				require.EqualValues([]schema.Attr{&Invisible{}}, t.Columns[2].Attrs)
+				require.True(t.PrimaryKey.Parts[0].C == t.Columns[0])
+				require.True(sqlx.Has(t.Attrs, &GeneratedPK{}))
+				h := &Histogram{}
+				require.True(sqlx.Has(t.Attrs, h))
+				require.Equal(&Histogram{C: t.Columns[1], Buckets: 100}, h)
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -768,7 +903,7 @@ func TestDriver_InspectTable(t *testing.T) {
 			drv, err := Open(db)
 			require.NoError(t, err)
 			s, err := drv.InspectSchema(context.Background(), "public", &schema.InspectOptions{
-				Mode: ^schema.InspectViews,
+				Mode: ^(schema.InspectViews | schema.InspectObjects),
 			})
 			require.NoError(t, err)
 			require.NotNil(t, s)
@@ -818,6 +953,7 @@ func TestDriver_InspectSchema(t *testing.T) {
 							&schema.Collation{
 								V: "utf8_general_ci",
 							},
+							&schema.ServerInfo{Flavor: "mysql", Version: "5.7.23", Charset: "utf8", Collation: "utf8_general_ci", TimeZone: "SYSTEM"},
 						},
 					}
 					realm.Schemas[0].Realm = realm
@@ -864,12 +1000,21 @@ func TestDriver_InspectSchema(t *testing.T) {
 | owner_id         | pets       | owner_id    | public       | users                 | id                     | public                 | NO ACTION   | CASCADE     |
 +------------------+------------+-------------+--------------+-----------------------+------------------------+------------------------+-------------+-------------+
 				`))
+				m.ExpectQuery(sqltest.Escape(fmt.Sprintf(histogramsQuery, "?, ?"))).
+					WithArgs("public", "users", "pets").
+					WillReturnRows(sqltest.Rows(`
++------------+-------------+---------+
+| TABLE_NAME | COLUMN_NAME | BUCKETS |
++------------+-------------+---------+
+| pets       | owner_id    | 32      |
++------------+-------------+---------+
+				`))
 			},
 			expect: func(require *require.Assertions, s *schema.Schema, err error) {
 				require.NoError(err)
 				ts := s.Tables
 				require.Len(ts, 2)
-				users, pets := ts[0], ts[1]
+				pets, users := ts[0], ts[1]
 
 				require.Equal("users", users.Name)
 				userFKs := []*schema.ForeignKey{
@@ -895,6 +1040,7 @@ func TestDriver_InspectSchema(t *testing.T) {
 				petsFKs[0].Columns = petsColumns[1:]
 				require.EqualValues(petsColumns, pets.Columns)
 				require.EqualValues(petsFKs, pets.ForeignKeys)
+				require.Equal([]schema.Attr{&Histogram{C: pets.Columns[1], Buckets: 32}}, pets.Attrs)
 			},
 		},
 	}
@@ -913,6 +1059,62 @@ func TestDriver_InspectSchema(t *testing.T) {
 	}
 }
 
+func TestDriver_InspectSequences(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("10.6.4-MariaDB")
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= ?"))).
+		WithArgs("test").
+		WillReturnRows(sqltest.Rows(`
++-------------+----------------------------+------------------------+
+| SCHEMA_NAME | DEFAULT_CHARACTER_SET_NAME | DEFAULT_COLLATION_NAME |
++-------------+----------------------------+------------------------+
+| test        | utf8mb4                    | utf8mb4_unicode_ci     |
++-------------+----------------------------+------------------------+
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(sequencesQuery, "?"))).
+		WithArgs("test").
+		WillReturnRows(sqltest.Rows(`
++--------------+------------+---------------+
+| TABLE_SCHEMA | TABLE_NAME | TABLE_COMMENT |
++--------------+------------+---------------+
+| test         | s1         |               |
+| test         | s2         | countdown     |
++--------------+------------+---------------+
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(sequenceQuery, "`test`", "`s1`"))).
+		WillReturnRows(sqltest.Rows(`
++-------------+---------------+---------------------+-----------+------------+--------------+
+| start_value | minimum_value | maximum_value       | increment | cache_size | cycle_option |
++-------------+---------------+---------------------+-----------+------------+--------------+
+| 1           | 1             | 9223372036854775806 | 1         | 1000       | 0            |
++-------------+---------------+---------------------+-----------+------------+--------------+
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(sequenceQuery, "`test`", "`s2`"))).
+		WillReturnRows(sqltest.Rows(`
++-------------+---------------+---------------+-----------+------------+--------------+
+| start_value | minimum_value | maximum_value | increment | cache_size | cycle_option |
++-------------+---------------+---------------+-----------+------------+--------------+
+| 100         | 0             | 100           | -1        | 0          | 1            |
++-------------+---------------+---------------+-----------+------------+--------------+
+`))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	s, err := drv.InspectSchema(context.Background(), "test", &schema.InspectOptions{
+		Mode: schema.InspectObjects,
+	})
+	require.NoError(t, err)
+	require.Len(t, s.Objects, 2)
+	zero, hundred := int64(0), int64(100)
+	require.Equal(t, &Sequence{Name: "s1", Schema: s, Start: 1, Increment: 1, Cache: 1000}, s.Objects[0])
+	require.Equal(t, &Sequence{
+		Name: "s2", Schema: s, Start: 100, Increment: -1, Min: &zero, Max: &hundred, Cycle: true,
+		Attrs: []schema.Attr{&schema.Comment{Text: "countdown"}},
+	}, s.Objects[1])
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
 func TestDriver_Realm(t *testing.T) {
 	db, m, err := sqlmock.New()
 	require.NoError(t, err)
@@ -952,6 +1154,7 @@ func TestDriver_Realm(t *testing.T) {
 				&schema.Collation{
 					V: "utf8_general_ci",
 				},
+				&schema.ServerInfo{Flavor: "mysql", Version: "8.0.13", Charset: "utf8", Collation: "utf8_general_ci", TimeZone: "SYSTEM"},
 			},
 		}
 		r.Schemas[0].Realm = r
@@ -980,17 +1183,17 @@ func TestDriver_Realm(t *testing.T) {
 		r := &schema.Realm{
 			Schemas: []*schema.Schema{
 				{
-					Name: "test",
+					Name: "public",
 					Attrs: []schema.Attr{
-						&schema.Charset{V: "utf8mb4"},
-						&schema.Collation{V: "utf8mb4_unicode_ci"},
+						&schema.Charset{V: "utf8"},
+						&schema.Collation{V: "utf8_general_ci"},
 					},
 				},
 				{
-					Name: "public",
+					Name: "test",
 					Attrs: []schema.Attr{
-						&schema.Charset{V: "utf8"},
-						&schema.Collation{V: "utf8_general_ci"},
+						&schema.Charset{V: "utf8mb4"},
+						&schema.Collation{V: "utf8mb4_unicode_ci"},
 					},
 				},
 			},
@@ -1002,6 +1205,7 @@ func TestDriver_Realm(t *testing.T) {
 				&schema.Collation{
 					V: "utf8_general_ci",
 				},
+				&schema.ServerInfo{Flavor: "mysql", Version: "8.0.13", Charset: "utf8", Collation: "utf8_general_ci", TimeZone: "SYSTEM"},
 			},
 		}
 		r.Schemas[0].Realm = r
@@ -1046,6 +1250,7 @@ func TestInspectMode_InspectRealm(t *testing.T) {
 				&schema.Collation{
 					V: "utf8_general_ci",
 				},
+				&schema.ServerInfo{Flavor: "mysql", Version: "8.0.13", Charset: "utf8", Collation: "utf8_general_ci", TimeZone: "SYSTEM"},
 			},
 		}
 		r.Schemas[0].Realm = r
@@ -1060,22 +1265,22 @@ type mock struct {
 func (m mock) version(version string) {
 	m.ExpectQuery(sqltest.Escape(variablesQuery)).
 		WillReturnRows(sqltest.Rows(`
-+-----------------+--------------------+------------------------+--------------------------+ 
-| @@version       | @@collation_server | @@character_set_server | @@lower_case_table_names | 
-+-----------------+--------------------+------------------------+--------------------------+ 
-| ` + version + ` | utf8_general_ci    | utf8                   | 0                        | 
-+-----------------+--------------------+------------------------+--------------------------+ 
++-----------------+--------------------+------------------------+--------------------------+-------------+ 
+| @@version       | @@collation_server | @@character_set_server | @@lower_case_table_names | @@time_zone | 
++-----------------+--------------------+------------------------+--------------------------+-------------+ 
+| ` + version + ` | utf8_general_ci    | utf8                   | 0                        | SYSTEM      | 
++-----------------+--------------------+------------------------+--------------------------+-------------+ 
 `))
 }
 
 func (m mock) lcmode(version, mode string) {
 	m.ExpectQuery(sqltest.Escape(variablesQuery)).
 		WillReturnRows(sqltest.Rows(`
-+-----------------+--------------------+------------------------+--------------------------+ 
-| @@version       | @@collation_server | @@character_set_server | @@lower_case_table_names | 
-+-----------------+--------------------+------------------------+--------------------------+ 
-| ` + version + ` | utf8_general_ci    | utf8                   | ` + mode + `             |
-+-----------------+--------------------+------------------------+--------------------------+ 
++-----------------+--------------------+------------------------+--------------------------+-------------+ 
+| @@version       | @@collation_server | @@character_set_server | @@lower_case_table_names | @@time_zone | 
++-----------------+--------------------+------------------------+--------------------------+-------------+ 
+| ` + version + ` | utf8_general_ci    | utf8                   | ` + mode + `             | SYSTEM      |
++-----------------+--------------------+------------------------+--------------------------+-------------+ 
 `))
 }
 
@@ -1089,6 +1294,11 @@ func (m mock) noFKs() {
 		WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME", "CONSTRAINT_NAME", "TABLE_NAME", "COLUMN_NAME", "REFERENCED_TABLE_NAME", "REFERENCED_COLUMN_NAME", "REFERENCED_TABLE_SCHEMA", "UPDATE_RULE", "DELETE_RULE"}))
 }
 
+func (m mock) noHistograms() {
+	m.ExpectQuery(queryHistograms).
+		WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME", "COLUMN_NAME", "BUCKETS"}))
+}
+
 func (m mock) tableExists(schema, table string, exists bool) {
 	rows := sqlmock.NewRows([]string{"table_schema", "table_name", "table_collation", "character_set", "auto_increment", "table_comment", "create_options", "engine", "default_engine", "table_type"})
 	if exists {
@@ -1108,3 +1318,36 @@ func (m mock) tables(schema string, tables ...string) {
 		WithArgs(schema).
 		WillReturnRows(rows)
 }
+
+func TestShowTable_SetKeyBlockSize(t *testing.T) {
+	var (
+		innodb = schema.NewTable("t").AddAttrs(&Engine{V: EngineInnoDB, Default: true})
+		myisam = schema.NewTable("t").AddAttrs(&Engine{V: EngineMyISAM})
+		packed = schema.NewTable("t").AddAttrs(&Engine{V: EngineInnoDB, Default: true}, &KeyBlockSize{V: 8})
+	)
+	require.False(t, keyBlockSized(innodb))
+	require.True(t, keyBlockSized(myisam))
+	require.True(t, keyBlockSized(packed))
+
+	var (
+		s     = &showTable{}
+		name  = schema.NewIndex("name")
+		email = schema.NewUniqueIndex("email")
+		id    = schema.NewIndex("id")
+	)
+	s.addKeyBlock(name)
+	s.addKeyBlock(email)
+	s.addKeyBlock(id)
+	s.setKeyBlockSize(&CreateStmt{S: "CREATE TABLE `t` (\n" +
+		"  `id` int NOT NULL,\n" +
+		"  `name` varchar(255) NOT NULL,\n" +
+		"  `email` varchar(255) NOT NULL,\n" +
+		"  UNIQUE KEY `email` (`email`) KEY_BLOCK_SIZE=4,\n" +
+		"  KEY `name` (`name`(10)) KEY_BLOCK_SIZE=2 COMMENT 'KEY_BLOCK_SIZE=8',\n" +
+		"  KEY `id` (`id`)\n" +
+		") ENGINE=MyISAM DEFAULT CHARSET=utf8mb4 KEY_BLOCK_SIZE=8",
+	})
+	require.Equal(t, []schema.Attr{&KeyBlockSize{V: 2}}, name.Attrs)
+	require.Equal(t, []schema.Attr{&KeyBlockSize{V: 4}}, email.Attrs)
+	require.Empty(t, id.Attrs)
+}
@@ -80,6 +80,16 @@ func (v V) SupportsRenameColumn() bool {
 	return v.GTE(u)
 }
 
+// SupportsInstantAlgorithm reports if the version supports
+// the "ALGORITHM=INSTANT" clause in ALTER TABLE statements.
+func (v V) SupportsInstantAlgorithm() bool {
+	u := "8.0.12"
+	if v.Maria() {
+		u = "10.3.2"
+	}
+	return v.GTE(u)
+}
+
 // SupportsIndexComment reports if the version
 // supports comments on indexes.
 func (v V) SupportsIndexComment() bool {
@@ -94,6 +104,33 @@ func (v V) SupportsViewUsage() bool {
 	return !v.Maria() && v.GTE("8.0.13")
 }
 
+// SupportsInvisibleColumns reports if the version supports
+// the INVISIBLE column attribute.
+func (v V) SupportsInvisibleColumns() bool {
+	u := "8.0.23"
+	if v.Maria() {
+		u = "10.3.3"
+	}
+	return v.GTE(u)
+}
+
+// SupportsGIPK reports if the version supports generated invisible
+// primary keys (sql_generate_invisible_primary_key).
+func (v V) SupportsGIPK() bool {
+	return !v.Maria() && v.GTE("8.0.30")
+}
+
+// SupportsHistograms reports if the version supports column histograms
+// and querying them from the COLUMN_STATISTICS table.
+func (v V) SupportsHistograms() bool {
+	return !v.Maria() && !v.TiDB() && v.GTE("8.0.3")
+}
+
+// SupportsSequences reports if the version supports sequence objects.
+func (v V) SupportsSequences() bool {
+	return v.Maria() && v.GTE("10.3.0")
+}
+
 // CharsetToCollate returns the mapping from charset to its default collation.
 func (v V) CharsetToCollate(conn schema.ExecQuerier) (map[string]string, error) {
 	name := "is/charset2collate"
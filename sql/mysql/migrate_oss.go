@@ -10,12 +10,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 
 	"ariga.io/atlas/sql/internal/sqlx"
 	"ariga.io/atlas/sql/migrate"
 	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlerr"
 )
 
 var (
@@ -43,9 +45,13 @@ func (p *planApply) PlanChanges(ctx context.Context, name string, changes []sche
 	for _, o := range opts {
 		o(&s.PlanOptions)
 	}
+	changes = sqlx.SkipVirtualFKs(changes)
 	if err := verifyChanges(ctx, changes); err != nil {
 		return nil, err
 	}
+	if err := verifyEngines(changes); err != nil {
+		return nil, err
+	}
 	if err := s.plan(changes); err != nil {
 		return nil, err
 	}
@@ -71,6 +77,90 @@ type state struct {
 	migrate.PlanOptions
 }
 
+// verifyEngines ensures the given changes are supported by the storage engines of the
+// tables they affect, to fail on planning with an actionable error instead of failing
+// on execution, or being silently ignored by the database. For example, foreign keys
+// are parsed but ignored by MyISAM tables, and are rejected for partitioned tables.
+func verifyEngines(changes []schema.Change) error {
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddTable:
+			for _, fk := range c.T.ForeignKeys {
+				if err := verifyForeignKey(c.T, fk); err != nil {
+					return fmt.Errorf("create table %q: %w", c.T.Name, err)
+				}
+			}
+		case *schema.ModifyTable:
+			var fks []*schema.ForeignKey
+			for _, mc := range c.Changes {
+				switch mc := mc.(type) {
+				case *schema.AddForeignKey:
+					fks = append(fks, mc.F)
+				case *schema.ModifyForeignKey:
+					fks = append(fks, mc.To)
+				case *schema.ModifyAttr:
+					// Existing foreign keys must be supported by the new engine.
+					if _, ok := mc.To.(*Engine); ok {
+						fks = append(fks, c.T.ForeignKeys...)
+					}
+				}
+			}
+			for _, fk := range fks {
+				if err := verifyForeignKey(c.T, fk); err != nil {
+					return fmt.Errorf("alter table %q: %w", c.T.Name, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// verifyForeignKey reports an error if the given foreign key of
+// table t is not supported by the engines of the linked tables.
+func verifyForeignKey(t *schema.Table, fk *schema.ForeignKey) error {
+	e := tableEngine(t)
+	switch {
+	case !supportsFKs(e):
+		return fmt.Errorf("foreign key %q is not supported by the %s engine: change the table engine to %s or remove the constraint", fk.Symbol, e, EngineInnoDB)
+	case partitioned(t):
+		return fmt.Errorf("foreign key %q is not supported on partitioned tables: remove the partitioning or the constraint", fk.Symbol)
+	case fk.RefTable == nil || fk.RefTable == t:
+		return nil
+	case partitioned(fk.RefTable):
+		return fmt.Errorf("foreign key %q references partitioned table %q: remove the partitioning or the constraint", fk.Symbol, fk.RefTable.Name)
+	case !strings.EqualFold(e, tableEngine(fk.RefTable)):
+		return fmt.Errorf("foreign key %q references table %q that uses a different engine (%s != %s)", fk.Symbol, fk.RefTable.Name, e, tableEngine(fk.RefTable))
+	}
+	return nil
+}
+
+// tableEngine returns the storage engine of the table. Tables without
+// an explicit engine are assumed to use the server default, InnoDB.
+func tableEngine(t *schema.Table) string {
+	var e Engine
+	if !sqlx.Has(t.Attrs, &e) || e.V == "" {
+		return EngineInnoDB
+	}
+	// NDB is reported as NDBCLUSTER by the database.
+	if strings.EqualFold(e.V, "NDBCLUSTER") {
+		return EngineNDB
+	}
+	return e.V
+}
+
+// supportsFKs reports if the given storage engine supports foreign keys.
+func supportsFKs(e string) bool {
+	return strings.EqualFold(e, EngineInnoDB) || strings.EqualFold(e, EngineNDB)
+}
+
+// partitioned reports if the table was inspected as a partitioned table.
+func partitioned(t *schema.Table) bool {
+	var o CreateOptions
+	return sqlx.Has(t.Attrs, &o) && slices.ContainsFunc(strings.Fields(o.V), func(f string) bool {
+		return strings.EqualFold(f, "partitioned")
+	})
+}
+
 // plan builds the migration plan for applying the
 // given changes on the attached connection.
 func (s *state) plan(changes []schema.Change) error {
@@ -88,20 +178,34 @@ func (s *state) plan(changes []schema.Change) error {
 		if err != nil {
 			return err
 		}
-		planned = sqlx.SortChanges(planned, nil)
+		if s.Order.Is(migrate.PlanOrderByKind) {
+			planned = sqlx.GroupByKind(planned)
+		}
+		if planned = sqlx.SortChanges(planned, nil); !s.NoMergeAlters {
+			planned = sqlx.MergeAlters(planned)
+		}
+		planned = sqlx.PostponeChanges(planned, s.Order)
 	}
 	for _, c := range planned {
 		switch c := c.(type) {
 		case *schema.AddTable:
 			err = s.addTable(c)
+		case *schema.CloneTable:
+			s.cloneTable(c)
 		case *schema.DropTable:
 			err = s.dropTable(c)
 		case *schema.ModifyTable:
 			err = s.modifyTable(c)
 		case *schema.RenameTable:
 			s.renameTable(c)
+		case *schema.AddObject:
+			err = s.addObject(c)
+		case *schema.DropObject:
+			err = s.dropObject(c)
+		case *schema.ModifyObject:
+			err = s.modifyObject(c)
 		default:
-			err = fmt.Errorf("unsupported change %T", c)
+			err = sqlerr.Errorf(sqlerr.Unsupported, "unsupported change %T", c)
 		}
 		if err != nil {
 			return err
@@ -272,9 +376,43 @@ func (s *state) addTable(add *schema.AddTable) error {
 		Reverse: s.Build("DROP TABLE").Table(add.T).String(),
 		Comment: fmt.Sprintf("create %q table", add.T.Name),
 	})
+	for _, a := range add.T.Attrs {
+		if h, ok := a.(*Histogram); ok {
+			s.histogram(add.T, &schema.AddAttr{A: h})
+		}
+	}
 	return nil
 }
 
+// cloneTable builds and appends the migration changes for creating a
+// table with the structure of its source. Since 'CREATE TABLE ... LIKE'
+// copies all indexes of the source, its secondary indexes are dropped.
+func (s *state) cloneTable(c *schema.CloneTable) {
+	b := s.Build("CREATE TABLE")
+	if sqlx.Has(c.Extra, &schema.IfNotExists{}) {
+		b.P("IF NOT EXISTS")
+	}
+	s.append(&migrate.Change{
+		Cmd:     b.Table(c.T).P("LIKE").Table(c.Source).String(),
+		Source:  c,
+		Reverse: s.Build("DROP TABLE").Table(c.T).String(),
+		Comment: fmt.Sprintf("create %q table as a clone of %q", c.T.Name, c.Source.Name),
+	})
+	if len(c.Source.Indexes) > 0 {
+		s.append(&migrate.Change{
+			Cmd: s.Build("ALTER TABLE").Table(c.T).MapComma(c.Source.Indexes, func(i int, b *sqlx.Builder) {
+				b.P("DROP INDEX").Ident(c.Source.Indexes[i].Name)
+			}).String(),
+			Reverse: s.Build("ALTER TABLE").Table(c.T).MapComma(c.Source.Indexes, func(i int, b *sqlx.Builder) {
+				b.P("ADD")
+				index(b, c.Source.Indexes[i])
+			}).String(),
+			Source:  c,
+			Comment: fmt.Sprintf("drop indexes copied from %q to %q table", c.Source.Name, c.T.Name),
+		})
+	}
+}
+
 // dropTable builds and appends the migrate.Change
 // for dropping a table from a schema.
 func (s *state) dropTable(drop *schema.DropTable) error {
@@ -299,11 +437,20 @@ func (s *state) dropTable(drop *schema.DropTable) error {
 // modifyTable builds and appends the migration changes for
 // bringing the table into its modified state.
 func (s *state) modifyTable(modify *schema.ModifyTable) error {
-	var changes [2][]schema.Change
+	var (
+		changes    [2][]schema.Change
+		histograms []schema.Change
+	)
 	if len(modify.T.Columns) == 0 {
 		return fmt.Errorf("table %q has no columns; drop the table instead", modify.T.Name)
 	}
 	for _, change := range skipAutoChanges(modify.Changes) {
+		// Histograms are not part of the table definition,
+		// and are maintained using the ANALYZE TABLE command.
+		if histogramOf(change) != nil {
+			histograms = append(histograms, change)
+			continue
+		}
 		switch change := change.(type) {
 		// Foreign-key modification is translated into 2 steps.
 		// Dropping the current foreign key and creating a new one.
@@ -344,9 +491,51 @@ func (s *state) modifyTable(modify *schema.ModifyTable) error {
 			}
 		}
 	}
+	for _, c := range histograms {
+		s.histogram(modify.T, c)
+	}
 	return nil
 }
 
+// histogram builds and appends the migration change for creating, updating
+// or dropping a column histogram using the ANALYZE TABLE command.
+func (s *state) histogram(t *schema.Table, c schema.Change) {
+	var (
+		h      = histogramOf(c)
+		update = func(h *Histogram) string {
+			b := s.Build("ANALYZE TABLE").Table(t).P("UPDATE HISTOGRAM ON").Ident(h.C.Name)
+			if h.Buckets > 0 {
+				b.P("WITH", strconv.Itoa(h.Buckets), "BUCKETS")
+			}
+			return b.String()
+		}
+		drop = s.Build("ANALYZE TABLE").Table(t).P("DROP HISTOGRAM ON").Ident(h.C.Name).String()
+	)
+	switch c := c.(type) {
+	case *schema.AddAttr:
+		s.append(&migrate.Change{
+			Cmd:     update(h),
+			Source:  c,
+			Reverse: drop,
+			Comment: fmt.Sprintf("create histogram on %q.%q", t.Name, h.C.Name),
+		})
+	case *schema.ModifyAttr:
+		s.append(&migrate.Change{
+			Cmd:     update(h),
+			Source:  c,
+			Reverse: update(c.From.(*Histogram)),
+			Comment: fmt.Sprintf("update histogram on %q.%q", t.Name, h.C.Name),
+		})
+	case *schema.DropAttr:
+		s.append(&migrate.Change{
+			Cmd:     drop,
+			Source:  c,
+			Reverse: update(h),
+			Comment: fmt.Sprintf("drop histogram on %q.%q", t.Name, h.C.Name),
+		})
+	}
+}
+
 // alterTable modifies the given table by executing on it a list of
 // changes in one SQL statement.
 func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
@@ -370,6 +559,7 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 				if err := s.column(b, t, change.C); err != nil {
 					return err
 				}
+				position(b, change.Extra)
 				reverse = append(reverse, &schema.DropColumn{C: change.C})
 			case *schema.ModifyColumn:
 				if err := checkChangeGenerated(change.From, change.To); err != nil {
@@ -384,6 +574,11 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 				if err := s.column(b, t, change.To); err != nil {
 					return err
 				}
+				// The previous position of the column is unknown
+				// at this stage, and it cannot be restored.
+				if position(b, change.Extra) {
+					reversible = false
+				}
 				reverse = append(reverse, &schema.ModifyColumn{
 					From:   change.To,
 					To:     change.From,
@@ -507,6 +702,22 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 	return nil
 }
 
+// position writes the column position clause (if exists) to
+// the builder, and reports if such clause was written.
+func position(b *sqlx.Builder, extra []schema.Clause) bool {
+	for _, c := range extra {
+		if p, ok := c.(*schema.ColumnPosition); ok {
+			if p.After == nil {
+				b.P("FIRST")
+			} else {
+				b.P("AFTER").Ident(p.After.Name)
+			}
+			return true
+		}
+	}
+	return false
+}
+
 func (s *state) renameTable(c *schema.RenameTable) {
 	s.append(&migrate.Change{
 		Source:  c,
@@ -569,7 +780,20 @@ func (s *state) column(b *sqlx.Builder, t *schema.Table, c *schema.Column) error
 				b.P("COLLATE", a.V)
 			}
 		case *OnUpdate:
+			// MySQL requires the precision of the ON UPDATE
+			// clause to match the precision of the column.
+			if tt, ok := c.Type.Type.(*schema.TimeType); ok {
+				var tp int
+				if tt.Precision != nil {
+					tp = *tt.Precision
+				}
+				if p, ok := onUpdatePrecision(a.A); ok && p != tp {
+					return fmt.Errorf("column %q: ON UPDATE precision (%d) does not match the column precision (%d)", c.Name, p, tp)
+				}
+			}
 			b.P("ON UPDATE", a.A)
+		case *Invisible:
+			b.P("INVISIBLE")
 		case *AutoIncrement:
 			b.P("AUTO_INCREMENT")
 			// Auto increment with value should be configured on table options.
@@ -597,6 +821,9 @@ func index(b *sqlx.Builder, idx *schema.Index) {
 	}
 	b.P("INDEX").Ident(idx.Name)
 	indexTypeParts(b, idx)
+	if k := (KeyBlockSize{}); sqlx.Has(idx.Attrs, &k) && k.V > 0 {
+		b.P("KEY_BLOCK_SIZE", strconv.FormatInt(k.V, 10))
+	}
 	if c := (schema.Comment{}); sqlx.Has(idx.Attrs, &c) {
 		b.P("COMMENT", quote(c.Text))
 	}
@@ -678,6 +905,14 @@ func (s *state) tableAttrs(b *sqlx.Builder, c schema.Change, attrs ...schema.Att
 			if _, ok := c.(*schema.ModifyAttr); ok || !a.Default {
 				b.P("ENGINE", a.V)
 			}
+		case *RowFormat:
+			b.P("ROW_FORMAT", a.V)
+		case *KeyBlockSize:
+			b.P("KEY_BLOCK_SIZE", strconv.FormatInt(a.V, 10))
+		case *StatsPersistent:
+			b.P("STATS_PERSISTENT", a.V)
+		case *Compression:
+			b.P("COMPRESSION", quote(a.V))
 		case *schema.Check:
 			// Ignore CHECK constraints as they are not real attributes,
 			// and handled on CREATE or ALTER.
@@ -711,6 +946,142 @@ func (s *state) collation(t *schema.Table) string {
 	return s.collate
 }
 
+func (s *state) addObject(add *schema.AddObject) error {
+	seq, ok := add.O.(*Sequence)
+	if !ok {
+		return sqlerr.Errorf(sqlerr.Unsupported, "unsupported object %T", add.O)
+	}
+	if !s.SupportsSequences() {
+		return fmt.Errorf("mysql: sequences are supported only by MariaDB 10.3 and above")
+	}
+	s.append(&migrate.Change{
+		Source:  add,
+		Cmd:     s.createSequence(seq, sqlx.Has(add.Extra, &schema.IfNotExists{})),
+		Reverse: s.Build("DROP SEQUENCE").SchemaResource(seq.Schema, seq.Name).String(),
+		Comment: fmt.Sprintf("create sequence %q", seq.Name),
+	})
+	return nil
+}
+
+func (s *state) dropObject(drop *schema.DropObject) error {
+	seq, ok := drop.O.(*Sequence)
+	if !ok {
+		return sqlerr.Errorf(sqlerr.Unsupported, "unsupported object %T", drop.O)
+	}
+	b := s.Build("DROP SEQUENCE")
+	if sqlx.Has(drop.Extra, &schema.IfExists{}) {
+		b.P("IF EXISTS")
+	}
+	s.append(&migrate.Change{
+		Source:  drop,
+		Cmd:     b.SchemaResource(seq.Schema, seq.Name).String(),
+		Reverse: s.createSequence(seq, false),
+		Comment: fmt.Sprintf("drop sequence %q", seq.Name),
+	})
+	return nil
+}
+
+func (s *state) modifyObject(modify *schema.ModifyObject) error {
+	from, ok1 := modify.From.(*Sequence)
+	to, ok2 := modify.To.(*Sequence)
+	if !ok1 || !ok2 {
+		return sqlerr.Errorf(sqlerr.Unsupported, "unsupported object modification %T -> %T", modify.From, modify.To)
+	}
+	if cmd := s.alterSequence(from, to); cmd != "" {
+		s.append(&migrate.Change{
+			Source:  modify,
+			Cmd:     cmd,
+			Reverse: s.alterSequence(to, from),
+			Comment: fmt.Sprintf("modify sequence %q", from.Name),
+		})
+	}
+	// Table options, such as comments, are altered with the ALTER TABLE command.
+	if c1, c2 := seqComment(from), seqComment(to); c1 != c2 {
+		b := s.Build("ALTER TABLE").SchemaResource(to.Schema, to.Name).P("COMMENT")
+		s.append(&migrate.Change{
+			Source:  modify,
+			Cmd:     b.Clone().P(quote(c2)).String(),
+			Reverse: b.Clone().P(quote(c1)).String(),
+			Comment: fmt.Sprintf("set comment to sequence %q", from.Name),
+		})
+	}
+	return nil
+}
+
+// createSequence returns the CREATE SEQUENCE statement of the given sequence.
+func (s *state) createSequence(seq *Sequence, ifNotExists bool) string {
+	b := s.Build("CREATE SEQUENCE")
+	if ifNotExists {
+		b.P("IF NOT EXISTS")
+	}
+	b.SchemaResource(seq.Schema, seq.Name)
+	if i := seqIncrement(seq); i != 1 {
+		b.P("INCREMENT BY", strconv.FormatInt(i, 10))
+	}
+	if seq.Min != nil {
+		b.P("MINVALUE", strconv.FormatInt(*seq.Min, 10))
+	}
+	if seq.Max != nil {
+		b.P("MAXVALUE", strconv.FormatInt(*seq.Max, 10))
+	}
+	if seq.Start != 0 && seq.Start != seqStart(&Sequence{Increment: seq.Increment, Min: seq.Min, Max: seq.Max}) {
+		b.P("START WITH", strconv.FormatInt(seq.Start, 10))
+	}
+	if c := seqCache(seq); c != defaultSeqCache {
+		b.P("CACHE", strconv.FormatInt(c, 10))
+	}
+	if seq.Cycle {
+		b.P("CYCLE")
+	}
+	if c := seqComment(seq); c != "" {
+		b.P("COMMENT", quote(c))
+	}
+	return b.String()
+}
+
+// alterSequence returns the ALTER SEQUENCE statement for moving the sequence
+// options from one state to the other, or an empty string if they are equal.
+func (s *state) alterSequence(from, to *Sequence) string {
+	var (
+		n          int
+		b          = s.Build("ALTER SEQUENCE").SchemaResource(to.Schema, to.Name)
+		min1, max1 = seqBounds(from)
+		min2, max2 = seqBounds(to)
+	)
+	if i := seqIncrement(to); seqIncrement(from) != i {
+		b.P("INCREMENT BY", strconv.FormatInt(i, 10))
+		n++
+	}
+	if min1 != min2 {
+		b.P("MINVALUE", strconv.FormatInt(min2, 10))
+		n++
+	}
+	if max1 != max2 {
+		b.P("MAXVALUE", strconv.FormatInt(max2, 10))
+		n++
+	}
+	if v := seqStart(to); seqStart(from) != v {
+		b.P("START WITH", strconv.FormatInt(v, 10))
+		n++
+	}
+	if c := seqCache(to); seqCache(from) != c {
+		b.P("CACHE", strconv.FormatInt(c, 10))
+		n++
+	}
+	if from.Cycle != to.Cycle {
+		if to.Cycle {
+			b.P("CYCLE")
+		} else {
+			b.P("NOCYCLE")
+		}
+		n++
+	}
+	if n == 0 {
+		return ""
+	}
+	return b.String()
+}
+
 func (s *state) append(c *migrate.Change) {
 	s.Changes = append(s.Changes, c)
 }
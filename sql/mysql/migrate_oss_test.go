@@ -203,6 +203,11 @@ func TestMigrate_DetachCycles(t *testing.T) {
 }
 
 func TestPlanChanges(t *testing.T) {
+	histT := schema.NewTable("users").AddColumns(
+		schema.NewIntColumn("id", "int"),
+		schema.NewIntColumn("age", "int").AddAttrs(&Invisible{}),
+	)
+	histT.AddAttrs(&Histogram{C: histT.Columns[1], Buckets: 32})
 	tests := []struct {
 		version  string
 		changes  []schema.Change
@@ -242,6 +247,22 @@ func TestPlanChanges(t *testing.T) {
 				},
 			},
 		},
+		{
+			changes: []schema.Change{&schema.AddTable{T: histT}},
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     "CREATE TABLE `users` (`id` int NOT NULL, `age` int NOT NULL INVISIBLE)",
+						Reverse: "DROP TABLE `users`",
+					},
+					{
+						Cmd:     "ANALYZE TABLE `users` UPDATE HISTOGRAM ON `age` WITH 32 BUCKETS",
+						Reverse: "ANALYZE TABLE `users` DROP HISTOGRAM ON `age`",
+					},
+				},
+			},
+		},
 		{
 			changes: []schema.Change{
 				&schema.AddTable{
@@ -604,6 +625,9 @@ func TestPlanChanges(t *testing.T) {
 							&schema.Comment{Text: "posts comment"},
 							&schema.Check{Name: "id_nonzero", Expr: "(`id` > 0)"},
 							&CreateOptions{V: `COMPRESSION="ZLIB"`},
+							&RowFormat{V: "COMPRESSED"},
+							&KeyBlockSize{V: 8},
+							&StatsPersistent{V: "1"},
 						},
 						Indexes: []*schema.Index{
 							{
@@ -611,6 +635,7 @@ func TestPlanChanges(t *testing.T) {
 								Parts: []*schema.IndexPart{
 									{Desc: true, Attrs: []schema.Attr{&SubPart{Len: 100}}},
 								},
+								Attrs: []schema.Attr{&KeyBlockSize{V: 4}},
 							},
 						},
 					}
@@ -621,7 +646,7 @@ func TestPlanChanges(t *testing.T) {
 			},
 			wantPlan: &migrate.Plan{
 				Reversible: true,
-				Changes:    []*migrate.Change{{Cmd: "CREATE TABLE `posts` (`id` bigint NOT NULL AUTO_INCREMENT, `text` text NULL, `ch` char NOT NULL, PRIMARY KEY (`id`), INDEX `text_prefix` (`text` (100) DESC), CONSTRAINT `id_nonzero` CHECK (`id` > 0)) CHARSET utf8mb4 COLLATE utf8mb4_bin COMMENT \"posts comment\" COMPRESSION=\"ZLIB\" AUTO_INCREMENT 100", Reverse: "DROP TABLE `posts`"}},
+				Changes:    []*migrate.Change{{Cmd: "CREATE TABLE `posts` (`id` bigint NOT NULL AUTO_INCREMENT, `text` text NULL, `ch` char NOT NULL, PRIMARY KEY (`id`), INDEX `text_prefix` (`text` (100) DESC) KEY_BLOCK_SIZE 4, CONSTRAINT `id_nonzero` CHECK (`id` > 0)) CHARSET utf8mb4 COLLATE utf8mb4_bin COMMENT \"posts comment\" COMPRESSION=\"ZLIB\" ROW_FORMAT COMPRESSED KEY_BLOCK_SIZE 8 STATS_PERSISTENT 1 AUTO_INCREMENT 100", Reverse: "DROP TABLE `posts`"}},
 			},
 		},
 		{
@@ -931,6 +956,41 @@ func TestPlanChanges(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		// Adding an ON UPDATE clause to a column.
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: schema.NewTable("users").
+						AddColumns(schema.NewTimeColumn("updated_at", TypeTimestamp, schema.TimePrecision(3))),
+					Changes: []schema.Change{
+						&schema.ModifyColumn{
+							Change: schema.ChangeAttr,
+							From:   schema.NewTimeColumn("updated_at", TypeTimestamp, schema.TimePrecision(3)),
+							To:     schema.NewTimeColumn("updated_at", TypeTimestamp, schema.TimePrecision(3)).AddAttrs(&OnUpdate{A: "CURRENT_TIMESTAMP(3)"}),
+						},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     "ALTER TABLE `users` MODIFY COLUMN `updated_at` timestamp(3) NOT NULL ON UPDATE CURRENT_TIMESTAMP(3)",
+						Reverse: "ALTER TABLE `users` MODIFY COLUMN `updated_at` timestamp(3) NOT NULL",
+					},
+				},
+			},
+		},
+		// The ON UPDATE precision must match the column precision.
+		{
+			changes: []schema.Change{
+				&schema.AddTable{
+					T: schema.NewTable("users").
+						AddColumns(schema.NewTimeColumn("updated_at", TypeTimestamp, schema.TimePrecision(6)).AddAttrs(&OnUpdate{A: "CURRENT_TIMESTAMP"})),
+				},
+			},
+			wantErr: true,
+		},
 		// Changing a STORED generated column to a regular column.
 		{
 			changes: []schema.Change{
@@ -1172,6 +1232,117 @@ func TestPlanChanges(t *testing.T) {
 	}
 }
 
+func TestPlanChanges_Engines(t *testing.T) {
+	var (
+		users = schema.NewTable("users").
+			SetSchema(schema.New("test")).
+			AddColumns(schema.NewIntColumn("id", "int"))
+		posts = schema.NewTable("posts").
+			SetSchema(users.Schema).
+			AddColumns(schema.NewIntColumn("author_id", "int"))
+		fk = schema.NewForeignKey("author").SetTable(posts).AddColumns(posts.Columns[0]).SetRefTable(users).AddRefColumns(users.Columns[0])
+	)
+	posts.AddForeignKeys(fk)
+	_, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddTable{T: posts}})
+	require.NoError(t, err, "InnoDB is the default engine")
+
+	posts.Attrs = []schema.Attr{&Engine{V: EngineMyISAM}}
+	_, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddTable{T: posts}})
+	require.EqualError(t, err, `create table "posts": foreign key "author" is not supported by the MyISAM engine: change the table engine to InnoDB or remove the constraint`)
+	_, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{T: posts, Changes: []schema.Change{&schema.ModifyAttr{From: &Engine{V: EngineInnoDB}, To: &Engine{V: EngineMyISAM}}}},
+	})
+	require.EqualError(t, err, `alter table "posts": foreign key "author" is not supported by the MyISAM engine: change the table engine to InnoDB or remove the constraint`)
+
+	posts.Attrs = []schema.Attr{&Engine{V: "ndbcluster"}}
+	_, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{T: posts, Changes: []schema.Change{&schema.AddForeignKey{F: fk}}},
+	})
+	require.EqualError(t, err, `alter table "posts": foreign key "author" references table "users" that uses a different engine (NDB != InnoDB)`)
+
+	posts.Attrs = nil
+	users.Attrs = []schema.Attr{&CreateOptions{V: "partitioned"}}
+	_, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{T: posts, Changes: []schema.Change{&schema.AddForeignKey{F: fk}}},
+	})
+	require.EqualError(t, err, `alter table "posts": foreign key "author" references partitioned table "users": remove the partitioning or the constraint`)
+}
+
+func TestPlanChanges_MergeAlters(t *testing.T) {
+	var (
+		users = schema.NewTable("users").
+			SetSchema(schema.New("test")).
+			AddColumns(schema.NewIntColumn("id", "int"), schema.NewIntColumn("age", "int"))
+		changes = []schema.Change{
+			&schema.ModifyTable{T: users, Changes: []schema.Change{&schema.AddColumn{C: schema.NewStringColumn("name", "varchar(255)")}}},
+			&schema.ModifyTable{T: users, Changes: []schema.Change{&schema.DropColumn{C: users.Columns[1]}}},
+		}
+	)
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, "ALTER TABLE `test`.`users` ADD COLUMN `name` varchar(255) NOT NULL, DROP COLUMN `age`", plan.Changes[0].Cmd)
+
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", changes, func(o *migrate.PlanOptions) {
+		o.NoMergeAlters = true
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, "ALTER TABLE `test`.`users` ADD COLUMN `name` varchar(255) NOT NULL", plan.Changes[0].Cmd)
+	require.Equal(t, "ALTER TABLE `test`.`users` DROP COLUMN `age`", plan.Changes[1].Cmd)
+}
+
+func TestPlanChanges_Sequences(t *testing.T) {
+	var (
+		s    = schema.New("test")
+		max  = int64(100)
+		seq1 = &Sequence{Name: "s1", Schema: s}
+		seq2 = &Sequence{Name: "s2", Schema: s, Start: 10, Increment: 2, Max: &max, Cache: 10, Cycle: true, Attrs: []schema.Attr{&schema.Comment{Text: "c"}}}
+	)
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("10.6.0-MariaDB")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	plan, err := drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddObject{O: seq1},
+		&schema.AddObject{O: seq2},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, "CREATE SEQUENCE `test`.`s1`", plan.Changes[0].Cmd)
+	require.Equal(t, "DROP SEQUENCE `test`.`s1`", plan.Changes[0].Reverse)
+	require.Equal(t, "CREATE SEQUENCE `test`.`s2` INCREMENT BY 2 MAXVALUE 100 START WITH 10 CACHE 10 CYCLE COMMENT \"c\"", plan.Changes[1].Cmd)
+
+	changes, err := drv.SchemaDiff(
+		schema.New("test").AddObjects(&Sequence{Name: "s1"}, &Sequence{Name: "s2"}),
+		schema.New("test").AddObjects(&Sequence{Name: "s1", Increment: 1}, &Sequence{Name: "s3"}),
+	)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	require.IsType(t, &schema.DropObject{}, changes[0])
+	require.IsType(t, &schema.AddObject{}, changes[1])
+	changes, err = drv.SchemaDiff(
+		schema.New("test").AddObjects(&Sequence{Name: "s1"}),
+		schema.New("test").AddObjects(&Sequence{Name: "s1", Cache: 5}),
+	)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.IsType(t, &schema.ModifyObject{}, changes[0])
+	plan, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyObject{From: seq1, To: &Sequence{Name: "s1", Schema: s, Increment: 5}},
+		&schema.DropObject{O: seq2},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, "ALTER SEQUENCE `test`.`s1` INCREMENT BY 5", plan.Changes[0].Cmd)
+	require.Equal(t, "ALTER SEQUENCE `test`.`s1` INCREMENT BY 1", plan.Changes[0].Reverse)
+	require.Equal(t, "DROP SEQUENCE `test`.`s2`", plan.Changes[1].Cmd)
+
+	_, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddObject{O: seq1}})
+	require.EqualError(t, err, "mysql: sequences are supported only by MariaDB 10.3 and above")
+}
+
 func TestDefaultPlan(t *testing.T) {
 	changes, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
 		&schema.AddTable{T: schema.NewTable("t1").SetSchema(schema.New("s1")).AddColumns(schema.NewIntColumn("a", "int"))},
@@ -1412,3 +1583,27 @@ func newMigrate(version string) (migrate.PlanApplier, *mock, error) {
 }
 
 func join(lines ...string) string { return strings.Join(lines, "\n") }
+
+func TestPlanChanges_CloneTable(t *testing.T) {
+	var (
+		users = schema.NewTable("users").
+			SetSchema(schema.New("test")).
+			AddColumns(schema.NewIntColumn("id", "int"), schema.NewStringColumn("name", "varchar(255)"))
+		history = schema.NewTable("users_history").
+			SetSchema(users.Schema).
+			AddColumns(schema.NewIntColumn("id", "int"), schema.NewStringColumn("name", "varchar(255)"))
+	)
+	users.AddIndexes(schema.NewIndex("name").AddColumns(users.Columns[1]))
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.CloneTable{T: history, Source: users},
+		&schema.ModifyTable{T: users, Changes: []schema.Change{&schema.AddIndex{I: users.Indexes[0]}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 3)
+	require.Equal(t, "ALTER TABLE `test`.`users` ADD INDEX `name` (`name`)", plan.Changes[0].Cmd)
+	require.Equal(t, "CREATE TABLE `test`.`users_history` LIKE `test`.`users`", plan.Changes[1].Cmd)
+	require.Equal(t, "DROP TABLE `test`.`users_history`", plan.Changes[1].Reverse)
+	require.True(t, plan.Reversible)
+	require.Equal(t, "ALTER TABLE `test`.`users_history` DROP INDEX `name`", plan.Changes[2].Cmd)
+	require.Equal(t, "ALTER TABLE `test`.`users_history` ADD INDEX `name` (`name`)", plan.Changes[2].Reverse)
+}
@@ -244,11 +244,11 @@ func devDriver(t *testing.T, version string) migrate.Driver {
 	require.NoError(t, err)
 	mk.ExpectQuery("SELECT @@version, @@collation_server, @@character_set_server, @@lower_case_table_name").
 		WillReturnRows(sqltest.Rows(`
-+-----------------+--------------------+------------------------+--------------------------+ 
-| @@version       | @@collation_server | @@character_set_server | @@lower_case_table_names | 
-+-----------------+--------------------+------------------------+--------------------------+ 
-|` + version + `  | utf8_general_ci    | utf8                   | 0                        | 
-+-----------------+--------------------+------------------------+--------------------------+ 
++-----------------+--------------------+------------------------+--------------------------+-------------+ 
+| @@version       | @@collation_server | @@character_set_server | @@lower_case_table_names | @@time_zone | 
++-----------------+--------------------+------------------------+--------------------------+-------------+ 
+|` + version + `  | utf8_general_ci    | utf8                   | 0                        | SYSTEM      | 
++-----------------+--------------------+------------------------+--------------------------+-------------+ 
 `))
 	drv, err := mysql.Open(db)
 	require.NoError(t, err)
@@ -0,0 +1,103 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+//go:build !ent
+
+package mysql
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlerr"
+)
+
+// simulateTable is the name of the table copy used for simulating ALTER TABLE statements.
+const simulateTable = "atlas_simulate"
+
+// SimulateChange implements migrate.Simulator. ALTER TABLE statements are simulated by
+// executing them on an empty copy of the table, with the cheapest ALGORITHM and LOCK
+// clauses accepted by the server. Other statements are not simulated.
+func (d *Driver) SimulateChange(ctx context.Context, c *migrate.Change) (_ *migrate.Simulation, err error) {
+	m, ok := c.Source.(*schema.ModifyTable)
+	// Foreign keys are not copied by CREATE TABLE ... LIKE,
+	// and therefore, changes on them cannot be simulated.
+	if !ok || d.TiDB() || len(c.Args) > 0 || slices.ContainsFunc(m.Changes, func(c schema.Change) bool {
+		switch c.(type) {
+		case *schema.DropForeignKey, *schema.ModifyForeignKey:
+			return true
+		}
+		return false
+	}) {
+		return nil, nil
+	}
+	clauses, ok := d.alterClauses(c.Cmd, m.T)
+	if !ok {
+		return nil, nil
+	}
+	tc := schema.NewTable(simulateTable).SetSchema(m.T.Schema)
+	b := d.StmtBuilder(migrate.PlanOptions{}).P("CREATE TABLE").Table(tc).P("LIKE").Table(m.T)
+	switch _, err := d.ExecContext(ctx, b.String()); {
+	case errors.Is(err, sqlerr.NotExist):
+		// The table does not exist yet. e.g., it is created by the plan.
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+	defer func() {
+		if _, err2 := d.ExecContext(ctx, d.StmtBuilder(migrate.PlanOptions{}).P("DROP TABLE").Table(tc).String()); err2 != nil {
+			err = errors.Join(err, err2)
+		}
+	}()
+	var last error
+	for _, o := range d.alterOptions() {
+		b := d.StmtBuilder(migrate.PlanOptions{}).P("ALTER TABLE").Table(tc).P(clauses).Comma().P("ALGORITHM=" + o[0])
+		if o[1] != "" {
+			b.Comma().P("LOCK=" + o[1])
+		}
+		switch _, err := d.ExecContext(ctx, b.String()); {
+		case err == nil:
+			lock := o[1]
+			if lock == "" {
+				lock = "NONE"
+			}
+			return &migrate.Simulation{Feasible: true, Algorithm: o[0], Lock: lock}, nil
+		case errors.Is(err, sqlerr.Unsupported):
+			last = err
+		default:
+			return &migrate.Simulation{Reason: err.Error()}, nil
+		}
+	}
+	return &migrate.Simulation{Reason: last.Error()}, nil
+}
+
+// alterClauses returns the clauses of the given ALTER TABLE
+// statement, if it was planned for the given table.
+func (d *Driver) alterClauses(cmd string, t *schema.Table) (string, bool) {
+	for _, q := range []*string{nil, new(string)} {
+		prefix := d.StmtBuilder(migrate.PlanOptions{SchemaQualifier: q}).P("ALTER TABLE").Table(t).String()
+		if clauses, ok := strings.CutPrefix(cmd, prefix+" "); ok && clauses != "" {
+			return clauses, true
+		}
+	}
+	return "", false
+}
+
+// alterOptions returns the ALGORITHM and LOCK options to try
+// when simulating an ALTER TABLE, from cheapest to costliest.
+func (d *Driver) alterOptions() [][2]string {
+	opts := [][2]string{
+		{"INPLACE", "NONE"}, {"INPLACE", "SHARED"}, {"INPLACE", "EXCLUSIVE"},
+		{"COPY", "SHARED"}, {"COPY", "EXCLUSIVE"},
+	}
+	if d.SupportsInstantAlgorithm() {
+		// Only the default lock level is allowed with INSTANT.
+		opts = append([][2]string{{"INSTANT", ""}}, opts...)
+	}
+	return opts
+}
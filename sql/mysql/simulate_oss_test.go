@@ -0,0 +1,74 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+//go:build !ent
+
+package mysql
+
+import (
+	"context"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriver_SimulateChange(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("8.0.13")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	var (
+		ctx    = context.Background()
+		users  = schema.NewTable("users").SetSchema(schema.New("test")).AddColumns(schema.NewIntColumn("id", "int"))
+		c      = schema.NewIntColumn("c", "int")
+		change = &migrate.Change{
+			Cmd:    "ALTER TABLE `test`.`users` ADD COLUMN `c` int NOT NULL",
+			Source: &schema.ModifyTable{T: users, Changes: []schema.Change{&schema.AddColumn{C: c}}},
+		}
+		sim = drv.(migrate.Simulator)
+	)
+
+	// Non-table changes are not simulated.
+	r, err := sim.SimulateChange(ctx, &migrate.Change{Cmd: "CREATE DATABASE `test`", Source: &schema.AddSchema{S: users.Schema}})
+	require.NoError(t, err)
+	require.Nil(t, r)
+
+	// INSTANT is not supported, but INPLACE without locking is.
+	m.ExpectExec(sqltest.Escape("CREATE TABLE `test`.`atlas_simulate` LIKE `test`.`users`")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectExec(sqltest.Escape("ALTER TABLE `test`.`atlas_simulate` ADD COLUMN `c` int NOT NULL, ALGORITHM=INSTANT")).
+		WillReturnError(&mysqlError{Number: 1845, Message: "ALGORITHM=INSTANT is not supported for this operation"})
+	m.ExpectExec(sqltest.Escape("ALTER TABLE `test`.`atlas_simulate` ADD COLUMN `c` int NOT NULL, ALGORITHM=INPLACE, LOCK=NONE")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectExec(sqltest.Escape("DROP TABLE `test`.`atlas_simulate`")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	r, err = sim.SimulateChange(ctx, change)
+	require.NoError(t, err)
+	require.Equal(t, &migrate.Simulation{Feasible: true, Algorithm: "INPLACE", Lock: "NONE"}, r)
+
+	// Changes that are rejected by the server are not feasible.
+	m.ExpectExec(sqltest.Escape("CREATE TABLE `test`.`atlas_simulate` LIKE `test`.`users`")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectExec(sqltest.Escape("ALTER TABLE `test`.`atlas_simulate` ADD COLUMN `c` int NOT NULL, ALGORITHM=INSTANT")).
+		WillReturnError(&mysqlError{Number: 1060, Message: "Duplicate column name 'c'"})
+	m.ExpectExec(sqltest.Escape("DROP TABLE `test`.`atlas_simulate`")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	r, err = sim.SimulateChange(ctx, change)
+	require.NoError(t, err)
+	require.Equal(t, &migrate.Simulation{Reason: "Error 1060: Duplicate column name 'c'"}, r)
+
+	// Tables that do not exist yet are not simulated.
+	m.ExpectExec(sqltest.Escape("CREATE TABLE `test`.`atlas_simulate` LIKE `test`.`users`")).
+		WillReturnError(&mysqlError{Number: 1146, Message: "Table 'test.users' doesn't exist"})
+	r, err = sim.SimulateChange(ctx, change)
+	require.NoError(t, err)
+	require.Nil(t, r)
+	require.NoError(t, m.ExpectationsWereMet())
+}
@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 
 	"ariga.io/atlas/schemahcl"
@@ -46,6 +47,9 @@ func (c *Codec) EvalOptions(p *hclparse.Parser, v any, opts *schemahcl.EvalOptio
 		); err != nil {
 			return fmt.Errorf("mysql: failed converting to *schema.Realm: %w", err)
 		}
+		if err := convertSequences(d.Sequences, v); err != nil {
+			return err
+		}
 		for _, spec := range d.Schemas {
 			s, ok := v.Schema(spec.Name)
 			if !ok {
@@ -73,6 +77,9 @@ func (c *Codec) EvalOptions(p *hclparse.Parser, v any, opts *schemahcl.EvalOptio
 		if err := convertCharset(d.Schemas[0], &r.Schemas[0].Attrs); err != nil {
 			return err
 		}
+		if err := convertSequences(d.Sequences, r); err != nil {
+			return err
+		}
 		*v = *r.Schemas[0]
 	case schema.Schema, schema.Realm:
 		return fmt.Errorf("mysql: Eval expects a pointer: received %[1]T, expected *%[1]T", v)
@@ -172,9 +179,75 @@ func convertTable(spec *sqlspec.Table, parent *schema.Schema) (*schema.Table, er
 		}
 		t.AddAttrs(&Engine{V: v})
 	}
+	if err := convertTableOptions(spec, t); err != nil {
+		return nil, err
+	}
+	if err := convertHistograms(spec.Extra, t); err != nil {
+		return nil, err
+	}
 	return t, nil
 }
 
+// convertHistograms converts and appends the histogram blocks into the table attributes.
+func convertHistograms(spec schemahcl.Resource, t *schema.Table) error {
+	for _, r := range spec.Resources("histogram") {
+		var h struct {
+			Column  *schemahcl.Ref `spec:"column"`
+			Buckets int            `spec:"buckets"`
+		}
+		if err := r.As(&h); err != nil {
+			return fmt.Errorf("parsing %s.histogram: %w", t.Name, err)
+		}
+		if h.Column == nil {
+			return fmt.Errorf("missing column for %s.histogram", t.Name)
+		}
+		c, err := specutil.ColumnByRef(t, h.Column)
+		if err != nil {
+			return err
+		}
+		t.AddAttrs(&Histogram{C: c, Buckets: h.Buckets})
+	}
+	return nil
+}
+
+// convertTableOptions converts the table options defined
+// in the spec (e.g., row_format) to table attributes.
+func convertTableOptions(spec *sqlspec.Table, t *schema.Table) error {
+	if attr, ok := spec.Attr("row_format"); ok {
+		v, err := attr.String()
+		if err != nil {
+			return err
+		}
+		t.AddAttrs(&RowFormat{V: strings.ToUpper(v)})
+	}
+	if attr, ok := spec.Attr("key_block_size"); ok {
+		v, err := attr.Int64()
+		if err != nil {
+			return err
+		}
+		t.AddAttrs(&KeyBlockSize{V: v})
+	}
+	if attr, ok := spec.Attr("stats_persistent"); ok {
+		v, err := attr.String()
+		// Numeric values (0 or 1) are accepted as well.
+		if n, err1 := attr.Int64(); err != nil && err1 == nil {
+			v, err = strconv.FormatInt(n, 10), nil
+		}
+		if err != nil {
+			return err
+		}
+		t.AddAttrs(&StatsPersistent{V: strings.ToUpper(v)})
+	}
+	if attr, ok := spec.Attr("compression"); ok {
+		v, err := attr.String()
+		if err != nil {
+			return err
+		}
+		t.AddAttrs(&Compression{V: v})
+	}
+	return nil
+}
+
 // convertPK converts a sqlspec.PrimaryKey into a schema.Index.
 func convertPK(spec *sqlspec.PrimaryKey, parent *schema.Table) (*schema.Index, error) {
 	return convertIndex(&sqlspec.Index{
@@ -196,6 +269,13 @@ func convertIndex(spec *sqlspec.Index, parent *schema.Table) (*schema.Index, err
 	if err := convertIndexParser(spec, idx); err != nil {
 		return nil, err
 	}
+	if attr, ok := spec.Attr("key_block_size"); ok {
+		v, err := attr.Int64()
+		if err != nil {
+			return nil, err
+		}
+		idx.AddAttrs(&KeyBlockSize{V: v})
+	}
 	return idx, nil
 }
 
@@ -276,6 +356,15 @@ func convertColumn(spec *sqlspec.Column, _ *schema.Table) (*schema.Column, error
 			c.AddAttrs(&AutoIncrement{})
 		}
 	}
+	if attr, ok := spec.Attr("invisible"); ok {
+		b, err := attr.Bool()
+		if err != nil {
+			return nil, err
+		}
+		if b {
+			c.AddAttrs(&Invisible{})
+		}
+	}
 	if err := specutil.ConvertGenExpr(spec.Remain(), c, storedOrVirtual); err != nil {
 		return nil, err
 	}
@@ -299,9 +388,45 @@ func schemaSpec(s *schema.Schema) (*specutil.SchemaSpec, error) {
 	if c, ok := sqlx.Collate(s.Attrs, nil); ok {
 		spec.Schema.Extra.Attrs = append(spec.Schema.Extra.Attrs, schemahcl.StringAttr("collate", c))
 	}
+	for _, o := range s.Objects {
+		if seq, ok := o.(*Sequence); ok {
+			spec.Sequences = append(spec.Sequences, sequenceSpec(seq))
+		}
+	}
 	return spec, nil
 }
 
+// sequenceSpec converts from a MariaDB sequence to its spec. Options
+// that are equal to their defaults are omitted from the spec.
+func sequenceSpec(seq *Sequence) *sqlspec.Sequence {
+	spec := &sqlspec.Sequence{
+		Name:   seq.Name,
+		Schema: specutil.SchemaRef(seq.Schema.Name),
+	}
+	if seq.Start != 0 {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.Int64Attr("start", seq.Start))
+	}
+	if seq.Increment != 0 && seq.Increment != 1 {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.Int64Attr("increment", seq.Increment))
+	}
+	if seq.Min != nil {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.Int64Attr("min_value", *seq.Min))
+	}
+	if seq.Max != nil {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.Int64Attr("max_value", *seq.Max))
+	}
+	if seq.Cache != 0 && seq.Cache != defaultSeqCache {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.Int64Attr("cache", seq.Cache))
+	}
+	if seq.Cycle {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.BoolAttr("cycle", true))
+	}
+	if c := seqComment(seq); c != "" {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.StringAttr("comment", c))
+	}
+	return spec
+}
+
 // tableSpec converts from a concrete MySQL sqlspec.Table to a schema.Table.
 func tableSpec(t *schema.Table) (*sqlspec.Table, error) {
 	ts, err := specutil.FromTable(
@@ -332,6 +457,35 @@ func tableSpec(t *schema.Table) (*sqlspec.Table, error) {
 		}
 		ts.Extra.Attrs = append(ts.Extra.Attrs, attr)
 	}
+	if f := (&RowFormat{}); sqlx.Has(t.Attrs, f) && f.V != "" {
+		ts.Extra.Attrs = append(ts.Extra.Attrs, schemahcl.StringAttr("row_format", f.V))
+	}
+	if k := (&KeyBlockSize{}); sqlx.Has(t.Attrs, k) {
+		ts.Extra.Attrs = append(ts.Extra.Attrs, schemahcl.Int64Attr("key_block_size", k.V))
+	}
+	if p := (&StatsPersistent{}); sqlx.Has(t.Attrs, p) && p.V != "" {
+		// Numeric values (0 or 1) are written back as numbers.
+		if n, err := strconv.ParseInt(p.V, 10, 64); err == nil {
+			ts.Extra.Attrs = append(ts.Extra.Attrs, schemahcl.Int64Attr("stats_persistent", n))
+		} else {
+			ts.Extra.Attrs = append(ts.Extra.Attrs, schemahcl.StringAttr("stats_persistent", p.V))
+		}
+	}
+	if c := (&Compression{}); sqlx.Has(t.Attrs, c) && c.V != "" {
+		ts.Extra.Attrs = append(ts.Extra.Attrs, schemahcl.StringAttr("compression", c.V))
+	}
+	for _, a := range t.Attrs {
+		if h, ok := a.(*Histogram); ok && h.C != nil {
+			r := &schemahcl.Resource{
+				Type:  "histogram",
+				Attrs: []*schemahcl.Attr{schemahcl.RefAttr("column", specutil.ColumnRef(h.C.Name))},
+			}
+			if h.Buckets > 0 {
+				r.Attrs = append(r.Attrs, schemahcl.IntAttr("buckets", h.Buckets))
+			}
+			ts.Extra.Children = append(ts.Extra.Children, r)
+		}
+	}
 	return ts, nil
 }
 
@@ -373,6 +527,9 @@ func indexTypeSpec(idx *schema.Index, attrs []*schemahcl.Attr) []*schemahcl.Attr
 		}
 		attrs = append(attrs, attr)
 	}
+	if k := (KeyBlockSize{}); sqlx.Has(idx.Attrs, &k) && k.V > 0 {
+		attrs = append(attrs, schemahcl.Int64Attr("key_block_size", k.V))
+	}
 	return attrs
 }
 
@@ -401,6 +558,9 @@ func columnSpec(c *schema.Column, t *schema.Table) (*sqlspec.Column, error) {
 	if sqlx.Has(c.Attrs, &AutoIncrement{}) {
 		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.BoolAttr("auto_increment", true))
 	}
+	if sqlx.Has(c.Attrs, &Invisible{}) {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.BoolAttr("invisible", true))
+	}
 	if x := (schema.GeneratedExpr{}); sqlx.Has(c.Attrs, &x) {
 		spec.Extra.Children = append(spec.Extra.Children, specutil.FromGenExpr(x, storedOrVirtual))
 	}
@@ -471,6 +631,62 @@ func convertCharset(spec specutil.Attrer, attrs *[]schema.Attr) error {
 	return nil
 }
 
+// convertSequences converts the sequence specs into MariaDB sequences
+// and adds them to the objects of their schemas in the realm.
+func convertSequences(specs []*sqlspec.Sequence, r *schema.Realm) error {
+	for _, spec := range specs {
+		name, err := specutil.SchemaName(spec.Schema)
+		if err != nil {
+			return fmt.Errorf("mysql: extract schema name from sequence reference: %w", err)
+		}
+		s, ok := r.Schema(name)
+		if !ok {
+			return fmt.Errorf("mysql: schema %q for sequence %q was not found", name, spec.Name)
+		}
+		seq := &Sequence{Name: spec.Name, Schema: s}
+		for _, a := range []struct {
+			name string
+			v    *int64
+		}{
+			{"start", &seq.Start}, {"increment", &seq.Increment}, {"cache", &seq.Cache},
+		} {
+			if attr, ok := spec.Attr(a.name); ok {
+				if *a.v, err = attr.Int64(); err != nil {
+					return fmt.Errorf("mysql: sequence %q: %w", spec.Name, err)
+				}
+			}
+		}
+		for _, a := range []struct {
+			name string
+			v    **int64
+		}{
+			{"min_value", &seq.Min}, {"max_value", &seq.Max},
+		} {
+			if attr, ok := spec.Attr(a.name); ok {
+				v, err := attr.Int64()
+				if err != nil {
+					return fmt.Errorf("mysql: sequence %q: %w", spec.Name, err)
+				}
+				*a.v = &v
+			}
+		}
+		if attr, ok := spec.Attr("cycle"); ok {
+			if seq.Cycle, err = attr.Bool(); err != nil {
+				return fmt.Errorf("mysql: sequence %q: %w", spec.Name, err)
+			}
+		}
+		if attr, ok := spec.Attr("comment"); ok {
+			c, err := attr.String()
+			if err != nil {
+				return fmt.Errorf("mysql: sequence %q: %w", spec.Name, err)
+			}
+			seq.Attrs = append(seq.Attrs, &schema.Comment{Text: c})
+		}
+		s.Objects = append(s.Objects, seq)
+	}
+	return nil
+}
+
 // TypeRegistry contains the supported TypeSpecs for the mysql driver.
 var TypeRegistry = schemahcl.NewRegistry(
 	schemahcl.WithFormatter(FormatType),
@@ -8,6 +8,7 @@ package mysql
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"ariga.io/atlas/sql/internal/spectest"
@@ -675,6 +676,136 @@ schema "a8m" {}
 	}
 }
 
+func TestMarshalSpec_Sequences(t *testing.T) {
+	var (
+		s   = schema.New("test")
+		min = int64(-10)
+	)
+	s.AddObjects(
+		&Sequence{Name: "s1", Schema: s, Start: 1, Increment: 1, Cache: 1000},
+		&Sequence{Name: "s2", Schema: s, Start: 5, Increment: -1, Min: &min, Cache: 10, Cycle: true, Attrs: []schema.Attr{&schema.Comment{Text: "c"}}},
+	)
+	buf, err := MarshalHCL(s)
+	require.NoError(t, err)
+	require.Equal(t, `sequence "s1" {
+  schema = schema.test
+  start  = 1
+}
+sequence "s2" {
+  schema    = schema.test
+  start     = 5
+  increment = -1
+  min_value = -10
+  cache     = 10
+  cycle     = true
+  comment   = "c"
+}
+schema "test" {
+}
+`, string(buf))
+
+	var got schema.Schema
+	require.NoError(t, EvalMariaHCLBytes(buf, &got, nil))
+	require.Len(t, got.Objects, 2)
+	require.Equal(t, &Sequence{Name: "s1", Schema: &got, Start: 1}, got.Objects[0])
+	require.Equal(t, &Sequence{
+		Name: "s2", Schema: &got, Start: 5, Increment: -1, Min: &min, Cache: 10, Cycle: true,
+		Attrs: []schema.Attr{&schema.Comment{Text: "c"}},
+	}, got.Objects[1])
+}
+
+func TestMarshalSpec_TableOptions(t *testing.T) {
+	var (
+		s = schema.New("a8m")
+		f = `table "users" {
+  schema           = schema.a8m
+  row_format       = "COMPRESSED"
+  key_block_size   = 8
+  stats_persistent = 1
+  compression      = "zlib"
+  column "id" {
+    null = false
+    type = bigint
+  }
+  index "id" {
+    columns        = [column.id]
+    key_block_size = 4
+  }
+}
+schema "a8m" {
+}
+`
+	)
+	require.NoError(t, EvalHCLBytes([]byte(f), s, nil))
+	require.EqualValues(t, []schema.Attr{
+		&RowFormat{V: "COMPRESSED"},
+		&KeyBlockSize{V: 8},
+		&StatsPersistent{V: "1"},
+		&Compression{V: "zlib"},
+	}, s.Tables[0].Attrs)
+	require.EqualValues(t, []schema.Attr{&KeyBlockSize{V: 4}}, s.Tables[0].Indexes[0].Attrs)
+	buf, err := MarshalHCL(s)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+
+	// Non-numeric values are written as strings.
+	s.Tables[0].Attrs[2] = &StatsPersistent{V: "DEFAULT"}
+	buf, err = MarshalHCL(s)
+	require.NoError(t, err)
+	require.Equal(t, strings.Replace(f, "stats_persistent = 1", `stats_persistent = "DEFAULT"`, 1), string(buf))
+}
+
+func TestMarshalSpec_InvisibleHistogram(t *testing.T) {
+	var (
+		s = schema.New("a8m")
+		f = `table "users" {
+  schema = schema.a8m
+  column "my_row_id" {
+    null           = false
+    type           = bigint
+    unsigned       = true
+    auto_increment = true
+    invisible      = true
+  }
+  column "name" {
+    null = false
+    type = varchar(255)
+  }
+  primary_key {
+    columns = [column.my_row_id]
+  }
+  histogram {
+    column  = column.name
+    buckets = 64
+  }
+}
+schema "a8m" {
+}
+`
+	)
+	require.NoError(t, EvalHCLBytes([]byte(f), s, nil))
+	users := s.Tables[0]
+	require.EqualValues(t, []schema.Attr{&AutoIncrement{}, &Invisible{}}, users.Columns[0].Attrs)
+	require.EqualValues(t, []schema.Attr{&Histogram{C: users.Columns[1], Buckets: 64}}, users.Attrs)
+	buf, err := MarshalHCL(s)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+
+	err = EvalHCLBytes([]byte(`
+schema "a8m" {}
+table "users" {
+  schema = schema.a8m
+  column "name" {
+    type = text
+  }
+  histogram {
+    buckets = 64
+  }
+}
+`), schema.New("a8m"), nil)
+	require.EqualError(t, err, `cannot convert table "users": missing column for users.histogram`)
+}
+
 func TestUnmarshalSpec_IndexParts(t *testing.T) {
 	var (
 		s schema.Schema
@@ -1563,6 +1694,38 @@ func TestParseType_Decimal(t *testing.T) {
 	}
 }
 
+func TestFormatType_RoundTrip(t *testing.T) {
+	for _, typ := range []string{
+		"decimal(10,0)",
+		"decimal(10,2)",
+		"decimal(10,2) unsigned",
+		"bit",
+		"bit(64)",
+		"varchar(255)",
+		"char(5)",
+		"binary(16)",
+		"varbinary(255)",
+		"datetime",
+		"datetime(6)",
+		"timestamp(3)",
+		"time(2)",
+		"int unsigned",
+		"double unsigned",
+		"enum('a','b')",
+		"set('a','b')",
+	} {
+		t.Run(typ, func(t *testing.T) {
+			t1, err := ParseType(typ)
+			require.NoError(t, err)
+			f, err := FormatType(t1)
+			require.NoError(t, err)
+			t2, err := ParseType(f)
+			require.NoError(t, err)
+			require.Equal(t, t1, t2)
+		})
+	}
+}
+
 func typeTime(t string, p int) schema.Type {
 	return &schema.TimeType{T: t, Precision: &p}
 }
@@ -82,7 +82,7 @@ func flat(changes []schema.Change) []schema.Change {
 
 // PlanChanges returns a migration plan for the given schema changes.
 func (p *tplanApply) PlanChanges(ctx context.Context, name string, changes []schema.Change, opts ...migrate.PlanOption) (*migrate.Plan, error) {
-	planned, err := sqlx.DetachCycles(changes)
+	planned, err := sqlx.DetachCycles(sqlx.SkipVirtualFKs(changes))
 	if err != nil {
 		return nil, err
 	}
@@ -5,15 +5,49 @@
 package postgres
 
 import (
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
 
+	"ariga.io/atlas/sql/internal/sqlx"
 	"ariga.io/atlas/sql/schema"
 )
 
+// literals defines how Go values are formatted as PostgreSQL literals.
+var literals = &sqlx.LiteralFormat{
+	String:     QuoteString,
+	Bytes:      func(b []byte) string { return `'\x` + hex.EncodeToString(b) + "'" },
+	Bool:       strconv.FormatBool,
+	TimeLayout: "2006-01-02 15:04:05.999999Z07:00",
+	Array: func(elems []string) string {
+		// An empty ARRAY[] cannot be used without an explicit cast.
+		if len(elems) == 0 {
+			return "'{}'"
+		}
+		return "ARRAY[" + strings.Join(elems, ", ") + "]"
+	},
+}
+
+// QuoteString quotes the given string as a PostgreSQL string literal. Note, backslashes
+// are not escaped, as standard_conforming_strings is enabled by default since PostgreSQL 9.1.
+func QuoteString(s string) string {
+	return sqlx.SingleQuoteEscape(s)
+}
+
+// FormatLiteral formats the given Go value as a PostgreSQL literal. Slices
+// and arrays are formatted using the ARRAY constructor, binary strings using
+// the hex format, and JSON documents (e.g., maps) as string literals.
+//
+//	FormatLiteral("it's")                 // 'it''s'
+//	FormatLiteral([]int{1, 2})            // ARRAY[1, 2]
+//	FormatLiteral(map[string]int{"a": 1}) // '{"a":1}'
+func FormatLiteral(v any) (string, error) {
+	return literals.Format(v)
+}
+
 // FormatType converts schema type to its column form in the database.
 // An error is returned if the type cannot be recognized.
 func FormatType(t schema.Type) (string, error) {
@@ -42,6 +76,11 @@ func FormatType(t schema.Type) (string, error) {
 			return "", errors.New("postgres: missing composite type name")
 		}
 		f = t.T
+	case *UserRangeType:
+		if t.T == "" {
+			return "", errors.New("postgres: missing range type name")
+		}
+		f = t.T
 	case *RowType:
 		if t.T == nil || t.T.Name == "" {
 			return "", errors.New("postgres: missing table for composite (row) type")
@@ -388,7 +427,7 @@ func parseColumn(s string) (*columnDesc, error) {
 				return nil, fmt.Errorf("postgres: parse scale %q: %w", parts[1], err)
 			}
 		}
-	case TypeBit:
+	case TypeBit, TypeVarBit:
 		if err := parseBitParts(parts, c); err != nil {
 			return nil, err
 		}
@@ -451,21 +490,27 @@ func parseCharParts(parts []string, c *columnDesc) error {
 }
 
 func parseBitParts(parts []string, c *columnDesc) error {
-	if len(parts) == 1 {
+	switch {
+	// VARBIT is an alias for BIT VARYING.
+	case parts[0] == TypeVarBit:
+		c.typ = TypeBitVar
+		parts = parts[1:]
+	case len(parts) == 1:
 		c.size = 1
 		return nil
-	}
-	parts = parts[1:]
-	if parts[0] == "varying" {
-		c.typ = TypeBitVar
+	default:
 		parts = parts[1:]
+		if parts[0] == "varying" {
+			c.typ = TypeBitVar
+			parts = parts[1:]
+		}
 	}
 	if len(parts) == 0 {
 		return nil
 	}
 	size, err := strconv.ParseInt(parts[0], 10, 64)
 	if err != nil {
-		return fmt.Errorf("postgres: parse size %q: %w", parts[1], err)
+		return fmt.Errorf("postgres: parse size %q: %w", parts[0], err)
 	}
 	c.size = size
 	return nil
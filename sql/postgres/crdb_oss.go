@@ -9,13 +9,16 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"ariga.io/atlas/sql/internal/sqlx"
 	"ariga.io/atlas/sql/migrate"
 	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlerr"
 )
 
 type (
@@ -24,6 +27,7 @@ type (
 	noLocker    interface {
 		migrate.Driver
 		schema.Normalizer
+		schema.SchemasNormalizer
 	}
 	noLockDriver struct {
 		noLocker
@@ -323,7 +327,12 @@ SELECT
 	t4.typtype,
 	t4.typelem,
 	t4.oid,
-	a.attnum
+	a.attnum,
+	t5.min_value AS identity_minimum,
+	t5.max_value AS identity_maximum,
+	t5.cache_size AS identity_cache,
+	CASE WHEN t5.cycle THEN 'YES' ELSE 'NO' END AS identity_cycle,
+	NULL AS compression
 FROM
 	"information_schema"."columns" AS t1
 	JOIN pg_catalog.pg_namespace AS t2 ON t2.nspname = t1.table_schema
@@ -339,3 +348,48 @@ ORDER BY
 	t1.table_name, t1.ordinal_position
 `
 )
+
+// reCRDBVersion extracts the major and minor versions from the server version string.
+var reCRDBVersion = regexp.MustCompile(`v(\d+)\.(\d+)`)
+
+// SimulateChange implements migrate.Simulator. Statements are simulated using the EXPLAIN (DDL)
+// command, that is supported in CockroachDB v22.1 and above. Statements that are not supported
+// by the declarative schema changer, or that depend on objects created by the plan, are not
+// simulated.
+func (d noLockDriver) SimulateChange(ctx context.Context, c *migrate.Change) (*migrate.Simulation, error) {
+	drv, ok := d.noLocker.(*Driver)
+	if !ok || !drv.crdbExplainDDL() {
+		return nil, nil
+	}
+	rows, err := drv.QueryContext(ctx, "EXPLAIN (DDL) "+c.Cmd, c.Args...)
+	switch {
+	case errors.Is(err, sqlerr.Unsupported), errors.Is(err, sqlerr.NotExist):
+		return nil, nil
+	case err != nil:
+		return &migrate.Simulation{Reason: err.Error()}, nil
+	}
+	defer rows.Close()
+	var details []string
+	for rows.Next() {
+		var info string
+		if err := rows.Scan(&info); err != nil {
+			return nil, err
+		}
+		details = append(details, info)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return &migrate.Simulation{Feasible: true, Details: details}, nil
+}
+
+// crdbExplainDDL reports if the CockroachDB version supports the EXPLAIN (DDL) command.
+func (c *conn) crdbExplainDDL() bool {
+	m := reCRDBVersion.FindStringSubmatch(c.crdbV)
+	if len(m) != 3 {
+		return false
+	}
+	major, _ := strconv.Atoi(m[1])
+	minor, _ := strconv.Atoi(m[2])
+	return major > 22 || major == 22 && minor >= 1
+}
@@ -0,0 +1,294 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+//go:build !ent
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	// DataMove describes a batched data movement between two tables, or between columns of
+	// the same table. Rows are processed in batches, ordered by the primary key of the source
+	// table, and each batch is executed as a single statement. Hence, large tables can be
+	// rebuilt (e.g., for column type changes) without holding long-running locks, and an
+	// interrupted move can be resumed from the last completed batch.
+	//
+	// Note, COPY cannot be used through the database/sql interface, and therefore, batches
+	// are executed using INSERT ... SELECT statements, or UPDATE statements in case Source
+	// and Target are the same table.
+	DataMove struct {
+		// Source is the table to read the rows from. It must have a primary key.
+		Source *schema.Table
+		// Target is the table to write the rows to. If Target is nil or equals
+		// to Source, the rows are updated in place (i.e., a backfill).
+		Target *schema.Table
+		// Columns to move. At least one column is required.
+		Columns []*MoveColumn
+		// BatchSize is the number of rows in each batch. Defaults to 10,000.
+		BatchSize int
+		// SkipConflicts indicates that rows conflicting with existing rows in Target
+		// (e.g., a row with the same primary key) are skipped silently, and are not
+		// counted in the progress. By default, conflicts fail the move.
+		SkipConflicts bool
+		// Resume is the marker to resume the move from, as reported by a Progress
+		// of a previous run. Rows up to the marker (inclusive) are skipped.
+		Resume []any
+		// Progress, if not nil, is called after each completed batch.
+		Progress func(*MoveProgress)
+	}
+
+	// MoveColumn describes a column to move.
+	MoveColumn struct {
+		// From is the source column. Expr, if set, is used as the source value
+		// instead. e.g., "amount::numeric(10,2)" for type changes that require
+		// an explicit conversion.
+		From *schema.Column
+		Expr string
+		// To is the target column.
+		To *schema.Column
+	}
+
+	// MoveProgress reports the progress of a data move.
+	MoveProgress struct {
+		Batches int   // Number of completed batches.
+		Rows    int64 // Number of rows written.
+		// Marker holds the primary key values of the last row in the completed
+		// batches. It can be stored and used to resume an interrupted move.
+		Marker []any
+		// Done indicates the move was completed.
+		Done bool
+	}
+)
+
+// DefaultMoveBatchSize is the default number of rows in a DataMove batch.
+const DefaultMoveBatchSize = 10000
+
+// BackfillMove returns a DataMove for executing the given backfill of an
+// expand/contract migration in batches, instead of a single UPDATE statement.
+// Backfills planned by the Driver are executed in batches using the same
+// strategy, in case the table has a single-column primary key. See Backfill.
+func BackfillMove(b *migrate.Backfill) *DataMove {
+	return &DataMove{
+		Source:  b.T,
+		Target:  b.T,
+		Columns: []*MoveColumn{{From: b.From, Expr: b.Using, To: b.To}},
+	}
+}
+
+// MoveData executes the given data move in batches, and returns its final progress. In case
+// of an error, the returned progress (if not nil) holds the marker to resume the move from.
+func (d *Driver) MoveData(ctx context.Context, m *DataMove) (*MoveProgress, error) {
+	if err := m.validate(); err != nil {
+		return nil, fmt.Errorf("postgres: %w", err)
+	}
+	var (
+		p     = &MoveProgress{Marker: m.Resume}
+		pk    = m.Source.PrimaryKey
+		size  = m.BatchSize
+		build = func() *sqlx.Builder { return d.StmtBuilder(migrate.PlanOptions{}) }
+	)
+	if size <= 0 {
+		size = DefaultMoveBatchSize
+	}
+	for !p.Done {
+		// Find the upper bound of the next batch. A missing bound
+		// indicates the next batch is the last one.
+		b := build().P("SELECT")
+		keyParts(b, pk)
+		b.P("FROM").Table(m.Source)
+		args := afterMarker(b, pk, p.Marker)
+		b.P("ORDER BY")
+		keyParts(b, pk)
+		b.P("OFFSET", strconv.Itoa(size-1), "LIMIT 1")
+		bound := make([]any, len(pk.Parts))
+		ptrs := make([]any, len(bound))
+		for i := range bound {
+			ptrs[i] = &bound[i]
+		}
+		rows, err := d.QueryContext(ctx, b.String(), args...)
+		if err != nil {
+			return p, fmt.Errorf("postgres: query batch bound of table %q: %w", m.Source.Name, err)
+		}
+		if err := sqlx.ScanOne(rows, ptrs...); errors.Is(err, sql.ErrNoRows) {
+			bound, p.Done = nil, true
+		} else if err != nil {
+			return p, fmt.Errorf("postgres: scan batch bound of table %q: %w", m.Source.Name, err)
+		}
+		for i, v := range bound {
+			// Values of types without a native Go representation (e.g., uuid)
+			// are scanned as their text form, and must be passed back as text
+			// to be compared with the key columns. bytea is the exception.
+			if v, ok := v.([]byte); ok {
+				if _, ok := pk.Parts[i].C.Type.Type.(*schema.BinaryType); !ok {
+					bound[i] = string(v)
+				}
+			}
+		}
+		res, err := d.ExecContext(ctx, m.batch(build(), p.Marker, bound), slices.Concat(args, bound)...)
+		if err != nil {
+			return p, fmt.Errorf("postgres: move batch %d of table %q: %w", p.Batches+1, m.Source.Name, err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return p, err
+		}
+		p.Batches++
+		p.Rows += n
+		if !p.Done {
+			p.Marker = bound
+		}
+		if m.Progress != nil {
+			m.Progress(p)
+		}
+	}
+	return p, nil
+}
+
+// validate checks the data move is valid for execution.
+func (m *DataMove) validate() error {
+	switch {
+	case m.Source == nil:
+		return errors.New("missing source table for data move")
+	case m.Source.PrimaryKey == nil || len(m.Source.PrimaryKey.Parts) == 0:
+		return fmt.Errorf("moving data of table %q requires a primary key", m.Source.Name)
+	case len(m.Columns) == 0:
+		return fmt.Errorf("missing columns to move for table %q", m.Source.Name)
+	case m.Resume != nil && len(m.Resume) != len(m.Source.PrimaryKey.Parts):
+		return fmt.Errorf("resume marker of table %q has %d values, expected %d", m.Source.Name, len(m.Resume), len(m.Source.PrimaryKey.Parts))
+	}
+	for _, p := range m.Source.PrimaryKey.Parts {
+		if p.C == nil {
+			return fmt.Errorf("moving data of table %q requires a primary key without expressions", m.Source.Name)
+		}
+	}
+	for _, c := range m.Columns {
+		if c.To == nil || c.From == nil && c.Expr == "" {
+			return fmt.Errorf("invalid column to move for table %q", m.Source.Name)
+		}
+	}
+	return nil
+}
+
+// batch returns the statement for moving the rows between the two given markers.
+func (m *DataMove) batch(b *sqlx.Builder, from, to []any) string {
+	pk := m.Source.PrimaryKey
+	if m.Target == nil || m.Target == m.Source {
+		b.P("UPDATE").Table(m.Source).P("SET").MapComma(m.Columns, func(i int, b *sqlx.Builder) {
+			b.Ident(m.Columns[i].To.Name).P("=")
+			m.Columns[i].value(b)
+		})
+		afterMarker(b, pk, from)
+		beforeMarker(b, pk, len(from), to)
+		return b.String()
+	}
+	b.P("INSERT INTO").Table(m.Target).Wrap(func(b *sqlx.Builder) {
+		b.MapComma(m.Columns, func(i int, b *sqlx.Builder) {
+			b.Ident(m.Columns[i].To.Name)
+		})
+	}).P("SELECT").MapComma(m.Columns, func(i int, b *sqlx.Builder) {
+		m.Columns[i].value(b)
+	}).P("FROM").Table(m.Source)
+	afterMarker(b, pk, from)
+	beforeMarker(b, pk, len(from), to)
+	if m.SkipConflicts {
+		b.P("ON CONFLICT DO NOTHING")
+	}
+	return b.String()
+}
+
+// block returns a DO block that executes the data move in batches on the database, using
+// the same strategy as MoveData. It allows planning batched backfills in migration files.
+// Only in-place moves of tables with a single-column primary key are supported, and false
+// is returned otherwise. Note, the batches share the transaction of the migration file.
+func (m *DataMove) block(build func() *sqlx.Builder) (string, bool) {
+	if m.validate() != nil || m.Target != nil && m.Target != m.Source || len(m.Source.PrimaryKey.Parts) != 1 {
+		return "", false
+	}
+	size := m.BatchSize
+	if size <= 0 {
+		size = DefaultMoveBatchSize
+	}
+	var (
+		t   = build().Table(m.Source).String()
+		k   = build().Ident(m.Source.PrimaryKey.Parts[0].C.Name).String()
+		set = build().MapComma(m.Columns, func(i int, b *sqlx.Builder) {
+			b.Ident(m.Columns[i].To.Name).P("=")
+			m.Columns[i].value(b)
+		}).String()
+	)
+	return fmt.Sprintf(`DO $$
+DECLARE
+  last %[1]s.%[2]s%%TYPE;
+  bound %[1]s.%[2]s%%TYPE;
+BEGIN
+  LOOP
+    SELECT %[2]s INTO bound FROM %[1]s WHERE last IS NULL OR %[2]s > last ORDER BY %[2]s OFFSET %[3]d LIMIT 1;
+    UPDATE %[1]s SET %[4]s WHERE (last IS NULL OR %[2]s > last) AND (bound IS NULL OR %[2]s <= bound);
+    EXIT WHEN bound IS NULL;
+    last := bound;
+  END LOOP;
+END $$`, t, k, size-1, set), true
+}
+
+// value writes the source value of the column.
+func (c *MoveColumn) value(b *sqlx.Builder) {
+	if c.Expr != "" {
+		b.P(c.Expr)
+	} else {
+		b.Ident(c.From.Name)
+	}
+}
+
+// keyParts writes the primary key columns separated by commas.
+func keyParts(b *sqlx.Builder, pk *schema.Index) {
+	b.MapComma(pk.Parts, func(i int, b *sqlx.Builder) {
+		b.Ident(pk.Parts[i].C.Name)
+	})
+}
+
+// afterMarker writes the condition for selecting the rows after the
+// given marker, if exists, and returns its arguments.
+func afterMarker(b *sqlx.Builder, pk *schema.Index, marker []any) []any {
+	if len(marker) == 0 {
+		return nil
+	}
+	b.P("WHERE").Wrap(func(b *sqlx.Builder) { keyParts(b, pk) }).P(">").P(placeholders(0, len(marker)))
+	return marker
+}
+
+// beforeMarker writes the condition for selecting the rows up
+// to the given marker (inclusive), if exists.
+func beforeMarker(b *sqlx.Builder, pk *schema.Index, offset int, marker []any) {
+	if len(marker) == 0 {
+		return
+	}
+	if offset > 0 {
+		b.P("AND")
+	} else {
+		b.P("WHERE")
+	}
+	b.Wrap(func(b *sqlx.Builder) { keyParts(b, pk) }).P("<=").P(placeholders(offset, len(marker)))
+}
+
+// placeholders returns a parenthesized list of n placeholders, starting after the given offset.
+func placeholders(offset, n int) string {
+	ps := make([]string, n)
+	for i := range ps {
+		ps[i] = "$" + strconv.Itoa(offset+i+1)
+	}
+	return "(" + strings.Join(ps, ", ") + ")"
+}
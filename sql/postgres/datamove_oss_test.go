@@ -0,0 +1,131 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+//go:build !ent
+
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriver_MoveData(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("130000")
+	d, err := Open(db)
+	require.NoError(t, err)
+	drv := d.(*Driver)
+
+	orders := schema.NewTable("orders").
+		SetSchema(schema.New("public")).
+		AddColumns(
+			schema.NewIntColumn("id", TypeBigInt),
+			schema.NewIntColumn("amount", TypeInteger),
+			schema.NewNullColumn("amount_new").SetType(&schema.DecimalType{T: TypeNumeric, Precision: 10, Scale: 2}),
+		)
+	orders.SetPrimaryKey(schema.NewPrimaryKey(orders.Columns[0]))
+
+	// Backfill in place.
+	m.ExpectQuery(sqltest.Escape(`SELECT "id" FROM "public"."orders" ORDER BY "id" OFFSET 1 LIMIT 1`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	m.ExpectExec(sqltest.Escape(`UPDATE "public"."orders" SET "amount_new" = "amount" WHERE ("id") <= ($1)`)).
+		WithArgs(2).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	m.ExpectQuery(sqltest.Escape(`SELECT "id" FROM "public"."orders" WHERE ("id") > ($1) ORDER BY "id" OFFSET 1 LIMIT 1`)).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+	m.ExpectExec(sqltest.Escape(`UPDATE "public"."orders" SET "amount_new" = "amount" WHERE ("id") > ($1) AND ("id") <= ($2)`)).
+		WithArgs(2, 4).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	m.ExpectQuery(sqltest.Escape(`SELECT "id" FROM "public"."orders" WHERE ("id") > ($1) ORDER BY "id" OFFSET 1 LIMIT 1`)).
+		WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	m.ExpectExec(sqltest.Escape(`UPDATE "public"."orders" SET "amount_new" = "amount" WHERE ("id") > ($1)`)).
+		WithArgs(4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	var markers [][]any
+	move := BackfillMove(&migrate.Backfill{T: orders, From: orders.Columns[1], To: orders.Columns[2]})
+	move.BatchSize = 2
+	move.Progress = func(p *MoveProgress) {
+		markers = append(markers, p.Marker)
+	}
+	p, err := drv.MoveData(context.Background(), move)
+	require.NoError(t, err)
+	require.Equal(t, &MoveProgress{Batches: 3, Rows: 5, Marker: []any{int64(4)}, Done: true}, p)
+	require.Equal(t, [][]any{{int64(2)}, {int64(4)}, {int64(4)}}, markers)
+
+	// Copy to another table, resumed from a marker.
+	archive := schema.NewTable("orders_archive").
+		SetSchema(orders.Schema).
+		AddColumns(schema.NewIntColumn("id", TypeBigInt), schema.NewDecimalColumn("amount", TypeNumeric))
+	m.ExpectQuery(sqltest.Escape(`SELECT "id" FROM "public"."orders" WHERE ("id") > ($1) ORDER BY "id" OFFSET 9999 LIMIT 1`)).
+		WithArgs(100).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	m.ExpectExec(sqltest.Escape(`INSERT INTO "public"."orders_archive" ("id", "amount") SELECT "id", amount::numeric(10,2) FROM "public"."orders" WHERE ("id") > ($1)`)).
+		WithArgs(100).
+		WillReturnResult(sqlmock.NewResult(0, 10))
+	move = &DataMove{
+		Source: orders,
+		Target: archive,
+		Columns: []*MoveColumn{
+			{From: orders.Columns[0], To: archive.Columns[0]},
+			{Expr: "amount::numeric(10,2)", To: archive.Columns[1]},
+		},
+		Resume: []any{100},
+	}
+	p, err = drv.MoveData(context.Background(), move)
+	require.NoError(t, err)
+	require.Equal(t, &MoveProgress{Batches: 1, Rows: 10, Marker: []any{100}, Done: true}, p)
+
+	// Conflicts are skipped only if requested.
+	m.ExpectQuery(sqltest.Escape(`SELECT "id" FROM "public"."orders" WHERE ("id") > ($1) ORDER BY "id" OFFSET 9999 LIMIT 1`)).
+		WithArgs(100).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	m.ExpectExec(sqltest.Escape(`INSERT INTO "public"."orders_archive" ("id", "amount") SELECT "id", amount::numeric(10,2) FROM "public"."orders" WHERE ("id") > ($1) ON CONFLICT DO NOTHING`)).
+		WithArgs(100).
+		WillReturnResult(sqlmock.NewResult(0, 8))
+	move.SkipConflicts = true
+	p, err = drv.MoveData(context.Background(), move)
+	require.NoError(t, err)
+	require.Equal(t, &MoveProgress{Batches: 1, Rows: 8, Marker: []any{100}, Done: true}, p)
+
+	// Keys that are scanned as bytes (e.g., uuid) are passed back as text.
+	events := schema.NewTable("events").
+		SetSchema(orders.Schema).
+		AddColumns(
+			schema.NewColumn("id").SetType(&UUIDType{T: TypeUUID}),
+			schema.NewStringColumn("name", "text"),
+			schema.NewStringColumn("title", "text"),
+		)
+	events.SetPrimaryKey(schema.NewPrimaryKey(events.Columns[0]))
+	m.ExpectQuery(sqltest.Escape(`SELECT "id" FROM "public"."events" ORDER BY "id" OFFSET 0 LIMIT 1`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow([]byte("5f0e1d2c-0000-4000-8000-000000000000")))
+	m.ExpectExec(sqltest.Escape(`UPDATE "public"."events" SET "title" = "name" WHERE ("id") <= ($1)`)).
+		WithArgs("5f0e1d2c-0000-4000-8000-000000000000").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	m.ExpectQuery(sqltest.Escape(`SELECT "id" FROM "public"."events" WHERE ("id") > ($1) ORDER BY "id" OFFSET 0 LIMIT 1`)).
+		WithArgs("5f0e1d2c-0000-4000-8000-000000000000").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	m.ExpectExec(sqltest.Escape(`UPDATE "public"."events" SET "title" = "name" WHERE ("id") > ($1)`)).
+		WithArgs("5f0e1d2c-0000-4000-8000-000000000000").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	move = BackfillMove(&migrate.Backfill{T: events, From: events.Columns[1], To: events.Columns[2]})
+	move.BatchSize = 1
+	p, err = drv.MoveData(context.Background(), move)
+	require.NoError(t, err)
+	require.Equal(t, []any{"5f0e1d2c-0000-4000-8000-000000000000"}, p.Marker)
+	require.NoError(t, m.ExpectationsWereMet())
+
+	_, err = drv.MoveData(context.Background(), &DataMove{Source: archive, Columns: move.Columns})
+	require.EqualError(t, err, `postgres: moving data of table "orders_archive" requires a primary key`)
+}
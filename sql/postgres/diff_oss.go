@@ -11,6 +11,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"unicode"
@@ -30,9 +31,12 @@ type diff struct{ *conn }
 
 // SupportChange reports if the change is supported by the differ.
 func (*diff) SupportChange(c schema.Change) bool {
-	switch c.(type) {
+	switch c := c.(type) {
 	case *schema.RenameConstraint:
 		return false
+	case *schema.ModifyColumn:
+		// PostgreSQL does not support changing the column position.
+		return !c.Change.Is(schema.ChangePosition)
 	}
 	return true
 }
@@ -54,7 +58,9 @@ func (d *diff) SchemaAttrDiff(from, to *schema.Schema) []schema.Change {
 	if change := sqlx.CommentDiff(fromA, toA); change != nil {
 		changes = append(changes, change)
 	}
-	return changes
+	return append(changes, grantsDiff(schemaGrants(from.Attrs), schemaGrants(to.Attrs), func(g *ColumnGrant) schema.Attr {
+		return (*SchemaGrant)(g)
+	})...)
 }
 
 func skipDefaultComment(s *schema.Schema, public string) []schema.Attr {
@@ -74,16 +80,233 @@ func (d *diff) TableAttrDiff(from, to *schema.Table, opts *schema.DiffOptions) (
 	if err := d.partitionChanged(from, to); err != nil {
 		return nil, err
 	}
+	if change := d.accessMethodChange(from, to); change != nil {
+		changes = append(changes, change)
+	}
+	if change := storageParamsChange(from.Attrs, to.Attrs); change != nil {
+		changes = append(changes, change)
+	}
+	if change := tablespaceChange(from.Attrs, to.Attrs); change != nil {
+		changes = append(changes, change)
+	}
+	if change := unloggedChange(from.Attrs, to.Attrs); change != nil {
+		changes = append(changes, change)
+	}
+	changes = append(changes, inheritsDiff(from, to)...)
 	change, err := d.tableAttrDiff(from, to)
 	if err != nil {
 		return nil, err
 	}
 	changes = append(changes, change...)
-	return append(changes, sqlx.CheckDiffMode(from, to, opts.Mode, func(c1, c2 *schema.Check) bool {
+	changes = append(changes, statsDiff(from, to)...)
+	changes = append(changes, rulesDiff(from, to)...)
+	changes = append(changes, grantsDiff(tableGrants(from.Attrs), tableGrants(to.Attrs), func(g *ColumnGrant) schema.Attr {
+		return (*TableGrant)(g)
+	})...)
+	return append(changes, sqlx.CheckDiffMode(from, to, opts.Mode, exprNormalizer, func(c1, c2 *schema.Check) bool {
 		return sqlx.Has(c1.Attrs, &NoInherit{}) == sqlx.Has(c2.Attrs, &NoInherit{})
 	})...), nil
 }
 
+// accessMethodChange returns the change for migrating the access method of one table to
+// the other, if it was changed. A table without an access method uses the default one.
+func (d *diff) accessMethodChange(from, to *schema.Table) schema.Change {
+	fromA, toA := &AccessMethod{V: d.defaultAccessMethod()}, &AccessMethod{V: d.defaultAccessMethod()}
+	sqlx.Has(from.Attrs, fromA)
+	sqlx.Has(to.Attrs, toA)
+	if fromA.V == toA.V {
+		return nil
+	}
+	return &schema.ModifyAttr{From: fromA, To: toA}
+}
+
+// storageParamsChange returns the change for migrating the storage parameters of
+// one relation to the other, if they were changed. The order of the parameters is
+// ignored, and relations without storage parameters use an empty list.
+func storageParamsChange(from, to []schema.Attr) schema.Change {
+	fromP, toP := &StorageParams{}, &StorageParams{}
+	sqlx.Has(from, fromP)
+	sqlx.Has(to, toP)
+	if storageParamsEqual(fromP, toP) {
+		return nil
+	}
+	return &schema.ModifyAttr{From: fromP, To: toP}
+}
+
+// tablespaceChange returns the change for migrating the tablespace of one relation
+// to the other, if it was changed. A relation without a tablespace uses the default.
+func tablespaceChange(from, to []schema.Attr) schema.Change {
+	fromT, toT := tablespace(from), tablespace(to)
+	if fromT == toT {
+		return nil
+	}
+	return &schema.ModifyAttr{From: &Tablespace{V: fromT}, To: &Tablespace{V: toT}}
+}
+
+// unloggedChange returns the change for migrating the persistence
+// of one table to the other (LOGGED/UNLOGGED), if it was changed.
+func unloggedChange(from, to []schema.Attr) schema.Change {
+	switch fromU, toU := sqlx.Has(from, &Unlogged{}), sqlx.Has(to, &Unlogged{}); {
+	case !fromU && toU:
+		return &schema.AddAttr{A: &Unlogged{}}
+	case fromU && !toU:
+		return &schema.DropAttr{A: &Unlogged{}}
+	}
+	return nil
+}
+
+// tablespace returns the tablespace of the given attributes,
+// or an empty string if the default tablespace is used.
+func tablespace(attrs []schema.Attr) string {
+	var t Tablespace
+	if !sqlx.Has(attrs, &t) || t.V == defaultTablespace {
+		return ""
+	}
+	return t.V
+}
+
+// storageParamsEqual reports if the two lists hold the same storage parameters.
+func storageParamsEqual(p1, p2 *StorageParams) bool {
+	if len(p1.V) != len(p2.V) {
+		return false
+	}
+	for _, p := range p1.V {
+		if v, ok := p2.param(p.Name); !ok || !strings.EqualFold(v, p.Value) {
+			return false
+		}
+	}
+	return true
+}
+
+// inheritsDiff returns the changes for migrating the parent tables of one table to the other.
+// Each added or dropped parent is reported as a separate AddAttr or DropAttr change.
+func inheritsDiff(from, to *schema.Table) []schema.Change {
+	var (
+		changes    []schema.Change
+		fromP, toP = tableInherits(from), tableInherits(to)
+		fromN, toN = make([]string, len(fromP)), make([]string, len(toP))
+	)
+	for i, p := range fromP {
+		fromN[i] = parentName(from, p)
+	}
+	for i, p := range toP {
+		toN[i] = parentName(to, p)
+	}
+	for i, p := range fromP {
+		if !slices.Contains(toN, fromN[i]) {
+			changes = append(changes, &schema.DropAttr{A: &Inherits{T: []*schema.Table{p}}})
+		}
+	}
+	for i, p := range toP {
+		if !slices.Contains(fromN, toN[i]) {
+			changes = append(changes, &schema.AddAttr{A: &Inherits{T: []*schema.Table{p}}})
+		}
+	}
+	return changes
+}
+
+// tableInherits returns the parent tables of the given table, if any.
+func tableInherits(t *schema.Table) []*schema.Table {
+	var in Inherits
+	if sqlx.Has(t.Attrs, &in) {
+		return in.T
+	}
+	return nil
+}
+
+// parentName returns the name of the parent table, qualified
+// with its schema name if it resides in another schema.
+func parentName(child, parent *schema.Table) string {
+	if parent.Schema == nil || child.Schema != nil && parent.Schema.Name == child.Schema.Name {
+		return parent.Name
+	}
+	return parent.Schema.Name + "." + parent.Name
+}
+
+// statsDiff returns the changes for migrating the extended statistics of one table to the other.
+func statsDiff(from, to *schema.Table) []schema.Change {
+	var (
+		changes       []schema.Change
+		fromSt, toSt  = tableStats(from), tableStats(to)
+		statsColNames = func(s *Statistics) []string {
+			names := make([]string, len(s.Columns))
+			for i, c := range s.Columns {
+				names[i] = c.Name
+			}
+			return names
+		}
+	)
+	for _, s1 := range fromSt {
+		i := slices.IndexFunc(toSt, func(s2 *Statistics) bool { return s1.Name == s2.Name })
+		switch {
+		case i == -1:
+			changes = append(changes, &schema.DropAttr{A: s1})
+		case !slices.Equal(StatsKinds(s1.Kinds), StatsKinds(toSt[i].Kinds)) || !slices.Equal(statsColNames(s1), statsColNames(toSt[i])):
+			changes = append(changes, &schema.ModifyAttr{From: s1, To: toSt[i]})
+		}
+	}
+	for _, s2 := range toSt {
+		if !slices.ContainsFunc(fromSt, func(s1 *Statistics) bool { return s1.Name == s2.Name }) {
+			changes = append(changes, &schema.AddAttr{A: s2})
+		}
+	}
+	return changes
+}
+
+// tableStats returns the extended statistics objects defined on the table.
+func tableStats(t *schema.Table) (stats []*Statistics) {
+	for _, a := range t.Attrs {
+		if s, ok := a.(*Statistics); ok {
+			stats = append(stats, s)
+		}
+	}
+	return stats
+}
+
+// rulesDiff returns the changes for migrating the rewrite rules of one table to the other.
+func rulesDiff(from, to *schema.Table) []schema.Change {
+	var (
+		changes    []schema.Change
+		fromR, toR = tableRules(from), tableRules(to)
+	)
+	for _, r1 := range fromR {
+		i := slices.IndexFunc(toR, func(r2 *Rule) bool { return r1.Name == r2.Name })
+		switch {
+		case i == -1:
+			changes = append(changes, &schema.DropAttr{A: r1})
+		case !ruleEqual(r1, toR[i]):
+			changes = append(changes, &schema.ModifyAttr{From: r1, To: toR[i]})
+		}
+	}
+	for _, r2 := range toR {
+		if !slices.ContainsFunc(fromR, func(r1 *Rule) bool { return r1.Name == r2.Name }) {
+			changes = append(changes, &schema.AddAttr{A: r2})
+		}
+	}
+	return changes
+}
+
+// ruleEqual reports if the two rules have the same definition.
+func ruleEqual(r1, r2 *Rule) bool {
+	return strings.EqualFold(r1.Event, r2.Event) && r1.Instead == r2.Instead &&
+		exprNormalizer.Equal(r1.Where, r2.Where) && exprNormalizer.Equal(ruleAction(r1), ruleAction(r2))
+}
+
+// ruleAction returns the action of the rule without its trailing semicolon.
+func ruleAction(r *Rule) string {
+	return strings.TrimSuffix(strings.TrimSpace(r.Action), ";")
+}
+
+// tableRules returns the rewrite rules defined on the table.
+func tableRules(t *schema.Table) (rules []*Rule) {
+	for _, a := range t.Attrs {
+		if r, ok := a.(*Rule); ok {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
 // ColumnChange returns the schema changes (if any) for migrating one column to the other.
 func (d *diff) ColumnChange(_ *schema.Table, from, to *schema.Column, _ *schema.DiffOptions) (schema.Change, error) {
 	change := sqlx.CommentChange(from.Attrs, to.Attrs)
@@ -103,9 +326,14 @@ func (d *diff) ColumnChange(_ *schema.Table, from, to *schema.Column, _ *schema.
 	if changed {
 		change |= schema.ChangeDefault
 	}
-	if identityChanged(from.Attrs, to.Attrs) {
+	if identityChanged(from, to) || grantsChanged(from.Attrs, to.Attrs) || compression(from.Attrs) != compression(to.Attrs) {
 		change |= schema.ChangeAttr
 	}
+	// Collation names are compared as-is, as they are case-sensitive
+	// identifiers. CockroachDB collations are part of the column type.
+	if !d.crdb && fieldCollation(from) != fieldCollation(to) {
+		change |= schema.ChangeCollate
+	}
 	if changed, err = d.generatedChanged(from, to); err != nil {
 		return sqlx.NoChange, err
 	}
@@ -129,7 +357,7 @@ func (d *diff) defaultChanged(from, to *schema.Column) (bool, error) {
 	if ok1 != ok2 {
 		return true, nil
 	}
-	if !ok1 && !ok2 || trimCast(d1) == trimCast(d2) || quote(d1) == quote(d2) {
+	if !ok1 && !ok2 || trimCast(d1) == trimCast(d2) || quote(d1) == quote(d2) || exprNormalizer.Equal(d1, d2) {
 		return false, nil
 	}
 	var (
@@ -153,7 +381,7 @@ func (d *diff) defaultChanged(from, to *schema.Column) (bool, error) {
 func (*diff) generatedChanged(from, to *schema.Column) (bool, error) {
 	var fromX, toX schema.GeneratedExpr
 	switch fromHas, toHas := sqlx.Has(from.Attrs, &fromX), sqlx.Has(to.Attrs, &toX); {
-	case fromHas && toHas && sqlx.MayWrap(fromX.Expr) != sqlx.MayWrap(toX.Expr):
+	case fromHas && toHas && !generatedNormalizer.Equal(fromX.Expr, toX.Expr):
 		return false, fmt.Errorf("changing the generation expression for a column %q is not supported", from.Name)
 	case !fromHas && toHas:
 		return false, fmt.Errorf("changing column %q to generated column is not supported (drop and add is required)", from.Name)
@@ -226,13 +454,21 @@ func (*diff) IndexAttrChanged(from, to []schema.Attr) bool {
 	if uniqueConstChanged(from, to) || excludeConstChanged(from, to) {
 		return true
 	}
+	// Unlike foreign keys, the deferrability of unique and exclude
+	// constraints cannot be altered, and they are recreated instead.
+	if deferrableChanged(from, to) {
+		return true
+	}
 	var p1, p2 IndexPredicate
-	if sqlx.Has(from, &p1) != sqlx.Has(to, &p2) || (p1.P != p2.P && p1.P != sqlx.MayWrap(p2.P)) {
+	if sqlx.Has(from, &p1) != sqlx.Has(to, &p2) || (p1.P != p2.P && !exprNormalizer.Equal(p1.P, p2.P)) {
 		return true
 	}
 	if indexIncludeChanged(from, to) {
 		return true
 	}
+	if storageParamsChange(from, to) != nil || tablespace(from) != tablespace(to) {
+		return true
+	}
 	s1, ok1 := indexStorageParams(from)
 	s2, ok2 := indexStorageParams(to)
 	return ok1 != ok2 || ok1 && *s1 != *s2
@@ -248,8 +484,8 @@ func (*diff) IndexPartAttrChanged(fromI, toI *schema.Index, i int) bool {
 	if p1.NullsFirst != p2.NullsFirst || p1.NullsLast != p2.NullsLast {
 		return true
 	}
-	_, ok1 := excludeConst(from.Attrs)
-	_, ok2 := excludeConst(to.Attrs)
+	_, ok1 := excludeConst(fromI.Attrs)
+	_, ok2 := excludeConst(toI.Attrs)
 	if ok1 && ok2 {
 		// In case the index(es) are EXCLUDE constraint, we compare its operator
 		// (and not its class) because the class is derived from the operator.
@@ -287,8 +523,25 @@ func (*diff) ReferenceChanged(from, to schema.ReferenceOption) bool {
 }
 
 // ForeignKeyAttrChanged reports if any of the foreign-key attributes were changed.
-func (*diff) ForeignKeyAttrChanged(_, _ []schema.Attr) bool {
-	return false
+// A foreign key that was not validated yet is not equal to a validated one.
+func (*diff) ForeignKeyAttrChanged(from, to []schema.Attr) bool {
+	return sqlx.Has(from, &NotValid{}) != sqlx.Has(to, &NotValid{}) || deferrableChanged(from, to) || onDeleteColumnsChanged(from, to)
+}
+
+// onDeleteColumnsChanged reports if the column list of the ON DELETE action was changed.
+func onDeleteColumnsChanged(from, to []schema.Attr) bool {
+	var c1, c2 OnDeleteColumns
+	sqlx.Has(from, &c1)
+	sqlx.Has(to, &c2)
+	return !slices.EqualFunc(c1.Columns, c2.Columns, func(c1, c2 *schema.Column) bool {
+		return c1.Name == c2.Name
+	})
+}
+
+// deferrableChanged reports if the DEFERRABLE clause of a constraint was changed.
+func deferrableChanged(from, to []schema.Attr) bool {
+	var d1, d2 Deferrable
+	return sqlx.Has(from, &d1) != sqlx.Has(to, &d2) || d1.InitiallyDeferred != d2.InitiallyDeferred
 }
 
 // DiffOptions defines PostgreSQL specific schema diffing process.
@@ -372,6 +625,10 @@ func typeChanged(from, to *schema.Column, ns string) (bool, error) {
 		toT := toT.(*CompositeType)
 		changed = toT.T != fromT.T ||
 			(toT.Schema != nil && fromT.Schema != nil && toT.Schema.Name != fromT.Schema.Name)
+	case *UserRangeType:
+		toT := toT.(*UserRangeType)
+		changed = toT.T != fromT.T ||
+			(toT.Schema != nil && fromT.Schema != nil && toT.Schema.Name != fromT.Schema.Name)
 	case *DomainType:
 		toT := toT.(*DomainType)
 		changed = toT.T != fromT.T ||
@@ -456,13 +713,41 @@ const (
 )
 
 // identityChanged reports if one of the identity attributes was changed.
-func identityChanged(from, to []schema.Attr) bool {
-	i1, ok1 := identity(from)
-	i2, ok2 := identity(to)
+func identityChanged(from, to *schema.Column) bool {
+	i1, ok1 := identity(from.Attrs)
+	i2, ok2 := identity(to.Attrs)
 	if !ok1 && !ok2 || ok1 != ok2 {
 		return ok1 != ok2
 	}
-	return i1.Generation != i2.Generation || i1.Sequence.Start != i2.Sequence.Start || i1.Sequence.Increment != i2.Sequence.Increment
+	min1, max1 := identityBounds(from, i1)
+	min2, max2 := identityBounds(to, i2)
+	return i1.Generation != i2.Generation || i1.Sequence.Start != i2.Sequence.Start || i1.Sequence.Increment != i2.Sequence.Increment ||
+		min1 != min2 || max1 != max2 || seqCache(i1.Sequence) != seqCache(i2.Sequence) || i1.Sequence.Cycle != i2.Sequence.Cycle
+}
+
+// compression returns the compression method of the column attributes,
+// or an empty string if the column uses the default compression method.
+func compression(attrs []schema.Attr) string {
+	var c Compression
+	if !sqlx.Has(attrs, &c) || strings.EqualFold(c.V, "default") {
+		return ""
+	}
+	return strings.ToLower(c.V)
+}
+
+// identityBounds returns the minimum and maximum values of the identity sequence.
+// Unlike standalone sequences, the type of the sequence is the column type.
+func identityBounds(c *schema.Column, i *Identity) (int64, int64) {
+	seq := &Sequence{Increment: i.Sequence.Increment, Min: i.Sequence.Min, Max: i.Sequence.Max}
+	if c.Type != nil {
+		seq.Type = c.Type.Type
+	}
+	return seqBounds(seq)
+}
+
+// defaultIdentity returns an identity with the default options.
+func defaultIdentity() *Identity {
+	return &Identity{Generation: defaultIdentityGen, Sequence: &Sequence{Start: defaultSeqStart, Increment: defaultSeqIncrement}}
 }
 
 func identity(attrs []schema.Attr) (*Identity, bool) {
@@ -486,6 +771,112 @@ func identity(attrs []schema.Attr) (*Identity, bool) {
 	return i, true
 }
 
+// columnGrants returns the column privileges, keyed by their grantees. The
+// privileges of each grant are normalized to be upper-cased and sorted.
+func columnGrants(attrs []schema.Attr) map[string]*ColumnGrant {
+	return grantsOf(attrs, func(a schema.Attr) (*ColumnGrant, bool) {
+		g, ok := a.(*ColumnGrant)
+		return g, ok
+	})
+}
+
+// tableGrants returns the table privileges, keyed by their grantees.
+// Grants are returned as ColumnGrant, as they share the same structure.
+func tableGrants(attrs []schema.Attr) map[string]*ColumnGrant {
+	return grantsOf(attrs, func(a schema.Attr) (*ColumnGrant, bool) {
+		g, ok := a.(*TableGrant)
+		return (*ColumnGrant)(g), ok
+	})
+}
+
+// schemaGrants returns the schema privileges, keyed by their grantees.
+// Grants are returned as ColumnGrant, as they share the same structure.
+func schemaGrants(attrs []schema.Attr) map[string]*ColumnGrant {
+	return grantsOf(attrs, func(a schema.Attr) (*ColumnGrant, bool) {
+		g, ok := a.(*SchemaGrant)
+		return (*ColumnGrant)(g), ok
+	})
+}
+
+// grantsOf returns the grants extracted from the attributes by the given function,
+// keyed by their grantees. The privileges of each grant are upper-cased and sorted.
+func grantsOf(attrs []schema.Attr, grant func(schema.Attr) (*ColumnGrant, bool)) map[string]*ColumnGrant {
+	grants := make(map[string]*ColumnGrant)
+	for _, a := range attrs {
+		g, ok := grant(a)
+		if !ok {
+			continue
+		}
+		n, ok := grants[g.Grantee]
+		if !ok {
+			n = &ColumnGrant{Grantee: g.Grantee}
+			grants[g.Grantee] = n
+		}
+		n.Grantable = n.Grantable || g.Grantable
+		for _, p := range g.Privileges {
+			if p = strings.ToUpper(p); !slices.Contains(n.Privileges, p) {
+				n.Privileges = append(n.Privileges, p)
+			}
+		}
+		slices.Sort(n.Privileges)
+	}
+	return grants
+}
+
+// grantsChanged reports if the column privileges were changed.
+func grantsChanged(from, to []schema.Attr) bool {
+	g1, g2 := columnGrants(from), columnGrants(to)
+	if len(g1) != len(g2) {
+		return true
+	}
+	for k, v1 := range g1 {
+		v2, ok := g2[k]
+		if !ok || !grantEqual(v1, v2) {
+			return true
+		}
+	}
+	return false
+}
+
+// grantEqual reports if the two normalized grants are equal.
+func grantEqual(g1, g2 *ColumnGrant) bool {
+	return g1.Grantable == g2.Grantable && slices.Equal(g1.Privileges, g2.Privileges)
+}
+
+// grantees returns the sorted names of the grantees in both grant sets.
+func grantees(g1, g2 map[string]*ColumnGrant) []string {
+	names := make([]string, 0, len(g1)+len(g2))
+	for n := range g1 {
+		names = append(names, n)
+	}
+	for n := range g2 {
+		if _, ok := g1[n]; !ok {
+			names = append(names, n)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// grantsDiff returns the changes for migrating the grants of one object to the other,
+// one per grantee. The attr function converts the normalized grants back to attributes.
+func grantsDiff(g1, g2 map[string]*ColumnGrant, attr func(*ColumnGrant) schema.Attr) []schema.Change {
+	var changes []schema.Change
+	for _, n := range grantees(g1, g2) {
+		f, ok1 := g1[n]
+		t, ok2 := g2[n]
+		switch {
+		case !ok1:
+			changes = append(changes, &schema.AddAttr{A: attr(t)})
+		case !ok2:
+			changes = append(changes, &schema.DropAttr{A: attr(f)})
+		case !grantEqual(f, t):
+			changes = append(changes, &schema.ModifyAttr{From: attr(f), To: attr(t)})
+		}
+	}
+	return changes
+}
+
 // formatPartition returns the string representation of the
 // partition key according to the PostgreSQL format/grammar.
 func formatPartition(p Partition) (string, error) {
@@ -570,6 +961,222 @@ func excludeConst(attrs []schema.Attr) (*Constraint, bool) {
 	return nil, false
 }
 
+// exprNormalizer normalizes expressions before they are compared. PostgreSQL
+// rewrites expressions when they are stored, and adds parentheses and casts to
+// text-like types. e.g., "(status)::text = 'active'::text".
+var exprNormalizer = &sqlx.ExprNormalizer{
+	IdentQuotes:   `"`,
+	CaseSensitive: true,
+	TrimCast: func(t string) bool {
+		switch t {
+		case TypeText, TypeCharVar, TypeVarChar, TypeBPChar, TypeCharacter, "unknown":
+			return true
+		}
+		return false
+	},
+}
+
+// generatedNormalizer normalizes generation expressions before they are compared. In addition
+// to text-like types, PostgreSQL adds casts to numeric types when the operands of an arithmetic
+// operation differ. e.g., "(price * (quantity)::numeric)". Since generation expressions cannot
+// be changed in place, reporting such a difference fails the migration.
+var generatedNormalizer = &sqlx.ExprNormalizer{
+	IdentQuotes:   exprNormalizer.IdentQuotes,
+	CaseSensitive: exprNormalizer.CaseSensitive,
+	TrimCast: func(t string) bool {
+		switch t {
+		case TypeSmallInt, TypeInteger, TypeInt, TypeBigInt, TypeInt8, TypeReal, TypeDouble, TypeFloat8, TypeNumeric, TypeDecimal:
+			return true
+		}
+		return exprNormalizer.TrimCast(t)
+	},
+}
+
+// ExprEqual implements the sqlx.ExprComparer interface.
+func (*diff) ExprEqual(x1, x2 string) bool {
+	return exprNormalizer.Equal(x1, x2)
+}
+
+// ProcFuncsDiff implements the sqlx.ProcFuncsDiffer interface. Functions and procedures are
+// matched by their names and the types of their input arguments, as Postgres allows overloading
+// them. Hence, changing the input types of a function is planned as a drop and a creation.
+func (d *diff) ProcFuncsDiff(from, to *schema.Schema, opts *schema.DiffOptions) ([]schema.Change, error) {
+	var changes schema.Changes
+	for _, f1 := range from.Funcs {
+		i := slices.IndexFunc(to.Funcs, func(f2 *schema.Func) bool { return sameFuncSig(f1.Name, f1.Args, f2.Name, f2.Args) })
+		if i == -1 {
+			changes = opts.AddOrSkip(changes, &schema.DropFunc{F: f1})
+			continue
+		}
+		f2 := to.Funcs[i]
+		changed, err := d.funcChanged(f1, f2)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			changes = opts.AddOrSkip(changes, &schema.ModifyFunc{From: f1, To: f2})
+		}
+	}
+	for _, f2 := range to.Funcs {
+		if !slices.ContainsFunc(from.Funcs, func(f1 *schema.Func) bool { return sameFuncSig(f1.Name, f1.Args, f2.Name, f2.Args) }) {
+			changes = opts.AddOrSkip(changes, &schema.AddFunc{F: f2})
+		}
+	}
+	for _, p1 := range from.Procs {
+		i := slices.IndexFunc(to.Procs, func(p2 *schema.Proc) bool { return sameFuncSig(p1.Name, p1.Args, p2.Name, p2.Args) })
+		if i == -1 {
+			changes = opts.AddOrSkip(changes, &schema.DropProc{P: p1})
+			continue
+		}
+		p2 := to.Procs[i]
+		changed, err := d.procChanged(p1, p2)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			changes = opts.AddOrSkip(changes, &schema.ModifyProc{From: p1, To: p2})
+		}
+	}
+	for _, p2 := range to.Procs {
+		if !slices.ContainsFunc(from.Procs, func(p1 *schema.Proc) bool { return sameFuncSig(p1.Name, p1.Args, p2.Name, p2.Args) }) {
+			changes = opts.AddOrSkip(changes, &schema.AddProc{P: p2})
+		}
+	}
+	return changes, nil
+}
+
+// funcChanged reports if the function definition was changed.
+func (d *diff) funcChanged(from, to *schema.Func) (bool, error) {
+	if !sameType(from.Ret, to.Ret) {
+		return true, nil
+	}
+	return d.routineChanged(from.Args, to.Args, from.Lang, to.Lang, from.Body, to.Body, from.Attrs, to.Attrs)
+}
+
+// procChanged reports if the procedure definition was changed.
+func (d *diff) procChanged(from, to *schema.Proc) (bool, error) {
+	return d.routineChanged(from.Args, to.Args, from.Lang, to.Lang, from.Body, to.Body, from.Attrs, to.Attrs)
+}
+
+// routineChanged reports if the definition of a function or a procedure was changed.
+func (d *diff) routineChanged(fromA, toA []*schema.FuncArg, fromL, toL, fromB, toB string, fromX, toX []schema.Attr) (bool, error) {
+	if !strings.EqualFold(fromL, toL) || !pretty.Equal(fromB, toB) ||
+		funcVolatility(fromX) != funcVolatility(toX) || funcComment(fromX) != funcComment(toX) || len(fromA) != len(toA) {
+		return true, nil
+	}
+	for i := range fromA {
+		a1, a2 := fromA[i], toA[i]
+		if a1.Name != a2.Name || funcArgMode(a1) != funcArgMode(a2) || !sameType(a1.Type, a2.Type) {
+			return true, nil
+		}
+		changed, err := d.defaultChanged(
+			&schema.Column{Type: &schema.ColumnType{Type: a1.Type}, Default: a1.Default},
+			&schema.Column{Type: &schema.ColumnType{Type: a2.Type}, Default: a2.Default},
+		)
+		if err != nil || changed {
+			return changed, err
+		}
+	}
+	return false, nil
+}
+
+// sameFuncSig reports if the two functions (or procedures) have the same signature,
+// i.e., the same name and the same types of input arguments.
+func sameFuncSig(n1 string, a1 []*schema.FuncArg, n2 string, a2 []*schema.FuncArg) bool {
+	return n1 == n2 && slices.EqualFunc(funcInputArgs(a1), funcInputArgs(a2), func(a1, a2 *schema.FuncArg) bool {
+		return sameType(a1.Type, a2.Type)
+	})
+}
+
+// funcInputArgs returns the arguments that identify the function (i.e., all except OUT).
+func funcInputArgs(args []*schema.FuncArg) []*schema.FuncArg {
+	in := make([]*schema.FuncArg, 0, len(args))
+	for _, a := range args {
+		if a.Mode != schema.FuncArgModeOut {
+			in = append(in, a)
+		}
+	}
+	return in
+}
+
+// funcArgMode returns the mode of the argument. IN is the default.
+func funcArgMode(a *schema.FuncArg) schema.FuncArgMode {
+	if a.Mode == "" {
+		return schema.FuncArgModeIn
+	}
+	return a.Mode
+}
+
+// funcVolatility returns the volatility of a function. VOLATILE is the default.
+func funcVolatility(attrs []schema.Attr) string {
+	if v := (FuncVolatility{}); sqlx.Has(attrs, &v) && v.V != "" {
+		return strings.ToUpper(v.V)
+	}
+	return VolatilityVolatile
+}
+
+// funcComment returns the comment of a function or a procedure, if exists.
+func funcComment(attrs []schema.Attr) string {
+	var c schema.Comment
+	sqlx.Has(attrs, &c)
+	return c.Text
+}
+
+// sameType reports if the two types are formatted the same.
+func sameType(t1, t2 schema.Type) bool {
+	if t1 == nil || t2 == nil {
+		return t1 == t2
+	}
+	f1, err1 := FormatType(t1)
+	f2, err2 := FormatType(t2)
+	return err1 == nil && err2 == nil && f1 == f2
+}
+
+// TriggerDiff implements the sqlx.TriggerDiffer interface. A change to the trigger
+// definition is planned as a modification (i.e., a replacement) of the trigger, while
+// a change to its firing state only is planned as an attribute modification.
+func (*diff) TriggerDiff(from, to *schema.Trigger) ([]schema.Change, error) {
+	fromS, toS := &TriggerState{}, &TriggerState{}
+	sqlx.Has(from.Attrs, fromS)
+	sqlx.Has(to.Attrs, toS)
+	if !triggerDefEqual(from, to) {
+		return []schema.Change{&schema.ModifyTrigger{From: from, To: to}}, nil
+	}
+	if fromS.V != toS.V {
+		return []schema.Change{&schema.ModifyTrigger{
+			From:    from,
+			To:      to,
+			Changes: []schema.Change{&schema.ModifyAttr{From: fromS, To: toS}},
+		}}, nil
+	}
+	return nil, nil
+}
+
+// triggerDefEqual reports if the definitions of the two triggers are equal.
+func triggerDefEqual(t1, t2 *schema.Trigger) bool {
+	if t1.ActionTime != t2.ActionTime || t1.For != t2.For || len(t1.Events) != len(t2.Events) {
+		return false
+	}
+	for i := range t1.Events {
+		e1, e2 := t1.Events[i], t2.Events[i]
+		if e1.Name != e2.Name || len(e1.Columns) != len(e2.Columns) {
+			return false
+		}
+		for j := range e1.Columns {
+			if e1.Columns[j].Name != e2.Columns[j].Name {
+				return false
+			}
+		}
+	}
+	w1, w2 := &TriggerWhen{}, &TriggerWhen{}
+	sqlx.Has(t1.Attrs, w1)
+	sqlx.Has(t2.Attrs, w2)
+	if w1.X != w2.X && !exprNormalizer.Equal(w1.X, w2.X) {
+		return false
+	}
+	return strings.Join(strings.Fields(t1.Body), " ") == strings.Join(strings.Fields(t2.Body), " ")
+}
+
 func trimCast(s string) string {
 	i := strings.LastIndex(s, "::")
 	if i == -1 {
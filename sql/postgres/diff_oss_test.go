@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"ariga.io/atlas/schemahcl"
+	"ariga.io/atlas/sql/internal/sqlx"
 	"ariga.io/atlas/sql/schema"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -60,6 +61,73 @@ func TestDiff_TableDiff(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "identity default options",
+			from: schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "integer").AddAttrs(&Identity{Sequence: &Sequence{Start: 1, Min: sqlx.P[int64](1), Max: sqlx.P[int64](2147483647), Cache: 1}})),
+			to:   schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "integer").AddAttrs(&Identity{})),
+		},
+		func() testcase {
+			var (
+				from = schema.NewIntColumn("id", "integer").AddAttrs(&Identity{Sequence: &Sequence{Start: 1, Cache: 10}})
+				to   = schema.NewIntColumn("id", "integer").AddAttrs(&Identity{Sequence: &Sequence{Start: 1, Cache: 10, Cycle: true}})
+			)
+			return testcase{
+				name:        "identity cycle",
+				from:        schema.NewTable("users").AddColumns(from),
+				to:          schema.NewTable("users").AddColumns(to),
+				wantChanges: []schema.Change{&schema.ModifyColumn{From: from, To: to, Change: schema.ChangeAttr}},
+			}
+		}(),
+		func() testcase {
+			var (
+				a    = schema.NewIntColumn("a", "int")
+				b    = schema.NewIntColumn("b", "int")
+				from = schema.NewTable("users").AddColumns(a, b).AddAttrs(
+					&Statistics{Name: "same", Kinds: []string{StatsNDistinct, StatsDependencies, StatsMCV}, Columns: []*schema.Column{a, b}},
+					&Statistics{Name: "dropped", Columns: []*schema.Column{a}},
+					&Statistics{Name: "changed", Kinds: []string{StatsMCV}, Columns: []*schema.Column{a, b}},
+				)
+				to = schema.NewTable("users").AddColumns(a, b).AddAttrs(
+					&Statistics{Name: "same", Columns: []*schema.Column{schema.NewIntColumn("a", "int"), schema.NewIntColumn("b", "int")}},
+					&Statistics{Name: "changed", Kinds: []string{StatsMCV}, Columns: []*schema.Column{b, a}},
+					&Statistics{Name: "added", Columns: []*schema.Column{b}},
+				)
+			)
+			return testcase{
+				name: "statistics",
+				from: from,
+				to:   to,
+				wantChanges: []schema.Change{
+					&schema.DropAttr{A: from.Attrs[1]},
+					&schema.ModifyAttr{From: from.Attrs[2], To: to.Attrs[1]},
+					&schema.AddAttr{A: to.Attrs[2]},
+				},
+			}
+		}(),
+		func() testcase {
+			var (
+				from = schema.NewTable("users").AddAttrs(
+					&Rule{Name: "same", Event: RuleEventDelete, Instead: true, Where: "(old.id > 0)", Action: "NOTHING"},
+					&Rule{Name: "dropped", Event: RuleEventInsert, Action: "NOTHING"},
+					&Rule{Name: "changed", Event: RuleEventUpdate, Action: "NOTHING"},
+				)
+				to = schema.NewTable("users").AddAttrs(
+					&Rule{Name: "same", Event: "delete", Instead: true, Where: "old.id > 0", Action: "NOTHING;"},
+					&Rule{Name: "changed", Event: RuleEventUpdate, Instead: true, Action: "NOTHING"},
+					&Rule{Name: "added", Event: RuleEventInsert, Action: "NOTHING"},
+				)
+			)
+			return testcase{
+				name: "rules",
+				from: from,
+				to:   to,
+				wantChanges: []schema.Change{
+					&schema.DropAttr{A: from.Attrs[1]},
+					&schema.ModifyAttr{From: from.Attrs[2], To: to.Attrs[1]},
+					&schema.AddAttr{A: to.Attrs[2]},
+				},
+			}
+		}(),
 		{
 			name: "drop partition key",
 			from: schema.NewTable("logs").
@@ -128,6 +196,25 @@ func TestDiff_TableDiff(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "normalized checks",
+			from: &schema.Table{Name: "t1", Attrs: []schema.Attr{
+				&schema.Check{Name: "t1_status_check", Expr: "(((status)::text = 'active'::text) AND (c1 > 1))"},
+				&schema.Check{Name: "t1_c2_check", Expr: "(\"C2\" > 0)"},
+			}},
+			to: &schema.Table{Name: "t1", Attrs: []schema.Attr{
+				&schema.Check{Expr: "status = 'active' AND c1>1"},
+				&schema.Check{Expr: "c2 > 0"},
+			}},
+			wantChanges: []schema.Change{
+				&schema.DropCheck{
+					C: &schema.Check{Name: "t1_c2_check", Expr: "(\"C2\" > 0)"},
+				},
+				&schema.AddCheck{
+					C: &schema.Check{Expr: "c2 > 0"},
+				},
+			},
+		},
 		{
 			name: "add comment",
 			from: &schema.Table{Name: "t1", Schema: &schema.Schema{Name: "public"}},
@@ -200,6 +287,25 @@ func TestDiff_TableDiff(t *testing.T) {
 				),
 			wantErr: true,
 		},
+		{
+			name: "equal generation expressions",
+			from: schema.NewTable("t1").
+				SetSchema(schema.New("public")).
+				AddColumns(
+					schema.NewIntColumn("c1", "int").
+						SetGeneratedExpr(&schema.GeneratedExpr{Expr: "(price * (quantity)::numeric)", Type: "STORED"}),
+					schema.NewIntColumn("c2", "int").
+						SetGeneratedExpr(&schema.GeneratedExpr{Expr: "(a + (b * 2))", Type: "STORED"}),
+				),
+			to: schema.NewTable("t1").
+				SetSchema(schema.New("public")).
+				AddColumns(
+					schema.NewIntColumn("c1", "int").
+						SetGeneratedExpr(&schema.GeneratedExpr{Expr: "price * quantity", Type: "STORED"}),
+					schema.NewIntColumn("c2", "int").
+						SetGeneratedExpr(&schema.GeneratedExpr{Expr: "a + b * 2", Type: "STORED"}),
+				),
+		},
 		func() testcase {
 			var (
 				from = &schema.Table{
@@ -471,6 +577,26 @@ func TestDiff_TableDiff(t *testing.T) {
 				},
 			}
 		}(),
+		func() testcase {
+			var (
+				from = schema.NewTable("t1").SetSchema(schema.New("public")).AddColumns(schema.NewIntColumn("id", "int"), schema.NewIntColumn("ref_id", "int"))
+				to   = schema.NewTable("t1").SetSchema(schema.New("public")).AddColumns(schema.NewIntColumn("id", "int"), schema.NewIntColumn("ref_id", "int"))
+			)
+			from.AddForeignKeys(schema.NewForeignKey("ref").AddColumns(from.Columns[1]).SetRefTable(from).AddRefColumns(from.Columns[0]).AddAttrs(&NotValid{}))
+			to.AddForeignKeys(schema.NewForeignKey("ref").AddColumns(to.Columns[1]).SetRefTable(to).AddRefColumns(to.Columns[0]))
+			return testcase{
+				name: "foreign-keys not valid",
+				from: from,
+				to:   to,
+				wantChanges: []schema.Change{
+					&schema.ModifyForeignKey{
+						From:   from.ForeignKeys[0],
+						To:     to.ForeignKeys[0],
+						Change: schema.ChangeAttr,
+					},
+				},
+			}
+		}(),
 	}
 	for _, tt := range tests {
 		db, m, err := sqlmock.New()
@@ -507,6 +633,31 @@ func TestDiff_RealmDiff(t *testing.T) {
 	}, changes)
 }
 
+func TestDiff_CronJobs(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	var (
+		rotate = &CronJob{Name: "rotate", Schedule: "0 3 * * *", Command: "CALL rotate_partitions()"}
+		vacuum = &CronJob{Name: "vacuum", Schedule: "0 4 * * *", Command: "VACUUM"}
+		purge  = &CronJob{Name: "purge", Schedule: "0 5 * * *", Command: "DELETE FROM events"}
+		from   = schema.NewRealm().AddObjects(rotate, vacuum)
+		to     = schema.NewRealm().AddObjects(
+			&CronJob{Name: "rotate", Schedule: "0 3 * * *", Command: "CALL rotate_partitions() "},
+			&CronJob{Name: "vacuum", Schedule: "0 2 * * *", Command: "VACUUM"},
+			purge,
+		)
+	)
+	changes, err := drv.RealmDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.ModifyObject{From: vacuum, To: to.Objects[1]},
+		&schema.AddObject{O: purge},
+	}, changes)
+}
+
 func TestDiff_SchemaDiff(t *testing.T) {
 	db, m, err := sqlmock.New()
 	require.NoError(t, err)
@@ -637,6 +788,27 @@ func TestDefaultDiff(t *testing.T) {
 	require.IsType(t, &schema.DropTable{}, changes[0])
 }
 
+func TestDiff_ColumnOrder(t *testing.T) {
+	var (
+		s    = schema.New("public")
+		from = schema.NewTable("t").SetSchema(s).AddColumns(
+			schema.NewIntColumn("a", "int"),
+			schema.NewIntColumn("b", "int"),
+		)
+		to = schema.NewTable("t").SetSchema(s).AddColumns(
+			schema.NewIntColumn("b", "int"),
+			schema.NewIntColumn("a", "int"),
+		)
+		reported []string
+	)
+	changes, err := DefaultDiff.TableDiff(from, to, schema.DiffColumnOrder(func(_, to *schema.Table) {
+		reported = append(reported, to.Name)
+	}))
+	require.NoError(t, err)
+	require.Empty(t, changes)
+	require.Equal(t, []string{"t"}, reported)
+}
+
 func TestDiff_AnnotateChanges(t *testing.T) {
 	var cfg struct {
 		schemahcl.DefaultExtension
@@ -7,13 +7,18 @@
 package postgres
 
 import (
+	"cmp"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"hash/fnv"
+	"maps"
 	"math/rand"
 	"net/url"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"ariga.io/atlas/schemahcl"
@@ -23,6 +28,8 @@ import (
 	"ariga.io/atlas/sql/schema"
 	"ariga.io/atlas/sql/sqlclient"
 	"ariga.io/atlas/sql/sqlspec"
+
+	"github.com/zclconf/go-cty/cty"
 )
 
 type (
@@ -43,8 +50,12 @@ type (
 		// Maps to the connection default_table_access_method parameter.
 		accessMethod string
 		// System variables that are set on `Open`.
-		version int
-		crdb    bool
+		version  int
+		crdb     bool
+		crdbV    string
+		encoding string
+		collate  string
+		timezone string
 	}
 )
 
@@ -94,15 +105,23 @@ func opener(_ context.Context, u *url.URL) (*sqlclient.Client, error) {
 	}, nil
 }
 
+// defaultAccessMethod returns the default table access method of the connection.
+func (c *conn) defaultAccessMethod() string {
+	if c.accessMethod != "" {
+		return c.accessMethod
+	}
+	return "heap"
+}
+
 // Open opens a new PostgreSQL driver.
 func Open(db schema.ExecQuerier) (migrate.Driver, error) {
-	c := &conn{ExecQuerier: db}
+	c := &conn{ExecQuerier: sqlx.WithErrorKind(db, errorKind)}
 	rows, err := db.QueryContext(context.Background(), paramsQuery)
 	if err != nil {
 		return nil, fmt.Errorf("postgres: scanning system variables: %w", err)
 	}
-	var ver, am, crdb sql.NullString
-	if err := sqlx.ScanOne(rows, &ver, &am, &crdb); err != nil {
+	var ver, am, crdb, encoding, collate, timezone sql.NullString
+	if err := sqlx.ScanOne(rows, &ver, &am, &crdb, &encoding, &collate, &timezone); err != nil {
 		return nil, fmt.Errorf("postgres: scanning system variables: %w", err)
 	}
 	if c.version, err = strconv.Atoi(ver.String); err != nil {
@@ -112,12 +131,14 @@ func Open(db schema.ExecQuerier) (migrate.Driver, error) {
 		return nil, fmt.Errorf("postgres: unsupported postgres version: %d", c.version)
 	}
 	c.accessMethod = am.String
+	c.encoding, c.collate, c.timezone = encoding.String, collate.String, timezone.String
 	if c.crdb = sqlx.ValidString(crdb); c.crdb {
+		c.crdbV = crdb.String
 		return noLockDriver{
 			&Driver{
 				conn:        c,
 				Differ:      &sqlx.Diff{DiffDriver: &crdbDiff{diff{c}}},
-				Inspector:   &crdbInspect{inspect{c}},
+				Inspector:   &sqlx.PartialInspect{Inspector: &crdbInspect{inspect{c}}},
 				PlanApplier: &planApply{c},
 			},
 		}, nil
@@ -125,7 +146,7 @@ func Open(db schema.ExecQuerier) (migrate.Driver, error) {
 	return &Driver{
 		conn:        c,
 		Differ:      &sqlx.Diff{DiffDriver: &diff{c}},
-		Inspector:   &inspect{c},
+		Inspector:   &sqlx.PartialInspect{Inspector: &inspect{c}},
 		PlanApplier: &planApply{c},
 	}, nil
 }
@@ -134,8 +155,11 @@ func (d *Driver) dev() *sqlx.DevDriver {
 	return &sqlx.DevDriver{
 		Driver: d,
 		PatchObject: func(s *schema.Schema, o schema.Object) {
-			if e, ok := o.(*schema.EnumType); ok {
-				e.Schema = s
+			switch o := o.(type) {
+			case *schema.EnumType:
+				o.Schema = s
+			case *Sequence:
+				o.Schema = s
 			}
 		},
 	}
@@ -151,6 +175,11 @@ func (d *Driver) NormalizeSchema(ctx context.Context, s *schema.Schema) (*schema
 	return d.dev().NormalizeSchema(ctx, s)
 }
 
+// NormalizeSchemas returns the normal representation of the given schemas.
+func (d *Driver) NormalizeSchemas(ctx context.Context, schemas ...*schema.Schema) ([]*schema.Schema, error) {
+	return d.dev().NormalizeSchemas(ctx, schemas...)
+}
+
 // Lock implements the schema.Locker interface.
 func (d *Driver) Lock(ctx context.Context, name string, timeout time.Duration) (schema.UnlockFunc, error) {
 	conn, err := sqlx.SingleConn(ctx, d.ExecQuerier)
@@ -346,6 +375,12 @@ func (*Driver) FormatType(t schema.Type) (string, error) {
 	return FormatType(t)
 }
 
+// FormatLiteral formats the given Go value as a PostgreSQL literal.
+// It implements the migrate.LiteralFormatter interface.
+func (*Driver) FormatLiteral(v any) (string, error) {
+	return FormatLiteral(v)
+}
+
 // ParseType returns the schema.Type value represented by the given string.
 func (*Driver) ParseType(s string) (schema.Type, error) {
 	return ParseType(s)
@@ -358,7 +393,44 @@ func (*Driver) StmtBuilder(opts migrate.PlanOptions) *sqlx.Builder {
 		QuoteClosing: '"',
 		Schema:       opts.SchemaQualifier,
 		Indent:       opts.Indent,
+		Quoting:      opts.Quoting,
+	}
+}
+
+// Backfill implements migrate.Backfiller. Values of columns whose type was changed
+// are cast to the new type, unless a Using expression is set. Tables with a single-column
+// primary key are backfilled in batches, using the strategy of DataMove.
+func (d *Driver) Backfill(b *migrate.Backfill, opts ...migrate.PlanOption) (*migrate.Change, error) {
+	var o migrate.PlanOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if b.Using == "" {
+		t1, err := FormatType(b.From.Type.Type)
+		if err != nil {
+			return nil, fmt.Errorf("postgres: format type of column %q: %w", b.From.Name, err)
+		}
+		t2, err := FormatType(b.To.Type.Type)
+		if err != nil {
+			return nil, fmt.Errorf("postgres: format type of column %q: %w", b.To.Name, err)
+		}
+		if t1 != t2 {
+			c := *b
+			c.Using = fmt.Sprintf("CAST(%s AS %s)", d.StmtBuilder(o).Ident(b.From.Name), t2)
+			b = &c
+		}
+	}
+	c := sqlx.Backfill(d.StmtBuilder(o), b)
+	// Backfill tables with a single-column primary key in batches.
+	if cmd, ok := BackfillMove(b).block(func() *sqlx.Builder { return d.StmtBuilder(o) }); ok {
+		c.Cmd = cmd
 	}
+	return c, nil
+}
+
+// DependsOn implements migrate.ChangeDepender.
+func (*Driver) DependsOn(change, other schema.Change) bool {
+	return sqlx.DependsOn(change, other, sortOptions)
 }
 
 // ScanStmts implements migrate.StmtScanner.
@@ -408,6 +480,31 @@ func acquire(ctx context.Context, conn schema.ExecQuerier, id uint32, timeout ti
 	}
 }
 
+// serverInfo returns the information of the connected server.
+func (c *conn) serverInfo() *schema.ServerInfo {
+	info := &schema.ServerInfo{
+		Flavor:    DriverName,
+		Version:   versionString(c.version),
+		Charset:   c.encoding,
+		Collation: c.collate,
+		TimeZone:  c.timezone,
+	}
+	if c.crdb {
+		info.Flavor, info.Version = "cockroach", c.crdbV
+	}
+	return info
+}
+
+// versionString formats the given server_version_num. Since v10, the number
+// is formatted as MMmmmm (major, minor), and before that, as MMmmpp (major,
+// minor, patch). e.g., 150004 is 15.4, and 90605 is 9.6.5.
+func versionString(v int) string {
+	if v >= 10_00_00 {
+		return fmt.Sprintf("%d.%d", v/10000, v%10000)
+	}
+	return fmt.Sprintf("%d.%d.%d", v/10000, v/100%100, v%100)
+}
+
 // supportsIndexInclude reports if the server supports the INCLUDE clause.
 func (c *conn) supportsIndexInclude() bool {
 	return c.version >= 11_00_00
@@ -418,6 +515,11 @@ func (c *conn) supportsIndexNullsDistinct() bool {
 	return c.version >= 15_00_00
 }
 
+// supportsStatsMCV reports if the server supports MCV extended statistics.
+func (c *conn) supportsStatsMCV() bool {
+	return c.version >= 12_00_00
+}
+
 type parser struct{}
 
 // ParseURL implements the sqlclient.URLParser interface.
@@ -434,11 +536,58 @@ func (parser) ChangeSchema(u *url.URL, s string) *url.URL {
 	return &nu
 }
 
+// maintenanceDB is the database used for creating other databases, as done by createdb.
+const maintenanceDB = "postgres"
+
+// EnsureDatabase implements the sqlclient.DatabaseEnsurer interface. It creates the database
+// of the URL, if it does not exist, using a connection to the maintenance database.
+func (parser) EnsureDatabase(ctx context.Context, u *url.URL) error {
+	name := strings.TrimPrefix(u.Path, "/")
+	if name == "" || name == maintenanceDB {
+		return nil
+	}
+	mu := parser{}.ChangeSchema(u, "")
+	mu.Path = "/" + maintenanceDB
+	db, err := sql.Open(DriverName, mu.String())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return ensureDatabase(ctx, db, name)
+}
+
+// ensureDatabase creates the given database if it does not exist. CREATE DATABASE
+// does not support the IF NOT EXISTS clause, and cannot be executed in a transaction.
+func ensureDatabase(ctx context.Context, db schema.ExecQuerier, name string) error {
+	rows, err := db.QueryContext(ctx, "SELECT COUNT(*) FROM pg_database WHERE datname = $1", name)
+	if err != nil {
+		return fmt.Errorf("postgres: query database %q: %w", name, err)
+	}
+	var n int
+	if err := sqlx.ScanOne(rows, &n); err != nil {
+		return fmt.Errorf("postgres: scan database %q: %w", name, err)
+	}
+	if n > 0 {
+		return nil
+	}
+	b := &sqlx.Builder{QuoteOpening: '"', QuoteClosing: '"'}
+	if _, err := db.ExecContext(ctx, b.P("CREATE DATABASE").Ident(name).String()); err != nil {
+		// Another session created the database in the meantime.
+		var e interface{ SQLState() string }
+		if errors.As(err, &e) && e.SQLState() == "42P04" {
+			return nil
+		}
+		return fmt.Errorf("postgres: create database %q: %w", name, err)
+	}
+	return nil
+}
+
 // Standard column types (and their aliases) as defined in
 // PostgreSQL codebase/website.
 const (
 	TypeBit     = "bit"
 	TypeBitVar  = "bit varying"
+	TypeVarBit  = "varbit"
 	TypeBoolean = "boolean"
 	TypeBool    = "bool" // boolean.
 	TypeBytea   = "bytea"
@@ -553,6 +702,9 @@ const (
 	typeUnknown      = "unknown"
 )
 
+// maxEnumLabel is the maximum length of an enum label (NAMEDATALEN-1).
+const maxEnumLabel = 63
+
 // List of supported index types.
 const (
 	IndexTypeBTree       = "BTREE"
@@ -564,6 +716,7 @@ const (
 	defaultPagesPerRange = 128
 	defaultListLimit     = 4 * 1024
 	defaultBtreeFill     = 90
+	defaultTablespace    = "pg_default"
 )
 
 const (
@@ -574,6 +727,7 @@ const (
 	storageParamListLimit  = "gin_pending_list_limit"
 	storageParamPagesRange = "pages_per_range"
 	storageParamAutoSum    = "autosummarize"
+	storageParamToast      = "toast."
 )
 
 const (
@@ -584,6 +738,36 @@ const (
 	storageParamOff = "OFF"
 )
 
+// List of extended statistics kinds.
+const (
+	StatsNDistinct    = "ndistinct"
+	StatsDependencies = "dependencies"
+	StatsMCV          = "mcv"
+)
+
+// statsKinds maps the kinds stored in pg_statistic_ext.stxkind to their names.
+var statsKinds = map[string]string{
+	"d": StatsNDistinct,
+	"f": StatsDependencies,
+	"m": StatsMCV,
+}
+
+// List of rewrite rule events.
+const (
+	RuleEventSelect = "SELECT"
+	RuleEventInsert = "INSERT"
+	RuleEventUpdate = "UPDATE"
+	RuleEventDelete = "DELETE"
+)
+
+// ruleEvents maps the events stored in pg_rewrite.ev_type to their names.
+var ruleEvents = map[string]string{
+	"1": RuleEventSelect,
+	"2": RuleEventUpdate,
+	"3": RuleEventInsert,
+	"4": RuleEventDelete,
+}
+
 // List of "GENERATED" types.
 const (
 	GeneratedTypeAlways    = "ALWAYS"
@@ -597,15 +781,48 @@ const (
 	PartitionTypeHash  = "HASH"
 )
 
+// List of function languages that are defined as enums in the HCL.
+const (
+	LangSQL     = "SQL"
+	LangPLpgSQL = "PLpgSQL"
+)
+
+// List of function volatility categories.
+const (
+	VolatilityVolatile  = "VOLATILE"
+	VolatilityStable    = "STABLE"
+	VolatilityImmutable = "IMMUTABLE"
+)
+
+// List of non-default parallel safety levels of aggregates.
+const (
+	ParallelSafe       = "SAFE"
+	ParallelRestricted = "RESTRICTED"
+	ParallelUnsafe     = "UNSAFE"
+)
+
+// List of non-default trigger states.
+const (
+	TriggerStateDisabled = "DISABLED"
+	TriggerStateReplica  = "REPLICA"
+	TriggerStateAlways   = "ALWAYS"
+)
+
 var (
 	specOptions []schemahcl.Option
 	specFuncs   = &specutil.SchemaFuncs{
 		Table: tableSpec,
 		View:  viewSpec,
+		Func:  funcSpec,
+		Proc:  procSpec,
 	}
 	scanFuncs = &specutil.ScanFuncs{
-		Table: convertTable,
-		View:  convertView,
+		Table:      convertTable,
+		View:       convertView,
+		Func:       convertFunc,
+		Proc:       convertProc,
+		Triggers:   convertTriggers,
+		ForeignKey: convertForeignKey,
 	}
 )
 
@@ -632,100 +849,499 @@ func (s *state) alterTableAttr(*sqlx.Builder, *schema.ModifyAttr) {
 	// unimplemented.
 }
 
-func realmObjectsSpec(*doc, *schema.Realm) error {
-	return nil // unimplemented.
+func realmObjectsSpec(d *doc, r *schema.Realm) error {
+	for _, o := range r.Objects {
+		switch o := o.(type) {
+		case *CronJob:
+			d.CronJobs = append(d.CronJobs, &cronJob{Name: o.Name, Schedule: o.Schedule, Command: o.Command})
+		case *Extension:
+			d.Extensions = append(d.Extensions, extensionSpec(r, o))
+		case *EventTrigger:
+			d.EventTriggers = append(d.EventTriggers, eventTriggerSpec(r, o))
+		case *ForeignServer:
+			d.Servers = append(d.Servers, foreignServerSpec(r, o))
+		case *Publication:
+			d.Publications = append(d.Publications, publicationSpec(o))
+		}
+	}
+	return nil
 }
 
-func triggersSpec([]*schema.Trigger, *doc) error {
-	return nil // unimplemented.
+// publicationSpec converts the publication to its spec. Published tables
+// are defined as "on" blocks, holding their column lists and row filters.
+func publicationSpec(p *Publication) *publication {
+	spec := &publication{Name: p.Name}
+	if p.AllTables {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.BoolAttr("all_tables", true))
+	}
+	if len(p.Schemas) > 0 {
+		refs := make([]*schemahcl.Ref, len(p.Schemas))
+		for i, s := range p.Schemas {
+			refs[i] = specutil.SchemaRef(s.Name)
+		}
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.RefsAttr("schemas", refs...))
+	}
+	if p.Publish != nil {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.StringsAttr("publish", p.Publish...))
+	}
+	if c := publicationComment(p); c != "" {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.StringAttr("comment", c))
+	}
+	for _, t := range p.Tables {
+		r := &schemahcl.Resource{Type: "on"}
+		r.Attrs = append(r.Attrs, schemahcl.RefAttr("table", specutil.TableSpecRef(t.Table)))
+		if len(t.Columns) > 0 {
+			names := make([]string, len(t.Columns))
+			for i, c := range t.Columns {
+				names[i] = c.Name
+			}
+			r.Attrs = append(r.Attrs, schemahcl.StringsAttr("columns", names...))
+		}
+		if t.Where != "" {
+			r.Attrs = append(r.Attrs, schemahcl.StringAttr("where", t.Where))
+		}
+		spec.Extra.Children = append(spec.Extra.Children, r)
+	}
+	return spec
 }
 
-func (*inspect) inspectViews(context.Context, *schema.Realm, *schema.InspectOptions) error {
-	return nil // unimplemented.
+// foreignServerSpec converts the foreign server to its spec, with its user mappings nested in it.
+func foreignServerSpec(r *schema.Realm, srv *ForeignServer) *foreignServer {
+	spec := &foreignServer{Name: srv.Name}
+	spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.StringAttr("wrapper", srv.Wrapper))
+	if srv.Type != "" {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.StringAttr("type", srv.Type))
+	}
+	if srv.Version != "" {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.StringAttr("version", srv.Version))
+	}
+	if c := foreignServerComment(srv); c != "" {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.StringAttr("comment", c))
+	}
+	if len(srv.Options) > 0 {
+		spec.Extra.Children = append(spec.Extra.Children, optionsSpec(srv.Options))
+	}
+	for _, o := range r.Objects {
+		if u, ok := o.(*UserMapping); ok && u.Server.Name == srv.Name {
+			um := &schemahcl.Resource{Type: "user_mapping", Name: u.User}
+			if len(u.Options) > 0 {
+				um.Children = append(um.Children, optionsSpec(u.Options))
+			}
+			spec.Extra.Children = append(spec.Extra.Children, um)
+		}
+	}
+	return spec
 }
 
-func (*inspect) inspectFuncs(context.Context, *schema.Realm, *schema.InspectOptions) error {
-	return nil // unimplemented.
+// optionsSpec converts the wrapper-specific options into an "options" block.
+func optionsSpec(opts map[string]string) *schemahcl.Resource {
+	r := &schemahcl.Resource{Type: "options"}
+	for _, k := range sortedKeys(opts) {
+		r.Attrs = append(r.Attrs, schemahcl.StringAttr(k, opts[k]))
+	}
+	return r
 }
 
-func (*inspect) inspectTypes(context.Context, *schema.Realm, *schema.InspectOptions) error {
-	return nil // unimplemented.
+// eventTriggerSpec converts the event trigger to its spec. The function is
+// qualified with its schema, if another schema holds a function with this name.
+func eventTriggerSpec(r *schema.Realm, e *EventTrigger) *eventTrigger {
+	spec := &eventTrigger{Name: e.Name}
+	spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.VarAttr("on", e.Event))
+	if len(e.Tags) > 0 {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.StringsAttr("tags", e.Tags...))
+	}
+	idx := schemahcl.PathIndex{T: "function", V: []string{e.Func.Name}}
+	if slices.ContainsFunc(r.Schemas, func(s *schema.Schema) bool {
+		_, ok := s.Func(e.Func.Name)
+		return ok && s.Name != sqlx.V(e.Func.Schema).Name
+	}) {
+		idx.V = append([]string{e.Func.Schema.Name}, idx.V...)
+	}
+	spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.RefAttr("execute", schemahcl.BuildRef([]schemahcl.PathIndex{idx})))
+	if v := eventTriggerState(e); v != "" {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.VarAttr("state", v))
+	}
+	if c := eventTriggerComment(e); c != "" {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.StringAttr("comment", c))
+	}
+	return spec
 }
 
-func (*inspect) inspectObjects(context.Context, *schema.Realm, *schema.InspectOptions) error {
-	return nil // unimplemented.
+// extensionSpec converts the extension to its spec. The schema is referenced
+// if it is part of the realm, and written as a string otherwise.
+func extensionSpec(r *schema.Realm, e *Extension) *extension {
+	spec := &extension{Name: e.Name}
+	if e.Schema != "" {
+		if _, ok := r.Schema(e.Schema); ok {
+			spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.RefAttr("schema", specutil.SchemaRef(e.Schema)))
+		} else {
+			spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.StringAttr("schema", e.Schema))
+		}
+	}
+	if e.Version != "" {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.StringAttr("version", e.Version))
+	}
+	if c := extensionComment(e); c != "" {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.StringAttr("comment", c))
+	}
+	return spec
 }
 
-func (*inspect) inspectTriggers(context.Context, *schema.Realm, *schema.InspectOptions) error {
-	return nil // unimplemented.
+// funcSpec converts the function to its spec.
+func funcSpec(f *schema.Func) (*sqlspec.Func, error) {
+	spec, err := routineSpec(f.Name, f.Args, f.Lang)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: function %q: %w", f.Name, err)
+	}
+	if f.Ret == nil {
+		return nil, fmt.Errorf("postgres: missing return type for function %q", f.Name)
+	}
+	ret, err := columnTypeSpec(f.Ret)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: return type of function %q: %w", f.Name, err)
+	}
+	spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.TypeAttr("return", ret.Type), schemahcl.StringAttr("as", sqlspec.MightHeredoc(pretty.Format(f.Body))))
+	if v := funcVolatility(f.Attrs); v != VolatilityVolatile {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.VarAttr("volatility", v))
+	}
+	if c := funcComment(f.Attrs); c != "" {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.StringAttr("comment", c))
+	}
+	return spec, nil
 }
 
-func (*inspect) inspectDeps(context.Context, *schema.Realm, *schema.InspectOptions) error {
-	return nil // unimplemented.
+// procSpec converts the procedure to its spec.
+func procSpec(p *schema.Proc) (*sqlspec.Func, error) {
+	spec, err := routineSpec(p.Name, p.Args, p.Lang)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: procedure %q: %w", p.Name, err)
+	}
+	spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.StringAttr("as", sqlspec.MightHeredoc(pretty.Format(p.Body))))
+	if c := funcComment(p.Attrs); c != "" {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.StringAttr("comment", c))
+	}
+	return spec, nil
 }
 
-func (*inspect) inspectRealmObjects(context.Context, *schema.Realm, *schema.InspectOptions) error {
-	return nil // unimplemented.
+// routineSpec returns the spec of a function or a procedure with its arguments and language.
+func routineSpec(name string, args []*schema.FuncArg, lang string) (*sqlspec.Func, error) {
+	spec := &sqlspec.Func{Name: name, Lang: cty.StringVal(lang)}
+	switch strings.ToLower(lang) {
+	case "", "sql":
+		spec.Lang = schemahcl.RefValue(LangSQL)
+	case "plpgsql":
+		spec.Lang = schemahcl.RefValue(LangPLpgSQL)
+	}
+	for _, a := range args {
+		t, err := columnTypeSpec(a.Type)
+		if err != nil {
+			return nil, fmt.Errorf("type of argument %q: %w", a.Name, err)
+		}
+		arg := &sqlspec.FuncArg{Name: a.Name, Type: t.Type}
+		if a.Default != nil {
+			if arg.Default, err = specutil.ColumnDefault(&schema.Column{Type: &schema.ColumnType{Type: a.Type}, Default: a.Default}); err != nil {
+				return nil, fmt.Errorf("default value of argument %q: %w", a.Name, err)
+			}
+		}
+		if a.Mode != "" && a.Mode != schema.FuncArgModeIn {
+			arg.Extra.Attrs = append(arg.Extra.Attrs, specutil.VarAttr("mode", string(a.Mode)))
+		}
+		spec.Args = append(spec.Args, arg)
+	}
+	return spec, nil
 }
 
-func (*state) addView(*schema.AddView) error {
-	return nil // unimplemented.
+// triggersSpec converts the given triggers to their specs and adds them to the document.
+func triggersSpec(ts []*schema.Trigger, d *doc) error {
+	for _, t := range ts {
+		spec := &sqlspec.Trigger{Name: t.Name}
+		switch {
+		case t.Table != nil:
+			spec.On = specutil.TableSpecRef(t.Table)
+		case t.View != nil:
+			spec.On = specutil.ViewSpecRef(t.View)
+		default:
+			return fmt.Errorf("postgres: missing table or view for trigger %q", t.Name)
+		}
+		events := &schemahcl.Resource{Type: strings.ReplaceAll(strings.ToLower(string(t.ActionTime)), " ", "_")}
+		for _, e := range t.Events {
+			switch {
+			case len(e.Columns) > 0:
+				refs := make([]*schemahcl.Ref, len(e.Columns))
+				for i, c := range e.Columns {
+					refs[i] = triggerColumnRef(spec.On, c)
+				}
+				events.Attrs = append(events.Attrs, schemahcl.RefsAttr("update_of", refs...))
+			default:
+				events.Attrs = append(events.Attrs, schemahcl.BoolAttr(strings.ToLower(e.Name), true))
+			}
+		}
+		spec.Extra.Children = append(spec.Extra.Children, events)
+		spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.VarAttr("foreach", string(t.For)))
+		if w := (TriggerWhen{}); sqlx.Has(t.Attrs, &w) && w.X != "" {
+			spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.StringAttr("when", w.X))
+		}
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.StringAttr("execute", t.Body))
+		if v := triggerState(t); v != "" {
+			spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.VarAttr("state", v))
+		}
+		d.Triggers = append(d.Triggers, spec)
+	}
+	return nil
 }
 
-func (*state) dropView(*schema.DropView) error {
-	return nil // unimplemented.
+// triggerColumnRef returns a reference to the column of the table referenced by the trigger.
+func triggerColumnRef(on *schemahcl.Ref, c *schema.Column) *schemahcl.Ref {
+	q, name, err := specutil.TableName(on)
+	if err == nil && q != "" {
+		return specutil.QualifiedExternalColRef(c.Name, name, q)
+	}
+	return specutil.ExternalColumnRef(c.Name, name)
 }
 
-func (*state) modifyView(*schema.ModifyView) error {
+func (*inspect) inspectViews(context.Context, *schema.Realm, *schema.InspectOptions) error {
 	return nil // unimplemented.
 }
 
-func (*state) renameView(*schema.RenameView) {
-	// unimplemented.
+func (i *inspect) inspectFuncs(ctx context.Context, r *schema.Realm, _ *schema.InspectOptions) error {
+	if err := i.inspectProcFuncs(ctx, r); err != nil {
+		return err
+	}
+	return i.inspectAggregates(ctx, r)
 }
 
-func (s *state) addFunc(*schema.AddFunc) error {
-	return nil // unimplemented.
+func (i *inspect) inspectTypes(ctx context.Context, r *schema.Realm, _ *schema.InspectOptions) error {
+	if err := i.inspectDomains(ctx, r); err != nil {
+		return err
+	}
+	if err := i.inspectRanges(ctx, r); err != nil {
+		return err
+	}
+	return i.inspectComposites(ctx, r)
 }
 
-func (s *state) dropFunc(*schema.DropFunc) error {
-	return nil // unimplemented.
+func (i *inspect) inspectObjects(ctx context.Context, r *schema.Realm, _ *schema.InspectOptions) error {
+	if err := i.inspectCollations(ctx, r); err != nil {
+		return err
+	}
+	return i.inspectSequences(ctx, r)
 }
 
-func (s *state) modifyFunc(*schema.ModifyFunc) error {
-	return nil // unimplemented.
+func (i *inspect) inspectTriggers(ctx context.Context, r *schema.Realm, _ *schema.InspectOptions) error {
+	return i.inspectTableTriggers(ctx, r)
 }
 
-func (s *state) renameFunc(*schema.RenameFunc) error {
+func (*inspect) inspectDeps(context.Context, *schema.Realm, *schema.InspectOptions) error {
 	return nil // unimplemented.
 }
 
-func (s *state) addProc(*schema.AddProc) error {
-	return nil // unimplemented.
+func (i *inspect) inspectRealmObjects(ctx context.Context, r *schema.Realm, _ *schema.InspectOptions) error {
+	if err := i.inspectExtensions(ctx, r); err != nil {
+		return err
+	}
+	return i.inspectEventTriggers(ctx, r)
 }
 
-func (s *state) dropProc(*schema.DropProc) error {
+func (*state) addView(*schema.AddView) error {
 	return nil // unimplemented.
 }
 
-func (s *state) modifyProc(*schema.ModifyProc) error {
+func (*state) dropView(*schema.DropView) error {
 	return nil // unimplemented.
 }
 
-func (s *state) renameProc(*schema.RenameProc) error {
+func (*state) modifyView(*schema.ModifyView) error {
 	return nil // unimplemented.
 }
 
+func (*state) renameView(*schema.RenameView) {
+	// unimplemented.
+}
+
+func (s *state) renameFunc(rename *schema.RenameFunc) error {
+	fromSig, err := s.funcSig(rename.From.Name, rename.From.Schema, rename.From.Args)
+	if err != nil {
+		return err
+	}
+	toSig, err := s.funcSig(rename.To.Name, rename.To.Schema, rename.To.Args)
+	if err != nil {
+		return err
+	}
+	s.append(&migrate.Change{
+		Source:  rename,
+		Cmd:     fmt.Sprintf("ALTER FUNCTION %s RENAME TO %s", fromSig, s.Build().Ident(rename.To.Name).String()),
+		Reverse: fmt.Sprintf("ALTER FUNCTION %s RENAME TO %s", toSig, s.Build().Ident(rename.From.Name).String()),
+		Comment: fmt.Sprintf("rename a function from %q to %q", rename.From.Name, rename.To.Name),
+	})
+	return nil
+}
+
+func (s *state) renameProc(rename *schema.RenameProc) error {
+	fromSig, err := s.funcSig(rename.From.Name, rename.From.Schema, rename.From.Args)
+	if err != nil {
+		return err
+	}
+	toSig, err := s.funcSig(rename.To.Name, rename.To.Schema, rename.To.Args)
+	if err != nil {
+		return err
+	}
+	s.append(&migrate.Change{
+		Source:  rename,
+		Cmd:     fmt.Sprintf("ALTER PROCEDURE %s RENAME TO %s", fromSig, s.Build().Ident(rename.To.Name).String()),
+		Reverse: fmt.Sprintf("ALTER PROCEDURE %s RENAME TO %s", toSig, s.Build().Ident(rename.From.Name).String()),
+		Comment: fmt.Sprintf("rename a procedure from %q to %q", rename.From.Name, rename.To.Name),
+	})
+	return nil
+}
+
 func (s *state) addObject(add *schema.AddObject) error {
 	switch o := add.O.(type) {
 	case *schema.EnumType:
 		create, drop := s.createDropEnum(o)
+		// Types do not support the IF NOT EXISTS clause. Hence,
+		// the creation is guarded by an anonymous code block.
+		if s.ifNotExists(add.Extra) {
+			create = GuardDuplicate(create)
+		}
 		s.append(&migrate.Change{
 			Source:  add,
 			Cmd:     create,
 			Reverse: drop,
 			Comment: fmt.Sprintf("create enum type %q", o.T),
 		})
+		if c := enumComment(o); c != "" {
+			s.append(s.enumComment(add, o, c, ""))
+		}
+	case *DomainType:
+		create, err := s.createDomain(o)
+		if err != nil {
+			return err
+		}
+		// Similar to enums, domains do not support the IF NOT EXISTS clause.
+		if s.ifNotExists(add.Extra) {
+			create = GuardDuplicate(create)
+		}
+		s.append(&migrate.Change{
+			Source:  add,
+			Cmd:     create,
+			Reverse: s.Build("DROP DOMAIN").P(s.domainIdent(o)).String(),
+			Comment: fmt.Sprintf("create domain type %q", o.T),
+		})
+		if c := domainComment(o); c != "" {
+			s.append(s.domainComment(add, o, c, ""))
+		}
+	case *CompositeType:
+		create, err := s.createComposite(o)
+		if err != nil {
+			return err
+		}
+		if s.ifNotExists(add.Extra) {
+			create = GuardDuplicate(create)
+		}
+		s.append(&migrate.Change{
+			Source:  add,
+			Cmd:     create,
+			Reverse: s.Build("DROP TYPE").P(s.compositeIdent(o)).String(),
+			Comment: fmt.Sprintf("create composite type %q", o.T),
+		})
+		if c := compositeComment(o); c != "" {
+			s.append(s.compositeComment(add, o, c, ""))
+		}
+	case *UserRangeType:
+		create, err := s.createRange(o)
+		if err != nil {
+			return err
+		}
+		if s.ifNotExists(add.Extra) {
+			create = GuardDuplicate(create)
+		}
+		s.append(&migrate.Change{
+			Source:  add,
+			Cmd:     create,
+			Reverse: s.Build("DROP TYPE").P(s.rangeIdent(o)).String(),
+			Comment: fmt.Sprintf("create range type %q", o.T),
+		})
+		if c := rangeComment(o); c != "" {
+			s.append(s.rangeComment(add, o, c, ""))
+		}
+	case *Collation:
+		s.append(&migrate.Change{
+			Source:  add,
+			Cmd:     s.createCollation(o, s.ifNotExists(add.Extra)),
+			Reverse: s.Build("DROP COLLATION").P(s.collationIdent(o)).String(),
+			Comment: fmt.Sprintf("create collation %q", o.Name),
+		})
+		if c := collationComment(o); c != "" {
+			s.append(s.collationComment(add, o, c, ""))
+		}
+	case *TablePartition:
+		s.addPartition(add, o)
+	case *TextSearchDict:
+		s.addTextSearchDict(add, o)
+	case *TextSearchConfig:
+		s.addTextSearchConfig(add, o)
+	case *Operator:
+		s.addOperator(add, o)
+	case *Aggregate:
+		return s.addAggregate(add, o)
+	case *OpFamily:
+		s.addOpFamily(add, o)
+	case *OpClass:
+		s.addOpClass(add, o)
+	case *Sequence:
+		s.append(&migrate.Change{
+			Source:  add,
+			Cmd:     s.createSequence(o, s.ifNotExists(add.Extra)),
+			Reverse: s.Build("DROP SEQUENCE").P(s.seqIdent(o)).String(),
+			Comment: fmt.Sprintf("create sequence %q", o.Name),
+		})
+		if c := seqComment(o); c != "" {
+			s.append(s.seqComment(add, o, c, ""))
+		}
+	case *Extension:
+		s.append(&migrate.Change{
+			Source:  add,
+			Cmd:     s.createExtension(o, s.ifNotExists(add.Extra)),
+			Reverse: s.Build("DROP EXTENSION").Ident(o.Name).String(),
+			Comment: fmt.Sprintf("create extension %q", o.Name),
+		})
+		if c := extensionComment(o); c != "" {
+			s.append(s.extensionComment(add, o, c, ""))
+		}
+	case *CronJob:
+		s.append(&migrate.Change{
+			Source:  add,
+			Cmd:     scheduleJob(o),
+			Reverse: unscheduleJob(o),
+			Comment: fmt.Sprintf("schedule cron job %q", o.Name),
+		})
+	case *EventTrigger:
+		s.addEventTrigger(add, o)
+	case *ForeignServer:
+		s.addForeignServer(add, o)
+	case *Publication:
+		s.addPublication(add, o)
+	case *UserMapping:
+		s.append(&migrate.Change{
+			Source:  add,
+			Cmd:     s.createUserMapping(o),
+			Reverse: s.dropUserMapping(o),
+			Comment: fmt.Sprintf("create user mapping %q for server %q", o.User, o.Server.Name),
+		})
+	case *ForeignTable:
+		return s.addForeignTable(add, o)
+	case *Role:
+		create := s.createRole(o)
+		// Similar to types, roles do not support the IF NOT EXISTS clause.
+		if s.ifNotExists(add.Extra) {
+			create = GuardDuplicate(create)
+		}
+		s.append(&migrate.Change{
+			Source:  add,
+			Cmd:     create,
+			Reverse: s.Build("DROP ROLE").Ident(o.Name).String(),
+			Comment: fmt.Sprintf("create role %q", o.Name),
+		})
 	default:
 		// unsupported object type.
 	}
@@ -742,147 +1358,5750 @@ func (s *state) dropObject(drop *schema.DropObject) error {
 			Reverse: create,
 			Comment: fmt.Sprintf("drop enum type %q", o.T),
 		})
-	default:
-		// unsupported object type.
-	}
-	return nil
-}
-
-func (s *state) modifyObject(modify *schema.ModifyObject) error {
-	if _, ok := modify.From.(*schema.EnumType); ok {
-		return s.alterEnum(modify)
-	}
-	return nil // unimplemented.
-}
-
-func (*state) addTrigger(*schema.AddTrigger) error {
-	return nil // unimplemented.
-}
-
-func (*state) dropTrigger(*schema.DropTrigger) error {
-	return nil // unimplemented.
+	case *DomainType:
+		create, err := s.createDomain(o)
+		if err != nil {
+			return err
+		}
+		s.append(&migrate.Change{
+			Source:  drop,
+			Cmd:     s.Build("DROP DOMAIN").P(s.domainIdent(o)).String(),
+			Reverse: create,
+			Comment: fmt.Sprintf("drop domain type %q", o.T),
+		})
+	case *CompositeType:
+		create, err := s.createComposite(o)
+		if err != nil {
+			return err
+		}
+		s.append(&migrate.Change{
+			Source:  drop,
+			Cmd:     s.Build("DROP TYPE").P(s.compositeIdent(o)).String(),
+			Reverse: create,
+			Comment: fmt.Sprintf("drop composite type %q", o.T),
+		})
+	case *UserRangeType:
+		create, err := s.createRange(o)
+		if err != nil {
+			return err
+		}
+		s.append(&migrate.Change{
+			Source:  drop,
+			Cmd:     s.Build("DROP TYPE").P(s.rangeIdent(o)).String(),
+			Reverse: create,
+			Comment: fmt.Sprintf("drop range type %q", o.T),
+		})
+	case *Collation:
+		s.append(&migrate.Change{
+			Source:  drop,
+			Cmd:     s.Build("DROP COLLATION").P(s.collationIdent(o)).String(),
+			Reverse: s.createCollation(o, false),
+			Comment: fmt.Sprintf("drop collation %q", o.Name),
+		})
+	case *TablePartition:
+		s.append(&migrate.Change{
+			Source:  drop,
+			Cmd:     s.Build("DROP TABLE").P(s.typeIdent(o.Schema, o.Name)).String(),
+			Reverse: s.createPartition(o),
+			Comment: fmt.Sprintf("drop partition %q", o.Name),
+		})
+	case *TextSearchDict:
+		s.append(&migrate.Change{
+			Source:  drop,
+			Cmd:     s.Build("DROP TEXT SEARCH DICTIONARY").P(s.typeIdent(o.Schema, o.Name)).String(),
+			Reverse: s.createTextSearchDict(o),
+			Comment: fmt.Sprintf("drop text search dictionary %q", o.Name),
+		})
+	case *TextSearchConfig:
+		s.append(&migrate.Change{
+			Source:  drop,
+			Cmd:     s.Build("DROP TEXT SEARCH CONFIGURATION").P(s.typeIdent(o.Schema, o.Name)).String(),
+			Reverse: s.createTextSearchConfig(o),
+			Comment: fmt.Sprintf("drop text search configuration %q", o.Name),
+		})
+	case *Operator:
+		s.dropOperator(drop, o)
+	case *Aggregate:
+		return s.dropAggregate(drop, o)
+	case *OpFamily:
+		s.append(&migrate.Change{
+			Source:  drop,
+			Cmd:     s.dropOpFamily(o),
+			Reverse: s.createOpFamily(o),
+			Comment: fmt.Sprintf("drop operator family %q", o.Name),
+		})
+	case *OpClass:
+		s.dropOpClass(drop, o)
+	case *Sequence:
+		b := s.Build("DROP SEQUENCE")
+		// Owned sequences are dropped along with their tables.
+		if o.Owner.T != nil {
+			b.P("IF EXISTS")
+		}
+		s.append(&migrate.Change{
+			Source:  drop,
+			Cmd:     b.P(s.seqIdent(o)).String(),
+			Reverse: s.createSequence(o, false),
+			Comment: fmt.Sprintf("drop sequence %q", o.Name),
+		})
+	case *Extension:
+		s.append(&migrate.Change{
+			Source:  drop,
+			Cmd:     s.Build("DROP EXTENSION").Ident(o.Name).String(),
+			Reverse: s.createExtension(o, false),
+			Comment: fmt.Sprintf("drop extension %q", o.Name),
+		})
+	case *CronJob:
+		s.append(&migrate.Change{
+			Source:  drop,
+			Cmd:     unscheduleJob(o),
+			Reverse: scheduleJob(o),
+			Comment: fmt.Sprintf("unschedule cron job %q", o.Name),
+		})
+	case *EventTrigger:
+		s.append(&migrate.Change{
+			Source:  drop,
+			Cmd:     s.Build("DROP EVENT TRIGGER").Ident(o.Name).String(),
+			Reverse: s.createEventTrigger(o),
+			Comment: fmt.Sprintf("drop event trigger %q", o.Name),
+		})
+	case *Publication:
+		s.append(&migrate.Change{
+			Source:  drop,
+			Cmd:     s.Build("DROP PUBLICATION").Ident(o.Name).String(),
+			Reverse: s.createPublication(o),
+			Comment: fmt.Sprintf("drop publication %q", o.Name),
+		})
+	case *ForeignServer:
+		s.append(&migrate.Change{
+			Source:  drop,
+			Cmd:     s.Build("DROP SERVER").Ident(o.Name).String(),
+			Reverse: s.createForeignServer(o),
+			Comment: fmt.Sprintf("drop foreign server %q", o.Name),
+		})
+	case *Role:
+		s.append(&migrate.Change{
+			Source:  drop,
+			Cmd:     s.Build("DROP ROLE").Ident(o.Name).String(),
+			Reverse: s.createRole(o),
+			Comment: fmt.Sprintf("drop role %q", o.Name),
+		})
+	case *UserMapping:
+		s.append(&migrate.Change{
+			Source:  drop,
+			Cmd:     s.dropUserMapping(o),
+			Reverse: s.createUserMapping(o),
+			Comment: fmt.Sprintf("drop user mapping %q for server %q", o.User, o.Server.Name),
+		})
+	case *ForeignTable:
+		create, err := s.createForeignTable(o)
+		if err != nil {
+			return err
+		}
+		s.append(&migrate.Change{
+			Source:  drop,
+			Cmd:     s.Build("DROP FOREIGN TABLE").P(s.foreignTableIdent(o)).String(),
+			Reverse: create,
+			Comment: fmt.Sprintf("drop foreign table %q", o.Name),
+		})
+	default:
+		// unsupported object type.
+	}
+	return nil
+}
+
+func (s *state) modifyObject(modify *schema.ModifyObject) error {
+	switch from := modify.From.(type) {
+	case *schema.EnumType:
+		return s.alterEnum(modify)
+	case *DomainType:
+		return s.alterDomain(modify, from, modify.To.(*DomainType))
+	case *CompositeType:
+		return s.alterComposite(modify, from, modify.To.(*CompositeType))
+	case *UserRangeType:
+		return s.alterRange(modify, from, modify.To.(*UserRangeType))
+	case *Collation:
+		s.alterCollation(modify, from, modify.To.(*Collation))
+	case *TablePartition:
+		s.alterPartition(modify, from, modify.To.(*TablePartition))
+	case *TextSearchDict:
+		s.alterTextSearchDict(modify, from, modify.To.(*TextSearchDict))
+	case *TextSearchConfig:
+		s.alterTextSearchConfig(modify, from, modify.To.(*TextSearchConfig))
+	case *Operator:
+		s.alterOperator(modify, from, modify.To.(*Operator))
+	case *Aggregate:
+		return s.alterAggregate(modify, from, modify.To.(*Aggregate))
+	case *OpFamily:
+		if c1, c2 := opFamilyComment(from), opFamilyComment(modify.To.(*OpFamily)); c1 != c2 {
+			s.append(s.opComment(modify, "OPERATOR FAMILY", s.opFamilyIdent(from), from.Name, c2, c1))
+		}
+	case *OpClass:
+		s.alterOpClass(modify, from, modify.To.(*OpClass))
+	case *Sequence:
+		to := modify.To.(*Sequence)
+		if cmd := s.alterSequence(from, to); cmd != "" {
+			s.append(&migrate.Change{
+				Source:  modify,
+				Cmd:     cmd,
+				Reverse: s.alterSequence(to, from),
+				Comment: fmt.Sprintf("modify sequence %q", from.Name),
+			})
+		}
+		if c1, c2 := seqComment(from), seqComment(to); c1 != c2 {
+			s.append(s.seqComment(modify, to, c2, c1))
+		}
+	case *Extension:
+		s.alterExtension(modify, from, modify.To.(*Extension))
+	case *CronJob:
+		// Scheduling a job with an existing name updates it in place.
+		s.append(&migrate.Change{
+			Source:  modify,
+			Cmd:     scheduleJob(modify.To.(*CronJob)),
+			Reverse: scheduleJob(from),
+			Comment: fmt.Sprintf("modify cron job %q", from.Name),
+		})
+	case *EventTrigger:
+		s.alterEventTrigger(modify, from, modify.To.(*EventTrigger))
+	case *ForeignServer:
+		s.alterForeignServer(modify, from, modify.To.(*ForeignServer))
+	case *Publication:
+		s.alterPublication(modify, from, modify.To.(*Publication))
+	case *UserMapping:
+		to := modify.To.(*UserMapping)
+		s.append(&migrate.Change{
+			Source:  modify,
+			Cmd:     s.alterOptions(s.Build("ALTER USER MAPPING FOR").P(userMappingFor(to)), from.Options, to.Options),
+			Reverse: s.alterOptions(s.Build("ALTER USER MAPPING FOR").P(userMappingFor(to)), to.Options, from.Options),
+			Comment: fmt.Sprintf("modify user mapping %q for server %q", from.User, from.Server.Name),
+		})
+	case *ForeignTable:
+		return s.alterForeignTable(modify, from, modify.To.(*ForeignTable))
+	}
+	return nil
+}
+
+// createDomain returns the CREATE DOMAIN statement of the given domain.
+func (s *state) createDomain(d *DomainType) (string, error) {
+	t, err := s.formatType(d.Type)
+	if err != nil {
+		return "", fmt.Errorf("format type of domain %q: %w", d.T, err)
+	}
+	b := s.Build("CREATE DOMAIN").P(s.domainIdent(d), "AS", t)
+	if d.Default != nil {
+		s.formatDefault(b, d.Type, d.Default)
+	}
+	if !d.Null {
+		b.P("NOT NULL")
+	}
+	for _, c := range d.Checks {
+		check(b, c)
+	}
+	return b.String(), nil
+}
+
+// alterDomain appends the ALTER DOMAIN statements for moving the domain from one state to the other.
+func (s *state) alterDomain(modify *schema.ModifyObject, from, to *DomainType) error {
+	t1, err1 := FormatType(from.Type)
+	t2, err2 := FormatType(to.Type)
+	if err1 != nil || err2 != nil || t1 != t2 {
+		return fmt.Errorf("changing the underlying type of domain %q is not supported", from.T)
+	}
+	var (
+		name   = s.domainIdent(to)
+		alter  = func() *sqlx.Builder { return s.Build("ALTER DOMAIN").P(name) }
+		setDef = func(d *DomainType) string {
+			if d.Default == nil {
+				return alter().P("DROP DEFAULT").String()
+			}
+			b := alter().P("SET")
+			s.formatDefault(b, d.Type, d.Default)
+			return b.String()
+		}
+		setNull = func(d *DomainType) string {
+			if d.Null {
+				return alter().P("DROP NOT NULL").String()
+			}
+			return alter().P("SET NOT NULL").String()
+		}
+	)
+	if domainDefault(from) != domainDefault(to) {
+		s.append(&migrate.Change{
+			Source:  modify,
+			Cmd:     setDef(to),
+			Reverse: setDef(from),
+			Comment: fmt.Sprintf("modify default value of domain type %q", to.T),
+		})
+	}
+	if from.Null != to.Null {
+		s.append(&migrate.Change{
+			Source:  modify,
+			Cmd:     setNull(to),
+			Reverse: setNull(from),
+			Comment: fmt.Sprintf("modify nullability of domain type %q", to.T),
+		})
+	}
+	drop, add := domainChecks(from, to)
+	for _, c := range drop {
+		b := alter().P("ADD")
+		check(b, c)
+		s.append(&migrate.Change{
+			Source:  modify,
+			Cmd:     alter().P("DROP CONSTRAINT").Ident(c.Name).String(),
+			Reverse: b.String(),
+			Comment: fmt.Sprintf("drop check constraint %q from domain type %q", c.Name, to.T),
+		})
+	}
+	for _, c := range add {
+		b := alter().P("ADD")
+		check(b, c)
+		// Unnamed constraints are named by the database
+		// using the "<domain>_check" naming convention.
+		n := c.Name
+		if n == "" {
+			n = fmt.Sprintf("%s_check", to.T)
+		}
+		s.append(&migrate.Change{
+			Source:  modify,
+			Cmd:     b.String(),
+			Reverse: alter().P("DROP CONSTRAINT").Ident(n).String(),
+			Comment: fmt.Sprintf("add check constraint to domain type %q", to.T),
+		})
+	}
+	if c1, c2 := domainComment(from), domainComment(to); c1 != c2 {
+		s.append(s.domainComment(modify, to, c2, c1))
+	}
+	return nil
+}
+
+func (s *state) domainComment(src schema.Change, d *DomainType, to, from string) *migrate.Change {
+	b := s.Build("COMMENT ON DOMAIN").P(s.domainIdent(d), "IS")
+	return &migrate.Change{
+		Cmd:     b.Clone().P(quote(to)).String(),
+		Source:  src,
+		Comment: fmt.Sprintf("set comment to domain type: %q", d.T),
+		Reverse: b.Clone().P(quote(from)).String(),
+	}
+}
+
+// createComposite returns the CREATE TYPE statement of the given composite type.
+func (s *state) createComposite(c *CompositeType) (string, error) {
+	b := s.Build("CREATE TYPE").P(s.compositeIdent(c), "AS")
+	if err := b.WrapErr(func(b *sqlx.Builder) error {
+		return b.MapCommaErr(c.Fields, func(i int, b *sqlx.Builder) error {
+			return s.compositeField(b, c, c.Fields[i])
+		})
+	}); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// compositeField writes the definition of the composite type field to the builder.
+func (s *state) compositeField(b *sqlx.Builder, c *CompositeType, f *schema.Column) error {
+	return s.compositeFieldType(b.Ident(f.Name), c, f)
+}
+
+// compositeFieldType writes the type of the composite type field, and its collation, to the builder.
+func (s *state) compositeFieldType(b *sqlx.Builder, c *CompositeType, f *schema.Column) error {
+	t, err := s.formatType(f.Type.Type)
+	if err != nil {
+		return fmt.Errorf("format type of field %q in composite type %q: %w", f.Name, c.T, err)
+	}
+	b.P(t)
+	if v := fieldCollation(f); v != "" {
+		b.P("COLLATE").Ident(v)
+	}
+	return nil
+}
+
+// alterComposite appends the ALTER TYPE statement for moving the composite type from one state to
+// the other. Note, fields that were added to the type are appended after the existing ones, as the
+// database does not support positioning them.
+func (s *state) alterComposite(modify *schema.ModifyObject, from, to *CompositeType) error {
+	var (
+		cmd, reverse []string
+		addF         = func(c *CompositeType, f *schema.Column) (string, error) {
+			b := s.Build("ADD ATTRIBUTE")
+			if err := s.compositeField(b, c, f); err != nil {
+				return "", err
+			}
+			return b.String(), nil
+		}
+		alterF = func(c *CompositeType, f *schema.Column) (string, error) {
+			b := s.Build("ALTER ATTRIBUTE").Ident(f.Name).P("TYPE")
+			if err := s.compositeFieldType(b, c, f); err != nil {
+				return "", err
+			}
+			return b.String(), nil
+		}
+	)
+	drop, add, alter := compositeFields(from, to)
+	for _, f := range drop {
+		cmd = append(cmd, s.Build("DROP ATTRIBUTE").Ident(f.Name).String())
+		r, err := addF(from, f)
+		if err != nil {
+			return err
+		}
+		reverse = append(reverse, r)
+	}
+	for _, f := range add {
+		c, err := addF(to, f)
+		if err != nil {
+			return err
+		}
+		cmd = append(cmd, c)
+		reverse = append(reverse, s.Build("DROP ATTRIBUTE").Ident(f.Name).String())
+	}
+	for _, fs := range alter {
+		c, err := alterF(to, fs[1])
+		if err != nil {
+			return err
+		}
+		r, err := alterF(from, fs[0])
+		if err != nil {
+			return err
+		}
+		cmd, reverse = append(cmd, c), append(reverse, r)
+	}
+	if len(cmd) > 0 {
+		s.append(&migrate.Change{
+			Source:  modify,
+			Cmd:     s.Build("ALTER TYPE").P(s.compositeIdent(to), strings.Join(cmd, ", ")).String(),
+			Reverse: s.Build("ALTER TYPE").P(s.compositeIdent(from), strings.Join(reverse, ", ")).String(),
+			Comment: fmt.Sprintf("modify composite type %q", to.T),
+		})
+	}
+	if c1, c2 := compositeComment(from), compositeComment(to); c1 != c2 {
+		s.append(s.compositeComment(modify, to, c2, c1))
+	}
+	return nil
+}
+
+func (s *state) compositeComment(src schema.Change, c *CompositeType, to, from string) *migrate.Change {
+	b := s.Build("COMMENT ON TYPE").P(s.compositeIdent(c), "IS")
+	return &migrate.Change{
+		Cmd:     b.Clone().P(quote(to)).String(),
+		Source:  src,
+		Comment: fmt.Sprintf("set comment to composite type: %q", c.T),
+		Reverse: b.Clone().P(quote(from)).String(),
+	}
+}
+
+// createCollation returns the CREATE COLLATION statement of the given collation.
+func (s *state) createCollation(c *Collation, ifNotExists bool) string {
+	b := s.Build("CREATE COLLATION")
+	if ifNotExists {
+		b.P("IF NOT EXISTS")
+	}
+	b.P(s.collationIdent(c)).Wrap(func(b *sqlx.Builder) {
+		var opts []string
+		if c.Provider != "" {
+			opts = append(opts, "PROVIDER = "+c.Provider)
+		}
+		if c.Locale != "" {
+			opts = append(opts, "LOCALE = "+quote(c.Locale))
+		} else {
+			opts = append(opts, "LC_COLLATE = "+quote(c.LcCollate), "LC_CTYPE = "+quote(c.LcCtype))
+		}
+		if !c.Deterministic {
+			opts = append(opts, "DETERMINISTIC = false")
+		}
+		b.P(strings.Join(opts, ", "))
+	})
+	return b.String()
+}
+
+// alterCollation appends the statements for moving the collation from one state to the other.
+// Collations cannot be altered, and therefore, a change to their definition recreates them.
+func (s *state) alterCollation(modify *schema.ModifyObject, from, to *Collation) {
+	if !collationEqual(from, to) {
+		s.append(&migrate.Change{
+			Source:  modify,
+			Cmd:     s.Build("DROP COLLATION").P(s.collationIdent(from)).String(),
+			Reverse: s.createCollation(from, false),
+			Comment: fmt.Sprintf("drop collation %q for recreation", from.Name),
+		}, &migrate.Change{
+			Source:  modify,
+			Cmd:     s.createCollation(to, false),
+			Reverse: s.Build("DROP COLLATION").P(s.collationIdent(to)).String(),
+			Comment: fmt.Sprintf("create collation %q", to.Name),
+		})
+		// The comment is dropped along with the collation.
+		if c := collationComment(to); c != "" {
+			s.append(s.collationComment(modify, to, c, ""))
+		}
+		return
+	}
+	if c1, c2 := collationComment(from), collationComment(to); c1 != c2 {
+		s.append(s.collationComment(modify, to, c2, c1))
+	}
+}
+
+func (s *state) collationComment(src schema.Change, c *Collation, to, from string) *migrate.Change {
+	b := s.Build("COMMENT ON COLLATION").P(s.collationIdent(c)).P("IS")
+	return &migrate.Change{
+		Cmd:     b.Clone().P(quote(to)).String(),
+		Source:  src,
+		Comment: fmt.Sprintf("set comment to collation: %q", c.Name),
+		Reverse: b.Clone().P(quote(from)).String(),
+	}
+}
+
+func (s *state) collationIdent(c *Collation) string {
+	return s.typeIdent(c.Schema, c.Name)
+}
+
+// addPartition appends the changes for creating the given partition.
+func (s *state) addPartition(src schema.Change, p *TablePartition) {
+	s.append(&migrate.Change{
+		Source:  src,
+		Cmd:     s.createPartition(p),
+		Reverse: s.Build("DROP TABLE").P(s.typeIdent(p.Schema, p.Name)).String(),
+		Comment: fmt.Sprintf("create partition %q", p.Name),
+	})
+	if c := partitionComment(p); c != "" {
+		s.append(s.partitionComment(src, p, c, ""))
+	}
+}
+
+// createPartition returns the CREATE TABLE statement of the given partition.
+func (s *state) createPartition(p *TablePartition) string {
+	b := s.Build("CREATE TABLE").P(s.typeIdent(p.Schema, p.Name), "PARTITION OF", s.partitionParent(p), p.Bound)
+	if p.PartitionBy != "" {
+		b.P("PARTITION BY", p.PartitionBy)
+	}
+	return b.String()
+}
+
+// alterPartition appends the changes for moving the partition from one state to the other.
+// Partitions that were moved to another parent, or whose bound was changed, are detached and
+// re-attached to keep their data. Changing the partition key of a partition recreates it.
+func (s *state) alterPartition(modify *schema.ModifyObject, from, to *TablePartition) {
+	if from.PartitionBy != to.PartitionBy {
+		s.append(&migrate.Change{
+			Source:  modify,
+			Cmd:     s.Build("DROP TABLE").P(s.typeIdent(from.Schema, from.Name)).String(),
+			Reverse: s.createPartition(from),
+			Comment: fmt.Sprintf("drop partition %q for recreation", from.Name),
+		})
+		s.addPartition(modify, to)
+		return
+	}
+	if s.partitionParent(from) != s.partitionParent(to) || !partitionBoundEqual(from.Bound, to.Bound) {
+		var (
+			ident  = s.typeIdent(to.Schema, to.Name)
+			detach = func(p *TablePartition) string {
+				return s.Build("ALTER TABLE").P(s.partitionParent(p), "DETACH PARTITION", ident).String()
+			}
+			attach = func(p *TablePartition) string {
+				return s.Build("ALTER TABLE").P(s.partitionParent(p), "ATTACH PARTITION", ident, p.Bound).String()
+			}
+		)
+		s.append(&migrate.Change{
+			Source:  modify,
+			Cmd:     detach(from),
+			Reverse: attach(from),
+			Comment: fmt.Sprintf("detach partition %q", from.Name),
+		}, &migrate.Change{
+			Source:  modify,
+			Cmd:     attach(to),
+			Reverse: detach(to),
+			Comment: fmt.Sprintf("attach partition %q", to.Name),
+		})
+	}
+	if c1, c2 := partitionComment(from), partitionComment(to); c1 != c2 {
+		s.append(s.partitionComment(modify, to, c2, c1))
+	}
+}
+
+func (s *state) partitionComment(src schema.Change, p *TablePartition, to, from string) *migrate.Change {
+	b := s.Build("COMMENT ON TABLE").P(s.typeIdent(p.Schema, p.Name)).P("IS")
+	return &migrate.Change{
+		Cmd:     b.Clone().P(quote(to)).String(),
+		Source:  src,
+		Comment: fmt.Sprintf("set comment to partition: %q", p.Name),
+		Reverse: b.Clone().P(quote(from)).String(),
+	}
+}
+
+// partitionParent returns the identifier of the parent of the partition.
+func (s *state) partitionParent(p *TablePartition) string {
+	if p.Table != nil {
+		return s.typeIdent(p.Table.Schema, p.Table.Name)
+	}
+	return s.typeIdent(p.Parent.Schema, p.Parent.Name)
+}
+
+// addTextSearchDict appends the changes for creating the given text search dictionary.
+func (s *state) addTextSearchDict(src schema.Change, d *TextSearchDict) {
+	s.append(&migrate.Change{
+		Source:  src,
+		Cmd:     s.createTextSearchDict(d),
+		Reverse: s.Build("DROP TEXT SEARCH DICTIONARY").P(s.typeIdent(d.Schema, d.Name)).String(),
+		Comment: fmt.Sprintf("create text search dictionary %q", d.Name),
+	})
+	if c := tsDictComment(d); c != "" {
+		s.append(s.tsComment(src, "DICTIONARY", d.Schema, d.Name, c, ""))
+	}
+}
+
+// createTextSearchDict returns the CREATE TEXT SEARCH DICTIONARY statement of the given dictionary.
+func (s *state) createTextSearchDict(d *TextSearchDict) string {
+	return s.Build("CREATE TEXT SEARCH DICTIONARY").P(s.typeIdent(d.Schema, d.Name)).Wrap(func(b *sqlx.Builder) {
+		b.P("TEMPLATE =", tsIdent(d.Template))
+		for _, k := range sortedKeys(d.Options) {
+			b.Comma().Ident(k).P("=", quote(d.Options[k]))
+		}
+	}).String()
+}
+
+// alterTextSearchDict appends the changes for moving the dictionary from one state to the other.
+// Changing the template of a dictionary recreates it, while its options are altered in place.
+func (s *state) alterTextSearchDict(modify *schema.ModifyObject, from, to *TextSearchDict) {
+	if from.Template != to.Template {
+		s.append(&migrate.Change{
+			Source:  modify,
+			Cmd:     s.Build("DROP TEXT SEARCH DICTIONARY").P(s.typeIdent(from.Schema, from.Name)).String(),
+			Reverse: s.createTextSearchDict(from),
+			Comment: fmt.Sprintf("drop text search dictionary %q for recreation", from.Name),
+		})
+		s.addTextSearchDict(modify, to)
+		return
+	}
+	if !maps.Equal(from.Options, to.Options) {
+		cmd := func(from, to *TextSearchDict) string {
+			return s.Build("ALTER TEXT SEARCH DICTIONARY").P(s.typeIdent(to.Schema, to.Name)).Wrap(func(b *sqlx.Builder) {
+				var opts []string
+				for _, k := range sortedKeys(to.Options) {
+					if v, ok := from.Options[k]; !ok || v != to.Options[k] {
+						opts = append(opts, s.Build().Ident(k).P("=", quote(to.Options[k])).String())
+					}
+				}
+				// Options without a value are reset to their defaults.
+				for _, k := range sortedKeys(from.Options) {
+					if _, ok := to.Options[k]; !ok {
+						opts = append(opts, s.Build().Ident(k).String())
+					}
+				}
+				b.P(strings.Join(opts, ", "))
+			}).String()
+		}
+		s.append(&migrate.Change{
+			Source:  modify,
+			Cmd:     cmd(from, to),
+			Reverse: cmd(to, from),
+			Comment: fmt.Sprintf("modify text search dictionary %q", from.Name),
+		})
+	}
+	if c1, c2 := tsDictComment(from), tsDictComment(to); c1 != c2 {
+		s.append(s.tsComment(modify, "DICTIONARY", to.Schema, to.Name, c2, c1))
+	}
+}
+
+// addTextSearchConfig appends the changes for creating the given text search configuration.
+// The configuration is created without mappings, and they are added separately.
+func (s *state) addTextSearchConfig(src schema.Change, c *TextSearchConfig) {
+	s.append(&migrate.Change{
+		Source:  src,
+		Cmd:     s.createTextSearchConfig(c),
+		Reverse: s.Build("DROP TEXT SEARCH CONFIGURATION").P(s.typeIdent(c.Schema, c.Name)).String(),
+		Comment: fmt.Sprintf("create text search configuration %q", c.Name),
+	})
+	s.alterTSMappings(src, &TextSearchConfig{Name: c.Name, Schema: c.Schema}, c)
+	if cm := tsConfigComment(c); cm != "" {
+		s.append(s.tsComment(src, "CONFIGURATION", c.Schema, c.Name, cm, ""))
+	}
+}
+
+// createTextSearchConfig returns the CREATE TEXT SEARCH CONFIGURATION statement of the given configuration.
+func (s *state) createTextSearchConfig(c *TextSearchConfig) string {
+	return s.Build("CREATE TEXT SEARCH CONFIGURATION").P(s.typeIdent(c.Schema, c.Name)).Wrap(func(b *sqlx.Builder) {
+		b.P("PARSER =", tsIdent(c.Parser))
+	}).String()
+}
+
+// alterTextSearchConfig appends the changes for moving the configuration from one state to the other.
+// The parser of a configuration cannot be changed, and therefore, changing it recreates the configuration.
+func (s *state) alterTextSearchConfig(modify *schema.ModifyObject, from, to *TextSearchConfig) {
+	if from.Parser != to.Parser {
+		s.append(&migrate.Change{
+			Source:  modify,
+			Cmd:     s.Build("DROP TEXT SEARCH CONFIGURATION").P(s.typeIdent(from.Schema, from.Name)).String(),
+			Reverse: s.createTextSearchConfig(from),
+			Comment: fmt.Sprintf("drop text search configuration %q for recreation", from.Name),
+		})
+		s.addTextSearchConfig(modify, to)
+		return
+	}
+	s.alterTSMappings(modify, from, to)
+	if c1, c2 := tsConfigComment(from), tsConfigComment(to); c1 != c2 {
+		s.append(s.tsComment(modify, "CONFIGURATION", to.Schema, to.Name, c2, c1))
+	}
+}
+
+// alterTSMappings appends the changes for moving the token mappings of a configuration from one
+// state to the other. Token types that share the same change are grouped into one statement.
+func (s *state) alterTSMappings(src schema.Change, from, to *TextSearchConfig) {
+	var (
+		ident          = s.typeIdent(to.Schema, to.Name)
+		drop, add, mod []string
+	)
+	for _, t := range sortedTokens(from.Mappings) {
+		if _, ok := to.Mappings[t]; !ok {
+			drop = append(drop, t)
+		}
+	}
+	for _, t := range sortedTokens(to.Mappings) {
+		switch d, ok := from.Mappings[t]; {
+		case !ok:
+			add = append(add, t)
+		case !slices.Equal(d, to.Mappings[t]):
+			mod = append(mod, t)
+		}
+	}
+	mapping := func(op string, m map[string][]string, tokens []string) string {
+		b := s.Build("ALTER TEXT SEARCH CONFIGURATION").P(ident, op, "MAPPING FOR", strings.Join(tokens, ", "))
+		if op != "DROP" {
+			b.P("WITH").MapComma(m[tokens[0]], func(i int, b *sqlx.Builder) {
+				b.P(tsIdent(m[tokens[0]][i]))
+			})
+		}
+		return b.String()
+	}
+	for _, g := range groupTokens(drop, from.Mappings, nil) {
+		s.append(&migrate.Change{
+			Source:  src,
+			Cmd:     mapping("DROP", nil, g),
+			Reverse: mapping("ADD", from.Mappings, g),
+			Comment: fmt.Sprintf("drop mapping from text search configuration %q", to.Name),
+		})
+	}
+	for _, g := range groupTokens(mod, to.Mappings, from.Mappings) {
+		s.append(&migrate.Change{
+			Source:  src,
+			Cmd:     mapping("ALTER", to.Mappings, g),
+			Reverse: mapping("ALTER", from.Mappings, g),
+			Comment: fmt.Sprintf("modify mapping of text search configuration %q", to.Name),
+		})
+	}
+	for _, g := range groupTokens(add, to.Mappings, nil) {
+		s.append(&migrate.Change{
+			Source:  src,
+			Cmd:     mapping("ADD", to.Mappings, g),
+			Reverse: mapping("DROP", nil, g),
+			Comment: fmt.Sprintf("add mapping to text search configuration %q", to.Name),
+		})
+	}
+}
+
+func (s *state) tsComment(src schema.Change, kind string, ns *schema.Schema, name, to, from string) *migrate.Change {
+	b := s.Build("COMMENT ON TEXT SEARCH", kind).P(s.typeIdent(ns, name)).P("IS")
+	return &migrate.Change{
+		Cmd:     b.Clone().P(quote(to)).String(),
+		Source:  src,
+		Comment: fmt.Sprintf("set comment to text search %s: %q", strings.ToLower(kind), name),
+		Reverse: b.Clone().P(quote(from)).String(),
+	}
+}
+
+// tsIdent quotes the given, optionally qualified, name of a text search object.
+func tsIdent(name string) string {
+	if q, n, ok := strings.Cut(name, "."); ok {
+		return fmt.Sprintf("%q.%q", q, n)
+	}
+	return strconv.Quote(name)
+}
+
+// sortedTokens returns the token types of the given mappings, sorted.
+func sortedTokens(m map[string][]string) []string {
+	tokens := make([]string, 0, len(m))
+	for t := range m {
+		tokens = append(tokens, t)
+	}
+	slices.Sort(tokens)
+	return tokens
+}
+
+// groupTokens groups the given token types by their dictionaries in m1 and
+// m2 (if not nil), in the order of the first token type of each group.
+func groupTokens(tokens []string, m1, m2 map[string][]string) [][]string {
+	var groups [][]string
+	for _, t := range tokens {
+		i := slices.IndexFunc(groups, func(g []string) bool {
+			return slices.Equal(m1[g[0]], m1[t]) && slices.Equal(m2[g[0]], m2[t])
+		})
+		if i == -1 {
+			groups = append(groups, []string{t})
+		} else {
+			groups[i] = append(groups[i], t)
+		}
+	}
+	return groups
+}
+
+// addOperator appends the changes for creating the given operator.
+func (s *state) addOperator(src schema.Change, o *Operator) {
+	s.append(&migrate.Change{
+		Source:  src,
+		Cmd:     s.createOperator(o),
+		Reverse: s.Build("DROP OPERATOR").P(s.operatorSig(o)).String(),
+		Comment: fmt.Sprintf("create operator %q", o.Name),
+	})
+	if c := operatorComment(o); c != "" {
+		s.append(s.opComment(src, "OPERATOR", s.operatorSig(o), o.Name, c, ""))
+	}
+}
+
+// dropOperator appends the change for dropping the given operator.
+func (s *state) dropOperator(src schema.Change, o *Operator) {
+	s.append(&migrate.Change{
+		Source:  src,
+		Cmd:     s.Build("DROP OPERATOR").P(s.operatorSig(o)).String(),
+		Reverse: s.createOperator(o),
+		Comment: fmt.Sprintf("drop operator %q", o.Name),
+	})
+}
+
+// createOperator returns the CREATE OPERATOR statement of the given operator.
+func (s *state) createOperator(o *Operator) string {
+	return s.Build("CREATE OPERATOR").P(s.operatorIdent(o)).Wrap(func(b *sqlx.Builder) {
+		b.P("FUNCTION =", o.Function)
+		if o.Left != "" {
+			b.Comma().P("LEFTARG =", o.Left)
+		}
+		b.Comma().P("RIGHTARG =", o.Right)
+		for _, a := range []struct{ k, v string }{
+			{"COMMUTATOR", operatorRef(o.Commutator)},
+			{"NEGATOR", operatorRef(o.Negator)},
+			{"RESTRICT", o.Restrict},
+			{"JOIN", o.Join},
+		} {
+			if a.v != "" {
+				b.Comma().P(a.k, "=", a.v)
+			}
+		}
+		if o.Hashes {
+			b.Comma().P("HASHES")
+		}
+		if o.Merges {
+			b.Comma().P("MERGES")
+		}
+	}).String()
+}
+
+// alterOperator appends the changes for moving the operator from one state to the other. Only the
+// selectivity estimators of an operator can be altered, and other changes recreate the operator.
+func (s *state) alterOperator(modify *schema.ModifyObject, from, to *Operator) {
+	if from.Function != to.Function || from.Commutator != to.Commutator || from.Negator != to.Negator || from.Hashes != to.Hashes || from.Merges != to.Merges {
+		s.dropOperator(modify, from)
+		s.addOperator(modify, to)
+		return
+	}
+	if from.Restrict != to.Restrict || from.Join != to.Join {
+		cmd := func(o *Operator) string {
+			return s.Build("ALTER OPERATOR").P(s.operatorSig(o), "SET").Wrap(func(b *sqlx.Builder) {
+				b.P("RESTRICT =", cmp.Or(o.Restrict, "NONE")).Comma().P("JOIN =", cmp.Or(o.Join, "NONE"))
+			}).String()
+		}
+		s.append(&migrate.Change{
+			Source:  modify,
+			Cmd:     cmd(to),
+			Reverse: cmd(from),
+			Comment: fmt.Sprintf("modify operator %q", to.Name),
+		})
+	}
+	if c1, c2 := operatorComment(from), operatorComment(to); c1 != c2 {
+		s.append(s.opComment(modify, "OPERATOR", s.operatorSig(to), to.Name, c2, c1))
+	}
+}
+
+// createAggregate returns the CREATE AGGREGATE statement of the given aggregate.
+func (s *state) createAggregate(a *Aggregate) (string, error) {
+	b := s.Build("CREATE AGGREGATE").Func(&schema.Func{Name: a.Name, Schema: a.Schema})
+	if len(a.Args) == 0 {
+		b.P("(*)")
+	} else if err := s.funcArgs(b, a.Args); err != nil {
+		return "", fmt.Errorf("arguments of aggregate %q: %w", a.Name, err)
+	}
+	t, err := s.formatType(a.StateType)
+	if err != nil {
+		return "", fmt.Errorf("state type of aggregate %q: %w", a.Name, err)
+	}
+	opts := []string{"SFUNC = " + a.StateFunc, "STYPE = " + t}
+	if a.FinalFunc != "" {
+		opts = append(opts, "FINALFUNC = "+a.FinalFunc)
+	}
+	if a.InitCond != nil {
+		opts = append(opts, "INITCOND = "+quote(*a.InitCond))
+	}
+	if a.Parallel != "" && a.Parallel != ParallelUnsafe {
+		opts = append(opts, "PARALLEL = "+a.Parallel)
+	}
+	return b.P("(" + strings.Join(opts, ", ") + ")").String(), nil
+}
+
+// aggregateSig returns the signature of the aggregate that identifies it,
+// i.e., its name and the types of its arguments. e.g., "public"."rows" (*).
+func (s *state) aggregateSig(a *Aggregate) (string, error) {
+	if len(a.Args) == 0 {
+		return s.Build().Func(&schema.Func{Name: a.Name, Schema: a.Schema}).P("(*)").String(), nil
+	}
+	return s.funcSig(a.Name, a.Schema, a.Args)
+}
+
+// addAggregate appends the changes for creating the given aggregate.
+func (s *state) addAggregate(src schema.Change, a *Aggregate) error {
+	create, err := s.createAggregate(a)
+	if err != nil {
+		return err
+	}
+	sig, err := s.aggregateSig(a)
+	if err != nil {
+		return err
+	}
+	s.append(&migrate.Change{
+		Source:  src,
+		Cmd:     create,
+		Reverse: s.Build("DROP AGGREGATE").P(sig).String(),
+		Comment: fmt.Sprintf("create aggregate %q", a.Name),
+	})
+	if c := aggregateComment(a); c != "" {
+		s.append(s.funcCommentChange(src, "AGGREGATE", sig, c, ""))
+	}
+	return nil
+}
+
+// dropAggregate appends the change for dropping the given aggregate.
+func (s *state) dropAggregate(src schema.Change, a *Aggregate) error {
+	create, err := s.createAggregate(a)
+	if err != nil {
+		return err
+	}
+	sig, err := s.aggregateSig(a)
+	if err != nil {
+		return err
+	}
+	s.append(&migrate.Change{
+		Source:  src,
+		Cmd:     s.Build("DROP AGGREGATE").P(sig).String(),
+		Reverse: create,
+		Comment: fmt.Sprintf("drop aggregate %q", a.Name),
+	})
+	return nil
+}
+
+// alterAggregate appends the statements for moving the aggregate from one state to the other.
+// A change to the aggregate definition recreates it, as its state type cannot be replaced.
+func (s *state) alterAggregate(modify *schema.ModifyObject, from, to *Aggregate) error {
+	if !aggregateEqual(from, to) {
+		if err := s.dropAggregate(modify, from); err != nil {
+			return err
+		}
+		return s.addAggregate(modify, to)
+	}
+	if c1, c2 := aggregateComment(from), aggregateComment(to); c1 != c2 {
+		sig, err := s.aggregateSig(to)
+		if err != nil {
+			return err
+		}
+		s.append(s.funcCommentChange(modify, "AGGREGATE", sig, c2, c1))
+	}
+	return nil
+}
+
+// operatorIdent returns the qualified name of the operator. Unlike
+// other identifiers, operator names cannot be double-quoted.
+func (s *state) operatorIdent(o *Operator) string {
+	switch {
+	// In case the plan uses a specific schema qualifier.
+	case s.SchemaQualifier != nil:
+		if *s.SchemaQualifier != "" {
+			return fmt.Sprintf("%q.%s", *s.SchemaQualifier, o.Name)
+		}
+	case o.Schema != nil && o.Schema.Name != "":
+		return fmt.Sprintf("%q.%s", o.Schema.Name, o.Name)
+	}
+	return o.Name
+}
+
+// operatorSig returns the qualified name of the operator with its operand types.
+func (s *state) operatorSig(o *Operator) string {
+	return fmt.Sprintf("%s (%s, %s)", s.operatorIdent(o), cmp.Or(o.Left, "NONE"), o.Right)
+}
+
+// operatorRef returns the reference to the given, optionally
+// qualified, operator name. e.g., OPERATOR("public".<->).
+func operatorRef(name string) string {
+	if ns, n, ok := strings.Cut(name, "."); ok {
+		return fmt.Sprintf("OPERATOR(%q.%s)", ns, n)
+	}
+	return name
+}
+
+// addOpFamily appends the changes for creating the given operator family.
+func (s *state) addOpFamily(src schema.Change, f *OpFamily) {
+	s.append(&migrate.Change{
+		Source:  src,
+		Cmd:     s.createOpFamily(f),
+		Reverse: s.dropOpFamily(f),
+		Comment: fmt.Sprintf("create operator family %q", f.Name),
+	})
+	if c := opFamilyComment(f); c != "" {
+		s.append(s.opComment(src, "OPERATOR FAMILY", s.opFamilyIdent(f), f.Name, c, ""))
+	}
+}
+
+// createOpFamily returns the CREATE OPERATOR FAMILY statement of the given family.
+func (s *state) createOpFamily(f *OpFamily) string {
+	return s.Build("CREATE OPERATOR FAMILY").P(s.opFamilyIdent(f)).String()
+}
+
+// dropOpFamily returns the DROP OPERATOR FAMILY statement of the given family.
+func (s *state) dropOpFamily(f *OpFamily) string {
+	return s.Build("DROP OPERATOR FAMILY").P(s.opFamilyIdent(f)).String()
+}
+
+// opFamilyIdent returns the qualified name of the family with its access method.
+func (s *state) opFamilyIdent(f *OpFamily) string {
+	return s.typeIdent(f.Schema, f.Name) + " USING " + f.Method
+}
+
+// addOpClass appends the changes for creating the given operator class.
+func (s *state) addOpClass(src schema.Change, c *OpClass) {
+	s.append(&migrate.Change{
+		Source:  src,
+		Cmd:     s.createOpClass(c),
+		Reverse: s.dropOpClassCmd(c),
+		Comment: fmt.Sprintf("create operator class %q", c.Name),
+	})
+	if cm := opClassComment(c); cm != "" {
+		s.append(s.opComment(src, "OPERATOR CLASS", s.opClassIdent(c), c.Name, cm, ""))
+	}
+}
+
+// dropOpClass appends the change for dropping the given operator class.
+func (s *state) dropOpClass(src schema.Change, c *OpClass) {
+	s.append(&migrate.Change{
+		Source:  src,
+		Cmd:     s.dropOpClassCmd(c),
+		Reverse: s.createOpClass(c),
+		Comment: fmt.Sprintf("drop operator class %q", c.Name),
+	})
+}
+
+// createOpClass returns the CREATE OPERATOR CLASS statement of the given class.
+func (s *state) createOpClass(c *OpClass) string {
+	b := s.Build("CREATE OPERATOR CLASS").P(s.typeIdent(c.Schema, c.Name))
+	if c.Default {
+		b.P("DEFAULT")
+	}
+	b.P("FOR TYPE", c.Type, "USING", c.Method)
+	if c.Family != "" {
+		b.P("FAMILY", s.opClassFamily(c))
+	}
+	var items []string
+	for _, o := range c.Operators {
+		item := fmt.Sprintf("OPERATOR %d %s", o.Strategy, o.Name)
+		if o.OrderBy != "" {
+			item += " FOR ORDER BY " + o.OrderBy
+		}
+		items = append(items, item)
+	}
+	for _, f := range c.Funcs {
+		items = append(items, fmt.Sprintf("FUNCTION %d %s", f.Number, f.Name))
+	}
+	if c.Storage != "" {
+		items = append(items, "STORAGE "+c.Storage)
+	}
+	return b.P("AS", strings.Join(items, ", ")).String()
+}
+
+// dropOpClassCmd returns the statement for dropping the given operator class. Classes
+// with an implicit family are dropped along with their family, as it is not dropped
+// automatically by Postgres, and it is created again when the class is recreated.
+func (s *state) dropOpClassCmd(c *OpClass) string {
+	if c.Family == "" {
+		return s.dropOpFamily(&OpFamily{Name: c.Name, Schema: c.Schema, Method: c.Method})
+	}
+	return s.Build("DROP OPERATOR CLASS").P(s.opClassIdent(c)).String()
+}
+
+// alterOpClass appends the changes for moving the operator class from one state to the other.
+// Operator classes cannot be altered, and therefore, a change to their definition recreates them.
+func (s *state) alterOpClass(modify *schema.ModifyObject, from, to *OpClass) {
+	if !opClassEqual(from, to) {
+		s.append(&migrate.Change{
+			Source:  modify,
+			Cmd:     s.dropOpClassCmd(from),
+			Reverse: s.createOpClass(from),
+			Comment: fmt.Sprintf("drop operator class %q for recreation", from.Name),
+		})
+		s.addOpClass(modify, to)
+		return
+	}
+	if c1, c2 := opClassComment(from), opClassComment(to); c1 != c2 {
+		s.append(s.opComment(modify, "OPERATOR CLASS", s.opClassIdent(to), to.Name, c2, c1))
+	}
+}
+
+// opClassIdent returns the qualified name of the class with its access method.
+func (s *state) opClassIdent(c *OpClass) string {
+	return s.typeIdent(c.Schema, c.Name) + " USING " + c.Method
+}
+
+// opClassFamily returns the qualified name of the family of the class.
+func (s *state) opClassFamily(c *OpClass) string {
+	if ns, n, ok := strings.Cut(c.Family, "."); ok {
+		return fmt.Sprintf("%q.%q", ns, n)
+	}
+	return s.typeIdent(c.Schema, c.Family)
+}
+
+func (s *state) opComment(src schema.Change, kind, ident, name, to, from string) *migrate.Change {
+	b := s.Build("COMMENT ON", kind, ident, "IS")
+	return &migrate.Change{
+		Cmd:     b.Clone().P(quote(to)).String(),
+		Source:  src,
+		Comment: fmt.Sprintf("set comment to %s: %q", strings.ToLower(kind), name),
+		Reverse: b.Clone().P(quote(from)).String(),
+	}
+}
+
+// createRange returns the CREATE TYPE statement of the given range type.
+func (s *state) createRange(r *UserRangeType) (string, error) {
+	t, err := s.formatType(r.Subtype)
+	if err != nil {
+		return "", fmt.Errorf("format subtype of range type %q: %w", r.T, err)
+	}
+	opts := []string{"SUBTYPE = " + t}
+	if r.Collation != "" {
+		opts = append(opts, "COLLATION = "+strconv.Quote(r.Collation))
+	}
+	if r.Canonical != "" {
+		opts = append(opts, "CANONICAL = "+r.Canonical)
+	}
+	if r.SubtypeDiff != "" {
+		opts = append(opts, "SUBTYPE_DIFF = "+r.SubtypeDiff)
+	}
+	return s.Build("CREATE TYPE").
+		P(s.rangeIdent(r), "AS RANGE").
+		Wrap(func(b *sqlx.Builder) { b.P(strings.Join(opts, ", ")) }).
+		String(), nil
+}
+
+// alterRange appends the statements for moving the range type from one state to the other.
+// The definition of range types cannot be altered, and therefore, a change to it recreates
+// the type. Note, the database rejects dropping a range type that is used by other objects.
+func (s *state) alterRange(modify *schema.ModifyObject, from, to *UserRangeType) error {
+	if !rangeEqual(from, to) {
+		c1, err := s.createRange(from)
+		if err != nil {
+			return err
+		}
+		c2, err := s.createRange(to)
+		if err != nil {
+			return err
+		}
+		s.append(&migrate.Change{
+			Source:  modify,
+			Cmd:     s.Build("DROP TYPE").P(s.rangeIdent(from)).String(),
+			Reverse: c1,
+			Comment: fmt.Sprintf("drop range type %q for recreation", from.T),
+		}, &migrate.Change{
+			Source:  modify,
+			Cmd:     c2,
+			Reverse: s.Build("DROP TYPE").P(s.rangeIdent(to)).String(),
+			Comment: fmt.Sprintf("create range type %q", to.T),
+		})
+		// The comment is dropped along with the type.
+		if c := rangeComment(to); c != "" {
+			s.append(s.rangeComment(modify, to, c, ""))
+		}
+		return nil
+	}
+	if c1, c2 := rangeComment(from), rangeComment(to); c1 != c2 {
+		s.append(s.rangeComment(modify, to, c2, c1))
+	}
+	return nil
+}
+
+func (s *state) rangeComment(src schema.Change, r *UserRangeType, to, from string) *migrate.Change {
+	b := s.Build("COMMENT ON TYPE").P(s.rangeIdent(r), "IS")
+	return &migrate.Change{
+		Cmd:     b.Clone().P(quote(to)).String(),
+		Source:  src,
+		Comment: fmt.Sprintf("set comment to range type: %q", r.T),
+		Reverse: b.Clone().P(quote(from)).String(),
+	}
+}
+
+// createSequence returns the CREATE SEQUENCE statement of the given sequence. Note, the
+// OWNED BY clause is set separately by seqOwners, as the owner table might not exist yet.
+func (s *state) createSequence(seq *Sequence, ifNotExists bool) string {
+	b := s.Build("CREATE SEQUENCE")
+	if ifNotExists {
+		b.P("IF NOT EXISTS")
+	}
+	b.P(s.seqIdent(seq))
+	if t := seqType(seq); t != TypeBigInt {
+		b.P("AS", t)
+	}
+	if i := seqIncrement(seq); i != defaultSeqIncrement {
+		b.P("INCREMENT BY", strconv.FormatInt(i, 10))
+	}
+	if seq.Min != nil {
+		b.P("MINVALUE", strconv.FormatInt(*seq.Min, 10))
+	}
+	if seq.Max != nil {
+		b.P("MAXVALUE", strconv.FormatInt(*seq.Max, 10))
+	}
+	if seq.Start != 0 && seq.Start != seqStart(&Sequence{Type: seq.Type, Increment: seq.Increment, Min: seq.Min, Max: seq.Max}) {
+		b.P("START WITH", strconv.FormatInt(seq.Start, 10))
+	}
+	if c := seqCache(seq); c != 1 {
+		b.P("CACHE", strconv.FormatInt(c, 10))
+	}
+	if seq.Cycle {
+		b.P("CYCLE")
+	}
+	return b.String()
+}
+
+// alterSequence returns the ALTER SEQUENCE statement for moving the sequence
+// options from one state to the other, or an empty string if they are equal.
+func (s *state) alterSequence(from, to *Sequence) string {
+	var (
+		n          int
+		b          = s.Build("ALTER SEQUENCE").P(s.seqIdent(to))
+		min1, max1 = seqBounds(from)
+		min2, max2 = seqBounds(to)
+	)
+	if t := seqType(to); seqType(from) != t {
+		b.P("AS", t)
+		n++
+	}
+	if i := seqIncrement(to); seqIncrement(from) != i {
+		b.P("INCREMENT BY", strconv.FormatInt(i, 10))
+		n++
+	}
+	if min1 != min2 {
+		b.P("MINVALUE", strconv.FormatInt(min2, 10))
+		n++
+	}
+	if max1 != max2 {
+		b.P("MAXVALUE", strconv.FormatInt(max2, 10))
+		n++
+	}
+	if v := seqStart(to); seqStart(from) != v {
+		b.P("START WITH", strconv.FormatInt(v, 10))
+		n++
+	}
+	if c := seqCache(to); seqCache(from) != c {
+		b.P("CACHE", strconv.FormatInt(c, 10))
+		n++
+	}
+	if from.Cycle != to.Cycle {
+		if !to.Cycle {
+			b.P("NO")
+		}
+		b.P("CYCLE")
+		n++
+	}
+	if n == 0 {
+		return ""
+	}
+	return b.String()
+}
+
+// seqOwners sets the owners of the sequences that were created or modified
+// in the given changes. It is called after all tables were created.
+func (s *state) seqOwners(changes []schema.Change) {
+	for _, c := range changes {
+		var from, to *Sequence
+		switch c := c.(type) {
+		case *schema.AddObject:
+			to, _ = c.O.(*Sequence)
+			from = &Sequence{}
+		case *schema.ModifyObject:
+			from, _ = c.From.(*Sequence)
+			to, _ = c.To.(*Sequence)
+		}
+		if from == nil || to == nil || seqOwner(from) == seqOwner(to) {
+			continue
+		}
+		b := s.Build("ALTER SEQUENCE").P(s.seqIdent(to), "OWNED BY")
+		s.append(&migrate.Change{
+			Source:  c,
+			Cmd:     s.seqOwnedBy(b.Clone(), to),
+			Reverse: s.seqOwnedBy(b.Clone(), from),
+			Comment: fmt.Sprintf("set owner of sequence %q", to.Name),
+		})
+	}
+}
+
+func (s *state) seqOwnedBy(b *sqlx.Builder, seq *Sequence) string {
+	if seqOwner(seq) == "" {
+		return b.P("NONE").String()
+	}
+	return b.TableResource(seq.Owner.T, seq.Owner.C).String()
+}
+
+func (s *state) seqComment(src schema.Change, seq *Sequence, to, from string) *migrate.Change {
+	b := s.Build("COMMENT ON SEQUENCE").P(s.seqIdent(seq)).P("IS")
+	return &migrate.Change{
+		Cmd:     b.Clone().P(quote(to)).String(),
+		Source:  src,
+		Comment: fmt.Sprintf("set comment to sequence: %q", seq.Name),
+		Reverse: b.Clone().P(quote(from)).String(),
+	}
+}
+
+func (s *state) seqIdent(seq *Sequence) string {
+	return s.typeIdent(seq.Schema, seq.Name)
+}
+
+// enumComment returns the comment of the given enum type, if exists.
+func enumComment(e *schema.EnumType) string {
+	var c schema.Comment
+	sqlx.Has(e.Attrs, &c)
+	return c.Text
+}
+
+// createExtension returns the CREATE EXTENSION statement of the given extension.
+func (s *state) createExtension(e *Extension, ifNotExists bool) string {
+	b := s.Build("CREATE EXTENSION")
+	if ifNotExists {
+		b.P("IF NOT EXISTS")
+	}
+	b.Ident(e.Name)
+	if e.Schema != "" {
+		b.P("WITH SCHEMA").Ident(e.Schema)
+	}
+	if e.Version != "" {
+		b.P("VERSION", quote(e.Version))
+	}
+	return b.String()
+}
+
+// alterExtension appends the ALTER EXTENSION statements for moving the extension from one state to the other.
+func (s *state) alterExtension(modify *schema.ModifyObject, from, to *Extension) {
+	if to.Version != "" && from.Version != to.Version {
+		update := func(e *Extension) string {
+			return s.Build("ALTER EXTENSION").Ident(e.Name).P("UPDATE TO", quote(e.Version)).String()
+		}
+		s.append(&migrate.Change{
+			Source:  modify,
+			Cmd:     update(to),
+			Reverse: update(from),
+			Comment: fmt.Sprintf("update extension %q to version %q", to.Name, to.Version),
+		})
+	}
+	if to.Schema != "" && from.Schema != to.Schema {
+		setSchema := func(e *Extension) string {
+			return s.Build("ALTER EXTENSION").Ident(e.Name).P("SET SCHEMA").Ident(e.Schema).String()
+		}
+		s.append(&migrate.Change{
+			Source:  modify,
+			Cmd:     setSchema(to),
+			Reverse: setSchema(from),
+			Comment: fmt.Sprintf("move extension %q to schema %q", to.Name, to.Schema),
+		})
+	}
+	if c1, c2 := extensionComment(from), extensionComment(to); c1 != c2 {
+		s.append(s.extensionComment(modify, to, c2, c1))
+	}
+}
+
+func (s *state) extensionComment(src schema.Change, e *Extension, to, from string) *migrate.Change {
+	b := s.Build("COMMENT ON EXTENSION").Ident(e.Name).P("IS")
+	return &migrate.Change{
+		Cmd:     b.Clone().P(quote(to)).String(),
+		Source:  src,
+		Comment: fmt.Sprintf("set comment to extension: %q", e.Name),
+		Reverse: b.Clone().P(quote(from)).String(),
+	}
+}
+
+// scheduleJob returns the statement for scheduling (or updating) the given cron job.
+func scheduleJob(j *CronJob) string {
+	return fmt.Sprintf("SELECT cron.schedule(%s, %s, %s)", quote(j.Name), quote(j.Schedule), quote(j.Command))
+}
+
+// unscheduleJob returns the statement for unscheduling the given cron job.
+func unscheduleJob(j *CronJob) string {
+	return fmt.Sprintf("SELECT cron.unschedule(%s)", quote(j.Name))
+}
+
+// addForeignServer appends the changes for creating the given foreign server.
+func (s *state) addForeignServer(src schema.Change, srv *ForeignServer) {
+	s.append(&migrate.Change{
+		Source:  src,
+		Cmd:     s.createForeignServer(srv),
+		Reverse: s.Build("DROP SERVER").Ident(srv.Name).String(),
+		Comment: fmt.Sprintf("create foreign server %q", srv.Name),
+	})
+	if c := foreignServerComment(srv); c != "" {
+		s.append(s.foreignServerComment(src, srv, c, ""))
+	}
+}
+
+// createForeignServer returns the CREATE SERVER statement of the given foreign server.
+func (s *state) createForeignServer(srv *ForeignServer) string {
+	b := s.Build("CREATE SERVER").Ident(srv.Name)
+	if srv.Type != "" {
+		b.P("TYPE", quote(srv.Type))
+	}
+	if srv.Version != "" {
+		b.P("VERSION", quote(srv.Version))
+	}
+	b.P("FOREIGN DATA WRAPPER").Ident(srv.Wrapper)
+	if len(srv.Options) > 0 {
+		s.alterOptions(b, nil, srv.Options)
+	}
+	return b.String()
+}
+
+// alterForeignServer appends the changes for moving the foreign server from one state to the other.
+// Changing the wrapper or the type of the server recreates it, along with the objects that use it.
+func (s *state) alterForeignServer(modify *schema.ModifyObject, from, to *ForeignServer) {
+	if from.Wrapper != to.Wrapper || from.Type != to.Type {
+		s.append(&migrate.Change{
+			Source:  modify,
+			Cmd:     s.Build("DROP SERVER").Ident(from.Name).P("CASCADE").String(),
+			Reverse: s.createForeignServer(from),
+			Comment: fmt.Sprintf("drop foreign server %q for recreation", from.Name),
+		})
+		s.addForeignServer(modify, to)
+		return
+	}
+	if to.Version != "" && from.Version != to.Version || !maps.Equal(from.Options, to.Options) {
+		cmd := func(from, to *ForeignServer) string {
+			b := s.Build("ALTER SERVER").Ident(to.Name)
+			if to.Version != "" && from.Version != to.Version {
+				b.P("VERSION", quote(to.Version))
+			}
+			return s.alterOptions(b, from.Options, to.Options)
+		}
+		s.append(&migrate.Change{
+			Source:  modify,
+			Cmd:     cmd(from, to),
+			Reverse: cmd(to, from),
+			Comment: fmt.Sprintf("modify foreign server %q", from.Name),
+		})
+	}
+	if c1, c2 := foreignServerComment(from), foreignServerComment(to); c1 != c2 {
+		s.append(s.foreignServerComment(modify, to, c2, c1))
+	}
+}
+
+func (s *state) foreignServerComment(src schema.Change, srv *ForeignServer, to, from string) *migrate.Change {
+	b := s.Build("COMMENT ON SERVER").Ident(srv.Name).P("IS")
+	return &migrate.Change{
+		Cmd:     b.Clone().P(quote(to)).String(),
+		Source:  src,
+		Comment: fmt.Sprintf("set comment to foreign server: %q", srv.Name),
+		Reverse: b.Clone().P(quote(from)).String(),
+	}
+}
+
+// addPublication appends the changes for creating the given publication.
+func (s *state) addPublication(src schema.Change, p *Publication) {
+	s.append(&migrate.Change{
+		Source:  src,
+		Cmd:     s.createPublication(p),
+		Reverse: s.Build("DROP PUBLICATION").Ident(p.Name).String(),
+		Comment: fmt.Sprintf("create publication %q", p.Name),
+	})
+	if c := publicationComment(p); c != "" {
+		s.append(s.publicationComment(src, p, c, ""))
+	}
+}
+
+// createPublication returns the CREATE PUBLICATION statement of the given publication.
+func (s *state) createPublication(p *Publication) string {
+	b := s.Build("CREATE PUBLICATION").Ident(p.Name)
+	switch {
+	case p.AllTables:
+		b.P("FOR ALL TABLES")
+	case len(p.Tables) > 0 || len(p.Schemas) > 0:
+		s.publicationMembers(b.P("FOR"), p, true)
+	}
+	if p.Publish != nil {
+		b.P("WITH").Wrap(func(b *sqlx.Builder) {
+			b.P("publish =", quote(publishOps(p)))
+		})
+	}
+	return b.String()
+}
+
+// publicationMembers writes the tables and the schemas of the publication. Column lists and
+// row filters are written only in full mode, as they are not accepted when members are dropped.
+func (s *state) publicationMembers(b *sqlx.Builder, p *Publication, full bool) {
+	if len(p.Tables) > 0 {
+		b.P("TABLE").MapComma(p.Tables, func(i int, b *sqlx.Builder) {
+			t := p.Tables[i]
+			b.Table(t.Table)
+			if !full {
+				return
+			}
+			if len(t.Columns) > 0 {
+				b.Wrap(func(b *sqlx.Builder) {
+					b.MapComma(t.Columns, func(i int, b *sqlx.Builder) {
+						b.Ident(t.Columns[i].Name)
+					})
+				})
+			}
+			if t.Where != "" {
+				b.P("WHERE", sqlx.MayWrap(t.Where))
+			}
+		})
+	}
+	if len(p.Schemas) > 0 {
+		if len(p.Tables) > 0 {
+			b.Comma()
+		}
+		b.P("TABLES IN SCHEMA").MapComma(p.Schemas, func(i int, b *sqlx.Builder) {
+			b.Ident(p.Schemas[i].Name)
+		})
+	}
+}
+
+// alterPublication appends the changes for moving the publication from one state to the
+// other. Switching from or to publishing all tables recreates the publication.
+func (s *state) alterPublication(modify *schema.ModifyObject, from, to *Publication) {
+	if from.AllTables != to.AllTables {
+		s.append(&migrate.Change{
+			Source:  modify,
+			Cmd:     s.Build("DROP PUBLICATION").Ident(from.Name).String(),
+			Reverse: s.createPublication(from),
+			Comment: fmt.Sprintf("drop publication %q for recreation", from.Name),
+		})
+		s.addPublication(modify, to)
+		return
+	}
+	if !publicationMembersEqual(from, to) {
+		s.append(&migrate.Change{
+			Source:  modify,
+			Cmd:     s.setPublicationMembers(from, to),
+			Reverse: s.setPublicationMembers(to, from),
+			Comment: fmt.Sprintf("modify the tables of publication %q", to.Name),
+		})
+	}
+	if o1, o2 := publishOps(from), publishOps(to); o1 != o2 {
+		b := s.Build("ALTER PUBLICATION").Ident(to.Name).P("SET")
+		s.append(&migrate.Change{
+			Source:  modify,
+			Cmd:     b.Clone().P(fmt.Sprintf("(publish = %s)", quote(o2))).String(),
+			Reverse: b.Clone().P(fmt.Sprintf("(publish = %s)", quote(o1))).String(),
+			Comment: fmt.Sprintf("modify the operations of publication %q", to.Name),
+		})
+	}
+	if c1, c2 := publicationComment(from), publicationComment(to); c1 != c2 {
+		s.append(s.publicationComment(modify, to, c2, c1))
+	}
+}
+
+// setPublicationMembers returns the statement for moving the members of the publication from
+// one state to the other. Since SET requires at least one member, removing all of them uses DROP.
+func (s *state) setPublicationMembers(from, to *Publication) string {
+	b := s.Build("ALTER PUBLICATION").Ident(to.Name)
+	if len(to.Tables) == 0 && len(to.Schemas) == 0 {
+		s.publicationMembers(b.P("DROP"), from, false)
+	} else {
+		s.publicationMembers(b.P("SET"), to, true)
+	}
+	return b.String()
+}
+
+func (s *state) publicationComment(src schema.Change, p *Publication, to, from string) *migrate.Change {
+	b := s.Build("COMMENT ON PUBLICATION").Ident(p.Name).P("IS")
+	return &migrate.Change{
+		Cmd:     b.Clone().P(quote(to)).String(),
+		Source:  src,
+		Comment: fmt.Sprintf("set comment to publication: %q", p.Name),
+		Reverse: b.Clone().P(quote(from)).String(),
+	}
+}
+
+// alterOptions writes the OPTIONS clause for moving the wrapper-specific options from one state
+// to the other, and returns the statement. Options are written in the order of their names.
+func (s *state) alterOptions(b *sqlx.Builder, from, to map[string]string) string {
+	var ops []func(*sqlx.Builder)
+	for _, k := range sortedKeys(from) {
+		if _, ok := to[k]; !ok {
+			ops = append(ops, func(b *sqlx.Builder) { b.P("DROP").Ident(k) })
+		}
+	}
+	for _, k := range sortedKeys(to) {
+		v, ok := from[k]
+		switch {
+		case from == nil:
+			ops = append(ops, func(b *sqlx.Builder) { b.Ident(k).P(quote(to[k])) })
+		case !ok:
+			ops = append(ops, func(b *sqlx.Builder) { b.P("ADD").Ident(k).P(quote(to[k])) })
+		case v != to[k]:
+			ops = append(ops, func(b *sqlx.Builder) { b.P("SET").Ident(k).P(quote(to[k])) })
+		}
+	}
+	if len(ops) > 0 {
+		b.P("OPTIONS").Wrap(func(b *sqlx.Builder) {
+			b.MapComma(ops, func(i int, b *sqlx.Builder) { ops[i](b) })
+		})
+	}
+	return b.String()
+}
+
+// sortedKeys returns the keys of the given options, sorted.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// userMappingFor returns the user and the server clause of the user mapping.
+func userMappingFor(u *UserMapping) string {
+	user := strconv.Quote(u.User)
+	if strings.EqualFold(u.User, "PUBLIC") {
+		user = "PUBLIC"
+	}
+	return fmt.Sprintf("%s SERVER %q", user, u.Server.Name)
+}
+
+// createUserMapping returns the CREATE USER MAPPING statement of the given user mapping.
+func (s *state) createUserMapping(u *UserMapping) string {
+	b := s.Build("CREATE USER MAPPING FOR").P(userMappingFor(u))
+	if len(u.Options) > 0 {
+		s.alterOptions(b, nil, u.Options)
+	}
+	return b.String()
+}
+
+// createRole returns the CREATE ROLE statement of the given role.
+func (s *state) createRole(r *Role) string {
+	b := s.Build("CREATE ROLE").Ident(r.Name)
+	if r.Login {
+		b.P("LOGIN")
+	}
+	return b.String()
+}
+
+// dropUserMapping returns the DROP USER MAPPING statement of the given user mapping.
+func (s *state) dropUserMapping(u *UserMapping) string {
+	return s.Build("DROP USER MAPPING FOR").P(userMappingFor(u)).String()
+}
+
+// addForeignTable appends the changes for creating the given foreign table.
+func (s *state) addForeignTable(src schema.Change, t *ForeignTable) error {
+	create, err := s.createForeignTable(t)
+	if err != nil {
+		return err
+	}
+	s.append(&migrate.Change{
+		Source:  src,
+		Cmd:     create,
+		Reverse: s.Build("DROP FOREIGN TABLE").P(s.foreignTableIdent(t)).String(),
+		Comment: fmt.Sprintf("create foreign table %q", t.Name),
+	})
+	if c := foreignTableComment(t); c != "" {
+		s.append(s.foreignTableComment(src, t, c, ""))
+	}
+	return nil
+}
+
+// createForeignTable returns the CREATE FOREIGN TABLE statement of the given foreign table.
+func (s *state) createForeignTable(t *ForeignTable) (string, error) {
+	b := s.Build("CREATE FOREIGN TABLE").P(s.foreignTableIdent(t))
+	if err := b.WrapErr(func(b *sqlx.Builder) error {
+		return b.MapCommaErr(t.Columns, func(i int, b *sqlx.Builder) error {
+			f, err := s.formatType(t.Columns[i].Type.Type)
+			if err != nil {
+				return fmt.Errorf("format type of column %q in foreign table %q: %w", t.Columns[i].Name, t.Name, err)
+			}
+			b.Ident(t.Columns[i].Name).P(f)
+			if !t.Columns[i].Type.Null {
+				b.P("NOT NULL")
+			}
+			return nil
+		})
+	}); err != nil {
+		return "", err
+	}
+	b.P("SERVER").Ident(t.Server.Name)
+	if len(t.Options) > 0 {
+		s.alterOptions(b, nil, t.Options)
+	}
+	return b.String(), nil
+}
+
+// alterForeignTable appends the changes for moving the foreign table from one state to the other.
+// Foreign tables do not hold local data, and therefore, they are recreated if their columns or
+// their server were changed.
+func (s *state) alterForeignTable(modify *schema.ModifyObject, from, to *ForeignTable) error {
+	if !foreignColumnsEqual(from, to) || from.Server.Name != to.Server.Name {
+		create, err := s.createForeignTable(from)
+		if err != nil {
+			return err
+		}
+		s.append(&migrate.Change{
+			Source:  modify,
+			Cmd:     s.Build("DROP FOREIGN TABLE").P(s.foreignTableIdent(from)).String(),
+			Reverse: create,
+			Comment: fmt.Sprintf("drop foreign table %q for recreation", from.Name),
+		})
+		return s.addForeignTable(modify, to)
+	}
+	if !maps.Equal(from.Options, to.Options) {
+		s.append(&migrate.Change{
+			Source:  modify,
+			Cmd:     s.alterOptions(s.Build("ALTER FOREIGN TABLE").P(s.foreignTableIdent(to)), from.Options, to.Options),
+			Reverse: s.alterOptions(s.Build("ALTER FOREIGN TABLE").P(s.foreignTableIdent(to)), to.Options, from.Options),
+			Comment: fmt.Sprintf("modify foreign table %q", from.Name),
+		})
+	}
+	if c1, c2 := foreignTableComment(from), foreignTableComment(to); c1 != c2 {
+		s.append(s.foreignTableComment(modify, to, c2, c1))
+	}
+	return nil
+}
+
+func (s *state) foreignTableComment(src schema.Change, t *ForeignTable, to, from string) *migrate.Change {
+	b := s.Build("COMMENT ON FOREIGN TABLE").P(s.foreignTableIdent(t)).P("IS")
+	return &migrate.Change{
+		Cmd:     b.Clone().P(quote(to)).String(),
+		Source:  src,
+		Comment: fmt.Sprintf("set comment to foreign table: %q", t.Name),
+		Reverse: b.Clone().P(quote(from)).String(),
+	}
+}
+
+func (s *state) foreignTableIdent(t *ForeignTable) string {
+	return s.typeIdent(t.Schema, t.Name)
+}
+
+// addEventTrigger appends the changes for creating the given event trigger.
+func (s *state) addEventTrigger(src schema.Change, e *EventTrigger) {
+	s.append(&migrate.Change{
+		Source:  src,
+		Cmd:     s.createEventTrigger(e),
+		Reverse: s.Build("DROP EVENT TRIGGER").Ident(e.Name).String(),
+		Comment: fmt.Sprintf("create event trigger %q", e.Name),
+	})
+	// Event triggers are created enabled.
+	if v := eventTriggerState(e); v != "" {
+		s.append(s.eventTriggerState(src, e, v, ""))
+	}
+	if c := eventTriggerComment(e); c != "" {
+		s.append(s.eventTriggerComment(src, e, c, ""))
+	}
+}
+
+// createEventTrigger returns the CREATE EVENT TRIGGER statement of the given event trigger.
+func (s *state) createEventTrigger(e *EventTrigger) string {
+	b := s.Build("CREATE EVENT TRIGGER").Ident(e.Name).P("ON", e.Event)
+	if len(e.Tags) > 0 {
+		b.P("WHEN TAG IN").Wrap(func(b *sqlx.Builder) {
+			b.MapComma(e.Tags, func(i int, b *sqlx.Builder) {
+				b.WriteString(quote(e.Tags[i]))
+			})
+		})
+	}
+	// The FUNCTION keyword was added in version 11, and PROCEDURE is still accepted.
+	if s.conn.version >= 11_00_00 {
+		b.P("EXECUTE FUNCTION")
+	} else {
+		b.P("EXECUTE PROCEDURE")
+	}
+	return b.FuncCall(e.Func).String()
+}
+
+// alterEventTrigger appends the changes for moving the event trigger from one state
+// to the other. Changing the event, the tags or the function recreates the trigger.
+func (s *state) alterEventTrigger(modify *schema.ModifyObject, from, to *EventTrigger) {
+	if !eventTriggerEqual(from, to) {
+		s.append(&migrate.Change{
+			Source:  modify,
+			Cmd:     s.Build("DROP EVENT TRIGGER").Ident(from.Name).String(),
+			Reverse: s.createEventTrigger(from),
+			Comment: fmt.Sprintf("drop event trigger %q for recreation", from.Name),
+		})
+		s.addEventTrigger(modify, to)
+		return
+	}
+	if v1, v2 := eventTriggerState(from), eventTriggerState(to); v1 != v2 {
+		s.append(s.eventTriggerState(modify, to, v2, v1))
+	}
+	if c1, c2 := eventTriggerComment(from), eventTriggerComment(to); c1 != c2 {
+		s.append(s.eventTriggerComment(modify, to, c2, c1))
+	}
+}
+
+// eventTriggerState returns the change for moving the firing state of the event trigger from one value to the other.
+func (s *state) eventTriggerState(src schema.Change, e *EventTrigger, to, from string) *migrate.Change {
+	cmd := func(v string) string {
+		b := s.Build("ALTER EVENT TRIGGER").Ident(e.Name)
+		switch v {
+		case TriggerStateDisabled:
+			b.P("DISABLE")
+		case TriggerStateReplica:
+			b.P("ENABLE REPLICA")
+		case TriggerStateAlways:
+			b.P("ENABLE ALWAYS")
+		default:
+			b.P("ENABLE")
+		}
+		return b.String()
+	}
+	return &migrate.Change{
+		Source:  src,
+		Cmd:     cmd(to),
+		Reverse: cmd(from),
+		Comment: fmt.Sprintf("set the state of event trigger %q", e.Name),
+	}
+}
+
+func (s *state) eventTriggerComment(src schema.Change, e *EventTrigger, to, from string) *migrate.Change {
+	b := s.Build("COMMENT ON EVENT TRIGGER").Ident(e.Name).P("IS")
+	return &migrate.Change{
+		Cmd:     b.Clone().P(quote(to)).String(),
+		Source:  src,
+		Comment: fmt.Sprintf("set comment to event trigger: %q", e.Name),
+		Reverse: b.Clone().P(quote(from)).String(),
+	}
+}
+
+func (s *state) addTrigger(add *schema.AddTrigger) error {
+	if err := checkTrigger(add.T); err != nil {
+		return err
+	}
+	s.append(&migrate.Change{
+		Source:  add,
+		Cmd:     s.createTrigger(add.T, false),
+		Reverse: s.dropTriggerCmd(add.T),
+		Comment: fmt.Sprintf("create trigger %q", add.T.Name),
+	})
+	if v := triggerState(add.T); v != "" {
+		s.append(s.triggerStateChange(add, add.T, "", v))
+	}
+	return nil
+}
+
+func (s *state) dropTrigger(drop *schema.DropTrigger) error {
+	if err := checkTrigger(drop.T); err != nil {
+		return err
+	}
+	s.append(&migrate.Change{
+		Source:  drop,
+		Cmd:     s.dropTriggerCmd(drop.T),
+		Reverse: s.createTrigger(drop.T, false),
+		Comment: fmt.Sprintf("drop trigger %q", drop.T.Name),
+	})
+	return nil
+}
+
+func (s *state) renameTrigger(rename *schema.RenameTrigger) error {
+	if err := checkTrigger(rename.From); err != nil {
+		return err
+	}
+	cmd := func(from, to *schema.Trigger) string {
+		return triggerOn(s.Build("ALTER TRIGGER").Ident(from.Name).P("ON"), from).P("RENAME TO").Ident(to.Name).String()
+	}
+	s.append(&migrate.Change{
+		Source:  rename,
+		Cmd:     cmd(rename.From, rename.To),
+		Reverse: cmd(rename.To, rename.From),
+		Comment: fmt.Sprintf("rename a trigger from %q to %q", rename.From.Name, rename.To.Name),
+	})
+	return nil
+}
+
+func (s *state) modifyTrigger(modify *schema.ModifyTrigger) error {
+	if err := checkTrigger(modify.To); err != nil {
+		return err
+	}
+	from, to := triggerState(modify.From), triggerState(modify.To)
+	if !triggerDefEqual(modify.From, modify.To) {
+		// Servers prior to version 14 do not support the CREATE OR REPLACE TRIGGER command.
+		if s.conn.version >= 14_00_00 {
+			s.append(&migrate.Change{
+				Source:  modify,
+				Cmd:     s.createTrigger(modify.To, true),
+				Reverse: s.createTrigger(modify.From, true),
+				Comment: fmt.Sprintf("modify trigger %q", modify.To.Name),
+			})
+		} else {
+			s.append(
+				&migrate.Change{
+					Source:  modify,
+					Cmd:     s.dropTriggerCmd(modify.From),
+					Reverse: s.createTrigger(modify.From, false),
+					Comment: fmt.Sprintf("drop trigger %q", modify.From.Name),
+				},
+				&migrate.Change{
+					Source:  modify,
+					Cmd:     s.createTrigger(modify.To, false),
+					Reverse: s.dropTriggerCmd(modify.To),
+					Comment: fmt.Sprintf("create trigger %q", modify.To.Name),
+				},
+			)
+		}
+		// A (re)created trigger is enabled.
+		from = ""
+	}
+	if from != to {
+		s.append(s.triggerStateChange(modify, modify.To, from, to))
+	}
+	return nil
+}
+
+// createTrigger returns the CREATE TRIGGER statement of the given trigger.
+func (s *state) createTrigger(t *schema.Trigger, replace bool) string {
+	b := s.Build("CREATE")
+	if replace {
+		b.P("OR REPLACE")
+	}
+	b.P("TRIGGER").Ident(t.Name).P(string(t.ActionTime))
+	for i, e := range t.Events {
+		if i > 0 {
+			b.P("OR")
+		}
+		b.P(e.Name)
+		if len(e.Columns) > 0 {
+			b.MapComma(e.Columns, func(i int, b *sqlx.Builder) {
+				b.Ident(e.Columns[i].Name)
+			})
+		}
+	}
+	triggerOn(b.P("ON"), t).P("FOR EACH", string(t.For))
+	if w := (TriggerWhen{}); sqlx.Has(t.Attrs, &w) && w.X != "" {
+		b.P("WHEN", sqlx.MayWrap(w.X))
+	}
+	// The FUNCTION keyword was added in version 11, and PROCEDURE is still accepted.
+	if s.conn.version >= 11_00_00 {
+		b.P("EXECUTE FUNCTION", t.Body)
+	} else {
+		b.P("EXECUTE PROCEDURE", t.Body)
+	}
+	return b.String()
+}
+
+// dropTriggerCmd returns the DROP TRIGGER statement of the given trigger.
+func (s *state) dropTriggerCmd(t *schema.Trigger) string {
+	return triggerOn(s.Build("DROP TRIGGER").Ident(t.Name).P("ON"), t).String()
+}
+
+// triggerStateChange returns the change for moving the firing state of the trigger from one value to the other.
+func (s *state) triggerStateChange(source schema.Change, t *schema.Trigger, from, to string) *migrate.Change {
+	cmd := func(v string) string {
+		b := s.Build("ALTER TABLE").Table(t.Table)
+		switch v {
+		case TriggerStateDisabled:
+			b.P("DISABLE TRIGGER")
+		case TriggerStateReplica:
+			b.P("ENABLE REPLICA TRIGGER")
+		case TriggerStateAlways:
+			b.P("ENABLE ALWAYS TRIGGER")
+		default:
+			b.P("ENABLE TRIGGER")
+		}
+		return b.Ident(t.Name).String()
+	}
+	return &migrate.Change{
+		Source:  source,
+		Cmd:     cmd(to),
+		Reverse: cmd(from),
+		Comment: fmt.Sprintf("set the state of trigger %q", t.Name),
+	}
+}
+
+// triggerOn writes the table or the view that the trigger is defined on.
+func triggerOn(b *sqlx.Builder, t *schema.Trigger) *sqlx.Builder {
+	if t.Table != nil {
+		return b.Table(t.Table)
+	}
+	return b.View(t.View)
+}
+
+// triggerState returns the firing state of the trigger, or an empty string if it is enabled.
+func triggerState(t *schema.Trigger) string {
+	if s := (TriggerState{}); sqlx.Has(t.Attrs, &s) {
+		return s.V
+	}
+	return ""
+}
+
+func (s *state) addFunc(add *schema.AddFunc) error {
+	create, err := s.createFunc(add.F, false)
+	if err != nil {
+		return err
+	}
+	sig, err := s.funcSig(add.F.Name, add.F.Schema, add.F.Args)
+	if err != nil {
+		return err
+	}
+	s.append(&migrate.Change{
+		Source:  add,
+		Cmd:     create,
+		Reverse: "DROP FUNCTION " + sig,
+		Comment: fmt.Sprintf("create function %q", add.F.Name),
+	})
+	if c := funcComment(add.F.Attrs); c != "" {
+		s.append(s.funcCommentChange(add, "FUNCTION", sig, c, ""))
+	}
+	return nil
+}
+
+func (s *state) dropFunc(drop *schema.DropFunc) error {
+	create, err := s.createFunc(drop.F, false)
+	if err != nil {
+		return err
+	}
+	sig, err := s.funcSig(drop.F.Name, drop.F.Schema, drop.F.Args)
+	if err != nil {
+		return err
+	}
+	s.append(&migrate.Change{
+		Source:  drop,
+		Cmd:     "DROP FUNCTION " + sig,
+		Reverse: create,
+		Comment: fmt.Sprintf("drop function %q", drop.F.Name),
+	})
+	return nil
+}
+
+func (s *state) modifyFunc(modify *schema.ModifyFunc) error {
+	from, to := modify.From, modify.To
+	fromSig, err := s.funcSig(from.Name, from.Schema, from.Args)
+	if err != nil {
+		return err
+	}
+	toSig, err := s.funcSig(to.Name, to.Schema, to.Args)
+	if err != nil {
+		return err
+	}
+	if !sameType(from.Ret, to.Ret) || !sameFuncArgs(from.Args, to.Args) || !s.sameRoutine(from.Args, to.Args, from.Lang, to.Lang, from.Body, to.Body, from.Attrs, to.Attrs) {
+		// The return type and the names of the arguments cannot
+		// be changed by the CREATE OR REPLACE FUNCTION command.
+		replace := sameType(from.Ret, to.Ret) && sameFuncArgs(from.Args, to.Args)
+		createF, err := s.createFunc(from, replace)
+		if err != nil {
+			return err
+		}
+		createT, err := s.createFunc(to, replace)
+		if err != nil {
+			return err
+		}
+		if replace {
+			s.append(&migrate.Change{
+				Source:  modify,
+				Cmd:     createT,
+				Reverse: createF,
+				Comment: fmt.Sprintf("modify function %q", to.Name),
+			})
+		} else {
+			s.append(
+				&migrate.Change{
+					Source:  modify,
+					Cmd:     "DROP FUNCTION " + fromSig,
+					Reverse: createF,
+					Comment: fmt.Sprintf("drop function %q", from.Name),
+				},
+				&migrate.Change{
+					Source:  modify,
+					Cmd:     createT,
+					Reverse: "DROP FUNCTION " + toSig,
+					Comment: fmt.Sprintf("create function %q", to.Name),
+				},
+			)
+		}
+	}
+	if c1, c2 := funcComment(from.Attrs), funcComment(to.Attrs); c1 != c2 {
+		s.append(s.funcCommentChange(modify, "FUNCTION", toSig, c2, c1))
+	}
+	return nil
+}
+
+func (s *state) addProc(add *schema.AddProc) error {
+	create, err := s.createProc(add.P, false)
+	if err != nil {
+		return err
+	}
+	sig, err := s.funcSig(add.P.Name, add.P.Schema, add.P.Args)
+	if err != nil {
+		return err
+	}
+	s.append(&migrate.Change{
+		Source:  add,
+		Cmd:     create,
+		Reverse: "DROP PROCEDURE " + sig,
+		Comment: fmt.Sprintf("create procedure %q", add.P.Name),
+	})
+	if c := funcComment(add.P.Attrs); c != "" {
+		s.append(s.funcCommentChange(add, "PROCEDURE", sig, c, ""))
+	}
+	return nil
+}
+
+func (s *state) dropProc(drop *schema.DropProc) error {
+	create, err := s.createProc(drop.P, false)
+	if err != nil {
+		return err
+	}
+	sig, err := s.funcSig(drop.P.Name, drop.P.Schema, drop.P.Args)
+	if err != nil {
+		return err
+	}
+	s.append(&migrate.Change{
+		Source:  drop,
+		Cmd:     "DROP PROCEDURE " + sig,
+		Reverse: create,
+		Comment: fmt.Sprintf("drop procedure %q", drop.P.Name),
+	})
+	return nil
+}
+
+func (s *state) modifyProc(modify *schema.ModifyProc) error {
+	from, to := modify.From, modify.To
+	fromSig, err := s.funcSig(from.Name, from.Schema, from.Args)
+	if err != nil {
+		return err
+	}
+	toSig, err := s.funcSig(to.Name, to.Schema, to.Args)
+	if err != nil {
+		return err
+	}
+	if !sameFuncArgs(from.Args, to.Args) || !s.sameRoutine(from.Args, to.Args, from.Lang, to.Lang, from.Body, to.Body, from.Attrs, to.Attrs) {
+		replace := sameFuncArgs(from.Args, to.Args)
+		createF, err := s.createProc(from, replace)
+		if err != nil {
+			return err
+		}
+		createT, err := s.createProc(to, replace)
+		if err != nil {
+			return err
+		}
+		if replace {
+			s.append(&migrate.Change{
+				Source:  modify,
+				Cmd:     createT,
+				Reverse: createF,
+				Comment: fmt.Sprintf("modify procedure %q", to.Name),
+			})
+		} else {
+			s.append(
+				&migrate.Change{
+					Source:  modify,
+					Cmd:     "DROP PROCEDURE " + fromSig,
+					Reverse: createF,
+					Comment: fmt.Sprintf("drop procedure %q", from.Name),
+				},
+				&migrate.Change{
+					Source:  modify,
+					Cmd:     createT,
+					Reverse: "DROP PROCEDURE " + toSig,
+					Comment: fmt.Sprintf("create procedure %q", to.Name),
+				},
+			)
+		}
+	}
+	if c1, c2 := funcComment(from.Attrs), funcComment(to.Attrs); c1 != c2 {
+		s.append(s.funcCommentChange(modify, "PROCEDURE", toSig, c2, c1))
+	}
+	return nil
+}
+
+// createFunc returns the CREATE FUNCTION statement of the given function.
+func (s *state) createFunc(f *schema.Func, replace bool) (string, error) {
+	if f.Ret == nil {
+		return "", fmt.Errorf("postgres: missing return type for function %q", f.Name)
+	}
+	b := s.Build("CREATE")
+	if replace {
+		b.P("OR REPLACE")
+	}
+	b.P("FUNCTION").Func(f)
+	if err := s.funcArgs(b, f.Args); err != nil {
+		return "", fmt.Errorf("postgres: arguments of function %q: %w", f.Name, err)
+	}
+	ret, err := s.formatType(f.Ret)
+	if err != nil {
+		return "", fmt.Errorf("postgres: return type of function %q: %w", f.Name, err)
+	}
+	b.P("RETURNS", ret)
+	s.routineBody(b, f.Lang, f.Body, f.Attrs)
+	return b.String(), nil
+}
+
+// createProc returns the CREATE PROCEDURE statement of the given procedure.
+func (s *state) createProc(p *schema.Proc, replace bool) (string, error) {
+	b := s.Build("CREATE")
+	if replace {
+		b.P("OR REPLACE")
+	}
+	b.P("PROCEDURE").Proc(p)
+	if err := s.funcArgs(b, p.Args); err != nil {
+		return "", fmt.Errorf("postgres: arguments of procedure %q: %w", p.Name, err)
+	}
+	s.routineBody(b, p.Lang, p.Body, nil)
+	return b.String(), nil
+}
+
+// funcArgs writes the arguments list of a function or a procedure.
+func (s *state) funcArgs(b *sqlx.Builder, args []*schema.FuncArg) error {
+	return b.WrapErr(func(b *sqlx.Builder) error {
+		return b.MapCommaErr(args, func(i int, b *sqlx.Builder) error {
+			a := args[i]
+			if a.Mode != "" && a.Mode != schema.FuncArgModeIn {
+				b.P(string(a.Mode))
+			}
+			if a.Name != "" {
+				b.Ident(a.Name)
+			}
+			t, err := s.formatType(a.Type)
+			if err != nil {
+				return err
+			}
+			b.P(t)
+			if a.Default != nil {
+				s.formatDefault(b, a.Type, a.Default)
+			}
+			return nil
+		})
+	})
+}
+
+// routineBody writes the language, the volatility and the body of a function or a procedure.
+func (s *state) routineBody(b *sqlx.Builder, lang, body string, attrs []schema.Attr) {
+	if lang == "" {
+		lang = "sql"
+	}
+	b.P("LANGUAGE", strings.ToLower(lang))
+	if v := funcVolatility(attrs); v != VolatilityVolatile {
+		b.P(v)
+	}
+	// Bodies of indented plans are written in their own lines.
+	if b.Indent != "" {
+		if p := (&sqlx.Pretty{Indent: b.Indent, DollarQuote: true}).Format(body); strings.Contains(p, "\n") {
+			body = "\n" + p + "\n"
+		}
+	}
+	b.P("AS", dollarQuote(body))
+}
+
+// sameRoutine reports if the arguments and the body of two functions (or procedures) are the same.
+func (s *state) sameRoutine(fromA, toA []*schema.FuncArg, fromL, toL, fromB, toB string, fromX, toX []schema.Attr) bool {
+	x1, x2 := slices.DeleteFunc(slices.Clone(fromX), isComment), slices.DeleteFunc(slices.Clone(toX), isComment)
+	changed, err := (&diff{conn: s.conn}).routineChanged(fromA, toA, fromL, toL, fromB, toB, x1, x2)
+	return err == nil && !changed
+}
+
+// isComment reports if the attribute is a comment.
+func isComment(a schema.Attr) bool {
+	_, ok := a.(*schema.Comment)
+	return ok
+}
+
+// sameFuncArgs reports if the arguments of two functions (or procedures) have the same names,
+// modes and types, and that no default value was removed. Only these functions can be replaced.
+func sameFuncArgs(from, to []*schema.FuncArg) bool {
+	return slices.EqualFunc(from, to, func(a1, a2 *schema.FuncArg) bool {
+		return a1.Name == a2.Name && funcArgMode(a1) == funcArgMode(a2) && sameType(a1.Type, a2.Type) && (a1.Default == nil || a2.Default != nil)
+	})
+}
+
+// funcSig returns the signature of a function (or a procedure) that identifies it,
+// i.e., its name and the types of its input arguments.
+func (s *state) funcSig(name string, ns *schema.Schema, args []*schema.FuncArg) (string, error) {
+	b := s.Build().Func(&schema.Func{Name: name, Schema: ns})
+	in := funcInputArgs(args)
+	if err := b.WrapErr(func(b *sqlx.Builder) error {
+		return b.MapCommaErr(in, func(i int, b *sqlx.Builder) error {
+			t, err := s.formatType(in[i].Type)
+			if err != nil {
+				return fmt.Errorf("postgres: format type of argument %d of %q: %w", i+1, name, err)
+			}
+			b.P(t)
+			return nil
+		})
+	}); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// funcCommentChange returns the COMMENT ON change for a function or a procedure.
+func (s *state) funcCommentChange(source schema.Change, kind, sig, to, from string) *migrate.Change {
+	return &migrate.Change{
+		Source:  source,
+		Cmd:     fmt.Sprintf("COMMENT ON %s %s IS %s", kind, sig, quote(to)),
+		Reverse: fmt.Sprintf("COMMENT ON %s %s IS %s", kind, sig, quote(from)),
+		Comment: fmt.Sprintf("set comment to %s", strings.ToLower(kind)),
+	}
+}
+
+// pretty is the pretty-printer of function and procedure bodies.
+var pretty = &sqlx.Pretty{DollarQuote: true}
+
+// dollarQuote returns the body wrapped with dollar quotes. The tag is
+// extended in case the body contains the default ($$) delimiter.
+func dollarQuote(body string) string {
+	tag := "$$"
+	for i := 0; strings.Contains(body, tag); i++ {
+		tag = fmt.Sprintf("$body%d$", i)
+	}
+	return tag + body + tag
+}
+
+// checkTrigger reports an error if the trigger cannot be planned.
+func checkTrigger(t *schema.Trigger) error {
+	switch {
+	case t.Table == nil && t.View == nil:
+		return fmt.Errorf("postgres: missing table or view for trigger %q", t.Name)
+	case t.Table == nil && triggerState(t) != "":
+		return fmt.Errorf("postgres: state of trigger %q can be set only for tables", t.Name)
+	case len(t.Events) == 0:
+		return fmt.Errorf("postgres: missing events for trigger %q", t.Name)
+	case t.Body == "":
+		return fmt.Errorf("postgres: missing function call for trigger %q", t.Name)
+	}
+	return nil
+}
+
+func (*diff) ViewAttrChanges(_, _ *schema.View) []schema.Change {
+	return nil // unimplemented.
+}
+
+// RealmObjectDiff returns a changeset for migrating realm (database) objects
+// from one state to the other. For example, adding extensions or users.
+func (*diff) RealmObjectDiff(from, to *schema.Realm) ([]schema.Change, error) {
+	var changes []schema.Change
+	// Drop or modify cron jobs, extensions, foreign servers, user mappings, event triggers and publications.
+	for _, o1 := range from.Objects {
+		switch o1 := o1.(type) {
+		case *CronJob:
+			j2, ok := findCronJob(to, o1.Name)
+			switch {
+			case !ok:
+				changes = append(changes, &schema.DropObject{O: o1})
+			case o1.Schedule != j2.Schedule || strings.TrimSpace(o1.Command) != strings.TrimSpace(j2.Command):
+				changes = append(changes, &schema.ModifyObject{From: o1, To: j2})
+			}
+		case *Extension:
+			e2, ok := findExtension(to, o1.Name)
+			switch {
+			case !ok:
+				changes = append(changes, &schema.DropObject{O: o1})
+			case extensionChanged(o1, e2):
+				changes = append(changes, &schema.ModifyObject{From: o1, To: e2})
+			}
+		case *ForeignServer:
+			s2, ok := findForeignServer(to, o1.Name)
+			switch {
+			case !ok:
+				changes = append(changes, &schema.DropObject{O: o1})
+			case foreignServerChanged(o1, s2):
+				changes = append(changes, &schema.ModifyObject{From: o1, To: s2})
+			}
+		case *UserMapping:
+			u2, ok := findUserMapping(to, o1.User, o1.Server.Name)
+			switch {
+			case !ok:
+				changes = append(changes, &schema.DropObject{O: o1})
+			case !maps.Equal(o1.Options, u2.Options):
+				changes = append(changes, &schema.ModifyObject{From: o1, To: u2})
+			}
+		case *EventTrigger:
+			e2, ok := findEventTrigger(to, o1.Name)
+			switch {
+			case !ok:
+				changes = append(changes, &schema.DropObject{O: o1})
+			case !eventTriggerEqual(o1, e2) || eventTriggerState(o1) != eventTriggerState(e2) || eventTriggerComment(o1) != eventTriggerComment(e2):
+				changes = append(changes, &schema.ModifyObject{From: o1, To: e2})
+			}
+		case *Publication:
+			p2, ok := findPublication(to, o1.Name)
+			switch {
+			case !ok:
+				changes = append(changes, &schema.DropObject{O: o1})
+			case publicationChanged(o1, p2):
+				changes = append(changes, &schema.ModifyObject{From: o1, To: p2})
+			}
+		}
+	}
+	// Add new cron jobs, extensions, foreign servers, user mappings, event triggers and publications.
+	for _, o1 := range to.Objects {
+		switch o1 := o1.(type) {
+		case *CronJob:
+			if _, ok := findCronJob(from, o1.Name); !ok {
+				changes = append(changes, &schema.AddObject{O: o1})
+			}
+		case *Extension:
+			if _, ok := findExtension(from, o1.Name); !ok {
+				changes = append(changes, &schema.AddObject{O: o1})
+			}
+		case *ForeignServer:
+			if _, ok := findForeignServer(from, o1.Name); !ok {
+				changes = append(changes, &schema.AddObject{O: o1})
+			}
+		case *UserMapping:
+			if _, ok := findUserMapping(from, o1.User, o1.Server.Name); !ok {
+				changes = append(changes, &schema.AddObject{O: o1})
+			}
+		case *EventTrigger:
+			if _, ok := findEventTrigger(from, o1.Name); !ok {
+				changes = append(changes, &schema.AddObject{O: o1})
+			}
+		case *Publication:
+			if _, ok := findPublication(from, o1.Name); !ok {
+				changes = append(changes, &schema.AddObject{O: o1})
+			}
+		}
+	}
+	return changes, nil
+}
+
+// findExtension returns the extension with the given name from the realm, if exists.
+func findExtension(r *schema.Realm, name string) (*Extension, bool) {
+	o, ok := r.Object(func(o schema.Object) bool {
+		e, ok := o.(*Extension)
+		return ok && e.Name == name
+	})
+	if !ok {
+		return nil, false
+	}
+	return o.(*Extension), true
+}
+
+// extensionChanged reports if the extension was changed. An empty version
+// or schema in the desired state means the database default is accepted.
+func extensionChanged(from, to *Extension) bool {
+	return to.Version != "" && from.Version != to.Version ||
+		to.Schema != "" && from.Schema != to.Schema ||
+		extensionComment(from) != extensionComment(to)
+}
+
+// extensionComment returns the comment of the extension, if exists.
+func extensionComment(e *Extension) string {
+	var c schema.Comment
+	sqlx.Has(e.Attrs, &c)
+	return c.Text
+}
+
+// DependsOn implements the sqlx.Depender interface. Extensions are
+// dropped after the objects that might use their types or functions.
+func (*Extension) DependsOn(change, other schema.Change) bool {
+	if _, ok := change.(*schema.DropObject); !ok {
+		return false
+	}
+	switch o := other.(type) {
+	case *schema.DropTable, *schema.DropView, *schema.DropFunc, *schema.DropProc:
+		return true
+	case *schema.DropObject:
+		_, ok := o.O.(*Extension)
+		return !ok
+	}
+	return false
+}
+
+// DependencyOf implements the sqlx.Depender interface. Extensions are
+// created before the objects that might use their types or functions.
+func (*Extension) DependencyOf(change, other schema.Change) bool {
+	if _, ok := change.(*schema.AddObject); !ok {
+		return false
+	}
+	switch o := other.(type) {
+	case *schema.AddTable, *schema.ModifyTable, *schema.AddView, *schema.AddFunc, *schema.AddProc:
+		return true
+	case *schema.AddObject:
+		_, ok := o.O.(*Extension)
+		return !ok
+	}
+	return false
+}
+
+// findForeignServer returns the foreign server with the given name from the realm, if exists.
+func findForeignServer(r *schema.Realm, name string) (*ForeignServer, bool) {
+	o, ok := r.Object(func(o schema.Object) bool {
+		s, ok := o.(*ForeignServer)
+		return ok && s.Name == name
+	})
+	if !ok {
+		return nil, false
+	}
+	return o.(*ForeignServer), true
+}
+
+// foreignServerChanged reports if the foreign server was changed. An empty
+// version in the desired state means the current version is accepted.
+func foreignServerChanged(from, to *ForeignServer) bool {
+	return from.Wrapper != to.Wrapper || from.Type != to.Type ||
+		to.Version != "" && from.Version != to.Version ||
+		!maps.Equal(from.Options, to.Options) ||
+		foreignServerComment(from) != foreignServerComment(to)
+}
+
+// foreignServerComment returns the comment of the foreign server, if exists.
+func foreignServerComment(s *ForeignServer) string {
+	var c schema.Comment
+	sqlx.Has(s.Attrs, &c)
+	return c.Text
+}
+
+// findUserMapping returns the user mapping of the given user and server from the realm, if exists.
+func findUserMapping(r *schema.Realm, user, server string) (*UserMapping, bool) {
+	o, ok := r.Object(func(o schema.Object) bool {
+		u, ok := o.(*UserMapping)
+		return ok && u.Server.Name == server &&
+			(u.User == user || strings.EqualFold(u.User, "PUBLIC") && strings.EqualFold(user, "PUBLIC"))
+	})
+	if !ok {
+		return nil, false
+	}
+	return o.(*UserMapping), true
+}
+
+// usesServer reports if the change creates or modifies an object that uses the
+// given server, or drops it, in case the drop argument is true.
+func usesServer(srv string, c schema.Change, drop bool) bool {
+	var o schema.Object
+	switch c := c.(type) {
+	case *schema.AddObject:
+		o = c.O
+	case *schema.ModifyObject:
+		o = c.To
+	case *schema.DropObject:
+		o = c.O
+	}
+	if _, ok := c.(*schema.DropObject); ok != drop {
+		return false
+	}
+	switch o := o.(type) {
+	case *UserMapping:
+		return o.Server.Name == srv
+	case *ForeignTable:
+		return o.Server.Name == srv
+	}
+	return false
+}
+
+// DependencyOf implements the sqlx.Depender interface. Foreign servers are
+// created before the user mappings and the foreign tables that use them.
+func (s *ForeignServer) DependencyOf(change, other schema.Change) bool {
+	switch change.(type) {
+	case *schema.AddObject, *schema.ModifyObject:
+		return usesServer(s.Name, other, false)
+	}
+	return false
+}
+
+// DependsOn implements the sqlx.Depender interface. Foreign servers are
+// dropped after the user mappings and the foreign tables that use them.
+func (s *ForeignServer) DependsOn(change, other schema.Change) bool {
+	if _, ok := change.(*schema.DropObject); !ok {
+		return false
+	}
+	return usesServer(s.Name, other, true)
+}
+
+// findPublication returns the publication with the given name from the realm, if exists.
+func findPublication(r *schema.Realm, name string) (*Publication, bool) {
+	o, ok := r.Object(func(o schema.Object) bool {
+		p, ok := o.(*Publication)
+		return ok && p.Name == name
+	})
+	if !ok {
+		return nil, false
+	}
+	return o.(*Publication), true
+}
+
+// publicationChanged reports if the publication was changed.
+func publicationChanged(from, to *Publication) bool {
+	return from.AllTables != to.AllTables || !publicationMembersEqual(from, to) ||
+		publishOps(from) != publishOps(to) || publicationComment(from) != publicationComment(to)
+}
+
+// publicationMembersEqual reports if the two publications publish the same tables, with the
+// same column lists and row filters, and the same schemas. The order of members is ignored.
+func publicationMembersEqual(p1, p2 *Publication) bool {
+	if len(p1.Tables) != len(p2.Tables) || len(p1.Schemas) != len(p2.Schemas) {
+		return false
+	}
+	for _, t1 := range p1.Tables {
+		t2, ok := p2.table(t1.Table)
+		if !ok || len(t1.Columns) != len(t2.Columns) || t1.Where != t2.Where && sqlx.MayWrap(t1.Where) != sqlx.MayWrap(t2.Where) {
+			return false
+		}
+		for _, c := range t1.Columns {
+			if !publishesColumn(t2, c.Name) {
+				return false
+			}
+		}
+	}
+	for _, s1 := range p1.Schemas {
+		if !slices.ContainsFunc(p2.Schemas, func(s2 *schema.Schema) bool { return s1.Name == s2.Name }) {
+			return false
+		}
+	}
+	return true
+}
+
+// publishOps returns the operations published by the publication, as written in the publish option.
+func publishOps(p *Publication) string {
+	if p.Publish == nil {
+		return strings.Join(PublicationOps, ", ")
+	}
+	return strings.Join(p.Publish, ", ")
+}
+
+// publicationComment returns the comment of the publication, if exists.
+func publicationComment(p *Publication) string {
+	var c schema.Comment
+	sqlx.Has(p.Attrs, &c)
+	return c.Text
+}
+
+// table returns the published entry of the given table, if exists.
+func (p *Publication) table(t *schema.Table) (*PublicationTable, bool) {
+	for _, pt := range p.Tables {
+		if pt.Table.Name == t.Name && sqlx.V(pt.Table.Schema).Name == sqlx.V(t.Schema).Name {
+			return pt, true
+		}
+	}
+	return nil, false
+}
+
+// publishesSchema reports if the publication publishes the tables of the given schema.
+func (p *Publication) publishesSchema(s *schema.Schema) bool {
+	return slices.ContainsFunc(p.Schemas, func(s1 *schema.Schema) bool { return s1.Name == s.Name })
+}
+
+// publishesColumn reports if the column is listed explicitly in the published table.
+func publishesColumn(t *PublicationTable, name string) bool {
+	return slices.ContainsFunc(t.Columns, func(c *schema.Column) bool { return c.Name == name })
+}
+
+// DependsOn implements the sqlx.Depender interface. Publications are created (or
+// modified) after the tables, columns and schemas they publish are created.
+func (p *Publication) DependsOn(change, other schema.Change) bool {
+	switch change.(type) {
+	case *schema.AddObject, *schema.ModifyObject:
+	default:
+		return false
+	}
+	switch o := other.(type) {
+	case *schema.AddSchema:
+		return p.publishesSchema(o.S)
+	case *schema.AddTable:
+		_, ok := p.table(o.T)
+		return ok
+	case *schema.ModifyTable:
+		pt, ok := p.table(o.T)
+		return ok && slices.ContainsFunc(o.Changes, func(c schema.Change) bool {
+			a, ok := c.(*schema.AddColumn)
+			return ok && publishesColumn(pt, a.C.Name)
+		})
+	}
+	return false
+}
+
+// DependencyOf implements the sqlx.Depender interface. Publications are dropped (or
+// modified) before the tables, columns and schemas they publish are dropped.
+func (p *Publication) DependencyOf(change, other schema.Change) bool {
+	switch c := change.(type) {
+	case *schema.DropObject:
+	case *schema.ModifyObject:
+		// The members that are dropped are held by the previous state.
+		p = c.From.(*Publication)
+	default:
+		return false
+	}
+	switch o := other.(type) {
+	case *schema.DropSchema:
+		return p.publishesSchema(o.S)
+	case *schema.DropTable:
+		_, ok := p.table(o.T)
+		return ok
+	case *schema.ModifyTable:
+		pt, ok := p.table(o.T)
+		return ok && slices.ContainsFunc(o.Changes, func(c schema.Change) bool {
+			d, ok := c.(*schema.DropColumn)
+			return ok && publishesColumn(pt, d.C.Name)
+		})
+	}
+	return false
+}
+
+// findForeignTable returns the foreign table with the given name from the schema, if exists.
+func findForeignTable(s *schema.Schema, name string) (*ForeignTable, bool) {
+	o, ok := s.Object(func(o schema.Object) bool {
+		t, ok := o.(*ForeignTable)
+		return ok && t.Name == name
+	})
+	if !ok {
+		return nil, false
+	}
+	return o.(*ForeignTable), true
+}
+
+// foreignTableChanged reports if the foreign table was changed.
+func foreignTableChanged(from, to *ForeignTable) bool {
+	return !foreignColumnsEqual(from, to) || from.Server.Name != to.Server.Name ||
+		!maps.Equal(from.Options, to.Options) ||
+		foreignTableComment(from) != foreignTableComment(to)
+}
+
+// foreignColumnsEqual reports if the two foreign tables have the same columns, in the same order.
+func foreignColumnsEqual(t1, t2 *ForeignTable) bool {
+	return slices.EqualFunc(t1.Columns, t2.Columns, func(c1, c2 *schema.Column) bool {
+		f1, err1 := FormatType(c1.Type.Type)
+		f2, err2 := FormatType(c2.Type.Type)
+		return c1.Name == c2.Name && err1 == nil && err2 == nil && f1 == f2 && c1.Type.Null == c2.Type.Null
+	})
+}
+
+// foreignTableComment returns the comment of the foreign table, if exists.
+func foreignTableComment(t *ForeignTable) string {
+	var c schema.Comment
+	sqlx.Has(t.Attrs, &c)
+	return c.Text
+}
+
+// findEventTrigger returns the event trigger with the given name from the realm, if exists.
+func findEventTrigger(r *schema.Realm, name string) (*EventTrigger, bool) {
+	o, ok := r.Object(func(o schema.Object) bool {
+		e, ok := o.(*EventTrigger)
+		return ok && e.Name == name
+	})
+	if !ok {
+		return nil, false
+	}
+	return o.(*EventTrigger), true
+}
+
+// eventTriggerEqual reports if the two event triggers fire on the same
+// events and execute the same function. Command tags are case-insensitive.
+func eventTriggerEqual(e1, e2 *EventTrigger) bool {
+	return e1.Event == e2.Event && e1.Func.Name == e2.Func.Name &&
+		sqlx.V(e1.Func.Schema).Name == sqlx.V(e2.Func.Schema).Name &&
+		slices.EqualFunc(e1.Tags, e2.Tags, strings.EqualFold)
+}
+
+// eventTriggerState returns the firing state of the event trigger, or an empty string if it is enabled.
+func eventTriggerState(e *EventTrigger) string {
+	if s := (TriggerState{}); sqlx.Has(e.Attrs, &s) {
+		return s.V
+	}
+	return ""
+}
+
+// eventTriggerComment returns the comment of the event trigger, if exists.
+func eventTriggerComment(e *EventTrigger) string {
+	var c schema.Comment
+	sqlx.Has(e.Attrs, &c)
+	return c.Text
+}
+
+// executes reports if the event trigger executes the given function.
+func (e *EventTrigger) executes(f *schema.Func) bool {
+	return e.Func.Name == f.Name && sqlx.V(e.Func.Schema).Name == sqlx.V(f.Schema).Name
+}
+
+// DependsOn implements the sqlx.Depender interface. Event
+// triggers are created after the functions they execute.
+func (e *EventTrigger) DependsOn(change, other schema.Change) bool {
+	switch change.(type) {
+	case *schema.AddObject, *schema.ModifyObject:
+	default:
+		return false
+	}
+	switch other := other.(type) {
+	case *schema.AddFunc:
+		return e.executes(other.F)
+	case *schema.ModifyFunc:
+		return e.executes(other.To)
+	}
+	return false
+}
+
+// DependencyOf implements the sqlx.Depender interface. Event
+// triggers are dropped before the functions they execute.
+func (e *EventTrigger) DependencyOf(change, other schema.Change) bool {
+	if _, ok := change.(*schema.DropObject); !ok {
+		return false
+	}
+	if other, ok := other.(*schema.DropFunc); ok {
+		return e.executes(other.F)
+	}
+	return false
+}
+
+// findCronJob returns the cron job with the given name from the realm, if exists.
+func findCronJob(r *schema.Realm, name string) (*CronJob, bool) {
+	o, ok := r.Object(func(o schema.Object) bool {
+		j, ok := o.(*CronJob)
+		return ok && j.Name == name
+	})
+	if !ok {
+		return nil, false
+	}
+	return o.(*CronJob), true
+}
+
+// SchemaObjectDiff returns a changeset for migrating schema objects from
+// one state to the other.
+func (*diff) SchemaObjectDiff(from, to *schema.Schema, _ *schema.DiffOptions) ([]schema.Change, error) {
+	var changes []schema.Change
+	// Drop or modify enums.
+	for _, o1 := range from.Objects {
+		e1, ok := o1.(*schema.EnumType)
+		if !ok {
+			continue // Unsupported object type.
+		}
+		o2, ok := to.Object(func(o schema.Object) bool {
+			e2, ok := o.(*schema.EnumType)
+			return ok && e1.T == e2.T
+		})
+		if !ok {
+			changes = append(changes, &schema.DropObject{O: o1})
+			continue
+		}
+		if e2 := o2.(*schema.EnumType); !sqlx.ValuesEqual(e1.Values, e2.Values) || enumComment(e1) != enumComment(e2) {
+			changes = append(changes, &schema.ModifyObject{From: e1, To: e2})
+		}
+	}
+	// Add new enums.
+	for _, o1 := range to.Objects {
+		e1, ok := o1.(*schema.EnumType)
+		if !ok {
+			continue // Unsupported object type.
+		}
+		if _, ok := from.Object(func(o schema.Object) bool {
+			e2, ok := o.(*schema.EnumType)
+			return ok && e1.T == e2.T
+		}); !ok {
+			changes = append(changes, &schema.AddObject{O: e1})
+		}
+	}
+	// Drop or modify domains.
+	for _, o1 := range from.Objects {
+		d1, ok := o1.(*DomainType)
+		if !ok {
+			continue
+		}
+		d2, ok := findDomain(to, d1.T)
+		switch {
+		case !ok:
+			changes = append(changes, &schema.DropObject{O: d1})
+		case domainChanged(d1, d2):
+			changes = append(changes, &schema.ModifyObject{From: d1, To: d2})
+		}
+	}
+	// Add new domains.
+	for _, o1 := range to.Objects {
+		if d1, ok := o1.(*DomainType); ok {
+			if _, ok := findDomain(from, d1.T); !ok {
+				changes = append(changes, &schema.AddObject{O: d1})
+			}
+		}
+	}
+	// Drop or modify composite types.
+	for _, o1 := range from.Objects {
+		c1, ok := o1.(*CompositeType)
+		if !ok {
+			continue
+		}
+		c2, ok := findComposite(to, c1.T)
+		switch {
+		case !ok:
+			changes = append(changes, &schema.DropObject{O: c1})
+		case compositeChanged(c1, c2):
+			changes = append(changes, &schema.ModifyObject{From: c1, To: c2})
+		}
+	}
+	// Add new composite types.
+	for _, o1 := range to.Objects {
+		if c1, ok := o1.(*CompositeType); ok {
+			if _, ok := findComposite(from, c1.T); !ok {
+				changes = append(changes, &schema.AddObject{O: c1})
+			}
+		}
+	}
+	// Drop or modify range types.
+	for _, o1 := range from.Objects {
+		r1, ok := o1.(*UserRangeType)
+		if !ok {
+			continue
+		}
+		r2, ok := findRange(to, r1.T)
+		switch {
+		case !ok:
+			changes = append(changes, &schema.DropObject{O: r1})
+		case !rangeEqual(r1, r2) || rangeComment(r1) != rangeComment(r2):
+			changes = append(changes, &schema.ModifyObject{From: r1, To: r2})
+		}
+	}
+	// Add new range types.
+	for _, o1 := range to.Objects {
+		if r1, ok := o1.(*UserRangeType); ok {
+			if _, ok := findRange(from, r1.T); !ok {
+				changes = append(changes, &schema.AddObject{O: r1})
+			}
+		}
+	}
+	// Drop or modify collations.
+	for _, o1 := range from.Objects {
+		c1, ok := o1.(*Collation)
+		if !ok {
+			continue
+		}
+		c2, ok := findCollation(to, c1.Name)
+		switch {
+		case !ok:
+			changes = append(changes, &schema.DropObject{O: c1})
+		case !collationEqual(c1, c2) || collationComment(c1) != collationComment(c2):
+			changes = append(changes, &schema.ModifyObject{From: c1, To: c2})
+		}
+	}
+	// Add new collations.
+	for _, o1 := range to.Objects {
+		if c1, ok := o1.(*Collation); ok {
+			if _, ok := findCollation(from, c1.Name); !ok {
+				changes = append(changes, &schema.AddObject{O: c1})
+			}
+		}
+	}
+	// Drop or modify partitions.
+	for _, o1 := range from.Objects {
+		p1, ok := o1.(*TablePartition)
+		if !ok {
+			continue
+		}
+		p2, ok := findPartition(to, p1.Name)
+		switch {
+		case !ok && !rotated(p1, to):
+			changes = append(changes, &schema.DropObject{O: p1})
+		case ok && !partitionEqual(p1, p2):
+			changes = append(changes, &schema.ModifyObject{From: p1, To: p2})
+		}
+	}
+	// Add new partitions.
+	for _, o1 := range to.Objects {
+		if p1, ok := o1.(*TablePartition); ok {
+			if _, ok := findPartition(from, p1.Name); !ok {
+				changes = append(changes, &schema.AddObject{O: p1})
+			}
+		}
+	}
+	// Drop or modify text search dictionaries and configurations.
+	for _, o1 := range from.Objects {
+		switch o1 := o1.(type) {
+		case *TextSearchDict:
+			d2, ok := findTextSearchDict(to, o1.Name)
+			switch {
+			case !ok:
+				changes = append(changes, &schema.DropObject{O: o1})
+			case !tsDictEqual(o1, d2):
+				changes = append(changes, &schema.ModifyObject{From: o1, To: d2})
+			}
+		case *TextSearchConfig:
+			c2, ok := findTextSearchConfig(to, o1.Name)
+			switch {
+			case !ok:
+				changes = append(changes, &schema.DropObject{O: o1})
+			case !tsConfigEqual(o1, c2):
+				changes = append(changes, &schema.ModifyObject{From: o1, To: c2})
+			}
+		}
+	}
+	// Add new text search dictionaries and configurations.
+	for _, o1 := range to.Objects {
+		switch o1 := o1.(type) {
+		case *TextSearchDict:
+			if _, ok := findTextSearchDict(from, o1.Name); !ok {
+				changes = append(changes, &schema.AddObject{O: o1})
+			}
+		case *TextSearchConfig:
+			if _, ok := findTextSearchConfig(from, o1.Name); !ok {
+				changes = append(changes, &schema.AddObject{O: o1})
+			}
+		}
+	}
+	// Drop or modify aggregates.
+	for _, o1 := range from.Objects {
+		a1, ok := o1.(*Aggregate)
+		if !ok {
+			continue
+		}
+		a2, ok := findAggregate(to, a1)
+		switch {
+		case !ok:
+			changes = append(changes, &schema.DropObject{O: a1})
+		case !aggregateEqual(a1, a2) || aggregateComment(a1) != aggregateComment(a2):
+			changes = append(changes, &schema.ModifyObject{From: a1, To: a2})
+		}
+	}
+	// Add new aggregates.
+	for _, o1 := range to.Objects {
+		if a1, ok := o1.(*Aggregate); ok {
+			if _, ok := findAggregate(from, a1); !ok {
+				changes = append(changes, &schema.AddObject{O: a1})
+			}
+		}
+	}
+	// Drop or modify operators, operator families and operator classes.
+	for _, o1 := range from.Objects {
+		switch o1 := o1.(type) {
+		case *Operator:
+			o2, ok := findOperator(to, o1)
+			switch {
+			case !ok:
+				changes = append(changes, &schema.DropObject{O: o1})
+			case !operatorEqual(o1, o2) || operatorComment(o1) != operatorComment(o2):
+				changes = append(changes, &schema.ModifyObject{From: o1, To: o2})
+			}
+		case *OpFamily:
+			f2, ok := findOpFamily(to, o1.Name, o1.Method)
+			switch {
+			case !ok:
+				changes = append(changes, &schema.DropObject{O: o1})
+			case opFamilyComment(o1) != opFamilyComment(f2):
+				changes = append(changes, &schema.ModifyObject{From: o1, To: f2})
+			}
+		case *OpClass:
+			c2, ok := findOpClass(to, o1.Name, o1.Method)
+			switch {
+			case !ok:
+				changes = append(changes, &schema.DropObject{O: o1})
+			case !opClassEqual(o1, c2) || opClassComment(o1) != opClassComment(c2):
+				changes = append(changes, &schema.ModifyObject{From: o1, To: c2})
+			}
+		}
+	}
+	// Add new operators, operator families and operator classes.
+	for _, o1 := range to.Objects {
+		switch o1 := o1.(type) {
+		case *Operator:
+			if _, ok := findOperator(from, o1); !ok {
+				changes = append(changes, &schema.AddObject{O: o1})
+			}
+		case *OpFamily:
+			if _, ok := findOpFamily(from, o1.Name, o1.Method); !ok {
+				changes = append(changes, &schema.AddObject{O: o1})
+			}
+		case *OpClass:
+			if _, ok := findOpClass(from, o1.Name, o1.Method); !ok {
+				changes = append(changes, &schema.AddObject{O: o1})
+			}
+		}
+	}
+	// Drop or modify foreign tables.
+	for _, o1 := range from.Objects {
+		t1, ok := o1.(*ForeignTable)
+		if !ok {
+			continue
+		}
+		t2, ok := findForeignTable(to, t1.Name)
+		switch {
+		case !ok:
+			changes = append(changes, &schema.DropObject{O: t1})
+		case foreignTableChanged(t1, t2):
+			changes = append(changes, &schema.ModifyObject{From: t1, To: t2})
+		}
+	}
+	// Add new foreign tables.
+	for _, o1 := range to.Objects {
+		if t1, ok := o1.(*ForeignTable); ok {
+			if _, ok := findForeignTable(from, t1.Name); !ok {
+				changes = append(changes, &schema.AddObject{O: t1})
+			}
+		}
+	}
+	// Drop or modify sequences.
+	for _, o1 := range from.Objects {
+		s1, ok := o1.(*Sequence)
+		if !ok {
+			continue
+		}
+		s2, ok := findSequence(to, s1.Name)
+		switch {
+		case !ok:
+			changes = append(changes, &schema.DropObject{O: s1})
+		case !seqEqual(s1, s2):
+			changes = append(changes, &schema.ModifyObject{From: s1, To: s2})
+		}
+	}
+	// Add new sequences.
+	for _, o1 := range to.Objects {
+		if s1, ok := o1.(*Sequence); ok {
+			if _, ok := findSequence(from, s1.Name); !ok {
+				changes = append(changes, &schema.AddObject{O: s1})
+			}
+		}
+	}
+	return changes, nil
+}
+
+// findDomain returns the domain with the given name from the schema, if exists.
+func findDomain(s *schema.Schema, name string) (*DomainType, bool) {
+	o, ok := s.Object(func(o schema.Object) bool {
+		d, ok := o.(*DomainType)
+		return ok && d.T == name
+	})
+	if !ok {
+		return nil, false
+	}
+	return o.(*DomainType), true
+}
+
+// domainChanged reports if the domain definition was changed.
+func domainChanged(d1, d2 *DomainType) bool {
+	t1, err1 := FormatType(d1.Type)
+	t2, err2 := FormatType(d2.Type)
+	if err1 != nil || err2 != nil || t1 != t2 || d1.Null != d2.Null || domainDefault(d1) != domainDefault(d2) {
+		return true
+	}
+	drop, add := domainChecks(d1, d2)
+	return len(drop) > 0 || len(add) > 0 || domainComment(d1) != domainComment(d2)
+}
+
+// domainDefault returns the default value of the domain, without casting.
+func domainDefault(d *DomainType) string {
+	switch x := d.Default.(type) {
+	case *schema.Literal:
+		return trimCast(x.V)
+	case *schema.RawExpr:
+		return trimCast(x.X)
+	default:
+		return ""
+	}
+}
+
+// domainChecks returns the CHECK constraints that should be dropped from the
+// first domain and added to it in order to match the second one. Constraints
+// are matched by their expressions, and by their names if both are named.
+func domainChecks(from, to *DomainType) (drop, add []*schema.Check) {
+	matched := make(map[*schema.Check]bool)
+	for _, c1 := range from.Checks {
+		i := slices.IndexFunc(to.Checks, func(c2 *schema.Check) bool {
+			return !matched[c2] && sqlx.MayWrap(c1.Expr) == sqlx.MayWrap(c2.Expr) && (c1.Name == "" || c2.Name == "" || c1.Name == c2.Name)
+		})
+		if i == -1 {
+			drop = append(drop, c1)
+			continue
+		}
+		matched[to.Checks[i]] = true
+	}
+	for _, c2 := range to.Checks {
+		if !matched[c2] {
+			add = append(add, c2)
+		}
+	}
+	return drop, add
+}
+
+// domainComment returns the comment of the domain, if exists.
+func domainComment(d *DomainType) string {
+	var c schema.Comment
+	sqlx.Has(d.Attrs, &c)
+	return c.Text
+}
+
+// findComposite returns the composite type with the given name from the schema, if exists.
+func findComposite(s *schema.Schema, name string) (*CompositeType, bool) {
+	o, ok := s.Object(func(o schema.Object) bool {
+		c, ok := o.(*CompositeType)
+		return ok && c.T == name
+	})
+	if !ok {
+		return nil, false
+	}
+	return o.(*CompositeType), true
+}
+
+// compositeChanged reports if the composite type definition was changed.
+func compositeChanged(c1, c2 *CompositeType) bool {
+	drop, add, alter := compositeFields(c1, c2)
+	return len(drop) > 0 || len(add) > 0 || len(alter) > 0 || compositeComment(c1) != compositeComment(c2)
+}
+
+// compositeFields returns the fields that should be dropped from the first composite type, added
+// to it, or altered (returned as pairs of the current and desired field) in order to match the
+// second one. Fields are matched by their names, and their order is ignored.
+func compositeFields(from, to *CompositeType) (drop, add []*schema.Column, alter [][2]*schema.Column) {
+	for _, f1 := range from.Fields {
+		i := slices.IndexFunc(to.Fields, func(f2 *schema.Column) bool { return f1.Name == f2.Name })
+		if i == -1 {
+			drop = append(drop, f1)
+			continue
+		}
+		f2 := to.Fields[i]
+		t1, err1 := FormatType(f1.Type.Type)
+		t2, err2 := FormatType(f2.Type.Type)
+		if err1 != nil || err2 != nil || t1 != t2 || fieldCollation(f1) != fieldCollation(f2) {
+			alter = append(alter, [2]*schema.Column{f1, f2})
+		}
+	}
+	for _, f2 := range to.Fields {
+		if !slices.ContainsFunc(from.Fields, func(f1 *schema.Column) bool { return f1.Name == f2.Name }) {
+			add = append(add, f2)
+		}
+	}
+	return drop, add, alter
+}
+
+// fieldCollation returns the explicit collation of the composite type field, if exists.
+func fieldCollation(f *schema.Column) string {
+	var c schema.Collation
+	sqlx.Has(f.Attrs, &c)
+	return c.V
+}
+
+// compositeComment returns the comment of the composite type, if exists.
+func compositeComment(c *CompositeType) string {
+	var cm schema.Comment
+	sqlx.Has(c.Attrs, &cm)
+	return cm.Text
+}
+
+// DependsOn implements the sqlx.Depender interface. Composite types are
+// created (or modified) after the types that are used by their fields.
+func (c *CompositeType) DependsOn(change, other schema.Change) bool {
+	switch change.(type) {
+	case *schema.AddObject, *schema.ModifyObject:
+		add, ok := other.(*schema.AddObject)
+		return ok && slices.Contains(c.Deps, add.O)
+	}
+	return false
+}
+
+// DependencyOf implements the sqlx.Depender interface. Types that are
+// used by the composite type fields are dropped after it.
+func (c *CompositeType) DependencyOf(change, other schema.Change) bool {
+	if _, ok := change.(*schema.DropObject); !ok {
+		return false
+	}
+	drop, ok := other.(*schema.DropObject)
+	return ok && slices.Contains(c.Deps, drop.O)
+}
+
+// findRange returns the range type with the given name from the schema, if exists.
+func findRange(s *schema.Schema, name string) (*UserRangeType, bool) {
+	o, ok := s.Object(func(o schema.Object) bool {
+		r, ok := o.(*UserRangeType)
+		return ok && r.T == name
+	})
+	if !ok {
+		return nil, false
+	}
+	return o.(*UserRangeType), true
+}
+
+// rangeEqual reports if the two range types have the same definition. Comments are ignored.
+func rangeEqual(r1, r2 *UserRangeType) bool {
+	t1, err1 := FormatType(r1.Subtype)
+	t2, err2 := FormatType(r2.Subtype)
+	return err1 == nil && err2 == nil && t1 == t2 &&
+		r1.Collation == r2.Collation && r1.Canonical == r2.Canonical && r1.SubtypeDiff == r2.SubtypeDiff
+}
+
+// rangeComment returns the comment of the range type, if exists.
+func rangeComment(r *UserRangeType) string {
+	var c schema.Comment
+	sqlx.Has(r.Attrs, &c)
+	return c.Text
+}
+
+// DependsOn implements the sqlx.Depender interface. Range types are
+// created (or modified) after the types that are used as their subtype.
+func (r *UserRangeType) DependsOn(change, other schema.Change) bool {
+	switch change.(type) {
+	case *schema.AddObject, *schema.ModifyObject:
+		add, ok := other.(*schema.AddObject)
+		return ok && slices.Contains(r.Deps, add.O)
+	}
+	return false
+}
+
+// DependencyOf implements the sqlx.Depender interface. Types that
+// are used as the subtype of the range type are dropped after it.
+func (r *UserRangeType) DependencyOf(change, other schema.Change) bool {
+	if _, ok := change.(*schema.DropObject); !ok {
+		return false
+	}
+	drop, ok := other.(*schema.DropObject)
+	return ok && slices.Contains(r.Deps, drop.O)
+}
+
+// findCollation returns the collation with the given name from the schema, if exists.
+func findCollation(s *schema.Schema, name string) (*Collation, bool) {
+	o, ok := s.Object(func(o schema.Object) bool {
+		c, ok := o.(*Collation)
+		return ok && c.Name == name
+	})
+	if !ok {
+		return nil, false
+	}
+	return o.(*Collation), true
+}
+
+// collationEqual reports if the two collations have the same definition. Comments are ignored.
+func collationEqual(c1, c2 *Collation) bool {
+	p1, p2 := c1.Provider, c2.Provider
+	if p1 == "" {
+		p1 = "libc"
+	}
+	if p2 == "" {
+		p2 = "libc"
+	}
+	return p1 == p2 && c1.Locale == c2.Locale && c1.LcCollate == c2.LcCollate && c1.LcCtype == c2.LcCtype && c1.Deterministic == c2.Deterministic
+}
+
+// collationComment returns the comment of the collation, if exists.
+func collationComment(c *Collation) string {
+	var cm schema.Comment
+	sqlx.Has(c.Attrs, &cm)
+	return cm.Text
+}
+
+// usedBy reports if the collation is used by one of the given columns.
+func (c *Collation) usedBy(columns ...*schema.Column) bool {
+	return slices.ContainsFunc(columns, func(col *schema.Column) bool {
+		return c.is(fieldCollation(col))
+	})
+}
+
+// usedByIndex reports if the collation is used by one of the parts of the given indexes.
+func (c *Collation) usedByIndex(indexes ...*schema.Index) bool {
+	return slices.ContainsFunc(indexes, func(idx *schema.Index) bool {
+		return idx != nil && slices.ContainsFunc(idx.Parts, func(p *schema.IndexPart) bool {
+			var v schema.Collation
+			return sqlx.Has(p.Attrs, &v) && c.is(v.V)
+		})
+	})
+}
+
+// usedByTable reports if the collation is used by one of the
+// columns, indexes or constraints (e.g., primary key) of the table.
+func (c *Collation) usedByTable(t *schema.Table) bool {
+	return c.usedBy(t.Columns...) || c.usedByIndex(append(t.Indexes, t.PrimaryKey)...)
+}
+
+// usedByObject reports if the collation is used by the given object (e.g., composite type fields).
+func (c *Collation) usedByObject(o schema.Object) bool {
+	switch o := o.(type) {
+	case *CompositeType:
+		return c.usedBy(o.Fields...)
+	case *UserRangeType:
+		return c.is(o.Collation)
+	}
+	return false
+}
+
+// is reports if the given collation name (optionally, schema-qualified) refers to the collation.
+func (c *Collation) is(name string) bool {
+	return name == c.Name || c.Schema != nil && name == c.Schema.Name+"."+c.Name
+}
+
+// DependencyOf implements the sqlx.Depender interface. Tables, indexes,
+// constraints and composite types that use the collation are created
+// (or modified) after it.
+func (c *Collation) DependencyOf(change, other schema.Change) bool {
+	switch change.(type) {
+	case *schema.AddObject, *schema.ModifyObject:
+	default:
+		return false
+	}
+	switch other := other.(type) {
+	case *schema.AddTable:
+		return c.usedByTable(other.T)
+	case *schema.AddObject:
+		return c.usedByObject(other.O)
+	case *schema.ModifyObject:
+		return c.usedByObject(other.To)
+	case *schema.ModifyTable:
+		return slices.ContainsFunc(other.Changes, func(change schema.Change) bool {
+			switch change := change.(type) {
+			case *schema.AddColumn:
+				return c.usedBy(change.C)
+			case *schema.ModifyColumn:
+				return c.usedBy(change.To)
+			case *schema.AddIndex:
+				return c.usedByIndex(change.I)
+			case *schema.ModifyIndex:
+				return c.usedByIndex(change.To)
+			case *schema.AddPrimaryKey:
+				return c.usedByIndex(change.P)
+			case *schema.ModifyPrimaryKey:
+				return c.usedByIndex(change.To)
+			}
+			return false
+		})
+	}
+	return false
+}
+
+// DependsOn implements the sqlx.Depender interface. Collations are dropped after
+// the tables, columns, indexes and composite types that use them.
+func (c *Collation) DependsOn(change, other schema.Change) bool {
+	if _, ok := change.(*schema.DropObject); !ok {
+		return false
+	}
+	switch other := other.(type) {
+	case *schema.DropTable:
+		return c.usedByTable(other.T)
+	case *schema.DropObject:
+		return c.usedByObject(other.O)
+	case *schema.ModifyObject:
+		return c.usedByObject(other.From)
+	case *schema.ModifyTable:
+		return slices.ContainsFunc(other.Changes, func(change schema.Change) bool {
+			switch change := change.(type) {
+			case *schema.DropColumn:
+				return c.usedBy(change.C)
+			case *schema.ModifyColumn:
+				return c.usedBy(change.From)
+			case *schema.DropIndex:
+				return c.usedByIndex(change.I)
+			case *schema.ModifyIndex:
+				return c.usedByIndex(change.From)
+			case *schema.DropPrimaryKey:
+				return c.usedByIndex(change.P)
+			case *schema.ModifyPrimaryKey:
+				return c.usedByIndex(change.From)
+			}
+			return false
+		})
+	}
+	return false
+}
+
+// findPartition returns the partition with the given name from the schema, if exists.
+func findPartition(s *schema.Schema, name string) (*TablePartition, bool) {
+	o, ok := s.Object(func(o schema.Object) bool {
+		p, ok := o.(*TablePartition)
+		return ok && p.Name == name
+	})
+	if !ok {
+		return nil, false
+	}
+	return o.(*TablePartition), true
+}
+
+// rotated reports if the partition is managed by the PartitionRotation policy of its root
+// table in the given schema. Such partitions are not expected to be defined in the desired
+// state, and therefore, they are not dropped.
+func rotated(p *TablePartition, s *schema.Schema) bool {
+	root := partitionRoot(p)
+	if root == nil {
+		return false
+	}
+	t, ok := s.Table(root.Name)
+	return ok && sqlx.Has(t.Attrs, &PartitionRotation{}) && strings.HasPrefix(p.Name, t.Name+"_p")
+}
+
+// partitionEqual reports if the two partitions are equal, including their comments.
+func partitionEqual(p1, p2 *TablePartition) bool {
+	return partitionParentName(p1) == partitionParentName(p2) && partitionBoundEqual(p1.Bound, p2.Bound) &&
+		p1.PartitionBy == p2.PartitionBy && partitionComment(p1) == partitionComment(p2)
+}
+
+// partitionParentName returns the qualified name of the parent of the partition.
+func partitionParentName(p *TablePartition) string {
+	if p.Table != nil {
+		return sqlx.V(p.Table.Schema).Name + "." + p.Table.Name
+	}
+	return sqlx.V(p.Parent.Schema).Name + "." + p.Parent.Name
+}
+
+// partitionBoundEqual reports if the two partition bounds are equal, ignoring
+// letter case and whitespace differences. e.g., "for values in (1)" and
+// "FOR VALUES IN (1)".
+func partitionBoundEqual(b1, b2 string) bool {
+	return strings.EqualFold(strings.Join(strings.Fields(b1), " "), strings.Join(strings.Fields(b2), " "))
+}
+
+// partitionComment returns the comment of the partition, if exists.
+func partitionComment(p *TablePartition) string {
+	var c schema.Comment
+	sqlx.Has(p.Attrs, &c)
+	return c.Text
+}
+
+// DependsOn implements the sqlx.Depender interface. Partitions
+// are created (or modified) after their parents.
+func (p *TablePartition) DependsOn(change, other schema.Change) bool {
+	switch change.(type) {
+	case *schema.AddObject, *schema.ModifyObject:
+	default:
+		return false
+	}
+	switch other := other.(type) {
+	case *schema.AddTable:
+		return p.Table != nil && other.T == p.Table
+	case *schema.ModifyTable:
+		return p.Table != nil && other.T == p.Table
+	case *schema.AddObject:
+		return p.Parent != nil && other.O == p.Parent
+	case *schema.ModifyObject:
+		return p.Parent != nil && other.To == p.Parent
+	}
+	return false
+}
+
+// DependencyOf implements the sqlx.Depender interface. Partitions are dropped
+// before their parents, as dropping a parent drops its partitions as well.
+func (p *TablePartition) DependencyOf(change, other schema.Change) bool {
+	if _, ok := change.(*schema.DropObject); !ok {
+		return false
+	}
+	switch other := other.(type) {
+	case *schema.DropTable:
+		return p.Table != nil && other.T == p.Table
+	case *schema.DropObject:
+		return p.Parent != nil && other.O == p.Parent
+	}
+	return false
+}
+
+// findTextSearchDict returns the text search dictionary with the given name from the schema, if exists.
+func findTextSearchDict(s *schema.Schema, name string) (*TextSearchDict, bool) {
+	o, ok := s.Object(func(o schema.Object) bool {
+		d, ok := o.(*TextSearchDict)
+		return ok && d.Name == name
+	})
+	if !ok {
+		return nil, false
+	}
+	return o.(*TextSearchDict), true
+}
+
+// tsDictEqual reports if the two text search dictionaries are equal, including their comments.
+func tsDictEqual(d1, d2 *TextSearchDict) bool {
+	return d1.Template == d2.Template && maps.Equal(d1.Options, d2.Options) && tsDictComment(d1) == tsDictComment(d2)
+}
+
+// tsDictComment returns the comment of the text search dictionary, if exists.
+func tsDictComment(d *TextSearchDict) string {
+	var c schema.Comment
+	sqlx.Has(d.Attrs, &c)
+	return c.Text
+}
+
+// usedBy reports if the dictionary is used by one of the mappings of the given configuration.
+func (d *TextSearchDict) usedBy(c *TextSearchConfig) bool {
+	for _, dicts := range c.Mappings {
+		if slices.ContainsFunc(dicts, func(n string) bool {
+			return n == d.Name || d.Schema != nil && n == d.Schema.Name+"."+d.Name
+		}) {
+			return true
+		}
+	}
+	return false
+}
+
+// DependencyOf implements the sqlx.Depender interface. Configurations
+// that use the dictionary are created (or modified) after it.
+func (d *TextSearchDict) DependencyOf(change, other schema.Change) bool {
+	switch change.(type) {
+	case *schema.AddObject, *schema.ModifyObject:
+	default:
+		return false
+	}
+	switch other := other.(type) {
+	case *schema.AddObject:
+		c, ok := other.O.(*TextSearchConfig)
+		return ok && d.usedBy(c)
+	case *schema.ModifyObject:
+		c, ok := other.To.(*TextSearchConfig)
+		return ok && d.usedBy(c)
+	}
+	return false
+}
+
+// DependsOn implements the sqlx.Depender interface. Dictionaries are
+// dropped after the configurations that use them are dropped (or modified).
+func (d *TextSearchDict) DependsOn(change, other schema.Change) bool {
+	if _, ok := change.(*schema.DropObject); !ok {
+		return false
+	}
+	switch other := other.(type) {
+	case *schema.DropObject:
+		c, ok := other.O.(*TextSearchConfig)
+		return ok && d.usedBy(c)
+	case *schema.ModifyObject:
+		c, ok := other.From.(*TextSearchConfig)
+		return ok && d.usedBy(c)
+	}
+	return false
+}
+
+// findTextSearchConfig returns the text search configuration with the given name from the schema, if exists.
+func findTextSearchConfig(s *schema.Schema, name string) (*TextSearchConfig, bool) {
+	o, ok := s.Object(func(o schema.Object) bool {
+		c, ok := o.(*TextSearchConfig)
+		return ok && c.Name == name
+	})
+	if !ok {
+		return nil, false
+	}
+	return o.(*TextSearchConfig), true
+}
+
+// tsConfigEqual reports if the two text search configurations are equal, including their comments.
+func tsConfigEqual(c1, c2 *TextSearchConfig) bool {
+	return c1.Parser == c2.Parser && maps.EqualFunc(c1.Mappings, c2.Mappings, slices.Equal) && tsConfigComment(c1) == tsConfigComment(c2)
+}
+
+// tsConfigComment returns the comment of the text search configuration, if exists.
+func tsConfigComment(c *TextSearchConfig) string {
+	var cm schema.Comment
+	sqlx.Has(c.Attrs, &cm)
+	return cm.Text
+}
+
+// findOperator returns the operator with the same name and operand types from the schema, if exists.
+func findOperator(s *schema.Schema, o1 *Operator) (*Operator, bool) {
+	o, ok := s.Object(func(o schema.Object) bool {
+		o2, ok := o.(*Operator)
+		return ok && o2.Name == o1.Name && o2.Left == o1.Left && o2.Right == o1.Right
+	})
+	if !ok {
+		return nil, false
+	}
+	return o.(*Operator), true
+}
+
+// operatorEqual reports if the two operators have the same definition. Comments are ignored.
+func operatorEqual(o1, o2 *Operator) bool {
+	return o1.Function == o2.Function && o1.Commutator == o2.Commutator && o1.Negator == o2.Negator &&
+		o1.Restrict == o2.Restrict && o1.Join == o2.Join && o1.Hashes == o2.Hashes && o1.Merges == o2.Merges
+}
+
+// operatorComment returns the comment of the operator, if exists.
+func operatorComment(o *Operator) string {
+	var c schema.Comment
+	sqlx.Has(o.Attrs, &c)
+	return c.Text
+}
+
+// usedBy reports if the operator is a member of the given operator class.
+func (o *Operator) usedBy(c *OpClass) bool {
+	sig := fmt.Sprintf("%s(%s,%s)", o.Name, cmp.Or(o.Left, "NONE"), o.Right)
+	return slices.ContainsFunc(c.Operators, func(op *OpClassOperator) bool {
+		return op.Name == sig || o.Schema != nil && op.Name == o.Schema.Name+"."+sig
+	})
+}
+
+// usesFunc reports if the operator is implemented by the given function.
+func (o *Operator) usesFunc(f *schema.Func) bool {
+	return o.Function == f.Name || f.Schema != nil && o.Function == f.Schema.Name+"."+f.Name
+}
+
+// DependsOn implements the sqlx.Depender interface. Operators are created after their
+// functions, and dropped after the operator classes that use them.
+func (o *Operator) DependsOn(change, other schema.Change) bool {
+	switch change.(type) {
+	case *schema.AddObject:
+		add, ok := other.(*schema.AddFunc)
+		return ok && o.usesFunc(add.F)
+	case *schema.DropObject:
+		switch other := other.(type) {
+		case *schema.DropObject:
+			c, ok := other.O.(*OpClass)
+			return ok && o.usedBy(c)
+		case *schema.ModifyObject:
+			c, ok := other.From.(*OpClass)
+			return ok && o.usedBy(c)
+		}
+	}
+	return false
+}
+
+// DependencyOf implements the sqlx.Depender interface. Operator
+// classes that use the operator are created (or modified) after it.
+func (o *Operator) DependencyOf(change, other schema.Change) bool {
+	switch change.(type) {
+	case *schema.AddObject, *schema.ModifyObject:
+	default:
+		return false
+	}
+	switch other := other.(type) {
+	case *schema.AddObject:
+		c, ok := other.O.(*OpClass)
+		return ok && o.usedBy(c)
+	case *schema.ModifyObject:
+		c, ok := other.To.(*OpClass)
+		return ok && o.usedBy(c)
+	}
+	return false
+}
+
+// findAggregate returns the aggregate with the same name and argument types from the schema, if exists.
+func findAggregate(s *schema.Schema, a *Aggregate) (*Aggregate, bool) {
+	o, ok := s.Object(func(o schema.Object) bool {
+		a2, ok := o.(*Aggregate)
+		return ok && a2.Name == a.Name && slices.EqualFunc(a.Args, a2.Args, func(a1, a2 *schema.FuncArg) bool {
+			return sameType(a1.Type, a2.Type)
+		})
+	})
+	if !ok {
+		return nil, false
+	}
+	return o.(*Aggregate), true
+}
+
+// aggregateEqual reports if the two aggregates have the same definition. Comments are ignored.
+func aggregateEqual(a1, a2 *Aggregate) bool {
+	return sameFuncArgs(a1.Args, a2.Args) && sameType(a1.StateType, a2.StateType) &&
+		a1.StateFunc == a2.StateFunc && a1.FinalFunc == a2.FinalFunc &&
+		cmp.Or(a1.Parallel, ParallelUnsafe) == cmp.Or(a2.Parallel, ParallelUnsafe) &&
+		(a1.InitCond == nil) == (a2.InitCond == nil) && (a1.InitCond == nil || *a1.InitCond == *a2.InitCond)
+}
+
+// aggregateComment returns the comment of the aggregate, if exists.
+func aggregateComment(a *Aggregate) string {
+	var c schema.Comment
+	sqlx.Has(a.Attrs, &c)
+	return c.Text
+}
+
+// usesFunc reports if the given function is the state or the final function of the aggregate.
+func (a *Aggregate) usesFunc(f *schema.Func) bool {
+	return slices.ContainsFunc([]string{a.StateFunc, a.FinalFunc}, func(name string) bool {
+		return name != "" && (name == f.Name || f.Schema != nil && name == f.Schema.Name+"."+f.Name)
+	})
+}
+
+// DependsOn implements the sqlx.Depender interface. Aggregates are created (or
+// modified) after their state and final functions, and the types they use.
+func (a *Aggregate) DependsOn(change, other schema.Change) bool {
+	switch change.(type) {
+	case *schema.AddObject, *schema.ModifyObject:
+	default:
+		return false
+	}
+	switch other := other.(type) {
+	case *schema.AddFunc:
+		return a.usesFunc(other.F)
+	case *schema.ModifyFunc:
+		return a.usesFunc(other.To)
+	case *schema.AddObject:
+		return slices.Contains(a.Deps, other.O)
+	}
+	return false
+}
+
+// DependencyOf implements the sqlx.Depender interface. The functions
+// and the types that are used by the aggregate are dropped after it.
+func (a *Aggregate) DependencyOf(change, other schema.Change) bool {
+	if _, ok := change.(*schema.DropObject); !ok {
+		return false
+	}
+	switch other := other.(type) {
+	case *schema.DropFunc:
+		return a.usesFunc(other.F)
+	case *schema.DropObject:
+		return slices.Contains(a.Deps, other.O)
+	}
+	return false
+}
+
+// findOpFamily returns the operator family with the given name and access method from the schema, if exists.
+func findOpFamily(s *schema.Schema, name, method string) (*OpFamily, bool) {
+	o, ok := s.Object(func(o schema.Object) bool {
+		f, ok := o.(*OpFamily)
+		return ok && f.Name == name && f.Method == method
+	})
+	if !ok {
+		return nil, false
+	}
+	return o.(*OpFamily), true
+}
+
+// opFamilyComment returns the comment of the operator family, if exists.
+func opFamilyComment(f *OpFamily) string {
+	var c schema.Comment
+	sqlx.Has(f.Attrs, &c)
+	return c.Text
+}
+
+// usedBy reports if the family is the family of the given operator class.
+func (f *OpFamily) usedBy(c *OpClass) bool {
+	return c.Method == f.Method && (c.Family == f.Name || f.Schema != nil && c.Family == f.Schema.Name+"."+f.Name)
+}
+
+// DependencyOf implements the sqlx.Depender interface. Operator
+// classes of the family are created after it.
+func (f *OpFamily) DependencyOf(change, other schema.Change) bool {
+	if _, ok := change.(*schema.AddObject); !ok {
+		return false
+	}
+	switch other := other.(type) {
+	case *schema.AddObject:
+		c, ok := other.O.(*OpClass)
+		return ok && f.usedBy(c)
+	case *schema.ModifyObject:
+		c, ok := other.To.(*OpClass)
+		return ok && f.usedBy(c)
+	}
+	return false
+}
+
+// DependsOn implements the sqlx.Depender interface. Families
+// are dropped after the operator classes that use them.
+func (f *OpFamily) DependsOn(change, other schema.Change) bool {
+	if _, ok := change.(*schema.DropObject); !ok {
+		return false
+	}
+	switch other := other.(type) {
+	case *schema.DropObject:
+		c, ok := other.O.(*OpClass)
+		return ok && f.usedBy(c)
+	case *schema.ModifyObject:
+		c, ok := other.From.(*OpClass)
+		return ok && f.usedBy(c)
+	}
+	return false
+}
+
+// findOpClass returns the operator class with the given name and access method from the schema, if exists.
+func findOpClass(s *schema.Schema, name, method string) (*OpClass, bool) {
+	o, ok := s.Object(func(o schema.Object) bool {
+		c, ok := o.(*OpClass)
+		return ok && c.Name == name && c.Method == method
+	})
+	if !ok {
+		return nil, false
+	}
+	return o.(*OpClass), true
+}
+
+// opClassEqual reports if the two operator classes have the same definition. Comments are ignored.
+func opClassEqual(c1, c2 *OpClass) bool {
+	return c1.Type == c2.Type && c1.Default == c2.Default && c1.Family == c2.Family && c1.Storage == c2.Storage &&
+		slices.EqualFunc(c1.Operators, c2.Operators, func(o1, o2 *OpClassOperator) bool { return *o1 == *o2 }) &&
+		slices.EqualFunc(c1.Funcs, c2.Funcs, func(f1, f2 *OpClassFunc) bool { return *f1 == *f2 })
+}
+
+// opClassComment returns the comment of the operator class, if exists.
+func opClassComment(c *OpClass) string {
+	var cm schema.Comment
+	sqlx.Has(c.Attrs, &cm)
+	return cm.Text
+}
+
+// usedBy reports if the operator class is used by one of the given indexes.
+func (c *OpClass) usedBy(indexes ...*schema.Index) bool {
+	return slices.ContainsFunc(indexes, func(idx *schema.Index) bool {
+		return slices.ContainsFunc(idx.Parts, func(p *schema.IndexPart) bool {
+			op := &IndexOpClass{}
+			return sqlx.Has(p.Attrs, op) && (op.Name == c.Name || c.Schema != nil && op.Name == c.Schema.Name+"."+c.Name)
+		})
+	})
+}
+
+// usesFunc reports if the given function is a support function of the operator class.
+func (c *OpClass) usesFunc(f *schema.Func) bool {
+	return slices.ContainsFunc(c.Funcs, func(fn *OpClassFunc) bool {
+		name, _, _ := strings.Cut(fn.Name, "(")
+		return name == f.Name || f.Schema != nil && name == f.Schema.Name+"."+f.Name
+	})
+}
+
+// DependsOn implements the sqlx.Depender interface. Operator classes are created
+// after their support functions, and dropped after the indexes that use them.
+func (c *OpClass) DependsOn(change, other schema.Change) bool {
+	switch change.(type) {
+	case *schema.AddObject:
+		add, ok := other.(*schema.AddFunc)
+		return ok && c.usesFunc(add.F)
+	case *schema.DropObject:
+		switch other := other.(type) {
+		case *schema.DropTable:
+			return c.usedBy(other.T.Indexes...)
+		case *schema.ModifyTable:
+			return slices.ContainsFunc(other.Changes, func(change schema.Change) bool {
+				switch change := change.(type) {
+				case *schema.DropIndex:
+					return c.usedBy(change.I)
+				case *schema.ModifyIndex:
+					return c.usedBy(change.From)
+				}
+				return false
+			})
+		}
+	}
+	return false
+}
+
+// DependencyOf implements the sqlx.Depender interface. Tables
+// with indexes that use the operator class are created after it.
+func (c *OpClass) DependencyOf(change, other schema.Change) bool {
+	switch change.(type) {
+	case *schema.AddObject, *schema.ModifyObject:
+	default:
+		return false
+	}
+	switch other := other.(type) {
+	case *schema.AddTable:
+		return c.usedBy(other.T.Indexes...)
+	case *schema.ModifyTable:
+		return slices.ContainsFunc(other.Changes, func(change schema.Change) bool {
+			switch change := change.(type) {
+			case *schema.AddIndex:
+				return c.usedBy(change.I)
+			case *schema.ModifyIndex:
+				return c.usedBy(change.To)
+			}
+			return false
+		})
+	}
+	return false
+}
+
+// findSequence returns the sequence with the given name from the schema, if exists.
+func findSequence(s *schema.Schema, name string) (*Sequence, bool) {
+	o, ok := s.Object(func(o schema.Object) bool {
+		seq, ok := o.(*Sequence)
+		return ok && seq.Name == name
+	})
+	if !ok {
+		return nil, false
+	}
+	return o.(*Sequence), true
+}
+
+// seqEqual reports if the two standalone sequences are equal.
+func seqEqual(s1, s2 *Sequence) bool {
+	min1, max1 := seqBounds(s1)
+	min2, max2 := seqBounds(s2)
+	return seqType(s1) == seqType(s2) && seqStart(s1) == seqStart(s2) && seqIncrement(s1) == seqIncrement(s2) &&
+		min1 == min2 && max1 == max2 && seqCache(s1) == seqCache(s2) && s1.Cycle == s2.Cycle &&
+		seqOwner(s1) == seqOwner(s2) && seqComment(s1) == seqComment(s2)
+}
+
+// seqType returns the data type of the sequence. Defaults to bigint.
+func seqType(s *Sequence) string {
+	if t, ok := s.Type.(*schema.IntegerType); ok {
+		switch strings.ToLower(t.T) {
+		case TypeSmallInt, TypeInt2:
+			return TypeSmallInt
+		case TypeInteger, TypeInt, TypeInt4:
+			return TypeInteger
+		}
+	}
+	return TypeBigInt
+}
+
+// seqIncrement returns the increment of the sequence. Defaults to 1.
+func seqIncrement(s *Sequence) int64 {
+	if s.Increment == 0 {
+		return defaultSeqIncrement
+	}
+	return s.Increment
+}
+
+// seqStart returns the start value of the sequence. Defaults
+// to the minimum value for ascending sequences, and the
+// maximum value for descending sequences.
+func seqStart(s *Sequence) int64 {
+	if s.Start != 0 {
+		return s.Start
+	}
+	minv, maxv := seqBounds(s)
+	if seqIncrement(s) < 0 {
+		return maxv
+	}
+	return minv
+}
+
+// seqBounds returns the minimum and maximum values of the sequence.
+func seqBounds(s *Sequence) (int64, int64) {
+	minv, maxv := seqMinMax(&Sequence{Type: s.Type, Increment: seqIncrement(s)})
+	if s.Min != nil {
+		minv = *s.Min
+	}
+	if s.Max != nil {
+		maxv = *s.Max
+	}
+	return minv, maxv
+}
+
+// seqCache returns the cache size of the sequence. Defaults to 1.
+func seqCache(s *Sequence) int64 {
+	if s.Cache == 0 {
+		return 1
+	}
+	return s.Cache
+}
+
+// seqOwner returns the owner of the sequence in the format of "table.column".
+func seqOwner(s *Sequence) string {
+	if s.Owner.T == nil || s.Owner.C == nil {
+		return ""
+	}
+	return s.Owner.T.Name + "." + s.Owner.C.Name
+}
+
+// seqComment returns the comment of the sequence, if exists.
+func seqComment(s *Sequence) string {
+	var c schema.Comment
+	sqlx.Has(s.Attrs, &c)
+	return c.Text
+}
+
+func verifyChanges(context.Context, []schema.Change) error {
+	return nil // unimplemented.
+}
+
+// convertDomains converts the domain specs into domain types, and links
+// the table columns that reference them to the created domain objects.
+func convertDomains(tables []*sqlspec.Table, domains []*domain, r *schema.Realm) error {
+	if len(domains) == 0 {
+		return nil
+	}
+	for _, spec := range domains {
+		ns, err := specutil.SchemaName(spec.Schema)
+		if err != nil {
+			return fmt.Errorf("extract schema name from domain reference: %w", err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return fmt.Errorf("schema %q defined on domain %q was not found in realm", ns, spec.Name)
+		}
+		if spec.Type == nil {
+			return fmt.Errorf("missing type definition for domain %q", spec.Name)
+		}
+		d := &DomainType{T: spec.Name, Schema: s, Null: spec.Null}
+		if spec.Type.IsRefTo("enum") {
+			n, err := enumName(spec.Type)
+			if err != nil {
+				return err
+			}
+			o, ok := s.Object(func(o schema.Object) bool {
+				e, ok := o.(*schema.EnumType)
+				return ok && e.T == n
+			})
+			if !ok {
+				return fmt.Errorf("enum %q defined on domain %q was not found in schema %q", n, spec.Name, ns)
+			}
+			d.Type, d.Deps = o.(*schema.EnumType), append(d.Deps, o)
+		} else if d.Type, err = TypeRegistry.Type(spec.Type, nil); err != nil {
+			return fmt.Errorf("convert type of domain %q: %w", spec.Name, err)
+		}
+		if !spec.Default.IsNull() {
+			if d.Default, err = specutil.Default(spec.Default); err != nil {
+				return fmt.Errorf("convert default value of domain %q: %w", spec.Name, err)
+			}
+		}
+		for _, c := range spec.Checks {
+			ck, err := specutil.Check(c)
+			if err != nil {
+				return err
+			}
+			d.Checks = append(d.Checks, ck)
+		}
+		if c, ok := spec.Attr("comment"); ok {
+			v, err := c.String()
+			if err != nil {
+				return fmt.Errorf("extract comment of domain %q: %w", spec.Name, err)
+			}
+			d.Attrs = append(d.Attrs, &schema.Comment{Text: v})
+		}
+		s.AddObjects(d)
+	}
+	return linkColumnTypes(tables, r, "domain", func(s *schema.Schema, name string) (schema.Type, bool) {
+		return findDomain(s, name)
+	})
+}
+
+// convertComposites converts the composite type specs into composite types, and links
+// the table columns that reference them to the created composite objects.
+func convertComposites(tables []*sqlspec.Table, composites []*composite, r *schema.Realm) error {
+	if len(composites) == 0 {
+		return nil
+	}
+	types := make([]*CompositeType, 0, len(composites))
+	for _, spec := range composites {
+		ns, err := specutil.SchemaName(spec.Schema)
+		if err != nil {
+			return fmt.Errorf("extract schema name from composite reference: %w", err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return fmt.Errorf("schema %q defined on composite %q was not found in realm", ns, spec.Name)
+		}
+		c := &CompositeType{T: spec.Name, Schema: s}
+		if v, ok := spec.Attr("comment"); ok {
+			cm, err := v.String()
+			if err != nil {
+				return fmt.Errorf("extract comment of composite %q: %w", spec.Name, err)
+			}
+			c.Attrs = append(c.Attrs, &schema.Comment{Text: cm})
+		}
+		s.AddObjects(c)
+		types = append(types, c)
+	}
+	// Fields are converted after all types were added,
+	// as they may reference other composite types.
+	for i, spec := range composites {
+		c := types[i]
+		for _, fs := range spec.Fields {
+			if fs.Type == nil {
+				return fmt.Errorf("missing type definition for field %q in composite %q", fs.Name, spec.Name)
+			}
+			f := &schema.Column{Name: fs.Name, Type: &schema.ColumnType{Null: true}}
+			t, err := refType(r, c.Schema, fs.Type)
+			switch {
+			case err != nil:
+				return fmt.Errorf("convert type of field %q in composite %q: %w", fs.Name, spec.Name, err)
+			case t != nil:
+				f.Type.Type, c.Deps = t, append(c.Deps, t.(schema.Object))
+			default:
+				if f.Type.Type, err = TypeRegistry.Type(fs.Type, nil); err != nil {
+					return fmt.Errorf("convert type of field %q in composite %q: %w", fs.Name, spec.Name, err)
+				}
+			}
+			if v, ok := fs.Attr("collate"); ok {
+				cl, err := v.String()
+				if err != nil {
+					return fmt.Errorf("extract collation of field %q in composite %q: %w", fs.Name, spec.Name, err)
+				}
+				f.SetCollation(cl)
+			}
+			c.Fields = append(c.Fields, f)
+		}
+	}
+	return linkColumnTypes(tables, r, "composite", func(s *schema.Schema, name string) (schema.Type, bool) {
+		return findComposite(s, name)
+	})
+}
+
+// convertRanges converts the range type specs into range types, and links
+// the table columns that reference them to the created range objects.
+func convertRanges(tables []*sqlspec.Table, ranges []*rangeType, r *schema.Realm) error {
+	if len(ranges) == 0 {
+		return nil
+	}
+	for _, spec := range ranges {
+		ns, err := specutil.SchemaName(spec.Schema)
+		if err != nil {
+			return fmt.Errorf("extract schema name from range reference: %w", err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return fmt.Errorf("schema %q defined on range %q was not found in realm", ns, spec.Name)
+		}
+		if spec.Subtype == nil {
+			return fmt.Errorf("missing subtype definition for range %q", spec.Name)
+		}
+		rt := &UserRangeType{T: spec.Name, Schema: s}
+		t, err := refType(r, s, spec.Subtype)
+		switch {
+		case err != nil:
+			return fmt.Errorf("convert subtype of range %q: %w", spec.Name, err)
+		case t != nil:
+			rt.Subtype, rt.Deps = t, append(rt.Deps, t.(schema.Object))
+		default:
+			if rt.Subtype, err = TypeRegistry.Type(spec.Subtype, nil); err != nil {
+				return fmt.Errorf("convert subtype of range %q: %w", spec.Name, err)
+			}
+		}
+		for _, a := range []struct {
+			name string
+			v    *string
+		}{
+			{"collate", &rt.Collation},
+			{"canonical", &rt.Canonical},
+			{"subtype_diff", &rt.SubtypeDiff},
+		} {
+			if v, ok := spec.Attr(a.name); ok {
+				if *a.v, err = v.String(); err != nil {
+					return fmt.Errorf("extract %s of range %q: %w", a.name, spec.Name, err)
+				}
+			}
+		}
+		if v, ok := spec.Attr("comment"); ok {
+			cm, err := v.String()
+			if err != nil {
+				return fmt.Errorf("extract comment of range %q: %w", spec.Name, err)
+			}
+			rt.Attrs = append(rt.Attrs, &schema.Comment{Text: cm})
+		}
+		s.AddObjects(rt)
+	}
+	return linkColumnTypes(tables, r, "range", func(s *schema.Schema, name string) (schema.Type, bool) {
+		return findRange(s, name)
+	})
+}
+
+// convertCollations converts the collation specs into collation objects.
+func convertCollations(collations []*collation, r *schema.Realm) error {
+	for _, spec := range collations {
+		ns, err := specutil.SchemaName(spec.Schema)
+		if err != nil {
+			return fmt.Errorf("extract schema name from collation reference: %w", err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return fmt.Errorf("schema %q defined on collation %q was not found in realm", ns, spec.Name)
+		}
+		c := &Collation{Name: spec.Name, Schema: s, Deterministic: true}
+		for _, a := range []struct {
+			name string
+			v    *string
+		}{
+			{"provider", &c.Provider},
+			{"locale", &c.Locale},
+			{"lc_collate", &c.LcCollate},
+			{"lc_ctype", &c.LcCtype},
+		} {
+			if v, ok := spec.Attr(a.name); ok {
+				if *a.v, err = v.String(); err != nil {
+					return fmt.Errorf("extract %s of collation %q: %w", a.name, spec.Name, err)
+				}
+			}
+		}
+		switch {
+		case c.Locale == "" && (c.LcCollate == "" || c.LcCtype == ""):
+			return fmt.Errorf("collation %q must define a locale, or both lc_collate and lc_ctype", spec.Name)
+		case c.Locale != "" && (c.LcCollate != "" || c.LcCtype != ""):
+			return fmt.Errorf("collation %q cannot define both locale and lc_collate or lc_ctype", spec.Name)
+		// Normalize identical categories to a single locale, as it is inspected.
+		case c.Locale == "" && c.LcCollate == c.LcCtype:
+			c.Locale, c.LcCollate, c.LcCtype = c.LcCollate, "", ""
+		}
+		if v, ok := spec.Attr("deterministic"); ok {
+			if c.Deterministic, err = v.Bool(); err != nil {
+				return fmt.Errorf("extract deterministic of collation %q: %w", spec.Name, err)
+			}
+		}
+		if v, ok := spec.Attr("comment"); ok {
+			cm, err := v.String()
+			if err != nil {
+				return fmt.Errorf("extract comment of collation %q: %w", spec.Name, err)
+			}
+			c.Attrs = append(c.Attrs, &schema.Comment{Text: cm})
+		}
+		s.AddObjects(c)
+	}
+	return nil
+}
+
+// convertPartitions converts the partition specs into partition objects. The parent
+// of a partition (the "of" attribute) is either a table or another partition.
+func convertPartitions(specs []*tablePartition, r *schema.Realm) error {
+	parts := make([]*TablePartition, 0, len(specs))
+	for _, spec := range specs {
+		ns, err := specutil.SchemaName(spec.Schema)
+		if err != nil {
+			return fmt.Errorf("extract schema name from partition reference: %w", err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return fmt.Errorf("schema %q defined on partition %q was not found in realm", ns, spec.Name)
+		}
+		if spec.Bound == "" {
+			return fmt.Errorf("missing bound for partition %q", spec.Name)
+		}
+		p := &TablePartition{Name: spec.Name, Schema: s, Bound: spec.Bound}
+		if v, ok := spec.Attr("partition_by"); ok {
+			if p.PartitionBy, err = v.String(); err != nil {
+				return fmt.Errorf("extract partition_by of partition %q: %w", spec.Name, err)
+			}
+		}
+		if v, ok := spec.Attr("comment"); ok {
+			c, err := v.String()
+			if err != nil {
+				return fmt.Errorf("extract comment of partition %q: %w", spec.Name, err)
+			}
+			p.Attrs = append(p.Attrs, &schema.Comment{Text: c})
+		}
+		s.AddObjects(p)
+		parts = append(parts, p)
+	}
+	// Parents are resolved after all partitions were added,
+	// as partitions may reference partitions defined after them.
+	for i, spec := range specs {
+		p := parts[i]
+		if spec.Of == nil {
+			return fmt.Errorf("missing parent for partition %q", spec.Name)
+		}
+		typ := "table"
+		if path, err := spec.Of.Path(); err == nil && len(path) > 0 && path[0].T == "table_partition" {
+			typ = "table_partition"
+		}
+		q, name, err := specutil.RefName(spec.Of, typ)
+		if err != nil {
+			return fmt.Errorf("extract parent of partition %q: %w", spec.Name, err)
+		}
+		ns := p.Schema
+		if q != "" {
+			if ns, _ = r.Schema(q); ns == nil {
+				return fmt.Errorf("schema %q of the parent of partition %q was not found in realm", q, spec.Name)
+			}
+		}
+		if typ == "table" {
+			if p.Table, _ = ns.Table(name); p.Table == nil {
+				return fmt.Errorf("parent table %q of partition %q was not found", name, spec.Name)
+			}
+		} else if p.Parent, _ = findPartition(ns, name); p.Parent == nil {
+			return fmt.Errorf("parent partition %q of partition %q was not found", name, spec.Name)
+		}
+	}
+	return nil
+}
+
+// convertTextSearch converts the text search dictionary and configuration specs into objects.
+// Names of templates, parsers and dictionaries in pg_catalog are stored unqualified, as they
+// are inspected.
+func convertTextSearch(dicts []*tsDict, configs []*tsConfig, r *schema.Realm) error {
+	for _, spec := range dicts {
+		ns, err := specutil.SchemaName(spec.Schema)
+		if err != nil {
+			return fmt.Errorf("extract schema name from text search dictionary reference: %w", err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return fmt.Errorf("schema %q defined on text search dictionary %q was not found in realm", ns, spec.Name)
+		}
+		d := &TextSearchDict{Name: spec.Name, Schema: s}
+		a, ok := spec.Attr("template")
+		if !ok {
+			return fmt.Errorf("missing template for text search dictionary %q", spec.Name)
+		}
+		if d.Template, err = a.String(); err != nil {
+			return fmt.Errorf("extract template of text search dictionary %q: %w", spec.Name, err)
+		}
+		d.Template = strings.TrimPrefix(d.Template, "pg_catalog.")
+		if d.Options, err = convertOptions(&spec.Extra); err != nil {
+			return fmt.Errorf("extract options of text search dictionary %q: %w", spec.Name, err)
+		}
+		if v, ok := spec.Attr("comment"); ok {
+			c, err := v.String()
+			if err != nil {
+				return fmt.Errorf("extract comment of text search dictionary %q: %w", spec.Name, err)
+			}
+			d.Attrs = append(d.Attrs, &schema.Comment{Text: c})
+		}
+		s.AddObjects(d)
+	}
+	for _, spec := range configs {
+		ns, err := specutil.SchemaName(spec.Schema)
+		if err != nil {
+			return fmt.Errorf("extract schema name from text search configuration reference: %w", err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return fmt.Errorf("schema %q defined on text search configuration %q was not found in realm", ns, spec.Name)
+		}
+		c := &TextSearchConfig{Name: spec.Name, Schema: s, Parser: "default", Mappings: make(map[string][]string)}
+		if v, ok := spec.Attr("parser"); ok {
+			if c.Parser, err = v.String(); err != nil {
+				return fmt.Errorf("extract parser of text search configuration %q: %w", spec.Name, err)
+			}
+			c.Parser = strings.TrimPrefix(c.Parser, "pg_catalog.")
+		}
+		for _, m := range spec.Extra.Resources("mapping") {
+			var tokens, dicts []string
+			if v, ok := m.Attr("tokens"); ok {
+				if tokens, err = v.Strings(); err != nil {
+					return fmt.Errorf("extract mapping tokens of text search configuration %q: %w", spec.Name, err)
+				}
+			}
+			if v, ok := m.Attr("dictionaries"); ok {
+				if dicts, err = v.Strings(); err != nil {
+					return fmt.Errorf("extract mapping dictionaries of text search configuration %q: %w", spec.Name, err)
+				}
+			}
+			if len(tokens) == 0 || len(dicts) == 0 {
+				return fmt.Errorf("mapping of text search configuration %q must define tokens and dictionaries", spec.Name)
+			}
+			for i := range dicts {
+				dicts[i] = strings.TrimPrefix(dicts[i], "pg_catalog.")
+			}
+			for _, t := range tokens {
+				if _, ok := c.Mappings[t]; ok {
+					return fmt.Errorf("token %q is mapped more than once in text search configuration %q", t, spec.Name)
+				}
+				c.Mappings[t] = dicts
+			}
+		}
+		if v, ok := spec.Attr("comment"); ok {
+			cm, err := v.String()
+			if err != nil {
+				return fmt.Errorf("extract comment of text search configuration %q: %w", spec.Name, err)
+			}
+			c.Attrs = append(c.Attrs, &schema.Comment{Text: cm})
+		}
+		s.AddObjects(c)
+	}
+	return nil
+}
+
+// convertOperators converts the operator, operator family and operator class specs into objects.
+func convertOperators(d *doc, r *schema.Realm) error {
+	// specSchema returns the schema of the given object spec.
+	specSchema := func(kind, name string, ref *schemahcl.Ref) (*schema.Schema, error) {
+		ns, err := specutil.SchemaName(ref)
+		if err != nil {
+			return nil, fmt.Errorf("extract schema name from %s reference: %w", kind, err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return nil, fmt.Errorf("schema %q defined on %s %q was not found in realm", ns, kind, name)
+		}
+		return s, nil
+	}
+	// stringAttrs sets the string attributes of the given spec, and returns the comment, if exists.
+	stringAttrs := func(kind, name string, r *schemahcl.Resource, attrs map[string]*string) ([]schema.Attr, error) {
+		for k, v := range attrs {
+			if a, ok := r.Attr(k); ok {
+				s, err := a.String()
+				if err != nil {
+					return nil, fmt.Errorf("extract %s of %s %q: %w", k, kind, name, err)
+				}
+				*v = s
+			}
+		}
+		if a, ok := r.Attr("comment"); ok {
+			c, err := a.String()
+			if err != nil {
+				return nil, fmt.Errorf("extract comment of %s %q: %w", kind, name, err)
+			}
+			return []schema.Attr{&schema.Comment{Text: c}}, nil
+		}
+		return nil, nil
+	}
+	for _, spec := range d.Operators {
+		s, err := specSchema("operator", spec.Name, spec.Schema)
+		if err != nil {
+			return err
+		}
+		o := &Operator{Name: spec.Name, Schema: s}
+		if o.Attrs, err = stringAttrs("operator", spec.Name, &spec.Extra, map[string]*string{
+			"left":       &o.Left,
+			"right":      &o.Right,
+			"function":   &o.Function,
+			"commutator": &o.Commutator,
+			"negator":    &o.Negator,
+			"restrict":   &o.Restrict,
+			"join":       &o.Join,
+		}); err != nil {
+			return err
+		}
+		if o.Right == "" || o.Function == "" {
+			return fmt.Errorf("operator %q must define its right type and function", spec.Name)
+		}
+		for _, a := range []struct {
+			name string
+			v    *bool
+		}{
+			{"hashes", &o.Hashes},
+			{"merges", &o.Merges},
+		} {
+			if v, ok := spec.Attr(a.name); ok {
+				if *a.v, err = v.Bool(); err != nil {
+					return fmt.Errorf("extract %s of operator %q: %w", a.name, spec.Name, err)
+				}
+			}
+		}
+		s.AddObjects(o)
+	}
+	for _, spec := range d.OpFamilies {
+		s, err := specSchema("operator family", spec.Name, spec.Schema)
+		if err != nil {
+			return err
+		}
+		f := &OpFamily{Name: spec.Name, Schema: s}
+		if f.Attrs, err = stringAttrs("operator family", spec.Name, &spec.Extra, map[string]*string{"method": &f.Method}); err != nil {
+			return err
+		}
+		if f.Method == "" {
+			return fmt.Errorf("missing method for operator family %q", spec.Name)
+		}
+		s.AddObjects(f)
+	}
+	for _, spec := range d.OpClasses {
+		s, err := specSchema("operator class", spec.Name, spec.Schema)
+		if err != nil {
+			return err
+		}
+		c := &OpClass{Name: spec.Name, Schema: s}
+		if c.Attrs, err = stringAttrs("operator class", spec.Name, &spec.Extra, map[string]*string{
+			"method":  &c.Method,
+			"type":    &c.Type,
+			"family":  &c.Family,
+			"storage": &c.Storage,
+		}); err != nil {
+			return err
+		}
+		if c.Method == "" || c.Type == "" {
+			return fmt.Errorf("operator class %q must define its method and type", spec.Name)
+		}
+		if v, ok := spec.Attr("default"); ok {
+			if c.Default, err = v.Bool(); err != nil {
+				return fmt.Errorf("extract default of operator class %q: %w", spec.Name, err)
+			}
+		}
+		for _, r := range spec.Extra.Resources("operator") {
+			op := &OpClassOperator{}
+			if _, err := stringAttrs("operator class", spec.Name, r, map[string]*string{"name": &op.Name, "order_by": &op.OrderBy}); err != nil {
+				return err
+			}
+			if v, ok := r.Attr("strategy"); ok {
+				if op.Strategy, err = v.Int(); err != nil {
+					return fmt.Errorf("extract operator strategy of operator class %q: %w", spec.Name, err)
+				}
+			}
+			if op.Strategy <= 0 || op.Name == "" {
+				return fmt.Errorf("operator of operator class %q must define its strategy and name", spec.Name)
+			}
+			c.Operators = append(c.Operators, op)
+		}
+		for _, r := range spec.Extra.Resources("function") {
+			fn := &OpClassFunc{}
+			if _, err := stringAttrs("operator class", spec.Name, r, map[string]*string{"name": &fn.Name}); err != nil {
+				return err
+			}
+			if v, ok := r.Attr("number"); ok {
+				if fn.Number, err = v.Int(); err != nil {
+					return fmt.Errorf("extract function number of operator class %q: %w", spec.Name, err)
+				}
+			}
+			if fn.Number <= 0 || fn.Name == "" {
+				return fmt.Errorf("function of operator class %q must define its number and name", spec.Name)
+			}
+			c.Funcs = append(c.Funcs, fn)
+		}
+		s.AddObjects(c)
+	}
+	return nil
+}
+
+// refType returns the user-defined type (enum, domain, composite or range) that is referenced by the
+// spec type. A nil type is returned if the spec type does not reference a user-defined type.
+func refType(r *schema.Realm, ns *schema.Schema, t *schemahcl.Type) (schema.Type, error) {
+	var kind string
+	switch {
+	case t.IsRefTo("enum"):
+		kind = "enum"
+	case t.IsRefTo("domain"):
+		kind = "domain"
+	case t.IsRefTo("composite"):
+		kind = "composite"
+	case t.IsRefTo("range"):
+		kind = "range"
+	default:
+		return nil, nil
+	}
+	q, n, err := specutil.RefName(&schemahcl.Ref{V: t.T}, kind)
+	if err != nil {
+		return nil, err
+	}
+	if q != "" {
+		var ok bool
+		if ns, ok = r.Schema(q); !ok {
+			return nil, fmt.Errorf("schema %q of %s %q was not found in realm", q, kind, n)
+		}
+	}
+	o, ok := ns.Object(func(o schema.Object) bool {
+		switch o := o.(type) {
+		case *schema.EnumType:
+			return kind == "enum" && o.T == n
+		case *DomainType:
+			return kind == "domain" && o.T == n
+		case *CompositeType:
+			return kind == "composite" && o.T == n
+		case *UserRangeType:
+			return kind == "range" && o.T == n
+		}
+		return false
+	})
+	if !ok {
+		return nil, fmt.Errorf("%s %q was not found in schema %q", kind, n, ns.Name)
+	}
+	return o.(schema.Type), nil
+}
+
+// linkColumnTypes links the table columns that reference a user-defined type of the
+// given kind (e.g., domain) to the type object that is returned by the find function.
+func linkColumnTypes(tables []*sqlspec.Table, r *schema.Realm, kind string, find func(*schema.Schema, string) (schema.Type, bool)) error {
+	for _, t := range tables {
+		for _, c := range t.Columns {
+			if !c.Type.IsRefTo(kind) {
+				continue
+			}
+			q, n, err := specutil.RefName(&schemahcl.Ref{V: c.Type.T}, kind)
+			if err != nil {
+				return err
+			}
+			ns, err := specutil.SchemaName(t.Schema)
+			if err != nil {
+				return fmt.Errorf("extract schema name from table reference: %w", err)
+			}
+			ts, ok := r.Schema(ns)
+			if !ok {
+				return fmt.Errorf("schema %q not found in realm for table %q", ns, t.Name)
+			}
+			ds := ts
+			if q != "" {
+				if ds, ok = r.Schema(q); !ok {
+					return fmt.Errorf("schema %q of %s %q was not found in realm", q, kind, n)
+				}
+			}
+			o, ok := find(ds, n)
+			if !ok {
+				return fmt.Errorf("%s %q was not found in schema %q", kind, n, ds.Name)
+			}
+			tt, ok := ts.Table(t.Name)
+			if !ok {
+				return fmt.Errorf("table %q not found in schema %q", t.Name, ts.Name)
+			}
+			cc, ok := tt.Column(c.Name)
+			if !ok {
+				return fmt.Errorf("column %q not found in table %q", c.Name, t.Name)
+			}
+			cc.Type.Type = o
+		}
+	}
+	return nil
+}
+
+// convertAggregate converts the aggregate specs into aggregate objects. User-defined
+// types that are used by the aggregates are resolved in the same way as functions.
+func convertAggregate(d *doc, r *schema.Realm) error {
+	for _, spec := range d.Aggregates {
+		ns, err := specutil.SchemaName(spec.Schema)
+		if err != nil {
+			return fmt.Errorf("extract schema name from aggregate reference: %w", err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return fmt.Errorf("schema %q defined on aggregate %q was not found in realm", ns, spec.Name)
+		}
+		a := &Aggregate{Name: spec.Name, Schema: s}
+		if a.Args, _, err = convertRoutine(&sqlspec.Func{Args: spec.Args}); err != nil {
+			return fmt.Errorf("aggregate %q: %w", spec.Name, err)
+		}
+		for _, fa := range a.Args {
+			fa.Type, a.Deps = resolveFuncType(s, fa.Type, a.Deps)
+		}
+		st, ok := spec.Attr("state_type")
+		if !ok {
+			return fmt.Errorf("missing state_type for aggregate %q", spec.Name)
+		}
+		t, err := st.Type()
+		if err != nil {
+			return fmt.Errorf("reading state_type of aggregate %q: %w", spec.Name, err)
+		}
+		if a.StateType, err = funcTypeOf(t); err != nil {
+			return fmt.Errorf("converting state_type of aggregate %q: %w", spec.Name, err)
+		}
+		a.StateType, a.Deps = resolveFuncType(s, a.StateType, a.Deps)
+		for _, at := range []struct {
+			name string
+			v    *string
+		}{
+			{"state_func", &a.StateFunc},
+			{"final_func", &a.FinalFunc},
+		} {
+			if v, ok := spec.Attr(at.name); ok {
+				if *at.v, err = v.String(); err != nil {
+					return fmt.Errorf("extract %s of aggregate %q: %w", at.name, spec.Name, err)
+				}
+			}
+		}
+		if a.StateFunc == "" {
+			return fmt.Errorf("missing state_func for aggregate %q", spec.Name)
+		}
+		if v, ok := spec.Attr("initial_condition"); ok {
+			c, err := v.String()
+			if err != nil {
+				return fmt.Errorf("extract initial_condition of aggregate %q: %w", spec.Name, err)
+			}
+			a.InitCond = &c
+		}
+		if v, ok := spec.Attr("parallel"); ok {
+			p, err := v.String()
+			if err != nil {
+				return fmt.Errorf("extract parallel of aggregate %q: %w", spec.Name, err)
+			}
+			a.Parallel = strings.ToUpper(p)
+		}
+		if v, ok := spec.Attr("comment"); ok {
+			c, err := v.String()
+			if err != nil {
+				return fmt.Errorf("extract comment of aggregate %q: %w", spec.Name, err)
+			}
+			a.Attrs = append(a.Attrs, &schema.Comment{Text: c})
+		}
+		s.AddObjects(a)
+	}
+	return nil
+}
+
+func convertSequences(_ []*sqlspec.Table, seqs []*sqlspec.Sequence, _ *schema.Realm) error {
+	if len(seqs) > 0 {
+		return fmt.Errorf("postgres: sequences are not supported by this version. Use: https://atlasgo.io/getting-started")
+	}
+	return nil
+}
+
+func convertPolicies(_ []*sqlspec.Table, ps []*policy, _ *schema.Realm) error {
+	if len(ps) > 0 {
+		return fmt.Errorf("postgres: policies are not supported by this version. Use: https://atlasgo.io/getting-started")
+	}
+	return nil
+}
+
+func convertExtensions(exs []*extension, r *schema.Realm) error {
+	for _, x := range exs {
+		if _, ok := findExtension(r, x.Name); ok {
+			return fmt.Errorf("postgres: extension %q is defined more than once", x.Name)
+		}
+		e := &Extension{Name: x.Name}
+		if a, ok := x.Attr("schema"); ok {
+			var err error
+			if a.IsRef() {
+				var ref string
+				if ref, err = a.Ref(); err == nil {
+					e.Schema, err = specutil.SchemaName(&schemahcl.Ref{V: ref})
+				}
+			} else {
+				e.Schema, err = a.String()
+			}
+			if err != nil {
+				return fmt.Errorf("postgres: reading schema of extension %q: %w", x.Name, err)
+			}
+		}
+		if a, ok := x.Attr("version"); ok {
+			v, err := a.String()
+			if err != nil {
+				return fmt.Errorf("postgres: reading version of extension %q: %w", x.Name, err)
+			}
+			e.Version = v
+		}
+		if a, ok := x.Attr("comment"); ok {
+			c, err := a.String()
+			if err != nil {
+				return fmt.Errorf("postgres: reading comment of extension %q: %w", x.Name, err)
+			}
+			e.Attrs = append(e.Attrs, &schema.Comment{Text: c})
+		}
+		r.AddObjects(e)
+	}
+	return nil
+}
+
+// convertFunc converts the function spec to a schema function. User-defined types
+// (e.g., enums) are resolved by convertFuncTypes after they were added to the realm.
+func convertFunc(spec *sqlspec.Func, s *schema.Schema) (*schema.Func, error) {
+	args, lang, err := convertRoutine(spec)
+	if err != nil {
+		return nil, err
+	}
+	f := &schema.Func{Name: spec.Name, Schema: s, Args: args, Lang: lang}
+	schemahcl.AppendPos(&f.Attrs, spec.Range)
+	a, ok := spec.Attr("return")
+	if !ok {
+		return nil, errors.New("missing 'return' attribute")
+	}
+	t, err := a.Type()
+	if err != nil {
+		return nil, fmt.Errorf("reading return type: %w", err)
+	}
+	if f.Ret, err = funcTypeOf(t); err != nil {
+		return nil, fmt.Errorf("converting return type: %w", err)
+	}
+	if f.Body, err = routineAs(spec); err != nil {
+		return nil, err
+	}
+	if a, ok := spec.Attr("volatility"); ok {
+		v, err := a.String()
+		if err != nil {
+			return nil, fmt.Errorf("reading volatility: %w", err)
+		}
+		f.Attrs = append(f.Attrs, &FuncVolatility{V: strings.ToUpper(v)})
+	}
+	if c, err := routineComment(spec); err != nil {
+		return nil, err
+	} else if c != nil {
+		f.Attrs = append(f.Attrs, c)
+	}
+	return f, nil
+}
+
+// convertProc converts the procedure spec to a schema procedure.
+func convertProc(spec *sqlspec.Func, s *schema.Schema) (*schema.Proc, error) {
+	args, lang, err := convertRoutine(spec)
+	if err != nil {
+		return nil, err
+	}
+	p := &schema.Proc{Name: spec.Name, Schema: s, Args: args, Lang: lang}
+	schemahcl.AppendPos(&p.Attrs, spec.Range)
+	if p.Body, err = routineAs(spec); err != nil {
+		return nil, err
+	}
+	if c, err := routineComment(spec); err != nil {
+		return nil, err
+	} else if c != nil {
+		p.Attrs = append(p.Attrs, c)
+	}
+	return p, nil
+}
+
+// convertRoutine converts the arguments and the language of a function or a procedure.
+func convertRoutine(spec *sqlspec.Func) ([]*schema.FuncArg, string, error) {
+	var lang string
+	if !spec.Lang.IsNull() {
+		if spec.Lang.Type() != cty.String {
+			return nil, "", errors.New("expect 'lang' to be a string")
+		}
+		lang = spec.Lang.AsString()
+	}
+	args := make([]*schema.FuncArg, 0, len(spec.Args))
+	for _, a := range spec.Args {
+		if a.Type == nil {
+			return nil, "", fmt.Errorf("missing type for argument %q", a.Name)
+		}
+		t, err := funcTypeOf(a.Type)
+		if err != nil {
+			return nil, "", fmt.Errorf("converting type of argument %q: %w", a.Name, err)
+		}
+		arg := &schema.FuncArg{Name: a.Name, Type: t}
+		if arg.Default, err = specutil.Default(a.Default); err != nil {
+			return nil, "", fmt.Errorf("converting default value of argument %q: %w", a.Name, err)
+		}
+		if m, ok := a.Attr("mode"); ok {
+			v, err := m.String()
+			if err != nil {
+				return nil, "", fmt.Errorf("reading mode of argument %q: %w", a.Name, err)
+			}
+			arg.Mode = schema.FuncArgMode(strings.ToUpper(v))
+		}
+		args = append(args, arg)
+	}
+	return args, lang, nil
+}
+
+// funcTypeOf returns the schema type of an argument or a return type spec. References
+// to user-defined types are resolved after all types were added to the realm.
+func funcTypeOf(t *schemahcl.Type) (schema.Type, error) {
+	if !t.IsRef {
+		return TypeRegistry.Type(t, nil)
+	}
+	path, err := (&schemahcl.Ref{V: t.T}).Path()
+	if err != nil {
+		return nil, err
+	}
+	if len(path) == 0 || len(path[len(path)-1].V) == 0 {
+		return nil, fmt.Errorf("unexpected type reference %q", t.T)
+	}
+	return &UserDefinedType{T: strings.Join(path[len(path)-1].V, ".")}, nil
+}
+
+// resolveFuncType resolves the user-defined type that is used by a function (or an aggregate),
+// and appends it to the given dependencies, if it is a schema object.
+func resolveFuncType(s *schema.Schema, t schema.Type, deps []schema.Object) (schema.Type, []schema.Object) {
+	if u, ok := t.(*UserDefinedType); ok {
+		t = (&inspect{conn: &conn{}}).underlyingType(s, u)
+	}
+	if o, ok := t.(schema.Object); ok && !slices.Contains(deps, o) {
+		deps = append(deps, o)
+	}
+	return t, deps
+}
+
+// convertFuncTypes resolves the user-defined types that are used by the functions and procedures.
+func convertFuncTypes(r *schema.Realm) {
+	for _, s := range r.Schemas {
+		for _, f := range s.Funcs {
+			for _, a := range f.Args {
+				a.Type, f.Deps = resolveFuncType(s, a.Type, f.Deps)
+			}
+			f.Ret, f.Deps = resolveFuncType(s, f.Ret, f.Deps)
+		}
+		for _, p := range s.Procs {
+			for _, a := range p.Args {
+				a.Type, p.Deps = resolveFuncType(s, a.Type, p.Deps)
+			}
+		}
+	}
+}
+
+// routineAs returns the body of a function or a procedure.
+func routineAs(spec *sqlspec.Func) (string, error) {
+	a, ok := spec.Attr("as")
+	if !ok {
+		return "", errors.New("missing 'as' attribute")
+	}
+	return a.String()
+}
+
+// routineComment returns the comment of a function or a procedure, if defined.
+func routineComment(spec *sqlspec.Func) (*schema.Comment, error) {
+	a, ok := spec.Attr("comment")
+	if !ok {
+		return nil, nil
+	}
+	c, err := a.String()
+	if err != nil {
+		return nil, fmt.Errorf("reading comment: %w", err)
+	}
+	return &schema.Comment{Text: c}, nil
+}
+
+// convertTriggers converts the trigger specs and adds them to their tables or views.
+func convertTriggers(r *schema.Realm, ts []*sqlspec.Trigger) error {
+	for _, spec := range ts {
+		if spec.On == nil {
+			return fmt.Errorf("postgres: missing 'on' attribute for trigger %q", spec.Name)
+		}
+		t := &schema.Trigger{Name: spec.Name, For: schema.TriggerForStmt}
+		schemahcl.AppendPos(&t.Attrs, spec.Range)
+		if q, name, err := specutil.TableName(spec.On); err == nil {
+			tb, err := triggerTarget(r, q, name, func(s *schema.Schema, name string) (*schema.Table, bool) {
+				return s.Table(name)
+			})
+			if err != nil {
+				return fmt.Errorf("postgres: trigger %q: %w", spec.Name, err)
+			}
+			if _, ok := tb.Trigger(spec.Name); ok {
+				return fmt.Errorf("postgres: trigger %q is defined more than once on table %q", spec.Name, tb.Name)
+			}
+			t.Table = tb
+		} else if q, name, err := specutil.RefName(spec.On, "view"); err == nil {
+			v, err := triggerTarget(r, q, name, func(s *schema.Schema, name string) (*schema.View, bool) {
+				return s.View(name)
+			})
+			if err != nil {
+				return fmt.Errorf("postgres: trigger %q: %w", spec.Name, err)
+			}
+			if _, ok := v.Trigger(spec.Name); ok {
+				return fmt.Errorf("postgres: trigger %q is defined more than once on view %q", spec.Name, v.Name)
+			}
+			t.View = v
+		} else {
+			return fmt.Errorf("postgres: unexpected 'on' reference %q for trigger %q", spec.On.V, spec.Name)
+		}
+		var events *schemahcl.Resource
+		for _, at := range []schema.TriggerTime{schema.TriggerTimeBefore, schema.TriggerTimeAfter, schema.TriggerTimeInstead} {
+			typ := strings.ReplaceAll(strings.ToLower(string(at)), " ", "_")
+			if r, ok := spec.Extra.Resource(typ); ok {
+				if events != nil {
+					return fmt.Errorf("postgres: multiple action times were defined for trigger %q", spec.Name)
+				}
+				events, t.ActionTime = r, at
+			}
+		}
+		if events == nil {
+			return fmt.Errorf("postgres: missing action time (before, after or instead_of) for trigger %q", spec.Name)
+		}
+		if err := convertTriggerEvents(events, t); err != nil {
+			return fmt.Errorf("postgres: trigger %q: %w", spec.Name, err)
+		}
+		if a, ok := spec.Extra.Attr("foreach"); ok {
+			v, err := a.String()
+			if err != nil {
+				return fmt.Errorf("postgres: reading foreach of trigger %q: %w", spec.Name, err)
+			}
+			t.For = schema.TriggerFor(strings.ToUpper(v))
+		}
+		if a, ok := spec.Extra.Attr("when"); ok {
+			x, err := a.String()
+			if err != nil {
+				return fmt.Errorf("postgres: reading when of trigger %q: %w", spec.Name, err)
+			}
+			t.Attrs = append(t.Attrs, &TriggerWhen{X: x})
+		}
+		a, ok := spec.Extra.Attr("execute")
+		if !ok {
+			return fmt.Errorf("postgres: missing 'execute' attribute for trigger %q", spec.Name)
+		}
+		body, err := a.String()
+		if err != nil {
+			return fmt.Errorf("postgres: reading execute of trigger %q: %w", spec.Name, err)
+		}
+		t.Body = body
+		if a, ok := spec.Extra.Attr("state"); ok {
+			v, err := a.String()
+			if err != nil {
+				return fmt.Errorf("postgres: reading state of trigger %q: %w", spec.Name, err)
+			}
+			t.Attrs = append(t.Attrs, &TriggerState{V: strings.ToUpper(v)})
+		}
+		if err := checkTrigger(t); err != nil {
+			return err
+		}
+		if t.Table != nil {
+			t.Table.Triggers = append(t.Table.Triggers, t)
+		} else {
+			t.View.Triggers = append(t.View.Triggers, t)
+		}
+	}
+	triggerDeps(r)
+	return nil
+}
+
+// convertTriggerEvents converts the events block of the trigger spec.
+func convertTriggerEvents(r *schemahcl.Resource, t *schema.Trigger) error {
+	var spec struct {
+		Insert   bool             `spec:"insert"`
+		Update   bool             `spec:"update"`
+		UpdateOf []*schemahcl.Ref `spec:"update_of"`
+		Delete   bool             `spec:"delete"`
+		Truncate bool             `spec:"truncate"`
+	}
+	if err := r.As(&spec); err != nil {
+		return err
+	}
+	if spec.Insert {
+		t.Events = append(t.Events, schema.TriggerEventInsert)
+	}
+	switch {
+	case spec.Update && len(spec.UpdateOf) > 0:
+		return errors.New(`both "update" and "update_of" were defined`)
+	case spec.Update:
+		t.Events = append(t.Events, schema.TriggerEventUpdate)
+	case len(spec.UpdateOf) > 0:
+		if t.Table == nil {
+			return errors.New(`"update_of" is supported only for table triggers`)
+		}
+		cs := make([]*schema.Column, len(spec.UpdateOf))
+		for i, ref := range spec.UpdateOf {
+			c, err := specutil.ColumnByRef(t.Table, ref)
+			if err != nil {
+				return err
+			}
+			cs[i] = c
+		}
+		t.Events = append(t.Events, schema.TriggerEventUpdateOf(cs...))
+	}
+	if spec.Delete {
+		t.Events = append(t.Events, schema.TriggerEventDelete)
+	}
+	if spec.Truncate {
+		t.Events = append(t.Events, schema.TriggerEventTruncate)
+	}
+	return nil
+}
+
+// triggerTarget finds the table or the view the trigger is defined on.
+func triggerTarget[T any](r *schema.Realm, qualifier, name string, find func(*schema.Schema, string) (T, bool)) (T, error) {
+	var matches []T
+	for _, s := range r.Schemas {
+		if qualifier != "" && s.Name != qualifier {
+			continue
+		}
+		if t, ok := find(s, name); ok {
+			matches = append(matches, t)
+		}
+	}
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		var zero T
+		return zero, fmt.Errorf("referenced %q was not found", name)
+	default:
+		var zero T
+		return zero, fmt.Errorf("multiple references were found for %q", name)
+	}
+}
+
+func convertEventTriggers(evs []*eventTrigger, r *schema.Realm) error {
+	for _, spec := range evs {
+		if _, ok := findEventTrigger(r, spec.Name); ok {
+			return fmt.Errorf("postgres: event trigger %q is defined more than once", spec.Name)
+		}
+		e := &EventTrigger{Name: spec.Name}
+		a, ok := spec.Attr("on")
+		if !ok {
+			return fmt.Errorf("postgres: missing 'on' attribute for event trigger %q", spec.Name)
+		}
+		v, err := a.String()
+		if err != nil {
+			return fmt.Errorf("postgres: reading on of event trigger %q: %w", spec.Name, err)
+		}
+		e.Event = v
+		if a, ok := spec.Attr("tags"); ok {
+			if e.Tags, err = a.Strings(); err != nil {
+				return fmt.Errorf("postgres: reading tags of event trigger %q: %w", spec.Name, err)
+			}
+		}
+		a, ok = spec.Attr("execute")
+		if !ok {
+			return fmt.Errorf("postgres: missing 'execute' attribute for event trigger %q", spec.Name)
+		}
+		ref, err := a.Ref()
+		if err != nil {
+			return fmt.Errorf("postgres: reading execute of event trigger %q: %w", spec.Name, err)
+		}
+		q, name, err := specutil.RefName(&schemahcl.Ref{V: ref}, "function")
+		if err != nil {
+			return fmt.Errorf("postgres: reading execute of event trigger %q: %w", spec.Name, err)
+		}
+		if e.Func, err = triggerTarget(r, q, name, (*schema.Schema).Func); err != nil {
+			return fmt.Errorf("postgres: function of event trigger %q: %w", spec.Name, err)
+		}
+		if a, ok := spec.Attr("state"); ok {
+			v, err := a.String()
+			if err != nil {
+				return fmt.Errorf("postgres: reading state of event trigger %q: %w", spec.Name, err)
+			}
+			e.Attrs = append(e.Attrs, &TriggerState{V: strings.ToUpper(v)})
+		}
+		if a, ok := spec.Attr("comment"); ok {
+			c, err := a.String()
+			if err != nil {
+				return fmt.Errorf("postgres: reading comment of event trigger %q: %w", spec.Name, err)
+			}
+			e.Attrs = append(e.Attrs, &schema.Comment{Text: c})
+		}
+		r.AddObjects(e)
+	}
+	return nil
+}
+
+// convertPublications converts the publication specs into realm objects.
+func convertPublications(specs []*publication, r *schema.Realm) error {
+	for _, spec := range specs {
+		if _, ok := findPublication(r, spec.Name); ok {
+			return fmt.Errorf("postgres: publication %q is defined more than once", spec.Name)
+		}
+		p := &Publication{Name: spec.Name}
+		if a, ok := spec.Attr("all_tables"); ok {
+			var err error
+			if p.AllTables, err = a.Bool(); err != nil {
+				return fmt.Errorf("postgres: reading all_tables of publication %q: %w", spec.Name, err)
+			}
+		}
+		for _, ts := range spec.Extra.Resources("on") {
+			pt, err := convertPublicationTable(ts, r)
+			if err != nil {
+				return fmt.Errorf("postgres: table of publication %q: %w", spec.Name, err)
+			}
+			p.Tables = append(p.Tables, pt)
+		}
+		if a, ok := spec.Attr("schemas"); ok {
+			refs, err := a.Refs()
+			if err != nil {
+				return fmt.Errorf("postgres: reading schemas of publication %q: %w", spec.Name, err)
+			}
+			for _, ref := range refs {
+				name, err := specutil.SchemaName(ref)
+				if err != nil {
+					return fmt.Errorf("postgres: reading schemas of publication %q: %w", spec.Name, err)
+				}
+				s, ok := r.Schema(name)
+				if !ok {
+					return fmt.Errorf("postgres: schema %q of publication %q was not found", name, spec.Name)
+				}
+				p.Schemas = append(p.Schemas, s)
+			}
+		}
+		if p.AllTables && (len(p.Tables) > 0 || len(p.Schemas) > 0) {
+			return fmt.Errorf("postgres: publication %q cannot publish all tables along with specific tables or schemas", spec.Name)
+		}
+		if a, ok := spec.Attr("publish"); ok {
+			ops, err := a.Strings()
+			if err != nil {
+				return fmt.Errorf("postgres: reading publish of publication %q: %w", spec.Name, err)
+			}
+			for _, op := range ops {
+				if !slices.Contains(PublicationOps, strings.ToLower(op)) {
+					return fmt.Errorf("postgres: unknown operation %q in publication %q", op, spec.Name)
+				}
+			}
+			// Operations are kept in their canonical order, and
+			// publishing all of them is equivalent to the default.
+			p.Publish = make([]string, 0, len(PublicationOps))
+			for _, op := range PublicationOps {
+				if slices.ContainsFunc(ops, func(o string) bool { return strings.EqualFold(o, op) }) {
+					p.Publish = append(p.Publish, op)
+				}
+			}
+			if len(p.Publish) == len(PublicationOps) {
+				p.Publish = nil
+			}
+		}
+		if a, ok := spec.Attr("comment"); ok {
+			c, err := a.String()
+			if err != nil {
+				return fmt.Errorf("postgres: reading comment of publication %q: %w", spec.Name, err)
+			}
+			p.Attrs = append(p.Attrs, &schema.Comment{Text: c})
+		}
+		r.AddObjects(p)
+	}
+	return nil
+}
+
+// convertPublicationTable converts an "on" block of a publication into a published table.
+func convertPublicationTable(spec *schemahcl.Resource, r *schema.Realm) (*PublicationTable, error) {
+	a, ok := spec.Attr("table")
+	if !ok {
+		return nil, errors.New("missing 'table' attribute")
+	}
+	ref, err := a.Ref()
+	if err != nil {
+		return nil, fmt.Errorf("reading table: %w", err)
+	}
+	q, name, err := specutil.RefName(&schemahcl.Ref{V: ref}, "table")
+	if err != nil {
+		return nil, fmt.Errorf("reading table: %w", err)
+	}
+	t, err := triggerTarget(r, q, name, (*schema.Schema).Table)
+	if err != nil {
+		return nil, err
+	}
+	pt := &PublicationTable{Table: t}
+	if a, ok := spec.Attr("columns"); ok {
+		names, err := a.Strings()
+		if err != nil {
+			return nil, fmt.Errorf("reading columns of table %q: %w", t.Name, err)
+		}
+		for _, n := range names {
+			c, ok := t.Column(n)
+			if !ok {
+				return nil, fmt.Errorf("column %q of table %q was not found", n, t.Name)
+			}
+			pt.Columns = append(pt.Columns, c)
+		}
+	}
+	if a, ok := spec.Attr("where"); ok {
+		if pt.Where, err = a.String(); err != nil {
+			return nil, fmt.Errorf("reading where of table %q: %w", t.Name, err)
+		}
+	}
+	return pt, nil
 }
 
-func (*state) renameTrigger(*schema.RenameTrigger) error {
-	return nil // unimplemented.
+// convertForeignData converts the foreign server and foreign table specs into objects.
+// Foreign tables that reference servers that are not defined in the document (e.g.,
+// in schema scope) are expected to reference them by name.
+func convertForeignData(servers []*foreignServer, tables []*foreignTable, r *schema.Realm) error {
+	for _, spec := range servers {
+		if _, ok := findForeignServer(r, spec.Name); ok {
+			return fmt.Errorf("postgres: foreign server %q is defined more than once", spec.Name)
+		}
+		srv := &ForeignServer{Name: spec.Name}
+		for _, a := range []struct {
+			name string
+			v    *string
+		}{
+			{"wrapper", &srv.Wrapper},
+			{"type", &srv.Type},
+			{"version", &srv.Version},
+		} {
+			if v, ok := spec.Attr(a.name); ok {
+				var err error
+				if *a.v, err = v.String(); err != nil {
+					return fmt.Errorf("postgres: reading %s of foreign server %q: %w", a.name, spec.Name, err)
+				}
+			}
+		}
+		if srv.Wrapper == "" {
+			return fmt.Errorf("postgres: missing 'wrapper' attribute for foreign server %q", spec.Name)
+		}
+		if a, ok := spec.Attr("comment"); ok {
+			c, err := a.String()
+			if err != nil {
+				return fmt.Errorf("postgres: reading comment of foreign server %q: %w", spec.Name, err)
+			}
+			srv.Attrs = append(srv.Attrs, &schema.Comment{Text: c})
+		}
+		var err error
+		if srv.Options, err = convertOptions(&spec.Extra); err != nil {
+			return fmt.Errorf("postgres: reading options of foreign server %q: %w", spec.Name, err)
+		}
+		r.AddObjects(srv)
+		for _, um := range spec.Extra.Resources("user_mapping") {
+			if _, ok := findUserMapping(r, um.Name, srv.Name); ok {
+				return fmt.Errorf("postgres: user mapping %q is defined more than once in foreign server %q", um.Name, spec.Name)
+			}
+			u := &UserMapping{User: um.Name, Server: srv}
+			if u.Options, err = convertOptions(um); err != nil {
+				return fmt.Errorf("postgres: reading options of user mapping %q in foreign server %q: %w", um.Name, spec.Name, err)
+			}
+			r.AddObjects(u)
+		}
+	}
+	for _, spec := range tables {
+		ns, err := specutil.SchemaName(spec.Schema)
+		if err != nil {
+			return fmt.Errorf("extract schema name from foreign table reference: %w", err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return fmt.Errorf("schema %q defined on foreign table %q was not found in realm", ns, spec.Name)
+		}
+		t := &ForeignTable{Name: spec.Name, Schema: s}
+		a, ok := spec.Attr("server")
+		if !ok {
+			return fmt.Errorf("postgres: missing 'server' attribute for foreign table %q", spec.Name)
+		}
+		var name string
+		if a.IsRef() {
+			var ref string
+			if ref, err = a.Ref(); err == nil {
+				_, name, err = specutil.RefName(&schemahcl.Ref{V: ref}, "foreign_server")
+			}
+		} else {
+			name, err = a.String()
+		}
+		if err != nil {
+			return fmt.Errorf("postgres: reading server of foreign table %q: %w", spec.Name, err)
+		}
+		if t.Server, ok = findForeignServer(r, name); !ok {
+			if a.IsRef() {
+				return fmt.Errorf("postgres: foreign server %q of foreign table %q was not found", name, spec.Name)
+			}
+			t.Server = &ForeignServer{Name: name}
+		}
+		for _, cs := range spec.Columns {
+			c, err := convertColumn(cs, nil)
+			if err != nil {
+				return fmt.Errorf("postgres: convert column %q of foreign table %q: %w", cs.Name, spec.Name, err)
+			}
+			t.Columns = append(t.Columns, c)
+		}
+		if a, ok := spec.Attr("comment"); ok {
+			c, err := a.String()
+			if err != nil {
+				return fmt.Errorf("postgres: reading comment of foreign table %q: %w", spec.Name, err)
+			}
+			t.Attrs = append(t.Attrs, &schema.Comment{Text: c})
+		}
+		if t.Options, err = convertOptions(&spec.Extra); err != nil {
+			return fmt.Errorf("postgres: reading options of foreign table %q: %w", spec.Name, err)
+		}
+		s.AddObjects(t)
+	}
+	return nil
 }
 
-func (*state) modifyTrigger(*schema.ModifyTrigger) error {
-	return nil // unimplemented.
+// convertOptions converts the "options" block of the given resource, if exists.
+func convertOptions(r *schemahcl.Resource) (map[string]string, error) {
+	b, ok := r.Resource("options")
+	if !ok {
+		return nil, nil
+	}
+	opts := make(map[string]string, len(b.Attrs))
+	for _, a := range b.Attrs {
+		v, err := a.String()
+		if err != nil {
+			return nil, fmt.Errorf("reading option %q: %w", a.K, err)
+		}
+		opts[a.K] = v
+	}
+	return opts, nil
 }
 
-func (*diff) ViewAttrChanges(_, _ *schema.View) []schema.Change {
-	return nil // unimplemented.
+func convertCronJobs(jobs []*cronJob, r *schema.Realm) error {
+	for _, j := range jobs {
+		if _, ok := findCronJob(r, j.Name); ok {
+			return fmt.Errorf("postgres: cron job %q is defined more than once", j.Name)
+		}
+		r.AddObjects(&CronJob{Name: j.Name, Schedule: j.Schedule, Command: j.Command})
+	}
+	return nil
 }
 
-// RealmObjectDiff returns a changeset for migrating realm (database) objects
-// from one state to the other. For example, adding extensions or users.
-func (*diff) RealmObjectDiff(_, _ *schema.Realm) ([]schema.Change, error) {
-	return nil, nil // unimplemented.
+func normalizeRealm(*schema.Realm) error {
+	return nil
 }
 
-// SchemaObjectDiff returns a changeset for migrating schema objects from
-// one state to the other.
-func (*diff) SchemaObjectDiff(from, to *schema.Schema, _ *schema.DiffOptions) ([]schema.Change, error) {
-	var changes []schema.Change
-	// Drop or modify enums.
-	for _, o1 := range from.Objects {
-		e1, ok := o1.(*schema.EnumType)
-		if !ok {
-			continue // Unsupported object type.
+func schemasObjectSpec(*doc, ...*schema.Schema) error {
+	return nil // unimplemented.
+}
+
+// objectSpec converts from a concrete schema objects into specs.
+func objectSpec(d *doc, spec *specutil.SchemaSpec, s *schema.Schema) error {
+	for _, o := range s.Objects {
+		if e, ok := o.(*schema.EnumType); ok {
+			es := &enum{
+				Name:   e.T,
+				Values: e.Values,
+				Schema: specutil.SchemaRef(spec.Schema.Name),
+			}
+			if c := enumComment(e); c != "" {
+				es.Extra.Attrs = append(es.Extra.Attrs, schemahcl.StringAttr("comment", c))
+			}
+			d.Enums = append(d.Enums, es)
 		}
-		o2, ok := to.Object(func(o schema.Object) bool {
-			e2, ok := o.(*schema.EnumType)
-			return ok && e1.T == e2.T
-		})
-		if !ok {
-			changes = append(changes, &schema.DropObject{O: o1})
-			continue
+		if dt, ok := o.(*DomainType); ok {
+			ds, err := domainSpec(spec, dt)
+			if err != nil {
+				return err
+			}
+			d.Domains = append(d.Domains, ds)
 		}
-		if e2 := o2.(*schema.EnumType); !sqlx.ValuesEqual(e1.Values, e2.Values) {
-			changes = append(changes, &schema.ModifyObject{From: e1, To: e2})
+		if ct, ok := o.(*CompositeType); ok {
+			cs, err := compositeSpec(spec, ct)
+			if err != nil {
+				return err
+			}
+			d.Composites = append(d.Composites, cs)
 		}
-	}
-	// Add new enums.
-	for _, o1 := range to.Objects {
-		e1, ok := o1.(*schema.EnumType)
-		if !ok {
-			continue // Unsupported object type.
+		if rt, ok := o.(*UserRangeType); ok {
+			rs, err := rangeSpec(spec, rt)
+			if err != nil {
+				return err
+			}
+			d.Ranges = append(d.Ranges, rs)
 		}
-		if _, ok := from.Object(func(o schema.Object) bool {
-			e2, ok := o.(*schema.EnumType)
-			return ok && e1.T == e2.T
-		}); !ok {
-			changes = append(changes, &schema.AddObject{O: e1})
+		if c, ok := o.(*Collation); ok {
+			d.Collations = append(d.Collations, collationSpec(spec, c))
+		}
+		if p, ok := o.(*TablePartition); ok {
+			d.Partitions = append(d.Partitions, partitionSpec(spec, p))
+		}
+		if ts, ok := o.(*TextSearchDict); ok {
+			d.TSDicts = append(d.TSDicts, tsDictSpec(spec, ts))
+		}
+		if ts, ok := o.(*TextSearchConfig); ok {
+			d.TSConfigs = append(d.TSConfigs, tsConfigSpec(spec, ts))
+		}
+		if a, ok := o.(*Aggregate); ok {
+			as, err := aggregateSpec(spec, a)
+			if err != nil {
+				return err
+			}
+			d.Aggregates = append(d.Aggregates, as)
+		}
+		switch o := o.(type) {
+		case *Operator:
+			d.Operators = append(d.Operators, operatorSpec(spec, o))
+		case *OpFamily:
+			d.OpFamilies = append(d.OpFamilies, opFamilySpec(spec, o))
+		case *OpClass:
+			d.OpClasses = append(d.OpClasses, opClassSpec(spec, o))
+		}
+		if t, ok := o.(*ForeignTable); ok {
+			ts, err := foreignTableSpec(spec, t)
+			if err != nil {
+				return err
+			}
+			d.ForeignTables = append(d.ForeignTables, ts)
 		}
 	}
-	return changes, nil
+	return nil
 }
 
-func verifyChanges(context.Context, []schema.Change) error {
-	return nil // unimplemented.
+// partitionSpec converts a partition into its spec.
+func partitionSpec(spec *specutil.SchemaSpec, p *TablePartition) *tablePartition {
+	ps := &tablePartition{
+		Name:   p.Name,
+		Schema: specutil.SchemaRef(spec.Schema.Name),
+		Bound:  p.Bound,
+	}
+	if p.Table != nil {
+		ps.Of = specutil.TableSpecRef(p.Table)
+	} else {
+		ps.Of = specutil.ObjectRef(p.Parent.Schema, p.Parent)
+	}
+	if p.PartitionBy != "" {
+		ps.Extra.Attrs = append(ps.Extra.Attrs, schemahcl.StringAttr("partition_by", p.PartitionBy))
+	}
+	if c := partitionComment(p); c != "" {
+		ps.Extra.Attrs = append(ps.Extra.Attrs, schemahcl.StringAttr("comment", c))
+	}
+	return ps
 }
 
-func convertDomains(_ []*sqlspec.Table, domains []*domain, _ *schema.Realm) error {
-	if len(domains) > 0 {
-		return fmt.Errorf("postgres: domains are not supported by this version. Use: https://atlasgo.io/getting-started")
+// tsDictSpec converts a text search dictionary into its spec.
+func tsDictSpec(spec *specutil.SchemaSpec, d *TextSearchDict) *tsDict {
+	ds := &tsDict{
+		Name:   d.Name,
+		Schema: specutil.SchemaRef(spec.Schema.Name),
 	}
-	return nil
+	ds.Extra.Attrs = append(ds.Extra.Attrs, schemahcl.StringAttr("template", d.Template))
+	if c := tsDictComment(d); c != "" {
+		ds.Extra.Attrs = append(ds.Extra.Attrs, schemahcl.StringAttr("comment", c))
+	}
+	if len(d.Options) > 0 {
+		ds.Extra.Children = append(ds.Extra.Children, optionsSpec(d.Options))
+	}
+	return ds
 }
 
-func convertAggregate(d *doc, _ *schema.Realm) error {
-	if len(d.Aggregates) > 0 {
-		return fmt.Errorf("postgres: aggregates are not supported by this version. Use: https://atlasgo.io/getting-started")
+// tsConfigSpec converts a text search configuration into its spec. Token
+// types that are mapped to the same dictionaries share a "mapping" block.
+func tsConfigSpec(spec *specutil.SchemaSpec, c *TextSearchConfig) *tsConfig {
+	cs := &tsConfig{
+		Name:   c.Name,
+		Schema: specutil.SchemaRef(spec.Schema.Name),
 	}
-	return nil
+	cs.Extra.Attrs = append(cs.Extra.Attrs, schemahcl.StringAttr("parser", c.Parser))
+	if cm := tsConfigComment(c); cm != "" {
+		cs.Extra.Attrs = append(cs.Extra.Attrs, schemahcl.StringAttr("comment", cm))
+	}
+	for _, g := range groupTokens(sortedTokens(c.Mappings), c.Mappings, nil) {
+		cs.Extra.Children = append(cs.Extra.Children, &schemahcl.Resource{
+			Type: "mapping",
+			Attrs: []*schemahcl.Attr{
+				schemahcl.StringsAttr("tokens", g...),
+				schemahcl.StringsAttr("dictionaries", c.Mappings[g[0]]...),
+			},
+		})
+	}
+	return cs
 }
 
-func convertSequences(_ []*sqlspec.Table, seqs []*sqlspec.Sequence, _ *schema.Realm) error {
-	if len(seqs) > 0 {
-		return fmt.Errorf("postgres: sequences are not supported by this version. Use: https://atlasgo.io/getting-started")
+// operatorSpec converts an operator into its spec.
+func operatorSpec(spec *specutil.SchemaSpec, o *Operator) *operator {
+	op := &operator{
+		Name:   o.Name,
+		Schema: specutil.SchemaRef(spec.Schema.Name),
 	}
-	return nil
+	for _, a := range []struct{ k, v string }{
+		{"left", o.Left},
+		{"right", o.Right},
+		{"function", o.Function},
+		{"commutator", o.Commutator},
+		{"negator", o.Negator},
+		{"restrict", o.Restrict},
+		{"join", o.Join},
+	} {
+		if a.v != "" {
+			op.Extra.Attrs = append(op.Extra.Attrs, schemahcl.StringAttr(a.k, a.v))
+		}
+	}
+	if o.Hashes {
+		op.Extra.Attrs = append(op.Extra.Attrs, schemahcl.BoolAttr("hashes", true))
+	}
+	if o.Merges {
+		op.Extra.Attrs = append(op.Extra.Attrs, schemahcl.BoolAttr("merges", true))
+	}
+	if c := operatorComment(o); c != "" {
+		op.Extra.Attrs = append(op.Extra.Attrs, schemahcl.StringAttr("comment", c))
+	}
+	return op
 }
 
-func convertPolicies(_ []*sqlspec.Table, ps []*policy, _ *schema.Realm) error {
-	if len(ps) > 0 {
-		return fmt.Errorf("postgres: policies are not supported by this version. Use: https://atlasgo.io/getting-started")
+// opFamilySpec converts an operator family into its spec.
+func opFamilySpec(spec *specutil.SchemaSpec, f *OpFamily) *opFamily {
+	fs := &opFamily{
+		Name:   f.Name,
+		Schema: specutil.SchemaRef(spec.Schema.Name),
 	}
-	return nil
+	fs.Extra.Attrs = append(fs.Extra.Attrs, schemahcl.StringAttr("method", f.Method))
+	if c := opFamilyComment(f); c != "" {
+		fs.Extra.Attrs = append(fs.Extra.Attrs, schemahcl.StringAttr("comment", c))
+	}
+	return fs
 }
 
-func convertExtensions(exs []*extension, _ *schema.Realm) error {
-	if len(exs) > 0 {
-		return fmt.Errorf("postgres: extensions are not supported by this version. Use: https://atlasgo.io/getting-started")
+// opClassSpec converts an operator class into its spec.
+func opClassSpec(spec *specutil.SchemaSpec, c *OpClass) *opClass {
+	cs := &opClass{
+		Name:   c.Name,
+		Schema: specutil.SchemaRef(spec.Schema.Name),
 	}
-	return nil
+	cs.Extra.Attrs = append(cs.Extra.Attrs, schemahcl.StringAttr("method", c.Method), schemahcl.StringAttr("type", c.Type))
+	if c.Default {
+		cs.Extra.Attrs = append(cs.Extra.Attrs, schemahcl.BoolAttr("default", true))
+	}
+	if c.Family != "" {
+		cs.Extra.Attrs = append(cs.Extra.Attrs, schemahcl.StringAttr("family", c.Family))
+	}
+	if c.Storage != "" {
+		cs.Extra.Attrs = append(cs.Extra.Attrs, schemahcl.StringAttr("storage", c.Storage))
+	}
+	if cm := opClassComment(c); cm != "" {
+		cs.Extra.Attrs = append(cs.Extra.Attrs, schemahcl.StringAttr("comment", cm))
+	}
+	for _, o := range c.Operators {
+		r := &schemahcl.Resource{
+			Type:  "operator",
+			Attrs: []*schemahcl.Attr{schemahcl.IntAttr("strategy", o.Strategy), schemahcl.StringAttr("name", o.Name)},
+		}
+		if o.OrderBy != "" {
+			r.Attrs = append(r.Attrs, schemahcl.StringAttr("order_by", o.OrderBy))
+		}
+		cs.Extra.Children = append(cs.Extra.Children, r)
+	}
+	for _, f := range c.Funcs {
+		cs.Extra.Children = append(cs.Extra.Children, &schemahcl.Resource{
+			Type:  "function",
+			Attrs: []*schemahcl.Attr{schemahcl.IntAttr("number", f.Number), schemahcl.StringAttr("name", f.Name)},
+		})
+	}
+	return cs
 }
 
-func convertEventTriggers(evs []*eventTrigger, _ *schema.Realm) error {
-	if len(evs) > 0 {
-		return fmt.Errorf("postgres: event triggers are not supported by this version. Use: https://atlasgo.io/getting-started")
+// foreignTableSpec converts a foreign table into its spec.
+func foreignTableSpec(spec *specutil.SchemaSpec, t *ForeignTable) (*foreignTable, error) {
+	ts := &foreignTable{
+		Name:   t.Name,
+		Schema: specutil.SchemaRef(spec.Schema.Name),
 	}
-	return nil
+	for _, c := range t.Columns {
+		cs, err := tableColumnSpec(c, nil)
+		if err != nil {
+			return nil, fmt.Errorf("convert column %q of foreign table %q: %w", c.Name, t.Name, err)
+		}
+		ts.Columns = append(ts.Columns, cs)
+	}
+	ts.Extra.Attrs = append(ts.Extra.Attrs, schemahcl.RefAttr("server", schemahcl.BuildRef([]schemahcl.PathIndex{{T: "foreign_server", V: []string{t.Server.Name}}})))
+	if c := foreignTableComment(t); c != "" {
+		ts.Extra.Attrs = append(ts.Extra.Attrs, schemahcl.StringAttr("comment", c))
+	}
+	if len(t.Options) > 0 {
+		ts.Extra.Children = append(ts.Extra.Children, optionsSpec(t.Options))
+	}
+	return ts, nil
 }
 
-func normalizeRealm(*schema.Realm) error {
-	return nil
+// rangeSpec converts a range type into its spec.
+func rangeSpec(spec *specutil.SchemaSpec, r *UserRangeType) (*rangeType, error) {
+	t, err := columnTypeSpec(r.Subtype)
+	if err != nil {
+		return nil, fmt.Errorf("convert subtype of range %q: %w", r.T, err)
+	}
+	rs := &rangeType{
+		Name:    r.T,
+		Schema:  specutil.SchemaRef(spec.Schema.Name),
+		Subtype: t.Type,
+	}
+	for _, a := range []struct{ name, v string }{
+		{"collate", r.Collation},
+		{"canonical", r.Canonical},
+		{"subtype_diff", r.SubtypeDiff},
+		{"comment", rangeComment(r)},
+	} {
+		if a.v != "" {
+			rs.Extra.Attrs = append(rs.Extra.Attrs, schemahcl.StringAttr(a.name, a.v))
+		}
+	}
+	return rs, nil
 }
 
-func schemasObjectSpec(*doc, ...*schema.Schema) error {
-	return nil // unimplemented.
+// aggregateSpec converts an aggregate into its spec.
+func aggregateSpec(spec *specutil.SchemaSpec, a *Aggregate) (*aggregate, error) {
+	fs, err := routineSpec(a.Name, a.Args, "")
+	if err != nil {
+		return nil, fmt.Errorf("postgres: aggregate %q: %w", a.Name, err)
+	}
+	st, err := columnTypeSpec(a.StateType)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: state type of aggregate %q: %w", a.Name, err)
+	}
+	as := &aggregate{
+		Name:   a.Name,
+		Schema: specutil.SchemaRef(spec.Schema.Name),
+		Args:   fs.Args,
+	}
+	as.Extra.Attrs = append(as.Extra.Attrs, specutil.TypeAttr("state_type", st.Type), schemahcl.StringAttr("state_func", a.StateFunc))
+	if a.FinalFunc != "" {
+		as.Extra.Attrs = append(as.Extra.Attrs, schemahcl.StringAttr("final_func", a.FinalFunc))
+	}
+	if a.InitCond != nil {
+		as.Extra.Attrs = append(as.Extra.Attrs, schemahcl.StringAttr("initial_condition", *a.InitCond))
+	}
+	if a.Parallel != "" && a.Parallel != ParallelUnsafe {
+		as.Extra.Attrs = append(as.Extra.Attrs, specutil.VarAttr("parallel", a.Parallel))
+	}
+	if c := aggregateComment(a); c != "" {
+		as.Extra.Attrs = append(as.Extra.Attrs, schemahcl.StringAttr("comment", c))
+	}
+	return as, nil
 }
 
-// objectSpec converts from a concrete schema objects into specs.
-func objectSpec(d *doc, spec *specutil.SchemaSpec, s *schema.Schema) error {
-	for _, o := range s.Objects {
-		if e, ok := o.(*schema.EnumType); ok {
-			d.Enums = append(d.Enums, &enum{
-				Name:   e.T,
-				Values: e.Values,
-				Schema: specutil.SchemaRef(spec.Schema.Name),
-			})
+// collationSpec converts a collation into its spec.
+func collationSpec(spec *specutil.SchemaSpec, c *Collation) *collation {
+	cs := &collation{
+		Name:   c.Name,
+		Schema: specutil.SchemaRef(spec.Schema.Name),
+	}
+	if c.Provider != "" && c.Provider != "libc" {
+		cs.Extra.Attrs = append(cs.Extra.Attrs, schemahcl.StringAttr("provider", c.Provider))
+	}
+	if c.Locale != "" {
+		cs.Extra.Attrs = append(cs.Extra.Attrs, schemahcl.StringAttr("locale", c.Locale))
+	} else {
+		cs.Extra.Attrs = append(cs.Extra.Attrs, schemahcl.StringAttr("lc_collate", c.LcCollate), schemahcl.StringAttr("lc_ctype", c.LcCtype))
+	}
+	if !c.Deterministic {
+		cs.Extra.Attrs = append(cs.Extra.Attrs, schemahcl.BoolAttr("deterministic", false))
+	}
+	if v := collationComment(c); v != "" {
+		cs.Extra.Attrs = append(cs.Extra.Attrs, schemahcl.StringAttr("comment", v))
+	}
+	return cs
+}
+
+// compositeSpec converts a composite type into its spec.
+func compositeSpec(spec *specutil.SchemaSpec, c *CompositeType) (*composite, error) {
+	cs := &composite{
+		Name:   c.T,
+		Schema: specutil.SchemaRef(spec.Schema.Name),
+	}
+	for _, f := range c.Fields {
+		t, err := columnTypeSpec(f.Type.Type)
+		if err != nil {
+			return nil, fmt.Errorf("convert type of field %q in composite %q: %w", f.Name, c.T, err)
+		}
+		fs := &compositeField{Name: f.Name, Type: t.Type}
+		if v := fieldCollation(f); v != "" {
+			fs.Extra.Attrs = append(fs.Extra.Attrs, schemahcl.StringAttr("collate", v))
+		}
+		cs.Fields = append(cs.Fields, fs)
+	}
+	if v := compositeComment(c); v != "" {
+		cs.Extra.Attrs = append(cs.Extra.Attrs, schemahcl.StringAttr("comment", v))
+	}
+	return cs, nil
+}
+
+// domainSpec converts a domain type into its spec.
+func domainSpec(spec *specutil.SchemaSpec, d *DomainType) (*domain, error) {
+	t, err := columnTypeSpec(d.Type)
+	if err != nil {
+		return nil, fmt.Errorf("convert type of domain %q: %w", d.T, err)
+	}
+	ds := &domain{
+		Name:   d.T,
+		Type:   t.Type,
+		Null:   d.Null,
+		Schema: specutil.SchemaRef(spec.Schema.Name),
+	}
+	if d.Default != nil {
+		if ds.Default, err = specutil.ColumnDefault(&schema.Column{Type: &schema.ColumnType{Type: d.Type}, Default: d.Default}); err != nil {
+			return nil, fmt.Errorf("convert default value of domain %q: %w", d.T, err)
+		}
+	}
+	for _, c := range d.Checks {
+		ds.Checks = append(ds.Checks, specutil.FromCheck(c))
+	}
+	if c := domainComment(d); c != "" {
+		ds.Extra.Attrs = append(ds.Extra.Attrs, schemahcl.StringAttr("comment", c))
+	}
+	return ds, nil
+}
+
+// checkEnumValues validates the values of the enum before they are planned. This is mostly
+// useful for values that are generated by data sources (e.g., read from a reference table),
+// as Postgres does not allow empty or duplicate labels, and the order of the values must be
+// stable between evaluations, as existing values cannot be reordered or removed.
+func checkEnumValues(e *enum) error {
+	seen := make(map[string]bool, len(e.Values))
+	for _, v := range e.Values {
+		switch {
+		case v == "":
+			return fmt.Errorf("enum %q contains an empty value", e.Name)
+		case len(v) > maxEnumLabel:
+			return fmt.Errorf("enum %q value %q exceeds the maximum length of %d bytes", e.Name, v, maxEnumLabel)
+		case seen[v]:
+			return fmt.Errorf("enum %q contains duplicate value %q", e.Name, v)
 		}
+		seen[v] = true
 	}
 	return nil
 }
@@ -906,7 +7125,17 @@ func convertTypes(d *doc, r *schema.Realm) error {
 		if !ok {
 			return fmt.Errorf("schema %q defined on enum %q was not found in realm", ns, e.Name)
 		}
+		if err := checkEnumValues(e); err != nil {
+			return err
+		}
 		e1 := &schema.EnumType{T: e.Name, Schema: es, Values: e.Values}
+		if c, ok := e.Attr("comment"); ok {
+			s, err := c.String()
+			if err != nil {
+				return fmt.Errorf("extract comment of enum %q: %w", e.Name, err)
+			}
+			e1.Attrs = append(e1.Attrs, &schema.Comment{Text: s})
+		}
 		es.AddObjects(e1)
 		byName[e.Name] = e1
 	}
@@ -969,31 +7198,110 @@ func uniqueConstChanged(_, _ []schema.Attr) bool {
 	return false
 }
 
-func excludeConstChanged(_, _ []schema.Attr) bool {
-	// Unsupported change in package mode (ariga.io/sql/postgres)
-	// to keep BC with old versions.
-	return false
+// excludeConstChanged reports if the index was changed from (or to) an
+// exclude constraint, or if the name of the exclude constraint was changed.
+func excludeConstChanged(from, to []schema.Attr) bool {
+	c1, ok1 := excludeConst(from)
+	c2, ok2 := excludeConst(to)
+	return ok1 != ok2 || ok1 && c1.N != c2.N
 }
 
-func convertExclude(schemahcl.Resource, *schema.Table) error {
-	return nil // unimplemented.
+// convertExclude converts the exclude constraints into indexes.
+func convertExclude(spec schemahcl.Resource, t *schema.Table) error {
+	for _, r := range spec.Resources("exclude") {
+		var sx sqlspec.Index
+		if err := r.As(&sx); err != nil {
+			return fmt.Errorf("parse %s.exclude constraint: %w", t.Name, err)
+		}
+		if len(sx.Columns) > 0 {
+			return fmt.Errorf("%s.exclude constraint %q: elements must be defined using 'on' blocks with their operators", t.Name, sx.Name)
+		}
+		idx, err := convertIndex(&sx, t)
+		if err != nil {
+			return err
+		}
+		for i, p := range sx.Parts {
+			a, ok := p.Attr("op")
+			if !ok {
+				return fmt.Errorf("missing operator for element %d of %s.exclude constraint %q", i+1, t.Name, sx.Name)
+			}
+			op, err := a.String()
+			if err != nil {
+				return fmt.Errorf("reading operator of %s.exclude constraint %q: %w", t.Name, sx.Name, err)
+			}
+			idx.Parts[i].AddAttrs(&Operator{Name: op})
+		}
+		idx.AddAttrs(ExcludeConstraint(sx.Name))
+		if err := convertDeferrable(&sx.DefaultExtension, &idx.Attrs); err != nil {
+			return fmt.Errorf("%s.exclude constraint %q: %w", t.Name, sx.Name, err)
+		}
+		t.AddIndexes(idx)
+	}
+	return nil
 }
 
 func (*state) sortChanges(changes []schema.Change) []schema.Change {
-	return sqlx.SortChanges(changes, nil)
+	return sqlx.SortChanges(changes, sortOptions)
+}
+
+// sortOptions holds the Postgres-specific rules for sorting changes.
+var sortOptions = &sqlx.SortOptions{TableDepT: inheritsFrom}
+
+// inheritsFrom reports if the table t inherits the parent table.
+func inheritsFrom(t, parent *schema.Table) bool {
+	return slices.ContainsFunc(tableInherits(t), func(p *schema.Table) bool {
+		return p.Name == parent.Name && sqlx.SameSchema(p.Schema, parent.Schema)
+	})
 }
 
 func (*state) detachCycles(changes []schema.Change) ([]schema.Change, error) {
 	return sqlx.DetachCycles(changes)
 }
 
-func excludeSpec(*sqlspec.Table, *sqlspec.Index, *schema.Index, *Constraint) error {
-	return nil // unimplemented.
+// excludeSpec appends the exclude constraint to the table spec. Unlike indexes, the
+// elements of the constraint are always written as "on" blocks with their operators.
+func excludeSpec(spec *sqlspec.Table, idx1 *sqlspec.Index, idx *schema.Index, c *Constraint) error {
+	name := c.N
+	if name == "" {
+		name = idx.Name
+	}
+	r := &schemahcl.Resource{Type: "exclude", Name: name, Attrs: append(idx1.Extra.Attrs, deferrableSpec(idx.Attrs)...)}
+	for i, p := range idx.Parts {
+		op := &Operator{}
+		if !sqlx.Has(p.Attrs, op) || op.Name == "" {
+			return fmt.Errorf("missing operator for element %d of exclude constraint %q", i+1, name)
+		}
+		part := &sqlspec.IndexPart{Desc: p.Desc}
+		switch x := p.X.(type) {
+		case nil:
+			if p.C == nil {
+				return fmt.Errorf("missing column or expression for element %d of exclude constraint %q", i+1, name)
+			}
+			part.Column = specutil.ColumnRef(p.C.Name)
+		case *schema.RawExpr:
+			part.Expr = x.X
+		default:
+			return fmt.Errorf("unexpected expression %T for exclude constraint %q", p.X, name)
+		}
+		if err := partAttr(idx, p, part); err != nil {
+			return err
+		}
+		on := &schemahcl.Resource{Type: "on"}
+		if err := on.Scan(part); err != nil {
+			return err
+		}
+		on.Attrs = append(on.Attrs, schemahcl.StringAttr("op", op.Name))
+		r.Children = append(r.Children, on)
+	}
+	spec.Extra.Children = append(spec.Extra.Children, r)
+	return nil
 }
 
 const (
-	// Query to list tables information.
-	// Note, 'attrs' are not supported in this version.
+	// Query to list tables information. The 'attrs' column holds the parent
+	// tables of tables that use (classic) inheritance, the storage
+	// parameters of the table and its TOAST table, its tablespace,
+	// and if it is unlogged.
 	tablesQuery = `
 SELECT
 	t3.oid,
@@ -1003,13 +7311,27 @@ SELECT
 	t4.partattrs AS partition_attrs,
 	t4.partstrat AS partition_strategy,
 	pg_get_expr(t4.partexprs, t4.partrelid) AS partition_exprs,
-	'{}' AS attrs
+	t6.amname AS access_method,
+	json_build_object(
+		'inherits', (
+			SELECT json_agg(json_build_array(pn.nspname, p.relname) ORDER BY i.inhseqno)
+			FROM pg_catalog.pg_inherits AS i
+			JOIN pg_catalog.pg_class AS p ON p.oid = i.inhparent
+			JOIN pg_catalog.pg_namespace AS pn ON pn.oid = p.relnamespace
+			WHERE i.inhrelid = t3.oid
+		),
+		'options', t3.reloptions,
+		'toast_options', (SELECT tc.reloptions FROM pg_catalog.pg_class AS tc WHERE tc.oid = t3.reltoastrelid),
+		'tablespace', (SELECT ts.spcname FROM pg_catalog.pg_tablespace AS ts WHERE ts.oid = t3.reltablespace),
+		'unlogged', t3.relpersistence = 'u'
+	) AS attrs
 FROM
 	INFORMATION_SCHEMA.TABLES AS t1
 	JOIN pg_catalog.pg_namespace AS t2 ON t2.nspname = t1.table_schema
 	JOIN pg_catalog.pg_class AS t3 ON t3.relnamespace = t2.oid AND t3.relname = t1.table_name
 	LEFT JOIN pg_catalog.pg_partitioned_table AS t4 ON t4.partrelid = t3.oid
 	LEFT JOIN pg_depend AS t5 ON t5.classid = 'pg_catalog.pg_class'::regclass::oid AND t5.objid = t3.oid AND t5.deptype = 'e'
+	LEFT JOIN pg_catalog.pg_am AS t6 ON t6.oid = t3.relam
 WHERE
 	t1.table_type = 'BASE TABLE'
 	AND NOT COALESCE(t3.relispartition, false)
@@ -1019,7 +7341,6 @@ ORDER BY
 	t1.table_schema, t1.table_name
 `
 	// Query to list tables by their names.
-	// Note, 'attrs' are not supported in this version.
 	tablesQueryArgs = `
 SELECT
 	t3.oid,
@@ -1029,13 +7350,27 @@ SELECT
 	t4.partattrs AS partition_attrs,
 	t4.partstrat AS partition_strategy,
 	pg_get_expr(t4.partexprs, t4.partrelid) AS partition_exprs,
-	'{}' AS attrs
+	t6.amname AS access_method,
+	json_build_object(
+		'inherits', (
+			SELECT json_agg(json_build_array(pn.nspname, p.relname) ORDER BY i.inhseqno)
+			FROM pg_catalog.pg_inherits AS i
+			JOIN pg_catalog.pg_class AS p ON p.oid = i.inhparent
+			JOIN pg_catalog.pg_namespace AS pn ON pn.oid = p.relnamespace
+			WHERE i.inhrelid = t3.oid
+		),
+		'options', t3.reloptions,
+		'toast_options', (SELECT tc.reloptions FROM pg_catalog.pg_class AS tc WHERE tc.oid = t3.reltoastrelid),
+		'tablespace', (SELECT ts.spcname FROM pg_catalog.pg_tablespace AS ts WHERE ts.oid = t3.reltablespace),
+		'unlogged', t3.relpersistence = 'u'
+	) AS attrs
 FROM
 	INFORMATION_SCHEMA.TABLES AS t1
 	JOIN pg_catalog.pg_namespace AS t2 ON t2.nspname = t1.table_schema
 	JOIN pg_catalog.pg_class AS t3 ON t3.relnamespace = t2.oid AND t3.relname = t1.table_name
 	LEFT JOIN pg_catalog.pg_partitioned_table AS t4 ON t4.partrelid = t3.oid
 	LEFT JOIN pg_depend AS t5 ON t5.classid = 'pg_catalog.pg_class'::regclass::oid AND t5.objid = t3.oid AND t5.deptype = 'e'
+	LEFT JOIN pg_catalog.pg_am AS t6 ON t6.oid = t3.relam
 WHERE
 	t1.table_type = 'BASE TABLE'
 	AND NOT COALESCE(t3.relispartition, false)
@@ -9,6 +9,7 @@ package postgres
 import (
 	"context"
 	"io"
+	"net/url"
 	"testing"
 	"time"
 
@@ -167,6 +168,54 @@ func TestDriver_Version(t *testing.T) {
 	type vr interface{ Version() string }
 	require.Implements(t, (*vr)(nil), drv)
 	require.Equal(t, "130000", drv.(vr).Version())
+
+	require.Equal(t, "15.4", versionString(150004))
+	require.Equal(t, "10.23", versionString(100023))
+	require.Equal(t, "9.6.5", versionString(90605))
+}
+
+func TestEnsureDatabase(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// Existing databases are not created.
+	m.ExpectQuery(sqltest.Escape("SELECT COUNT(*) FROM pg_database WHERE datname = $1")).
+		WithArgs("app").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	require.NoError(t, ensureDatabase(ctx, db, "app"))
+
+	// Missing databases are created.
+	m.ExpectQuery(sqltest.Escape("SELECT COUNT(*) FROM pg_database WHERE datname = $1")).
+		WithArgs("app").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	m.ExpectExec(sqltest.Escape(`CREATE DATABASE "app"`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	require.NoError(t, ensureDatabase(ctx, db, "app"))
+
+	// Databases created concurrently are accepted.
+	m.ExpectQuery(sqltest.Escape("SELECT COUNT(*) FROM pg_database WHERE datname = $1")).
+		WithArgs("app").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	m.ExpectExec(sqltest.Escape(`CREATE DATABASE "app"`)).
+		WillReturnError(&pgError{Code: "42P04", Message: `database "app" already exists`})
+	require.NoError(t, ensureDatabase(ctx, db, "app"))
+
+	// Other errors are returned.
+	m.ExpectQuery(sqltest.Escape("SELECT COUNT(*) FROM pg_database WHERE datname = $1")).
+		WithArgs("app").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	m.ExpectExec(sqltest.Escape(`CREATE DATABASE "app"`)).
+		WillReturnError(&pgError{Code: "42501", Message: "permission denied to create database"})
+	require.EqualError(t, ensureDatabase(ctx, db, "app"), `postgres: create database "app": pq: permission denied to create database`)
+	require.NoError(t, m.ExpectationsWereMet())
+
+	// URLs without a database, or connected to the maintenance database, are skipped.
+	for _, s := range []string{"postgres://localhost:5432", "postgres://localhost:5432/", "postgres://localhost:5432/postgres?search_path=public"} {
+		u, err := url.Parse(s)
+		require.NoError(t, err)
+		require.NoError(t, parser{}.EnsureDatabase(ctx, u))
+	}
 }
 
 func TestDriver_RealmRestoreFunc(t *testing.T) {
@@ -256,3 +305,117 @@ func (m *mockPlanApplier) ApplyChanges(_ context.Context, applied []schema.Chang
 	m.applied = append(m.applied, applied...)
 	return nil
 }
+
+func TestDriver_SimulateChange(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	m.ExpectQuery(sqltest.Escape(paramsQuery)).
+		WillReturnRows(sqltest.Rows(`
+  version       |  am  | crdb                  | encoding | collate | timezone
+----------------|------|-----------------------|----------|---------|---------
+ 130000         | heap | CockroachDB CCL v22.2 | UTF8     | en-US   | UTC
+`))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	sim, ok := drv.(migrate.Simulator)
+	require.True(t, ok)
+	ctx := context.Background()
+
+	m.ExpectQuery(sqltest.Escape(`EXPLAIN (DDL) ALTER TABLE "users" ADD COLUMN "c" bigint NULL`)).
+		WillReturnRows(sqltest.Rows(`
+ info
+-------------------------------------------------------------------
+ Schema change plan for ALTER TABLE ‹defaultdb›.‹public›.‹users› ADD COLUMN ‹c› INT8 NULL;
+`))
+	r, err := sim.SimulateChange(ctx, &migrate.Change{Cmd: `ALTER TABLE "users" ADD COLUMN "c" bigint NULL`})
+	require.NoError(t, err)
+	require.Equal(t, &migrate.Simulation{
+		Feasible: true,
+		Details:  []string{"Schema change plan for ALTER TABLE ‹defaultdb›.‹public›.‹users› ADD COLUMN ‹c› INT8 NULL;"},
+	}, r)
+
+	// Statements that are not supported by the declarative schema changer are not simulated.
+	m.ExpectQuery(sqltest.Escape(`EXPLAIN (DDL) CREATE TYPE "status" AS ENUM ('a')`)).
+		WillReturnError(&pgError{Code: "0A000", Message: "cannot explain a statement which is not supported by the declarative schema changer"})
+	r, err = sim.SimulateChange(ctx, &migrate.Change{Cmd: `CREATE TYPE "status" AS ENUM ('a')`})
+	require.NoError(t, err)
+	require.Nil(t, r)
+
+	m.ExpectQuery(sqltest.Escape(`EXPLAIN (DDL) ALTER TABLE "users" ADD COLUMN "c" bigint NULL`)).
+		WillReturnError(&pgError{Code: "42701", Message: `column "c" of relation "users" already exists`})
+	r, err = sim.SimulateChange(ctx, &migrate.Change{Cmd: `ALTER TABLE "users" ADD COLUMN "c" bigint NULL`})
+	require.NoError(t, err)
+	require.Equal(t, &migrate.Simulation{Reason: `pq: column "c" of relation "users" already exists`}, r)
+	require.NoError(t, m.ExpectationsWereMet())
+
+	// PostgreSQL does not support simulating changes.
+	db, m, err = sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("130000")
+	drv, err = Open(db)
+	require.NoError(t, err)
+	_, ok = drv.(migrate.Simulator)
+	require.False(t, ok)
+}
+
+func TestDriver_Backfill(t *testing.T) {
+	var (
+		drv   migrate.Backfiller = &Driver{}
+		users                    = schema.NewTable("users").SetSchema(schema.New("public"))
+		b                        = &migrate.Backfill{T: users, From: schema.NewStringColumn("name", "text"), To: schema.NewStringColumn("full_name", "text")}
+	)
+	c, err := drv.Backfill(b)
+	require.NoError(t, err)
+	require.Equal(t, `UPDATE "public"."users" SET "full_name" = "name"`, c.Cmd)
+
+	// Values are cast in case the type was changed.
+	b = &migrate.Backfill{T: users, From: schema.NewStringColumn("age", "text"), To: schema.NewIntColumn("age_new", "bigint")}
+	c, err = drv.Backfill(b)
+	require.NoError(t, err)
+	require.Equal(t, `UPDATE "public"."users" SET "age_new" = CAST("age" AS bigint)`, c.Cmd)
+	require.Empty(t, b.Using)
+	b.Using = `NULLIF("age", '')::bigint`
+	c, err = drv.Backfill(b)
+	require.NoError(t, err)
+	require.Equal(t, `UPDATE "public"."users" SET "age_new" = NULLIF("age", '')::bigint`, c.Cmd)
+
+	// Tables with a single-column primary key are backfilled in batches.
+	users.AddColumns(schema.NewIntColumn("id", "bigint"))
+	users.SetPrimaryKey(schema.NewPrimaryKey(users.Columns[0]))
+	c, err = drv.Backfill(b)
+	require.NoError(t, err)
+	require.Equal(t, `DO $$
+DECLARE
+  last "public"."users"."id"%TYPE;
+  bound "public"."users"."id"%TYPE;
+BEGIN
+  LOOP
+    SELECT "id" INTO bound FROM "public"."users" WHERE last IS NULL OR "id" > last ORDER BY "id" OFFSET 9999 LIMIT 1;
+    UPDATE "public"."users" SET "age_new" = NULLIF("age", '')::bigint WHERE (last IS NULL OR "id" > last) AND (bound IS NULL OR "id" <= bound);
+    EXIT WHEN bound IS NULL;
+    last := bound;
+  END LOOP;
+END $$`, c.Cmd)
+	require.Equal(t, `backfill column "age_new" from column "age"`, c.Comment)
+}
+
+func TestDriver_FormatLiteral(t *testing.T) {
+	var drv migrate.LiteralFormatter = &Driver{}
+	for _, tt := range []struct {
+		v      any
+		expect string
+	}{
+		{v: `it's \n`, expect: `'it''s \n'`},
+		{v: []byte{0xde, 0xad}, expect: `'\xdead'`},
+		{v: false, expect: "false"},
+		{v: time.Date(2024, 1, 2, 3, 4, 5, 6000, time.FixedZone("", 2*60*60)), expect: "'2024-01-02 03:04:05.000006+02:00'"},
+		{v: []string{"a", "b"}, expect: "ARRAY['a', 'b']"},
+		{v: [][]int{{1}, {2}}, expect: "ARRAY[ARRAY[1], ARRAY[2]]"},
+		{v: []int{}, expect: "'{}'"},
+		{v: map[string]any{"a": []int{1}}, expect: `'{"a":[1]}'`},
+	} {
+		got, err := drv.FormatLiteral(tt.v)
+		require.NoError(t, err)
+		require.Equal(t, tt.expect, got)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+//go:build !ent
+
+package postgres
+
+import (
+	"errors"
+	"fmt"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+// EnumLookup describes the conversion of an enum column into a text column that references a
+// lookup table holding the enum values. Unlike enum types, values of lookup tables can be added
+// and removed in a transaction, and can be extended with additional columns (e.g., a label).
+type EnumLookup struct {
+	// Table and Column are the table and its enum column to convert.
+	Table  *schema.Table
+	Column *schema.Column
+	// Name of the lookup table, created in the schema of Table. Defaults to the name of the enum
+	// suffixed with "_values", as a table cannot share its name with a type of its schema.
+	Name string
+	// Constraint is the name of the foreign key. Defaults to "<table>_<column>_fkey".
+	Constraint string
+}
+
+// EnumLookupPlan returns a plan that converts the enum column described by the given EnumLookup
+// into a foreign key referencing a generated lookup table. That is, create the lookup table, insert
+// the enum values into it, change the column type to text, and add the foreign key. Note, the enum
+// type itself is not dropped, as it may be used by other columns or functions. For example:
+//
+//	plan, err := drv.EnumLookupPlan("status_lookup", &postgres.EnumLookup{Table: users, Column: status})
+//	...
+//	err = migrate.NewPlanner(nil, dir).WritePlan(plan)
+func (d *Driver) EnumLookupPlan(name string, l *EnumLookup, opts ...migrate.PlanOption) (*migrate.Plan, error) {
+	e, err := l.validate()
+	if err != nil {
+		return nil, fmt.Errorf("postgres: %w", err)
+	}
+	s := &state{
+		Plan: migrate.Plan{
+			Name:          name,
+			Transactional: true,
+			Reversible:    true,
+		},
+	}
+	for _, o := range opts {
+		o(&s.PlanOptions)
+	}
+	var (
+		c      = l.Column
+		lookup = schema.NewTable(l.Name).SetSchema(l.Table.Schema)
+		fk     = l.Constraint
+		value  = "value"
+	)
+	if lookup.Name == "" {
+		lookup.Name = e.T + "_values"
+	}
+	if fk == "" {
+		fk = fmt.Sprintf("%s_%s_fkey", l.Table.Name, c.Name)
+	}
+	s.append(
+		&migrate.Change{
+			Cmd: s.Build("CREATE TABLE").Table(lookup).Wrap(func(b *sqlx.Builder) {
+				b.Ident(value).P(TypeText, "NOT NULL PRIMARY KEY")
+			}).String(),
+			Reverse: s.Build("DROP TABLE").Table(lookup).String(),
+			Comment: fmt.Sprintf("create lookup table %q", lookup.Name),
+		},
+		&migrate.Change{
+			Cmd: s.Build("INSERT INTO").Table(lookup).Wrap(func(b *sqlx.Builder) {
+				b.Ident(value)
+			}).P("VALUES").MapComma(e.Values, func(i int, b *sqlx.Builder) {
+				b.Wrap(func(b *sqlx.Builder) { b.WriteString(quote(e.Values[i])) })
+			}).String(),
+			Reverse: s.Build("DELETE FROM").Table(lookup).String(),
+			Comment: fmt.Sprintf("insert the values of enum %q", e.T),
+		},
+		&migrate.Change{
+			Cmd:     s.alterLookupType(l.Table, c, TypeText),
+			Reverse: s.alterLookupType(l.Table, c, s.enumIdent(e)),
+			Comment: fmt.Sprintf("change the type of column %q to text", c.Name),
+		},
+		&migrate.Change{
+			Cmd: s.Build("ALTER TABLE").Table(l.Table).P("ADD CONSTRAINT").Ident(fk).P("FOREIGN KEY").Wrap(func(b *sqlx.Builder) {
+				b.Ident(c.Name)
+			}).P("REFERENCES").RefTable(l.Table, lookup).Wrap(func(b *sqlx.Builder) {
+				b.Ident(value)
+			}).String(),
+			Reverse: s.Build("ALTER TABLE").Table(l.Table).P("DROP CONSTRAINT").Ident(fk).String(),
+			Comment: fmt.Sprintf("reference the lookup table %q", lookup.Name),
+		},
+	)
+	return &s.Plan, nil
+}
+
+// validate checks the enum lookup is valid and returns the enum type of its column.
+func (l *EnumLookup) validate() (*schema.EnumType, error) {
+	switch {
+	case l.Table == nil:
+		return nil, errors.New("missing table for enum lookup")
+	case l.Column == nil:
+		return nil, fmt.Errorf("missing column for enum lookup of table %q", l.Table.Name)
+	}
+	if c, ok := l.Table.Column(l.Column.Name); !ok || c != l.Column {
+		return nil, fmt.Errorf("column %q does not belong to table %q", l.Column.Name, l.Table.Name)
+	}
+	e, ok := l.Column.Type.Type.(*schema.EnumType)
+	if !ok {
+		return nil, fmt.Errorf("column %q of table %q is not an enum", l.Column.Name, l.Table.Name)
+	}
+	if len(e.Values) == 0 {
+		return nil, fmt.Errorf("enum %q of column %q has no values", e.T, l.Column.Name)
+	}
+	return e, nil
+}
+
+// alterLookupType returns the statement for changing the type of the given column. The column
+// default, if exists, is dropped and set again, as it cannot be cast automatically from text.
+func (s *state) alterLookupType(t *schema.Table, c *schema.Column, typ string) string {
+	b := s.Build("ALTER TABLE").Table(t)
+	if c.Default != nil {
+		b.P("ALTER COLUMN").Ident(c.Name).P("DROP DEFAULT").Comma()
+	}
+	b.P("ALTER COLUMN").Ident(c.Name).P("TYPE", typ, "USING", fmt.Sprintf("%q::%s", c.Name, typ))
+	if c.Default != nil {
+		b.Comma().P("ALTER COLUMN").Ident(c.Name).P("SET")
+		s.formatDefault(b, c.Type.Type, c.Default)
+	}
+	return b.String()
+}
@@ -0,0 +1,75 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+//go:build !ent
+
+package postgres
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriver_EnumLookupPlan(t *testing.T) {
+	var (
+		drv    = &Driver{}
+		public = schema.New("public")
+		status = &schema.EnumType{T: "status", Schema: public, Values: []string{"active", "blocked"}}
+		users  = schema.NewTable("users").
+			SetSchema(public).
+			AddColumns(
+				schema.NewIntColumn("id", TypeInteger),
+				schema.NewColumn("status").SetType(status).SetDefault(&schema.Literal{V: "'active'"}),
+				schema.NewStringColumn("name", TypeText),
+			)
+	)
+	plan, err := drv.EnumLookupPlan("status_lookup", &EnumLookup{Table: users, Column: users.Columns[1]})
+	require.NoError(t, err)
+	require.Equal(t, "status_lookup", plan.Name)
+	require.True(t, plan.Transactional)
+	require.True(t, plan.Reversible)
+	require.Len(t, plan.Changes, 4)
+	for i, c := range []struct{ cmd, reverse string }{
+		{
+			cmd:     `CREATE TABLE "public"."status_values" ("value" text NOT NULL PRIMARY KEY)`,
+			reverse: `DROP TABLE "public"."status_values"`,
+		},
+		{
+			cmd:     `INSERT INTO "public"."status_values" ("value") VALUES ('active'), ('blocked')`,
+			reverse: `DELETE FROM "public"."status_values"`,
+		},
+		{
+			cmd:     `ALTER TABLE "public"."users" ALTER COLUMN "status" DROP DEFAULT, ALTER COLUMN "status" TYPE text USING "status"::text, ALTER COLUMN "status" SET DEFAULT 'active'`,
+			reverse: `ALTER TABLE "public"."users" ALTER COLUMN "status" DROP DEFAULT, ALTER COLUMN "status" TYPE "public"."status" USING "status"::"public"."status", ALTER COLUMN "status" SET DEFAULT 'active'`,
+		},
+		{
+			cmd:     `ALTER TABLE "public"."users" ADD CONSTRAINT "users_status_fkey" FOREIGN KEY ("status") REFERENCES "public"."status_values" ("value")`,
+			reverse: `ALTER TABLE "public"."users" DROP CONSTRAINT "users_status_fkey"`,
+		},
+	} {
+		require.Equal(t, c.cmd, plan.Changes[i].Cmd)
+		require.Equal(t, c.reverse, plan.Changes[i].Reverse)
+	}
+
+	// Custom names, without a default and schema qualifier.
+	users.Columns[1].Default = nil
+	plan, err = drv.EnumLookupPlan("status_lookup", &EnumLookup{Table: users, Column: users.Columns[1], Name: "statuses", Constraint: "status_fk"}, func(o *migrate.PlanOptions) {
+		o.SchemaQualifier = new(string)
+	})
+	require.NoError(t, err)
+	require.Equal(t, `CREATE TABLE "statuses" ("value" text NOT NULL PRIMARY KEY)`, plan.Changes[0].Cmd)
+	require.Equal(t, `ALTER TABLE "users" ALTER COLUMN "status" TYPE text USING "status"::text`, plan.Changes[2].Cmd)
+	require.Equal(t, `ALTER TABLE "users" ALTER COLUMN "status" TYPE "status" USING "status"::"status"`, plan.Changes[2].Reverse)
+	require.Equal(t, `ALTER TABLE "users" ADD CONSTRAINT "status_fk" FOREIGN KEY ("status") REFERENCES "statuses" ("value")`, plan.Changes[3].Cmd)
+
+	// Invalid columns.
+	_, err = drv.EnumLookupPlan("", &EnumLookup{Table: users, Column: users.Columns[2]})
+	require.EqualError(t, err, `postgres: column "name" of table "users" is not an enum`)
+	_, err = drv.EnumLookupPlan("", &EnumLookup{Table: users, Column: schema.NewColumn("status")})
+	require.EqualError(t, err, `postgres: column "status" does not belong to table "users"`)
+}
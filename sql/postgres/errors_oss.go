@@ -0,0 +1,39 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+//go:build !ent
+
+package postgres
+
+import (
+	"errors"
+	"strings"
+
+	"ariga.io/atlas/sql/sqlerr"
+)
+
+// errorKind classifies the errors returned by the server based on their SQLSTATE code,
+// as exposed by the common drivers (e.g., *pq.Error and *pgconn.PgError).
+// See: https://www.postgresql.org/docs/current/errcodes-appendix.html
+func errorKind(err error) sqlerr.Kind {
+	var e interface{ SQLState() string }
+	if !errors.As(err, &e) {
+		return sqlerr.Unknown
+	}
+	switch code := e.SQLState(); {
+	case strings.HasPrefix(code, "23"):
+		// Class 23 - Integrity Constraint Violation.
+		return sqlerr.ConstraintViolation
+	case code == "3D000", code == "3F000", code == "42P01", code == "42703", code == "42704", code == "42883":
+		return sqlerr.NotExist
+	case code == "0A000":
+		return sqlerr.Unsupported
+	case code == "55P03", code == "40P01":
+		return sqlerr.Locked
+	case code == "42501", code == "28000", code == "28P01":
+		return sqlerr.PermissionDenied
+	default:
+		return sqlerr.Unknown
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+//go:build !ent
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/sqlerr"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+// pgError mimics the error types returned by the PostgreSQL drivers.
+type pgError struct {
+	Code, Message string
+}
+
+func (e *pgError) Error() string    { return fmt.Sprintf("pq: %s", e.Message) }
+func (e *pgError) SQLState() string { return e.Code }
+
+func TestDriver_ErrorKind(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	for _, tt := range []struct {
+		err  error
+		kind sqlerr.Kind
+	}{
+		{err: &pgError{Code: "42P01", Message: `relation "users" does not exist`}, kind: sqlerr.NotExist},
+		{err: &pgError{Code: "0A000", Message: "cannot alter type of a column used by a view or rule"}, kind: sqlerr.Unsupported},
+		{err: &pgError{Code: "55P03", Message: `could not obtain lock on relation "users"`}, kind: sqlerr.Locked},
+		{err: &pgError{Code: "42501", Message: "permission denied for schema public"}, kind: sqlerr.PermissionDenied},
+		{err: &pgError{Code: "23505", Message: `duplicate key value violates unique constraint "users_pkey"`}, kind: sqlerr.ConstraintViolation},
+		{err: fmt.Errorf("driver: %w", &pgError{Code: "23503", Message: "insert or update violates foreign key constraint"}), kind: sqlerr.ConstraintViolation},
+		{err: &pgError{Code: "42601", Message: "syntax error"}, kind: sqlerr.Unknown},
+	} {
+		m.ExpectExec(sqltest.Escape(`INSERT INTO "users" VALUES (1)`)).WillReturnError(tt.err)
+		_, err := drv.ExecContext(context.Background(), `INSERT INTO "users" VALUES (1)`)
+		require.EqualError(t, err, tt.err.Error())
+		require.Equal(t, tt.kind, sqlerr.KindOf(err))
+		require.ErrorIs(t, err, tt.err)
+	}
+	m.ExpectQuery(sqltest.Escape(`SELECT * FROM "users"`)).WillReturnError(&pgError{Code: "42501"})
+	_, err = drv.QueryContext(context.Background(), `SELECT * FROM "users"`)
+	require.ErrorIs(t, err, sqlerr.PermissionDenied)
+	require.NoError(t, m.ExpectationsWereMet())
+}
@@ -13,7 +13,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -45,7 +47,7 @@ func (i *inspect) InspectRealm(ctx context.Context, opts *schema.InspectRealmOpt
 		opts = &schema.InspectRealmOption{}
 	}
 	var (
-		r    = schema.NewRealm(schemas...)
+		r    = schema.NewRealm(schemas...).SetServerInfo(i.serverInfo())
 		mode = sqlx.ModeInspectRealm(opts)
 	)
 	if len(schemas) > 0 {
@@ -86,11 +88,69 @@ func (i *inspect) InspectRealm(ctx context.Context, opts *schema.InspectRealmOpt
 				return nil, err
 			}
 		}
+		if mode.Is(InspectMaterializedViews) {
+			if err := i.inspectMaterialized(ctx, r); err != nil {
+				return nil, err
+			}
+		}
+		if mode.Is(InspectForeignData) {
+			if err := i.inspectForeignData(ctx, r); err != nil {
+				return nil, err
+			}
+		}
+		if mode.Is(InspectTextSearch) {
+			if err := i.inspectTextSearch(ctx, r); err != nil {
+				return nil, err
+			}
+		}
+		if mode.Is(InspectPartitions) {
+			if err := i.inspectPartitions(ctx, r); err != nil {
+				return nil, err
+			}
+		}
+		if mode.Is(InspectOperators) {
+			if err := i.inspectOperators(ctx, r); err != nil {
+				return nil, err
+			}
+		}
+		if mode.Is(InspectPublications) {
+			if err := i.inspectPublications(ctx, r); err != nil {
+				return nil, err
+			}
+		}
+		if mode.Is(InspectColumnPrivileges) {
+			if err := i.inspectColumnGrants(ctx, r); err != nil {
+				return nil, err
+			}
+		}
+		if mode.Is(InspectTablePrivileges) {
+			if err := i.inspectTableGrants(ctx, r); err != nil {
+				return nil, err
+			}
+		}
+		if mode.Is(InspectSchemaPrivileges) {
+			if err := i.inspectSchemaGrants(ctx, r); err != nil {
+				return nil, err
+			}
+		}
 		if err := i.inspectDeps(ctx, r, nil); err != nil {
 			return nil, err
 		}
+		defaultDeps(r)
+		triggerDeps(r)
+	}
+	if mode.Is(InspectCronJobs) {
+		if err := i.inspectCronJobs(ctx, r); err != nil {
+			return nil, err
+		}
+	}
+	if mode.Is(InspectSettings) {
+		if err := i.inspectSettings(ctx, r); err != nil {
+			return nil, err
+		}
 	}
-	return schema.ExcludeRealm(r, opts.Exclude)
+	schema.SortRealm(r)
+	return schema.ExcludeRealm(r, opts.Excluded())
 }
 
 // noSearchPath ensures the session search_path is clean when inspecting realms to ensures all
@@ -145,7 +205,7 @@ func (i *inspect) InspectSchema(ctx context.Context, name string, opts *schema.I
 		opts = &schema.InspectOptions{}
 	}
 	var (
-		r    = schema.NewRealm(schemas...)
+		r    = schema.NewRealm(schemas...).SetServerInfo(i.serverInfo())
 		mode = sqlx.ModeInspectSchema(opts)
 	)
 	if mode.Is(schema.InspectTypes) {
@@ -182,10 +242,53 @@ func (i *inspect) InspectSchema(ctx context.Context, name string, opts *schema.I
 			return nil, err
 		}
 	}
+	if mode.Is(InspectMaterializedViews) {
+		if err := i.inspectMaterialized(ctx, r); err != nil {
+			return nil, err
+		}
+	}
+	if mode.Is(InspectForeignData) {
+		if err := i.inspectForeignData(ctx, r); err != nil {
+			return nil, err
+		}
+	}
+	if mode.Is(InspectTextSearch) {
+		if err := i.inspectTextSearch(ctx, r); err != nil {
+			return nil, err
+		}
+	}
+	if mode.Is(InspectPartitions) {
+		if err := i.inspectPartitions(ctx, r); err != nil {
+			return nil, err
+		}
+	}
+	if mode.Is(InspectOperators) {
+		if err := i.inspectOperators(ctx, r); err != nil {
+			return nil, err
+		}
+	}
+	if mode.Is(InspectColumnPrivileges) {
+		if err := i.inspectColumnGrants(ctx, r); err != nil {
+			return nil, err
+		}
+	}
+	if mode.Is(InspectTablePrivileges) {
+		if err := i.inspectTableGrants(ctx, r); err != nil {
+			return nil, err
+		}
+	}
+	if mode.Is(InspectSchemaPrivileges) {
+		if err := i.inspectSchemaGrants(ctx, r); err != nil {
+			return nil, err
+		}
+	}
 	if err := i.inspectDeps(ctx, r, opts); err != nil {
 		return nil, err
 	}
-	return schema.ExcludeSchema(r.Schemas[0], opts.Exclude)
+	defaultDeps(r)
+	triggerDeps(r)
+	schema.SortRealm(r)
+	return schema.ExcludeSchema(r.Schemas[0], opts.Excluded())
 }
 
 func (i *inspect) inspectTables(ctx context.Context, r *schema.Realm, opts *schema.InspectOptions) error {
@@ -211,6 +314,12 @@ func (i *inspect) inspectTables(ctx context.Context, r *schema.Realm, opts *sche
 		if err := i.checks(ctx, s); err != nil {
 			return err
 		}
+		if err := i.statistics(ctx, s); err != nil {
+			return err
+		}
+		if err := i.rules(ctx, s); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -235,12 +344,15 @@ func (i *inspect) tables(ctx context.Context, realm *schema.Realm, opts *schema.
 		return err
 	}
 	defer rows.Close()
+	// Parents are resolved after all tables were added,
+	// as children may inherit tables that are listed after them.
+	inherits := make(map[*schema.Table][][]string)
 	for rows.Next() {
 		var (
-			oid                                                            sql.NullInt64
-			tSchema, name, comment, partattrs, partstart, partexprs, extra sql.NullString
+			oid                                                                sql.NullInt64
+			tSchema, name, comment, partattrs, partstart, partexprs, am, extra sql.NullString
 		)
-		if err := rows.Scan(&oid, &tSchema, &name, &comment, &partattrs, &partstart, &partexprs, &extra); err != nil {
+		if err := rows.Scan(&oid, &tSchema, &name, &comment, &partattrs, &partstart, &partexprs, &am, &extra); err != nil {
 			return fmt.Errorf("scan table information: %w", err)
 		}
 		if !sqlx.ValidString(tSchema) || !sqlx.ValidString(name) {
@@ -265,15 +377,71 @@ func (i *inspect) tables(ctx context.Context, realm *schema.Realm, opts *schema.
 				exprs: partexprs.String,
 			})
 		}
+		// Access methods are recorded only if they differ from the default.
+		if sqlx.ValidString(am) && am.String != i.accessMethod {
+			t.AddAttrs(&AccessMethod{V: am.String})
+		}
+		if sqlx.ValidString(extra) {
+			var attrs struct {
+				Inherits     [][]string `json:"inherits"`
+				Options      []string   `json:"options"`
+				ToastOptions []string   `json:"toast_options"`
+				Tablespace   string     `json:"tablespace"`
+				Unlogged     bool       `json:"unlogged"`
+			}
+			if err := json.Unmarshal([]byte(extra.String), &attrs); err != nil {
+				return fmt.Errorf("postgres: unmarshal attributes of table %q: %w", t.Name, err)
+			}
+			if len(attrs.Inherits) > 0 {
+				inherits[t] = attrs.Inherits
+			}
+			if p := tableStorageParams(attrs.Options, attrs.ToastOptions); len(p.V) > 0 {
+				t.AddAttrs(p)
+			}
+			if attrs.Tablespace != "" {
+				t.AddAttrs(&Tablespace{V: attrs.Tablespace})
+			}
+			if attrs.Unlogged {
+				t.AddAttrs(&Unlogged{})
+			}
+		}
 	}
-	return rows.Err()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for t, parents := range inherits {
+		in := &Inherits{}
+		for _, p := range parents {
+			if len(p) != 2 {
+				return fmt.Errorf("postgres: unexpected parent %q of table %q", p, t.Name)
+			}
+			in.T = append(in.T, inheritedTable(realm, p[0], p[1]))
+		}
+		t.AddAttrs(in)
+	}
+	return nil
+}
+
+// inheritedTable returns the parent table with the given name from the
+// realm. Parents that were not inspected are returned as table references.
+func inheritedTable(r *schema.Realm, ns, name string) *schema.Table {
+	if s, ok := r.Schema(ns); ok {
+		if t, ok := s.Table(name); ok {
+			return t
+		}
+		return schema.NewTable(name).SetSchema(s)
+	}
+	return schema.NewTable(name).SetSchema(schema.New(ns))
 }
 
 // columns queries and appends the columns of the given table.
 func (i *inspect) columns(ctx context.Context, s *schema.Schema) error {
 	query := columnsQuery
-	if i.crdb {
+	switch {
+	case i.crdb:
 		query = crdbColumnsQuery
+	case i.version >= 14_00_00:
+		query = columnsAbove14
 	}
 	rows, err := i.querySchema(ctx, query, s)
 	if err != nil {
@@ -291,12 +459,14 @@ func (i *inspect) columns(ctx context.Context, s *schema.Schema) error {
 // addColumn scans the current row and adds a new column from it to the scope (table or view).
 func (i *inspect) addColumn(s *schema.Schema, rows *sql.Rows) (err error) {
 	var (
-		typid, typelem, maxlen, precision, timeprecision, scale, seqstart, seqinc, seqlast, attnum                                 sql.NullInt64
+		typid, typelem, maxlen, precision, timeprecision, scale, seqstart, seqinc, seqlast, seqcache, attnum                       sql.NullInt64
 		table, name, typ, fmtype, nullable, defaults, identity, genidentity, genexpr, charset, collate, comment, typtype, interval sql.NullString
+		seqmin, seqmax, seqcycle, compression                                                                                      sql.NullString
 	)
 	if err = rows.Scan(
 		&table, &name, &typ, &fmtype, &nullable, &defaults, &maxlen, &precision, &timeprecision, &scale, &interval, &charset,
 		&collate, &identity, &seqstart, &seqinc, &seqlast, &genidentity, &genexpr, &comment, &typtype, &typelem, &typid, &attnum,
+		&seqmin, &seqmax, &seqcache, &seqcycle, &compression,
 	); err != nil {
 		return err
 	}
@@ -345,14 +515,22 @@ func (i *inspect) addColumn(s *schema.Schema, rows *sql.Rows) (err error) {
 		columnDefault(c, defaults.String)
 	}
 	if identity.String == "YES" {
-		c.Attrs = append(c.Attrs, &Identity{
-			Generation: genidentity.String,
-			Sequence: &Sequence{
-				Last:      seqlast.Int64,
-				Start:     seqstart.Int64,
-				Increment: seqinc.Int64,
-			},
-		})
+		seq := &Sequence{
+			Last:      seqlast.Int64,
+			Start:     seqstart.Int64,
+			Increment: seqinc.Int64,
+			Cache:     seqcache.Int64,
+			Cycle:     seqcycle.String == "YES",
+		}
+		// Min and max values are omitted if they are the defaults of the column type.
+		d1, d2 := seqMinMax(&Sequence{Type: c.Type.Type, Increment: seqinc.Int64})
+		if v, err := strconv.ParseInt(seqmin.String, 10, 64); err == nil && v != d1 {
+			seq.Min = &v
+		}
+		if v, err := strconv.ParseInt(seqmax.String, 10, 64); err == nil && v != d2 {
+			seq.Max = &v
+		}
+		c.Attrs = append(c.Attrs, &Identity{Generation: genidentity.String, Sequence: seq})
 	}
 	if sqlx.ValidString(genexpr) {
 		c.Attrs = append(c.Attrs, &schema.GeneratedExpr{
@@ -368,6 +546,9 @@ func (i *inspect) addColumn(s *schema.Schema, rows *sql.Rows) (err error) {
 	if sqlx.ValidString(collate) {
 		c.SetCollation(collate.String)
 	}
+	if sqlx.ValidString(compression) {
+		c.Attrs = append(c.Attrs, &Compression{V: compression.String})
+	}
 	t.AddColumns(c)
 	return nil
 }
@@ -423,6 +604,8 @@ func (i *inspect) underlyingType(s *schema.Schema, u *UserDefinedType) schema.Ty
 				return d
 			} else if c, ok := o.(*CompositeType); ok && c.T == name {
 				return c
+			} else if rt, ok := o.(*UserRangeType); ok && rt.T == name {
+				return rt
 			}
 		}
 	}
@@ -430,147 +613,1997 @@ func (i *inspect) underlyingType(s *schema.Schema, u *UserDefinedType) schema.Ty
 	return u
 }
 
-// enumValues fills enum columns with their values from the database.
-func (i *inspect) inspectEnums(ctx context.Context, r *schema.Realm) error {
-	var (
-		ids  = make(map[int64]*schema.EnumType)
-		args = make([]any, 0, len(r.Schemas))
-	)
+// Driver-specific inspection modes. Unlike the standard modes,
+// they are not enabled by default and must be set explicitly.
+const (
+	// InspectCronJobs enables the inspection of pg_cron jobs. It requires
+	// the pg_cron extension to be installed in the inspected database.
+	InspectCronJobs schema.InspectMode = 1 << (16 + iota)
+
+	// InspectSettings enables the inspection of the server parameters listed in
+	// InspectedSettings. The parameters are attached to the realm as a Settings
+	// attribute, and they are not part of the schema diff (read-only).
+	InspectSettings
+
+	// InspectMaterializedViews enables the inspection of materialized views. The views
+	// are added to the schema objects with their columns, indexes, storage parameters
+	// and population state (i.e., WITH [NO] DATA).
+	InspectMaterializedViews
+
+	// InspectColumnPrivileges enables the inspection of column-level privileges (column ACLs).
+	// The privileges are attached to their columns as ColumnGrant attributes, and allow
+	// versioning the security configuration of the tables together with the schema.
+	InspectColumnPrivileges
+
+	// InspectTablePrivileges enables the inspection of table-level privileges (table ACLs).
+	// The privileges are attached to their tables as TableGrant attributes. Privileges of
+	// the table owner are implicit, and therefore, are not inspected.
+	InspectTablePrivileges
+
+	// InspectSchemaPrivileges enables the inspection of schema-level privileges (schema ACLs).
+	// The privileges are attached to their schemas as SchemaGrant attributes. Privileges of
+	// the schema owner are implicit, and therefore, are not inspected.
+	InspectSchemaPrivileges
+
+	// InspectForeignData enables the inspection of foreign servers, user mappings and foreign
+	// tables (SQL/MED). Servers and user mappings are added to the realm objects, and foreign
+	// tables are added to the schema objects with their columns and options.
+	InspectForeignData
+
+	// InspectTextSearch enables the inspection of text search dictionaries and configurations
+	// (i.e., full-text search setups). Both are added to the schema objects, and configurations
+	// hold the dictionaries that are consulted for each of their token types.
+	InspectTextSearch
+
+	// InspectPartitions enables the inspection of the child partitions of partitioned tables.
+	// Partitions are added to the schema objects, and are linked to their parent table (or
+	// parent partition, in case of sub-partitioning) together with their bound expressions.
+	InspectPartitions
+
+	// InspectOperators enables the inspection of user-defined operators, operator families and
+	// operator classes. All are added to the schema objects, which allows creating the operator
+	// classes that are used by indexes before the indexes themselves.
+	InspectOperators
+
+	// InspectPublications enables the inspection of logical replication publications. Publications
+	// are added to the realm objects with their tables, schemas, column lists and row filters, which
+	// allows versioning the replication topology of the database together with its schema.
+	InspectPublications
+)
+
+// InspectPrivileges enables the inspection of the privileges granted on schemas, tables and columns.
+const InspectPrivileges = InspectColumnPrivileges | InspectTablePrivileges | InspectSchemaPrivileges
+
+// PublicationOps lists the operations that can be published by a publication.
+var PublicationOps = []string{"insert", "update", "delete", "truncate"}
+
+// InspectedSettings lists the server parameters that are inspected in InspectSettings mode.
+// Note, only parameters that affect the behavior of the schema should be inspected.
+var InspectedSettings = []string{
+	"DateStyle",
+	"IntervalStyle",
+	"TimeZone",
+	"bytea_output",
+	"default_table_access_method",
+	"default_text_search_config",
+	"lc_collate",
+	"lc_ctype",
+	"search_path",
+	"server_encoding",
+	"standard_conforming_strings",
+}
+
+// inspectCronJobs adds the named pg_cron jobs of the current database to the realm.
+func (i *inspect) inspectCronJobs(ctx context.Context, r *schema.Realm) error {
+	rows, err := i.QueryContext(ctx, cronJobsQuery)
+	if err != nil {
+		return fmt.Errorf("postgres: querying pg_cron jobs: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		j := &CronJob{}
+		if err := rows.Scan(&j.Name, &j.Schedule, &j.Command); err != nil {
+			return fmt.Errorf("postgres: scanning pg_cron job: %w", err)
+		}
+		r.AddObjects(j)
+	}
+	return rows.Err()
+}
+
+// inspectTableTriggers adds the triggers of the inspected tables. Internal triggers
+// (e.g., the ones implementing foreign keys) and constraint triggers are skipped.
+func (i *inspect) inspectTableTriggers(ctx context.Context, r *schema.Realm) error {
+	args := make([]any, 0, len(r.Schemas))
 	for _, s := range r.Schemas {
-		args = append(args, s.Name)
+		if len(s.Tables) > 0 {
+			args = append(args, s.Name)
+		}
 	}
-	if len(args) == 0 {
+	if len(args) == 0 || i.crdb {
 		return nil
 	}
-	rows, err := i.QueryContext(ctx, fmt.Sprintf(enumsQuery, nArgs(0, len(args))), args...)
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(triggersQuery, nArgs(0, len(args))), args...)
 	if err != nil {
-		return fmt.Errorf("postgres: querying enum values: %w", err)
+		return fmt.Errorf("postgres: querying triggers: %w", err)
 	}
 	defer rows.Close()
 	for rows.Next() {
 		var (
-			id       int64
-			ns, n, v string
+			typ                  int64
+			ns, table, name, def string
+			enabled, columns     string
 		)
-		if err := rows.Scan(&ns, &id, &n, &v); err != nil {
-			return fmt.Errorf("postgres: scanning enum label: %w", err)
+		if err := rows.Scan(&ns, &table, &name, &typ, &enabled, &columns, &def); err != nil {
+			return fmt.Errorf("postgres: scanning trigger: %w", err)
 		}
-		e, ok := ids[id]
+		s, ok := r.Schema(ns)
 		if !ok {
-			e = &schema.EnumType{T: n}
-			ids[id] = e
+			return fmt.Errorf("postgres: schema %q for trigger %q was not found in inspection", ns, name)
 		}
-		if e.Schema == nil {
-			s, ok := r.Schema(ns)
-			if !ok {
-				return fmt.Errorf("postgres: schema %q for enum %q was not found in inspection", ns, e.T)
+		t, ok := s.Table(table)
+		if !ok {
+			continue // Not inspected (e.g., a partition).
+		}
+		var names []string
+		if err := json.Unmarshal([]byte(columns), &names); err != nil {
+			return fmt.Errorf("postgres: parsing columns of trigger %q: %w", name, err)
+		}
+		tg, err := triggerFromDef(t, name, typ, names, def)
+		if err != nil {
+			return err
+		}
+		switch enabled {
+		case "D":
+			tg.Attrs = append(tg.Attrs, &TriggerState{V: TriggerStateDisabled})
+		case "R":
+			tg.Attrs = append(tg.Attrs, &TriggerState{V: TriggerStateReplica})
+		case "A":
+			tg.Attrs = append(tg.Attrs, &TriggerState{V: TriggerStateAlways})
+		}
+		t.Triggers = append(t.Triggers, tg)
+	}
+	return rows.Err()
+}
+
+// Bits of the pg_trigger.tgtype column.
+const (
+	triggerTypeRow      = 1 << 0
+	triggerTypeBefore   = 1 << 1
+	triggerTypeInsert   = 1 << 2
+	triggerTypeDelete   = 1 << 3
+	triggerTypeUpdate   = 1 << 4
+	triggerTypeTruncate = 1 << 5
+	triggerTypeInstead  = 1 << 6
+)
+
+// triggerFromDef builds the trigger from its type bits, the columns of its UPDATE
+// OF event and its definition, from which the WHEN clause and the function call
+// are extracted. e.g., "... FOR EACH ROW WHEN (cond) EXECUTE FUNCTION f()".
+func triggerFromDef(t *schema.Table, name string, typ int64, columns []string, def string) (*schema.Trigger, error) {
+	tg := &schema.Trigger{
+		Name:       name,
+		Table:      t,
+		ActionTime: schema.TriggerTimeAfter,
+		For:        schema.TriggerForStmt,
+	}
+	switch {
+	case typ&triggerTypeBefore != 0:
+		tg.ActionTime = schema.TriggerTimeBefore
+	case typ&triggerTypeInstead != 0:
+		tg.ActionTime = schema.TriggerTimeInstead
+	}
+	if typ&triggerTypeRow != 0 {
+		tg.For = schema.TriggerForRow
+	}
+	if typ&triggerTypeInsert != 0 {
+		tg.Events = append(tg.Events, schema.TriggerEventInsert)
+	}
+	if typ&triggerTypeUpdate != 0 {
+		e := schema.TriggerEventUpdate
+		if len(columns) > 0 {
+			cs := make([]*schema.Column, len(columns))
+			for i, n := range columns {
+				c, ok := t.Column(n)
+				if !ok {
+					return nil, fmt.Errorf("postgres: column %q of trigger %q was not found in table %q", n, name, t.Name)
+				}
+				cs[i] = c
 			}
-			e.Schema = s
-			s.Objects = append(s.Objects, e)
+			e = schema.TriggerEventUpdateOf(cs...)
 		}
-		e.Values = append(e.Values, v)
+		tg.Events = append(tg.Events, e)
 	}
-	return nil
+	if typ&triggerTypeDelete != 0 {
+		tg.Events = append(tg.Events, schema.TriggerEventDelete)
+	}
+	if typ&triggerTypeTruncate != 0 {
+		tg.Events = append(tg.Events, schema.TriggerEventTruncate)
+	}
+	exec := -1
+	for _, k := range []string{" EXECUTE FUNCTION ", " EXECUTE PROCEDURE "} {
+		if exec = strings.LastIndex(def, k); exec != -1 {
+			tg.Body = strings.TrimSpace(def[exec+len(k):])
+			break
+		}
+	}
+	if exec == -1 {
+		return nil, fmt.Errorf("postgres: unexpected definition for trigger %q: %q", name, def)
+	}
+	if w := strings.Index(def, " WHEN ("); w != -1 && w < exec {
+		tg.Attrs = append(tg.Attrs, &TriggerWhen{X: strings.TrimSpace(def[w+len(" WHEN ") : exec])})
+	}
+	return tg, nil
 }
 
-// indexes queries and appends the indexes of the given table.
-func (i *inspect) indexes(ctx context.Context, s *schema.Schema) error {
+// inspectExtensions adds the installed extensions of the current database to the realm.
+// Only extensions that were installed in one of the inspected schemas are added, as the
+// objects they provide (e.g., types and functions) are owned by these schemas.
+func (i *inspect) inspectExtensions(ctx context.Context, r *schema.Realm) error {
 	if i.crdb {
-		return i.crdbIndexes(ctx, s)
+		return nil
 	}
-	rows, err := i.querySchema(ctx, i.indexesQuery(), s)
+	rows, err := i.QueryContext(ctx, extensionsQuery)
 	if err != nil {
-		return fmt.Errorf("postgres: querying schema %q indexes: %w", s.Name, err)
+		return fmt.Errorf("postgres: querying extensions: %w", err)
 	}
 	defer rows.Close()
-	if err := i.addIndexes(s, rows, queryScope{
-		hasT: func(tv string) bool {
-			_, ok := s.Table(tv)
-			return ok
-		},
-		setPK: func(tv string, idx *schema.Index) error {
-			if t, ok := s.Table(tv); ok {
-				t.SetPrimaryKey(idx)
-				return nil
-			}
-			return fmt.Errorf("postgres: table %q for primary key was not found in schema", tv)
-		},
-		addIndex: func(tv string, idx *schema.Index) error {
-			if t, ok := s.Table(tv); ok {
-				t.AddIndexes(idx)
-				return nil
-			}
-			return fmt.Errorf("postgres: table %q for index was not found in schema", tv)
-		},
-		column: func(tv, name string) (*schema.Column, bool) {
-			if t, ok := s.Table(tv); ok {
-				return t.Column(name)
-			}
-			return nil, false
-		},
-	}); err != nil {
-		return err
+	for rows.Next() {
+		var (
+			e       = &Extension{}
+			comment sql.NullString
+		)
+		if err := rows.Scan(&e.Name, &e.Schema, &e.Version, &comment); err != nil {
+			return fmt.Errorf("postgres: scanning extension: %w", err)
+		}
+		if _, ok := r.Schema(e.Schema); !ok {
+			continue
+		}
+		if sqlx.ValidString(comment) {
+			e.Attrs = append(e.Attrs, &schema.Comment{Text: comment.String})
+		}
+		r.AddObjects(e)
 	}
 	return rows.Err()
 }
 
-func (i *inspect) indexesQuery() (q string) {
-	switch {
-	case i.supportsIndexNullsDistinct():
-		q = indexesAbove15
-	case i.supportsIndexInclude():
-		q = indexesAbove11
-	default:
-		q = indexesBelow11
+// inspectEventTriggers adds the event triggers of the current database to the realm. Only
+// triggers that execute functions of the inspected schemas are added, and triggers that
+// were created by extensions are skipped, as they are managed by their extensions.
+func (i *inspect) inspectEventTriggers(ctx context.Context, r *schema.Realm) error {
+	if i.crdb {
+		return nil
 	}
-	return
-}
-
-type queryScope struct {
-	hasT     func(tv string) bool
-	setPK    func(tv string, idx *schema.Index) error
-	addIndex func(tv string, idx *schema.Index) error
-	column   func(tv, name string) (*schema.Column, bool)
-}
-
-// addIndexes scans the rows and adds the indexes to the table.
-func (i *inspect) addIndexes(s *schema.Schema, rows *sql.Rows, scope queryScope) error {
-	names := make(map[string]*schema.Index)
+	rows, err := i.QueryContext(ctx, eventTriggersQuery)
+	if err != nil {
+		return fmt.Errorf("postgres: querying event triggers: %w", err)
+	}
+	defer rows.Close()
 	for rows.Next() {
 		var (
-			table, name, typ                                                                         string
-			uniq, primary, included, nullsnotdistinct                                                bool
-			desc, nullsfirst, nullslast, opcdefault                                                  sql.NullBool
-			column, constraints, pred, expr, comment, options, opcname, opcschema, opcparams, exoper sql.NullString
+			e                           = &EventTrigger{}
+			enabled, tags, ns, function string
+			comment                     sql.NullString
 		)
-		if err := rows.Scan(
-			&table, &name, &typ, &column, &included, &primary, &uniq, &exoper, &constraints, &pred, &expr, &desc,
-			&nullsfirst, &nullslast, &comment, &options, &opcname, &opcschema, &opcdefault, &opcparams, &nullsnotdistinct,
-		); err != nil {
-			return fmt.Errorf("postgres: scanning indexes for schema %q: %w", s.Name, err)
-		}
-		if !scope.hasT(table) {
-			return fmt.Errorf("table %q was not found in schema", table)
+		if err := rows.Scan(&e.Name, &e.Event, &enabled, &tags, &ns, &function, &comment); err != nil {
+			return fmt.Errorf("postgres: scanning event trigger: %w", err)
 		}
-		idx, ok := names[name]
+		s, ok := r.Schema(ns)
 		if !ok {
-			idx = &schema.Index{
-				Name:   name,
-				Unique: uniq,
-				Attrs: []schema.Attr{
-					&IndexType{T: typ},
-				},
-			}
-			if sqlx.ValidString(comment) {
-				idx.Attrs = append(idx.Attrs, &schema.Comment{Text: comment.String})
-			}
-			if sqlx.ValidString(constraints) {
-				var m map[string]string
-				if err := json.Unmarshal([]byte(constraints.String), &m); err != nil {
-					return fmt.Errorf("postgres: unmarshaling index constraints: %w", err)
+			continue
+		}
+		if e.Func, ok = s.Func(function); !ok {
+			// Functions were not inspected.
+			e.Func = &schema.Func{Name: function, Schema: s}
+		}
+		if err := json.Unmarshal([]byte(tags), &e.Tags); err != nil {
+			return fmt.Errorf("postgres: parsing tags of event trigger %q: %w", e.Name, err)
+		}
+		switch enabled {
+		case "D":
+			e.Attrs = append(e.Attrs, &TriggerState{V: TriggerStateDisabled})
+		case "R":
+			e.Attrs = append(e.Attrs, &TriggerState{V: TriggerStateReplica})
+		case "A":
+			e.Attrs = append(e.Attrs, &TriggerState{V: TriggerStateAlways})
+		}
+		if sqlx.ValidString(comment) {
+			e.Attrs = append(e.Attrs, &schema.Comment{Text: comment.String})
+		}
+		r.AddObjects(e)
+	}
+	return rows.Err()
+}
+
+// inspectPublications adds the logical replication publications of the current database to the
+// realm. Publications that publish tables or schemas that were not inspected are skipped, as they
+// cannot be fully described by the inspected realm.
+func (i *inspect) inspectPublications(ctx context.Context, r *schema.Realm) error {
+	if i.crdb {
+		return nil
+	}
+	truncate := "true"
+	if i.version >= 11_00_00 {
+		truncate = "p.pubtruncate"
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(publicationsQuery, truncate))
+	if err != nil {
+		return fmt.Errorf("postgres: querying publications: %w", err)
+	}
+	var (
+		pubs    []*Publication
+		names   = make(map[string]*Publication)
+		skipped = make(map[string]bool)
+	)
+	if err := func() error {
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				p                                  = &Publication{}
+				insert, update, remove, truncation bool
+				comment                            sql.NullString
+			)
+			if err := rows.Scan(&p.Name, &p.AllTables, &insert, &update, &remove, &truncation, &comment); err != nil {
+				return fmt.Errorf("postgres: scanning publication: %w", err)
+			}
+			// A nil list means all operations are published (the default).
+			if ops := []bool{insert, update, remove, truncation}; slices.Contains(ops, false) {
+				p.Publish = make([]string, 0, len(ops))
+				for j, op := range PublicationOps {
+					if ops[j] {
+						p.Publish = append(p.Publish, op)
+					}
+				}
+			}
+			if sqlx.ValidString(comment) {
+				p.Attrs = append(p.Attrs, &schema.Comment{Text: comment.String})
+			}
+			pubs = append(pubs, p)
+			names[p.Name] = p
+		}
+		return rows.Err()
+	}(); err != nil || len(pubs) == 0 {
+		return err
+	}
+	// The column lists and the row filters were added in v15.
+	columns, filter := "NULL", "NULL"
+	if i.version >= 15_00_00 {
+		columns, filter = "pr.prattrs", "pg_catalog.pg_get_expr(pr.prqual, pr.prrelid)"
+	}
+	rows, err = i.QueryContext(ctx, fmt.Sprintf(publicationTablesQuery, columns, filter))
+	if err != nil {
+		return fmt.Errorf("postgres: querying publication tables: %w", err)
+	}
+	if err := func() error {
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				pub, ns, name string
+				cols, where   sql.NullString
+			)
+			if err := rows.Scan(&pub, &ns, &name, &cols, &where); err != nil {
+				return fmt.Errorf("postgres: scanning publication table: %w", err)
+			}
+			p, ok := names[pub]
+			if !ok {
+				continue
+			}
+			s, ok := r.Schema(ns)
+			if !ok {
+				skipped[pub] = true
+				continue
+			}
+			t, ok := s.Table(name)
+			if !ok {
+				// Tables were not inspected.
+				t = schema.NewTable(name).SetSchema(s)
+			}
+			pt := &PublicationTable{Table: t, Where: where.String}
+			var list []string
+			if sqlx.ValidString(cols) {
+				if err := json.Unmarshal([]byte(cols.String), &list); err != nil {
+					return fmt.Errorf("postgres: parsing columns of publication %q: %w", pub, err)
+				}
+			}
+			for _, n := range list {
+				c, ok := t.Column(n)
+				if !ok {
+					c = schema.NewColumn(n)
+				}
+				pt.Columns = append(pt.Columns, c)
+			}
+			p.Tables = append(p.Tables, pt)
+		}
+		return rows.Err()
+	}(); err != nil {
+		return err
+	}
+	// Publishing the tables of a schema was added in v15.
+	if i.version >= 15_00_00 {
+		rows, err := i.QueryContext(ctx, publicationSchemasQuery)
+		if err != nil {
+			return fmt.Errorf("postgres: querying publication schemas: %w", err)
+		}
+		if err := func() error {
+			defer rows.Close()
+			for rows.Next() {
+				var pub, ns string
+				if err := rows.Scan(&pub, &ns); err != nil {
+					return fmt.Errorf("postgres: scanning publication schema: %w", err)
+				}
+				p, ok := names[pub]
+				if !ok {
+					continue
+				}
+				if s, ok := r.Schema(ns); ok {
+					p.Schemas = append(p.Schemas, s)
+				} else {
+					skipped[pub] = true
+				}
+			}
+			return rows.Err()
+		}(); err != nil {
+			return err
+		}
+	}
+	for _, p := range pubs {
+		if !skipped[p.Name] {
+			r.AddObjects(p)
+		}
+	}
+	return nil
+}
+
+// inspectSequences adds the standalone sequences of the inspected schemas to their objects.
+// Sequences that back IDENTITY or serial columns are managed by their columns and skipped.
+func (i *inspect) inspectSequences(ctx context.Context, r *schema.Realm) error {
+	args := make([]any, 0, len(r.Schemas))
+	for _, s := range r.Schemas {
+		args = append(args, s.Name)
+	}
+	if len(args) == 0 || i.crdb {
+		return nil
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(sequencesQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying sequences: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			ns, name, typ                 string
+			start, inc, minv, maxv, cache int64
+			cycle                         bool
+			ownerT, ownerC, comment       sql.NullString
+		)
+		if err := rows.Scan(&ns, &name, &typ, &start, &inc, &minv, &maxv, &cache, &cycle, &ownerT, &ownerC, &comment); err != nil {
+			return fmt.Errorf("postgres: scanning sequence: %w", err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return fmt.Errorf("postgres: schema %q for sequence %q was not found in inspection", ns, name)
+		}
+		t, err := ParseType(typ)
+		if err != nil {
+			return err
+		}
+		seq := &Sequence{Name: name, Schema: s, Type: t, Start: start, Increment: inc, Cache: cache, Cycle: cycle}
+		// Min and max values are omitted if they are the defaults.
+		d1, d2 := seqMinMax(seq)
+		if minv != d1 {
+			seq.Min = &minv
+		}
+		if maxv != d2 {
+			seq.Max = &maxv
+		}
+		if sqlx.ValidString(ownerT) {
+			t, ok := s.Table(ownerT.String)
+			if ok {
+				seq.Owner.T = t
+				seq.Owner.C, _ = t.Column(ownerC.String)
+			}
+			// Sequences that were created by serial columns are managed by the columns.
+			if seq.Owner.C != nil && seq.Owner.C.Type != nil {
+				if _, ok := seq.Owner.C.Type.Type.(*SerialType); ok {
+					continue
+				}
+			}
+		}
+		if sqlx.ValidString(comment) {
+			seq.Attrs = append(seq.Attrs, &schema.Comment{Text: comment.String})
+		}
+		s.Objects = append(s.Objects, seq)
+	}
+	return rows.Err()
+}
+
+// inspectDomains adds the domain types of the inspected schemas to their objects. Domains are
+// inspected before the tables, so columns that use them are linked to the domain objects.
+func (i *inspect) inspectDomains(ctx context.Context, r *schema.Realm) error {
+	args := make([]any, 0, len(r.Schemas))
+	for _, s := range r.Schemas {
+		args = append(args, s.Name)
+	}
+	if len(args) == 0 || i.crdb {
+		return nil
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(domainsQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying domains: %w", err)
+	}
+	var domains []*DomainType
+	if err := func() error {
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				ns, name, typ             string
+				notnull                   bool
+				defaults, checks, comment sql.NullString
+			)
+			if err := rows.Scan(&ns, &name, &typ, &notnull, &defaults, &checks, &comment); err != nil {
+				return fmt.Errorf("postgres: scanning domain: %w", err)
+			}
+			s, ok := r.Schema(ns)
+			if !ok {
+				return fmt.Errorf("postgres: schema %q for domain %q was not found in inspection", ns, name)
+			}
+			t, err := ParseType(typ)
+			if err != nil {
+				return err
+			}
+			d := &DomainType{T: name, Schema: s, Type: t, Null: !notnull}
+			if sqlx.ValidString(defaults) {
+				d.Default = defaultExpr(t, defaults.String)
+			}
+			if sqlx.ValidString(checks) {
+				var cks []struct {
+					Name, Def string
+				}
+				if err := json.Unmarshal([]byte(checks.String), &cks); err != nil {
+					return fmt.Errorf("postgres: unmarshal checks of domain %q: %w", name, err)
+				}
+				for _, c := range cks {
+					d.Checks = append(d.Checks, &schema.Check{Name: c.Name, Expr: domainCheckExpr(c.Def)})
+				}
+			}
+			if sqlx.ValidString(comment) {
+				d.Attrs = append(d.Attrs, &schema.Comment{Text: comment.String})
+			}
+			s.Objects = append(s.Objects, d)
+			domains = append(domains, d)
+		}
+		return rows.Err()
+	}(); err != nil {
+		return err
+	}
+	// Domains may be defined on top of other user-defined types,
+	// such as enums or other domains. Hence, they are linked after
+	// all domains were added to their schemas.
+	for _, d := range domains {
+		if u, ok := d.Type.(*UserDefinedType); ok {
+			d.Type = i.underlyingType(d.Schema, u)
+			if o, ok := d.Type.(schema.Object); ok {
+				d.Deps = append(d.Deps, o)
+			}
+		}
+	}
+	return nil
+}
+
+// inspectComposites adds the composite types of the inspected schemas to their objects. Similar
+// to domains, composite types are inspected before the tables, so columns that use them are linked
+// to the composite objects. Row types that are implicitly created for tables are skipped.
+func (i *inspect) inspectComposites(ctx context.Context, r *schema.Realm) error {
+	args := make([]any, 0, len(r.Schemas))
+	for _, s := range r.Schemas {
+		args = append(args, s.Name)
+	}
+	if len(args) == 0 || i.crdb {
+		return nil
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(compositesQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying composite types: %w", err)
+	}
+	var types []*CompositeType
+	if err := func() error {
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				ns, name, fields string
+				comment          sql.NullString
+			)
+			if err := rows.Scan(&ns, &name, &fields, &comment); err != nil {
+				return fmt.Errorf("postgres: scanning composite type: %w", err)
+			}
+			s, ok := r.Schema(ns)
+			if !ok {
+				return fmt.Errorf("postgres: schema %q for composite type %q was not found in inspection", ns, name)
+			}
+			var fs []struct {
+				Name, Type string
+				Collation  *string
+			}
+			if err := json.Unmarshal([]byte(fields), &fs); err != nil {
+				return fmt.Errorf("postgres: unmarshal fields of composite type %q: %w", name, err)
+			}
+			c := &CompositeType{T: name, Schema: s}
+			for _, f := range fs {
+				t, err := ParseType(f.Type)
+				if err != nil {
+					return fmt.Errorf("postgres: parse type of field %q in composite type %q: %w", f.Name, name, err)
+				}
+				fc := &schema.Column{Name: f.Name, Type: &schema.ColumnType{Type: t, Raw: f.Type, Null: true}}
+				if f.Collation != nil {
+					fc.SetCollation(*f.Collation)
+				}
+				c.Fields = append(c.Fields, fc)
+			}
+			if sqlx.ValidString(comment) {
+				c.Attrs = append(c.Attrs, &schema.Comment{Text: comment.String})
+			}
+			s.Objects = append(s.Objects, c)
+			types = append(types, c)
+		}
+		return rows.Err()
+	}(); err != nil {
+		return err
+	}
+	// Fields may use other user-defined types, including other
+	// composite types. Hence, they are linked after all types
+	// were added to their schemas.
+	for _, c := range types {
+		for _, f := range c.Fields {
+			switch t := f.Type.Type.(type) {
+			case *ArrayType:
+				if u, ok := t.Underlying().(*UserDefinedType); ok {
+					t.Type = i.underlyingType(c.Schema, u)
+					if o, ok := t.Type.(schema.Object); ok {
+						c.Deps = append(c.Deps, o)
+					}
+				}
+			case *UserDefinedType:
+				f.Type.Type = i.underlyingType(c.Schema, t)
+				if o, ok := f.Type.Type.(schema.Object); ok {
+					c.Deps = append(c.Deps, o)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// inspectRanges adds the user-defined range types of the inspected schemas to their objects.
+// Range types that were installed by extensions are skipped, and so are the multirange types
+// that are implicitly created for them.
+func (i *inspect) inspectRanges(ctx context.Context, r *schema.Realm) error {
+	args := make([]any, 0, len(r.Schemas))
+	for _, s := range r.Schemas {
+		args = append(args, s.Name)
+	}
+	if len(args) == 0 || i.crdb {
+		return nil
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(rangesQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying range types: %w", err)
+	}
+	var types []*UserRangeType
+	if err := func() error {
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				ns, name, subtype                      string
+				collation, canonical, subdiff, comment sql.NullString
+			)
+			if err := rows.Scan(&ns, &name, &subtype, &collation, &canonical, &subdiff, &comment); err != nil {
+				return fmt.Errorf("postgres: scanning range type: %w", err)
+			}
+			s, ok := r.Schema(ns)
+			if !ok {
+				return fmt.Errorf("postgres: schema %q for range type %q was not found in inspection", ns, name)
+			}
+			t, err := ParseType(subtype)
+			if err != nil {
+				return fmt.Errorf("postgres: parse subtype of range type %q: %w", name, err)
+			}
+			rt := &UserRangeType{
+				T:           name,
+				Schema:      s,
+				Subtype:     t,
+				Collation:   collation.String,
+				Canonical:   canonical.String,
+				SubtypeDiff: subdiff.String,
+			}
+			if sqlx.ValidString(comment) {
+				rt.Attrs = append(rt.Attrs, &schema.Comment{Text: comment.String})
+			}
+			s.Objects = append(s.Objects, rt)
+			types = append(types, rt)
+		}
+		return rows.Err()
+	}(); err != nil {
+		return err
+	}
+	// The subtype might be a user-defined type, such as an enum or a domain.
+	for _, rt := range types {
+		if u, ok := rt.Subtype.(*UserDefinedType); ok {
+			rt.Subtype = i.underlyingType(rt.Schema, u)
+			if o, ok := rt.Subtype.(schema.Object); ok {
+				rt.Deps = append(rt.Deps, o)
+			}
+		}
+	}
+	return nil
+}
+
+// inspectCollations adds the user-defined collations of the inspected schemas to their objects.
+// Collations that were installed by extensions are skipped.
+func (i *inspect) inspectCollations(ctx context.Context, r *schema.Realm) error {
+	args := make([]any, 0, len(r.Schemas))
+	for _, s := range r.Schemas {
+		args = append(args, s.Name)
+	}
+	if len(args) == 0 || i.crdb {
+		return nil
+	}
+	// The deterministic property was added in v12, and the
+	// locale of non-libc providers was added in v15 (renamed in v17).
+	deterministic, locale := "true", "NULL"
+	if i.version >= 12_00_00 {
+		deterministic = "c.collisdeterministic"
+	}
+	switch {
+	case i.version >= 17_00_00:
+		locale = "c.colllocale"
+	case i.version >= 15_00_00:
+		locale = "c.colliculocale"
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(collationsQuery, deterministic, locale, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying collations: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			ns, name, provider                  string
+			deterministic                       bool
+			lcCollate, lcCtype, locale, comment sql.NullString
+		)
+		if err := rows.Scan(&ns, &name, &provider, &deterministic, &lcCollate, &lcCtype, &locale, &comment); err != nil {
+			return fmt.Errorf("postgres: scanning collation: %w", err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return fmt.Errorf("postgres: schema %q for collation %q was not found in inspection", ns, name)
+		}
+		c := &Collation{Name: name, Schema: s, Provider: provider, Deterministic: deterministic}
+		switch {
+		case sqlx.ValidString(locale):
+			c.Locale = locale.String
+		case lcCollate.String == lcCtype.String:
+			c.Locale = lcCollate.String
+		default:
+			c.LcCollate, c.LcCtype = lcCollate.String, lcCtype.String
+		}
+		if sqlx.ValidString(comment) {
+			c.Attrs = append(c.Attrs, &schema.Comment{Text: comment.String})
+		}
+		s.Objects = append(s.Objects, c)
+	}
+	return rows.Err()
+}
+
+// inspectAggregates adds the user-defined aggregate functions of the inspected schemas to their
+// objects. Aggregates that were installed by extensions are skipped, and so are ordered-set and
+// hypothetical-set aggregates. Note, the functions are qualified by the inspection search path,
+// and therefore, functions that are not defined in pg_catalog are schema-qualified.
+func (i *inspect) inspectAggregates(ctx context.Context, r *schema.Realm) error {
+	args := make([]any, 0, len(r.Schemas))
+	for _, s := range r.Schemas {
+		args = append(args, s.Name)
+	}
+	if len(args) == 0 || i.crdb {
+		return nil
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(aggregatesQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying aggregates: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			ns, name, fargs, stype, sfunc, parallel string
+			ffunc, initcond, comment                sql.NullString
+		)
+		if err := rows.Scan(&ns, &name, &fargs, &stype, &sfunc, &ffunc, &initcond, &parallel, &comment); err != nil {
+			return fmt.Errorf("postgres: scanning aggregate: %w", err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return fmt.Errorf("postgres: schema %q for aggregate %q was not found in inspection", ns, name)
+		}
+		a := &Aggregate{Name: name, Schema: s, StateFunc: sfunc, FinalFunc: ffunc.String}
+		if a.Args, err = i.funcArgs(s, fargs); err != nil {
+			return fmt.Errorf("postgres: arguments of aggregate %q: %w", name, err)
+		}
+		t, err := ParseType(stype)
+		if err != nil {
+			return fmt.Errorf("postgres: parse state type of aggregate %q: %w", name, err)
+		}
+		if u, ok := t.(*UserDefinedType); ok {
+			t = i.underlyingType(s, u)
+		}
+		a.StateType = t
+		for _, fa := range a.Args {
+			if o, ok := fa.Type.(schema.Object); ok && !slices.Contains(a.Deps, o) {
+				a.Deps = append(a.Deps, o)
+			}
+		}
+		if o, ok := t.(schema.Object); ok && !slices.Contains(a.Deps, o) {
+			a.Deps = append(a.Deps, o)
+		}
+		if initcond.Valid {
+			a.InitCond = &initcond.String
+		}
+		switch parallel {
+		case "s":
+			a.Parallel = ParallelSafe
+		case "r":
+			a.Parallel = ParallelRestricted
+		}
+		if sqlx.ValidString(comment) {
+			a.Attrs = append(a.Attrs, &schema.Comment{Text: comment.String})
+		}
+		s.AddObjects(a)
+	}
+	return rows.Err()
+}
+
+// inspectProcFuncs adds the user-defined functions and procedures of the inspected schemas.
+// Functions that were installed by extensions, aggregates and window functions are skipped,
+// and so are functions written in C or internal ones, as their body is not an SQL text.
+func (i *inspect) inspectProcFuncs(ctx context.Context, r *schema.Realm) error {
+	args := make([]any, 0, len(r.Schemas))
+	for _, s := range r.Schemas {
+		args = append(args, s.Name)
+	}
+	if len(args) == 0 || i.crdb {
+		return nil
+	}
+	// The prokind column was added in version 11, along with procedures.
+	kind := "p.prokind"
+	if i.version < 11_00_00 {
+		kind = "CASE WHEN p.proisagg THEN 'a' WHEN p.proiswindow THEN 'w' ELSE 'f' END"
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(funcsQuery, kind, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying functions: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			ns, name, kind, fargs, lang, volatility, body string
+			ret, comment                                  sql.NullString
+		)
+		if err := rows.Scan(&ns, &name, &kind, &fargs, &ret, &lang, &volatility, &body, &comment); err != nil {
+			return fmt.Errorf("postgres: scanning function: %w", err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return fmt.Errorf("postgres: schema %q for function %q was not found in inspection", ns, name)
+		}
+		var (
+			deps  []schema.Object
+			attrs []schema.Attr
+		)
+		fa, err := i.funcArgs(s, fargs)
+		if err != nil {
+			return fmt.Errorf("postgres: arguments of function %q: %w", name, err)
+		}
+		for _, a := range fa {
+			if o, ok := a.Type.(schema.Object); ok {
+				deps = append(deps, o)
+			}
+		}
+		switch volatility {
+		case "i":
+			attrs = append(attrs, &FuncVolatility{V: VolatilityImmutable})
+		case "s":
+			attrs = append(attrs, &FuncVolatility{V: VolatilityStable})
+		}
+		if sqlx.ValidString(comment) {
+			attrs = append(attrs, &schema.Comment{Text: comment.String})
+		}
+		if kind == "p" {
+			s.AddProcs(&schema.Proc{Name: name, Args: fa, Lang: lang, Body: body, Attrs: attrs, Deps: deps})
+			continue
+		}
+		f := &schema.Func{Name: name, Args: fa, Lang: lang, Body: body, Attrs: attrs, Deps: deps}
+		if f.Ret, err = i.funcReturnType(s, ret.String); err != nil {
+			return fmt.Errorf("postgres: return type of function %q: %w", name, err)
+		}
+		if o, ok := f.Ret.(schema.Object); ok {
+			f.Deps = append(f.Deps, o)
+		}
+		s.AddFuncs(f)
+	}
+	return rows.Err()
+}
+
+// funcArgs parses the JSON-encoded arguments of a function or a procedure. Columns
+// of the RETURNS TABLE clause (mode "t") are part of the function return type.
+func (i *inspect) funcArgs(s *schema.Schema, fargs string) ([]*schema.FuncArg, error) {
+	var args []struct {
+		Name, Mode, Type string
+		Default          *string
+	}
+	if err := json.Unmarshal([]byte(fargs), &args); err != nil {
+		return nil, err
+	}
+	fa := make([]*schema.FuncArg, 0, len(args))
+	for _, a := range args {
+		arg := &schema.FuncArg{Name: a.Name}
+		switch a.Mode {
+		case "t":
+			continue
+		case "o":
+			arg.Mode = schema.FuncArgModeOut
+		case "b":
+			arg.Mode = schema.FuncArgModeInOut
+		case "v":
+			arg.Mode = schema.FuncArgModeVariadic
+		}
+		t, err := ParseType(a.Type)
+		if err != nil {
+			return nil, err
+		}
+		if u, ok := t.(*UserDefinedType); ok {
+			t = i.underlyingType(s, u)
+		}
+		arg.Type = t
+		if a.Default != nil {
+			arg.Default = defaultExpr(t, *a.Default)
+		}
+		fa = append(fa, arg)
+	}
+	return fa, nil
+}
+
+// funcReturnType returns the type of the function result. Sets and tables
+// are kept as-is, as they cannot be represented by a column type.
+func (i *inspect) funcReturnType(s *schema.Schema, ret string) (schema.Type, error) {
+	if strings.HasPrefix(ret, "SETOF ") || strings.HasPrefix(ret, "TABLE(") {
+		return &UserDefinedType{T: ret}, nil
+	}
+	t, err := ParseType(ret)
+	if err != nil {
+		return nil, err
+	}
+	if u, ok := t.(*UserDefinedType); ok {
+		t = i.underlyingType(s, u)
+	}
+	return t, nil
+}
+
+// domainCheckExpr extracts the expression from a CHECK constraint
+// definition. e.g., "CHECK (VALUE > 0)" is returned as "(VALUE > 0)".
+func domainCheckExpr(def string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(def, "CHECK "), " NOT VALID")
+}
+
+// seqMinMax returns the default minimum and maximum values of the given sequence,
+// which are derived from its data type and its direction (increment sign).
+func seqMinMax(seq *Sequence) (int64, int64) {
+	maxv := int64(math.MaxInt64)
+	if t, ok := seq.Type.(*schema.IntegerType); ok {
+		switch strings.ToLower(t.T) {
+		case TypeSmallInt, TypeInt2:
+			maxv = math.MaxInt16
+		case TypeInteger, TypeInt, TypeInt4:
+			maxv = math.MaxInt32
+		}
+	}
+	if seq.Increment < 0 {
+		return -maxv - 1, -1
+	}
+	return 1, maxv
+}
+
+// inspectColumnGrants attaches the column-level privileges of the inspected tables to their
+// columns. Privileges that were granted on the table level are not part of the column ACLs.
+func (i *inspect) inspectColumnGrants(ctx context.Context, r *schema.Realm) error {
+	args := make([]any, 0, len(r.Schemas))
+	for _, s := range r.Schemas {
+		if len(s.Tables) > 0 {
+			args = append(args, s.Name)
+		}
+	}
+	// CockroachDB does not support column-level privileges.
+	if len(args) == 0 || i.crdb {
+		return nil
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(columnGrantsQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying column privileges: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			ns, table, column, grantee, privs string
+			grantable                         bool
+		)
+		if err := rows.Scan(&ns, &table, &column, &grantee, &privs, &grantable); err != nil {
+			return fmt.Errorf("postgres: scanning column privileges: %w", err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return fmt.Errorf("postgres: schema %q for column privileges was not found in inspection", ns)
+		}
+		t, ok := s.Table(table)
+		if !ok {
+			continue // Skip tables that were excluded from inspection.
+		}
+		c, ok := t.Column(column)
+		if !ok {
+			return fmt.Errorf("postgres: column %q was not found in table %q", column, table)
+		}
+		c.Attrs = append(c.Attrs, &ColumnGrant{
+			Grantee:    grantee,
+			Privileges: strings.Split(privs, ","),
+			Grantable:  grantable,
+		})
+	}
+	return rows.Err()
+}
+
+// inspectTableGrants attaches the table-level privileges of the inspected tables to their tables.
+func (i *inspect) inspectTableGrants(ctx context.Context, r *schema.Realm) error {
+	args := make([]any, 0, len(r.Schemas))
+	for _, s := range r.Schemas {
+		if len(s.Tables) > 0 {
+			args = append(args, s.Name)
+		}
+	}
+	if len(args) == 0 || i.crdb {
+		return nil
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(tableGrantsQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying table privileges: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			ns, table, grantee, privs string
+			grantable                 bool
+		)
+		if err := rows.Scan(&ns, &table, &grantee, &privs, &grantable); err != nil {
+			return fmt.Errorf("postgres: scanning table privileges: %w", err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return fmt.Errorf("postgres: schema %q for table privileges was not found in inspection", ns)
+		}
+		t, ok := s.Table(table)
+		if !ok {
+			continue // Skip tables that were excluded from inspection.
+		}
+		t.Attrs = append(t.Attrs, &TableGrant{
+			Grantee:    grantee,
+			Privileges: strings.Split(privs, ","),
+			Grantable:  grantable,
+		})
+	}
+	return rows.Err()
+}
+
+// inspectSchemaGrants attaches the schema-level privileges of the inspected schemas to their schemas.
+func (i *inspect) inspectSchemaGrants(ctx context.Context, r *schema.Realm) error {
+	args := make([]any, 0, len(r.Schemas))
+	for _, s := range r.Schemas {
+		args = append(args, s.Name)
+	}
+	if len(args) == 0 || i.crdb {
+		return nil
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(schemaGrantsQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying schema privileges: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			ns, grantee, privs string
+			grantable          bool
+		)
+		if err := rows.Scan(&ns, &grantee, &privs, &grantable); err != nil {
+			return fmt.Errorf("postgres: scanning schema privileges: %w", err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return fmt.Errorf("postgres: schema %q for schema privileges was not found in inspection", ns)
+		}
+		s.Attrs = append(s.Attrs, &SchemaGrant{
+			Grantee:    grantee,
+			Privileges: strings.Split(privs, ","),
+			Grantable:  grantable,
+		})
+	}
+	return rows.Err()
+}
+
+// inspectMaterialized adds the materialized views of the inspected schemas to their objects.
+func (i *inspect) inspectMaterialized(ctx context.Context, r *schema.Realm) error {
+	args := make([]any, 0, len(r.Schemas))
+	for _, s := range r.Schemas {
+		args = append(args, s.Name)
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(matViewsQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying materialized views: %w", err)
+	}
+	views := make(map[string]*schema.View)
+	if err := func() error {
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				ns, name, def string
+				populated     bool
+				opts, comment sql.NullString
+			)
+			if err := rows.Scan(&ns, &name, &def, &populated, &opts, &comment); err != nil {
+				return fmt.Errorf("postgres: scanning materialized view: %w", err)
+			}
+			s, ok := r.Schema(ns)
+			if !ok {
+				return fmt.Errorf("postgres: schema %q for materialized view %q was not found in inspection", ns, name)
+			}
+			v := schema.NewMaterializedView(name, def)
+			v.SetSchema(s)
+			v.Attrs = append(v.Attrs, &WithData{V: populated})
+			if sqlx.ValidString(opts) {
+				v.Attrs = append(v.Attrs, newStorageParams(opts.String))
+			}
+			if sqlx.ValidString(comment) {
+				v.SetComment(comment.String)
+			}
+			s.Objects = append(s.Objects, v)
+			views[ns+"."+name] = v
+		}
+		return rows.Err()
+	}(); err != nil || len(views) == 0 {
+		return err
+	}
+	if err := i.matViewColumns(ctx, args, views); err != nil {
+		return err
+	}
+	return i.matViewIndexes(ctx, args, views)
+}
+
+// matViewColumns appends the columns of the given materialized views.
+func (i *inspect) matViewColumns(ctx context.Context, args []any, views map[string]*schema.View) error {
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(matViewColumnsQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying materialized view columns: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			ns, name, column, typ string
+			notnull               bool
+		)
+		if err := rows.Scan(&ns, &name, &column, &typ, &notnull); err != nil {
+			return fmt.Errorf("postgres: scanning materialized view column: %w", err)
+		}
+		v, ok := views[ns+"."+name]
+		if !ok {
+			continue
+		}
+		t, err := i.parseType(v.Schema, typ)
+		if err != nil {
+			return err
+		}
+		v.AddColumns(&schema.Column{Name: column, Type: &schema.ColumnType{Type: t, Raw: typ, Null: !notnull}})
+	}
+	return rows.Err()
+}
+
+// matViewIndexes appends the indexes of the given materialized views.
+func (i *inspect) matViewIndexes(ctx context.Context, args []any, views map[string]*schema.View) error {
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(matViewIndexesQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying materialized view indexes: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			ns, name, index, parts string
+			unique                 bool
+		)
+		if err := rows.Scan(&ns, &name, &index, &unique, &parts); err != nil {
+			return fmt.Errorf("postgres: scanning materialized view index: %w", err)
+		}
+		v, ok := views[ns+"."+name]
+		if !ok {
+			continue
+		}
+		var defs []string
+		if err := json.Unmarshal([]byte(parts), &defs); err != nil {
+			return fmt.Errorf("postgres: unmarshal index parts of %q: %w", index, err)
+		}
+		idx := &schema.Index{Name: index, Unique: unique, View: v}
+		for _, d := range defs {
+			part := &schema.IndexPart{SeqNo: len(idx.Parts) + 1}
+			if c, ok := v.Column(identName(d)); ok {
+				part.C = c
+			} else {
+				part.X = &schema.RawExpr{X: d}
+			}
+			idx.Parts = append(idx.Parts, part)
+		}
+		v.Indexes = append(v.Indexes, idx)
+	}
+	return rows.Err()
+}
+
+// inspectPartitions adds the child partitions of the inspected tables to the schema objects.
+// Partitions whose parent table was not inspected (e.g., filtered out) are skipped.
+func (i *inspect) inspectPartitions(ctx context.Context, r *schema.Realm) error {
+	args := make([]any, 0, len(r.Schemas))
+	for _, s := range r.Schemas {
+		args = append(args, s.Name)
+	}
+	if len(args) == 0 || i.crdb {
+		return nil
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(childPartitionsQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying partitions: %w", err)
+	}
+	var (
+		parts   = make(map[string]*TablePartition)
+		parents = make(map[*TablePartition][2]string)
+		ordered []*TablePartition
+	)
+	if err := func() error {
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				ns, name, pns, pname, bound string
+				partBy, comment             sql.NullString
+			)
+			if err := rows.Scan(&ns, &name, &pns, &pname, &bound, &partBy, &comment); err != nil {
+				return fmt.Errorf("postgres: scanning partition: %w", err)
+			}
+			s, ok := r.Schema(ns)
+			if !ok {
+				return fmt.Errorf("postgres: schema %q for partition %q was not found in inspection", ns, name)
+			}
+			p := &TablePartition{Name: name, Schema: s, Bound: bound, PartitionBy: partBy.String}
+			if sqlx.ValidString(comment) {
+				p.Attrs = append(p.Attrs, &schema.Comment{Text: comment.String})
+			}
+			parts[ns+"."+name], parents[p] = p, [2]string{pns, pname}
+			ordered = append(ordered, p)
+		}
+		return rows.Err()
+	}(); err != nil {
+		return err
+	}
+	// Parents are resolved after all partitions were scanned, as
+	// sub-partitions may be returned before their parent partitions.
+	for _, p := range ordered {
+		pns, pname := parents[p][0], parents[p][1]
+		if s, ok := r.Schema(pns); ok {
+			p.Table, _ = s.Table(pname)
+		}
+		if p.Table == nil {
+			p.Parent = parts[pns+"."+pname]
+		}
+	}
+	for _, p := range ordered {
+		if partitionRoot(p) != nil {
+			p.Schema.AddObjects(p)
+		}
+	}
+	return nil
+}
+
+// partitionRoot returns the root table of the given partition, or nil if it was not inspected.
+func partitionRoot(p *TablePartition) *schema.Table {
+	for ; p != nil; p = p.Parent {
+		if p.Table != nil {
+			return p.Table
+		}
+	}
+	return nil
+}
+
+// inspectTextSearch adds the text search dictionaries and configurations of the inspected schemas
+// to their objects. Objects that were created by extensions are skipped.
+func (i *inspect) inspectTextSearch(ctx context.Context, r *schema.Realm) error {
+	args := make([]any, 0, len(r.Schemas))
+	for _, s := range r.Schemas {
+		args = append(args, s.Name)
+	}
+	if len(args) == 0 || i.crdb {
+		return nil
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(tsDictsQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying text search dictionaries: %w", err)
+	}
+	if err := func() error {
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				ns, name, tmpl   string
+				options, comment sql.NullString
+			)
+			if err := rows.Scan(&ns, &name, &tmpl, &options, &comment); err != nil {
+				return fmt.Errorf("postgres: scanning text search dictionary: %w", err)
+			}
+			s, ok := r.Schema(ns)
+			if !ok {
+				return fmt.Errorf("postgres: schema %q for text search dictionary %q was not found in inspection", ns, name)
+			}
+			d := &TextSearchDict{Name: name, Schema: s, Template: tmpl}
+			if sqlx.ValidString(options) {
+				if d.Options, err = parseDictOptions(options.String); err != nil {
+					return fmt.Errorf("postgres: parsing options of text search dictionary %q: %w", name, err)
+				}
+			}
+			if sqlx.ValidString(comment) {
+				d.Attrs = append(d.Attrs, &schema.Comment{Text: comment.String})
+			}
+			s.AddObjects(d)
+		}
+		return rows.Err()
+	}(); err != nil {
+		return err
+	}
+	rows, err = i.QueryContext(ctx, fmt.Sprintf(tsConfigsQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying text search configurations: %w", err)
+	}
+	configs := make(map[string]*TextSearchConfig)
+	if err := func() error {
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				ns, name, parser string
+				comment          sql.NullString
+			)
+			if err := rows.Scan(&ns, &name, &parser, &comment); err != nil {
+				return fmt.Errorf("postgres: scanning text search configuration: %w", err)
+			}
+			s, ok := r.Schema(ns)
+			if !ok {
+				return fmt.Errorf("postgres: schema %q for text search configuration %q was not found in inspection", ns, name)
+			}
+			c := &TextSearchConfig{Name: name, Schema: s, Parser: parser, Mappings: make(map[string][]string)}
+			if sqlx.ValidString(comment) {
+				c.Attrs = append(c.Attrs, &schema.Comment{Text: comment.String})
+			}
+			s.AddObjects(c)
+			configs[ns+"."+name] = c
+		}
+		return rows.Err()
+	}(); err != nil || len(configs) == 0 {
+		return err
+	}
+	rows, err = i.QueryContext(ctx, fmt.Sprintf(tsMappingsQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying text search configuration mappings: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var ns, name, token, dict string
+		if err := rows.Scan(&ns, &name, &token, &dict); err != nil {
+			return fmt.Errorf("postgres: scanning text search configuration mapping: %w", err)
+		}
+		if c, ok := configs[ns+"."+name]; ok {
+			c.Mappings[token] = append(c.Mappings[token], dict)
+		}
+	}
+	return rows.Err()
+}
+
+// parseDictOptions parses the initialization options of a text search
+// dictionary. e.g., "language = 'english', stopwords = 'english'".
+func parseDictOptions(s string) (map[string]string, error) {
+	opts := make(map[string]string)
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimSpace(strings.TrimPrefix(s, ",")) {
+		k, rest, ok := strings.Cut(s, "=")
+		if !ok {
+			return nil, fmt.Errorf("missing value for option %q", s)
+		}
+		k, s = strings.Trim(strings.TrimSpace(k), `"`), strings.TrimSpace(rest)
+		if !strings.HasPrefix(s, "'") {
+			v, rest, _ := strings.Cut(s, ",")
+			opts[k], s = strings.TrimSpace(v), strings.TrimSpace(rest)
+			continue
+		}
+		// Quoted values escape single quotes by doubling them.
+		var b strings.Builder
+		for j := 1; ; j++ {
+			if j == len(s) {
+				return nil, fmt.Errorf("unterminated value for option %q", k)
+			}
+			if s[j] != '\'' {
+				b.WriteByte(s[j])
+				continue
+			}
+			if j+1 < len(s) && s[j+1] == '\'' {
+				b.WriteByte('\'')
+				j++
+				continue
+			}
+			opts[k], s = b.String(), strings.TrimSpace(s[j+1:])
+			break
+		}
+	}
+	return opts, nil
+}
+
+// inspectOperators adds the user-defined operators, operator families and operator classes of the
+// inspected schemas to their objects. Objects that were created by extensions are skipped.
+func (i *inspect) inspectOperators(ctx context.Context, r *schema.Realm) error {
+	args := make([]any, 0, len(r.Schemas))
+	for _, s := range r.Schemas {
+		args = append(args, s.Name)
+	}
+	if len(args) == 0 || i.crdb {
+		return nil
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(operatorsQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying operators: %w", err)
+	}
+	if err := func() error {
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				hashes, merges                          bool
+				ns, name, right, fn                     string
+				left, com, neg, restrict, join, comment sql.NullString
+			)
+			if err := rows.Scan(&ns, &name, &left, &right, &fn, &com, &neg, &restrict, &join, &hashes, &merges, &comment); err != nil {
+				return fmt.Errorf("postgres: scanning operator: %w", err)
+			}
+			s, ok := r.Schema(ns)
+			if !ok {
+				return fmt.Errorf("postgres: schema %q for operator %q was not found in inspection", ns, name)
+			}
+			o := &Operator{
+				Name:       name,
+				Schema:     s,
+				Left:       left.String,
+				Right:      right,
+				Function:   fn,
+				Commutator: com.String,
+				Negator:    neg.String,
+				Restrict:   restrict.String,
+				Join:       join.String,
+				Hashes:     hashes,
+				Merges:     merges,
+			}
+			if sqlx.ValidString(comment) {
+				o.Attrs = append(o.Attrs, &schema.Comment{Text: comment.String})
+			}
+			s.AddObjects(o)
+		}
+		return rows.Err()
+	}(); err != nil {
+		return err
+	}
+	rows, err = i.QueryContext(ctx, fmt.Sprintf(opFamiliesQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying operator families: %w", err)
+	}
+	if err := func() error {
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				ns, name, method string
+				comment          sql.NullString
+			)
+			if err := rows.Scan(&ns, &name, &method, &comment); err != nil {
+				return fmt.Errorf("postgres: scanning operator family: %w", err)
+			}
+			s, ok := r.Schema(ns)
+			if !ok {
+				return fmt.Errorf("postgres: schema %q for operator family %q was not found in inspection", ns, name)
+			}
+			f := &OpFamily{Name: name, Schema: s, Method: method}
+			if sqlx.ValidString(comment) {
+				f.Attrs = append(f.Attrs, &schema.Comment{Text: comment.String})
+			}
+			s.AddObjects(f)
+		}
+		return rows.Err()
+	}(); err != nil {
+		return err
+	}
+	rows, err = i.QueryContext(ctx, fmt.Sprintf(opClassesDefQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying operator classes: %w", err)
+	}
+	classes := make(map[string]*OpClass)
+	if err := func() error {
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				ns, name, method, typ    string
+				isDefault                bool
+				family, storage, comment sql.NullString
+			)
+			if err := rows.Scan(&ns, &name, &method, &typ, &isDefault, &family, &storage, &comment); err != nil {
+				return fmt.Errorf("postgres: scanning operator class: %w", err)
+			}
+			s, ok := r.Schema(ns)
+			if !ok {
+				return fmt.Errorf("postgres: schema %q for operator class %q was not found in inspection", ns, name)
+			}
+			c := &OpClass{Name: name, Schema: s, Method: method, Type: typ, Default: isDefault, Family: family.String, Storage: storage.String}
+			if sqlx.ValidString(comment) {
+				c.Attrs = append(c.Attrs, &schema.Comment{Text: comment.String})
+			}
+			s.AddObjects(c)
+			classes[ns+"."+name+"."+method] = c
+		}
+		return rows.Err()
+	}(); err != nil || len(classes) == 0 {
+		return err
+	}
+	rows, err = i.QueryContext(ctx, fmt.Sprintf(opClassMembersQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying operator class members: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			ns, name, method, kind, member string
+			number                         int
+			orderBy                        sql.NullString
+		)
+		if err := rows.Scan(&ns, &name, &method, &kind, &number, &member, &orderBy); err != nil {
+			return fmt.Errorf("postgres: scanning operator class member: %w", err)
+		}
+		c, ok := classes[ns+"."+name+"."+method]
+		switch {
+		case !ok:
+		case kind == "operator":
+			c.Operators = append(c.Operators, &OpClassOperator{Strategy: number, Name: member, OrderBy: orderBy.String})
+		default:
+			c.Funcs = append(c.Funcs, &OpClassFunc{Number: number, Name: member})
+		}
+	}
+	return rows.Err()
+}
+
+// inspectForeignData adds the foreign servers and user mappings of the current database to the
+// realm, and the foreign tables of the inspected schemas to their objects. In schema scope, the
+// servers are not added to the realm, but they are still referenced by the foreign tables.
+func (i *inspect) inspectForeignData(ctx context.Context, r *schema.Realm) error {
+	if i.crdb {
+		return nil
+	}
+	list, err := i.foreignServers(ctx)
+	if err != nil {
+		return err
+	}
+	servers := make(map[string]*ForeignServer, len(list))
+	for _, srv := range list {
+		r.AddObjects(srv)
+		servers[srv.Name] = srv
+	}
+	if err := i.userMappings(ctx, r, servers); err != nil {
+		return err
+	}
+	args := make([]any, 0, len(r.Schemas))
+	for _, s := range r.Schemas {
+		args = append(args, s.Name)
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(foreignTablesQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying foreign tables: %w", err)
+	}
+	tables := make(map[string]*ForeignTable)
+	if err := func() error {
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				ns, name, server, opts string
+				comment                sql.NullString
+			)
+			if err := rows.Scan(&ns, &name, &server, &opts, &comment); err != nil {
+				return fmt.Errorf("postgres: scanning foreign table: %w", err)
+			}
+			s, ok := r.Schema(ns)
+			if !ok {
+				return fmt.Errorf("postgres: schema %q for foreign table %q was not found in inspection", ns, name)
+			}
+			srv, ok := servers[server]
+			if !ok {
+				return fmt.Errorf("postgres: server %q for foreign table %q was not found in inspection", server, name)
+			}
+			t := &ForeignTable{Name: name, Schema: s, Server: srv}
+			if err := json.Unmarshal([]byte(opts), &t.Options); err != nil {
+				return fmt.Errorf("postgres: parsing options of foreign table %q: %w", name, err)
+			}
+			if sqlx.ValidString(comment) {
+				t.Attrs = append(t.Attrs, &schema.Comment{Text: comment.String})
+			}
+			s.AddObjects(t)
+			tables[ns+"."+name] = t
+		}
+		return rows.Err()
+	}(); err != nil || len(tables) == 0 {
+		return err
+	}
+	return i.foreignColumns(ctx, args, tables)
+}
+
+// foreignServers returns the foreign servers of the current database.
+func (i *inspect) foreignServers(ctx context.Context) ([]*ForeignServer, error) {
+	rows, err := i.QueryContext(ctx, foreignServersQuery)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: querying foreign servers: %w", err)
+	}
+	defer rows.Close()
+	var servers []*ForeignServer
+	for rows.Next() {
+		var (
+			srv               = &ForeignServer{}
+			opts              string
+			typ, ver, comment sql.NullString
+		)
+		if err := rows.Scan(&srv.Name, &srv.Wrapper, &typ, &ver, &opts, &comment); err != nil {
+			return nil, fmt.Errorf("postgres: scanning foreign server: %w", err)
+		}
+		srv.Type, srv.Version = typ.String, ver.String
+		if err := json.Unmarshal([]byte(opts), &srv.Options); err != nil {
+			return nil, fmt.Errorf("postgres: parsing options of foreign server %q: %w", srv.Name, err)
+		}
+		if sqlx.ValidString(comment) {
+			srv.Attrs = append(srv.Attrs, &schema.Comment{Text: comment.String})
+		}
+		servers = append(servers, srv)
+	}
+	return servers, rows.Err()
+}
+
+// userMappings adds the user mappings of the given foreign servers to the realm.
+func (i *inspect) userMappings(ctx context.Context, r *schema.Realm, servers map[string]*ForeignServer) error {
+	rows, err := i.QueryContext(ctx, userMappingsQuery)
+	if err != nil {
+		return fmt.Errorf("postgres: querying user mappings: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			u            = &UserMapping{}
+			server, opts string
+		)
+		if err := rows.Scan(&server, &u.User, &opts); err != nil {
+			return fmt.Errorf("postgres: scanning user mapping: %w", err)
+		}
+		if u.Server = servers[server]; u.Server == nil {
+			continue
+		}
+		if err := json.Unmarshal([]byte(opts), &u.Options); err != nil {
+			return fmt.Errorf("postgres: parsing options of user mapping %q for server %q: %w", u.User, server, err)
+		}
+		r.AddObjects(u)
+	}
+	return rows.Err()
+}
+
+// foreignColumns appends the columns of the given foreign tables.
+func (i *inspect) foreignColumns(ctx context.Context, args []any, tables map[string]*ForeignTable) error {
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(foreignColumnsQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying foreign table columns: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			ns, name, column, typ string
+			notnull               bool
+		)
+		if err := rows.Scan(&ns, &name, &column, &typ, &notnull); err != nil {
+			return fmt.Errorf("postgres: scanning foreign table column: %w", err)
+		}
+		t, ok := tables[ns+"."+name]
+		if !ok {
+			continue
+		}
+		ct, err := i.parseType(t.Schema, typ)
+		if err != nil {
+			return err
+		}
+		t.Columns = append(t.Columns, &schema.Column{Name: column, Type: &schema.ColumnType{Type: ct, Raw: typ, Null: !notnull}})
+	}
+	return rows.Err()
+}
+
+// newStorageParams parses and returns the storage parameters of a relation.
+// Parameters with the given names (e.g., ones that have a dedicated attribute)
+// are skipped.
+func newStorageParams(opts string, skip ...string) *StorageParams {
+	params := &StorageParams{}
+	for _, p := range strings.Split(strings.Trim(opts, "{}"), ",") {
+		if k, v, ok := strings.Cut(p, "="); ok && !slices.Contains(skip, k) {
+			params.V = append(params.V, StorageParam{Name: k, Value: v})
+		}
+	}
+	return params
+}
+
+// param returns the value of the storage parameter with the given name, if exists.
+func (p *StorageParams) param(name string) (string, bool) {
+	for _, sp := range p.V {
+		if sp.Name == name {
+			return sp.Value, true
+		}
+	}
+	return "", false
+}
+
+// tableStorageParams returns the storage parameters of a table from its reloptions
+// and the reloptions of its TOAST table. TOAST parameters are prefixed with "toast.",
+// similar to the way they are set with the WITH clause.
+func tableStorageParams(opts, toast []string) *StorageParams {
+	params := &StorageParams{}
+	for _, p := range opts {
+		if k, v, ok := strings.Cut(p, "="); ok {
+			params.V = append(params.V, StorageParam{Name: k, Value: v})
+		}
+	}
+	for _, p := range toast {
+		if k, v, ok := strings.Cut(p, "="); ok {
+			params.V = append(params.V, StorageParam{Name: storageParamToast + k, Value: v})
+		}
+	}
+	return params
+}
+
+// inspectSettings attaches the allowlisted server parameters to the realm.
+func (i *inspect) inspectSettings(ctx context.Context, r *schema.Realm) error {
+	if len(InspectedSettings) == 0 {
+		return nil
+	}
+	args := make([]any, len(InspectedSettings))
+	for j, n := range InspectedSettings {
+		args[j] = n
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(settingsQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying server settings: %w", err)
+	}
+	defer rows.Close()
+	set := &Settings{}
+	for rows.Next() {
+		var (
+			s      Setting
+			source sql.NullString
+		)
+		if err := rows.Scan(&s.Name, &s.Value, &source); err != nil {
+			return fmt.Errorf("postgres: scanning server setting: %w", err)
+		}
+		s.Source = source.String
+		set.V = append(set.V, s)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	schema.ReplaceOrAppend(&r.Attrs, set)
+	return nil
+}
+
+// Drift returns the names of the parameters whose values differ between the two
+// settings, or that exist only in one of them. The sources of values are ignored.
+func (s *Settings) Drift(other *Settings) []string {
+	var (
+		names  []string
+		values = make(map[string]string, len(s.V))
+	)
+	for _, v := range s.V {
+		values[v.Name] = v.Value
+	}
+	for _, v := range other.V {
+		if v1, ok := values[v.Name]; !ok || v1 != v.Value {
+			names = append(names, v.Name)
+		}
+		delete(values, v.Name)
+	}
+	for _, v := range s.V {
+		if _, ok := values[v.Name]; ok {
+			names = append(names, v.Name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// inspectEnums inspects the enum types of the given realm, and their comments.
+func (i *inspect) inspectEnums(ctx context.Context, r *schema.Realm) error {
+	var (
+		ids  = make(map[int64]*schema.EnumType)
+		args = make([]any, 0, len(r.Schemas))
+	)
+	for _, s := range r.Schemas {
+		args = append(args, s.Name)
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(enumsQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying enum values: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			id         int64
+			ns, n      string
+			v, comment sql.NullString
+		)
+		if err := rows.Scan(&ns, &id, &n, &v, &comment); err != nil {
+			return fmt.Errorf("postgres: scanning enum label: %w", err)
+		}
+		e, ok := ids[id]
+		if !ok {
+			e = &schema.EnumType{T: n}
+			if sqlx.ValidString(comment) {
+				e.Attrs = append(e.Attrs, &schema.Comment{Text: comment.String})
+			}
+			ids[id] = e
+		}
+		if e.Schema == nil {
+			s, ok := r.Schema(ns)
+			if !ok {
+				return fmt.Errorf("postgres: schema %q for enum %q was not found in inspection", ns, e.T)
+			}
+			e.Schema = s
+			s.Objects = append(s.Objects, e)
+		}
+		// Enum types without values are returned
+		// with a single row and a NULL label.
+		if v.Valid {
+			e.Values = append(e.Values, v.String)
+		}
+	}
+	return nil
+}
+
+// indexes queries and appends the indexes of the given table.
+func (i *inspect) indexes(ctx context.Context, s *schema.Schema) error {
+	if i.crdb {
+		return i.crdbIndexes(ctx, s)
+	}
+	rows, err := i.querySchema(ctx, i.indexesQuery(), s)
+	if err != nil {
+		return fmt.Errorf("postgres: querying schema %q indexes: %w", s.Name, err)
+	}
+	defer rows.Close()
+	if err := i.addIndexes(s, rows, queryScope{
+		hasT: func(tv string) bool {
+			_, ok := s.Table(tv)
+			return ok
+		},
+		setPK: func(tv string, idx *schema.Index) error {
+			if t, ok := s.Table(tv); ok {
+				t.SetPrimaryKey(idx)
+				return nil
+			}
+			return fmt.Errorf("postgres: table %q for primary key was not found in schema", tv)
+		},
+		addIndex: func(tv string, idx *schema.Index) error {
+			if t, ok := s.Table(tv); ok {
+				t.AddIndexes(idx)
+				return nil
+			}
+			return fmt.Errorf("postgres: table %q for index was not found in schema", tv)
+		},
+		column: func(tv, name string) (*schema.Column, bool) {
+			if t, ok := s.Table(tv); ok {
+				return t.Column(name)
+			}
+			return nil, false
+		},
+		addDep: func(tv string, o schema.Object) {
+			if t, ok := s.Table(tv); ok && !slices.ContainsFunc(t.Deps, func(d schema.Object) bool {
+				d1, ok := d.(*OpClassDep)
+				return ok && *d1 == *o.(*OpClassDep)
+			}) {
+				t.Deps = append(t.Deps, o)
+			}
+		},
+	}); err != nil {
+		return err
+	}
+	return rows.Err()
+}
+
+func (i *inspect) indexesQuery() (q string) {
+	switch {
+	case i.supportsIndexNullsDistinct():
+		q = indexesAbove15
+	case i.supportsIndexInclude():
+		q = indexesAbove11
+	default:
+		q = indexesBelow11
+	}
+	return
+}
+
+type queryScope struct {
+	hasT     func(tv string) bool
+	setPK    func(tv string, idx *schema.Index) error
+	addIndex func(tv string, idx *schema.Index) error
+	column   func(tv, name string) (*schema.Column, bool)
+	addDep   func(tv string, o schema.Object)
+}
+
+// addIndexes scans the rows and adds the indexes to the table.
+func (i *inspect) addIndexes(s *schema.Schema, rows *sql.Rows, scope queryScope) error {
+	names := make(map[string]*schema.Index)
+	for rows.Next() {
+		var (
+			table, name, typ                                                                         string
+			uniq, primary, included, nullsnotdistinct                                                bool
+			desc, nullsfirst, nullslast, opcdefault                                                  sql.NullBool
+			column, constraints, pred, expr, comment, options, opcname, opcschema, opcparams, exoper sql.NullString
+			opcext, deferrable, tablespace                                                           sql.NullString
+		)
+		if err := rows.Scan(
+			&table, &name, &typ, &column, &included, &primary, &uniq, &exoper, &constraints, &pred, &expr, &desc,
+			&nullsfirst, &nullslast, &comment, &options, &opcname, &opcschema, &opcdefault, &opcparams, &nullsnotdistinct,
+			&opcext, &deferrable, &tablespace,
+		); err != nil {
+			return fmt.Errorf("postgres: scanning indexes for schema %q: %w", s.Name, err)
+		}
+		if !scope.hasT(table) {
+			return fmt.Errorf("table %q was not found in schema", table)
+		}
+		idx, ok := names[name]
+		if !ok {
+			idx = &schema.Index{
+				Name:   name,
+				Unique: uniq,
+				Attrs: []schema.Attr{
+					&IndexType{T: typ},
+				},
+			}
+			if sqlx.ValidString(comment) {
+				idx.Attrs = append(idx.Attrs, &schema.Comment{Text: comment.String})
+			}
+			if sqlx.ValidString(constraints) {
+				var m map[string]string
+				if err := json.Unmarshal([]byte(constraints.String), &m); err != nil {
+					return fmt.Errorf("postgres: unmarshaling index constraints: %w", err)
 				}
-				for n, t := range m {
-					idx.AddAttrs(&Constraint{N: n, T: t})
+				// Constraints are added in a stable order.
+				for _, n := range sortedKeys(m) {
+					idx.AddAttrs(&Constraint{N: n, T: m[n]})
+				}
+			}
+			if sqlx.ValidString(deferrable) {
+				var m map[string]string
+				if err := json.Unmarshal([]byte(deferrable.String), &m); err != nil {
+					return fmt.Errorf("postgres: unmarshaling index constraints deferrability: %w", err)
+				}
+				// An index is backed by at most one constraint.
+				for _, v := range m {
+					idx.AddAttrs(&Deferrable{InitiallyDeferred: v == "deferred"})
 				}
 			}
 			if sqlx.ValidString(pred) {
@@ -582,10 +2615,17 @@ func (i *inspect) addIndexes(s *schema.Schema, rows *sql.Rows, scope queryScope)
 					return err
 				}
 				idx.AddAttrs(p)
+				// Other parameters, such as fillfactor, are kept as is.
+				if p := newStorageParams(options.String, storageParamAutoSum, storageParamPagesRange); len(p.V) > 0 {
+					idx.AddAttrs(p)
+				}
 			}
 			if nullsnotdistinct {
 				idx.AddAttrs(&IndexNullsDistinct{V: false})
 			}
+			if sqlx.ValidString(tablespace) {
+				idx.AddAttrs(&Tablespace{V: tablespace.String})
+			}
 			names[name] = idx
 			var err error
 			if primary {
@@ -635,6 +2675,11 @@ func (i *inspect) addIndexes(s *schema.Schema, rows *sql.Rows, scope queryScope)
 		if err := i.mayAppendOps(part, opcschema.String, opcname.String, opcparams.String, opcdefault.Bool); err != nil {
 			return err
 		}
+		// Record the dependency on operator classes that are defined outside
+		// the current scope, so it can be verified on planning.
+		if op := (IndexOpClass{}); sqlx.Has(part.Attrs, &op) && strings.Contains(op.Name, ".") && scope.addDep != nil {
+			scope.addDep(table, &OpClassDep{Name: op.Name, Extension: opcext.String})
+		}
 	}
 	return nil
 }
@@ -717,12 +2762,46 @@ func (i *inspect) partitions(s *schema.Schema) error {
 
 // fks queries and appends the foreign keys of the given table.
 func (i *inspect) fks(ctx context.Context, s *schema.Schema) error {
-	rows, err := i.querySchema(ctx, fksQuery, s)
+	query := fksQuery
+	// Column lists of SET NULL and SET DEFAULT actions were added in v15.
+	if !i.crdb && i.version >= 15_00_00 {
+		query = fksAbove15
+	}
+	rows, err := i.querySchema(ctx, query, s)
 	if err != nil {
 		return fmt.Errorf("postgres: querying schema %q foreign keys: %w", s.Name, err)
 	}
 	defer rows.Close()
-	if err := sqlx.TypedSchemaFKs[*ReferenceOption](s, rows); err != nil {
+	if err := sqlx.TypedSchemaFKs[*ReferenceOption](s, rows, &sqlx.FKAttrScanner{
+		Columns: func() []any {
+			return []any{new(bool), new(bool), new(bool), new(sql.NullString)}
+		},
+		ScanFunc: func(fk *schema.ForeignKey, columns []any) error {
+			if validated := *columns[0].(*bool); !validated {
+				schema.ReplaceOrAppend(&fk.Attrs, &NotValid{})
+			}
+			if deferrable := *columns[1].(*bool); deferrable {
+				schema.ReplaceOrAppend(&fk.Attrs, &Deferrable{InitiallyDeferred: *columns[2].(*bool)})
+			}
+			// The column list is repeated on each row of the foreign key.
+			if cols := columns[3].(*sql.NullString); cols.Valid && !sqlx.Has(fk.Attrs, &OnDeleteColumns{}) {
+				var names []string
+				if err := json.Unmarshal([]byte(cols.String), &names); err != nil {
+					return fmt.Errorf("parse ON DELETE columns of foreign key %q: %w", fk.Symbol, err)
+				}
+				d := &OnDeleteColumns{Columns: make([]*schema.Column, len(names))}
+				for j, n := range names {
+					c, ok := fk.Table.Column(n)
+					if !ok {
+						return fmt.Errorf("ON DELETE column %q was not found for foreign key %q", n, fk.Symbol)
+					}
+					d.Columns[j] = c
+				}
+				fk.AddAttrs(d)
+			}
+			return nil
+		},
+	}); err != nil {
 		return fmt.Errorf("postgres: %w", err)
 	}
 	return rows.Err()
@@ -777,6 +2856,119 @@ func (i *inspect) addChecks(s *schema.Schema, rows *sql.Rows) error {
 	return nil
 }
 
+// statistics queries and appends the extended statistics objects of the given tables.
+func (i *inspect) statistics(ctx context.Context, s *schema.Schema) error {
+	// CockroachDB does not support extended statistics objects.
+	if i.crdb {
+		return nil
+	}
+	rows, err := i.querySchema(ctx, statisticsQuery, s)
+	if err != nil {
+		return fmt.Errorf("postgres: querying schema %q statistics: %w", s.Name, err)
+	}
+	defer rows.Close()
+	if err := i.addStatistics(s, rows); err != nil {
+		return err
+	}
+	return rows.Err()
+}
+
+// addStatistics scans the rows and adds the statistics objects to the table.
+func (i *inspect) addStatistics(s *schema.Schema, rows *sql.Rows) error {
+	type tc struct{ t, n string }
+	names := make(map[tc]*Statistics)
+	for rows.Next() {
+		var table, name, kinds, column string
+		if err := rows.Scan(&table, &name, &kinds, &column); err != nil {
+			return fmt.Errorf("postgres: scanning statistics: %w", err)
+		}
+		t, ok := s.Table(table)
+		if !ok {
+			return fmt.Errorf("table %q was not found in schema", table)
+		}
+		c, ok := t.Column(column)
+		if !ok {
+			return fmt.Errorf("postgres: column %q was not found for statistics %q", column, name)
+		}
+		st, ok := names[tc{t: table, n: name}]
+		if !ok {
+			st = &Statistics{Name: name}
+			for _, k := range strings.Split(kinds, ",") {
+				if k, ok := statsKinds[k]; ok {
+					st.Kinds = append(st.Kinds, k)
+				}
+			}
+			// Omit the kinds, if all were defined.
+			if st.Kinds = StatsKinds(st.Kinds); len(st.Kinds) == 0 || !i.supportsStatsMCV() && slices.Equal(st.Kinds, []string{StatsDependencies, StatsNDistinct}) {
+				st.Kinds = nil
+			}
+			names[tc{t: table, n: name}] = st
+			t.AddAttrs(st)
+		}
+		st.Columns = append(st.Columns, c)
+	}
+	return nil
+}
+
+// rules queries and appends the rewrite rules of the given tables.
+func (i *inspect) rules(ctx context.Context, s *schema.Schema) error {
+	// CockroachDB does not support rewrite rules.
+	if i.crdb {
+		return nil
+	}
+	rows, err := i.querySchema(ctx, rulesQuery, s)
+	if err != nil {
+		return fmt.Errorf("postgres: querying schema %q rules: %w", s.Name, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var table, name, event, def string
+		var instead bool
+		if err := rows.Scan(&table, &name, &event, &instead, &def); err != nil {
+			return fmt.Errorf("postgres: scanning rules: %w", err)
+		}
+		t, ok := s.Table(table)
+		if !ok {
+			return fmt.Errorf("table %q was not found in schema", table)
+		}
+		r, err := ruleFromDef(name, def)
+		if err != nil {
+			return err
+		}
+		r.Event, r.Instead = ruleEvents[event], instead
+		t.AddAttrs(r)
+	}
+	return rows.Err()
+}
+
+// ruleDef matches the condition and the action of a rule definition, as returned
+// by pg_get_ruledef. e.g., "CREATE RULE r AS ON INSERT TO t WHERE (cond) DO INSTEAD NOTHING;".
+var ruleDef = regexp.MustCompile(`(?is)\sON\s+\w+\s+TO\s+\S+(?:\s+WHERE\s+(.+?))?\s+DO(?:\s+(?:INSTEAD|ALSO))?\s+(.+?);?\s*$`)
+
+// ruleFromDef builds the rule from its definition.
+func ruleFromDef(name, def string) (*Rule, error) {
+	matches := ruleDef.FindStringSubmatch(def)
+	if len(matches) != 3 {
+		return nil, fmt.Errorf("postgres: unexpected definition for rule %q: %q", name, def)
+	}
+	return &Rule{Name: name, Where: strings.TrimSpace(matches[1]), Action: strings.TrimSpace(matches[2])}, nil
+}
+
+// StatsKinds returns the sorted statistics kinds of an extended statistics
+// object. A nil slice is returned if all kinds were defined, as it is the
+// default when creating statistics without specifying their kinds.
+func StatsKinds(kinds []string) []string {
+	ks := make([]string, 0, len(kinds))
+	for _, k := range kinds {
+		ks = append(ks, strings.ToLower(k))
+	}
+	slices.Sort(ks)
+	if ks = slices.Compact(ks); len(ks) == 0 || slices.Equal(ks, []string{StatsDependencies, StatsMCV, StatsNDistinct}) {
+		return nil
+	}
+	return ks
+}
+
 // schemas returns the list of the schemas in the database.
 func (i *inspect) schemas(ctx context.Context, opts *schema.InspectRealmOption) ([]*schema.Schema, error) {
 	var (
@@ -866,6 +3058,121 @@ func columnDefault(c *schema.Column, s string) {
 	}
 }
 
+var (
+	// A regexp to match function calls in expressions. e.g., "public.f(...)" or "\"S\".\"F\"(...)".
+	reFuncCall = regexp.MustCompile(`(?:("[^"]+"|[\w$]+)\.)?("[^"]+"|[\w$]+)\s*\(`)
+	// A regexp to match sequence references in sequence functions. e.g., "nextval('s.seq'::regclass)".
+	reSeqCall = regexp.MustCompile(`(?i)\b(?:nextval|currval|setval)\(\s*'(?:("[^"]+"|[\w$]+)\.)?("[^"]+"|[\w$]+)'`)
+	// A regexp to match string literals.
+	reStrLit = regexp.MustCompile(`'(?:[^']|'')*'`)
+)
+
+// A defaultRef describes an object referenced by a default expression.
+type defaultRef struct {
+	schema, name string // Optional schema and the object name.
+	seq          bool   // Sequence or function.
+}
+
+// defaultRefs parses the given default expression and returns the
+// functions and sequences it references. Unquoted identifiers are
+// folded to lowercase, as done by PostgreSQL.
+func defaultRefs(x string) []defaultRef {
+	var refs []defaultRef
+	for _, m := range reSeqCall.FindAllStringSubmatch(x, -1) {
+		refs = append(refs, defaultRef{schema: identName(m[1]), name: identName(m[2]), seq: true})
+	}
+	for _, m := range reFuncCall.FindAllStringSubmatch(reStrLit.ReplaceAllString(x, "''"), -1) {
+		refs = append(refs, defaultRef{schema: identName(m[1]), name: identName(m[2])})
+	}
+	return refs
+}
+
+// identName returns the name of the given (possibly quoted) identifier.
+func identName(s string) string {
+	if sqlx.IsQuoted(s, '"') {
+		return strings.ReplaceAll(s[1:len(s)-1], `""`, `"`)
+	}
+	return strings.ToLower(s)
+}
+
+// defaultDeps resolves the functions and sequences referenced by the column
+// defaults of the inspected tables, and records them as the table dependencies.
+// Objects that are not part of the inspected realm are ignored.
+func defaultDeps(r *schema.Realm) {
+	for _, s := range r.Schemas {
+		for _, t := range s.Tables {
+			for _, c := range t.Columns {
+				x, ok := c.Default.(*schema.RawExpr)
+				if !ok {
+					continue
+				}
+				for _, ref := range defaultRefs(x.X) {
+					rs := s
+					if ref.schema != "" {
+						if rs, ok = r.Schema(ref.schema); !ok {
+							continue
+						}
+					}
+					switch o := defaultObject(rs, ref).(type) {
+					case *schema.Func:
+						if !slices.Contains(t.Deps, schema.Object(o)) {
+							t.Deps = append(t.Deps, o)
+							o.Refs = append(o.Refs, t)
+						}
+					case *Sequence:
+						if !slices.Contains(t.Deps, schema.Object(o)) {
+							t.Deps = append(t.Deps, o)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// triggerDeps resolves the functions executed by the triggers of the realm tables,
+// and records them as the trigger dependencies. e.g., "public.audit()".
+func triggerDeps(r *schema.Realm) {
+	for _, s := range r.Schemas {
+		for _, t := range s.Tables {
+			for _, tg := range t.Triggers {
+				m := reFuncCall.FindStringSubmatch(tg.Body)
+				if m == nil {
+					continue
+				}
+				rs, ok := s, true
+				if ns := identName(m[1]); ns != "" {
+					if rs, ok = r.Schema(ns); !ok {
+						continue
+					}
+				}
+				if f, ok := rs.Func(identName(m[2])); ok && !slices.Contains(tg.Deps, schema.Object(f)) {
+					tg.Deps = append(tg.Deps, f)
+					f.Refs = append(f.Refs, tg)
+				}
+			}
+		}
+	}
+}
+
+// defaultObject returns the object in the schema that matches the reference, if exists.
+func defaultObject(s *schema.Schema, ref defaultRef) schema.Object {
+	if !ref.seq {
+		if f, ok := s.Func(ref.name); ok {
+			return f
+		}
+		return nil
+	}
+	o, ok := s.Object(func(o schema.Object) bool {
+		seq, ok := o.(*Sequence)
+		return ok && seq.Name == ref.name
+	})
+	if !ok {
+		return nil
+	}
+	return o
+}
+
 func defaultExpr(t schema.Type, s string) schema.Expr {
 	switch {
 	case sqlx.IsLiteralBool(s), sqlx.IsLiteralNumber(s), sqlx.IsQuoted(s, '\''):
@@ -932,6 +3239,45 @@ type (
 		V int64
 	}
 
+	// Inherits describes the parent tables of a table that uses (classic) table
+	// inheritance, in their inheritance order. Note, unlike declarative partitioning
+	// (see TablePartition), children hold both their own and their inherited columns.
+	// https://www.postgresql.org/docs/current/ddl-inherit.html
+	Inherits struct {
+		schema.Attr
+		T []*schema.Table
+	}
+
+	// AccessMethod describes the table access method (e.g., heap or columnar).
+	// https://www.postgresql.org/docs/current/tableam.html
+	AccessMethod struct {
+		schema.Attr
+		V string
+	}
+
+	// Tablespace describes the tablespace a table or an index is stored in.
+	// Relations without a Tablespace use the default tablespace of the database.
+	// https://www.postgresql.org/docs/current/manage-ag-tablespaces.html
+	Tablespace struct {
+		schema.Attr
+		V string
+	}
+
+	// Compression describes the compression method of a column (e.g., pglz or lz4).
+	// Columns without a Compression use the default_toast_compression setting.
+	// https://www.postgresql.org/docs/current/sql-createtable.html#SQL-CREATETABLE-PARMS-COMPRESSION
+	Compression struct {
+		schema.Attr
+		V string
+	}
+
+	// Unlogged marks a table as unlogged. Its data is not written to the write-ahead
+	// log, and therefore, it is not replicated to standby servers and is truncated
+	// after a crash. https://www.postgresql.org/docs/current/sql-createtable.html
+	Unlogged struct {
+		schema.Attr
+	}
+
 	// ArrayType defines an array type.
 	// https://postgresql.org/docs/current/arrays.html
 	ArrayType struct {
@@ -970,7 +3316,54 @@ type (
 		Schema *schema.Schema   // Optional schema.
 		Fields []*schema.Column // Type fields, also known as attributes/columns.
 		Attrs  []schema.Attr    // Extra attributes, such as OID.
-		Deps   []schema.Object  // Objects this domain depends on.
+		Deps   []schema.Object  // Objects this composite type depends on.
+	}
+
+	// UserRangeType defines a user-defined range type. Unlike RangeType, which
+	// holds the built-in range types, it is a schema object that can be created,
+	// modified and dropped.
+	// https://www.postgresql.org/docs/current/rangetypes.html#RANGETYPES-DEFINING
+	UserRangeType struct {
+		schema.Type
+		schema.Object
+		T           string          // Type name.
+		Schema      *schema.Schema  // Optional schema.
+		Subtype     schema.Type     // Type of the range elements, e.g., float8.
+		Collation   string          // Optional collation of the subtype.
+		Canonical   string          // Optional canonical function, e.g., "public.canon".
+		SubtypeDiff string          // Optional subtype difference function, e.g., "float8mi".
+		Attrs       []schema.Attr   // Extra attributes, such as comments.
+		Deps        []schema.Object // Objects this range type depends on.
+	}
+
+	// Aggregate defines a user-defined aggregate function.
+	// https://www.postgresql.org/docs/current/sql-createaggregate.html
+	Aggregate struct {
+		schema.Object
+		Name      string            // Aggregate name.
+		Schema    *schema.Schema    // Optional schema.
+		Args      []*schema.FuncArg // Aggregated arguments. Empty for aggregates over rows, e.g., count(*).
+		StateType schema.Type       // Type of the state value (STYPE).
+		StateFunc string            // State transition function (SFUNC), qualified if not in pg_catalog.
+		FinalFunc string            // Optional final function (FINALFUNC), qualified if not in pg_catalog.
+		InitCond  *string           // Optional initial value of the state (INITCOND).
+		Parallel  string            // Optional parallel safety, SAFE or RESTRICTED. Defaults to UNSAFE.
+		Attrs     []schema.Attr     // Extra attributes, such as comments.
+		Deps      []schema.Object   // Objects this aggregate depends on.
+	}
+
+	// Collation defines a user-defined collation object.
+	// https://www.postgresql.org/docs/current/sql-createcollation.html
+	Collation struct {
+		schema.Object
+		Name          string         // Collation name.
+		Schema        *schema.Schema // Optional schema.
+		Provider      string         // Collation provider, e.g., libc, icu or builtin.
+		Locale        string         // Locale, used for both LC_COLLATE and LC_CTYPE.
+		LcCollate     string         // LC_COLLATE, if it differs from LC_CTYPE.
+		LcCtype       string         // LC_CTYPE, if it differs from LC_COLLATE.
+		Deterministic bool           // Whether the collation is deterministic (the default).
+		Attrs         []schema.Attr  // Extra attributes, such as comments.
 	}
 
 	// IntervalType defines an interval type.
@@ -1064,8 +3457,20 @@ type (
 		// Operator name. Might include the schema name if the schema
 		// is not managed by the current scope or extension based.
 		// e.g., "public.&&".
-		Name  string
-		Attrs []schema.Attr
+		Name string
+		// The definition below is set only for user-defined operators that are
+		// inspected as schema objects. Types and functions are qualified if they
+		// are not in pg_catalog, and the left type is empty for prefix operators.
+		Left, Right string
+		Function    string
+		// Optional commutator and negator operators, qualified if they
+		// are not defined in the schema of the operator or pg_catalog.
+		Commutator, Negator string
+		// Optional restriction and join selectivity estimators.
+		Restrict, Join string
+		// Whether the operator supports hash and merge joins.
+		Hashes, Merges bool
+		Attrs          []schema.Attr
 	}
 
 	// Sequence defines (the supported) sequence options.
@@ -1101,6 +3506,62 @@ type (
 		Sequence   *Sequence
 	}
 
+	// FuncVolatility describes the volatility category of a function. Functions
+	// without this attribute are VOLATILE (the default).
+	FuncVolatility struct {
+		schema.Attr
+		V string // IMMUTABLE, STABLE or VOLATILE.
+	}
+
+	// TriggerWhen describes the WHEN condition of a trigger.
+	TriggerWhen struct {
+		schema.Attr
+		X string
+	}
+
+	// TriggerState describes the firing state of a trigger that was changed by the
+	// ALTER TABLE ... ENABLE/DISABLE TRIGGER command. Triggers without this attribute
+	// are enabled and fire in the "origin" and "local" replication roles (the default).
+	TriggerState struct {
+		schema.Attr
+		V string // DISABLED, REPLICA or ALWAYS.
+	}
+
+	// ColumnGrant describes the privileges that were granted on a column
+	// to a role. A column may hold multiple grants, one per grantee.
+	// https://postgresql.org/docs/current/ddl-priv.html
+	ColumnGrant struct {
+		schema.Attr
+		Grantee    string   // Role name, or PUBLIC.
+		Privileges []string // SELECT, INSERT, UPDATE or REFERENCES.
+		Grantable  bool     // WITH GRANT OPTION.
+	}
+
+	// TableGrant describes the privileges that were granted on a table
+	// to a role. A table may hold multiple grants, one per grantee.
+	TableGrant struct {
+		schema.Attr
+		Grantee    string   // Role name, or PUBLIC.
+		Privileges []string // e.g., SELECT, INSERT, UPDATE, DELETE or TRUNCATE.
+		Grantable  bool     // WITH GRANT OPTION.
+	}
+
+	// SchemaGrant describes the privileges that were granted on a schema
+	// to a role. A schema may hold multiple grants, one per grantee.
+	SchemaGrant struct {
+		schema.Attr
+		Grantee    string   // Role name, or PUBLIC.
+		Privileges []string // USAGE or CREATE.
+		Grantable  bool     // WITH GRANT OPTION.
+	}
+
+	// SchemaOwner describes the role that owns a schema. It is used for creating
+	// schemas with the AUTHORIZATION clause, and it is not inspected or diffed.
+	SchemaOwner struct {
+		schema.Attr
+		Name string
+	}
+
 	// IndexType represents an index type.
 	// https://postgresql.org/docs/current/indexes-types.html
 	IndexType struct {
@@ -1153,6 +3614,15 @@ type (
 		Params  []struct{ N, V string } // Optional parameters.
 	}
 
+	// OpClassDep describes a dependency of a table on an operator class that is
+	// defined outside the inspected scope. For example, an operator class that is
+	// provided by an extension installed in another schema.
+	OpClassDep struct {
+		schema.Object
+		Name      string // Qualified name of the operator class.
+		Extension string // Extension providing the operator class, if any.
+	}
+
 	// IndexNullsDistinct describes the NULLS [NOT] DISTINCT clause.
 	IndexNullsDistinct struct {
 		schema.Attr
@@ -1167,45 +3637,313 @@ type (
 	}
 
 	// NotValid describes the NOT VALID clause for the creation
-	// of check and foreign-key constraints.
+	// of check and foreign-key constraints. As an attribute, it
+	// marks foreign keys that were not validated yet.
 	NotValid struct {
 		schema.Clause
+		schema.Attr
+	}
+
+	// Deferrable describes the DEFERRABLE clause of foreign-key, unique and
+	// exclude constraints. Constraints without it are NOT DEFERRABLE.
+	// https://www.postgresql.org/docs/current/sql-set-constraints.html
+	Deferrable struct {
+		schema.Attr
+		InitiallyDeferred bool
+	}
+
+	// OnDeleteColumns describes the column list of the ON DELETE SET NULL and
+	// SET DEFAULT actions of a foreign key, that limits the action to a subset
+	// of the referencing columns. Supported since PostgreSQL 15.
+	// https://www.postgresql.org/docs/current/sql-createtable.html
+	OnDeleteColumns struct {
+		schema.Attr
+		Columns []*schema.Column
+	}
+
+	// NoInherit attribute defines the NO INHERIT flag for CHECK constraint.
+	// https://postgresql.org/docs/current/catalog-pg-constraint.html
+	NoInherit struct {
+		schema.Attr
+	}
+
+	// CheckColumns attribute hold the column named used by the CHECK constraints.
+	// This attribute is added on inspection for internal usage and has no meaning
+	// on migration.
+	CheckColumns struct {
+		schema.Attr
+		Columns []string
+	}
+
+	// Partition defines the spec of a partitioned table.
+	Partition struct {
+		schema.Attr
+		// T defines the type/strategy of the partition.
+		// Can be one of: RANGE, LIST, HASH.
+		T string
+		// Partition parts. The additional attributes
+		// on each part can be used to control collation.
+		Parts []*PartitionPart
+
+		// Internal info returned from pg_partitioned_table.
+		start, attrs, exprs string
+	}
+
+	// An PartitionPart represents an index part that
+	// can be either an expression or a column.
+	PartitionPart struct {
+		X     schema.Expr
+		C     *schema.Column
+		Attrs []schema.Attr
+	}
+
+	// Statistics describes an extended statistics object defined on table columns.
+	// See: https://www.postgresql.org/docs/current/sql-createstatistics.html.
+	Statistics struct {
+		schema.Attr
+		Name string
+		// Kinds of the statistics. Can be one of: ndistinct, dependencies, mcv.
+		// Empty means all kinds that are supported by the database.
+		Kinds   []string
+		Columns []*schema.Column
+	}
+
+	// Rule describes a rewrite rule defined on a table.
+	// See: https://www.postgresql.org/docs/current/sql-createrule.html.
+	Rule struct {
+		schema.Attr
+		Name string
+		// Event of the rule. One of: SELECT, INSERT, UPDATE or DELETE.
+		Event string
+		// Instead reports if the action is executed instead of
+		// the original command (DO INSTEAD), or in addition to it.
+		Instead bool
+		// Where holds the optional condition of the rule.
+		Where string
+		// Action holds the commands of the rule, or NOTHING.
+		Action string
+	}
+
+	// Settings attribute holds the server parameters (e.g., TimeZone) that were
+	// inspected in InspectSettings mode, ordered by their names.
+	Settings struct {
+		schema.Attr
+		V []Setting
+	}
+
+	// StorageParams describes the storage parameters of a relation,
+	// which are set with the WITH clause. e.g., fillfactor=70. The
+	// parameters of the TOAST table of a table are prefixed with
+	// "toast.", e.g., toast.autovacuum_enabled=false.
+	StorageParams struct {
+		schema.Attr
+		V []StorageParam
+	}
+
+	// StorageParam describes a single storage parameter.
+	StorageParam struct {
+		Name, Value string
+	}
+
+	// WithData describes the population state of a materialized view. A view that
+	// was created (or refreshed) WITH NO DATA cannot be queried until it is refreshed.
+	WithData struct {
+		schema.Attr
+		V bool
+	}
+
+	// Setting describes a server parameter and its value.
+	Setting struct {
+		Name   string
+		Value  string
+		Source string // Source of the value. e.g., "default" or "configuration file".
+	}
+
+	// CronJob describes a job that is scheduled by the pg_cron extension in the
+	// current database. Jobs are realm objects and are identified by their names.
+	// See: https://github.com/citusdata/pg_cron.
+	CronJob struct {
+		schema.Object
+		Name     string // Unique name of the job.
+		Schedule string // Cron syntax or interval. e.g., "0 3 * * *" or "30 seconds".
+		Command  string // SQL command to execute.
+	}
+
+	// EventTrigger describes an event trigger of the current database. Event triggers
+	// are realm objects that fire on DDL events, and are identified by their names.
+	// See: https://www.postgresql.org/docs/current/sql-createeventtrigger.html.
+	EventTrigger struct {
+		schema.Object
+		Name  string        // Unique name of the trigger.
+		Event string        // Event that fires the trigger. e.g., "ddl_command_end".
+		Tags  []string      // Optional command tags filter. e.g., "CREATE TABLE".
+		Func  *schema.Func  // Function executed by the trigger.
+		Attrs []schema.Attr // Optional attributes. e.g., comment or TriggerState.
+	}
+
+	// ForeignServer describes a foreign server of the current database. Foreign
+	// servers are realm objects, and are identified by their names.
+	// See: https://www.postgresql.org/docs/current/sql-createserver.html.
+	ForeignServer struct {
+		schema.Object
+		Name    string            // Unique name of the server.
+		Wrapper string            // Foreign-data wrapper. e.g., "postgres_fdw".
+		Type    string            // Optional server type.
+		Version string            // Optional server version.
+		Options map[string]string // Wrapper-specific options. e.g., host and port.
+		Attrs   []schema.Attr     // Optional attributes. e.g., comment.
+	}
+
+	// Role describes a database role. Roles are realm objects that can be
+	// created and dropped by the planner, but are not inspected by the driver.
+	// See: https://www.postgresql.org/docs/current/sql-createrole.html.
+	Role struct {
+		schema.Object
+		Name  string
+		Login bool // Role is allowed to log in. i.e., a user.
+	}
+
+	// UserMapping describes the mapping of a user to a foreign server. User
+	// mappings are realm objects, and are identified by their user and server.
+	// See: https://www.postgresql.org/docs/current/sql-createusermapping.html.
+	UserMapping struct {
+		schema.Object
+		User    string            // Role name, or PUBLIC.
+		Server  *ForeignServer    // Server the user is mapped to.
+		Options map[string]string // Wrapper-specific options. e.g., user and password.
+	}
+
+	// ForeignTable describes a table whose data is stored on a foreign server.
+	// See: https://www.postgresql.org/docs/current/sql-createforeigntable.html.
+	ForeignTable struct {
+		schema.Object
+		Name    string
+		Schema  *schema.Schema
+		Columns []*schema.Column
+		Server  *ForeignServer    // Server that holds the data.
+		Options map[string]string // Wrapper-specific options. e.g., table_name.
+		Attrs   []schema.Attr     // Optional attributes. e.g., comment.
+	}
+
+	// Publication describes a logical replication publication of the current database.
+	// Publications are realm objects, and are identified by their names.
+	// See: https://www.postgresql.org/docs/current/sql-createpublication.html.
+	Publication struct {
+		schema.Object
+		Name      string              // Unique name of the publication.
+		AllTables bool                // FOR ALL TABLES.
+		Tables    []*PublicationTable // Published tables, if not all tables are published.
+		Schemas   []*schema.Schema    // Schemas whose tables are published (TABLES IN SCHEMA).
+		Publish   []string            // Published operations. nil means all operations.
+		Attrs     []schema.Attr       // Optional attributes. e.g., comment.
+	}
+
+	// PublicationTable describes a table that is published by a publication,
+	// with its optional column list and row filter.
+	PublicationTable struct {
+		Table   *schema.Table
+		Columns []*schema.Column // Published columns. nil means all columns.
+		Where   string           // Optional row filter. e.g., "active".
+	}
+
+	// TablePartition describes a child partition of a partitioned table. Note, the columns,
+	// constraints and partitioned indexes of a partition are inherited from its parent.
+	// See: https://www.postgresql.org/docs/current/ddl-partitioning.html.
+	TablePartition struct {
+		schema.Object
+		Name   string
+		Schema *schema.Schema
+		// Table is the parent table of the partition, or nil
+		// if the parent is another partition (Parent).
+		Table  *schema.Table
+		Parent *TablePartition
+		// Bound of the partition. e.g., "FOR VALUES FROM (1) TO (10)" or "DEFAULT".
+		Bound string
+		// PartitionBy holds the key of sub-partitioned partitions. e.g., "RANGE (id)".
+		PartitionBy string
+		Attrs       []schema.Attr // Optional attributes. e.g., comment.
+	}
+
+	// TextSearchDict describes a text search dictionary.
+	// See: https://www.postgresql.org/docs/current/sql-createtsdictionary.html.
+	TextSearchDict struct {
+		schema.Object
+		Name     string
+		Schema   *schema.Schema
+		Template string            // Template name, qualified if not in pg_catalog. e.g., "snowball".
+		Options  map[string]string // Template-specific options. e.g., language and stopwords.
+		Attrs    []schema.Attr     // Optional attributes. e.g., comment.
+	}
+
+	// TextSearchConfig describes a text search configuration.
+	// See: https://www.postgresql.org/docs/current/sql-createtsconfig.html.
+	TextSearchConfig struct {
+		schema.Object
+		Name   string
+		Schema *schema.Schema
+		Parser string // Parser name, qualified if not in pg_catalog. e.g., "default".
+		// Mappings hold the dictionaries of each token type (e.g., "asciiword"), in the
+		// order they are consulted. Dictionaries are qualified if not in pg_catalog.
+		Mappings map[string][]string
+		Attrs    []schema.Attr // Optional attributes. e.g., comment.
 	}
 
-	// NoInherit attribute defines the NO INHERIT flag for CHECK constraint.
-	// https://postgresql.org/docs/current/catalog-pg-constraint.html
-	NoInherit struct {
-		schema.Attr
+	// OpFamily describes a user-defined operator family. Families that were implicitly
+	// created for an operator class of the same name are not described separately.
+	// See: https://www.postgresql.org/docs/current/sql-createopfamily.html.
+	OpFamily struct {
+		schema.Object
+		Name   string
+		Schema *schema.Schema
+		Method string        // Index access method. e.g., "btree" or "gist".
+		Attrs  []schema.Attr // Optional attributes. e.g., comment.
 	}
 
-	// CheckColumns attribute hold the column named used by the CHECK constraints.
-	// This attribute is added on inspection for internal usage and has no meaning
-	// on migration.
-	CheckColumns struct {
-		schema.Attr
-		Columns []string
+	// OpClass describes a user-defined operator class.
+	// See: https://www.postgresql.org/docs/current/sql-createopclass.html.
+	OpClass struct {
+		schema.Object
+		Name    string
+		Schema  *schema.Schema
+		Method  string // Index access method. e.g., "btree" or "gist".
+		Type    string // Indexed data type, qualified if not in pg_catalog.
+		Default bool   // Whether it is the default operator class of its type.
+		// Family is the operator family of the class, qualified if not in the schema
+		// of the class. Empty, if it is the implicit family of the class.
+		Family    string
+		Storage   string // Stored data type, if it differs from Type.
+		Operators []*OpClassOperator
+		Funcs     []*OpClassFunc
+		Attrs     []schema.Attr // Optional attributes. e.g., comment.
 	}
 
-	// Partition defines the spec of a partitioned table.
-	Partition struct {
-		schema.Attr
-		// T defines the type/strategy of the partition.
-		// Can be one of: RANGE, LIST, HASH.
-		T string
-		// Partition parts. The additional attributes
-		// on each part can be used to control collation.
-		Parts []*PartitionPart
+	// OpClassOperator describes an operator of an operator class.
+	OpClassOperator struct {
+		Strategy int
+		// Operator with its operand types, qualified if not in pg_catalog.
+		// e.g., "public.<->(public.vec,public.vec)".
+		Name string
+		// Sort family of ordering operators (i.e., FOR ORDER BY).
+		OrderBy string
+	}
 
-		// Internal info returned from pg_partitioned_table.
-		start, attrs, exprs string
+	// OpClassFunc describes a support function of an operator class.
+	OpClassFunc struct {
+		Number int
+		// Function with its argument types, qualified if not in pg_catalog.
+		// e.g., "public.vec_distance(public.vec,public.vec)".
+		Name string
 	}
 
-	// An PartitionPart represents an index part that
-	// can be either an expression or a column.
-	PartitionPart struct {
-		X     schema.Expr
-		C     *schema.Column
-		Attrs []schema.Attr
+	// Extension describes an extension that is installed in the current database.
+	// Extensions are realm objects and are identified by their names.
+	// See: https://www.postgresql.org/docs/current/sql-createextension.html.
+	Extension struct {
+		schema.Object
+		Name    string        // Unique name of the extension. e.g., "ltree".
+		Schema  string        // Schema that holds the objects of the extension.
+		Version string        // Installed version. Empty means the default version.
+		Attrs   []schema.Attr // Optional attributes. e.g., comment.
 	}
 
 	// Cascade describes that a CASCADE clause should be added to the DROP [TABLE|SCHEMA]
@@ -1250,6 +3988,26 @@ func (c *CompositeType) SpecName() string {
 	return c.T
 }
 
+// SpecType returns the type of the range type.
+func (r *UserRangeType) SpecType() string {
+	return "range"
+}
+
+// SpecName returns the name of the range type.
+func (r *UserRangeType) SpecName() string {
+	return r.T
+}
+
+// SpecType returns the type of the table partition.
+func (p *TablePartition) SpecType() string {
+	return "table_partition"
+}
+
+// SpecName returns the name of the table partition.
+func (p *TablePartition) SpecName() string {
+	return p.Name
+}
+
 // Underlying returns the underlying type of the array.
 func (a *ArrayType) Underlying() schema.Type {
 	return a.Type
@@ -1503,7 +4261,741 @@ func parseFmtType(t string) (s, n string) {
 
 const (
 	// Query to list runtime parameters.
-	paramsQuery = `SELECT current_setting('server_version_num'), current_setting('default_table_access_method', true), current_setting('crdb_version', true)`
+	paramsQuery = `SELECT current_setting('server_version_num'), current_setting('default_table_access_method', true), current_setting('crdb_version', true), current_setting('server_encoding', true), (SELECT datcollate FROM pg_catalog.pg_database WHERE datname = current_database()), current_setting('TimeZone', true)`
+
+	// Query to list the allowlisted server parameters.
+	settingsQuery = `SELECT name, setting, source FROM pg_catalog.pg_settings WHERE name IN (%s) ORDER BY name`
+
+	// Query to list materialized views of the given schemas.
+	matViewsQuery = `
+SELECT
+	n.nspname,
+	c.relname,
+	pg_catalog.pg_get_viewdef(c.oid, true),
+	c.relispopulated,
+	c.reloptions,
+	d.description
+FROM
+	pg_catalog.pg_class AS c
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = c.relnamespace
+	LEFT JOIN pg_catalog.pg_description AS d ON d.objoid = c.oid AND d.classoid = 'pg_catalog.pg_class'::regclass AND d.objsubid = 0
+WHERE
+	c.relkind = 'm'
+	AND n.nspname IN (%s)
+ORDER BY
+	n.nspname, c.relname
+`
+
+	// Query to list the columns of materialized views of the given schemas.
+	matViewColumnsQuery = `
+SELECT
+	n.nspname,
+	c.relname,
+	a.attname,
+	pg_catalog.format_type(a.atttypid, a.atttypmod),
+	a.attnotnull
+FROM
+	pg_catalog.pg_attribute AS a
+	JOIN pg_catalog.pg_class AS c ON c.oid = a.attrelid
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = c.relnamespace
+WHERE
+	c.relkind = 'm'
+	AND a.attnum > 0
+	AND NOT a.attisdropped
+	AND n.nspname IN (%s)
+ORDER BY
+	n.nspname, c.relname, a.attnum
+`
+
+	// Query to list the indexes of materialized views of the given schemas.
+	matViewIndexesQuery = `
+SELECT
+	n.nspname,
+	t.relname,
+	i.relname,
+	idx.indisunique,
+	(SELECT json_agg(pg_catalog.pg_get_indexdef(idx.indexrelid, k + 1, true) ORDER BY k) FROM pg_catalog.generate_subscripts(idx.indkey, 1) AS k)
+FROM
+	pg_catalog.pg_index AS idx
+	JOIN pg_catalog.pg_class AS i ON i.oid = idx.indexrelid
+	JOIN pg_catalog.pg_class AS t ON t.oid = idx.indrelid
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = t.relnamespace
+WHERE
+	t.relkind = 'm'
+	AND n.nspname IN (%s)
+ORDER BY
+	n.nspname, t.relname, i.relname
+`
+
+	// Query to list the domain types of the given schemas, excluding the ones that were created by extensions.
+	domainsQuery = `
+SELECT
+	n.nspname,
+	t.typname,
+	pg_catalog.format_type(t.typbasetype, t.typtypmod),
+	t.typnotnull,
+	t.typdefault,
+	(SELECT json_agg(json_build_object('name', c.conname, 'def', pg_catalog.pg_get_constraintdef(c.oid, true)) ORDER BY c.conname) FROM pg_catalog.pg_constraint AS c WHERE c.contypid = t.oid AND c.contype = 'c'),
+	d.description
+FROM
+	pg_catalog.pg_type AS t
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = t.typnamespace
+	LEFT JOIN pg_catalog.pg_description AS d ON d.objoid = t.oid AND d.classoid = 'pg_catalog.pg_type'::regclass AND d.objsubid = 0
+	LEFT JOIN pg_catalog.pg_depend AS dep ON dep.classid = 'pg_catalog.pg_type'::regclass AND dep.objid = t.oid AND dep.deptype = 'e'
+WHERE
+	t.typtype = 'd'
+	AND n.nspname IN (%s)
+	AND dep.objid IS NULL
+ORDER BY
+	n.nspname, t.typname
+`
+
+	// Query to list the user-defined collations of the given schemas. The first and second
+	// arguments are the expressions for the deterministic property and the non-libc locale.
+	collationsQuery = `
+SELECT
+	n.nspname,
+	c.collname,
+	CASE c.collprovider WHEN 'i' THEN 'icu' WHEN 'b' THEN 'builtin' ELSE 'libc' END,
+	%s AS deterministic,
+	c.collcollate,
+	c.collctype,
+	%s AS locale,
+	d.description
+FROM
+	pg_catalog.pg_collation AS c
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = c.collnamespace
+	LEFT JOIN pg_catalog.pg_description AS d ON d.objoid = c.oid AND d.classoid = 'pg_catalog.pg_collation'::regclass AND d.objsubid = 0
+	LEFT JOIN pg_catalog.pg_depend AS dep ON dep.classid = 'pg_catalog.pg_collation'::regclass AND dep.objid = c.oid AND dep.deptype = 'e'
+WHERE
+	n.nspname IN (%s)
+	AND dep.objid IS NULL
+ORDER BY
+	n.nspname, c.collname
+`
+
+	// Query to list the child partitions of the given schemas, and their parents.
+	childPartitionsQuery = `
+SELECT
+	n.nspname,
+	c.relname,
+	pn.nspname AS parent_schema,
+	p.relname AS parent_name,
+	pg_catalog.pg_get_expr(c.relpartbound, c.oid) AS bound,
+	CASE WHEN c.relkind = 'p' THEN pg_catalog.pg_get_partkeydef(c.oid) END AS partition_by,
+	pg_catalog.obj_description(c.oid, 'pg_class') AS comment
+FROM
+	pg_catalog.pg_class AS c
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = c.relnamespace
+	JOIN pg_catalog.pg_inherits AS i ON i.inhrelid = c.oid
+	JOIN pg_catalog.pg_class AS p ON p.oid = i.inhparent
+	JOIN pg_catalog.pg_namespace AS pn ON pn.oid = p.relnamespace
+WHERE
+	c.relispartition
+	AND c.relkind IN ('r', 'p', 'f')
+	AND n.nspname IN (%s)
+ORDER BY
+	n.nspname, c.relname
+`
+
+	// Query to list the text search dictionaries of the given schemas.
+	// Templates in pg_catalog are returned unqualified.
+	tsDictsQuery = `
+SELECT
+	n.nspname,
+	d.dictname,
+	CASE WHEN tn.nspname = 'pg_catalog' THEN t.tmplname ELSE tn.nspname || '.' || t.tmplname END AS template,
+	d.dictinitoption,
+	pg_catalog.obj_description(d.oid, 'pg_ts_dict') AS comment
+FROM
+	pg_catalog.pg_ts_dict AS d
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = d.dictnamespace
+	JOIN pg_catalog.pg_ts_template AS t ON t.oid = d.dicttemplate
+	JOIN pg_catalog.pg_namespace AS tn ON tn.oid = t.tmplnamespace
+	LEFT JOIN pg_catalog.pg_depend AS dep ON dep.classid = 'pg_catalog.pg_ts_dict'::regclass AND dep.objid = d.oid AND dep.deptype = 'e'
+WHERE
+	n.nspname IN (%s)
+	AND dep.objid IS NULL
+ORDER BY
+	n.nspname, d.dictname
+`
+
+	// Query to list the text search configurations of the given schemas.
+	// Parsers in pg_catalog are returned unqualified.
+	tsConfigsQuery = `
+SELECT
+	n.nspname,
+	c.cfgname,
+	CASE WHEN pn.nspname = 'pg_catalog' THEN p.prsname ELSE pn.nspname || '.' || p.prsname END AS parser,
+	pg_catalog.obj_description(c.oid, 'pg_ts_config') AS comment
+FROM
+	pg_catalog.pg_ts_config AS c
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = c.cfgnamespace
+	JOIN pg_catalog.pg_ts_parser AS p ON p.oid = c.cfgparser
+	JOIN pg_catalog.pg_namespace AS pn ON pn.oid = p.prsnamespace
+	LEFT JOIN pg_catalog.pg_depend AS dep ON dep.classid = 'pg_catalog.pg_ts_config'::regclass AND dep.objid = c.oid AND dep.deptype = 'e'
+WHERE
+	n.nspname IN (%s)
+	AND dep.objid IS NULL
+ORDER BY
+	n.nspname, c.cfgname
+`
+
+	// Query to list the token mappings of the text search configurations of the given
+	// schemas, in the order the dictionaries are consulted. Dictionaries in pg_catalog
+	// are returned unqualified.
+	tsMappingsQuery = `
+SELECT
+	n.nspname,
+	c.cfgname,
+	t.alias,
+	CASE WHEN dn.nspname = 'pg_catalog' THEN d.dictname ELSE dn.nspname || '.' || d.dictname END AS dictionary
+FROM
+	pg_catalog.pg_ts_config_map AS m
+	JOIN pg_catalog.pg_ts_config AS c ON c.oid = m.mapcfg
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = c.cfgnamespace
+	JOIN pg_catalog.pg_ts_dict AS d ON d.oid = m.mapdict
+	JOIN pg_catalog.pg_namespace AS dn ON dn.oid = d.dictnamespace
+	JOIN pg_catalog.ts_token_type(c.cfgparser) AS t ON t.tokid = m.maptokentype
+WHERE
+	n.nspname IN (%s)
+ORDER BY
+	n.nspname, c.cfgname, t.alias, m.mapseqno
+`
+
+	// Query to list the user-defined operators of the given schemas. Types and
+	// functions in pg_catalog are returned unqualified, as the search_path is empty.
+	operatorsQuery = `
+SELECT
+	n.nspname,
+	o.oprname,
+	CASE WHEN o.oprleft = 0 THEN NULL ELSE pg_catalog.format_type(o.oprleft, NULL) END AS left_type,
+	pg_catalog.format_type(o.oprright, NULL) AS right_type,
+	o.oprcode::text AS function,
+	(SELECT CASE WHEN cn.nspname IN (n.nspname, 'pg_catalog') THEN c.oprname ELSE cn.nspname || '.' || c.oprname END FROM pg_catalog.pg_operator AS c JOIN pg_catalog.pg_namespace AS cn ON cn.oid = c.oprnamespace WHERE c.oid = o.oprcom) AS commutator,
+	(SELECT CASE WHEN cn.nspname IN (n.nspname, 'pg_catalog') THEN c.oprname ELSE cn.nspname || '.' || c.oprname END FROM pg_catalog.pg_operator AS c JOIN pg_catalog.pg_namespace AS cn ON cn.oid = c.oprnamespace WHERE c.oid = o.oprnegate) AS negator,
+	CASE WHEN o.oprrest = 0 THEN NULL ELSE o.oprrest::text END AS restrict,
+	CASE WHEN o.oprjoin = 0 THEN NULL ELSE o.oprjoin::text END AS join,
+	o.oprcanhash,
+	o.oprcanmerge,
+	pg_catalog.obj_description(o.oid, 'pg_operator') AS comment
+FROM
+	pg_catalog.pg_operator AS o
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = o.oprnamespace
+	LEFT JOIN pg_catalog.pg_depend AS dep ON dep.classid = 'pg_catalog.pg_operator'::regclass AND dep.objid = o.oid AND dep.deptype = 'e'
+WHERE
+	n.nspname IN (%s)
+	AND dep.objid IS NULL
+ORDER BY
+	n.nspname, o.oprname, o.oid
+`
+
+	// Query to list the operator families of the given schemas. Families that
+	// were implicitly created for an operator class of the same name are skipped.
+	opFamiliesQuery = `
+SELECT
+	n.nspname,
+	f.opfname,
+	am.amname,
+	pg_catalog.obj_description(f.oid, 'pg_opfamily') AS comment
+FROM
+	pg_catalog.pg_opfamily AS f
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = f.opfnamespace
+	JOIN pg_catalog.pg_am AS am ON am.oid = f.opfmethod
+	LEFT JOIN pg_catalog.pg_depend AS dep ON dep.classid = 'pg_catalog.pg_opfamily'::regclass AND dep.objid = f.oid AND dep.deptype = 'e'
+WHERE
+	n.nspname IN (%s)
+	AND dep.objid IS NULL
+	AND NOT EXISTS (SELECT 1 FROM pg_catalog.pg_opclass AS c WHERE c.opcfamily = f.oid AND c.opcname = f.opfname AND c.opcnamespace = f.opfnamespace)
+ORDER BY
+	n.nspname, f.opfname, am.amname
+`
+
+	// Query to list the operator classes of the given schemas. The family is
+	// returned only if it is not the implicit family of the class.
+	opClassesDefQuery = `
+SELECT
+	n.nspname,
+	c.opcname,
+	am.amname,
+	pg_catalog.format_type(c.opcintype, NULL) AS type,
+	c.opcdefault,
+	CASE
+		WHEN f.opfname = c.opcname AND f.opfnamespace = c.opcnamespace THEN NULL
+		WHEN f.opfnamespace = c.opcnamespace THEN f.opfname
+		ELSE fn.nspname || '.' || f.opfname
+	END AS family,
+	CASE WHEN c.opckeytype = 0 THEN NULL ELSE pg_catalog.format_type(c.opckeytype, NULL) END AS storage,
+	pg_catalog.obj_description(c.oid, 'pg_opclass') AS comment
+FROM
+	pg_catalog.pg_opclass AS c
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = c.opcnamespace
+	JOIN pg_catalog.pg_am AS am ON am.oid = c.opcmethod
+	JOIN pg_catalog.pg_opfamily AS f ON f.oid = c.opcfamily
+	JOIN pg_catalog.pg_namespace AS fn ON fn.oid = f.opfnamespace
+	LEFT JOIN pg_catalog.pg_depend AS dep ON dep.classid = 'pg_catalog.pg_opclass'::regclass AND dep.objid = c.oid AND dep.deptype = 'e'
+WHERE
+	n.nspname IN (%s)
+	AND dep.objid IS NULL
+ORDER BY
+	n.nspname, c.opcname, am.amname
+`
+
+	// Query to list the operators and support functions of the operator classes of the
+	// given schemas. Members that were added to their family separately are skipped.
+	opClassMembersQuery = `
+SELECT
+	n.nspname,
+	c.opcname,
+	am.amname,
+	'operator' AS kind,
+	ao.amopstrategy AS number,
+	ao.amopopr::pg_catalog.regoperator::text AS member,
+	(SELECT CASE WHEN sn.nspname = 'pg_catalog' THEN sf.opfname ELSE sn.nspname || '.' || sf.opfname END FROM pg_catalog.pg_opfamily AS sf JOIN pg_catalog.pg_namespace AS sn ON sn.oid = sf.opfnamespace WHERE sf.oid = ao.amopsortfamily) AS order_by
+FROM
+	pg_catalog.pg_amop AS ao
+	JOIN pg_catalog.pg_depend AS d ON d.classid = 'pg_catalog.pg_amop'::regclass AND d.objid = ao.oid AND d.refclassid = 'pg_catalog.pg_opclass'::regclass
+	JOIN pg_catalog.pg_opclass AS c ON c.oid = d.refobjid
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = c.opcnamespace
+	JOIN pg_catalog.pg_am AS am ON am.oid = c.opcmethod
+WHERE
+	n.nspname IN (%[1]s)
+UNION ALL
+SELECT
+	n.nspname,
+	c.opcname,
+	am.amname,
+	'function' AS kind,
+	ap.amprocnum AS number,
+	ap.amproc::pg_catalog.regprocedure::text AS member,
+	NULL AS order_by
+FROM
+	pg_catalog.pg_amproc AS ap
+	JOIN pg_catalog.pg_depend AS d ON d.classid = 'pg_catalog.pg_amproc'::regclass AND d.objid = ap.oid AND d.refclassid = 'pg_catalog.pg_opclass'::regclass
+	JOIN pg_catalog.pg_opclass AS c ON c.oid = d.refobjid
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = c.opcnamespace
+	JOIN pg_catalog.pg_am AS am ON am.oid = c.opcmethod
+WHERE
+	n.nspname IN (%[1]s)
+ORDER BY
+	1, 2, 3, 4, 5
+`
+
+	// Query to list the composite types of the given schemas. Row types of
+	// tables, views, etc. are excluded, as they are not standalone types.
+	compositesQuery = `
+SELECT
+	n.nspname,
+	t.typname,
+	COALESCE((
+		SELECT json_agg(json_build_object(
+			'name', a.attname,
+			'type', pg_catalog.format_type(a.atttypid, a.atttypmod),
+			'collation', CASE WHEN a.attcollation <> ft.typcollation THEN co.collname END
+		) ORDER BY a.attnum)
+		FROM pg_catalog.pg_attribute AS a
+		JOIN pg_catalog.pg_type AS ft ON ft.oid = a.atttypid
+		LEFT JOIN pg_catalog.pg_collation AS co ON co.oid = a.attcollation
+		WHERE a.attrelid = t.typrelid AND a.attnum > 0 AND NOT a.attisdropped
+	), '[]') AS fields,
+	d.description
+FROM
+	pg_catalog.pg_type AS t
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = t.typnamespace
+	JOIN pg_catalog.pg_class AS c ON c.oid = t.typrelid AND c.relkind = 'c'
+	LEFT JOIN pg_catalog.pg_description AS d ON d.objoid = t.oid AND d.classoid = 'pg_catalog.pg_type'::regclass AND d.objsubid = 0
+	LEFT JOIN pg_catalog.pg_depend AS dep ON dep.classid = 'pg_catalog.pg_type'::regclass AND dep.objid = t.oid AND dep.deptype = 'e'
+WHERE
+	t.typtype = 'c'
+	AND n.nspname IN (%s)
+	AND dep.objid IS NULL
+ORDER BY
+	n.nspname, t.typname
+`
+
+	// Query to list the range types of the given schemas. The subtype collation is
+	// returned only if it differs from the default collation of the subtype.
+	rangesQuery = `
+SELECT
+	n.nspname,
+	t.typname,
+	pg_catalog.format_type(r.rngsubtype, NULL) AS subtype,
+	CASE WHEN r.rngcollation <> st.typcollation THEN co.collname END AS collation,
+	CASE WHEN r.rngcanonical <> 0 THEN r.rngcanonical::text END AS canonical,
+	CASE WHEN r.rngsubdiff <> 0 THEN r.rngsubdiff::text END AS subtype_diff,
+	d.description
+FROM
+	pg_catalog.pg_type AS t
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = t.typnamespace
+	JOIN pg_catalog.pg_range AS r ON r.rngtypid = t.oid
+	JOIN pg_catalog.pg_type AS st ON st.oid = r.rngsubtype
+	LEFT JOIN pg_catalog.pg_collation AS co ON co.oid = r.rngcollation
+	LEFT JOIN pg_catalog.pg_description AS d ON d.objoid = t.oid AND d.classoid = 'pg_catalog.pg_type'::regclass AND d.objsubid = 0
+	LEFT JOIN pg_catalog.pg_depend AS dep ON dep.classid = 'pg_catalog.pg_type'::regclass AND dep.objid = t.oid AND dep.deptype = 'e'
+WHERE
+	t.typtype = 'r'
+	AND n.nspname IN (%s)
+	AND dep.objid IS NULL
+ORDER BY
+	n.nspname, t.typname
+`
+
+	// Query to list the (normal) aggregate functions of the given schemas.
+	aggregatesQuery = `
+SELECT
+	n.nspname,
+	p.proname,
+	COALESCE((
+		SELECT json_agg(json_build_object(
+			'name', COALESCE(p.proargnames[a.i], ''),
+			'mode', COALESCE(p.proargmodes[a.i], 'i'),
+			'type', pg_catalog.format_type(a.t, NULL)
+		) ORDER BY a.i)
+		FROM unnest(p.proargtypes::oid[]) WITH ORDINALITY AS a(t, i)
+	), '[]') AS args,
+	pg_catalog.format_type(g.aggtranstype, NULL) AS state_type,
+	g.aggtransfn::text AS state_func,
+	CASE WHEN g.aggfinalfn <> 0 THEN g.aggfinalfn::text END AS final_func,
+	g.agginitval,
+	p.proparallel,
+	d.description
+FROM
+	pg_catalog.pg_aggregate AS g
+	JOIN pg_catalog.pg_proc AS p ON p.oid = g.aggfnoid
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = p.pronamespace
+	LEFT JOIN pg_catalog.pg_description AS d ON d.objoid = p.oid AND d.classoid = 'pg_catalog.pg_proc'::regclass AND d.objsubid = 0
+	LEFT JOIN pg_catalog.pg_depend AS dep ON dep.classid = 'pg_catalog.pg_proc'::regclass AND dep.objid = p.oid AND dep.deptype = 'e'
+WHERE
+	n.nspname IN (%s)
+	AND g.aggkind = 'n'
+	AND dep.objid IS NULL
+ORDER BY
+	n.nspname, p.proname, p.oid
+`
+
+	// Query to list the functions and procedures of the given schemas. The first argument
+	// is the expression for the function kind, as the prokind column was added in v11.
+	funcsQuery = `
+SELECT
+	n.nspname,
+	p.proname,
+	%[1]s AS kind,
+	COALESCE((
+		SELECT json_agg(json_build_object(
+			'name', COALESCE(p.proargnames[a.i], ''),
+			'mode', COALESCE(p.proargmodes[a.i], 'i'),
+			'type', pg_catalog.format_type(a.t, NULL),
+			'default', pg_catalog.pg_get_function_arg_default(p.oid, a.i::int)
+		) ORDER BY a.i)
+		FROM unnest(COALESCE(p.proallargtypes, p.proargtypes::oid[])) WITH ORDINALITY AS a(t, i)
+	), '[]') AS args,
+	CASE WHEN %[1]s = 'f' THEN pg_catalog.pg_get_function_result(p.oid) END AS result,
+	l.lanname,
+	p.provolatile,
+	p.prosrc,
+	d.description
+FROM
+	pg_catalog.pg_proc AS p
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = p.pronamespace
+	JOIN pg_catalog.pg_language AS l ON l.oid = p.prolang
+	LEFT JOIN pg_catalog.pg_description AS d ON d.objoid = p.oid AND d.classoid = 'pg_catalog.pg_proc'::regclass AND d.objsubid = 0
+	LEFT JOIN pg_catalog.pg_depend AS dep ON dep.classid = 'pg_catalog.pg_proc'::regclass AND dep.objid = p.oid AND dep.deptype = 'e'
+WHERE
+	n.nspname IN (%[2]s)
+	AND %[1]s IN ('f', 'p')
+	AND l.lanname NOT IN ('c', 'internal')
+	AND dep.objid IS NULL
+ORDER BY
+	n.nspname, p.proname, p.oid
+`
+
+	// Query to list the column-level privileges of the tables in the given schemas.
+	columnGrantsQuery = `
+SELECT
+	n.nspname,
+	c.relname,
+	a.attname,
+	CASE WHEN acl.grantee = 0 THEN 'PUBLIC' ELSE pg_catalog.pg_get_userbyid(acl.grantee) END,
+	string_agg(acl.privilege_type, ',' ORDER BY acl.privilege_type),
+	acl.is_grantable
+FROM
+	pg_catalog.pg_attribute AS a
+	JOIN pg_catalog.pg_class AS c ON c.oid = a.attrelid
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = c.relnamespace
+	CROSS JOIN LATERAL pg_catalog.aclexplode(a.attacl) AS acl
+WHERE
+	n.nspname IN (%s)
+	AND c.relkind IN ('r', 'p')
+	AND a.attnum > 0
+	AND NOT a.attisdropped
+	AND a.attacl IS NOT NULL
+GROUP BY
+	n.nspname, c.relname, a.attnum, a.attname, acl.grantee, acl.is_grantable
+ORDER BY
+	n.nspname, c.relname, a.attnum, 4, acl.is_grantable
+`
+
+	// Query to list the table-level privileges of the tables in the given schemas.
+	// The implicit privileges of the table owners are excluded.
+	tableGrantsQuery = `
+SELECT
+	n.nspname,
+	c.relname,
+	CASE WHEN acl.grantee = 0 THEN 'PUBLIC' ELSE pg_catalog.pg_get_userbyid(acl.grantee) END,
+	string_agg(acl.privilege_type, ',' ORDER BY acl.privilege_type),
+	acl.is_grantable
+FROM
+	pg_catalog.pg_class AS c
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = c.relnamespace
+	CROSS JOIN LATERAL pg_catalog.aclexplode(c.relacl) AS acl
+WHERE
+	n.nspname IN (%s)
+	AND c.relkind IN ('r', 'p')
+	AND c.relacl IS NOT NULL
+	AND acl.grantee <> c.relowner
+GROUP BY
+	n.nspname, c.relname, acl.grantee, acl.is_grantable
+ORDER BY
+	n.nspname, c.relname, 3, acl.is_grantable
+`
+
+	// Query to list the privileges of the given schemas.
+	// The implicit privileges of the schema owners are excluded.
+	schemaGrantsQuery = `
+SELECT
+	n.nspname,
+	CASE WHEN acl.grantee = 0 THEN 'PUBLIC' ELSE pg_catalog.pg_get_userbyid(acl.grantee) END,
+	string_agg(acl.privilege_type, ',' ORDER BY acl.privilege_type),
+	acl.is_grantable
+FROM
+	pg_catalog.pg_namespace AS n
+	CROSS JOIN LATERAL pg_catalog.aclexplode(n.nspacl) AS acl
+WHERE
+	n.nspname IN (%s)
+	AND n.nspacl IS NOT NULL
+	AND acl.grantee <> n.nspowner
+GROUP BY
+	n.nspname, acl.grantee, acl.is_grantable
+ORDER BY
+	n.nspname, 2, acl.is_grantable
+`
+
+	// Query to list the user-defined triggers of the tables in the given schemas.
+	triggersQuery = `
+SELECT
+	n.nspname,
+	c.relname,
+	t.tgname,
+	t.tgtype,
+	t.tgenabled,
+	COALESCE((
+		SELECT json_agg(a.attname ORDER BY k.i)
+		FROM unnest(t.tgattr::int2[]) WITH ORDINALITY AS k(attnum, i)
+		JOIN pg_catalog.pg_attribute AS a ON a.attrelid = t.tgrelid AND a.attnum = k.attnum
+	), '[]') AS columns,
+	pg_catalog.pg_get_triggerdef(t.oid) AS definition
+FROM
+	pg_catalog.pg_trigger AS t
+	JOIN pg_catalog.pg_class AS c ON c.oid = t.tgrelid
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = c.relnamespace
+WHERE
+	NOT t.tgisinternal
+	AND t.tgconstraint = 0
+	AND n.nspname IN (%s)
+ORDER BY
+	n.nspname, c.relname, t.tgname
+`
+
+	// Query to list the installed extensions of the current database. The procedural language
+	// plpgsql is installed by default in every database, and therefore it is skipped.
+	extensionsQuery = `
+SELECT
+	e.extname,
+	n.nspname,
+	e.extversion,
+	pg_catalog.obj_description(e.oid, 'pg_extension') AS comment
+FROM
+	pg_catalog.pg_extension AS e
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = e.extnamespace
+WHERE
+	e.extname <> 'plpgsql'
+ORDER BY
+	e.extname
+`
+
+	// Query to list the foreign servers of the current database.
+	foreignServersQuery = `
+SELECT
+	s.srvname,
+	w.fdwname,
+	s.srvtype,
+	s.srvversion,
+	COALESCE((SELECT json_object_agg(o.option_name, o.option_value) FROM pg_catalog.pg_options_to_table(s.srvoptions) AS o), '{}') AS options,
+	pg_catalog.obj_description(s.oid, 'pg_foreign_server') AS comment
+FROM
+	pg_catalog.pg_foreign_server AS s
+	JOIN pg_catalog.pg_foreign_data_wrapper AS w ON w.oid = s.srvfdw
+ORDER BY
+	s.srvname
+`
+
+	// Query to list the user mappings of the current database. Note, the options
+	// are visible only to the owners of the servers and to the mapped users.
+	userMappingsQuery = `
+SELECT
+	u.srvname,
+	CASE WHEN u.umuser = 0 THEN 'PUBLIC' ELSE u.usename END AS usename,
+	COALESCE((SELECT json_object_agg(o.option_name, o.option_value) FROM pg_catalog.pg_options_to_table(u.umoptions) AS o), '{}') AS options
+FROM
+	pg_catalog.pg_user_mappings AS u
+ORDER BY
+	u.srvname, 2
+`
+
+	// Query to list the foreign tables of the given schemas.
+	foreignTablesQuery = `
+SELECT
+	n.nspname,
+	c.relname,
+	s.srvname,
+	COALESCE((SELECT json_object_agg(o.option_name, o.option_value) FROM pg_catalog.pg_options_to_table(f.ftoptions) AS o), '{}') AS options,
+	d.description
+FROM
+	pg_catalog.pg_foreign_table AS f
+	JOIN pg_catalog.pg_class AS c ON c.oid = f.ftrelid
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = c.relnamespace
+	JOIN pg_catalog.pg_foreign_server AS s ON s.oid = f.ftserver
+	LEFT JOIN pg_catalog.pg_description AS d ON d.objoid = c.oid AND d.classoid = 'pg_catalog.pg_class'::regclass AND d.objsubid = 0
+WHERE
+	n.nspname IN (%s)
+ORDER BY
+	n.nspname, c.relname
+`
+
+	// Query to list the columns of foreign tables of the given schemas.
+	foreignColumnsQuery = `
+SELECT
+	n.nspname,
+	c.relname,
+	a.attname,
+	pg_catalog.format_type(a.atttypid, a.atttypmod),
+	a.attnotnull
+FROM
+	pg_catalog.pg_attribute AS a
+	JOIN pg_catalog.pg_class AS c ON c.oid = a.attrelid
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = c.relnamespace
+WHERE
+	c.relkind = 'f'
+	AND a.attnum > 0
+	AND NOT a.attisdropped
+	AND n.nspname IN (%s)
+ORDER BY
+	n.nspname, c.relname, a.attnum
+`
+
+	// Query to list the event triggers of the current database.
+	eventTriggersQuery = `
+SELECT
+	e.evtname,
+	e.evtevent,
+	e.evtenabled,
+	COALESCE(array_to_json(e.evttags), '[]') AS tags,
+	n.nspname,
+	p.proname,
+	pg_catalog.obj_description(e.oid, 'pg_event_trigger') AS comment
+FROM
+	pg_catalog.pg_event_trigger AS e
+	JOIN pg_catalog.pg_proc AS p ON p.oid = e.evtfoid
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = p.pronamespace
+WHERE
+	NOT EXISTS (
+		SELECT 1 FROM pg_catalog.pg_depend AS d
+		WHERE d.classid = 'pg_catalog.pg_event_trigger'::regclass AND d.objid = e.oid AND d.deptype = 'e'
+	)
+ORDER BY
+	e.evtname
+`
+
+	// Query to list the publications of the current database. The truncate
+	// operation was added in v11, and is formatted as true before that.
+	publicationsQuery = `
+SELECT
+	p.pubname,
+	p.puballtables,
+	p.pubinsert,
+	p.pubupdate,
+	p.pubdelete,
+	%s AS pubtruncate,
+	pg_catalog.obj_description(p.oid, 'pg_publication') AS comment
+FROM
+	pg_catalog.pg_publication AS p
+ORDER BY
+	p.pubname
+`
+
+	// Query to list the tables of the publications. Column lists and row
+	// filters were added in v15, and are formatted as NULL before that.
+	publicationTablesQuery = `
+SELECT
+	p.pubname,
+	n.nspname,
+	c.relname,
+	(SELECT json_agg(a.attname ORDER BY a.attnum) FROM pg_catalog.pg_attribute AS a WHERE a.attrelid = pr.prrelid AND a.attnum = ANY(%s)) AS columns,
+	%s AS filter
+FROM
+	pg_catalog.pg_publication_rel AS pr
+	JOIN pg_catalog.pg_publication AS p ON p.oid = pr.prpubid
+	JOIN pg_catalog.pg_class AS c ON c.oid = pr.prrelid
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = c.relnamespace
+ORDER BY
+	p.pubname, n.nspname, c.relname
+`
+
+	// Query to list the schemas of the publications (v15 and above).
+	publicationSchemasQuery = `
+SELECT
+	p.pubname,
+	n.nspname
+FROM
+	pg_catalog.pg_publication_namespace AS pn
+	JOIN pg_catalog.pg_publication AS p ON p.oid = pn.pnpubid
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = pn.pnnspid
+ORDER BY
+	p.pubname, n.nspname
+`
+
+	// Query to list the named pg_cron jobs of the current database.
+	cronJobsQuery = `SELECT jobname, schedule, command FROM cron.job WHERE jobname IS NOT NULL AND database = current_database() ORDER BY jobname`
+
+	// Query to list sequences of the given schemas that are not part of IDENTITY columns.
+	sequencesQuery = `
+SELECT
+	n.nspname,
+	c.relname,
+	pg_catalog.format_type(s.seqtypid, NULL),
+	s.seqstart,
+	s.seqincrement,
+	s.seqmin,
+	s.seqmax,
+	s.seqcache,
+	s.seqcycle,
+	t.relname,
+	a.attname,
+	d.description
+FROM
+	pg_catalog.pg_sequence AS s
+	JOIN pg_catalog.pg_class AS c ON c.oid = s.seqrelid
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = c.relnamespace
+	LEFT JOIN pg_catalog.pg_depend AS dep ON dep.classid = 'pg_catalog.pg_class'::regclass AND dep.objid = c.oid AND dep.refclassid = 'pg_catalog.pg_class'::regclass AND dep.deptype IN ('a', 'i')
+	LEFT JOIN pg_catalog.pg_class AS t ON t.oid = dep.refobjid
+	LEFT JOIN pg_catalog.pg_attribute AS a ON a.attrelid = dep.refobjid AND a.attnum = dep.refobjsubid
+	LEFT JOIN pg_catalog.pg_description AS d ON d.objoid = c.oid AND d.classoid = 'pg_catalog.pg_class'::regclass AND d.objsubid = 0
+WHERE
+	n.nspname IN (%s)
+	AND (dep.deptype IS NULL OR dep.deptype = 'a')
+ORDER BY
+	n.nspname, c.relname
+`
 
 	// Query to list database schemas.
 	schemasQuery = `
@@ -1534,8 +5026,9 @@ WHERE
 ORDER BY
     nspname`
 
-	// Query to list table columns.
-	columnsQuery = `
+	// Query to list table columns. The compression method is
+	// recorded only if it was set explicitly on the column.
+	columnsQueryTmpl = `
 SELECT
 	t1.table_name,
 	t1.column_name,
@@ -1549,7 +5042,7 @@ SELECT
 	t1.numeric_scale,
 	t1.interval_type,
 	t1.character_set_name,
-	t1.collation_name,
+	CASE WHEN a.attcollation <> t4.typcollation THEN t1.collation_name END AS collation_name,
 	t1.is_identity,
 	t1.identity_start,
 	t1.identity_increment,
@@ -1560,7 +5053,12 @@ SELECT
 	t4.typtype,
 	t4.typelem,
 	t4.oid,
-	a.attnum
+	a.attnum,
+	t1.identity_minimum,
+	t1.identity_maximum,
+	(CASE WHEN t1.is_identity = 'YES' THEN (SELECT cache_size FROM pg_sequences WHERE quote_ident(schemaname) || '.' || quote_ident(sequencename) = pg_get_serial_sequence(quote_ident(t1.table_schema) || '.' || quote_ident(t1.table_name), t1.column_name)) END) AS identity_cache,
+	t1.identity_cycle,
+	%s AS compression
 FROM
 	"information_schema"."columns" AS t1
 	JOIN pg_catalog.pg_namespace AS t2 ON t2.nspname = t1.table_schema
@@ -1576,20 +5074,22 @@ ORDER BY
 	enumsQuery = `
 SELECT
 	n.nspname AS schema_name,
-	e.enumtypid AS enum_id,
+	t.oid AS enum_id,
 	t.typname AS enum_name,
-	e.enumlabel AS enum_value
+	e.enumlabel AS enum_value,
+	obj_description(t.oid, 'pg_type') AS comment
 FROM
-	pg_enum e
-	JOIN pg_type t ON e.enumtypid = t.oid
+	pg_type t
 	JOIN pg_namespace n ON t.typnamespace = n.oid
+	LEFT JOIN pg_enum e ON e.enumtypid = t.oid
 WHERE
-    n.nspname IN (%s)
+    t.typtype = 'e'
+    AND n.nspname IN (%s)
 ORDER BY
-    n.nspname, e.enumtypid, e.enumsortorder
+    n.nspname, t.oid, e.enumsortorder
 `
 	// Query to list foreign-keys.
-	fksQuery = `
+	fksQueryTmpl = `
 SELECT 
     fk.constraint_name,
     fk.table_name,
@@ -1599,7 +5099,11 @@ SELECT
     a2.attname AS referenced_column_name,
     fk.referenced_schema_name,
     fk.confupdtype,
-    fk.confdeltype
+    fk.confdeltype,
+    fk.convalidated,
+    fk.condeferrable,
+    fk.condeferred,
+    fk.delsetcols
 	FROM 
 	    (
 	    	SELECT
@@ -1614,7 +5118,11 @@ SELECT
 	      		unnest(con.conkey) AS conkey,
 	      		unnest(con.confkey) AS confkey,
 	      		con.confupdtype,
-	      		con.confdeltype
+	      		con.confdeltype,
+	      		con.convalidated,
+	      		con.condeferrable,
+	      		con.condeferred,
+	      		%s AS delsetcols
 	    	FROM pg_constraint con
 	    	JOIN pg_class t1 ON t1.oid = con.conrelid
 	    	JOIN pg_class t2 ON t2.oid = con.confrelid
@@ -1630,6 +5138,47 @@ SELECT
 	    fk.conrelid, fk.constraint_name, fk.ord
 `
 
+	// Query to list table extended statistics objects.
+	statisticsQuery = `
+SELECT
+	t.relname AS table_name,
+	s.stxname AS statistics_name,
+	array_to_string(s.stxkind, ',') AS kinds,
+	a.attname AS column_name
+FROM
+	pg_catalog.pg_statistic_ext s
+	JOIN pg_catalog.pg_class t ON t.oid = s.stxrelid
+	JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+	JOIN LATERAL unnest(s.stxkeys::int2[]) WITH ORDINALITY AS k(attnum, ord) ON true
+	JOIN pg_catalog.pg_attribute a ON a.attrelid = s.stxrelid AND a.attnum = k.attnum
+WHERE
+	n.nspname = $1
+	AND t.relname IN (%s)
+ORDER BY
+	t.relname, s.stxname, k.ord
+`
+
+	// Query to list the rewrite rules of tables. The "_RETURN" rules are
+	// skipped, as they are created implicitly for views.
+	rulesQuery = `
+SELECT
+	t.relname AS table_name,
+	r.rulename AS rule_name,
+	r.ev_type AS event,
+	r.is_instead,
+	pg_catalog.pg_get_ruledef(r.oid) AS definition
+FROM
+	pg_catalog.pg_rewrite r
+	JOIN pg_catalog.pg_class t ON t.oid = r.ev_class
+	JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+WHERE
+	n.nspname = $1
+	AND t.relname IN (%s)
+	AND r.rulename <> '_RETURN'
+ORDER BY
+	t.relname, r.rulename
+`
+
 	// Query to list table check constraints.
 	checksQuery = `
 SELECT
@@ -1658,6 +5207,10 @@ ORDER BY
 )
 
 var (
+	fksQuery         = fmt.Sprintf(fksQueryTmpl, "NULL", "%s")
+	fksAbove15       = fmt.Sprintf(fksQueryTmpl, "(SELECT json_agg(a.attname ORDER BY k.ord) FROM unnest(con.confdelsetcols) WITH ORDINALITY AS k(attnum, ord) JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum)", "%s")
+	columnsQuery     = fmt.Sprintf(columnsQueryTmpl, "NULL", "%s")
+	columnsAbove14   = fmt.Sprintf(columnsQueryTmpl, "CASE a.attcompression WHEN 'p' THEN 'pglz' WHEN 'l' THEN 'lz4' END", "%s")
 	indexesBelow11   = fmt.Sprintf(indexesQueryTmpl, "false", "false", "%s")
 	indexesAbove11   = fmt.Sprintf(indexesQueryTmpl, "(a.attname <> '' AND idx.indnatts > idx.indnkeyatts AND idx.ord > idx.indnkeyatts)", "false", "%s")
 	indexesAbove15   = fmt.Sprintf(indexesQueryTmpl, "(a.attname <> '' AND idx.indnatts > idx.indnkeyatts AND idx.ord > idx.indnkeyatts)", "idx.indnullsnotdistinct", "%s")
@@ -1683,7 +5236,10 @@ SELECT
 	op.opcnamespace::regnamespace::text AS opclass_schema,
 	op.opcdefault AS opclass_default,
 	a2.attoptions AS opclass_params,
-    %s AS indnullsnotdistinct
+    %s AS indnullsnotdistinct,
+	(SELECT e.extname FROM pg_depend AS d JOIN pg_extension AS e ON e.oid = d.refobjid WHERE d.classid = 'pg_opclass'::regclass AND d.objid = op.oid AND d.refclassid = 'pg_extension'::regclass AND d.deptype = 'e' LIMIT 1) AS opclass_extension,
+	con.deferrable AS deferrable,
+	(SELECT ts.spcname FROM pg_catalog.pg_tablespace AS ts WHERE ts.oid = i.reltablespace) AS tablespace
 FROM
 	(
 		select
@@ -1696,7 +5252,10 @@ FROM
 	JOIN pg_class t ON t.oid = idx.indrelid
 	JOIN pg_namespace n ON n.oid = t.relnamespace
 	LEFT JOIN (
-	    select conindid, jsonb_object_agg(conname, contype) AS nametypes
+	    select
+	        conindid,
+	        jsonb_object_agg(conname, contype) AS nametypes,
+	        jsonb_object_agg(conname, CASE WHEN condeferred THEN 'deferred' ELSE 'immediate' END) FILTER (WHERE condeferrable) AS deferrable
 	    from pg_constraint
 	    group by conindid
 	) con ON con.conindid = idx.indexrelid
@@ -22,11 +22,13 @@ import (
 
 // Single table queries used by the different tests.
 var (
-	queryFKs         = sqltest.Escape(fmt.Sprintf(fksQuery, "$2"))
+	queryFKs         = sqltest.Escape(fmt.Sprintf(fksAbove15, "$2"))
 	queryEnums       = sqltest.Escape(fmt.Sprintf(enumsQuery, "$1"))
 	queryTables      = sqltest.Escape(fmt.Sprintf(tablesQuery, "$1"))
 	queryChecks      = sqltest.Escape(fmt.Sprintf(checksQuery, "$2"))
-	queryColumns     = sqltest.Escape(fmt.Sprintf(columnsQuery, "$2"))
+	queryStats       = sqltest.Escape(fmt.Sprintf(statisticsQuery, "$2"))
+	queryRules       = sqltest.Escape(fmt.Sprintf(rulesQuery, "$2"))
+	queryColumns     = sqltest.Escape(fmt.Sprintf(columnsAbove14, "$2"))
 	queryCRDBColumns = sqltest.Escape(fmt.Sprintf(crdbColumnsQuery, "$2"))
 	queryIndexes     = sqltest.Escape(fmt.Sprintf(indexesAbove15, "$2"))
 	queryCRDBIndexes = sqltest.Escape(fmt.Sprintf(crdbIndexesQuery, "$2"))
@@ -44,19 +46,22 @@ func TestDriver_InspectTable(t *testing.T) {
 				m.ExpectQuery(sqltest.Escape(fmt.Sprintf(enumsQuery, "$1"))).
 					WithArgs("public").
 					WillReturnRows(sqltest.Rows(`
- schema_name | enum_id | type    | enum_value
--------------+---------+---------+------------
- public      |   16774 |  state  | on
- public      |   16774 |  state  | off
- public      |   16775 |  status | unknown
+ schema_name | enum_id | type    | enum_value | comment
+-------------+---------+---------+------------+---------
+ public      |   16774 |  state  | on         |
+ public      |   16774 |  state  | off        |
+ public      |   16775 |  status | unknown    | unknown status
 `))
+				m.noDomains()
+				m.noRanges()
+				m.noComposites()
 				m.tableExists("public", "users", true)
 				m.ExpectQuery(queryColumns).
 					WithArgs("public", "users").
 					WillReturnRows(sqltest.Rows(`
- table_name  |  column_name |          data_type          |  formatted          | is_nullable |         column_default                 | character_maximum_length | numeric_precision | datetime_precision | numeric_scale |    interval_type    | character_set_name | collation_name | is_identity | identity_start | identity_increment |   identity_last  | identity_generation | generation_expression | comment | typtype | typelem |  oid  |  attnum  
--------------+--------------+-----------------------------+---------------------|-------------+----------------------------------------+--------------------------+-------------------+--------------------+---------------+---------------------+--------------------+----------------+-------------+----------------+--------------------+------------------+---------------------+-----------------------+---------+---------+---------+-------+-------
- users       |  id          | bigint                      | int8                | NO          |                                        |                          |                64 |                    |             0 |                     |                    |                | YES         |      100       |          1         |          1       |    BY DEFAULT       |                       |         | b       |         |    20 |  
+ table_name  |  column_name |          data_type          |  formatted          | is_nullable |         column_default                 | character_maximum_length | numeric_precision | datetime_precision | numeric_scale |    interval_type    | character_set_name | collation_name | is_identity | identity_start | identity_increment |   identity_last  | identity_generation | generation_expression | comment | typtype | typelem |  oid  |  attnum | identity_minimum | identity_maximum | identity_cache | identity_cycle | compression
+-------------+--------------+-----------------------------+---------------------|-------------+----------------------------------------+--------------------------+-------------------+--------------------+---------------+---------------------+--------------------+----------------+-------------+----------------+--------------------+------------------+---------------------+-----------------------+---------+---------+---------+-------+-------+------------------+------------------+----------------+---------------
+ users       |  id          | bigint                      | int8                | NO          |                                        |                          |                64 |                    |             0 |                     |                    |                | YES         |      100       |          1         |          1       |    BY DEFAULT       |                       |         | b       |         |    20 |  | 1                | 1000             | 10             | YES
  users       |  rank        | integer                     | int4                | YES         |                                        |                          |                32 |                    |             0 |                     |                    |                | NO          |                |                    |                  |                     |                       | rank    | b       |         |    23 |  
  users       |  c1          | smallint                    | int2                | NO          |           1000                         |                          |                16 |                    |             0 |                     |                    |                | NO          |                |                    |                  |                     |                       |         | b       |         |    21 |  
  users       |  c2          | bit                         | bit                 | NO          |                                        |                        1 |                   |                    |               |                     |                    |                | NO          |                |                    |                  |                     |                       |         | b       |         |  1560 |  
@@ -109,9 +114,9 @@ func TestDriver_InspectTable(t *testing.T) {
 				require.NoError(err)
 				require.Equal("users", t.Name)
 				stateE := &schema.EnumType{T: "state", Values: []string{"on", "off"}, Schema: t.Schema}
-				statusE := &schema.EnumType{T: "status", Values: []string{"unknown"}, Schema: t.Schema}
+				statusE := &schema.EnumType{T: "status", Values: []string{"unknown"}, Schema: t.Schema, Attrs: []schema.Attr{&schema.Comment{Text: "unknown status"}}}
 				expected := []*schema.Column{
-					{Name: "id", Type: &schema.ColumnType{Raw: "bigint", Type: &schema.IntegerType{T: "bigint"}}, Attrs: []schema.Attr{&Identity{Generation: "BY DEFAULT", Sequence: &Sequence{Start: 100, Increment: 1, Last: 1}}}},
+					{Name: "id", Type: &schema.ColumnType{Raw: "bigint", Type: &schema.IntegerType{T: "bigint"}}, Attrs: []schema.Attr{&Identity{Generation: "BY DEFAULT", Sequence: &Sequence{Start: 100, Increment: 1, Last: 1, Cache: 10, Cycle: true, Max: sqlx.P[int64](1000)}}}},
 					{Name: "rank", Type: &schema.ColumnType{Raw: "integer", Null: true, Type: &schema.IntegerType{T: "integer"}}, Attrs: []schema.Attr{&schema.Comment{Text: "rank"}}},
 					{Name: "c1", Type: &schema.ColumnType{Raw: "smallint", Type: &schema.IntegerType{T: "smallint"}}, Default: &schema.Literal{V: "1000"}},
 					{Name: "c2", Type: &schema.ColumnType{Raw: "bit", Type: &BitType{T: "bit", Len: 1}}},
@@ -166,12 +171,15 @@ func TestDriver_InspectTable(t *testing.T) {
 			name: "table indexes",
 			before: func(m mock) {
 				m.noEnums()
+				m.noDomains()
+				m.noRanges()
+				m.noComposites()
 				m.tableExists("public", "users", true)
 				m.ExpectQuery(queryColumns).
 					WithArgs("public", "users").
 					WillReturnRows(sqltest.Rows(`
-table_name | column_name |      data_type      | formatted |  is_nullable |         column_default          | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | interval_type | character_set_name | collation_name | is_identity | identity_start | identity_increment |   identity_last  | identity_generation | generation_expression | comment | typtype | typelem |  oid  |  attnum  
------------+-------------+---------------------+-----------+--------------+---------------------------------+--------------------------+-------------------+--------------------+---------------+---------------+--------------------+----------------+-------------+----------------+--------------------+------------------+---------------------+-----------------------+---------+---------+---------+-------+-------
+table_name | column_name |      data_type      | formatted |  is_nullable |         column_default          | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | interval_type | character_set_name | collation_name | is_identity | identity_start | identity_increment |   identity_last  | identity_generation | generation_expression | comment | typtype | typelem |  oid  |  attnum | identity_minimum | identity_maximum | identity_cache | identity_cycle | compression
+-----------+-------------+---------------------+-----------+--------------+---------------------------------+--------------------------+-------------------+--------------------+---------------+---------------+--------------------+----------------+-------------+----------------+--------------------+------------------+---------------------+-----------------------+---------+---------+---------+-------+-------+------------------+------------------+----------------+---------------
 users      | id          | bigint              | int8      |  NO          |                                 |                          |                64 |                    |             0 |               |                    |                | NO          |                |                    |                  |                     |                       |         | b       |         |    20 | 
 users      | c1          | smallint            | int2      |  NO          |                                 |                          |                16 |                    |             0 |               |                    |                | NO          |                |                    |                  |                     |                       |         | b       |         |    21 | 
 users      | parent_id   | bigint              | int8      |  YES         |                                 |                          |                64 |                    |             0 |               |                    |                | NO          |                |                    |                  |                     |                       |         | b       |         |    22 | 
@@ -180,24 +188,24 @@ users      | ts          | tsvector            | tsvector  |  NO          |
 				m.ExpectQuery(queryIndexes).
 					WithArgs("public", "users").
 					WillReturnRows(sqltest.Rows(`
-   table_name   |    index_name   | index_type  | column_name | included | primary | unique | opexpr |   constraints   | predicate             |   expression              | desc | nulls_first | nulls_last | comment   |                 options               |   opclass_name    |   opclass_schema  | opclass_default | opclass_params | indnullsnotdistinct 
-----------------+-----------------+-------------+-------------+----------+---------+--------+--------+-----------------+-----------------------+---------------------------+------+-------------+------------+-----------+---------------------------------------+-------------------+-------------------+-----------------+----------------+---------------------
-users           | idx             | hash        |             | f        | f       | f      |        |                 |                       | "left"((c11)::text, 100)  | t    | t           | f          | boring    |                                       |     int4_ops      |     public        |        t        |                | f
-users           | idx1            | btree       |             | f        | f       | f      |        |                 | (id <> NULL::integer) | "left"((c11)::text, 100)  | t    | t           | f          |           |                                       |     int4_ops      |     public        |        t        |                | f
-users           | t1_c1_key       | btree       | c1          | f        | f       | t      |        | {"name": "u"}   |                       | c1                        | t    | t           | f          |           |                                       |     int4_ops      |     public        |        t        |                | f
-users           | t1_pkey         | btree       | id          | f        | t       | t      |        | {"t_pkey": "p"} |                       | id                        | t    | f           | f          |           |                                       |     int4_ops      |     public        |        t        |                | f
-users           | idx4            | btree       | c1          | f        | f       | t      |        |                 |                       | c1                        | f    | f           | f          |           |                                       |     int4_ops      |     public        |        t        |                | f
-users           | idx4            | btree       | id          | f        | f       | t      |        |                 |                       | id                        | f    | f           | t          |           |                                       |     int4_ops      |     public        |        t        |                | f
-users           | idx5            | btree       | c1          | f        | f       | t      |        |                 |                       | c1                        | f    | f           | f          |           |                                       |     int4_ops      |     public        |        t        |                | f
-users           | idx5            | btree       |             | f        | f       | t      |        |                 |                       | coalesce(parent_id, 0)    | f    | f           | f          |           |                                       |     int4_ops      |     public        |        t        |                | f
-users           | idx6            | brin        | c1          | f        | f       | t      |        |                 |                       |                           | f    | f           | f          |           | {autosummarize=true,pages_per_range=2}|     int4_ops      |     public        |        t        |                | f
-users           | idx2            | btree       |             | f        | f       | f      |        |                 |                       | ((c * 2))                 | f    | f           | t          |           |                                       |     int4_ops      |     public        |        t        |                | f
-users           | idx2            | btree       | c1          | f        | f       | f      |        |                 |                       | c                         | f    | f           | t          |           |                                       |     int4_ops      |     public        |        t        |                | f
-users           | idx2            | btree       | id          | f        | f       | f      |        |                 |                       | d                         | f    | f           | t          |           |                                       |     int4_ops      |     public        |        t        |                | f
-users           | idx2            | btree       | c1          | t        | f       | f      |        |                 |                       | c                         |      |             |            |           |                                       |     int4_ops      |     public        |        t        |                | f
-users           | idx2            | btree       | parent_id   | t        | f       | f      |        |                 |                       | d                         |      |             |            |           |                                       |     int4_ops      |     public        |        t        |                | f
-users           | dep_other_ns    | vec         | c1          | f        | f       | f      |        |                 |                       | c1                        |      |             |            |           |                                       |     vec_ops       |     unknown_ns    |        f        | {siglen=1}     | f
-users           | tsx             | gist        | ts          | f        | f       | f      |        |                 |                       | ts                        |      |             |            |           |                                       |     tsvector_ops  |     pg_catalog    |        f        | {siglen=1}     | f
+   table_name   |    index_name   | index_type  | column_name | included | primary | unique | opexpr |   constraints   | predicate             |   expression              | desc | nulls_first | nulls_last | comment   |                 options               |   opclass_name    |   opclass_schema  | opclass_default | opclass_params | indnullsnotdistinct | opclass_extension | deferrable           | tablespace
+----------------+-----------------+-------------+-------------+----------+---------+--------+--------+-----------------+-----------------------+---------------------------+------+-------------+------------+-----------+---------------------------------------+-------------------+-------------------+-----------------+----------------+---------------------+-------------------+----------------------+------------
+users           | idx             | hash        |             | f        | f       | f      |        |                 |                       | "left"((c11)::text, 100)  | t    | t           | f          | boring    |                                       |     int4_ops      |     public        |        t        |                | f                   |
+users           | idx1            | btree       |             | f        | f       | f      |        |                 | (id <> NULL::integer) | "left"((c11)::text, 100)  | t    | t           | f          |           |                                       |     int4_ops      |     public        |        t        |                | f                   |                   |                      | fast
+users           | t1_c1_key       | btree       | c1          | f        | f       | t      |        | {"name": "u"}   |                       | c1                        | t    | t           | f          |           |                                       |     int4_ops      |     public        |        t        |                | f                   |                   | {"name": "deferred"}
+users           | t1_pkey         | btree       | id          | f        | t       | t      |        | {"t_pkey": "p"} |                       | id                        | t    | f           | f          |           |                                       |     int4_ops      |     public        |        t        |                | f                   |
+users           | idx4            | btree       | c1          | f        | f       | t      |        |                 |                       | c1                        | f    | f           | f          |           | {fillfactor=70}                       |     int4_ops      |     public        |        t        |                | f                   |
+users           | idx4            | btree       | id          | f        | f       | t      |        |                 |                       | id                        | f    | f           | t          |           | {fillfactor=70}                       |     int4_ops      |     public        |        t        |                | f                   |
+users           | idx5            | btree       | c1          | f        | f       | t      |        |                 |                       | c1                        | f    | f           | f          |           |                                       |     int4_ops      |     public        |        t        |                | f                   |
+users           | idx5            | btree       |             | f        | f       | t      |        |                 |                       | coalesce(parent_id, 0)    | f    | f           | f          |           |                                       |     int4_ops      |     public        |        t        |                | f                   |
+users           | idx6            | brin        | c1          | f        | f       | t      |        |                 |                       |                           | f    | f           | f          |           | {autosummarize=true,pages_per_range=2}|     int4_ops      |     public        |        t        |                | f                   |
+users           | idx2            | btree       |             | f        | f       | f      |        |                 |                       | ((c * 2))                 | f    | f           | t          |           |                                       |     int4_ops      |     public        |        t        |                | f                   |
+users           | idx2            | btree       | c1          | f        | f       | f      |        |                 |                       | c                         | f    | f           | t          |           |                                       |     int4_ops      |     public        |        t        |                | f                   |
+users           | idx2            | btree       | id          | f        | f       | f      |        |                 |                       | d                         | f    | f           | t          |           |                                       |     int4_ops      |     public        |        t        |                | f                   |
+users           | idx2            | btree       | c1          | t        | f       | f      |        |                 |                       | c                         |      |             |            |           |                                       |     int4_ops      |     public        |        t        |                | f                   |
+users           | idx2            | btree       | parent_id   | t        | f       | f      |        |                 |                       | d                         |      |             |            |           |                                       |     int4_ops      |     public        |        t        |                | f                   |
+users           | dep_other_ns    | vec         | c1          | f        | f       | f      |        |                 |                       | c1                        |      |             |            |           |                                       |     vec_ops       |     unknown_ns    |        f        | {siglen=1}     | f                   | vector
+users           | tsx             | gist        | ts          | f        | f       | f      |        |                 |                       | ts                        |      |             |            |           |                                       |     tsvector_ops  |     pg_catalog    |        f        | {siglen=1}     | f                   |
 `))
 				m.noFKs()
 				m.noChecks()
@@ -213,9 +221,9 @@ users           | tsx             | gist        | ts          | f        | f
 				}
 				indexes := []*schema.Index{
 					{Name: "idx", Table: t, Attrs: []schema.Attr{&IndexType{T: "hash"}, &schema.Comment{Text: "boring"}}, Parts: []*schema.IndexPart{{SeqNo: 1, X: &schema.RawExpr{X: `"left"((c11)::text, 100)`}, Desc: true, Attrs: []schema.Attr{&IndexColumnProperty{NullsFirst: true}}}}},
-					{Name: "idx1", Table: t, Attrs: []schema.Attr{&IndexType{T: "btree"}, &IndexPredicate{P: `(id <> NULL::integer)`}}, Parts: []*schema.IndexPart{{SeqNo: 1, X: &schema.RawExpr{X: `"left"((c11)::text, 100)`}, Desc: true, Attrs: []schema.Attr{&IndexColumnProperty{NullsFirst: true}}}}},
-					{Name: "t1_c1_key", Unique: true, Table: t, Attrs: []schema.Attr{&IndexType{T: "btree"}, &Constraint{N: "name", T: "u"}}, Parts: []*schema.IndexPart{{SeqNo: 1, C: columns[1], Desc: true, Attrs: []schema.Attr{&IndexColumnProperty{NullsFirst: true}}}}},
-					{Name: "idx4", Unique: true, Table: t, Attrs: []schema.Attr{&IndexType{T: "btree"}}, Parts: []*schema.IndexPart{{SeqNo: 1, C: columns[1]}, {SeqNo: 2, C: columns[0], Attrs: []schema.Attr{&IndexColumnProperty{NullsLast: true}}}}},
+					{Name: "idx1", Table: t, Attrs: []schema.Attr{&IndexType{T: "btree"}, &IndexPredicate{P: `(id <> NULL::integer)`}, &Tablespace{V: "fast"}}, Parts: []*schema.IndexPart{{SeqNo: 1, X: &schema.RawExpr{X: `"left"((c11)::text, 100)`}, Desc: true, Attrs: []schema.Attr{&IndexColumnProperty{NullsFirst: true}}}}},
+					{Name: "t1_c1_key", Unique: true, Table: t, Attrs: []schema.Attr{&IndexType{T: "btree"}, &Constraint{N: "name", T: "u"}, &Deferrable{InitiallyDeferred: true}}, Parts: []*schema.IndexPart{{SeqNo: 1, C: columns[1], Desc: true, Attrs: []schema.Attr{&IndexColumnProperty{NullsFirst: true}}}}},
+					{Name: "idx4", Unique: true, Table: t, Attrs: []schema.Attr{&IndexType{T: "btree"}, &IndexStorageParams{}, &StorageParams{V: []StorageParam{{Name: "fillfactor", Value: "70"}}}}, Parts: []*schema.IndexPart{{SeqNo: 1, C: columns[1]}, {SeqNo: 2, C: columns[0], Attrs: []schema.Attr{&IndexColumnProperty{NullsLast: true}}}}},
 					{Name: "idx5", Unique: true, Table: t, Attrs: []schema.Attr{&IndexType{T: "btree"}}, Parts: []*schema.IndexPart{{SeqNo: 1, C: columns[1]}, {SeqNo: 2, X: &schema.RawExpr{X: `coalesce(parent_id, 0)`}}}},
 					{Name: "idx6", Unique: true, Table: t, Attrs: []schema.Attr{&IndexType{T: "brin"}, &IndexStorageParams{AutoSummarize: true, PagesPerRange: 2}}, Parts: []*schema.IndexPart{{SeqNo: 1, C: columns[1]}}},
 					{Name: "idx2", Unique: false, Table: t, Attrs: []schema.Attr{&IndexType{T: "btree"}, &IndexInclude{Columns: columns[1:3]}}, Parts: []*schema.IndexPart{{SeqNo: 1, X: &schema.RawExpr{X: `((c * 2))`}, Attrs: []schema.Attr{&IndexColumnProperty{NullsLast: true}}}, {SeqNo: 2, C: columns[1], Attrs: []schema.Attr{&IndexColumnProperty{NullsLast: true}}}, {SeqNo: 3, C: columns[0], Attrs: []schema.Attr{&IndexColumnProperty{NullsLast: true}}}}},
@@ -235,18 +243,62 @@ users           | tsx             | gist        | ts          | f        | f
 				require.EqualValues(columns, t.Columns)
 				require.EqualValues(indexes, t.Indexes)
 				require.EqualValues(pk, t.PrimaryKey)
+				require.Equal([]schema.Object{&OpClassDep{Name: "unknown_ns.vec_ops", Extension: "vector"}}, t.Deps)
+			},
+		},
+		{
+			name: "exclude constraints",
+			before: func(m mock) {
+				m.noEnums()
+				m.noDomains()
+				m.noRanges()
+				m.noComposites()
+				m.tableExists("public", "bookings", true)
+				m.ExpectQuery(queryColumns).
+					WithArgs("public", "bookings").
+					WillReturnRows(sqltest.Rows(`
+table_name | column_name |      data_type      | formatted |  is_nullable |         column_default          | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | interval_type | character_set_name | collation_name | is_identity | identity_start | identity_increment |   identity_last  | identity_generation | generation_expression | comment | typtype | typelem |  oid  |  attnum | identity_minimum | identity_maximum | identity_cache | identity_cycle | compression
+-----------+-------------+---------------------+-----------+--------------+---------------------------------+--------------------------+-------------------+--------------------+---------------+---------------+--------------------+----------------+-------------+----------------+--------------------+------------------+---------------------+-----------------------+---------+---------+---------+-------+-------+------------------+------------------+----------------+---------------
+bookings   | room        | integer             | int4      |  NO          |                                 |                          |                32 |                    |             0 |               |                    |                | NO          |                |                    |                  |                     |                       |         | b       |         |    23 | 
+bookings   | during      | tsrange             | tsrange   |  NO          |                                 |                          |                   |                    |               |               |                    |                | NO          |                |                    |                  |                     |                       |         | r       |         |  3908 | 
+`))
+				m.ExpectQuery(queryIndexes).
+					WithArgs("public", "bookings").
+					WillReturnRows(sqltest.Rows(`
+   table_name   |    index_name   | index_type  | column_name | included | primary | unique |      opexpr       |        constraints       | predicate   |   expression    | desc | nulls_first | nulls_last | comment   | options |   opclass_name    |   opclass_schema  | opclass_default | opclass_params | indnullsnotdistinct | opclass_extension | deferrable           | tablespace
+----------------+-----------------+-------------+-------------+----------+---------+--------+-------------------+--------------------------+-------------+-----------------+------+-------------+------------+-----------+---------+-------------------+-------------------+-----------------+----------------+---------------------+-------------------+----------------------+------------
+bookings        | no_overlap      | gist        | room        | f        | f       | f      | pg_catalog.=      | {"no_overlap": "x"}      | (room > 0)  | room            | f    | f           | f          |           |         | gist_int4_ops     | public            | t               |                | f                   |
+bookings        | no_overlap      | gist        | during      | f        | f       | f      | pg_catalog.&&     | {"no_overlap": "x"}      | (room > 0)  | during          | f    | f           | f          |           |         | range_ops         | pg_catalog        | t               |                | f                   |
+`))
+				m.noFKs()
+				m.noChecks()
+			},
+			expect: func(require *require.Assertions, t *schema.Table, err error) {
+				require.NoError(err)
+				require.Len(t.Indexes, 1)
+				idx := t.Indexes[0]
+				require.Equal("no_overlap", idx.Name)
+				require.Equal([]schema.Attr{&IndexType{T: "gist"}, &Constraint{N: "no_overlap", T: "x"}, &IndexPredicate{P: "(room > 0)"}}, idx.Attrs)
+				require.Len(idx.Parts, 2)
+				require.Equal(t.Columns[0], idx.Parts[0].C)
+				require.Equal([]schema.Attr{&Operator{Name: "="}}, idx.Parts[0].Attrs)
+				require.Equal(t.Columns[1], idx.Parts[1].C)
+				require.Equal([]schema.Attr{&Operator{Name: "&&"}}, idx.Parts[1].Attrs)
 			},
 		},
 		{
 			name: "fks",
 			before: func(m mock) {
 				m.noEnums()
+				m.noDomains()
+				m.noRanges()
+				m.noComposites()
 				m.tableExists("public", "users", true)
 				m.ExpectQuery(queryColumns).
 					WithArgs("public", "users").
 					WillReturnRows(sqltest.Rows(`
-table_name | column_name |      data_type      | formatted | is_nullable |         column_default          | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | interval_type | character_set_name | collation_name | is_identity | identity_start | identity_increment |   identity_last  | identity_generation | generation_expression | comment | typtype | typelem | oid  | attnum 
------------+-------------+---------------------+-----------+-------------+---------------------------------+--------------------------+-------------------+--------------------+---------------+---------------+--------------------+----------------+-------------+----------------+--------------------+------------------+---------------------+-----------------------+---------+---------+---------+------+-----
+table_name | column_name |      data_type      | formatted | is_nullable |         column_default          | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | interval_type | character_set_name | collation_name | is_identity | identity_start | identity_increment |   identity_last  | identity_generation | generation_expression | comment | typtype | typelem | oid  | attnum | identity_minimum | identity_maximum | identity_cache | identity_cycle | compression
+-----------+-------------+---------------------+-----------+-------------+---------------------------------+--------------------------+-------------------+--------------------+---------------+---------------+--------------------+----------------+-------------+----------------+--------------------+------------------+---------------------+-----------------------+---------+---------+---------+------+-----+------------------+------------------+----------------+---------------
 users      | id          | integer             | int       | NO          |                                 |                          |                32 |                    |             0 |               |                    |                | NO          |                |                    |                  |                     |                       |         | b       |         |   20 |   
 users      | oid         | integer             | int       | NO          |                                 |                          |                32 |                    |             0 |               |                    |                | NO          |                |                    |                  |                     |                       |         | b       |         |   21 |   
 users      | uid         | integer             | int       | NO          |                                 |                          |                32 |                    |             0 |               |                    |                | NO          |                |                    |                  |                     |                       |         | b       |         |   21 |   
@@ -255,13 +307,13 @@ users      | uid         | integer             | int       | NO          |
 				m.ExpectQuery(queryFKs).
 					WithArgs("public", "users").
 					WillReturnRows(sqltest.Rows(`
-constraint_name | table_name | column_name | table_schema | referenced_table_name | referenced_column_name | referenced_schema_name | confupdtype | condeltype
------------------+------------+-------------+--------------+-----------------------+------------------------+------------------------+-------------+-------------
-multi_column    | users      | id          | public       | t1                    | gid                    | public                 | a            | c
-multi_column    | users      | id          | public       | t1                    | xid                    | public                 | a            | c
-multi_column    | users      | oid         | public       | t1                    | gid                    | public                 | a            | c
-multi_column    | users      | oid         | public       | t1                    | xid                    | public                 | a            | c
-self_reference  | users      | uid         | public       | users                 | id                     | public                 | a            | c
+constraint_name | table_name | column_name | table_schema | referenced_table_name | referenced_column_name | referenced_schema_name | confupdtype | condeltype | convalidated | condeferrable | condeferred | delsetcols
+-----------------+------------+-------------+--------------+-----------------------+------------------------+------------------------+-------------+------------+--------------+---------------+-------------+------------
+multi_column    | users      | id          | public       | t1                    | gid                    | public                 | a            | n         | t            | t             | f           | ["oid"]
+multi_column    | users      | id          | public       | t1                    | xid                    | public                 | a            | n         | t            | t             | f           | ["oid"]
+multi_column    | users      | oid         | public       | t1                    | gid                    | public                 | a            | n         | t            | t             | f           | ["oid"]
+multi_column    | users      | oid         | public       | t1                    | xid                    | public                 | a            | n         | t            | t             | f           | ["oid"]
+self_reference  | users      | uid         | public       | users                 | id                     | public                 | a            | c         | f            | f             | f           |
 `))
 				m.noChecks()
 			},
@@ -270,8 +322,8 @@ self_reference  | users      | uid         | public       | users
 				require.Equal("users", t.Name)
 				require.Equal("public", t.Schema.Name)
 				fks := []*schema.ForeignKey{
-					{Symbol: "multi_column", Table: t, OnUpdate: schema.NoAction, OnDelete: schema.Cascade, RefTable: &schema.Table{Name: "t1", Schema: t.Schema}, RefColumns: []*schema.Column{{Name: "gid"}, {Name: "xid"}}},
-					{Symbol: "self_reference", Table: t, OnUpdate: schema.NoAction, OnDelete: schema.Cascade, RefTable: t},
+					{Symbol: "multi_column", Table: t, OnUpdate: schema.NoAction, OnDelete: schema.SetNull, RefTable: &schema.Table{Name: "t1", Schema: t.Schema}, RefColumns: []*schema.Column{{Name: "gid"}, {Name: "xid"}}, Attrs: []schema.Attr{&Deferrable{}}},
+					{Symbol: "self_reference", Table: t, OnUpdate: schema.NoAction, OnDelete: schema.Cascade, RefTable: t, Attrs: []schema.Attr{&NotValid{}}},
 				}
 				columns := []*schema.Column{
 					{Name: "id", Type: &schema.ColumnType{Raw: "integer", Type: &schema.IntegerType{T: "integer"}}, ForeignKeys: fks[0:1]},
@@ -281,6 +333,7 @@ self_reference  | users      | uid         | public       | users
 				fks[0].Columns = columns[:2]
 				fks[1].Columns = columns[2:]
 				fks[1].RefColumns = columns[:1]
+				fks[0].Attrs = append(fks[0].Attrs, &OnDeleteColumns{Columns: columns[1:2]})
 				require.EqualValues(columns, t.Columns)
 				require.EqualValues(fks, t.ForeignKeys)
 			},
@@ -289,12 +342,15 @@ self_reference  | users      | uid         | public       | users
 			name: "check",
 			before: func(m mock) {
 				m.noEnums()
+				m.noDomains()
+				m.noRanges()
+				m.noComposites()
 				m.tableExists("public", "users", true)
 				m.ExpectQuery(queryColumns).
 					WithArgs("public", "users").
 					WillReturnRows(sqltest.Rows(`
-table_name |column_name | data_type | formatted | is_nullable | column_default | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | interval_type | character_set_name | collation_name | is_identity | identity_start | identity_increment |   identity_last  | identity_generation | generation_expression | comment | typtype | typelem | oid | attnum 
------------+------------+-----------+-----------+-------------+----------------+--------------------------+-------------------+--------------------+---------------+---------------+--------------------+----------------+-------------+----------------+--------------------+------------------+---------------------+-----------------------+---------+---------+---------+-----+-----
+table_name |column_name | data_type | formatted | is_nullable | column_default | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | interval_type | character_set_name | collation_name | is_identity | identity_start | identity_increment |   identity_last  | identity_generation | generation_expression | comment | typtype | typelem | oid | attnum | identity_minimum | identity_maximum | identity_cache | identity_cycle | compression
+-----------+------------+-----------+-----------+-------------+----------------+--------------------------+-------------------+--------------------+---------------+---------------+--------------------+----------------+-------------+----------------+--------------------+------------------+---------------------+-----------------------+---------+---------+---------+-----+-----+------------------+------------------+----------------+---------------
 users      | c1         | integer   | int4      | NO          |                |                          |                32 |                    |             0 |               |                    |                | NO          |                |                    |                  |                     |                       |         | b       |         |  23 | 
 users      | c2         | integer   | int4      | NO          |                |                          |                32 |                    |             0 |               |                    |                | NO          |                |                    |                  |                     |                       |         | b       |         |  23 | 
 users      | c3         | integer   | int4      | NO          |                |                          |                32 |                    |             0 |               |                    |                | NO          |                |                    |                  |                     |                       |         | b       |         |  23 | 
@@ -314,6 +370,24 @@ users        | users_check        | ((c2 + c1) > 2)         | c1          | {2,1
 users        | users_check1       | (((c2 + c1) + c3) > 10) | c2          | {2,1,3}        | f
 users        | users_check1       | (((c2 + c1) + c3) > 10) | c1          | {2,1,3}        | f
 users        | users_check1       | (((c2 + c1) + c3) > 10) | c3          | {2,1,3}        | f
+`))
+				m.ExpectQuery(queryStats).
+					WithArgs("public", "users").
+					WillReturnRows(sqltest.Rows(`
+table_name   | statistics_name | kinds   | column_name
+-------------+-----------------+---------+-------------
+users        | users_all       | d,f,m   | c1
+users        | users_all       | d,f,m   | c2
+users        | users_deps      | f       | c2
+users        | users_deps      | f       | c3
+`))
+				m.ExpectQuery(queryRules).
+					WithArgs("public", "users").
+					WillReturnRows(sqltest.Rows(`
+table_name | rule_name      | event | is_instead | definition
+-----------+----------------+-------+------------+--------------------------------------------------------------------------------------------------------------
+users      | users_log      | 3     | f          | CREATE RULE users_log AS ON INSERT TO public.users DO  INSERT INTO logs (id) VALUES (new.c1);
+users      | users_readonly | 4     | t          | CREATE RULE users_readonly AS ON DELETE TO public.users WHERE (old.c1 > 0) DO INSTEAD NOTHING;
 `))
 			},
 			expect: func(require *require.Assertions, t *schema.Table, err error) {
@@ -332,7 +406,13 @@ users        | users_check1       | (((c2 + c1) + c3) > 10) | c3          | {2,1
 					{Name: "c2", Type: &schema.ColumnType{Raw: "integer", Type: &schema.IntegerType{T: "integer"}}, Attrs: []schema.Attr{checks[1], checks[2], checks[3], checks[4]}},
 					{Name: "c3", Type: &schema.ColumnType{Raw: "integer", Type: &schema.IntegerType{T: "integer"}}, Attrs: []schema.Attr{checks[4]}},
 				}, t.Columns)
-				require.EqualValues(checks, t.Attrs)
+				require.EqualValues(checks, t.Attrs[:len(checks)])
+				require.EqualValues([]schema.Attr{
+					&Statistics{Name: "users_all", Columns: t.Columns[:2]},
+					&Statistics{Name: "users_deps", Kinds: []string{StatsDependencies}, Columns: t.Columns[1:]},
+					&Rule{Name: "users_log", Event: RuleEventInsert, Action: "INSERT INTO logs (id) VALUES (new.c1)"},
+					&Rule{Name: "users_readonly", Event: RuleEventDelete, Instead: true, Where: "(old.c1 > 0)", Action: "NOTHING"},
+				}, t.Attrs[len(checks):])
 			},
 		},
 	}
@@ -377,21 +457,24 @@ func TestDriver_InspectPartitionedTable(t *testing.T) {
  public      | nil
 `))
 	mk.noEnums()
+	mk.noDomains()
+	mk.noRanges()
+	mk.noComposites()
 	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(tablesQuery, "$1"))).
 		WithArgs("public").
 		WillReturnRows(sqltest.Rows(`
- oid   | table_schema | table_name  | comment | partition_attrs | partition_strategy |                  partition_exprs                   |                  extra                   
--------+--------------+-------------+---------+-----------------+--------------------+----------------------------------------------------+----------------------------------------------------
- 112  | public       | logs1       |         |                 |                     |                                                    |                                                    
- 113  | public       | logs2       |         | 1               | r                   |                                                    |                                                    
- 114  | public       | logs3       |         | 2 0 0           | l                   | (a + b), (a + (b * 2))                             |                              
+ oid   | table_schema | table_name  | comment | partition_attrs | partition_strategy |                  partition_exprs                   | access_method |                  extra                   
+-------+--------------+-------------+---------+-----------------+--------------------+----------------------------------------------------+---------------+----------------------------------------------------
+ 112  | public       | logs1       |         |                 |                     |                                                    | heap          |                                                    
+ 113  | public       | logs2       |         | 1               | r                   |                                                    |               |                                                    
+ 114  | public       | logs3       |         | 2 0 0           | l                   | (a + b), (a + (b * 2))                             | columnar      |                              
 
 `))
-	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(columnsQuery, "$2, $3, $4"))).
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(columnsAbove14, "$2, $3, $4"))).
 		WithArgs("public", "logs1", "logs2", "logs3").
 		WillReturnRows(sqltest.Rows(`
-table_name |column_name | data_type | formatted | is_nullable | column_default | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | interval_type | character_set_name | collation_name | is_identity | identity_start | identity_increment |   identity_last  | identity_generation | generation_expression | comment | typtype | typelem |  oid |  attnum 
------------+------------+-----------+-----------+-------------+----------------+--------------------------+-------------------+--------------------+---------------+---------------+--------------------+----------------+-------------+----------------+--------------------+------------------+---------------------+-----------------------+---------+---------+---------+------+--------
+table_name |column_name | data_type | formatted | is_nullable | column_default | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | interval_type | character_set_name | collation_name | is_identity | identity_start | identity_increment |   identity_last  | identity_generation | generation_expression | comment | typtype | typelem |  oid |  attnum | identity_minimum | identity_maximum | identity_cache | identity_cycle | compression
+-----------+------------+-----------+-----------+-------------+----------------+--------------------------+-------------------+--------------------+---------------+---------------+--------------------+----------------+-------------+----------------+--------------------+------------------+---------------------+-----------------------+---------+---------+---------+------+--------+------------------+------------------+----------------+---------------
 logs1      | c1         | integer   | integer   | NO          |                |                          |                32 |                    |             0 |               |                    |                | NO          |                |                    |                  |                     |                       |         | b       |         |   23 |  
 logs2      | c2         | integer   | integer   | NO          |                |                          |                32 |                    |             0 |               |                    |                | NO          |                |                    |                  |                     |                       |         | b       |         |   23 |  
 logs2      | c3         | integer   | integer   | NO          |                |                          |                32 |                    |             0 |               |                    |                | NO          |                |                    |                  |                     |                       |         | b       |         |   23 |  
@@ -400,10 +483,14 @@ logs3      | c5         | integer   | integer   | NO          |                |
 `))
 	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(indexesAbove15, "$2, $3, $4"))).
 		WillReturnRows(sqlmock.NewRows([]string{"table_name", "index_name", "column_name", "primary", "unique", "constraint_type", "predicate", "expression", "options", "indnullsnotdistinct"}))
-	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(fksQuery, "$2, $3, $4"))).
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(fksAbove15, "$2, $3, $4"))).
 		WillReturnRows(sqlmock.NewRows([]string{"constraint_name", "table_name", "column_name", "referenced_table_name", "referenced_column_name", "referenced_table_schema", "update_rule", "delete_rule"}))
 	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(checksQuery, "$2, $3, $4"))).
 		WillReturnRows(sqlmock.NewRows([]string{"table_name", "constraint_name", "expression", "column_name", "column_indexes"}))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(statisticsQuery, "$2, $3, $4"))).
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "statistics_name", "kinds", "column_name"}))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(rulesQuery, "$2, $3, $4"))).
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "rule_name", "event", "is_instead", "definition"}))
 	s, err := drv.InspectSchema(context.Background(), "", &schema.InspectOptions{
 		Mode: schema.InspectSchemas | schema.InspectTables | schema.InspectTypes,
 	})
@@ -424,7 +511,8 @@ logs3      | c5         | integer   | integer   | NO          |                |
 
 	t3, ok := s.Table("logs3")
 	require.True(t, ok)
-	require.Len(t, t3.Attrs, 2)
+	require.Len(t, t3.Attrs, 3)
+	require.Equal(t, &AccessMethod{V: "columnar"}, t3.Attrs[2])
 	key = t3.Attrs[1].(*Partition)
 	require.Equal(t, PartitionTypeList, key.T)
 	require.Equal(t, []*PartitionPart{
@@ -434,15 +522,142 @@ logs3      | c5         | integer   | integer   | NO          |                |
 	}, key.Parts)
 }
 
+func TestDriver_InspectInheritedTable(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("150000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= CURRENT_SCHEMA()"))).
+		WillReturnRows(sqltest.Rows(`
+ schema_name | comment 
+-------------+---------
+ public      | nil
+`))
+	mk.noEnums()
+	mk.noDomains()
+	mk.noRanges()
+	mk.noComposites()
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(tablesQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ oid   | table_schema | table_name  | comment | partition_attrs | partition_strategy | partition_exprs | access_method |                        extra
+-------+--------------+-------------+---------+-----------------+--------------------+-----------------+---------------+------------------------------------------------------
+ 112   | public       | logs        |         |                 |                    |                 | heap          | {"inherits" : null}
+ 113   | public       | logs_2023   |         |                 |                    |                 | heap          | {"inherits" : [["public","logs"],["audit","base"]]}
+`))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(columnsAbove14, "$2, $3"))).
+		WithArgs("public", "logs", "logs_2023").
+		WillReturnRows(sqltest.Rows(`
+table_name |column_name | data_type | formatted | is_nullable | column_default | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | interval_type | character_set_name | collation_name | is_identity | identity_start | identity_increment |   identity_last  | identity_generation | generation_expression | comment | typtype | typelem |  oid |  attnum | identity_minimum | identity_maximum | identity_cache | identity_cycle | compression
+-----------+------------+-----------+-----------+-------------+----------------+--------------------------+-------------------+--------------------+---------------+---------------+--------------------+----------------+-------------+----------------+--------------------+------------------+---------------------+-----------------------+---------+---------+---------+------+--------+------------------+------------------+----------------+---------------
+logs       | c1         | integer   | integer   | NO          |                |                          |                32 |                    |             0 |               |                    |                | NO          |                |                    |                  |                     |                       |         | b       |         |   23 |  
+logs_2023  | c1         | integer   | integer   | NO          |                |                          |                32 |                    |             0 |               |                    |                | NO          |                |                    |                  |                     |                       |         | b       |         |   23 |  
+`))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(indexesAbove15, "$2, $3"))).
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "index_name", "column_name", "primary", "unique", "constraint_type", "predicate", "expression", "options", "indnullsnotdistinct"}))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(fksAbove15, "$2, $3"))).
+		WillReturnRows(sqlmock.NewRows([]string{"constraint_name", "table_name", "column_name", "referenced_table_name", "referenced_column_name", "referenced_table_schema", "update_rule", "delete_rule"}))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(checksQuery, "$2, $3"))).
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "constraint_name", "expression", "column_name", "column_indexes"}))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(statisticsQuery, "$2, $3"))).
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "statistics_name", "kinds", "column_name"}))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(rulesQuery, "$2, $3"))).
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "rule_name", "event", "is_instead", "definition"}))
+	s, err := drv.InspectSchema(context.Background(), "", &schema.InspectOptions{
+		Mode: schema.InspectSchemas | schema.InspectTables | schema.InspectTypes,
+	})
+	require.NoError(t, err)
+
+	logs, ok := s.Table("logs")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{&OID{V: 112}}, logs.Attrs)
+	child, ok := s.Table("logs_2023")
+	require.True(t, ok)
+	require.Len(t, child.Attrs, 2)
+	in := child.Attrs[1].(*Inherits)
+	require.Len(t, in.T, 2)
+	// Inspected parents are linked, and others are referenced by their names.
+	require.Same(t, logs, in.T[0])
+	require.Equal(t, "base", in.T[1].Name)
+	require.Equal(t, "audit", in.T[1].Schema.Name)
+}
+
+func TestDriver_InspectTableStorage(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("150000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= CURRENT_SCHEMA()"))).
+		WillReturnRows(sqltest.Rows(`
+ schema_name | comment 
+-------------+---------
+ public      | nil
+`))
+	mk.noEnums()
+	mk.noDomains()
+	mk.noRanges()
+	mk.noComposites()
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(tablesQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ oid   | table_schema | table_name  | comment | partition_attrs | partition_strategy | partition_exprs | access_method |                        extra
+-------+--------------+-------------+---------+-----------------+--------------------+-----------------+---------------+------------------------------------------------------------------------------------------------------------------------------------
+ 112   | public       | events      |         |                 |                    |                 | heap          | {"inherits" : null, "options" : ["fillfactor=70","autovacuum_vacuum_scale_factor=0.05"], "toast_options" : ["autovacuum_enabled=false"]}
+ 113   | public       | users       |         |                 |                    |                 | heap          | {"inherits" : null, "options" : null, "toast_options" : null, "tablespace" : "fast", "unlogged" : true}
+`))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(columnsAbove14, "$2, $3"))).
+		WithArgs("public", "events", "users").
+		WillReturnRows(sqltest.Rows(`
+table_name |column_name | data_type | formatted | is_nullable | column_default | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | interval_type | character_set_name | collation_name | is_identity | identity_start | identity_increment |   identity_last  | identity_generation | generation_expression | comment | typtype | typelem |  oid |  attnum | identity_minimum | identity_maximum | identity_cache | identity_cycle | compression
+-----------+------------+-----------+-----------+-------------+----------------+--------------------------+-------------------+--------------------+---------------+---------------+--------------------+----------------+-------------+----------------+--------------------+------------------+---------------------+-----------------------+---------+---------+---------+------+--------+------------------+------------------+----------------+---------------
+events     | c1         | integer   | integer   | NO          |                |                          |                32 |                    |             0 |               |                    |                | NO          |                |                    |                  |                     |                       |         | b       |         |   23 |  
+users      | c1         | integer   | integer   | NO          |                |                          |                32 |                    |             0 |               |                    |                | NO          |                |                    |                  |                     |                       |         | b       |         |   23 |      1 |                  |                  |                | NO             | lz4
+`))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(indexesAbove15, "$2, $3"))).
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "index_name", "column_name", "primary", "unique", "constraint_type", "predicate", "expression", "options", "indnullsnotdistinct"}))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(fksAbove15, "$2, $3"))).
+		WillReturnRows(sqlmock.NewRows([]string{"constraint_name", "table_name", "column_name", "referenced_table_name", "referenced_column_name", "referenced_table_schema", "update_rule", "delete_rule"}))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(checksQuery, "$2, $3"))).
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "constraint_name", "expression", "column_name", "column_indexes"}))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(statisticsQuery, "$2, $3"))).
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "statistics_name", "kinds", "column_name"}))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(rulesQuery, "$2, $3"))).
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "rule_name", "event", "is_instead", "definition"}))
+	s, err := drv.InspectSchema(context.Background(), "", &schema.InspectOptions{
+		Mode: schema.InspectSchemas | schema.InspectTables | schema.InspectTypes,
+	})
+	require.NoError(t, err)
+
+	events, ok := s.Table("events")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{
+		&OID{V: 112},
+		&StorageParams{V: []StorageParam{
+			{Name: "fillfactor", Value: "70"},
+			{Name: "autovacuum_vacuum_scale_factor", Value: "0.05"},
+			{Name: "toast.autovacuum_enabled", Value: "false"},
+		}},
+	}, events.Attrs)
+	users, ok := s.Table("users")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{&OID{V: 113}, &Tablespace{V: "fast"}, &Unlogged{}}, users.Attrs)
+	require.Equal(t, []schema.Attr{&Compression{V: "lz4"}}, users.Columns[0].Attrs)
+	require.Empty(t, events.Columns[0].Attrs)
+}
+
 func TestDriver_InspectCRDBSchema(t *testing.T) {
 	db, m, err := sqlmock.New()
 	require.NoError(t, err)
 	mk := mock{m}
 	mk.ExpectQuery(sqltest.Escape(paramsQuery)).
 		WillReturnRows(sqltest.Rows(`
-  version       |  am  | crdb
-----------------|------|-----
- 130000         | heap | cockroach
+  version       |  am  | crdb                  | encoding | collate | timezone
+----------------|------|-----------------------|----------|---------|---------
+ 130000         | heap | CockroachDB CCL v22.2 | UTF8     | en-US   | UTC
 `))
 	drv, err := Open(db)
 	require.NoError(t, err)
@@ -457,8 +672,8 @@ func TestDriver_InspectCRDBSchema(t *testing.T) {
 	mk.ExpectQuery(queryCRDBColumns).
 		WithArgs("public", "users").
 		WillReturnRows(sqltest.Rows(`
-table_name  | column_name | data_type | formatted | is_nullable |              column_default               | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | interval_type | character_set_name | collation_name | is_identity | identity_start | identity_increment |   identity_last  |  identity_generation  | generation_expression | comment | typtype | typelem | oid | attnum 
-------------+-------------+-----------+-----------+-------------+-------------------------------------------+--------------------------+-------------------+--------------------+---------------+---------------+--------------------+----------------|-------------+----------------+--------------------+------------------+-----------------------+-----------------------+---------+---------+---------+-----+--------
+table_name  | column_name | data_type | formatted | is_nullable |              column_default               | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | interval_type | character_set_name | collation_name | is_identity | identity_start | identity_increment |   identity_last  |  identity_generation  | generation_expression | comment | typtype | typelem | oid | attnum | identity_minimum | identity_maximum | identity_cache | identity_cycle | compression
+------------+-------------+-----------+-----------+-------------+-------------------------------------------+--------------------------+-------------------+--------------------+---------------+---------------+--------------------+----------------|-------------+----------------+--------------------+------------------+-----------------------+-----------------------+---------+---------+---------+-----+--------+------------------+------------------+----------------+---------------
 users       | a           | bigint    | bigint    | NO          |                                           |                          |                64 |                    |             0 |               |                    |                | NO          |                |                    |                  |                       |                       |         | b       |         | 20  |        
 users       | b           | bigint    | bigint    | NO          |                                           |                          |                64 |                    |             0 |               |                    |                | NO          |                |                    |                  |                       |                       |         | b       |         | 20  |        
 users       | c           | bigint    | bigint    | NO          |                                           |                          |                64 |                    |             0 |               |                    |                | NO          |                |                    |                  |                       |                       |         | b       |         | 20  |        
@@ -477,12 +692,16 @@ users       | idx5       | a           | false   | false  |                 | CR
 users       | idx5       | b           | false   | false  |                 | CREATE INDEX idx5 ON defaultdb.public.serial USING btree (a ASC, b ASC, c ASC)  |           | b          |  
 users       | idx5       | c           | false   | false  |                 | CREATE INDEX idx5 ON defaultdb.public.serial USING btree (a ASC, b ASC, c ASC)  |           | c          |  
 `))
-	mk.noFKs()
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(fksQuery, "$2"))).
+		WillReturnRows(sqlmock.NewRows([]string{"constraint_name", "table_name", "column_name", "referenced_table_name", "referenced_column_name", "referenced_table_schema", "update_rule", "delete_rule"}))
 	mk.noChecks()
 	s, err := drv.InspectSchema(context.Background(), "public", &schema.InspectOptions{
 		Mode: schema.InspectSchemas | schema.InspectTables,
 	})
 	require.NoError(t, err)
+	info, ok := s.Realm.ServerInfo()
+	require.True(t, ok)
+	require.Equal(t, &schema.ServerInfo{Flavor: "cockroach", Version: "CockroachDB CCL v22.2", Charset: "UTF8", Collation: "en-US", TimeZone: "UTC"}, info)
 	tbl := s.Tables[0]
 	require.Equal(t, "users", tbl.Name)
 	columns := []*schema.Column{
@@ -528,6 +747,7 @@ func TestDriver_InspectSchema(t *testing.T) {
 	require.NoError(t, err)
 	require.EqualValues(t, func() *schema.Schema {
 		r := &schema.Realm{
+			Attrs: []schema.Attr{serverInfo},
 			Schemas: []*schema.Schema{
 				schema.New("test").SetComment("boring"),
 			},
@@ -571,12 +791,13 @@ func TestDriver_Realm(t *testing.T) {
 	require.NoError(t, err)
 	require.EqualValues(t, func() *schema.Realm {
 		r := &schema.Realm{
+			Attrs: []schema.Attr{serverInfo},
 			Schemas: []*schema.Schema{
 				{
-					Name: "test",
+					Name: "public",
 				},
 				{
-					Name: "public",
+					Name: "test",
 				},
 			},
 		}
@@ -610,12 +831,13 @@ func TestDriver_Realm(t *testing.T) {
 	require.NoError(t, err)
 	require.EqualValues(t, func() *schema.Realm {
 		r := &schema.Realm{
+			Attrs: []schema.Attr{serverInfo},
 			Schemas: []*schema.Schema{
 				{
-					Name: "test",
+					Name: "public",
 				},
 				{
-					Name: "public",
+					Name: "test",
 				},
 			},
 		}
@@ -648,6 +870,7 @@ func TestDriver_Realm(t *testing.T) {
 	require.NoError(t, err)
 	require.EqualValues(t, func() *schema.Realm {
 		r := &schema.Realm{
+			Attrs: []schema.Attr{serverInfo},
 			Schemas: []*schema.Schema{
 				{
 					Name: "test",
@@ -684,12 +907,13 @@ func TestInspectMode_InspectRealm(t *testing.T) {
 	require.NoError(t, err)
 	require.EqualValues(t, func() *schema.Realm {
 		r := &schema.Realm{
+			Attrs: []schema.Attr{serverInfo},
 			Schemas: []*schema.Schema{
 				{
-					Name: "test",
+					Name: "public",
 				},
 				{
-					Name: "public",
+					Name: "test",
 				},
 			},
 		}
@@ -699,6 +923,110 @@ func TestInspectMode_InspectRealm(t *testing.T) {
 	}(), realm)
 }
 
+func TestInspectRealm_CronJobs(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("130000")
+	mk.ExpectQuery(sqltest.Escape("SELECT current_setting('search_path'), set_config('search_path', '', false)")).
+		WillReturnRows(sqltest.Rows(`
+ current_setting | set_config
+-----------------+------------
+                 |
+`))
+	mk.ExpectQuery(sqltest.Escape(schemasQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"schema_name", "comment"}))
+	mk.ExpectQuery(sqltest.Escape(cronJobsQuery)).
+		WillReturnRows(sqltest.Rows(`
+ jobname          | schedule  | command
+------------------+-----------+---------------------------------
+ rotate_partitions | 0 3 * * * | CALL rotate_partitions()
+ vacuum_events     | 30 4 * * 0 | VACUUM ANALYZE events
+`))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	realm, err := drv.InspectRealm(context.Background(), &schema.InspectRealmOption{Mode: schema.InspectSchemas | InspectCronJobs})
+	require.NoError(t, err)
+	require.Equal(t, []schema.Object{
+		&CronJob{Name: "rotate_partitions", Schedule: "0 3 * * *", Command: "CALL rotate_partitions()"},
+		&CronJob{Name: "vacuum_events", Schedule: "30 4 * * 0", Command: "VACUUM ANALYZE events"},
+	}, realm.Objects)
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestInspectRealm_Settings(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("130000")
+	mk.ExpectQuery(sqltest.Escape("SELECT current_setting('search_path'), set_config('search_path', '', false)")).
+		WillReturnRows(sqltest.Rows(`
+ current_setting | set_config
+-----------------+------------
+                 |
+`))
+	mk.ExpectQuery(sqltest.Escape(schemasQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"schema_name", "comment"}))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(settingsQuery, nArgs(0, len(InspectedSettings))))).
+		WithArgs("DateStyle", "IntervalStyle", "TimeZone", "bytea_output", "default_table_access_method", "default_text_search_config", "lc_collate", "lc_ctype", "search_path", "server_encoding", "standard_conforming_strings").
+		WillReturnRows(sqltest.Rows(`
+            name             | setting |       source
+-----------------------------+---------+--------------------
+ TimeZone                    | UTC     | configuration file
+ standard_conforming_strings | on      | default
+`))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	realm, err := drv.InspectRealm(context.Background(), &schema.InspectRealmOption{Mode: schema.InspectSchemas | InspectSettings})
+	require.NoError(t, err)
+	set := &Settings{}
+	require.True(t, sqlx.Has(realm.Attrs, set))
+	require.Equal(t, []Setting{
+		{Name: "TimeZone", Value: "UTC", Source: "configuration file"},
+		{Name: "standard_conforming_strings", Value: "on", Source: "default"},
+	}, set.V)
+	require.NoError(t, m.ExpectationsWereMet())
+
+	require.Empty(t, set.Drift(set))
+	require.Equal(t, []string{"TimeZone", "bytea_output", "standard_conforming_strings"}, set.Drift(&Settings{
+		V: []Setting{
+			{Name: "TimeZone", Value: "Europe/Berlin", Source: "configuration file"},
+			{Name: "bytea_output", Value: "hex", Source: "default"},
+		},
+	}))
+}
+
+func TestDefaultDeps(t *testing.T) {
+	require.Equal(t, []defaultRef{
+		{schema: "foo", name: "T_C40_seq", seq: true},
+		{name: "nextval"},
+	}, defaultRefs(`nextval('foo."T_C40_seq"'::regclass)`))
+	require.Equal(t, []defaultRef{
+		{schema: "public", name: "gen_id"},
+		{name: "Fmt"},
+	}, defaultRefs(`PUBLIC.gen_id('f(x)', "Fmt"(1))`))
+
+	var (
+		pub   = schema.New("public")
+		other = schema.New("other")
+		genID = &schema.Func{Name: "gen_id", Schema: pub}
+		fmtF  = &schema.Func{Name: "Fmt", Schema: other}
+		seq   = &Sequence{Name: "users_seq", Schema: pub}
+		users = schema.NewTable("users").AddColumns(
+			schema.NewIntColumn("id", "bigint").SetDefault(&schema.RawExpr{X: "gen_id()"}),
+			schema.NewIntColumn("seq", "bigint").SetDefault(&schema.RawExpr{X: "nextval('users_seq'::regclass)"}),
+			schema.NewStringColumn("name", "text").SetDefault(&schema.RawExpr{X: `other."Fmt"(gen_id())`}),
+			schema.NewStringColumn("unknown", "text").SetDefault(&schema.RawExpr{X: "unknown()"}),
+		)
+	)
+	pub.AddTables(users).AddFuncs(genID).AddObjects(seq)
+	other.AddFuncs(fmtF)
+	defaultDeps(schema.NewRealm(pub, other))
+	require.Equal(t, []schema.Object{genID, seq, fmtF}, users.Deps)
+	require.Equal(t, []schema.Object{users}, genID.Refs)
+	require.Equal(t, []schema.Object{users}, fmtF.Refs)
+}
+
 func TestIndexOpClass_UnmarshalText(t *testing.T) {
 	var op IndexOpClass
 	require.NoError(t, op.UnmarshalText([]byte("int4_ops")))
@@ -716,38 +1044,1000 @@ type mock struct {
 	sqlmock.Sqlmock
 }
 
-func (m mock) version(version string) {
-	m.ExpectQuery(sqltest.Escape(paramsQuery)).
+// serverInfo is the server information returned by mock.version.
+var serverInfo = &schema.ServerInfo{Flavor: "postgres", Version: "13.0", Charset: "UTF8", Collation: "en_US.utf8", TimeZone: "UTC"}
+
+func TestInspectRealm_MaterializedViews(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("130000")
+	mk.ExpectQuery(sqltest.Escape("SELECT current_setting('search_path'), set_config('search_path', '', false)")).
+		WillReturnRows(sqltest.Rows(`
+ current_setting | set_config
+-----------------+------------
+                 |
+`))
+	mk.ExpectQuery(sqltest.Escape(schemasQuery)).
+		WillReturnRows(sqltest.Rows(`
+ schema_name | comment
+-------------+---------
+ public      | nil
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(matViewsQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ nspname | relname     | pg_get_viewdef                              | relispopulated | reloptions       | description
+---------+-------------+---------------------------------------------+----------------+------------------+-------------
+ public  | daily_stats | SELECT day, count(*) AS total FROM events;  | false          | {fillfactor=70}  | daily totals
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(matViewColumnsQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ nspname | relname     | attname | format_type | attnotnull
+---------+-------------+---------+-------------+------------
+ public  | daily_stats | day     | date        | false
+ public  | daily_stats | total   | bigint      | false
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(matViewIndexesQuery, "$1"))).
+		WithArgs("public").
 		WillReturnRows(sqltest.Rows(`
-  setting       |  am  | crdb
-----------------|------|-----
- ` + version + `| heap | NULL
+ nspname | relname     | relname         | indisunique | parts
+---------+-------------+-----------------+-------------+------------------
+ public  | daily_stats | daily_stats_day | true        | ["day"]
+ public  | daily_stats | daily_stats_exp | false       | ["(total * 2)"]
 `))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	realm, err := drv.InspectRealm(context.Background(), &schema.InspectRealmOption{Mode: schema.InspectSchemas | InspectMaterializedViews})
+	require.NoError(t, err)
+	require.NoError(t, m.ExpectationsWereMet())
+	require.Len(t, realm.Schemas[0].Objects, 1)
+	v := realm.Schemas[0].Objects[0].(*schema.View)
+	require.True(t, v.Materialized())
+	require.Equal(t, "daily_stats", v.Name)
+	require.Equal(t, "SELECT day, count(*) AS total FROM events;", v.Def)
+	require.Equal(t, realm.Schemas[0], v.Schema)
+	require.Equal(t, []schema.Attr{
+		&schema.Materialized{},
+		&WithData{V: false},
+		&StorageParams{V: []StorageParam{{Name: "fillfactor", Value: "70"}}},
+		&schema.Comment{Text: "daily totals"},
+	}, v.Attrs)
+	require.Len(t, v.Columns, 2)
+	require.Equal(t, &schema.ColumnType{Type: &schema.TimeType{T: "date"}, Raw: "date", Null: true}, v.Columns[0].Type)
+	require.Len(t, v.Indexes, 2)
+	require.True(t, v.Indexes[0].Unique)
+	require.Equal(t, v.Columns[0], v.Indexes[0].Parts[0].C)
+	require.Equal(t, &schema.RawExpr{X: "(total * 2)"}, v.Indexes[1].Parts[0].X)
 }
 
-func (m mock) tableExists(schema, table string, exists bool) {
-	rows := sqlmock.NewRows([]string{"oid", "table_schema", "table_name", "table_comment", "partition_attrs", "partition_strategy", "partition_exprs", "row_security"})
-	if exists {
-		rows.AddRow(nil, schema, table, nil, nil, nil, nil, nil)
-	}
-	m.ExpectQuery(queryTables).
-		WithArgs(schema).
-		WillReturnRows(rows)
+func TestInspectRealm_Sequences(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("130000")
+	mk.ExpectQuery(sqltest.Escape("SELECT current_setting('search_path'), set_config('search_path', '', false)")).
+		WillReturnRows(sqltest.Rows(`
+ current_setting | set_config
+-----------------+------------
+                 |
+`))
+	mk.ExpectQuery(sqltest.Escape(schemasQuery)).
+		WillReturnRows(sqltest.Rows(`
+ schema_name | comment
+-------------+---------
+ public      | nil
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(collationsQuery, "c.collisdeterministic", "NULL", "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqlmock.NewRows([]string{"nspname", "collname", "collprovider", "deterministic", "collcollate", "collctype", "locale", "description"}))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(sequencesQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ nspname | relname   | format_type | seqstart | seqincrement | seqmin | seqmax              | seqcache | seqcycle | relname | attname | description
+---------+-----------+-------------+----------+--------------+--------+---------------------+----------+----------+---------+---------+-------------
+ public  | order_seq | integer     | 100      | 10           | 1      | 2147483647          | 20       | true     | nil     | nil     | order numbers
+ public  | desc_seq  | bigint      | -1       | -1           | -1000  | -1                  | 1        | false    | nil     | nil     | nil
+`))
+	mk.ExpectQuery(sqltest.Escape(extensionsQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"extname", "nspname", "extversion", "comment"}))
+	mk.ExpectQuery(sqltest.Escape(eventTriggersQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"evtname", "evtevent", "evtenabled", "tags", "nspname", "proname", "comment"}))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	realm, err := drv.InspectRealm(context.Background(), &schema.InspectRealmOption{Mode: schema.InspectSchemas | schema.InspectObjects})
+	require.NoError(t, err)
+	require.NoError(t, m.ExpectationsWereMet())
+	minv := int64(-1000)
+	require.Equal(t, []schema.Object{
+		&Sequence{Name: "desc_seq", Schema: realm.Schemas[0], Type: &schema.IntegerType{T: TypeBigInt}, Start: -1, Increment: -1, Cache: 1, Min: &minv},
+		&Sequence{Name: "order_seq", Schema: realm.Schemas[0], Type: &schema.IntegerType{T: TypeInteger}, Start: 100, Increment: 10, Cache: 20, Cycle: true, Attrs: []schema.Attr{&schema.Comment{Text: "order numbers"}}},
+	}, realm.Schemas[0].Objects)
 }
 
-func (m mock) noIndexes() {
-	m.ExpectQuery(queryIndexes).
-		WillReturnRows(sqlmock.NewRows([]string{"table_name", "index_name", "column_name", "primary", "unique", "constraint_type", "predicate", "expression", "options", "indnullsnotdistinct"}))
+func TestInspectRealm_Collations(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("170000")
+	mk.ExpectQuery(sqltest.Escape("SELECT current_setting('search_path'), set_config('search_path', '', false)")).
+		WillReturnRows(sqltest.Rows(`
+ current_setting | set_config
+-----------------+------------
+                 |
+`))
+	mk.ExpectQuery(sqltest.Escape(schemasQuery)).
+		WillReturnRows(sqltest.Rows(`
+ schema_name | comment
+-------------+---------
+ public      | nil
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(collationsQuery, "c.collisdeterministic", "c.colllocale", "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ nspname | collname  | collprovider | deterministic | collcollate | collctype   | locale     | description
+---------+-----------+--------------+---------------+-------------+-------------+------------+-------------
+ public  | german    | icu          | false         | nil         | nil         | de-u-ks-l2 | case insensitive
+ public  | mixed     | libc         | true          | C           | en_US.UTF-8 | nil        | nil
+ public  | posix     | libc         | true          | POSIX       | POSIX       | nil        | nil
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(sequencesQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqlmock.NewRows([]string{"nspname", "relname", "format_type", "seqstart", "seqincrement", "seqmin", "seqmax", "seqcache", "seqcycle", "relname", "attname", "description"}))
+	mk.ExpectQuery(sqltest.Escape(extensionsQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"extname", "nspname", "extversion", "comment"}))
+	mk.ExpectQuery(sqltest.Escape(eventTriggersQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"evtname", "evtevent", "evtenabled", "tags", "nspname", "proname", "comment"}))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	realm, err := drv.InspectRealm(context.Background(), &schema.InspectRealmOption{Mode: schema.InspectSchemas | schema.InspectObjects})
+	require.NoError(t, err)
+	require.NoError(t, m.ExpectationsWereMet())
+	require.Equal(t, []schema.Object{
+		&Collation{Name: "german", Schema: realm.Schemas[0], Provider: "icu", Locale: "de-u-ks-l2", Attrs: []schema.Attr{&schema.Comment{Text: "case insensitive"}}},
+		&Collation{Name: "mixed", Schema: realm.Schemas[0], Provider: "libc", LcCollate: "C", LcCtype: "en_US.UTF-8", Deterministic: true},
+		&Collation{Name: "posix", Schema: realm.Schemas[0], Provider: "libc", Locale: "POSIX", Deterministic: true},
+	}, realm.Schemas[0].Objects)
 }
 
-func (m mock) noFKs() {
-	m.ExpectQuery(queryFKs).
-		WillReturnRows(sqlmock.NewRows([]string{"constraint_name", "table_name", "column_name", "referenced_table_name", "referenced_column_name", "referenced_table_schema", "update_rule", "delete_rule"}))
+func TestInspectRealm_EventTriggers(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("130000")
+	mk.ExpectQuery(sqltest.Escape("SELECT current_setting('search_path'), set_config('search_path', '', false)")).
+		WillReturnRows(sqltest.Rows(`
+ current_setting | set_config
+-----------------+------------
+                 |
+`))
+	mk.ExpectQuery(sqltest.Escape(schemasQuery)).
+		WillReturnRows(sqltest.Rows(`
+ schema_name | comment
+-------------+---------
+ audit       | nil
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(collationsQuery, "c.collisdeterministic", "NULL", "$1"))).
+		WithArgs("audit").
+		WillReturnRows(sqlmock.NewRows([]string{"nspname", "collname", "collprovider", "deterministic", "collcollate", "collctype", "locale", "description"}))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(sequencesQuery, "$1"))).
+		WithArgs("audit").
+		WillReturnRows(sqlmock.NewRows([]string{"nspname", "relname", "format_type", "seqstart", "seqincrement", "seqmin", "seqmax", "seqcache", "seqcycle", "relname", "attname", "description"}))
+	mk.ExpectQuery(sqltest.Escape(extensionsQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"extname", "nspname", "extversion", "comment"}))
+	mk.ExpectQuery(sqltest.Escape(eventTriggersQuery)).
+		WillReturnRows(sqltest.Rows(`
+ evtname     | evtevent        | evtenabled | tags                              | nspname | proname     | comment
+-------------+-----------------+------------+-----------------------------------+---------+-------------+-----------
+ log_ddl     | ddl_command_end | O          | ["CREATE TABLE","ALTER TABLE"]    | audit   | log_ddl     | audit DDL
+ log_drops   | sql_drop        | D          | []                                | audit   | log_drops   | nil
+ other_event | sql_drop        | O          | []                                | other   | other_event | nil
+`))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	realm, err := drv.InspectRealm(context.Background(), &schema.InspectRealmOption{Mode: schema.InspectSchemas | schema.InspectObjects})
+	require.NoError(t, err)
+	require.NoError(t, m.ExpectationsWereMet())
+	// Event triggers executing functions of schemas that were not inspected are skipped.
+	s := realm.Schemas[0]
+	require.Equal(t, []schema.Object{
+		&EventTrigger{Name: "log_ddl", Event: "ddl_command_end", Tags: []string{"CREATE TABLE", "ALTER TABLE"}, Func: &schema.Func{Name: "log_ddl", Schema: s}, Attrs: []schema.Attr{&schema.Comment{Text: "audit DDL"}}},
+		&EventTrigger{Name: "log_drops", Event: "sql_drop", Tags: []string{}, Func: &schema.Func{Name: "log_drops", Schema: s}, Attrs: []schema.Attr{&TriggerState{V: TriggerStateDisabled}}},
+	}, realm.Objects)
 }
 
-func (m mock) noChecks() {
-	m.ExpectQuery(queryChecks).
-		WillReturnRows(sqlmock.NewRows([]string{"table_name", "constraint_name", "expression", "column_name", "column_indexes"}))
+func TestInspectRealm_ForeignData(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("130000")
+	mk.ExpectQuery(sqltest.Escape("SELECT current_setting('search_path'), set_config('search_path', '', false)")).
+		WillReturnRows(sqltest.Rows(`
+ current_setting | set_config
+-----------------+------------
+                 |
+`))
+	mk.ExpectQuery(sqltest.Escape(schemasQuery)).
+		WillReturnRows(sqltest.Rows(`
+ schema_name | comment
+-------------+---------
+ public      | nil
+`))
+	mk.ExpectQuery(sqltest.Escape(foreignServersQuery)).
+		WillReturnRows(sqltest.Rows(`
+ srvname | fdwname      | srvtype | srvversion | options                                   | comment
+---------+--------------+---------+------------+-------------------------------------------+-----------
+ films   | postgres_fdw | nil     | 13         | {"host":"films.internal","dbname":"films"} | films db
+ logs    | file_fdw     | nil     | nil        | {}                                        | nil
+`))
+	mk.ExpectQuery(sqltest.Escape(userMappingsQuery)).
+		WillReturnRows(sqltest.Rows(`
+ srvname | usename | options
+---------+---------+----------------------
+ films   | PUBLIC  | {"user":"reader"}
+ films   | admin   | {"user":"admin"}
+ unknown | admin   | {}
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(foreignTablesQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ nspname | relname | srvname | options                 | description
+---------+---------+---------+-------------------------+-------------
+ public  | films   | films   | {"table_name":"films"}  | remote films
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(foreignColumnsQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ nspname | relname | attname | format_type | attnotnull
+---------+---------+---------+-------------+------------
+ public  | films   | id      | integer     | t
+ public  | films   | title   | text        | f
+`))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	realm, err := drv.InspectRealm(context.Background(), &schema.InspectRealmOption{Mode: schema.InspectSchemas | InspectForeignData})
+	require.NoError(t, err)
+	require.NoError(t, m.ExpectationsWereMet())
+	// User mappings of servers that were not inspected are skipped.
+	films := &ForeignServer{Name: "films", Wrapper: "postgres_fdw", Version: "13", Options: map[string]string{"host": "films.internal", "dbname": "films"}, Attrs: []schema.Attr{&schema.Comment{Text: "films db"}}}
+	require.Equal(t, []schema.Object{
+		films,
+		&ForeignServer{Name: "logs", Wrapper: "file_fdw", Options: map[string]string{}},
+		&UserMapping{User: "PUBLIC", Server: films, Options: map[string]string{"user": "reader"}},
+		&UserMapping{User: "admin", Server: films, Options: map[string]string{"user": "admin"}},
+	}, realm.Objects)
+	s := realm.Schemas[0]
+	require.Equal(t, []schema.Object{
+		&ForeignTable{
+			Name:   "films",
+			Schema: s,
+			Server: films,
+			Columns: []*schema.Column{
+				{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "integer"}, Raw: "integer"}},
+				{Name: "title", Type: &schema.ColumnType{Type: &schema.StringType{T: "text"}, Raw: "text", Null: true}},
+			},
+			Options: map[string]string{"table_name": "films"},
+			Attrs:   []schema.Attr{&schema.Comment{Text: "remote films"}},
+		},
+	}, s.Objects)
+}
+
+func TestInspectRealm_TextSearch(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("130000")
+	mk.ExpectQuery(sqltest.Escape("SELECT current_setting('search_path'), set_config('search_path', '', false)")).
+		WillReturnRows(sqltest.Rows(`
+ current_setting | set_config
+-----------------+------------
+                 |
+`))
+	mk.ExpectQuery(sqltest.Escape(schemasQuery)).
+		WillReturnRows(sqltest.Rows(`
+ schema_name | comment
+-------------+---------
+ public      | nil
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(tsDictsQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqlmock.NewRows([]string{"nspname", "dictname", "template", "dictinitoption", "comment"}).
+			AddRow("public", "english_nostop", "snowball", "language = 'english'", "no stop words").
+			AddRow("public", "quoted", "public.custom", "stopwords = 'it''s', accept = false", nil))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(tsConfigsQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ nspname | cfgname        | parser  | comment
+---------+----------------+---------+---------
+ public  | english_custom | default | nil
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(tsMappingsQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ nspname | cfgname        | alias     | dictionary
+---------+----------------+-----------+-----------------------
+ public  | english_custom | asciiword | public.english_nostop
+ public  | english_custom | asciiword | english_stem
+ public  | english_custom | int       | simple
+`))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	realm, err := drv.InspectRealm(context.Background(), &schema.InspectRealmOption{Mode: schema.InspectSchemas | InspectTextSearch})
+	require.NoError(t, err)
+	require.NoError(t, m.ExpectationsWereMet())
+	s := realm.Schemas[0]
+	require.Equal(t, []schema.Object{
+		&TextSearchConfig{Name: "english_custom", Schema: s, Parser: "default", Mappings: map[string][]string{
+			"asciiword": {"public.english_nostop", "english_stem"},
+			"int":       {"simple"},
+		}},
+		&TextSearchDict{Name: "english_nostop", Schema: s, Template: "snowball", Options: map[string]string{"language": "english"}, Attrs: []schema.Attr{&schema.Comment{Text: "no stop words"}}},
+		&TextSearchDict{Name: "quoted", Schema: s, Template: "public.custom", Options: map[string]string{"stopwords": "it's", "accept": "false"}},
+	}, s.Objects)
+}
+
+func TestInspectRealm_Operators(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("130000")
+	mk.ExpectQuery(sqltest.Escape("SELECT current_setting('search_path'), set_config('search_path', '', false)")).
+		WillReturnRows(sqltest.Rows(`
+ current_setting | set_config
+-----------------+------------
+                 |
+`))
+	mk.ExpectQuery(sqltest.Escape(schemasQuery)).
+		WillReturnRows(sqltest.Rows(`
+ schema_name | comment
+-------------+---------
+ public      | nil
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(operatorsQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqlmock.NewRows([]string{"nspname", "oprname", "left", "right", "function", "commutator", "negator", "restrict", "join", "hashes", "merges", "comment"}).
+			AddRow("public", "<->", "integer", "integer", "public.vec_distance", "<->", nil, nil, nil, false, false, "distance").
+			AddRow("public", "!!", nil, "integer", "public.vec_not", nil, nil, nil, nil, false, false, nil))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(opFamiliesQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ nspname | opfname    | method | comment
+---------+------------+--------+---------
+ public  | vec_family | gist   | nil
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(opClassesDefQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqlmock.NewRows([]string{"nspname", "opcname", "method", "type", "default", "family", "storage", "comment"}).
+			AddRow("public", "vec_ops", "gist", "integer", true, "vec_family", nil, "vector ops"))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(opClassMembersQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqlmock.NewRows([]string{"nspname", "opcname", "method", "kind", "number", "member", "order_by"}).
+			AddRow("public", "vec_ops", "gist", "function", 8, "public.vec_distance(integer,integer)", nil).
+			AddRow("public", "vec_ops", "gist", "operator", 15, "public.<->(integer,integer)", "integer_ops"))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	realm, err := drv.InspectRealm(context.Background(), &schema.InspectRealmOption{Mode: schema.InspectSchemas | InspectOperators})
+	require.NoError(t, err)
+	require.NoError(t, m.ExpectationsWereMet())
+	s := realm.Schemas[0]
+	require.Equal(t, []schema.Object{
+		&OpClass{
+			Name:      "vec_ops",
+			Schema:    s,
+			Method:    "gist",
+			Type:      "integer",
+			Default:   true,
+			Family:    "vec_family",
+			Operators: []*OpClassOperator{{Strategy: 15, Name: "public.<->(integer,integer)", OrderBy: "integer_ops"}},
+			Funcs:     []*OpClassFunc{{Number: 8, Name: "public.vec_distance(integer,integer)"}},
+			Attrs:     []schema.Attr{&schema.Comment{Text: "vector ops"}},
+		},
+		&OpFamily{Name: "vec_family", Schema: s, Method: "gist"},
+		&Operator{Name: "!!", Schema: s, Right: "integer", Function: "public.vec_not"},
+		&Operator{Name: "<->", Schema: s, Left: "integer", Right: "integer", Function: "public.vec_distance", Commutator: "<->", Attrs: []schema.Attr{&schema.Comment{Text: "distance"}}},
+	}, s.Objects)
+}
+
+func TestInspectRealm_Publications(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("150000")
+	mk.ExpectQuery(sqltest.Escape("SELECT current_setting('search_path'), set_config('search_path', '', false)")).
+		WillReturnRows(sqltest.Rows(`
+ current_setting | set_config
+-----------------+------------
+                 |
+`))
+	mk.ExpectQuery(sqltest.Escape(schemasQuery)).
+		WillReturnRows(sqltest.Rows(`
+ schema_name | comment
+-------------+---------
+ public      | nil
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(publicationsQuery, "p.pubtruncate"))).
+		WillReturnRows(sqlmock.NewRows([]string{"pubname", "puballtables", "pubinsert", "pubupdate", "pubdelete", "pubtruncate", "comment"}).
+			AddRow("all", true, true, true, true, true, nil).
+			AddRow("logs", false, true, true, true, true, nil).
+			AddRow("public", false, true, true, true, true, nil).
+			AddRow("users", false, true, true, false, false, "active users"))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(publicationTablesQuery, "pr.prattrs", "pg_catalog.pg_get_expr(pr.prqual, pr.prrelid)"))).
+		WillReturnRows(sqltest.Rows(`
+ pubname | nspname | relname | columns        | filter
+---------+---------+---------+----------------+----------
+ logs    | audit   | logs    | nil            | nil
+ users   | public  | users   | ["id","name"]  | (active)
+`))
+	mk.ExpectQuery(sqltest.Escape(publicationSchemasQuery)).
+		WillReturnRows(sqltest.Rows(`
+ pubname | nspname
+---------+---------
+ public  | public
+`))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	realm, err := drv.InspectRealm(context.Background(), &schema.InspectRealmOption{Mode: schema.InspectSchemas | InspectPublications})
+	require.NoError(t, err)
+	require.NoError(t, m.ExpectationsWereMet())
+	// Publications of schemas that were not inspected are skipped.
+	s := realm.Schemas[0]
+	users := schema.NewTable("users").SetSchema(s)
+	require.Equal(t, []schema.Object{
+		&Publication{Name: "all", AllTables: true},
+		&Publication{Name: "public", Schemas: []*schema.Schema{s}},
+		&Publication{
+			Name:    "users",
+			Tables:  []*PublicationTable{{Table: users, Columns: []*schema.Column{schema.NewColumn("id"), schema.NewColumn("name")}, Where: "(active)"}},
+			Publish: []string{"insert", "update"},
+			Attrs:   []schema.Attr{&schema.Comment{Text: "active users"}},
+		},
+	}, realm.Objects)
+}
+
+func TestInspectRealm_Partitions(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("150000")
+	mk.ExpectQuery(sqltest.Escape("SELECT current_setting('search_path'), set_config('search_path', '', false)")).
+		WillReturnRows(sqltest.Rows(`
+ current_setting | set_config
+-----------------+------------
+                 |
+`))
+	mk.ExpectQuery(sqltest.Escape(schemasQuery)).
+		WillReturnRows(sqltest.Rows(`
+ schema_name | comment
+-------------+---------
+ public      | nil
+`))
+	mk.tableExists("public", "events", true)
+	mk.ExpectQuery(queryColumns).
+		WithArgs("public", "events").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "column_name", "data_type", "formatted", "is_nullable", "column_default", "character_maximum_length", "numeric_precision", "datetime_precision", "numeric_scale", "interval_type", "character_set_name", "collation_name", "is_identity", "identity_start", "identity_increment", "identity_last", "identity_generation", "generation_expression", "comment", "typtype", "typelem", "oid", "attnum", "identity_minimum", "identity_maximum", "identity_cache", "identity_cycle", "compression"}))
+	mk.noIndexes()
+	mk.noFKs()
+	mk.noChecks()
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(childPartitionsQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqlmock.NewRows([]string{"nspname", "relname", "parent_schema", "parent_name", "bound", "partition_by", "comment"}).
+			AddRow("public", "events_2023", "public", "events", "FOR VALUES FROM ('2023-01-01') TO ('2024-01-01')", "LIST (kind)", "yearly").
+			AddRow("public", "events_2023_click", "public", "events_2023", "FOR VALUES IN ('click')", nil, nil).
+			AddRow("public", "events_default", "public", "events", "DEFAULT", nil, nil).
+			AddRow("public", "logs_2023", "public", "logs", "FOR VALUES FROM (1) TO (2)", nil, nil))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	realm, err := drv.InspectRealm(context.Background(), &schema.InspectRealmOption{Mode: schema.InspectSchemas | schema.InspectTables | InspectPartitions})
+	require.NoError(t, err)
+	require.NoError(t, m.ExpectationsWereMet())
+	// Partitions of tables that were not inspected are skipped.
+	s := realm.Schemas[0]
+	events, ok := s.Table("events")
+	require.True(t, ok)
+	p2023 := &TablePartition{Name: "events_2023", Schema: s, Table: events, Bound: "FOR VALUES FROM ('2023-01-01') TO ('2024-01-01')", PartitionBy: "LIST (kind)", Attrs: []schema.Attr{&schema.Comment{Text: "yearly"}}}
+	require.Equal(t, []schema.Object{
+		p2023,
+		&TablePartition{Name: "events_2023_click", Schema: s, Parent: p2023, Bound: "FOR VALUES IN ('click')"},
+		&TablePartition{Name: "events_default", Schema: s, Table: events, Bound: "DEFAULT"},
+	}, s.Objects)
+}
+
+func TestInspectRealm_Extensions(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("130000")
+	mk.ExpectQuery(sqltest.Escape("SELECT current_setting('search_path'), set_config('search_path', '', false)")).
+		WillReturnRows(sqltest.Rows(`
+ current_setting | set_config
+-----------------+------------
+                 |
+`))
+	mk.ExpectQuery(sqltest.Escape(schemasQuery)).
+		WillReturnRows(sqltest.Rows(`
+ schema_name | comment
+-------------+---------
+ public      | nil
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(collationsQuery, "c.collisdeterministic", "NULL", "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqlmock.NewRows([]string{"nspname", "collname", "collprovider", "deterministic", "collcollate", "collctype", "locale", "description"}))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(sequencesQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqlmock.NewRows([]string{"nspname", "relname", "format_type", "seqstart", "seqincrement", "seqmin", "seqmax", "seqcache", "seqcycle", "relname", "attname", "description"}))
+	mk.ExpectQuery(sqltest.Escape(extensionsQuery)).
+		WillReturnRows(sqltest.Rows(`
+ extname | nspname    | extversion | comment
+---------+------------+------------+----------------------------------------------
+ hstore  | public     | 1.8        | data type for storing sets of (key, value) pairs
+ ltree   | public     | 1.2        | nil
+ postgis | extensions | 3.4.2      | nil
+`))
+	mk.ExpectQuery(sqltest.Escape(eventTriggersQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"evtname", "evtevent", "evtenabled", "tags", "nspname", "proname", "comment"}))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	realm, err := drv.InspectRealm(context.Background(), &schema.InspectRealmOption{Mode: schema.InspectSchemas | schema.InspectObjects})
+	require.NoError(t, err)
+	require.NoError(t, m.ExpectationsWereMet())
+	// Extensions installed in schemas that were not inspected are skipped.
+	require.Equal(t, []schema.Object{
+		&Extension{Name: "hstore", Schema: "public", Version: "1.8", Attrs: []schema.Attr{&schema.Comment{Text: "data type for storing sets of (key, value) pairs"}}},
+		&Extension{Name: "ltree", Schema: "public", Version: "1.2"},
+	}, realm.Objects)
+}
+
+func TestInspectRealm_Enums(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("130000")
+	mk.ExpectQuery(sqltest.Escape("SELECT current_setting('search_path'), set_config('search_path', '', false)")).
+		WillReturnRows(sqltest.Rows(`
+ current_setting | set_config
+-----------------+------------
+                 |
+`))
+	mk.ExpectQuery(sqltest.Escape(schemasQuery)).
+		WillReturnRows(sqltest.Rows(`
+ schema_name | comment
+-------------+---------
+ public      | nil
+`))
+	mk.ExpectQuery(queryEnums).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ schema_name | enum_id | type    | enum_value | comment
+-------------+---------+---------+------------+---------
+ public      |   16774 | mood    | sad        | current mood
+ public      |   16774 | mood    | ok         | current mood
+ public      |   16774 | mood    | happy      | current mood
+ public      |   16780 | pending | nil        | nil
+`))
+	mk.noDomains()
+	mk.noRanges()
+	mk.noComposites()
+	drv, err := Open(db)
+	require.NoError(t, err)
+	realm, err := drv.InspectRealm(context.Background(), &schema.InspectRealmOption{Mode: schema.InspectSchemas | schema.InspectTypes})
+	require.NoError(t, err)
+	require.NoError(t, m.ExpectationsWereMet())
+	s := realm.Schemas[0]
+	require.Equal(t, []schema.Object{
+		&schema.EnumType{T: "mood", Schema: s, Values: []string{"sad", "ok", "happy"}, Attrs: []schema.Attr{&schema.Comment{Text: "current mood"}}},
+		// Enum types without values are inspected as well.
+		&schema.EnumType{T: "pending", Schema: s},
+	}, s.Objects)
+}
+
+func TestInspectRealm_Domains(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("130000")
+	mk.ExpectQuery(sqltest.Escape("SELECT current_setting('search_path'), set_config('search_path', '', false)")).
+		WillReturnRows(sqltest.Rows(`
+ current_setting | set_config
+-----------------+------------
+                 |
+`))
+	mk.ExpectQuery(sqltest.Escape(schemasQuery)).
+		WillReturnRows(sqltest.Rows(`
+ schema_name | comment
+-------------+---------
+ public      | nil
+`))
+	mk.ExpectQuery(queryEnums).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ schema_name | enum_id | type | enum_value | comment
+-------------+---------+------+------------+---------
+ public      |   16774 | mood | sad        |
+ public      |   16774 | mood | happy      |
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(domainsQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ nspname | typname | format_type | typnotnull | typdefault | checks                                                 | description
+---------+---------+-------------+------------+------------+--------------------------------------------------------+-------------
+ public  | posint  | integer     | true       | 1          | [{"name": "posint_check", "def": "CHECK (VALUE > 0)"}] | positive
+ public  | status  | mood        | false      | nil        | nil                                                    | nil
+`))
+	mk.noRanges()
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(compositesQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ nspname | typname | fields                                                                                                                                                    | description
+---------+---------+-----------------------------------------------------------------------------------------------------------------------------------------------------------+-------------
+ public  | address | [{"name": "street", "type": "character varying(255)", "collation": "C"}, {"name": "zip", "type": "posint", "collation": null}, {"name": "tags", "type": "mood[]", "collation": null}] | postal address
+`))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	realm, err := drv.InspectRealm(context.Background(), &schema.InspectRealmOption{Mode: schema.InspectSchemas | schema.InspectTypes})
+	require.NoError(t, err)
+	require.NoError(t, m.ExpectationsWereMet())
+	s := realm.Schemas[0]
+	require.Len(t, s.Objects, 4)
+	d, ok := findDomain(s, "posint")
+	require.True(t, ok)
+	require.Equal(t, &DomainType{
+		T:       "posint",
+		Schema:  s,
+		Type:    &schema.IntegerType{T: TypeInteger},
+		Default: &schema.Literal{V: "1"},
+		Checks:  []*schema.Check{{Name: "posint_check", Expr: "(VALUE > 0)"}},
+		Attrs:   []schema.Attr{&schema.Comment{Text: "positive"}},
+	}, d)
+	d, ok = findDomain(s, "status")
+	require.True(t, ok)
+	require.True(t, d.Null)
+	e, ok := d.Type.(*schema.EnumType)
+	require.True(t, ok)
+	require.Equal(t, "mood", e.T)
+	require.Equal(t, []schema.Object{e}, d.Deps)
+
+	o, ok := s.Object(func(o schema.Object) bool {
+		_, ok := o.(*CompositeType)
+		return ok
+	})
+	require.True(t, ok)
+	c := o.(*CompositeType)
+	require.Equal(t, "address", c.T)
+	require.Equal(t, []schema.Attr{&schema.Comment{Text: "postal address"}}, c.Attrs)
+	require.Len(t, c.Fields, 3)
+	require.Equal(t, "street", c.Fields[0].Name)
+	require.Equal(t, &schema.StringType{T: TypeCharVar, Size: 255}, c.Fields[0].Type.Type)
+	require.Equal(t, []schema.Attr{&schema.Collation{V: "C"}}, c.Fields[0].Attrs)
+	posint, _ := findDomain(s, "posint")
+	require.Equal(t, posint, c.Fields[1].Type.Type)
+	require.Equal(t, &ArrayType{Type: e, T: "mood[]"}, c.Fields[2].Type.Type)
+	require.Equal(t, []schema.Object{posint, e}, c.Deps)
+}
+
+func TestInspectRealm_Ranges(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("130000")
+	mk.ExpectQuery(sqltest.Escape("SELECT current_setting('search_path'), set_config('search_path', '', false)")).
+		WillReturnRows(sqltest.Rows(`
+ current_setting | set_config
+-----------------+------------
+                 |
+`))
+	mk.ExpectQuery(sqltest.Escape(schemasQuery)).
+		WillReturnRows(sqltest.Rows(`
+ schema_name | comment
+-------------+---------
+ public      | nil
+`))
+	mk.ExpectQuery(queryEnums).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ schema_name | enum_id | type | enum_value | comment
+-------------+---------+------+------------+---------
+ public      |   16774 | mood | sad        |
+ public      |   16774 | mood | happy      |
+`))
+	mk.noDomains()
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(rangesQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ nspname | typname    | subtype          | collation | canonical    | subtype_diff | description
+---------+------------+------------------+-----------+--------------+--------------+-------------
+ public  | floatrange | double precision | nil       | nil          | float8mi     | float ranges
+ public  | moodrange  | mood             | nil       | nil          | nil          | nil
+ public  | textrange  | text             | C         | public.canon | nil          | nil
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(compositesQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ nspname | typname | fields                                                                                                         | description
+---------+---------+----------------------------------------------------------------------------------------------------------------+-------------
+ public  | shift   | [{"name": "hours", "type": "floatrange", "collation": null}, {"name": "moods", "type": "moodrange[]", "collation": null}] | nil
+`))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	realm, err := drv.InspectRealm(context.Background(), &schema.InspectRealmOption{Mode: schema.InspectSchemas | schema.InspectTypes})
+	require.NoError(t, err)
+	require.NoError(t, m.ExpectationsWereMet())
+	s := realm.Schemas[0]
+	require.Len(t, s.Objects, 5)
+	r1, ok := findRange(s, "floatrange")
+	require.True(t, ok)
+	require.Equal(t, &UserRangeType{
+		T:           "floatrange",
+		Schema:      s,
+		Subtype:     &schema.FloatType{T: TypeDouble},
+		SubtypeDiff: "float8mi",
+		Attrs:       []schema.Attr{&schema.Comment{Text: "float ranges"}},
+	}, r1)
+	r2, ok := findRange(s, "moodrange")
+	require.True(t, ok)
+	e, ok := r2.Subtype.(*schema.EnumType)
+	require.True(t, ok)
+	require.Equal(t, "mood", e.T)
+	require.Equal(t, []schema.Object{e}, r2.Deps)
+	r3, ok := findRange(s, "textrange")
+	require.True(t, ok)
+	require.Equal(t, &schema.StringType{T: TypeText}, r3.Subtype)
+	require.Equal(t, "C", r3.Collation)
+	require.Equal(t, "public.canon", r3.Canonical)
+
+	c, ok := findComposite(s, "shift")
+	require.True(t, ok)
+	require.Equal(t, r1, c.Fields[0].Type.Type)
+	require.Equal(t, &ArrayType{Type: r2, T: "moodrange[]"}, c.Fields[1].Type.Type)
+	require.Equal(t, []schema.Object{r1, r2}, c.Deps)
+}
+
+func TestInspect_ColumnGrants(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(columnGrantsQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ nspname | relname | attname | grantee | string_agg    | is_grantable
+---------+---------+---------+---------+---------------+--------------
+ public  | users   | email   | PUBLIC  | SELECT        | false
+ public  | users   | email   | support | SELECT,UPDATE | true
+ public  | posts   | title   | PUBLIC  | SELECT        | false
+`))
+	var (
+		users = schema.NewTable("users").AddColumns(schema.NewStringColumn("email", "text"))
+		r     = schema.NewRealm(schema.New("public").AddTables(users))
+		i     = &inspect{conn: &conn{ExecQuerier: db}}
+	)
+	require.NoError(t, i.inspectColumnGrants(context.Background(), r))
+	require.Equal(t, []schema.Attr{
+		&ColumnGrant{Grantee: "PUBLIC", Privileges: []string{"SELECT"}},
+		&ColumnGrant{Grantee: "support", Privileges: []string{"SELECT", "UPDATE"}, Grantable: true},
+	}, users.Columns[0].Attrs)
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestInspect_TableSchemaGrants(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(tableGrantsQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ nspname | relname | grantee  | string_agg           | is_grantable
+---------+---------+----------+----------------------+--------------
+ public  | users   | PUBLIC   | SELECT               | false
+ public  | users   | app      | DELETE,INSERT,UPDATE | true
+ public  | posts   | readonly | SELECT               | false
+`))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemaGrantsQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ nspname | grantee | string_agg   | is_grantable
+---------+---------+--------------+--------------
+ public  | app     | CREATE,USAGE | false
+`))
+	var (
+		users = schema.NewTable("users").AddColumns(schema.NewStringColumn("email", "text"))
+		r     = schema.NewRealm(schema.New("public").AddTables(users))
+		i     = &inspect{conn: &conn{ExecQuerier: db}}
+	)
+	require.NoError(t, i.inspectTableGrants(context.Background(), r))
+	require.NoError(t, i.inspectSchemaGrants(context.Background(), r))
+	require.Equal(t, []schema.Attr{
+		&TableGrant{Grantee: "PUBLIC", Privileges: []string{"SELECT"}},
+		&TableGrant{Grantee: "app", Privileges: []string{"DELETE", "INSERT", "UPDATE"}, Grantable: true},
+	}, users.Attrs)
+	require.Equal(t, []schema.Attr{
+		&SchemaGrant{Grantee: "app", Privileges: []string{"CREATE", "USAGE"}},
+	}, r.Schemas[0].Attrs)
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestInspectRealm_Funcs(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("130000")
+	mk.ExpectQuery(sqltest.Escape("SELECT current_setting('search_path'), set_config('search_path', '', false)")).
+		WillReturnRows(sqltest.Rows(`
+ current_setting | set_config
+-----------------+------------
+                 |
+`))
+	mk.ExpectQuery(sqltest.Escape(schemasQuery)).
+		WillReturnRows(sqltest.Rows(`
+ schema_name | comment
+-------------+---------
+ public      | nil
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(funcsQuery, "p.prokind", "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ nspname | proname | kind | args                                                                                                                               | result            | lanname | provolatile | prosrc               | description
+---------+---------+------+------------------------------------------------------------------------------------------------------------------------------------+-------------------+---------+-------------+----------------------+-------------
+ public  | add     | f    | [{"name": "a", "mode": "i", "type": "integer", "default": null}, {"name": "b", "mode": "i", "type": "integer", "default": "1"}]     | integer           | sql     | i           | SELECT a + b         | adds numbers
+ public  | audit   | f    | []                                                                                                                                 | trigger           | plpgsql | v           | BEGIN RETURN NEW; END | nil
+ public  | names   | f    | [{"name": "n", "mode": "t", "type": "text", "default": null}]                                                                      | TABLE(n text)     | sql     | s           | SELECT name FROM t   | nil
+ public  | reset   | p    | [{"name": "", "mode": "i", "type": "text", "default": null}, {"name": "n", "mode": "o", "type": "bigint", "default": null}]         | nil               | plpgsql | v           | BEGIN END            | nil
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(aggregatesQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ nspname | proname | args                                                     | state_type       | state_func         | final_func        | agginitval | proparallel | description
+---------+---------+----------------------------------------------------------+------------------+--------------------+-------------------+------------+-------------+----------------
+ public  | rows    | []                                                       | bigint           | int8inc            | nil               | 0          | u           | nil
+ public  | sumsq   | [{"name": "", "mode": "i", "type": "double precision"}] | double precision | public.sumsq_sfunc | public.sumsq_ffunc | nil        | s           | sum of squares
+`))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	realm, err := drv.InspectRealm(context.Background(), &schema.InspectRealmOption{Mode: schema.InspectSchemas | schema.InspectFuncs})
+	require.NoError(t, err)
+	require.NoError(t, m.ExpectationsWereMet())
+	s, ok := realm.Schema("public")
+	require.True(t, ok)
+	require.Len(t, s.Funcs, 3)
+	require.Equal(t, &schema.Func{
+		Name:   "add",
+		Schema: s,
+		Args: []*schema.FuncArg{
+			{Name: "a", Type: &schema.IntegerType{T: "integer"}},
+			{Name: "b", Type: &schema.IntegerType{T: "integer"}, Default: &schema.Literal{V: "1"}},
+		},
+		Ret:   &schema.IntegerType{T: "integer"},
+		Lang:  "sql",
+		Body:  "SELECT a + b",
+		Attrs: []schema.Attr{&FuncVolatility{V: VolatilityImmutable}, &schema.Comment{Text: "adds numbers"}},
+	}, s.Funcs[0])
+	require.Equal(t, &PseudoType{T: "trigger"}, s.Funcs[1].Ret)
+	require.Empty(t, s.Funcs[1].Attrs)
+	require.Empty(t, s.Funcs[2].Args)
+	require.Equal(t, &UserDefinedType{T: "TABLE(n text)"}, s.Funcs[2].Ret)
+	require.Equal(t, []schema.Attr{&FuncVolatility{V: VolatilityStable}}, s.Funcs[2].Attrs)
+	require.Len(t, s.Procs, 1)
+	require.Equal(t, []*schema.FuncArg{
+		{Type: &schema.StringType{T: "text"}},
+		{Name: "n", Type: &schema.IntegerType{T: "bigint"}, Mode: schema.FuncArgModeOut},
+	}, s.Procs[0].Args)
+	require.Equal(t, "plpgsql", s.Procs[0].Lang)
+	require.Len(t, s.Objects, 2)
+	zero := "0"
+	require.Equal(t, &Aggregate{
+		Name:      "rows",
+		Schema:    s,
+		Args:      []*schema.FuncArg{},
+		StateType: &schema.IntegerType{T: TypeBigInt},
+		StateFunc: "int8inc",
+		InitCond:  &zero,
+	}, s.Objects[0])
+	require.Equal(t, &Aggregate{
+		Name:      "sumsq",
+		Schema:    s,
+		Args:      []*schema.FuncArg{{Type: &schema.FloatType{T: TypeDouble}}},
+		StateType: &schema.FloatType{T: TypeDouble},
+		StateFunc: "public.sumsq_sfunc",
+		FinalFunc: "public.sumsq_ffunc",
+		Parallel:  ParallelSafe,
+		Attrs:     []schema.Attr{&schema.Comment{Text: "sum of squares"}},
+	}, s.Objects[1])
+}
+
+func TestInspect_Triggers(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(triggersQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ nspname | relname | tgname   | tgtype | tgenabled | columns  | definition
+---------+---------+----------+--------+-----------+----------+---------------------------------------------------------------------------------------------------------------------------------------------------
+ public  | users   | audit    | 29     | O         | []       | CREATE TRIGGER audit AFTER INSERT OR DELETE OR UPDATE ON public.users FOR EACH ROW EXECUTE FUNCTION audit()
+ public  | users   | touch    | 19     | D         | ["name"] | CREATE TRIGGER touch BEFORE UPDATE OF name ON public.users FOR EACH ROW WHEN ((new.name IS NOT NULL)) EXECUTE FUNCTION touch('users', 'name')
+ public  | users   | truncate | 32     | A         | []       | CREATE TRIGGER truncate AFTER TRUNCATE ON public.users FOR EACH STATEMENT EXECUTE PROCEDURE prevent()
+ public  | logs_p1 | audit    | 5      | O         | []       | CREATE TRIGGER audit AFTER INSERT ON public.logs_p1 FOR EACH ROW EXECUTE FUNCTION audit()
+`))
+	var (
+		users = schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "integer"), schema.NewStringColumn("name", "text"))
+		r     = schema.NewRealm(schema.New("public").AddTables(users))
+		i     = &inspect{conn: &conn{ExecQuerier: db}}
+	)
+	require.NoError(t, i.inspectTableTriggers(context.Background(), r))
+	require.NoError(t, m.ExpectationsWereMet())
+	require.Equal(t, []*schema.Trigger{
+		{
+			Name: "audit", Table: users, ActionTime: schema.TriggerTimeAfter, For: schema.TriggerForRow, Body: "audit()",
+			Events: []schema.TriggerEvent{schema.TriggerEventInsert, schema.TriggerEventUpdate, schema.TriggerEventDelete},
+		},
+		{
+			Name: "touch", Table: users, ActionTime: schema.TriggerTimeBefore, For: schema.TriggerForRow, Body: "touch('users', 'name')",
+			Events: []schema.TriggerEvent{schema.TriggerEventUpdateOf(users.Columns[1])},
+			Attrs:  []schema.Attr{&TriggerWhen{X: "((new.name IS NOT NULL))"}, &TriggerState{V: TriggerStateDisabled}},
+		},
+		{
+			Name: "truncate", Table: users, ActionTime: schema.TriggerTimeAfter, For: schema.TriggerForStmt, Body: "prevent()",
+			Events: []schema.TriggerEvent{schema.TriggerEventTruncate},
+			Attrs:  []schema.Attr{&TriggerState{V: TriggerStateAlways}},
+		},
+	}, users.Triggers)
+}
+
+func (m mock) version(version string) {
+	m.ExpectQuery(sqltest.Escape(paramsQuery)).
+		WillReturnRows(sqltest.Rows(`
+  setting       |  am  | crdb | encoding | collate    | timezone
+----------------|------|------|----------|------------|---------
+ ` + version + `| heap | NULL | UTF8     | en_US.utf8 | UTC
+`))
+}
+
+func (m mock) tableExists(schema, table string, exists bool) {
+	rows := sqlmock.NewRows([]string{"oid", "table_schema", "table_name", "table_comment", "partition_attrs", "partition_strategy", "partition_exprs", "access_method", "row_security"})
+	if exists {
+		rows.AddRow(nil, schema, table, nil, nil, nil, nil, nil, nil)
+	}
+	m.ExpectQuery(queryTables).
+		WithArgs(schema).
+		WillReturnRows(rows)
+}
+
+func (m mock) noIndexes() {
+	m.ExpectQuery(queryIndexes).
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "index_name", "column_name", "primary", "unique", "constraint_type", "predicate", "expression", "options", "indnullsnotdistinct"}))
+}
+
+func (m mock) noFKs() {
+	m.ExpectQuery(queryFKs).
+		WillReturnRows(sqlmock.NewRows([]string{"constraint_name", "table_name", "column_name", "referenced_table_name", "referenced_column_name", "referenced_table_schema", "update_rule", "delete_rule"}))
+}
+
+func (m mock) noChecks() {
+	m.ExpectQuery(queryChecks).
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "constraint_name", "expression", "column_name", "column_indexes"}))
+	m.noStats()
+}
+
+func (m mock) noStats() {
+	m.ExpectQuery(queryStats).
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "statistics_name", "kinds", "column_name"}))
+	m.noRules()
+}
+
+func (m mock) noRules() {
+	m.ExpectQuery(queryRules).
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "rule_name", "event", "is_instead", "definition"}))
+}
+
+func (m mock) noDomains() {
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(domainsQuery, "$1"))).
+		WillReturnRows(sqlmock.NewRows([]string{"nspname", "typname", "format_type", "typnotnull", "typdefault", "checks", "description"}))
+}
+
+func (m mock) noRanges() {
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(rangesQuery, "$1"))).
+		WillReturnRows(sqlmock.NewRows([]string{"nspname", "typname", "subtype", "collation", "canonical", "subtype_diff", "description"}))
+}
+
+func (m mock) noComposites() {
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(compositesQuery, "$1"))).
+		WillReturnRows(sqlmock.NewRows([]string{"nspname", "typname", "fields", "description"}))
 }
 
 func (m mock) noEnums() {
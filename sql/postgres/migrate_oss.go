@@ -10,6 +10,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -17,6 +18,7 @@ import (
 	"ariga.io/atlas/sql/internal/sqlx"
 	"ariga.io/atlas/sql/migrate"
 	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlerr"
 )
 
 // DefaultPlan provides basic planning capabilities for PostgreSQL dialects.
@@ -39,9 +41,13 @@ func (p *planApply) PlanChanges(ctx context.Context, name string, changes []sche
 	for _, o := range opts {
 		o(&s.PlanOptions)
 	}
+	changes = sqlx.SkipVirtualFKs(changes)
 	if err := verifyChanges(ctx, changes); err != nil {
 		return nil, err
 	}
+	if err := s.verifyOpClasses(ctx, changes); err != nil {
+		return nil, err
+	}
 	if err := s.plan(changes); err != nil {
 		return nil, err
 	}
@@ -58,6 +64,12 @@ func (p *planApply) ApplyChanges(ctx context.Context, changes []schema.Change, o
 	return sqlx.ApplyChanges(ctx, changes, p, opts...)
 }
 
+// DependsOn implements migrate.ChangeDepender, and is used
+// to determine which statements can be applied concurrently.
+func (*planApply) DependsOn(change, other schema.Change) bool {
+	return sqlx.DependsOn(change, other, sortOptions)
+}
+
 // state represents the state of a planning. It is not part of
 // planApply so that multiple planning/applying can be called
 // in parallel.
@@ -67,6 +79,96 @@ type state struct {
 	migrate.PlanOptions
 }
 
+// verifyOpClasses ensures that operator classes defined outside the current scope
+// (e.g., by extensions) and used by created indexes exist in the target database,
+// to fail on planning with a clear diagnostic instead of failing on execution.
+func (s *state) verifyOpClasses(ctx context.Context, changes []schema.Change) error {
+	if s.ExecQuerier == nil || s.ExecQuerier == sqlx.NoRows {
+		return nil
+	}
+	var (
+		names   []string
+		used    = make(map[string]*schema.Index)
+		exts    = make(map[string]string)
+		created = make(map[string]bool)
+		add     = func(t *schema.Table, idxs ...*schema.Index) {
+			for _, idx := range idxs {
+				for _, p := range idx.Parts {
+					if op := (IndexOpClass{}); sqlx.Has(p.Attrs, &op) && strings.Contains(op.Name, ".") {
+						if _, ok := used[op.Name]; !ok {
+							names = append(names, op.Name)
+							used[op.Name] = idx
+						}
+					}
+				}
+			}
+			for _, d := range t.Deps {
+				if d, ok := d.(*OpClassDep); ok && d.Extension != "" {
+					exts[d.Name] = d.Extension
+				}
+			}
+		}
+	)
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddObject:
+			// Operator classes that are created by the plan are skipped.
+			if o, ok := c.O.(*OpClass); ok && o.Schema != nil {
+				created[o.Schema.Name+"."+o.Name] = true
+			}
+		case *schema.AddTable:
+			add(c.T, c.T.Indexes...)
+		case *schema.ModifyTable:
+			for _, c1 := range c.Changes {
+				switch c1 := c1.(type) {
+				case *schema.AddIndex:
+					add(c.T, c1.I)
+				case *schema.ModifyIndex:
+					add(c.T, c1.To)
+				}
+			}
+		}
+	}
+	names = slices.DeleteFunc(names, func(n string) bool { return created[n] })
+	if len(names) == 0 {
+		return nil
+	}
+	args := make([]any, len(names))
+	for i := range names {
+		args[i] = names[i]
+	}
+	rows, err := s.QueryContext(ctx, fmt.Sprintf(opClassesQuery, nArgs(0, len(names))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying operator classes: %w", err)
+	}
+	defer rows.Close()
+	exist := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return fmt.Errorf("postgres: scanning operator classes: %w", err)
+		}
+		exist[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, n := range names {
+		if exist[n] {
+			continue
+		}
+		idx := used[n]
+		if ext, ok := exts[n]; ok {
+			return fmt.Errorf("postgres: operator class %q used by index %q was not found in the target database. Ensure extension %q is installed", n, idx.Name, ext)
+		}
+		return fmt.Errorf("postgres: operator class %q used by index %q was not found in the target database. Ensure schema %q exists and defines it", n, idx.Name, n[:strings.LastIndexByte(n, '.')])
+	}
+	return nil
+}
+
+// Query to check the existence of operator classes by their qualified names.
+const opClassesQuery = `SELECT n.nspname || '.' || op.opcname FROM pg_catalog.pg_opclass AS op JOIN pg_catalog.pg_namespace AS n ON n.oid = op.opcnamespace WHERE n.nspname || '.' || op.opcname IN (%s)`
+
 // Exec executes the changes on the database. An error is returned
 // if one of the operations fail, or a change is not supported.
 func (s *state) plan(changes []schema.Change) error {
@@ -83,12 +185,20 @@ func (s *state) plan(changes []schema.Change) error {
 		if planned, err = s.detachCycles(planned); err != nil {
 			return err
 		}
-		planned = s.sortChanges(planned)
+		if s.Order.Is(migrate.PlanOrderByKind) {
+			planned = sqlx.GroupByKind(planned)
+		}
+		if planned = s.sortChanges(planned); !s.NoMergeAlters {
+			planned = sqlx.MergeAlters(planned)
+		}
+		planned = sqlx.PostponeChanges(planned, s.Order)
 	}
 	for _, c := range planned {
 		switch c := c.(type) {
 		case *schema.AddTable:
 			err = s.addTable(c)
+		case *schema.CloneTable:
+			err = s.cloneTable(c)
 		case *schema.ModifyTable:
 			err = s.modifyTable(c)
 		case *schema.RenameTable:
@@ -101,13 +211,38 @@ func (s *state) plan(changes []schema.Change) error {
 			err = s.modifyObject(c)
 		case *schema.DropObject:
 			err = s.dropObject(c)
+		case *schema.AddFunc:
+			err = s.addFunc(c)
+		case *schema.DropFunc:
+			err = s.dropFunc(c)
+		case *schema.ModifyFunc:
+			err = s.modifyFunc(c)
+		case *schema.RenameFunc:
+			err = s.renameFunc(c)
+		case *schema.AddProc:
+			err = s.addProc(c)
+		case *schema.DropProc:
+			err = s.dropProc(c)
+		case *schema.ModifyProc:
+			err = s.modifyProc(c)
+		case *schema.RenameProc:
+			err = s.renameProc(c)
+		case *schema.AddTrigger:
+			err = s.addTrigger(c)
+		case *schema.DropTrigger:
+			err = s.dropTrigger(c)
+		case *schema.ModifyTrigger:
+			err = s.modifyTrigger(c)
+		case *schema.RenameTrigger:
+			err = s.renameTrigger(c)
 		default:
-			err = fmt.Errorf("unsupported change %T", c)
+			err = sqlerr.Errorf(sqlerr.Unsupported, "unsupported change %T", c)
 		}
 		if err != nil {
 			return err
 		}
 	}
+	s.seqOwners(planned)
 	return nil
 }
 
@@ -126,6 +261,9 @@ func (s *state) topLevel(changes []schema.Change) ([]schema.Change, error) {
 				b.P("IF NOT EXISTS")
 			}
 			b.Ident(c.S.Name)
+			if o := (SchemaOwner{}); sqlx.Has(c.S.Attrs, &o) && o.Name != "" {
+				b.P("AUTHORIZATION").Ident(o.Name)
+			}
 			s.append(&migrate.Change{
 				Cmd:     b.String(),
 				Source:  c,
@@ -135,18 +273,33 @@ func (s *state) topLevel(changes []schema.Change) ([]schema.Change, error) {
 			if cm := (schema.Comment{}); sqlx.Has(c.S.Attrs, &cm) {
 				s.append(s.schemaComment(c, c.S, cm.Text, ""))
 			}
+			s.append(s.schemaGrants(c, c.S, nil, c.S.Attrs)...)
 		case *schema.ModifySchema:
 			for i := range c.Changes {
 				switch change := c.Changes[i].(type) {
 				// Add schema attributes to an existing schema only if
 				// it is different from the default server configuration.
 				case *schema.AddAttr:
+					if g, ok := change.A.(*SchemaGrant); ok {
+						s.append(s.schemaGrants(c, c.S, nil, []schema.Attr{g})...)
+						continue
+					}
 					a, ok := change.A.(*schema.Comment)
 					if !ok {
 						return nil, fmt.Errorf("unexpected schema AddAttr: %T", change.A)
 					}
 					s.append(s.schemaComment(c, c.S, a.Text, ""))
+				case *schema.DropAttr:
+					g, ok := change.A.(*SchemaGrant)
+					if !ok {
+						return nil, fmt.Errorf("unsupported ModifySchema change: %T", change)
+					}
+					s.append(s.schemaGrants(c, c.S, []schema.Attr{g}, nil)...)
 				case *schema.ModifyAttr:
+					if from, ok := change.From.(*SchemaGrant); ok {
+						s.append(s.schemaGrants(c, c.S, []schema.Attr{from}, []schema.Attr{change.To})...)
+						continue
+					}
 					to, ok1 := change.To.(*schema.Comment)
 					from, ok2 := change.From.(*schema.Comment)
 					if !ok1 || !ok2 {
@@ -191,8 +344,12 @@ func (s *state) topLevel(changes []schema.Change) ([]schema.Change, error) {
 func (s *state) addTable(add *schema.AddTable) error {
 	var (
 		errs []string
-		b    = s.Build("CREATE TABLE")
+		b    = s.Build("CREATE")
 	)
+	if sqlx.Has(add.T.Attrs, &Unlogged{}) {
+		b.P("UNLOGGED")
+	}
+	b.P("TABLE")
 	if sqlx.Has(add.Extra, &schema.IfNotExists{}) {
 		b.P("IF NOT EXISTS")
 	}
@@ -230,6 +387,13 @@ func (s *state) addTable(add *schema.AddTable) error {
 			}
 		}
 	})
+	if parents := tableInherits(add.T); len(parents) > 0 {
+		b.P("INHERITS").Wrap(func(b *sqlx.Builder) {
+			b.MapComma(parents, func(i int, b *sqlx.Builder) {
+				b.RefTable(add.T, parents[i])
+			})
+		})
+	}
 	if p := (Partition{}); sqlx.Has(add.T.Attrs, &p) {
 		s, err := formatPartition(p)
 		if err != nil {
@@ -237,6 +401,16 @@ func (s *state) addTable(add *schema.AddTable) error {
 		}
 		b.P(s)
 	}
+	if am := (AccessMethod{}); sqlx.Has(add.T.Attrs, &am) && am.V != "" {
+		b.P("USING").Ident(am.V)
+	}
+	if p := (StorageParams{}); sqlx.Has(add.T.Attrs, &p) && len(p.V) > 0 {
+		b.P("WITH")
+		storageParamsClause(b, p.V)
+	}
+	if ts := tablespace(add.T.Attrs); ts != "" {
+		b.P("TABLESPACE").Ident(ts)
+	}
 	if len(errs) > 0 {
 		return fmt.Errorf("create table %q: %s", add.T.Name, strings.Join(errs, ", "))
 	}
@@ -257,10 +431,53 @@ func (s *state) addTable(add *schema.AddTable) error {
 		}
 	}
 	s.addComments(add, add.T)
+	s.append(s.tableGrants(add, add.T, nil, add.T.Attrs)...)
+	for _, c := range add.T.Columns {
+		s.append(s.columnGrants(add, add.T, c, nil, c.Attrs)...)
+	}
+	for _, st := range tableStats(add.T) {
+		s.addStatistics(add, add.T, st)
+	}
+	for _, r := range tableRules(add.T) {
+		s.addRule(add, add.T, r)
+	}
 	s.addTableAttrs(add)
 	return nil
 }
 
+// cloneTable builds the statement for creating a table with the structure of its source.
+// Indexes are excluded from the copy, as their names are generated by the database, and
+// the primary key of the table is defined explicitly instead.
+func (s *state) cloneTable(c *schema.CloneTable) error {
+	b := s.Build("CREATE")
+	// Persistence is not copied from the source table.
+	if sqlx.Has(c.T.Attrs, &Unlogged{}) {
+		b.P("UNLOGGED")
+	}
+	b.P("TABLE")
+	if sqlx.Has(c.Extra, &schema.IfNotExists{}) {
+		b.P("IF NOT EXISTS")
+	}
+	b.Table(c.T)
+	if err := b.WrapErr(func(b *sqlx.Builder) error {
+		b.P("LIKE").Table(c.Source).P("INCLUDING ALL EXCLUDING INDEXES")
+		if pk := c.T.PrimaryKey; pk != nil {
+			b.Comma().P("PRIMARY KEY")
+			return s.index(b, pk)
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("clone table %q: %w", c.T.Name, err)
+	}
+	s.append(&migrate.Change{
+		Cmd:     b.String(),
+		Source:  c,
+		Comment: fmt.Sprintf("create %q table as a clone of %q", c.T.Name, c.Source.Name),
+		Reverse: s.Build("DROP TABLE").Table(c.T).String(),
+	})
+	return nil
+}
+
 // dropTable builds and executes the query for dropping a table from a schema.
 func (s *state) dropTable(drop *schema.DropTable) error {
 	cmd := &changeGroup{}
@@ -303,14 +520,30 @@ func (s *state) dropTable(drop *schema.DropTable) error {
 // modifyTable builds the statements that bring the table into its modified state.
 func (s *state) modifyTable(modify *schema.ModifyTable) error {
 	var (
-		alter   []schema.Change
-		addI    []*schema.AddIndex
-		dropI   []*schema.DropIndex
-		changes []*migrate.Change
+		alter         []schema.Change
+		addI          []*schema.AddIndex
+		dropI         []*schema.DropIndex
+		addSt, dropSt []*Statistics
+		addR, dropR   []*Rule
+		modR          []*schema.ModifyAttr
+		changes       []*migrate.Change
 	)
 	for _, change := range skipAutoChanges(modify.Changes) {
 		switch change := change.(type) {
 		case *schema.ModifyAttr:
+			if from, ok := change.From.(*TableGrant); ok {
+				changes = append(changes, s.tableGrants(modify, modify.T, []schema.Attr{from}, []schema.Attr{change.To})...)
+				continue
+			}
+			if from, ok := change.From.(*Statistics); ok {
+				// Statistics objects cannot be altered, and therefore, are recreated.
+				dropSt, addSt = append(dropSt, from), append(addSt, change.To.(*Statistics))
+				continue
+			}
+			if _, ok := change.From.(*Rule); ok {
+				modR = append(modR, change)
+				continue
+			}
 			if _, ok := change.From.(*schema.Comment); !ok {
 				alter = append(alter, change)
 				continue
@@ -322,6 +555,23 @@ func (s *state) modifyTable(modify *schema.ModifyTable) error {
 			// Comments are not part of the ALTER command.
 			changes = append(changes, s.tableComment(modify, modify.T, to, from))
 		case *schema.AddAttr:
+			if st, ok := change.A.(*Statistics); ok {
+				addSt = append(addSt, st)
+				continue
+			}
+			if r, ok := change.A.(*Rule); ok {
+				addR = append(addR, r)
+				continue
+			}
+			switch change.A.(type) {
+			case *Inherits, *Unlogged:
+				alter = append(alter, change)
+				continue
+			}
+			if g, ok := change.A.(*TableGrant); ok {
+				changes = append(changes, s.tableGrants(modify, modify.T, nil, []schema.Attr{g})...)
+				continue
+			}
 			from, to, err := commentChange(change)
 			if err != nil {
 				return err
@@ -329,7 +579,24 @@ func (s *state) modifyTable(modify *schema.ModifyTable) error {
 			// Comments are not part of the ALTER command.
 			changes = append(changes, s.tableComment(modify, modify.T, to, from))
 		case *schema.DropAttr:
-			return fmt.Errorf("unsupported change type: %T", change)
+			if st, ok := change.A.(*Statistics); ok {
+				dropSt = append(dropSt, st)
+				continue
+			}
+			if r, ok := change.A.(*Rule); ok {
+				dropR = append(dropR, r)
+				continue
+			}
+			switch change.A.(type) {
+			case *Inherits, *Unlogged:
+				alter = append(alter, change)
+				continue
+			}
+			if g, ok := change.A.(*TableGrant); ok {
+				changes = append(changes, s.tableGrants(modify, modify.T, []schema.Attr{g}, nil)...)
+				continue
+			}
+			return sqlerr.Errorf(sqlerr.Unsupported, "unsupported change type: %T", change)
 		case *schema.AddIndex:
 			if c := (schema.Comment{}); sqlx.Has(change.I.Attrs, &c) {
 				changes = append(changes, s.indexComment(modify, modify.T, change.I, c.Text, ""))
@@ -378,6 +645,11 @@ func (s *state) modifyTable(modify *schema.ModifyTable) error {
 				alter = append(alter, addU)
 				continue
 			}
+			// Indexes are moved to another tablespace without being rebuilt.
+			if k == schema.ChangeAttr && tablespaceChanged(change) {
+				changes = append(changes, s.indexTablespace(modify, modify.T, change))
+				continue
+			}
 			// Index (or constraint) modification requires rebuilding the index.
 			_, fromU := uniqueConst(change.From.Attrs)
 			_, fromE := excludeConst(change.From.Attrs)
@@ -401,6 +673,26 @@ func (s *state) modifyTable(modify *schema.ModifyTable) error {
 				Reverse: s.Build("ALTER INDEX").SchemaResource(modify.T.Schema, change.To.Name).P("RENAME TO").Ident(change.From.Name).String(),
 			})
 		case *schema.ModifyForeignKey:
+			// A foreign key that was created with NOT VALID only needs to be validated,
+			// and changing its deferrability does not require recreating it.
+			if fromNV, toNV := sqlx.Has(change.From.Attrs, &NotValid{}), sqlx.Has(change.To.Attrs, &NotValid{}); change.Change == schema.ChangeAttr && (fromNV == toNV || fromNV && !toNV) && !onDeleteColumnsChanged(change.From.Attrs, change.To.Attrs) {
+				if fromNV && !toNV {
+					changes = append(changes, &migrate.Change{
+						Source:  change,
+						Comment: fmt.Sprintf("validate foreign key %q", change.To.Symbol),
+						Cmd:     s.Build("ALTER TABLE").Table(modify.T).P("VALIDATE CONSTRAINT").Ident(change.To.Symbol).String(),
+					})
+				}
+				if deferrableChanged(change.From.Attrs, change.To.Attrs) {
+					changes = append(changes, &migrate.Change{
+						Source:  change,
+						Comment: fmt.Sprintf("modify deferrability of foreign key %q", change.To.Symbol),
+						Cmd:     s.Build("ALTER TABLE").Table(modify.T).P("ALTER CONSTRAINT").Ident(change.To.Symbol).P(deferrableMode(change.To.Attrs)).String(),
+						Reverse: s.Build("ALTER TABLE").Table(modify.T).P("ALTER CONSTRAINT").Ident(change.From.Symbol).P(deferrableMode(change.From.Attrs)).String(),
+					})
+				}
+				continue
+			}
 			// Foreign-key modification is translated into 2 steps.
 			// Dropping the current foreign key and creating a new one.
 			alter = append(alter, &schema.DropForeignKey{
@@ -412,6 +704,7 @@ func (s *state) modifyTable(modify *schema.ModifyTable) error {
 			if c := (schema.Comment{}); sqlx.Has(change.C.Attrs, &c) {
 				changes = append(changes, s.columnComment(modify, modify.T, change.C, c.Text, ""))
 			}
+			changes = append(changes, s.columnGrants(modify, modify.T, change.C, nil, change.C.Attrs)...)
 			alter = append(alter, change)
 		case *schema.ModifyColumn:
 			k := change.Change
@@ -426,6 +719,15 @@ func (s *state) modifyTable(modify *schema.ModifyTable) error {
 					continue
 				}
 			}
+			if k.Is(schema.ChangeAttr) && grantsChanged(change.From.Attrs, change.To.Attrs) {
+				// Privileges are not part of the ALTER command.
+				changes = append(changes, s.columnGrants(modify, modify.T, change.To, change.From.Attrs, change.To.Attrs)...)
+				if !identityChanged(change.From, change.To) && compression(change.From.Attrs) == compression(change.To.Attrs) {
+					if k &= ^schema.ChangeAttr; k.Is(schema.NoChange) {
+						continue
+					}
+				}
+			}
 			alter = append(alter, &schema.ModifyColumn{To: change.To, From: change.From, Change: k, Extra: change.Extra})
 		case *schema.RenameColumn:
 			// "RENAME COLUMN" cannot be combined with other alterations.
@@ -441,6 +743,13 @@ func (s *state) modifyTable(modify *schema.ModifyTable) error {
 			alter = append(alter, change)
 		}
 	}
+	// Statistics and rules are dropped first, as they may reference dropped columns.
+	for _, st := range dropSt {
+		s.dropStatistics(modify, modify.T, st)
+	}
+	for _, r := range dropR {
+		s.dropRule(modify, modify.T, r)
+	}
 	if err := s.dropIndexes(modify, modify.T, dropI...); err != nil {
 		return err
 	}
@@ -452,10 +761,100 @@ func (s *state) modifyTable(modify *schema.ModifyTable) error {
 	if err := s.addIndexes(modify, modify.T, addI...); err != nil {
 		return err
 	}
+	for _, st := range addSt {
+		s.addStatistics(modify, modify.T, st)
+	}
+	for _, r := range addR {
+		s.addRule(modify, modify.T, r)
+	}
+	for _, m := range modR {
+		from, to := m.From.(*Rule), m.To.(*Rule)
+		s.append(&migrate.Change{
+			Source:  modify,
+			Cmd:     s.ruleCmd(modify.T, to, true),
+			Comment: fmt.Sprintf("modify rule %q of table %q", to.Name, modify.T.Name),
+			Reverse: s.ruleCmd(modify.T, from, true),
+		})
+	}
 	s.append(changes...)
 	return nil
 }
 
+// addRule appends the statement for creating the rewrite rule.
+func (s *state) addRule(src schema.Change, t *schema.Table, r *Rule) {
+	s.append(&migrate.Change{
+		Source:  src,
+		Cmd:     s.ruleCmd(t, r, false),
+		Comment: fmt.Sprintf("create rule %q on table %q", r.Name, t.Name),
+		Reverse: s.Build("DROP RULE").Ident(r.Name).P("ON").Table(t).String(),
+	})
+}
+
+// dropRule appends the statement for dropping the rewrite rule.
+func (s *state) dropRule(src schema.Change, t *schema.Table, r *Rule) {
+	s.append(&migrate.Change{
+		Source:  src,
+		Cmd:     s.Build("DROP RULE").Ident(r.Name).P("ON").Table(t).String(),
+		Comment: fmt.Sprintf("drop rule %q from table %q", r.Name, t.Name),
+		Reverse: s.ruleCmd(t, r, false),
+	})
+}
+
+// ruleCmd returns the CREATE [OR REPLACE] RULE statement of the rewrite rule.
+func (s *state) ruleCmd(t *schema.Table, r *Rule, replace bool) string {
+	b := s.Build("CREATE")
+	if replace {
+		b.P("OR REPLACE")
+	}
+	b.P("RULE").Ident(r.Name).P("AS ON", strings.ToUpper(r.Event), "TO").Table(t)
+	if r.Where != "" {
+		b.P("WHERE", sqlx.MayWrap(r.Where))
+	}
+	b.P("DO")
+	if r.Instead {
+		b.P("INSTEAD")
+	}
+	return b.P(ruleAction(r)).String()
+}
+
+// addStatistics appends the statement for creating the extended statistics object.
+func (s *state) addStatistics(src schema.Change, t *schema.Table, st *Statistics) {
+	create, drop := s.statisticsCmds(t, st)
+	s.append(&migrate.Change{
+		Source:  src,
+		Cmd:     create,
+		Comment: fmt.Sprintf("create statistics %q on table %q", st.Name, t.Name),
+		Reverse: drop,
+	})
+}
+
+// dropStatistics appends the statement for dropping the extended statistics object.
+func (s *state) dropStatistics(src schema.Change, t *schema.Table, st *Statistics) {
+	create, drop := s.statisticsCmds(t, st)
+	s.append(&migrate.Change{
+		Source:  src,
+		Cmd:     drop,
+		Comment: fmt.Sprintf("drop statistics %q from table %q", st.Name, t.Name),
+		Reverse: create,
+	})
+}
+
+// statisticsCmds returns the CREATE and DROP statements of the extended statistics object.
+func (s *state) statisticsCmds(t *schema.Table, st *Statistics) (string, string) {
+	b := s.Build("CREATE STATISTICS").SchemaResource(t.Schema, st.Name)
+	if kinds := StatsKinds(st.Kinds); len(kinds) > 0 {
+		b.Wrap(func(b *sqlx.Builder) {
+			b.MapComma(kinds, func(i int, b *sqlx.Builder) {
+				b.P(kinds[i])
+			})
+		})
+	}
+	b.P("ON").MapComma(st.Columns, func(i int, b *sqlx.Builder) {
+		b.Ident(st.Columns[i].Name)
+	})
+	return b.P("FROM").Table(t).String(), s.Build("DROP STATISTICS").SchemaResource(t.Schema, st.Name).String()
+}
+
 type (
 	// AddUniqueConstraint to the table using the given index. Note, if the index
 	// name does not match the unique constraint name, PostgreSQL implicitly renames
@@ -554,7 +953,7 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 				reverse = append(reverse, &schema.AddPrimaryKey{P: change.P})
 			case *schema.AddForeignKey:
 				s.fks(b.P("ADD"), change.F)
-				if sqlx.Has(change.Extra, &NotValid{}) {
+				if sqlx.Has(change.Extra, &NotValid{}) || sqlx.Has(change.F.Attrs, &NotValid{}) {
 					b.P("NOT VALID")
 				}
 				reverse = append(reverse, &schema.DropForeignKey{F: change.F})
@@ -596,8 +995,45 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 					From: change.To,
 					To:   change.From,
 				})
+			case *schema.AddAttr:
+				switch a := change.A.(type) {
+				case *Inherits:
+					b.P("INHERIT").MapComma(a.T, func(i int, b *sqlx.Builder) {
+						b.RefTable(t, a.T[i])
+					})
+				case *Unlogged:
+					b.P("SET UNLOGGED")
+				default:
+					return fmt.Errorf("unexpected attribute change: %T", change.A)
+				}
+				reverse = append(reverse, &schema.DropAttr{A: change.A})
+			case *schema.DropAttr:
+				switch a := change.A.(type) {
+				case *Inherits:
+					b.P("NO INHERIT").MapComma(a.T, func(i int, b *sqlx.Builder) {
+						b.RefTable(t, a.T[i])
+					})
+				case *Unlogged:
+					b.P("SET LOGGED")
+				default:
+					return fmt.Errorf("unexpected attribute change: %T", change.A)
+				}
+				reverse = append(reverse, &schema.AddAttr{A: change.A})
 			case *schema.ModifyAttr:
-				s.alterTableAttr(b, change)
+				switch to := change.To.(type) {
+				case *AccessMethod:
+					b.P("SET ACCESS METHOD").Ident(to.V)
+				case *StorageParams:
+					from, ok := change.From.(*StorageParams)
+					if !ok {
+						return fmt.Errorf("unexpected storage parameters change: %T", change.From)
+					}
+					alterStorageParams(b, from, to)
+				case *Tablespace:
+					b.P("SET TABLESPACE").Ident(tablespaceOrDefault(to.V))
+				default:
+					s.alterTableAttr(b, change)
+				}
 				reverse = append(reverse, &schema.ModifyAttr{
 					From: change.To,
 					To:   change.From,
@@ -649,14 +1085,16 @@ func (a *changeGroup) append(s *state) {
 }
 
 func (s *state) alterColumn(b *sqlx.Builder, alter *changeGroup, t *schema.Table, c *schema.ModifyColumn) error {
+	var compressed bool
 	for k := c.Change; !k.Is(schema.NoChange); {
 		b.P("ALTER COLUMN").Ident(c.To.Name)
 		switch {
-		case k.Is(schema.ChangeType):
+		// Changing the column collation requires restating its type.
+		case k.Is(schema.ChangeType) || k.Is(schema.ChangeCollate):
 			if err := s.alterType(b, alter, t, c); err != nil {
 				return err
 			}
-			k &= ^schema.ChangeType
+			k &= ^(schema.ChangeType | schema.ChangeCollate)
 		case k.Is(schema.ChangeNull) && c.To.Type.Null:
 			if t, ok := c.To.Type.Type.(*SerialType); ok {
 				return fmt.Errorf("NOT NULL constraint is required for %s column %q", t.T, c.To.Name)
@@ -672,6 +1110,11 @@ func (s *state) alterColumn(b *sqlx.Builder, alter *changeGroup, t *schema.Table
 		case k.Is(schema.ChangeDefault) && c.To.Default != nil:
 			s.columnDefault(b.P("SET"), c.To)
 			k &= ^schema.ChangeDefault
+		case k.Is(schema.ChangeAttr) && compression(c.From.Attrs) != compression(c.To.Attrs) && !compressed:
+			b.P("SET COMPRESSION", compressionOrDefault(c.To.Attrs))
+			if compressed = true; !identityChanged(c.From, c.To) {
+				k &= ^schema.ChangeAttr
+			}
 		case k.Is(schema.ChangeAttr):
 			toI, ok := identity(c.To.Attrs)
 			if !ok {
@@ -680,9 +1123,14 @@ func (s *state) alterColumn(b *sqlx.Builder, alter *changeGroup, t *schema.Table
 			// The syntax for altering identity columns is identical to sequence_options.
 			// https://www.postgresql.org/docs/current/sql-altersequence.html
 			b.P("SET GENERATED", toI.Generation, "SET START WITH", strconv.FormatInt(toI.Sequence.Start, 10), "SET INCREMENT BY", strconv.FormatInt(toI.Sequence.Increment, 10))
+			fromI, ok := identity(c.From.Attrs)
+			if !ok {
+				fromI = defaultIdentity()
+			}
+			s.identityOptions(b, c.To, fromI, toI, "SET")
 			// Skip SEQUENCE RESTART in case the "start value" is less than the "current value" in one
 			// of the states (inspected and desired), because this function is used for both UP and DOWN.
-			if fromI, ok := identity(c.From.Attrs); (!ok || fromI.Sequence.Last < toI.Sequence.Start) && toI.Sequence.Last < toI.Sequence.Start {
+			if (!ok || fromI.Sequence.Last < toI.Sequence.Start) && toI.Sequence.Last < toI.Sequence.Start {
 				b.P("RESTART")
 			}
 			k &= ^schema.ChangeAttr
@@ -819,6 +1267,112 @@ func (s *state) addComments(src schema.Change, t *schema.Table) {
 	}
 }
 
+// columnGrants returns the GRANT and REVOKE statements for
+// migrating the column privileges from one state to the other.
+func (s *state) columnGrants(src schema.Change, t *schema.Table, c *schema.Column, from, to []schema.Attr) []*migrate.Change {
+	return s.grants(src, fmt.Sprintf("column %q of table %q", c.Name, t.Name), columnGrants(from), columnGrants(to), func(b *sqlx.Builder) {
+		b.Wrap(func(b *sqlx.Builder) {
+			b.Ident(c.Name)
+		})
+		b.P("ON").Table(t)
+	})
+}
+
+// tableGrants returns the GRANT and REVOKE statements for
+// migrating the table privileges from one state to the other.
+func (s *state) tableGrants(src schema.Change, t *schema.Table, from, to []schema.Attr) []*migrate.Change {
+	return s.grants(src, fmt.Sprintf("table %q", t.Name), tableGrants(from), tableGrants(to), func(b *sqlx.Builder) {
+		b.P("ON TABLE").Table(t)
+	})
+}
+
+// schemaGrants returns the GRANT and REVOKE statements for
+// migrating the schema privileges from one state to the other.
+func (s *state) schemaGrants(src schema.Change, sc *schema.Schema, from, to []schema.Attr) []*migrate.Change {
+	return s.grants(src, fmt.Sprintf("schema %q", sc.Name), schemaGrants(from), schemaGrants(to), func(b *sqlx.Builder) {
+		b.P("ON SCHEMA").Ident(sc.Name)
+	})
+}
+
+// grants returns the GRANT and REVOKE statements for migrating the privileges of the
+// described object from g1 to g2. The "on" function writes the object the privileges
+// are granted on (e.g., "ON TABLE t") to the statement.
+func (s *state) grants(src schema.Change, desc string, g1, g2 map[string]*ColumnGrant, on func(*sqlx.Builder)) []*migrate.Change {
+	var changes []*migrate.Change
+	for _, n := range grantees(g1, g2) {
+		f, ok1 := g1[n]
+		g, ok2 := g2[n]
+		var revoke, grant []string
+		switch {
+		case !ok1:
+			grant = g.Privileges
+		case !ok2:
+			revoke = f.Privileges
+		// Changing the grant option requires re-granting the privileges.
+		case f.Grantable != g.Grantable:
+			revoke, grant = f.Privileges, g.Privileges
+		default:
+			revoke, grant = diffPrivileges(f.Privileges, g.Privileges), diffPrivileges(g.Privileges, f.Privileges)
+		}
+		if len(revoke) > 0 {
+			changes = append(changes, &migrate.Change{
+				Source:  src,
+				Comment: fmt.Sprintf("revoke privileges on %s from %q", desc, n),
+				Cmd:     s.revokeCmd(on, f, revoke),
+				Reverse: s.grantCmd(on, f, revoke),
+			})
+		}
+		if len(grant) > 0 {
+			changes = append(changes, &migrate.Change{
+				Source:  src,
+				Comment: fmt.Sprintf("grant privileges on %s to %q", desc, n),
+				Cmd:     s.grantCmd(on, g, grant),
+				Reverse: s.revokeCmd(on, g, grant),
+			})
+		}
+	}
+	return changes
+}
+
+func (s *state) grantCmd(on func(*sqlx.Builder), g *ColumnGrant, privs []string) string {
+	b := s.Build("GRANT").P(strings.Join(privs, ", "))
+	on(b)
+	b.P("TO")
+	s.grantee(b, g.Grantee)
+	if g.Grantable {
+		b.P("WITH GRANT OPTION")
+	}
+	return b.String()
+}
+
+func (s *state) revokeCmd(on func(*sqlx.Builder), g *ColumnGrant, privs []string) string {
+	b := s.Build("REVOKE").P(strings.Join(privs, ", "))
+	on(b)
+	b.P("FROM")
+	s.grantee(b, g.Grantee)
+	return b.String()
+}
+
+// grantee writes the grantee role to the builder. PUBLIC is a keyword, and therefore, not quoted.
+func (s *state) grantee(b *sqlx.Builder, name string) {
+	if strings.EqualFold(name, "PUBLIC") {
+		b.P("PUBLIC")
+	} else {
+		b.Ident(name)
+	}
+}
+
+// diffPrivileges returns the privileges in p1 that do not exist in p2.
+func diffPrivileges(p1, p2 []string) []string {
+	var d []string
+	for _, p := range p1 {
+		if !slices.Contains(p2, p) {
+			d = append(d, p)
+		}
+	}
+	return d
+}
+
 func (s *state) schemaComment(src schema.Change, sc *schema.Schema, to, from string) *migrate.Change {
 	b := s.Build("COMMENT ON SCHEMA").Ident(sc.Name).P("IS")
 	return &migrate.Change{
@@ -839,6 +1393,16 @@ func (s *state) tableComment(src schema.Change, t *schema.Table, to, from string
 	}
 }
 
+func (s *state) enumComment(src schema.Change, e *schema.EnumType, to, from string) *migrate.Change {
+	b := s.Build("COMMENT ON TYPE").P(s.enumIdent(e), "IS")
+	return &migrate.Change{
+		Cmd:     b.Clone().P(quote(to)).String(),
+		Source:  src,
+		Comment: fmt.Sprintf("set comment to enum type: %q", e.T),
+		Reverse: b.Clone().P(quote(from)).String(),
+	}
+}
+
 func (s *state) columnComment(src schema.Change, t *schema.Table, c *schema.Column, to, from string) *migrate.Change {
 	b := s.Build("COMMENT ON COLUMN").TableResource(t, c)
 	b.P("IS")
@@ -886,30 +1450,42 @@ func (s *state) alterEnum(modify *schema.ModifyObject) error {
 	if !ok1 || !ok2 {
 		return fmt.Errorf("altering objects (%T) to (%T) is not supported", modify.From, modify.To)
 	}
-	fromV := make(map[string]int, len(from.Values))
-	for i, v := range from.Values {
-		fromV[v] = i
+	renames, err := enumRenames(from, to)
+	if err != nil {
+		return err
 	}
-	toV := make(map[string]int, len(to.Values))
+	var (
+		at     int
+		name   = s.enumIdent(from)
+		values = make([]string, len(from.Values))
+		fromV  = make(map[string]int, len(from.Values))
+	)
 	for i, v := range from.Values {
-		toV[v] = i
-	}
-	for v := range fromV {
-		if _, ok := toV[v]; !ok {
-			return fmt.Errorf("dropping value %q from enum %q is not supported", v, from.T)
+		if r, ok := renames[v]; ok {
+			s.append(&migrate.Change{
+				Cmd:     s.Build("ALTER TYPE").P(name, "RENAME VALUE", quote(v), "TO", quote(r)).String(),
+				Reverse: s.Build("ALTER TYPE").P(name, "RENAME VALUE", quote(r), "TO", quote(v)).String(),
+				Comment: fmt.Sprintf("rename value of enum type %q from %q to %q", from.T, v, r),
+			})
+			v = r
 		}
+		values[i] = v
+		fromV[v] = i
 	}
-	var (
-		at   int
-		name = s.enumIdent(from)
-	)
 	for i, v := range to.Values {
-		b := s.Build("ALTER TYPE").P(name, "ADD VALUE", quote(v))
+		b := s.Build("ALTER TYPE").P(name, "ADD VALUE")
+		// Unlike the creation of types, ADD VALUE cannot be guarded by an anonymous code
+		// block before v12, as it cannot be executed in a function. The IF NOT EXISTS clause
+		// is supported since v9.3, and therefore, by all versions supported by the driver.
+		if s.IfNotExists {
+			b.P("IF NOT EXISTS")
+		}
+		b.P(quote(v))
 		switch j, ok := fromV[v]; {
 		case !ok:
-			if i == 0 && len(from.Values) > 0 {
-				b.P("BEFORE").P(quote(from.Values[0]))
-			} else if i > 0 && at != len(from.Values) {
+			if i == 0 && len(values) > 0 {
+				b.P("BEFORE").P(quote(values[0]))
+			} else if i > 0 && at != len(values) {
 				b.P("AFTER").P(quote(to.Values[i-1]))
 			}
 			s.append(&migrate.Change{
@@ -922,9 +1498,54 @@ func (s *state) alterEnum(modify *schema.ModifyObject) error {
 			return fmt.Errorf("reordering enum %q value %q is not supported", from.T, v)
 		}
 	}
+	if c1, c2 := enumComment(from), enumComment(to); c1 != c2 {
+		s.append(s.enumComment(modify, to, c2, c1))
+	}
 	return nil
 }
 
+// enumRenames returns the enum values that were renamed between the two states. A value
+// that does not exist in the desired state is considered renamed to a new value if both
+// follow the same value (or are both first). Otherwise, the value is considered dropped
+// which is not supported by PostgreSQL.
+func enumRenames(from, to *schema.EnumType) (map[string]string, error) {
+	fromV, toV := make(map[string]bool, len(from.Values)), make(map[string]bool, len(to.Values))
+	for _, v := range from.Values {
+		fromV[v] = true
+	}
+	for _, v := range to.Values {
+		toV[v] = true
+	}
+	var (
+		used    = make(map[int]bool)
+		renames = make(map[string]string)
+	)
+	for i, v := range from.Values {
+		if toV[v] {
+			continue
+		}
+		var prev string
+		if i > 0 {
+			if prev = from.Values[i-1]; renames[prev] != "" {
+				prev = renames[prev]
+			}
+		}
+		j := -1
+		for k, v2 := range to.Values {
+			if !fromV[v2] && !used[k] && (i == 0 && k == 0 || i > 0 && k > 0 && to.Values[k-1] == prev) {
+				j = k
+				break
+			}
+		}
+		if j == -1 {
+			return nil, fmt.Errorf("dropping value %q from enum %q is not supported", v, from.T)
+		}
+		used[j] = true
+		renames[v] = to.Values[j]
+	}
+	return renames, nil
+}
+
 func (s *state) addIndexes(src schema.Change, t *schema.Table, adds ...*schema.AddIndex) error {
 	for _, add := range adds {
 		b, idx := s.Build("CREATE"), add.I
@@ -972,6 +1593,10 @@ func (s *state) column(b *sqlx.Builder, c *schema.Column) error {
 		return err
 	}
 	b.Ident(c.Name).P(f)
+	// The compression method must follow the column type.
+	if v := compression(c.Attrs); v != "" {
+		b.P("COMPRESSION", v)
+	}
 	if !c.Type.Null {
 		b.P("NOT")
 	} else if t, ok := c.Type.Type.(*SerialType); ok {
@@ -988,14 +1613,17 @@ func (s *state) column(b *sqlx.Builder, c *schema.Column) error {
 	case hasI:
 		id, _ := identity(c.Attrs)
 		b.P("GENERATED", id.Generation, "AS IDENTITY")
-		if id.Sequence.Start != defaultSeqStart || id.Sequence.Increment != defaultSeqIncrement {
+		opts := s.Build()
+		if id.Sequence.Start != defaultSeqStart {
+			opts.P("START WITH", strconv.FormatInt(id.Sequence.Start, 10))
+		}
+		if id.Sequence.Increment != defaultSeqIncrement {
+			opts.P("INCREMENT BY", strconv.FormatInt(id.Sequence.Increment, 10))
+		}
+		s.identityOptions(opts, c, defaultIdentity(), id, "")
+		if o := opts.String(); o != "" {
 			b.Wrap(func(b *sqlx.Builder) {
-				if id.Sequence.Start != defaultSeqStart {
-					b.P("START WITH", strconv.FormatInt(id.Sequence.Start, 10))
-				}
-				if id.Sequence.Increment != defaultSeqIncrement {
-					b.P("INCREMENT BY", strconv.FormatInt(id.Sequence.Increment, 10))
-				}
+				b.P(o)
 			})
 		}
 	case hasX:
@@ -1006,6 +1634,46 @@ func (s *state) column(b *sqlx.Builder, c *schema.Column) error {
 	return nil
 }
 
+// compressionOrDefault returns the compression method of the column
+// attributes, or the "default" keyword if no method is set.
+func compressionOrDefault(attrs []schema.Attr) string {
+	if v := compression(attrs); v != "" {
+		return v
+	}
+	return "default"
+}
+
+// identityOptions writes the MINVALUE, MAXVALUE, CACHE and CYCLE options of the identity
+// column that differ between the two states. If set is not empty, each option is prefixed
+// with it (i.e., SET), as required by the ALTER COLUMN command.
+func (s *state) identityOptions(b *sqlx.Builder, c *schema.Column, from, to *Identity, set string) {
+	var (
+		min1, max1 = identityBounds(c, from)
+		min2, max2 = identityBounds(c, to)
+		opt        = func(o ...string) {
+			if set != "" {
+				b.P(set)
+			}
+			b.P(o...)
+		}
+	)
+	if min1 != min2 {
+		opt("MINVALUE", strconv.FormatInt(min2, 10))
+	}
+	if max1 != max2 {
+		opt("MAXVALUE", strconv.FormatInt(max2, 10))
+	}
+	if c := seqCache(to.Sequence); seqCache(from.Sequence) != c {
+		opt("CACHE", strconv.FormatInt(c, 10))
+	}
+	switch {
+	case !from.Sequence.Cycle && to.Sequence.Cycle:
+		opt("CYCLE")
+	case from.Sequence.Cycle && !to.Sequence.Cycle:
+		opt("NO CYCLE")
+	}
+}
+
 // columnDefault writes the default value of column to the builder.
 func (s *state) columnDefault(b *sqlx.Builder, c *schema.Column) {
 	if c.Default != nil {
@@ -1108,18 +1776,30 @@ func (s *state) index(b *sqlx.Builder, idx *schema.Index) error {
 	if _, ok := uniqueConst(idx.Attrs); !ok {
 		nullsNotDistinct(b, idx)
 	}
+	var params []StorageParam
 	if p, ok := indexStorageParams(idx.Attrs); ok {
+		if p.AutoSummarize {
+			params = append(params, StorageParam{Name: storageParamAutoSum, Value: "true"})
+		}
+		if p.PagesPerRange != 0 && p.PagesPerRange != defaultPagesPerRange {
+			params = append(params, StorageParam{Name: storageParamPagesRange, Value: strconv.FormatInt(p.PagesPerRange, 10)})
+		}
+	}
+	if p := (StorageParams{}); sqlx.Has(idx.Attrs, &p) {
+		params = append(params, p.V...)
+	}
+	if len(params) > 0 {
 		b.P("WITH")
-		b.Wrap(func(b *sqlx.Builder) {
-			var parts []string
-			if p.AutoSummarize {
-				parts = append(parts, "autosummarize = true")
-			}
-			if p.PagesPerRange != 0 && p.PagesPerRange != defaultPagesPerRange {
-				parts = append(parts, fmt.Sprintf("pages_per_range = %d", p.PagesPerRange))
-			}
-			b.WriteString(strings.Join(parts, ", "))
-		})
+		storageParamsClause(b, params)
+	}
+	if ts := tablespace(idx.Attrs); ts != "" {
+		// Indexes that back constraints are defined with the USING INDEX clause.
+		_, okU := uniqueConst(idx.Attrs)
+		_, okE := excludeConst(idx.Attrs)
+		if okU || okE || idx.Table != nil && idx.Table.PrimaryKey == idx {
+			b.P("USING INDEX")
+		}
+		b.P("TABLESPACE").Ident(ts)
 	}
 	if p := (IndexPredicate{}); sqlx.Has(idx.Attrs, &p) {
 		b.P("WHERE").P(p.P)
@@ -1127,6 +1807,72 @@ func (s *state) index(b *sqlx.Builder, idx *schema.Index) error {
 	return nil
 }
 
+// tablespaceChanged reports if the tablespace is the only attribute of the index that was changed.
+func tablespaceChanged(m *schema.ModifyIndex) bool {
+	from, to := schema.RemoveAttr[*Tablespace](m.From.Attrs), schema.RemoveAttr[*Tablespace](m.To.Attrs)
+	return tablespace(m.From.Attrs) != tablespace(m.To.Attrs) && !(&diff{}).IndexAttrChanged(from, to)
+}
+
+// indexTablespace returns the statement for moving the index to its desired tablespace.
+func (s *state) indexTablespace(src schema.Change, t *schema.Table, m *schema.ModifyIndex) *migrate.Change {
+	stmt := func(ts string) string {
+		return s.Build("ALTER INDEX").SchemaResource(t.Schema, m.To.Name).P("SET TABLESPACE").Ident(tablespaceOrDefault(ts)).String()
+	}
+	return &migrate.Change{
+		Source:  src,
+		Comment: fmt.Sprintf("move index %q to tablespace %q", m.To.Name, tablespaceOrDefault(tablespace(m.To.Attrs))),
+		Cmd:     stmt(tablespace(m.To.Attrs)),
+		Reverse: stmt(tablespace(m.From.Attrs)),
+	}
+}
+
+// tablespaceOrDefault returns the given tablespace, or the default one if it is empty.
+func tablespaceOrDefault(ts string) string {
+	if ts == "" {
+		return defaultTablespace
+	}
+	return ts
+}
+
+// storageParamsClause writes the given storage parameters wrapped with parentheses.
+func storageParamsClause(b *sqlx.Builder, params []StorageParam) {
+	b.Wrap(func(b *sqlx.Builder) {
+		b.MapComma(params, func(i int, b *sqlx.Builder) {
+			b.P(params[i].Name, "=", params[i].Value)
+		})
+	})
+}
+
+// alterStorageParams writes the SET and RESET clauses for migrating the
+// storage parameters of a relation from one state to the other.
+func alterStorageParams(b *sqlx.Builder, from, to *StorageParams) {
+	var set, reset []StorageParam
+	for _, p := range to.V {
+		if v, ok := from.param(p.Name); !ok || !strings.EqualFold(v, p.Value) {
+			set = append(set, p)
+		}
+	}
+	for _, p := range from.V {
+		if _, ok := to.param(p.Name); !ok {
+			reset = append(reset, p)
+		}
+	}
+	if len(set) > 0 {
+		b.P("SET")
+		storageParamsClause(b, set)
+	}
+	if len(reset) > 0 {
+		if len(set) > 0 {
+			b.Comma()
+		}
+		b.P("RESET").Wrap(func(b *sqlx.Builder) {
+			b.MapComma(reset, func(i int, b *sqlx.Builder) {
+				b.P(reset[i].Name)
+			})
+		})
+	}
+}
+
 func (s *state) fks(b *sqlx.Builder, fks ...*schema.ForeignKey) {
 	b.MapIndent(fks, func(i int, b *sqlx.Builder) {
 		fk := fks[i]
@@ -1150,10 +1896,41 @@ func (s *state) fks(b *sqlx.Builder, fks ...*schema.ForeignKey) {
 		}
 		if fk.OnDelete != "" {
 			b.P("ON DELETE", string(fk.OnDelete))
+			if c := (OnDeleteColumns{}); sqlx.Has(fk.Attrs, &c) && len(c.Columns) > 0 {
+				b.Wrap(func(b *sqlx.Builder) {
+					b.MapComma(c.Columns, func(i int, b *sqlx.Builder) {
+						b.Ident(c.Columns[i].Name)
+					})
+				})
+			}
 		}
+		deferrable(b, fk.Attrs)
 	})
 }
 
+// deferrable writes the DEFERRABLE clause of a constraint, if it was defined.
+func deferrable(b *sqlx.Builder, attrs []schema.Attr) {
+	if d := (Deferrable{}); sqlx.Has(attrs, &d) {
+		b.P("DEFERRABLE")
+		if d.InitiallyDeferred {
+			b.P("INITIALLY DEFERRED")
+		}
+	}
+}
+
+// deferrableMode returns the mode of a constraint as used by ALTER CONSTRAINT.
+func deferrableMode(attrs []schema.Attr) string {
+	d := Deferrable{}
+	switch {
+	case !sqlx.Has(attrs, &d):
+		return "NOT DEFERRABLE"
+	case d.InitiallyDeferred:
+		return "DEFERRABLE INITIALLY DEFERRED"
+	default:
+		return "DEFERRABLE INITIALLY IMMEDIATE"
+	}
+}
+
 func (s *state) constraint(b *sqlx.Builder, idx *schema.Index) error {
 	if _, isU := uniqueConst(idx.Attrs); isU {
 		return s.unique(b, idx)
@@ -1177,7 +1954,11 @@ func (s *state) unique(b *sqlx.Builder, idx *schema.Index) error {
 	// In UNIQUE constraints, the NULLS [NOT] DISTINCT
 	// clause is written before the index parts.
 	nullsNotDistinct(b, idx)
-	return s.index(b, idx)
+	if err := s.index(b, idx); err != nil {
+		return err
+	}
+	deferrable(b, idx.Attrs)
+	return nil
 }
 
 func (s *state) exclude(b *sqlx.Builder, idx *schema.Index) error {
@@ -1190,7 +1971,11 @@ func (s *state) exclude(b *sqlx.Builder, idx *schema.Index) error {
 		name = idx.Name
 	}
 	b.P("CONSTRAINT").Ident(name).P("EXCLUDE")
-	return s.index(b, idx)
+	if err := s.index(b, idx); err != nil {
+		return err
+	}
+	deferrable(b, idx.Attrs)
+	return nil
 }
 
 func (s *state) append(c ...*migrate.Change) {
@@ -1280,7 +2065,7 @@ func quote(s string) string {
 	if sqlx.IsQuoted(s, '\'') {
 		return s
 	}
-	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+	return QuoteString(s)
 }
 
 func (s *state) createDropEnum(e *schema.EnumType) (string, string) {
@@ -1296,6 +2081,30 @@ func (s *state) createDropEnum(e *schema.EnumType) (string, string) {
 		s.Build("DROP TYPE").P(name).String()
 }
 
+// DoBlock wraps the given procedural code with an anonymous code block (DO).
+// The dollar-quote tag is chosen to not collide with the code, so the block
+// is scanned (and checksummed) as a single statement by the migrate package.
+func DoBlock(code string) string {
+	tag := "$$"
+	for i := 1; strings.Contains(code, tag); i++ {
+		tag = fmt.Sprintf("$block%d$", i)
+	}
+	return fmt.Sprintf("DO %s %s %s", tag, code, tag)
+}
+
+// GuardDuplicate wraps the given statement with an anonymous code block that
+// ignores the error raised if the object already exists. It is used for creating
+// objects that do not support the IF NOT EXISTS clause, such as types and roles.
+func GuardDuplicate(stmt string) string {
+	return DoBlock(fmt.Sprintf("BEGIN %s; EXCEPTION WHEN duplicate_object THEN NULL; END", stmt))
+}
+
+// ifNotExists reports if the creation of an object should not fail if the
+// object already exists, either by the change clauses or the plan options.
+func (s *state) ifNotExists(extra []schema.Clause) bool {
+	return s.IfNotExists || sqlx.Has(extra, &schema.IfNotExists{})
+}
+
 func (s *state) enumIdent(e *schema.EnumType) string {
 	return s.typeIdent(e.Schema, e.T)
 }
@@ -1308,6 +2117,10 @@ func (s *state) compositeIdent(c *CompositeType) string {
 	return s.typeIdent(c.Schema, c.T)
 }
 
+func (s *state) rangeIdent(r *UserRangeType) string {
+	return s.typeIdent(r.Schema, r.T)
+}
+
 func (s *state) typeIdent(ns *schema.Schema, name string) string {
 	switch {
 	// In case the plan uses a specific schema qualifier.
@@ -1344,6 +2157,8 @@ func (s *state) formatType(t schema.Type) (string, error) {
 		return s.domainIdent(t), nil
 	case *CompositeType:
 		return s.compositeIdent(t), nil
+	case *UserRangeType:
+		return s.rangeIdent(t), nil
 	case *ArrayType:
 		switch t := t.Type.(type) {
 		case *schema.EnumType:
@@ -1352,6 +2167,8 @@ func (s *state) formatType(t schema.Type) (string, error) {
 			return s.domainIdent(t) + "[]", nil
 		case *CompositeType:
 			return s.compositeIdent(t) + "[]", nil
+		case *UserRangeType:
+			return s.rangeIdent(t) + "[]", nil
 		}
 	}
 	return FormatType(t)
@@ -8,10 +8,12 @@ package postgres
 
 import (
 	"context"
+	"fmt"
 	"strconv"
 	"testing"
 
 	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/internal/sqlx"
 	"ariga.io/atlas/sql/migrate"
 	"ariga.io/atlas/sql/schema"
 
@@ -30,7 +32,7 @@ func TestPlanChanges(t *testing.T) {
 		{
 			changes: []schema.Change{
 				&schema.AddSchema{S: schema.New("public").SetComment("public schema")},
-				&schema.AddSchema{S: schema.New("test"), Extra: []schema.Clause{&schema.IfNotExists{}}},
+				&schema.AddSchema{S: schema.New("test").AddAttrs(&SchemaOwner{Name: "app"}), Extra: []schema.Clause{&schema.IfNotExists{}}},
 				&schema.DropSchema{S: schema.New("test"), Extra: []schema.Clause{&schema.IfExists{}}},
 				&schema.DropSchema{S: schema.New("test"), Extra: []schema.Clause{}},
 				&schema.ModifySchema{S: schema.New("modify1").SetComment("comment"), Changes: schema.Changes{&schema.AddAttr{A: &schema.Comment{Text: "comment"}}}},
@@ -49,7 +51,7 @@ func TestPlanChanges(t *testing.T) {
 						Reverse: `COMMENT ON SCHEMA "public" IS ''`,
 					},
 					{
-						Cmd:     `CREATE SCHEMA IF NOT EXISTS "test"`,
+						Cmd:     `CREATE SCHEMA IF NOT EXISTS "test" AUTHORIZATION "app"`,
 						Reverse: `DROP SCHEMA "test" CASCADE`,
 					},
 					{
@@ -959,6 +961,40 @@ func TestPlanChanges(t *testing.T) {
 				},
 			},
 		},
+		// Identity sequence options.
+		{
+			changes: []schema.Change{
+				&schema.AddTable{
+					T: schema.NewTable("posts").AddColumns(
+						schema.NewIntColumn("id", "integer").AddAttrs(&Identity{Generation: "ALWAYS", Sequence: &Sequence{Start: 10, Min: sqlx.P[int64](10), Max: sqlx.P[int64](2147483647), Cache: 20, Cycle: true}}),
+					),
+				},
+				&schema.ModifyTable{
+					T: schema.NewTable("users"),
+					Changes: []schema.Change{
+						&schema.ModifyColumn{
+							From:   schema.NewIntColumn("id", "integer").AddAttrs(&Identity{Sequence: &Sequence{Start: 1, Last: 10, Cycle: true}}),
+							To:     schema.NewIntColumn("id", "integer").AddAttrs(&Identity{Sequence: &Sequence{Start: 1, Last: 10, Max: sqlx.P[int64](1000), Cache: 10}}),
+							Change: schema.ChangeAttr,
+						},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `CREATE TABLE "posts" ("id" integer NOT NULL GENERATED ALWAYS AS IDENTITY (START WITH 10 MINVALUE 10 CACHE 20 CYCLE))`,
+						Reverse: `DROP TABLE "posts"`,
+					},
+					{
+						Cmd:     `ALTER TABLE "users" ALTER COLUMN "id" SET GENERATED BY DEFAULT SET START WITH 1 SET INCREMENT BY 1 SET MAXVALUE 1000 SET CACHE 10 SET NO CYCLE`,
+						Reverse: `ALTER TABLE "users" ALTER COLUMN "id" SET GENERATED BY DEFAULT SET START WITH 1 SET INCREMENT BY 1 SET MAXVALUE 2147483647 SET CACHE 1 SET CYCLE`,
+					},
+				},
+			},
+		},
 		{
 			changes: []schema.Change{
 				&schema.ModifyTable{
@@ -1270,6 +1306,74 @@ func TestPlanChanges(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		// Enum value renaming.
+		{
+			changes: []schema.Change{
+				&schema.ModifyObject{
+					From: &schema.EnumType{
+						T:      "state",
+						Values: []string{"on", "off", "unknown"},
+						Schema: schema.New("public"),
+					},
+					To: &schema.EnumType{
+						T:      "state",
+						Values: []string{"enabled", "disabled", "unknown"},
+						Schema: schema.New("public"),
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `ALTER TYPE "public"."state" RENAME VALUE 'on' TO 'enabled'`, Reverse: `ALTER TYPE "public"."state" RENAME VALUE 'enabled' TO 'on'`},
+					{Cmd: `ALTER TYPE "public"."state" RENAME VALUE 'off' TO 'disabled'`, Reverse: `ALTER TYPE "public"."state" RENAME VALUE 'disabled' TO 'off'`},
+				},
+			},
+		},
+		// Enum value renaming and appending.
+		{
+			changes: []schema.Change{
+				&schema.ModifyObject{
+					From: &schema.EnumType{
+						T:      "state",
+						Values: []string{"on", "off"},
+						Schema: schema.New("public"),
+					},
+					To: &schema.EnumType{
+						T:      "state",
+						Values: []string{"on", "paused", "disabled"},
+						Schema: schema.New("public"),
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    false,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `ALTER TYPE "public"."state" RENAME VALUE 'off' TO 'paused'`, Reverse: `ALTER TYPE "public"."state" RENAME VALUE 'paused' TO 'off'`},
+					{Cmd: `ALTER TYPE "public"."state" ADD VALUE 'disabled'`},
+				},
+			},
+		},
+		// Enum value dropping in the middle.
+		{
+			changes: []schema.Change{
+				&schema.ModifyObject{
+					From: &schema.EnumType{
+						T:      "state",
+						Values: []string{"on", "off", "unknown"},
+						Schema: schema.New("public"),
+					},
+					To: &schema.EnumType{
+						T:      "state",
+						Values: []string{"on", "unknown"},
+						Schema: schema.New("public"),
+					},
+				},
+			},
+			wantErr: true,
+		},
 		// Modify column type and drop comment.
 		{
 			changes: []schema.Change{
@@ -1715,6 +1819,117 @@ func TestPlanChanges(t *testing.T) {
 				},
 			},
 		},
+		// Extended statistics.
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: schema.NewTable("users").SetSchema(schema.New("public")),
+					Changes: schema.Changes{
+						&schema.AddAttr{A: &Statistics{Name: "users_ab", Columns: []*schema.Column{schema.NewIntColumn("a", "integer"), schema.NewIntColumn("b", "integer")}}},
+						&schema.DropAttr{A: &Statistics{Name: "users_old", Kinds: []string{StatsMCV}, Columns: []*schema.Column{schema.NewIntColumn("a", "integer")}}},
+						&schema.ModifyAttr{
+							From: &Statistics{Name: "users_ba", Columns: []*schema.Column{schema.NewIntColumn("b", "integer"), schema.NewIntColumn("a", "integer")}},
+							To:   &Statistics{Name: "users_ba", Kinds: []string{StatsNDistinct, StatsDependencies}, Columns: []*schema.Column{schema.NewIntColumn("b", "integer"), schema.NewIntColumn("a", "integer")}},
+						},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `DROP STATISTICS "public"."users_old"`,
+						Reverse: `CREATE STATISTICS "public"."users_old" (mcv) ON "a" FROM "public"."users"`,
+					},
+					{
+						Cmd:     `DROP STATISTICS "public"."users_ba"`,
+						Reverse: `CREATE STATISTICS "public"."users_ba" ON "b", "a" FROM "public"."users"`,
+					},
+					{
+						Cmd:     `CREATE STATISTICS "public"."users_ab" ON "a", "b" FROM "public"."users"`,
+						Reverse: `DROP STATISTICS "public"."users_ab"`,
+					},
+					{
+						Cmd:     `CREATE STATISTICS "public"."users_ba" (dependencies, ndistinct) ON "b", "a" FROM "public"."users"`,
+						Reverse: `DROP STATISTICS "public"."users_ba"`,
+					},
+				},
+			},
+		},
+		// Rewrite rules.
+		{
+			changes: []schema.Change{
+				&schema.AddTable{
+					T: schema.NewTable("logs").SetSchema(schema.New("public")).AddColumns(schema.NewIntColumn("id", "integer")).AddAttrs(
+						&Rule{Name: "logs_readonly", Event: RuleEventUpdate, Instead: true, Action: "NOTHING"},
+					),
+				},
+				&schema.ModifyTable{
+					T: schema.NewTable("users").SetSchema(schema.New("public")),
+					Changes: schema.Changes{
+						&schema.AddAttr{A: &Rule{Name: "users_log", Event: RuleEventInsert, Action: "INSERT INTO logs (id) VALUES (new.id)"}},
+						&schema.DropAttr{A: &Rule{Name: "users_old", Event: RuleEventSelect, Instead: true, Action: "SELECT 1"}},
+						&schema.ModifyAttr{
+							From: &Rule{Name: "users_protect", Event: RuleEventDelete, Instead: true, Action: "NOTHING"},
+							To:   &Rule{Name: "users_protect", Event: RuleEventDelete, Instead: true, Where: "old.id > 0", Action: "NOTHING;"},
+						},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `CREATE TABLE "public"."logs" ("id" integer NOT NULL)`,
+						Reverse: `DROP TABLE "public"."logs"`,
+					},
+					{
+						Cmd:     `CREATE RULE "logs_readonly" AS ON UPDATE TO "public"."logs" DO INSTEAD NOTHING`,
+						Reverse: `DROP RULE "logs_readonly" ON "public"."logs"`,
+					},
+					{
+						Cmd:     `DROP RULE "users_old" ON "public"."users"`,
+						Reverse: `CREATE RULE "users_old" AS ON SELECT TO "public"."users" DO INSTEAD SELECT 1`,
+					},
+					{
+						Cmd:     `CREATE RULE "users_log" AS ON INSERT TO "public"."users" DO INSERT INTO logs (id) VALUES (new.id)`,
+						Reverse: `DROP RULE "users_log" ON "public"."users"`,
+					},
+					{
+						Cmd:     `CREATE OR REPLACE RULE "users_protect" AS ON DELETE TO "public"."users" WHERE (old.id > 0) DO INSTEAD NOTHING`,
+						Reverse: `CREATE OR REPLACE RULE "users_protect" AS ON DELETE TO "public"."users" DO INSTEAD NOTHING`,
+					},
+				},
+			},
+		},
+		// Identifiers quoted only when needed.
+		{
+			changes: []schema.Change{
+				&schema.AddTable{
+					T: schema.NewTable("Users").
+						SetSchema(schema.New("public")).
+						AddColumns(
+							schema.NewIntColumn("id", "integer"),
+							schema.NewIntColumn("order", "integer"),
+						),
+				},
+			},
+			options: []migrate.PlanOption{
+				func(o *migrate.PlanOptions) { o.Quoting = migrate.QuoteWhenNeeded },
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `CREATE TABLE public."Users" (id integer NOT NULL, "order" integer NOT NULL)`,
+						Reverse: `DROP TABLE public."Users"`,
+					},
+				},
+			},
+		},
 		// Empty sequence qualifier.
 		{
 			changes: []schema.Change{
@@ -1884,6 +2099,99 @@ func TestPlanChanges(t *testing.T) {
 	}
 }
 
+func TestPlanChanges_OpClassDeps(t *testing.T) {
+	users := schema.NewTable("users").
+		SetSchema(schema.New("public")).
+		AddColumns(schema.NewIntColumn("v", "integer"))
+	users.AddIndexes(
+		schema.NewIndex("dep_other_ns").
+			AddParts(schema.NewColumnPart(users.Columns[0]).AddAttrs(&IndexOpClass{Name: "unknown_ns.vec_ops"})),
+	)
+	users.Deps = append(users.Deps, &OpClassDep{Name: "unknown_ns.vec_ops", Extension: "vector"})
+	query := sqltest.Escape(fmt.Sprintf(opClassesQuery, "$1"))
+
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	m := mock{mk}
+	m.version("130000")
+	m.ExpectQuery(query).
+		WithArgs("unknown_ns.vec_ops").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	_, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddTable{T: users}})
+	require.EqualError(t, err, `postgres: operator class "unknown_ns.vec_ops" used by index "dep_other_ns" was not found in the target database. Ensure extension "vector" is installed`)
+
+	m.ExpectQuery(query).
+		WithArgs("unknown_ns.vec_ops").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("unknown_ns.vec_ops"))
+	plan, err := drv.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddTable{T: users}})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `CREATE INDEX "dep_other_ns" ON "public"."users" ("v" unknown_ns.vec_ops)`, plan.Changes[1].Cmd)
+
+	// Operator classes that are created by the plan are not queried, and are created before the index.
+	ops := &OpClass{Name: "vec_ops", Schema: schema.New("unknown_ns"), Method: "btree", Type: "integer", Operators: []*OpClassOperator{{Strategy: 1, Name: "<(integer,integer)"}}}
+	plan, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddTable{T: users}, &schema.AddObject{O: ops}})
+	require.NoError(t, err)
+	require.NoError(t, mk.ExpectationsWereMet())
+	require.Len(t, plan.Changes, 3)
+	require.Equal(t, `CREATE OPERATOR CLASS "unknown_ns"."vec_ops" FOR TYPE integer USING btree AS OPERATOR 1 <(integer,integer)`, plan.Changes[0].Cmd)
+
+	// No connection to the target database.
+	_, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddTable{T: users}})
+	require.NoError(t, err)
+}
+
+func TestPlanChanges_NotValidFK(t *testing.T) {
+	var (
+		users = schema.NewTable("users").
+			SetSchema(schema.New("public")).
+			AddColumns(schema.NewIntColumn("id", "integer"))
+		posts = schema.NewTable("posts").
+			SetSchema(users.Schema).
+			AddColumns(schema.NewIntColumn("author_id", "integer"))
+		from = schema.NewForeignKey("author").SetTable(posts).AddColumns(posts.Columns[0]).SetRefTable(users).AddRefColumns(users.Columns[0]).AddAttrs(&NotValid{})
+		to   = schema.NewForeignKey("author").SetTable(posts).AddColumns(posts.Columns[0]).SetRefTable(users).AddRefColumns(users.Columns[0])
+	)
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{T: posts, Changes: []schema.Change{&schema.ModifyForeignKey{From: from, To: to, Change: schema.ChangeAttr}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, `ALTER TABLE "public"."posts" VALIDATE CONSTRAINT "author"`, plan.Changes[0].Cmd)
+	require.Nil(t, plan.Changes[0].Reverse)
+
+	// Recreate the constraint as NOT VALID.
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{T: posts, Changes: []schema.Change{&schema.ModifyForeignKey{From: to, To: from, Change: schema.ChangeAttr}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, `ALTER TABLE "public"."posts" DROP CONSTRAINT "author", ADD CONSTRAINT "author" FOREIGN KEY ("author_id") REFERENCES "public"."users" ("id") NOT VALID`, plan.Changes[0].Cmd)
+}
+
+func TestPlanChanges_CronJobs(t *testing.T) {
+	var (
+		from = &CronJob{Name: "rotate", Schedule: "0 3 * * *", Command: "CALL rotate_partitions('events')"}
+		to   = &CronJob{Name: "rotate", Schedule: "0 2 * * *", Command: "CALL rotate_partitions('events')"}
+	)
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddObject{O: from},
+		&schema.ModifyObject{From: from, To: to},
+		&schema.DropObject{O: to},
+	})
+	require.NoError(t, err)
+	require.True(t, plan.Reversible)
+	require.Len(t, plan.Changes, 3)
+	require.Equal(t, `SELECT cron.schedule('rotate', '0 3 * * *', 'CALL rotate_partitions(''events'')')`, plan.Changes[0].Cmd)
+	require.Equal(t, `SELECT cron.unschedule('rotate')`, plan.Changes[0].Reverse)
+	require.Equal(t, `SELECT cron.schedule('rotate', '0 2 * * *', 'CALL rotate_partitions(''events'')')`, plan.Changes[1].Cmd)
+	require.Equal(t, `SELECT cron.schedule('rotate', '0 3 * * *', 'CALL rotate_partitions(''events'')')`, plan.Changes[1].Reverse)
+	require.Equal(t, `SELECT cron.unschedule('rotate')`, plan.Changes[2].Cmd)
+	require.Equal(t, `SELECT cron.schedule('rotate', '0 2 * * *', 'CALL rotate_partitions(''events'')')`, plan.Changes[2].Reverse)
+}
+
 func TestDefaultPlan(t *testing.T) {
 	changes, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
 		&schema.AddTable{T: schema.NewTable("t1").SetSchema(schema.New("s1")).AddColumns(schema.NewIntColumn("a", "int"))},
@@ -2011,3 +2319,1700 @@ func TestIndentedPlan(t *testing.T) {
 		})
 	}
 }
+
+func TestPlanChanges_CloneTable(t *testing.T) {
+	var (
+		r = &schema.Realm{}
+		f = `
+schema "public" {}
+table "users_history" {
+  schema = schema.public
+  clone  = table.users
+}
+table "users" {
+  schema = schema.public
+  column "id" {
+    type = int
+  }
+  column "name" {
+    type = text
+  }
+  primary_key {
+    columns = [column.id]
+  }
+  index "users_name" {
+    columns = [column.name]
+  }
+}
+`
+	)
+	require.NoError(t, EvalHCLBytes([]byte(f), r, nil))
+	s := r.Schemas[0]
+	history, ok := s.Table("users_history")
+	require.True(t, ok)
+	require.Len(t, history.Columns, 2)
+	require.Empty(t, history.Indexes)
+	require.Equal(t, "id", history.PrimaryKey.Parts[0].C.Name)
+	require.Equal(t, history, history.PrimaryKey.Parts[0].C.Indexes[0].Table)
+
+	changes, err := DefaultDiff.SchemaDiff(schema.New("public"), s)
+	require.NoError(t, err)
+	require.IsType(t, &schema.CloneTable{}, changes[0])
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 3)
+	require.Equal(t, `CREATE TABLE "public"."users" ("id" integer NOT NULL, "name" text NOT NULL, PRIMARY KEY ("id"))`, plan.Changes[0].Cmd)
+	require.Equal(t, `CREATE INDEX "users_name" ON "public"."users" ("name")`, plan.Changes[1].Cmd)
+	require.Equal(t, `CREATE TABLE "public"."users_history" (LIKE "public"."users" INCLUDING ALL EXCLUDING INDEXES, PRIMARY KEY ("id"))`, plan.Changes[2].Cmd)
+	require.Equal(t, `DROP TABLE "public"."users_history"`, plan.Changes[2].Reverse)
+
+	err = EvalHCLBytes([]byte(`
+schema "public" {}
+table "users" {
+  schema = schema.public
+  column "id" {
+    type = int
+  }
+}
+table "users_history" {
+  schema = schema.public
+  clone  = table.users
+  column "id" {
+    type = int
+  }
+}
+`), &schema.Realm{}, nil)
+	require.EqualError(t, err, `specutil: failed converting to *schema.Realm: table "users_history" is a clone and cannot define columns, keys, indexes or checks`)
+}
+
+func TestPlanChanges_EnumComment(t *testing.T) {
+	var (
+		from = schema.New("public")
+		to   = schema.New("public")
+		r    = &schema.Realm{}
+		f    = `enum "status" {
+  schema  = schema.public
+  values  = ["active", "inactive"]
+  comment = "user status"
+}
+schema "public" {
+}
+`
+	)
+	require.NoError(t, EvalHCLBytes([]byte(f), r, nil))
+	e := r.Schemas[0].Objects[0].(*schema.EnumType)
+	require.Equal(t, []schema.Attr{&schema.Comment{Text: "user status"}}, e.Attrs)
+	buf, err := MarshalHCL(r)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+
+	// Create an enum with a comment.
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddObject{O: e}})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `CREATE TYPE "public"."status" AS ENUM ('active', 'inactive')`, plan.Changes[0].Cmd)
+	require.Equal(t, `COMMENT ON TYPE "public"."status" IS 'user status'`, plan.Changes[1].Cmd)
+	require.Equal(t, `COMMENT ON TYPE "public"."status" IS ''`, plan.Changes[1].Reverse)
+
+	// Comment-only changes are detected and planned.
+	from.AddObjects(&schema.EnumType{T: "status", Values: []string{"active", "inactive"}, Schema: from})
+	to.AddObjects(&schema.EnumType{T: "status", Values: []string{"active", "inactive"}, Schema: to, Attrs: []schema.Attr{&schema.Comment{Text: "user status"}}})
+	changes, err := DefaultDiff.SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, `COMMENT ON TYPE "public"."status" IS 'user status'`, plan.Changes[0].Cmd)
+	require.Equal(t, `COMMENT ON TYPE "public"."status" IS ''`, plan.Changes[0].Reverse)
+
+	changes, err = DefaultDiff.SchemaDiff(to, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestPlanChanges_DoBlock(t *testing.T) {
+	e := &schema.EnumType{T: "status", Values: []string{"active", "inactive"}, Schema: schema.New("public")}
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddObject{O: e, Extra: []schema.Clause{&schema.IfNotExists{}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, `DO $$ BEGIN CREATE TYPE "public"."status" AS ENUM ('active', 'inactive'); EXCEPTION WHEN duplicate_object THEN NULL; END $$`, plan.Changes[0].Cmd)
+	require.Equal(t, `DROP TYPE "public"."status"`, plan.Changes[0].Reverse)
+
+	// Dollar-quoted bodies do not collide with the block tag.
+	require.Equal(t, "DO $block1$ BEGIN PERFORM $$a$$; END $block1$", DoBlock("BEGIN PERFORM $$a$$; END"))
+
+	// Blocks are scanned as single statements.
+	f := migrate.NewLocalFile("1.sql", []byte(plan.Changes[0].Cmd+";\n"+DoBlock("BEGIN PERFORM $$a;$$; END")+";\n"))
+	stmts, err := f.Stmts()
+	require.NoError(t, err)
+	require.Len(t, stmts, 2)
+	require.Equal(t, plan.Changes[0].Cmd+";", stmts[0])
+}
+
+func TestPlanChanges_IfNotExists(t *testing.T) {
+	var (
+		pub    = schema.New("public")
+		status = &schema.EnumType{T: "status", Values: []string{"active"}, Schema: pub}
+		role   = &Role{Name: "app", Login: true}
+		ifne   = func(o *migrate.PlanOptions) { o.IfNotExists = true }
+	)
+	for _, tt := range []struct {
+		name    string
+		change  schema.Change
+		cmd     string
+		reverse string
+	}{
+		{
+			name:    "create type",
+			change:  &schema.AddObject{O: status},
+			cmd:     `DO $$ BEGIN CREATE TYPE "public"."status" AS ENUM ('active'); EXCEPTION WHEN duplicate_object THEN NULL; END $$`,
+			reverse: `DROP TYPE "public"."status"`,
+		},
+		{
+			name:    "create domain",
+			change:  &schema.AddObject{O: &DomainType{T: "email", Type: &schema.StringType{T: "text"}, Null: true, Schema: pub}},
+			cmd:     `DO $$ BEGIN CREATE DOMAIN "public"."email" AS text; EXCEPTION WHEN duplicate_object THEN NULL; END $$`,
+			reverse: `DROP DOMAIN "public"."email"`,
+		},
+		{
+			name:    "create role",
+			change:  &schema.AddObject{O: role},
+			cmd:     `DO $$ BEGIN CREATE ROLE "app" LOGIN; EXCEPTION WHEN duplicate_object THEN NULL; END $$`,
+			reverse: `DROP ROLE "app"`,
+		},
+		{
+			name:    "create extension",
+			change:  &schema.AddObject{O: &Extension{Name: "hstore"}},
+			cmd:     `CREATE EXTENSION IF NOT EXISTS "hstore"`,
+			reverse: `DROP EXTENSION "hstore"`,
+		},
+		{
+			name: "add enum value",
+			change: &schema.ModifyObject{
+				From: status,
+				To:   &schema.EnumType{T: "status", Values: []string{"active", "inactive"}, Schema: pub},
+			},
+			cmd: `ALTER TYPE "public"."status" ADD VALUE IF NOT EXISTS 'inactive'`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{tt.change}, ifne)
+			require.NoError(t, err)
+			require.Len(t, plan.Changes, 1)
+			require.Equal(t, tt.cmd, plan.Changes[0].Cmd)
+			if tt.reverse != "" {
+				require.Equal(t, tt.reverse, plan.Changes[0].Reverse)
+			}
+		})
+	}
+
+	// Without the option, statements are not guarded.
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddObject{O: role},
+		&schema.ModifyObject{From: status, To: &schema.EnumType{T: "status", Values: []string{"active", "inactive"}, Schema: pub}},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `CREATE ROLE "app" LOGIN`, plan.Changes[0].Cmd)
+	require.Equal(t, `ALTER TYPE "public"."status" ADD VALUE 'inactive'`, plan.Changes[1].Cmd)
+
+	// The clause guards the creation of a single object.
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddObject{O: role, Extra: []schema.Clause{&schema.IfNotExists{}}},
+		&schema.DropObject{O: &Role{Name: "old"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, GuardDuplicate(`CREATE ROLE "app" LOGIN`), plan.Changes[0].Cmd)
+	require.Equal(t, `DROP ROLE "old"`, plan.Changes[1].Cmd)
+	require.Equal(t, `CREATE ROLE "old"`, plan.Changes[1].Reverse)
+}
+
+func TestPlanChanges_Order(t *testing.T) {
+	users := schema.NewTable("users").SetSchema(schema.New("public")).AddColumns(schema.NewIntColumn("id", "int"))
+	users.AddIndexes(schema.NewIndex("users_id").AddColumns(users.Columns[0]))
+	posts := schema.NewTable("posts").SetSchema(users.Schema).AddColumns(schema.NewIntColumn("id", "int"))
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddTable{T: users}, &schema.AddTable{T: posts}}, func(o *migrate.PlanOptions) {
+		o.Order = migrate.PlanOrderIndexesLast
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 3)
+	require.Equal(t, `CREATE TABLE "public"."users" ("id" integer NOT NULL)`, plan.Changes[0].Cmd)
+	require.Equal(t, `CREATE TABLE "public"."posts" ("id" integer NOT NULL)`, plan.Changes[1].Cmd)
+	require.Equal(t, `CREATE INDEX "users_id" ON "public"."users" ("id")`, plan.Changes[2].Cmd)
+}
+
+func TestPlanChanges_Extensions(t *testing.T) {
+	var (
+		from = schema.NewRealm(schema.New("public"))
+		to   = schema.NewRealm(schema.New("public"))
+	)
+	from.AddObjects(
+		&Extension{Name: "hstore", Schema: "public", Version: "1.7"},
+		&Extension{Name: "citext", Schema: "public", Version: "1.6"},
+	)
+	to.AddObjects(
+		&Extension{Name: "hstore", Schema: "extensions", Version: "1.8", Attrs: []schema.Attr{&schema.Comment{Text: "key-value pairs"}}},
+		// An empty version accepts the installed one.
+		&Extension{Name: "ltree", Schema: "public"},
+	)
+	changes, err := DefaultDiff.RealmDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 5)
+	for i, c := range [][2]string{
+		{`ALTER EXTENSION "hstore" UPDATE TO '1.8'`, `ALTER EXTENSION "hstore" UPDATE TO '1.7'`},
+		{`ALTER EXTENSION "hstore" SET SCHEMA "extensions"`, `ALTER EXTENSION "hstore" SET SCHEMA "public"`},
+		{`COMMENT ON EXTENSION "hstore" IS 'key-value pairs'`, `COMMENT ON EXTENSION "hstore" IS ''`},
+		{`CREATE EXTENSION "ltree" WITH SCHEMA "public"`, `DROP EXTENSION "ltree"`},
+		{`DROP EXTENSION "citext"`, `CREATE EXTENSION "citext" WITH SCHEMA "public" VERSION '1.6'`},
+	} {
+		require.Equal(t, c[0], plan.Changes[i].Cmd)
+		require.Equal(t, c[1], plan.Changes[i].Reverse)
+	}
+
+	// Extensions are created before the tables that use their types,
+	// and dropped after them.
+	var (
+		ltree = &Extension{Name: "ltree"}
+		users = schema.NewTable("users").SetSchema(schema.New("public")).AddColumns(schema.NewColumn("path").SetType(&UserDefinedType{T: "ltree"}))
+	)
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddTable{T: users}, &schema.AddObject{O: ltree, Extra: []schema.Clause{&schema.IfNotExists{}}}})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `CREATE EXTENSION IF NOT EXISTS "ltree"`, plan.Changes[0].Cmd)
+	require.Equal(t, `CREATE TABLE "public"."users" ("path" ltree NOT NULL)`, plan.Changes[1].Cmd)
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.DropObject{O: ltree}, &schema.DropTable{T: users}})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `DROP TABLE "public"."users"`, plan.Changes[0].Cmd)
+	require.Equal(t, `DROP EXTENSION "ltree"`, plan.Changes[1].Cmd)
+}
+
+func TestPlanChanges_Triggers(t *testing.T) {
+	var (
+		from = schema.New("public")
+		to   = schema.New("public")
+		name = schema.NewStringColumn("name", TypeText)
+		newT = func(s *schema.Schema) *schema.Table {
+			t := schema.NewTable("users").AddColumns(schema.NewIntColumn("id", TypeInt), name)
+			s.AddTables(t)
+			return t
+		}
+		t1, t2 = newT(from), newT(to)
+	)
+	t1.Triggers = []*schema.Trigger{
+		{Name: "audit", Table: t1, ActionTime: schema.TriggerTimeAfter, For: schema.TriggerForRow, Events: []schema.TriggerEvent{schema.TriggerEventInsert}, Body: "audit()"},
+		{Name: "touch", Table: t1, ActionTime: schema.TriggerTimeBefore, For: schema.TriggerForRow, Events: []schema.TriggerEvent{schema.TriggerEventUpdate}, Body: "touch()"},
+		{Name: "legacy", Table: t1, ActionTime: schema.TriggerTimeAfter, For: schema.TriggerForStmt, Events: []schema.TriggerEvent{schema.TriggerEventTruncate}, Body: "legacy()"},
+	}
+	t2.Triggers = []*schema.Trigger{
+		{Name: "audit", Table: t2, ActionTime: schema.TriggerTimeAfter, For: schema.TriggerForRow, Events: []schema.TriggerEvent{schema.TriggerEventInsert}, Body: "audit()", Attrs: []schema.Attr{&TriggerState{V: TriggerStateDisabled}}},
+		{Name: "touch", Table: t2, ActionTime: schema.TriggerTimeBefore, For: schema.TriggerForRow, Events: []schema.TriggerEvent{schema.TriggerEventUpdateOf(name)}, Body: "touch()", Attrs: []schema.Attr{&TriggerWhen{X: "new.name IS NOT NULL"}}},
+		{Name: "notify", Table: t2, ActionTime: schema.TriggerTimeAfter, For: schema.TriggerForStmt, Events: []schema.TriggerEvent{schema.TriggerEventInsert, schema.TriggerEventDelete}, Body: "notify()"},
+	}
+	changes, err := DefaultDiff.SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 4)
+
+	// Triggers are replaced in place on version 14 and above.
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("140000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	plan, err := drv.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	for i, c := range [][2]string{
+		{`ALTER TABLE "public"."users" DISABLE TRIGGER "audit"`, `ALTER TABLE "public"."users" ENABLE TRIGGER "audit"`},
+		{`CREATE OR REPLACE TRIGGER "touch" BEFORE UPDATE OF "name" ON "public"."users" FOR EACH ROW WHEN (new.name IS NOT NULL) EXECUTE FUNCTION touch()`, `CREATE OR REPLACE TRIGGER "touch" BEFORE UPDATE ON "public"."users" FOR EACH ROW EXECUTE FUNCTION touch()`},
+		{`DROP TRIGGER "legacy" ON "public"."users"`, `CREATE TRIGGER "legacy" AFTER TRUNCATE ON "public"."users" FOR EACH STATEMENT EXECUTE FUNCTION legacy()`},
+		{`CREATE TRIGGER "notify" AFTER INSERT OR DELETE ON "public"."users" FOR EACH STATEMENT EXECUTE FUNCTION notify()`, `DROP TRIGGER "notify" ON "public"."users"`},
+	} {
+		require.Equal(t, c[0], plan.Changes[i].Cmd)
+		require.Equal(t, c[1], plan.Changes[i].Reverse)
+	}
+	require.Len(t, plan.Changes, 4)
+
+	// Older versions drop and recreate the trigger.
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTrigger{From: t1.Triggers[1], To: t2.Triggers[1]},
+		&schema.RenameTrigger{From: t1.Triggers[0], To: &schema.Trigger{Name: "audit_users", Table: t1}},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 3)
+	require.Equal(t, `DROP TRIGGER "touch" ON "public"."users"`, plan.Changes[0].Cmd)
+	require.Equal(t, `CREATE TRIGGER "touch" BEFORE UPDATE OF "name" ON "public"."users" FOR EACH ROW WHEN (new.name IS NOT NULL) EXECUTE PROCEDURE touch()`, plan.Changes[1].Cmd)
+	require.Equal(t, `ALTER TRIGGER "audit" ON "public"."users" RENAME TO "audit_users"`, plan.Changes[2].Cmd)
+	require.Equal(t, `ALTER TRIGGER "audit_users" ON "public"."users" RENAME TO "audit"`, plan.Changes[2].Reverse)
+
+	// Triggers are created after their tables.
+	users := schema.NewTable("users").SetSchema(schema.New("public")).AddColumns(schema.NewIntColumn("id", TypeInt))
+	tg := &schema.Trigger{Name: "audit", Table: users, ActionTime: schema.TriggerTimeAfter, For: schema.TriggerForRow, Events: []schema.TriggerEvent{schema.TriggerEventInsert}, Body: "audit()", Attrs: []schema.Attr{&TriggerState{V: TriggerStateReplica}}}
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddTrigger{T: tg}, &schema.AddTable{T: users}})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 3)
+	require.Equal(t, `CREATE TABLE "public"."users" ("id" integer NOT NULL)`, plan.Changes[0].Cmd)
+	require.Equal(t, `CREATE TRIGGER "audit" AFTER INSERT ON "public"."users" FOR EACH ROW EXECUTE PROCEDURE audit()`, plan.Changes[1].Cmd)
+	require.Equal(t, `ALTER TABLE "public"."users" ENABLE REPLICA TRIGGER "audit"`, plan.Changes[2].Cmd)
+}
+
+func TestPlanChanges_Funcs(t *testing.T) {
+	var (
+		from = schema.New("public")
+		to   = schema.New("public")
+		f1   = &schema.Func{
+			Name: "add", Lang: "sql", Body: "SELECT a + b",
+			Args: []*schema.FuncArg{{Name: "a", Type: &schema.IntegerType{T: TypeInteger}}, {Name: "b", Type: &schema.IntegerType{T: TypeInteger}}},
+			Ret:  &schema.IntegerType{T: TypeInteger},
+		}
+		f2 = &schema.Func{
+			Name: "add", Lang: "sql", Body: "SELECT a + b",
+			Args:  []*schema.FuncArg{{Name: "a", Type: &schema.IntegerType{T: TypeInteger}}, {Name: "b", Type: &schema.IntegerType{T: TypeInteger}, Default: &schema.Literal{V: "1"}}},
+			Ret:   &schema.IntegerType{T: TypeInteger},
+			Attrs: []schema.Attr{&FuncVolatility{V: VolatilityImmutable}, &schema.Comment{Text: "adds numbers"}},
+		}
+		p1 = &schema.Proc{Name: "reset", Lang: "plpgsql", Body: "BEGIN DELETE FROM t; END"}
+		p2 = &schema.Proc{Name: "cleanup", Lang: "plpgsql", Body: "BEGIN DELETE FROM t; END", Args: []*schema.FuncArg{{Name: "n", Type: &schema.IntegerType{T: TypeBigInt}, Mode: schema.FuncArgModeInOut}}}
+	)
+	from.AddFuncs(f1).AddProcs(p1)
+	to.AddFuncs(f2).AddProcs(p2)
+	changes, err := DefaultDiff.SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	for i, c := range [][2]string{
+		{`CREATE OR REPLACE FUNCTION "public"."add" ("a" integer, "b" integer DEFAULT 1) RETURNS integer LANGUAGE sql IMMUTABLE AS $$SELECT a + b$$`, `CREATE OR REPLACE FUNCTION "public"."add" ("a" integer, "b" integer) RETURNS integer LANGUAGE sql AS $$SELECT a + b$$`},
+		{`COMMENT ON FUNCTION "public"."add" (integer, integer) IS 'adds numbers'`, `COMMENT ON FUNCTION "public"."add" (integer, integer) IS ''`},
+		{`CREATE PROCEDURE "public"."cleanup" (INOUT "n" bigint) LANGUAGE plpgsql AS $$BEGIN DELETE FROM t; END$$`, `DROP PROCEDURE "public"."cleanup" (bigint)`},
+		{`DROP PROCEDURE "public"."reset" ()`, `CREATE PROCEDURE "public"."reset" () LANGUAGE plpgsql AS $$BEGIN DELETE FROM t; END$$`},
+	} {
+		require.Equal(t, c[0], plan.Changes[i].Cmd)
+		require.Equal(t, c[1], plan.Changes[i].Reverse)
+	}
+	require.Len(t, plan.Changes, 4)
+
+	// Changing the return type requires recreating the function.
+	f3 := &schema.Func{Name: "add", Schema: to, Lang: "sql", Body: "SELECT a + b", Args: f1.Args, Ret: &schema.IntegerType{T: TypeBigInt}}
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyFunc{From: f1, To: f3},
+		&schema.RenameProc{From: p1, To: &schema.Proc{Name: "truncate_t", Schema: from}},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 3)
+	require.Equal(t, `DROP FUNCTION "public"."add" (integer, integer)`, plan.Changes[0].Cmd)
+	require.Equal(t, `CREATE FUNCTION "public"."add" ("a" integer, "b" integer) RETURNS bigint LANGUAGE sql AS $$SELECT a + b$$`, plan.Changes[1].Cmd)
+	require.Equal(t, `ALTER PROCEDURE "public"."reset" () RENAME TO "truncate_t"`, plan.Changes[2].Cmd)
+	require.Equal(t, `ALTER PROCEDURE "public"."truncate_t" () RENAME TO "reset"`, plan.Changes[2].Reverse)
+
+	// Functions are created before the triggers that execute them.
+	users := schema.NewTable("users").SetSchema(schema.New("public")).AddColumns(schema.NewIntColumn("id", TypeInt))
+	fn := &schema.Func{Name: "audit", Schema: users.Schema, Lang: "plpgsql", Body: "BEGIN RETURN NEW; END", Ret: &PseudoType{T: "trigger"}}
+	tg := &schema.Trigger{Name: "audit", Table: users, ActionTime: schema.TriggerTimeAfter, For: schema.TriggerForRow, Events: []schema.TriggerEvent{schema.TriggerEventInsert}, Body: `"public"."audit"()`, Deps: []schema.Object{fn}}
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddTrigger{T: tg}, &schema.AddTable{T: users}, &schema.AddFunc{F: fn}})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 3)
+	require.Equal(t, `CREATE TABLE "public"."users" ("id" integer NOT NULL)`, plan.Changes[0].Cmd)
+	require.Equal(t, `CREATE FUNCTION "public"."audit" () RETURNS trigger LANGUAGE plpgsql AS $$BEGIN RETURN NEW; END$$`, plan.Changes[1].Cmd)
+	require.Equal(t, `CREATE TRIGGER "audit" AFTER INSERT ON "public"."users" FOR EACH ROW EXECUTE PROCEDURE "public"."audit"()`, plan.Changes[2].Cmd)
+}
+
+func TestPlanChanges_Domains(t *testing.T) {
+	var (
+		from = schema.New("public")
+		to   = schema.New("public")
+		d1   = &DomainType{T: "posint", Type: &schema.IntegerType{T: TypeInteger}, Checks: []*schema.Check{{Name: "posint_check", Expr: "(VALUE > 0)"}}}
+		d2   = &DomainType{
+			T: "posint", Type: &schema.IntegerType{T: TypeInteger}, Null: true, Default: &schema.Literal{V: "1"},
+			Checks: []*schema.Check{{Expr: "VALUE > 0"}, {Name: "posint_max", Expr: "VALUE < 100"}},
+			Attrs:  []schema.Attr{&schema.Comment{Text: "positive"}},
+		}
+		email = &DomainType{T: "email", Type: &schema.StringType{T: TypeText}, Null: true}
+	)
+	d1.Schema, email.Schema, d2.Schema = from, from, to
+	from.AddObjects(d1, email)
+	to.AddObjects(d2)
+	changes, err := DefaultDiff.SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 5)
+	require.Equal(t, `ALTER DOMAIN "public"."posint" SET DEFAULT 1`, plan.Changes[0].Cmd)
+	require.Equal(t, `ALTER DOMAIN "public"."posint" DROP DEFAULT`, plan.Changes[0].Reverse)
+	require.Equal(t, `ALTER DOMAIN "public"."posint" DROP NOT NULL`, plan.Changes[1].Cmd)
+	require.Equal(t, `ALTER DOMAIN "public"."posint" SET NOT NULL`, plan.Changes[1].Reverse)
+	require.Equal(t, `ALTER DOMAIN "public"."posint" ADD CONSTRAINT "posint_max" CHECK (VALUE < 100)`, plan.Changes[2].Cmd)
+	require.Equal(t, `ALTER DOMAIN "public"."posint" DROP CONSTRAINT "posint_max"`, plan.Changes[2].Reverse)
+	require.Equal(t, `COMMENT ON DOMAIN "public"."posint" IS 'positive'`, plan.Changes[3].Cmd)
+	require.Equal(t, `DROP DOMAIN "public"."email"`, plan.Changes[4].Cmd)
+	require.Equal(t, `CREATE DOMAIN "public"."email" AS text`, plan.Changes[4].Reverse)
+
+	// Tables are created after the domains they use.
+	users := schema.NewTable("users").SetSchema(to).AddColumns(schema.NewColumn("age").SetType(d2))
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddTable{T: users}, &schema.AddObject{O: d2}})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 3)
+	require.Equal(t, `CREATE DOMAIN "public"."posint" AS integer DEFAULT 1 CHECK (VALUE > 0) CONSTRAINT "posint_max" CHECK (VALUE < 100)`, plan.Changes[0].Cmd)
+	require.Equal(t, `COMMENT ON DOMAIN "public"."posint" IS 'positive'`, plan.Changes[1].Cmd)
+	require.Equal(t, `CREATE TABLE "public"."users" ("age" "public"."posint" NOT NULL)`, plan.Changes[2].Cmd)
+
+	d1.Type = &schema.IntegerType{T: TypeBigInt}
+	_, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.ModifyObject{From: d1, To: d2}})
+	require.EqualError(t, err, `changing the underlying type of domain "posint" is not supported`)
+}
+
+func TestPlanChanges_Composites(t *testing.T) {
+	var (
+		from = schema.New("public")
+		to   = schema.New("public")
+		mood = &schema.EnumType{T: "mood", Values: []string{"happy", "sad"}, Schema: to}
+		a1   = &CompositeType{
+			T: "address", Schema: from,
+			Fields: []*schema.Column{
+				schema.NewStringColumn("street", TypeText),
+				schema.NewIntColumn("zip", TypeInteger),
+				schema.NewStringColumn("city", TypeText),
+			},
+		}
+		a2 = &CompositeType{
+			T: "address", Schema: to,
+			Fields: []*schema.Column{
+				schema.NewStringColumn("street", TypeText).SetCollation("C"),
+				schema.NewStringColumn("zip", TypeText),
+				schema.NewColumn("mood").SetType(mood),
+			},
+			Attrs: []schema.Attr{&schema.Comment{Text: "postal address"}},
+			Deps:  []schema.Object{mood},
+		}
+		point = &CompositeType{T: "point2d", Schema: from, Fields: []*schema.Column{schema.NewFloatColumn("x", TypeDouble), schema.NewFloatColumn("y", TypeDouble)}}
+	)
+	from.AddObjects(a1, point)
+	to.AddObjects(mood, a2)
+	changes, err := DefaultDiff.SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	for i, c := range [][2]string{
+		{`CREATE TYPE "public"."mood" AS ENUM ('happy', 'sad')`, `DROP TYPE "public"."mood"`},
+		{
+			`ALTER TYPE "public"."address" DROP ATTRIBUTE "city", ADD ATTRIBUTE "mood" "public"."mood", ALTER ATTRIBUTE "street" TYPE text COLLATE "C", ALTER ATTRIBUTE "zip" TYPE text`,
+			`ALTER TYPE "public"."address" ADD ATTRIBUTE "city" text, DROP ATTRIBUTE "mood", ALTER ATTRIBUTE "street" TYPE text, ALTER ATTRIBUTE "zip" TYPE integer`,
+		},
+		{`COMMENT ON TYPE "public"."address" IS 'postal address'`, `COMMENT ON TYPE "public"."address" IS ''`},
+		{`DROP TYPE "public"."point2d"`, `CREATE TYPE "public"."point2d" AS ("x" double precision, "y" double precision)`},
+	} {
+		require.Equal(t, c[0], plan.Changes[i].Cmd)
+		require.Equal(t, c[1], plan.Changes[i].Reverse)
+	}
+	require.Len(t, plan.Changes, 4)
+
+	// Composite types are created after the types they use,
+	// and before the tables that use them.
+	users := schema.NewTable("users").SetSchema(to).AddColumns(schema.NewColumn("home").SetType(a2))
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddTable{T: users}, &schema.AddObject{O: a2}, &schema.AddObject{O: mood}})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 4)
+	require.Equal(t, `CREATE TYPE "public"."mood" AS ENUM ('happy', 'sad')`, plan.Changes[0].Cmd)
+	require.Equal(t, `CREATE TYPE "public"."address" AS ("street" text COLLATE "C", "zip" text, "mood" "public"."mood")`, plan.Changes[1].Cmd)
+	require.Equal(t, `COMMENT ON TYPE "public"."address" IS 'postal address'`, plan.Changes[2].Cmd)
+	require.Equal(t, `CREATE TABLE "public"."users" ("home" "public"."address" NOT NULL)`, plan.Changes[3].Cmd)
+
+	// And dropped before them.
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.DropObject{O: mood}, &schema.DropObject{O: a2}})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `DROP TYPE "public"."address"`, plan.Changes[0].Cmd)
+	require.Equal(t, `DROP TYPE "public"."mood"`, plan.Changes[1].Cmd)
+}
+
+func TestPlanChanges_Ranges(t *testing.T) {
+	var (
+		from = schema.New("public")
+		to   = schema.New("public")
+		mood = &schema.EnumType{T: "mood", Values: []string{"happy", "sad"}, Schema: to}
+		f1   = &UserRangeType{T: "floatrange", Schema: from, Subtype: &schema.FloatType{T: TypeDouble}}
+		f2   = &UserRangeType{T: "floatrange", Schema: to, Subtype: &schema.FloatType{T: TypeDouble}, Attrs: []schema.Attr{&schema.Comment{Text: "float ranges"}}}
+		m2   = &UserRangeType{T: "moodrange", Schema: to, Subtype: mood, Deps: []schema.Object{mood}}
+		t1   = &UserRangeType{T: "textrange", Schema: from, Subtype: &schema.StringType{T: TypeText}, Collation: "C", Canonical: "public.canon", SubtypeDiff: "public.diff"}
+	)
+	from.AddObjects(f1, t1)
+	to.AddObjects(mood, f2, m2)
+	changes, err := DefaultDiff.SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 4)
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	for i, c := range [][2]string{
+		{`CREATE TYPE "public"."mood" AS ENUM ('happy', 'sad')`, `DROP TYPE "public"."mood"`},
+		{`COMMENT ON TYPE "public"."floatrange" IS 'float ranges'`, `COMMENT ON TYPE "public"."floatrange" IS ''`},
+		{`CREATE TYPE "public"."moodrange" AS RANGE (SUBTYPE = "public"."mood")`, `DROP TYPE "public"."moodrange"`},
+		{`DROP TYPE "public"."textrange"`, `CREATE TYPE "public"."textrange" AS RANGE (SUBTYPE = text, COLLATION = "C", CANONICAL = public.canon, SUBTYPE_DIFF = public.diff)`},
+	} {
+		require.Equal(t, c[0], plan.Changes[i].Cmd)
+		require.Equal(t, c[1], plan.Changes[i].Reverse)
+	}
+	require.Len(t, plan.Changes, 4)
+
+	// Range types cannot be altered, and are recreated instead.
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyObject{From: f1, To: &UserRangeType{T: "floatrange", Schema: to, Subtype: &schema.FloatType{T: TypeDouble}, SubtypeDiff: "float8mi"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `DROP TYPE "public"."floatrange"`, plan.Changes[0].Cmd)
+	require.Equal(t, `CREATE TYPE "public"."floatrange" AS RANGE (SUBTYPE = double precision)`, plan.Changes[0].Reverse)
+	require.Equal(t, `CREATE TYPE "public"."floatrange" AS RANGE (SUBTYPE = double precision, SUBTYPE_DIFF = float8mi)`, plan.Changes[1].Cmd)
+
+	// Range types are created after their subtypes, and before the tables that use them.
+	users := schema.NewTable("users").SetSchema(to).AddColumns(schema.NewColumn("moods").SetType(m2))
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddTable{T: users}, &schema.AddObject{O: m2}, &schema.AddObject{O: mood}})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 3)
+	require.Equal(t, `CREATE TYPE "public"."mood" AS ENUM ('happy', 'sad')`, plan.Changes[0].Cmd)
+	require.Equal(t, `CREATE TYPE "public"."moodrange" AS RANGE (SUBTYPE = "public"."mood")`, plan.Changes[1].Cmd)
+	require.Equal(t, `CREATE TABLE "public"."users" ("moods" "public"."moodrange" NOT NULL)`, plan.Changes[2].Cmd)
+
+	// And dropped before them.
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.DropObject{O: mood}, &schema.DropObject{O: m2}})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `DROP TYPE "public"."moodrange"`, plan.Changes[0].Cmd)
+	require.Equal(t, `DROP TYPE "public"."mood"`, plan.Changes[1].Cmd)
+}
+
+func TestPlanChanges_Collations(t *testing.T) {
+	var (
+		from   = schema.New("public")
+		to     = schema.New("public")
+		german = &Collation{Name: "german", Schema: to, Provider: "icu", Locale: "de-u-ks-l2", Attrs: []schema.Attr{&schema.Comment{Text: "case insensitive"}}}
+		mixed1 = &Collation{Name: "mixed", Schema: from, LcCollate: "C", LcCtype: "en_US.UTF-8", Deterministic: true}
+		mixed2 = &Collation{Name: "mixed", Schema: to, LcCollate: "C", LcCtype: "en_US.UTF-8", Deterministic: true, Attrs: []schema.Attr{&schema.Comment{Text: "mixed"}}}
+		posix  = &Collation{Name: "posix", Schema: from, Provider: "libc", Locale: "POSIX", Deterministic: true}
+	)
+	from.AddObjects(mixed1, posix)
+	to.AddObjects(german, mixed2)
+	changes, err := DefaultDiff.SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	for i, c := range [][2]string{
+		{`COMMENT ON COLLATION "public"."mixed" IS 'mixed'`, `COMMENT ON COLLATION "public"."mixed" IS ''`},
+		{`CREATE COLLATION "public"."german" (PROVIDER = icu, LOCALE = 'de-u-ks-l2', DETERMINISTIC = false)`, `DROP COLLATION "public"."german"`},
+		{`COMMENT ON COLLATION "public"."german" IS 'case insensitive'`, `COMMENT ON COLLATION "public"."german" IS ''`},
+		{`DROP COLLATION "public"."posix"`, `CREATE COLLATION "public"."posix" (PROVIDER = libc, LOCALE = 'POSIX')`},
+	} {
+		require.Equal(t, c[0], plan.Changes[i].Cmd)
+		require.Equal(t, c[1], plan.Changes[i].Reverse)
+	}
+	require.Len(t, plan.Changes, 4)
+
+	// Collations cannot be altered, and are recreated instead.
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyObject{From: mixed1, To: &Collation{Name: "mixed", Schema: to, Locale: "C", Deterministic: true}},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `DROP COLLATION "public"."mixed"`, plan.Changes[0].Cmd)
+	require.Equal(t, `CREATE COLLATION "public"."mixed" (LOCALE = 'C')`, plan.Changes[1].Cmd)
+	require.Equal(t, `CREATE COLLATION "public"."mixed" (LC_COLLATE = 'C', LC_CTYPE = 'en_US.UTF-8')`, plan.Changes[0].Reverse)
+
+	// Collations are created before the tables that use them.
+	users := schema.NewTable("users").SetSchema(to).AddColumns(schema.NewStringColumn("name", TypeText).SetCollation("german"))
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddTable{T: users}, &schema.AddObject{O: german}})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 3)
+	require.Equal(t, `CREATE COLLATION "public"."german" (PROVIDER = icu, LOCALE = 'de-u-ks-l2', DETERMINISTIC = false)`, plan.Changes[0].Cmd)
+	require.Equal(t, `CREATE TABLE "public"."users" ("name" text NOT NULL COLLATE "german")`, plan.Changes[2].Cmd)
+
+	// Changing the collation of a column restates its type.
+	changes, err = DefaultDiff.TableDiff(
+		schema.NewTable("users").SetSchema(to).AddColumns(schema.NewStringColumn("name", TypeText)),
+		users,
+	)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, schema.ChangeCollate, changes[0].(*schema.ModifyColumn).Change)
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.ModifyTable{T: users, Changes: changes}})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, `ALTER TABLE "public"."users" ALTER COLUMN "name" TYPE text COLLATE "german"`, plan.Changes[0].Cmd)
+	require.Equal(t, `ALTER TABLE "public"."users" ALTER COLUMN "name" TYPE text`, plan.Changes[0].Reverse)
+}
+
+func TestPlanChanges_CollationDeps(t *testing.T) {
+	var (
+		s      = schema.New("public")
+		german = &Collation{Name: "german", Schema: s, Provider: "icu", Locale: "de-u-ks-l2"}
+		name   = schema.NewStringColumn("name", TypeText)
+		users  = schema.NewTable("users").SetSchema(s).AddColumns(name)
+		addr   = &CompositeType{T: "address", Schema: s, Fields: []*schema.Column{schema.NewStringColumn("city", TypeText).SetCollation("public.german")}}
+	)
+	users.AddIndexes(schema.NewUniqueIndex("users_name").AddParts(schema.NewColumnPart(name).AddAttrs(&schema.Collation{V: "german"})))
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddTable{T: users},
+		&schema.AddObject{O: addr},
+		&schema.AddObject{O: german},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 4)
+	require.Equal(t, `CREATE COLLATION "public"."german" (PROVIDER = icu, LOCALE = 'de-u-ks-l2', DETERMINISTIC = false)`, plan.Changes[0].Cmd)
+	require.Equal(t, `CREATE TABLE "public"."users" ("name" text NOT NULL)`, plan.Changes[1].Cmd)
+	require.Equal(t, `CREATE UNIQUE INDEX "users_name" ON "public"."users" ("name" COLLATE "german")`, plan.Changes[2].Cmd)
+	require.Equal(t, `CREATE TYPE "public"."address" AS ("city" text COLLATE "public.german")`, plan.Changes[3].Cmd)
+
+	// Indexes that are added to existing tables.
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{T: users, Changes: []schema.Change{&schema.AddIndex{I: users.Indexes[0]}}},
+		&schema.AddObject{O: german},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `CREATE COLLATION "public"."german" (PROVIDER = icu, LOCALE = 'de-u-ks-l2', DETERMINISTIC = false)`, plan.Changes[0].Cmd)
+	require.Equal(t, `CREATE UNIQUE INDEX "users_name" ON "public"."users" ("name" COLLATE "german")`, plan.Changes[1].Cmd)
+
+	// Collations are dropped after the objects that use them.
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.DropObject{O: german},
+		&schema.DropObject{O: addr},
+		&schema.ModifyTable{T: users, Changes: []schema.Change{&schema.DropIndex{I: users.Indexes[0]}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 3)
+	require.Equal(t, `DROP COLLATION "public"."german"`, plan.Changes[2].Cmd)
+}
+
+func TestPlanChanges_EventTriggers(t *testing.T) {
+	var (
+		s     = schema.New("audit")
+		fn    = &schema.Func{Name: "log_ddl", Schema: s, Lang: "plpgsql", Body: "BEGIN END", Ret: &PseudoType{T: "event_trigger"}}
+		from  = schema.NewRealm(s)
+		to    = schema.NewRealm(s)
+		ddl1  = &EventTrigger{Name: "log_ddl", Event: "ddl_command_end", Tags: []string{"CREATE TABLE"}, Func: fn}
+		ddl2  = &EventTrigger{Name: "log_ddl", Event: "ddl_command_end", Tags: []string{"create table"}, Func: fn, Attrs: []schema.Attr{&TriggerState{V: TriggerStateAlways}, &schema.Comment{Text: "audit DDL"}}}
+		drops = &EventTrigger{Name: "log_drops", Event: "sql_drop", Func: fn, Attrs: []schema.Attr{&TriggerState{V: TriggerStateDisabled}}}
+		start = &EventTrigger{Name: "log_start", Event: "ddl_command_start", Func: fn}
+	)
+	from.AddObjects(ddl1, start)
+	to.AddObjects(ddl2, drops)
+	changes, err := DefaultDiff.RealmDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	for i, c := range [][2]string{
+		{`ALTER EVENT TRIGGER "log_ddl" ENABLE ALWAYS`, `ALTER EVENT TRIGGER "log_ddl" ENABLE`},
+		{`COMMENT ON EVENT TRIGGER "log_ddl" IS 'audit DDL'`, `COMMENT ON EVENT TRIGGER "log_ddl" IS ''`},
+		{`CREATE EVENT TRIGGER "log_drops" ON sql_drop EXECUTE PROCEDURE "audit"."log_ddl"()`, `DROP EVENT TRIGGER "log_drops"`},
+		{`ALTER EVENT TRIGGER "log_drops" DISABLE`, `ALTER EVENT TRIGGER "log_drops" ENABLE`},
+		{`DROP EVENT TRIGGER "log_start"`, `CREATE EVENT TRIGGER "log_start" ON ddl_command_start EXECUTE PROCEDURE "audit"."log_ddl"()`},
+	} {
+		require.Equal(t, c[0], plan.Changes[i].Cmd)
+		require.Equal(t, c[1], plan.Changes[i].Reverse)
+	}
+	require.Len(t, plan.Changes, 5)
+
+	// Changing the events of a trigger recreates it.
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyObject{From: ddl1, To: &EventTrigger{Name: "log_ddl", Event: "ddl_command_end", Tags: []string{"CREATE TABLE", "DROP TABLE"}, Func: fn}},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `DROP EVENT TRIGGER "log_ddl"`, plan.Changes[0].Cmd)
+	require.Equal(t, `CREATE EVENT TRIGGER "log_ddl" ON ddl_command_end WHEN TAG IN ('CREATE TABLE', 'DROP TABLE') EXECUTE PROCEDURE "audit"."log_ddl"()`, plan.Changes[1].Cmd)
+
+	// Event triggers are created after their functions, and dropped before them.
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddObject{O: drops}, &schema.AddFunc{F: fn}})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 3)
+	require.Equal(t, `CREATE EVENT TRIGGER "log_drops" ON sql_drop EXECUTE PROCEDURE "audit"."log_ddl"()`, plan.Changes[1].Cmd)
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.DropFunc{F: fn}, &schema.DropObject{O: start}})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `DROP EVENT TRIGGER "log_start"`, plan.Changes[0].Cmd)
+
+	// The FUNCTION keyword is used on version 11 and above.
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("110000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	plan, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddObject{O: start}})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, `CREATE EVENT TRIGGER "log_start" ON ddl_command_start EXECUTE FUNCTION "audit"."log_ddl"()`, plan.Changes[0].Cmd)
+}
+
+func TestPlanChanges_ForeignData(t *testing.T) {
+	var (
+		s     = schema.New("public")
+		from  = schema.NewRealm(s)
+		to    = schema.NewRealm(schema.New("public"))
+		srv1  = &ForeignServer{Name: "films", Wrapper: "postgres_fdw", Version: "13", Options: map[string]string{"host": "a", "port": "5432"}}
+		srv2  = &ForeignServer{Name: "films", Wrapper: "postgres_fdw", Options: map[string]string{"host": "b", "dbname": "films"}, Attrs: []schema.Attr{&schema.Comment{Text: "films db"}}}
+		users = &ForeignServer{Name: "users", Wrapper: "postgres_fdw"}
+		ft    = &ForeignTable{
+			Name:    "films",
+			Schema:  to.Schemas[0],
+			Server:  srv2,
+			Columns: []*schema.Column{{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "integer"}}}, {Name: "title", Type: &schema.ColumnType{Type: &schema.StringType{T: "text"}, Null: true}}},
+			Options: map[string]string{"table_name": "films"},
+		}
+	)
+	from.AddObjects(srv1, users, &UserMapping{User: "admin", Server: users})
+	to.AddObjects(srv2, &UserMapping{User: "PUBLIC", Server: srv2, Options: map[string]string{"user": "reader"}})
+	to.Schemas[0].AddObjects(ft)
+	changes, err := DefaultDiff.RealmDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 5)
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	for i, c := range [][2]string{
+		{`ALTER SERVER "films" OPTIONS (DROP "port", ADD "dbname" 'films', SET "host" 'b')`, `ALTER SERVER "films" VERSION '13' OPTIONS (DROP "dbname", SET "host" 'a', ADD "port" '5432')`},
+		{`COMMENT ON SERVER "films" IS 'films db'`, `COMMENT ON SERVER "films" IS ''`},
+		{`CREATE USER MAPPING FOR PUBLIC SERVER "films" OPTIONS ("user" 'reader')`, `DROP USER MAPPING FOR PUBLIC SERVER "films"`},
+		{`CREATE FOREIGN TABLE "public"."films" ("id" integer NOT NULL, "title" text) SERVER "films" OPTIONS ("table_name" 'films')`, `DROP FOREIGN TABLE "public"."films"`},
+		{`DROP USER MAPPING FOR "admin" SERVER "users"`, `CREATE USER MAPPING FOR "admin" SERVER "users"`},
+		{`DROP SERVER "users"`, `CREATE SERVER "users" FOREIGN DATA WRAPPER "postgres_fdw"`},
+	} {
+		require.Equal(t, c[0], plan.Changes[i].Cmd)
+		require.Equal(t, c[1], plan.Changes[i].Reverse)
+	}
+	require.Len(t, plan.Changes, 6)
+
+	// Servers are created before the objects that use them, and dropped after them.
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddObject{O: ft},
+		&schema.AddObject{O: srv1},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `CREATE SERVER "films" VERSION '13' FOREIGN DATA WRAPPER "postgres_fdw" OPTIONS ("host" 'a', "port" '5432')`, plan.Changes[0].Cmd)
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.DropObject{O: srv2},
+		&schema.DropObject{O: ft},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `DROP FOREIGN TABLE "public"."films"`, plan.Changes[0].Cmd)
+
+	// Changing the columns of a foreign table recreates it,
+	// while changing its options only alters the table.
+	ft2 := &ForeignTable{Name: ft.Name, Schema: ft.Schema, Server: srv2, Columns: ft.Columns[:1], Options: map[string]string{"table_name": "movies"}}
+	changes, err = DefaultDiff.SchemaDiff(to.Schemas[0], schema.New("public").AddObjects(ft2))
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `DROP FOREIGN TABLE "public"."films"`, plan.Changes[0].Cmd)
+	require.Equal(t, `CREATE FOREIGN TABLE "public"."films" ("id" integer NOT NULL) SERVER "films" OPTIONS ("table_name" 'movies')`, plan.Changes[1].Cmd)
+	ft2.Columns = ft.Columns
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.ModifyObject{From: ft, To: ft2}})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, `ALTER FOREIGN TABLE "public"."films" OPTIONS (SET "table_name" 'movies')`, plan.Changes[0].Cmd)
+	require.Equal(t, `ALTER FOREIGN TABLE "public"."films" OPTIONS (SET "table_name" 'films')`, plan.Changes[0].Reverse)
+}
+
+func TestPlanChanges_Publications(t *testing.T) {
+	var (
+		from  = schema.NewRealm(schema.New("public"))
+		to    = schema.NewRealm(schema.New("public"))
+		users = schema.NewTable("users").SetSchema(from.Schemas[0]).AddColumns(schema.NewIntColumn("id", "int"), schema.NewStringColumn("name", "text"))
+		logs  = schema.NewTable("logs").SetSchema(from.Schemas[0])
+	)
+	from.AddObjects(
+		&Publication{Name: "all", AllTables: true},
+		&Publication{Name: "logs", Tables: []*PublicationTable{{Table: logs}}},
+		&Publication{Name: "users", Tables: []*PublicationTable{{Table: users, Columns: users.Columns[:1]}}, Publish: []string{"insert"}},
+	)
+	to.AddObjects(
+		&Publication{Name: "all", Tables: []*PublicationTable{{Table: logs}}},
+		&Publication{Name: "public", Schemas: to.Schemas},
+		&Publication{
+			Name:   "users",
+			Tables: []*PublicationTable{{Table: users, Columns: users.Columns, Where: "name <> ''"}},
+			Attrs:  []schema.Attr{&schema.Comment{Text: "users"}},
+		},
+	)
+	changes, err := DefaultDiff.RealmDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 4)
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	for i, c := range [][2]string{
+		{`DROP PUBLICATION "all"`, `CREATE PUBLICATION "all" FOR ALL TABLES`},
+		{`CREATE PUBLICATION "all" FOR TABLE "public"."logs"`, `DROP PUBLICATION "all"`},
+		{`ALTER PUBLICATION "users" SET TABLE "public"."users" ("id", "name") WHERE (name <> '')`, `ALTER PUBLICATION "users" SET TABLE "public"."users" ("id")`},
+		{`ALTER PUBLICATION "users" SET (publish = 'insert, update, delete, truncate')`, `ALTER PUBLICATION "users" SET (publish = 'insert')`},
+		{`COMMENT ON PUBLICATION "users" IS 'users'`, `COMMENT ON PUBLICATION "users" IS ''`},
+		{`CREATE PUBLICATION "public" FOR TABLES IN SCHEMA "public"`, `DROP PUBLICATION "public"`},
+		{`DROP PUBLICATION "logs"`, `CREATE PUBLICATION "logs" FOR TABLE "public"."logs"`},
+	} {
+		require.Equal(t, c[0], plan.Changes[i].Cmd)
+		require.Equal(t, c[1], plan.Changes[i].Reverse)
+	}
+	require.Len(t, plan.Changes, 7)
+
+	// Removing all members of a publication drops them explicitly.
+	p1 := &Publication{Name: "p", Tables: []*PublicationTable{{Table: users, Columns: users.Columns[:1]}, {Table: logs}}, Schemas: to.Schemas, Publish: []string{"insert", "delete"}}
+	p2 := &Publication{Name: "p", Publish: []string{"insert", "delete"}}
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.ModifyObject{From: p1, To: p2}})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, `ALTER PUBLICATION "p" DROP TABLE "public"."users", "public"."logs", TABLES IN SCHEMA "public"`, plan.Changes[0].Cmd)
+	require.Equal(t, `ALTER PUBLICATION "p" SET TABLE "public"."users" ("id"), "public"."logs", TABLES IN SCHEMA "public"`, plan.Changes[0].Reverse)
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddObject{O: p1}})
+	require.NoError(t, err)
+	require.Equal(t, `CREATE PUBLICATION "p" FOR TABLE "public"."users" ("id"), "public"."logs", TABLES IN SCHEMA "public" WITH (publish = 'insert, delete')`, plan.Changes[0].Cmd)
+
+	// Publications are created after the tables they publish, and dropped before them.
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddObject{O: p1},
+		&schema.AddTable{T: users},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `CREATE TABLE "public"."users" ("id" integer NOT NULL, "name" text NOT NULL)`, plan.Changes[0].Cmd)
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.DropTable{T: logs},
+		&schema.DropObject{O: p1},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `DROP PUBLICATION "p"`, plan.Changes[0].Cmd)
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{T: users, Changes: []schema.Change{&schema.DropColumn{C: users.Columns[0]}}},
+		&schema.ModifyObject{From: p1, To: p2},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `ALTER PUBLICATION "p" DROP TABLE "public"."users", "public"."logs", TABLES IN SCHEMA "public"`, plan.Changes[0].Cmd)
+}
+
+func TestPlanChanges_TextSearch(t *testing.T) {
+	var (
+		s    = schema.New("public")
+		dict = &TextSearchDict{Name: "english_nostop", Schema: s, Template: "snowball", Options: map[string]string{"language": "english"}}
+		cfg  = &TextSearchConfig{Name: "english_custom", Schema: s, Parser: "default", Mappings: map[string][]string{
+			"asciiword": {"public.english_nostop", "english_stem"},
+			"word":      {"public.english_nostop", "english_stem"},
+			"int":       {"simple"},
+		}}
+	)
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddObject{O: cfg},
+		&schema.AddObject{O: dict},
+	})
+	require.NoError(t, err)
+	for i, c := range [][2]string{
+		{`CREATE TEXT SEARCH DICTIONARY "public"."english_nostop" (TEMPLATE = "snowball", "language" = 'english')`, `DROP TEXT SEARCH DICTIONARY "public"."english_nostop"`},
+		{`CREATE TEXT SEARCH CONFIGURATION "public"."english_custom" (PARSER = "default")`, `DROP TEXT SEARCH CONFIGURATION "public"."english_custom"`},
+		{`ALTER TEXT SEARCH CONFIGURATION "public"."english_custom" ADD MAPPING FOR asciiword, word WITH "public"."english_nostop", "english_stem"`, `ALTER TEXT SEARCH CONFIGURATION "public"."english_custom" DROP MAPPING FOR asciiword, word`},
+		{`ALTER TEXT SEARCH CONFIGURATION "public"."english_custom" ADD MAPPING FOR int WITH "simple"`, `ALTER TEXT SEARCH CONFIGURATION "public"."english_custom" DROP MAPPING FOR int`},
+	} {
+		require.Equal(t, c[0], plan.Changes[i].Cmd)
+		require.Equal(t, c[1], plan.Changes[i].Reverse)
+	}
+	require.Len(t, plan.Changes, 4)
+
+	// Dictionaries are dropped after the configurations that use them.
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.DropObject{O: dict},
+		&schema.DropObject{O: cfg},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `DROP TEXT SEARCH CONFIGURATION "public"."english_custom"`, plan.Changes[0].Cmd)
+	require.Equal(t, `DROP TEXT SEARCH DICTIONARY "public"."english_nostop"`, plan.Changes[1].Cmd)
+
+	// Options and mappings are altered in place.
+	to := schema.New("public")
+	to.AddObjects(
+		&TextSearchDict{Name: "english_nostop", Schema: to, Template: "snowball", Options: map[string]string{"language": "english", "stopwords": "english"}, Attrs: []schema.Attr{&schema.Comment{Text: "stemmer"}}},
+		&TextSearchConfig{Name: "english_custom", Schema: to, Parser: "default", Mappings: map[string][]string{
+			"asciiword": {"english_stem"},
+			"word":      {"public.english_nostop", "english_stem"},
+			"email":     {"simple"},
+		}},
+	)
+	s.AddObjects(dict, cfg)
+	changes, err := DefaultDiff.SchemaDiff(s, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	for i, c := range [][2]string{
+		{`ALTER TEXT SEARCH DICTIONARY "public"."english_nostop" ("stopwords" = 'english')`, `ALTER TEXT SEARCH DICTIONARY "public"."english_nostop" ("stopwords")`},
+		{`COMMENT ON TEXT SEARCH DICTIONARY "public"."english_nostop" IS 'stemmer'`, `COMMENT ON TEXT SEARCH DICTIONARY "public"."english_nostop" IS ''`},
+		{`ALTER TEXT SEARCH CONFIGURATION "public"."english_custom" DROP MAPPING FOR int`, `ALTER TEXT SEARCH CONFIGURATION "public"."english_custom" ADD MAPPING FOR int WITH "simple"`},
+		{`ALTER TEXT SEARCH CONFIGURATION "public"."english_custom" ALTER MAPPING FOR asciiword WITH "english_stem"`, `ALTER TEXT SEARCH CONFIGURATION "public"."english_custom" ALTER MAPPING FOR asciiword WITH "public"."english_nostop", "english_stem"`},
+		{`ALTER TEXT SEARCH CONFIGURATION "public"."english_custom" ADD MAPPING FOR email WITH "simple"`, `ALTER TEXT SEARCH CONFIGURATION "public"."english_custom" DROP MAPPING FOR email`},
+	} {
+		require.Equal(t, c[0], plan.Changes[i].Cmd)
+		require.Equal(t, c[1], plan.Changes[i].Reverse)
+	}
+	require.Len(t, plan.Changes, 5)
+}
+
+func TestPlanChanges_Operators(t *testing.T) {
+	var (
+		s    = schema.New("public")
+		dist = &schema.Func{Name: "vec_distance", Schema: s, Lang: "sql", Body: "SELECT abs($1 - $2)", Args: []*schema.FuncArg{{Type: &schema.IntegerType{T: TypeInteger}}, {Type: &schema.IntegerType{T: TypeInteger}}}, Ret: &schema.IntegerType{T: TypeInteger}}
+		op   = &Operator{Name: "<->", Schema: s, Left: "integer", Right: "integer", Function: "public.vec_distance", Commutator: "<->", Attrs: []schema.Attr{&schema.Comment{Text: "distance"}}}
+		fam  = &OpFamily{Name: "vec_family", Schema: s, Method: "gist"}
+		ops  = &OpClass{
+			Name:   "vec_ops",
+			Schema: s,
+			Method: "gist",
+			Type:   "integer",
+			Family: "vec_family",
+			Operators: []*OpClassOperator{
+				{Strategy: 15, Name: "public.<->(integer,integer)", OrderBy: "integer_ops"},
+			},
+			Funcs: []*OpClassFunc{
+				{Number: 8, Name: "public.vec_distance(integer,integer)"},
+			},
+		}
+	)
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddObject{O: ops},
+		&schema.AddObject{O: fam},
+		&schema.AddObject{O: op},
+		&schema.AddFunc{F: dist},
+	})
+	require.NoError(t, err)
+	for i, c := range [][2]string{
+		{`CREATE OPERATOR FAMILY "public"."vec_family" USING gist`, `DROP OPERATOR FAMILY "public"."vec_family" USING gist`},
+		{`CREATE FUNCTION "public"."vec_distance" (integer, integer) RETURNS integer LANGUAGE sql AS $$SELECT abs($1 - $2)$$`, `DROP FUNCTION "public"."vec_distance" (integer, integer)`},
+		{`CREATE OPERATOR "public".<-> (FUNCTION = public.vec_distance, LEFTARG = integer, RIGHTARG = integer, COMMUTATOR = <->)`, `DROP OPERATOR "public".<-> (integer, integer)`},
+		{`COMMENT ON OPERATOR "public".<-> (integer, integer) IS 'distance'`, `COMMENT ON OPERATOR "public".<-> (integer, integer) IS ''`},
+		{`CREATE OPERATOR CLASS "public"."vec_ops" FOR TYPE integer USING gist FAMILY "public"."vec_family" AS OPERATOR 15 public.<->(integer,integer) FOR ORDER BY integer_ops, FUNCTION 8 public.vec_distance(integer,integer)`, `DROP OPERATOR CLASS "public"."vec_ops" USING gist`},
+	} {
+		require.Equal(t, c[0], plan.Changes[i].Cmd)
+		require.Equal(t, c[1], plan.Changes[i].Reverse)
+	}
+	require.Len(t, plan.Changes, 5)
+
+	// Operators and families are dropped after the classes that use them, and
+	// classes with an implicit family are dropped along with their family.
+	ops.Family = ""
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.DropObject{O: op},
+		&schema.DropObject{O: ops},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `DROP OPERATOR FAMILY "public"."vec_ops" USING gist`, plan.Changes[0].Cmd)
+	require.Equal(t, `DROP OPERATOR "public".<-> (integer, integer)`, plan.Changes[1].Cmd)
+
+	// Estimators are altered in place, and other changes recreate the operator or the class.
+	from, to := schema.New("public"), schema.New("public")
+	from.AddObjects(op, ops)
+	to.AddObjects(
+		&Operator{Name: "<->", Schema: to, Left: "integer", Right: "integer", Function: "public.vec_distance", Commutator: "<->", Restrict: "scalarltsel", Attrs: []schema.Attr{&schema.Comment{Text: "distance"}}},
+		&OpClass{Name: "vec_ops", Schema: to, Method: "gist", Type: "integer", Default: true, Operators: ops.Operators, Funcs: ops.Funcs},
+	)
+	changes, err := DefaultDiff.SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	for i, c := range [][2]string{
+		{`ALTER OPERATOR "public".<-> (integer, integer) SET (RESTRICT = scalarltsel, JOIN = NONE)`, `ALTER OPERATOR "public".<-> (integer, integer) SET (RESTRICT = NONE, JOIN = NONE)`},
+		{`DROP OPERATOR FAMILY "public"."vec_ops" USING gist`, `CREATE OPERATOR CLASS "public"."vec_ops" FOR TYPE integer USING gist AS OPERATOR 15 public.<->(integer,integer) FOR ORDER BY integer_ops, FUNCTION 8 public.vec_distance(integer,integer)`},
+		{`CREATE OPERATOR CLASS "public"."vec_ops" DEFAULT FOR TYPE integer USING gist AS OPERATOR 15 public.<->(integer,integer) FOR ORDER BY integer_ops, FUNCTION 8 public.vec_distance(integer,integer)`, `DROP OPERATOR FAMILY "public"."vec_ops" USING gist`},
+	} {
+		require.Equal(t, c[0], plan.Changes[i].Cmd)
+		require.Equal(t, c[1], plan.Changes[i].Reverse)
+	}
+	require.Len(t, plan.Changes, 3)
+
+	// Operator classes are created before the indexes that use them.
+	users := schema.NewTable("users").SetSchema(s).AddColumns(schema.NewIntColumn("v", TypeInteger))
+	users.AddIndexes(schema.NewIndex("users_v").AddParts(schema.NewColumnPart(users.Columns[0]).AddAttrs(&IndexOpClass{Name: "vec_ops"})))
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddTable{T: users}, &schema.AddObject{O: ops}})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 3)
+	require.Equal(t, `CREATE OPERATOR CLASS "public"."vec_ops" FOR TYPE integer USING gist AS OPERATOR 15 public.<->(integer,integer) FOR ORDER BY integer_ops, FUNCTION 8 public.vec_distance(integer,integer)`, plan.Changes[0].Cmd)
+	require.Equal(t, `CREATE INDEX "users_v" ON "public"."users" ("v" vec_ops)`, plan.Changes[2].Cmd)
+}
+
+func TestPlanChanges_Aggregates(t *testing.T) {
+	var (
+		s     = schema.New("public")
+		float = &schema.FloatType{T: TypeDouble}
+		sfunc = &schema.Func{Name: "sumsq_sfunc", Schema: s, Lang: "sql", Body: "SELECT $1 + $2 * $2", Args: []*schema.FuncArg{{Type: float}, {Type: float}}, Ret: float}
+		zero  = "0"
+		sumsq = &Aggregate{
+			Name:      "sumsq",
+			Schema:    s,
+			Args:      []*schema.FuncArg{{Name: "v", Type: float}},
+			StateType: float,
+			StateFunc: "public.sumsq_sfunc",
+			InitCond:  &zero,
+			Parallel:  ParallelSafe,
+			Attrs:     []schema.Attr{&schema.Comment{Text: "sum of squares"}},
+		}
+		rows = &Aggregate{Name: "rows", Schema: s, StateType: &schema.IntegerType{T: TypeBigInt}, StateFunc: "int8inc", FinalFunc: "int8inc", InitCond: &zero}
+	)
+	// Aggregates are created after their functions.
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddObject{O: sumsq},
+		&schema.AddObject{O: rows},
+		&schema.AddFunc{F: sfunc},
+	})
+	require.NoError(t, err)
+	for i, c := range [][2]string{
+		{`CREATE FUNCTION "public"."sumsq_sfunc" (double precision, double precision) RETURNS double precision LANGUAGE sql AS $$SELECT $1 + $2 * $2$$`, `DROP FUNCTION "public"."sumsq_sfunc" (double precision, double precision)`},
+		{`CREATE AGGREGATE "public"."sumsq" ("v" double precision) (SFUNC = public.sumsq_sfunc, STYPE = double precision, INITCOND = '0', PARALLEL = SAFE)`, `DROP AGGREGATE "public"."sumsq" (double precision)`},
+		{`COMMENT ON AGGREGATE "public"."sumsq" (double precision) IS 'sum of squares'`, `COMMENT ON AGGREGATE "public"."sumsq" (double precision) IS ''`},
+		{`CREATE AGGREGATE "public"."rows" (*) (SFUNC = int8inc, STYPE = bigint, FINALFUNC = int8inc, INITCOND = '0')`, `DROP AGGREGATE "public"."rows" (*)`},
+	} {
+		require.Equal(t, c[0], plan.Changes[i].Cmd)
+		require.Equal(t, c[1], plan.Changes[i].Reverse)
+	}
+	require.Len(t, plan.Changes, 4)
+
+	// And dropped before them.
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.DropFunc{F: sfunc},
+		&schema.DropObject{O: sumsq},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `DROP AGGREGATE "public"."sumsq" (double precision)`, plan.Changes[0].Cmd)
+	require.Equal(t, `DROP FUNCTION "public"."sumsq_sfunc" (double precision, double precision)`, plan.Changes[1].Cmd)
+
+	// Comments are altered in place, and other changes recreate the aggregate.
+	from, to := schema.New("public"), schema.New("public")
+	from.AddObjects(sumsq, rows)
+	to.AddObjects(
+		&Aggregate{Name: "sumsq", Schema: to, Args: []*schema.FuncArg{{Name: "v", Type: float}}, StateType: float, StateFunc: "public.sumsq_sfunc", InitCond: &zero, Parallel: ParallelSafe},
+		&Aggregate{Name: "rows", Schema: to, StateType: &schema.IntegerType{T: TypeBigInt}, StateFunc: "int8inc", InitCond: &zero},
+		// Aggregates are identified by their name and argument types.
+		&Aggregate{Name: "rows", Schema: to, Args: []*schema.FuncArg{{Type: float}}, StateType: &schema.IntegerType{T: TypeBigInt}, StateFunc: "int8inc_any"},
+	)
+	changes, err := DefaultDiff.SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	for i, c := range [][2]string{
+		{`COMMENT ON AGGREGATE "public"."sumsq" (double precision) IS ''`, `COMMENT ON AGGREGATE "public"."sumsq" (double precision) IS 'sum of squares'`},
+		{`DROP AGGREGATE "public"."rows" (*)`, `CREATE AGGREGATE "public"."rows" (*) (SFUNC = int8inc, STYPE = bigint, FINALFUNC = int8inc, INITCOND = '0')`},
+		{`CREATE AGGREGATE "public"."rows" (*) (SFUNC = int8inc, STYPE = bigint, INITCOND = '0')`, `DROP AGGREGATE "public"."rows" (*)`},
+		{`CREATE AGGREGATE "public"."rows" (double precision) (SFUNC = int8inc_any, STYPE = bigint)`, `DROP AGGREGATE "public"."rows" (double precision)`},
+	} {
+		require.Equal(t, c[0], plan.Changes[i].Cmd)
+		require.Equal(t, c[1], plan.Changes[i].Reverse)
+	}
+	require.Len(t, plan.Changes, 4)
+}
+
+func TestPlanChanges_Partitions(t *testing.T) {
+	var (
+		s      = schema.New("public")
+		events = schema.NewTable("events").SetSchema(s).
+			AddColumns(schema.NewTimeColumn("day", TypeDate), schema.NewStringColumn("kind", TypeText))
+		p2023 = &TablePartition{Name: "events_2023", Schema: s, Table: events, Bound: "FOR VALUES FROM ('2023-01-01') TO ('2024-01-01')", PartitionBy: "LIST (kind)"}
+		click = &TablePartition{Name: "events_2023_click", Schema: s, Parent: p2023, Bound: "FOR VALUES IN ('click')", Attrs: []schema.Attr{&schema.Comment{Text: "clicks"}}}
+	)
+	events.AddAttrs(&Partition{T: PartitionTypeRange, Parts: []*PartitionPart{{C: events.Columns[0]}}})
+	// Partitions are created after their parents, and dropped before them.
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddObject{O: click},
+		&schema.AddObject{O: p2023},
+		&schema.AddTable{T: events},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 4)
+	require.Equal(t, `CREATE TABLE "public"."events" ("day" date NOT NULL, "kind" text NOT NULL) PARTITION BY RANGE ("day")`, plan.Changes[0].Cmd)
+	require.Equal(t, `CREATE TABLE "public"."events_2023" PARTITION OF "public"."events" FOR VALUES FROM ('2023-01-01') TO ('2024-01-01') PARTITION BY LIST (kind)`, plan.Changes[1].Cmd)
+	require.Equal(t, `DROP TABLE "public"."events_2023"`, plan.Changes[1].Reverse)
+	require.Equal(t, `CREATE TABLE "public"."events_2023_click" PARTITION OF "public"."events_2023" FOR VALUES IN ('click')`, plan.Changes[2].Cmd)
+	require.Equal(t, `COMMENT ON TABLE "public"."events_2023_click" IS 'clicks'`, plan.Changes[3].Cmd)
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.DropTable{T: events},
+		&schema.DropObject{O: p2023},
+		&schema.DropObject{O: click},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 3)
+	require.Equal(t, `DROP TABLE "public"."events_2023_click"`, plan.Changes[0].Cmd)
+	require.Equal(t, `DROP TABLE "public"."events_2023"`, plan.Changes[1].Cmd)
+	require.Equal(t, `DROP TABLE "public"."events"`, plan.Changes[2].Cmd)
+
+	// Changing the bound of a partition detaches and re-attaches it.
+	s.AddTables(events)
+	s.AddObjects(p2023, click)
+	to := schema.New("public")
+	to.AddTables(schema.NewTable("events").AddColumns(events.Columns...).AddAttrs(events.Attrs...))
+	toEvents, _ := to.Table("events")
+	to.AddObjects(
+		&TablePartition{Name: "events_2023", Schema: to, Table: toEvents, Bound: "for values from ('2023-01-01') to ('2024-01-01')", PartitionBy: "LIST (kind)"},
+		&TablePartition{Name: "events_old", Schema: to, Table: toEvents, Bound: "FOR VALUES FROM (MINVALUE) TO ('2023-01-01')"},
+	)
+	click2 := &TablePartition{Name: "events_2023_click", Schema: to, Table: toEvents, Bound: "DEFAULT"}
+	to.AddObjects(click2)
+	changes, err := DefaultDiff.SchemaDiff(s, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	for i, c := range [][2]string{
+		{`ALTER TABLE "public"."events_2023" DETACH PARTITION "public"."events_2023_click"`, `ALTER TABLE "public"."events_2023" ATTACH PARTITION "public"."events_2023_click" FOR VALUES IN ('click')`},
+		{`ALTER TABLE "public"."events" ATTACH PARTITION "public"."events_2023_click" DEFAULT`, `ALTER TABLE "public"."events" DETACH PARTITION "public"."events_2023_click"`},
+		{`COMMENT ON TABLE "public"."events_2023_click" IS ''`, `COMMENT ON TABLE "public"."events_2023_click" IS 'clicks'`},
+		{`CREATE TABLE "public"."events_old" PARTITION OF "public"."events" FOR VALUES FROM (MINVALUE) TO ('2023-01-01')`, `DROP TABLE "public"."events_old"`},
+	} {
+		require.Equal(t, c[0], plan.Changes[i].Cmd)
+		require.Equal(t, c[1], plan.Changes[i].Reverse)
+	}
+	require.Len(t, plan.Changes, 4)
+
+	// Partitions that are managed by a rotation policy are not dropped.
+	toEvents.AddAttrs(&PartitionRotation{Interval: RotateMonthly})
+	s.AddObjects(&TablePartition{Name: "events_p202610", Schema: s, Table: events, Bound: "FOR VALUES FROM ('2026-10-01') TO ('2026-11-01')"})
+	changes, err = DefaultDiff.SchemaDiff(s, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+}
+
+func TestPlanChanges_ColumnGrants(t *testing.T) {
+	var (
+		email = schema.NewStringColumn("email", "text").AddAttrs(&ColumnGrant{Grantee: "PUBLIC", Privileges: []string{"SELECT"}})
+		users = schema.NewTable("users").SetSchema(schema.New("public")).AddColumns(email)
+	)
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddTable{T: users}})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `GRANT SELECT ("email") ON "public"."users" TO PUBLIC`, plan.Changes[1].Cmd)
+	require.Equal(t, `REVOKE SELECT ("email") ON "public"."users" FROM PUBLIC`, plan.Changes[1].Reverse)
+
+	to := schema.NewStringColumn("email", "text").AddAttrs(
+		&ColumnGrant{Grantee: "PUBLIC", Privileges: []string{"select", "update"}},
+		&ColumnGrant{Grantee: "support", Privileges: []string{"SELECT"}, Grantable: true},
+	)
+	changes, err := DefaultDiff.TableDiff(users, schema.NewTable("users").SetSchema(users.Schema).AddColumns(to))
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, schema.ChangeAttr, changes[0].(*schema.ModifyColumn).Change)
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{T: users, Changes: changes},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `GRANT UPDATE ("email") ON "public"."users" TO PUBLIC`, plan.Changes[0].Cmd)
+	require.Equal(t, `GRANT SELECT ("email") ON "public"."users" TO "support" WITH GRANT OPTION`, plan.Changes[1].Cmd)
+	require.Equal(t, `REVOKE SELECT ("email") ON "public"."users" FROM "support"`, plan.Changes[1].Reverse)
+
+	// Removing the grant option requires re-granting the privileges.
+	from := to
+	to = schema.NewStringColumn("email", "text").AddAttrs(&ColumnGrant{Grantee: "support", Privileges: []string{"SELECT"}})
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{T: users, Changes: []schema.Change{&schema.ModifyColumn{From: from, To: to, Change: schema.ChangeAttr}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 3)
+	require.Equal(t, `REVOKE SELECT, UPDATE ("email") ON "public"."users" FROM PUBLIC`, plan.Changes[0].Cmd)
+	require.Equal(t, `REVOKE SELECT ("email") ON "public"."users" FROM "support"`, plan.Changes[1].Cmd)
+	require.Equal(t, `GRANT SELECT ("email") ON "public"."users" TO "support"`, plan.Changes[2].Cmd)
+}
+
+func TestPlanChanges_TableSchemaGrants(t *testing.T) {
+	var (
+		public = schema.New("public").AddAttrs(&SchemaGrant{Grantee: "app", Privileges: []string{"USAGE"}})
+		users  = schema.NewTable("users").
+			SetSchema(public).
+			AddColumns(schema.NewStringColumn("email", "text")).
+			AddAttrs(&TableGrant{Grantee: "app", Privileges: []string{"SELECT", "INSERT"}})
+	)
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddSchema{S: public}, &schema.AddTable{T: users}})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 4)
+	require.Equal(t, `GRANT USAGE ON SCHEMA "public" TO "app"`, plan.Changes[1].Cmd)
+	require.Equal(t, `REVOKE USAGE ON SCHEMA "public" FROM "app"`, plan.Changes[1].Reverse)
+	require.Equal(t, `GRANT INSERT, SELECT ON TABLE "public"."users" TO "app"`, plan.Changes[3].Cmd)
+	require.Equal(t, `REVOKE INSERT, SELECT ON TABLE "public"."users" FROM "app"`, plan.Changes[3].Reverse)
+
+	to := schema.NewTable("users").
+		SetSchema(public).
+		AddColumns(schema.NewStringColumn("email", "text")).
+		AddAttrs(
+			&TableGrant{Grantee: "app", Privileges: []string{"select", "update"}},
+			&TableGrant{Grantee: "PUBLIC", Privileges: []string{"SELECT"}, Grantable: true},
+		)
+	changes, err := DefaultDiff.TableDiff(users, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.AddAttr{A: &TableGrant{Grantee: "PUBLIC", Privileges: []string{"SELECT"}, Grantable: true}},
+		&schema.ModifyAttr{
+			From: &TableGrant{Grantee: "app", Privileges: []string{"INSERT", "SELECT"}},
+			To:   &TableGrant{Grantee: "app", Privileges: []string{"SELECT", "UPDATE"}},
+		},
+	}, changes)
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{T: to, Changes: changes},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 3)
+	require.Equal(t, `GRANT SELECT ON TABLE "public"."users" TO PUBLIC WITH GRANT OPTION`, plan.Changes[0].Cmd)
+	require.Equal(t, `REVOKE INSERT ON TABLE "public"."users" FROM "app"`, plan.Changes[1].Cmd)
+	require.Equal(t, `GRANT UPDATE ON TABLE "public"."users" TO "app"`, plan.Changes[2].Cmd)
+
+	// Dropping the table grants revokes all their privileges.
+	changes, err = DefaultDiff.TableDiff(to, schema.NewTable("users").SetSchema(public).AddColumns(schema.NewStringColumn("email", "text")))
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{T: to, Changes: changes},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `REVOKE SELECT ON TABLE "public"."users" FROM PUBLIC`, plan.Changes[0].Cmd)
+	require.Equal(t, `REVOKE SELECT, UPDATE ON TABLE "public"."users" FROM "app"`, plan.Changes[1].Cmd)
+
+	// Schema privileges.
+	changes, err = DefaultDiff.SchemaDiff(public, schema.New("public").AddAttrs(
+		&SchemaGrant{Grantee: "app", Privileges: []string{"USAGE", "CREATE"}},
+		&SchemaGrant{Grantee: "readonly", Privileges: []string{"USAGE"}},
+	))
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `GRANT CREATE ON SCHEMA "public" TO "app"`, plan.Changes[0].Cmd)
+	require.Equal(t, `GRANT USAGE ON SCHEMA "public" TO "readonly"`, plan.Changes[1].Cmd)
+	changes, err = DefaultDiff.SchemaDiff(public, schema.New("public"))
+	require.NoError(t, err)
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, `REVOKE USAGE ON SCHEMA "public" FROM "app"`, plan.Changes[0].Cmd)
+}
+
+func TestPlanChanges_Sequences(t *testing.T) {
+	var (
+		from  = schema.New("public")
+		to    = schema.New("public")
+		users = schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "bigint"))
+		maxv  = int64(1000)
+	)
+	to.AddTables(users)
+	from.AddObjects(
+		&Sequence{Name: "s1", Type: &schema.IntegerType{T: TypeBigInt}, Start: 1, Increment: 1, Cache: 1},
+		&Sequence{Name: "s2", Type: &schema.IntegerType{T: TypeBigInt}, Start: 1, Increment: 1, Cache: 1},
+	)
+	s3 := &Sequence{Name: "s3", Type: &schema.IntegerType{T: TypeInteger}, Start: 10, Increment: 2, Max: &maxv, Cycle: true, Attrs: []schema.Attr{&schema.Comment{Text: "c"}}}
+	s3.Owner.T, s3.Owner.C = users, users.Columns[0]
+	to.AddObjects(
+		&Sequence{Name: "s1"},
+		&Sequence{Name: "s2", Increment: 5, Cache: 10},
+		s3,
+	)
+	for _, s := range []*schema.Schema{from, to} {
+		for _, o := range s.Objects {
+			o.(*Sequence).Schema = s
+		}
+	}
+	changes, err := DefaultDiff.SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	require.IsType(t, &schema.ModifyObject{}, changes[0])
+	require.IsType(t, &schema.AddObject{}, changes[1])
+	require.IsType(t, &schema.AddTable{}, changes[2])
+
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 5)
+	require.Equal(t, `ALTER SEQUENCE "public"."s2" INCREMENT BY 5 CACHE 10`, plan.Changes[0].Cmd)
+	require.Equal(t, `ALTER SEQUENCE "public"."s2" INCREMENT BY 1 CACHE 1`, plan.Changes[0].Reverse)
+	require.Equal(t, `CREATE SEQUENCE "public"."s3" AS integer INCREMENT BY 2 MAXVALUE 1000 START WITH 10 CYCLE`, plan.Changes[1].Cmd)
+	require.Equal(t, `DROP SEQUENCE "public"."s3"`, plan.Changes[1].Reverse)
+	require.Equal(t, `COMMENT ON SEQUENCE "public"."s3" IS 'c'`, plan.Changes[2].Cmd)
+	require.Equal(t, `CREATE TABLE "public"."users" ("id" bigint NOT NULL)`, plan.Changes[3].Cmd)
+	require.Equal(t, `ALTER SEQUENCE "public"."s3" OWNED BY "public"."users"."id"`, plan.Changes[4].Cmd)
+	require.Equal(t, `ALTER SEQUENCE "public"."s3" OWNED BY NONE`, plan.Changes[4].Reverse)
+
+	// Drop sequences.
+	changes, err = DefaultDiff.SchemaDiff(to, from)
+	require.NoError(t, err)
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	cmds := make([]string, len(plan.Changes))
+	for i, c := range plan.Changes {
+		cmds[i] = c.Cmd
+	}
+	require.Contains(t, cmds, `DROP SEQUENCE IF EXISTS "public"."s3"`)
+	require.Contains(t, cmds, `DROP TABLE "public"."users"`)
+}
+
+func TestPlanChanges_AccessMethod(t *testing.T) {
+	var (
+		r = &schema.Realm{}
+		f = `table "events" {
+  schema        = schema.public
+  access_method = "columnar"
+  column "id" {
+    null = false
+    type = bigint
+  }
+}
+schema "public" {
+}
+`
+	)
+	require.NoError(t, EvalHCLBytes([]byte(f), r, nil))
+	t1 := r.Schemas[0].Tables[0]
+	require.Equal(t, []schema.Attr{&AccessMethod{V: "columnar"}}, t1.Attrs)
+	buf, err := MarshalHCL(r)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+
+	// Create a table with a non-default access method.
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddTable{T: t1}})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, `CREATE TABLE "public"."events" ("id" bigint NOT NULL) USING "columnar"`, plan.Changes[0].Cmd)
+
+	// Changing the access method rewrites the table.
+	t2 := schema.NewTable("events").SetSchema(schema.New("public")).AddColumns(schema.NewIntColumn("id", "bigint"))
+	changes, err := DefaultDiff.TableDiff(t2, t1)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{&schema.ModifyAttr{From: &AccessMethod{V: "heap"}, To: &AccessMethod{V: "columnar"}}}, changes)
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.ModifyTable{T: t1, Changes: changes}})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, `ALTER TABLE "public"."events" SET ACCESS METHOD "columnar"`, plan.Changes[0].Cmd)
+	require.Equal(t, `ALTER TABLE "public"."events" SET ACCESS METHOD "heap"`, plan.Changes[0].Reverse)
+
+	changes, err = DefaultDiff.TableDiff(t1, t1)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestPlanChanges_Inherits(t *testing.T) {
+	var (
+		s    = schema.New("public")
+		logs = schema.NewTable("logs").SetSchema(s).AddColumns(schema.NewIntColumn("id", TypeInt))
+		base = schema.NewTable("base").SetSchema(schema.New("audit")).AddColumns(schema.NewIntColumn("id", TypeInt))
+		y23  = schema.NewTable("logs_2023").SetSchema(s).AddColumns(schema.NewIntColumn("id", TypeInt)).
+			AddAttrs(&Inherits{T: []*schema.Table{logs, base}})
+	)
+	// Children are created after their parents, and dropped before them.
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddTable{T: y23},
+		&schema.AddTable{T: logs},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `CREATE TABLE "public"."logs" ("id" integer NOT NULL)`, plan.Changes[0].Cmd)
+	require.Equal(t, `CREATE TABLE "public"."logs_2023" ("id" integer NOT NULL) INHERITS ("public"."logs", "audit"."base")`, plan.Changes[1].Cmd)
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.DropTable{T: logs},
+		&schema.DropTable{T: y23},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `DROP TABLE "public"."logs_2023"`, plan.Changes[0].Cmd)
+	require.Equal(t, `DROP TABLE "public"."logs"`, plan.Changes[1].Cmd)
+
+	// Parents are attached and detached on modification.
+	s.AddTables(logs, y23)
+	to := schema.New("public")
+	to.AddTables(
+		schema.NewTable("logs").AddColumns(schema.NewIntColumn("id", TypeInt)),
+		schema.NewTable("archive").AddColumns(schema.NewIntColumn("id", TypeInt)),
+	)
+	archive, _ := to.Table("archive")
+	to.AddTables(schema.NewTable("logs_2023").AddColumns(schema.NewIntColumn("id", TypeInt)).AddAttrs(&Inherits{T: []*schema.Table{archive, base}}))
+	changes, err := DefaultDiff.SchemaDiff(s, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `CREATE TABLE "public"."archive" ("id" integer NOT NULL)`, plan.Changes[0].Cmd)
+	require.Equal(t, `ALTER TABLE "public"."logs_2023" NO INHERIT "public"."logs", INHERIT "public"."archive"`, plan.Changes[1].Cmd)
+	require.Equal(t, `ALTER TABLE "public"."logs_2023" NO INHERIT "public"."archive", INHERIT "public"."logs"`, plan.Changes[1].Reverse)
+}
+
+func TestPlanChanges_StorageParams(t *testing.T) {
+	var (
+		s      = schema.New("public")
+		events = schema.NewTable("events").SetSchema(s).AddColumns(schema.NewIntColumn("id", TypeInt)).
+			AddAttrs(&StorageParams{V: []StorageParam{{Name: "fillfactor", Value: "70"}, {Name: "toast.autovacuum_enabled", Value: "false"}}})
+	)
+	events.AddIndexes(schema.NewIndex("events_id").AddColumns(events.Columns[0]).
+		AddAttrs(&StorageParams{V: []StorageParam{{Name: "fillfactor", Value: "80"}}}))
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddTable{T: events},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `CREATE TABLE "public"."events" ("id" integer NOT NULL) WITH (fillfactor = 70, toast.autovacuum_enabled = false)`, plan.Changes[0].Cmd)
+	require.Equal(t, `CREATE INDEX "events_id" ON "public"."events" ("id") WITH (fillfactor = 80)`, plan.Changes[1].Cmd)
+
+	// Changed parameters are set, and removed parameters are reset.
+	to := schema.NewTable("events").SetSchema(schema.New("public")).AddColumns(schema.NewIntColumn("id", TypeInt)).
+		AddAttrs(&StorageParams{V: []StorageParam{{Name: "fillfactor", Value: "90"}, {Name: "autovacuum_vacuum_scale_factor", Value: "0.05"}}})
+	to.AddIndexes(schema.NewIndex("events_id").AddColumns(to.Columns[0]).
+		AddAttrs(&StorageParams{V: []StorageParam{{Name: "fillfactor", Value: "80"}}}))
+	changes, err := DefaultDiff.TableDiff(events, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{T: to, Changes: changes},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, `ALTER TABLE "public"."events" SET (fillfactor = 90, autovacuum_vacuum_scale_factor = 0.05), RESET (toast.autovacuum_enabled)`, plan.Changes[0].Cmd)
+	require.Equal(t, `ALTER TABLE "public"."events" SET (fillfactor = 70, toast.autovacuum_enabled = false), RESET (autovacuum_vacuum_scale_factor)`, plan.Changes[0].Reverse)
+
+	// Parameters order is ignored.
+	to.Attrs = []schema.Attr{&StorageParams{V: []StorageParam{{Name: "toast.autovacuum_enabled", Value: "false"}, {Name: "fillfactor", Value: "70"}}}}
+	changes, err = DefaultDiff.TableDiff(events, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestPlanChanges_Compression(t *testing.T) {
+	var (
+		logs = schema.NewTable("logs").SetSchema(schema.New("public")).AddColumns(
+			schema.NewIntColumn("id", TypeInt),
+			schema.NewStringColumn("body", TypeText).AddAttrs(&Compression{V: "lz4"}),
+		)
+	)
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddTable{T: logs},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, `CREATE TABLE "public"."logs" ("id" integer NOT NULL, "body" text COMPRESSION lz4 NOT NULL)`, plan.Changes[0].Cmd)
+
+	// Compression methods are changed, or reset to the default.
+	to := schema.NewTable("logs").SetSchema(schema.New("public")).AddColumns(
+		schema.NewIntColumn("id", TypeInt).AddAttrs(&Compression{V: "pglz"}),
+		schema.NewStringColumn("body", TypeText),
+	)
+	changes, err := DefaultDiff.TableDiff(logs, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{T: to, Changes: changes},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, `ALTER TABLE "public"."logs" ALTER COLUMN "id" SET COMPRESSION pglz, ALTER COLUMN "body" SET COMPRESSION default`, plan.Changes[0].Cmd)
+	require.Equal(t, `ALTER TABLE "public"."logs" ALTER COLUMN "body" SET COMPRESSION lz4, ALTER COLUMN "id" SET COMPRESSION default`, plan.Changes[0].Reverse)
+
+	// The default compression method is equal to no compression method.
+	to.Columns[0].Attrs = nil
+	to.Columns[1].Attrs = []schema.Attr{&Compression{V: "LZ4"}}
+	logs.Columns[0].Attrs = []schema.Attr{&Compression{V: "default"}}
+	changes, err = DefaultDiff.TableDiff(logs, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestPlanChanges_Unlogged(t *testing.T) {
+	var (
+		staging = schema.NewTable("staging").SetSchema(schema.New("public")).AddColumns(schema.NewIntColumn("id", TypeInt)).
+			AddAttrs(&Unlogged{})
+		copied = schema.NewTable("staging_copy").SetSchema(schema.New("public")).AddAttrs(&Unlogged{})
+	)
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddTable{T: staging},
+		&schema.CloneTable{T: copied, Source: staging},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `CREATE UNLOGGED TABLE "public"."staging" ("id" integer NOT NULL)`, plan.Changes[0].Cmd)
+	require.Equal(t, `CREATE UNLOGGED TABLE "public"."staging_copy" (LIKE "public"."staging" INCLUDING ALL EXCLUDING INDEXES)`, plan.Changes[1].Cmd)
+
+	// Tables are switched between logged and unlogged.
+	to := schema.NewTable("staging").SetSchema(schema.New("public")).AddColumns(schema.NewIntColumn("id", TypeInt))
+	changes, err := DefaultDiff.TableDiff(staging, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{&schema.DropAttr{A: &Unlogged{}}}, changes)
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{T: to, Changes: changes},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, `ALTER TABLE "public"."staging" SET LOGGED`, plan.Changes[0].Cmd)
+	require.Equal(t, `ALTER TABLE "public"."staging" SET UNLOGGED`, plan.Changes[0].Reverse)
+
+	changes, err = DefaultDiff.TableDiff(to, staging)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{&schema.AddAttr{A: &Unlogged{}}}, changes)
+	changes, err = DefaultDiff.TableDiff(staging, staging)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestPlanChanges_Tablespace(t *testing.T) {
+	var (
+		s      = schema.New("public")
+		events = schema.NewTable("events").SetSchema(s).AddColumns(schema.NewIntColumn("id", TypeInt), schema.NewIntColumn("c", TypeInt)).
+			AddAttrs(&Tablespace{V: "fast"})
+	)
+	events.SetPrimaryKey(schema.NewPrimaryKey(events.Columns[0]).AddAttrs(&Tablespace{V: "fast_idx"}))
+	events.AddIndexes(schema.NewIndex("events_c").AddColumns(events.Columns[1]).AddAttrs(&Tablespace{V: "fast_idx"}))
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddTable{T: events},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `CREATE TABLE "public"."events" ("id" integer NOT NULL, "c" integer NOT NULL, PRIMARY KEY ("id") USING INDEX TABLESPACE "fast_idx") TABLESPACE "fast"`, plan.Changes[0].Cmd)
+	require.Equal(t, `CREATE INDEX "events_c" ON "public"."events" ("c") TABLESPACE "fast_idx"`, plan.Changes[1].Cmd)
+
+	// Tables and indexes are moved to other tablespaces.
+	to := schema.NewTable("events").SetSchema(schema.New("public")).AddColumns(schema.NewIntColumn("id", TypeInt), schema.NewIntColumn("c", TypeInt))
+	to.SetPrimaryKey(schema.NewPrimaryKey(to.Columns[0]).AddAttrs(&Tablespace{V: "fast_idx"}))
+	to.AddIndexes(schema.NewIndex("events_c").AddColumns(to.Columns[1]).AddAttrs(&Tablespace{V: "slow_idx"}))
+	changes, err := DefaultDiff.TableDiff(events, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{T: to, Changes: changes},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `ALTER TABLE "public"."events" SET TABLESPACE "pg_default"`, plan.Changes[0].Cmd)
+	require.Equal(t, `ALTER TABLE "public"."events" SET TABLESPACE "fast"`, plan.Changes[0].Reverse)
+	require.Equal(t, `ALTER INDEX "public"."events_c" SET TABLESPACE "slow_idx"`, plan.Changes[1].Cmd)
+	require.Equal(t, `ALTER INDEX "public"."events_c" SET TABLESPACE "fast_idx"`, plan.Changes[1].Reverse)
+
+	// The default tablespace is equal to no tablespace.
+	to.Attrs = []schema.Attr{&Tablespace{V: "fast"}}
+	to.Indexes[0].Attrs = []schema.Attr{&Tablespace{V: "fast_idx"}}
+	events.Attrs = []schema.Attr{&Tablespace{V: "fast"}}
+	to.PrimaryKey.Attrs = nil
+	events.PrimaryKey.Attrs = []schema.Attr{&Tablespace{V: "pg_default"}}
+	changes, err = DefaultDiff.TableDiff(events, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestPlanChanges_OnDeleteColumns(t *testing.T) {
+	var (
+		public = schema.New("public")
+		users  = schema.NewTable("users").SetSchema(public).AddColumns(schema.NewIntColumn("tenant_id", TypeInt), schema.NewIntColumn("id", TypeInt))
+		posts  = schema.NewTable("posts").SetSchema(public).AddColumns(schema.NewIntColumn("tenant_id", TypeInt), schema.NewNullIntColumn("author_id", TypeInt))
+		fk     = schema.NewForeignKey("author").
+			AddColumns(posts.Columns...).
+			SetRefTable(users).
+			AddRefColumns(users.Columns...).
+			SetOnDelete(schema.SetNull).
+			AddAttrs(&OnDeleteColumns{Columns: posts.Columns[1:]})
+	)
+	posts.AddForeignKeys(fk)
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddTable{T: posts},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, `CREATE TABLE "public"."posts" ("tenant_id" integer NOT NULL, "author_id" integer NULL, CONSTRAINT "author" FOREIGN KEY ("tenant_id", "author_id") REFERENCES "public"."users" ("tenant_id", "id") ON DELETE SET NULL ("author_id"))`, plan.Changes[0].Cmd)
+
+	// Identical column lists are not changed.
+	to := schema.NewTable("posts").SetSchema(public).AddColumns(schema.NewIntColumn("tenant_id", TypeInt), schema.NewNullIntColumn("author_id", TypeInt))
+	to.AddForeignKeys(
+		schema.NewForeignKey("author").
+			AddColumns(to.Columns...).
+			SetRefTable(users).
+			AddRefColumns(users.Columns...).
+			SetOnDelete(schema.SetNull).
+			AddAttrs(&OnDeleteColumns{Columns: []*schema.Column{schema.NewColumn("author_id")}}),
+	)
+	changes, err := DefaultDiff.TableDiff(posts, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	// Dropping the column list recreates the foreign key.
+	to.ForeignKeys[0].Attrs = nil
+	changes, err = DefaultDiff.TableDiff(posts, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, schema.ChangeAttr, changes[0].(*schema.ModifyForeignKey).Change)
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{T: to, Changes: changes},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, `ALTER TABLE "public"."posts" DROP CONSTRAINT "author", ADD CONSTRAINT "author" FOREIGN KEY ("tenant_id", "author_id") REFERENCES "public"."users" ("tenant_id", "id") ON DELETE SET NULL`, plan.Changes[0].Cmd)
+	require.Equal(t, `ALTER TABLE "public"."posts" DROP CONSTRAINT "author", ADD CONSTRAINT "author" FOREIGN KEY ("tenant_id", "author_id") REFERENCES "public"."users" ("tenant_id", "id") ON DELETE SET NULL ("author_id")`, plan.Changes[0].Reverse)
+}
+
+func TestPlanChanges_PrettyFuncs(t *testing.T) {
+	var (
+		public = schema.New("public")
+		audit  = &schema.Func{
+			Name: "audit", Schema: public, Lang: "plpgsql", Ret: &PseudoType{T: "trigger"},
+			Body: "BEGIN INSERT INTO audit_log (tbl, op) VALUES (TG_TABLE_NAME, TG_OP); RETURN NEW; END",
+		}
+		cleanup = &schema.Proc{
+			Name: "cleanup", Schema: public, Lang: "plpgsql",
+			Body: "\n\tBEGIN\n\t\tDELETE FROM audit_log WHERE ts < now() - interval '30 days';\n\tEND;\n",
+		}
+	)
+	// Bodies are written as-is by default.
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddFunc{F: audit}})
+	require.NoError(t, err)
+	require.Equal(t, `CREATE FUNCTION "public"."audit" () RETURNS trigger LANGUAGE plpgsql AS $$`+audit.Body+`$$`, plan.Changes[0].Cmd)
+
+	// Indented plans break the bodies into lines.
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddFunc{F: audit}, &schema.AddProc{P: cleanup}}, func(o *migrate.PlanOptions) {
+		o.Indent = "  "
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `CREATE FUNCTION "public"."audit" () RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+  INSERT INTO audit_log (tbl, op) VALUES (TG_TABLE_NAME, TG_OP);
+  RETURN NEW;
+END
+$$`, plan.Changes[0].Cmd)
+	require.Equal(t, `CREATE PROCEDURE "public"."cleanup" () LANGUAGE plpgsql AS $$
+BEGIN
+  DELETE FROM audit_log WHERE ts < now() - interval '30 days';
+END;
+$$`, plan.Changes[1].Cmd)
+
+	// Bodies that differ only in their layout are equal.
+	changes, err := DefaultDiff.SchemaDiff(
+		schema.New("public").AddFuncs(&schema.Func{Name: "audit", Lang: "plpgsql", Ret: audit.Ret, Body: "\nBEGIN\n    INSERT INTO audit_log (tbl, op) VALUES (TG_TABLE_NAME, TG_OP);\n    RETURN NEW;\nEND\n"}),
+		schema.New("public").AddFuncs(&schema.Func{Name: "audit", Lang: "plpgsql", Ret: audit.Ret, Body: audit.Body}),
+	)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestPlanChanges_FuncSigError(t *testing.T) {
+	var (
+		public = schema.New("public")
+		args   = []*schema.FuncArg{{Name: "a", Type: &schema.IntegerType{T: "int"}}, {Name: "b", Type: &schema.UnsupportedType{T: "unknown"}}}
+		f1     = &schema.Func{Name: "f1", Schema: public, Args: args, Ret: &schema.IntegerType{T: "int"}}
+		f2     = &schema.Func{Name: "f2", Schema: public, Args: args, Ret: &schema.IntegerType{T: "int"}}
+		p1     = &schema.Proc{Name: "p1", Schema: public, Args: args}
+		p2     = &schema.Proc{Name: "p2", Schema: public, Args: args}
+	)
+	// Arguments that cannot be formatted fail the
+	// plan instead of being dropped from the signature.
+	_, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.RenameFunc{From: f1, To: f2}})
+	require.EqualError(t, err, `postgres: format type of argument 2 of "f1": postgres: unsupported type: "unknown"`)
+	_, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.RenameProc{From: p1, To: p2}})
+	require.EqualError(t, err, `postgres: format type of argument 2 of "p1": postgres: unsupported type: "unknown"`)
+}
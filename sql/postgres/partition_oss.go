@@ -0,0 +1,239 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+//go:build !ent
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+// PartitionRotation describes a time-based maintenance policy of a table that is
+// partitioned by RANGE on a single date or timestamp column. Partitions that are
+// managed by the policy are named after the table and the start of their range.
+// For example, "events_p202610" holds the rows of October 2026 of table "events".
+type PartitionRotation struct {
+	schema.Attr
+	// Interval is the range covered by each partition.
+	// One of: day, week, month or year.
+	Interval string
+	// Premake is the number of future partitions to be
+	// created ahead of time, in addition to the current one.
+	Premake int
+	// Retention is the number of past partitions to keep. Older
+	// partitions are removed. Zero means partitions are kept forever.
+	Retention int
+	// Detach indicates expired partitions are detached
+	// from the table instead of being dropped.
+	Detach bool
+}
+
+// Partition rotation intervals.
+const (
+	RotateDaily   = "day"
+	RotateWeekly  = "week"
+	RotateMonthly = "month"
+	RotateYearly  = "year"
+)
+
+// start returns the start of the interval that contains t.
+func (r *PartitionRotation) start(t time.Time) time.Time {
+	y, m, d := t.Date()
+	switch r.Interval {
+	case RotateWeekly:
+		return time.Date(y, m, d-(int(t.Weekday())+6)%7, 0, 0, 0, 0, t.Location())
+	case RotateMonthly:
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+	case RotateYearly:
+		return time.Date(y, 1, 1, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	}
+}
+
+// add returns the start of the n-th interval after (or before) the given start.
+func (r *PartitionRotation) add(t time.Time, n int) time.Time {
+	switch r.Interval {
+	case RotateWeekly:
+		return t.AddDate(0, 0, 7*n)
+	case RotateMonthly:
+		return t.AddDate(0, n, 0)
+	case RotateYearly:
+		return t.AddDate(n, 0, 0)
+	default:
+		return t.AddDate(0, 0, n)
+	}
+}
+
+// layout returns the time layout used for naming the partitions.
+func (r *PartitionRotation) layout() string {
+	switch r.Interval {
+	case RotateMonthly:
+		return "200601"
+	case RotateYearly:
+		return "2006"
+	default:
+		return "20060102"
+	}
+}
+
+// validate checks the rotation policy is valid for the given table and
+// returns the column used as the partition key.
+func (r *PartitionRotation) validate(t *schema.Table) (*schema.Column, error) {
+	switch r.Interval {
+	case RotateDaily, RotateWeekly, RotateMonthly, RotateYearly:
+	default:
+		return nil, fmt.Errorf("unknown rotation interval %q for table %q", r.Interval, t.Name)
+	}
+	if r.Premake < 0 || r.Retention < 0 {
+		return nil, fmt.Errorf("negative premake or retention for table %q", t.Name)
+	}
+	var p Partition
+	if !sqlx.Has(t.Attrs, &p) || !strings.EqualFold(p.T, PartitionTypeRange) {
+		return nil, fmt.Errorf("partition rotation requires table %q to be partitioned by RANGE", t.Name)
+	}
+	if len(p.Parts) != 1 || p.Parts[0].C == nil {
+		return nil, fmt.Errorf("partition rotation requires table %q to be partitioned by a single column", t.Name)
+	}
+	c := p.Parts[0].C
+	if _, ok := c.Type.Type.(*schema.TimeType); !ok {
+		return nil, fmt.Errorf("partition rotation requires column %q of table %q to be a date or timestamp", c.Name, t.Name)
+	}
+	return c, nil
+}
+
+// RotatePartitions returns the changes needed to bring the partitions of the given table to
+// the state described by its PartitionRotation attribute at the given time. That is, create
+// the current partition and the partitions to be premade, and detach or drop the partitions
+// that are out of the retention window. Existing partitions that do not follow the naming
+// of the policy are ignored. The returned changes are suitable for running periodically, for
+// example, from a pg_cron job.
+func (d *Driver) RotatePartitions(ctx context.Context, t *schema.Table, now time.Time, opts ...migrate.PlanOption) ([]*migrate.Change, error) {
+	r := &PartitionRotation{}
+	if !sqlx.Has(t.Attrs, r) {
+		return nil, fmt.Errorf("postgres: missing partition rotation policy for table %q", t.Name)
+	}
+	c, err := r.validate(t)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: %w", err)
+	}
+	exists, err := d.partitions(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		o       migrate.PlanOptions
+		changes []*migrate.Change
+		current = r.start(now)
+		prefix  = t.Name + "_p"
+	)
+	for _, opt := range opts {
+		opt(&o)
+	}
+	// Remove the partitions that are out of the retention window.
+	if r.Retention > 0 {
+		horizon := r.add(current, -r.Retention)
+		for _, name := range exists {
+			if !strings.HasPrefix(name, prefix) {
+				continue
+			}
+			start, err := time.ParseInLocation(r.layout(), strings.TrimPrefix(name, prefix), now.Location())
+			if err != nil || !start.Before(horizon) {
+				continue
+			}
+			p := partitionOf(t, name)
+			attach := d.StmtBuilder(o).P("ALTER TABLE").Table(t).P("ATTACH PARTITION").Table(p).P(partitionBound(c, r, start)).String()
+			if r.Detach {
+				changes = append(changes, &migrate.Change{
+					Cmd:     d.StmtBuilder(o).P("ALTER TABLE").Table(t).P("DETACH PARTITION").Table(p).String(),
+					Reverse: attach,
+					Comment: fmt.Sprintf("detach expired partition %q", name),
+				})
+			} else {
+				changes = append(changes, &migrate.Change{
+					Cmd:     d.StmtBuilder(o).P("DROP TABLE").Table(p).String(),
+					Comment: fmt.Sprintf("drop expired partition %q", name),
+				})
+			}
+		}
+	}
+	// Create the current partition and the ones ahead of it.
+	for i := 0; i <= r.Premake; i++ {
+		start := r.add(current, i)
+		name := prefix + start.Format(r.layout())
+		if slices.Contains(exists, name) {
+			continue
+		}
+		p := partitionOf(t, name)
+		changes = append(changes, &migrate.Change{
+			Cmd:     d.StmtBuilder(o).P("CREATE TABLE").Table(p).P("PARTITION OF").Table(t).P(partitionBound(c, r, start)).String(),
+			Reverse: d.StmtBuilder(o).P("DROP TABLE").Table(p).String(),
+			Comment: fmt.Sprintf("create partition %q", name),
+		})
+	}
+	return changes, nil
+}
+
+// partitions returns the names of the partitions attached to the given table.
+func (d *Driver) partitions(ctx context.Context, t *schema.Table) ([]string, error) {
+	args := []any{t.Name}
+	query := fmt.Sprintf(partitionsQuery, "current_schema()")
+	if t.Schema != nil && t.Schema.Name != "" {
+		args = append(args, t.Schema.Name)
+		query = fmt.Sprintf(partitionsQuery, "$2")
+	}
+	rows, err := d.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: querying partitions of table %q: %w", t.Name, err)
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("postgres: scanning partitions of table %q: %w", t.Name, err)
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// partitionOf returns a table that represents the named partition of t.
+func partitionOf(t *schema.Table, name string) *schema.Table {
+	return &schema.Table{Name: name, Schema: t.Schema}
+}
+
+// partitionBound returns the FOR VALUES clause of the partition starting at the given time.
+func partitionBound(c *schema.Column, r *PartitionRotation, start time.Time) string {
+	layout := "2006-01-02"
+	if t, ok := c.Type.Type.(*schema.TimeType); ok && (t.T == TypeTimestampTZ || t.T == TypeTimestampWTZ) {
+		layout = "2006-01-02 15:04:05-07:00"
+	}
+	return fmt.Sprintf("FOR VALUES FROM (%s) TO (%s)", quote(start.Format(layout)), quote(r.add(start, 1).Format(layout)))
+}
+
+// Query to list the partitions of a table.
+const partitionsQuery = `
+SELECT
+	c.relname
+FROM
+	pg_catalog.pg_inherits AS i
+	JOIN pg_catalog.pg_class AS c ON c.oid = i.inhrelid
+	JOIN pg_catalog.pg_class AS p ON p.oid = i.inhparent
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = p.relnamespace
+WHERE
+	p.relname = $1
+	AND n.nspname = %s
+ORDER BY
+	c.relname
+`
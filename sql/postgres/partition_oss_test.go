@@ -0,0 +1,90 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+//go:build !ent
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriver_RotatePartitions(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("130000")
+	d, err := Open(db)
+	require.NoError(t, err)
+	drv := d.(*Driver)
+
+	c := schema.NewTimeColumn("created_at", TypeDate)
+	events := schema.NewTable("events").
+		SetSchema(schema.New("public")).
+		AddColumns(c).
+		AddAttrs(
+			&Partition{T: PartitionTypeRange, Parts: []*PartitionPart{{C: c}}},
+			&PartitionRotation{Interval: RotateMonthly, Premake: 2, Retention: 2},
+		)
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(partitionsQuery, "$2"))).
+		WithArgs("events", "public").
+		WillReturnRows(sqltest.Rows(`
+     relname
+----------------
+ events_default
+ events_p202607
+ events_p202608
+ events_p202610
+`))
+	now := time.Date(2026, time.October, 16, 12, 0, 0, 0, time.UTC)
+	changes, err := drv.RotatePartitions(context.Background(), events, now)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	require.Equal(t, `DROP TABLE "public"."events_p202607"`, changes[0].Cmd)
+	require.Nil(t, changes[0].Reverse)
+	require.Equal(t, `CREATE TABLE "public"."events_p202611" PARTITION OF "public"."events" FOR VALUES FROM ('2026-11-01') TO ('2026-12-01')`, changes[1].Cmd)
+	require.Equal(t, `DROP TABLE "public"."events_p202611"`, changes[1].Reverse)
+	require.Equal(t, `CREATE TABLE "public"."events_p202612" PARTITION OF "public"."events" FOR VALUES FROM ('2026-12-01') TO ('2027-01-01')`, changes[2].Cmd)
+
+	// Detach expired partitions, and use timestamps with time zone.
+	c.Type.Type = &schema.TimeType{T: TypeTimestampWTZ}
+	events.Attrs[1] = &PartitionRotation{Interval: RotateWeekly, Retention: 1, Detach: true}
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(partitionsQuery, "$2"))).
+		WithArgs("events", "public").
+		WillReturnRows(sqltest.Rows(`
+     relname
+-----------------
+ events_p20261005
+ events_p20261012
+`))
+	changes, err = drv.RotatePartitions(context.Background(), events, now)
+	require.NoError(t, err)
+	require.Empty(t, changes, "current and previous week exist")
+
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(partitionsQuery, "$2"))).
+		WithArgs("events", "public").
+		WillReturnRows(sqltest.Rows(`
+     relname
+-----------------
+ events_p20260928
+`))
+	changes, err = drv.RotatePartitions(context.Background(), events, now)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	require.Equal(t, `ALTER TABLE "public"."events" DETACH PARTITION "public"."events_p20260928"`, changes[0].Cmd)
+	require.Equal(t, `ALTER TABLE "public"."events" ATTACH PARTITION "public"."events_p20260928" FOR VALUES FROM ('2026-09-28 00:00:00+00:00') TO ('2026-10-05 00:00:00+00:00')`, changes[0].Reverse)
+	require.Equal(t, `CREATE TABLE "public"."events_p20261012" PARTITION OF "public"."events" FOR VALUES FROM ('2026-10-12 00:00:00+00:00') TO ('2026-10-19 00:00:00+00:00')`, changes[1].Cmd)
+	require.NoError(t, m.ExpectationsWereMet())
+
+	_, err = drv.RotatePartitions(context.Background(), schema.NewTable("logs"), now)
+	require.EqualError(t, err, `postgres: missing partition rotation policy for table "logs"`)
+}
@@ -0,0 +1,165 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgrescheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"ariga.io/atlas/schemahcl"
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/sqlcheck"
+)
+
+// persistence checks for changes of the table persistence (LOGGED/UNLOGGED),
+// which rewrite the entire table and affect its replication and crash-safety.
+type persistence struct {
+	sqlcheck.Options
+}
+
+// newPersistence creates a new table-persistence Analyzer with the given options.
+func newPersistence(r *schemahcl.Resource) (*persistence, error) {
+	az := &persistence{}
+	if r, ok := r.Resource(az.Name()); ok {
+		if err := r.As(&az.Options); err != nil {
+			return nil, fmt.Errorf("sql/sqlcheck: parsing persistence check options: %w", err)
+		}
+	}
+	return az, nil
+}
+
+// List of codes.
+var (
+	codeSetLogged      = sqlcheck.Code("PS101")
+	codeSetUnlogged    = sqlcheck.Code("PS102")
+	codeCreateUnlogged = sqlcheck.Code("PS103")
+)
+
+var (
+	reName           = `((?:"[^"]+"|[\w$]+)(?:\.(?:"[^"]+"|[\w$]+))?)`
+	reAlterTable     = regexp.MustCompile(`(?is)^\s*ALTER\s+TABLE\s+(?:IF\s+EXISTS\s+)?(?:ONLY\s+)?` + reName + `\s+(.+)$`)
+	reSetLogged      = regexp.MustCompile(`(?i)\bSET\s+(LOGGED|UNLOGGED)\b`)
+	reCreateUnlogged = regexp.MustCompile(`(?is)^\s*CREATE\s+UNLOGGED\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?` + reName)
+)
+
+// Name of the analyzer. Implements the sqlcheck.NamedAnalyzer interface.
+func (*persistence) Name() string {
+	return "persistence"
+}
+
+// Analyze implements sqlcheck.Analyzer.
+func (a *persistence) Analyze(_ context.Context, p *sqlcheck.Pass) error {
+	var diags []sqlcheck.Diagnostic
+	for _, sc := range p.File.Changes {
+		if sc.Stmt == nil {
+			continue
+		}
+		if m := reCreateUnlogged.FindStringSubmatch(sc.Stmt.Text); m != nil {
+			diags = append(diags, sqlcheck.Diagnostic{
+				Code: codeCreateUnlogged,
+				Pos:  sc.Stmt.Pos,
+				Text: fmt.Sprintf(
+					"Creating unlogged table %s. Its data is not replicated to standby servers and is truncated after a crash",
+					unquote(m[1]),
+				),
+			})
+			continue
+		}
+		m := reAlterTable.FindStringSubmatch(sc.Stmt.Text)
+		if m == nil {
+			continue
+		}
+		set := reSetLogged.FindStringSubmatch(m[2])
+		// Tables created in this file are empty, and
+		// changing their persistence does not cost much.
+		if set == nil || !existingTable(p.File, m[1]) {
+			continue
+		}
+		name := unquote(m[1])
+		switch strings.ToUpper(set[1]) {
+		case "LOGGED":
+			diags = append(diags, sqlcheck.Diagnostic{
+				Code: codeSetLogged,
+				Pos:  sc.Stmt.Pos,
+				Text: fmt.Sprintf(
+					"Setting table %s as logged rewrites it under an ACCESS EXCLUSIVE lock and writes its entire content to the WAL, which may cause replication lag on standby servers",
+					name,
+				),
+			})
+		default:
+			diags = append(diags, sqlcheck.Diagnostic{
+				Code: codeSetUnlogged,
+				Pos:  sc.Stmt.Pos,
+				Text: fmt.Sprintf(
+					"Setting table %s as unlogged rewrites it under an ACCESS EXCLUSIVE lock. Its data will no longer be replicated to standby servers and is truncated after a crash",
+					name,
+				),
+			})
+		}
+	}
+	if len(diags) > 0 {
+		const reportText = "table persistence changes detected"
+		p.Reporter.WriteReport(sqlcheck.Report{Text: reportText, Diagnostics: diags})
+		if sqlx.V(a.Error) {
+			return errors.New(reportText)
+		}
+	}
+	return nil
+}
+
+// existingTable reports if the table existed before the file was executed.
+// If the state before the file is unknown, the table is assumed to exist.
+func existingTable(f *sqlcheck.File, name string) bool {
+	if f.From == nil {
+		return true
+	}
+	var s string
+	if parts := splitName(name); len(parts) == 2 {
+		s, name = parts[0], parts[1]
+	} else {
+		name = parts[0]
+	}
+	for _, sc := range f.From.Schemas {
+		if s != "" && sc.Name != s {
+			continue
+		}
+		if _, ok := sc.Table(name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// splitName splits a possibly qualified identifier into its parts. Unquoted
+// parts are folded to lower case, as they are resolved by the database.
+func splitName(name string) []string {
+	var (
+		parts  []string
+		quoted bool
+		b      strings.Builder
+	)
+	for _, r := range name {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == '.' && !quoted:
+			parts = append(parts, b.String())
+			b.Reset()
+		case quoted:
+			b.WriteRune(r)
+		default:
+			b.WriteString(strings.ToLower(string(r)))
+		}
+	}
+	return append(parts, b.String())
+}
+
+// unquote returns the identifier as it is displayed in diagnostics.
+func unquote(name string) string {
+	return fmt.Sprintf("%q", strings.Join(splitName(name), "."))
+}
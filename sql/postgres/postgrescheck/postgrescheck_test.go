@@ -55,6 +55,46 @@ func TestDataDepend_MightFail(t *testing.T) {
 	require.Equal(t, report.Diagnostics[0].Text, `Adding a non-nullable "int" column "b" will fail in case table "users" is not empty`)
 }
 
+func TestPersistence(t *testing.T) {
+	var (
+		report *sqlcheck.Report
+		stmts  = []string{
+			`CREATE UNLOGGED TABLE "staging" ("id" int)`,
+			`ALTER TABLE "staging" SET LOGGED`,
+			`ALTER TABLE public.Events ADD COLUMN "c" int, SET LOGGED`,
+			`ALTER TABLE IF EXISTS ONLY "public"."logs" SET UNLOGGED`,
+			`ALTER TABLE "logs" SET (fillfactor = 70)`,
+		}
+		pass = &sqlcheck.Pass{
+			File: &sqlcheck.File{
+				File: testFile{name: "1.sql"},
+				From: schema.NewRealm(schema.New("public").AddTables(
+					schema.NewTable("events"),
+					schema.NewTable("logs"),
+				)),
+			},
+			Reporter: sqlcheck.ReportWriterFunc(func(r sqlcheck.Report) {
+				report = &r
+			}),
+		}
+	)
+	for i, s := range stmts {
+		pass.File.Changes = append(pass.File.Changes, &sqlcheck.Change{Stmt: &migrate.Stmt{Pos: i, Text: s}})
+	}
+	azs, err := sqlcheck.AnalyzerFor(postgres.DriverName, nil)
+	require.NoError(t, err)
+	require.NoError(t, sqlcheck.Analyzers(azs).Analyze(context.Background(), pass))
+	require.Equal(t, "table persistence changes detected", report.Text)
+	require.Len(t, report.Diagnostics, 3)
+	require.Equal(t, "PS103", report.Diagnostics[0].Code)
+	require.Equal(t, `Creating unlogged table "staging". Its data is not replicated to standby servers and is truncated after a crash`, report.Diagnostics[0].Text)
+	require.Equal(t, "PS101", report.Diagnostics[1].Code)
+	require.Equal(t, 2, report.Diagnostics[1].Pos)
+	require.Equal(t, `Setting table "public.events" as logged rewrites it under an ACCESS EXCLUSIVE lock and writes its entire content to the WAL, which may cause replication lag on standby servers`, report.Diagnostics[1].Text)
+	require.Equal(t, "PS102", report.Diagnostics[2].Code)
+	require.Equal(t, 3, report.Diagnostics[2].Pos)
+}
+
 type testFile struct {
 	name string
 	migrate.File
@@ -7,6 +7,7 @@
 package postgres
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"strconv"
@@ -31,6 +32,15 @@ type (
 		Enums         []*enum             `spec:"enum"`
 		Domains       []*domain           `spec:"domain"`
 		Composites    []*composite        `spec:"composite"`
+		Ranges        []*rangeType        `spec:"range"`
+		Collations    []*collation        `spec:"collation"`
+		ForeignTables []*foreignTable     `spec:"foreign_table"`
+		Partitions    []*tablePartition   `spec:"table_partition"`
+		TSDicts       []*tsDict           `spec:"text_search_dictionary"`
+		TSConfigs     []*tsConfig         `spec:"text_search_configuration"`
+		Operators     []*operator         `spec:"operator"`
+		OpFamilies    []*opFamily         `spec:"operator_family"`
+		OpClasses     []*opClass          `spec:"operator_class"`
 		Sequences     []*sqlspec.Sequence `spec:"sequence"`
 		Funcs         []*sqlspec.Func     `spec:"function"`
 		Procs         []*sqlspec.Func     `spec:"procedure"`
@@ -38,7 +48,10 @@ type (
 		Triggers      []*sqlspec.Trigger  `spec:"trigger"`
 		Policies      []*policy           `spec:"policy"`
 		EventTriggers []*eventTrigger     `spec:"event_trigger"`
+		Servers       []*foreignServer    `spec:"foreign_server"`
+		Publications  []*publication      `spec:"publication"`
 		Extensions    []*extension        `spec:"extension"`
+		CronJobs      []*cronJob          `spec:"cron_job"`
 		Schemas       []*sqlspec.Schema   `spec:"schema"`
 	}
 
@@ -80,6 +93,91 @@ type (
 		schemahcl.DefaultExtension
 	}
 
+	// rangeType holds a specification for a range type.
+	rangeType struct {
+		Name      string          `spec:",name"`
+		Qualifier string          `spec:",qualifier"`
+		Schema    *schemahcl.Ref  `spec:"schema"`
+		Subtype   *schemahcl.Type `spec:"subtype"`
+		// Collation, canonical and subtype_diff are
+		// conditionally added to the range definition.
+		schemahcl.DefaultExtension
+	}
+
+	// collation holds a specification for a collation object.
+	collation struct {
+		Name      string         `spec:",name"`
+		Qualifier string         `spec:",qualifier"`
+		Schema    *schemahcl.Ref `spec:"schema"`
+		// Provider, locale and the rest of the attributes
+		// are conditionally added to the collation definition.
+		schemahcl.DefaultExtension
+	}
+
+	// tablePartition holds a specification for a child partition of a table.
+	tablePartition struct {
+		Name      string         `spec:",name"`
+		Qualifier string         `spec:",qualifier"`
+		Schema    *schemahcl.Ref `spec:"schema"`
+		Of        *schemahcl.Ref `spec:"of"`
+		Bound     string         `spec:"bound"`
+		// Partition key and comment are conditionally
+		// added to the partition definition.
+		schemahcl.DefaultExtension
+	}
+
+	// tsDict holds a specification for a text search dictionary.
+	tsDict struct {
+		Name      string         `spec:",name"`
+		Qualifier string         `spec:",qualifier"`
+		Schema    *schemahcl.Ref `spec:"schema"`
+		// Template, options and comment are
+		// added to the dictionary definition.
+		schemahcl.DefaultExtension
+	}
+
+	// operator holds a specification for a user-defined operator.
+	operator struct {
+		Name      string         `spec:",name"`
+		Qualifier string         `spec:",qualifier"`
+		Schema    *schemahcl.Ref `spec:"schema"`
+		// Operand types, function, estimators and the rest
+		// of the attributes are added to the operator definition.
+		schemahcl.DefaultExtension
+	}
+
+	// opFamily holds a specification for an operator family.
+	opFamily struct {
+		Name      string         `spec:",name"`
+		Qualifier string         `spec:",qualifier"`
+		Schema    *schemahcl.Ref `spec:"schema"`
+		// Access method and comment are
+		// added to the family definition.
+		schemahcl.DefaultExtension
+	}
+
+	// opClass holds a specification for an operator class. Its operators
+	// and support functions are defined as "operator" and "function" blocks.
+	opClass struct {
+		Name      string         `spec:",name"`
+		Qualifier string         `spec:",qualifier"`
+		Schema    *schemahcl.Ref `spec:"schema"`
+		// Access method, type, members and the rest
+		// of the attributes are added to the class definition.
+		schemahcl.DefaultExtension
+	}
+
+	// tsConfig holds a specification for a text search configuration.
+	// The token mappings are defined as "mapping" blocks in it.
+	tsConfig struct {
+		Name      string         `spec:",name"`
+		Qualifier string         `spec:",qualifier"`
+		Schema    *schemahcl.Ref `spec:"schema"`
+		// Parser, mappings and comment are
+		// added to the configuration definition.
+		schemahcl.DefaultExtension
+	}
+
 	// extension holds a specification for a postgres extension.
 	// Note, extension names are unique within a realm (database).
 	extension struct {
@@ -93,11 +191,51 @@ type (
 	// Note, event trigger names are unique within a realm (database).
 	eventTrigger struct {
 		Name string `spec:",name"`
-		// Schema, version and comment are conditionally
-		// added to the extension definition.
+		// Event, tags, function, state and comment are
+		// added to the event trigger definition.
 		schemahcl.DefaultExtension
 	}
 
+	// foreignServer holds a specification for a foreign server. The user
+	// mappings of the server are defined as "user_mapping" blocks in it.
+	// Note, server names are unique within a realm (database).
+	foreignServer struct {
+		Name string `spec:",name"`
+		// Wrapper, type, version, options, user
+		// mappings and comment are conditionally
+		// added to the server definition.
+		schemahcl.DefaultExtension
+	}
+
+	// publication holds a specification for a logical replication publication.
+	// The published tables are defined as "on" blocks in it.
+	// Note, publication names are unique within a realm (database).
+	publication struct {
+		Name string `spec:",name"`
+		// Tables, schemas, operations and comment
+		// are added to the publication definition.
+		schemahcl.DefaultExtension
+	}
+
+	// foreignTable holds a specification for a foreign table.
+	foreignTable struct {
+		Name      string            `spec:",name"`
+		Qualifier string            `spec:",qualifier"`
+		Schema    *schemahcl.Ref    `spec:"schema"`
+		Columns   []*sqlspec.Column `spec:"column"`
+		// Server, options and comment are
+		// added to the foreign table definition.
+		schemahcl.DefaultExtension
+	}
+
+	// cronJob holds a specification for a pg_cron job.
+	// Note, job names are unique within a realm (database).
+	cronJob struct {
+		Name     string `spec:",name"`
+		Schedule string `spec:"schedule"`
+		Command  string `spec:"command"`
+	}
+
 	// aggregate holds the specification for an aggregation function.
 	aggregate struct {
 		Name      string             `spec:",name"`
@@ -128,13 +266,25 @@ func (d *doc) merge(d1 *doc) {
 	d.Tables = append(d.Tables, d1.Tables...)
 	d.Domains = append(d.Domains, d1.Domains...)
 	d.Composites = append(d.Composites, d1.Composites...)
+	d.Ranges = append(d.Ranges, d1.Ranges...)
+	d.Collations = append(d.Collations, d1.Collations...)
 	d.Schemas = append(d.Schemas, d1.Schemas...)
 	d.Aggregates = append(d.Aggregates, d1.Aggregates...)
 	d.Sequences = append(d.Sequences, d1.Sequences...)
 	d.Extensions = append(d.Extensions, d1.Extensions...)
+	d.CronJobs = append(d.CronJobs, d1.CronJobs...)
 	d.Triggers = append(d.Triggers, d1.Triggers...)
 	d.Policies = append(d.Policies, d1.Policies...)
 	d.EventTriggers = append(d.EventTriggers, d1.EventTriggers...)
+	d.Servers = append(d.Servers, d1.Servers...)
+	d.Publications = append(d.Publications, d1.Publications...)
+	d.ForeignTables = append(d.ForeignTables, d1.ForeignTables...)
+	d.Partitions = append(d.Partitions, d1.Partitions...)
+	d.TSDicts = append(d.TSDicts, d1.TSDicts...)
+	d.TSConfigs = append(d.TSConfigs, d1.TSConfigs...)
+	d.Operators = append(d.Operators, d1.Operators...)
+	d.OpFamilies = append(d.OpFamilies, d1.OpFamilies...)
+	d.OpClasses = append(d.OpClasses, d1.OpClasses...)
 	d.Materialized = append(d.Materialized, d1.Materialized...)
 }
 
@@ -186,6 +336,30 @@ func (c *composite) SetQualifier(q string) { c.Qualifier = q }
 // SchemaRef returns the schema reference for the composite.
 func (c *composite) SchemaRef() *schemahcl.Ref { return c.Schema }
 
+// Label returns the defaults label used for the range resource.
+func (r *rangeType) Label() string { return r.Name }
+
+// QualifierLabel returns the qualifier label used for the range resource, if any.
+func (r *rangeType) QualifierLabel() string { return r.Qualifier }
+
+// SetQualifier sets the qualifier label used for the range resource.
+func (r *rangeType) SetQualifier(q string) { r.Qualifier = q }
+
+// SchemaRef returns the schema reference for the range.
+func (r *rangeType) SchemaRef() *schemahcl.Ref { return r.Schema }
+
+// Label returns the defaults label used for the collation resource.
+func (c *collation) Label() string { return c.Name }
+
+// QualifierLabel returns the qualifier label used for the collation resource, if any.
+func (c *collation) QualifierLabel() string { return c.Qualifier }
+
+// SetQualifier sets the qualifier label used for the collation resource.
+func (c *collation) SetQualifier(q string) { c.Qualifier = q }
+
+// SchemaRef returns the schema reference for the collation.
+func (c *collation) SchemaRef() *schemahcl.Ref { return c.Schema }
+
 // Label returns the defaults label used for the aggregate resource.
 func (a *aggregate) Label() string { return a.Name }
 
@@ -198,14 +372,109 @@ func (a *aggregate) SetQualifier(q string) { a.Qualifier = q }
 // SchemaRef returns the schema reference for the aggregate.
 func (a *aggregate) SchemaRef() *schemahcl.Ref { return a.Schema }
 
+// Label returns the defaults label used for the foreign table resource.
+func (t *foreignTable) Label() string { return t.Name }
+
+// QualifierLabel returns the qualifier label used for the foreign table resource, if any.
+func (t *foreignTable) QualifierLabel() string { return t.Qualifier }
+
+// SetQualifier sets the qualifier label used for the foreign table resource.
+func (t *foreignTable) SetQualifier(q string) { t.Qualifier = q }
+
+// SchemaRef returns the schema reference for the foreign table.
+func (t *foreignTable) SchemaRef() *schemahcl.Ref { return t.Schema }
+
+// Label returns the defaults label used for the partition resource.
+func (p *tablePartition) Label() string { return p.Name }
+
+// QualifierLabel returns the qualifier label used for the partition resource, if any.
+func (p *tablePartition) QualifierLabel() string { return p.Qualifier }
+
+// SetQualifier sets the qualifier label used for the partition resource.
+func (p *tablePartition) SetQualifier(q string) { p.Qualifier = q }
+
+// SchemaRef returns the schema reference for the partition.
+func (p *tablePartition) SchemaRef() *schemahcl.Ref { return p.Schema }
+
+// Label returns the defaults label used for the text search dictionary resource.
+func (d *tsDict) Label() string { return d.Name }
+
+// QualifierLabel returns the qualifier label used for the text search dictionary resource, if any.
+func (d *tsDict) QualifierLabel() string { return d.Qualifier }
+
+// SetQualifier sets the qualifier label used for the text search dictionary resource.
+func (d *tsDict) SetQualifier(q string) { d.Qualifier = q }
+
+// SchemaRef returns the schema reference for the text search dictionary.
+func (d *tsDict) SchemaRef() *schemahcl.Ref { return d.Schema }
+
+// Label returns the defaults label used for the text search configuration resource.
+func (c *tsConfig) Label() string { return c.Name }
+
+// QualifierLabel returns the qualifier label used for the text search configuration resource, if any.
+func (c *tsConfig) QualifierLabel() string { return c.Qualifier }
+
+// SetQualifier sets the qualifier label used for the text search configuration resource.
+func (c *tsConfig) SetQualifier(q string) { c.Qualifier = q }
+
+// SchemaRef returns the schema reference for the text search configuration.
+func (c *tsConfig) SchemaRef() *schemahcl.Ref { return c.Schema }
+
+// Label returns the defaults label used for the operator resource.
+func (o *operator) Label() string { return o.Name }
+
+// QualifierLabel returns the qualifier label used for the operator resource, if any.
+func (o *operator) QualifierLabel() string { return o.Qualifier }
+
+// SetQualifier sets the qualifier label used for the operator resource.
+func (o *operator) SetQualifier(q string) { o.Qualifier = q }
+
+// SchemaRef returns the schema reference for the operator.
+func (o *operator) SchemaRef() *schemahcl.Ref { return o.Schema }
+
+// Label returns the defaults label used for the operator family resource.
+func (f *opFamily) Label() string { return f.Name }
+
+// QualifierLabel returns the qualifier label used for the operator family resource, if any.
+func (f *opFamily) QualifierLabel() string { return f.Qualifier }
+
+// SetQualifier sets the qualifier label used for the operator family resource.
+func (f *opFamily) SetQualifier(q string) { f.Qualifier = q }
+
+// SchemaRef returns the schema reference for the operator family.
+func (f *opFamily) SchemaRef() *schemahcl.Ref { return f.Schema }
+
+// Label returns the defaults label used for the operator class resource.
+func (c *opClass) Label() string { return c.Name }
+
+// QualifierLabel returns the qualifier label used for the operator class resource, if any.
+func (c *opClass) QualifierLabel() string { return c.Qualifier }
+
+// SetQualifier sets the qualifier label used for the operator class resource.
+func (c *opClass) SetQualifier(q string) { c.Qualifier = q }
+
+// SchemaRef returns the schema reference for the operator class.
+func (c *opClass) SchemaRef() *schemahcl.Ref { return c.Schema }
+
 func init() {
 	schemahcl.Register("enum", &enum{})
 	schemahcl.Register("domain", &domain{})
 	schemahcl.Register("policy", &policy{})
 	schemahcl.Register("composite", &composite{})
+	schemahcl.Register("range", &rangeType{})
 	schemahcl.Register("aggregate", &aggregate{})
 	schemahcl.Register("extension", &extension{})
 	schemahcl.Register("event_trigger", &eventTrigger{})
+	schemahcl.Register("foreign_server", &foreignServer{})
+	schemahcl.Register("publication", &publication{})
+	schemahcl.Register("foreign_table", &foreignTable{})
+	schemahcl.Register("table_partition", &tablePartition{})
+	schemahcl.Register("text_search_dictionary", &tsDict{})
+	schemahcl.Register("text_search_configuration", &tsConfig{})
+	schemahcl.Register("operator", &operator{})
+	schemahcl.Register("operator_family", &opFamily{})
+	schemahcl.Register("operator_class", &opClass{})
+	schemahcl.Register("cron_job", &cronJob{})
 }
 
 // Codec for schemahcl.
@@ -229,9 +498,37 @@ func (c *Codec) EvalOptions(p *hclparse.Parser, v any, opts *schemahcl.EvalOptio
 		if err := specutil.Scan(v, d.ScanDoc(), scanFuncs); err != nil {
 			return fmt.Errorf("specutil: failed converting to *schema.Realm: %w", err)
 		}
+		if err := convertSchemaGrants(d.Schemas, v); err != nil {
+			return err
+		}
 		if err := convertTypes(&d, v); err != nil {
 			return err
 		}
+		if err := convertRanges(d.Tables, d.Ranges, v); err != nil {
+			return err
+		}
+		if err := convertDomains(d.Tables, d.Domains, v); err != nil {
+			return err
+		}
+		if err := convertComposites(d.Tables, d.Composites, v); err != nil {
+			return err
+		}
+		if err := convertCollations(d.Collations, v); err != nil {
+			return err
+		}
+		if err := convertTextSearch(d.TSDicts, d.TSConfigs, v); err != nil {
+			return err
+		}
+		if err := convertOperators(&d, v); err != nil {
+			return err
+		}
+		if err := convertInherits(d.Tables, v); err != nil {
+			return err
+		}
+		if err := convertPartitions(d.Partitions, v); err != nil {
+			return err
+		}
+		convertFuncTypes(v)
 		if err := convertAggregate(&d, v); err != nil {
 			return err
 		}
@@ -247,6 +544,15 @@ func (c *Codec) EvalOptions(p *hclparse.Parser, v any, opts *schemahcl.EvalOptio
 		if err := convertEventTriggers(d.EventTriggers, v); err != nil {
 			return err
 		}
+		if err := convertForeignData(d.Servers, d.ForeignTables, v); err != nil {
+			return err
+		}
+		if err := convertPublications(d.Publications, v); err != nil {
+			return err
+		}
+		if err := convertCronJobs(d.CronJobs, v); err != nil {
+			return err
+		}
 		if err := normalizeRealm(v); err != nil {
 			return err
 		}
@@ -262,9 +568,37 @@ func (c *Codec) EvalOptions(p *hclparse.Parser, v any, opts *schemahcl.EvalOptio
 		if err := specutil.Scan(r, d.ScanDoc(), scanFuncs); err != nil {
 			return err
 		}
+		if err := convertSchemaGrants(d.Schemas, r); err != nil {
+			return err
+		}
 		if err := convertTypes(&d, r); err != nil {
 			return err
 		}
+		if err := convertRanges(d.Tables, d.Ranges, r); err != nil {
+			return err
+		}
+		if err := convertDomains(d.Tables, d.Domains, r); err != nil {
+			return err
+		}
+		if err := convertComposites(d.Tables, d.Composites, r); err != nil {
+			return err
+		}
+		if err := convertCollations(d.Collations, r); err != nil {
+			return err
+		}
+		if err := convertTextSearch(d.TSDicts, d.TSConfigs, r); err != nil {
+			return err
+		}
+		if err := convertOperators(&d, r); err != nil {
+			return err
+		}
+		if err := convertInherits(d.Tables, r); err != nil {
+			return err
+		}
+		if err := convertPartitions(d.Partitions, r); err != nil {
+			return err
+		}
+		convertFuncTypes(r)
 		if err := convertAggregate(&d, r); err != nil {
 			return err
 		}
@@ -274,7 +608,11 @@ func (c *Codec) EvalOptions(p *hclparse.Parser, v any, opts *schemahcl.EvalOptio
 		if err := convertPolicies(d.Tables, d.Policies, r); err != nil {
 			return err
 		}
-		// Extensions are skipped in schema scope.
+		// Extensions, cron jobs and foreign servers are skipped in schema scope.
+		// Foreign tables reference their servers by name in this case.
+		if err := convertForeignData(nil, d.ForeignTables, r); err != nil {
+			return err
+		}
 		if err := normalizeRealm(r); err != nil {
 			return err
 		}
@@ -337,6 +675,33 @@ func (c *Codec) MarshalSpec(v any) ([]byte, error) {
 		if err := specutil.QualifyObjects(d.Composites); err != nil {
 			return nil, err
 		}
+		if err := specutil.QualifyObjects(d.Ranges); err != nil {
+			return nil, err
+		}
+		if err := specutil.QualifyObjects(d.Collations); err != nil {
+			return nil, err
+		}
+		if err := specutil.QualifyObjects(d.ForeignTables); err != nil {
+			return nil, err
+		}
+		if err := specutil.QualifyObjects(d.Partitions); err != nil {
+			return nil, err
+		}
+		if err := specutil.QualifyObjects(d.TSDicts); err != nil {
+			return nil, err
+		}
+		if err := specutil.QualifyObjects(d.TSConfigs); err != nil {
+			return nil, err
+		}
+		if err := specutil.QualifyObjects(d.Operators); err != nil {
+			return nil, err
+		}
+		if err := specutil.QualifyObjects(d.OpFamilies); err != nil {
+			return nil, err
+		}
+		if err := specutil.QualifyObjects(d.OpClasses); err != nil {
+			return nil, err
+		}
 		if err := specutil.QualifyObjects(d.Sequences); err != nil {
 			return nil, err
 		}
@@ -364,19 +729,36 @@ var (
 			schemahcl.WithTypes("table.column.type", TypeRegistry.Specs()),
 			schemahcl.WithTypes("view.column.type", TypeRegistry.Specs()),
 			schemahcl.WithTypes("materialized.column.type", TypeRegistry.Specs()),
+			schemahcl.WithTypes("domain.type", TypeRegistry.Specs()),
+			schemahcl.WithTypes("composite.field.type", TypeRegistry.Specs()),
+			schemahcl.WithTypes("range.subtype", TypeRegistry.Specs()),
+			schemahcl.WithTypes("foreign_table.column.type", TypeRegistry.Specs()),
+			schemahcl.WithTypes("function.arg.type", TypeRegistry.Specs()),
+			schemahcl.WithTypes("function.return", TypeRegistry.Specs()),
+			schemahcl.WithTypes("procedure.arg.type", TypeRegistry.Specs()),
+			schemahcl.WithTypes("aggregate.arg.type", TypeRegistry.Specs()),
+			schemahcl.WithTypes("aggregate.state_type", TypeRegistry.Specs()),
+			schemahcl.WithScopedEnums("function.lang", LangSQL, LangPLpgSQL),
+			schemahcl.WithScopedEnums("procedure.lang", LangSQL, LangPLpgSQL),
+			schemahcl.WithScopedEnums("function.volatility", VolatilityVolatile, VolatilityStable, VolatilityImmutable),
+			schemahcl.WithScopedEnums("aggregate.parallel", ParallelSafe, ParallelRestricted, ParallelUnsafe),
+			schemahcl.WithScopedEnums("function.arg.mode", string(schema.FuncArgModeIn), string(schema.FuncArgModeOut), string(schema.FuncArgModeInOut), string(schema.FuncArgModeVariadic)),
+			schemahcl.WithScopedEnums("procedure.arg.mode", string(schema.FuncArgModeIn), string(schema.FuncArgModeOut), string(schema.FuncArgModeInOut), string(schema.FuncArgModeVariadic)),
+			schemahcl.WithScopedEnums("aggregate.arg.mode", string(schema.FuncArgModeIn), string(schema.FuncArgModeVariadic)),
 			schemahcl.WithScopedEnums("view.check_option", schema.ViewCheckOptionLocal, schema.ViewCheckOptionCascaded),
+			schemahcl.WithScopedEnums("trigger.foreach", string(schema.TriggerForRow), string(schema.TriggerForStmt)),
+			schemahcl.WithScopedEnums("trigger.state", TriggerStateDisabled, TriggerStateReplica, TriggerStateAlways),
+			schemahcl.WithScopedEnums("event_trigger.on", "ddl_command_start", "ddl_command_end", "sql_drop", "table_rewrite", "login"),
+			schemahcl.WithScopedEnums("event_trigger.state", TriggerStateDisabled, TriggerStateReplica, TriggerStateAlways),
 			schemahcl.WithScopedEnums("table.index.type", IndexTypeBTree, IndexTypeBRIN, IndexTypeHash, IndexTypeGIN, IndexTypeGiST, "GiST", IndexTypeSPGiST, "SPGiST"),
+			schemahcl.WithScopedEnums("table.exclude.type", IndexTypeBTree, IndexTypeHash, IndexTypeGiST, "GiST", IndexTypeSPGiST, "SPGiST"),
 			schemahcl.WithScopedEnums("table.partition.type", PartitionTypeRange, PartitionTypeList, PartitionTypeHash),
 			schemahcl.WithScopedEnums("table.column.identity.generated", GeneratedTypeAlways, GeneratedTypeByDefault),
 			schemahcl.WithScopedEnums("table.column.as.type", "STORED"),
 			schemahcl.WithScopedEnums("table.foreign_key.on_update", specutil.ReferenceVars...),
 			schemahcl.WithScopedEnums("table.foreign_key.on_delete", specutil.ReferenceVars...),
-			schemahcl.WithScopedEnums("table.index.on.ops", func() (ops []string) {
-				for _, op := range postgresop.Classes {
-					ops = append(ops, op.Name)
-				}
-				return ops
-			}()...))...,
+			schemahcl.WithScopedEnums("table.index.on.ops", opClasses()...),
+			schemahcl.WithScopedEnums("table.exclude.on.ops", opClasses()...))...,
 		),
 	}
 	// MarshalHCL marshals v into an Atlas HCL DDL document.
@@ -388,6 +770,14 @@ var (
 	EvalHCLBytes = specutil.HCLBytesFunc(codec)
 )
 
+// opClasses returns the names of the builtin operator classes.
+func opClasses() (ops []string) {
+	for _, op := range postgresop.Classes {
+		ops = append(ops, op.Name)
+	}
+	return ops
+}
+
 // convertTable converts a sqlspec.Table to a schema.Table. Table conversion is done without converting
 // ForeignKeySpecs into ForeignKeys, as the target tables do not necessarily exist in the schema
 // at this point. Instead, the linking is done by the convertSchema function.
@@ -405,12 +795,100 @@ func convertTable(spec *sqlspec.Table, parent *schema.Schema) (*schema.Table, er
 	if err := convertPartition(spec.Extra, t); err != nil {
 		return nil, err
 	}
+	if err := convertStatistics(spec.Extra, t); err != nil {
+		return nil, err
+	}
+	if err := convertRules(spec.Extra, t); err != nil {
+		return nil, err
+	}
+	if attr, ok := spec.Attr("access_method"); ok {
+		am, err := attr.String()
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s.access_method: %w", t.Name, err)
+		}
+		t.AddAttrs(&AccessMethod{V: am})
+	}
+	if p, err := convertStorageParams(&spec.Extra); err != nil {
+		return nil, fmt.Errorf("parsing %s.storage_params: %w", t.Name, err)
+	} else if p != nil {
+		t.AddAttrs(p)
+	}
+	if attr, ok := spec.Attr("tablespace"); ok {
+		ts, err := attr.String()
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s.tablespace: %w", t.Name, err)
+		}
+		t.AddAttrs(&Tablespace{V: ts})
+	}
+	if attr, ok := spec.Attr("unlogged"); ok {
+		b, err := attr.Bool()
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s.unlogged: %w", t.Name, err)
+		}
+		if b {
+			t.AddAttrs(&Unlogged{})
+		}
+	}
+	for _, r := range spec.Extra.Resources("grant") {
+		g, err := convertGrant(r)
+		if err != nil {
+			return nil, fmt.Errorf("%s.grant: %w", t.Name, err)
+		}
+		t.AddAttrs((*TableGrant)(g))
+	}
 	if err := convertTableAttrs(spec, t); err != nil {
 		return nil, err
 	}
 	return t, nil
 }
 
+// convertInherits converts the "inherits" attributes of the table specs into Inherits
+// attributes. It is called after all tables were converted, as parents are referenced.
+func convertInherits(specs []*sqlspec.Table, r *schema.Realm) error {
+	for _, spec := range specs {
+		a, ok := spec.Attr("inherits")
+		if !ok {
+			continue
+		}
+		refs, err := a.Refs()
+		if err != nil {
+			return fmt.Errorf("parsing %s.inherits: %w", spec.Name, err)
+		}
+		ns, err := specutil.SchemaName(spec.Schema)
+		if err != nil {
+			return fmt.Errorf("extract schema name of table %q: %w", spec.Name, err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return fmt.Errorf("schema %q of table %q was not found in realm", ns, spec.Name)
+		}
+		t, ok := s.Table(spec.Name)
+		if !ok {
+			return fmt.Errorf("table %q was not found in schema %q", spec.Name, ns)
+		}
+		in := &Inherits{T: make([]*schema.Table, 0, len(refs))}
+		for _, ref := range refs {
+			q, name, err := specutil.TableName(ref)
+			if err != nil {
+				return fmt.Errorf("parsing %s.inherits: %w", spec.Name, err)
+			}
+			ps := s
+			if q != "" {
+				if ps, ok = r.Schema(q); !ok {
+					return fmt.Errorf("schema %q of the parent of table %q was not found in realm", q, spec.Name)
+				}
+			}
+			p, ok := ps.Table(name)
+			if !ok {
+				return fmt.Errorf("parent table %q of table %q was not found in schema %q", name, spec.Name, ps.Name)
+			}
+			in.T = append(in.T, p)
+		}
+		t.AddAttrs(in)
+	}
+	return nil
+}
+
 // convertView converts a sqlspec.View to a schema.View.
 func convertView(spec *sqlspec.View, parent *schema.Schema) (*schema.View, error) {
 	v, err := specutil.View(
@@ -446,11 +924,107 @@ func convertUnique(spec schemahcl.Resource, t *schema.Table) error {
 			return err
 		}
 		idx.SetUnique(true).AddAttrs(UniqueConstraint(sx.Name))
+		if err := convertDeferrable(&sx.DefaultExtension, &idx.Attrs); err != nil {
+			return fmt.Errorf("%s.unique constraint %q: %w", t.Name, sx.Name, err)
+		}
 		t.AddIndexes(idx)
 	}
 	return nil
 }
 
+// convertForeignKey converts the PostgreSQL specific attributes of a foreign key.
+func convertForeignKey(spec *sqlspec.ForeignKey, fk *schema.ForeignKey) error {
+	if err := convertDeferrable(&spec.DefaultExtension, &fk.Attrs); err != nil {
+		return fmt.Errorf("%s.foreign_key %q: %w", fk.Table.Name, fk.Symbol, err)
+	}
+	if a, ok := spec.Attr("on_delete_columns"); ok {
+		refs, err := a.Refs()
+		if err != nil {
+			return fmt.Errorf("%s.foreign_key %q: %w", fk.Table.Name, fk.Symbol, err)
+		}
+		if fk.OnDelete != schema.SetNull && fk.OnDelete != schema.SetDefault {
+			return fmt.Errorf("%s.foreign_key %q: on_delete_columns requires the SET_NULL or SET_DEFAULT action", fk.Table.Name, fk.Symbol)
+		}
+		d := &OnDeleteColumns{Columns: make([]*schema.Column, len(refs))}
+		for i, r := range refs {
+			if d.Columns[i], err = specutil.ColumnByRef(fk.Table, r); err != nil {
+				return fmt.Errorf("%s.foreign_key %q: %w", fk.Table.Name, fk.Symbol, err)
+			}
+		}
+		fk.AddAttrs(d)
+	}
+	if a, ok := spec.Attr("not_valid"); ok {
+		b, err := a.Bool()
+		if err != nil {
+			return fmt.Errorf("%s.foreign_key %q: %w", fk.Table.Name, fk.Symbol, err)
+		}
+		if b {
+			fk.AddAttrs(&NotValid{})
+		}
+	}
+	return nil
+}
+
+// convertDeferrable appends the Deferrable attribute to the
+// constraint attributes, if it was defined in the spec.
+func convertDeferrable(spec *schemahcl.DefaultExtension, attrs *[]schema.Attr) error {
+	var deferrable, deferred bool
+	if a, ok := spec.Attr("deferrable"); ok {
+		b, err := a.Bool()
+		if err != nil {
+			return err
+		}
+		deferrable = b
+	}
+	if a, ok := spec.Attr("initially_deferred"); ok {
+		b, err := a.Bool()
+		if err != nil {
+			return err
+		}
+		deferred = b
+	}
+	switch {
+	case deferred && !deferrable:
+		return errors.New("initially deferred constraint must be deferrable")
+	case deferrable:
+		schema.ReplaceOrAppend(attrs, &Deferrable{InitiallyDeferred: deferred})
+	}
+	return nil
+}
+
+// fkSpec converts from a concrete PostgreSQL schema.ForeignKey to a sqlspec.ForeignKey.
+func fkSpec(fk *schema.ForeignKey) (*sqlspec.ForeignKey, error) {
+	spec, err := specutil.FromForeignKey(fk)
+	if err != nil {
+		return nil, err
+	}
+	spec.Extra.Attrs = append(spec.Extra.Attrs, deferrableSpec(fk.Attrs)...)
+	if d := (OnDeleteColumns{}); sqlx.Has(fk.Attrs, &d) && len(d.Columns) > 0 {
+		refs := make([]*schemahcl.Ref, len(d.Columns))
+		for i, c := range d.Columns {
+			refs[i] = specutil.ColumnRef(c.Name)
+		}
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.RefsAttr("on_delete_columns", refs...))
+	}
+	if sqlx.Has(fk.Attrs, &NotValid{}) {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.BoolAttr("not_valid", true))
+	}
+	return spec, nil
+}
+
+// deferrableSpec returns the spec attributes of the constraint deferrability, if defined.
+func deferrableSpec(attrs []schema.Attr) []*schemahcl.Attr {
+	d := Deferrable{}
+	if !sqlx.Has(attrs, &d) {
+		return nil
+	}
+	specs := []*schemahcl.Attr{schemahcl.BoolAttr("deferrable", true)}
+	if d.InitiallyDeferred {
+		specs = append(specs, schemahcl.BoolAttr("initially_deferred", true))
+	}
+	return specs
+}
+
 // convertPartition converts and appends the partition block into the table attributes if exists.
 func convertPartition(spec schemahcl.Resource, table *schema.Table) error {
 	r, ok := spec.Resource("partition")
@@ -504,9 +1078,134 @@ func convertPartition(spec schemahcl.Resource, table *schema.Table) error {
 		}
 	}
 	table.AddAttrs(key)
+	if r, ok := r.Resource("rotation"); ok {
+		var rot struct {
+			Interval  string `spec:"interval"`
+			Premake   int    `spec:"premake"`
+			Retention int    `spec:"retention"`
+			Detach    bool   `spec:"detach"`
+		}
+		if err := r.As(&rot); err != nil {
+			return fmt.Errorf("parsing %s.partition.rotation: %w", table.Name, err)
+		}
+		rp := &PartitionRotation{Interval: rot.Interval, Premake: rot.Premake, Retention: rot.Retention, Detach: rot.Detach}
+		if _, err := rp.validate(table); err != nil {
+			return err
+		}
+		table.AddAttrs(rp)
+	}
 	return nil
 }
 
+// fromRotation returns the resource spec for representing the partition rotation block.
+func fromRotation(r *PartitionRotation) *schemahcl.Resource {
+	spec := &schemahcl.Resource{
+		Type: "rotation",
+		Attrs: []*schemahcl.Attr{
+			schemahcl.StringAttr("interval", r.Interval),
+			schemahcl.IntAttr("premake", r.Premake),
+			schemahcl.IntAttr("retention", r.Retention),
+		},
+	}
+	if r.Detach {
+		spec.Attrs = append(spec.Attrs, schemahcl.BoolAttr("detach", true))
+	}
+	return spec
+}
+
+// convertStatistics converts and appends the statistics blocks into the table attributes.
+func convertStatistics(spec schemahcl.Resource, table *schema.Table) error {
+	for _, r := range spec.Resources("statistics") {
+		var sx struct {
+			Name    string           `spec:",name"`
+			Kinds   []string         `spec:"kinds"`
+			Columns []*schemahcl.Ref `spec:"columns"`
+		}
+		if err := r.As(&sx); err != nil {
+			return fmt.Errorf("parsing %s.statistics: %w", table.Name, err)
+		}
+		if len(sx.Columns) == 0 {
+			return fmt.Errorf("missing columns for %s.statistics.%s", table.Name, sx.Name)
+		}
+		st := &Statistics{Name: sx.Name, Kinds: StatsKinds(sx.Kinds)}
+		for _, k := range st.Kinds {
+			if k != StatsNDistinct && k != StatsDependencies && k != StatsMCV {
+				return fmt.Errorf("unknown kind %q for %s.statistics.%s", k, table.Name, sx.Name)
+			}
+		}
+		for _, r := range sx.Columns {
+			c, err := specutil.ColumnByRef(table, r)
+			if err != nil {
+				return err
+			}
+			st.Columns = append(st.Columns, c)
+		}
+		table.AddAttrs(st)
+	}
+	return nil
+}
+
+// convertRules converts and appends the rule blocks into the table attributes.
+func convertRules(spec schemahcl.Resource, table *schema.Table) error {
+	for _, r := range spec.Resources("rule") {
+		var rx struct {
+			Name    string `spec:",name"`
+			Event   string `spec:"event"`
+			Instead bool   `spec:"instead"`
+			Where   string `spec:"where"`
+			Action  string `spec:"action"`
+		}
+		if err := r.As(&rx); err != nil {
+			return fmt.Errorf("parsing %s.rule: %w", table.Name, err)
+		}
+		switch e := strings.ToUpper(rx.Event); e {
+		case RuleEventSelect, RuleEventInsert, RuleEventUpdate, RuleEventDelete:
+			rx.Event = e
+		default:
+			return fmt.Errorf("unknown event %q for %s.rule.%s", rx.Event, table.Name, rx.Name)
+		}
+		if rx.Action == "" {
+			return fmt.Errorf("missing action for %s.rule.%s", table.Name, rx.Name)
+		}
+		table.AddAttrs(&Rule{Name: rx.Name, Event: rx.Event, Instead: rx.Instead, Where: rx.Where, Action: rx.Action})
+	}
+	return nil
+}
+
+// fromRule returns the resource spec for representing the rule block.
+func fromRule(r *Rule) *schemahcl.Resource {
+	spec := &schemahcl.Resource{
+		Type:  "rule",
+		Name:  r.Name,
+		Attrs: []*schemahcl.Attr{schemahcl.StringAttr("event", r.Event)},
+	}
+	if r.Instead {
+		spec.Attrs = append(spec.Attrs, schemahcl.BoolAttr("instead", true))
+	}
+	if r.Where != "" {
+		spec.Attrs = append(spec.Attrs, schemahcl.StringAttr("where", r.Where))
+	}
+	spec.Attrs = append(spec.Attrs, schemahcl.StringAttr("action", ruleAction(r)))
+	return spec
+}
+
+// fromStatistics returns the resource spec for representing the statistics block.
+func fromStatistics(st *Statistics) *schemahcl.Resource {
+	columns := make([]*schemahcl.Ref, len(st.Columns))
+	for i, c := range st.Columns {
+		columns[i] = specutil.ColumnRef(c.Name)
+	}
+	r := &schemahcl.Resource{
+		Type:  "statistics",
+		Name:  st.Name,
+		Attrs: []*schemahcl.Attr{schemahcl.RefsAttr("columns", columns...)},
+	}
+	if kinds := StatsKinds(st.Kinds); len(kinds) > 0 {
+		r.Attrs = append(r.Attrs, schemahcl.StringsAttr("kinds", kinds...))
+	}
+	return r
+}
+
 // fromPartition returns the resource spec for representing the partition block.
 func fromPartition(p Partition) *schemahcl.Resource {
 	key := &schemahcl.Resource{
@@ -558,6 +1257,27 @@ func convertColumn(spec *sqlspec.Column, _ *schema.Table) (*schema.Column, error
 		}
 		c.Attrs = append(c.Attrs, id)
 	}
+	if a, ok := spec.Attr("collate"); ok {
+		v, err := a.String()
+		if err != nil {
+			return nil, fmt.Errorf("extract collation of column %q: %w", spec.Name, err)
+		}
+		c.SetCollation(v)
+	}
+	if a, ok := spec.Attr("compression"); ok {
+		v, err := a.String()
+		if err != nil {
+			return nil, fmt.Errorf("extract compression of column %q: %w", spec.Name, err)
+		}
+		c.Attrs = append(c.Attrs, &Compression{V: v})
+	}
+	for _, r := range spec.Extra.Resources("grant") {
+		g, err := convertGrant(r)
+		if err != nil {
+			return nil, err
+		}
+		c.Attrs = append(c.Attrs, g)
+	}
 	if err := specutil.ConvertGenExpr(spec.Remain(), c, generatedType); err != nil {
 		return nil, err
 	}
@@ -569,20 +1289,68 @@ func convertIdentity(r *schemahcl.Resource) (*Identity, error) {
 		Generation string `spec:"generated"`
 		Start      int64  `spec:"start"`
 		Increment  int64  `spec:"increment"`
+		Cache      int64  `spec:"cache"`
+		Cycle      bool   `spec:"cycle"`
 	}
 	if err := r.As(&spec); err != nil {
 		return nil, err
 	}
-	id := &Identity{Generation: specutil.FromVar(spec.Generation), Sequence: &Sequence{}}
+	id := &Identity{Generation: specutil.FromVar(spec.Generation), Sequence: &Sequence{Cache: spec.Cache, Cycle: spec.Cycle}}
 	if spec.Start != 0 {
 		id.Sequence.Start = spec.Start
 	}
 	if spec.Increment != 0 {
 		id.Sequence.Increment = spec.Increment
 	}
+	for n, v := range map[string]**int64{"min_value": &id.Sequence.Min, "max_value": &id.Sequence.Max} {
+		if a, ok := r.Attr(n); ok {
+			i, err := a.Int64()
+			if err != nil {
+				return nil, fmt.Errorf("parsing identity %s: %w", n, err)
+			}
+			*v = &i
+		}
+	}
 	return id, nil
 }
 
+// convertSchemaGrants converts the "grant" blocks of the schemas into SchemaGrant attributes.
+func convertSchemaGrants(specs []*sqlspec.Schema, r *schema.Realm) error {
+	for _, spec := range specs {
+		rs := spec.Extra.Resources("grant")
+		if len(rs) == 0 {
+			continue
+		}
+		s, ok := r.Schema(spec.Name)
+		if !ok {
+			return fmt.Errorf("postgres: schema %q was not found in realm", spec.Name)
+		}
+		for _, rg := range rs {
+			g, err := convertGrant(rg)
+			if err != nil {
+				return fmt.Errorf("schema %q: %w", spec.Name, err)
+			}
+			s.AddAttrs((*SchemaGrant)(g))
+		}
+	}
+	return nil
+}
+
+// convertGrant converts a "grant" block of a schema, table or column into a ColumnGrant.
+func convertGrant(r *schemahcl.Resource) (*ColumnGrant, error) {
+	var spec struct {
+		Privileges []string `spec:"privileges"`
+		Grantable  bool     `spec:"grantable"`
+	}
+	if err := r.As(&spec); err != nil {
+		return nil, err
+	}
+	if r.Name == "" || len(spec.Privileges) == 0 {
+		return nil, fmt.Errorf("postgres: grant must define a grantee and at least one privilege")
+	}
+	return &ColumnGrant{Grantee: r.Name, Privileges: spec.Privileges, Grantable: spec.Grantable}, nil
+}
+
 // fixDefaultQuotes fixes the quotes on the Default field to be single quotes
 // instead of double quotes.
 func fixDefaultQuotes(spec *sqlspec.Column) error {
@@ -641,12 +1409,55 @@ func convertIndex(spec *sqlspec.Index, t *schema.Table) (*schema.Index, error) {
 		}
 		idx.Attrs = append(idx.Attrs, &IndexNullsDistinct{V: v})
 	}
+	if p, err := convertStorageParams(&spec.Extra); err != nil {
+		return nil, fmt.Errorf("parsing %s.storage_params: %w", idx.Name, err)
+	} else if p != nil {
+		idx.Attrs = append(idx.Attrs, p)
+	}
 	if err := convertIndexPK(spec, t, idx); err != nil {
 		return nil, err
 	}
 	return idx, nil
 }
 
+// convertStorageParams converts the "storage_params" block of the given resource into
+// StorageParams. Parameters defined in its nested "toast" block are prefixed with "toast.".
+func convertStorageParams(r *schemahcl.Resource) (*StorageParams, error) {
+	b, ok := r.Resource("storage_params")
+	if !ok {
+		return nil, nil
+	}
+	p := &StorageParams{}
+	add := func(prefix string, attrs []*schemahcl.Attr) error {
+		for _, a := range attrs {
+			var v string
+			switch a.V.Type() {
+			case cty.Number:
+				v = a.V.AsBigFloat().Text('f', -1)
+			case cty.Bool:
+				v = strconv.FormatBool(a.V.True())
+			default:
+				s, err := a.String()
+				if err != nil {
+					return fmt.Errorf("reading parameter %q: %w", a.K, err)
+				}
+				v = s
+			}
+			p.V = append(p.V, StorageParam{Name: prefix + a.K, Value: v})
+		}
+		return nil
+	}
+	if err := add("", b.Attrs); err != nil {
+		return nil, err
+	}
+	if t, ok := b.Resource("toast"); ok {
+		if err := add(storageParamToast, t.Attrs); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
 // convertIndexPK converts the index parameters shared between primary and secondary indexes.
 func convertIndexPK(spec specutil.Attrer, t *schema.Table, idx *schema.Index) error {
 	if attr, ok := spec.Attr("page_per_range"); ok {
@@ -656,6 +1467,13 @@ func convertIndexPK(spec specutil.Attrer, t *schema.Table, idx *schema.Index) er
 		}
 		idx.Attrs = append(idx.Attrs, &IndexStorageParams{PagesPerRange: p})
 	}
+	if attr, ok := spec.Attr("tablespace"); ok {
+		ts, err := attr.String()
+		if err != nil {
+			return err
+		}
+		idx.Attrs = append(idx.Attrs, &Tablespace{V: ts})
+	}
 	if attr, ok := spec.Attr("include"); ok {
 		refs, err := attr.Refs()
 		if err != nil {
@@ -772,6 +1590,12 @@ func schemaSpec(s *schema.Schema) (*doc, []*schema.Trigger, error) {
 		Enums:        make([]*enum, 0, len(s.Objects)),
 		Domains:      make([]*domain, 0, len(s.Objects)),
 		Composites:   make([]*composite, 0, len(s.Objects)),
+		Ranges:       make([]*rangeType, 0, len(s.Objects)),
+	}
+	for _, a := range s.Attrs {
+		if g, ok := a.(*SchemaGrant); ok {
+			spec.Schema.Extra.Children = append(spec.Schema.Extra.Children, fromGrant((*ColumnGrant)(g)))
+		}
 	}
 	if err := objectSpec(d, spec, s); err != nil {
 		return nil, nil, err
@@ -786,7 +1610,7 @@ func tableSpec(t *schema.Table) (*sqlspec.Table, error) {
 		tableColumnSpec,
 		pkSpec,
 		indexSpec,
-		specutil.FromForeignKey,
+		fkSpec,
 		specutil.FromCheck,
 	)
 	if err != nil {
@@ -801,10 +1625,10 @@ func tableSpec(t *schema.Table) (*sqlspec.Table, error) {
 			spec.Extra.Children = append(spec.Extra.Children, &schemahcl.Resource{
 				Type: "unique",
 				Name: c.N,
-				Attrs: append(
+				Attrs: append(append(
 					[]*schemahcl.Attr{schemahcl.RefsAttr("columns", idx1.Columns...)},
 					idx1.Extra.Attrs...,
-				),
+				), deferrableSpec(t.Indexes[i].Attrs)...),
 			})
 		} else if ex, ok := excludeConst(t.Indexes[i].Attrs); !ok {
 			idxs = append(idxs, idx1)
@@ -814,7 +1638,41 @@ func tableSpec(t *schema.Table) (*sqlspec.Table, error) {
 	}
 	spec.Indexes = idxs
 	if p := (Partition{}); sqlx.Has(t.Attrs, &p) {
-		spec.Extra.Children = append(spec.Extra.Children, fromPartition(p))
+		key := fromPartition(p)
+		if r := (&PartitionRotation{}); sqlx.Has(t.Attrs, r) {
+			key.Children = append(key.Children, fromRotation(r))
+		}
+		spec.Extra.Children = append(spec.Extra.Children, key)
+	}
+	if am := (AccessMethod{}); sqlx.Has(t.Attrs, &am) {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.StringAttr("access_method", am.V))
+	}
+	if p := (StorageParams{}); sqlx.Has(t.Attrs, &p) && len(p.V) > 0 {
+		spec.Extra.Children = append(spec.Extra.Children, storageParamsSpec(p.V))
+	}
+	if ts := tablespace(t.Attrs); ts != "" {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.StringAttr("tablespace", ts))
+	}
+	if sqlx.Has(t.Attrs, &Unlogged{}) {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.BoolAttr("unlogged", true))
+	}
+	if parents := tableInherits(t); len(parents) > 0 {
+		refs := make([]*schemahcl.Ref, len(parents))
+		for i, p := range parents {
+			refs[i] = specutil.TableSpecRef(p)
+		}
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.RefsAttr("inherits", refs...))
+	}
+	for _, st := range tableStats(t) {
+		spec.Extra.Children = append(spec.Extra.Children, fromStatistics(st))
+	}
+	for _, r := range tableRules(t) {
+		spec.Extra.Children = append(spec.Extra.Children, fromRule(r))
+	}
+	for _, a := range t.Attrs {
+		if g, ok := a.(*TableGrant); ok {
+			spec.Extra.Children = append(spec.Extra.Children, fromGrant((*ColumnGrant)(g)))
+		}
 	}
 	tableAttrsSpec(t, spec)
 	return spec, nil
@@ -867,9 +1725,39 @@ func indexSpec(idx *schema.Index) (*sqlspec.Index, error) {
 		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.BoolAttr("nulls_distinct", i.V))
 	}
 	spec.Extra.Attrs = indexPKSpec(idx, spec.Extra.Attrs)
+	if p := (StorageParams{}); sqlx.Has(idx.Attrs, &p) && len(p.V) > 0 {
+		spec.Extra.Children = append(spec.Extra.Children, storageParamsSpec(p.V))
+	}
 	return spec, nil
 }
 
+// storageParamsSpec returns the "storage_params" block of the given parameters.
+// Numeric and boolean values are written as is, and other values as strings.
+func storageParamsSpec(params []StorageParam) *schemahcl.Resource {
+	var (
+		r     = &schemahcl.Resource{Type: "storage_params"}
+		toast = &schemahcl.Resource{Type: "toast"}
+	)
+	for _, p := range params {
+		k := strings.TrimPrefix(p.Name, storageParamToast)
+		attr := schemahcl.StringAttr(k, p.Value)
+		if f, err := strconv.ParseFloat(p.Value, 64); err == nil {
+			attr = schemahcl.Float64Attr(k, f)
+		} else if b, err := strconv.ParseBool(p.Value); err == nil && strings.EqualFold(p.Value, strconv.FormatBool(b)) {
+			attr = schemahcl.BoolAttr(k, b)
+		}
+		if strings.HasPrefix(p.Name, storageParamToast) {
+			toast.Attrs = append(toast.Attrs, attr)
+		} else {
+			r.Attrs = append(r.Attrs, attr)
+		}
+	}
+	if len(toast.Attrs) > 0 {
+		r.Children = append(r.Children, toast)
+	}
+	return r
+}
+
 func indexPKSpec(idx *schema.Index, attrs []*schemahcl.Attr) []*schemahcl.Attr {
 	if i := (IndexInclude{}); sqlx.Has(idx.Attrs, &i) && len(i.Columns) > 0 {
 		refs := make([]*schemahcl.Ref, 0, len(i.Columns))
@@ -881,6 +1769,9 @@ func indexPKSpec(idx *schema.Index, attrs []*schemahcl.Attr) []*schemahcl.Attr {
 	if p, ok := indexStorageParams(idx.Attrs); ok {
 		attrs = append(attrs, schemahcl.Int64Attr("page_per_range", p.PagesPerRange))
 	}
+	if ts := tablespace(idx.Attrs); ts != "" {
+		attrs = append(attrs, schemahcl.StringAttr("tablespace", ts))
+	}
 	return attrs
 }
 
@@ -918,12 +1809,36 @@ func tableColumnSpec(c *schema.Column, _ *schema.Table) (*sqlspec.Column, error)
 	if i := (&Identity{}); sqlx.Has(c.Attrs, i) {
 		s.Extra.Children = append(s.Extra.Children, fromIdentity(i))
 	}
+	if v := fieldCollation(c); v != "" {
+		s.Extra.Attrs = append(s.Extra.Attrs, schemahcl.StringAttr("collate", v))
+	}
+	if v := compression(c.Attrs); v != "" {
+		s.Extra.Attrs = append(s.Extra.Attrs, schemahcl.StringAttr("compression", v))
+	}
 	if x := (schema.GeneratedExpr{}); sqlx.Has(c.Attrs, &x) {
 		s.Extra.Children = append(s.Extra.Children, specutil.FromGenExpr(x, generatedType))
 	}
+	for _, a := range c.Attrs {
+		if g, ok := a.(*ColumnGrant); ok {
+			s.Extra.Children = append(s.Extra.Children, fromGrant(g))
+		}
+	}
 	return s, nil
 }
 
+// fromGrant returns the resource spec for representing the privileges of a grant.
+func fromGrant(g *ColumnGrant) *schemahcl.Resource {
+	r := &schemahcl.Resource{
+		Type:  "grant",
+		Name:  g.Grantee,
+		Attrs: []*schemahcl.Attr{schemahcl.StringsAttr("privileges", g.Privileges...)},
+	}
+	if g.Grantable {
+		r.Attrs = append(r.Attrs, schemahcl.BoolAttr("grantable", true))
+	}
+	return r
+}
+
 // fromIdentity returns the resource spec for representing the identity attributes.
 func fromIdentity(i *Identity) *schemahcl.Resource {
 	id := &schemahcl.Resource{
@@ -933,12 +1848,24 @@ func fromIdentity(i *Identity) *schemahcl.Resource {
 		},
 	}
 	if s := i.Sequence; s != nil {
-		if s.Start != 1 {
+		if s.Start != 0 && s.Start != 1 {
 			id.Attrs = append(id.Attrs, schemahcl.Int64Attr("start", s.Start))
 		}
-		if s.Increment != 1 {
+		if s.Increment != 0 && s.Increment != 1 {
 			id.Attrs = append(id.Attrs, schemahcl.Int64Attr("increment", s.Increment))
 		}
+		if s.Min != nil {
+			id.Attrs = append(id.Attrs, schemahcl.Int64Attr("min_value", *s.Min))
+		}
+		if s.Max != nil {
+			id.Attrs = append(id.Attrs, schemahcl.Int64Attr("max_value", *s.Max))
+		}
+		if s.Cache > 1 {
+			id.Attrs = append(id.Attrs, schemahcl.Int64Attr("cache", s.Cache))
+		}
+		if s.Cycle {
+			id.Attrs = append(id.Attrs, schemahcl.BoolAttr("cycle", true))
+		}
 	}
 	return id
 }
@@ -958,6 +1885,12 @@ func columnTypeSpec(t schema.Type) (*sqlspec.Column, error) {
 				IsRef: true,
 				T:     specutil.ObjectRef(o.Schema, o).V},
 		}, nil
+	case *UserRangeType:
+		return &sqlspec.Column{
+			Type: &schemahcl.Type{
+				IsRef: true,
+				T:     specutil.ObjectRef(o.Schema, o).V},
+		}, nil
 	case *DomainType:
 		return &sqlspec.Column{
 			Type: &schemahcl.Type{
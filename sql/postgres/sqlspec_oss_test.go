@@ -7,14 +7,21 @@
 package postgres
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"testing"
 
+	"ariga.io/atlas/schemahcl"
 	"ariga.io/atlas/sql/internal/spectest"
 	"ariga.io/atlas/sql/internal/sqlx"
 	"ariga.io/atlas/sql/schema"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
 )
 
 func TestSQLSpec(t *testing.T) {
@@ -886,6 +893,189 @@ schema "test" {
 	})
 }
 
+func TestSpec_PartitionRotation(t *testing.T) {
+	var (
+		s = &schema.Schema{}
+		f = `table "events" {
+  schema = schema.test
+  column "created_at" {
+    null = false
+    type = timestamptz
+  }
+  partition {
+    type    = RANGE
+    columns = [column.created_at]
+    rotation {
+      interval  = "month"
+      premake   = 2
+      retention = 12
+      detach    = true
+    }
+  }
+}
+schema "test" {
+}
+`
+	)
+	require.NoError(t, EvalHCLBytes([]byte(f), s, nil))
+	r := &PartitionRotation{}
+	require.True(t, sqlx.Has(s.Tables[0].Attrs, r))
+	require.Equal(t, &PartitionRotation{Interval: RotateMonthly, Premake: 2, Retention: 12, Detach: true}, r)
+	buf, err := MarshalHCL(s)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+
+	err = EvalHCLBytes([]byte(`
+schema "test" {}
+table "events" {
+  schema = schema.test
+  column "name" {
+    type = text
+  }
+  partition {
+    type    = LIST
+    columns = [column.name]
+    rotation {
+      interval = "month"
+    }
+  }
+}
+`), &schema.Schema{}, nil)
+	require.EqualError(t, err, `cannot convert table "events": partition rotation requires table "events" to be partitioned by RANGE`)
+}
+
+func TestSpec_Statistics(t *testing.T) {
+	var (
+		s = &schema.Schema{}
+		f = `table "users" {
+  schema = schema.test
+  column "a" {
+    null = false
+    type = integer
+  }
+  column "b" {
+    null = false
+    type = integer
+  }
+  statistics "users_ab" {
+    columns = [column.a, column.b]
+  }
+  statistics "users_deps" {
+    columns = [column.b, column.a]
+    kinds   = ["dependencies", "ndistinct"]
+  }
+}
+schema "test" {
+}
+`
+	)
+	require.NoError(t, EvalHCLBytes([]byte(f), s, nil))
+	tbl := s.Tables[0]
+	require.Equal(t, []schema.Attr{
+		&Statistics{Name: "users_ab", Columns: tbl.Columns},
+		&Statistics{Name: "users_deps", Kinds: []string{StatsDependencies, StatsNDistinct}, Columns: []*schema.Column{tbl.Columns[1], tbl.Columns[0]}},
+	}, tbl.Attrs)
+	buf, err := MarshalHCL(s)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+
+	err = EvalHCLBytes([]byte(`
+schema "test" {}
+table "users" {
+  schema = schema.test
+  column "a" {
+    type = integer
+  }
+  statistics "s" {
+    columns = [column.a]
+    kinds   = ["histogram"]
+  }
+}
+`), &schema.Schema{}, nil)
+	require.EqualError(t, err, `cannot convert table "users": unknown kind "histogram" for users.statistics.s`)
+}
+
+func TestSpec_Rules(t *testing.T) {
+	var (
+		s = &schema.Schema{}
+		f = `table "users" {
+  schema = schema.test
+  column "id" {
+    null = false
+    type = integer
+  }
+  rule "users_log" {
+    event  = "INSERT"
+    action = "INSERT INTO logs (id) VALUES (new.id)"
+  }
+  rule "users_protect" {
+    event   = "DELETE"
+    instead = true
+    where   = "(old.id > 0)"
+    action  = "NOTHING"
+  }
+}
+schema "test" {
+}
+`
+	)
+	require.NoError(t, EvalHCLBytes([]byte(f), s, nil))
+	require.Equal(t, []schema.Attr{
+		&Rule{Name: "users_log", Event: RuleEventInsert, Action: "INSERT INTO logs (id) VALUES (new.id)"},
+		&Rule{Name: "users_protect", Event: RuleEventDelete, Instead: true, Where: "(old.id > 0)", Action: "NOTHING"},
+	}, s.Tables[0].Attrs)
+	buf, err := MarshalHCL(s)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+
+	err = EvalHCLBytes([]byte(`
+schema "test" {}
+table "users" {
+  schema = schema.test
+  column "id" {
+    type = integer
+  }
+  rule "r" {
+    event  = "TRUNCATE"
+    action = "NOTHING"
+  }
+}
+`), &schema.Schema{}, nil)
+	require.EqualError(t, err, `cannot convert table "users": unknown event "TRUNCATE" for users.rule.r`)
+}
+
+func TestSpec_CronJobs(t *testing.T) {
+	var (
+		r = &schema.Realm{}
+		f = `cron_job "rotate_partitions" {
+  schedule = "0 3 * * *"
+  command  = "CALL rotate_partitions('events')"
+}
+schema "public" {
+}
+`
+	)
+	require.NoError(t, EvalHCLBytes([]byte(f), r, nil))
+	require.Equal(t, []schema.Object{
+		&CronJob{Name: "rotate_partitions", Schedule: "0 3 * * *", Command: "CALL rotate_partitions('events')"},
+	}, r.Objects)
+	buf, err := MarshalHCL(r)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+
+	err = EvalHCLBytes([]byte(`
+cron_job "a" {
+  schedule = "0 3 * * *"
+  command  = "SELECT 1"
+}
+cron_job "a" {
+  schedule = "0 4 * * *"
+  command  = "SELECT 2"
+}
+`), &schema.Realm{}, nil)
+	require.EqualError(t, err, `postgres: cron job "a" is defined more than once`)
+}
+
 func TestMarshalSpec_IndexPredicate(t *testing.T) {
 	s := &schema.Schema{
 		Name: "test",
@@ -1279,6 +1469,277 @@ table "t" {
 		require.EqualValues(t, 10, id.Sequence.Start)
 		require.Zero(t, id.Sequence.Increment)
 	})
+	t.Run("Options", func(t *testing.T) {
+		var (
+			s schema.Schema
+			f = `table "t" {
+  schema = schema.s
+  column "c" {
+    null = false
+    type = integer
+    identity {
+      generated = ALWAYS
+      start     = 10
+      min_value = 10
+      max_value = 1000
+      cache     = 20
+      cycle     = true
+    }
+  }
+}
+schema "s" {
+}
+`
+		)
+		require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+		id := s.Tables[0].Columns[0].Attrs[0].(*Identity)
+		require.Equal(t, &Sequence{Start: 10, Min: sqlx.P[int64](10), Max: sqlx.P[int64](1000), Cache: 20, Cycle: true}, id.Sequence)
+		buf, err := MarshalHCL(&s)
+		require.NoError(t, err)
+		require.Equal(t, f, string(buf))
+	})
+}
+
+func TestMarshalSpec_Extensions(t *testing.T) {
+	r := schema.NewRealm(schema.New("public"))
+	r.AddObjects(
+		&Extension{Name: "hstore", Schema: "public", Version: "1.8", Attrs: []schema.Attr{&schema.Comment{Text: "key-value pairs"}}},
+		&Extension{Name: "postgis", Schema: "extensions"},
+	)
+	buf, err := MarshalHCL(r)
+	require.NoError(t, err)
+	require.Equal(t, `extension "hstore" {
+  schema  = schema.public
+  version = "1.8"
+  comment = "key-value pairs"
+}
+extension "postgis" {
+  schema = "extensions"
+}
+schema "public" {
+}
+`, string(buf))
+
+	var got schema.Realm
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	require.Equal(t, r.Objects, got.Objects)
+
+	err = EvalHCLBytes([]byte(`
+extension "ltree" {}
+extension "ltree" {}
+`), &got, nil)
+	require.EqualError(t, err, `postgres: extension "ltree" is defined more than once`)
+}
+
+func TestMarshalSpec_Triggers(t *testing.T) {
+	s := schema.New("public")
+	users := schema.NewTable("users").AddColumns(schema.NewIntColumn("id", TypeInteger), schema.NewStringColumn("name", TypeText))
+	s.AddTables(users)
+	name, _ := users.Column("name")
+	users.Triggers = []*schema.Trigger{
+		{
+			Name: "audit", Table: users, ActionTime: schema.TriggerTimeAfter, For: schema.TriggerForRow,
+			Events: []schema.TriggerEvent{schema.TriggerEventInsert, schema.TriggerEventUpdateOf(name), schema.TriggerEventDelete},
+			Body:   "audit()",
+			Attrs:  []schema.Attr{&TriggerWhen{X: "(new.id > 0)"}, &TriggerState{V: TriggerStateDisabled}},
+		},
+		{
+			Name: "truncate", Table: users, ActionTime: schema.TriggerTimeBefore, For: schema.TriggerForStmt,
+			Events: []schema.TriggerEvent{schema.TriggerEventTruncate},
+			Body:   "prevent()",
+		},
+	}
+	buf, err := MarshalHCL(s)
+	require.NoError(t, err)
+	require.Equal(t, `table "users" {
+  schema = schema.public
+  column "id" {
+    null = false
+    type = integer
+  }
+  column "name" {
+    null = false
+    type = text
+  }
+}
+trigger "audit" {
+  on      = table.users
+  foreach = ROW
+  when    = "(new.id > 0)"
+  execute = "audit()"
+  state   = DISABLED
+  after {
+    insert    = true
+    update_of = [table.users.column.name]
+    delete    = true
+  }
+}
+trigger "truncate" {
+  on      = table.users
+  foreach = STATEMENT
+  execute = "prevent()"
+  before {
+    truncate = true
+  }
+}
+schema "public" {
+}
+`, string(buf))
+
+	var got schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	tb, ok := got.Table("users")
+	require.True(t, ok)
+	require.Len(t, tb.Triggers, 2)
+	for i, tg := range tb.Triggers {
+		require.Equal(t, users.Triggers[i].Name, tg.Name)
+		require.Equal(t, tb, tg.Table)
+		require.True(t, triggerDefEqual(users.Triggers[i], tg))
+		require.Equal(t, triggerState(users.Triggers[i]), triggerState(tg))
+	}
+	require.Equal(t, tb.Columns[1], tb.Triggers[0].Events[1].Columns[0])
+
+	err = EvalHCLBytes([]byte(`
+schema "public" {}
+table "users" {
+  schema = schema.public
+  column "id" {
+    type = int
+  }
+}
+trigger "audit" {
+  on = table.users
+  before {
+    insert = true
+  }
+  after {
+    insert = true
+  }
+  execute = "audit()"
+}
+`), &got, nil)
+	require.EqualError(t, err, `postgres: multiple action times were defined for trigger "audit"`)
+}
+
+func TestMarshalSpec_Domains(t *testing.T) {
+	s := schema.New("public")
+	d := &DomainType{
+		T: "posint", Schema: s, Type: &schema.IntegerType{T: TypeInteger}, Default: &schema.Literal{V: "1"},
+		Checks: []*schema.Check{{Name: "posint_check", Expr: "(VALUE > 0)"}},
+		Attrs:  []schema.Attr{&schema.Comment{Text: "positive"}},
+	}
+	s.AddObjects(d)
+	s.AddTables(schema.NewTable("users").AddColumns(schema.NewColumn("age").SetType(d)))
+	buf, err := MarshalHCL(s)
+	require.NoError(t, err)
+	require.Equal(t, `table "users" {
+  schema = schema.public
+  column "age" {
+    null = false
+    type = domain.posint
+  }
+}
+domain "posint" {
+  schema  = schema.public
+  type    = integer
+  null    = false
+  default = 1
+  comment = "positive"
+  check "posint_check" {
+    expr = "(VALUE > 0)"
+  }
+}
+schema "public" {
+}
+`, string(buf))
+
+	var got schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	gotD, ok := findDomain(&got, "posint")
+	require.True(t, ok)
+	require.Equal(t, gotD, got.Tables[0].Columns[0].Type.Type)
+	require.False(t, domainChanged(d, gotD))
+}
+
+func TestMarshalSpec_ColumnGrants(t *testing.T) {
+	s := schema.New("s").AddTables(
+		schema.NewTable("t").AddColumns(
+			schema.NewStringColumn("c", "text").AddAttrs(
+				&ColumnGrant{Grantee: "PUBLIC", Privileges: []string{"SELECT"}},
+				&ColumnGrant{Grantee: "support", Privileges: []string{"SELECT", "UPDATE"}, Grantable: true},
+			),
+		),
+	)
+	buf, err := MarshalHCL(s)
+	require.NoError(t, err)
+	require.Equal(t, `table "t" {
+  schema = schema.s
+  column "c" {
+    null = false
+    type = text
+    grant "PUBLIC" {
+      privileges = ["SELECT"]
+    }
+    grant "support" {
+      privileges = ["SELECT", "UPDATE"]
+      grantable  = true
+    }
+  }
+}
+schema "s" {
+}
+`, string(buf))
+
+	var got schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	require.Equal(t, s.Tables[0].Columns[0].Attrs, got.Tables[0].Columns[0].Attrs)
+}
+
+func TestMarshalSpec_TableSchemaGrants(t *testing.T) {
+	s := schema.New("s").
+		AddAttrs(&SchemaGrant{Grantee: "app", Privileges: []string{"USAGE"}}).
+		AddTables(
+			schema.NewTable("t").
+				AddColumns(schema.NewStringColumn("c", "text")).
+				AddAttrs(
+					&TableGrant{Grantee: "PUBLIC", Privileges: []string{"SELECT"}},
+					&TableGrant{Grantee: "app", Privileges: []string{"INSERT", "SELECT"}, Grantable: true},
+				),
+		)
+	buf, err := MarshalHCL(s)
+	require.NoError(t, err)
+	require.Equal(t, `table "t" {
+  schema = schema.s
+  column "c" {
+    null = false
+    type = text
+  }
+  grant "PUBLIC" {
+    privileges = ["SELECT"]
+  }
+  grant "app" {
+    privileges = ["INSERT", "SELECT"]
+    grantable  = true
+  }
+}
+schema "s" {
+  grant "app" {
+    privileges = ["USAGE"]
+  }
+}
+`, string(buf))
+
+	var got schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	require.Equal(t, s.Attrs, got.Attrs)
+	require.Equal(t, s.Tables[0].Attrs, got.Tables[0].Attrs)
+
+	err = EvalHCLBytes([]byte(`
+schema "s" {
+  grant "app" {}
+}
+`), &got, nil)
+	require.EqualError(t, err, `schema "s": postgres: grant must define a grantee and at least one privilege`)
 }
 
 func TestUnmarshalSpec_IndexInclude(t *testing.T) {
@@ -1525,6 +1986,56 @@ table "users" {
 	require.EqualValues(t, expected, &s)
 }
 
+func TestUnmarshalSpec_EnumDataSource(t *testing.T) {
+	var (
+		rows []string
+		src  = map[string]schemahcl.BlockFunc{
+			// A fake "sql" data source that returns the rows of a reference table.
+			"sql": func(context.Context, *hcl.EvalContext, *hclsyntax.Block) (cty.Value, error) {
+				vs := make([]cty.Value, len(rows))
+				for i, r := range rows {
+					vs[i] = cty.StringVal(r)
+				}
+				return cty.ObjectVal(map[string]cty.Value{"values": cty.ListVal(vs)}), nil
+			},
+		}
+		eval = func(t *testing.T) (*schema.Schema, error) {
+			p := hclparse.NewParser()
+			_, diags := p.ParseHCL([]byte(`
+data "sql" "statuses" {
+  query = "SELECT name FROM statuses ORDER BY id"
+}
+
+schema "public" {}
+
+enum "status" {
+  schema = schema.public
+  values = data.sql.statuses.values
+}
+`), "")
+			require.False(t, diags.HasErrors())
+			var s schema.Schema
+			return &s, codec.EvalOptions(p, &s, &schemahcl.EvalOptions{DataSources: src})
+		}
+	)
+	rows = []string{"active", "inactive"}
+	s, err := eval(t)
+	require.NoError(t, err)
+	e, ok := s.Object(func(o schema.Object) bool {
+		e, ok := o.(*schema.EnumType)
+		return ok && e.T == "status"
+	})
+	require.True(t, ok)
+	require.Equal(t, []string{"active", "inactive"}, e.(*schema.EnumType).Values)
+
+	rows = []string{"active", "inactive", "active"}
+	_, err = eval(t)
+	require.EqualError(t, err, `enum "status" contains duplicate value "active"`)
+	rows = []string{"active", ""}
+	_, err = eval(t)
+	require.EqualError(t, err, `enum "status" contains an empty value`)
+}
+
 func TestMarshalSpec_Enum(t *testing.T) {
 	stateE := &schema.EnumType{
 		T:      "state",
@@ -1534,9 +2045,13 @@ func TestMarshalSpec_Enum(t *testing.T) {
 		T:      "account_type",
 		Values: []string{"private", "business"},
 	}
+	// Enum types without values.
+	pendingE := &schema.EnumType{
+		T: "pending",
+	}
 	s := schema.New("test").
 		AddObjects(
-			typeE, stateE,
+			typeE, stateE, pendingE,
 		).
 		AddTables(
 			schema.NewTable("account").
@@ -1585,6 +2100,9 @@ enum "state" {
   schema = schema.test
   values = ["on", "off"]
 }
+enum "pending" {
+  schema = schema.test
+}
 schema "test" {
 }
 `
@@ -2198,6 +2716,49 @@ func TestParseType_Interval(t *testing.T) {
 	}
 }
 
+func TestFormatType_RoundTrip(t *testing.T) {
+	for _, typ := range []string{
+		"numeric",
+		"numeric(10)",
+		"numeric(10,2)",
+		"bit",
+		"bit(8)",
+		"bit varying",
+		"bit varying(64)",
+		"character(5)",
+		"character varying(255)",
+		"time(3) without time zone",
+		"time with time zone",
+		"timestamp(0) without time zone",
+		"timestamp(4) with time zone",
+		"interval",
+		"interval(3)",
+		"interval day to second(2)",
+		"integer[]",
+	} {
+		t.Run(typ, func(t *testing.T) {
+			t1, err := ParseType(typ)
+			require.NoError(t, err)
+			f, err := FormatType(t1)
+			require.NoError(t, err)
+			t2, err := ParseType(f)
+			require.NoError(t, err)
+			require.Equal(t, t1, t2)
+		})
+	}
+	for _, tt := range []struct {
+		typ      string
+		expected schema.Type
+	}{
+		{typ: "varbit", expected: &BitType{T: TypeBitVar}},
+		{typ: "varbit(10)", expected: &BitType{T: TypeBitVar, Len: 10}},
+	} {
+		typ, err := ParseType(tt.typ)
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, typ)
+	}
+}
+
 func TestRegistrySanity(t *testing.T) {
 	spectest.RegistrySanityTest(t, TypeRegistry, []string{"enum"})
 }
@@ -2502,3 +3063,1460 @@ schema "public" {
 	require.Equal(t, &IndexInclude{Columns: []*schema.Column{s.Tables[0].Columns[1]}}, u3.Attrs[0])
 	require.Equal(t, UniqueConstraint("u3"), u3.Attrs[1].(*Constraint))
 }
+
+func TestMarshalSpec_Funcs(t *testing.T) {
+	s := schema.New("public")
+	s.AddFuncs(
+		&schema.Func{
+			Name: "add", Lang: "sql", Body: "SELECT a + b",
+			Args:  []*schema.FuncArg{{Name: "a", Type: &schema.IntegerType{T: TypeInteger}}, {Name: "b", Type: &schema.IntegerType{T: TypeInteger}, Default: &schema.Literal{V: "1"}}},
+			Ret:   &schema.IntegerType{T: TypeInteger},
+			Attrs: []schema.Attr{&FuncVolatility{V: VolatilityImmutable}, &schema.Comment{Text: "adds numbers"}},
+		},
+	)
+	s.AddProcs(
+		&schema.Proc{
+			Name: "cleanup", Lang: "plpgsql", Body: "BEGIN DELETE FROM t; END",
+			Args: []*schema.FuncArg{{Name: "n", Type: &schema.IntegerType{T: TypeBigInt}, Mode: schema.FuncArgModeInOut}},
+		},
+	)
+	buf, err := MarshalHCL(s)
+	require.NoError(t, err)
+	require.Equal(t, `function "add" {
+  schema     = schema.public
+  lang       = SQL
+  return     = integer
+  as         = "SELECT a + b"
+  volatility = IMMUTABLE
+  comment    = "adds numbers"
+  arg "a" {
+    type = integer
+  }
+  arg "b" {
+    type    = integer
+    default = 1
+  }
+}
+procedure "cleanup" {
+  schema = schema.public
+  lang   = PLpgSQL
+  as     = "BEGIN DELETE FROM t; END"
+  arg "n" {
+    type = bigint
+    mode = INOUT
+  }
+}
+schema "public" {
+}
+`, string(buf))
+
+	var got schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	require.Len(t, got.Funcs, 1)
+	require.Len(t, got.Procs, 1)
+	d := &diff{conn: &conn{}}
+	require.Equal(t, "add", got.Funcs[0].Name)
+	changed, err := d.funcChanged(s.Funcs[0], got.Funcs[0])
+	require.NoError(t, err)
+	require.False(t, changed)
+	require.Equal(t, "cleanup", got.Procs[0].Name)
+	changed, err = d.procChanged(s.Procs[0], got.Procs[0])
+	require.NoError(t, err)
+	require.False(t, changed)
+}
+
+func TestMarshalSpec_PrettyFuncs(t *testing.T) {
+	s := schema.New("public")
+	s.AddFuncs(
+		&schema.Func{
+			Name: "audit", Lang: "plpgsql", Ret: &PseudoType{T: "trigger"},
+			Body: "\n\tBEGIN\n\t\tINSERT INTO audit_log (op, note) VALUES (TG_OP, 'multi\n\tline');\n\n\n\t\tRETURN NEW;\n\tEND;\n",
+		},
+	)
+	buf, err := MarshalHCL(s)
+	require.NoError(t, err)
+	require.Equal(t, `function "audit" {
+  schema = schema.public
+  lang   = PLpgSQL
+  return = trigger
+  as     = <<-SQL
+  BEGIN
+    INSERT INTO audit_log (op, note) VALUES (TG_OP, 'multi
+  	line');
+
+    RETURN NEW;
+  END;
+  SQL
+}
+schema "public" {
+}
+`, string(buf))
+
+	var got schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	require.Len(t, got.Funcs, 1)
+	changed, err := (&diff{conn: &conn{}}).funcChanged(s.Funcs[0], got.Funcs[0])
+	require.NoError(t, err)
+	require.False(t, changed)
+}
+
+func TestMarshalSpec_Composites(t *testing.T) {
+	s := schema.New("public")
+	mood := &schema.EnumType{T: "mood", Values: []string{"happy", "sad"}, Schema: s}
+	address := &CompositeType{
+		T: "address", Schema: s,
+		Fields: []*schema.Column{
+			schema.NewStringColumn("street", TypeText).SetCollation("C"),
+			schema.NewColumn("mood").SetType(mood),
+		},
+		Attrs: []schema.Attr{&schema.Comment{Text: "postal address"}},
+	}
+	s.AddObjects(mood, address)
+	s.AddTables(schema.NewTable("users").AddColumns(schema.NewColumn("home").SetType(address)))
+	buf, err := MarshalHCL(s)
+	require.NoError(t, err)
+	require.Equal(t, `table "users" {
+  schema = schema.public
+  column "home" {
+    null = false
+    type = composite.address
+  }
+}
+enum "mood" {
+  schema = schema.public
+  values = ["happy", "sad"]
+}
+composite "address" {
+  schema  = schema.public
+  comment = "postal address"
+  field "street" {
+    type    = text
+    collate = "C"
+  }
+  field "mood" {
+    type = enum.mood
+  }
+}
+schema "public" {
+}
+`, string(buf))
+
+	var got schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	c, ok := findComposite(&got, "address")
+	require.True(t, ok)
+	require.False(t, compositeChanged(address, c))
+	e, ok := c.Fields[1].Type.Type.(*schema.EnumType)
+	require.True(t, ok)
+	require.Equal(t, []schema.Object{e}, c.Deps)
+	users, ok := got.Table("users")
+	require.True(t, ok)
+	require.Equal(t, c, users.Columns[0].Type.Type)
+}
+
+func TestMarshalSpec_Ranges(t *testing.T) {
+	s := schema.New("public")
+	mood := &schema.EnumType{T: "mood", Values: []string{"happy", "sad"}, Schema: s}
+	moods := &UserRangeType{T: "moodrange", Schema: s, Subtype: mood}
+	floats := &UserRangeType{
+		T: "floatrange", Schema: s,
+		Subtype:     &schema.FloatType{T: TypeDouble},
+		SubtypeDiff: "float8mi",
+		Attrs:       []schema.Attr{&schema.Comment{Text: "float ranges"}},
+	}
+	texts := &UserRangeType{T: "textrange", Schema: s, Subtype: &schema.StringType{T: TypeText}, Collation: "C", Canonical: "public.canon"}
+	s.AddObjects(mood, floats, moods, texts)
+	s.AddTables(schema.NewTable("shifts").AddColumns(schema.NewColumn("hours").SetType(floats)))
+	buf, err := MarshalHCL(s)
+	require.NoError(t, err)
+	require.Equal(t, `table "shifts" {
+  schema = schema.public
+  column "hours" {
+    null = false
+    type = range.floatrange
+  }
+}
+enum "mood" {
+  schema = schema.public
+  values = ["happy", "sad"]
+}
+range "floatrange" {
+  schema       = schema.public
+  subtype      = double_precision
+  subtype_diff = "float8mi"
+  comment      = "float ranges"
+}
+range "moodrange" {
+  schema  = schema.public
+  subtype = enum.mood
+}
+range "textrange" {
+  schema    = schema.public
+  subtype   = text
+  collate   = "C"
+  canonical = "public.canon"
+}
+schema "public" {
+}
+`, string(buf))
+
+	var got schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	for _, r1 := range []*UserRangeType{floats, moods, texts} {
+		r2, ok := findRange(&got, r1.T)
+		require.True(t, ok)
+		require.True(t, rangeEqual(r1, r2))
+		require.Equal(t, rangeComment(r1), rangeComment(r2))
+	}
+	r, _ := findRange(&got, "moodrange")
+	e, ok := r.Subtype.(*schema.EnumType)
+	require.True(t, ok)
+	require.Equal(t, []schema.Object{e}, r.Deps)
+	shifts, ok := got.Table("shifts")
+	require.True(t, ok)
+	r, _ = findRange(&got, "floatrange")
+	require.Equal(t, r, shifts.Columns[0].Type.Type)
+
+	err = EvalHCLBytes([]byte(`
+schema "public" {}
+range "r" {
+  schema = schema.public
+}
+`), &got, nil)
+	require.EqualError(t, err, `missing subtype definition for range "r"`)
+}
+
+func TestMarshalSpec_EventTriggers(t *testing.T) {
+	s := schema.New("audit")
+	fn := &schema.Func{Name: "log_ddl", Lang: "plpgsql", Body: "BEGIN END", Ret: &PseudoType{T: "event_trigger"}}
+	s.AddFuncs(fn)
+	r := schema.NewRealm(s)
+	r.AddObjects(
+		&EventTrigger{Name: "log_ddl", Event: "ddl_command_end", Tags: []string{"CREATE TABLE", "ALTER TABLE"}, Func: fn, Attrs: []schema.Attr{&schema.Comment{Text: "audit DDL"}}},
+		&EventTrigger{Name: "log_drops", Event: "sql_drop", Func: fn, Attrs: []schema.Attr{&TriggerState{V: TriggerStateDisabled}}},
+	)
+	buf, err := MarshalHCL(r)
+	require.NoError(t, err)
+	require.Equal(t, `function "log_ddl" {
+  schema = schema.audit
+  lang   = PLpgSQL
+  return = event_trigger
+  as     = "BEGIN END"
+}
+event_trigger "log_ddl" {
+  on      = ddl_command_end
+  tags    = ["CREATE TABLE", "ALTER TABLE"]
+  execute = function.log_ddl
+  comment = "audit DDL"
+}
+event_trigger "log_drops" {
+  on      = sql_drop
+  execute = function.log_ddl
+  state   = DISABLED
+}
+schema "audit" {
+}
+`, string(buf))
+
+	var got schema.Realm
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	changes, err := DefaultDiff.RealmDiff(r, &got)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+	e, ok := findEventTrigger(&got, "log_ddl")
+	require.True(t, ok)
+	require.Equal(t, got.Schemas[0].Funcs[0], e.Func)
+
+	err = EvalHCLBytes([]byte(`
+schema "audit" {}
+event_trigger "log_ddl" {
+  on      = ddl_command_end
+  execute = function.log_ddl
+}
+`), &got, nil)
+	require.Error(t, err)
+}
+
+func TestMarshalSpec_Publications(t *testing.T) {
+	s := schema.New("public")
+	r := schema.NewRealm(s)
+	users := schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"), schema.NewBoolColumn("active", "boolean"))
+	s.AddTables(users)
+	r.AddObjects(
+		&Publication{Name: "all", AllTables: true, Publish: []string{"insert", "update"}},
+		&Publication{Name: "public", Schemas: []*schema.Schema{s}},
+		&Publication{
+			Name:   "users",
+			Tables: []*PublicationTable{{Table: users, Columns: users.Columns[:1], Where: "(active)"}},
+			Attrs:  []schema.Attr{&schema.Comment{Text: "active users"}},
+		},
+	)
+	buf, err := MarshalHCL(r)
+	require.NoError(t, err)
+	require.Equal(t, `table "users" {
+  schema = schema.public
+  column "id" {
+    null = false
+    type = int
+  }
+  column "active" {
+    null = false
+    type = boolean
+  }
+}
+publication "all" {
+  all_tables = true
+  publish    = ["insert", "update"]
+}
+publication "public" {
+  schemas = [schema.public]
+}
+publication "users" {
+  comment = "active users"
+  on {
+    table   = table.users
+    columns = ["id"]
+    where   = "(active)"
+  }
+}
+schema "public" {
+}
+`, string(buf))
+
+	var got schema.Realm
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	changes, err := DefaultDiff.RealmDiff(r, &got)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+	p, ok := findPublication(&got, "users")
+	require.True(t, ok)
+	require.Equal(t, got.Schemas[0].Tables[0], p.Tables[0].Table)
+
+	// Publishing all operations is equivalent to the default,
+	// and row filters are compared with their parentheses.
+	var got2 schema.Realm
+	require.NoError(t, EvalHCLBytes([]byte(`
+schema "public" {}
+table "users" {
+  schema = schema.public
+  column "id" {
+    type = int
+  }
+  column "active" {
+    type = boolean
+  }
+}
+publication "users" {
+  comment = "active users"
+  publish = ["TRUNCATE", "delete", "update", "insert"]
+  on {
+    table   = table.users
+    columns = ["id"]
+    where   = "active"
+  }
+}
+`), &got2, nil))
+	p, ok = findPublication(&got2, "users")
+	require.True(t, ok)
+	require.Nil(t, p.Publish)
+	require.False(t, publicationChanged(r.Objects[2].(*Publication), p))
+
+	for _, c := range []struct{ spec, err string }{
+		{
+			spec: `publication "p" {
+  all_tables = true
+  schemas    = [schema.public]
+}`,
+			err: `postgres: publication "p" cannot publish all tables along with specific tables or schemas`,
+		},
+		{
+			spec: `publication "p" {
+  publish = ["insert", "select"]
+}`,
+			err: `postgres: unknown operation "select" in publication "p"`,
+		},
+		{
+			spec: `publication "p" {
+  on {
+    table   = table.users
+    columns = ["name"]
+  }
+}`,
+			err: `postgres: table of publication "p": column "name" of table "users" was not found`,
+		},
+	} {
+		var r schema.Realm
+		err := EvalHCLBytes([]byte(`
+schema "public" {}
+table "users" {
+  schema = schema.public
+  column "id" {
+    type = int
+  }
+}
+`+c.spec), &r, nil)
+		require.EqualError(t, err, c.err)
+	}
+}
+
+func TestMarshalSpec_ForeignData(t *testing.T) {
+	s := schema.New("public")
+	r := schema.NewRealm(s)
+	srv := &ForeignServer{Name: "films", Wrapper: "postgres_fdw", Version: "13", Options: map[string]string{"host": "films.internal", "dbname": "films"}, Attrs: []schema.Attr{&schema.Comment{Text: "films db"}}}
+	r.AddObjects(
+		srv,
+		&UserMapping{User: "PUBLIC", Server: srv, Options: map[string]string{"user": "reader"}},
+		&UserMapping{User: "admin", Server: srv},
+	)
+	s.AddObjects(&ForeignTable{
+		Name:    "films",
+		Schema:  s,
+		Server:  srv,
+		Columns: []*schema.Column{{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "integer"}}}},
+		Options: map[string]string{"table_name": "films"},
+		Attrs:   []schema.Attr{&schema.Comment{Text: "remote films"}},
+	})
+	buf, err := MarshalHCL(r)
+	require.NoError(t, err)
+	require.Equal(t, `foreign_table "films" {
+  schema  = schema.public
+  server  = foreign_server.films
+  comment = "remote films"
+  column "id" {
+    null = false
+    type = integer
+  }
+  options {
+    table_name = "films"
+  }
+}
+foreign_server "films" {
+  wrapper = "postgres_fdw"
+  version = "13"
+  comment = "films db"
+  options {
+    dbname = "films"
+    host   = "films.internal"
+  }
+  user_mapping "PUBLIC" {
+    options {
+      user = "reader"
+    }
+  }
+  user_mapping "admin" {
+  }
+}
+schema "public" {
+}
+`, string(buf))
+
+	var got schema.Realm
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	changes, err := DefaultDiff.RealmDiff(r, &got)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+	ft, ok := findForeignTable(got.Schemas[0], "films")
+	require.True(t, ok)
+	require.Equal(t, got.Objects[0], ft.Server)
+
+	// In schema scope, servers are referenced by name.
+	var ns schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(`
+schema "public" {}
+foreign_table "films" {
+  schema = schema.public
+  column "id" {
+    type = integer
+  }
+  server = "films"
+}
+`), &ns, nil))
+	ft, ok = findForeignTable(&ns, "films")
+	require.True(t, ok)
+	require.Equal(t, "films", ft.Server.Name)
+
+	err = EvalHCLBytes([]byte(`
+schema "public" {}
+foreign_server "films" {}
+`), &got, nil)
+	require.Error(t, err)
+}
+
+func TestMarshalSpec_TextSearch(t *testing.T) {
+	s := schema.New("public")
+	s.AddObjects(
+		&TextSearchDict{Name: "english_nostop", Schema: s, Template: "snowball", Options: map[string]string{"language": "english"}, Attrs: []schema.Attr{&schema.Comment{Text: "no stop words"}}},
+		&TextSearchConfig{Name: "english_custom", Schema: s, Parser: "default", Mappings: map[string][]string{
+			"asciiword": {"public.english_nostop", "english_stem"},
+			"word":      {"public.english_nostop", "english_stem"},
+			"int":       {"simple"},
+		}},
+	)
+	buf, err := MarshalHCL(s)
+	require.NoError(t, err)
+	require.Equal(t, `text_search_dictionary "english_nostop" {
+  schema   = schema.public
+  template = "snowball"
+  comment  = "no stop words"
+  options {
+    language = "english"
+  }
+}
+text_search_configuration "english_custom" {
+  schema = schema.public
+  parser = "default"
+  mapping {
+    tokens       = ["asciiword", "word"]
+    dictionaries = ["public.english_nostop", "english_stem"]
+  }
+  mapping {
+    tokens       = ["int"]
+    dictionaries = ["simple"]
+  }
+}
+schema "public" {
+}
+`, string(buf))
+
+	var got schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	changes, err := DefaultDiff.SchemaDiff(s, &got)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	// Objects in pg_catalog are stored unqualified, and the default parser is used if not set.
+	require.NoError(t, EvalHCLBytes([]byte(`
+schema "public" {}
+text_search_configuration "simple_custom" {
+  schema = schema.public
+  mapping {
+    tokens       = ["word"]
+    dictionaries = ["pg_catalog.simple"]
+  }
+}
+`), &got, nil))
+	c, ok := findTextSearchConfig(&got, "simple_custom")
+	require.True(t, ok)
+	require.Equal(t, "default", c.Parser)
+	require.Equal(t, map[string][]string{"word": {"simple"}}, c.Mappings)
+	err = EvalHCLBytes([]byte(`
+schema "public" {}
+text_search_configuration "simple_custom" {
+  schema = schema.public
+  mapping {
+    tokens       = ["word"]
+    dictionaries = ["simple"]
+  }
+  mapping {
+    tokens       = ["word"]
+    dictionaries = ["english_stem"]
+  }
+}
+`), &got, nil)
+	require.Error(t, err)
+}
+
+func TestMarshalSpec_VirtualFK(t *testing.T) {
+	var (
+		s   schema.Schema
+		src = `
+schema "public" {}
+table "users" {
+  schema = schema.public
+  column "id" {
+    type = int
+  }
+  primary_key {
+    columns = [column.id]
+  }
+}
+table "pets" {
+  schema = schema.public
+  column "owner_id" {
+    type = int
+  }
+  foreign_key "owner" {
+    columns     = [column.owner_id]
+    ref_columns = [table.users.column.id]
+    on_delete   = CASCADE
+    virtual     = true
+  }
+}
+`
+	)
+	require.NoError(t, EvalHCLBytes([]byte(src), &s, nil))
+	pets, ok := s.Table("pets")
+	require.True(t, ok)
+	require.Len(t, pets.ForeignKeys, 1)
+	require.True(t, pets.ForeignKeys[0].Virtual())
+	buf, err := MarshalHCL(&s)
+	require.NoError(t, err)
+	require.Contains(t, string(buf), `
+  foreign_key "owner" {
+    columns     = [column.owner_id]
+    ref_columns = [table.users.column.id]
+    on_delete   = CASCADE
+    virtual     = true
+  }
+`)
+
+	// Virtual foreign keys are not created in the database,
+	// and therefore, are not compared with inspected tables.
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddTable{T: pets}})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, `CREATE TABLE "public"."pets" ("owner_id" integer NOT NULL)`, plan.Changes[0].Cmd)
+	inspected := schema.NewTable("pets").SetSchema(schema.New("public")).AddColumns(schema.NewIntColumn("owner_id", TypeInteger))
+	changes, err := DefaultDiff.TableDiff(inspected, pets)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+	changes, err = DefaultDiff.TableDiff(pets, inspected)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestMarshalSpec_Operators(t *testing.T) {
+	s := schema.New("public")
+	s.AddObjects(
+		&Operator{Name: "<->", Schema: s, Left: "integer", Right: "integer", Function: "public.vec_distance", Commutator: "<->", Hashes: true, Attrs: []schema.Attr{&schema.Comment{Text: "distance"}}},
+		&Operator{Name: "!!", Schema: s, Right: "integer", Function: "public.vec_not"},
+		&OpFamily{Name: "vec_family", Schema: s, Method: "gist"},
+		&OpClass{
+			Name:      "vec_ops",
+			Schema:    s,
+			Method:    "gist",
+			Type:      "integer",
+			Default:   true,
+			Family:    "vec_family",
+			Operators: []*OpClassOperator{{Strategy: 15, Name: "public.<->(integer,integer)", OrderBy: "integer_ops"}},
+			Funcs:     []*OpClassFunc{{Number: 8, Name: "public.vec_distance(integer,integer)"}},
+		},
+	)
+	buf, err := MarshalHCL(s)
+	require.NoError(t, err)
+	require.Equal(t, `operator "<->" {
+  schema     = schema.public
+  left       = "integer"
+  right      = "integer"
+  function   = "public.vec_distance"
+  commutator = "<->"
+  hashes     = true
+  comment    = "distance"
+}
+operator "!!" {
+  schema   = schema.public
+  right    = "integer"
+  function = "public.vec_not"
+}
+operator_family "vec_family" {
+  schema = schema.public
+  method = "gist"
+}
+operator_class "vec_ops" {
+  schema  = schema.public
+  method  = "gist"
+  type    = "integer"
+  default = true
+  family  = "vec_family"
+  operator {
+    strategy = 15
+    name     = "public.<->(integer,integer)"
+    order_by = "integer_ops"
+  }
+  function {
+    number = 8
+    name   = "public.vec_distance(integer,integer)"
+  }
+}
+schema "public" {
+}
+`, string(buf))
+
+	var got schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	changes, err := DefaultDiff.SchemaDiff(s, &got)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	err = EvalHCLBytes([]byte(`
+schema "public" {}
+operator_class "vec_ops" {
+  schema = schema.public
+  method = "btree"
+  type   = "integer"
+  operator {
+    name = "<(integer,integer)"
+  }
+}
+`), &got, nil)
+	require.EqualError(t, err, `operator of operator class "vec_ops" must define its strategy and name`)
+	err = EvalHCLBytes([]byte(`
+schema "public" {}
+operator "<->" {
+  schema = schema.public
+  left   = "integer"
+}
+`), &got, nil)
+	require.EqualError(t, err, `operator "<->" must define its right type and function`)
+}
+
+func TestMarshalSpec_Partitions(t *testing.T) {
+	s := schema.New("public")
+	events := schema.NewTable("events").AddColumns(schema.NewTimeColumn("day", TypeDate), schema.NewStringColumn("kind", TypeText))
+	events.AddAttrs(&Partition{T: PartitionTypeRange, Parts: []*PartitionPart{{C: events.Columns[0]}}})
+	s.AddTables(events)
+	p2023 := &TablePartition{Name: "events_2023", Schema: s, Table: events, Bound: "FOR VALUES FROM ('2023-01-01') TO ('2024-01-01')", PartitionBy: "LIST (kind)"}
+	s.AddObjects(
+		p2023,
+		&TablePartition{Name: "events_2023_click", Schema: s, Parent: p2023, Bound: "FOR VALUES IN ('click')", Attrs: []schema.Attr{&schema.Comment{Text: "clicks"}}},
+	)
+	buf, err := MarshalHCL(s)
+	require.NoError(t, err)
+	require.Equal(t, `table "events" {
+  schema = schema.public
+  column "day" {
+    null = false
+    type = date
+  }
+  column "kind" {
+    null = false
+    type = text
+  }
+  partition {
+    type    = RANGE
+    columns = [column.day]
+  }
+}
+table_partition "events_2023" {
+  schema       = schema.public
+  of           = table.events
+  bound        = "FOR VALUES FROM ('2023-01-01') TO ('2024-01-01')"
+  partition_by = "LIST (kind)"
+}
+table_partition "events_2023_click" {
+  schema  = schema.public
+  of      = table_partition.events_2023
+  bound   = "FOR VALUES IN ('click')"
+  comment = "clicks"
+}
+schema "public" {
+}
+`, string(buf))
+
+	var got schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	changes, err := DefaultDiff.SchemaDiff(s, &got)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+	p, ok := findPartition(&got, "events_2023_click")
+	require.True(t, ok)
+	require.Equal(t, "events_2023", p.Parent.Name)
+	require.Equal(t, got.Tables[0], p.Parent.Table)
+
+	err = EvalHCLBytes([]byte(`
+schema "public" {}
+table_partition "events_2023" {
+  schema = schema.public
+  of     = table.events
+  bound  = "DEFAULT"
+}
+`), &got, nil)
+	require.Error(t, err)
+}
+
+func TestMarshalSpec_Collations(t *testing.T) {
+	s := schema.New("public")
+	s.AddObjects(
+		&Collation{Name: "german", Schema: s, Provider: "icu", Locale: "de-u-ks-l2", Attrs: []schema.Attr{&schema.Comment{Text: "case insensitive"}}},
+		&Collation{Name: "mixed", Schema: s, Provider: "libc", LcCollate: "C", LcCtype: "en_US.UTF-8", Deterministic: true},
+	)
+	s.AddTables(schema.NewTable("users").AddColumns(schema.NewStringColumn("name", TypeText).SetCollation("german")))
+	buf, err := MarshalHCL(s)
+	require.NoError(t, err)
+	require.Equal(t, `table "users" {
+  schema = schema.public
+  column "name" {
+    null    = false
+    type    = text
+    collate = "german"
+  }
+}
+collation "german" {
+  schema        = schema.public
+  provider      = "icu"
+  locale        = "de-u-ks-l2"
+  deterministic = false
+  comment       = "case insensitive"
+}
+collation "mixed" {
+  schema     = schema.public
+  lc_collate = "C"
+  lc_ctype   = "en_US.UTF-8"
+}
+schema "public" {
+}
+`, string(buf))
+
+	var got schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	changes, err := DefaultDiff.SchemaDiff(s, &got)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	// Identical categories are normalized to a single locale.
+	require.NoError(t, EvalHCLBytes([]byte(`
+schema "public" {}
+collation "posix" {
+  schema     = schema.public
+  lc_collate = "POSIX"
+  lc_ctype   = "POSIX"
+}
+`), &got, nil))
+	c, ok := findCollation(&got, "posix")
+	require.True(t, ok)
+	require.Equal(t, "POSIX", c.Locale)
+	require.True(t, c.Deterministic)
+	err = EvalHCLBytes([]byte(`
+schema "public" {}
+collation "c" {
+  schema = schema.public
+}
+`), &got, nil)
+	require.EqualError(t, err, `collation "c" must define a locale, or both lc_collate and lc_ctype`)
+}
+
+func TestEvalHCL_Pos(t *testing.T) {
+	p := hclparse.NewParser()
+	_, diags := p.ParseHCL([]byte(`schema "public" {}
+
+table "users" {
+  schema = schema.public
+  column "id" {
+    type = int
+  }
+}
+
+function "one" {
+  schema = schema.public
+  lang   = SQL
+  return = integer
+  as     = "SELECT 1"
+}
+
+trigger "t" {
+  on = table.users
+  before {
+    insert = true
+  }
+  execute = "EXECUTE FUNCTION f()"
+}
+`), "schema.hcl")
+	require.False(t, diags.HasErrors())
+	var r schema.Realm
+	require.NoError(t, codec.EvalOptions(p, &r, &schemahcl.EvalOptions{RecordPos: true}))
+	users, ok := r.Schemas[0].Table("users")
+	require.True(t, ok)
+	pos, ok := users.Columns[0].Pos()
+	require.True(t, ok)
+	require.Equal(t, "schema.hcl:5:3", pos.String())
+	pos, ok = r.Schemas[0].Funcs[0].Pos()
+	require.True(t, ok)
+	require.Equal(t, "schema.hcl:10:1", pos.String())
+	pos, ok = users.Triggers[0].Pos()
+	require.True(t, ok)
+	require.Equal(t, "schema.hcl:17:1", pos.String())
+
+	// Planned statements point to the elements that caused them.
+	changes, err := DefaultDiff.RealmDiff(schema.NewRealm(), &r)
+	require.NoError(t, err)
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	var got []string
+	for _, c := range plan.Changes {
+		if pos, ok := c.Pos(); ok {
+			got = append(got, pos.String())
+		}
+	}
+	require.Equal(t, []string{"schema.hcl:1:1", "schema.hcl:10:1", "schema.hcl:3:1", "schema.hcl:17:1"}, got)
+}
+
+func TestMarshalSpec_Exclude(t *testing.T) {
+	var (
+		s = schema.New("public")
+		b = schema.NewTable("bookings").
+			AddColumns(
+				schema.NewIntColumn("room", TypeInteger),
+				schema.NewColumn("during").SetType(&RangeType{T: TypeTSRange}),
+			)
+	)
+	s.AddTables(b)
+	b.AddIndexes(
+		schema.NewIndex("no_overlap").
+			AddParts(
+				schema.NewColumnPart(b.Columns[0]).AddAttrs(&Operator{Name: "="}),
+				schema.NewColumnPart(b.Columns[1]).AddAttrs(&Operator{Name: "&&"}),
+			).
+			AddAttrs(&IndexType{T: IndexTypeGiST}, ExcludeConstraint("no_overlap"), &IndexPredicate{P: "room > 0"}),
+	)
+	buf, err := MarshalHCL(s)
+	require.NoError(t, err)
+	require.Equal(t, `table "bookings" {
+  schema = schema.public
+  column "room" {
+    null = false
+    type = integer
+  }
+  column "during" {
+    null = false
+    type = tsrange
+  }
+  exclude "no_overlap" {
+    type  = GIST
+    where = "room > 0"
+    on {
+      column = column.room
+      op     = "="
+    }
+    on {
+      column = column.during
+      op     = "&&"
+    }
+  }
+}
+schema "public" {
+}
+`, string(buf))
+
+	var got schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	changes, err := DefaultDiff.SchemaDiff(s, &got)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+	idx := got.Tables[0].Indexes[0]
+	c, ok := excludeConst(idx.Attrs)
+	require.True(t, ok)
+	require.Equal(t, "no_overlap", c.N)
+	require.Equal(t, []schema.Attr{&Operator{Name: "&&"}}, idx.Parts[1].Attrs)
+
+	// Changing an operator rebuilds the constraint.
+	idx.Parts[1].Attrs = []schema.Attr{&Operator{Name: "="}}
+	changes, err = DefaultDiff.SchemaDiff(s, &got)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, `ALTER TABLE "public"."bookings" DROP CONSTRAINT "no_overlap", ADD CONSTRAINT "no_overlap" EXCLUDE USING GIST ("room" WITH =, "during" WITH =) WHERE room > 0`, plan.Changes[0].Cmd)
+
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddTable{T: b}})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, `CREATE TABLE "public"."bookings" ("room" integer NOT NULL, "during" tsrange NOT NULL, CONSTRAINT "no_overlap" EXCLUDE USING GIST ("room" WITH =, "during" WITH &&) WHERE room > 0)`, plan.Changes[0].Cmd)
+
+	err = EvalHCLBytes([]byte(`
+schema "public" {}
+table "bookings" {
+  schema = schema.public
+  column "room" {
+    type = integer
+  }
+  exclude "no_overlap" {
+    columns = [column.room]
+  }
+}
+`), &got, nil)
+	require.EqualError(t, err, `cannot convert table "bookings": bookings.exclude constraint "no_overlap": elements must be defined using 'on' blocks with their operators`)
+}
+
+func TestMarshalSpec_Deferrable(t *testing.T) {
+	var (
+		s = schema.New("public")
+		u = schema.NewTable("users").
+			AddColumns(
+				schema.NewIntColumn("id", TypeInteger),
+				schema.NewIntColumn("email", TypeInteger),
+			)
+		p = schema.NewTable("posts").
+			AddColumns(schema.NewIntColumn("author_id", TypeInteger))
+	)
+	s.AddTables(u, p)
+	u.SetPrimaryKey(schema.NewPrimaryKey(u.Columns[0]))
+	u.AddIndexes(
+		schema.NewUniqueIndex("users_email_key").
+			AddColumns(u.Columns[1]).
+			AddAttrs(UniqueConstraint("users_email_key"), &Deferrable{}),
+	)
+	p.AddForeignKeys(
+		schema.NewForeignKey("author_fk").
+			AddColumns(p.Columns[0]).
+			SetRefTable(u).
+			AddRefColumns(u.Columns[0]).
+			AddAttrs(&Deferrable{InitiallyDeferred: true}),
+	)
+	buf, err := MarshalHCL(s)
+	require.NoError(t, err)
+	require.Contains(t, string(buf), `  unique "users_email_key" {
+    columns    = [column.email]
+    deferrable = true
+  }`)
+	require.Contains(t, string(buf), `  foreign_key "author_fk" {
+    columns            = [column.author_id]
+    ref_columns        = [table.users.column.id]
+    deferrable         = true
+    initially_deferred = true
+  }`)
+
+	var got schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	changes, err := DefaultDiff.SchemaDiff(s, &got)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	// Deferrability of foreign keys is altered in place.
+	gp, ok := got.Table("posts")
+	require.True(t, ok)
+	gp.ForeignKeys[0].Attrs = []schema.Attr{&Deferrable{}}
+	changes, err = DefaultDiff.SchemaDiff(s, &got)
+	require.NoError(t, err)
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, `ALTER TABLE "public"."posts" ALTER CONSTRAINT "author_fk" DEFERRABLE INITIALLY IMMEDIATE`, plan.Changes[0].Cmd)
+	require.Equal(t, `ALTER TABLE "public"."posts" ALTER CONSTRAINT "author_fk" DEFERRABLE INITIALLY DEFERRED`, plan.Changes[0].Reverse)
+	gp.ForeignKeys[0].Attrs = nil
+	changes, err = DefaultDiff.SchemaDiff(s, &got)
+	require.NoError(t, err)
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, `ALTER TABLE "public"."posts" ALTER CONSTRAINT "author_fk" NOT DEFERRABLE`, plan.Changes[0].Cmd)
+
+	// Unique constraints are recreated.
+	gp.ForeignKeys[0].Attrs = []schema.Attr{&Deferrable{InitiallyDeferred: true}}
+	gu, ok := got.Table("users")
+	require.True(t, ok)
+	gu.Indexes[0].Attrs = []schema.Attr{UniqueConstraint("users_email_key")}
+	changes, err = DefaultDiff.SchemaDiff(s, &got)
+	require.NoError(t, err)
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, `ALTER TABLE "public"."users" DROP CONSTRAINT "users_email_key", ADD CONSTRAINT "users_email_key" UNIQUE ("email")`, plan.Changes[0].Cmd)
+
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddTable{T: u}, &schema.AddTable{T: p}})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `CREATE TABLE "public"."users" ("id" integer NOT NULL, "email" integer NOT NULL, PRIMARY KEY ("id"), CONSTRAINT "users_email_key" UNIQUE ("email") DEFERRABLE)`, plan.Changes[0].Cmd)
+	require.Equal(t, `CREATE TABLE "public"."posts" ("author_id" integer NOT NULL, CONSTRAINT "author_fk" FOREIGN KEY ("author_id") REFERENCES "public"."users" ("id") DEFERRABLE INITIALLY DEFERRED)`, plan.Changes[1].Cmd)
+
+	err = EvalHCLBytes([]byte(`
+schema "public" {}
+table "users" {
+  schema = schema.public
+  column "id" {
+    type = integer
+  }
+  unique "users_id_key" {
+    columns            = [column.id]
+    initially_deferred = true
+  }
+}
+`), &got, nil)
+	require.EqualError(t, err, `cannot convert table "users": users.unique constraint "users_id_key": initially deferred constraint must be deferrable`)
+}
+
+func TestMarshalSpec_Inherits(t *testing.T) {
+	var (
+		public = schema.New("public")
+		audit  = schema.New("audit")
+		logs   = schema.NewTable("logs").AddColumns(schema.NewIntColumn("id", TypeInt))
+		base   = schema.NewTable("logs").AddColumns(schema.NewIntColumn("id", TypeInt))
+		y23    = schema.NewTable("logs_2023").AddColumns(schema.NewIntColumn("id", TypeInt))
+	)
+	public.AddTables(logs, y23)
+	audit.AddTables(base)
+	schema.NewRealm(public, audit)
+	y23.AddAttrs(&Inherits{T: []*schema.Table{logs, base}})
+	buf, err := MarshalHCL(public.Realm)
+	require.NoError(t, err)
+	require.Equal(t, `table "public" "logs" {
+  schema = schema.public
+  column "id" {
+    null = false
+    type = int
+  }
+}
+table "logs_2023" {
+  schema   = schema.public
+  inherits = [table.public.logs, table.audit.logs]
+  column "id" {
+    null = false
+    type = int
+  }
+}
+table "audit" "logs" {
+  schema = schema.audit
+  column "id" {
+    null = false
+    type = int
+  }
+}
+schema "public" {
+}
+schema "audit" {
+}
+`, string(buf))
+
+	var got schema.Realm
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	changes, err := DefaultDiff.RealmDiff(public.Realm, &got)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+	s, ok := got.Schema("public")
+	require.True(t, ok)
+	child, ok := s.Table("logs_2023")
+	require.True(t, ok)
+	parents := tableInherits(child)
+	require.Len(t, parents, 2)
+	require.Equal(t, "public", parents[0].Schema.Name)
+	require.Equal(t, "audit", parents[1].Schema.Name)
+}
+
+func TestMarshalSpec_StorageParams(t *testing.T) {
+	var (
+		public = schema.New("public")
+		events = schema.NewTable("events").AddColumns(schema.NewIntColumn("id", TypeInt)).
+			AddAttrs(&StorageParams{V: []StorageParam{
+				{Name: "fillfactor", Value: "70"},
+				{Name: "vacuum_index_cleanup", Value: "auto"},
+				{Name: "autovacuum_vacuum_scale_factor", Value: "0.05"},
+				{Name: "toast.autovacuum_enabled", Value: "false"},
+			}})
+	)
+	public.AddTables(events)
+	events.AddIndexes(schema.NewIndex("events_id").AddColumns(events.Columns[0]).
+		AddAttrs(&StorageParams{V: []StorageParam{{Name: "fillfactor", Value: "80"}}}))
+	schema.NewRealm(public)
+	buf, err := MarshalHCL(public)
+	require.NoError(t, err)
+	require.Equal(t, `table "events" {
+  schema = schema.public
+  column "id" {
+    null = false
+    type = int
+  }
+  index "events_id" {
+    columns = [column.id]
+    storage_params {
+      fillfactor = 80
+    }
+  }
+  storage_params {
+    fillfactor                     = 70
+    vacuum_index_cleanup           = "auto"
+    autovacuum_vacuum_scale_factor = 0.05
+    toast {
+      autovacuum_enabled = false
+    }
+  }
+}
+schema "public" {
+}
+`, string(buf))
+
+	var got schema.Realm
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	changes, err := DefaultDiff.RealmDiff(public.Realm, &got)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestMarshalSpec_Compression(t *testing.T) {
+	var (
+		public = schema.New("public")
+		logs   = schema.NewTable("logs").AddColumns(schema.NewStringColumn("body", TypeText).AddAttrs(&Compression{V: "lz4"}))
+	)
+	public.AddTables(logs)
+	schema.NewRealm(public)
+	buf, err := MarshalHCL(public)
+	require.NoError(t, err)
+	require.Equal(t, `table "logs" {
+  schema = schema.public
+  column "body" {
+    null        = false
+    type        = text
+    compression = "lz4"
+  }
+}
+schema "public" {
+}
+`, string(buf))
+
+	var got schema.Realm
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	require.Equal(t, []schema.Attr{&Compression{V: "lz4"}}, got.Schemas[0].Tables[0].Columns[0].Attrs)
+	changes, err := DefaultDiff.RealmDiff(public.Realm, &got)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestMarshalSpec_Unlogged(t *testing.T) {
+	var (
+		public  = schema.New("public")
+		staging = schema.NewTable("staging").AddColumns(schema.NewIntColumn("id", TypeInt)).AddAttrs(&Unlogged{})
+	)
+	public.AddTables(staging)
+	schema.NewRealm(public)
+	buf, err := MarshalHCL(public)
+	require.NoError(t, err)
+	require.Equal(t, `table "staging" {
+  schema   = schema.public
+  unlogged = true
+  column "id" {
+    null = false
+    type = int
+  }
+}
+schema "public" {
+}
+`, string(buf))
+
+	var got schema.Realm
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	changes, err := DefaultDiff.RealmDiff(public.Realm, &got)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	// Explicitly logged tables.
+	got = schema.Realm{}
+	require.NoError(t, EvalHCLBytes([]byte(`
+table "staging" {
+  schema   = schema.public
+  unlogged = false
+  column "id" {
+    type = int
+  }
+}
+schema "public" {
+}
+`), &got, nil))
+	require.Empty(t, got.Schemas[0].Tables[0].Attrs)
+}
+
+func TestMarshalSpec_Tablespace(t *testing.T) {
+	var (
+		public = schema.New("public")
+		events = schema.NewTable("events").AddColumns(schema.NewIntColumn("id", TypeInt)).AddAttrs(&Tablespace{V: "fast"})
+	)
+	public.AddTables(events)
+	events.SetPrimaryKey(schema.NewPrimaryKey(events.Columns[0]).AddAttrs(&Tablespace{V: "fast_idx"}))
+	events.AddIndexes(schema.NewIndex("events_id").AddColumns(events.Columns[0]).AddAttrs(&Tablespace{V: "slow_idx"}))
+	schema.NewRealm(public)
+	buf, err := MarshalHCL(public)
+	require.NoError(t, err)
+	require.Equal(t, `table "events" {
+  schema     = schema.public
+  tablespace = "fast"
+  column "id" {
+    null = false
+    type = int
+  }
+  primary_key {
+    columns    = [column.id]
+    tablespace = "fast_idx"
+  }
+  index "events_id" {
+    columns    = [column.id]
+    tablespace = "slow_idx"
+  }
+}
+schema "public" {
+}
+`, string(buf))
+
+	var got schema.Realm
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	changes, err := DefaultDiff.RealmDiff(public.Realm, &got)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestMarshalSpec_OnDeleteColumns(t *testing.T) {
+	var (
+		public = schema.New("public")
+		users  = schema.NewTable("users").AddColumns(schema.NewIntColumn("tenant_id", TypeInt), schema.NewIntColumn("id", TypeInt))
+		posts  = schema.NewTable("posts").AddColumns(schema.NewIntColumn("tenant_id", TypeInt), schema.NewNullIntColumn("author_id", TypeInt))
+	)
+	public.AddTables(posts, users)
+	schema.NewRealm(public)
+	posts.AddForeignKeys(
+		schema.NewForeignKey("author").
+			AddColumns(posts.Columns...).
+			SetRefTable(users).
+			AddRefColumns(users.Columns...).
+			SetOnDelete(schema.SetNull).
+			AddAttrs(&OnDeleteColumns{Columns: posts.Columns[1:]}),
+	)
+	buf, err := MarshalHCL(public)
+	require.NoError(t, err)
+	require.Equal(t, `table "posts" {
+  schema = schema.public
+  column "tenant_id" {
+    null = false
+    type = int
+  }
+  column "author_id" {
+    null = true
+    type = int
+  }
+  foreign_key "author" {
+    columns           = [column.tenant_id, column.author_id]
+    ref_columns       = [table.users.column.tenant_id, table.users.column.id]
+    on_delete         = SET_NULL
+    on_delete_columns = [column.author_id]
+  }
+}
+table "users" {
+  schema = schema.public
+  column "tenant_id" {
+    null = false
+    type = int
+  }
+  column "id" {
+    null = false
+    type = int
+  }
+}
+schema "public" {
+}
+`, string(buf))
+
+	var got schema.Realm
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	fk := got.Schemas[0].Tables[0].ForeignKeys[0]
+	require.Equal(t, []schema.Attr{&OnDeleteColumns{Columns: got.Schemas[0].Tables[0].Columns[1:]}}, fk.Attrs)
+	changes, err := DefaultDiff.RealmDiff(public.Realm, &got)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	// Column lists are allowed only for SET NULL and SET DEFAULT actions.
+	err = EvalHCLBytes([]byte(`
+schema "public" {}
+table "posts" {
+  schema = schema.public
+  column "author_id" {
+    type = int
+  }
+  foreign_key "author" {
+    columns           = [column.author_id]
+    ref_columns       = [column.author_id]
+    on_delete         = CASCADE
+    on_delete_columns = [column.author_id]
+  }
+}
+`), &got, nil)
+	require.EqualError(t, err, `specutil: failed converting to *schema.Realm: posts.foreign_key "author": on_delete_columns requires the SET_NULL or SET_DEFAULT action`)
+}
+
+func TestMarshalSpec_Aggregates(t *testing.T) {
+	var (
+		s     = schema.New("public")
+		float = &schema.FloatType{T: TypeDouble}
+		zero  = "0"
+		sumsq = &Aggregate{
+			Name:      "sumsq",
+			Schema:    s,
+			Args:      []*schema.FuncArg{{Name: "v", Type: float}},
+			StateType: float,
+			StateFunc: "public.sumsq_sfunc",
+			FinalFunc: "sqrt",
+			Parallel:  ParallelSafe,
+			Attrs:     []schema.Attr{&schema.Comment{Text: "sum of squares"}},
+		}
+		rows = &Aggregate{Name: "rows", Schema: s, StateType: &schema.IntegerType{T: TypeBigInt}, StateFunc: "int8inc", InitCond: &zero}
+	)
+	s.AddObjects(sumsq, rows)
+	buf, err := MarshalHCL(s)
+	require.NoError(t, err)
+	require.Equal(t, `aggregate "sumsq" {
+  schema     = schema.public
+  state_type = double_precision
+  state_func = "public.sumsq_sfunc"
+  final_func = "sqrt"
+  parallel   = SAFE
+  comment    = "sum of squares"
+  arg "v" {
+    type = double_precision
+  }
+}
+aggregate "rows" {
+  schema            = schema.public
+  state_type        = bigint
+  state_func        = "int8inc"
+  initial_condition = "0"
+}
+schema "public" {
+}
+`, string(buf))
+
+	var got schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	for _, a1 := range []*Aggregate{sumsq, rows} {
+		a2, ok := findAggregate(&got, a1)
+		require.True(t, ok)
+		require.True(t, aggregateEqual(a1, a2))
+		require.Equal(t, aggregateComment(a1), aggregateComment(a2))
+	}
+
+	err = EvalHCLBytes([]byte(`
+schema "public" {}
+aggregate "rows" {
+  schema     = schema.public
+  state_type = bigint
+}
+`), &got, nil)
+	require.EqualError(t, err, `missing state_func for aggregate "rows"`)
+}
+
+func TestMarshalSpec_NotValidFK(t *testing.T) {
+	var (
+		public = schema.New("public")
+		users  = schema.NewTable("users").AddColumns(schema.NewIntColumn("id", TypeInt))
+		posts  = schema.NewTable("posts").AddColumns(schema.NewIntColumn("author_id", TypeInt))
+	)
+	public.AddTables(posts, users)
+	schema.NewRealm(public)
+	posts.AddForeignKeys(
+		schema.NewForeignKey("author").
+			AddColumns(posts.Columns...).
+			SetRefTable(users).
+			AddRefColumns(users.Columns...).
+			AddAttrs(&NotValid{}),
+	)
+	buf, err := MarshalHCL(public)
+	require.NoError(t, err)
+	require.Contains(t, string(buf), `  foreign_key "author" {
+    columns     = [column.author_id]
+    ref_columns = [table.users.column.id]
+    not_valid   = true
+  }`)
+
+	var got schema.Realm
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	fk := got.Schemas[0].Tables[0].ForeignKeys[0]
+	require.Equal(t, []schema.Attr{&NotValid{}}, fk.Attrs)
+	// Keys that were not validated yet are kept as-is.
+	changes, err := DefaultDiff.RealmDiff(public.Realm, &got)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	// Removing the attribute validates the key.
+	fk.Attrs = nil
+	changes, err = DefaultDiff.RealmDiff(public.Realm, &got)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, `ALTER TABLE "public"."posts" VALIDATE CONSTRAINT "author"`, plan.Changes[0].Cmd)
+}
@@ -0,0 +1,302 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema
+
+import (
+	"reflect"
+	"slices"
+)
+
+// CopySchemas returns copies of the given schemas and their resources (tables, views,
+// columns, indexes, objects, etc.), with the references between them pointing to the
+// copied resources. Schema objects (e.g., enums or sequences) are copied as well, and
+// their schema reference and the column and argument types that use them are pointing
+// to the copies. References to resources that reside in schemas that were not given
+// (e.g., a foreign key to an external table) are kept as-is. Note, attributes, other
+// types and expressions are not copied, and are expected to be treated as immutable.
+func CopySchemas(schemas ...*Schema) []*Schema {
+	var (
+		copied  = make([]*Schema, 0, len(schemas))
+		objects = make(map[any]any)
+	)
+	// Schema objects are copied first, as they can be used by
+	// the tables and functions of all schemas (e.g., an enum type).
+	for _, s := range schemas {
+		sc := &Schema{
+			Name:    s.Name,
+			Attrs:   slices.Clone(s.Attrs),
+			Objects: make([]Object, 0, len(s.Objects)),
+			Tables:  make([]*Table, 0, len(s.Tables)),
+			Views:   make([]*View, 0, len(s.Views)),
+			Funcs:   make([]*Func, 0, len(s.Funcs)),
+			Procs:   make([]*Proc, 0, len(s.Procs)),
+		}
+		objects[s] = sc
+		for _, o := range s.Objects {
+			oc := copyObject(o, sc)
+			objects[o] = oc
+			sc.Objects = append(sc.Objects, oc)
+		}
+		copied = append(copied, sc)
+	}
+	// Copy the resources, and fix their references after.
+	for _, s := range schemas {
+		sc := objects[s].(*Schema)
+		for _, t := range s.Tables {
+			tc := &Table{
+				Name:   t.Name,
+				Schema: sc,
+				Attrs:  slices.Clone(t.Attrs),
+				Deps:   slices.Clone(t.Deps),
+				Refs:   slices.Clone(t.Refs),
+			}
+			objects[t] = tc
+			tc.Columns = copyColumns(t.Columns, objects)
+			sc.Tables = append(sc.Tables, tc)
+		}
+		for _, v := range s.Views {
+			vc := &View{
+				Name:   v.Name,
+				Def:    v.Def,
+				Schema: sc,
+				Attrs:  slices.Clone(v.Attrs),
+				Deps:   slices.Clone(v.Deps),
+				Refs:   slices.Clone(v.Refs),
+			}
+			objects[v] = vc
+			vc.Columns = copyColumns(v.Columns, objects)
+			sc.Views = append(sc.Views, vc)
+		}
+		for _, f := range s.Funcs {
+			fc := &Func{
+				Name:   f.Name,
+				Schema: sc,
+				Args:   copyArgs(f.Args, objects),
+				Ret:    copyType(f.Ret, objects),
+				Body:   f.Body,
+				Lang:   f.Lang,
+				Attrs:  slices.Clone(f.Attrs),
+				Deps:   slices.Clone(f.Deps),
+				Refs:   slices.Clone(f.Refs),
+			}
+			objects[f] = fc
+			sc.Funcs = append(sc.Funcs, fc)
+		}
+		for _, p := range s.Procs {
+			pc := &Proc{
+				Name:   p.Name,
+				Schema: sc,
+				Args:   copyArgs(p.Args, objects),
+				Body:   p.Body,
+				Lang:   p.Lang,
+				Attrs:  slices.Clone(p.Attrs),
+				Deps:   slices.Clone(p.Deps),
+				Refs:   slices.Clone(p.Refs),
+			}
+			objects[p] = pc
+			sc.Procs = append(sc.Procs, pc)
+		}
+	}
+	for _, s := range schemas {
+		for _, t := range s.Tables {
+			tc := objects[t].(*Table)
+			tc.Indexes = copyIndexes(t.Indexes, objects)
+			if t.PrimaryKey != nil {
+				tc.PrimaryKey = copyIndexes([]*Index{t.PrimaryKey}, objects)[0]
+			}
+			for _, fk := range t.ForeignKeys {
+				fkc := &ForeignKey{
+					Symbol:     fk.Symbol,
+					Table:      tc,
+					Columns:    copyRefs(fk.Columns, objects),
+					RefTable:   copyRef(fk.RefTable, objects),
+					RefColumns: copyRefs(fk.RefColumns, objects),
+					OnUpdate:   fk.OnUpdate,
+					OnDelete:   fk.OnDelete,
+					Attrs:      slices.Clone(fk.Attrs),
+				}
+				for _, c := range fkc.Columns {
+					c.ForeignKeys = append(c.ForeignKeys, fkc)
+				}
+				tc.ForeignKeys = append(tc.ForeignKeys, fkc)
+			}
+			tc.Triggers = copyTriggers(t.Triggers, objects)
+		}
+		for _, v := range s.Views {
+			vc := objects[v].(*View)
+			vc.Indexes = copyIndexes(v.Indexes, objects)
+			vc.Triggers = copyTriggers(v.Triggers, objects)
+		}
+	}
+	// Dependencies are fixed last, as they can reference any copied object.
+	for _, o := range objects {
+		switch o := o.(type) {
+		case *Table:
+			copyDeps(o.Deps, objects)
+			copyDeps(o.Refs, objects)
+		case *View:
+			copyDeps(o.Deps, objects)
+			copyDeps(o.Refs, objects)
+		case *Func:
+			copyDeps(o.Deps, objects)
+			copyDeps(o.Refs, objects)
+		case *Proc:
+			copyDeps(o.Deps, objects)
+			copyDeps(o.Refs, objects)
+		case *Trigger:
+			copyDeps(o.Deps, objects)
+			copyDeps(o.Refs, objects)
+		}
+	}
+	return copied
+}
+
+// copyColumns copies the given columns, and records them in the objects map.
+func copyColumns(columns []*Column, objects map[any]any) []*Column {
+	copied := make([]*Column, 0, len(columns))
+	for _, c := range columns {
+		cc := &Column{
+			Name:    c.Name,
+			Default: c.Default,
+			Attrs:   slices.Clone(c.Attrs),
+		}
+		if c.Type != nil {
+			ct := *c.Type
+			ct.Type = copyType(ct.Type, objects)
+			cc.Type = &ct
+		}
+		objects[c] = cc
+		copied = append(copied, cc)
+	}
+	return copied
+}
+
+// copyIndexes copies the given indexes and links them to their copied columns.
+func copyIndexes(indexes []*Index, objects map[any]any) []*Index {
+	copied := make([]*Index, 0, len(indexes))
+	for _, idx := range indexes {
+		if ic, ok := objects[idx].(*Index); ok {
+			copied = append(copied, ic)
+			continue
+		}
+		ic := &Index{
+			Name:   idx.Name,
+			Unique: idx.Unique,
+			Table:  copyRef(idx.Table, objects),
+			View:   copyRef(idx.View, objects),
+			Attrs:  slices.Clone(idx.Attrs),
+			Parts:  make([]*IndexPart, 0, len(idx.Parts)),
+		}
+		for _, p := range idx.Parts {
+			pc := &IndexPart{SeqNo: p.SeqNo, Desc: p.Desc, X: p.X, C: copyRef(p.C, objects), Attrs: slices.Clone(p.Attrs)}
+			if pc.C != nil {
+				pc.C.Indexes = append(pc.C.Indexes, ic)
+			}
+			ic.Parts = append(ic.Parts, pc)
+		}
+		objects[idx] = ic
+		copied = append(copied, ic)
+	}
+	return copied
+}
+
+// copyTriggers copies the given triggers and links them to their copied owners.
+func copyTriggers(triggers []*Trigger, objects map[any]any) []*Trigger {
+	copied := make([]*Trigger, 0, len(triggers))
+	for _, t := range triggers {
+		tc := &Trigger{
+			Name:       t.Name,
+			Table:      copyRef(t.Table, objects),
+			View:       copyRef(t.View, objects),
+			ActionTime: t.ActionTime,
+			For:        t.For,
+			Body:       t.Body,
+			Attrs:      slices.Clone(t.Attrs),
+			Deps:       slices.Clone(t.Deps),
+			Refs:       slices.Clone(t.Refs),
+		}
+		for _, e := range t.Events {
+			tc.Events = append(tc.Events, TriggerEvent{Name: e.Name, Columns: copyRefs(e.Columns, objects)})
+		}
+		objects[t] = tc
+		copied = append(copied, tc)
+	}
+	return copied
+}
+
+// copyArgs copies the given function arguments.
+func copyArgs(args []*FuncArg, objects map[any]any) []*FuncArg {
+	copied := make([]*FuncArg, 0, len(args))
+	for _, a := range args {
+		ac := *a
+		ac.Type = copyType(a.Type, objects)
+		ac.Attrs = slices.Clone(a.Attrs)
+		copied = append(copied, &ac)
+	}
+	return copied
+}
+
+// copyObject returns a copy of the given schema object. Objects are copied
+// shallowly, and their Schema field (if exists) is set to the given schema.
+func copyObject(o Object, s *Schema) Object {
+	if e, ok := o.(*EnumType); ok {
+		return &EnumType{T: e.T, Values: slices.Clone(e.Values), Schema: s, Attrs: slices.Clone(e.Attrs)}
+	}
+	v := reflect.ValueOf(o)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return o
+	}
+	c := reflect.New(v.Elem().Type())
+	c.Elem().Set(v.Elem())
+	if f := c.Elem().FieldByName("Schema"); f.IsValid() && f.CanSet() && f.Type() == reflect.TypeOf(s) {
+		f.Set(reflect.ValueOf(s))
+	}
+	oc, ok := c.Interface().(Object)
+	if !ok {
+		return o
+	}
+	return oc
+}
+
+// copyType returns the copy of the given type, if it is a copied
+// schema object (e.g., an enum type), or the type itself otherwise.
+func copyType(t Type, objects map[any]any) Type {
+	// Only pointers can be copied objects, and other
+	// types might not be valid map keys (e.g., slices).
+	if reflect.ValueOf(t).Kind() != reflect.Ptr {
+		return t
+	}
+	if c, ok := objects[t].(Type); ok {
+		return c
+	}
+	return t
+}
+
+// copyRef returns the copy of the given resource, or the resource itself
+// if it was not copied. e.g., a table that resides in an external schema.
+func copyRef[T any](r *T, objects map[any]any) *T {
+	if c, ok := objects[r].(*T); ok {
+		return c
+	}
+	return r
+}
+
+// copyRefs returns the copies of the given resources. See copyRef.
+func copyRefs[T any](rs []*T, objects map[any]any) []*T {
+	copied := make([]*T, 0, len(rs))
+	for _, r := range rs {
+		copied = append(copied, copyRef(r, objects))
+	}
+	return copied
+}
+
+// copyDeps replaces the copied objects in the given dependencies with their copies.
+func copyDeps(deps []Object, objects map[any]any) {
+	for i, d := range deps {
+		if c, ok := objects[d].(Object); ok {
+			deps[i] = c
+		}
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema_test
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+// sequence is a driver-specific object that holds a schema reference.
+type sequence struct {
+	schema.Object
+	Name   string
+	Schema *schema.Schema
+}
+
+func TestCopySchemas_Objects(t *testing.T) {
+	var (
+		s      = schema.New("public")
+		status = &schema.EnumType{T: "status", Values: []string{"active"}, Schema: s}
+		seq    = &sequence{Name: "ids", Schema: s}
+		users  = schema.NewTable("users").AddColumns(schema.NewEnumColumn("status", schema.EnumName(status.T), schema.EnumValues(status.Values...)))
+		fn     = &schema.Func{Name: "activate", Args: []*schema.FuncArg{{Name: "s", Type: status}}, Ret: status}
+	)
+	users.Columns[0].Type.Type = status
+	s.AddObjects(status, seq).AddTables(users).AddFuncs(fn)
+
+	copied := schema.CopySchemas(s)
+	require.Len(t, copied, 1)
+	// Rename the copied schema, as done on
+	// normalization with temporary names.
+	copied[0].Name = "tenant"
+	require.Len(t, copied[0].Objects, 2)
+
+	e, ok := copied[0].Objects[0].(*schema.EnumType)
+	require.True(t, ok)
+	require.NotSame(t, status, e)
+	require.Same(t, copied[0], e.Schema)
+	require.Equal(t, "tenant", e.Schema.Name)
+	require.Equal(t, status.Values, e.Values)
+	require.Same(t, e, copied[0].Tables[0].Columns[0].Type.Type, "column type points to the copied enum")
+	require.Same(t, e, copied[0].Funcs[0].Args[0].Type)
+	require.Same(t, e, copied[0].Funcs[0].Ret)
+
+	sc, ok := copied[0].Objects[1].(*sequence)
+	require.True(t, ok)
+	require.NotSame(t, seq, sc)
+	require.Equal(t, "ids", sc.Name)
+	require.Same(t, copied[0], sc.Schema)
+
+	// The original schema is not modified.
+	require.Equal(t, "public", s.Name)
+	require.Same(t, s, status.Schema)
+	require.Same(t, s, seq.Schema)
+	require.Same(t, status, users.Columns[0].Type.Type)
+}
@@ -51,6 +51,13 @@ func (s *Schema) SetComment(v string) *Schema {
 	return s
 }
 
+// SetLabels sets the labels of the schema. Labels are
+// stored in its comment. See EncodeLabels for details.
+func (s *Schema) SetLabels(labels map[string]string) *Schema {
+	SetLabels(&s.Attrs, labels)
+	return s
+}
+
 // AddAttrs adds additional attributes to the schema.
 func (s *Schema) AddAttrs(attrs ...Attr) *Schema {
 	s.Attrs = append(s.Attrs, attrs...)
@@ -161,6 +168,13 @@ func (r *Realm) UnsetCollation() *Realm {
 	return r
 }
 
+// SetServerInfo sets or appends the ServerInfo attribute
+// to the realm with the given value.
+func (r *Realm) SetServerInfo(v *ServerInfo) *Realm {
+	ReplaceOrAppend(&r.Attrs, v)
+	return r
+}
+
 // NewTable creates a new Table.
 func NewTable(name string) *Table {
 	return &Table{Name: name}
@@ -199,6 +213,13 @@ func (t *Table) SetComment(v string) *Table {
 	return t
 }
 
+// SetLabels sets the labels of the table. Labels are
+// stored in its comment. See EncodeLabels for details.
+func (t *Table) SetLabels(labels map[string]string) *Table {
+	SetLabels(&t.Attrs, labels)
+	return t
+}
+
 // AddChecks appends the given checks to the attribute list.
 func (t *Table) AddChecks(checks ...*Check) *Table {
 	for _, c := range checks {
@@ -366,6 +387,13 @@ func (v *View) SetComment(c string) *View {
 	return v
 }
 
+// SetLabels sets the labels of the view. Labels are
+// stored in its comment. See EncodeLabels for details.
+func (v *View) SetLabels(labels map[string]string) *View {
+	SetLabels(&v.Attrs, labels)
+	return v
+}
+
 // AddAttrs adds and additional attributes to the view.
 func (v *View) AddAttrs(attrs ...Attr) *View {
 	v.Attrs = append(v.Attrs, attrs...)
@@ -733,6 +761,13 @@ func (c *Column) SetComment(v string) *Column {
 	return c
 }
 
+// SetLabels sets the labels of the column. Labels are
+// stored in its comment. See EncodeLabels for details.
+func (c *Column) SetLabels(labels map[string]string) *Column {
+	SetLabels(&c.Attrs, labels)
+	return c
+}
+
 // SetGeneratedExpr sets or appends the GeneratedExpr attribute.
 func (c *Column) SetGeneratedExpr(x *GeneratedExpr) *Column {
 	ReplaceOrAppend(&c.Attrs, x)
@@ -8,6 +8,10 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"slices"
+
+	"ariga.io/atlas/sql/sqlerr"
 )
 
 // A NotExistError wraps another error to retain its original text
@@ -18,6 +22,9 @@ type NotExistError struct {
 
 func (e NotExistError) Error() string { return e.Err.Error() }
 
+// Is reports if the target is the sqlerr.NotExist kind.
+func (e NotExistError) Is(target error) bool { return target == sqlerr.NotExist }
+
 // IsNotExistError reports if an error is a NotExistError.
 func IsNotExistError(err error) bool {
 	if err == nil {
@@ -64,6 +71,20 @@ const (
 // Is reports whether the given mode is enabled.
 func (m InspectMode) Is(i InspectMode) bool { return m&i != 0 }
 
+// An InspectErrorPolicy controls how permission errors are handled on inspection.
+type InspectErrorPolicy uint
+
+const (
+	// InspectErrorFail fails the inspection on permission errors. This is the default policy.
+	InspectErrorFail InspectErrorPolicy = iota
+
+	// InspectErrorPartial skips the objects that cannot be inspected due to permission errors,
+	// and returns the partial results with an InspectWarnings attribute listing the skipped
+	// objects. In realm scope, schemas are skipped. In schema scope, the tables listed in the
+	// inspect options are skipped, as there is no way to list the tables of inaccessible schemas.
+	InspectErrorPartial
+)
+
 type (
 	// InspectOptions describes options for Inspector.
 	InspectOptions struct {
@@ -100,6 +121,18 @@ type (
 		//	*.* // the last item defines the filtering; all resourced under all tables are excluded.
 		//
 		Exclude []string
+
+		// ExcludeDefaults indicates if the bookkeeping tables of common migration
+		// tools should be excluded from inspection. See DefaultExclude.
+		ExcludeDefaults bool
+
+		// DefaultExclude lists the table names that are excluded when ExcludeDefaults
+		// is set. If nil, the tables returned by BookkeepingTables are used.
+		DefaultExclude []string
+
+		// OnPermissionError defines the policy for objects that cannot be
+		// inspected due to missing privileges. See InspectErrorPolicy.
+		OnPermissionError InspectErrorPolicy
 	}
 
 	// InspectRealmOption describes options for RealmInspector.
@@ -141,6 +174,18 @@ type (
 		//	*.*.* // the last item defines the filtering; all resources are excluded in all tables.
 		//
 		Exclude []string
+
+		// ExcludeDefaults indicates if the bookkeeping tables of common migration
+		// tools should be excluded from inspection. See DefaultExclude.
+		ExcludeDefaults bool
+
+		// DefaultExclude lists the table names that are excluded when ExcludeDefaults
+		// is set. If nil, the tables returned by BookkeepingTables are used.
+		DefaultExclude []string
+
+		// OnPermissionError defines the policy for objects that cannot be
+		// inspected due to missing privileges. See InspectErrorPolicy.
+		OnPermissionError InspectErrorPolicy
 	}
 
 	// Inspector is the interface implemented by the different database
@@ -156,6 +201,95 @@ type (
 	}
 )
 
+type (
+	// InspectWarnings is an attribute that is attached to realms and schemas that were
+	// partially inspected, and lists the objects that were skipped. See InspectErrorPartial.
+	InspectWarnings struct {
+		Skipped []*SkippedObject
+	}
+
+	// SkippedObject describes an object that was skipped on inspection.
+	SkippedObject struct {
+		Type string // Object type. e.g., "schema" or "table".
+		Name string // Object name. Tables are qualified with their schema name, if known.
+		Err  error  // The error returned on inspection.
+	}
+)
+
+func (*InspectWarnings) attr() {}
+
+// String returns the description of the skipped object.
+func (o *SkippedObject) String() string {
+	return fmt.Sprintf("%s %q was skipped: %v", o.Type, o.Name, o.Err)
+}
+
+// BookkeepingTables returns the bookkeeping tables of common migration tools and frameworks
+// that are excluded from inspection when ExcludeDefaults is set, unless the DefaultExclude
+// option is provided. Applications can extend the returned slice and use it as the option.
+func BookkeepingTables() []string {
+	return slices.Clone(bookkeepingTables)
+}
+
+// revisionsTable is the default name of the Atlas revisions
+// table, and the schema that holds it in realm scope.
+const revisionsTable = "atlas_schema_revisions"
+
+var bookkeepingTables = []string{
+	revisionsTable,          // Atlas.
+	"django_migrations",     // Django.
+	"flyway_schema_history", // Flyway.
+	"databasechangelog",     // Liquibase.
+	"databasechangeloglock", // Liquibase.
+	"schema_migrations",     // Rails, golang-migrate, dbmate.
+	"ar_internal_metadata",  // Rails.
+	"goose_db_version",      // Goose.
+	"alembic_version",       // Alembic.
+	"knex_migrations",       // Knex.
+	"knex_migrations_lock",  // Knex.
+	"__EFMigrationsHistory", // Entity Framework.
+	"SequelizeMeta",         // Sequelize.
+}
+
+// Excluded returns the exclusion patterns of the inspection, including
+// the default ones in case ExcludeDefaults is set.
+func (o *InspectOptions) Excluded() []string {
+	if o == nil {
+		return nil
+	}
+	if !o.ExcludeDefaults {
+		return o.Exclude
+	}
+	return append(slices.Clone(o.Exclude), defaultExclude(o.DefaultExclude)...)
+}
+
+// Excluded returns the exclusion patterns of the inspection, including
+// the default ones in case ExcludeDefaults is set. Note that in realm
+// scope, the Atlas revisions entry excludes the revisions schema as well.
+func (o *InspectRealmOption) Excluded() []string {
+	if o == nil {
+		return nil
+	}
+	if !o.ExcludeDefaults {
+		return o.Exclude
+	}
+	excluded := slices.Clone(o.Exclude)
+	for _, t := range defaultExclude(o.DefaultExclude) {
+		excluded = append(excluded, "*."+t)
+		if t == revisionsTable {
+			excluded = append(excluded, t)
+		}
+	}
+	return excluded
+}
+
+// defaultExclude returns the given tables, or the bookkeeping tables if nil.
+func defaultExclude(tables []string) []string {
+	if tables == nil {
+		return bookkeepingTables
+	}
+	return tables
+}
+
 // Normalizer is the interface implemented by the different database drivers for
 // "normalizing" schema objects. i.e. converting schema objects defined in natural
 // form to their representation in the database. Thus, two schema objects are equal
@@ -167,3 +301,12 @@ type Normalizer interface {
 	// NormalizeRealm returns the normal representation of a database.
 	NormalizeRealm(context.Context, *Realm) (*Realm, error)
 }
+
+// SchemasNormalizer is an optional interface implemented by drivers that can normalize
+// multiple schemas in a single round on the dev database, including schemas with the
+// same name (e.g., tenants that are defined by different specs).
+type SchemasNormalizer interface {
+	// NormalizeSchemas returns the normal representation of the given
+	// schemas, in their order. The given schemas are not modified.
+	NormalizeSchemas(context.Context, ...*Schema) ([]*Schema, error)
+}
@@ -0,0 +1,85 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema_test
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspectOptions_Excluded(t *testing.T) {
+	var opts *schema.InspectOptions
+	require.Nil(t, opts.Excluded())
+	opts = &schema.InspectOptions{Exclude: []string{"t"}}
+	require.Equal(t, []string{"t"}, opts.Excluded())
+	opts.ExcludeDefaults = true
+	require.Equal(t, append([]string{"t"}, schema.BookkeepingTables()...), opts.Excluded())
+	require.Equal(t, []string{"t"}, opts.Exclude)
+	// The default exclusion is configured per inspection.
+	opts.DefaultExclude = append(schema.BookkeepingTables(), "migrations")
+	require.Equal(t, append([]string{"t"}, opts.DefaultExclude...), opts.Excluded())
+	tables := schema.BookkeepingTables()
+	tables[0] = "users"
+	require.NotEqual(t, "users", schema.BookkeepingTables()[0], "tables are returned as a copy")
+	opts.DefaultExclude = []string{}
+	require.Equal(t, []string{"t"}, opts.Excluded())
+	opts.DefaultExclude = nil
+
+	r := schema.NewRealm(
+		schema.New("public").AddTables(
+			schema.NewTable("users"),
+			schema.NewTable("django_migrations"),
+			schema.NewTable("flyway_schema_history"),
+		),
+	)
+	s, err := schema.ExcludeSchema(r.Schemas[0], opts.Excluded())
+	require.NoError(t, err)
+	require.Len(t, s.Tables, 1)
+	require.Equal(t, "users", s.Tables[0].Name)
+}
+
+func TestInspectRealmOption_Excluded(t *testing.T) {
+	opts := &schema.InspectRealmOption{ExcludeDefaults: true}
+	r := schema.NewRealm(
+		schema.New("public").AddTables(
+			schema.NewTable("users"),
+			schema.NewTable("goose_db_version"),
+		),
+		schema.New("atlas_schema_revisions").AddTables(
+			schema.NewTable("atlas_schema_revisions"),
+		),
+	)
+	r, err := schema.ExcludeRealm(r, opts.Excluded())
+	require.NoError(t, err)
+	require.Len(t, r.Schemas, 1)
+	require.Len(t, r.Schemas[0].Tables, 1)
+	require.Equal(t, "users", r.Schemas[0].Tables[0].Name)
+
+	opts.DefaultExclude = []string{"migrations"}
+	require.Equal(t, []string{"*.migrations"}, opts.Excluded())
+
+	// An empty default list inspects the revisions schema and table.
+	opts.DefaultExclude = []string{}
+	require.Empty(t, opts.Excluded())
+	r = schema.NewRealm(
+		schema.New("public").AddTables(
+			schema.NewTable("users"),
+			schema.NewTable("atlas_schema_revisions"),
+		),
+		schema.New("atlas_schema_revisions").AddTables(
+			schema.NewTable("atlas_schema_revisions"),
+		),
+	)
+	r, err = schema.ExcludeRealm(r, opts.Excluded())
+	require.NoError(t, err)
+	require.Len(t, r.Schemas, 2)
+	require.Len(t, r.Schemas[0].Tables, 2)
+	require.Equal(t, "atlas_schema_revisions", r.Schemas[0].Tables[1].Name)
+	require.Equal(t, "atlas_schema_revisions", r.Schemas[1].Name)
+	require.Len(t, r.Schemas[1].Tables, 1)
+}
@@ -0,0 +1,202 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// labelsPrefix marks the trailer line of a comment that holds the labels of its object.
+const labelsPrefix = "@labels "
+
+// EncodeLabels returns the comment text of an object that carries the given labels.
+// Labels are key/value tags (e.g., team=payments or tier=critical) stored as a JSON
+// trailer line of the object comment, which allows them to survive the inspect/plan
+// cycles of every database that supports comments. For example:
+//
+//	Payment transactions.
+//	@labels {"team":"payments","tier":"critical"}
+//
+// Existing labels in the text are replaced, and empty labels remove the trailer.
+func EncodeLabels(text string, labels map[string]string) string {
+	text, _ = DecodeLabels(text)
+	if len(labels) == 0 {
+		return text
+	}
+	b, err := json.Marshal(labels) // Keys are sorted.
+	if err != nil {
+		return text
+	}
+	if text == "" {
+		return labelsPrefix + string(b)
+	}
+	return text + "\n" + labelsPrefix + string(b)
+}
+
+// DecodeLabels splits the given comment into its text and the labels encoded in it.
+// A comment without a valid labels trailer is returned as is, with nil labels.
+func DecodeLabels(comment string) (string, map[string]string) {
+	i := strings.LastIndex(comment, labelsPrefix)
+	if i == -1 || i > 0 && comment[i-1] != '\n' {
+		return comment, nil
+	}
+	var labels map[string]string
+	if err := json.Unmarshal([]byte(comment[i+len(labelsPrefix):]), &labels); err != nil {
+		return comment, nil
+	}
+	return strings.TrimSuffix(comment[:i], "\n"), labels
+}
+
+// LabelsOf returns the labels stored in the comment of the given attributes, if exists.
+func LabelsOf(attrs []Attr) map[string]string {
+	_, labels := DecodeLabels(commentOf(attrs))
+	return labels
+}
+
+// SetLabels sets the labels of the given attributes. The Comment attribute is
+// created if it does not exist, and removed if it holds only the labels and
+// the given labels are empty.
+func SetLabels(attrs *[]Attr, labels map[string]string) {
+	if text := EncodeLabels(commentOf(*attrs), labels); text != "" {
+		ReplaceOrAppend(attrs, &Comment{Text: text})
+	} else {
+		*attrs = RemoveAttr[*Comment](*attrs)
+	}
+}
+
+// MatchLabels reports if the given labels contain all key/value pairs of the selector.
+// A selector value of "*" matches all values of its key, as long as the key exists.
+func MatchLabels(labels, selector map[string]string) bool {
+	for k, v := range selector {
+		l, ok := labels[k]
+		if !ok || v != "*" && l != v {
+			return false
+		}
+	}
+	return true
+}
+
+// ChangeLabels returns the labels of the element that is affected by the given change.
+// For modified and renamed elements, the labels of the desired element are returned.
+// Attribute changes (e.g., comments) have no element of their own and return nil.
+func ChangeLabels(c Change) map[string]string {
+	var attrs []Attr
+	switch c := c.(type) {
+	case *AddSchema:
+		attrs = c.S.Attrs
+	case *DropSchema:
+		attrs = c.S.Attrs
+	case *ModifySchema:
+		attrs = c.S.Attrs
+	case *AddTable:
+		attrs = c.T.Attrs
+	case *CloneTable:
+		attrs = c.T.Attrs
+	case *DropTable:
+		attrs = c.T.Attrs
+	case *ModifyTable:
+		attrs = c.T.Attrs
+	case *RenameTable:
+		attrs = c.To.Attrs
+	case *AddView:
+		attrs = c.V.Attrs
+	case *DropView:
+		attrs = c.V.Attrs
+	case *ModifyView:
+		attrs = c.To.Attrs
+	case *RenameView:
+		attrs = c.To.Attrs
+	case *AddFunc:
+		attrs = c.F.Attrs
+	case *DropFunc:
+		attrs = c.F.Attrs
+	case *ModifyFunc:
+		attrs = c.To.Attrs
+	case *RenameFunc:
+		attrs = c.To.Attrs
+	case *AddProc:
+		attrs = c.P.Attrs
+	case *DropProc:
+		attrs = c.P.Attrs
+	case *ModifyProc:
+		attrs = c.To.Attrs
+	case *RenameProc:
+		attrs = c.To.Attrs
+	case *AddTrigger:
+		attrs = c.T.Attrs
+	case *DropTrigger:
+		attrs = c.T.Attrs
+	case *ModifyTrigger:
+		attrs = c.To.Attrs
+	case *RenameTrigger:
+		attrs = c.To.Attrs
+	case *AddObject:
+		attrs = objectAttrs(c.O)
+	case *DropObject:
+		attrs = objectAttrs(c.O)
+	case *ModifyObject:
+		attrs = objectAttrs(c.To)
+	case *RenameObject:
+		attrs = objectAttrs(c.To)
+	case *AddColumn:
+		attrs = c.C.Attrs
+	case *DropColumn:
+		attrs = c.C.Attrs
+	case *ModifyColumn:
+		attrs = c.To.Attrs
+	case *RenameColumn:
+		attrs = c.To.Attrs
+	case *AddIndex:
+		attrs = c.I.Attrs
+	case *DropIndex:
+		attrs = c.I.Attrs
+	case *ModifyIndex:
+		attrs = c.To.Attrs
+	case *RenameIndex:
+		attrs = c.To.Attrs
+	case *AddForeignKey:
+		attrs = c.F.Attrs
+	case *DropForeignKey:
+		attrs = c.F.Attrs
+	case *ModifyForeignKey:
+		attrs = c.To.Attrs
+	case *AddCheck:
+		attrs = c.C.Attrs
+	case *DropCheck:
+		attrs = c.C.Attrs
+	case *ModifyCheck:
+		attrs = c.To.Attrs
+	}
+	return LabelsOf(attrs)
+}
+
+// objectAttrs returns the attributes of a driver-specific
+// object, based on its Attrs field, if exists.
+func objectAttrs(o Object) []Attr {
+	v := reflect.ValueOf(o)
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return nil
+	}
+	if v = reflect.Indirect(v); v.Kind() != reflect.Struct {
+		return nil
+	}
+	if f := v.FieldByName("Attrs"); f.IsValid() && f.CanInterface() {
+		attrs, _ := f.Interface().([]Attr)
+		return attrs
+	}
+	return nil
+}
+
+// commentOf returns the text of the Comment attribute, if exists.
+func commentOf(attrs []Attr) string {
+	for _, a := range attrs {
+		if c, ok := a.(*Comment); ok {
+			return c.Text
+		}
+	}
+	return ""
+}
@@ -0,0 +1,71 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema_test
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabels(t *testing.T) {
+	labels := map[string]string{"tier": "critical", "team": "payments"}
+	c := schema.EncodeLabels("Payment transactions.", labels)
+	require.Equal(t, "Payment transactions.\n@labels {\"team\":\"payments\",\"tier\":\"critical\"}", c)
+	text, got := schema.DecodeLabels(c)
+	require.Equal(t, "Payment transactions.", text)
+	require.Equal(t, labels, got)
+
+	// Labels are replaced, and removed.
+	c = schema.EncodeLabels(c, map[string]string{"team": "core"})
+	require.Equal(t, "Payment transactions.\n@labels {\"team\":\"core\"}", c)
+	require.Equal(t, "Payment transactions.", schema.EncodeLabels(c, nil))
+	require.Equal(t, `@labels {"a":"b"}`, schema.EncodeLabels("", map[string]string{"a": "b"}))
+
+	// Invalid trailers are kept as text.
+	for _, c := range []string{"@labels", "a @labels {}", "@labels {invalid}", "a\n@labels [1]"} {
+		text, got := schema.DecodeLabels(c)
+		require.Equal(t, c, text)
+		require.Nil(t, got)
+	}
+
+	tbl := schema.NewTable("users").SetComment("Users.").SetLabels(labels)
+	require.Equal(t, labels, schema.LabelsOf(tbl.Attrs))
+	tbl.SetLabels(nil)
+	require.Equal(t, []schema.Attr{&schema.Comment{Text: "Users."}}, tbl.Attrs)
+	tbl = schema.NewTable("users").SetLabels(labels)
+	require.Equal(t, labels, schema.LabelsOf(tbl.Attrs))
+	tbl.SetLabels(nil)
+	require.Empty(t, tbl.Attrs)
+}
+
+func TestMatchLabels(t *testing.T) {
+	labels := map[string]string{"team": "payments", "tier": "critical"}
+	require.True(t, schema.MatchLabels(labels, nil))
+	require.True(t, schema.MatchLabels(labels, map[string]string{"team": "payments"}))
+	require.True(t, schema.MatchLabels(labels, map[string]string{"team": "payments", "tier": "*"}))
+	require.False(t, schema.MatchLabels(labels, map[string]string{"team": "core"}))
+	require.False(t, schema.MatchLabels(labels, map[string]string{"owner": "*"}))
+	require.False(t, schema.MatchLabels(nil, map[string]string{"team": "payments"}))
+}
+
+func TestChangeLabels(t *testing.T) {
+	var (
+		labels = map[string]string{"team": "payments"}
+		users  = schema.NewTable("users").SetLabels(labels)
+		email  = schema.NewStringColumn("email", "text").SetLabels(labels)
+		enum   = &schema.EnumType{T: "status", Attrs: []schema.Attr{&schema.Comment{Text: schema.EncodeLabels("", labels)}}}
+	)
+	require.Equal(t, labels, schema.ChangeLabels(&schema.AddTable{T: users}))
+	require.Equal(t, labels, schema.ChangeLabels(&schema.ModifyTable{T: users}))
+	require.Equal(t, labels, schema.ChangeLabels(&schema.ModifyColumn{From: schema.NewStringColumn("email", "text"), To: email}))
+	require.Equal(t, labels, schema.ChangeLabels(&schema.DropView{V: schema.NewView("v", "SELECT 1").SetLabels(labels)}))
+	require.Equal(t, labels, schema.ChangeLabels(&schema.AddObject{O: enum}))
+	require.Nil(t, schema.ChangeLabels(&schema.AddObject{O: &schema.EnumType{T: "status"}}))
+	require.Nil(t, schema.ChangeLabels(&schema.ModifyAttr{From: &schema.Comment{}, To: &schema.Comment{}}))
+	require.Nil(t, schema.ChangeLabels(&schema.AddColumn{C: schema.NewIntColumn("id", "int")}))
+}
@@ -9,6 +9,8 @@ import (
 	"errors"
 	"reflect"
 	"time"
+
+	"ariga.io/atlas/sql/sqlerr"
 )
 
 type (
@@ -70,6 +72,15 @@ type (
 		Extra []Clause // Extra clauses and options.
 	}
 
+	// CloneTable describes a table creation change, in which the table
+	// is created by copying the structure of the Source table without its
+	// data. e.g., CREATE TABLE ... LIKE, or CREATE TABLE ... AS with no data.
+	CloneTable struct {
+		T      *Table
+		Source *Table
+		Extra  []Clause // Extra clauses and options.
+	}
+
 	// DropTable describes a table removal change.
 	DropTable struct {
 		T     *Table
@@ -213,7 +224,8 @@ type (
 
 	// AddColumn describes a column creation change.
 	AddColumn struct {
-		C *Column
+		C     *Column
+		Extra []Clause // Extra clauses and options.
 	}
 
 	// DropColumn describes a column removal change.
@@ -335,6 +347,14 @@ type (
 	// IfNotExists represents a clause in a schema change that is commonly
 	// supported by multiple statements (e.g. CREATE TABLE or CREATE SCHEMA).
 	IfNotExists struct{}
+
+	// ColumnPosition represents a clause in a column change that describes
+	// the position of the column in its table (e.g. FIRST or AFTER in MySQL).
+	ColumnPosition struct {
+		// After is the column that precedes the column. A nil
+		// value indicates the column is placed first.
+		After *Column
+	}
 )
 
 // A ChangeKind describes a change kind that can be combined
@@ -392,6 +412,11 @@ const (
 	ChangeUpdateAction
 	// ChangeDeleteAction describes a change to the foreign-key delete action.
 	ChangeDeleteAction
+
+	// ChangePosition describes a change to the column position in its table.
+	// This change is reported only if column order comparison is enabled.
+	// See DiffColumnOrder for more details.
+	ChangePosition
 )
 
 // List of diff modes.
@@ -445,6 +470,16 @@ type (
 		// AskFunc can be implemented by the caller to
 		// make diff process interactive.
 		AskFunc func(string, []string) (string, error)
+
+		// ColumnOrder indicates if the order of table columns should be
+		// compared. ColumnOrderReport, if not nil, is called for tables
+		// whose column order cannot be changed by the driver.
+		ColumnOrder       bool
+		ColumnOrderReport func(from, to *Table)
+
+		// comparators holds the custom comparators registered
+		// by DiffAttrComparator, keyed by the compared type.
+		comparators map[reflect.Type]func(from, to any) bool
 	}
 
 	// DiffOption allows configuring the DiffOptions using functional options.
@@ -485,6 +520,65 @@ func DiffNormalized() DiffOption {
 	}
 }
 
+// DiffColumnOrder returns a DiffOption that enables the comparison of the column order
+// of tables. Drivers that support column reordering (e.g., MySQL) plan the changes using
+// the ColumnPosition clause. On other drivers, the diff does not fail, and the given
+// report function (if not nil) is called with the tables whose column order differs.
+//
+//	DiffColumnOrder(func(from, to *Table) {
+//		log.Printf("column order of table %q cannot be changed", to.Name)
+//	})
+func DiffColumnOrder(report func(from, to *Table)) DiffOption {
+	return func(o *DiffOptions) {
+		o.ColumnOrder = true
+		o.ColumnOrderReport = report
+	}
+}
+
+// DiffAttrComparator returns a DiffOption that registers a comparator for elements
+// of type T, allowing the caller to suppress changes that are considered noise. T is
+// expected to be a concrete attribute type (e.g., *Comment or *Collation) or a column
+// default expression (e.g., *RawExpr). The comparator is called with the zero value
+// of T in case the element does not exist in one of the states, and reporting true
+// means the two are equal and no change is emitted. For example:
+//
+//	DiffAttrComparator(func(_, _ *Comment) bool {
+//		return true // Ignore comment changes.
+//	})
+//
+//	DiffAttrComparator(func(from, to *RawExpr) bool {
+//		return isNow(from) && isNow(to)
+//	})
+func DiffAttrComparator[T any](eq func(from, to T) bool) DiffOption {
+	return func(o *DiffOptions) {
+		if o.comparators == nil {
+			o.comparators = make(map[reflect.Type]func(from, to any) bool)
+		}
+		o.comparators[reflect.TypeFor[T]()] = func(from, to any) bool {
+			x, _ := from.(T)
+			y, _ := to.(T)
+			return eq(x, y)
+		}
+	}
+}
+
+// AttrEqual reports whether the two elements were reported as equal by a comparator
+// registered with DiffAttrComparator. One of the elements can be nil, but if both are
+// not nil, they are expected to be of the same type. Otherwise, false is returned.
+func (o *DiffOptions) AttrEqual(from, to any) bool {
+	t1, t2 := reflect.TypeOf(from), reflect.TypeOf(to)
+	switch {
+	case t1 == nil && t2 == nil:
+		return false
+	case t1 == nil:
+		t1 = t2
+	case t2 != nil && t1 != t2:
+		return false
+	}
+	eq, ok := o.comparators[t1]
+	return ok && eq(from, to)
+}
+
 // Skipped reports whether the given change should be skipped.
 func (o *DiffOptions) Skipped(c Change) bool {
 	for _, s := range o.SkipChanges {
@@ -507,7 +601,8 @@ func (o *DiffOptions) AddOrSkip(changes Changes, cs ...Change) Changes {
 }
 
 // ErrLocked is returned on Lock calls which have failed to obtain the lock.
-var ErrLocked = errors.New("sql/schema: lock is held by other session")
+// It is classified as an error of kind sqlerr.Locked.
+var ErrLocked error = &sqlerr.Error{Kind: sqlerr.Locked, Err: errors.New("sql/schema: lock is held by other session")}
 
 type (
 	// UnlockFunc is returned by the Locker to explicitly
@@ -654,6 +749,7 @@ func (*AddSchema) change()        {}
 func (*DropSchema) change()       {}
 func (*ModifySchema) change()     {}
 func (*AddTable) change()         {}
+func (*CloneTable) change()       {}
 func (*DropTable) change()        {}
 func (*ModifyTable) change()      {}
 func (*RenameTable) change()      {}
@@ -697,5 +793,6 @@ func (*ModifyForeignKey) change() {}
 func (*RenameConstraint) change() {}
 
 // clauses.
-func (*IfExists) clause()    {}
-func (*IfNotExists) clause() {}
+func (*IfExists) clause()       {}
+func (*IfNotExists) clause()    {}
+func (*ColumnPosition) clause() {}
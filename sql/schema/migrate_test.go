@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"log"
 	"strconv"
+	"strings"
 	"testing"
 
 	"ariga.io/atlas/sql/schema"
@@ -124,3 +125,18 @@ func ExampleChanges_RemoveIndex() {
 	// *schema.AddColumn(created_at)
 	// *schema.RenameColumn(old_name -> new_name)
 }
+
+func TestDiffOptions_AttrEqual(t *testing.T) {
+	opts := schema.NewDiffOptions()
+	require.False(t, opts.AttrEqual(&schema.Comment{}, &schema.Comment{}), "no comparators")
+	opts = schema.NewDiffOptions(schema.DiffAttrComparator(func(from, to *schema.Comment) bool {
+		return from == nil || to == nil || strings.TrimSpace(from.Text) == strings.TrimSpace(to.Text)
+	}))
+	require.True(t, opts.AttrEqual(&schema.Comment{Text: "a"}, &schema.Comment{Text: "a "}))
+	require.False(t, opts.AttrEqual(&schema.Comment{Text: "a"}, &schema.Comment{Text: "b"}))
+	require.True(t, opts.AttrEqual(nil, &schema.Comment{Text: "a"}))
+	require.True(t, opts.AttrEqual(&schema.Comment{Text: "a"}, nil))
+	require.False(t, opts.AttrEqual(nil, nil))
+	require.False(t, opts.AttrEqual(&schema.Comment{}, &schema.Charset{}), "mismatched types")
+	require.False(t, opts.AttrEqual(&schema.Charset{V: "a"}, &schema.Charset{V: "b"}), "unregistered type")
+}
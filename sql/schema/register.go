@@ -0,0 +1,74 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema
+
+import (
+	"slices"
+	"strings"
+	"sync"
+)
+
+// registry holds the schemas registered by the application.
+var registry struct {
+	sync.Mutex
+	schemas []*Schema
+}
+
+// Register registers the given schemas as part of the desired state of the
+// application. It is intended to be called from the init functions of the
+// application packages that own the schema, allowing the application itself
+// to be the single source of truth for its schema. For example:
+//
+//	func init() {
+//		schema.Register(
+//			schema.New("public").
+//				AddTables(users, posts),
+//		)
+//	}
+//
+// Register panics if it is called twice for the same schema name. Tests that
+// register schemas should remove them using ResetRegistry when they are done.
+func Register(schemas ...*Schema) {
+	registry.Lock()
+	defer registry.Unlock()
+	for _, s := range schemas {
+		if s == nil {
+			panic("sql/schema: Register schema is nil")
+		}
+		if slices.ContainsFunc(registry.schemas, func(r *Schema) bool { return r.Name == s.Name }) {
+			panic("sql/schema: Register called twice for schema " + s.Name)
+		}
+		registry.schemas = append(registry.schemas, s)
+	}
+}
+
+// Registered returns a Realm holding copies of all schemas registered by Register,
+// sorted by their names. A nil Realm is returned if no schema was registered.
+//
+// The returned schemas and their resources (tables, columns, indexes, etc.) can be
+// modified by the caller without affecting the registered schemas. Note, attributes,
+// types and expressions are not copied, and are expected to be treated as immutable.
+func Registered() *Realm {
+	registry.Lock()
+	defer registry.Unlock()
+	if len(registry.schemas) == 0 {
+		return nil
+	}
+	schemas := CopySchemas(registry.schemas...)
+	slices.SortFunc(schemas, func(a, b *Schema) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return NewRealm(schemas...)
+}
+
+// ResetRegistry removes all schemas registered by Register. It is intended
+// to be used by tests that register schemas. For example:
+//
+//	t.Cleanup(schema.ResetRegistry)
+func ResetRegistry() {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.schemas = nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema_test
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegister(t *testing.T) {
+	t.Cleanup(schema.ResetRegistry)
+	require.Nil(t, schema.Registered())
+	var (
+		users = schema.NewTable("users").
+			AddColumns(schema.NewIntColumn("id", "int"))
+		posts = schema.NewTable("posts").
+			AddColumns(schema.NewIntColumn("id", "int"), schema.NewIntColumn("author_id", "int"))
+		a = schema.New("a").AddTables(users)
+		b = schema.New("b").AddTables(posts)
+	)
+	users.SetPrimaryKey(schema.NewPrimaryKey(users.Columns[0]))
+	posts.AddIndexes(schema.NewIndex("author").AddColumns(posts.Columns[1]))
+	posts.AddForeignKeys(schema.NewForeignKey("author").AddColumns(posts.Columns[1]).SetRefTable(users).AddRefColumns(users.Columns[0]))
+	schema.Register(b, a)
+	r := schema.Registered()
+	require.Len(t, r.Schemas, 2)
+	require.Equal(t, "a", r.Schemas[0].Name)
+	require.Equal(t, "b", r.Schemas[1].Name)
+	require.Equal(t, r, r.Schemas[0].Realm)
+	require.Nil(t, a.Realm, "registered schemas are not modified")
+
+	// Registered returns copies of the registered schemas.
+	u, p := r.Schemas[0].Tables[0], r.Schemas[1].Tables[0]
+	require.Equal(t, users.Name, u.Name)
+	require.NotSame(t, users, u)
+	require.NotSame(t, users.Columns[0], u.Columns[0])
+	require.Same(t, u, u.Columns[0].Indexes[0].Table)
+	require.Same(t, u.Columns[0], u.PrimaryKey.Parts[0].C)
+	require.Same(t, p.Columns[1], p.Indexes[0].Parts[0].C)
+	require.Same(t, p.Columns[1].Indexes[0], p.Indexes[0])
+	require.Same(t, u, p.ForeignKeys[0].RefTable)
+	require.Same(t, u.Columns[0], p.ForeignKeys[0].RefColumns[0])
+	require.Same(t, p.ForeignKeys[0], p.Columns[1].ForeignKeys[0])
+	u.AddColumns(schema.NewStringColumn("name", "text"))
+	require.Len(t, users.Columns, 1)
+	require.Len(t, schema.Registered().Schemas[0].Tables[0].Columns, 1)
+
+	require.Panics(t, func() {
+		schema.Register(schema.New("a"))
+	})
+	require.Panics(t, func() {
+		schema.Register(nil)
+	})
+	schema.ResetRegistry()
+	require.Nil(t, schema.Registered())
+}
@@ -5,6 +5,7 @@
 package schema
 
 import (
+	"fmt"
 	"reflect"
 )
 
@@ -243,6 +244,36 @@ func (r *Realm) Object(f func(Object) bool) (Object, bool) {
 	return nil, false
 }
 
+// InspectWarnings returns the warnings of a partial inspection of the realm, if exists.
+func (r *Realm) InspectWarnings() (*InspectWarnings, bool) {
+	for _, a := range r.Attrs {
+		if w, ok := a.(*InspectWarnings); ok {
+			return w, true
+		}
+	}
+	return nil, false
+}
+
+// InspectWarnings returns the warnings of a partial inspection of the schema, if exists.
+func (s *Schema) InspectWarnings() (*InspectWarnings, bool) {
+	for _, a := range s.Attrs {
+		if w, ok := a.(*InspectWarnings); ok {
+			return w, true
+		}
+	}
+	return nil, false
+}
+
+// ServerInfo returns the information of the server the realm was inspected from, if exists.
+func (r *Realm) ServerInfo() (*ServerInfo, bool) {
+	for _, a := range r.Attrs {
+		if s, ok := a.(*ServerInfo); ok {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
 // Pos of the schema, if exists.
 func (s *Schema) Pos() (*Pos, bool) {
 	for _, a := range s.Attrs {
@@ -490,6 +521,41 @@ func (f *ForeignKey) Pos() (*Pos, bool) {
 	return nil, false
 }
 
+// Pos of the function, if exists.
+func (f *Func) Pos() (*Pos, bool) {
+	for _, a := range f.Attrs {
+		if p, ok := a.(*Pos); ok {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// Pos of the procedure, if exists.
+func (p *Proc) Pos() (*Pos, bool) {
+	for _, a := range p.Attrs {
+		if p, ok := a.(*Pos); ok {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// Pos of the trigger, if exists.
+func (t *Trigger) Pos() (*Pos, bool) {
+	for _, a := range t.Attrs {
+		if p, ok := a.(*Pos); ok {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// String returns the position in the "file:line:column" format.
+func (p *Pos) String() string {
+	return fmt.Sprintf("%s:%d:%d", p.Filename, p.Start.Line, p.Start.Column)
+}
+
 // Column returns the first column that matches the given name.
 func (f *ForeignKey) Column(name string) (*Column, bool) {
 	for _, c := range f.Columns {
@@ -510,6 +576,16 @@ func (f *ForeignKey) RefColumn(name string) (*Column, bool) {
 	return nil, false
 }
 
+// Virtual reports if the foreign key is virtual, i.e., not enforced by the database.
+func (f *ForeignKey) Virtual() bool {
+	for _, a := range f.Attrs {
+		if _, ok := a.(*VirtualFK); ok {
+			return true
+		}
+	}
+	return false
+}
+
 // ReferenceOption for constraint actions.
 type ReferenceOption string
 
@@ -545,6 +621,7 @@ type (
 		T      string   // Optional type.
 		Values []string // Enum values.
 		Schema *Schema  // Optional schema.
+		Attrs  []Attr   // Optional attributes. e.g., Comment.
 	}
 
 	// BinaryType represents a type that stores a binary data.
@@ -689,6 +766,16 @@ type (
 		V string
 	}
 
+	// ServerInfo describes the database server of an inspected realm.
+	// It is attached by the drivers to the realm attributes on inspection.
+	ServerInfo struct {
+		Flavor    string // Server flavor. e.g., "mysql", "mariadb", "tidb", "postgres" or "cockroach".
+		Version   string // Server version. e.g., "8.0.32" or "15.2".
+		Charset   string // Default character set (or encoding) of the server.
+		Collation string // Default collation of the server.
+		TimeZone  string // Default time zone of the server, if known.
+	}
+
 	// Check describes a CHECK constraint.
 	Check struct {
 		Name  string // Optional constraint name.
@@ -714,6 +801,20 @@ type (
 		Attr
 	}
 
+	// Clone is a table attribute that indicates the table is created as a
+	// copy of the structure of its Source table (e.g., history or archive tables).
+	// The columns, primary key and checks of the table are copied from the source,
+	// while its secondary indexes and foreign keys are not.
+	Clone struct {
+		Source *Table
+	}
+
+	// VirtualFK is a foreign-key attribute that indicates the foreign key is not enforced
+	// by the database. Virtual foreign keys are carried in the schema model (e.g., for ERDs,
+	// analyzers and rename detection), but are never created, modified or dropped in the
+	// database, for teams that intentionally avoid database-enforced foreign keys.
+	VirtualFK struct{}
+
 	// Pos is an attribute that holds the position of a schema element.
 	Pos struct {
 		// Filename is the name (or full path) of the file which loaded the schema element.
@@ -768,11 +869,14 @@ func (*UnsupportedType) typ() {}
 // attributes.
 func (*Pos) attr()             {}
 func (*Check) attr()           {}
+func (*Clone) attr()           {}
 func (*Comment) attr()         {}
 func (*Charset) attr()         {}
 func (*Collation) attr()       {}
+func (*ServerInfo) attr()      {}
 func (*GeneratedExpr) attr()   {}
 func (*ViewCheckOption) attr() {}
+func (*VirtualFK) attr()       {}
 
 // SpecType returns the type of the spec.
 func (e *EnumType) SpecType() string { return "enum" }
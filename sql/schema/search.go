@@ -0,0 +1,144 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+)
+
+type (
+	// A ColumnPredicate reports if a column of the given table matches a search.
+	ColumnPredicate func(*Table, *Column) bool
+
+	// TableColumn is a column returned by a search, along with the table it belongs to.
+	TableColumn struct {
+		T *Table
+		C *Column
+	}
+)
+
+// ColumnTypeName returns a ColumnPredicate that matches columns by their type name, as returned
+// by the database or defined by the user (e.g., "jsonb" or "varchar(255)"). The comparison
+// is case-insensitive, and a name without arguments matches all arguments. e.g., "varchar"
+// matches "varchar(255)".
+func ColumnTypeName(name string) ColumnPredicate {
+	return func(_ *Table, c *Column) bool {
+		if c.Type == nil {
+			return false
+		}
+		for _, t := range []string{c.Type.Raw, typeName(c.Type.Type)} {
+			if t == "" {
+				continue
+			}
+			if strings.EqualFold(t, name) || !strings.Contains(name, "(") && strings.EqualFold(strings.TrimSpace(strings.SplitN(t, "(", 2)[0]), name) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ColumnTypeOf returns a ColumnPredicate that matches columns whose type is T.
+// For example, ColumnTypeOf[*JSONType]() matches all JSON columns.
+func ColumnTypeOf[T Type]() ColumnPredicate {
+	return func(_ *Table, c *Column) bool {
+		if c.Type == nil {
+			return false
+		}
+		_, ok := c.Type.Type.(T)
+		return ok
+	}
+}
+
+// ColumnNamed returns a ColumnPredicate that matches columns by their name.
+func ColumnNamed(name string) ColumnPredicate {
+	return func(_ *Table, c *Column) bool {
+		return c.Name == name
+	}
+}
+
+// ColumnNullable returns a ColumnPredicate that matches nullable columns.
+func ColumnNullable() ColumnPredicate {
+	return func(_ *Table, c *Column) bool {
+		return c.Type != nil && c.Type.Null
+	}
+}
+
+// FindColumns returns the table columns in the realm that match all the given
+// predicates, ordered by their position in the realm.
+func (r *Realm) FindColumns(preds ...ColumnPredicate) []TableColumn {
+	var found []TableColumn
+	for _, s := range r.Schemas {
+		for _, t := range s.Tables {
+			for _, c := range t.Columns {
+				if !slices.ContainsFunc(preds, func(p ColumnPredicate) bool { return !p(t, c) }) {
+					found = append(found, TableColumn{T: t, C: c})
+				}
+			}
+		}
+	}
+	return found
+}
+
+// TablesReferencing returns the tables in the realm that have foreign keys referencing the
+// given table, ordered by their position in the realm. A self-referencing table is included.
+func (r *Realm) TablesReferencing(t *Table) []*Table {
+	var found []*Table
+	for _, s := range r.Schemas {
+		for _, t1 := range s.Tables {
+			if slices.ContainsFunc(t1.ForeignKeys, func(fk *ForeignKey) bool { return fk.RefTable == t }) {
+				found = append(found, t1)
+			}
+		}
+	}
+	return found
+}
+
+// IndexesCovering returns the indexes of the table, including its primary key, that cover
+// the given columns. An index covers the columns if they are its leading parts, in any order,
+// and therefore the index can be used for lookups on these columns. For example, an index on
+// (a, b, c) covers the columns (b, a), but not the columns (a, c).
+func (t *Table) IndexesCovering(columns ...*Column) []*Index {
+	if len(columns) == 0 {
+		return nil
+	}
+	var found []*Index
+	if t.PrimaryKey != nil && covers(t.PrimaryKey, columns) {
+		found = append(found, t.PrimaryKey)
+	}
+	for _, idx := range t.Indexes {
+		if idx != t.PrimaryKey && covers(idx, columns) {
+			found = append(found, idx)
+		}
+	}
+	return found
+}
+
+// covers reports if the leading parts of the index are the given columns.
+func covers(idx *Index, columns []*Column) bool {
+	if len(idx.Parts) < len(columns) {
+		return false
+	}
+	for _, p := range idx.Parts[:len(columns)] {
+		if p.C == nil || !slices.Contains(columns, p.C) {
+			return false
+		}
+	}
+	return true
+}
+
+// typeName returns the name of the type held in its T field, if exists.
+func typeName(t Type) string {
+	v := reflect.Indirect(reflect.ValueOf(t))
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+	if f := v.FieldByName("T"); f.IsValid() && f.Kind() == reflect.String {
+		return f.String()
+	}
+	return ""
+}
@@ -0,0 +1,59 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema_test
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRealm_Search(t *testing.T) {
+	var (
+		users = schema.NewTable("users").AddColumns(
+			schema.NewIntColumn("id", "bigint"),
+			schema.NewColumn("doc").SetType(&schema.JSONType{T: "jsonb"}),
+			schema.NewStringColumn("name", "varchar").SetType(&schema.StringType{T: "varchar", Size: 255}).SetNull(true),
+		)
+		posts = schema.NewTable("posts").AddColumns(
+			schema.NewIntColumn("id", "bigint"),
+			schema.NewIntColumn("author_id", "bigint"),
+			schema.NewColumn("meta").SetType(&schema.JSONType{T: "json"}),
+			schema.NewNullIntColumn("parent_id", "bigint"),
+		)
+		r = schema.NewRealm(schema.New("public").AddTables(users, posts))
+	)
+	users.Columns[2].Type.Raw = "varchar(255)"
+	users.SetPrimaryKey(schema.NewPrimaryKey(users.Columns[0]))
+	posts.AddForeignKeys(
+		schema.NewForeignKey("author").AddColumns(posts.Columns[1]).SetRefTable(users).AddRefColumns(users.Columns[0]),
+		schema.NewForeignKey("parent").AddColumns(posts.Columns[3]).SetRefTable(posts).AddRefColumns(posts.Columns[0]),
+	)
+	posts.AddIndexes(
+		schema.NewIndex("author_parent").AddColumns(posts.Columns[1], posts.Columns[3]),
+		schema.NewIndex("parent").AddColumns(posts.Columns[3]),
+		schema.NewIndex("lower_meta").AddExprs(&schema.RawExpr{X: "lower(meta)"}),
+	)
+
+	require.Equal(t, []schema.TableColumn{{T: users, C: users.Columns[1]}}, r.FindColumns(schema.ColumnTypeName("JSONB")))
+	require.Equal(t, []schema.TableColumn{{T: users, C: users.Columns[1]}, {T: posts, C: posts.Columns[2]}}, r.FindColumns(schema.ColumnTypeOf[*schema.JSONType]()))
+	require.Equal(t, []schema.TableColumn{{T: users, C: users.Columns[2]}}, r.FindColumns(schema.ColumnTypeName("varchar")))
+	require.Empty(t, r.FindColumns(schema.ColumnTypeName("varchar(100)")))
+	require.Equal(t, []schema.TableColumn{{T: posts, C: posts.Columns[3]}}, r.FindColumns(schema.ColumnNullable(), schema.ColumnTypeName("bigint")))
+	require.Len(t, r.FindColumns(schema.ColumnNamed("id")), 2)
+
+	require.Equal(t, []*schema.Table{posts}, r.TablesReferencing(users))
+	require.Equal(t, []*schema.Table{posts}, r.TablesReferencing(posts))
+	require.Empty(t, r.TablesReferencing(schema.NewTable("other")))
+
+	require.Equal(t, []*schema.Index{users.PrimaryKey}, users.IndexesCovering(users.Columns[0]))
+	require.Equal(t, []*schema.Index{posts.Indexes[0]}, posts.IndexesCovering(posts.Columns[1]))
+	require.Equal(t, []*schema.Index{posts.Indexes[0]}, posts.IndexesCovering(posts.Columns[3], posts.Columns[1]))
+	require.Equal(t, []*schema.Index{posts.Indexes[1]}, posts.IndexesCovering(posts.Columns[3]))
+	require.Empty(t, posts.IndexesCovering(posts.Columns[2]))
+	require.Empty(t, posts.IndexesCovering())
+}
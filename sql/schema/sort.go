@@ -0,0 +1,103 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema
+
+import (
+	"cmp"
+	"reflect"
+	"slices"
+)
+
+// SortRealm sorts the elements of the realm in a deterministic order, and it is called
+// by the drivers at the end of the inspection. The ordering contract is as follows:
+//
+//   - Schemas, tables, views, functions and procedures are ordered by their names.
+//   - Schema objects (e.g., enums or sequences) are ordered by their type names and then
+//     by their names.
+//   - The elements of a table are kept in their driver order, which does not depend on
+//     the other tables in the schema: columns are ordered by their ordinal position, index
+//     parts by their position in the index, and indexes, foreign keys and checks by the
+//     order they are returned by the database. Use SortTable to order them by name.
+func SortRealm(r *Realm) {
+	slices.SortStableFunc(r.Schemas, func(a, b *Schema) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	slices.SortStableFunc(r.Objects, compareObjects)
+	for _, s := range r.Schemas {
+		SortSchema(s)
+	}
+}
+
+// SortSchema sorts the elements of the schema in a deterministic order.
+// See SortRealm for the ordering contract.
+func SortSchema(s *Schema) {
+	slices.SortStableFunc(s.Tables, func(a, b *Table) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	slices.SortStableFunc(s.Views, func(a, b *View) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	slices.SortStableFunc(s.Funcs, func(a, b *Func) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	slices.SortStableFunc(s.Procs, func(a, b *Proc) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	slices.SortStableFunc(s.Objects, compareObjects)
+}
+
+// SortTable sorts the indexes, foreign keys, checks and triggers of the table by their
+// names. Columns and index parts are not reordered, and elements without a name keep
+// their relative order.
+func SortTable(t *Table) {
+	slices.SortStableFunc(t.Indexes, func(a, b *Index) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	slices.SortStableFunc(t.ForeignKeys, func(a, b *ForeignKey) int {
+		return cmp.Compare(a.Symbol, b.Symbol)
+	})
+	slices.SortStableFunc(t.Triggers, func(a, b *Trigger) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	// Checks are sorted in-place, and other attributes keep their positions.
+	var (
+		pos    []int
+		checks []*Check
+	)
+	for i, a := range t.Attrs {
+		if c, ok := a.(*Check); ok {
+			pos, checks = append(pos, i), append(checks, c)
+		}
+	}
+	slices.SortStableFunc(checks, func(a, b *Check) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	for i, p := range pos {
+		t.Attrs[p] = checks[i]
+	}
+}
+
+// compareObjects compares two objects by their type names and then by their names.
+func compareObjects(a, b Object) int {
+	if c := cmp.Compare(reflect.TypeOf(a).String(), reflect.TypeOf(b).String()); c != 0 {
+		return c
+	}
+	return cmp.Compare(objectName(a), objectName(b))
+}
+
+// objectName returns the name of the object, stored either in its
+// Name or T fields. e.g., Sequence.Name or EnumType.T.
+func objectName(o Object) string {
+	v := reflect.Indirect(reflect.ValueOf(o))
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+	for _, n := range []string{"Name", "T"} {
+		if f := v.FieldByName(n); f.IsValid() && f.Kind() == reflect.String {
+			return f.String()
+		}
+	}
+	return ""
+}
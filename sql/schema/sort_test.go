@@ -0,0 +1,43 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema_test
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortRealm(t *testing.T) {
+	var (
+		users = schema.NewTable("users").AddColumns(schema.NewIntColumn("z", "int"), schema.NewIntColumn("a", "int"))
+		pets  = schema.NewTable("pets").AddColumns(schema.NewIntColumn("id", "int"))
+		s1    = schema.New("s1").AddTables(users, pets).AddObjects(
+			&schema.EnumType{T: "status"},
+			&schema.EnumType{T: "mood"},
+		)
+		s2 = schema.New("public").AddViews(schema.NewView("v2", "SELECT 1"), schema.NewView("v1", "SELECT 1"))
+		r  = schema.NewRealm(s1, s2)
+	)
+	users.AddIndexes(schema.NewIndex("z_idx").AddColumns(users.Columns[0]), schema.NewIndex("a_idx").AddColumns(users.Columns[1]))
+	schema.SortRealm(r)
+	require.Equal(t, []*schema.Schema{s2, s1}, r.Schemas)
+	require.Equal(t, []*schema.Table{pets, users}, s1.Tables)
+	require.Equal(t, "v1", s2.Views[0].Name)
+	require.Equal(t, "mood", s1.Objects[0].(*schema.EnumType).T)
+	require.Equal(t, "z", users.Columns[0].Name, "columns keep their order")
+	require.Equal(t, "z_idx", users.Indexes[0].Name, "table elements keep their order")
+
+	users.AddChecks(schema.NewCheck().SetName("c2"), schema.NewCheck().SetName("c1")).SetComment("comment")
+	users.AddForeignKeys(schema.NewForeignKey("fk2"), schema.NewForeignKey("fk1"))
+	schema.SortTable(users)
+	require.Equal(t, "a_idx", users.Indexes[0].Name)
+	require.Equal(t, "fk1", users.ForeignKeys[0].Symbol)
+	require.Equal(t, "c1", users.Attrs[0].(*schema.Check).Name)
+	require.Equal(t, "c2", users.Attrs[1].(*schema.Check).Name)
+	require.IsType(t, &schema.Comment{}, users.Attrs[2])
+}
@@ -0,0 +1,234 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema
+
+import "reflect"
+
+type (
+	// A Summary is a human-oriented summary of a changeset, suitable for reporting
+	// (e.g., in pull-request comments or chat notifications). Its JSON encoding is
+	// stable and can be consumed by external tools.
+	Summary struct {
+		// Total is the number of changes, including the nested changes
+		// of modified elements (e.g., the column changes of a table).
+		Total int `json:"Total"`
+		// Counts holds the number of changes by their kind. e.g., {"AddColumn": 2}.
+		Counts map[string]int `json:"Counts"`
+		// Objects holds the objects affected by the changes, in their changeset order.
+		Objects []*SummaryObject `json:"Objects"`
+		// Destructive holds the subset of Objects whose changes may cause data loss.
+		Destructive []*SummaryObject `json:"Destructive,omitempty"`
+	}
+
+	// SummaryObject describes an object affected by a change.
+	SummaryObject struct {
+		// Action that was applied on the object. One of: Add, Drop, Modify or Rename.
+		Action string `json:"Action"`
+		// Type of the object. e.g., Table, Column or Index. Driver-specific objects
+		// are reported by their type name, e.g., EnumType.
+		Type string `json:"Type"`
+		// Name is the qualified name of the object. e.g., "public.users.name".
+		Name string `json:"Name"`
+		// From holds the previous name of a renamed object.
+		From string `json:"From,omitempty"`
+	}
+)
+
+// List of summary actions.
+const (
+	SummaryAdd    = "Add"
+	SummaryDrop   = "Drop"
+	SummaryModify = "Modify"
+	SummaryRename = "Rename"
+)
+
+// Summarize reduces the given changeset into a Summary. Dropping schemas, tables
+// and columns are considered destructive changes, as they may cause data loss.
+func Summarize(changes []Change) *Summary {
+	s := &Summary{Counts: make(map[string]int), Objects: make([]*SummaryObject, 0, len(changes))}
+	s.add("", changes)
+	return s
+}
+
+// add summarizes the given changes of the element with the given qualified name.
+func (s *Summary) add(parent string, changes []Change) {
+	for _, c := range changes {
+		s.Total++
+		s.Counts[changeKind(c)]++
+		var (
+			o      *SummaryObject
+			nested []Change
+		)
+		switch c := c.(type) {
+		case *AddSchema:
+			o = &SummaryObject{Action: SummaryAdd, Type: "Schema", Name: c.S.Name}
+		case *DropSchema:
+			o = &SummaryObject{Action: SummaryDrop, Type: "Schema", Name: c.S.Name}
+			s.Destructive = append(s.Destructive, o)
+		case *ModifySchema:
+			o = &SummaryObject{Action: SummaryModify, Type: "Schema", Name: c.S.Name}
+			nested = c.Changes
+		case *AddTable:
+			o = &SummaryObject{Action: SummaryAdd, Type: "Table", Name: qualified(c.T.Schema, c.T.Name)}
+		case *CloneTable:
+			o = &SummaryObject{Action: SummaryAdd, Type: "Table", Name: qualified(c.T.Schema, c.T.Name)}
+		case *DropTable:
+			o = &SummaryObject{Action: SummaryDrop, Type: "Table", Name: qualified(c.T.Schema, c.T.Name)}
+			s.Destructive = append(s.Destructive, o)
+		case *ModifyTable:
+			o = &SummaryObject{Action: SummaryModify, Type: "Table", Name: qualified(c.T.Schema, c.T.Name)}
+			nested = c.Changes
+		case *RenameTable:
+			o = &SummaryObject{Action: SummaryRename, Type: "Table", Name: qualified(c.To.Schema, c.To.Name), From: qualified(c.From.Schema, c.From.Name)}
+		case *AddView:
+			o = &SummaryObject{Action: SummaryAdd, Type: "View", Name: qualified(c.V.Schema, c.V.Name)}
+		case *DropView:
+			o = &SummaryObject{Action: SummaryDrop, Type: "View", Name: qualified(c.V.Schema, c.V.Name)}
+		case *ModifyView:
+			o = &SummaryObject{Action: SummaryModify, Type: "View", Name: qualified(c.To.Schema, c.To.Name)}
+			nested = c.Changes
+		case *RenameView:
+			o = &SummaryObject{Action: SummaryRename, Type: "View", Name: qualified(c.To.Schema, c.To.Name), From: qualified(c.From.Schema, c.From.Name)}
+		case *AddFunc:
+			o = &SummaryObject{Action: SummaryAdd, Type: "Func", Name: qualified(c.F.Schema, c.F.Name)}
+		case *DropFunc:
+			o = &SummaryObject{Action: SummaryDrop, Type: "Func", Name: qualified(c.F.Schema, c.F.Name)}
+		case *ModifyFunc:
+			o = &SummaryObject{Action: SummaryModify, Type: "Func", Name: qualified(c.To.Schema, c.To.Name)}
+		case *RenameFunc:
+			o = &SummaryObject{Action: SummaryRename, Type: "Func", Name: qualified(c.To.Schema, c.To.Name), From: qualified(c.From.Schema, c.From.Name)}
+		case *AddProc:
+			o = &SummaryObject{Action: SummaryAdd, Type: "Proc", Name: qualified(c.P.Schema, c.P.Name)}
+		case *DropProc:
+			o = &SummaryObject{Action: SummaryDrop, Type: "Proc", Name: qualified(c.P.Schema, c.P.Name)}
+		case *ModifyProc:
+			o = &SummaryObject{Action: SummaryModify, Type: "Proc", Name: qualified(c.To.Schema, c.To.Name)}
+		case *RenameProc:
+			o = &SummaryObject{Action: SummaryRename, Type: "Proc", Name: qualified(c.To.Schema, c.To.Name), From: qualified(c.From.Schema, c.From.Name)}
+		case *AddTrigger:
+			o = &SummaryObject{Action: SummaryAdd, Type: "Trigger", Name: triggerName(c.T)}
+		case *DropTrigger:
+			o = &SummaryObject{Action: SummaryDrop, Type: "Trigger", Name: triggerName(c.T)}
+		case *ModifyTrigger:
+			o = &SummaryObject{Action: SummaryModify, Type: "Trigger", Name: triggerName(c.To)}
+		case *RenameTrigger:
+			o = &SummaryObject{Action: SummaryRename, Type: "Trigger", Name: triggerName(c.To), From: triggerName(c.From)}
+		case *AddObject:
+			o = &SummaryObject{Action: SummaryAdd, Type: objectType(c.O), Name: objectQualified(c.O)}
+		case *DropObject:
+			o = &SummaryObject{Action: SummaryDrop, Type: objectType(c.O), Name: objectQualified(c.O)}
+		case *ModifyObject:
+			o = &SummaryObject{Action: SummaryModify, Type: objectType(c.To), Name: objectQualified(c.To)}
+		case *RenameObject:
+			o = &SummaryObject{Action: SummaryRename, Type: objectType(c.To), Name: objectQualified(c.To), From: objectQualified(c.From)}
+		case *AddColumn:
+			o = &SummaryObject{Action: SummaryAdd, Type: "Column", Name: qualifiedIn(parent, c.C.Name)}
+		case *DropColumn:
+			o = &SummaryObject{Action: SummaryDrop, Type: "Column", Name: qualifiedIn(parent, c.C.Name)}
+			s.Destructive = append(s.Destructive, o)
+		case *ModifyColumn:
+			o = &SummaryObject{Action: SummaryModify, Type: "Column", Name: qualifiedIn(parent, c.To.Name)}
+		case *RenameColumn:
+			o = &SummaryObject{Action: SummaryRename, Type: "Column", Name: qualifiedIn(parent, c.To.Name), From: qualifiedIn(parent, c.From.Name)}
+		case *AddIndex:
+			o = &SummaryObject{Action: SummaryAdd, Type: "Index", Name: qualifiedIn(parent, c.I.Name)}
+		case *DropIndex:
+			o = &SummaryObject{Action: SummaryDrop, Type: "Index", Name: qualifiedIn(parent, c.I.Name)}
+		case *ModifyIndex:
+			o = &SummaryObject{Action: SummaryModify, Type: "Index", Name: qualifiedIn(parent, c.To.Name)}
+		case *RenameIndex:
+			o = &SummaryObject{Action: SummaryRename, Type: "Index", Name: qualifiedIn(parent, c.To.Name), From: qualifiedIn(parent, c.From.Name)}
+		case *AddPrimaryKey:
+			o = &SummaryObject{Action: SummaryAdd, Type: "PrimaryKey", Name: qualifiedIn(parent, c.P.Name)}
+		case *DropPrimaryKey:
+			o = &SummaryObject{Action: SummaryDrop, Type: "PrimaryKey", Name: qualifiedIn(parent, c.P.Name)}
+		case *ModifyPrimaryKey:
+			o = &SummaryObject{Action: SummaryModify, Type: "PrimaryKey", Name: qualifiedIn(parent, c.To.Name)}
+		case *AddForeignKey:
+			o = &SummaryObject{Action: SummaryAdd, Type: "ForeignKey", Name: qualifiedIn(parent, c.F.Symbol)}
+		case *DropForeignKey:
+			o = &SummaryObject{Action: SummaryDrop, Type: "ForeignKey", Name: qualifiedIn(parent, c.F.Symbol)}
+		case *ModifyForeignKey:
+			o = &SummaryObject{Action: SummaryModify, Type: "ForeignKey", Name: qualifiedIn(parent, c.To.Symbol)}
+		case *AddCheck:
+			o = &SummaryObject{Action: SummaryAdd, Type: "Check", Name: qualifiedIn(parent, c.C.Name)}
+		case *DropCheck:
+			o = &SummaryObject{Action: SummaryDrop, Type: "Check", Name: qualifiedIn(parent, c.C.Name)}
+		case *ModifyCheck:
+			o = &SummaryObject{Action: SummaryModify, Type: "Check", Name: qualifiedIn(parent, c.To.Name)}
+		}
+		// Attribute changes (e.g., comments) are counted,
+		// but reported as changes of their parent element.
+		if o != nil {
+			s.Objects = append(s.Objects, o)
+			s.add(o.Name, nested)
+		}
+	}
+}
+
+// changeKind returns the kind of the change, as reported by the summary.
+func changeKind(c Change) string {
+	t := reflect.TypeOf(c)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+// qualified returns the name qualified with its schema name, if exists.
+func qualified(s *Schema, name string) string {
+	if s == nil || s.Name == "" {
+		return name
+	}
+	return s.Name + "." + name
+}
+
+// qualifiedIn returns the name qualified with the name of its parent element.
+func qualifiedIn(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	if name == "" {
+		return parent
+	}
+	return parent + "." + name
+}
+
+// triggerName returns the qualified name of the trigger.
+func triggerName(t *Trigger) string {
+	switch {
+	case t.Table != nil:
+		return qualifiedIn(qualified(t.Table.Schema, t.Table.Name), t.Name)
+	case t.View != nil:
+		return qualifiedIn(qualified(t.View.Schema, t.View.Name), t.Name)
+	default:
+		return t.Name
+	}
+}
+
+// objectType returns the type name of a driver-specific object.
+func objectType(o Object) string {
+	t := reflect.TypeOf(o)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+// objectQualified returns the qualified name of a driver-specific
+// object, based on its name and its Schema field, if exists.
+func objectQualified(o Object) string {
+	name := objectName(o)
+	v := reflect.Indirect(reflect.ValueOf(o))
+	if v.Kind() != reflect.Struct {
+		return name
+	}
+	if f := v.FieldByName("Schema"); f.IsValid() && f.CanInterface() {
+		if s, ok := f.Interface().(*Schema); ok {
+			return qualified(s, name)
+		}
+	}
+	return name
+}
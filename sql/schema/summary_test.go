@@ -0,0 +1,81 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarize(t *testing.T) {
+	var (
+		public = schema.New("public")
+		users  = schema.NewTable("users").SetSchema(public)
+		posts  = schema.NewTable("posts").SetSchema(public)
+		status = &schema.EnumType{T: "status", Schema: public}
+	)
+	s := schema.Summarize([]schema.Change{
+		&schema.AddObject{O: status},
+		&schema.AddTable{T: posts},
+		&schema.ModifyTable{
+			T: users,
+			Changes: []schema.Change{
+				&schema.AddColumn{C: schema.NewIntColumn("age", "int")},
+				&schema.DropColumn{C: schema.NewStringColumn("nick", "text")},
+				&schema.RenameColumn{From: schema.NewStringColumn("name", "text"), To: schema.NewStringColumn("full_name", "text")},
+				&schema.AddIndex{I: schema.NewIndex("users_age")},
+				&schema.ModifyAttr{From: &schema.Comment{Text: "a"}, To: &schema.Comment{Text: "b"}},
+			},
+		},
+		&schema.DropTable{T: schema.NewTable("logs").SetSchema(public)},
+	})
+	require.Equal(t, 9, s.Total)
+	require.Equal(t, map[string]int{
+		"AddObject":    1,
+		"AddTable":     1,
+		"ModifyTable":  1,
+		"AddColumn":    1,
+		"DropColumn":   1,
+		"RenameColumn": 1,
+		"AddIndex":     1,
+		"ModifyAttr":   1,
+		"DropTable":    1,
+	}, s.Counts)
+	require.Len(t, s.Objects, 8)
+	require.Equal(t, []*schema.SummaryObject{
+		{Action: schema.SummaryDrop, Type: "Column", Name: "public.users.nick"},
+		{Action: schema.SummaryDrop, Type: "Table", Name: "public.logs"},
+	}, s.Destructive)
+
+	buf, err := json.Marshal(s)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+  "Total": 9,
+  "Counts": {"AddColumn": 1, "AddIndex": 1, "AddObject": 1, "AddTable": 1, "DropColumn": 1, "DropTable": 1, "ModifyAttr": 1, "ModifyTable": 1, "RenameColumn": 1},
+  "Objects": [
+    {"Action": "Add", "Type": "EnumType", "Name": "public.status"},
+    {"Action": "Add", "Type": "Table", "Name": "public.posts"},
+    {"Action": "Modify", "Type": "Table", "Name": "public.users"},
+    {"Action": "Add", "Type": "Column", "Name": "public.users.age"},
+    {"Action": "Drop", "Type": "Column", "Name": "public.users.nick"},
+    {"Action": "Rename", "Type": "Column", "Name": "public.users.full_name", "From": "public.users.name"},
+    {"Action": "Add", "Type": "Index", "Name": "public.users.users_age"},
+    {"Action": "Drop", "Type": "Table", "Name": "public.logs"}
+  ],
+  "Destructive": [
+    {"Action": "Drop", "Type": "Column", "Name": "public.users.nick"},
+    {"Action": "Drop", "Type": "Table", "Name": "public.logs"}
+  ]
+}`, string(buf))
+
+	// Empty changesets are encoded with empty collections.
+	buf, err = json.Marshal(schema.Summarize(nil))
+	require.NoError(t, err)
+	require.Equal(t, `{"Total":0,"Counts":{},"Objects":[]}`, string(buf))
+}
@@ -93,6 +93,9 @@ type (
 		Text           string         `json:"Text"`                     // Diagnostic text.
 		Code           string         `json:"Code"`                     // Code describes the check. For example, DS101
 		SuggestedFixes []SuggestedFix `json:"SuggestedFixes,omitempty"` // Fixes to this specific diagnostics (statement-level).
+		// SchemaPos is the position of the schema element that caused the statement, if known.
+		// For example, the table block in the HCL file that was used to plan the migration.
+		SchemaPos *schema.Pos `json:"SchemaPos,omitempty"`
 	}
 
 	// A SuggestedFix is a change associated with a diagnostic that can
@@ -132,6 +135,22 @@ func (d *Diagnostic) SuggestFix(m string, e *TextEdit) {
 	d.SuggestedFixes = append(d.SuggestedFixes, SuggestedFix{Message: m, TextEdit: e})
 }
 
+// WithSchemaPos returns a ReportWriter that sets the SchemaPos of the reported diagnostics
+// to the source positions of the schema elements that caused their statements, if known.
+func WithSchemaPos(f *File, w ReportWriter) ReportWriter {
+	return ReportWriterFunc(func(r Report) {
+		ds := make([]Diagnostic, len(r.Diagnostics))
+		for i, d := range r.Diagnostics {
+			if d.SchemaPos == nil {
+				d.SchemaPos, _ = f.SchemaPos(d.Pos)
+			}
+			ds[i] = d
+		}
+		r.Diagnostics = ds
+		w.WriteReport(r)
+	})
+}
+
 // Analyzers implements Analyzer.
 type Analyzers []Analyzer
 
@@ -183,6 +202,23 @@ const (
 	SpanTemporary = SpanAdded | SpanDropped
 )
 
+// SchemaPos returns the source position of the schema element that caused the
+// statement at the given position, if exists. Positions are known only if the
+// changes of the file were computed from a schema loaded with its positions.
+func (f *File) SchemaPos(pos int) (*schema.Pos, bool) {
+	for _, c := range f.Changes {
+		if c.Stmt == nil || c.Stmt.Pos != pos {
+			continue
+		}
+		for _, sc := range c.Changes {
+			if p, ok := migrate.ChangePos(sc); ok {
+				return p, true
+			}
+		}
+	}
+	return nil, false
+}
+
 // SchemaSpan returns the span information for the schema.
 func (f *File) SchemaSpan(s *schema.Schema) ResourceSpan {
 	return f.schemaSpan(s).state
@@ -111,6 +111,33 @@ func (c *Client) Tx(ctx context.Context, opts *sql.TxOptions) (*TxClient, error)
 		}
 		tx = &Tx{Tx: ttx}
 	}
+	return c.txClient(ctx, tx)
+}
+
+// UseTx returns a transactional client that wraps a transaction managed by the caller.
+// All operations of the returned client (e.g., planning and applying changes, or executing
+// migration files) are executed within the given transaction, and no other transactions are
+// opened by it. Calling Commit or Rollback on the returned client only runs the registered
+// transaction hooks, and it is the responsibility of the caller to end the transaction.
+//
+// Note that custom transaction openers registered by the driver (RegisterTxOpener) are not
+// used, and therefore, settings they apply outside the transaction are not applied to it.
+func (c *Client) UseTx(ctx context.Context, tx *sql.Tx) (*TxClient, error) {
+	if c.openDriver == nil {
+		return nil, errors.New("sql/sqlclient: unexpected driver opener: <nil>")
+	}
+	if tx == nil {
+		return nil, errors.New("sql/sqlclient: unexpected transaction: <nil>")
+	}
+	return c.txClient(ctx, &Tx{
+		Tx:         tx,
+		CommitFn:   func() error { return nil },
+		RollbackFn: func() error { return nil },
+	})
+}
+
+// txClient returns a transactional client that wraps the given transaction.
+func (c *Client) txClient(ctx context.Context, tx *Tx) (*TxClient, error) {
 	drv, err := c.openDriver(tx)
 	if err != nil {
 		return nil, fmt.Errorf("sql/sqlclient: opening atlas driver: %w", err)
@@ -249,6 +276,12 @@ type (
 		ChangeSchema(*url.URL, string) *url.URL
 	}
 
+	// DatabaseEnsurer is implemented by a driver whose URLs are bound to a database that holds
+	// the schemas (e.g., PostgreSQL), if it knows how to create this database when it is missing.
+	DatabaseEnsurer interface {
+		EnsureDatabase(context.Context, *url.URL) error
+	}
+
 	driver struct {
 		Opener
 		name     string
@@ -285,8 +318,12 @@ var drivers sync.Map
 type (
 	// openOptions holds additional configuration values for opening a Client.
 	openOptions struct {
-		schema *string
-		hooks  []*Hook
+		schema    *string
+		hooks     []*Hook
+		resolvers map[string]SecretResolver
+		secrets   bool
+		ensure    bool
+		attrs     []schema.Attr
 	}
 	// OpenOption allows to configure a openOptions using functional arguments.
 	OpenOption func(*openOptions) error
@@ -295,11 +332,19 @@ type (
 // ErrUnsupported is returned if a registered driver does not support changing the schema.
 var ErrUnsupported = errors.New("sql/sqlclient: driver does not support changing connected schema")
 
-// Open opens an Atlas client by its provided url string.
+// Open opens an Atlas client by its provided url string. Secret references in
+// the url are resolved before it is parsed only if enabled by the OpenWithSecrets
+// or OpenWithSecretResolver options. See, ResolveURL for details.
 func Open(ctx context.Context, s string, opts ...OpenOption) (*Client, error) {
-	u, err := ParseURL(s)
+	cfg := &openOptions{}
+	for _, opt := range opts {
+		if err := opt(cfg); err != nil {
+			return nil, err
+		}
+	}
+	u, err := cfg.parseURL(ctx, s)
 	if err != nil {
-		return nil, fmt.Errorf("sql/sqlclient: parse open url: %w", err)
+		return nil, err
 	}
 	return OpenURL(ctx, u, opts...)
 }
@@ -334,6 +379,11 @@ func OpenURL(ctx context.Context, u *url.URL, opts ...OpenOption) (*Client, erro
 		}
 		u = sc.ChangeSchema(u, *cfg.schema)
 	}
+	if cfg.ensure {
+		if err := drv.ensureSchema(ctx, u, cfg.attrs); err != nil {
+			return nil, err
+		}
+	}
 	client, err := drv.Open(ctx, u)
 	if err != nil {
 		return nil, err
@@ -362,6 +412,54 @@ func OpenSchema(s string) OpenOption {
 	}
 }
 
+// OpenEnsureSchema returns an OpenOption that creates the schema (named database in MySQL)
+// the URL is connected to, if it does not exist, before opening the connection to it. The
+// given attributes are used for creating the schema. e.g., schema.Charset in MySQL or
+// postgres.SchemaOwner in PostgreSQL. For drivers that implement the DatabaseEnsurer
+// interface, the database of the URL (e.g., the path of a PostgreSQL URL) is created
+// first, if it does not exist.
+//
+// URLs that are not connected to a schema, or drivers that cannot connect without it (e.g.,
+// SQLite, where the database file is created on connection), are opened as-is.
+func OpenEnsureSchema(attrs ...schema.Attr) OpenOption {
+	return func(c *openOptions) error {
+		c.ensure, c.attrs = true, attrs
+		return nil
+	}
+}
+
+// ensureSchema creates the schema of the given URL, if it does not exist, using a connection
+// that is not bound to the schema, as some databases fail to connect to a missing schema.
+func (d *driver) ensureSchema(ctx context.Context, u *url.URL, attrs []schema.Attr) error {
+	if de, ok := d.parser.(DatabaseEnsurer); ok {
+		if err := de.EnsureDatabase(ctx, u); err != nil {
+			return err
+		}
+	}
+	name := d.parser.ParseURL(u).Schema
+	sc, ok := d.parser.(SchemaChanger)
+	if name == "" || !ok {
+		return nil
+	}
+	c, err := d.Open(ctx, sc.ChangeSchema(u, ""))
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	switch _, err := c.InspectSchema(ctx, name, &schema.InspectOptions{Mode: schema.InspectSchemas}); {
+	case err == nil:
+		return nil
+	case !schema.IsNotExistError(err):
+		return fmt.Errorf("sql/sqlclient: inspect schema %q: %w", name, err)
+	}
+	if err := c.ApplyChanges(ctx, []schema.Change{
+		&schema.AddSchema{S: schema.New(name).AddAttrs(attrs...), Extra: []schema.Clause{&schema.IfNotExists{}}},
+	}); err != nil {
+		return fmt.Errorf("sql/sqlclient: create schema %q: %w", name, err)
+	}
+	return nil
+}
+
 // OpenWithHooks returns an OpenOption that sets
 // the hooks for the client after opening.
 func OpenWithHooks(hks ...*Hook) OpenOption {
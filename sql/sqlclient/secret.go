@@ -0,0 +1,181 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+type (
+	// SecretResolver resolves secret references in connection URLs. For example,
+	// a resolver registered with the "vault" scheme resolves the reference "db/pass"
+	// in the URL "mysql://root:${vault:db/pass}@localhost:3306".
+	SecretResolver interface {
+		ResolveSecret(ctx context.Context, ref string) (string, error)
+	}
+
+	// SecretResolverFunc allows using an ordinary function as a SecretResolver.
+	SecretResolverFunc func(context.Context, string) (string, error)
+)
+
+// ResolveSecret calls f(ctx, ref).
+func (f SecretResolverFunc) ResolveSecret(ctx context.Context, ref string) (string, error) {
+	return f(ctx, ref)
+}
+
+var resolvers sync.Map
+
+var (
+	// EnvSecrets resolves secret references to environment variables. It is not
+	// registered by default, and callers that open trusted URLs opt in to it with:
+	//
+	//	sqlclient.OpenWithSecretResolver("env", sqlclient.EnvSecrets)
+	EnvSecrets SecretResolver = SecretResolverFunc(func(_ context.Context, name string) (string, error) {
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %q is not set", name)
+		}
+		return v, nil
+	})
+
+	// FileSecrets resolves secret references to file contents. Like EnvSecrets,
+	// it is not registered by default.
+	FileSecrets SecretResolver = SecretResolverFunc(func(_ context.Context, path string) (string, error) {
+		buf, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		// Secret files are commonly terminated with a newline.
+		return strings.TrimRight(string(buf), "\r\n"), nil
+	})
+)
+
+// RegisterSecretResolver registers a SecretResolver for the given scheme. Registered
+// resolvers are used by ResolveURL, and by Open only if secret resolution was enabled
+// using the OpenWithSecrets or OpenWithSecretResolver options.
+//
+// Note, no resolvers are registered by default, as resolving references in URLs that
+// are not trusted (e.g., given by users of a service) may expose the environment or
+// the file system of the process.
+func RegisterSecretResolver(scheme string, r SecretResolver) {
+	if r == nil {
+		panic("sql/sqlclient: RegisterSecretResolver resolver is nil")
+	}
+	if _, ok := resolvers.LoadOrStore(scheme, r); ok {
+		panic("sql/sqlclient: RegisterSecretResolver called twice for " + scheme)
+	}
+}
+
+// OpenWithSecrets returns an OpenOption that enables resolving the secret references
+// of the URL given to Open with the registered resolvers. Without it, or without the
+// OpenWithSecretResolver option, Open does not resolve secret references.
+func OpenWithSecrets() OpenOption {
+	return func(c *openOptions) error {
+		c.secrets = true
+		return nil
+	}
+}
+
+// OpenWithSecretResolver returns an OpenOption that enables secret resolution and
+// resolves the secret references of the given scheme with r. It takes precedence
+// over the registered resolvers.
+func OpenWithSecretResolver(scheme string, r SecretResolver) OpenOption {
+	return func(c *openOptions) error {
+		if c.resolvers == nil {
+			c.resolvers = make(map[string]SecretResolver)
+		}
+		c.resolvers[scheme] = r
+		c.secrets = true
+		return nil
+	}
+}
+
+// reSecret matches secret references. e.g., ${env:DB_PASS}.
+var reSecret = regexp.MustCompile(`\$\{([a-zA-Z][a-zA-Z0-9+.-]*):([^}]+)}`)
+
+// ResolveURL replaces the secret references in the given URL with the values returned
+// by their resolvers. References are written as ${scheme:ref}, and the resolved values
+// are percent-encoded to keep the URL valid. For example:
+//
+//	mysql://root:${env:DB_PASS}@localhost:3306/app
+//	postgres://app:${file:/run/secrets/db}@db:5432/app
+//
+// Unlike Open, ResolveURL always resolves the references it is given, and no schemes
+// are resolved unless registered or passed using the OpenWithSecretResolver option.
+//
+// Note, errors do not include the resolved values to avoid leaking secrets.
+func ResolveURL(ctx context.Context, s string, opts ...OpenOption) (string, error) {
+	cfg := &openOptions{}
+	for _, opt := range opts {
+		if err := opt(cfg); err != nil {
+			return "", err
+		}
+	}
+	return cfg.resolveURL(ctx, s)
+}
+
+// resolveURL resolves the secret references in the given URL.
+func (c *openOptions) resolveURL(ctx context.Context, s string) (string, error) {
+	var (
+		errs []error
+		b    strings.Builder
+		last int
+	)
+	for _, m := range reSecret.FindAllStringSubmatchIndex(s, -1) {
+		scheme, ref := s[m[2]:m[3]], s[m[4]:m[5]]
+		r, ok := c.resolvers[scheme]
+		if !ok {
+			v, ok := resolvers.Load(scheme)
+			if !ok {
+				errs = append(errs, fmt.Errorf("sql/sqlclient: unknown secret resolver %q", scheme))
+				continue
+			}
+			r = v.(SecretResolver)
+		}
+		v, err := r.ResolveSecret(ctx, ref)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("sql/sqlclient: resolve %q secret: %w", scheme, err))
+			continue
+		}
+		b.WriteString(s[last:m[0]])
+		b.WriteString(strings.ReplaceAll(url.QueryEscape(v), "+", "%20"))
+		last = m[1]
+	}
+	if len(errs) > 0 {
+		return "", errors.Join(errs...)
+	}
+	b.WriteString(s[last:])
+	return b.String(), nil
+}
+
+// parseURL parses the given URL, after resolving its secret references if resolution
+// was enabled. Errors of resolved URLs are reported on their unresolved form, as the
+// errors returned by url.Parse may include parts of the resolved secrets.
+func (c *openOptions) parseURL(ctx context.Context, s string) (*url.URL, error) {
+	r := s
+	if c.secrets {
+		var err error
+		if r, err = c.resolveURL(ctx, s); err != nil {
+			return nil, err
+		}
+	}
+	u, err := ParseURL(r)
+	if err == nil {
+		return u, nil
+	}
+	if r != s {
+		if _, err = ParseURL(s); err == nil {
+			err = errors.New("invalid url after resolving secret references")
+		}
+	}
+	return nil, fmt.Errorf("sql/sqlclient: parse open url: %w", err)
+}
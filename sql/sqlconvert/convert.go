@@ -0,0 +1,453 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package sqlconvert provides an API for converting schema resources inspected
+// from one database dialect to their closest equivalent in another dialect.
+package sqlconvert
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"ariga.io/atlas/sql/mysql"
+	"ariga.io/atlas/sql/postgres"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlite"
+)
+
+type (
+	// Converter converts schema resources from one dialect to another.
+	Converter struct {
+		from, to string
+		types    map[string]TypeMapping
+	}
+
+	// Option allows configuring a Converter using functional arguments.
+	Option func(*Converter)
+
+	// Report describes the features that could not be converted as-is to the target
+	// dialect and were either downgraded to their closest equivalent or dropped.
+	Report struct {
+		Downgrades []*Downgrade
+	}
+
+	// Downgrade describes a feature that was downgraded or dropped.
+	Downgrade struct {
+		Object string // Qualified name of the object. e.g., "public.users.id".
+		Reason string // Human-readable reason.
+	}
+)
+
+// dialects holds the type formatters and parsers of the supported dialects.
+var dialects = map[string]struct {
+	format func(schema.Type) (string, error)
+	parse  func(string) (schema.Type, error)
+}{
+	mysql.DriverName:    {format: mysql.FormatType, parse: mysql.ParseType},
+	postgres.DriverName: {format: postgres.FormatType, parse: postgres.ParseType},
+	sqlite.DriverName:   {format: sqlite.FormatType, parse: sqlite.ParseType},
+}
+
+// New returns a new Converter from the source dialect to the target dialect.
+// The supported dialects are "mysql", "postgres" and "sqlite3".
+func New(from, to string, opts ...Option) (*Converter, error) {
+	if _, ok := dialects[from]; !ok {
+		return nil, fmt.Errorf("sql/sqlconvert: unsupported source dialect %q", from)
+	}
+	if _, ok := dialects[to]; !ok {
+		return nil, fmt.Errorf("sql/sqlconvert: unsupported target dialect %q", to)
+	}
+	c := &Converter{from: from, to: to, types: make(map[string]TypeMapping)}
+	for k, v := range TypeMappings[from][to] {
+		c.types[k] = v
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// WithTypes sets custom type mappings that take precedence over
+// the builtin ones. The keys are the source type names without
+// their arguments. For example:
+//
+//	WithTypes(map[string]TypeMapping{
+//		"tinyint": {T: "boolean"},
+//	})
+func WithTypes(m map[string]TypeMapping) Option {
+	return func(c *Converter) {
+		for k, v := range m {
+			c.types[strings.ToLower(k)] = v
+		}
+	}
+}
+
+// Convert is a shorthand for creating a Converter and calling its ConvertRealm method.
+func Convert(r *schema.Realm, from, to string, opts ...Option) (*schema.Realm, *Report, error) {
+	c, err := New(from, to, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c.ConvertRealm(r)
+}
+
+// ConvertRealm returns the closest equivalent of the given realm in the target dialect,
+// and a report of the features that were downgraded or dropped. The given realm is not
+// modified. Functions, procedures, triggers and dialect-specific objects are not converted.
+func (c *Converter) ConvertRealm(r *schema.Realm) (*schema.Realm, *Report, error) {
+	var (
+		report  = &Report{}
+		realm   = schema.NewRealm()
+		tables  = make(map[*schema.Table]*schema.Table)
+		columns = make(map[*schema.Column]*schema.Column)
+	)
+	for _, o := range r.Objects {
+		report.add(fmt.Sprintf("%T", o), "realm objects are not converted")
+	}
+	for _, s1 := range r.Schemas {
+		s2 := schema.New(s1.Name)
+		realm.AddSchemas(s2)
+		for _, a := range s1.Attrs {
+			if cm, ok := a.(*schema.Comment); ok && c.to != sqlite.DriverName {
+				s2.SetComment(cm.Text)
+			}
+		}
+		for _, t1 := range s1.Tables {
+			t2, err := c.table(s2, t1, columns, report)
+			if err != nil {
+				return nil, nil, err
+			}
+			tables[t1] = t2
+		}
+		for _, v1 := range s1.Views {
+			v2 := schema.NewView(v1.Name, v1.Def)
+			for _, c1 := range v1.Columns {
+				t, err := c.columnType(qualify(s1.Name, v1.Name, c1.Name), c1, s2, report)
+				if err != nil {
+					return nil, nil, err
+				}
+				v2.AddColumns(&schema.Column{Name: c1.Name, Type: t})
+			}
+			s2.AddViews(v2)
+			report.add(qualify(s1.Name, v1.Name), "view definition was copied as-is")
+		}
+		for _, f := range s1.Funcs {
+			report.add(qualify(s1.Name, f.Name), "functions are not converted")
+		}
+		for _, p := range s1.Procs {
+			report.add(qualify(s1.Name, p.Name), "procedures are not converted")
+		}
+		for _, o := range s1.Objects {
+			// Enum types are converted when used by columns.
+			if _, ok := o.(*schema.EnumType); !ok {
+				report.add(fmt.Sprintf("%s.%T", s1.Name, o), "schema objects are not converted")
+			}
+		}
+	}
+	// Foreign keys are converted after all tables were created.
+	for _, s1 := range r.Schemas {
+		for _, t1 := range s1.Tables {
+			c.foreignKeys(t1, tables, columns, report)
+		}
+	}
+	return realm, report, nil
+}
+
+// foreignKeys converts the foreign keys of the given table to the target dialect.
+func (*Converter) foreignKeys(t1 *schema.Table, tables map[*schema.Table]*schema.Table, columns map[*schema.Column]*schema.Column, report *Report) {
+	t2 := tables[t1]
+	for _, fk1 := range t1.ForeignKeys {
+		ref, ok := tables[fk1.RefTable]
+		if !ok {
+			report.add(qualify(t1.Schema.Name, t1.Name, fk1.Symbol), "referenced table was not found")
+			continue
+		}
+		fk2 := schema.NewForeignKey(fk1.Symbol).
+			SetTable(t2).
+			SetRefTable(ref).
+			SetOnUpdate(fk1.OnUpdate).
+			SetOnDelete(fk1.OnDelete)
+		for _, c1 := range fk1.Columns {
+			fk2.AddColumns(columns[c1])
+		}
+		for _, c1 := range fk1.RefColumns {
+			fk2.AddRefColumns(columns[c1])
+		}
+		t2.AddForeignKeys(fk2)
+	}
+}
+
+// table converts the given table to the target dialect.
+func (c *Converter) table(s *schema.Schema, t1 *schema.Table, columns map[*schema.Column]*schema.Column, report *Report) (*schema.Table, error) {
+	t2 := schema.NewTable(t1.Name)
+	s.AddTables(t2)
+	for _, a := range t1.Attrs {
+		switch a := a.(type) {
+		case *schema.Comment:
+			c.comment(qualify(s.Name, t1.Name), &t2.Attrs, a, report)
+		case *schema.Check:
+			t2.AddChecks(schema.NewCheck().SetName(a.Name).SetExpr(a.Expr))
+			report.add(qualify(s.Name, t1.Name, a.Name), "check expression was copied as-is")
+		case *mysql.CreateStmt, *sqlite.CreateStmt, *mysql.AutoIncrement:
+		case *mysql.Engine:
+			if !a.Default {
+				report.add(qualify(s.Name, t1.Name), fmt.Sprintf("engine %q was dropped", a.V))
+			}
+		default:
+			c.dropped(qualify(s.Name, t1.Name), a, report)
+		}
+	}
+	for _, c1 := range t1.Columns {
+		c2, err := c.column(s, t1, c1, report)
+		if err != nil {
+			return nil, err
+		}
+		columns[c1] = c2
+		t2.AddColumns(c2)
+	}
+	if t1.PrimaryKey != nil {
+		t2.SetPrimaryKey(c.index(s, t1, t1.PrimaryKey, columns, report))
+		// SQLite supports AUTOINCREMENT only on INTEGER PRIMARY KEY columns.
+		if c.to == sqlite.DriverName {
+			for _, c2 := range t2.Columns {
+				if slices.ContainsFunc(c2.Attrs, func(a schema.Attr) bool { _, ok := a.(*sqlite.AutoIncrement); return ok }) &&
+					(len(t2.PrimaryKey.Parts) != 1 || t2.PrimaryKey.Parts[0].C != c2) {
+					c2.Attrs = slices.DeleteFunc(c2.Attrs, func(a schema.Attr) bool { _, ok := a.(*sqlite.AutoIncrement); return ok })
+					report.add(qualify(s.Name, t1.Name, c2.Name), "auto-increment is supported only on single primary-key columns")
+				}
+			}
+		}
+	}
+	for _, idx := range t1.Indexes {
+		t2.AddIndexes(c.index(s, t1, idx, columns, report))
+	}
+	for _, tr := range t1.Triggers {
+		report.add(qualify(s.Name, t1.Name, tr.Name), "triggers are not converted")
+	}
+	return t2, nil
+}
+
+// column converts the given column to the target dialect.
+func (c *Converter) column(s *schema.Schema, t *schema.Table, c1 *schema.Column, report *Report) (*schema.Column, error) {
+	name := qualify(s.Name, t.Name, c1.Name)
+	typ, err := c.columnType(name, c1, s, report)
+	if err != nil {
+		return nil, err
+	}
+	c2 := &schema.Column{Name: c1.Name, Type: typ}
+	if c1.Default != nil {
+		c2.Default = c.defaultExpr(name, c1.Default, typ.Type, report)
+	}
+	autoinc := false
+	if _, ok := c1.Type.Type.(*postgres.SerialType); ok {
+		autoinc = true
+	}
+	for _, a := range c1.Attrs {
+		switch a := a.(type) {
+		case *mysql.AutoIncrement, *sqlite.AutoIncrement, *postgres.Identity:
+			autoinc = true
+		case *schema.Comment:
+			c.comment(name, &c2.Attrs, a, report)
+		case *schema.GeneratedExpr:
+			c2.SetGeneratedExpr(&schema.GeneratedExpr{Expr: a.Expr, Type: a.Type})
+			report.add(name, "generated expression was copied as-is")
+		case *schema.Charset, *schema.Collation:
+			// Character sets and collations are dialect-specific, and
+			// the target database defaults are used instead.
+		default:
+			c.dropped(name, a, report)
+		}
+	}
+	if autoinc {
+		switch c.to {
+		case mysql.DriverName:
+			c2.AddAttrs(&mysql.AutoIncrement{})
+		case postgres.DriverName:
+			c2.AddAttrs(&postgres.Identity{Generation: "BY DEFAULT"})
+		case sqlite.DriverName:
+			c2.AddAttrs(&sqlite.AutoIncrement{})
+		}
+	}
+	return c2, nil
+}
+
+// reArgs matches the arguments of a formatted type.
+var reArgs = regexp.MustCompile(`\(([^)]*)\)`)
+
+// columnType converts the column type to the target dialect.
+func (c *Converter) columnType(name string, c1 *schema.Column, s *schema.Schema, report *Report) (*schema.ColumnType, error) {
+	ct := &schema.ColumnType{Null: c1.Type.Null}
+	switch t := c1.Type.Type.(type) {
+	case *schema.EnumType:
+		ct.Type = c.enumType(name, t, s, report)
+		return ct, nil
+	case *postgres.SerialType:
+		// Serial types are converted to their underlying integer types.
+		switch t.T {
+		case postgres.TypeSmallSerial, postgres.TypeSerial2:
+			ct.Type = &schema.IntegerType{T: postgres.TypeSmallInt}
+		case postgres.TypeSerial, postgres.TypeSerial4:
+			ct.Type = &schema.IntegerType{T: postgres.TypeInteger}
+		default:
+			ct.Type = &schema.IntegerType{T: postgres.TypeBigInt}
+		}
+		c1 = &schema.Column{Type: &schema.ColumnType{Type: ct.Type}}
+	}
+	raw, err := dialects[c.from].format(c1.Type.Type)
+	if err != nil {
+		return nil, fmt.Errorf("sql/sqlconvert: format type of %q: %w", name, err)
+	}
+	raw = strings.ToLower(raw)
+	var (
+		args string
+		base = raw
+	)
+	if m := reArgs.FindStringSubmatch(raw); len(m) == 2 {
+		args, base = m[1], strings.Join(strings.Fields(reArgs.ReplaceAllString(raw, " ")), " ")
+	}
+	target := raw
+	switch m, ok := c.types[base]; {
+	case ok:
+		target = m.T
+		switch {
+		case m.Args && args != "":
+			target += "(" + args + ")"
+		case m.DefaultArgs != "":
+			target += "(" + m.DefaultArgs + ")"
+		}
+		if args != "" && !m.Args {
+			report.add(name, fmt.Sprintf("type %q was converted to %q", raw, target))
+		}
+	}
+	t, err := dialects[c.to].parse(target)
+	switch _, ok := t.(*schema.UnsupportedType); {
+	case err != nil || ok:
+		fallback := fallbackTypes[c.to]
+		report.add(name, fmt.Sprintf("type %q is not supported and was converted to %q", raw, fallback))
+		if t, err = dialects[c.to].parse(fallback); err != nil {
+			return nil, err
+		}
+	case target == raw:
+		if _, ok := c.types[base]; !ok {
+			report.add(name, fmt.Sprintf("type %q has no mapping and was copied as-is", raw))
+		}
+	}
+	ct.Type = t
+	return ct, nil
+}
+
+// enumType converts the given enum type to the target dialect.
+func (c *Converter) enumType(name string, e *schema.EnumType, s *schema.Schema, report *Report) schema.Type {
+	switch c.to {
+	case mysql.DriverName:
+		return &schema.EnumType{T: mysql.TypeEnum, Values: e.Values}
+	case postgres.DriverName:
+		// PostgreSQL enums are named types. Enums that were defined
+		// inline (e.g., MySQL) are named after their table and column.
+		tn := e.T
+		if e.Schema == nil || tn == "" || tn == mysql.TypeEnum {
+			tn = strings.ReplaceAll(strings.TrimPrefix(name, s.Name+"."), ".", "_")
+		}
+		for _, o := range s.Objects {
+			if e1, ok := o.(*schema.EnumType); ok && e1.T == tn {
+				return e1
+			}
+		}
+		e2 := &schema.EnumType{T: tn, Values: e.Values, Schema: s}
+		s.AddObjects(e2)
+		return e2
+	default:
+		report.add(name, "enum type was converted to text")
+		return &schema.StringType{T: "text"}
+	}
+}
+
+// portableDefaults lists the default expressions that are supported by all dialects.
+var portableDefaults = []string{"CURRENT_TIMESTAMP", "CURRENT_DATE", "CURRENT_TIME", "NULL"}
+
+// defaultExpr converts the given default expression to the target dialect.
+func (c *Converter) defaultExpr(name string, x schema.Expr, t schema.Type, report *Report) schema.Expr {
+	switch x := x.(type) {
+	case *schema.Literal:
+		if _, ok := t.(*schema.BoolType); ok && c.to == postgres.DriverName {
+			switch x.V {
+			case "0":
+				return &schema.Literal{V: "false"}
+			case "1":
+				return &schema.Literal{V: "true"}
+			}
+		}
+		return &schema.Literal{V: x.V}
+	case *schema.RawExpr:
+		if slices.Contains(portableDefaults, strings.ToUpper(strings.Trim(x.X, "()"))) {
+			return &schema.RawExpr{X: strings.Trim(x.X, "()")}
+		}
+	}
+	report.add(name, "default expression is not portable and was dropped")
+	return nil
+}
+
+// index converts the given index to the target dialect.
+func (c *Converter) index(s *schema.Schema, t *schema.Table, idx *schema.Index, columns map[*schema.Column]*schema.Column, report *Report) *schema.Index {
+	name := qualify(s.Name, t.Name, idx.Name)
+	idx2 := schema.NewIndex(idx.Name).SetUnique(idx.Unique)
+	for _, p := range idx.Parts {
+		p2 := &schema.IndexPart{SeqNo: p.SeqNo, Desc: p.Desc}
+		switch {
+		case p.C != nil:
+			p2.C = columns[p.C]
+		case p.X != nil:
+			p2.X = p.X
+			report.add(name, "index expression was copied as-is")
+		}
+		idx2.AddParts(p2)
+	}
+	for _, a := range idx.Attrs {
+		switch a := a.(type) {
+		case *schema.Comment:
+			c.comment(name, &idx2.Attrs, a, report)
+		case *mysql.IndexType:
+			if !strings.EqualFold(a.T, "BTREE") {
+				report.add(name, fmt.Sprintf("index type %q was dropped", a.T))
+			}
+		case *postgres.IndexType:
+			if !strings.EqualFold(a.T, "BTREE") {
+				report.add(name, fmt.Sprintf("index type %q was dropped", a.T))
+			}
+		default:
+			c.dropped(name, a, report)
+		}
+	}
+	return idx2
+}
+
+// comment adds the comment to the attributes, if supported by the target dialect.
+func (c *Converter) comment(name string, attrs *[]schema.Attr, cm *schema.Comment, report *Report) {
+	if c.to == sqlite.DriverName {
+		report.add(name, "comments are not supported")
+		return
+	}
+	*attrs = append(*attrs, &schema.Comment{Text: cm.Text})
+}
+
+// dropped reports the given attribute as dropped.
+func (*Converter) dropped(name string, a schema.Attr, report *Report) {
+	report.add(name, fmt.Sprintf("attribute %T was dropped", a))
+}
+
+func (r *Report) add(object, reason string) {
+	r.Downgrades = append(r.Downgrades, &Downgrade{Object: object, Reason: reason})
+}
+
+// String implements the fmt.Stringer interface.
+func (d *Downgrade) String() string {
+	return fmt.Sprintf("%s: %s", d.Object, d.Reason)
+}
+
+// qualify returns the qualified name of the given parts.
+func qualify(parts ...string) string {
+	return strings.Join(slices.DeleteFunc(parts, func(s string) bool { return s == "" }), ".")
+}
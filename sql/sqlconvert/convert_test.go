@@ -0,0 +1,132 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlconvert_test
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/mysql"
+	"ariga.io/atlas/sql/postgres"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlconvert"
+	"ariga.io/atlas/sql/sqlite"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	_, err := sqlconvert.New("oracle", postgres.DriverName)
+	require.EqualError(t, err, `sql/sqlconvert: unsupported source dialect "oracle"`)
+	_, err = sqlconvert.New(mysql.DriverName, "oracle")
+	require.EqualError(t, err, `sql/sqlconvert: unsupported target dialect "oracle"`)
+}
+
+func TestConvert_MySQLToPostgres(t *testing.T) {
+	var (
+		users = schema.NewTable("users").
+			AddColumns(
+				schema.NewIntColumn("id", "bigint").AddAttrs(&mysql.AutoIncrement{}),
+				schema.NewStringColumn("name", "varchar", schema.StringSize(255)).
+					AddAttrs(&schema.Charset{V: "utf8mb4"}),
+				schema.NewEnumColumn("status", schema.EnumValues("active", "blocked")),
+				schema.NewBoolColumn("admin", "bool").SetDefault(&schema.Literal{V: "0"}),
+				schema.NewTimeColumn("created_at", "timestamp").SetDefault(&schema.RawExpr{X: "CURRENT_TIMESTAMP"}),
+				schema.NewColumn("uuid").SetType(&schema.StringType{T: "char", Size: 36}).SetDefault(&schema.RawExpr{X: "(uuid())"}),
+			).
+			AddAttrs(&mysql.Engine{V: "InnoDB", Default: true}, &mysql.CreateStmt{S: "CREATE TABLE ..."})
+		posts = schema.NewTable("posts").
+			AddColumns(
+				schema.NewIntColumn("id", "int"),
+				schema.NewIntColumn("author_id", "bigint"),
+			)
+	)
+	users.SetPrimaryKey(schema.NewPrimaryKey(users.Columns[0]))
+	users.AddIndexes(schema.NewUniqueIndex("name").AddColumns(users.Columns[1]))
+	posts.AddForeignKeys(schema.NewForeignKey("author").AddColumns(posts.Columns[1]).SetRefTable(users).AddRefColumns(users.Columns[0]))
+	r := schema.NewRealm(schema.New("app").AddTables(users, posts))
+
+	c, err := sqlconvert.New(mysql.DriverName, postgres.DriverName)
+	require.NoError(t, err)
+	got, report, err := c.ConvertRealm(r)
+	require.NoError(t, err)
+	require.Len(t, got.Schemas, 1)
+	s := got.Schemas[0]
+	require.Len(t, s.Tables, 2)
+	require.Len(t, s.Objects, 1)
+	u, ok := s.Table("users")
+	require.True(t, ok)
+	require.Equal(t, &schema.IntegerType{T: postgres.TypeBigInt}, u.Columns[0].Type.Type)
+	require.Equal(t, []schema.Attr{&postgres.Identity{Generation: "BY DEFAULT"}}, u.Columns[0].Attrs)
+	require.Equal(t, &schema.StringType{T: postgres.TypeCharVar, Size: 255}, u.Columns[1].Type.Type)
+	require.Empty(t, u.Columns[1].Attrs)
+	require.Equal(t, &schema.EnumType{T: "users_status", Values: []string{"active", "blocked"}, Schema: s}, u.Columns[2].Type.Type)
+	require.Equal(t, s.Objects[0], u.Columns[2].Type.Type)
+	require.Equal(t, &schema.Literal{V: "false"}, u.Columns[3].Default)
+	require.Equal(t, &schema.RawExpr{X: "CURRENT_TIMESTAMP"}, u.Columns[4].Default)
+	require.Nil(t, u.Columns[5].Default)
+	require.Equal(t, u.Columns[0], u.PrimaryKey.Parts[0].C)
+	require.Equal(t, u.Columns[1], u.Indexes[0].Parts[0].C)
+	require.True(t, u.Indexes[0].Unique)
+	require.Empty(t, u.Attrs)
+	p, ok := s.Table("posts")
+	require.True(t, ok)
+	require.Len(t, p.ForeignKeys, 1)
+	require.Equal(t, u, p.ForeignKeys[0].RefTable)
+	require.Equal(t, u.Columns[0], p.ForeignKeys[0].RefColumns[0])
+	require.Equal(t, p.Columns[1], p.ForeignKeys[0].Columns[0])
+	require.Equal(t, []*sqlconvert.Downgrade{
+		{Object: "app.users.uuid", Reason: "default expression is not portable and was dropped"},
+	}, report.Downgrades)
+	// The source realm was not modified.
+	require.Equal(t, "bigint", r.Schemas[0].Tables[0].Columns[0].Type.Type.(*schema.IntegerType).T)
+}
+
+func TestConvert_PostgresToMySQL(t *testing.T) {
+	s := schema.New("public")
+	status := &schema.EnumType{T: "status", Values: []string{"on", "off"}, Schema: s}
+	s.AddObjects(status)
+	tbl := schema.NewTable("t").
+		AddColumns(
+			schema.NewColumn("id").SetType(&postgres.SerialType{T: postgres.TypeBigSerial}),
+			schema.NewEnumColumn("status", schema.EnumName("status"), schema.EnumValues("on", "off")),
+			schema.NewStringColumn("body", "text").SetComment("the body"),
+			schema.NewColumn("tags").SetType(&postgres.ArrayType{Type: &schema.StringType{T: "text"}, T: "text[]"}),
+		).
+		AddChecks(schema.NewCheck().SetName("body_len").SetExpr("length(body) > 0"))
+	tbl.Columns[1].Type.Type = status
+	s.AddTables(tbl)
+	got, report, err := sqlconvert.Convert(schema.NewRealm(s), postgres.DriverName, mysql.DriverName)
+	require.NoError(t, err)
+	c := got.Schemas[0].Tables[0].Columns
+	require.Equal(t, &schema.IntegerType{T: mysql.TypeBigInt}, c[0].Type.Type)
+	require.Equal(t, []schema.Attr{&mysql.AutoIncrement{}}, c[0].Attrs)
+	require.Equal(t, &schema.EnumType{T: mysql.TypeEnum, Values: []string{"on", "off"}}, c[1].Type.Type)
+	require.Equal(t, &schema.StringType{T: mysql.TypeLongText}, c[2].Type.Type)
+	require.Equal(t, []schema.Attr{&schema.Comment{Text: "the body"}}, c[2].Attrs)
+	require.Equal(t, &schema.StringType{T: mysql.TypeLongText}, c[3].Type.Type)
+	require.Empty(t, got.Schemas[0].Objects)
+	require.Equal(t, []*sqlconvert.Downgrade{
+		{Object: "public.t.body_len", Reason: "check expression was copied as-is"},
+		{Object: "public.t.tags", Reason: `type "text[]" is not supported and was converted to "longtext"`},
+	}, report.Downgrades)
+}
+
+func TestConvert_WithTypes(t *testing.T) {
+	r := schema.NewRealm(schema.New("main").AddTables(
+		schema.NewTable("t").AddColumns(
+			schema.NewIntColumn("id", "int").AddAttrs(&mysql.AutoIncrement{}),
+			schema.NewIntColumn("flag", "tinyint"),
+		),
+	))
+	r.Schemas[0].Tables[0].SetPrimaryKey(schema.NewPrimaryKey(r.Schemas[0].Tables[0].Columns[0]))
+	got, report, err := sqlconvert.Convert(r, mysql.DriverName, sqlite.DriverName, sqlconvert.WithTypes(map[string]sqlconvert.TypeMapping{
+		"tinyint": {T: "bool"},
+	}))
+	require.NoError(t, err)
+	require.Empty(t, report.Downgrades)
+	c := got.Schemas[0].Tables[0].Columns
+	require.Equal(t, []schema.Attr{&sqlite.AutoIncrement{}}, c[0].Attrs)
+	require.Equal(t, &schema.BoolType{T: "bool"}, c[1].Type.Type)
+}
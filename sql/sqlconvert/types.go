@@ -0,0 +1,189 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlconvert
+
+import (
+	"ariga.io/atlas/sql/mysql"
+	"ariga.io/atlas/sql/postgres"
+	"ariga.io/atlas/sql/sqlite"
+)
+
+// TypeMapping describes how a type of the source dialect is mapped to the target dialect.
+type TypeMapping struct {
+	// T is the type name in the target dialect.
+	T string
+	// Args indicates if the type arguments (e.g., size or precision)
+	// of the source type are kept.
+	Args bool
+	// DefaultArgs are used in case the source type has no
+	// arguments, but they are required by the target type.
+	DefaultArgs string
+}
+
+// TypeMappings holds the builtin type mapping tables between the supported dialects.
+// The tables are keyed by the source dialect, the target dialect and the source type
+// name without its arguments (e.g., "varchar" or "int unsigned").
+var TypeMappings = map[string]map[string]map[string]TypeMapping{
+	mysql.DriverName: {
+		postgres.DriverName: {
+			"tinyint":            {T: "smallint"},
+			"tinyint unsigned":   {T: "smallint"},
+			"smallint":           {T: "smallint"},
+			"smallint unsigned":  {T: "integer"},
+			"mediumint":          {T: "integer"},
+			"mediumint unsigned": {T: "integer"},
+			"int":                {T: "integer"},
+			"int unsigned":       {T: "bigint"},
+			"bigint":             {T: "bigint"},
+			"bigint unsigned":    {T: "numeric", DefaultArgs: "20"},
+			"bool":               {T: "boolean"},
+			"boolean":            {T: "boolean"},
+			"bit":                {T: "bit", Args: true},
+			"decimal":            {T: "numeric", Args: true},
+			"decimal unsigned":   {T: "numeric", Args: true},
+			"numeric":            {T: "numeric", Args: true},
+			"float":              {T: "real"},
+			"double":             {T: "double precision"},
+			"real":               {T: "double precision"},
+			"char":               {T: "character", Args: true},
+			"varchar":            {T: "character varying", Args: true},
+			"tinytext":           {T: "text"},
+			"text":               {T: "text"},
+			"mediumtext":         {T: "text"},
+			"longtext":           {T: "text"},
+			"binary":             {T: "bytea"},
+			"varbinary":          {T: "bytea"},
+			"tinyblob":           {T: "bytea"},
+			"blob":               {T: "bytea"},
+			"mediumblob":         {T: "bytea"},
+			"longblob":           {T: "bytea"},
+			"date":               {T: "date"},
+			"time":               {T: "time", Args: true},
+			"datetime":           {T: "timestamp", Args: true},
+			"timestamp":          {T: "timestamptz", Args: true},
+			"year":               {T: "smallint"},
+			"json":               {T: "jsonb"},
+			"inet4":              {T: "inet"},
+			"inet6":              {T: "inet"},
+			"uuid":               {T: "uuid"},
+		},
+		sqlite.DriverName: {
+			"tinyint":   {T: "integer"},
+			"smallint":  {T: "integer"},
+			"mediumint": {T: "integer"},
+			"int":       {T: "integer"},
+			"bigint":    {T: "integer"},
+			"bool":      {T: "bool"},
+			"boolean":   {T: "bool"},
+			"decimal":   {T: "decimal", Args: true},
+			"numeric":   {T: "numeric", Args: true},
+			"float":     {T: "real"},
+			"double":    {T: "real"},
+			"char":      {T: "text"},
+			"varchar":   {T: "text"},
+			"text":      {T: "text"},
+			"longtext":  {T: "text"},
+			"blob":      {T: "blob"},
+			"longblob":  {T: "blob"},
+			"date":      {T: "date"},
+			"datetime":  {T: "datetime"},
+			"timestamp": {T: "datetime"},
+			"json":      {T: "json"},
+		},
+	},
+	postgres.DriverName: {
+		mysql.DriverName: {
+			"smallint":                    {T: "smallint"},
+			"integer":                     {T: "int"},
+			"bigint":                      {T: "bigint"},
+			"boolean":                     {T: "bool"},
+			"bit":                         {T: "bit", Args: true},
+			"numeric":                     {T: "decimal", Args: true},
+			"real":                        {T: "float"},
+			"double precision":            {T: "double"},
+			"money":                       {T: "decimal", DefaultArgs: "19,2"},
+			"character":                   {T: "char", Args: true},
+			"character varying":           {T: "varchar", Args: true, DefaultArgs: "255"},
+			"text":                        {T: "longtext"},
+			"bytea":                       {T: "longblob"},
+			"date":                        {T: "date"},
+			"time":                        {T: "time", Args: true},
+			"time without time zone":      {T: "time", Args: true},
+			"timestamp":                   {T: "datetime", Args: true},
+			"timestamp without time zone": {T: "datetime", Args: true},
+			"timestamptz":                 {T: "timestamp", Args: true},
+			"timestamp with time zone":    {T: "timestamp", Args: true},
+			"json":                        {T: "json"},
+			"jsonb":                       {T: "json"},
+			"uuid":                        {T: "char", DefaultArgs: "36"},
+			"inet":                        {T: "varchar", DefaultArgs: "43"},
+			"cidr":                        {T: "varchar", DefaultArgs: "43"},
+			"macaddr":                     {T: "varchar", DefaultArgs: "17"},
+			"xml":                         {T: "longtext"},
+		},
+		sqlite.DriverName: {
+			"smallint":                    {T: "integer"},
+			"integer":                     {T: "integer"},
+			"bigint":                      {T: "integer"},
+			"boolean":                     {T: "bool"},
+			"numeric":                     {T: "numeric", Args: true},
+			"real":                        {T: "real"},
+			"double precision":            {T: "real"},
+			"character":                   {T: "text"},
+			"character varying":           {T: "text"},
+			"text":                        {T: "text"},
+			"bytea":                       {T: "blob"},
+			"date":                        {T: "date"},
+			"time":                        {T: "time"},
+			"timestamp":                   {T: "datetime"},
+			"timestamp without time zone": {T: "datetime"},
+			"timestamptz":                 {T: "datetime"},
+			"timestamp with time zone":    {T: "datetime"},
+			"json":                        {T: "json"},
+			"jsonb":                       {T: "json"},
+			"uuid":                        {T: "uuid"},
+		},
+	},
+	sqlite.DriverName: {
+		mysql.DriverName: {
+			"integer":  {T: "bigint"},
+			"int":      {T: "int"},
+			"bool":     {T: "bool"},
+			"boolean":  {T: "bool"},
+			"real":     {T: "double"},
+			"numeric":  {T: "decimal", Args: true},
+			"decimal":  {T: "decimal", Args: true},
+			"text":     {T: "longtext"},
+			"varchar":  {T: "varchar", Args: true, DefaultArgs: "255"},
+			"blob":     {T: "longblob"},
+			"date":     {T: "date"},
+			"datetime": {T: "datetime"},
+			"json":     {T: "json"},
+		},
+		postgres.DriverName: {
+			"integer":  {T: "bigint"},
+			"int":      {T: "integer"},
+			"bool":     {T: "boolean"},
+			"boolean":  {T: "boolean"},
+			"real":     {T: "double precision"},
+			"numeric":  {T: "numeric", Args: true},
+			"decimal":  {T: "numeric", Args: true},
+			"text":     {T: "text"},
+			"varchar":  {T: "character varying", Args: true},
+			"blob":     {T: "bytea"},
+			"date":     {T: "date"},
+			"datetime": {T: "timestamp"},
+			"json":     {T: "jsonb"},
+			"uuid":     {T: "uuid"},
+		},
+	},
+}
+
+// fallbackTypes holds the types used for types that cannot be converted.
+var fallbackTypes = map[string]string{
+	mysql.DriverName:    "longtext",
+	postgres.DriverName: "text",
+	sqlite.DriverName:   "text",
+}
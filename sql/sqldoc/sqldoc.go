@@ -0,0 +1,461 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package sqldoc generates documentation data from inspected schema realms, such as
+// a markdown page per table and a JSON index, allowing teams to publish an always
+// up-to-date data dictionary of their databases.
+package sqldoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	// Exporter exports the documentation of a realm.
+	Exporter struct {
+		fmt  schema.TypeFormatter
+		path func(*schema.Table) string
+	}
+
+	// Option allows configuring an Exporter using functional arguments.
+	Option func(*Exporter)
+
+	// FileWriter is the interface used for writing the generated files.
+	// For example, migrate.LocalDir or migrate.MemDir.
+	FileWriter interface {
+		WriteFile(string, []byte) error
+	}
+
+	// Index describes the documented realm. It is written as JSON to
+	// the index file and can be used for building navigation or search.
+	Index struct {
+		Server  *ServerDoc   `json:"server,omitempty"`
+		Schemas []*SchemaDoc `json:"schemas"`
+	}
+
+	// ServerDoc describes the database server the realm was inspected from.
+	ServerDoc struct {
+		Flavor    string `json:"flavor,omitempty"`
+		Version   string `json:"version,omitempty"`
+		Charset   string `json:"charset,omitempty"`
+		Collation string `json:"collation,omitempty"`
+		TimeZone  string `json:"timezone,omitempty"`
+	}
+
+	// SchemaDoc describes a documented schema.
+	SchemaDoc struct {
+		Name    string      `json:"name"`
+		Comment string      `json:"comment,omitempty"`
+		Tables  []*TableDoc `json:"tables"`
+	}
+
+	// TableDoc describes a documented table.
+	TableDoc struct {
+		Name         string         `json:"name"`
+		Schema       string         `json:"schema"`
+		Comment      string         `json:"comment,omitempty"`
+		Path         string         `json:"path"`
+		Columns      []*ColumnDoc   `json:"columns"`
+		PrimaryKey   []string       `json:"primary_key,omitempty"`
+		Indexes      []*IndexDoc    `json:"indexes,omitempty"`
+		ForeignKeys  []*FKDoc       `json:"foreign_keys,omitempty"`
+		Checks       []*CheckDoc    `json:"checks,omitempty"`
+		ReferencedBy []*TableRefDoc `json:"referenced_by,omitempty"`
+	}
+
+	// ColumnDoc describes a documented column.
+	ColumnDoc struct {
+		Name     string `json:"name"`
+		Type     string `json:"type"`
+		Nullable bool   `json:"nullable"`
+		Default  string `json:"default,omitempty"`
+		Comment  string `json:"comment,omitempty"`
+	}
+
+	// IndexDoc describes a documented index.
+	IndexDoc struct {
+		Name    string   `json:"name"`
+		Unique  bool     `json:"unique"`
+		Parts   []string `json:"parts"`
+		Comment string   `json:"comment,omitempty"`
+	}
+
+	// FKDoc describes a documented foreign key.
+	FKDoc struct {
+		Name       string   `json:"name"`
+		Columns    []string `json:"columns"`
+		RefSchema  string   `json:"ref_schema,omitempty"`
+		RefTable   string   `json:"ref_table"`
+		RefColumns []string `json:"ref_columns"`
+		RefPath    string   `json:"ref_path,omitempty"`
+		OnUpdate   string   `json:"on_update,omitempty"`
+		OnDelete   string   `json:"on_delete,omitempty"`
+	}
+
+	// CheckDoc describes a documented check constraint.
+	CheckDoc struct {
+		Name string `json:"name,omitempty"`
+		Expr string `json:"expr"`
+	}
+
+	// TableRefDoc describes a table that references the documented table.
+	TableRefDoc struct {
+		Schema     string `json:"schema"`
+		Table      string `json:"table"`
+		ForeignKey string `json:"foreign_key"`
+		Path       string `json:"path"`
+	}
+)
+
+// IndexFile is the name of the JSON index file written by Exporter.Write.
+const IndexFile = "index.json"
+
+// New returns a new Exporter configured with the given options.
+func New(opts ...Option) *Exporter {
+	e := &Exporter{path: TablePath}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// WithTypeFormatter configures the formatter used for printing column types that
+// were not inspected from a database, and therefore do not hold their raw form.
+// For example, the Driver of the dialect, or a TypeFormatter that wraps postgres.FormatType.
+func WithTypeFormatter(f schema.TypeFormatter) Option {
+	return func(e *Exporter) {
+		e.fmt = f
+	}
+}
+
+// WithTablePath configures the function that returns the path of the markdown page
+// of a table. By default, TablePath is used.
+func WithTablePath(f func(*schema.Table) string) Option {
+	return func(e *Exporter) {
+		e.path = f
+	}
+}
+
+// TablePath returns the default path of the markdown page of a table. For example,
+// "public.users.md". The page is written flat to the root of the output directory.
+func TablePath(t *schema.Table) string {
+	if t.Schema != nil && t.Schema.Name != "" {
+		return t.Schema.Name + "." + t.Name + ".md"
+	}
+	return t.Name + ".md"
+}
+
+// Write writes the markdown pages of all tables in the realm
+// and the JSON index file (IndexFile) to the given writer.
+func (e *Exporter) Write(w FileWriter, r *schema.Realm) error {
+	idx, err := e.Index(r)
+	if err != nil {
+		return err
+	}
+	for _, s := range idx.Schemas {
+		for _, t := range s.Tables {
+			if err := w.WriteFile(t.Path, []byte(Markdown(t))); err != nil {
+				return fmt.Errorf("sql/sqldoc: write table %q: %w", t.Name, err)
+			}
+		}
+	}
+	buf, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := w.WriteFile(IndexFile, append(buf, '\n')); err != nil {
+		return fmt.Errorf("sql/sqldoc: write index: %w", err)
+	}
+	return nil
+}
+
+// Index returns the documentation index of the given realm.
+func (e *Exporter) Index(r *schema.Realm) (*Index, error) {
+	var (
+		idx  = &Index{Schemas: make([]*SchemaDoc, 0, len(r.Schemas))}
+		docs = make(map[*schema.Table]*TableDoc)
+	)
+	if info, ok := r.ServerInfo(); ok {
+		idx.Server = &ServerDoc{
+			Flavor:    info.Flavor,
+			Version:   info.Version,
+			Charset:   info.Charset,
+			Collation: info.Collation,
+			TimeZone:  info.TimeZone,
+		}
+	}
+	for _, s := range r.Schemas {
+		sd := &SchemaDoc{Name: s.Name, Comment: comment(s.Attrs), Tables: make([]*TableDoc, 0, len(s.Tables))}
+		for _, t := range s.Tables {
+			td, err := e.table(t)
+			if err != nil {
+				return nil, err
+			}
+			docs[t] = td
+			sd.Tables = append(sd.Tables, td)
+		}
+		idx.Schemas = append(idx.Schemas, sd)
+	}
+	// Link the referenced tables back to the tables that reference them.
+	for _, s := range r.Schemas {
+		for _, t := range s.Tables {
+			for i, fk := range t.ForeignKeys {
+				if ref, ok := docs[refTable(r, fk)]; ok {
+					docs[t].ForeignKeys[i].RefPath = ref.Path
+					ref.ReferencedBy = append(ref.ReferencedBy, &TableRefDoc{
+						Schema:     docs[t].Schema,
+						Table:      t.Name,
+						ForeignKey: fk.Symbol,
+						Path:       docs[t].Path,
+					})
+				}
+			}
+		}
+	}
+	return idx, nil
+}
+
+// table returns the documentation of the given table.
+func (e *Exporter) table(t *schema.Table) (*TableDoc, error) {
+	td := &TableDoc{
+		Name:    t.Name,
+		Comment: comment(t.Attrs),
+		Path:    e.path(t),
+		Columns: make([]*ColumnDoc, 0, len(t.Columns)),
+	}
+	if t.Schema != nil {
+		td.Schema = t.Schema.Name
+	}
+	for _, c := range t.Columns {
+		typ, err := e.typ(c)
+		if err != nil {
+			return nil, fmt.Errorf("sql/sqldoc: format type of column %q.%q: %w", t.Name, c.Name, err)
+		}
+		td.Columns = append(td.Columns, &ColumnDoc{
+			Name:     c.Name,
+			Type:     typ,
+			Nullable: c.Type != nil && c.Type.Null,
+			Default:  exprString(c.Default),
+			Comment:  comment(c.Attrs),
+		})
+	}
+	if t.PrimaryKey != nil {
+		td.PrimaryKey = parts(t.PrimaryKey)
+	}
+	for _, idx := range t.Indexes {
+		td.Indexes = append(td.Indexes, &IndexDoc{
+			Name:    idx.Name,
+			Unique:  idx.Unique,
+			Parts:   parts(idx),
+			Comment: comment(idx.Attrs),
+		})
+	}
+	for _, fk := range t.ForeignKeys {
+		fd := &FKDoc{
+			Name:       fk.Symbol,
+			Columns:    columns(fk.Columns),
+			RefTable:   fk.RefTable.Name,
+			OnUpdate:   string(fk.OnUpdate),
+			OnDelete:   string(fk.OnDelete),
+			RefColumns: columns(fk.RefColumns),
+		}
+		if s := fk.RefTable.Schema; s != nil {
+			fd.RefSchema = s.Name
+		}
+		td.ForeignKeys = append(td.ForeignKeys, fd)
+	}
+	for _, a := range t.Attrs {
+		if c, ok := a.(*schema.Check); ok {
+			td.Checks = append(td.Checks, &CheckDoc{Name: c.Name, Expr: c.Expr})
+		}
+	}
+	return td, nil
+}
+
+// typ returns the printed type of the column.
+func (e *Exporter) typ(c *schema.Column) (string, error) {
+	switch {
+	case c.Type == nil || c.Type.Type == nil:
+		return "", nil
+	case c.Type.Raw != "":
+		return c.Type.Raw, nil
+	case e.fmt != nil:
+		return e.fmt.FormatType(c.Type.Type)
+	}
+	// Most types hold their name in the T field.
+	if v := reflect.Indirect(reflect.ValueOf(c.Type.Type)); v.Kind() == reflect.Struct {
+		if f := v.FieldByName("T"); f.IsValid() && f.Kind() == reflect.String && f.String() != "" {
+			return f.String(), nil
+		}
+	}
+	return fmt.Sprintf("%T", c.Type.Type), nil
+}
+
+// Markdown returns the markdown page of the given table documentation.
+func Markdown(t *TableDoc) string {
+	var b strings.Builder
+	name := t.Name
+	if t.Schema != "" {
+		name = t.Schema + "." + t.Name
+	}
+	fmt.Fprintf(&b, "# `%s`\n\n", name)
+	if t.Comment != "" {
+		fmt.Fprintf(&b, "%s\n\n", t.Comment)
+	}
+	b.WriteString("## Columns\n\n")
+	table(&b, []string{"Name", "Type", "Nullable", "Default", "Comment"}, len(t.Columns), func(i int) []string {
+		c := t.Columns[i]
+		return []string{code(c.Name), code(c.Type), fmt.Sprint(c.Nullable), code(c.Default), c.Comment}
+	})
+	if len(t.PrimaryKey) > 0 {
+		fmt.Fprintf(&b, "## Primary Key\n\n%s\n\n", codes(t.PrimaryKey))
+	}
+	if len(t.Indexes) > 0 {
+		b.WriteString("## Indexes\n\n")
+		table(&b, []string{"Name", "Unique", "Parts", "Comment"}, len(t.Indexes), func(i int) []string {
+			idx := t.Indexes[i]
+			return []string{code(idx.Name), fmt.Sprint(idx.Unique), codes(idx.Parts), idx.Comment}
+		})
+	}
+	if len(t.ForeignKeys) > 0 {
+		b.WriteString("## Foreign Keys\n\n")
+		table(&b, []string{"Name", "Columns", "References", "On Update", "On Delete"}, len(t.ForeignKeys), func(i int) []string {
+			fk := t.ForeignKeys[i]
+			ref := fk.RefTable
+			if fk.RefSchema != "" {
+				ref = fk.RefSchema + "." + ref
+			}
+			if fk.RefPath != "" {
+				ref = fmt.Sprintf("[%s](%s)", ref, fk.RefPath)
+			}
+			return []string{code(fk.Name), codes(fk.Columns), fmt.Sprintf("%s (%s)", ref, codes(fk.RefColumns)), fk.OnUpdate, fk.OnDelete}
+		})
+	}
+	if len(t.Checks) > 0 {
+		b.WriteString("## Checks\n\n")
+		table(&b, []string{"Name", "Expression"}, len(t.Checks), func(i int) []string {
+			return []string{code(t.Checks[i].Name), code(t.Checks[i].Expr)}
+		})
+	}
+	if len(t.ReferencedBy) > 0 {
+		b.WriteString("## Referenced By\n\n")
+		for _, r := range t.ReferencedBy {
+			name := r.Table
+			if r.Schema != "" {
+				name = r.Schema + "." + r.Table
+			}
+			fmt.Fprintf(&b, "- [%s](%s) (%s)\n", name, r.Path, code(r.ForeignKey))
+		}
+		b.WriteByte('\n')
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// table writes a markdown table with the given header and rows.
+func table(b *strings.Builder, header []string, n int, row func(int) []string) {
+	b.WriteString("| " + strings.Join(header, " | ") + " |\n")
+	b.WriteString("|" + strings.Repeat(" --- |", len(header)) + "\n")
+	for i := 0; i < n; i++ {
+		cells := row(i)
+		for j := range cells {
+			cells[j] = escape(cells[j])
+		}
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	b.WriteByte('\n')
+}
+
+// escape escapes the markdown table cell.
+func escape(s string) string {
+	return strings.NewReplacer("|", `\|`, "\r\n", " ", "\n", " ").Replace(s)
+}
+
+// code wraps the given string with backticks, if it is not empty.
+func code(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + s + "`"
+}
+
+// codes returns the given strings wrapped with backticks and separated by commas.
+func codes(s []string) string {
+	c := make([]string, len(s))
+	for i := range s {
+		c[i] = code(s[i])
+	}
+	return strings.Join(c, ", ")
+}
+
+// parts returns the printed parts of the given index.
+func parts(idx *schema.Index) []string {
+	ps := make([]string, 0, len(idx.Parts))
+	for _, p := range idx.Parts {
+		var s string
+		switch {
+		case p.C != nil:
+			s = p.C.Name
+		case p.X != nil:
+			s = exprString(p.X)
+		}
+		if p.Desc {
+			s += " DESC"
+		}
+		ps = append(ps, s)
+	}
+	return ps
+}
+
+// columns returns the names of the given columns.
+func columns(cs []*schema.Column) []string {
+	names := make([]string, len(cs))
+	for i := range cs {
+		names[i] = cs[i].Name
+	}
+	return names
+}
+
+// comment returns the comment stored in the attributes, if exists.
+func comment(attrs []schema.Attr) string {
+	for _, a := range attrs {
+		if c, ok := a.(*schema.Comment); ok {
+			return c.Text
+		}
+	}
+	return ""
+}
+
+// exprString returns the string representation of the given expression.
+func exprString(x schema.Expr) string {
+	switch x := schema.UnderlyingExpr(x).(type) {
+	case *schema.Literal:
+		return x.V
+	case *schema.RawExpr:
+		return x.X
+	default:
+		return ""
+	}
+}
+
+// refTable returns the table referenced by the foreign key. Foreign keys that were
+// not linked to their tables (e.g., stub tables) are resolved by their names.
+func refTable(r *schema.Realm, fk *schema.ForeignKey) *schema.Table {
+	ref := fk.RefTable
+	if ref.Schema == nil {
+		return ref
+	}
+	s, ok := r.Schema(ref.Schema.Name)
+	if !ok {
+		return ref
+	}
+	if t, ok := s.Table(ref.Name); ok {
+		return t
+	}
+	return ref
+}
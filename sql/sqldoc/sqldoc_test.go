@@ -0,0 +1,127 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqldoc_test
+
+import (
+	"encoding/json"
+	"io/fs"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqldoc"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExporter_Write(t *testing.T) {
+	var (
+		users = schema.NewTable("users").
+			SetComment("Registered users").
+			AddColumns(
+				schema.NewIntColumn("id", "bigint"),
+				schema.NewNullStringColumn("email", "varchar(255)").SetComment("Contact | login email"),
+				schema.NewTimeColumn("created_at", "timestamp").SetDefault(&schema.RawExpr{X: "CURRENT_TIMESTAMP"}),
+			)
+		posts = schema.NewTable("posts").
+			AddColumns(
+				schema.NewIntColumn("id", "bigint"),
+				schema.NewIntColumn("author_id", "bigint"),
+			)
+		r = schema.NewRealm(schema.New("public").SetComment("Application schema").AddTables(users, posts))
+	)
+	users.SetPrimaryKey(schema.NewPrimaryKey(users.Columns[0]))
+	users.AddIndexes(schema.NewUniqueIndex("users_email").AddColumns(users.Columns[1]))
+	users.AddChecks(schema.NewCheck().SetName("positive_id").SetExpr("id > 0"))
+	posts.SetPrimaryKey(schema.NewPrimaryKey(posts.Columns[0]))
+	posts.AddForeignKeys(
+		schema.NewForeignKey("author").
+			AddColumns(posts.Columns[1]).
+			SetRefTable(users).
+			AddRefColumns(users.Columns[0]).
+			SetOnDelete(schema.Cascade),
+	)
+
+	dir := &migrate.MemDir{}
+	require.NoError(t, sqldoc.New().Write(dir, r))
+	md, err := fs.ReadFile(dir, "public.users.md")
+	require.NoError(t, err)
+	require.Equal(t, "# `public.users`\n\n"+
+		"Registered users\n\n"+
+		"## Columns\n\n"+
+		"| Name | Type | Nullable | Default | Comment |\n"+
+		"| --- | --- | --- | --- | --- |\n"+
+		"| `id` | `bigint` | false |  |  |\n"+
+		"| `email` | `varchar(255)` | true |  | Contact \\| login email |\n"+
+		"| `created_at` | `timestamp` | false | `CURRENT_TIMESTAMP` |  |\n\n"+
+		"## Primary Key\n\n`id`\n\n"+
+		"## Indexes\n\n"+
+		"| Name | Unique | Parts | Comment |\n"+
+		"| --- | --- | --- | --- |\n"+
+		"| `users_email` | true | `email` |  |\n\n"+
+		"## Checks\n\n"+
+		"| Name | Expression |\n"+
+		"| --- | --- |\n"+
+		"| `positive_id` | `id > 0` |\n\n"+
+		"## Referenced By\n\n"+
+		"- [public.posts](public.posts.md) (`author`)\n", string(md))
+
+	buf, err := fs.ReadFile(dir, sqldoc.IndexFile)
+	require.NoError(t, err)
+	idx := &sqldoc.Index{}
+	require.NoError(t, json.Unmarshal(buf, idx))
+	require.Nil(t, idx.Server)
+	require.Len(t, idx.Schemas, 1)
+	require.Equal(t, "Application schema", idx.Schemas[0].Comment)
+	require.Len(t, idx.Schemas[0].Tables, 2)
+	fk := idx.Schemas[0].Tables[1].ForeignKeys[0]
+	require.Equal(t, &sqldoc.FKDoc{
+		Name:       "author",
+		Columns:    []string{"author_id"},
+		RefSchema:  "public",
+		RefTable:   "users",
+		RefColumns: []string{"id"},
+		RefPath:    "public.users.md",
+		OnDelete:   "CASCADE",
+	}, fk)
+}
+
+func TestExporter_TypeFormatter(t *testing.T) {
+	tbl := schema.NewTable("t").AddColumns(
+		schema.NewColumn("a").SetType(&schema.IntegerType{T: "int"}),
+		schema.NewColumn("b").SetType(&schema.DecimalType{T: "decimal", Precision: 10, Scale: 2}),
+	)
+	idx, err := sqldoc.New().Index(schema.NewRealm(schema.New("s").AddTables(tbl)))
+	require.NoError(t, err)
+	require.Equal(t, "int", idx.Schemas[0].Tables[0].Columns[0].Type)
+	require.Equal(t, "decimal", idx.Schemas[0].Tables[0].Columns[1].Type)
+
+	idx, err = sqldoc.New(sqldoc.WithTypeFormatter(formatter{})).Index(schema.NewRealm(schema.New("s").AddTables(tbl)))
+	require.NoError(t, err)
+	require.Equal(t, "T(int)", idx.Schemas[0].Tables[0].Columns[0].Type)
+	require.Equal(t, "s.t.md", idx.Schemas[0].Tables[0].Path)
+}
+
+func TestExporter_ServerInfo(t *testing.T) {
+	r := schema.NewRealm(schema.New("public")).SetServerInfo(&schema.ServerInfo{Flavor: "postgres", Version: "15.2", Charset: "UTF8", TimeZone: "UTC"})
+	dir := &migrate.MemDir{}
+	require.NoError(t, sqldoc.New().Write(dir, r))
+	buf, err := fs.ReadFile(dir, sqldoc.IndexFile)
+	require.NoError(t, err)
+	idx := &sqldoc.Index{}
+	require.NoError(t, json.Unmarshal(buf, idx))
+	require.Equal(t, &sqldoc.ServerDoc{Flavor: "postgres", Version: "15.2", Charset: "UTF8", TimeZone: "UTC"}, idx.Server)
+}
+
+type formatter struct{}
+
+func (formatter) FormatType(t schema.Type) (string, error) {
+	switch t := t.(type) {
+	case *schema.IntegerType:
+		return "T(" + t.T + ")", nil
+	default:
+		return "T", nil
+	}
+}
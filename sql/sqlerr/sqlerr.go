@@ -0,0 +1,108 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package sqlerr provides a classification of the errors returned by the
+// different drivers, to allow callers branching on the kind of an error
+// instead of parsing driver-specific error messages or codes. e.g.,
+//
+//	if errors.Is(err, sqlerr.NotExist) {
+//		// Object was not found in the database.
+//	}
+package sqlerr
+
+import (
+	"errors"
+	"fmt"
+)
+
+// A Kind describes the class of an error.
+type Kind uint
+
+// List of error kinds.
+const (
+	// Unknown is the kind of errors that were not classified.
+	Unknown Kind = iota
+	// NotExist indicates a database object (e.g., schema, table or column) does not exist.
+	NotExist
+	// Unsupported indicates a feature or a change is not supported by the database or the driver.
+	Unsupported
+	// Locked indicates a lock could not be acquired. e.g., lock wait timeouts or deadlocks.
+	Locked
+	// PermissionDenied indicates the user does not have the privileges to execute an operation.
+	PermissionDenied
+	// ConstraintViolation indicates a change violates a constraint of the database.
+	// e.g., unique, foreign key, not null or check constraints.
+	ConstraintViolation
+)
+
+// String implements the fmt.Stringer interface.
+func (k Kind) String() string {
+	switch k {
+	case NotExist:
+		return "not exist"
+	case Unsupported:
+		return "unsupported"
+	case Locked:
+		return "locked"
+	case PermissionDenied:
+		return "permission denied"
+	case ConstraintViolation:
+		return "constraint violation"
+	default:
+		return "unknown"
+	}
+}
+
+// Error implements the error interface. It allows using the kinds as
+// targets for errors.Is. e.g., errors.Is(err, sqlerr.Locked).
+func (k Kind) Error() string {
+	return "sql: " + k.String()
+}
+
+// Error wraps an error with its classification. The
+// original text of the error is retained.
+type Error struct {
+	Kind Kind
+	Err  error
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string { return e.Err.Error() }
+
+// Unwrap returns the wrapped error.
+func (e *Error) Unwrap() error { return e.Err }
+
+// Is reports if the error is of the given kind.
+func (e *Error) Is(target error) bool {
+	k, ok := target.(Kind)
+	return ok && k != Unknown && k == e.Kind
+}
+
+// Wrap wraps the given error with the given kind. Nil errors, errors of
+// Unknown kind and errors that are already classified are returned as-is.
+func Wrap(err error, k Kind) error {
+	if err == nil || k == Unknown || KindOf(err) != Unknown {
+		return err
+	}
+	return &Error{Kind: k, Err: err}
+}
+
+// Errorf formats according to a format specifier and returns
+// the result as an error of the given kind.
+func Errorf(k Kind, format string, a ...any) error {
+	return &Error{Kind: k, Err: fmt.Errorf(format, a...)}
+}
+
+// KindOf returns the kind of the given error, or
+// Unknown if the error was not classified.
+func KindOf(err error) Kind {
+	var k Kind
+	for _, k1 := range []Kind{NotExist, Unsupported, Locked, PermissionDenied, ConstraintViolation} {
+		if errors.Is(err, k1) {
+			k = k1
+			break
+		}
+	}
+	return k
+}
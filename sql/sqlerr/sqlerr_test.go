@@ -0,0 +1,46 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlerr_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlerr"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKindOf(t *testing.T) {
+	require.Nil(t, sqlerr.Wrap(nil, sqlerr.Locked))
+	err := errors.New("lock wait timeout exceeded")
+	require.Equal(t, err, sqlerr.Wrap(err, sqlerr.Unknown))
+	require.Equal(t, sqlerr.Unknown, sqlerr.KindOf(err))
+
+	locked := sqlerr.Wrap(err, sqlerr.Locked)
+	require.EqualError(t, locked, err.Error())
+	require.Equal(t, sqlerr.Locked, sqlerr.KindOf(locked))
+	require.ErrorIs(t, locked, sqlerr.Locked)
+	require.ErrorIs(t, locked, err)
+	require.NotErrorIs(t, locked, sqlerr.NotExist)
+	require.Equal(t, locked, sqlerr.Wrap(locked, sqlerr.NotExist), "classified errors are not re-wrapped")
+
+	wrapped := fmt.Errorf("apply migration: %w", locked)
+	require.Equal(t, sqlerr.Locked, sqlerr.KindOf(wrapped))
+	var e *sqlerr.Error
+	require.ErrorAs(t, wrapped, &e)
+	require.Equal(t, err, e.Err)
+
+	err = sqlerr.Errorf(sqlerr.Unsupported, "unsupported change %T", &schema.AddView{})
+	require.EqualError(t, err, "unsupported change *schema.AddView")
+	require.ErrorIs(t, err, sqlerr.Unsupported)
+
+	// Errors defined by the schema package.
+	require.Equal(t, sqlerr.NotExist, sqlerr.KindOf(&schema.NotExistError{Err: errors.New("table was not found")}))
+	require.Equal(t, sqlerr.Locked, sqlerr.KindOf(fmt.Errorf("acquire lock: %w", schema.ErrLocked)))
+	require.Equal(t, "sql: permission denied", sqlerr.PermissionDenied.Error())
+}
@@ -9,9 +9,35 @@ import (
 	"strconv"
 	"strings"
 
+	"ariga.io/atlas/sql/internal/sqlx"
 	"ariga.io/atlas/sql/schema"
 )
 
+// literals defines how Go values are formatted as SQLite literals.
+var literals = &sqlx.LiteralFormat{
+	String: QuoteString,
+	Bytes:  sqlx.HexBytes,
+	Bool: func(b bool) string {
+		if b {
+			return "1"
+		}
+		return "0"
+	},
+	TimeLayout: "2006-01-02 15:04:05.999999999-07:00",
+}
+
+// QuoteString quotes the given string as an SQLite string literal.
+func QuoteString(s string) string {
+	return sqlx.SingleQuoteEscape(s)
+}
+
+// FormatLiteral formats the given Go value as an SQLite literal. Booleans are
+// formatted as integers, binary strings as BLOB literals, and JSON documents
+// (e.g., maps) as string literals. Arrays are not supported.
+func FormatLiteral(v any) (string, error) {
+	return literals.Format(v)
+}
+
 // FormatType converts types to one format. A lowered format.
 // This is due to SQLite flexibility to allow any data types
 // and use a set of rules to define the type affinity.
@@ -29,12 +55,22 @@ func FormatType(t schema.Type) (string, error) {
 		f = strings.ToLower(t.T)
 	case *schema.StringType:
 		f = strings.ToLower(t.T)
+		// Sizes are not enforced by SQLite, but they are
+		// kept in order to not lose information on round-trip.
+		if t.Size > 0 {
+			f = fmt.Sprintf("%s(%d)", f, t.Size)
+		}
 	case *schema.TimeType:
 		f = strings.ToLower(t.T)
 	case *schema.FloatType:
 		f = strings.ToLower(t.T)
 	case *schema.DecimalType:
-		f = strings.ToLower(t.T)
+		switch f = strings.ToLower(t.T); {
+		case t.Precision > 0 && t.Scale > 0:
+			f = fmt.Sprintf("%s(%d,%d)", f, t.Precision, t.Scale)
+		case t.Precision > 0:
+			f = fmt.Sprintf("%s(%d)", f, t.Precision)
+		}
 	case *schema.JSONType:
 		f = strings.ToLower(t.T)
 	case *schema.SpatialType:
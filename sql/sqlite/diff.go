@@ -55,7 +55,7 @@ func (d *diff) TableAttrDiff(from, to *schema.Table, opts *schema.DiffOptions) (
 			})
 		}
 	}
-	return append(changes, sqlx.CheckDiffMode(from, to, opts.Mode)...), nil
+	return append(changes, sqlx.CheckDiffMode(from, to, opts.Mode, exprNormalizer)...), nil
 }
 
 func (*diff) ViewAttrChanges(_, _ *schema.View) []schema.Change {
@@ -116,18 +116,34 @@ func (d *diff) defaultChanged(from, to *schema.Column) bool {
 	if d1 == d2 {
 		return false
 	}
+	_, fromX := from.Default.(*schema.RawExpr)
+	_, toX := to.Default.(*schema.RawExpr)
+	if fromX && toX {
+		return !exprNormalizer.Equal(d1, d2)
+	}
 	x1, err1 := sqlx.Unquote(d1)
 	x2, err2 := sqlx.Unquote(d2)
 	return err1 != nil || err2 != nil || x1 != x2
 }
 
+// exprNormalizer normalizes expressions before they are compared.
+// SQLite accepts both double quotes and backticks for identifiers.
+var exprNormalizer = &sqlx.ExprNormalizer{
+	IdentQuotes: "\"`",
+}
+
+// ExprEqual implements the sqlx.ExprComparer interface.
+func (*diff) ExprEqual(x1, x2 string) bool {
+	return exprNormalizer.Equal(x1, x2)
+}
+
 // generatedChanged reports if the generated expression of a column was changed.
 func (*diff) generatedChanged(from, to *schema.Column) bool {
 	var (
 		fromX, toX     schema.GeneratedExpr
 		fromHas, toHas = sqlx.Has(from.Attrs, &fromX), sqlx.Has(to.Attrs, &toX)
 	)
-	return fromHas != toHas || fromHas && (sqlx.MayWrap(fromX.Expr) != sqlx.MayWrap(toX.Expr) || storedOrVirtual(fromX.Type) != storedOrVirtual(toX.Type))
+	return fromHas != toHas || fromHas && (!exprNormalizer.Equal(fromX.Expr, toX.Expr) || storedOrVirtual(fromX.Type) != storedOrVirtual(toX.Type))
 }
 
 // IsGeneratedIndexName reports if the index name was generated by the database.
@@ -156,7 +172,7 @@ func (d *diff) FindGeneratedIndex(t *schema.Table, idx *schema.Index) (*schema.I
 // IndexAttrChanged reports if the index attributes were changed.
 func (*diff) IndexAttrChanged(from, to []schema.Attr) bool {
 	var p1, p2 IndexPredicate
-	return sqlx.Has(from, &p1) != sqlx.Has(to, &p2) || (p1.P != p2.P && p1.P != sqlx.MayWrap(p2.P))
+	return sqlx.Has(from, &p1) != sqlx.Has(to, &p2) || (p1.P != p2.P && !exprNormalizer.Equal(p1.P, p2.P))
 }
 
 // IndexPartAttrChanged reports if the index-part attributes were changed.
@@ -112,11 +112,11 @@ func opener(_ context.Context, u *url.URL) (*sqlclient.Client, error) {
 
 // Open opens a new SQLite driver.
 func Open(db schema.ExecQuerier) (migrate.Driver, error) {
-	c := &conn{ExecQuerier: db}
+	c := &conn{ExecQuerier: sqlx.WithErrorKind(db, errorKind)}
 	return &Driver{
 		conn:        c,
 		Differ:      &sqlx.Diff{DiffDriver: &diff{}},
-		Inspector:   &inspect{c},
+		Inspector:   &sqlx.PartialInspect{Inspector: &inspect{c}},
 		PlanApplier: &planApply{c},
 	}, nil
 }
@@ -164,6 +164,11 @@ func (d *Driver) CheckClean(ctx context.Context, revT *migrate.TableIdent) error
 	return nil
 }
 
+// DependsOn implements migrate.ChangeDepender.
+func (*Driver) DependsOn(change, other schema.Change) bool {
+	return sqlx.DependsOn(change, other, nil)
+}
+
 // Lock implements the schema.Locker interface.
 func (d *Driver) Lock(_ context.Context, name string, timeout time.Duration) (schema.UnlockFunc, error) {
 	// If the URL was set and the database is a file, use its name in the lock file.
@@ -195,6 +200,12 @@ func (*Driver) FormatType(t schema.Type) (string, error) {
 	return FormatType(t)
 }
 
+// FormatLiteral formats the given Go value as an SQLite literal.
+// It implements the migrate.LiteralFormatter interface.
+func (*Driver) FormatLiteral(v any) (string, error) {
+	return FormatLiteral(v)
+}
+
 // ParseType returns the schema.Type value represented by the given string.
 func (*Driver) ParseType(s string) (schema.Type, error) {
 	return ParseType(s)
@@ -207,6 +218,7 @@ func (*Driver) StmtBuilder(opts migrate.PlanOptions) *sqlx.Builder {
 		QuoteClosing: '`',
 		Schema:       opts.SchemaQualifier,
 		Indent:       opts.Indent,
+		Quoting:      opts.Quoting,
 	}
 }
 
@@ -58,7 +58,8 @@ func (i *inspect) InspectRealm(ctx context.Context, opts *schema.InspectRealmOpt
 			return nil, err
 		}
 	}
-	return schema.ExcludeRealm(r, opts.Exclude)
+	schema.SortRealm(r)
+	return schema.ExcludeRealm(r, opts.Excluded())
 }
 
 // InspectSchema returns schema descriptions of the tables in the given schema.
@@ -108,7 +109,8 @@ func (i *inspect) InspectSchema(ctx context.Context, name string, opts *schema.I
 			return nil, err
 		}
 	}
-	return schema.ExcludeSchema(r.Schemas[0], opts.Exclude)
+	schema.SortRealm(r)
+	return schema.ExcludeSchema(r.Schemas[0], opts.Excluded())
 }
 
 var (
@@ -161,9 +163,12 @@ func verifyChanges(context.Context, []schema.Change) error {
 
 // SupportChange reports if the change is supported by the differ.
 func (*diff) SupportChange(c schema.Change) bool {
-	switch c.(type) {
+	switch c := c.(type) {
 	case *schema.RenameConstraint:
 		return false
+	case *schema.ModifyColumn:
+		// SQLite does not support changing the column position.
+		return !c.Change.Is(schema.ChangePosition)
 	}
 	return true
 }
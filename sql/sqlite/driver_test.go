@@ -7,6 +7,7 @@ package sqlite
 import (
 	"context"
 	"database/sql/driver"
+	"errors"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -15,6 +16,7 @@ import (
 
 	"ariga.io/atlas/sql/migrate"
 	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlerr"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/require"
 )
@@ -65,6 +67,26 @@ func TestDriver_LockAcquired(t *testing.T) {
 	_, err = drv.Lock(context.Background(), "another", time.Second)
 }
 
+func TestDriver_ErrorKind(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	drv, err := Open(db)
+	require.NoError(t, err)
+	for msg, kind := range map[string]sqlerr.Kind{
+		"no such table: users":                     sqlerr.NotExist,
+		"UNIQUE constraint failed: users.id":       sqlerr.ConstraintViolation,
+		"database is locked (5) (SQLITE_BUSY)":     sqlerr.Locked,
+		"attempt to write a readonly database (8)": sqlerr.PermissionDenied,
+		`near "TABLE": syntax error (1)`:           sqlerr.Unknown,
+	} {
+		m.ExpectExec("INSERT INTO users").WillReturnError(errors.New(msg))
+		_, err := drv.ExecContext(context.Background(), "INSERT INTO users VALUES (1)")
+		require.EqualError(t, err, msg)
+		require.Equal(t, kind, sqlerr.KindOf(err))
+	}
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
 func TestDriver_CheckClean(t *testing.T) {
 	var (
 		r   = schema.NewRealm()
@@ -99,3 +121,24 @@ type mockInspector struct {
 func (m *mockInspector) InspectRealm(context.Context, *schema.InspectRealmOption) (*schema.Realm, error) {
 	return m.realm, nil
 }
+
+func TestDriver_FormatLiteral(t *testing.T) {
+	var drv migrate.LiteralFormatter = &Driver{}
+	for _, tt := range []struct {
+		v      any
+		expect string
+	}{
+		{v: `it's \n`, expect: `'it''s \n'`},
+		{v: []byte{0xde, 0xad}, expect: "X'DEAD'"},
+		{v: true, expect: "1"},
+		{v: false, expect: "0"},
+		{v: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), expect: "'2024-01-02 03:04:05+00:00'"},
+		{v: 42, expect: "42"},
+	} {
+		got, err := drv.FormatLiteral(tt.v)
+		require.NoError(t, err)
+		require.Equal(t, tt.expect, got)
+	}
+	_, err := drv.FormatLiteral([]int{1})
+	require.Error(t, err)
+}
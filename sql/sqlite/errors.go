@@ -0,0 +1,31 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlite
+
+import (
+	"strings"
+
+	"ariga.io/atlas/sql/sqlerr"
+)
+
+// errorKind classifies the errors returned by SQLite based on their message, as
+// the different drivers do not share a common type for exposing the error codes.
+func errorKind(err error) sqlerr.Kind {
+	if err == nil {
+		return sqlerr.Unknown
+	}
+	switch msg := strings.ToLower(err.Error()); {
+	case strings.Contains(msg, "no such "):
+		return sqlerr.NotExist
+	case strings.Contains(msg, "constraint failed"):
+		return sqlerr.ConstraintViolation
+	case strings.Contains(msg, "database is locked"), strings.Contains(msg, "database table is locked"):
+		return sqlerr.Locked
+	case strings.Contains(msg, "not authorized"), strings.Contains(msg, "readonly database"):
+		return sqlerr.PermissionDenied
+	default:
+		return sqlerr.Unknown
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"ariga.io/atlas/sql/internal/sqlx"
 	"ariga.io/atlas/sql/migrate"
 	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlerr"
 )
 
 // DefaultPlan provides basic planning capabilities for SQLite dialects.
@@ -40,6 +41,7 @@ func (p *planApply) PlanChanges(ctx context.Context, name string, changes []sche
 	for _, o := range opts {
 		o(&s.PlanOptions)
 	}
+	changes = sqlx.SkipVirtualFKs(changes)
 	if err := verifyChanges(ctx, changes); err != nil {
 		return nil, err
 	}
@@ -84,6 +86,10 @@ func (s *state) plan(ctx context.Context, changes []schema.Change) (err error) {
 		switch c := c.(type) {
 		case *schema.AddTable:
 			err = s.addTable(ctx, c)
+		case *schema.CloneTable:
+			// SQLite does not support copying the table structure using
+			// 'CREATE TABLE ... LIKE'. Therefore, it is created explicitly.
+			err = s.addTable(ctx, &schema.AddTable{T: c.T, Extra: c.Extra})
 		case *schema.DropTable:
 			err = s.dropTable(ctx, c)
 		case *schema.ModifyTable:
@@ -103,7 +109,7 @@ func (s *state) plan(ctx context.Context, changes []schema.Change) (err error) {
 		case *schema.DropTrigger:
 			err = s.dropTrigger(c)
 		default:
-			err = fmt.Errorf("unsupported change %T", c)
+			err = sqlerr.Errorf(sqlerr.Unsupported, "unsupported change %T", c)
 		}
 		if err != nil {
 			return err
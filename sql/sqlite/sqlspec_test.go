@@ -526,3 +526,27 @@ schema "test" {
 func TestInputVars(t *testing.T) {
 	spectest.TestInputVars(t, EvalHCL)
 }
+
+func TestFormatType_RoundTrip(t *testing.T) {
+	for _, typ := range []string{
+		"decimal",
+		"decimal(10)",
+		"numeric(10,2)",
+		"varchar(255)",
+		"character(5)",
+		"text",
+		"integer",
+		"datetime",
+	} {
+		t.Run(typ, func(t *testing.T) {
+			t1, err := ParseType(typ)
+			require.NoError(t, err)
+			f, err := FormatType(t1)
+			require.NoError(t, err)
+			require.Equal(t, typ, f)
+			t2, err := ParseType(f)
+			require.NoError(t, err)
+			require.Equal(t, t1, t2)
+		})
+	}
+}
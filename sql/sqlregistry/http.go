@@ -0,0 +1,203 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlregistry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"ariga.io/atlas/sql/migrate"
+)
+
+type (
+	// HTTPClient is a Client for registries that expose the following HTTP API. Request
+	// and response bodies are JSON encoded, and a 404 status is reported as ErrNotFound.
+	//
+	//	POST {base}/{name}/{kind}           push a new version. Body: {"content": ..., "format": ..., "tags": [...]}
+	//	GET  {base}/{name}/{kind}           list the versions of an artifact.
+	//	GET  {base}/{name}/{kind}/{ref}     pull a version by its version or tag.
+	//	PUT  {base}/{name}/{kind}/tags/{tag} tag a version. Body: {"version": ...}
+	//
+	// Migration directories are transferred as tar archives (see migrate.ArchiveDir).
+	HTTPClient struct {
+		base   string
+		client *http.Client
+		header http.Header
+	}
+
+	// HTTPOption configures an HTTPClient.
+	HTTPOption func(*HTTPClient)
+
+	// pushBody is the body of push requests.
+	pushBody struct {
+		Format  string   `json:"format,omitempty"`
+		Content []byte   `json:"content"`
+		Tags    []string `json:"tags,omitempty"`
+	}
+
+	// pullBody is the body of pull responses.
+	pullBody struct {
+		Format  string   `json:"format,omitempty"`
+		Content []byte   `json:"content"`
+		Version *Version `json:"version"`
+	}
+)
+
+// NewHTTPClient returns a new HTTPClient for the registry in the given base URL.
+func NewHTTPClient(base string, opts ...HTTPOption) (*HTTPClient, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return nil, fmt.Errorf("sql/sqlregistry: parse base url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("sql/sqlregistry: unsupported url scheme %q", u.Scheme)
+	}
+	c := &HTTPClient{
+		base:   strings.TrimSuffix(u.String(), "/"),
+		client: http.DefaultClient,
+		header: make(http.Header),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// WithToken sets the bearer token used for authenticating the requests.
+func WithToken(token string) HTTPOption {
+	return func(c *HTTPClient) {
+		c.header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// WithHeader sets an additional header that is sent with the requests.
+func WithHeader(key, value string) HTTPOption {
+	return func(c *HTTPClient) {
+		c.header.Set(key, value)
+	}
+}
+
+// WithHTTPClient sets the underlying http.Client used for sending the requests.
+func WithHTTPClient(hc *http.Client) HTTPOption {
+	return func(c *HTTPClient) {
+		c.client = hc
+	}
+}
+
+// PushState implements the Client interface.
+func (c *HTTPClient) PushState(ctx context.Context, name string, s *State, tags ...string) (*Version, error) {
+	var v Version
+	if err := c.do(ctx, http.MethodPost, c.path(name, KindState), &pushBody{Format: s.Format, Content: s.Content, Tags: tags}, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// PullState implements the Client interface.
+func (c *HTTPClient) PullState(ctx context.Context, name, ref string) (*State, error) {
+	var b pullBody
+	if err := c.do(ctx, http.MethodGet, c.path(name, KindState, ref), nil, &b); err != nil {
+		return nil, err
+	}
+	return &State{Format: b.Format, Content: b.Content, Version: b.Version}, nil
+}
+
+// PushDir implements the Client interface.
+func (c *HTTPClient) PushDir(ctx context.Context, name string, dir migrate.Dir, tags ...string) (*Version, error) {
+	arc, err := migrate.ArchiveDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("sql/sqlregistry: archive dir: %w", err)
+	}
+	var v Version
+	if err := c.do(ctx, http.MethodPost, c.path(name, KindDir), &pushBody{Content: arc, Tags: tags}, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// PullDir implements the Client interface.
+func (c *HTTPClient) PullDir(ctx context.Context, name, ref string) (migrate.Dir, error) {
+	var b pullBody
+	if err := c.do(ctx, http.MethodGet, c.path(name, KindDir, ref), nil, &b); err != nil {
+		return nil, err
+	}
+	dir, err := migrate.UnarchiveDir(b.Content)
+	if err != nil {
+		return nil, fmt.Errorf("sql/sqlregistry: unarchive dir %s@%s: %w", name, ref, err)
+	}
+	return dir, nil
+}
+
+// Tag implements the Client interface.
+func (c *HTTPClient) Tag(ctx context.Context, kind Kind, name, version, tag string) error {
+	return c.do(ctx, http.MethodPut, c.path(name, kind, "tags", tag), struct {
+		Version string `json:"version"`
+	}{Version: version}, nil)
+}
+
+// Versions implements the Client interface.
+func (c *HTTPClient) Versions(ctx context.Context, kind Kind, name string) ([]*Version, error) {
+	var vs []*Version
+	if err := c.do(ctx, http.MethodGet, c.path(name, kind), nil, &vs); err != nil {
+		return nil, err
+	}
+	return vs, nil
+}
+
+// path returns the URL of the given path elements.
+func (c *HTTPClient) path(name string, kind Kind, elems ...string) string {
+	p := []string{c.base, url.PathEscape(name), string(kind)}
+	for _, e := range elems {
+		p = append(p, url.PathEscape(e))
+	}
+	return strings.Join(p, "/")
+}
+
+// do sends a request with the given body and decodes the response into v.
+func (c *HTTPClient) do(ctx context.Context, method, u string, body, v any) error {
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, r)
+	if err != nil {
+		return err
+	}
+	for k, vs := range c.header {
+		req.Header[k] = vs
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	res, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sql/sqlregistry: %s %s: %w", method, u, err)
+	}
+	defer res.Body.Close()
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%w: %s", ErrNotFound, strings.TrimPrefix(u, c.base+"/"))
+	case res.StatusCode < 200 || res.StatusCode > 299:
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1<<10))
+		return fmt.Errorf("sql/sqlregistry: %s %s: unexpected status %d: %s", method, u, res.StatusCode, bytes.TrimSpace(msg))
+	case v == nil:
+		return nil
+	}
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		return fmt.Errorf("sql/sqlregistry: decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlregistry_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlregistry"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPClient(t *testing.T) {
+	srv := httptest.NewServer(newRegistry(t))
+	defer srv.Close()
+	_, err := sqlregistry.NewHTTPClient("ftp://localhost")
+	require.EqualError(t, err, `sql/sqlregistry: unsupported url scheme "ftp"`)
+	c, err := sqlregistry.NewHTTPClient(srv.URL+"/", sqlregistry.WithToken("secret"))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// States.
+	v, err := c.PushState(ctx, "users", &sqlregistry.State{Format: "sql", Content: []byte("CREATE TABLE t(c int);")}, "v1")
+	require.NoError(t, err)
+	require.Equal(t, "1", v.Version)
+	require.Equal(t, []string{"v1", sqlregistry.TagLatest}, v.Tags)
+	_, err = c.PushState(ctx, "users", &sqlregistry.State{Format: "sql", Content: []byte("CREATE TABLE t(c bigint);")})
+	require.NoError(t, err)
+	s, err := c.PullState(ctx, "users", "v1")
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE t(c int);", string(s.Content))
+	require.Equal(t, "sql", s.Format)
+	s, err = c.PullState(ctx, "users", sqlregistry.TagLatest)
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE t(c bigint);", string(s.Content))
+	require.Equal(t, "2", s.Version.Version)
+	_, err = c.PullState(ctx, "users", "v2")
+	require.True(t, errors.Is(err, sqlregistry.ErrNotFound))
+	require.EqualError(t, err, "sql/sqlregistry: not found: users/states/v2")
+	require.NoError(t, c.Tag(ctx, sqlregistry.KindState, "users", "2", "v2"))
+	s, err = c.PullState(ctx, "users", "v2")
+	require.NoError(t, err)
+	require.Equal(t, "2", s.Version.Version)
+	vs, err := c.Versions(ctx, sqlregistry.KindState, "users")
+	require.NoError(t, err)
+	require.Len(t, vs, 2)
+
+	// Directories.
+	dir := &migrate.MemDir{}
+	require.NoError(t, dir.WriteFile("1_init.sql", []byte("CREATE TABLE t(c int);\n")))
+	v, err = c.PushDir(ctx, "users", dir, "prod")
+	require.NoError(t, err)
+	require.Equal(t, sqlregistry.KindDir, v.Kind)
+	pulled, err := c.PullDir(ctx, "users", "prod")
+	require.NoError(t, err)
+	files, err := pulled.Files()
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.Equal(t, "1_init.sql", files[0].Name())
+
+	// State reader.
+	r, err := sqlregistry.StateReader(c, "users", "v1", func(_ context.Context, s *sqlregistry.State) (*schema.Realm, error) {
+		require.Equal(t, "1", s.Version.Version)
+		return schema.NewRealm(schema.New("users")), nil
+	}).ReadState(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "users", r.Schemas[0].Name)
+	_, err = sqlregistry.StateReader(c, "users", "v1", func(context.Context, *sqlregistry.State) (*schema.Realm, error) {
+		return nil, errors.New("invalid")
+	}).ReadState(ctx)
+	require.EqualError(t, err, "sql/sqlregistry: load state users@v1: invalid")
+
+	// Unauthorized.
+	c, err = sqlregistry.NewHTTPClient(srv.URL)
+	require.NoError(t, err)
+	_, err = c.Versions(ctx, sqlregistry.KindState, "users")
+	require.EqualError(t, err, "sql/sqlregistry: GET "+srv.URL+"/users/states: unexpected status 401: unauthorized")
+}
+
+// registry is a minimal in-memory registry used for testing.
+type registry struct {
+	t        *testing.T
+	versions map[string][]*sqlregistry.Version
+	content  map[string]map[string]any
+	tags     map[string]map[string]string
+}
+
+func newRegistry(t *testing.T) *registry {
+	return &registry{
+		t:        t,
+		versions: make(map[string][]*sqlregistry.Version),
+		content:  make(map[string]map[string]any),
+		tags:     make(map[string]map[string]string),
+	}
+}
+
+func (r *registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Header.Get("Authorization") != "Bearer secret" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var (
+		parts = strings.Split(strings.TrimPrefix(req.URL.Path, "/"), "/")
+		key   = parts[0] + "/" + parts[1]
+	)
+	switch {
+	case req.Method == http.MethodPost && len(parts) == 2:
+		var b struct {
+			Format  string   `json:"format"`
+			Content []byte   `json:"content"`
+			Tags    []string `json:"tags"`
+		}
+		require.NoError(r.t, json.NewDecoder(req.Body).Decode(&b))
+		v := &sqlregistry.Version{
+			Kind:    sqlregistry.Kind(parts[1]),
+			Name:    parts[0],
+			Version: strconv.Itoa(len(r.versions[key]) + 1),
+			Tags:    append(b.Tags, sqlregistry.TagLatest),
+		}
+		r.versions[key] = append(r.versions[key], v)
+		if r.content[key] == nil {
+			r.content[key], r.tags[key] = make(map[string]any), make(map[string]string)
+		}
+		r.content[key][v.Version] = map[string]any{"format": b.Format, "content": b.Content, "version": v}
+		for _, t := range v.Tags {
+			r.tags[key][t] = v.Version
+		}
+		require.NoError(r.t, json.NewEncoder(w).Encode(v))
+	case req.Method == http.MethodGet && len(parts) == 2:
+		require.NoError(r.t, json.NewEncoder(w).Encode(r.versions[key]))
+	case req.Method == http.MethodGet && len(parts) == 3:
+		ref := parts[2]
+		if v, ok := r.tags[key][ref]; ok {
+			ref = v
+		}
+		c, ok := r.content[key][ref]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		require.NoError(r.t, json.NewEncoder(w).Encode(c))
+	case req.Method == http.MethodPut && len(parts) == 4 && parts[2] == "tags":
+		var b struct {
+			Version string `json:"version"`
+		}
+		require.NoError(r.t, json.NewDecoder(req.Body).Decode(&b))
+		r.tags[key][parts[3]] = b.Version
+	default:
+		http.Error(w, "bad request", http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package sqlregistry provides a client for pushing and pulling versioned schema
+// states and migration directories to and from a remote schema registry, allowing
+// multiple services to share and consume canonical schema versions.
+package sqlregistry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	// Client is the interface implemented by schema registry clients. Artifacts are
+	// stored under a name (e.g., the name of a service schema), and each push creates
+	// a new immutable version. A reference (ref) is either a version or a tag pointing
+	// to a version. The special tag "latest" always points to the last pushed version.
+	Client interface {
+		// PushState pushes a new version of the named schema state and tags it.
+		PushState(ctx context.Context, name string, s *State, tags ...string) (*Version, error)
+		// PullState pulls the version of the named schema state that is referenced by ref.
+		PullState(ctx context.Context, name, ref string) (*State, error)
+		// PushDir pushes a new version of the named migration directory and tags it.
+		PushDir(ctx context.Context, name string, dir migrate.Dir, tags ...string) (*Version, error)
+		// PullDir pulls the version of the named migration directory that is referenced by ref.
+		PullDir(ctx context.Context, name, ref string) (migrate.Dir, error)
+		// Tag sets the given tag to point to the version of the named artifact.
+		Tag(ctx context.Context, kind Kind, name, version, tag string) error
+		// Versions returns the versions of the named artifact, ordered by their creation time.
+		Versions(ctx context.Context, kind Kind, name string) ([]*Version, error)
+	}
+
+	// Kind describes the kind of artifact stored in the registry.
+	Kind string
+
+	// State describes a schema state stored in the registry.
+	State struct {
+		// Format of the content. e.g., "hcl" or "sql".
+		Format string `json:"format"`
+		// Content holds the schema definition.
+		Content []byte `json:"content"`
+		// Version is set by the registry when the state is pulled.
+		Version *Version `json:"version,omitempty"`
+	}
+
+	// Version describes an immutable version of an artifact stored in the registry.
+	Version struct {
+		Kind      Kind      `json:"kind"`
+		Name      string    `json:"name"`
+		Version   string    `json:"version"`
+		Hash      string    `json:"hash,omitempty"`
+		Tags      []string  `json:"tags,omitempty"`
+		CreatedAt time.Time `json:"createdAt"`
+	}
+)
+
+// List of artifact kinds.
+const (
+	KindState Kind = "states"
+	KindDir   Kind = "dirs"
+)
+
+// TagLatest is the tag that points to the last pushed version of an artifact.
+const TagLatest = "latest"
+
+// ErrNotFound is returned when the requested artifact, version or tag does not exist.
+var ErrNotFound = errors.New("sql/sqlregistry: not found")
+
+// StateReader returns a migrate.StateReader that pulls the referenced schema state
+// from the registry and loads it using the given function. It allows using the
+// canonical schema stored in the registry as the desired state of a migration plan.
+func StateReader(c Client, name, ref string, load func(context.Context, *State) (*schema.Realm, error)) migrate.StateReader {
+	return migrate.StateReaderFunc(func(ctx context.Context) (*schema.Realm, error) {
+		s, err := c.PullState(ctx, name, ref)
+		if err != nil {
+			return nil, err
+		}
+		r, err := load(ctx, s)
+		if err != nil {
+			return nil, fmt.Errorf("sql/sqlregistry: load state %s@%s: %w", name, ref, err)
+		}
+		return r, nil
+	})
+}
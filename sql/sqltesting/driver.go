@@ -0,0 +1,597 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqltesting
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	// Driver is an in-memory fake implementation of migrate.Driver. It allows applications
+	// to unit test their migration logic without a real database or sqlmock plumbing. The
+	// state of the fake database is a scriptable schema.Realm that is returned by inspection
+	// and is updated by ApplyChanges, and all executed statements are recorded.
+	//
+	// Schema diffing is delegated to the configured Differ (e.g., postgres.DefaultDiff), and
+	// plans are generated by the configured PlanApplier. By default, plans contain a single
+	// statement per change, describing it in a human-readable form (see DescribeChanges).
+	Driver struct {
+		mu      sync.Mutex
+		realm   *schema.Realm
+		differ  schema.Differ
+		planner migrate.PlanApplier
+		eq      schema.ExecQuerier
+		stmts   []string
+		fails   []*failure
+		locks   map[string]bool
+	}
+
+	// DriverOption configures a Driver.
+	DriverOption func(*Driver)
+
+	// failure describes a scripted failure of statements execution.
+	failure struct {
+		match string
+		err   error
+	}
+)
+
+var _ migrate.Driver = (*Driver)(nil)
+
+// NewDriver returns a new fake Driver for the given realm. A nil realm
+// is treated as an empty database.
+func NewDriver(r *schema.Realm, opts ...DriverOption) *Driver {
+	if r == nil {
+		r = schema.NewRealm()
+	}
+	d := &Driver{realm: cloneRealm(r), locks: make(map[string]bool)}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// WithDiffer configures the Differ used by the Driver for computing schema changes.
+func WithDiffer(df schema.Differ) DriverOption {
+	return func(d *Driver) {
+		d.differ = df
+	}
+}
+
+// WithPlanner configures the PlanApplier used by the Driver for generating the
+// migration plans. Note, only its PlanChanges method is used, as ApplyChanges
+// executes the planned statements on the fake Driver.
+func WithPlanner(p migrate.PlanApplier) DriverOption {
+	return func(d *Driver) {
+		d.planner = p
+	}
+}
+
+// WithExecQuerier configures an ExecQuerier (e.g., a sqlmock database) that queries
+// and statements are delegated to. By default, queries are not supported and statements
+// are only recorded.
+func WithExecQuerier(eq schema.ExecQuerier) DriverOption {
+	return func(d *Driver) {
+		d.eq = eq
+	}
+}
+
+// SetRealm replaces the current state of the fake database.
+func (d *Driver) SetRealm(r *schema.Realm) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.realm = cloneRealm(r)
+}
+
+// Realm returns a copy of the current state of the fake database.
+func (d *Driver) Realm() *schema.Realm {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return cloneRealm(d.realm)
+}
+
+// Stmts returns the statements that were executed on the fake database.
+func (d *Driver) Stmts() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return slices.Clone(d.stmts)
+}
+
+// Reset clears the recorded statements and the scripted failures.
+func (d *Driver) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stmts, d.fails = nil, nil
+}
+
+// FailOn configures the Driver to fail the execution of the
+// next statement that contains the given text with err.
+func (d *Driver) FailOn(match string, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.fails = append(d.fails, &failure{match: match, err: err})
+}
+
+// ExecContext implements the schema.ExecQuerier interface.
+func (d *Driver) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	d.mu.Lock()
+	for i, f := range d.fails {
+		if strings.Contains(query, f.match) {
+			d.fails = slices.Delete(d.fails, i, i+1)
+			d.mu.Unlock()
+			return nil, f.err
+		}
+	}
+	d.stmts = append(d.stmts, query)
+	d.mu.Unlock()
+	if d.eq != nil {
+		return d.eq.ExecContext(ctx, query, args...)
+	}
+	return driver.RowsAffected(0), nil
+}
+
+// QueryContext implements the schema.ExecQuerier interface.
+func (d *Driver) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	if d.eq == nil {
+		return nil, fmt.Errorf("sqltesting: queries are not supported by the fake driver: %q", query)
+	}
+	return d.eq.QueryContext(ctx, query, args...)
+}
+
+// InspectSchema implements the schema.Inspector interface.
+func (d *Driver) InspectSchema(_ context.Context, name string, opts *schema.InspectOptions) (*schema.Schema, error) {
+	r := d.Realm()
+	if name == "" && len(r.Schemas) > 0 {
+		name = r.Schemas[0].Name
+	}
+	s, ok := r.Schema(name)
+	if !ok {
+		return nil, &schema.NotExistError{Err: fmt.Errorf("sqltesting: schema %q was not found", name)}
+	}
+	if opts != nil && len(opts.Tables) > 0 {
+		s.Tables = slices.DeleteFunc(s.Tables, func(t *schema.Table) bool {
+			return !slices.Contains(opts.Tables, t.Name)
+		})
+	}
+	return s, nil
+}
+
+// InspectRealm implements the schema.Inspector interface.
+func (d *Driver) InspectRealm(_ context.Context, opts *schema.InspectRealmOption) (*schema.Realm, error) {
+	r := d.Realm()
+	if opts != nil && len(opts.Schemas) > 0 {
+		r.Schemas = slices.DeleteFunc(r.Schemas, func(s *schema.Schema) bool {
+			return !slices.Contains(opts.Schemas, s.Name)
+		})
+	}
+	return r, nil
+}
+
+// RealmDiff implements the schema.Differ interface.
+func (d *Driver) RealmDiff(from, to *schema.Realm, opts ...schema.DiffOption) ([]schema.Change, error) {
+	if d.differ == nil {
+		return nil, errors.New("sqltesting: no differ was configured for the fake driver")
+	}
+	return d.differ.RealmDiff(from, to, opts...)
+}
+
+// SchemaDiff implements the schema.Differ interface.
+func (d *Driver) SchemaDiff(from, to *schema.Schema, opts ...schema.DiffOption) ([]schema.Change, error) {
+	if d.differ == nil {
+		return nil, errors.New("sqltesting: no differ was configured for the fake driver")
+	}
+	return d.differ.SchemaDiff(from, to, opts...)
+}
+
+// TableDiff implements the schema.Differ interface.
+func (d *Driver) TableDiff(from, to *schema.Table, opts ...schema.DiffOption) ([]schema.Change, error) {
+	if d.differ == nil {
+		return nil, errors.New("sqltesting: no differ was configured for the fake driver")
+	}
+	return d.differ.TableDiff(from, to, opts...)
+}
+
+// PlanChanges implements the migrate.PlanApplier interface.
+func (d *Driver) PlanChanges(ctx context.Context, name string, changes []schema.Change, opts ...migrate.PlanOption) (*migrate.Plan, error) {
+	if d.planner != nil {
+		return d.planner.PlanChanges(ctx, name, changes, opts...)
+	}
+	p := &migrate.Plan{Name: name, Transactional: true}
+	for _, c := range changes {
+		p.Changes = append(p.Changes, &migrate.Change{
+			Cmd:    strings.TrimSuffix(DescribeChanges([]schema.Change{c}), "\n"),
+			Source: c,
+		})
+	}
+	return p, nil
+}
+
+// ApplyChanges implements the migrate.PlanApplier interface. The planned statements
+// are executed (i.e., recorded) on the fake database, and the changes are applied on
+// its state only if all statements were executed successfully.
+func (d *Driver) ApplyChanges(ctx context.Context, changes []schema.Change, opts ...migrate.PlanOption) error {
+	p, err := d.PlanChanges(ctx, "apply", changes, opts...)
+	if err != nil {
+		return err
+	}
+	for _, c := range p.Changes {
+		if _, err := d.ExecContext(ctx, c.Cmd, c.Args...); err != nil {
+			return err
+		}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var o migrate.PlanOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	r := cloneRealm(d.realm)
+	if err := apply(r, changes, o.SchemaQualifier); err != nil {
+		return err
+	}
+	d.realm = r
+	return nil
+}
+
+// Lock implements the schema.Locker interface.
+func (d *Driver) Lock(_ context.Context, name string, _ time.Duration) (schema.UnlockFunc, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.locks[name] {
+		return nil, schema.ErrLocked
+	}
+	d.locks[name] = true
+	return func() error {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		delete(d.locks, name)
+		return nil
+	}, nil
+}
+
+// Snapshot implements the migrate.Snapshoter interface.
+func (d *Driver) Snapshot(context.Context) (migrate.RestoreFunc, error) {
+	r := d.Realm()
+	return func(context.Context) error {
+		d.SetRealm(r)
+		return nil
+	}, nil
+}
+
+// CheckClean implements the migrate.CleanChecker interface.
+func (d *Driver) CheckClean(_ context.Context, revT *migrate.TableIdent) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, s := range d.realm.Schemas {
+		for _, t := range s.Tables {
+			if revT == nil || t.Name != revT.Name || (revT.Schema != "" && revT.Schema != s.Name) {
+				return &migrate.NotCleanError{State: d.realm, Reason: fmt.Sprintf("found table %q in schema %q", t.Name, s.Name)}
+			}
+		}
+	}
+	return nil
+}
+
+// apply the given changes on the realm. If the schema qualifier is not nil,
+// tables are resolved in the qualified schema instead of their own schema.
+func apply(r *schema.Realm, changes []schema.Change, q *string) error {
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddSchema:
+			if _, ok := r.Schema(c.S.Name); ok {
+				return fmt.Errorf("sqltesting: schema %q already exists", c.S.Name)
+			}
+			r.AddSchemas(cloneSchema(c.S))
+		case *schema.DropSchema:
+			if _, ok := r.Schema(c.S.Name); !ok {
+				return fmt.Errorf("sqltesting: schema %q was not found", c.S.Name)
+			}
+			r.Schemas = slices.DeleteFunc(r.Schemas, func(s *schema.Schema) bool { return s.Name == c.S.Name })
+		case *schema.ModifySchema:
+			s, ok := r.Schema(c.S.Name)
+			if !ok {
+				return fmt.Errorf("sqltesting: schema %q was not found", c.S.Name)
+			}
+			s.Attrs = slices.Clone(c.S.Attrs)
+		case *schema.AddTable:
+			s, err := tableSchema(r, c.T, q)
+			if err != nil {
+				return err
+			}
+			if _, ok := s.Table(c.T.Name); ok {
+				return fmt.Errorf("sqltesting: table %q already exists", c.T.Name)
+			}
+			s.AddTables(cloneTable(c.T))
+		case *schema.DropTable:
+			s, err := tableSchema(r, c.T, q)
+			if err != nil {
+				return err
+			}
+			if _, ok := s.Table(c.T.Name); !ok {
+				return fmt.Errorf("sqltesting: table %q was not found", c.T.Name)
+			}
+			s.Tables = slices.DeleteFunc(s.Tables, func(t *schema.Table) bool { return t.Name == c.T.Name })
+		case *schema.RenameTable:
+			t, err := findTable(r, c.From, q)
+			if err != nil {
+				return err
+			}
+			t.Name = c.To.Name
+		case *schema.ModifyTable:
+			t, err := findTable(r, c.T, q)
+			if err != nil {
+				return err
+			}
+			if err := applyTable(t, c.Changes); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("sqltesting: applying %T is not supported by the fake driver", c)
+		}
+	}
+	relink(r)
+	return nil
+}
+
+// applyTable applies the given changes on the table.
+func applyTable(t *schema.Table, changes []schema.Change) error {
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddColumn:
+			t.AddColumns(cloneColumn(c.C))
+		case *schema.DropColumn:
+			t.Columns = slices.DeleteFunc(t.Columns, func(c1 *schema.Column) bool { return c1.Name == c.C.Name })
+		case *schema.ModifyColumn:
+			i := slices.IndexFunc(t.Columns, func(c1 *schema.Column) bool { return c1.Name == c.From.Name })
+			if i == -1 {
+				return fmt.Errorf("sqltesting: column %q was not found in table %q", c.From.Name, t.Name)
+			}
+			t.Columns[i] = cloneColumn(c.To)
+		case *schema.RenameColumn:
+			col, ok := t.Column(c.From.Name)
+			if !ok {
+				return fmt.Errorf("sqltesting: column %q was not found in table %q", c.From.Name, t.Name)
+			}
+			col.Name = c.To.Name
+		case *schema.AddIndex:
+			t.AddIndexes(cloneIndex(c.I))
+		case *schema.DropIndex:
+			t.Indexes = slices.DeleteFunc(t.Indexes, func(i *schema.Index) bool { return i.Name == c.I.Name })
+		case *schema.ModifyIndex:
+			i := slices.IndexFunc(t.Indexes, func(i *schema.Index) bool { return i.Name == c.From.Name })
+			if i == -1 {
+				return fmt.Errorf("sqltesting: index %q was not found in table %q", c.From.Name, t.Name)
+			}
+			t.Indexes[i] = cloneIndex(c.To)
+		case *schema.RenameIndex:
+			idx, ok := t.Index(c.From.Name)
+			if !ok {
+				return fmt.Errorf("sqltesting: index %q was not found in table %q", c.From.Name, t.Name)
+			}
+			idx.Name = c.To.Name
+		case *schema.AddPrimaryKey:
+			t.PrimaryKey = cloneIndex(c.P)
+		case *schema.DropPrimaryKey:
+			t.PrimaryKey = nil
+		case *schema.ModifyPrimaryKey:
+			t.PrimaryKey = cloneIndex(c.To)
+		case *schema.AddForeignKey:
+			t.AddForeignKeys(cloneForeignKey(c.F))
+		case *schema.DropForeignKey:
+			t.ForeignKeys = slices.DeleteFunc(t.ForeignKeys, func(fk *schema.ForeignKey) bool { return fk.Symbol == c.F.Symbol })
+		case *schema.ModifyForeignKey:
+			i := slices.IndexFunc(t.ForeignKeys, func(fk *schema.ForeignKey) bool { return fk.Symbol == c.From.Symbol })
+			if i == -1 {
+				return fmt.Errorf("sqltesting: foreign key %q was not found in table %q", c.From.Symbol, t.Name)
+			}
+			t.ForeignKeys[i] = cloneForeignKey(c.To)
+		case *schema.AddCheck:
+			t.Attrs = append(t.Attrs, c.C)
+		case *schema.DropCheck:
+			t.Attrs = slices.DeleteFunc(t.Attrs, func(a schema.Attr) bool {
+				ck, ok := a.(*schema.Check)
+				return ok && ck.Name == c.C.Name
+			})
+		case *schema.ModifyCheck:
+			i := slices.IndexFunc(t.Attrs, func(a schema.Attr) bool {
+				ck, ok := a.(*schema.Check)
+				return ok && ck.Name == c.From.Name
+			})
+			if i == -1 {
+				return fmt.Errorf("sqltesting: check %q was not found in table %q", c.From.Name, t.Name)
+			}
+			t.Attrs[i] = c.To
+		case *schema.AddAttr:
+			t.Attrs = append(t.Attrs, c.A)
+		case *schema.DropAttr:
+			t.Attrs = slices.DeleteFunc(t.Attrs, func(a schema.Attr) bool { return a == c.A })
+		case *schema.ModifyAttr:
+			if i := slices.Index(t.Attrs, c.From); i != -1 {
+				t.Attrs[i] = c.To
+			} else {
+				t.Attrs = append(t.Attrs, c.To)
+			}
+		default:
+			return fmt.Errorf("sqltesting: applying %T is not supported by the fake driver", c)
+		}
+	}
+	return nil
+}
+
+// tableSchema returns the schema of the given table in the realm. Tables that are not
+// attached to a schema, or with an empty qualifier, are attached to the first schema.
+func tableSchema(r *schema.Realm, t *schema.Table, q *string) (*schema.Schema, error) {
+	switch {
+	case q != nil && *q != "":
+		s, ok := r.Schema(*q)
+		if !ok {
+			return nil, fmt.Errorf("sqltesting: schema %q was not found", *q)
+		}
+		return s, nil
+	case t.Schema != nil && q == nil && t.Schema.Name != "":
+		s, ok := r.Schema(t.Schema.Name)
+		if !ok {
+			return nil, fmt.Errorf("sqltesting: schema %q was not found", t.Schema.Name)
+		}
+		return s, nil
+	case len(r.Schemas) > 0:
+		return r.Schemas[0], nil
+	default:
+		return nil, fmt.Errorf("sqltesting: no schema was found for table %q", t.Name)
+	}
+}
+
+// findTable returns the table in the realm that has the same name as the given table.
+func findTable(r *schema.Realm, t *schema.Table, q *string) (*schema.Table, error) {
+	s, err := tableSchema(r, t, q)
+	if err != nil {
+		return nil, err
+	}
+	t1, ok := s.Table(t.Name)
+	if !ok {
+		return nil, fmt.Errorf("sqltesting: table %q was not found", t.Name)
+	}
+	return t1, nil
+}
+
+// cloneRealm returns a deep copy of the realm structure. Types, expressions,
+// attributes and schema objects are shared, as they are not modified in place.
+func cloneRealm(r *schema.Realm) *schema.Realm {
+	c := &schema.Realm{Attrs: slices.Clone(r.Attrs)}
+	for _, s := range r.Schemas {
+		c.AddSchemas(cloneSchema(s))
+	}
+	relink(c)
+	return c
+}
+
+func cloneSchema(s *schema.Schema) *schema.Schema {
+	c := &schema.Schema{
+		Name:    s.Name,
+		Views:   slices.Clone(s.Views),
+		Funcs:   slices.Clone(s.Funcs),
+		Procs:   slices.Clone(s.Procs),
+		Attrs:   slices.Clone(s.Attrs),
+		Objects: slices.Clone(s.Objects),
+	}
+	for _, t := range s.Tables {
+		c.AddTables(cloneTable(t))
+	}
+	return c
+}
+
+func cloneTable(t *schema.Table) *schema.Table {
+	c := &schema.Table{
+		Name:     t.Name,
+		Attrs:    slices.Clone(t.Attrs),
+		Triggers: slices.Clone(t.Triggers),
+		Deps:     slices.Clone(t.Deps),
+		Refs:     slices.Clone(t.Refs),
+	}
+	for _, col := range t.Columns {
+		c.AddColumns(cloneColumn(col))
+	}
+	for _, idx := range t.Indexes {
+		c.AddIndexes(cloneIndex(idx))
+	}
+	if t.PrimaryKey != nil {
+		c.PrimaryKey = cloneIndex(t.PrimaryKey)
+		c.PrimaryKey.Table = c
+	}
+	for _, fk := range t.ForeignKeys {
+		c.AddForeignKeys(cloneForeignKey(fk))
+	}
+	return c
+}
+
+func cloneColumn(c *schema.Column) *schema.Column {
+	col := &schema.Column{Name: c.Name, Default: c.Default, Attrs: slices.Clone(c.Attrs)}
+	if c.Type != nil {
+		ct := *c.Type
+		col.Type = &ct
+	}
+	return col
+}
+
+// cloneIndex clones the index. Its columns are resolved by relink.
+func cloneIndex(idx *schema.Index) *schema.Index {
+	c := &schema.Index{Name: idx.Name, Unique: idx.Unique, Attrs: slices.Clone(idx.Attrs)}
+	for _, p := range idx.Parts {
+		p1 := *p
+		c.Parts = append(c.Parts, &p1)
+	}
+	return c
+}
+
+// cloneForeignKey clones the foreign key. Its columns and
+// referenced table are resolved by relink.
+func cloneForeignKey(fk *schema.ForeignKey) *schema.ForeignKey {
+	c := *fk
+	c.Columns, c.RefColumns = slices.Clone(fk.Columns), slices.Clone(fk.RefColumns)
+	return &c
+}
+
+// relink resolves the columns and tables referenced by indexes and
+// foreign keys, by their names, to the objects defined in the realm.
+func relink(r *schema.Realm) {
+	for _, s := range r.Schemas {
+		s.Realm = r
+		for _, t := range s.Tables {
+			t.Schema = s
+			for _, c := range t.Columns {
+				c.Indexes, c.ForeignKeys = nil, nil
+			}
+		}
+	}
+	column := func(t *schema.Table, c *schema.Column) *schema.Column {
+		if c1, ok := t.Column(c.Name); ok {
+			return c1
+		}
+		return c
+	}
+	for _, s := range r.Schemas {
+		for _, t := range s.Tables {
+			for _, idx := range append(slices.Clone(t.Indexes), t.PrimaryKey) {
+				if idx == nil {
+					continue
+				}
+				idx.Table = t
+				for _, p := range idx.Parts {
+					if p.C != nil {
+						p.C = column(t, p.C)
+						p.C.Indexes = append(p.C.Indexes, idx)
+					}
+				}
+			}
+			for _, fk := range t.ForeignKeys {
+				fk.Table = t
+				for i, c := range fk.Columns {
+					fk.Columns[i] = column(t, c)
+					fk.Columns[i].ForeignKeys = append(fk.Columns[i].ForeignKeys, fk)
+				}
+				if fk.RefTable == nil {
+					continue
+				}
+				if ref, err := findTable(r, fk.RefTable, nil); err == nil {
+					fk.RefTable = ref
+				}
+				for i, c := range fk.RefColumns {
+					fk.RefColumns[i] = column(fk.RefTable, c)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqltesting_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlite"
+	"ariga.io/atlas/sql/sqltesting"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriver(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		users = schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))
+		drv   = sqltesting.NewDriver(schema.NewRealm(schema.New("main").AddTables(users)), sqltesting.WithDiffer(sqlite.DefaultDiff))
+	)
+	users.SetPrimaryKey(schema.NewPrimaryKey(users.Columns[0]))
+	s, err := drv.InspectSchema(ctx, "", nil)
+	require.NoError(t, err)
+	require.Equal(t, "main", s.Name)
+	require.Len(t, s.Tables, 1)
+	require.Nil(t, s.Tables[0].PrimaryKey, "realm is copied on creation")
+	_, err = drv.InspectSchema(ctx, "other", nil)
+	require.True(t, schema.IsNotExistError(err))
+
+	// Plan and apply the changes between the current and desired states.
+	desired := schema.New("main").AddTables(
+		schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"), schema.NewStringColumn("name", "text")),
+		schema.NewTable("posts").AddColumns(schema.NewIntColumn("id", "int"), schema.NewIntColumn("author_id", "int")),
+	)
+	posts := desired.Tables[1]
+	posts.AddForeignKeys(schema.NewForeignKey("author").AddColumns(posts.Columns[1]).SetRefTable(desired.Tables[0]).AddRefColumns(desired.Tables[0].Columns[0]))
+	changes, err := drv.SchemaDiff(s, desired)
+	require.NoError(t, err)
+	plan, err := drv.PlanChanges(ctx, "add_posts", changes)
+	require.NoError(t, err)
+	require.Equal(t, "modify table \"users\"\n  add column \"name\"", plan.Changes[0].Cmd)
+	require.Equal(t, "add table \"posts\"", plan.Changes[1].Cmd)
+
+	drv.FailOn("posts", errors.New("table is locked"))
+	require.EqualError(t, drv.ApplyChanges(ctx, changes), "table is locked")
+	require.Equal(t, []string{"modify table \"users\"\n  add column \"name\""}, drv.Stmts())
+	s, err = drv.InspectSchema(ctx, "main", nil)
+	require.NoError(t, err)
+	require.Len(t, s.Tables, 1, "state is not changed on failure")
+
+	drv.Reset()
+	restore, err := drv.Snapshot(ctx)
+	require.NoError(t, err)
+	require.NoError(t, drv.ApplyChanges(ctx, changes))
+	require.Len(t, drv.Stmts(), 2)
+	s, err = drv.InspectSchema(ctx, "main", nil)
+	require.NoError(t, err)
+	require.Len(t, s.Tables, 2)
+	require.Len(t, s.Tables[0].Columns, 2)
+	fk := s.Tables[1].ForeignKeys[0]
+	require.Equal(t, s.Tables[0], fk.RefTable)
+	require.Equal(t, s.Tables[0].Columns[0], fk.RefColumns[0])
+	require.Equal(t, s.Tables[1].Columns[1], fk.Columns[0])
+	changes, err = drv.SchemaDiff(s, desired)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+	s, err = drv.InspectSchema(ctx, "main", &schema.InspectOptions{Tables: []string{"posts"}})
+	require.NoError(t, err)
+	require.Len(t, s.Tables, 1)
+
+	require.IsType(t, &migrate.NotCleanError{}, drv.CheckClean(ctx, nil))
+	require.NoError(t, restore(ctx))
+	r, err := drv.InspectRealm(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, r.Schemas[0].Tables, 1)
+	require.Error(t, drv.ApplyChanges(ctx, []schema.Change{&schema.DropTable{T: schema.NewTable("unknown")}}))
+
+	unlock, err := drv.Lock(ctx, "atlas", 0)
+	require.NoError(t, err)
+	_, err = drv.Lock(ctx, "atlas", 0)
+	require.ErrorIs(t, err, schema.ErrLocked)
+	require.NoError(t, unlock())
+
+	_, err = drv.QueryContext(ctx, "SELECT 1")
+	require.EqualError(t, err, `sqltesting: queries are not supported by the fake driver: "SELECT 1"`)
+}
+
+func TestDriver_Executor(t *testing.T) {
+	var (
+		ctx = context.Background()
+		drv = sqltesting.NewDriver(schema.NewRealm(schema.New("main")))
+		dir = &migrate.MemDir{}
+	)
+	require.NoError(t, dir.WriteFile("1_init.sql", []byte("CREATE TABLE t(c int);\nCREATE TABLE t2(c int);\n")))
+	sum, err := dir.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(dir, sum))
+	ex, err := migrate.NewExecutor(drv, dir, &mockRevisions{})
+	require.NoError(t, err)
+	require.NoError(t, ex.ExecuteN(ctx, 0))
+	require.Equal(t, []string{"CREATE TABLE t(c int);", "CREATE TABLE t2(c int);"}, drv.Stmts())
+}
+
+// mockRevisions is an in-memory revisions storage.
+type mockRevisions struct {
+	revs []*migrate.Revision
+}
+
+func (*mockRevisions) Ident() *migrate.TableIdent {
+	return &migrate.TableIdent{Name: "atlas_schema_revisions"}
+}
+
+func (r *mockRevisions) ReadRevisions(context.Context) ([]*migrate.Revision, error) {
+	return r.revs, nil
+}
+
+func (r *mockRevisions) ReadRevision(_ context.Context, v string) (*migrate.Revision, error) {
+	for _, rev := range r.revs {
+		if rev.Version == v {
+			return rev, nil
+		}
+	}
+	return nil, migrate.ErrRevisionNotExist
+}
+
+func (r *mockRevisions) WriteRevision(_ context.Context, rev *migrate.Revision) error {
+	for i, r1 := range r.revs {
+		if r1.Version == rev.Version {
+			r.revs[i] = rev
+			return nil
+		}
+	}
+	r.revs = append(r.revs, rev)
+	return nil
+}
+
+func (r *mockRevisions) DeleteRevision(_ context.Context, v string) error {
+	for i, rev := range r.revs {
+		if rev.Version == v {
+			r.revs = append(r.revs[:i], r.revs[i+1:]...)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,322 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package sqltesting provides helpers for testing applications and libraries that
+// work with Atlas, such as golden-file assertions for migration plans, readable
+// comparison of schema realms, an in-memory fake driver and sqlmock utilities.
+package sqltesting
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// TestingT is the subset of testing.TB used by the assertion helpers.
+type TestingT interface {
+	Helper()
+	Fatalf(format string, args ...any)
+}
+
+// UpdateEnv is the environment variable that, when set to a non-empty
+// value, makes the golden-file helpers (re)write their golden files
+// instead of comparing them with the actual output.
+const UpdateEnv = "ATLAS_UPDATE_GOLDEN"
+
+// PlanSQL returns the normalized SQL statements of the given plan,
+// terminated with a semicolon and separated by a newline.
+func PlanSQL(p *migrate.Plan) string {
+	var b strings.Builder
+	for _, c := range p.Changes {
+		b.WriteString(NormalizeSQL(c.Cmd))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// GoldenPlan asserts that the SQL statements of the given plan match the statements
+// in the golden file. Statements are normalized before they are compared, allowing
+// golden files to be formatted freely (e.g., break long statements into multiple lines).
+// If the UpdateEnv environment variable is set, the golden file is written instead.
+func GoldenPlan(t TestingT, p *migrate.Plan, path string) {
+	t.Helper()
+	actual := PlanSQL(p)
+	if os.Getenv(UpdateEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("sqltesting: create golden directory: %v", err)
+			return
+		}
+		if err := os.WriteFile(path, []byte(actual), 0644); err != nil {
+			t.Fatalf("sqltesting: write golden file: %v", err)
+			return
+		}
+		return
+	}
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("sqltesting: read golden file (set %s=1 to create it): %v", UpdateEnv, err)
+		return
+	}
+	stmts, err := migrate.Stmts(string(buf))
+	if err != nil {
+		t.Fatalf("sqltesting: scan golden file %q: %v", path, err)
+		return
+	}
+	var expected strings.Builder
+	for _, s := range stmts {
+		expected.WriteString(NormalizeSQL(s.Text))
+		expected.WriteByte('\n')
+	}
+	if d := LineDiff(expected.String(), actual); d != "" {
+		t.Fatalf("sqltesting: plan does not match golden file %q (-expected +actual):\n%s", path, d)
+	}
+}
+
+// NormalizeSQL normalizes the given SQL statement by collapsing whitespace sequences
+// outside of quoted literals and identifiers into a single space, removing whitespace
+// after opening and before closing parentheses and commas, and ensures the statement
+// is terminated with a semicolon.
+func NormalizeSQL(s string) string {
+	var (
+		b     strings.Builder
+		quote rune
+		space bool
+	)
+	s = strings.TrimSuffix(strings.TrimSpace(s), ";")
+	for _, r := range strings.TrimSpace(s) {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case unicode.IsSpace(r):
+			space = true
+			continue
+		}
+		if space && r != ')' && r != ',' && !strings.HasSuffix(b.String(), "(") {
+			b.WriteByte(' ')
+		}
+		space = false
+		b.WriteRune(r)
+	}
+	b.WriteByte(';')
+	return b.String()
+}
+
+// LineDiff returns a line-based diff between the two strings, where lines that exist
+// only in "a" are prefixed with "-", and lines that exist only in "b" are prefixed
+// with "+". An empty string is returned if the strings are equal.
+func LineDiff(a, b string) string {
+	if a == b {
+		return ""
+	}
+	x, y := strings.Split(strings.TrimSuffix(a, "\n"), "\n"), strings.Split(strings.TrimSuffix(b, "\n"), "\n")
+	// Longest common subsequence table.
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var (
+		d    strings.Builder
+		i, j int
+	)
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			d.WriteString("  " + x[i] + "\n")
+			i, j = i+1, j+1
+		case i < len(x) && (j == len(y) || lcs[i+1][j] >= lcs[i][j+1]):
+			d.WriteString("- " + x[i] + "\n")
+			i++
+		default:
+			d.WriteString("+ " + y[j] + "\n")
+			j++
+		}
+	}
+	return d.String()
+}
+
+// EqualRealm asserts that the two realms are equal, as computed by the given
+// differ (e.g., the Driver of the dialect), and reports the changes required
+// for turning the expected realm into the actual one in case they are not.
+func EqualRealm(t TestingT, d schema.Differ, expected, actual *schema.Realm, opts ...schema.DiffOption) {
+	t.Helper()
+	changes, err := d.RealmDiff(expected, actual, opts...)
+	if err != nil {
+		t.Fatalf("sqltesting: diff realms: %v", err)
+		return
+	}
+	if len(changes) > 0 {
+		t.Fatalf("sqltesting: realms are not equal. Changes from expected to actual:\n%s", DescribeChanges(changes))
+	}
+}
+
+// EqualSchema is like EqualRealm, but compares two schemas.
+func EqualSchema(t TestingT, d schema.Differ, expected, actual *schema.Schema, opts ...schema.DiffOption) {
+	t.Helper()
+	changes, err := d.SchemaDiff(expected, actual, opts...)
+	if err != nil {
+		t.Fatalf("sqltesting: diff schemas: %v", err)
+		return
+	}
+	if len(changes) > 0 {
+		t.Fatalf("sqltesting: schemas are not equal. Changes from expected to actual:\n%s", DescribeChanges(changes))
+	}
+}
+
+// DescribeChanges returns a human-readable description of the given changes, one change
+// per line. Changes of nested objects (e.g., columns of a table) are indented under their
+// parent. For example:
+//
+//	modify table "users"
+//	  add column "name"
+//	  modify column "age" (ChangeType)
+func DescribeChanges(changes []schema.Change) string {
+	var b strings.Builder
+	describe(&b, changes, "")
+	return b.String()
+}
+
+func describe(b *strings.Builder, changes []schema.Change, indent string) {
+	for _, c := range changes {
+		v := reflect.Indirect(reflect.ValueOf(c))
+		if v.Kind() != reflect.Struct {
+			fmt.Fprintf(b, "%s%T\n", indent, c)
+			continue
+		}
+		b.WriteString(indent)
+		b.WriteString(strings.ToLower(reCamel.ReplaceAllString(v.Type().Name(), "$1 $2")))
+		switch from, to := objName(v, "From"), objName(v, "To"); {
+		case from != "" && to != "" && from != to:
+			fmt.Fprintf(b, " %q to %q", from, to)
+		case to != "":
+			fmt.Fprintf(b, " %q", to)
+		default:
+			for _, f := range []string{"S", "T", "V", "F", "P", "O", "C", "I", "A"} {
+				if n := objName(v, f); n != "" {
+					fmt.Fprintf(b, " %q", n)
+					break
+				}
+			}
+		}
+		if f := v.FieldByName("Change"); f.IsValid() {
+			if k, ok := f.Interface().(schema.ChangeKind); ok && k != schema.NoChange {
+				fmt.Fprintf(b, " (%s)", k)
+			}
+		}
+		b.WriteByte('\n')
+		if f := v.FieldByName("Changes"); f.IsValid() {
+			if cs, ok := f.Interface().([]schema.Change); ok {
+				describe(b, cs, indent+"  ")
+			}
+		}
+	}
+}
+
+// reCamel splits CamelCase type names. e.g., ModifyTable => Modify Table.
+var reCamel = regexp.MustCompile(`([a-z])([A-Z])`)
+
+// objName returns the name of the object stored in the given field, if exists.
+func objName(v reflect.Value, field string) string {
+	f := v.FieldByName(field)
+	if !f.IsValid() {
+		return ""
+	}
+	if f.Kind() == reflect.Interface || f.Kind() == reflect.Pointer {
+		if f.IsNil() {
+			return ""
+		}
+		f = f.Elem()
+	}
+	f = reflect.Indirect(f)
+	if f.Kind() != reflect.Struct {
+		return ""
+	}
+	if n := f.FieldByName("Name"); n.IsValid() && n.Kind() == reflect.String {
+		return n.String()
+	}
+	if n := f.FieldByName("T"); n.IsValid() && n.Kind() == reflect.String {
+		return n.String()
+	}
+	return ""
+}
+
+// Rows converts MySQL/PostgreSQL table output to sqlmock.Rows, allowing the
+// results of mocked queries to be written as tables. All row values are parsed
+// as text except the "nil" and NULL keywords. For example:
+//
+//	+-------------+-------------+-------------+----------------+
+//	| column_name | column_type | is_nullable | column_default |
+//	+-------------+-------------+-------------+----------------+
+//	| c1          | float       | YES         | nil            |
+//	| c2          | int         | YES         |                |
+//	| c3          | double      | YES         | NULL           |
+//	+-------------+-------------+-------------+----------------+
+func Rows(table string) *sqlmock.Rows {
+	var (
+		nc    int
+		rows  *sqlmock.Rows
+		lines = strings.Split(table, "\n")
+	)
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimFunc(lines[i], unicode.IsSpace)
+		// Skip new lines, header and footer.
+		if line == "" || strings.IndexAny(line, "+-") == 0 {
+			continue
+		}
+		columns := strings.FieldsFunc(line, func(r rune) bool {
+			return r == '|'
+		})
+		for i, c := range columns {
+			columns[i] = strings.TrimSpace(c)
+		}
+		if rows == nil {
+			nc = len(columns)
+			rows = sqlmock.NewRows(columns)
+		} else {
+			values := make([]driver.Value, nc)
+			for i, c := range columns {
+				switch c {
+				case "", "nil", "NULL":
+				default:
+					values[i] = c
+				}
+			}
+			rows.AddRow(values...)
+		}
+	}
+	return rows
+}
+
+// Escape escapes all regular expression metacharacters in the given
+// query, allowing it to be used as an exact sqlmock expectation.
+func Escape(query string) string {
+	rows := strings.Split(query, "\n")
+	for i := range rows {
+		rows[i] = strings.TrimPrefix(rows[i], " ")
+	}
+	query = strings.Join(rows, " ")
+	return strings.TrimSpace(regexp.QuoteMeta(query)) + "$"
+}
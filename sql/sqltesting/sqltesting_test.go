@@ -0,0 +1,104 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqltesting_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlite"
+	"ariga.io/atlas/sql/sqltesting"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeSQL(t *testing.T) {
+	require.Equal(t, "CREATE TABLE t (c int);", sqltesting.NormalizeSQL("CREATE  TABLE t (\n  c int\n)"))
+	require.Equal(t, "INSERT INTO t VALUES ('a  b');", sqltesting.NormalizeSQL("INSERT INTO t\tVALUES ('a  b');"))
+	require.Equal(t, "SELECT \"a  b\";", sqltesting.NormalizeSQL("  SELECT   \"a  b\"  ;  "))
+}
+
+func TestLineDiff(t *testing.T) {
+	require.Empty(t, sqltesting.LineDiff("a\nb", "a\nb"))
+	require.Equal(t, "  a\n- b\n+ c\n  d\n", sqltesting.LineDiff("a\nb\nd", "a\nc\nd"))
+}
+
+func TestGoldenPlan(t *testing.T) {
+	var (
+		tt   = &mockT{}
+		path = filepath.Join(t.TempDir(), "testdata", "plan.sql")
+		plan = &migrate.Plan{
+			Changes: []*migrate.Change{
+				{Cmd: "CREATE TABLE `users` (`id` int NOT NULL)"},
+				{Cmd: "ALTER TABLE `users` ADD COLUMN `name` text"},
+			},
+		}
+	)
+	sqltesting.GoldenPlan(tt, plan, path)
+	require.Contains(t, tt.msg, "read golden file (set ATLAS_UPDATE_GOLDEN=1 to create it)")
+
+	t.Setenv(sqltesting.UpdateEnv, "1")
+	tt = &mockT{}
+	sqltesting.GoldenPlan(tt, plan, path)
+	require.Empty(t, tt.msg)
+	buf, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE `users` (`id` int NOT NULL);\nALTER TABLE `users` ADD COLUMN `name` text;\n", string(buf))
+
+	t.Setenv(sqltesting.UpdateEnv, "")
+	require.NoError(t, os.WriteFile(path, []byte("CREATE TABLE `users` (\n  `id` int NOT NULL\n);\n\nALTER TABLE `users`\n  ADD COLUMN `name` text;\n"), 0644))
+	sqltesting.GoldenPlan(tt, plan, path)
+	require.Empty(t, tt.msg)
+
+	plan.Changes[1].Cmd = "ALTER TABLE `users` ADD COLUMN `name` varchar(255)"
+	sqltesting.GoldenPlan(tt, plan, path)
+	require.Equal(t, fmt.Sprintf(`sqltesting: plan does not match golden file %q (-expected +actual):
+  CREATE TABLE `+"`users` (`id` int NOT NULL);"+`
+- ALTER TABLE `+"`users` ADD COLUMN `name` text;"+`
++ ALTER TABLE `+"`users` ADD COLUMN `name` varchar(255);"+`
+`, path), tt.msg)
+}
+
+func TestEqualRealm(t *testing.T) {
+	var (
+		tt       = &mockT{}
+		expected = schema.NewRealm(
+			schema.New("main").AddTables(
+				schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"), schema.NewIntColumn("age", "int")),
+				schema.NewTable("posts").AddColumns(schema.NewIntColumn("id", "int")),
+			),
+		)
+		actual = schema.NewRealm(
+			schema.New("main").AddTables(
+				schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"), schema.NewStringColumn("age", "text"), schema.NewStringColumn("name", "text")),
+			),
+		)
+	)
+	sqltesting.EqualRealm(tt, sqlite.DefaultDiff, expected, expected)
+	require.Empty(t, tt.msg)
+	sqltesting.EqualRealm(tt, sqlite.DefaultDiff, expected, actual)
+	require.Equal(t, `sqltesting: realms are not equal. Changes from expected to actual:
+modify table "users"
+  modify column "age" (ChangeType)
+  add column "name"
+drop table "posts"
+`, tt.msg)
+	tt = &mockT{}
+	sqltesting.EqualSchema(tt, sqlite.DefaultDiff, expected.Schemas[0], expected.Schemas[0])
+	require.Empty(t, tt.msg)
+}
+
+// mockT records the last failure message.
+type mockT struct{ msg string }
+
+func (*mockT) Helper() {}
+
+func (m *mockT) Fatalf(format string, args ...any) {
+	m.msg = fmt.Sprintf(format, args...)
+}
@@ -26,14 +26,14 @@ import (
 var (
 	// GolangMigrateFormatter returns migrate.Formatter compatible with golang-migrate/migrate.
 	GolangMigrateFormatter = templateFormatter(
-		"{{ now }}{{ with .Name }}_{{ . }}{{ end }}.up.sql",
+		"{{ version . }}{{ with .Name }}_{{ . }}{{ end }}.up.sql",
 		`{{ range .Changes }}{{ with .Comment }}-- {{ println . }}{{ end }}{{ printf "%s;\n" .Cmd }}{{ end }}`,
-		"{{ now }}{{ with .Name }}_{{ . }}{{ end }}.down.sql",
+		"{{ version . }}{{ with .Name }}_{{ . }}{{ end }}.down.sql",
 		`{{ range $c := rev .Changes }}{{ with $stmts := .ReverseStmts }}{{ with $c.Comment }}-- reverse: {{ println . }}{{ end }}{{ range $stmts }}{{ printf "%s;\n" . }}{{ end }}{{ end }}{{ end }}`,
 	)
 	// GooseFormatter returns migrate.Formatter compatible with pressly/goose.
 	GooseFormatter = templateFormatter(
-		"{{ now }}{{ with .Name }}_{{ . }}{{ end }}.sql",
+		"{{ version . }}{{ with .Name }}_{{ . }}{{ end }}.sql",
 		`-- +goose Up
 {{ range .Changes }}{{ with .Comment }}-- {{ println . }}{{ end }}{{ printf "%s;\n" .Cmd }}{{ end }}
 -- +goose Down
@@ -41,15 +41,15 @@ var (
 	)
 	// FlywayFormatter returns migrate.Formatter compatible with Flyway.
 	FlywayFormatter = templateFormatter(
-		"V{{ now }}{{ with .Name }}__{{ . }}{{ end }}.sql",
+		"V{{ version . }}{{ with .Name }}__{{ . }}{{ end }}.sql",
 		`{{ range .Changes }}{{ with .Comment }}-- {{ println . }}{{ end }}{{ printf "%s;\n" .Cmd }}{{ end }}`,
-		"U{{ now }}{{ with .Name }}__{{ . }}{{ end }}.sql",
+		"U{{ version . }}{{ with .Name }}__{{ . }}{{ end }}.sql",
 		`{{ range $c := rev .Changes }}{{ with $stmts := .ReverseStmts }}{{ with $c.Comment }}-- reverse: {{ println . }}{{ end }}{{ range $stmts }}{{ printf "%s;\n" . }}{{ end }}{{ end }}{{ end }}`,
 	)
 	// LiquibaseFormatter returns migrate.Formatter compatible with Liquibase.
 	LiquibaseFormatter = templateFormatter(
-		"{{ now }}{{ with .Name }}_{{ . }}{{ end }}.sql",
-		`{{- $now := now -}}
+		"{{ version . }}{{ with .Name }}_{{ . }}{{ end }}.sql",
+		`{{- $now := version . -}}
 --liquibase formatted sql
 
 {{- range $index, $change := .Changes }}
@@ -61,7 +61,7 @@ var (
 	)
 	// DBMateFormatter returns migrate.Formatter compatible with amacneil/dbmate.
 	DBMateFormatter = templateFormatter(
-		"{{ now }}{{ with .Name }}_{{ . }}{{ end }}.sql",
+		"{{ version . }}{{ with .Name }}_{{ . }}{{ end }}.sql",
 		`-- migrate:up
 {{ range .Changes }}{{ with .Comment }}-- {{ println . }}{{ end }}{{ printf "%s;\n" .Cmd }}{{ end }}
 -- migrate:down
@@ -592,8 +592,14 @@ func unexpectedPragmaErr(f migrate.File, line int, pragma string) error {
 // funcs contains the template.FuncMap for the different formatters.
 var funcs = template.FuncMap{
 	"inc": func(x int) int { return x + 1 },
-	// now formats the current time in a lexicographically ascending order while maintaining human readability.
-	"now": func() string { return time.Now().UTC().Format("20060102150405") },
+	// version returns the version of the plan, if it was set. Otherwise, it formats the current time
+	// in a lexicographically ascending order while maintaining human readability.
+	"version": func(p *migrate.Plan) string {
+		if p.Version != "" {
+			return p.Version
+		}
+		return time.Now().UTC().Format("20060102150405")
+	},
 	"rev": reverse,
 }
 
@@ -7,6 +7,7 @@ package sqltool_test
 import (
 	"fmt"
 	"io/fs"
+	"strings"
 	"testing"
 	"testing/fstest"
 	"time"
@@ -154,6 +155,16 @@ DROP TABLE t1 IF EXISTS;
 			for name, content := range tt.expected {
 				requireFileEqual(t, d, name, content)
 			}
+
+			// Versioned plans are written with their versions.
+			d = dir(t)
+			pl = migrate.NewPlanner(nil, d, migrate.PlanFormat(tt.fmt), migrate.PlanWithChecksum(false))
+			p := *plan
+			p.Version = "20200101000000"
+			require.NoError(t, pl.WritePlan(&p))
+			for name, content := range tt.expected {
+				requireFileEqual(t, d, strings.ReplaceAll(name, v, p.Version), strings.ReplaceAll(content, v, p.Version))
+			}
 		})
 	}
 }